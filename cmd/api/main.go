@@ -8,10 +8,10 @@ import (
 	"os/signal"
 	"reflect"
 	"regexp"
-	"strconv"
 	"strings"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Group and user time zones work on images without a zoneinfo database
 
 	"github.com/gin-gonic/gin/binding"
 
@@ -20,17 +20,25 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"github.com/networkengineer-cloud/go-volunteer-media/frontend"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/alerting"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/convert"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/database"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/groupme"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/handlers"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/lifecycle"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/maintenance"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/moderation"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/oidc"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/sms"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/telemetry"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/upload"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
@@ -144,6 +152,47 @@ func main() {
 		logger.Info("Email service not configured - password reset and email notifications will be disabled")
 	}
 
+	// Initialize SMS for emergency broadcasts; with no provider they're disabled
+	smsProvider, err := sms.NewProvider()
+	if err != nil {
+		logger.Fatal("Invalid SMS configuration", err)
+	}
+	if smsProvider != nil {
+		logger.Infof("SMS configured (%s provider)", smsProvider.GetProviderName())
+	} else {
+		logger.Info("SMS not configured - emergency broadcasts will be disabled")
+	}
+
+	// Initialize image moderation; with no provider every upload is published
+	moderator, err := moderation.NewModerator()
+	if err != nil {
+		logger.Fatal("Invalid image moderation configuration", err)
+	}
+	if moderator != nil {
+		logger.Infof("Image moderation enabled (%s provider)", moderator.GetProviderName())
+	} else {
+		logger.Info("Image moderation not configured - uploaded images are published without review")
+	}
+
+	// Initialize OIDC sign-in providers (Google, Microsoft)
+	oidcProviders, err := oidc.ProvidersFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid OIDC configuration", err)
+	}
+	if n := len(oidcProviders.List()); n > 0 {
+		logger.Infof("OIDC sign-in configured with %d provider(s)", n)
+	} else {
+		logger.Info("OIDC sign-in not configured - volunteers sign in with passwords only")
+	}
+
+	// Browser sessions: bearer JWTs by default, or HttpOnly cookies with CSRF
+	// tokens when SESSION_MODE=cookie. API clients can use bearer JWTs either way.
+	if middleware.SessionConfigFromEnv().Cookies() {
+		logger.Info("Cookie sessions enabled - logins set an HttpOnly session cookie and require CSRF tokens")
+	} else {
+		logger.Info("Bearer sessions - logins return the JWT to the client")
+	}
+
 	// Initialize embedding provider for semantic search. Declared as the
 	// interface type (not *embedding.VoyageEmbedder) so it can be reset to a
 	// true nil interface below — embedding.Usable(nil) then correctly
@@ -172,6 +221,57 @@ func main() {
 	groupMeService := groupme.NewService()
 	logger.Info("GroupMe service initialized and ready")
 
+	// Sends notifications for scheduled announcements once publish_at passes
+	stopAnnouncementScheduler := handlers.StartAnnouncementScheduler(db, emailService, groupMeService, 60*time.Second)
+
+	// Queues Monday's weekly statistics emails to group admins who opted in
+	stopWeeklyStatsScheduler := handlers.StartWeeklyStatsScheduler(db, 15*time.Minute)
+
+	// Queues emails to group admins about animals past a status alert rule
+	stopStatusAlertScheduler := handlers.StartStatusAlertScheduler(db, time.Hour)
+
+	// Starts onboarding checklists, and queues welcome emails, for new group members
+	stopOnboardingScheduler := handlers.StartOnboardingScheduler(db, 5*time.Minute)
+
+	// Anonymizes self-deactivated accounts once their grace period ends
+	stopAccountPurge := maintenance.StartAccountPurge(db, maintenance.AccountDeletionGracePeriod(), time.Hour)
+
+	// Permanently removes deleted comments once their retention period ends
+	stopCommentPurge := maintenance.StartCommentPurge(db, maintenance.DeletedCommentRetention(), time.Hour)
+
+	// Removes finished data exports once they expire
+	stopExportPurge := maintenance.StartExportPurge(db, storageProvider, maintenance.DataExportRetention(), time.Hour)
+
+	// Purges or anonymizes records past their configured retention window
+	retentionPolicy := maintenance.RetentionPolicyFromEnv()
+	stopRetentionPurge := maintenance.StartRetentionPurge(db, storageProvider, retentionPolicy, 24*time.Hour)
+
+	// Reports (or, if configured, deletes) files in the uploads directory nothing references
+	orphanedUploads := maintenance.OrphanedUploadConfigFromEnv()
+	stopOrphanedUploadSweep := maintenance.StartOrphanedUploadSweep(db, orphanedUploads, 24*time.Hour)
+
+	// Operational alerts (error-rate spikes, failed jobs, job backlog) to
+	// Slack, a webhook, or email; thresholds are site settings with env override
+	alertConfig := alerting.NewConfigStore(db)
+	alertSinks, err := alerting.SinksFromEnv(db, emailService, alertConfig)
+	if err != nil {
+		logger.Fatal("Invalid alerting configuration", err)
+	}
+	alerter := alerting.NewAlerter(alertConfig, alertSinks...)
+	if len(alertSinks) > 0 {
+		logger.Infof("Operational alerts enabled (%s)", strings.Join(alerter.SinkNames(), ", "))
+	} else {
+		logger.Info("Operational alerts not configured - failures are only logged")
+	}
+	stopJobBacklogCheck := alerting.StartJobBacklogCheck(db, alerter, 5*time.Minute)
+
+	// Runs queued background jobs (e.g. announcement emails) with retries
+	jobQueue := jobs.NewQueue(db)
+	jobQueue.OnFailure(alerter.JobFailed)
+	handlers.RegisterJobHandlers(jobQueue, db, emailService, storageProvider)
+	handlers.RegisterSMSJobHandlers(jobQueue, db, smsProvider)
+	stopJobWorkers := jobQueue.Start(jobs.WorkerCount(), 5*time.Second)
+
 	// Load embedded frontend assets at startup
 	distFS, err := fs.Sub(frontend.DistFS, "dist")
 	if err != nil {
@@ -193,8 +293,18 @@ func main() {
 
 	registerCoreMiddleware(router, serviceName)
 
+	// CORS and security header configuration: site settings with env
+	// override, reloaded periodically and on admin changes
+	securityConfig := middleware.NewSecurityConfigStore(db)
+
+	// Image upload limits and processing: site settings with env override
+	imageConfig := upload.NewImageConfigStore(db)
+	upload.ConfigureHEIFFromEnv()
+	// External images on allow-listed hosts get proxy URLs as their variants
+	models.ExternalImageProxy = handlers.ProxiedImageURL
+
 	// Security headers middleware (add before CORS)
-	router.Use(middleware.SecurityHeaders())
+	router.Use(middleware.SecurityHeaders(securityConfig))
 
 	// Request ID middleware for tracing
 	router.Use(middleware.RequestID())
@@ -202,6 +312,9 @@ func main() {
 	// Structured logging middleware
 	router.Use(middleware.LoggingMiddleware())
 
+	// Counts server errors toward the error-rate alert
+	router.Use(middleware.ServerErrorAlerts(alerter))
+
 	// Request-scoped DB middleware — binds *gorm.DB to the request context
 	// once per request so GORM's OTel plugin nests query spans under the
 	// request's trace. Handlers retrieve it via middleware.GetDB(c).
@@ -210,74 +323,168 @@ func main() {
 	// Max request body size middleware — 10 MB default for most routes.
 	// Document upload routes raise this to 25 MB via per-route middleware.
 	// Per-type limits are enforced by ValidateImageUpload / ValidateDocumentUpload.
+	// Image upload routes raise this to fit the configurable image size limit.
 	router.Use(middleware.MaxRequestBodySize(10 * 1024 * 1024))
 
 	// CORS middleware
-	router.Use(middleware.CORS())
+	router.Use(middleware.CORS(securityConfig))
 
 	// Health check endpoints (public, no auth required)
 	router.GET("/health", handlers.HealthCheck())
 	router.GET("/healthz", handlers.HealthCheck())
-	router.GET("/ready", handlers.ReadinessCheck(db))
+	router.GET("/ready", handlers.ReadinessCheck(db, emailService, storageProvider))
 
 	// Serve uploaded images from database (public, cached)
 	// Legacy: also serve from filesystem for backwards compatibility
-	router.Static("/uploads", "./public/uploads")
+	router.Static("/uploads", orphanedUploads.Dir)
 	router.StaticFile("/default-hero.svg", "./public/default-hero.svg")
 
 	// Serve security.txt for responsible vulnerability disclosure
 	router.StaticFile("/.well-known/security.txt", "./public/.well-known/security.txt")
 
-	// API routes
+	// API routes. They are registered once under /api; VersionedAPI (see the
+	// server below) serves them under /api/v1 too, with /api as an alias for
+	// v1. Routes whose response shape changes in a later version wire both
+	// handlers with middleware.Versioned.
 	api := router.Group("/api")
 
 	// Serve images from database (public endpoint, no auth required)
 	api.GET("/images/:uuid", handlers.ServeImage(db, storageProvider))
 	// Serve video blobs through the backend proxy (public, no auth required)
 	api.GET("/videos/:uuid", handlers.ServeVideo(db, storageProvider))
+	// Fetch, cache, and serve external images (signed URLs, no auth required)
+	imageProxyLimiter := middleware.RateLimit(middleware.RateLimitFromEnv("IMAGE_PROXY_RATE_LIMIT_PER_MINUTE", 300), 1*time.Minute)
+	api.GET("/image-proxy", imageProxyLimiter, handlers.ServeProxiedImage(db, imageConfig))
+
+	// Rate limit budgets (requests per minute, env-overridable). Auth and
+	// public routes are limited per IP; authenticated routes per user, with
+	// tighter budgets for expensive route groups on top of the general one.
+	authLimiter := middleware.RateLimit(middleware.RateLimitFromEnv("AUTH_RATE_LIMIT_PER_MINUTE", 5), 1*time.Minute)
+	shareLimiter := middleware.RateLimit(middleware.RateLimitFromEnv("SHARE_RATE_LIMIT_PER_MINUTE", 60), 1*time.Minute)
+	apiLimiter := middleware.RateLimitByUser(middleware.RateLimitFromEnv("API_RATE_LIMIT_PER_MINUTE", 300), 1*time.Minute)
+	uploadLimiter := middleware.RateLimitByUser(middleware.RateLimitFromEnv("UPLOAD_RATE_LIMIT_PER_MINUTE", 30), 1*time.Minute)
+	commentLimiter := middleware.RateLimitByUser(middleware.RateLimitFromEnv("COMMENT_RATE_LIMIT_PER_MINUTE", 30), 1*time.Minute)
+	exportLimiter := middleware.RateLimitByUser(middleware.RateLimitFromEnv("EXPORT_RATE_LIMIT_PER_MINUTE", 5), 1*time.Minute)
+	// Emergency broadcasts text everyone who opted in, so they're budgeted per hour
+	broadcastLimiter := middleware.RateLimitByUser(middleware.RateLimitFromEnv("EMERGENCY_BROADCAST_RATE_LIMIT_PER_HOUR", 5), 1*time.Hour)
+
+	// Per-user request counts for abuse reporting, and the throttles and
+	// suspensions admins set on individual users
+	apiUsage := middleware.NewAPIUsageTracker(db)
+
+	// Failed logins and password reset requests also back off per IP, so
+	// credential stuffing slows down without locking out the accounts it
+	// targets. Set CAPTCHA_VERIFY_URL and CAPTCHA_SECRET to let throttled
+	// clients through with a CAPTCHA instead of waiting.
+	loginThrottle := middleware.NewLoginThrottler(middleware.LoginThrottleConfigFromEnv(), middleware.CaptchaVerifierFromEnv())
 
 	// Public routes (with rate limiting for auth endpoints)
-	authRateLimit := 5
-	if v := os.Getenv("AUTH_RATE_LIMIT_PER_MINUTE"); v != "" {
-		if parsed, err := strconv.Atoi(v); err == nil && parsed > 0 {
-			authRateLimit = parsed
-		}
-	}
-	authLimiter := middleware.RateLimit(authRateLimit, 1*time.Minute)
-	api.POST("/login", authLimiter, handlers.Login(db))
+	api.POST("/login", authLimiter, loginThrottle.Throttle("login", handlers.LoginAttemptFailed), handlers.Login(db, securityConfig))
+	// Clears cookie session cookies; public so an expired session can still log out
+	api.POST("/logout", handlers.Logout())
 	// Registration disabled - invite-only system. Admins can create users via /api/admin/users
-	// api.POST("/register", authLimiter, handlers.Register(db))
-	api.POST("/request-password-reset", authLimiter, handlers.RequestPasswordReset(db, emailService))
+	// api.POST("/register", authLimiter, handlers.Register(db, emailService))
+	api.POST("/request-password-reset", authLimiter, loginThrottle.Throttle("password_reset", handlers.PasswordResetAttempted), handlers.RequestPasswordReset(db, emailService))
 	api.POST("/reset-password", authLimiter, handlers.ResetPassword(db))
 	api.POST("/setup-password", authLimiter, handlers.SetupPassword(db)) // New user password setup (invite flow)
+	api.POST("/verify-email", authLimiter, handlers.VerifyEmail(db))
+	api.POST("/verify-reply-to", authLimiter, handlers.VerifyGroupReplyTo(db))
+
+	// OIDC sign-in (browser redirects, not JSON)
+	api.GET("/auth/oidc/providers", handlers.ListOIDCProviders(oidcProviders, securityConfig))
+	api.GET("/auth/oidc/login", handlers.OIDCLogin(oidcProviders))
+	api.GET("/auth/oidc/callback", authLimiter, handlers.OIDCCallback(db, oidcProviders))
+
+	// Email provider bounce and complaint notifications (signature verified).
+	// Each endpoint is only registered when its provider is configured.
+	if sesWebhook := email.NewSESBounceWebhook(); sesWebhook != nil {
+		api.POST("/webhooks/email/ses", shareLimiter, handlers.SESEmailWebhook(db, sesWebhook))
+	}
+	sendGridWebhook, err := email.NewSendGridBounceWebhook()
+	if err != nil {
+		logger.Fatal("Invalid SendGrid webhook configuration", err)
+	}
+	if sendGridWebhook != nil {
+		api.POST("/webhooks/email/sendgrid", shareLimiter, handlers.SendGridEmailWebhook(db, sendGridWebhook))
+	}
+	if twilioWebhook := sms.NewTwilioStatusWebhook(); twilioWebhook != nil {
+		api.POST("/webhooks/sms/twilio", shareLimiter, handlers.TwilioSMSStatusWebhook(db, twilioWebhook))
+	}
 
 	// Site settings (public read)
 	api.GET("/settings", handlers.GetSiteSettings(db))
 
+	// Group branding (public read, for theming the login and share pages)
+	api.GET("/groups/:id/branding", handlers.GetGroupBranding(db))
+
+	// Public animal share pages (signed link, no auth required)
+	api.GET("/share/:token", shareLimiter, handlers.GetSharedAnimal(db))
+
+	// Announcement email open and click tracking (signed links, no auth
+	// required). Email clients fetch images through shared proxies, so the
+	// per-IP budget is higher than for share pages.
+	emailTrackingLimiter := middleware.RateLimit(middleware.RateLimitFromEnv("EMAIL_TRACKING_RATE_LIMIT_PER_MINUTE", 600), 1*time.Minute)
+	api.GET("/email-tracking/:token/open.gif", emailTrackingLimiter, handlers.TrackAnnouncementEmailOpen(db))
+	api.GET("/email-tracking/:token/click", emailTrackingLimiter, handlers.TrackAnnouncementEmailClick(db))
+
+	// Public animal feeds for embedding on group websites (opt-in per group)
+	router.GET("/public/groups/:slug/animals.json", shareLimiter, handlers.GetPublicAnimalFeed(db, handlers.PublicFeedJSON))
+	router.GET("/public/groups/:slug/animals.rss", shareLimiter, handlers.GetPublicAnimalFeed(db, handlers.PublicFeedRSS))
+
 	// Protected routes
 	protected := api.Group("/")
-	protected.Use(middleware.AuthRequired(db))
+	protected.Use(middleware.AuthRequired(db), middleware.CSRFRequired(), middleware.OrganizationScope(db), apiUsage.Track(), apiLimiter)
 	{
 		// Environment info (authenticated users can check environment)
 		protected.GET("/environment", handlers.GetEnvironment())
 
+		// Read-only GraphQL API for reporting clients
+		protected.POST("/graphql", handlers.GraphQL(db))
+
 		// User routes
 		protected.GET("/me", handlers.GetCurrentUser(db))
 		protected.GET("/users/:id/profile", handlers.GetUserProfile(db))
 		protected.PUT("/me/profile", handlers.UpdateCurrentUserProfile(db))
+		protected.POST("/me/avatar", uploadLimiter, middleware.MaxRequestBodySize(upload.MaxImageRequestBodySize), handlers.UploadCurrentUserAvatar(db, storageProvider, imageConfig))
+		protected.DELETE("/me/avatar", handlers.DeleteCurrentUserAvatar(db, storageProvider))
+		protected.PUT("/me/username", authLimiter, handlers.ChangeCurrentUsername(db))
+		protected.POST("/refresh", handlers.RefreshToken(db))
+		protected.GET("/me/join-requests", handlers.GetMyJoinRequests(db))
+		protected.GET("/me/permissions", handlers.GetMyPermissions(db))
+		protected.GET("/me/export", exportLimiter, handlers.ExportCurrentUserData(db))
+		protected.GET("/exports/:exportId", handlers.GetDataExport(db))
+		protected.GET("/exports/:exportId/download", exportLimiter, handlers.DownloadDataExport(db, storageProvider))
+		protected.POST("/me/deactivate", authLimiter, handlers.DeactivateCurrentUser(db))
 		protected.GET("/email-preferences", handlers.GetEmailPreferences(db))
 		protected.PUT("/email-preferences", handlers.UpdateEmailPreferences(db))
+		protected.GET("/me/sms-preferences", handlers.GetSMSPreferences(db))
+		protected.PUT("/me/sms-preferences", handlers.UpdateSMSPreferences(db))
+		protected.GET("/emergency-broadcasts/:broadcastId", handlers.GetEmergencyBroadcast(db))
+		protected.POST("/resend-verification", authLimiter, handlers.ResendEmailVerification(db, emailService))
 		protected.PUT("/default-group", handlers.SetDefaultGroup(db))
 		protected.GET("/default-group", handlers.GetDefaultGroup(db))
 
-		// Announcement routes (all authenticated users can view)
+		// Organization routes (members read; organization admins manage,
+		// checked in the handlers)
+		protected.GET("/organization", handlers.GetMyOrganization(db))
+		protected.GET("/organization/users", handlers.GetOrganizationUsers(db))
+		protected.PUT("/organization/settings/:key", handlers.UpdateOrganizationSetting(db))
+		protected.DELETE("/organization/settings/:key", handlers.DeleteOrganizationSetting(db))
+
+		// Announcement routes (all authenticated users can view; site admins and
+		// group admins can post, group admins only to groups they administer)
 		protected.GET("/announcements", handlers.GetAnnouncements(db))
+		protected.POST("/announcements", handlers.CreateAnnouncement(db, emailService, groupMeService))
+		protected.POST("/announcements/:id/read", handlers.MarkAnnouncementRead(db))
 
 		// Group routes
 		protected.GET("/groups", handlers.GetGroups(db))
 
+		// Managed breed list and autocomplete
+		protected.GET("/breeds", handlers.GetBreeds(db))
+
 		// Image upload (authenticated users only) - stores in database
-		protected.POST("/animals/upload-image", handlers.UploadAnimalImageSimple(db, storageProvider))
+		protected.POST("/animals/upload-image", uploadLimiter, middleware.MaxRequestBodySize(upload.MaxImageRequestBodySize), handlers.UploadAnimalImageSimple(db, storageProvider, imageConfig, moderator))
 
 		// Document serving route (PROTECTED): requires authentication and group membership
 		protected.GET("/documents/:uuid", handlers.ServeAnimalProtocolDocument(db, storageProvider))
@@ -308,36 +515,109 @@ func main() {
 			admin.PUT("/users/:userId", handlers.AdminUpdateUser(db)) // Admin-specific endpoint (preferred path for admins)
 			admin.DELETE("/users/:userId", handlers.AdminDeleteUser(db))
 			admin.GET("/users/deleted", handlers.GetDeletedUsers(db))
+			admin.GET("/users/activity", handlers.GetUserActivity(db))
+			admin.GET("/users/locked", handlers.GetLockedUsers(db))
+			admin.POST("/users/bulk", handlers.BulkUserAction(db, emailService))
+
+			// API usage and per-user restrictions
+			admin.GET("/api-usage/top", handlers.GetTopAPIConsumers(db, apiUsage))
+			admin.GET("/users/:userId/usage", handlers.GetUserAPIUsage(db, apiUsage))
+			admin.PUT("/users/:userId/api-restriction", handlers.RestrictUserAPIAccess(db, apiUsage))
+			admin.DELETE("/users/:userId/api-restriction", handlers.LiftUserAPIRestriction(db, apiUsage))
+
+			// Background jobs
+			admin.GET("/jobs", handlers.ListJobs(db))
+			admin.POST("/jobs/:id/requeue", handlers.RequeueJob(db))
 			admin.POST("/users/:userId/restore", handlers.RestoreUser(db))
+			admin.POST("/users/:userId/merge", handlers.MergeUsers(db))
+			admin.GET("/user-merges", handlers.GetUserMerges(db))
+			admin.POST("/user-merges/:mergeId/undo", handlers.UndoUserMerge(db))
 			admin.POST("/users/:userId/promote", handlers.PromoteUser(db))
 			admin.POST("/users/:userId/demote", handlers.DemoteUser(db))
+			admin.PUT("/users/:userId/password-login", handlers.SetUserPasswordLogin(db))
+			admin.DELETE("/users/:userId/avatar", handlers.AdminResetUserAvatar(db, storageProvider))
+			admin.DELETE("/users/:userId/email-undeliverable", handlers.ClearEmailUndeliverable(db))
 
 			// Group management (admin only)
 			admin.POST("/groups", handlers.CreateGroup(db))
 			admin.PUT("/groups/:id", handlers.UpdateGroup(db))
 			admin.DELETE("/groups/:id", handlers.DeleteGroup(db))
-			admin.POST("/groups/upload-image", handlers.UploadGroupImage(storageProvider))
+			admin.POST("/groups/upload-image", uploadLimiter, middleware.MaxRequestBodySize(upload.MaxImageRequestBodySize), handlers.UploadGroupImage(storageProvider, imageConfig))
+			admin.PUT("/users/:userId/groups", handlers.SetUserGroups(db))
 			admin.POST("/users/:userId/groups/:groupId", handlers.AddUserToGroup(db))
 			admin.DELETE("/users/:userId/groups/:groupId", handlers.RemoveUserFromGroup(db))
 
 			// Announcement routes (admin only)
 			admin.POST("/announcements", handlers.CreateAnnouncement(db, emailService, groupMeService))
 			admin.DELETE("/announcements/:id", handlers.DeleteAnnouncement(db))
+			admin.GET("/announcements/:id/reads", handlers.GetAnnouncementReads(db))
+			admin.GET("/announcements/:id/stats", handlers.GetAnnouncementEmailStats(db))
+
+			// Emergency SMS broadcasts to every opted-in user (admin only)
+			admin.POST("/emergency-broadcasts", broadcastLimiter, handlers.CreateEmergencyBroadcast(db, smsProvider))
+			admin.GET("/emergency-broadcasts", handlers.GetEmergencyBroadcasts(db))
 
 			// Site settings management (admin only)
-			admin.PUT("/settings/:key", handlers.UpdateSiteSetting(db))
-			admin.POST("/settings/upload-hero-image", handlers.UploadHeroImage(db, storageProvider))
+			admin.PUT("/settings/:key", handlers.UpdateSiteSetting(db, securityConfig, imageConfig))
+			admin.GET("/security-config", handlers.GetSecurityConfig(securityConfig))
+			admin.GET("/login-throttle", handlers.GetLoginThrottleStats(loginThrottle))
+			admin.DELETE("/login-throttle/:ip", handlers.UnblockLoginThrottleIP(loginThrottle))
+			admin.GET("/image-config", handlers.GetImageConfig(imageConfig))
+			admin.GET("/alerts/config", handlers.GetAlertConfig(alerter))
+			admin.POST("/alerts/test", handlers.SendTestAlert(alerter))
+			admin.POST("/settings/upload-hero-image", uploadLimiter, middleware.MaxRequestBodySize(upload.MaxImageRequestBodySize), handlers.UploadHeroImage(db, storageProvider, imageConfig))
+
+			// Site configuration export/import, for copying settings, tags,
+			// statuses, and breeds to another install such as staging
+			admin.GET("/site-config", handlers.ExportSiteConfig(db))
+			admin.POST("/site-config/import", handlers.ImportSiteConfig(db, securityConfig, imageConfig))
+
+			// Email templates - replacing built-in emails, with versions and previews
+			admin.GET("/email-templates", handlers.GetEmailTemplates(db))
+			admin.GET("/email-templates/:type", handlers.GetEmailTemplate(db))
+			admin.PUT("/email-templates/:type", handlers.UpdateEmailTemplate(db))
+			admin.DELETE("/email-templates/:type", handlers.ResetEmailTemplate(db))
+			admin.GET("/email-templates/:type/versions", handlers.GetEmailTemplateVersions(db))
+			admin.POST("/email-templates/:type/versions/:version/restore", handlers.RestoreEmailTemplateVersion(db))
+			admin.POST("/email-templates/:type/preview", handlers.PreviewEmailTemplate(emailService))
+			admin.POST("/email-templates/:type/test", handlers.SendTestEmailTemplate(db, emailService))
+
+			// Managed breed list and migration of free-text breeds
+			admin.POST("/breeds", handlers.CreateBreed(db))
+			admin.PUT("/breeds/:breedId", handlers.UpdateBreed(db))
+			admin.DELETE("/breeds/:breedId", handlers.DeleteBreed(db))
+			admin.GET("/breeds/report", handlers.GetBreedReport(db))
+			admin.POST("/breeds/migrate", handlers.MigrateBreeds(db))
+
+			// Site-wide animal status taxonomy (groups without their own inherit it)
+			admin.GET("/animal-statuses", handlers.GetSiteAnimalStatuses(db))
+			admin.PUT("/animal-statuses", handlers.UpdateSiteAnimalStatuses(db))
 
 			// Bulk animal management (admin only)
 			admin.GET("/animals", handlers.GetAllAnimals(db))
+			admin.GET("/animals/lookup", handlers.LookupAnimals(db))
 			admin.POST("/animals/bulk-update", handlers.BulkUpdateAnimals(db))
-			admin.POST("/animals/import-csv", handlers.ImportAnimalsCSV(db, embedder))
-			admin.POST("/animals/export-csv", handlers.ExportAnimalsCSV(db))
-			admin.GET("/animals/export-comments-csv", handlers.ExportAnimalCommentsCSV(db))
+			admin.POST("/animals/import-csv", uploadLimiter, handlers.ImportAnimalsCSV(db, embedder, storageProvider, imageConfig))
+			admin.POST("/animals/import-comments-csv", uploadLimiter, handlers.ImportAnimalCommentsCSV(db))
+			admin.POST("/animals/export-csv", exportLimiter, handlers.ExportAnimalsCSV(db))
+			admin.GET("/animals/export-comments-csv", exportLimiter, handlers.ExportAnimalCommentsCSV(db))
+			admin.POST("/exports", exportLimiter, handlers.CreateDataExport(db))
 			admin.PUT("/animals/:animalId", handlers.UpdateAnimalAdmin(db, emailService, embedder))
+			admin.PUT("/animals/:animalId/legal-hold", handlers.PlaceLegalHold(db))
+			admin.DELETE("/animals/:animalId/legal-hold", handlers.LiftLegalHold(db))
 
 			// Animal image management (admin only)
 			admin.PUT("/animals/:animalId/images/:imageId/set-profile", handlers.SetAnimalProfilePicture(db))
+			admin.GET("/images/quarantined", handlers.GetQuarantinedImages(db))
+			admin.GET("/images/quarantined/:imageId/content", handlers.GetQuarantinedImageContent(db, storageProvider))
+			admin.POST("/images/quarantined/:imageId/approve", handlers.ApproveQuarantinedImage(db))
+			admin.POST("/images/quarantined/:imageId/reject", handlers.RejectQuarantinedImage(db, storageProvider))
+
+			// Data retention (admin only)
+			admin.GET("/retention", handlers.GetRetentionPolicy(db, storageProvider, retentionPolicy))
+			admin.POST("/retention/run", handlers.RunRetentionPurge(db, storageProvider, retentionPolicy))
+			admin.GET("/orphaned-uploads", handlers.GetOrphanedUploads(db, orphanedUploads))
+			admin.POST("/orphaned-uploads/purge", handlers.PurgeOrphanedUploads(db, orphanedUploads))
 
 			// Database seeding (admin only, dangerous operation)
 			admin.POST("/seed-database", handlers.SeedDatabase(db))
@@ -345,9 +625,12 @@ func main() {
 			// Statistics routes (admin only)
 			admin.GET("/statistics/groups", handlers.GetGroupStatistics(db))
 			admin.GET("/statistics/users", handlers.GetUserStatistics(db))
+			admin.GET("/analytics/animals", handlers.GetAnimalAnalytics(db))
 
 			// Admin dashboard
 			admin.GET("/dashboard/stats", handlers.GetAdminDashboardStats(db))
+			admin.GET("/stats", handlers.GetAdminStats(db))
+			admin.GET("/stats/intake-outcome", handlers.GetIntakeOutcomeStats(db))
 
 			// Admin content moderation - view deleted content
 			admin.GET("/groups/:id/deleted-comments", handlers.GetDeletedComments(db))
@@ -357,6 +640,15 @@ func main() {
 			admin.GET("/api-tokens", handlers.ListMyAPITokens(db))
 			admin.POST("/api-tokens", handlers.CreateAPIToken(db))
 			admin.DELETE("/api-tokens/:tokenId", handlers.RevokeAPIToken(db))
+			// Organizations (multi-tenancy)
+			admin.GET("/organizations", handlers.GetOrganizations(db))
+			admin.POST("/organizations", handlers.CreateOrganization(db))
+			admin.PUT("/organizations/:orgId", handlers.UpdateOrganization(db))
+			admin.DELETE("/organizations/:orgId", handlers.DeleteOrganization(db))
+			admin.PUT("/organizations/:orgId/settings/:key", handlers.UpdateOrganizationSetting(db))
+			admin.DELETE("/organizations/:orgId/settings/:key", handlers.DeleteOrganizationSetting(db))
+			admin.PUT("/groups/:id/organization", handlers.SetGroupOrganization(db))
+			admin.PUT("/users/:userId/organization", handlers.SetUserOrganization(db))
 		}
 
 		// Group-specific routes
@@ -365,38 +657,107 @@ func main() {
 			group.GET("", handlers.GetGroup(db))
 			group.GET("/membership", handlers.GetGroupMembership(db))
 
+			// Join requests - any user can ask; group admins and site admins review
+			group.POST("/join-requests", handlers.CreateJoinRequest(db))
+			group.GET("/join-requests", handlers.GetJoinRequests(db))
+			group.POST("/join-requests/:requestId/approve", handlers.ApproveJoinRequest(db))
+			group.POST("/join-requests/:requestId/deny", handlers.DenyJoinRequest(db))
+
+			// Emergency SMS broadcasts to the group's opted-in members (group admin or site admin)
+			group.POST("/emergency-broadcasts", broadcastLimiter, handlers.CreateGroupEmergencyBroadcast(db, smsProvider))
+			group.GET("/emergency-broadcasts", handlers.GetGroupEmergencyBroadcasts(db))
+
+			// Branding - group admins and site admins (checked in the handlers)
+			group.PUT("/branding", handlers.UpdateGroupBranding(db))
+			group.POST("/branding/logo", uploadLimiter, middleware.MaxRequestBodySize(upload.MaxImageRequestBodySize), handlers.UploadGroupLogo(db, storageProvider, imageConfig))
+			group.DELETE("/branding/logo", handlers.DeleteGroupLogo(db))
+
+			// Animal list defaults and card fields - group admins and site admins (checked in the handler)
+			group.PUT("/display-settings", handlers.UpdateGroupDisplaySettings(db))
+
+			// Email sender identity - group admins and site admins (checked in the handlers)
+			group.GET("/email-sender", handlers.GetGroupEmailSender(db))
+			group.PUT("/email-sender", handlers.UpdateGroupEmailSender(db, emailService))
+			group.POST("/email-sender/resend-verification", authLimiter, handlers.ResendGroupReplyToVerification(db, emailService))
+
+			// Public feed settings - group admins and site admins (checked in the handler)
+			group.PUT("/public-feed", handlers.UpdateGroupPublicFeed(db))
+
 			// Animal routes - viewing accessible to all group members
 			group.GET("/animals", handlers.GetAnimals(db))
 			group.GET("/animals/:animalId", handlers.GetAnimal(db))
 			group.GET("/animals/check-duplicates", handlers.CheckDuplicateNames(db))
+			group.GET("/animals/compare", handlers.CompareAnimals(db))
+			group.GET("/animals/:animalId/checklist", handlers.GetAnimalChecklist(db))
+
+			// Saved animal filters - each member manages their own
+			group.GET("/saved-filters", handlers.GetSavedFilters(db))
+			group.POST("/saved-filters", handlers.CreateSavedFilter(db))
+			group.PUT("/saved-filters/:filterId", handlers.UpdateSavedFilter(db))
+			group.DELETE("/saved-filters/:filterId", handlers.DeleteSavedFilter(db))
 
 			// Hybrid search over animals, comments, and updates: Postgres
 			// full-text keyword ranking, fused via RRF with semantic
 			// (embedding) ranking when SEMANTIC_SEARCH_ENABLED and Voyage
 			// are both configured — see handlers.Search's doc comment.
 			group.GET("/search", handlers.Search(db, embedder))
+			group.GET("/comments/search", handlers.SearchGroupComments(db))
+
+			// Offline sync - comments written offline are sent in batches, and
+			// changes since a cursor are pulled
+			group.POST("/comments/batch", commentLimiter, handlers.CreateAnimalCommentBatch(db, embedder))
+			group.GET("/comments/sync", handlers.SyncAnimalComments(db))
 
 			// Animal images - all group members can view, upload, and set profile pictures
 			group.GET("/animals/:animalId/images", handlers.GetAnimalImages(db))
-			group.POST("/animals/:animalId/images", handlers.UploadAnimalImageToGallery(db, storageProvider))
+			group.POST("/animals/:animalId/images", uploadLimiter, middleware.MaxRequestBodySize(upload.MaxImageRequestBodySize), handlers.UploadAnimalImageToGallery(db, storageProvider, imageConfig, moderator))
 			group.DELETE("/animals/:animalId/images/:imageId", handlers.DeleteAnimalImage(db, storageProvider))
 			// Profile picture selection - available to all group members to help curate animal photos
 			group.PUT("/animals/:animalId/images/:imageId/set-profile", handlers.SetAnimalProfilePictureGroupScoped(db))
 
 			// Animal media and videos - all group members can view, upload videos, and delete videos
 			group.GET("/animals/:animalId/media", handlers.GetAnimalMedia(db))
+			group.GET("/animals/:animalId/timeline", handlers.GetAnimalTimeline(db))
 			group.POST("/animals/:animalId/videos",
+				uploadLimiter,
 				middleware.MaxRequestBodySize(210*1024*1024),
 				handlers.UploadAnimalVideo(db, storageProvider))
 			group.DELETE("/animals/:animalId/videos/:videoId", handlers.DeleteAnimalVideo(db, storageProvider))
 
-			// Animal comments - all group members can view, add, and edit own comments
+			// Weight tracking - any member can record; recorder or group admin can edit/delete
+			group.GET("/animals/:animalId/weights", handlers.GetAnimalWeights(db))
+			group.GET("/animals/:animalId/weights/series", handlers.GetAnimalWeightSeries(db))
+			group.POST("/animals/:animalId/weights", handlers.CreateAnimalWeight(db))
+			group.PUT("/animals/:animalId/weights/:weightId", handlers.UpdateAnimalWeight(db))
+			group.DELETE("/animals/:animalId/weights/:weightId", handlers.DeleteAnimalWeight(db))
+
+			// Animal relationships - any member can view; group admins link and unlink animals
+			group.GET("/animals/:animalId/relationships", handlers.GetAnimalRelationships(db))
+			group.POST("/animals/:animalId/relationships", handlers.CreateAnimalRelationship(db))
+			group.PUT("/animals/:animalId/relationships/:relationshipId", handlers.UpdateAnimalRelationship(db))
+			group.DELETE("/animals/:animalId/relationships/:relationshipId", handlers.DeleteAnimalRelationship(db))
+
+			// Behavior assessments - any member can view; group admins record them
+			group.GET("/animals/:animalId/behavior-assessments", handlers.GetBehaviorAssessments(db))
+			group.POST("/animals/:animalId/behavior-assessments", handlers.CreateBehaviorAssessment(db))
+
+			// Animal comments - all group members can view, add, and edit own comments;
+			// group admins can list and restore deleted ones (checked in the handlers)
 			group.GET("/animals/:animalId/comments", handlers.GetAnimalComments(db))
-			group.POST("/animals/:animalId/comments", handlers.CreateAnimalComment(db, embedder))
-			group.PUT("/animals/:animalId/comments/:commentId", handlers.UpdateAnimalComment(db, embedder))
+			group.POST("/animals/:animalId/comments", commentLimiter, handlers.CreateAnimalComment(db, embedder))
+			group.PUT("/animals/:animalId/comments/:commentId", commentLimiter, handlers.UpdateAnimalComment(db, embedder))
 			group.DELETE("/animals/:animalId/comments/:commentId", handlers.DeleteAnimalComment(db))
+			group.GET("/animals/:animalId/comments/deleted", handlers.GetDeletedComments(db))
+			group.POST("/animals/:animalId/comments/:commentId/restore", handlers.RestoreAnimalComment(db))
+			group.PUT("/animals/:animalId/comments/:commentId/pin", handlers.PinAnimalComment(db))
+			group.DELETE("/animals/:animalId/comments/:commentId/pin", handlers.UnpinAnimalComment(db))
 			group.GET("/animals/:animalId/comments/:commentId/history", handlers.GetCommentHistory(db))
 			group.GET("/animals/:animalId/comments/:commentId/position", handlers.GetAnimalCommentPosition(db))
+			group.POST("/animals/:animalId/comments/:commentId/reactions", handlers.AddCommentReaction(db))
+			group.DELETE("/animals/:animalId/comments/:commentId/reactions/:type", handlers.RemoveCommentReaction(db))
+
+			// Printable kennel card - all group members can print
+			group.GET("/animals/:animalId/kennel-card.pdf", handlers.GetAnimalKennelCard(db, storageProvider))
 
 			// Latest comments across the group
 			group.GET("/latest-comments", handlers.GetGroupLatestComments(db))
@@ -404,14 +765,32 @@ func main() {
 			// Activity feed - unified view of announcements and comments
 			group.GET("/activity-feed", handlers.GetGroupActivityFeed(db))
 
+			// Group statistics (group admin or site admin)
+			group.GET("/stats", handlers.GetGroupStats(db))
+
 			// Updates routes
 			group.GET("/updates", handlers.GetUpdates(db))
 			group.POST("/updates", handlers.CreateUpdate(db, emailService, groupMeService, embedder))
 			group.DELETE("/updates/:updateId", handlers.DeleteUpdate(db))
 
+			// Discussion threads - any member can start or reply; authors
+			// edit their own, and authors or group admins delete
+			group.GET("/discussions", handlers.GetDiscussions(db))
+			group.POST("/discussions", handlers.CreateDiscussion(db))
+			group.GET("/discussions/:discussionId", handlers.GetDiscussion(db))
+			group.PUT("/discussions/:discussionId", handlers.UpdateDiscussion(db))
+			group.DELETE("/discussions/:discussionId", handlers.DeleteDiscussion(db))
+			group.PUT("/discussions/:discussionId/mute", handlers.MuteDiscussion(db))
+			group.POST("/discussions/:discussionId/replies", handlers.CreateDiscussionReply(db))
+			group.PUT("/discussions/:discussionId/replies/:replyId", handlers.UpdateDiscussionReply(db))
+			group.DELETE("/discussions/:discussionId/replies/:replyId", handlers.DeleteDiscussionReply(db))
+
 			// Protocol/Script routes - all group members can view
 			group.GET("/protocols", handlers.GetProtocols(db))
 			group.GET("/protocols/:protocolId", handlers.GetProtocol(db))
+			group.GET("/protocols/:protocolId/versions", handlers.GetProtocolVersions(db))
+			group.POST("/protocols/:protocolId/acknowledge", handlers.AcknowledgeProtocol(db))
+			group.GET("/protocols.pdf", handlers.ExportProtocolsPDF(db, storageProvider))
 			group.GET("/scripts", handlers.GetScripts(db))
 			group.GET("/scripts/:scriptId", handlers.GetScript(db))
 			group.GET("/documents", handlers.GetGroupDocuments(db))
@@ -423,7 +802,44 @@ func main() {
 			group.PUT("/animal-tags/:tagId", handlers.UpdateAnimalTag(db))
 			group.DELETE("/animal-tags/:tagId", handlers.DeleteAnimalTag(db))
 
+			// Animal status taxonomy - viewing for group members, replacing for group admins
+			group.GET("/animal-statuses", handlers.GetAnimalStatuses(db))
+			group.PUT("/animal-statuses", handlers.UpdateGroupAnimalStatuses(db))
+			group.GET("/status-alerts", handlers.GetStatusAlertRules(db))
+			group.PUT("/status-alerts", handlers.UpdateStatusAlertRules(db))
+
+			// Animal custom field definitions - viewing for group members, replacing for group admins
+			group.GET("/animal-fields", handlers.GetAnimalCustomFields(db))
+			group.PUT("/animal-fields", handlers.UpdateGroupAnimalCustomFields(db))
+
+			// New animal form - required fields; viewing for group members, replacing for group admins
+			group.GET("/animal-form", handlers.GetAnimalForm(db))
+			group.PUT("/animal-form", handlers.UpdateAnimalForm(db))
+
+			// Field visibility by group role - viewing for group members, replacing for group admins
+			group.GET("/field-visibility", handlers.GetFieldVisibility(db))
+			group.PUT("/field-visibility", handlers.UpdateFieldVisibility(db))
+
+			// Status checklists - viewing for group members, replacing for group admins
+			group.GET("/status-checklists", handlers.GetStatusChecklists(db))
+			group.PUT("/status-checklists", handlers.UpdateStatusChecklists(db))
+
+			// Onboarding checklists - items managed by group admins, completed by new members
+			group.GET("/onboarding", handlers.GetMyOnboarding(db))
+			group.GET("/onboarding/report", handlers.GetOnboardingReport(db))
+			group.GET("/onboarding/items", handlers.GetOnboardingItems(db))
+			group.POST("/onboarding/items", handlers.CreateOnboardingItem(db))
+			group.PUT("/onboarding/items/:itemId", handlers.UpdateOnboardingItem(db))
+			group.DELETE("/onboarding/items/:itemId", handlers.DeleteOnboardingItem(db))
+			group.POST("/onboarding/items/:itemId/complete", handlers.CompleteOnboardingItem(db))
+			group.DELETE("/onboarding/items/:itemId/complete", handlers.UncompleteOnboardingItem(db))
+
+			// Kennel card template - viewing for group members, replacing for group admins
+			group.GET("/kennel-card-template", handlers.GetKennelCardTemplate(db))
+			group.PUT("/kennel-card-template", handlers.UpdateKennelCardTemplate(db))
+
 			group.GET("/comment-tags", handlers.GetCommentTags(db))
+			group.GET("/comment-tags/volume", handlers.GetCommentTagVolume(db))
 			group.POST("/comment-tags", handlers.CreateCommentTag(db))
 			group.DELETE("/comment-tags/:tagId", handlers.DeleteCommentTag(db))
 
@@ -435,15 +851,27 @@ func main() {
 			group.DELETE("/user-skill-tags/:tagId", handlers.DeleteUserSkillTag(db))
 			group.PUT("/members/:userId/skill-tags", handlers.AssignUserSkillTags(db))
 
+			// Qualifications for restricted animal tags
+			group.GET("/qualifications", handlers.GetUserQualifications(db))
+			group.PUT("/members/:userId/qualifications", handlers.AssignUserQualifications(db))
+
+			// Foster availability - members keep their own; group admins match fosters to animals
+			group.GET("/foster-profile", handlers.GetMyFosterProfile(db))
+			group.PUT("/foster-profile", handlers.UpdateMyFosterProfile(db))
+			group.GET("/fosters", handlers.GetFosterMatches(db))
+
 			// Group settings - group admin or site admin can update
 			group.PUT("/settings", handlers.UpdateGroupSettings(db))
 
 			// Member management - group admin or site admin (checks access within handlers)
 			group.GET("/members", handlers.GetGroupMembers(db))
+			group.GET("/members/activity", handlers.GetGroupMemberActivity(db))
+			group.GET("/weekly-stats", handlers.GetGroupWeeklyStats(db))
 			group.POST("/members/:userId", handlers.AddMemberToGroup(db))
 			group.DELETE("/members/:userId", handlers.RemoveMemberFromGroup(db))
 			group.POST("/members/:userId/promote", handlers.PromoteMemberToGroupAdmin(db))
 			group.POST("/members/:userId/demote", handlers.DemoteMemberFromGroupAdmin(db))
+			group.PUT("/members/:userId/role", handlers.SetMemberRole(db))
 
 			// Content moderation - group admin or site admin can view deleted content
 			group.GET("/deleted-comments", handlers.GetDeletedComments(db))
@@ -460,30 +888,44 @@ func main() {
 			groupAdminAnimals.POST("", handlers.CreateAnimal(db, emailService, embedder))
 			groupAdminAnimals.PUT("/:animalId", handlers.UpdateAnimal(db, emailService, embedder))
 			groupAdminAnimals.DELETE("/:animalId", handlers.DeleteAnimal(db))
+			// Fold a duplicate record into this animal
+			groupAdminAnimals.POST("/:animalId/merge", handlers.MergeAnimals(db))
 			// Tag assignment for animals
 			groupAdminAnimals.POST("/:animalId/tags", handlers.AssignTagsToAnimal(db))
 			// Protocol document management
-			groupAdminAnimals.POST("/:animalId/protocol-document", handlers.UploadAnimalProtocolDocument(db, storageProvider))
+			groupAdminAnimals.POST("/:animalId/protocol-document", uploadLimiter, handlers.UploadAnimalProtocolDocument(db, storageProvider))
 			groupAdminAnimals.DELETE("/:animalId/protocol-document", handlers.DeleteAnimalProtocolDocument(db, storageProvider))
 			// Animal script link management
 			groupAdminAnimals.PUT("/:animalId/scripts", handlers.SetAnimalScripts(db))
+			// Public share links and kennel card QR codes
+			groupAdminAnimals.GET("/:animalId/share-links", handlers.GetAnimalShareLinks(db))
+			groupAdminAnimals.POST("/:animalId/share-links", handlers.CreateAnimalShareLink(db))
+			groupAdminAnimals.DELETE("/:animalId/share-links/:linkId", handlers.RevokeAnimalShareLink(db))
+			groupAdminAnimals.GET("/:animalId/share-links/:linkId/qr.png", handlers.GetAnimalShareLinkQRCode(db))
+			// Comment export scoped to the group
+			groupAdminAnimals.GET("/export-comments-csv", exportLimiter, handlers.ExportGroupAnimalCommentsCSV(db))
+			groupAdminAnimals.POST("/exports", exportLimiter, handlers.CreateGroupDataExport(db))
 		}
 
 		// Group admin or site admin protocol management routes
 		// These routes check for site admin OR group admin access within the handlers
 		groupAdminProtocols := protected.Group("/groups/:id/protocols")
 		{
-			groupAdminProtocols.POST("/upload-image", handlers.UploadProtocolImage(db, storageProvider))
+			groupAdminProtocols.POST("/upload-image", uploadLimiter, middleware.MaxRequestBodySize(upload.MaxImageRequestBodySize), handlers.UploadProtocolImage(db, storageProvider, imageConfig))
 			groupAdminProtocols.POST("", handlers.CreateProtocol(db))
+			groupAdminProtocols.GET("/acknowledgments", handlers.GetProtocolAcknowledgments(db))
 			groupAdminProtocols.PUT("/:protocolId", handlers.UpdateProtocol(db))
 			groupAdminProtocols.DELETE("/:protocolId", handlers.DeleteProtocol(db))
+			groupAdminProtocols.POST("/:protocolId/move", handlers.MoveProtocol(db))
+			groupAdminProtocols.POST("/:protocolId/attachments", uploadLimiter, middleware.MaxRequestBodySize(25*1024*1024), handlers.UploadProtocolAttachment(db, storageProvider, imageConfig))
+			groupAdminProtocols.DELETE("/:protocolId/attachments/:attachmentId", handlers.DeleteProtocolAttachment(db, storageProvider))
 		}
 
 		// Group admin or site admin script management routes
 		groupAdminScripts := protected.Group("/groups/:id/scripts")
 		{
-			groupAdminScripts.POST("", handlers.CreateScript(db, storageProvider))
-			groupAdminScripts.PUT("/:scriptId", handlers.UpdateScript(db, storageProvider))
+			groupAdminScripts.POST("", uploadLimiter, handlers.CreateScript(db, storageProvider))
+			groupAdminScripts.PUT("/:scriptId", uploadLimiter, handlers.UpdateScript(db, storageProvider))
 			groupAdminScripts.DELETE("/:scriptId", handlers.DeleteScript(db, storageProvider))
 		}
 
@@ -491,13 +933,16 @@ func main() {
 		groupAdminDocuments := protected.Group("/groups/:id/documents")
 		{
 			// Document uploads can be up to 20 MB; raise the body limit for this route only.
-			groupAdminDocuments.POST("", middleware.MaxRequestBodySize(25*1024*1024), handlers.UploadGroupDocument(db, storageProvider, converter))
+			groupAdminDocuments.POST("", uploadLimiter, middleware.MaxRequestBodySize(25*1024*1024), handlers.UploadGroupDocument(db, storageProvider, converter))
 			groupAdminDocuments.DELETE("/:docId", handlers.DeleteGroupDocument(db, storageProvider))
 		}
 
 		// Group document file serving (authenticated, group membership checked inside handler)
 		protected.GET("/group-documents/:uuid", handlers.ServeGroupDocument(db, storageProvider))
 
+		// Protocol attachment serving (authenticated, group membership checked inside handler)
+		protected.GET("/protocol-attachments/:uuid", handlers.ServeProtocolAttachment(db, storageProvider))
+
 		// Script file serving (authenticated, group membership checked inside handler)
 		protected.GET("/script-files/:uuid", handlers.ServeScriptFile(db, storageProvider))
 
@@ -507,6 +952,36 @@ func main() {
 		protected.POST("/bulk-animals/bulk-update", handlers.BulkUpdateAnimals(db))
 	}
 
+	// SCIM 2.0 provisioning for identity providers (Okta, Entra ID). Only
+	// registered when SCIM_BEARER_TOKEN is set; rate limited per IP ahead of
+	// the token check.
+	scimToken, err := middleware.SCIMTokenFromEnv()
+	if err != nil {
+		logger.Fatal("Invalid SCIM configuration", err)
+	}
+	if scimToken != "" {
+		scimLimiter := middleware.RateLimit(middleware.RateLimitFromEnv("SCIM_RATE_LIMIT_PER_MINUTE", 600), 1*time.Minute)
+		scim := router.Group("/scim/v2", scimLimiter, middleware.SCIMAuthRequired(scimToken))
+		{
+			scim.GET("/ServiceProviderConfig", handlers.GetSCIMServiceProviderConfig())
+			scim.GET("/Users", handlers.ListSCIMUsers(db))
+			scim.POST("/Users", handlers.CreateSCIMUser(db, emailService))
+			scim.GET("/Users/:id", handlers.GetSCIMUser(db))
+			scim.PUT("/Users/:id", handlers.ReplaceSCIMUser(db))
+			scim.PATCH("/Users/:id", handlers.PatchSCIMUser(db))
+			scim.DELETE("/Users/:id", handlers.DeleteSCIMUser(db))
+			scim.GET("/Groups", handlers.ListSCIMGroups(db))
+			scim.POST("/Groups", handlers.CreateSCIMGroup(db))
+			scim.GET("/Groups/:id", handlers.GetSCIMGroup(db))
+			scim.PUT("/Groups/:id", handlers.ReplaceSCIMGroup(db))
+			scim.PATCH("/Groups/:id", handlers.PatchSCIMGroup(db))
+			scim.DELETE("/Groups/:id", handlers.DeleteSCIMGroup())
+		}
+		logger.Info("SCIM provisioning enabled at /scim/v2")
+	} else {
+		logger.Info("SCIM provisioning not configured - set SCIM_BEARER_TOKEN to enable it")
+	}
+
 	// Serve frontend from embedded filesystem (guarantees correct MIME types)
 	router.GET("/", func(c *gin.Context) {
 		c.Data(http.StatusOK, "text/html; charset=utf-8", indexBytes)
@@ -538,7 +1013,7 @@ func main() {
 	// the LibreOffice conversion path.
 	srv := &http.Server{
 		Addr:              ":" + port,
-		Handler:           router,
+		Handler:           middleware.VersionedAPI(router, middleware.APIVersions...),
 		ReadHeaderTimeout: 30 * time.Second,
 		WriteTimeout:      120 * time.Second,
 		IdleTimeout:       60 * time.Second,
@@ -574,6 +1049,17 @@ func main() {
 	}
 
 	stopEmbeddingSweep()
+	stopAnnouncementScheduler()
+	stopWeeklyStatsScheduler()
+	stopStatusAlertScheduler()
+	stopOnboardingScheduler()
+	stopAccountPurge()
+	stopCommentPurge()
+	stopExportPurge()
+	stopRetentionPurge()
+	stopOrphanedUploadSweep()
+	stopJobWorkers()
+	stopJobBacklogCheck()
 
 	// srv.Shutdown only waits for in-flight HTTP handlers, not the detached
 	// write-path embed goroutines those handlers spawn (see embedAsync in
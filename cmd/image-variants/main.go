@@ -0,0 +1,76 @@
+// Command image-variants generates the thumbnail and card-size copies of
+// animal images uploaded before variants were generated on upload. It is
+// safe to rerun: images that already have their variants are skipped.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/joho/godotenv"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/database"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/maintenance"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/upload"
+)
+
+func main() {
+	envFile := flag.String("env-file", ".env", "environment file to load, e.g. .env.staging to target another database")
+	batchSize := flag.Int("batch-size", 50, "images to load at a time")
+	limit := flag.Int("limit", 0, "stop after this many images (0 for all)")
+	dryRun := flag.Bool("dry-run", false, "only count the images that need variants")
+	flag.Parse()
+
+	logging.InitFromEnv()
+	logger := logging.GetDefaultLogger()
+
+	if err := godotenv.Load(*envFile); err != nil {
+		logger.Info("No " + *envFile + " file found, using system environment variables")
+	}
+
+	db, err := database.Initialize()
+	if err != nil {
+		logger.Fatal("Failed to initialize database", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		logger.Fatal("Failed to get database instance", err)
+	}
+	defer func() {
+		if err := sqlDB.Close(); err != nil {
+			logger.Error("Error closing database", err)
+		}
+	}()
+
+	// Makes sure the variants table exists when this runs before the new
+	// server version has started
+	if err := database.RunMigrations(db); err != nil {
+		logger.Fatal("Failed to run migrations", err)
+	}
+
+	storageProvider, err := storage.NewProvider(storage.LoadConfig(), db)
+	if err != nil {
+		logger.Fatal("Failed to initialize storage provider", err)
+	}
+
+	// Ctrl-C stops after the image being processed; rerun to continue
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	cfg := upload.NewImageConfigStore(db).Get(ctx)
+	result, err := maintenance.BackfillImageVariants(ctx, db, storageProvider, cfg, *batchSize, *limit, *dryRun)
+	if *dryRun {
+		fmt.Printf("%d images need variants\n", result.ImagesChecked)
+	} else {
+		fmt.Printf("Checked %d images: created %d variants, %d images failed (see the log)\n",
+			result.ImagesChecked, result.VariantsCreated, result.Failed)
+	}
+	if err != nil {
+		logger.Error("Image variant backfill stopped early", err)
+		os.Exit(1)
+	}
+}
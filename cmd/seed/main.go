@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
@@ -10,13 +11,45 @@ import (
 )
 
 func main() {
+	force := flag.Bool("force", false, "seed demo data even if users already exist (deletes existing demo data)")
+	envFile := flag.String("env-file", ".env", "environment file to load, e.g. .env.staging to target another database")
+	allowProduction := flag.Bool("allow-production", false, "allow seeding when ENV=production")
+	var synthetic database.SyntheticOptions
+	flag.IntVar(&synthetic.Groups, "groups", 5, "synthetic mode: number of groups")
+	flag.IntVar(&synthetic.AnimalsPerGroup, "animals-per-group", 20, "synthetic mode: animals per group")
+	flag.IntVar(&synthetic.Users, "users", 50, "synthetic mode: number of volunteers")
+	flag.BoolVar(&synthetic.WithComments, "with-comments", false, "synthetic mode: generate comments on animals")
+	flag.Int64Var(&synthetic.Seed, "seed", 1, "synthetic mode: random seed; the same seed and sizes give the same data")
+	flag.StringVar(&synthetic.Only, "only", "", "synthetic mode: reseed only groups, users, animals, or comments (and what depends on it)")
+	flag.Parse()
+
+	// Any dataset flag switches from the fixed demo data to a generated dataset
+	syntheticMode := false
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "groups", "animals-per-group", "users", "with-comments", "seed", "only":
+			syntheticMode = true
+		}
+	})
+
 	// Initialize logging
 	logging.InitFromEnv()
 	logger := logging.GetDefaultLogger()
 
 	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		logger.Info("No .env file found, using system environment variables")
+	if err := godotenv.Load(*envFile); err != nil {
+		logger.Info("No " + *envFile + " file found, using system environment variables")
+	}
+
+	if os.Getenv("ENV") == "production" && !*allowProduction {
+		fmt.Fprintln(os.Stderr, "Refusing to seed a production database (ENV=production); pass --allow-production to override")
+		os.Exit(1)
+	}
+	if syntheticMode {
+		if err := synthetic.Validate(); err != nil {
+			fmt.Fprintln(os.Stderr, "Invalid flags:", err)
+			os.Exit(2)
+		}
 	}
 
 	logger.Info("Starting database seed process...")
@@ -43,15 +76,24 @@ func main() {
 		logger.Fatal("Failed to run migrations", err)
 	}
 
-	// Check if force flag is provided
-	force := false
-	if len(os.Args) > 1 && os.Args[1] == "--force" {
-		force = true
+	if syntheticMode {
+		result, err := database.SeedSynthetic(db, synthetic)
+		if err != nil {
+			logger.Fatal("Failed to seed synthetic dataset", err)
+		}
+		fmt.Println("\n✅ Synthetic dataset seeded successfully!")
+		fmt.Printf("  Groups: %d  Users: %d  Memberships: %d  Animals: %d  Comments: %d\n",
+			result.Groups, result.Users, result.Memberships, result.Animals, result.Comments)
+		fmt.Printf("  Log in as synthetic_user_00001 (password: %s) or any other synthetic user.\n", database.SyntheticUserPassword)
+		return
+	}
+
+	if *force {
 		logger.Info("Force flag detected - will seed data even if users exist")
 	}
 
 	// Seed data
-	if err := database.SeedData(db, force); err != nil {
+	if err := database.SeedData(db, *force); err != nil {
 		logger.Fatal("Failed to seed database", err)
 	}
 
@@ -69,6 +69,7 @@ async function main() {
     const env = {
       ...process.env,
       AUTH_RATE_LIMIT_PER_MINUTE: process.env.AUTH_RATE_LIMIT_PER_MINUTE ?? '1000',
+      LOGIN_THROTTLE_FREE_ATTEMPTS: process.env.LOGIN_THROTTLE_FREE_ATTEMPTS ?? '1000',
     };
 
     backendProcess = spawnChild('go', ['run', 'cmd/api/main.go'], repoRoot, env);
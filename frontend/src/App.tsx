@@ -26,6 +26,7 @@ import Settings from './pages/Settings';
 import UserProfilePage from './pages/UserProfilePage';
 import AdminDashboard from './pages/AdminDashboard';
 import AdminApiTokensPage from './pages/AdminApiTokensPage';
+import SharedAnimalPage from './pages/SharedAnimalPage';
 import './App.css';
 
 const PrivateRoute: React.FC<{ children: React.ReactNode }> = ({ children }) => {
@@ -143,6 +144,7 @@ function App() {
               </PublicRoute>
             }
           />
+          <Route path="/share/:token" element={<SharedAnimalPage />} />
           <Route
             path="/dashboard"
             element={
@@ -11,13 +11,65 @@ export const usersApi = {
   delete: (userId: number) => api.delete(`/admin/users/${userId}`),
   assignGroup: (userId: number, groupId: number) => api.post(`/admin/users/${userId}/groups/${groupId}`),
   removeGroup: (userId: number, groupId: number) => api.delete(`/admin/users/${userId}/groups/${groupId}`),
+  // Replaces all of a user's memberships; omit is_group_admin to keep a member's current rights
+  setGroups: (userId: number, groups: { group_id: number; is_group_admin?: boolean }[]) =>
+    api.put<UserGroupsResult>(`/admin/users/${userId}/groups`, { groups }),
   getDeleted: () => api.get<User[]>('/admin/users/deleted'),
+  getLocked: () => api.get<User[]>('/admin/users/locked'),
   restore: (userId: number) => api.post(`/admin/users/${userId}/restore`),
   resetPassword: (userId: number, newPassword: string) => api.post(`/users/${userId}/reset-password`, { new_password: newPassword }),
   resendInvitation: (userId: number) => api.post(`/users/${userId}/resend-invitation`),
   unlock: (userId: number) => api.post<{ message: string; user: User }>(`/users/${userId}/unlock`),
+  // With password login disabled, the user must sign in through an OIDC provider
+  setPasswordLogin: (userId: number, disabled: boolean) =>
+    api.put<{ user_id: number; password_login_disabled: boolean; linked_providers: string[] }>(
+      `/admin/users/${userId}/password-login`, { disabled }),
+  resetAvatar: (userId: number) => api.delete<UserAvatar>(`/admin/users/${userId}/avatar`),
+  // Lifts email suppression after the provider reported the address as bouncing
+  clearEmailUndeliverable: (userId: number) => api.delete<User>(`/admin/users/${userId}/email-undeliverable`),
+  getActivity: (params?: UserActivityParams) =>
+    api.get<PaginatedResponse<UserActivity>>('/admin/users/activity', { params }),
+  // All or nothing: a 422 response carries the results and nothing was changed.
+  // A dry run returns the results without applying them.
+  bulk: (data: { action: BulkUserActionType; user_ids: number[]; group_id?: number }, dryRun = false) =>
+    api.post<BulkUserActionResponse>('/admin/users/bulk', data, { params: dryRun ? { dry_run: true } : undefined }),
+  // Folds duplicateUserId into userId; undoable until the merge's undo_deadline
+  merge: (userId: number, duplicateUserId: number) =>
+    api.post<UserMergeResult>(`/admin/users/${userId}/merge`, { duplicate_user_id: duplicateUserId }),
+  getMerges: () => api.get<UserMerge[]>('/admin/user-merges'),
+  undoMerge: (mergeId: number) => api.post<UserMerge>(`/admin/user-merges/${mergeId}/undo`),
 };
 
+export interface UserMerge {
+  id: number;
+  created_at: string;
+  target_user_id: number;
+  source_user_id: number;
+  merged_by_id: number;
+  undo_deadline: string;
+  undone_at?: string;
+  undone_by_id?: number;
+}
+
+// moved and dropped count records by kind, e.g. comments, reactions, groups
+export interface UserMergeResult {
+  merge: UserMerge;
+  moved: Record<string, number>;
+  dropped: Record<string, number>;
+}
+
+export type BulkUserActionType = 'delete' | 'restore' | 'add-to-group' | 'remove-from-group' | 'force-password-reset';
+
+export interface BulkUserActionResponse {
+  action: BulkUserActionType;
+  applied: boolean;
+  dry_run: boolean;
+  succeeded: number;
+  skipped: number;
+  failed: number;
+  results: { user_id: number; username?: string; status: 'ok' | 'skipped' | 'failed'; message?: string }[];
+}
+
 // API Tokens (admin, self-service — each admin manages only their own)
 export const apiTokensApi = {
   list: () => api.get<ApiToken[]>('/admin/api-tokens'),
@@ -26,6 +78,83 @@ export const apiTokensApi = {
   revoke: (tokenId: number) => api.delete(`/admin/api-tokens/${tokenId}`),
 };
 
+// Per-IP login throttling (admin). Counts are for the replica that answers.
+export interface ThrottledIP {
+  ip: string;
+  endpoint: 'login' | 'password_reset';
+  failures: number;
+  last_failure: string;
+  blocked_until?: string;
+}
+
+export interface LoginThrottleStats {
+  since: string;
+  captcha_enabled: boolean;
+  blocked_total: number;
+  blocked_by_endpoint: Record<string, number>;
+  captcha_challenges: number;
+  captcha_passed: number;
+  throttled_ips: ThrottledIP[];
+}
+
+export const loginThrottleApi = {
+  getStats: () => api.get<LoginThrottleStats>('/admin/login-throttle'),
+  unblock: (ip: string) => api.delete(`/admin/login-throttle/${encodeURIComponent(ip)}`),
+};
+
+// Per-user API usage and restrictions (admin). Counts are for the replica that answers.
+export interface APIUsage {
+  user_id: number;
+  requests_this_minute: number;
+  requests_last_hour: number;
+  rejected_last_hour: number;
+  peak_per_minute: number;
+  total_requests: number;
+  total_rejected: number;
+  first_seen: string;
+  last_seen: string;
+  last_ip: string;
+  minutes?: { minute: string; requests: number; rejected: number }[];
+  top_routes?: { route: string; requests: number }[];
+}
+
+export interface APIAccessRestriction {
+  id: number;
+  created_at: string;
+  updated_at: string;
+  user_id: number;
+  mode: 'throttle' | 'suspend';
+  requests_per_minute: number;
+  reason: string;
+  expires_at: string | null;
+  created_by_id: number;
+}
+
+export interface UserAPIUsage {
+  user_id: number;
+  username: string;
+  email: string;
+  since: string;
+  usage: APIUsage | null;
+  restriction: APIAccessRestriction | null;
+}
+
+export interface APIConsumer extends APIUsage {
+  username: string;
+  email: string;
+  restriction_mode?: 'throttle' | 'suspend';
+}
+
+export const apiUsageApi = {
+  getTop: (limit?: number) =>
+    api.get<{ since: string; consumers: APIConsumer[] }>('/admin/api-usage/top', { params: { limit } }),
+  getUser: (userId: number) => api.get<UserAPIUsage>(`/admin/users/${userId}/usage`),
+  // Leave duration_minutes out to restrict until lifted
+  restrict: (userId: number, data: { mode: 'throttle' | 'suspend'; requests_per_minute?: number; duration_minutes?: number; reason?: string }) =>
+    api.put<APIAccessRestriction>(`/admin/users/${userId}/api-restriction`, data),
+  lift: (userId: number) => api.delete(`/admin/users/${userId}/api-restriction`),
+};
+
 // Group Admin API (accessible by site admins and group admins)
 export const groupAdminApi = {
   // Promote a user to group admin (site admins and group admins can do this for their groups)
@@ -48,25 +177,47 @@ export const groupAdminApi = {
 import axios from 'axios';
 
 const api = axios.create({
-  baseURL: '/api',
+  baseURL: '/api/v1',
+  withCredentials: true, // Sends the session cookie when the server uses cookie sessions
 });
 
-// Add token to requests if available
+// The CSRF token of a cookie session. Login, refresh, and username changes
+// return a new one; after a reload it's read back from its cookie.
+let csrfToken: string | null = null;
+
+const readCookie = (name: string) =>
+  document.cookie.split('; ').find((c) => c.startsWith(name + '='))?.slice(name.length + 1) ?? null;
+
+// Add token to requests if available, and the CSRF token to writes
 api.interceptors.request.use((config) => {
   const token = localStorage.getItem('token');
   if (token) {
     config.headers.Authorization = 'Bearer ' + token;
   }
+  const method = (config.method || 'get').toUpperCase();
+  if (!['GET', 'HEAD', 'OPTIONS'].includes(method)) {
+    const csrf = csrfToken ?? readCookie('csrf_token');
+    if (csrf) {
+      config.headers['X-CSRF-Token'] = csrf;
+    }
+  }
   return config;
 });
 
-// Handle 401 responses (expired/invalid token)
+// Keep new CSRF tokens, and handle 401 responses (expired/invalid token)
 api.interceptors.response.use(
-  (response) => response,
+  (response) => {
+    if (typeof response.data?.csrf_token === 'string') {
+      csrfToken = response.data.csrf_token;
+    }
+    return response;
+  },
   (error) => {
     if (error.response?.status === 401) {
       // Clear invalid token
       localStorage.removeItem('token');
+      localStorage.removeItem('session');
+      csrfToken = null;
       
       // Redirect to login if not already there
       if (window.location.pathname !== '/login') {
@@ -90,6 +241,27 @@ export interface PaginatedResponse<T> {
   hasMore: boolean;
 }
 
+export interface UserActivity {
+  user_id: number;
+  username: string;
+  first_name: string;
+  last_name: string;
+  email: string;
+  created_at: string;
+  last_login: string | null;
+  last_comment_at: string | null;
+  comment_count: number;
+  inactive_days: number | null;
+}
+
+export interface UserActivityParams {
+  sort?: 'username' | 'created_at' | 'last_login' | 'last_comment_at' | 'comment_count';
+  order?: 'asc' | 'desc';
+  inactive_days?: number;
+  limit?: number;
+  offset?: number;
+}
+
 export interface User {
   id: number;
   username: string;
@@ -106,9 +278,44 @@ export interface User {
   deleted_at?: string | null;
   requires_password_setup?: boolean; // True if user hasn't completed initial password setup
   last_login?: string;
+  password_login_disabled?: boolean; // True if the user must sign in through an OIDC provider
+  avatar_url?: string; // Empty when the user has no avatar; show initials instead
+  avatar_thumbnail_url?: string;
+  sms_opt_in?: boolean; // Receives emergency broadcast texts at phone_number
+  time_zone?: string; // IANA name; empty uses the group's or site's time zone
+  organization_id?: number | null; // null outside any organization
+  is_org_admin?: boolean; // Administers every group in organization_id
   // Lockout fields — only present in admin-scoped responses
   locked_until?: string | null;
   failed_login_attempts?: number;
+  lockout_count?: number;
+  // Set when the email provider reported a hard bounce or spam complaint — admin-scoped responses only
+  email_undeliverable?: boolean;
+  email_undeliverable_reason?: 'bounce' | 'complaint';
+  email_undeliverable_detail?: string;
+  email_undeliverable_at?: string;
+}
+
+// Body of a 428 response from a destructive endpoint that needs confirming
+export interface DeleteConfirmation<Impact = Record<string, unknown>> {
+  error: string;
+  code: 'CONFIRMATION_REQUIRED';
+  confirmation_token: string;
+  expires_at: string;
+  impact: Impact;
+}
+
+export interface GroupDeletionImpact {
+  group_name: string;
+  member_count: number;
+  animal_count: number;
+  protocol_count: number;
+  update_count: number;
+}
+
+export interface UserAvatar {
+  avatar_url: string;
+  avatar_thumbnail_url: string;
 }
 
 export interface ApiToken {
@@ -132,6 +339,24 @@ export type CreateUserResponse =
   | User  // Direct user object when password provided
   | { user: User; message?: string; warning?: string };  // Wrapped response with setup email
 
+export type JoinRequestStatus = 'pending' | 'approved' | 'denied';
+
+// GroupJoinRequest is a user's request to join a group, reviewed by its admins
+export interface GroupJoinRequest {
+  id: number;
+  group_id: number;
+  user_id: number;
+  message: string;
+  status: JoinRequestStatus;
+  reviewed_by_id?: number;
+  reviewed_at?: string;
+  deny_reason?: string;  // Shown to the requester when denied
+  created_at: string;
+  updated_at: string;
+  user?: User;  // Included when group admins list requests
+  group?: Pick<Group, 'id' | 'name'>;  // Included in the user's own list
+}
+
 // GroupMember represents a user's membership in a group with admin status
 export interface GroupMember {
   user_id: number;
@@ -140,13 +365,29 @@ export interface GroupMember {
   last_name?: string;
   email: string;
   phone_number?: string;
+  avatar_url: string;
+  avatar_thumbnail_url: string;
   is_group_admin: boolean;
   is_site_admin: boolean;
+  role: GroupRole;
   skill_tags: UserSkillTag[];
   last_login?: string;
   requires_password_setup?: boolean;
 }
 
+export interface GroupWeeklyStats {
+  group_id: number;
+  group_name: string;
+  week_start: string;
+  week_end: string; // Exclusive: the following Monday
+  new_animals: number;
+  adoptions: number;
+  comments: number;
+  top_volunteers: Array<{ user_id: number; username: string; first_name: string; last_name: string; comment_count: number }>;
+  // Animals in care with no comments during the week
+  inactive_animals: Array<{ id: number; name: string; status: string; last_comment_at: string | null }>;
+}
+
 export interface UserSkillTag {
   id: number;
   group_id: number;
@@ -161,9 +402,94 @@ export interface Group {
   description: string;
   image_url: string;
   hero_image_url: string;
+  image_variants?: ImageVariantURLs; // Set for uploaded images and external images the image proxy serves
+  hero_image_variants?: ImageVariantURLs;
   has_protocols: boolean;
+  organization_id?: number | null; // The owning organization; null outside any organization
   groupme_bot_id?: string; // Only present in admin responses; hidden from regular group members
   groupme_enabled: boolean;
+  public_sharing: boolean;
+  accent_color?: string;
+  logo_url?: string;
+  welcome_text?: string;
+  public_feed?: boolean;
+  slug?: string;
+  // Display settings; empty values mean the app's defaults
+  default_status_filter?: string;
+  default_sort?: AnimalSort | '';
+  default_sort_order?: 'asc' | 'desc' | '';
+  card_fields?: string[] | null;
+  time_zone?: string; // IANA name; empty uses the site's time zone
+  unique_animal_names?: boolean; // Active animals can't share a name
+  adopt_bonded_together?: boolean; // Bonded pairs can only be adopted in one bulk update
+}
+
+export interface GroupDisplaySettings {
+  group_id: number;
+  default_status_filter: string;
+  default_sort: AnimalSort | '';
+  default_sort_order: 'asc' | 'desc' | '';
+  card_fields: string[];
+  time_zone: string;
+  unique_animal_names: boolean;
+  adopt_bonded_together: boolean;
+}
+
+// GroupEmailSender is the display name and reply-to address of a group's
+// notification emails. The reply-to address is used once verified.
+export interface GroupEmailSender {
+  group_id: number;
+  sender_name: string;
+  reply_to: string;
+  reply_to_verified: boolean;
+  reply_to_verified_at: string | null;
+  verification_sent?: boolean;
+}
+
+// StatusAlertRule flags a group's animals that have been in a status for
+// more than max_days days
+export interface StatusAlertRule {
+  id: number;
+  group_id: number;
+  status: string;
+  max_days: number;
+  message: string;
+}
+
+// StatusAlert is a status alert rule an animal has passed
+export interface StatusAlert {
+  rule_id: number;
+  status: string;
+  max_days: number;
+  days: number;
+  since: string;
+  message?: string;
+}
+
+// PublicGroupFeedSettings is a group's public animal feed setting and URLs
+export interface PublicGroupFeedSettings {
+  enabled: boolean;
+  slug: string;
+  json_url?: string;
+  rss_url?: string;
+}
+
+// UserGroupsResult lists the group IDs a bulk membership update changed
+export interface UserGroupsResult {
+  user_id: number;
+  added: number[];
+  removed: number[];
+  admin_changed: number[];
+}
+
+// GroupBranding is a group's public look (no login needed to read it)
+export interface GroupBranding {
+  group_id: number;
+  name: string;
+  accent_color: string;
+  logo_url: string;
+  hero_image_url: string;
+  welcome_text: string;
 }
 
 // GroupMembership represents the current user's membership status in a group
@@ -173,6 +499,81 @@ export interface GroupMembership {
   is_member: boolean;
   is_group_admin: boolean;
   is_site_admin: boolean;
+  role: GroupRole;
+}
+
+// UserPermissions is the current user's capability map: site-wide, and per
+// group (every group, for site admins)
+export interface UserPermissions {
+  user_id: number;
+  is_site_admin: boolean;
+  site: {
+    can_manage_users: boolean;
+    can_manage_groups: boolean;
+    can_post_site_announcements: boolean;
+    can_send_emergency_broadcasts: boolean;
+    can_manage_settings: boolean;
+    can_import_animals: boolean;
+    can_bulk_update_animals: boolean;
+    can_view_statistics: boolean;
+    can_manage_api_tokens: boolean;
+    can_review_quarantined_images: boolean;
+  };
+  groups: GroupPermissions[];
+}
+
+export interface GroupPermissions {
+  group_id: number;
+  group_name: string;
+  role: GroupRole;
+  is_member: boolean;
+  can_view_animals: boolean;
+  can_edit_animals: boolean;
+  can_delete_animals: boolean;
+  can_comment: boolean;
+  can_moderate_comments: boolean;
+  can_upload_media: boolean;
+  can_record_weights: boolean;
+  can_view_behavior_assessments: boolean;
+  can_record_behavior_assessments: boolean;
+  can_post_announcements: boolean;
+  can_send_emergency_broadcasts: boolean;
+  can_manage_members: boolean;
+  can_review_join_requests: boolean;
+  can_view_member_activity: boolean;
+  can_manage_settings: boolean;
+  can_manage_tags: boolean;
+  can_manage_field_visibility: boolean;
+  can_manage_status_checklists: boolean;
+  can_pin_comments: boolean;
+  can_view_statistics: boolean;
+  can_export_data: boolean;
+  can_manage_share_links: boolean;
+  hidden_fields: string[]; // Animal fields left out of responses for this user
+}
+
+// GroupRole is a member's role in a group, lowest first. Group admins and
+// site admins rank above the roles a member can be given.
+export type GroupRole = 'new_volunteer' | 'volunteer' | 'group_admin' | 'site_admin';
+
+// FieldVisibility maps animal fields, or "field.<key>" for custom fields, to
+// the lowest role that sees them. Restricted fields are left out of animal
+// responses for lower roles.
+export interface FieldVisibility {
+  fields: Record<string, Exclude<GroupRole, 'site_admin'>>;
+  role?: GroupRole; // The requesting user's role
+}
+
+export interface ProtocolAttachment {
+  id: number;
+  protocol_id: number;
+  group_id: number;
+  is_image: boolean;
+  file_url: string;
+  file_name: string;
+  file_type: string;
+  file_size: number;
+  created_at: string;
 }
 
 export interface Protocol {
@@ -180,8 +581,10 @@ export interface Protocol {
   group_id: number;
   title: string;
   content: string;
+  content_format?: 'plain' | 'markdown';
   image_url: string;
   order_index: number;
+  attachments?: ProtocolAttachment[];
   created_at: string;
   updated_at: string;
 }
@@ -208,6 +611,7 @@ export interface AnimalNameHistory {
   old_name: string;
   new_name: string;
   changed_by: number;
+  source?: 'edit' | 'merge' | 'import';
 }
 
 export interface AnimalBQIncident {
@@ -219,6 +623,15 @@ export interface AnimalBQIncident {
   created_at: string;
 }
 
+// Sized copies of an image served by /api/images/:uuid, or for an external
+// image on an allow-listed host, signed /api/image-proxy URLs. A size the
+// image is already no bigger than serves the full image.
+export interface ImageVariantURLs {
+  thumb: string;
+  card: string;
+  full: string;
+}
+
 export interface Animal {
   id: number;
   group_id: number;
@@ -227,9 +640,14 @@ export interface Animal {
   breed: string;
   age: number;
   estimated_birth_date?: string;
+  birth_date?: string; // Accepted on create/update as an alias for estimated_birth_date
+  age_years?: number;
+  age_months?: number;
+  custom_fields?: Record<string, string>; // Values of the group's custom fields, by key
   description: string;
   trainer_notes?: string;
   image_url: string;
+  image_variants?: ImageVariantURLs;
   status: string;
   arrival_date?: string;
   foster_start_date?: string;
@@ -241,8 +659,20 @@ export interface Animal {
   archived_date?: string;
   last_status_change?: string;
   is_returned: boolean;
+  intake_source?: IntakeSource | '';
+  size?: AnimalSize | '';
+  energy_level?: EnergyLevel | '';
+  legal_hold?: boolean; // Blocks status changes, transfers, and deletion; set by site admins only
+  legal_hold_reason?: string;
+  legal_hold_at?: string;
+  legal_hold_by_id?: number;
+  outcome?: AnimalOutcome | ''; // Set once the animal has left care
+  microchip_number?: string; // Stored without separators; unique in the group
+  license_number?: string;
+  outcome_date?: string;
   image_count?: number;
   video_count?: number;
+  alerts?: StatusAlert[]; // List responses only
   protocol_document_url?: string;
   protocol_document_name?: string;
   protocol_document_type?: string;
@@ -252,7 +682,38 @@ export interface Animal {
   name_history?: AnimalNameHistory[];
   bq_incidents?: AnimalBQIncident[];
   scripts?: Script[];
+  comment_tag_counts?: CommentTagCount[];
+  pinned_comments?: AnimalComment[]; // Pinned by group admins, newest pin first
+  related_animals?: RelatedAnimal[]; // Detail responses only
+}
+
+export type AnimalRelation = 'bonded_pair' | 'littermate' | 'parent' | 'offspring';
+
+// RelatedAnimal is an animal linked to another, with `relation` read from
+// the other animal's side: a parent's page lists its litter as offspring.
+export interface RelatedAnimal {
+  relationship_id: number;
+  relation: AnimalRelation;
+  notes?: string;
+  animal_id: number;
+  group_id: number;
+  name: string;
+  status: string;
+  image_url?: string;
+}
+
+// Other shelter software exports the animal CSV import reads
+export type AnimalImportFormat = 'petpoint' | 'shelterluv';
+
+export type IntakeSource = 'stray' | 'owner_surrender' | 'transfer' | 'returned_adoption' | 'born_in_care' | 'other';
+export type AnimalSize = 'small' | 'medium' | 'large';
+export type EnergyLevel = 'low' | 'medium' | 'high';
+// Animals per value of each facet; 'unknown' counts animals without one
+export interface AnimalFacetCounts {
+  size: Record<AnimalSize | 'unknown', number>;
+  energy_level: Record<EnergyLevel | 'unknown', number>;
 }
+export type AnimalOutcome = 'adopted' | 'returned_to_owner' | 'transferred' | 'other_live' | 'euthanized' | 'died' | 'lost';
 
 export interface Update {
   id: number;
@@ -266,6 +727,131 @@ export interface Update {
   user?: User;
 }
 
+export interface SiteConfigStatus {
+  key: string;
+  label: string;
+  color: string;
+  date_field: string;
+}
+
+export interface SiteConfig {
+  version: number;
+  exported_at: string;
+  settings: Record<string, string>;
+  statuses?: SiteConfigStatus[];
+  breeds: { species: string; name: string; aliases?: string[] }[];
+  groups: {
+    name: string;
+    description: string;
+    comment_tags: { name: string; color: string; is_system?: boolean }[];
+    animal_tags: { name: string; category: 'behavior' | 'walker_status'; color: string; restricted?: boolean }[];
+    statuses?: SiteConfigStatus[];
+  }[];
+}
+
+export interface SiteConfigChange {
+  section: 'setting' | 'breed' | 'group' | 'comment_tag' | 'animal_tag' | 'statuses';
+  group?: string;
+  name?: string;
+  action: 'create' | 'update';
+  fields?: string[];
+  from?: string;
+  to?: string;
+}
+
+export interface SiteConfigImportResult {
+  dry_run: boolean;
+  changes: SiteConfigChange[];
+  unchanged: number;
+}
+
+export interface Discussion {
+  id: number;
+  group_id: number;
+  user_id: number;
+  title: string;
+  content: string;
+  is_edited: boolean;
+  reply_count: number;
+  last_reply_at?: string | null;
+  created_at: string;
+  updated_at: string;
+  user?: User;
+}
+
+export interface DiscussionReply {
+  id: number;
+  discussion_id: number;
+  parent_id?: number | null;
+  user_id: number;
+  content: string;
+  is_edited: boolean;
+  created_at: string;
+  updated_at: string;
+  user?: User;
+}
+
+export interface DiscussionDetail extends Discussion {
+  replies: DiscussionReply[];
+  muted: boolean;
+}
+
+export interface DiscussionListResponse {
+  items: Discussion[];
+  total: number;
+  limit: number;
+  offset: number;
+  hasMore: boolean;
+}
+
+export type FosterSize = 'small' | 'medium' | 'large';
+
+// An inclusive range of YYYY-MM-DD dates the volunteer can't foster
+export interface FosterBlackout {
+  start: string;
+  end: string;
+  note?: string;
+}
+
+export interface FosterProfile {
+  id: number; // 0 until the volunteer saves one
+  user_id: number;
+  group_id: number;
+  available: boolean;
+  capacity: number;
+  current_count: number;
+  species: string[] | null; // Empty accepts any species
+  sizes: FosterSize[] | null; // Empty accepts any size
+  blackout_dates: FosterBlackout[] | null;
+  notes: string;
+  user?: User;
+  created_at: string;
+  updated_at: string;
+}
+
+export type FosterProfileInput = Pick<FosterProfile, 'available' | 'capacity' | 'current_count' | 'notes'> & {
+  species: string[];
+  sizes: FosterSize[];
+  blackout_dates: FosterBlackout[];
+};
+
+export type FosterMatchReason = 'unavailable' | 'full' | 'blackout' | 'species' | 'size' | 'qualification';
+
+export interface FosterMatch {
+  profile: FosterProfile;
+  open_slots: number;
+  match: boolean;
+  reasons?: FosterMatchReason[];
+}
+
+export interface FosterMatches {
+  animal_id?: number;
+  animal_size?: FosterSize; // Omitted when the animal has no weigh-ins
+  from: string;
+  to: string;
+  items: FosterMatch[];
+}
+
 export interface Announcement {
   id: number;
   user_id: number;
@@ -273,10 +859,91 @@ export interface Announcement {
   content: string;
   send_email: boolean;
   send_groupme: boolean;
+  group_id?: number;
+  groups?: Pick<Group, 'id' | 'name'>[];  // Targeted groups; omitted for site-wide announcements
+  is_read: boolean;  // Whether the current user has marked it read
   created_at: string;
   user?: User;
 }
 
+export interface AnnouncementRead {
+  id: number;
+  announcement_id: number;
+  user_id: number;
+  read_at: string;
+}
+
+export interface AnnouncementReader {
+  id: number;
+  username: string;
+  first_name: string;
+  last_name: string;
+  read_at?: string;
+}
+
+export interface AnnouncementReadReport {
+  announcement_id: number;
+  title: string;
+  audience_count: number;
+  read_count: number;
+  read: AnnouncementReader[];
+  unread: AnnouncementReader[];
+}
+
+export interface AnnouncementLinkStats {
+  url: string;
+  clicks: number;
+  recipients: number; // Recipients who clicked it at least once
+}
+
+// Opens and clicks are only recorded while the email_tracking site setting
+// is on. delivered, opened, and clicked count recipients; a click counts as
+// an open too.
+export interface AnnouncementEmailStats {
+  announcement_id: number;
+  title: string;
+  tracking_enabled: boolean;
+  delivered: number;
+  opened: number;
+  clicked: number;
+  total_opens: number;
+  total_clicks: number;
+  open_rate: number; // 0-1, out of delivered
+  click_rate: number;
+  links: AnnouncementLinkStats[];
+}
+
+export type SMSDeliveryStatus = 'pending' | 'sent' | 'delivered' | 'failed';
+
+export interface SMSPreferences {
+  phone_number: string; // E.164 once opted in, e.g. "+15551234567"
+  sms_opt_in: boolean;
+}
+
+export interface EmergencyBroadcastDelivery {
+  id: number;
+  broadcast_id: number;
+  user_id: number;
+  phone_number: string;
+  provider_message_id: string;
+  status: SMSDeliveryStatus;
+  error?: string;
+  user?: Pick<User, 'id' | 'username' | 'first_name' | 'last_name'>;
+}
+
+export interface EmergencyBroadcast {
+  id: number;
+  created_at: string;
+  group_id: number | null; // null for site-wide broadcasts
+  sent_by_id: number;
+  message: string;
+  recipient_count: number;
+  sent_by?: Pick<User, 'id' | 'username' | 'first_name' | 'last_name'>;
+  group?: Pick<Group, 'id' | 'name'>;
+  status_counts: Record<SMSDeliveryStatus, number>;
+  deliveries?: EmergencyBroadcastDelivery[]; // Only from emergencyBroadcastsApi.get
+}
+
 export interface AnimalImage {
   id: number;
   animal_id: number;
@@ -287,9 +954,13 @@ export interface AnimalImage {
   width: number;
   height: number;
   file_size: number;
+  variants?: ImageVariantURLs;
+  moderation_status?: 'approved' | 'pending';
+  moderation_labels?: string[];
   created_at: string;
   deleted_at?: string | null;
   user?: User;
+  animal?: Animal;
 }
 
 export interface AnimalVideo {
@@ -316,6 +987,7 @@ export interface AnimalComment {
   animal_id: number;
   user_id: number;
   content: string;
+  author_name?: string; // Original author of an imported comment without an account; show in place of user
   image_url: string;
   is_edited: boolean;
   created_at: string;
@@ -324,74 +996,451 @@ export interface AnimalComment {
   metadata?: SessionMetadata;
   tags?: CommentTag[];
   user?: User;
+  reactions?: ReactionCount[]; // Only on list endpoints; omitted when there are none
+  pinned: boolean;
+  pinned_at?: string | null;
+  pinned_by_id?: number | null;
+  client_id?: string; // Set on comments sent through the offline batch endpoint
+}
+
+// OfflineComment is a comment written offline, queued until it can be sent.
+// client_id is a UUID generated when it's written and kept across retries.
+export interface OfflineComment {
+  client_id: string;
+  animal_id: number;
+  content: string;
+  image_url?: string;
+  tag_ids?: number[];
+  metadata?: SessionMetadata;
+}
+
+// CommentBatchResult is the outcome of one offline comment. Drop it from the
+// queue unless status is 'failed', which means it can be resent later.
+export interface CommentBatchResult {
+  index: number;
+  client_id: string;
+  status: 'created' | 'duplicate' | 'conflict' | 'error' | 'failed';
+  comment?: AnimalComment;
+  error?: string;
+}
+
+// CommentSyncPage holds the comment changes since a sync cursor; keep
+// next_cursor for the next sync
+export interface CommentSyncPage {
+  comments: AnimalComment[];
+  deleted_ids: number[];
+  next_cursor: string | null;
+  has_more: boolean;
+}
+
+// DeletedAnimalComment is a deleted comment as group admins see it, until
+// the comment purge removes it at purge_after
+export interface DeletedAnimalComment extends AnimalComment {
+  animal: Animal;
+  deleted_at: string;
+  purge_after: string;
+}
+
+export type ReactionType = 'thumbs_up' | 'heart';
+
+export interface ReactionCount {
+  type: ReactionType;
+  count: number;
+  reacted: boolean; // True if the current user left this reaction
+}
+
+export interface CommentReactionsResponse {
+  comment_id: number;
+  reactions: ReactionCount[];
+}
+
+export interface SessionMetadata {
+  session_goal?: string;
+  session_outcome?: string;
+  behavior_notes?: string;
+  medical_notes?: string;
+  session_rating?: number; // 1-5 (Poor, Fair, Okay, Good, Great)
+  other_notes?: string;
+  session_date?: string;       // "YYYY-MM-DD" format
+  session_start_time?: string; // "HH:MM" 24-hour format
+  session_end_time?: string;   // "HH:MM" 24-hour format
+}
+
+export interface CommentHistory {
+  id: number;
+  created_at: string;
+  comment_id: number;
+  content: string;
+  image_url: string;
+  metadata?: SessionMetadata;
+  edited_by: number;
+  user?: User;
+}
+
+export interface PaginatedCommentsResponse {
+  comments: AnimalComment[];
+  total: number;
+  limit: number;
+  offset: number;
+  hasMore: boolean;
+}
+
+export type CommentPositionResponse =
+  | { found: true; offset: number }
+  | { found: false };
+
+export interface CommentWithAnimal extends AnimalComment {
+  animal: Animal;
+}
+
+export interface CommentTag {
+  id: number;
+  name: string;
+  color: string;
+  is_system: boolean;
+  created_at: string;
+}
+
+// CommentTagCount is how many of an animal's comments carry a tag
+export interface CommentTagCount {
+  tag_id: number;
+  name: string;
+  color: string;
+  count: number;
+}
+
+// CommentTagVolume is a group's tagged comment counts per day or week
+export interface CommentTagVolume {
+  from: string;
+  to: string;
+  interval: 'day' | 'week';
+  tags: Array<{
+    tag_id: number;
+    name: string;
+    color: string;
+    total: number;
+    previous_total: number;
+    points: Array<{ date: string; count: number }>;
+  }>;
+}
+
+// Params are GET /animals filters: status, name, and field.<key>
+export interface SavedFilter {
+  id: number;
+  user_id: number;
+  group_id: number;
+  name: string;
+  params: Record<string, string>;
+  is_default: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface SavedFilterInput {
+  name: string;
+  params: Record<string, string>;
+  is_default?: boolean;
+}
+
+export type AnimalCustomFieldType = 'text' | 'number' | 'date' | 'boolean' | 'select';
+
+export interface AnimalCustomField {
+  id: number;
+  group_id: number;
+  key: string;
+  name: string;
+  type: AnimalCustomFieldType;
+  required: boolean;
+  options?: string[];
+  order_index: number;
+}
+
+export interface Breed {
+  id: number;
+  species: string;
+  name: string;
+  aliases: string[];
+  created_at: string;
+  updated_at: string;
+}
+
+export interface BreedInput {
+  species: string;
+  name: string;
+  aliases?: string[];
+}
+
+export interface BreedReportEntry {
+  species: string;
+  breed: string;
+  animal_count: number;
+  status: 'canonical' | 'matched' | 'unmatched';
+  canonical?: string;
+  suggestion?: Breed;
+}
+
+export interface BreedMigrationRequest {
+  apply_matches?: boolean;
+  mappings?: { species: string; breed: string; breed_id: number }[];
+  add_aliases?: boolean;
+  dry_run?: boolean;
+}
+
+export interface BreedMigrationResult {
+  dry_run: boolean;
+  changes: { species: string; from: string; to: string; animal_count: number }[];
+  animals_updated: number;
+}
+
+export interface AnimalCustomFieldInput {
+  key: string;
+  name?: string;
+  type?: AnimalCustomFieldType;
+  required?: boolean;
+  options?: string[];
+}
+
+export interface AnimalLookupResult {
+  id: number;
+  group_id: number;
+  group_name: string;
+  name: string;
+  species: string;
+  breed: string;
+  status: string;
+  image_url: string;
+  microchip_number: string;
+  license_number: string;
+}
+
+export interface AnimalTag {
+  id: number;
+  name: string;
+  category: string; // 'behavior' or 'walker_status'
+  color: string;
+  icon: string; // Unicode emoji
+  // Restricted tags hide the animal from members without a matching qualification
+  restricted: boolean;
+  created_at: string;
+}
+
+export interface UserQualification {
+  id: number;
+  created_at: string;
+  group_id: number;
+  user_id: number;
+  animal_tag_id: number;
+  granted_by_id: number;
+  user?: Pick<User, 'id' | 'username' | 'first_name' | 'last_name'>;
+  animal_tag?: AnimalTag;
+}
+
+export interface DuplicateNameInfo {
+  name: string;
+  count: number;
+  animals: Animal[];
+  has_duplicates: boolean;
+}
+
+// AnimalNameTakenError is the 409 body returned when a group with unique
+// animal names already has an active animal by the name
+export interface AnimalNameTakenError {
+  error: string;
+  code?: 'ANIMAL_NAME_TAKEN';
+  suggestions: string[];
+}
+
+export type ChecklistKind = 'photo' | 'behavior_assessment' | 'weight' | 'microchip' | 'custom_field';
+
+// StatusChecklistItem is one thing an animal needs before it can move into to_status
+export interface StatusChecklistItem {
+  id: number;
+  group_id: number;
+  to_status: string;
+  kind: ChecklistKind;
+  field?: string; // Custom field key, for custom_field items
+  label: string;
+  order_index: number;
+}
+
+export interface ChecklistItemResult {
+  id: number;
+  kind: ChecklistKind;
+  field?: string;
+  label: string;
+  complete: boolean;
+}
+
+// AnimalChecklist is an animal's progress toward one gated status
+export interface AnimalChecklist {
+  status: string;
+  complete: boolean;
+  items: ChecklistItemResult[];
+}
+
+// ChecklistIncompleteError is the 409 body returned when a status change is
+// blocked by checklist items the animal hasn't met
+export interface ChecklistIncompleteError {
+  error: string;
+  code?: 'CHECKLIST_INCOMPLETE';
+  status: string;
+  incomplete: ChecklistItemResult[];
+}
+
+export type OnboardingKind = 'protocols' | 'profile' | 'task' | 'confirmed';
+
+// OnboardingItem is one step of a group's checklist for new members.
+// protocols and profile items are checked automatically.
+export interface OnboardingItem {
+  id: number;
+  group_id: number;
+  kind: OnboardingKind;
+  label: string;
+  description: string;
+  order_index: number;
 }
 
-export interface SessionMetadata {
-  session_goal?: string;
-  session_outcome?: string;
-  behavior_notes?: string;
-  medical_notes?: string;
-  session_rating?: number; // 1-5 (Poor, Fair, Okay, Good, Great)
-  other_notes?: string;
-  session_date?: string;       // "YYYY-MM-DD" format
-  session_start_time?: string; // "HH:MM" 24-hour format
-  session_end_time?: string;   // "HH:MM" 24-hour format
+export interface OnboardingItemInput {
+  kind: OnboardingKind;
+  label?: string;
+  description?: string;
+  order_index?: number;
 }
 
-export interface CommentHistory {
-  id: number;
-  created_at: string;
-  comment_id: number;
-  content: string;
-  image_url: string;
-  metadata?: SessionMetadata;
-  edited_by: number;
-  user?: User;
+export interface OnboardingItemProgress extends OnboardingItem {
+  complete: boolean;
+  completed_at?: string;
 }
 
-export interface PaginatedCommentsResponse {
-  comments: AnimalComment[];
-  total: number;
-  limit: number;
-  offset: number;
-  hasMore: boolean;
+// OnboardingChecklist is the current user's progress; required is false for
+// members who joined before the group had a checklist
+export interface OnboardingChecklist {
+  group_id: number;
+  required: boolean;
+  started_at?: string;
+  complete: boolean;
+  completed_count: number;
+  items: OnboardingItemProgress[];
 }
 
-export type CommentPositionResponse =
-  | { found: true; offset: number }
-  | { found: false };
+export interface OnboardingMember {
+  user_id: number;
+  username: string;
+  first_name: string;
+  last_name: string;
+  email: string;
+  started_at: string;
+  welcome_email_sent_at: string | null;
+  completed_count: number;
+  complete: boolean;
+  pending_item_ids: number[];
+}
 
-export interface CommentWithAnimal extends AnimalComment {
+export interface OnboardingReport {
+  items: OnboardingItem[];
+  complete_count: number;
+  incomplete_count: number;
+  members: OnboardingMember[];
+}
+
+// PossibleDuplicateError is the 409 body returned when creating an animal that
+// looks like one already in the group
+export interface PossibleDuplicateError {
+  error: string;
+  code: 'POSSIBLE_DUPLICATE';
+  candidates: Array<Pick<Animal, 'id' | 'name' | 'species' | 'breed' | 'age' | 'status' | 'image_url'> & {
+    matched_on: string[];
+  }>;
+}
+
+export interface AnimalMergeResult {
   animal: Animal;
+  moved: Record<string, number>;
 }
 
-export interface CommentTag {
+// BehaviorAssessment scores an animal's behavior from 1 to 5
+export interface BehaviorAssessment {
   id: number;
-  name: string;
-  color: string;
-  is_system: boolean;
+  animal_id: number;
+  score: number;
+  assessed_at: string;
+  assessed_by_id: number;
+  assessed_by?: { id: number; username: string };
+  notes: string;
   created_at: string;
 }
 
-export interface AnimalTag {
+// AnimalComparison is one animal in a side-by-side comparison, with ages in
+// months, weight in pounds, and durations in whole days
+export interface AnimalComparison {
   id: number;
   name: string;
-  category: string; // 'behavior' or 'walker_status'
-  color: string;
-  icon: string; // Unicode emoji
+  species: string;
+  breed: string;
+  status: string;
+  image_url: string;
+  age_months: number | null;
+  weight_lb: number | null;
+  days_in_shelter: number | null;
+  days_in_status: number | null;
+  is_returned: boolean;
+  tags: Array<Pick<AnimalTag, 'name' | 'category' | 'color'>>;
+  custom_fields: Record<string, unknown>;
+  behavior_assessment: { score: number; assessed_at: string } | null;
+  activity: {
+    comments: number;
+    recent_comments: number; // Last 30 days
+    last_comment_at: string | null;
+    recent_views: number; // Last 30 days
+    photos: number;
+  };
+}
+
+// KennelCardTemplate controls the layout of a group's printable kennel cards
+export interface KennelCardTemplate {
+  group_id: number;
+  updated_at: string;
+  page_size: 'letter' | 'half_letter' | 'a4';
+  heading: string;
+  footer_text: string;
+  accent_color: string;
+  show_photo: boolean;
+  show_breed: boolean;
+  show_age: boolean;
+  show_tags: boolean;
+  show_description: boolean;
+  show_qr_code: boolean;
+}
+
+// AnimalShareLink is a public, no-login link to an animal's share page
+export interface AnimalShareLink {
+  id: number;
   created_at: string;
+  animal_id: number;
+  group_id: number;
+  created_by_id: number;
+  revoked_at: string | null;
+  view_count: number;
+  last_viewed_at: string | null;
+  token: string;
+  url: string;
 }
 
-export interface DuplicateNameInfo {
+// SharedAnimal is the read-only view of an animal shown on its public share page
+export interface SharedAnimal {
   name: string;
-  count: number;
-  animals: Animal[];
-  has_duplicates: boolean;
+  species: string;
+  breed: string;
+  description: string;
+  image_url: string;
+  status: string;
+  status_label: string;
+  group_name: string;
 }
 
 export interface ActivityItem {
   id: number;
-  type: 'comment' | 'announcement';
+  type: 'comment' | 'announcement' | 'animal_change' | 'name_change' | 'photo' | 'discussion' | 'discussion_reply';
   created_at: string;
   updated_at?: string;
   user_id: number;
@@ -402,7 +1451,31 @@ export interface ActivityItem {
   animal_id?: number;
   animal?: Animal;
   tags?: CommentTag[];
+  reactions?: ReactionCount[];
   metadata?: SessionMetadata;
+  changes?: AnimalFieldChange[];
+  discussion_id?: number;
+}
+
+export interface AnimalFieldChange {
+  field: string;
+  old: string;
+  new: string;
+}
+
+// What a dry run would do to one animal; animal_id is absent for animals an import would create
+export interface AnimalDiff {
+  animal_id?: number;
+  name: string;
+  action: 'create' | 'update' | 'delete';
+  changes: AnimalFieldChange[];
+}
+
+export interface AnimalDryRunResult {
+  message: string;
+  dry_run: true;
+  count: number;
+  changes: AnimalDiff[];
 }
 
 export interface ActivityFeedResponse {
@@ -411,6 +1484,7 @@ export interface ActivityFeedResponse {
   limit: number;
   offset: number;
   hasMore: boolean;
+  next_cursor?: string | null;
   summary?: {
     behavior_concerns_count: number;
     medical_concerns_count: number;
@@ -511,13 +1585,30 @@ export interface UserProfile {
 
 // Auth API
 export const authApi = {
+  // Returns the JWT, or with cookie sessions only a CSRF token; the JWT is
+  // then in an HttpOnly cookie
   login: (username: string, password: string) =>
-    api.post<{ token: string; user: User }>('/login', { username, password }),
+    api.post<{ token?: string; csrf_token?: string; user: User }>('/login', { username, password }),
+
+  // Clears cookie session cookies
+  logout: () => api.post<{ message: string }>('/logout'),
+
+  // OIDC providers configured on the server, and whether password login is on
+  oidcProviders: () =>
+    api.get<{ providers: { name: string; display_name: string }[]; password_login_enabled: boolean }>('/auth/oidc/providers'),
+
+  // Full-page navigation target that starts an OIDC login. The server finishes
+  // at /login/oidc with the token in the URL fragment, or session=cookie.
+  oidcLoginUrl: (provider: string, redirect?: string) =>
+    `/api/auth/oidc/login?${new URLSearchParams({ provider, ...(redirect ? { redirect } : {}) })}`,
   
   register: (username: string, email: string, password: string) =>
     api.post<{ token: string; user: User }>('/register', { username, email, password }),
   
   getCurrentUser: () => api.get<User>('/me'),
+
+  // What the current user can do, computed with the server's own access checks
+  getPermissions: () => api.get<UserPermissions>('/me/permissions'),
   
   updateCurrentUserProfile: (profile: {
     username?: string;
@@ -527,6 +1618,7 @@ export const authApi = {
     phone_number?: string;
     hide_email?: boolean;
     hide_phone_number?: boolean;
+    time_zone?: string; // Omit to leave unchanged
   }) =>
     api.put<{
       message: string;
@@ -537,7 +1629,29 @@ export const authApi = {
       phone_number?: string;
       hide_email?: boolean;
       hide_phone_number?: boolean;
+      time_zone: string;
     }>('/me/profile', profile),
+
+  // Limited to one change every 30 days. The response carries a fresh token
+  // with the new username; store it in place of the current one.
+  changeUsername: (username: string) =>
+    api.put<{
+      message: string;
+      username: string;
+      username_changed_at: string;
+      next_change_at: string;
+      token?: string; // Cookie sessions get csrf_token instead
+      csrf_token?: string;
+    }>('/me/username', { username }),
+
+  refreshToken: () => api.post<{ token?: string; csrf_token?: string }>('/refresh'),
+
+  uploadAvatar: (file: File) => {
+    const formData = new FormData();
+    formData.append('image', file);
+    return api.post<UserAvatar>('/me/avatar', formData);
+  },
+  deleteAvatar: () => api.delete<UserAvatar>('/me/avatar'),
   
   // Shares the same endpoint as usersApi.resetPassword. When changing your own
   // password, current_password is verified server-side; admin resets omit it.
@@ -548,13 +1662,20 @@ export const authApi = {
   
   getDefaultGroup: () => api.get<Group>('/default-group'),
   
-  getEmailPreferences: () => api.get<{ email_notifications_enabled: boolean; show_length_of_stay: boolean }>('/email-preferences'),
+  getEmailPreferences: () => api.get<{ email_notifications_enabled: boolean; show_length_of_stay: boolean; animal_change_emails_enabled: boolean; weekly_stats_emails_enabled: boolean }>('/email-preferences'),
   
-  updateEmailPreferences: (emailNotificationsEnabled: boolean, showLengthOfStay: boolean) =>
-    api.put<{ message: string; email_notifications_enabled: boolean; show_length_of_stay: boolean }>('/email-preferences', {
+  updateEmailPreferences: (emailNotificationsEnabled: boolean, showLengthOfStay: boolean, animalChangeEmailsEnabled?: boolean, weeklyStatsEmailsEnabled?: boolean) =>
+    api.put<{ message: string; email_notifications_enabled: boolean; show_length_of_stay: boolean; animal_change_emails_enabled: boolean; weekly_stats_emails_enabled: boolean }>('/email-preferences', {
       email_notifications_enabled: emailNotificationsEnabled,
       show_length_of_stay: showLengthOfStay,
+      animal_change_emails_enabled: animalChangeEmailsEnabled,
+      weekly_stats_emails_enabled: weeklyStatsEmailsEnabled,
     }),
+
+  // Opting in needs a mobile number, passed here or already on the profile
+  getSMSPreferences: () => api.get<SMSPreferences>('/me/sms-preferences'),
+  updateSMSPreferences: (smsOptIn: boolean, phoneNumber?: string) =>
+    api.put<SMSPreferences>('/me/sms-preferences', { sms_opt_in: smsOptIn, phone_number: phoneNumber }),
 };
 
 // Groups API
@@ -569,7 +1690,8 @@ export const groupsApi = {
   getActivityFeed: (id: number, options?: { 
     limit?: number; 
     offset?: number; 
-    type?: 'all' | 'comments' | 'announcements';
+    cursor?: string;
+    type?: 'all' | 'comments' | 'announcements' | 'changes' | 'discussions';
     animal?: number;
     tags?: string;
     rating?: string;
@@ -579,6 +1701,7 @@ export const groupsApi = {
     const params: Record<string, unknown> = {};
     if (options?.limit) params.limit = options.limit;
     if (options?.offset) params.offset = options.offset;
+    if (options?.cursor) params.cursor = options.cursor;
     if (options?.type && options.type !== 'all') params.type = options.type;
     if (options?.animal) params.animal = options.animal;
     if (options?.tags) params.tags = options.tags;
@@ -587,12 +1710,40 @@ export const groupsApi = {
     if (options?.to) params.to = options.to;
     return api.get<ActivityFeedResponse>('/groups/' + id + '/activity-feed', { params });
   },
-  create: (name: string, description: string, image_url?: string, hero_image_url?: string, has_protocols?: boolean, groupme_bot_id?: string, groupme_enabled?: boolean) =>
-    api.post<Group>('/admin/groups', { name, description, image_url, hero_image_url, has_protocols, groupme_bot_id, groupme_enabled }),
-  update: (id: number, name: string, description: string, image_url?: string, hero_image_url?: string, has_protocols?: boolean, groupme_bot_id?: string, groupme_enabled?: boolean) =>
-    api.put<Group>('/admin/groups/' + id, { name, description, image_url, hero_image_url, has_protocols, groupme_bot_id, groupme_enabled }),
+  create: (name: string, description: string, image_url?: string, hero_image_url?: string, has_protocols?: boolean, groupme_bot_id?: string, groupme_enabled?: boolean, public_sharing?: boolean) =>
+    api.post<Group>('/admin/groups', { name, description, image_url, hero_image_url, has_protocols, groupme_bot_id, groupme_enabled, public_sharing }),
+  update: (id: number, name: string, description: string, image_url?: string, hero_image_url?: string, has_protocols?: boolean, groupme_bot_id?: string, groupme_enabled?: boolean, public_sharing?: boolean) =>
+    api.put<Group>('/admin/groups/' + id, { name, description, image_url, hero_image_url, has_protocols, groupme_bot_id, groupme_enabled, public_sharing }),
   // Requires group membership (not admin). Server filters contact info based on privacy settings.
   getMembers: (groupId: number) => api.get<GroupMember[]>(`/groups/${groupId}/members`),
+  // Group admin or site admin
+  setMemberRole: (groupId: number, userId: number, role: 'new_volunteer' | 'volunteer') =>
+    api.put<{ user_id: number; group_id: number; role: GroupRole }>(`/groups/${groupId}/members/${userId}/role`, { role }),
+  getFieldVisibility: (groupId: number) => api.get<FieldVisibility>(`/groups/${groupId}/field-visibility`),
+  // Group admin or site admin. Replaces every override; fields left out use the defaults.
+  updateFieldVisibility: (groupId: number, fields: FieldVisibility['fields']) =>
+    api.put<FieldVisibility>(`/groups/${groupId}/field-visibility`, { fields }),
+  getStatusChecklists: (groupId: number) =>
+    api.get<{ items: StatusChecklistItem[] }>(`/groups/${groupId}/status-checklists`),
+  // Group admin or site admin. Replaces every item; an empty list removes every gate.
+  updateStatusChecklists: (groupId: number, items: Pick<StatusChecklistItem, 'to_status' | 'kind' | 'field' | 'label'>[]) =>
+    api.put<{ items: StatusChecklistItem[] }>(`/groups/${groupId}/status-checklists`, { items }),
+  requestToJoin: (groupId: number, message?: string) =>
+    api.post<GroupJoinRequest>(`/groups/${groupId}/join-requests`, { message }),
+  // Group admins only; pending requests unless another status is given
+  getJoinRequests: (groupId: number, status?: JoinRequestStatus | 'all') =>
+    api.get<GroupJoinRequest[]>(`/groups/${groupId}/join-requests`, { params: status ? { status } : undefined }),
+  approveJoinRequest: (groupId: number, requestId: number) =>
+    api.post<GroupJoinRequest>(`/groups/${groupId}/join-requests/${requestId}/approve`),
+  denyJoinRequest: (groupId: number, requestId: number, reason?: string) =>
+    api.post<GroupJoinRequest>(`/groups/${groupId}/join-requests/${requestId}/deny`, { reason }),
+  getMyJoinRequests: () => api.get<GroupJoinRequest[]>('/me/join-requests'),
+  // Group admins only; counts only comments on the group's animals
+  getMemberActivity: (groupId: number, params?: UserActivityParams) =>
+    api.get<PaginatedResponse<UserActivity>>(`/groups/${groupId}/members/activity`, { params }),
+  // weekStart (YYYY-MM-DD) picks the week containing that date; defaults to the last full week
+  getWeeklyStats: (groupId: number, weekStart?: string) =>
+    api.get<GroupWeeklyStats>(`/groups/${groupId}/weekly-stats`, { params: weekStart ? { week_start: weekStart } : undefined }),
   getUserSkillTags: (groupId: number) => api.get<UserSkillTag[]>(`/groups/${groupId}/user-skill-tags`),
   createUserSkillTag: (groupId: number, name: string, color: string) =>
     api.post<UserSkillTag>(`/groups/${groupId}/user-skill-tags`, { name, color }),
@@ -602,36 +1753,122 @@ export const groupsApi = {
     api.delete(`/groups/${groupId}/user-skill-tags/${tagId}`),
   assignUserSkillTags: (groupId: number, userId: number, tagIds: number[]) =>
     api.put(`/groups/${groupId}/members/${userId}/skill-tags`, { tag_ids: tagIds }),
-  delete: (id: number) => api.delete('/admin/groups/' + id),
+  // Group admins see every member's qualifications; others see their own
+  getQualifications: (groupId: number) => api.get<UserQualification[]>(`/groups/${groupId}/qualifications`),
+  assignQualifications: (groupId: number, userId: number, animalTagIds: number[]) =>
+    api.put<UserQualification[]>(`/groups/${groupId}/members/${userId}/qualifications`, { animal_tag_ids: animalTagIds }),
+  // Two calls: without a token the server responds 428 with a
+  // DeleteConfirmation; repeat with its confirmation_token to delete.
+  delete: (id: number, confirmationToken?: string) =>
+    api.delete('/admin/groups/' + id, confirmationToken ? { params: { confirmation_token: confirmationToken } } : undefined),
   uploadImage: (file: File) => {
     const formData = new FormData();
     formData.append('image', file);
     return api.post<{ url: string }>('/admin/groups/upload-image', formData);
   },
+  getBranding: (groupId: number) => api.get<GroupBranding>(`/groups/${groupId}/branding`),
+  // Group admin or site admin
+  updateBranding: (groupId: number, branding: { accent_color: string; welcome_text: string }) =>
+    api.put<GroupBranding>(`/groups/${groupId}/branding`, branding),
+  uploadLogo: (groupId: number, file: File) => {
+    const formData = new FormData();
+    formData.append('image', file);
+    return api.post<GroupBranding>(`/groups/${groupId}/branding/logo`, formData);
+  },
+  deleteLogo: (groupId: number) => api.delete<GroupBranding>(`/groups/${groupId}/branding/logo`),
+  // Group admin or site admin
+  updatePublicFeed: (groupId: number, settings: { enabled: boolean; slug: string }) =>
+    api.put<PublicGroupFeedSettings>(`/groups/${groupId}/public-feed`, settings),
+  // Group admin or site admin. Card fields may name custom fields as "field.<key>".
+  updateDisplaySettings: (groupId: number, settings: Partial<Omit<GroupDisplaySettings, 'group_id'>>) =>
+    api.put<GroupDisplaySettings>(`/groups/${groupId}/display-settings`, settings),
+  // Group admin or site admin. A new reply-to address is emailed a verification link.
+  getEmailSender: (groupId: number) => api.get<GroupEmailSender>(`/groups/${groupId}/email-sender`),
+  updateEmailSender: (groupId: number, settings: { sender_name: string; reply_to: string }) =>
+    api.put<GroupEmailSender>(`/groups/${groupId}/email-sender`, settings),
+  resendReplyToVerification: (groupId: number) =>
+    api.post<{ message: string }>(`/groups/${groupId}/email-sender/resend-verification`),
+  getStatusAlertRules: (groupId: number) => api.get<StatusAlertRule[]>(`/groups/${groupId}/status-alerts`),
+  // Group admin or site admin. Replaces every rule; an empty list turns alerts off.
+  updateStatusAlertRules: (groupId: number, rules: { status: string; max_days: number; message?: string }[]) =>
+    api.put<StatusAlertRule[]>(`/groups/${groupId}/status-alerts`, { rules }),
+  // Public: the token comes from the verification email's /verify-reply-to link
+  verifyReplyTo: (token: string) =>
+    api.post<{ message: string; group_id: number; reply_to: string }>('/verify-reply-to', { token }),
+};
+
+export type AnimalSort = 'name' | 'arrival_date' | 'last_status_change' | 'age' | 'recently_commented';
+
+// Sorting and paging for animal lists. With a limit, the X-Total-Count
+// response header holds the number of matching animals.
+export interface AnimalListOptions {
+  sort?: AnimalSort;
+  order?: 'asc' | 'desc';
+  limit?: number;
+  offset?: number;
+  size?: (AnimalSize | 'unknown')[]; // Any of these
+  energy_level?: (EnergyLevel | 'unknown')[];
+}
+
+const animalListParams = (status?: string, name?: string, options?: AnimalListOptions): Record<string, unknown> => {
+  const params: Record<string, unknown> = { ...options };
+  if (status !== undefined) params.status = status;
+  if (name) params.name = name;
+  if (options?.size) params.size = options.size.join(',') || undefined;
+  if (options?.energy_level) params.energy_level = options.energy_level.join(',') || undefined;
+  return params;
+};
+
+// Column choices for CSV exports. columns are header names, written in the
+// given order; excludePII drops comment authors and microchip/license numbers.
+export interface CSVColumnOptions {
+  columns?: string[];
+  excludePII?: boolean;
+}
+
+const csvColumnParams = (options?: CSVColumnOptions): Record<string, unknown> => {
+  const params: Record<string, unknown> = {};
+  if (options?.columns?.length) params.columns = options.columns.join(',');
+  if (options?.excludePII) params.exclude_pii = true;
+  return params;
 };
 
 // Animals API
 export const animalsApi = {
-  getAll: (groupId: number, status?: string, name?: string) => {
-    const params: Record<string, unknown> = {};
-    if (status !== undefined) params.status = status;
-    if (name) params.name = name;
-    return api.get<Animal[]>('/groups/' + groupId + '/animals', { params });
-  },
+  getAll: (groupId: number, status?: string, name?: string, options?: AnimalListOptions) =>
+    api.get<Animal[]>('/groups/' + groupId + '/animals', { params: animalListParams(status, name, options) }),
+  // Same filters as getAll, with counts per size and energy level
+  getWithFacets: (groupId: number, status?: string, name?: string, options?: AnimalListOptions) =>
+    api.get<{ animals: Animal[]; facets: AnimalFacetCounts }>('/groups/' + groupId + '/animals', {
+      params: { ...animalListParams(status, name, options), facets: true },
+    }),
   getById: (groupId: number, id: number) =>
     api.get<Animal>('/groups/' + groupId + '/animals/' + id),
   checkDuplicates: (groupId: number, name: string) =>
     api.get<DuplicateNameInfo>('/groups/' + groupId + '/animals/check-duplicates', { params: { name } }),
-  create: (groupId: number, data: Partial<Animal>) =>
-    api.post<Animal>('/groups/' + groupId + '/animals', data),
-  update: (groupId: number, id: number, data: Partial<Animal>) =>
-    api.put<Animal>('/groups/' + groupId + '/animals/' + id, data),
+  compare: (groupId: number, animalIds: number[]) =>
+    api.get<{ animals: AnimalComparison[] }>('/groups/' + groupId + '/animals/compare', { params: { ids: animalIds.join(',') } }),
+  getBehaviorAssessments: (groupId: number, animalId: number) =>
+    api.get<BehaviorAssessment[]>('/groups/' + groupId + '/animals/' + animalId + '/behavior-assessments'),
+  createBehaviorAssessment: (groupId: number, animalId: number, data: { score: number; assessed_at?: string; notes?: string }) =>
+    api.post<BehaviorAssessment>('/groups/' + groupId + '/animals/' + animalId + '/behavior-assessments', data),
+  create: (groupId: number, data: Partial<Animal>, force?: boolean) =>
+    api.post<Animal>('/groups/' + groupId + '/animals', data, force ? { params: { force: true } } : undefined),
+  merge: (groupId: number, animalId: number, duplicateId: number) =>
+    api.post<AnimalMergeResult>('/groups/' + groupId + '/animals/' + animalId + '/merge', { duplicate_id: duplicateId }),
+  // overrideChecklist changes the status even if the group's checklist for it isn't complete
+  update: (groupId: number, id: number, data: Partial<Animal>, overrideChecklist?: boolean) =>
+    api.put<Animal>('/groups/' + groupId + '/animals/' + id, overrideChecklist ? { ...data, override_checklist: true } : data),
+  getChecklist: (groupId: number, animalId: number, status?: string) =>
+    api.get<AnimalChecklist[]>('/groups/' + groupId + '/animals/' + animalId + '/checklist', { params: status ? { status } : undefined }),
   delete: (groupId: number, id: number) =>
     api.delete('/groups/' + groupId + '/animals/' + id),
+  previewDelete: (groupId: number, id: number) =>
+    api.delete<AnimalDryRunResult>('/groups/' + groupId + '/animals/' + id, { params: { dry_run: true } }),
   uploadImage: (file: File) => {
     const formData = new FormData();
     formData.append('image', file);
-    return api.post<{ url: string }>('/animals/upload-image', formData);
+    return api.post<{ url: string; variants?: ImageVariantURLs; moderation_status: 'approved' | 'pending' }>('/animals/upload-image', formData);
   },
   // Image gallery API
   getImages: (groupId: number, animalId: number) =>
@@ -646,6 +1883,18 @@ export const animalsApi = {
     api.delete('/groups/' + groupId + '/animals/' + animalId + '/images/' + imageId),
   getMedia: (groupId: number, animalId: number) =>
     api.get<AnimalMedia>('/groups/' + groupId + '/animals/' + animalId + '/media'),
+  // Comments, edits, renames, and photos for one animal, newest first
+  getTimeline: (groupId: number, animalId: number, options?: {
+    limit?: number;
+    cursor?: string;
+    type?: 'all' | 'comments' | 'changes' | 'name_changes' | 'photos';
+  }) => {
+    const params: Record<string, unknown> = {};
+    if (options?.limit) params.limit = options.limit;
+    if (options?.cursor) params.cursor = options.cursor;
+    if (options?.type && options.type !== 'all') params.type = options.type;
+    return api.get<ActivityFeedResponse>('/groups/' + groupId + '/animals/' + animalId + '/timeline', { params });
+  },
   uploadVideo: (
     groupId: number,
     animalId: number,
@@ -670,6 +1919,15 @@ export const animalsApi = {
     api.get<AnimalImage[]>('/admin/groups/' + groupId + '/deleted-images'),
   setProfilePicture: (groupId: number, animalId: number, imageId: number) =>
     api.put<AnimalImage>(`/groups/${groupId}/animals/${animalId}/images/${imageId}/set-profile`),
+  // Image moderation API (site admins)
+  getQuarantinedImages: () =>
+    api.get<AnimalImage[]>('/admin/images/quarantined'),
+  getQuarantinedImageContent: (imageId: number) =>
+    api.get<Blob>('/admin/images/quarantined/' + imageId + '/content', { responseType: 'blob' }),
+  approveQuarantinedImage: (imageId: number) =>
+    api.post<{ message: string }>('/admin/images/quarantined/' + imageId + '/approve'),
+  rejectQuarantinedImage: (imageId: number) =>
+    api.post<{ message: string }>('/admin/images/quarantined/' + imageId + '/reject'),
   // Protocol document API
   uploadProtocolDocument: (groupId: number, animalId: number, file: File) => {
     const formData = new FormData();
@@ -684,6 +1942,21 @@ export const animalsApi = {
   },
   deleteProtocolDocument: (groupId: number, animalId: number) =>
     api.delete(`/groups/${groupId}/animals/${animalId}/protocol-document`),
+  // Public share links
+  getShareLinks: (groupId: number, animalId: number) =>
+    api.get<AnimalShareLink[]>(`/groups/${groupId}/animals/${animalId}/share-links`),
+  createShareLink: (groupId: number, animalId: number) =>
+    api.post<AnimalShareLink>(`/groups/${groupId}/animals/${animalId}/share-links`),
+  revokeShareLink: (groupId: number, animalId: number, linkId: number) =>
+    api.delete<AnimalShareLink>(`/groups/${groupId}/animals/${animalId}/share-links/${linkId}`),
+  getShareLinkQRCode: (groupId: number, animalId: number, linkId: number) =>
+    api.get<Blob>(`/groups/${groupId}/animals/${animalId}/share-links/${linkId}/qr.png`, { responseType: 'blob' }),
+  getKennelCard: (groupId: number, animalId: number) =>
+    api.get<Blob>(`/groups/${groupId}/animals/${animalId}/kennel-card.pdf`, { responseType: 'blob' }),
+  getKennelCardTemplate: (groupId: number) =>
+    api.get<KennelCardTemplate>(`/groups/${groupId}/kennel-card-template`),
+  updateKennelCardTemplate: (groupId: number, template: Omit<KennelCardTemplate, 'group_id' | 'updated_at'>) =>
+    api.put<KennelCardTemplate>(`/groups/${groupId}/kennel-card-template`, template),
   getProtocolDocument: (uuid: string) =>
     api.get(`/documents/${uuid}`, { responseType: 'blob' }),
   // Admin and group admin bulk operations
@@ -700,20 +1973,70 @@ export const animalsApi = {
     if (status !== undefined) data.status = status;
     return api.post<{ message: string; count: number }>('/bulk-animals/bulk-update', data);
   },
-  importCSV: (file: File) => {
+  // Checks the update and returns what it would change without saving it
+  previewBulkUpdate: (animalIds: number[], groupId?: number, status?: string) => {
+    const data: Record<string, unknown> = { animal_ids: animalIds };
+    if (groupId !== undefined) data.group_id = groupId;
+    if (status !== undefined) data.status = status;
+    return api.post<AnimalDryRunResult>('/bulk-animals/bulk-update', data, { params: { dry_run: true } });
+  },
+  // format reads a PetPoint or Shelterluv export as is; groupId places every row in one group
+  // fetchImages downloads remote image_url values into the site's store;
+  // requireImages skips rows that end up without an image;
+  // dryRun rolls the import back and returns sample changes without fetching images
+  importCSV: (
+    file: File,
+    mode: 'insert' | 'upsert' = 'insert',
+    format?: AnimalImportFormat,
+    groupId?: number,
+    options: { fetchImages?: boolean; requireImages?: boolean; dryRun?: boolean } = {},
+  ) => {
+    const formData = new FormData();
+    formData.append('file', file);
+    const params: Record<string, string | number> = { mode };
+    if (format) params.format = format;
+    if (groupId !== undefined) params.group_id = groupId;
+    if (options.fetchImages) params.fetch_images = 'true';
+    if (options.requireImages) params.require_images = 'true';
+    if (options.dryRun) params.dry_run = 'true';
+    return api.post<{
+      message: string;
+      dry_run: boolean;
+      count: number;
+      created: number;
+      updated: number;
+      images_fetched?: number;
+      warnings?: string[];
+      changes?: AnimalDiff[];
+    }>(
+      '/admin/animals/import-csv', formData, { params });
+  },
+  // Historical comments; a dry run checks the file without saving anything
+  importCommentsCSV: (file: File, dryRun = false) => {
     const formData = new FormData();
     formData.append('file', file);
-    return api.post<{ message: string; count: number; warnings?: string[] }>('/admin/animals/import-csv', formData);
+    return api.post<{
+      message: string;
+      dry_run: boolean;
+      count: number;
+      duplicates: number;
+      unmatched_authors: string[];
+      warnings?: string[];
+    }>('/admin/animals/import-comments-csv', formData, { params: { dry_run: dryRun } });
   },
-  exportCSV: (groupId?: number) => {
-    const params = groupId ? { group_id: groupId } : {};
+  // Site admins only; searches every group, including animals that left care
+  lookup: (params: { microchip?: string; license?: string }) =>
+    api.get<AnimalLookupResult[]>('/admin/animals/lookup', { params }),
+  exportCSV: (groupId?: number, columns?: CSVColumnOptions) => {
+    const params: Record<string, unknown> = csvColumnParams(columns);
+    if (groupId) params.group_id = groupId;
     return api.get('/admin/animals/export-csv', { 
       params,
       responseType: 'blob' 
     });
   },
-  exportCommentsCSV: (groupId?: number, animalId?: number, tags?: string) => {
-    const params: Record<string, unknown> = {};
+  exportCommentsCSV: (groupId?: number, animalId?: number, tags?: string, columns?: CSVColumnOptions) => {
+    const params: Record<string, unknown> = csvColumnParams(columns);
     if (groupId) params.group_id = groupId;
     if (animalId) params.animal_id = animalId;
     if (tags) params.tags = tags;
@@ -759,7 +2082,16 @@ export const animalCommentsApi = {
   delete: (groupId: number, animalId: number, commentId: number) =>
     api.delete('/groups/' + groupId + '/animals/' + animalId + '/comments/' + commentId),
   getDeleted: (groupId: number) =>
-    api.get<AnimalComment[]>('/admin/groups/' + groupId + '/deleted-comments'),
+    api.get<DeletedAnimalComment[]>('/admin/groups/' + groupId + '/deleted-comments'),
+  // Group admin or site admin
+  getDeletedForAnimal: (groupId: number, animalId: number) =>
+    api.get<DeletedAnimalComment[]>('/groups/' + groupId + '/animals/' + animalId + '/comments/deleted'),
+  restore: (groupId: number, animalId: number, commentId: number) =>
+    api.post<AnimalComment>('/groups/' + groupId + '/animals/' + animalId + '/comments/' + commentId + '/restore'),
+  pin: (groupId: number, animalId: number, commentId: number) =>
+    api.put<AnimalComment>('/groups/' + groupId + '/animals/' + animalId + '/comments/' + commentId + '/pin'),
+  unpin: (groupId: number, animalId: number, commentId: number) =>
+    api.delete<AnimalComment>('/groups/' + groupId + '/animals/' + animalId + '/comments/' + commentId + '/pin'),
   getHistory: (groupId: number, animalId: number, commentId: number) =>
     api.get<CommentHistory[]>('/groups/' + groupId + '/animals/' + animalId + '/comments/' + commentId + '/history'),
   // Looks up which page a comment falls on under a given tagFilter/order, so
@@ -774,6 +2106,15 @@ export const animalCommentsApi = {
     if (options?.order) params.order = options.order;
     return api.get<CommentPositionResponse>('/groups/' + groupId + '/animals/' + animalId + '/comments/' + commentId + '/position', { params });
   },
+  addReaction: (groupId: number, animalId: number, commentId: number, type: ReactionType) =>
+    api.post<CommentReactionsResponse>('/groups/' + groupId + '/animals/' + animalId + '/comments/' + commentId + '/reactions', { type }),
+  removeReaction: (groupId: number, animalId: number, commentId: number, type: ReactionType) =>
+    api.delete<CommentReactionsResponse>('/groups/' + groupId + '/animals/' + animalId + '/comments/' + commentId + '/reactions/' + type),
+  // Offline support: send up to 50 queued comments, across the group's animals
+  sendBatch: (groupId: number, comments: OfflineComment[]) =>
+    api.post<{ results: CommentBatchResult[] }>('/groups/' + groupId + '/comments/batch', { comments }),
+  sync: (groupId: number, since?: string | null, limit?: number) =>
+    api.get<CommentSyncPage>('/groups/' + groupId + '/comments/sync', { params: { since: since || undefined, limit } }),
 };
 
 // Comment Tags API - Group-specific tags
@@ -782,27 +2123,145 @@ export const commentTagsApi = {
   create: (groupId: number, name: string, color: string) =>
     api.post<CommentTag>('/groups/' + groupId + '/comment-tags', { name, color }),
   delete: (groupId: number, tagId: number) => api.delete('/groups/' + groupId + '/comment-tags/' + tagId),
+  getVolume: (groupId: number, params?: { from?: string; to?: string; interval?: 'day' | 'week'; tags?: string }) =>
+    api.get<CommentTagVolume>('/groups/' + groupId + '/comment-tags/volume', { params }),
 };
 
 // Animal Tags API - Group-specific tags
 export const animalTagsApi = {
   getAll: (groupId: number) => api.get<AnimalTag[]>('/groups/' + groupId + '/animal-tags'),
-  create: (groupId: number, data: { name: string; category: string; color: string }) =>
+  create: (groupId: number, data: { name: string; category: string; color: string; restricted?: boolean }) =>
     api.post<AnimalTag>('/groups/' + groupId + '/animal-tags', data),
-  update: (groupId: number, tagId: number, data: { name: string; category: string; color: string }) =>
+  update: (groupId: number, tagId: number, data: { name: string; category: string; color: string; restricted?: boolean }) =>
     api.put<AnimalTag>('/groups/' + groupId + '/animal-tags/' + tagId, data),
   delete: (groupId: number, tagId: number) => api.delete('/groups/' + groupId + '/animal-tags/' + tagId),
   assignToAnimal: (groupId: number, animalId: number, tagIds: number[]) =>
     api.post<Animal>('/groups/' + groupId + '/animals/' + animalId + '/tags', { tag_ids: tagIds }),
 };
 
+export const animalCustomFieldsApi = {
+  getAll: (groupId: number) => api.get<AnimalCustomField[]>('/groups/' + groupId + '/animal-fields'),
+  replace: (groupId: number, fields: AnimalCustomFieldInput[]) =>
+    api.put<AnimalCustomField[]>('/groups/' + groupId + '/animal-fields', { fields }),
+};
+
+// AnimalFormField is one field of a group's new animal form
+export interface AnimalFormField {
+  field: string;
+  label: string;
+  type: 'text' | 'textarea' | 'number' | 'date' | 'select' | 'image';
+  options?: string[];
+  required: boolean;
+  configurable: boolean; // Whether group admins can change required
+}
+
+export interface AnimalForm {
+  fields: AnimalFormField[];
+  custom_fields: AnimalCustomField[];
+}
+
+// Required fields of new animals, per group. Replacing them is for group
+// admins and site admins.
+export const animalFormApi = {
+  get: (groupId: number) => api.get<AnimalForm>('/groups/' + groupId + '/animal-form'),
+  setRequired: (groupId: number, required: string[]) =>
+    api.put<AnimalForm>('/groups/' + groupId + '/animal-form', { required }),
+};
+
+// Animal relationships; any member can list them, group admins link and unlink.
+// With the group's adopt_bonded_together setting on, adopting one of a bonded
+// pair alone responds 409 with a BondedPartnerInCareError; adopt both in one
+// bulk update.
+export const animalRelationshipsApi = {
+  list: (groupId: number, animalId: number) =>
+    api.get<RelatedAnimal[]>('/groups/' + groupId + '/animals/' + animalId + '/relationships'),
+  create: (groupId: number, animalId: number, data: { related_animal_id: number; type: AnimalRelation; notes?: string }) =>
+    api.post<RelatedAnimal>('/groups/' + groupId + '/animals/' + animalId + '/relationships', data),
+  update: (groupId: number, animalId: number, relationshipId: number, data: { type: AnimalRelation; notes?: string }) =>
+    api.put<RelatedAnimal>('/groups/' + groupId + '/animals/' + animalId + '/relationships/' + relationshipId, data),
+  delete: (groupId: number, animalId: number, relationshipId: number) =>
+    api.delete('/groups/' + groupId + '/animals/' + animalId + '/relationships/' + relationshipId),
+};
+
+// FieldError is one entry of a structured error's details. Details that
+// name an animal, such as a held animal or a bonded partner, have a field
+// of `animal:<id>`.
+export interface FieldError {
+  field: string;
+  rule?: string;
+  message: string;
+}
+
+// The animal ID of a detail that names an animal
+export const detailAnimalId = (detail: FieldError): number | undefined => {
+  const match = /^animal:(\d+)$/.exec(detail.field);
+  return match ? Number(match[1]) : undefined;
+};
+
+// LegalHoldError is the 423 body returned when a legal hold blocks a change.
+// With structured errors, details lists each held animal (rule `legal_hold`).
+export interface LegalHoldError {
+  error: string;
+  code?: 'LEGAL_HOLD';
+  details?: FieldError[];
+}
+
+// BondedPartnerInCareError is the 409 body returned when an adoption would
+// leave a bonded partner in care. With structured errors, details lists each
+// partner (rule `bonded_pair`).
+export interface BondedPartnerInCareError {
+  error: string;
+  code?: 'BONDED_PARTNER_IN_CARE';
+  details?: FieldError[];
+}
+
+// An animal under a legal hold: the impact of lifting its hold
+export interface HeldAnimal {
+  animal_id: number;
+  name: string;
+  reason: string;
+}
+
+// Legal holds (site admin only)
+export const legalHoldApi = {
+  place: (animalId: number, reason: string) =>
+    api.put<Animal>('/admin/animals/' + animalId + '/legal-hold', { reason }),
+  // Two calls: without a token the server responds 428 with a
+  // DeleteConfirmation<HeldAnimal>; repeat with its confirmation_token to lift.
+  lift: (animalId: number, confirmationToken?: string) =>
+    api.delete<Animal>('/admin/animals/' + animalId + '/legal-hold',
+      confirmationToken ? { params: { confirmation_token: confirmationToken } } : undefined),
+};
+
+export const breedsApi = {
+  getAll: (species?: string) => api.get<Breed[]>('/breeds', { params: { species } }),
+  suggest: (q: string, species?: string, limit?: number) =>
+    api.get<Breed[]>('/breeds', { params: { q, species, limit } }),
+  create: (data: BreedInput) => api.post<Breed>('/admin/breeds', data),
+  update: (breedId: number, data: BreedInput) => api.put<Breed>('/admin/breeds/' + breedId, data),
+  delete: (breedId: number) => api.delete('/admin/breeds/' + breedId),
+  getReport: () => api.get<BreedReportEntry[]>('/admin/breeds/report'),
+  migrate: (data: BreedMigrationRequest) => api.post<BreedMigrationResult>('/admin/breeds/migrate', data),
+};
+
+// Saved animal filters belong to the current user
+export const savedFiltersApi = {
+  getAll: (groupId: number) => api.get<SavedFilter[]>('/groups/' + groupId + '/saved-filters'),
+  create: (groupId: number, filter: SavedFilterInput) =>
+    api.post<SavedFilter>('/groups/' + groupId + '/saved-filters', filter),
+  update: (groupId: number, filterId: number, filter: SavedFilterInput) =>
+    api.put<SavedFilter>('/groups/' + groupId + '/saved-filters/' + filterId, filter),
+  delete: (groupId: number, filterId: number) =>
+    api.delete('/groups/' + groupId + '/saved-filters/' + filterId),
+};
+
 // Protocols API
 export const protocolsApi = {
   getAll: (groupId: number) => api.get<Protocol[]>('/groups/' + groupId + '/protocols'),
   getById: (groupId: number, protocolId: number) => api.get<Protocol>('/groups/' + groupId + '/protocols/' + protocolId),
-  create: (groupId: number, data: { title: string; content: string; image_url?: string; order_index?: number }) =>
+  create: (groupId: number, data: { title: string; content: string; content_format?: 'plain' | 'markdown'; image_url?: string; order_index?: number }) =>
     api.post<Protocol>('/groups/' + groupId + '/protocols', data),
-  update: (groupId: number, protocolId: number, data: { title: string; content: string; image_url?: string; order_index?: number }) =>
+  update: (groupId: number, protocolId: number, data: { title: string; content: string; content_format?: 'plain' | 'markdown'; image_url?: string; order_index?: number }) =>
     api.put<Protocol>('/groups/' + groupId + '/protocols/' + protocolId, data),
   delete: (groupId: number, protocolId: number) => api.delete('/groups/' + groupId + '/protocols/' + protocolId),
   uploadImage: (groupId: number, file: File) => {
@@ -810,6 +2269,17 @@ export const protocolsApi = {
     formData.append('image', file);
     return api.post<{ url: string }>('/groups/' + groupId + '/protocols/upload-image', formData);
   },
+  move: (groupId: number, protocolId: number, direction: 'up' | 'down') =>
+    api.post<Protocol[]>('/groups/' + groupId + '/protocols/' + protocolId + '/move', { direction }),
+  uploadAttachment: (groupId: number, protocolId: number, file: File) => {
+    const formData = new FormData();
+    formData.append('file', file);
+    return api.post<ProtocolAttachment>('/groups/' + groupId + '/protocols/' + protocolId + '/attachments', formData);
+  },
+  deleteAttachment: (groupId: number, protocolId: number, attachmentId: number) =>
+    api.delete('/groups/' + groupId + '/protocols/' + protocolId + '/attachments/' + attachmentId),
+  exportPdf: (groupId: number) =>
+    api.get<Blob>('/groups/' + groupId + '/protocols.pdf', { responseType: 'blob' }),
 };
 
 export const scriptsApi = {
@@ -833,17 +2303,93 @@ export const updatesApi = {
   delete: (groupId: number, updateId: number) => api.delete('/groups/' + groupId + '/updates/' + updateId),
 };
 
-// Announcements API
+// Site configuration export/import for copying settings, tags, statuses,
+// and breeds between installs (admin only)
+export const siteConfigApi = {
+  export: () => api.get<SiteConfig>('/admin/site-config'),
+  import: (config: SiteConfig, dryRun = false) =>
+    api.post<SiteConfigImportResult>('/admin/site-config/import', config, { params: dryRun ? { dry_run: true } : undefined }),
+};
+
+// Group discussion threads. Replying makes you a participant; participants
+// are emailed about new replies unless they mute the discussion.
+export const discussionsApi = {
+  list: (groupId: number, options?: { limit?: number; offset?: number }) =>
+    api.get<DiscussionListResponse>('/groups/' + groupId + '/discussions', { params: options }),
+  get: (groupId: number, discussionId: number) =>
+    api.get<DiscussionDetail>('/groups/' + groupId + '/discussions/' + discussionId),
+  create: (groupId: number, title: string, content: string) =>
+    api.post<Discussion>('/groups/' + groupId + '/discussions', { title, content }),
+  update: (groupId: number, discussionId: number, title: string, content: string) =>
+    api.put<Discussion>('/groups/' + groupId + '/discussions/' + discussionId, { title, content }),
+  delete: (groupId: number, discussionId: number) =>
+    api.delete('/groups/' + groupId + '/discussions/' + discussionId),
+  setMuted: (groupId: number, discussionId: number, muted: boolean) =>
+    api.put<{ muted: boolean }>('/groups/' + groupId + '/discussions/' + discussionId + '/mute', { muted }),
+  reply: (groupId: number, discussionId: number, content: string, parentId?: number) =>
+    api.post<DiscussionReply>('/groups/' + groupId + '/discussions/' + discussionId + '/replies', { content, parent_id: parentId }),
+  updateReply: (groupId: number, discussionId: number, replyId: number, content: string) =>
+    api.put<DiscussionReply>('/groups/' + groupId + '/discussions/' + discussionId + '/replies/' + replyId, { content }),
+  deleteReply: (groupId: number, discussionId: number, replyId: number) =>
+    api.delete('/groups/' + groupId + '/discussions/' + discussionId + '/replies/' + replyId),
+};
+
+// Foster availability. Members keep their own profile per group; group
+// admins list the fosters available for an animal and dates.
+export const fostersApi = {
+  getMine: (groupId: number) => api.get<FosterProfile>('/groups/' + groupId + '/foster-profile'),
+  updateMine: (groupId: number, profile: FosterProfileInput) =>
+    api.put<FosterProfile>('/groups/' + groupId + '/foster-profile', profile),
+  match: (groupId: number, options?: { animal_id?: number; from?: string; to?: string; all?: boolean }) =>
+    api.get<FosterMatches>('/groups/' + groupId + '/fosters', { params: options }),
+};
+
+// Onboarding checklists for new group members. Group admins manage the items,
+// check off confirmed items for members, and see the report.
+export const onboardingApi = {
+  getMine: (groupId: number) => api.get<OnboardingChecklist>('/groups/' + groupId + '/onboarding'),
+  getReport: (groupId: number, status?: 'complete' | 'incomplete') =>
+    api.get<OnboardingReport>('/groups/' + groupId + '/onboarding/report', { params: status ? { status } : undefined }),
+  getItems: (groupId: number) => api.get<{ items: OnboardingItem[] }>('/groups/' + groupId + '/onboarding/items'),
+  createItem: (groupId: number, item: OnboardingItemInput) =>
+    api.post<OnboardingItem>('/groups/' + groupId + '/onboarding/items', item),
+  updateItem: (groupId: number, itemId: number, item: OnboardingItemInput) =>
+    api.put<OnboardingItem>('/groups/' + groupId + '/onboarding/items/' + itemId, item),
+  deleteItem: (groupId: number, itemId: number) => api.delete('/groups/' + groupId + '/onboarding/items/' + itemId),
+  // userId is for group admins checking off another member's item
+  complete: (groupId: number, itemId: number, userId?: number) =>
+    api.post<OnboardingItemProgress>('/groups/' + groupId + '/onboarding/items/' + itemId + '/complete', userId ? { user_id: userId } : {}),
+  uncomplete: (groupId: number, itemId: number, userId?: number) =>
+    api.delete<OnboardingItemProgress>('/groups/' + groupId + '/onboarding/items/' + itemId + '/complete', userId ? { data: { user_id: userId } } : undefined),
+};
+
+// Announcements API. getAll's X-Unread-Count response header holds how many
+// live announcements the user hasn't read.
 export const announcementsApi = {
   getAll: () => api.get<Announcement[]>('/announcements'),
-  create: (title: string, content: string, send_email: boolean, send_groupme: boolean) =>
-    api.post<Announcement>('/admin/announcements', { title, content, send_email, send_groupme }),
+  markRead: (id: number) => api.post<AnnouncementRead>('/announcements/' + id + '/read'),
+  getReads: (id: number) => api.get<AnnouncementReadReport>('/admin/announcements/' + id + '/reads'),
+  getStats: (id: number) => api.get<AnnouncementEmailStats>('/admin/announcements/' + id + '/stats'),
+  create: (title: string, content: string, send_email: boolean, send_groupme: boolean, group_ids?: number[]) =>
+    api.post<Announcement>('/announcements', { title, content, send_email, send_groupme, group_ids }),
   delete: (id: number) => api.delete('/admin/announcements/' + id),
 };
 
+// Emergency SMS broadcasts to users who opted in. Group admins send to their
+// group; site admins can also send to everyone (groupId omitted).
+export const emergencyBroadcastsApi = {
+  send: (message: string, groupId?: number) =>
+    api.post<EmergencyBroadcast>(groupId ? `/groups/${groupId}/emergency-broadcasts` : '/admin/emergency-broadcasts', { message }),
+  list: (groupId?: number) =>
+    api.get<EmergencyBroadcast[]>(groupId ? `/groups/${groupId}/emergency-broadcasts` : '/admin/emergency-broadcasts'),
+  get: (broadcastId: number) => api.get<EmergencyBroadcast>(`/emergency-broadcasts/${broadcastId}`),
+};
+
 // Site Settings API
 export const settingsApi = {
-  getAll: () => api.get<Record<string, string>>('/settings'),
+  // With an organization slug, that organization's overrides replace the site's
+  getAll: (organization?: string) =>
+    api.get<Record<string, string>>('/settings', { params: organization ? { organization } : undefined }),
   update: (key: string, value: string) => api.put('/admin/settings/' + key, { value }),
   uploadHeroImage: (file: File) => {
     const formData = new FormData();
@@ -856,6 +2402,115 @@ export const settingsApi = {
   },
 };
 
+// AlertConfig is the effective operational alert thresholds; sources says
+// whether each came from an env variable, a site setting, or the default
+export interface AlertConfig {
+  error_threshold: number;
+  error_window_minutes: number;
+  job_backlog_threshold: number;
+  cooldown_minutes: number;
+  email_admins: boolean;
+  sources: Record<string, 'env' | 'setting' | 'default'>;
+}
+
+// Operational alerts to Slack, a webhook, or email (admin only). Thresholds
+// are updated through settingsApi.update with the alert_* keys.
+export const alertsApi = {
+  getConfig: () => api.get<{ config: AlertConfig; sinks: string[] }>('/admin/alerts/config'),
+  sendTest: () => api.post<{ results: Record<string, string> }>('/admin/alerts/test'),
+};
+
+export type EmailTemplateType = 'invitation' | 'password_reset' | 'announcement';
+
+// EmailTemplateVersion is one saved version of an admin's replacement for a built-in email
+export interface EmailTemplateVersion {
+  id: number;
+  created_at: string;
+  type: EmailTemplateType;
+  version: number;
+  subject: string;
+  body: string;
+  active: boolean;
+  created_by_id: number;
+  created_by?: { id: number; username: string };
+}
+
+export interface EmailTemplate {
+  type: EmailTemplateType;
+  description: string;
+  variables: { name: string; description: string; example: string }[]; // Used as {{.Name}}
+  default_subject: string;
+  default_body: string;
+  customized: boolean; // An admin's version is sent instead of the built-in
+  current?: EmailTemplateVersion;
+}
+
+// Admin only
+export const emailTemplatesApi = {
+  list: () => api.get<EmailTemplate[]>('/admin/email-templates'),
+  get: (type: EmailTemplateType) => api.get<EmailTemplate>(`/admin/email-templates/${type}`),
+  // Saves a new version and starts sending it
+  save: (type: EmailTemplateType, subject: string, body: string) =>
+    api.put<EmailTemplateVersion>(`/admin/email-templates/${type}`, { subject, body }),
+  // Goes back to the built-in email; saved versions are kept
+  reset: (type: EmailTemplateType) => api.delete(`/admin/email-templates/${type}`),
+  versions: (type: EmailTemplateType) => api.get<EmailTemplateVersion[]>(`/admin/email-templates/${type}/versions`),
+  restore: (type: EmailTemplateType, version: number) =>
+    api.post<EmailTemplateVersion>(`/admin/email-templates/${type}/versions/${version}/restore`),
+  // Without subject and body, previews or sends the version in use
+  preview: (type: EmailTemplateType, draft?: { subject: string; body: string }) =>
+    api.post<{ subject: string; html: string }>(`/admin/email-templates/${type}/preview`, draft ?? {}),
+  sendTest: (type: EmailTemplateType, draft?: { subject: string; body: string }) =>
+    api.post<{ message: string }>(`/admin/email-templates/${type}/test`, draft ?? {}),
+};
+
+// Organizations: tenants above groups, each with its own groups, users,
+// and site setting overrides
+export interface Organization {
+  id: number;
+  name: string;
+  slug: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface OrganizationSummary extends Organization {
+  group_count: number;
+  user_count: number;
+}
+
+export interface OrganizationDetail extends Organization {
+  groups: Group[];
+  settings: Record<string, string>; // Overrides of site_name, site_short_name, site_description, hero_image_url
+}
+
+export const organizationsApi = {
+  // The current user's organization; 404 outside any organization
+  getMine: () => api.get<OrganizationDetail>('/organization'),
+  // Organization admins only
+  getMyUsers: () => api.get<User[]>('/organization/users'),
+  updateMySetting: (key: string, value: string) => api.put('/organization/settings/' + key, { value }),
+  resetMySetting: (key: string) => api.delete('/organization/settings/' + key),
+  // Site admins only
+  list: () => api.get<OrganizationSummary[]>('/admin/organizations'),
+  create: (name: string, slug: string) => api.post<Organization>('/admin/organizations', { name, slug }),
+  update: (id: number, name: string, slug: string) => api.put<Organization>('/admin/organizations/' + id, { name, slug }),
+  delete: (id: number) => api.delete('/admin/organizations/' + id),
+  updateSetting: (id: number, key: string, value: string) =>
+    api.put('/admin/organizations/' + id + '/settings/' + key, { value }),
+  resetSetting: (id: number, key: string) => api.delete('/admin/organizations/' + id + '/settings/' + key),
+  // null moves the group or user out of all organizations
+  assignGroup: (groupId: number, organizationId: number | null) =>
+    api.put<Group>('/admin/groups/' + groupId + '/organization', { organization_id: organizationId }),
+  assignUser: (userId: number, organizationId: number | null, isOrgAdmin = false) =>
+    api.put<User>('/admin/users/' + userId + '/organization', { organization_id: organizationId, is_org_admin: isOrgAdmin }),
+};
+
+// Public animal share pages (no auth required)
+export const shareApi = {
+  getSharedAnimal: (token: string) => api.get<SharedAnimal>('/share/' + encodeURIComponent(token)),
+};
+
 // Statistics API
 // TODO: Implement proper pagination in admin UI; limit=100 is a temporary workaround
 export const statisticsApi = {
@@ -918,9 +2573,30 @@ export interface SystemHealthInfo {
   average_comments_per_day: number;
 }
 
+// IntakeOutcomeCounts are a period's intakes and outcomes. live_release_rate
+// is live outcomes / (live outcomes + euthanasias), or null without either.
+export interface IntakeOutcomeCounts {
+  intakes: number;
+  intakes_by_source: Record<string, number>; // 'unknown' for animals without a source
+  outcomes: number;
+  outcomes_by_type: Record<string, number>;
+  live_outcomes: number;
+  live_release_rate: number | null;
+}
+
+export interface IntakeOutcomeStats {
+  from: string; // YYYY-MM
+  to: string; // YYYY-MM
+  group_id?: number;
+  months: Array<IntakeOutcomeCounts & { month: string }>;
+  totals: IntakeOutcomeCounts;
+}
+
 // Admin Dashboard API
 export const adminDashboardApi = {
   getStats: () => api.get<AdminDashboardStats>('/admin/dashboard/stats'),
+  getIntakeOutcomeStats: (params?: { from?: string; to?: string; group_id?: number }) =>
+    api.get<IntakeOutcomeStats>('/admin/stats/intake-outcome', { params }),
 };
 
 export interface GroupDocument {
@@ -984,6 +2660,142 @@ export const searchApi = {
     params: { q: string; type?: 'all' | 'animals' | 'comments' | 'updates'; limit?: number; offset?: number },
     options?: { signal?: AbortSignal }
   ) => api.get<SearchResponse>(`/groups/${groupId}/search`, { params, signal: options?.signal }),
+  comments: (
+    groupId: number,
+    params: CommentSearchParams,
+    options?: { signal?: AbortSignal }
+  ) => api.get<CommentSearchResponse>(`/groups/${groupId}/comments/search`, { params, signal: options?.signal }),
+};
+
+// Comment search within a group, newest first. Snippets are plain text
+// segments; render highlighted ones as <mark>, never as HTML.
+export interface SnippetSegment {
+  text: string;
+  highlight?: boolean;
+}
+
+export interface CommentSearchHit {
+  comment_id: number;
+  animal_id: number;
+  animal_name: string;
+  user_id: number;
+  username: string;
+  created_at: string;
+  tags: string[];
+  snippet: SnippetSegment[];
+}
+
+export interface CommentSearchParams {
+  q: string;
+  animal_id?: number;
+  author?: string;
+  tag?: string; // Comma-separated comment tag names
+  from?: string; // YYYY-MM-DD
+  to?: string; // YYYY-MM-DD, inclusive
+  limit?: number;
+  offset?: number;
+}
+
+export interface CommentSearchResponse {
+  results: CommentSearchHit[];
+  total: number;
+  limit: number;
+  offset: number;
+  hasMore: boolean;
+}
+
+// DataExport is a CSV export built in the background; poll get() until
+// download_url is set
+export interface DataExport {
+  id: number;
+  user_id: number;
+  kind: 'animals' | 'comments';
+  group_id: number | null;
+  animal_id: number | null;
+  tags: string;
+  status: 'pending' | 'running' | 'succeeded' | 'failed';
+  rows_total: number;
+  rows_done: number;
+  progress: number;
+  error?: string;
+  file_name: string;
+  file_size: number;
+  download_url?: string;
+  completed_at: string | null;
+  expires_at: string | null;
+  created_at: string;
+}
+
+export interface DataExportRequest {
+  kind: 'animals' | 'comments';
+  group_id?: number;
+  animal_id?: number;
+  tags?: string;
+}
+
+export const dataExportsApi = {
+  create: (data: DataExportRequest) =>
+    api.post<DataExport>('/admin/exports', data),
+  createForGroup: (groupId: number, data: Omit<DataExportRequest, 'group_id'>) =>
+    api.post<DataExport>(`/groups/${groupId}/animals/exports`, data),
+  get: (id: number) =>
+    api.get<DataExport>(`/exports/${id}`),
+  download: (id: number) =>
+    api.get(`/exports/${id}/download`, { responseType: 'blob' }),
+};
+
+export type RetentionEntity = 'deleted_users' | 'comments' | 'stale_uploads';
+
+export interface RetentionRule {
+  entity: RetentionEntity;
+  action: 'anonymize' | 'delete';
+  days: number;
+  env_var: string;
+}
+
+export interface RetentionResult {
+  entity: RetentionEntity;
+  action: 'anonymize' | 'delete';
+  days: number;
+  cutoff?: string;
+  matched: number;
+  purged: number;
+  error?: string;
+}
+
+export const retentionApi = {
+  get: () =>
+    api.get<{ policy: { rules: RetentionRule[]; dry_run: boolean }; report: RetentionResult[] }>('/admin/retention'),
+  run: (dryRun: boolean) =>
+    api.post<{ dry_run: boolean; results: RetentionResult[] }>('/admin/retention/run', { dry_run: dryRun }),
+};
+
+// OrphanedUpload is a file in the uploads directory that nothing references
+export interface OrphanedUpload {
+  name: string;
+  url: string;
+  size: number;
+  modified_at: string;
+}
+
+export const orphanedUploadsApi = {
+  list: () =>
+    api.get<{ config: { dir: string; grace_days: number; auto_purge: boolean }; files: OrphanedUpload[]; total_bytes: number }>('/admin/orphaned-uploads'),
+  // Purges every orphan, or only the named ones
+  purge: (files?: string[]) =>
+    api.post<{ deleted: string[]; freed_bytes: number; failed?: string[] }>('/admin/orphaned-uploads/purge', files ? { files } : {}),
+};
+
+// GraphQLResponse is a read-only GraphQL query's result. Errors come back
+// with status 200 next to whatever data could be resolved.
+export interface GraphQLResponse<T> {
+  data: T | null;
+  errors?: { message: string; path?: (string | number)[] }[];
+}
+
+export const graphqlApi = {
+  query: <T>(query: string, variables?: Record<string, unknown>) =>
+    api.post<GraphQLResponse<T>>('/graphql', { query, variables }),
 };
 
 export default api;
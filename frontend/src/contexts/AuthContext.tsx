@@ -17,6 +17,10 @@ interface AuthContextType {
 
 const AuthContext = createContext<AuthContextType | undefined>(undefined);
 
+// With cookie sessions the JWT is in an HttpOnly cookie, so `token` holds
+// this marker instead, and only the marker is kept in storage.
+const COOKIE_SESSION = 'cookie';
+
 // Export the context so it can be used by the hook in a separate file
 export { AuthContext };
 
@@ -24,7 +28,7 @@ export const AuthProvider: React.FC<{ children: ReactNode }> = ({ children }) =>
   const [user, setUser] = useState<User | null>(null);
   const [token, setToken] = useState<string | null>(() => {
     try {
-      return localStorage.getItem('token');
+      return localStorage.getItem('token') ?? (localStorage.getItem('session') === COOKIE_SESSION ? COOKIE_SESSION : null);
     } catch {
       return null;
     }
@@ -53,6 +57,7 @@ export const AuthProvider: React.FC<{ children: ReactNode }> = ({ children }) =>
       .catch(() => {
         try {
           localStorage.removeItem('token');
+          localStorage.removeItem('session');
         } catch {
           // ignore storage errors
         }
@@ -66,9 +71,14 @@ export const AuthProvider: React.FC<{ children: ReactNode }> = ({ children }) =>
 
   const login = async (username: string, password: string) => {
     const response = await authApi.login(username, password);
-    setToken(response.data.token);
+    const sessionToken = response.data.token ?? COOKIE_SESSION;
+    setToken(sessionToken);
     try {
-      localStorage.setItem('token', response.data.token);
+      if (response.data.token) {
+        localStorage.setItem('token', response.data.token);
+      } else {
+        localStorage.setItem('session', COOKIE_SESSION);
+      }
     } catch {
       // ignore storage errors
     }
@@ -95,10 +105,16 @@ export const AuthProvider: React.FC<{ children: ReactNode }> = ({ children }) =>
   };
 
   const logout = () => {
+    if (token === COOKIE_SESSION) {
+      authApi.logout().catch(() => {
+        // the cookies expire with the session anyway
+      });
+    }
     setToken(null);
     setUser(null);
     try {
       localStorage.removeItem('token');
+      localStorage.removeItem('session');
     } catch {
       // ignore storage errors
     }
@@ -581,6 +581,25 @@ const AnimalDetailPage: React.FC = () => {
     }
   };
 
+  const handlePrintKennelCard = async () => {
+    try {
+      const response = await animalsApi.getKennelCard(Number(groupId), Number(id));
+      const url = window.URL.createObjectURL(new Blob([response.data], { type: 'application/pdf' }));
+      const link = document.createElement('a');
+      link.href = url;
+      link.target = '_blank';
+      link.rel = 'noopener';
+      document.body.appendChild(link);
+      link.click();
+      link.remove();
+      // Give the new tab time to load the PDF before releasing it
+      setTimeout(() => window.URL.revokeObjectURL(url), 60000);
+    } catch (error) {
+      console.error('Failed to generate kennel card:', error);
+      toast.showError('Failed to generate kennel card. Please try again.');
+    }
+  };
+
   const handleOpenProtocolDocument = () => {
     if (!animal?.protocol_document_url) return;
     setShowProtocolModal(true);
@@ -921,6 +940,14 @@ const AnimalDetailPage: React.FC = () => {
                 >
                   📷 Photo Gallery
                 </Link>
+
+                <button
+                  onClick={handlePrintKennelCard}
+                  className="btn-print-card"
+                  title="Open a printable kennel card PDF"
+                >
+                  🖨️ Print Kennel Card
+                </button>
                 
                 {(isAdmin || membership?.is_group_admin) && (
                   <button onClick={handleEdit} className="btn-edit">
@@ -1080,6 +1107,7 @@ const AnimalDetailPage: React.FC = () => {
               </button>
               {availableTags.map((tag) => {
                 const isActive = filterTags.includes(tag.name);
+                const tagCount = animal?.comment_tag_counts?.find(tc => tc.tag_id === tag.id)?.count;
                 return (
                   <button
                     key={tag.id}
@@ -1101,7 +1129,7 @@ const AnimalDetailPage: React.FC = () => {
                     aria-label={`Filter by ${tag.name}`}
                     aria-pressed={isActive}
                   >
-                    {tag.name}
+                    {tag.name}{tagCount ? ` (${tagCount})` : ''}
                   </button>
                 );
               })}
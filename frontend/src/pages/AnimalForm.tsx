@@ -1,7 +1,8 @@
 import React, { useEffect, useState, useCallback } from 'react';
+import { isAxiosError } from 'axios';
 import { useParams, useNavigate, Link } from 'react-router-dom';
 import { animalsApi, animalTagsApi, commentTagsApi, animalCommentsApi } from '../api/client';
-import type { AnimalTag, Animal, DuplicateNameInfo, AnimalImage } from '../api/client';
+import type { AnimalTag, Animal, DuplicateNameInfo, AnimalImage, PossibleDuplicateError } from '../api/client';
 import { useToast } from '../hooks/useToast';
 import { calculateQuarantineEndDateISO, calculateAge, computeEstimatedBirthDate, formatCalendarDate } from '../utils/dateUtils';
 import { formatAnimalStatus } from '../utils/animalUtils';
@@ -17,6 +18,26 @@ import './Form.css';
 // Shared by every save path (normal save, quarantine-entry modal, BQ-exit modal) so
 // the same animal edit always resolves birth date the same way regardless of which
 // path it's saved through.
+// Creates an animal, and if the server reports likely duplicates, asks the user
+// whether to create it anyway. Returns null when the user backs out.
+async function createAnimalConfirmingDuplicates(groupId: number, data: Partial<Animal>): Promise<Animal | null> {
+  try {
+    return (await animalsApi.create(groupId, data)).data;
+  } catch (error) {
+    const body = isAxiosError(error) ? (error.response?.data as PossibleDuplicateError | undefined) : undefined;
+    if (!isAxiosError(error) || error.response?.status !== 409 || body?.code !== 'POSSIBLE_DUPLICATE') {
+      throw error;
+    }
+    const matches = body.candidates
+      .map(a => `• ${a.name}${a.breed ? ` (${a.breed})` : ''} — ${formatAnimalStatus(a.status)}`)
+      .join('\n');
+    if (!window.confirm(`This animal may already exist:\n\n${matches}\n\nCreate it anyway?`)) {
+      return null;
+    }
+    return (await animalsApi.create(groupId, data, true)).data;
+  }
+}
+
 function resolveFinalBirthDate(estimatedBirthDate: string, birthYears: number, birthMonths: number): string | undefined {
   return estimatedBirthDate ||
     (birthYears > 0 || birthMonths > 0 ? computeEstimatedBirthDate(birthYears, birthMonths) : undefined);
@@ -574,8 +595,11 @@ const AnimalForm: React.FC = () => {
         await animalsApi.update(parseInt(groupId), parseInt(id), cleanedFormData);
         toast.showSuccess('Animal updated successfully!');
       } else if (groupId) {
-        const response = await animalsApi.create(parseInt(groupId), cleanedFormData);
-        animalId = response.data.id;
+        const created = await createAnimalConfirmingDuplicates(parseInt(groupId), cleanedFormData);
+        if (!created) {
+          return;
+        }
+        animalId = created.id;
         toast.showSuccess('Animal added successfully!');
         
         // Upload protocol document if one was selected for new animal
@@ -646,8 +670,11 @@ const AnimalForm: React.FC = () => {
         const response = await animalsApi.update(parseInt(groupId), parseInt(id), updatedFormData);
         animalId = response.data.id;
       } else if (groupId) {
-        const response = await animalsApi.create(parseInt(groupId), updatedFormData);
-        animalId = response.data.id;
+        const created = await createAnimalConfirmingDuplicates(parseInt(groupId), updatedFormData);
+        if (!created) {
+          return;
+        }
+        animalId = created.id;
       }
 
       const normalisedQuarantineDate = /^\d{4}-\d{2}-\d{2}$/.test(quarantineDate)
@@ -28,6 +28,7 @@ const BulkEditAnimalsPage: React.FC = () => {
   const [bulkStatus, setBulkStatus] = useState<string>('');
   const [showImportModal, setShowImportModal] = useState(false);
   const [importFile, setImportFile] = useState<File | null>(null);
+  const [importUpsert, setImportUpsert] = useState(false);
   const [importResult, setImportResult] = useState<{ message: string; warnings?: string[] } | null>(null);
   const [updatingAnimal, setUpdatingAnimal] = useState<number | null>(null);
   const [viewMode, setViewMode] = useState<'cards' | 'table'>('cards');
@@ -233,7 +234,7 @@ const BulkEditAnimalsPage: React.FC = () => {
     }
 
     try {
-      const response = await animalsApi.importCSV(importFile);
+      const response = await animalsApi.importCSV(importFile, importUpsert ? 'upsert' : 'insert');
       setImportResult(response.data);
       setImportFile(null);
       loadData();
@@ -838,11 +839,19 @@ const BulkEditAnimalsPage: React.FC = () => {
               Upload a CSV file with the following columns:
             </p>
             <code className="csv-format">
-              group_id, name, species, breed, age, estimated_birth_date, description, trainer_notes, status, image_url
+              group_id, name, external_id, species, breed, age, estimated_birth_date, description, trainer_notes, status, image_url
             </code>
             <div className="import-note">
               <strong>Note:</strong> Only <code>group_id</code> and <code>name</code> are required.
             </div>
+            <label className="import-option">
+              <input
+                type="checkbox"
+                checked={importUpsert}
+                onChange={(e) => setImportUpsert(e.target.checked)}
+              />
+              Update existing animals (matched by <code>external_id</code>, or by name) instead of creating duplicates
+            </label>
 
             <div className="file-upload-area">
               <input
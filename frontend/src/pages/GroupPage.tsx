@@ -857,6 +857,14 @@ const GroupPage: React.FC = () => {
 
                     {activity.type === 'comment' ? (
                       <SessionCommentDisplay comment={activity as any} />
+                    ) : activity.type === 'animal_change' ? (
+                      <ul className="activity-changes">
+                        {activity.changes?.map((change) => (
+                          <li key={change.field}>
+                            <strong>{change.field.replace(/_/g, ' ')}</strong>: {change.old || '(empty)'} → {change.new || '(empty)'}
+                          </li>
+                        ))}
+                      </ul>
                     ) : (
                       <p className="activity-text">{activity.content}</p>
                     )}
@@ -1,7 +1,7 @@
 import React from 'react';
 import { Link } from 'react-router-dom';
 import './GroupsPage.css';
-import type { Group, Animal, GroupStatistics } from '../api/client';
+import type { Group, Animal, GroupStatistics, DeleteConfirmation, GroupDeletionImpact } from '../api/client';
 import { groupsApi, animalsApi, statisticsApi } from '../api/client';
 import { useToast } from '../hooks/useToast';
 import { formatRelativeTime } from '../utils/dateUtils';
@@ -34,7 +34,8 @@ const GroupsPage: React.FC = () => {
     hero_image_url: '',
     has_protocols: false,
     groupme_bot_id: '',
-    groupme_enabled: false
+    groupme_enabled: false,
+    public_sharing: false
   });
   const [modalLoading, setModalLoading] = React.useState(false);
   const [modalError, setModalError] = React.useState<string | null>(null);
@@ -138,7 +139,7 @@ const GroupsPage: React.FC = () => {
   // Open modal for creating a new group
   const openCreateModal = () => {
     setEditingGroup(null);
-    setModalData({ name: '', description: '', image_url: '', hero_image_url: '', has_protocols: false, groupme_bot_id: '', groupme_enabled: false });
+    setModalData({ name: '', description: '', image_url: '', hero_image_url: '', has_protocols: false, groupme_bot_id: '', groupme_enabled: false, public_sharing: false });
     setModalError(null);
     setShowModal(true);
   };
@@ -153,7 +154,8 @@ const GroupsPage: React.FC = () => {
       hero_image_url: group.hero_image_url || '',
       has_protocols: group.has_protocols || false,
       groupme_bot_id: group.groupme_bot_id || '',
-      groupme_enabled: group.groupme_enabled || false
+      groupme_enabled: group.groupme_enabled || false,
+      public_sharing: group.public_sharing || false
     });
     setModalError(null);
     setShowModal(true);
@@ -172,7 +174,7 @@ const GroupsPage: React.FC = () => {
   const closeModal = () => {
     setShowModal(false);
     setEditingGroup(null);
-    setModalData({ name: '', description: '', image_url: '', hero_image_url: '', has_protocols: false, groupme_bot_id: '', groupme_enabled: false });
+    setModalData({ name: '', description: '', image_url: '', hero_image_url: '', has_protocols: false, groupme_bot_id: '', groupme_enabled: false, public_sharing: false });
     setModalError(null);
     setAvailableAnimals([]);
     setShowAnimalSelector(false);
@@ -201,7 +203,8 @@ const GroupsPage: React.FC = () => {
           modalData.hero_image_url,
           modalData.has_protocols,
           modalData.groupme_bot_id,
-          modalData.groupme_enabled
+          modalData.groupme_enabled,
+          modalData.public_sharing
         );
       } else {
         // Create new group
@@ -212,7 +215,8 @@ const GroupsPage: React.FC = () => {
           modalData.hero_image_url,
           modalData.has_protocols,
           modalData.groupme_bot_id,
-          modalData.groupme_enabled
+          modalData.groupme_enabled,
+          modalData.public_sharing
         );
       }
       fetchGroups();
@@ -224,14 +228,29 @@ const GroupsPage: React.FC = () => {
     }
   };
 
-  // Delete group
-  const handleDelete = (group: Group) => {
+  // Delete group: the first call returns the impact and a confirmation token
+  const handleDelete = async (group: Group) => {
+    let confirmation: DeleteConfirmation<GroupDeletionImpact>;
+    try {
+      await groupsApi.delete(group.id);
+      fetchGroups();
+      return;
+    } catch (err: unknown) {
+      const response = (err as { response?: { status?: number; data?: DeleteConfirmation<GroupDeletionImpact> & { error?: string } } }).response;
+      if (response?.status !== 428 || !response.data) {
+        setError(response?.data?.error || 'Failed to delete group');
+        return;
+      }
+      confirmation = response.data;
+    }
+
+    const { member_count, animal_count, protocol_count } = confirmation.impact;
     openConfirmDialog(
       'Delete Group',
-      `Delete group "${group.name}"? This cannot be undone and will affect all associated data.`,
+      `Delete group "${group.name}"? It has ${member_count} member(s), ${animal_count} animal(s), and ${protocol_count} protocol(s). This cannot be undone.`,
       async () => {
         try {
-          await groupsApi.delete(group.id);
+          await groupsApi.delete(group.id, confirmation.confirmation_token);
           fetchGroups();
         } catch (err: unknown) {
           setError((err as { response?: { data?: { error?: string } } }).response?.data?.error || 'Failed to delete group');
@@ -488,6 +507,21 @@ const GroupsPage: React.FC = () => {
               Protocols allow you to document standardized procedures and workflows for this group.
             </small>
           </div>
+          <div className="form-group">
+            <label htmlFor="public_sharing" className="group-modal-checkbox-label">
+              <input
+                id="public_sharing"
+                name="public_sharing"
+                type="checkbox"
+                checked={modalData.public_sharing}
+                onChange={(e) => setModalData(d => ({ ...d, public_sharing: e.target.checked }))}
+              />
+              <span>Allow public share links</span>
+            </label>
+            <small className="group-modal-hint">
+              Group admins can create no-login links and QR codes (e.g. for kennel cards) showing an animal's name, photo, description, and status. Turning this off disables every existing link.
+            </small>
+          </div>
 
           {/* GroupMe Integration Section */}
           <div className="form-group group-modal-section-divider">
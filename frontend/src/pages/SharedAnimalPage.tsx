@@ -0,0 +1,55 @@
+import React, { useEffect, useState } from 'react';
+import { useParams } from 'react-router-dom';
+import { shareApi } from '../api/client';
+import type { SharedAnimal } from '../api/client';
+import LoadingSpinner from '../components/LoadingSpinner';
+import './SharedAnimalPage.css';
+
+// SharedAnimalPage is the public, no-login page a kennel card QR code opens.
+// It is reachable whether or not the visitor is signed in.
+const SharedAnimalPage: React.FC = () => {
+  const { token } = useParams<{ token: string }>();
+  const [animal, setAnimal] = useState<SharedAnimal | null>(null);
+  const [loading, setLoading] = useState(true);
+  const [notFound, setNotFound] = useState(false);
+
+  useEffect(() => {
+    if (!token) return;
+    shareApi.getSharedAnimal(token)
+      .then((res) => setAnimal(res.data))
+      .catch(() => setNotFound(true))
+      .finally(() => setLoading(false));
+  }, [token]);
+
+  if (loading) return <LoadingSpinner label="Loading" />;
+
+  if (notFound || !animal) {
+    return (
+      <div className="shared-animal-container">
+        <div className="shared-animal-card">
+          <h1>Link unavailable</h1>
+          <p className="shared-animal-muted">This share link has expired or been turned off.</p>
+        </div>
+      </div>
+    );
+  }
+
+  return (
+    <div className="shared-animal-container">
+      <article className="shared-animal-card">
+        {animal.image_url && (
+          <img className="shared-animal-photo" src={animal.image_url} alt={animal.name} />
+        )}
+        <h1>{animal.name}</h1>
+        <p className="shared-animal-muted">
+          {[animal.species, animal.breed].filter(Boolean).join(' · ')}
+        </p>
+        <span className="shared-animal-status">{animal.status_label}</span>
+        {animal.description && <p className="shared-animal-description">{animal.description}</p>}
+        <p className="shared-animal-muted shared-animal-footer">{animal.group_name}</p>
+      </article>
+    </div>
+  );
+};
+
+export default SharedAnimalPage;
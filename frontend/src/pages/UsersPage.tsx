@@ -1397,6 +1397,14 @@ const UsersPage: React.FC = () => {
                         {user.is_admin && <span className="badge badge-admin">Admin</span>}
                         {user.deleted_at && <span className="badge badge-deleted">Deleted</span>}
                         {canManageUsers && isUserLocked(user) && <span className="badge badge-locked">Locked</span>}
+                        {user.email_undeliverable && (
+                          <span
+                            className="badge badge-undeliverable"
+                            title={user.email_undeliverable_detail || 'The email provider reported this address as undeliverable'}
+                          >
+                            {user.email_undeliverable_reason === 'complaint' ? 'Marked email as spam' : 'Email bouncing'}
+                          </span>
+                        )}
                       </div>
                       {(user.first_name || user.last_name) && (
                         <div className="user-fullname">
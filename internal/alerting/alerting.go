@@ -0,0 +1,271 @@
+// Package alerting tells operators about failures no volunteer will report:
+// a spike in server errors, background jobs that run out of retries, and a
+// job queue falling behind. Alerts go to every configured Sink (a Slack
+// webhook, a generic webhook, email), and repeats of the same alert are
+// held back for a cooldown so an outage doesn't flood the channel.
+package alerting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// Severity is how urgently an alert needs attention
+type Severity string
+
+const (
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+const (
+	// sendTimeout bounds delivering one alert to one sink.
+	sendTimeout = 10 * time.Second
+
+	// jobOverdueAfter is how long past its run_at a pending job must be to
+	// count toward the backlog threshold.
+	jobOverdueAfter = 10 * time.Minute
+
+	// maxErrorLength truncates job errors quoted in an alert.
+	maxErrorLength = 500
+)
+
+// Alert is one operational event
+type Alert struct {
+	// Key identifies repeats of the same alert for the cooldown, e.g.
+	// "job_failed:email".
+	Key      string            `json:"key"`
+	Severity Severity          `json:"severity"`
+	Title    string            `json:"title"`
+	Message  string            `json:"message"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	Time     time.Time         `json:"time"`
+}
+
+// Sink delivers alerts to operators
+type Sink interface {
+	Send(ctx context.Context, alert Alert) error
+
+	// Name returns the name of the sink for logging
+	Name() string
+}
+
+// Alerter tracks error rates and sends alerts to its sinks. A nil Alerter,
+// or one without sinks, drops every alert.
+type Alerter struct {
+	config *ConfigStore
+	sinks  []Sink
+	now    func() time.Time
+
+	mu           sync.Mutex
+	lastSent     map[string]time.Time
+	serverErrors []time.Time
+}
+
+// NewAlerter returns an alerter that sends to sinks using the thresholds in
+// config.
+func NewAlerter(config *ConfigStore, sinks ...Sink) *Alerter {
+	return &Alerter{config: config, sinks: sinks, now: time.Now, lastSent: make(map[string]time.Time)}
+}
+
+// SinkNames returns the names of the configured sinks.
+func (a *Alerter) SinkNames() []string {
+	if a == nil {
+		return []string{}
+	}
+	names := make([]string, len(a.sinks))
+	for i, sink := range a.sinks {
+		names[i] = sink.Name()
+	}
+	return names
+}
+
+// Config returns the effective alerting configuration.
+func (a *Alerter) Config(ctx context.Context) Config {
+	if a == nil {
+		return resolveConfig(nil)
+	}
+	return a.config.Get(ctx)
+}
+
+// Notify sends alert to every sink unless an alert with the same key was
+// sent within the cooldown. Sink failures are logged and joined into the
+// returned error.
+func (a *Alerter) Notify(ctx context.Context, alert Alert) error {
+	if a == nil || len(a.sinks) == 0 {
+		return nil
+	}
+	now := a.now()
+	if alert.Time.IsZero() {
+		alert.Time = now
+	}
+	cooldown := a.config.Get(ctx).Cooldown()
+
+	a.mu.Lock()
+	if last, ok := a.lastSent[alert.Key]; ok && now.Sub(last) < cooldown {
+		a.mu.Unlock()
+		return nil
+	}
+	a.lastSent[alert.Key] = now
+	a.mu.Unlock()
+
+	var errs []error
+	for name, err := range a.deliver(ctx, alert) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SendTest sends a test alert to every sink, ignoring the cooldown, and
+// returns each sink's result keyed by name.
+func (a *Alerter) SendTest(ctx context.Context) map[string]error {
+	if a == nil {
+		return map[string]error{}
+	}
+	return a.deliver(ctx, Alert{
+		Key:      "test",
+		Severity: SeverityWarning,
+		Title:    "Test alert",
+		Message:  "Operational alerts are reaching this channel.",
+		Time:     a.now(),
+	})
+}
+
+func (a *Alerter) deliver(ctx context.Context, alert Alert) map[string]error {
+	results := make(map[string]error, len(a.sinks))
+	for _, sink := range a.sinks {
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err := sink.Send(sendCtx, alert)
+		cancel()
+		if err != nil {
+			logging.WithFields(map[string]interface{}{
+				"sink":      sink.Name(),
+				"alert_key": alert.Key,
+			}).Error("Failed to send alert", err)
+		}
+		results[sink.Name()] = err
+	}
+	return results
+}
+
+// RecordServerError counts a 5xx response toward the error-rate threshold
+// and alerts once the threshold is reached within the window. route is the
+// request's route pattern, quoted in the alert.
+func (a *Alerter) RecordServerError(ctx context.Context, route string) {
+	if a == nil || len(a.sinks) == 0 {
+		return
+	}
+	cfg := a.config.Get(ctx)
+	if cfg.ErrorThreshold <= 0 {
+		return
+	}
+	now := a.now()
+
+	a.mu.Lock()
+	cutoff := now.Add(-cfg.ErrorWindow())
+	kept := a.serverErrors[:0]
+	for _, t := range a.serverErrors {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	a.serverErrors = append(kept, now)
+	count := len(a.serverErrors)
+	if count >= cfg.ErrorThreshold {
+		// Start counting afresh, so the next alert needs a new spike
+		a.serverErrors = a.serverErrors[:0]
+	}
+	a.mu.Unlock()
+
+	if count < cfg.ErrorThreshold {
+		return
+	}
+	_ = a.Notify(ctx, Alert{
+		Key:      "server_errors",
+		Severity: SeverityCritical,
+		Title:    "Server error rate spike",
+		Message:  fmt.Sprintf("%d requests failed with a server error in the last %d minutes.", count, cfg.ErrorWindowMinutes),
+		Fields:   map[string]string{"last_route": route},
+	})
+}
+
+// JobFailed alerts that a background job ran out of retries. It matches
+// jobs.FailureHook.
+func (a *Alerter) JobFailed(ctx context.Context, job models.Job, err error) {
+	message := ""
+	if err != nil {
+		message = err.Error()
+		if len(message) > maxErrorLength {
+			message = message[:maxErrorLength] + "…"
+		}
+	}
+	_ = a.Notify(ctx, Alert{
+		Key:      "job_failed:" + job.Type,
+		Severity: SeverityWarning,
+		Title:    fmt.Sprintf("Background job %q failed", job.Type),
+		Message:  message,
+		Fields: map[string]string{
+			"job_id":   fmt.Sprint(job.ID),
+			"attempts": fmt.Sprint(job.Attempts),
+		},
+	})
+}
+
+// CheckJobBacklog alerts when at least the configured number of pending jobs
+// are more than jobOverdueAfter past their run time, which means workers are
+// stuck or can't keep up.
+func (a *Alerter) CheckJobBacklog(ctx context.Context, db *gorm.DB) error {
+	if a == nil || len(a.sinks) == 0 {
+		return nil
+	}
+	cfg := a.config.Get(ctx)
+	if cfg.JobBacklogThreshold <= 0 {
+		return nil
+	}
+	var overdue int64
+	if err := db.WithContext(ctx).Model(&models.Job{}).
+		Where("status = ? AND run_at < ?", models.JobStatusPending, a.now().Add(-jobOverdueAfter)).
+		Count(&overdue).Error; err != nil {
+		return err
+	}
+	if overdue < int64(cfg.JobBacklogThreshold) {
+		return nil
+	}
+	return a.Notify(ctx, Alert{
+		Key:      "job_backlog",
+		Severity: SeverityCritical,
+		Title:    "Background job backlog",
+		Message:  fmt.Sprintf("%d jobs are more than %d minutes overdue.", overdue, int(jobOverdueAfter.Minutes())),
+	})
+}
+
+// StartJobBacklogCheck runs CheckJobBacklog every interval in a background
+// goroutine. Returns a stop function; call it during graceful shutdown.
+func StartJobBacklogCheck(db *gorm.DB, alerter *Alerter, interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if err := alerter.CheckJobBacklog(context.Background(), db); err != nil {
+					logging.Error("Failed to check job backlog", err)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
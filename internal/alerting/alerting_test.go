@@ -0,0 +1,177 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type recordingSink struct {
+	alerts []Alert
+	err    error
+}
+
+func (s *recordingSink) Name() string { return "recording" }
+
+func (s *recordingSink) Send(_ context.Context, alert Alert) error {
+	s.alerts = append(s.alerts, alert)
+	return s.err
+}
+
+func newAlertingDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(&models.SiteSetting{}, &models.Job{}); err != nil {
+		t.Fatalf("failed to migrate test db: %v", err)
+	}
+	return db
+}
+
+func TestAlerter_CooldownAndErrorRate(t *testing.T) {
+	db := newAlertingDB(t)
+	db.Create(&models.SiteSetting{Key: SettingAlertErrorThreshold, Value: "3"})
+	sink := &recordingSink{}
+	alerter := NewAlerter(NewConfigStore(db), sink)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	alerter.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	alerter.JobFailed(ctx, models.Job{Type: "email"}, errors.New("provider down"))
+	alerter.JobFailed(ctx, models.Job{Type: "email"}, errors.New("provider down"))
+	alerter.JobFailed(ctx, models.Job{Type: "sms"}, errors.New("timeout"))
+	if len(sink.alerts) != 2 {
+		t.Fatalf("sent %d alerts, want 2: repeats within the cooldown are held back", len(sink.alerts))
+	}
+	now = now.Add(31 * time.Minute)
+	alerter.JobFailed(ctx, models.Job{Type: "email"}, errors.New("provider down"))
+	if len(sink.alerts) != 3 {
+		t.Fatalf("sent %d alerts, want 3 after the cooldown", len(sink.alerts))
+	}
+
+	alerter.RecordServerError(ctx, "/api/groups/:id")
+	now = now.Add(6 * time.Minute) // Outside the 5 minute window
+	alerter.RecordServerError(ctx, "/api/groups/:id")
+	alerter.RecordServerError(ctx, "/api/groups/:id")
+	if len(sink.alerts) != 3 {
+		t.Fatalf("errors outside the window counted toward the threshold")
+	}
+	alerter.RecordServerError(ctx, "/api/animals")
+	if len(sink.alerts) != 4 {
+		t.Fatalf("sent %d alerts, want an error-rate alert", len(sink.alerts))
+	}
+	got := sink.alerts[3]
+	if got.Key != "server_errors" || got.Severity != SeverityCritical || got.Fields["last_route"] != "/api/animals" {
+		t.Errorf("error-rate alert = %+v", got)
+	}
+}
+
+func TestAlerter_CheckJobBacklog(t *testing.T) {
+	db := newAlertingDB(t)
+	t.Setenv("ALERT_JOB_BACKLOG_THRESHOLD", "2")
+	sink := &recordingSink{}
+	alerter := NewAlerter(NewConfigStore(db), sink)
+
+	overdue := time.Now().Add(-time.Hour)
+	db.Create(&models.Job{Type: "email", Status: models.JobStatusPending, RunAt: overdue})
+	db.Create(&models.Job{Type: "email", Status: models.JobStatusFailed, RunAt: overdue})
+	db.Create(&models.Job{Type: "email", Status: models.JobStatusPending, RunAt: time.Now()})
+	if err := alerter.CheckJobBacklog(context.Background(), db); err != nil || len(sink.alerts) != 0 {
+		t.Fatalf("one overdue job alerted: %v, %d alerts", err, len(sink.alerts))
+	}
+
+	db.Create(&models.Job{Type: "sms", Status: models.JobStatusPending, RunAt: overdue})
+	if err := alerter.CheckJobBacklog(context.Background(), db); err != nil || len(sink.alerts) != 1 {
+		t.Fatalf("backlog of two didn't alert: %v, %d alerts", err, len(sink.alerts))
+	}
+	if !strings.HasPrefix(sink.alerts[0].Message, "2 jobs") {
+		t.Errorf("message = %q", sink.alerts[0].Message)
+	}
+}
+
+func TestSinks(t *testing.T) {
+	var bodies []string
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		auth = r.Header.Get("Authorization")
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	alert := Alert{Key: "k", Severity: SeverityCritical, Title: "Queue stuck", Message: "12 jobs overdue", Fields: map[string]string{"b": "2", "a": "1"}}
+	slack := &SlackSink{URL: server.URL + "/slack", client: server.Client()}
+	if err := slack.Send(context.Background(), alert); err != nil {
+		t.Fatalf("slack: %v", err)
+	}
+	var message map[string]string
+	json.Unmarshal([]byte(bodies[0]), &message)
+	if want := "*[CRITICAL] Queue stuck*\n12 jobs overdue\n• a: 1\n• b: 2"; message["text"] != want {
+		t.Errorf("slack text = %q, want %q", message["text"], want)
+	}
+
+	webhook := &WebhookSink{URL: server.URL + "/hook", Token: "secret", client: server.Client()}
+	if err := webhook.Send(context.Background(), alert); err != nil {
+		t.Fatalf("webhook: %v", err)
+	}
+	var sent Alert
+	json.Unmarshal([]byte(bodies[1]), &sent)
+	if sent.Key != "k" || sent.Fields["b"] != "2" || auth != "Bearer secret" {
+		t.Errorf("webhook sent %+v with auth %q", sent, auth)
+	}
+
+	broken := &WebhookSink{URL: server.URL + "/broken", client: server.Client()}
+	if err := broken.Send(context.Background(), alert); err == nil {
+		t.Error("a 500 from the webhook should be an error")
+	}
+}
+
+func TestSinksFromEnv_RejectsBadURLs(t *testing.T) {
+	t.Setenv("ALERT_SLACK_WEBHOOK_URL", "hooks.slack.com/services/x")
+	if _, err := SinksFromEnv(nil, nil, nil); err == nil {
+		t.Error("a URL without a scheme should be rejected")
+	}
+	t.Setenv("ALERT_SLACK_WEBHOOK_URL", "https://hooks.slack.com/services/x")
+	t.Setenv("ALERT_WEBHOOK_URL", "https://ops.example.org/alerts")
+	sinks, err := SinksFromEnv(nil, nil, nil)
+	if err != nil || len(sinks) != 2 {
+		t.Fatalf("SinksFromEnv = %d sinks, %v", len(sinks), err)
+	}
+}
+
+func TestValidateAlertSetting(t *testing.T) {
+	tests := []struct {
+		key, value string
+		valid      bool
+	}{
+		{SettingAlertErrorThreshold, "0", true},
+		{SettingAlertErrorThreshold, "-1", false},
+		{SettingAlertErrorWindow, "0", false},
+		{SettingAlertCooldown, "1440", true},
+		{SettingAlertEmailAdmins, "yes", false},
+		{SettingAlertEmailAdmins, "", true},
+		{"site_name", "x", false},
+	}
+	for _, tt := range tests {
+		if err := ValidateAlertSetting(tt.key, tt.value); (err == nil) != tt.valid {
+			t.Errorf("ValidateAlertSetting(%q, %q) = %v, want valid %v", tt.key, tt.value, err, tt.valid)
+		}
+	}
+}
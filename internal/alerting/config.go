@@ -0,0 +1,220 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// Site setting keys for alert thresholds. Each can be overridden by the
+// environment variable named in alertSettingEnv. Sink URLs and tokens are
+// secrets and are read from the environment only, since site settings are
+// public.
+const (
+	SettingAlertErrorThreshold      = "alert_error_threshold"
+	SettingAlertErrorWindow         = "alert_error_window_minutes"
+	SettingAlertJobBacklogThreshold = "alert_job_backlog_threshold"
+	SettingAlertCooldown            = "alert_cooldown_minutes"
+	SettingAlertEmailAdmins         = "alert_email_admins"
+)
+
+// Where an effective alert setting came from.
+const (
+	AlertSourceEnv     = "env"
+	AlertSourceSetting = "setting"
+	AlertSourceDefault = "default"
+)
+
+var alertSettingEnv = map[string]string{
+	SettingAlertErrorThreshold:      "ALERT_ERROR_THRESHOLD",
+	SettingAlertErrorWindow:         "ALERT_ERROR_WINDOW_MINUTES",
+	SettingAlertJobBacklogThreshold: "ALERT_JOB_BACKLOG_THRESHOLD",
+	SettingAlertCooldown:            "ALERT_COOLDOWN_MINUTES",
+	SettingAlertEmailAdmins:         "ALERT_EMAIL_ADMINS",
+}
+
+const (
+	defaultErrorThreshold      = 25
+	defaultErrorWindowMinutes  = 5
+	defaultJobBacklogThreshold = 200
+	defaultCooldownMinutes     = 30
+
+	// alertConfigTTL bounds how long a replica serves stale settings after
+	// an admin changes them.
+	alertConfigTTL = 30 * time.Second
+)
+
+// Config is the effective alerting configuration.
+type Config struct {
+	// An error-rate alert fires when ErrorThreshold server errors happen
+	// within ErrorWindowMinutes. Zero disables it.
+	ErrorThreshold     int `json:"error_threshold"`
+	ErrorWindowMinutes int `json:"error_window_minutes"`
+
+	// A backlog alert fires when this many jobs are overdue. Zero disables it.
+	JobBacklogThreshold int `json:"job_backlog_threshold"`
+
+	// The same alert isn't sent again within CooldownMinutes.
+	CooldownMinutes int `json:"cooldown_minutes"`
+
+	// EmailAdmins adds every site admin to the email sink's recipients.
+	EmailAdmins bool `json:"email_admins"`
+
+	Sources map[string]string `json:"sources"` // setting key -> env, setting, or default
+}
+
+// ErrorWindow returns ErrorWindowMinutes as a duration.
+func (cfg Config) ErrorWindow() time.Duration {
+	return time.Duration(cfg.ErrorWindowMinutes) * time.Minute
+}
+
+// Cooldown returns CooldownMinutes as a duration.
+func (cfg Config) Cooldown() time.Duration {
+	return time.Duration(cfg.CooldownMinutes) * time.Minute
+}
+
+// ConfigStore resolves Config from environment variables and site settings,
+// caching the result for alertConfigTTL. A nil store resolves from
+// environment variables and defaults only.
+type ConfigStore struct {
+	db       *gorm.DB
+	mu       sync.Mutex
+	cfg      Config
+	loadedAt time.Time
+}
+
+// NewConfigStore returns a store that reads site settings from db.
+func NewConfigStore(db *gorm.DB) *ConfigStore {
+	return &ConfigStore{db: db}
+}
+
+// Get returns the effective configuration, reloading it from site settings
+// when the cached copy has expired. If settings can't be read, the last
+// loaded configuration (or env and defaults) is used.
+func (s *ConfigStore) Get(ctx context.Context) Config {
+	if s == nil || s.db == nil {
+		return resolveConfig(nil)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.loadedAt.IsZero() && time.Since(s.loadedAt) < alertConfigTTL {
+		return s.cfg
+	}
+
+	keys := make([]string, 0, len(alertSettingEnv))
+	for key := range alertSettingEnv {
+		keys = append(keys, key)
+	}
+	var settings []models.SiteSetting
+	if err := s.db.WithContext(ctx).Where("key IN ?", keys).Find(&settings).Error; err != nil {
+		logging.Error("Failed to load alert settings", err)
+		if s.loadedAt.IsZero() {
+			return resolveConfig(nil)
+		}
+		return s.cfg
+	}
+	values := make(map[string]string, len(settings))
+	for _, setting := range settings {
+		values[setting.Key] = setting.Value
+	}
+	s.cfg = resolveConfig(values)
+	s.loadedAt = time.Now()
+	return s.cfg
+}
+
+// Invalidate forces the next Get to reload site settings.
+func (s *ConfigStore) Invalidate() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+// resolveConfig merges environment variables, site setting values, and
+// defaults, in that order of precedence. Invalid values fall back to the
+// next source.
+func resolveConfig(settings map[string]string) Config {
+	cfg := Config{Sources: make(map[string]string, len(alertSettingEnv))}
+	lookup := func(key string) (string, string) {
+		if v := strings.TrimSpace(os.Getenv(alertSettingEnv[key])); v != "" {
+			if err := ValidateAlertSetting(key, v); err == nil {
+				return v, AlertSourceEnv
+			}
+			logging.WithField("value", v).Warn("Invalid " + alertSettingEnv[key] + ", ignoring")
+		}
+		if v := strings.TrimSpace(settings[key]); v != "" && ValidateAlertSetting(key, v) == nil {
+			return v, AlertSourceSetting
+		}
+		return "", AlertSourceDefault
+	}
+	intValue := func(key string, def int) int {
+		v, source := lookup(key)
+		cfg.Sources[key] = source
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+		return def
+	}
+
+	cfg.ErrorThreshold = intValue(SettingAlertErrorThreshold, defaultErrorThreshold)
+	cfg.ErrorWindowMinutes = intValue(SettingAlertErrorWindow, defaultErrorWindowMinutes)
+	cfg.JobBacklogThreshold = intValue(SettingAlertJobBacklogThreshold, defaultJobBacklogThreshold)
+	cfg.CooldownMinutes = intValue(SettingAlertCooldown, defaultCooldownMinutes)
+
+	emailAdmins, source := lookup(SettingAlertEmailAdmins)
+	cfg.EmailAdmins = emailAdmins == "true"
+	cfg.Sources[SettingAlertEmailAdmins] = source
+
+	return cfg
+}
+
+// IsAlertSetting reports whether key is one of the alert setting keys.
+func IsAlertSetting(key string) bool {
+	_, ok := alertSettingEnv[key]
+	return ok
+}
+
+// ValidateAlertSetting checks a site setting value for an alert key. An
+// empty value is always valid and means "use the default".
+func ValidateAlertSetting(key, value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	intBetween := func(min, max int, unit string) error {
+		if n, err := strconv.Atoi(value); err != nil || n < min || n > max {
+			return fmt.Errorf("%s must be %s between %d and %d", key, unit, min, max)
+		}
+		return nil
+	}
+
+	switch key {
+	case SettingAlertErrorThreshold:
+		return intBetween(0, 100000, "a count")
+	case SettingAlertJobBacklogThreshold:
+		return intBetween(0, 1000000, "a count")
+	case SettingAlertErrorWindow:
+		return intBetween(1, 60, "a number of minutes")
+	case SettingAlertCooldown:
+		return intBetween(1, 24*60, "a number of minutes")
+	case SettingAlertEmailAdmins:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%s must be true or false", key)
+		}
+	default:
+		return errors.New("not an alert setting")
+	}
+	return nil
+}
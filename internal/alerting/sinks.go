@@ -0,0 +1,216 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// SinksFromEnv builds the sinks configured by environment variables:
+//
+//	ALERT_SLACK_WEBHOOK_URL  Slack incoming webhook
+//	ALERT_WEBHOOK_URL        generic webhook, sent the Alert as JSON
+//	ALERT_WEBHOOK_TOKEN      optional bearer token for ALERT_WEBHOOK_URL
+//	ALERT_EMAILS             comma-separated recipients
+//
+// An email sink is also added when the email service is configured, so the
+// alert_email_admins setting can reach site admins without a restart.
+func SinksFromEnv(db *gorm.DB, emailService *email.Service, config *ConfigStore) ([]Sink, error) {
+	var sinks []Sink
+	if raw := strings.TrimSpace(os.Getenv("ALERT_SLACK_WEBHOOK_URL")); raw != "" {
+		if err := validateSinkURL(raw); err != nil {
+			return nil, fmt.Errorf("ALERT_SLACK_WEBHOOK_URL %w", err)
+		}
+		sinks = append(sinks, &SlackSink{URL: raw, client: &http.Client{Timeout: sendTimeout}})
+	}
+	if raw := strings.TrimSpace(os.Getenv("ALERT_WEBHOOK_URL")); raw != "" {
+		if err := validateSinkURL(raw); err != nil {
+			return nil, fmt.Errorf("ALERT_WEBHOOK_URL %w", err)
+		}
+		sinks = append(sinks, &WebhookSink{URL: raw, Token: os.Getenv("ALERT_WEBHOOK_TOKEN"), client: &http.Client{Timeout: sendTimeout}})
+	}
+	if emailService != nil && emailService.IsConfigured() {
+		var recipients []string
+		for _, addr := range strings.Split(os.Getenv("ALERT_EMAILS"), ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				recipients = append(recipients, addr)
+			}
+		}
+		sinks = append(sinks, &EmailSink{Service: emailService, To: recipients, db: db, config: config})
+	}
+	return sinks, nil
+}
+
+func validateSinkURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("must be an http or https URL")
+	}
+	return nil
+}
+
+// postJSON sends body to target, treating any non-2xx response as a failure
+func postJSON(ctx context.Context, client *http.Client, target, token string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sortedFields returns an alert's field names in a stable order
+func sortedFields(alert Alert) []string {
+	keys := make([]string, 0, len(alert.Fields))
+	for key := range alert.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SlackSink posts alerts to a Slack incoming webhook
+type SlackSink struct {
+	URL    string
+	client *http.Client
+}
+
+// Name returns the sink name for logging
+func (s *SlackSink) Name() string {
+	return "slack"
+}
+
+// Send posts the alert as a Slack message
+func (s *SlackSink) Send(ctx context.Context, alert Alert) error {
+	var text strings.Builder
+	fmt.Fprintf(&text, "*[%s] %s*", strings.ToUpper(string(alert.Severity)), alert.Title)
+	if alert.Message != "" {
+		text.WriteString("\n" + alert.Message)
+	}
+	for _, key := range sortedFields(alert) {
+		fmt.Fprintf(&text, "\n• %s: %s", key, alert.Fields[key])
+	}
+	return postJSON(ctx, s.client, s.URL, "", map[string]string{"text": text.String()})
+}
+
+// WebhookSink POSTs each Alert as JSON to an external service, authenticated
+// with "Authorization: Bearer <token>" when a token is set
+type WebhookSink struct {
+	URL    string
+	Token  string
+	client *http.Client
+}
+
+// Name returns the sink name for logging
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+// Send posts the alert as JSON
+func (s *WebhookSink) Send(ctx context.Context, alert Alert) error {
+	return postJSON(ctx, s.client, s.URL, s.Token, alert)
+}
+
+// EmailSink emails alerts to a fixed list of recipients, plus every site
+// admin when alert_email_admins is on. It sends directly rather than through
+// the job queue, since a stuck queue is one of the things it reports.
+type EmailSink struct {
+	Service *email.Service
+	To      []string
+	db      *gorm.DB
+	config  *ConfigStore
+}
+
+// Name returns the sink name for logging
+func (s *EmailSink) Name() string {
+	return "email"
+}
+
+// Send emails the alert to each recipient, succeeding with no recipients
+func (s *EmailSink) Send(ctx context.Context, alert Alert) error {
+	recipients, err := s.recipients(ctx)
+	if err != nil {
+		return err
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "<h2>%s</h2>", html.EscapeString(alert.Title))
+	if alert.Message != "" {
+		fmt.Fprintf(&body, "<p>%s</p>", html.EscapeString(alert.Message))
+	}
+	if len(alert.Fields) > 0 {
+		body.WriteString("<ul>")
+		for _, key := range sortedFields(alert) {
+			fmt.Fprintf(&body, "<li><strong>%s:</strong> %s</li>", html.EscapeString(key), html.EscapeString(alert.Fields[key]))
+		}
+		body.WriteString("</ul>")
+	}
+	fmt.Fprintf(&body, "<p>Severity: %s<br>Time: %s</p>", alert.Severity, alert.Time.UTC().Format("2006-01-02 15:04:05 MST"))
+	subject := fmt.Sprintf("[%s] %s", strings.ToUpper(string(alert.Severity)), alert.Title)
+
+	var failed []string
+	for _, to := range recipients {
+		if err := s.Service.SendEmail(ctx, to, subject, body.String()); err != nil {
+			failed = append(failed, to)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to email %d of %d recipients", len(failed), len(recipients))
+	}
+	return nil
+}
+
+// recipients returns the configured addresses and, if enabled, site
+// admins', without duplicates
+func (s *EmailSink) recipients(ctx context.Context) ([]string, error) {
+	seen := make(map[string]bool)
+	var out []string
+	add := func(addr string) {
+		key := strings.ToLower(addr)
+		if addr != "" && !seen[key] {
+			seen[key] = true
+			out = append(out, addr)
+		}
+	}
+	for _, addr := range s.To {
+		add(addr)
+	}
+	if s.db != nil && s.config.Get(ctx).EmailAdmins {
+		var admins []string
+		if err := s.db.WithContext(ctx).Model(&models.User{}).
+			Where("is_admin = ? AND email <> ''", true).
+			Pluck("email", &admins).Error; err != nil {
+			return nil, fmt.Errorf("failed to load admin emails: %w", err)
+		}
+		for _, addr := range admins {
+			add(addr)
+		}
+	}
+	return out, nil
+}
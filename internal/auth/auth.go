@@ -3,7 +3,6 @@ package auth
 import (
 	"errors"
 	"fmt"
-	"os"
 	"strings"
 	"sync"
 	"time"
@@ -14,7 +13,8 @@ import (
 )
 
 var (
-	jwtSecret     []byte
+	// jwtKeys is the key ring; jwtKeys[0] signs new tokens.
+	jwtKeys       []jwtKey
 	jwtSecretOnce sync.Once
 )
 
@@ -52,40 +52,69 @@ func checkSecretEntropy(secret string) error {
 	return nil
 }
 
-// initJWTSecret initializes the JWT secret from environment variable
+// initJWTSecret initializes the JWT key ring from environment variables
 func initJWTSecret() {
 	jwtSecretOnce.Do(func() {
-		secret := os.Getenv("JWT_SECRET")
-		if secret == "" {
-			logging.Fatal("JWT_SECRET environment variable is required", nil)
-		}
-		if len(secret) < 32 {
-			logging.Fatal("JWT_SECRET must be at least 32 characters long for security", nil)
-		}
-
-		// Check secret entropy and quality
-		if err := checkSecretEntropy(secret); err != nil {
-			logging.Fatal(fmt.Sprintf("JWT_SECRET validation failed: %s. Generate a secure secret with: openssl rand -base64 32", err.Error()), nil)
+		keys, err := loadJWTKeys()
+		if err != nil {
+			logging.Fatal(fmt.Sprintf("JWT key configuration invalid: %s. Generate a secure secret with: openssl rand -base64 32", err.Error()), nil)
 		}
 
-		jwtSecret = []byte(secret)
-		logging.Info("JWT secret validated successfully")
+		jwtKeys = keys
+		logging.WithField("key_count", len(keys)).Info("JWT signing keys validated successfully")
 	})
 }
 
-// getJWTSecret returns the JWT secret, initializing it if necessary
+// getJWTSecret returns the signing key's secret, initializing it if necessary
 func getJWTSecret() ([]byte, error) {
+	key, err := signingKey()
+	if err != nil {
+		return nil, err
+	}
+	return key.secret, nil
+}
+
+// signingKey returns the newest key in the ring, which signs new tokens
+func signingKey() (jwtKey, error) {
+	initJWTSecret()
+	if len(jwtKeys) == 0 {
+		return jwtKey{}, fmt.Errorf("JWT secret not initialized")
+	}
+	return jwtKeys[0], nil
+}
+
+// verificationKeys returns the keys a token may have been signed with: the
+// key matching its kid header, or every key for tokens issued without one.
+func verificationKeys(token *jwt.Token) (jwt.VerificationKeySet, error) {
 	initJWTSecret()
-	if len(jwtSecret) == 0 {
-		return nil, fmt.Errorf("JWT secret not initialized")
+	kid, _ := token.Header["kid"].(string)
+	var set jwt.VerificationKeySet
+	for _, k := range jwtKeys {
+		if kid == "" || k.id == kid {
+			set.Keys = append(set.Keys, k.secret)
+		}
+	}
+	if len(set.Keys) == 0 {
+		return set, errors.New("unknown signing key")
 	}
-	return jwtSecret, nil
+	return set, nil
+}
+
+// GroupClaims are the groups a user belonged to when their token was
+// issued, so access checks can skip the database while they're fresh
+type GroupClaims struct {
+	GroupIDs      []uint `json:"ids"`
+	AdminGroupIDs []uint `json:"admin_ids,omitempty"` // Groups the user is a group admin of
 }
 
 // Claims represents JWT claims
 type Claims struct {
-	UserID  uint `json:"user_id"`
-	IsAdmin bool `json:"is_admin"`
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username,omitempty"`
+	IsAdmin  bool   `json:"is_admin"`
+	// Groups is nil in tokens issued without memberships (API clients and
+	// tokens from before group claims existed)
+	Groups *GroupClaims `json:"groups,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -102,14 +131,30 @@ func CheckPassword(hashedPassword, password string) error {
 
 // GenerateToken generates a JWT token for a user
 func GenerateToken(userID uint, isAdmin bool) (string, error) {
-	secret, err := getJWTSecret()
+	return GenerateUserToken(userID, "", isAdmin)
+}
+
+// GenerateUserToken generates a JWT token for a user that also carries their
+// username. The username is informational only: authorization is always by
+// user ID, so a token issued before a username change stays valid.
+func GenerateUserToken(userID uint, username string, isAdmin bool) (string, error) {
+	return GenerateUserTokenWithGroups(userID, username, isAdmin, nil)
+}
+
+// GenerateUserTokenWithGroups generates a JWT token that also carries the
+// user's group memberships as of now. Reissue it (e.g. on refresh) to pick
+// up membership changes.
+func GenerateUserTokenWithGroups(userID uint, username string, isAdmin bool, groups *GroupClaims) (string, error) {
+	key, err := signingKey()
 	if err != nil {
 		return "", err
 	}
 
 	claims := Claims{
-		UserID:  userID,
-		IsAdmin: isAdmin,
+		UserID:   userID,
+		Username: username,
+		IsAdmin:  isAdmin,
+		Groups:   groups,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -117,13 +162,15 @@ func GenerateToken(userID uint, isAdmin bool) (string, error) {
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(secret)
+	if key.id != "" {
+		token.Header["kid"] = key.id
+	}
+	return token.SignedString(key.secret)
 }
 
 // ValidateToken validates a JWT token and returns the claims
 func ValidateToken(tokenString string) (*Claims, error) {
-	secret, err := getJWTSecret()
-	if err != nil {
+	if _, err := signingKey(); err != nil {
 		return nil, err
 	}
 
@@ -131,7 +178,7 @@ func ValidateToken(tokenString string) (*Claims, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("unexpected signing method")
 		}
-		return secret, nil
+		return verificationKeys(token)
 	})
 
 	if err != nil {
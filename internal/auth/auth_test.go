@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"fmt"
 	"os"
 	"strings"
 	"sync"
@@ -13,7 +14,7 @@ import (
 // resetJWTSecret resets the JWT secret for testing
 func resetJWTSecret() {
 	jwtSecretOnce = sync.Once{}
-	jwtSecret = nil
+	jwtKeys = nil
 }
 
 func TestHashPassword(t *testing.T) {
@@ -460,3 +461,37 @@ func TestTokenGenerateAndValidateRoundTrip(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateUserTokenWithGroups(t *testing.T) {
+	os.Setenv("JWT_SECRET", "L5WTt6D+6R55YfKzwqPRAEX5bR0bkNo4i58jYKL0wsk=")
+	defer os.Unsetenv("JWT_SECRET")
+	defer resetJWTSecret()
+	resetJWTSecret()
+
+	token, err := GenerateUserTokenWithGroups(5, "vol", false, &GroupClaims{GroupIDs: []uint{1, 4}, AdminGroupIDs: []uint{4}})
+	if err != nil {
+		t.Fatalf("GenerateUserTokenWithGroups() failed: %v", err)
+	}
+	claims, err := ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken() failed: %v", err)
+	}
+	if claims.Groups == nil {
+		t.Fatal("Groups = nil, want the memberships the token was issued with")
+	}
+	if fmt.Sprint(claims.Groups.GroupIDs) != "[1 4]" || fmt.Sprint(claims.Groups.AdminGroupIDs) != "[4]" {
+		t.Errorf("Groups = %+v, want ids [1 4] and admin ids [4]", claims.Groups)
+	}
+
+	token, err = GenerateToken(5, false)
+	if err != nil {
+		t.Fatalf("GenerateToken() failed: %v", err)
+	}
+	claims, err = ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken() failed: %v", err)
+	}
+	if claims.Groups != nil {
+		t.Errorf("Groups = %+v, want nil for a token issued without memberships", claims.Groups)
+	}
+}
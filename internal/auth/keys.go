@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// jwtKey is one HMAC key in the signing key ring. An empty id marks the
+// legacy JWT_SECRET key, whose tokens carry no "kid" header.
+type jwtKey struct {
+	id     string
+	secret []byte
+}
+
+// jwtKeysFile is the format of the file named by JWT_KEYS_FILE.
+type jwtKeysFile struct {
+	Keys []struct {
+		KID    string `json:"kid"`
+		Secret string `json:"secret"`
+	} `json:"keys"`
+}
+
+// loadJWTKeys builds the key ring from the environment. Keys come from
+// JWT_KEYS_FILE if set, otherwise from JWT_KEYS ("kid:secret" pairs,
+// comma-separated). In both, the first key is the newest and signs new
+// tokens; the rest are accepted for verification only, so a rotation is:
+// prepend the new key, wait out the token lifetime, then drop the old one.
+// JWT_SECRET, if set, is appended as a verification key for tokens issued
+// before key IDs were introduced, and is the signing key when no key ring
+// is configured.
+func loadJWTKeys() ([]jwtKey, error) {
+	var keys []jwtKey
+	var err error
+	if path := os.Getenv("JWT_KEYS_FILE"); path != "" {
+		keys, err = readJWTKeysFile(path)
+	} else if spec := os.Getenv("JWT_KEYS"); spec != "" {
+		keys, err = parseJWTKeys(spec)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		keys = append(keys, jwtKey{secret: []byte(secret)})
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWT_SECRET, JWT_KEYS, or JWT_KEYS_FILE environment variable is required")
+	}
+
+	for _, k := range keys {
+		name := "JWT_SECRET"
+		if k.id != "" {
+			name = fmt.Sprintf("JWT key %q", k.id)
+		}
+		if len(k.secret) < 32 {
+			return nil, fmt.Errorf("%s must be at least 32 characters long for security", name)
+		}
+		if err := checkSecretEntropy(string(k.secret)); err != nil {
+			return nil, fmt.Errorf("%s validation failed: %s", name, err.Error())
+		}
+	}
+	return keys, nil
+}
+
+// parseJWTKeys parses a JWT_KEYS value such as "2026-10:secretB,2026-04:secretA".
+func parseJWTKeys(spec string) ([]jwtKey, error) {
+	var keys []jwtKey
+	seen := make(map[string]bool)
+	for i, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, secret, ok := strings.Cut(entry, ":")
+		id = strings.TrimSpace(id)
+		if !ok || id == "" || secret == "" {
+			return nil, fmt.Errorf("JWT_KEYS entry %d must have the form kid:secret", i)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("JWT_KEYS contains duplicate key ID %q", id)
+		}
+		seen[id] = true
+		keys = append(keys, jwtKey{id: id, secret: []byte(secret)})
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWT_KEYS contains no keys")
+	}
+	return keys, nil
+}
+
+// readJWTKeysFile reads a JSON key file:
+//
+//	{"keys": [{"kid": "2026-10", "secret": "..."}, {"kid": "2026-04", "secret": "..."}]}
+func readJWTKeysFile(path string) ([]jwtKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT_KEYS_FILE: %w", err)
+	}
+	var file jwtKeysFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse JWT_KEYS_FILE: %w", err)
+	}
+
+	var keys []jwtKey
+	seen := make(map[string]bool)
+	for i, k := range file.Keys {
+		if k.KID == "" || k.Secret == "" {
+			return nil, fmt.Errorf("JWT_KEYS_FILE key %d must have a kid and secret", i)
+		}
+		if seen[k.KID] {
+			return nil, fmt.Errorf("JWT_KEYS_FILE contains duplicate key ID %q", k.KID)
+		}
+		seen[k.KID] = true
+		keys = append(keys, jwtKey{id: k.KID, secret: []byte(k.Secret)})
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWT_KEYS_FILE contains no keys")
+	}
+	return keys, nil
+}
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testKeyOld = "L5WTt6D+6R55YfKzwqPRAEX5bR0bkNo4i58jYKL0wsk="
+	testKeyNew = "q8Vd3nR1xZ7pK2mW9sJ4tB6yH0cF5gL3aE8uN1iO7rQ="
+)
+
+func TestParseJWTKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantIDs []string
+		wantErr bool
+	}{
+		{name: "single key", spec: "k1:" + testKeyOld, wantIDs: []string{"k1"}},
+		{name: "newest first, whitespace tolerated", spec: " k2:" + testKeyNew + " , k1:" + testKeyOld, wantIDs: []string{"k2", "k1"}},
+		{name: "missing kid", spec: ":" + testKeyOld, wantErr: true},
+		{name: "missing separator", spec: testKeyOld, wantErr: true},
+		{name: "duplicate kid", spec: "k1:" + testKeyOld + ",k1:" + testKeyNew, wantErr: true},
+		{name: "empty", spec: " , ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := parseJWTKeys(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseJWTKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(keys) != len(tt.wantIDs) {
+				t.Fatalf("parseJWTKeys() returned %d keys, want %d", len(keys), len(tt.wantIDs))
+			}
+			for i, id := range tt.wantIDs {
+				if keys[i].id != id {
+					t.Errorf("keys[%d].id = %q, want %q", i, keys[i].id, id)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadJWTKeys(t *testing.T) {
+	t.Run("keys file takes precedence over JWT_KEYS", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "jwt-keys.json")
+		content := `{"keys": [{"kid": "file-new", "secret": "` + testKeyNew + `"}, {"kid": "file-old", "secret": "` + testKeyOld + `"}]}`
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("JWT_KEYS_FILE", path)
+		t.Setenv("JWT_KEYS", "env:"+testKeyOld)
+		t.Setenv("JWT_SECRET", "")
+
+		keys, err := loadJWTKeys()
+		if err != nil {
+			t.Fatalf("loadJWTKeys() error = %v", err)
+		}
+		if len(keys) != 2 || keys[0].id != "file-new" {
+			t.Errorf("loadJWTKeys() = %v, want file keys with file-new first", keys)
+		}
+	})
+
+	t.Run("legacy JWT_SECRET is appended for verification", func(t *testing.T) {
+		t.Setenv("JWT_KEYS_FILE", "")
+		t.Setenv("JWT_KEYS", "k2:"+testKeyNew)
+		t.Setenv("JWT_SECRET", testKeyOld)
+
+		keys, err := loadJWTKeys()
+		if err != nil {
+			t.Fatalf("loadJWTKeys() error = %v", err)
+		}
+		if len(keys) != 2 || keys[0].id != "k2" || keys[1].id != "" {
+			t.Errorf("loadJWTKeys() = %v, want [k2, legacy]", keys)
+		}
+	})
+
+	t.Run("weak rotated key is rejected", func(t *testing.T) {
+		t.Setenv("JWT_KEYS_FILE", "")
+		t.Setenv("JWT_KEYS", "k2:too-short")
+		t.Setenv("JWT_SECRET", testKeyOld)
+
+		if _, err := loadJWTKeys(); err == nil {
+			t.Error("loadJWTKeys() expected error for short key")
+		}
+	})
+
+	t.Run("no keys configured", func(t *testing.T) {
+		t.Setenv("JWT_KEYS_FILE", "")
+		t.Setenv("JWT_KEYS", "")
+		t.Setenv("JWT_SECRET", "")
+
+		if _, err := loadJWTKeys(); err == nil {
+			t.Error("loadJWTKeys() expected error with no keys")
+		}
+	})
+}
+
+func TestTokenKeyRotation(t *testing.T) {
+	defer resetJWTSecret()
+
+	// Before rotation: a single legacy secret, tokens without a kid.
+	resetJWTSecret()
+	t.Setenv("JWT_KEYS_FILE", "")
+	t.Setenv("JWT_KEYS", "")
+	t.Setenv("JWT_SECRET", testKeyOld)
+	legacyToken, err := GenerateToken(1, false)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	// First rotation: introduce k1, keep the legacy secret for verification.
+	resetJWTSecret()
+	t.Setenv("JWT_KEYS", "k1:"+testKeyNew)
+	k1Token, err := GenerateToken(2, false)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(k1Token, &Claims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified() error = %v", err)
+	}
+	if parsed.Header["kid"] != "k1" {
+		t.Errorf("kid header = %v, want k1", parsed.Header["kid"])
+	}
+
+	for name, token := range map[string]string{"legacy": legacyToken, "k1": k1Token} {
+		if _, err := ValidateToken(token); err != nil {
+			t.Errorf("ValidateToken(%s) during rotation window error = %v", name, err)
+		}
+	}
+
+	// Rotation window over: legacy secret removed.
+	resetJWTSecret()
+	t.Setenv("JWT_SECRET", "")
+	if _, err := ValidateToken(legacyToken); err == nil {
+		t.Error("ValidateToken(legacy) succeeded after its key was removed")
+	}
+	if _, err := ValidateToken(k1Token); err != nil {
+		t.Errorf("ValidateToken(k1) error = %v", err)
+	}
+
+	// A token naming an unknown kid is rejected even if its signature
+	// matches a configured key.
+	unknown := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{UserID: 3})
+	unknown.Header["kid"] = "k9"
+	unknownToken, _ := unknown.SignedString([]byte(testKeyNew))
+	if _, err := ValidateToken(unknownToken); err == nil {
+		t.Error("ValidateToken() accepted a token with an unknown kid")
+	}
+}
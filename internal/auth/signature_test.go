@@ -0,0 +1,42 @@
+package auth
+
+import "testing"
+
+func TestSignAndVerifySignature(t *testing.T) {
+	defer resetJWTSecret()
+
+	resetJWTSecret()
+	t.Setenv("JWT_KEYS_FILE", "")
+	t.Setenv("JWT_KEYS", "")
+	t.Setenv("JWT_SECRET", testKeyOld)
+	oldSig, err := Sign("share", "42")
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if !VerifySignature("share", "42", oldSig) {
+		t.Error("signature did not verify")
+	}
+	if VerifySignature("share", "43", oldSig) || VerifySignature("other", "42", oldSig) {
+		t.Error("signature verified for a different value or purpose")
+	}
+
+	// After rotation the old key still verifies until it is dropped.
+	resetJWTSecret()
+	t.Setenv("JWT_KEYS", "k1:"+testKeyNew)
+	newSig, err := Sign("share", "42")
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if newSig == oldSig {
+		t.Error("new signing key produced the same signature")
+	}
+	if !VerifySignature("share", "42", oldSig) || !VerifySignature("share", "42", newSig) {
+		t.Error("signatures should verify during the rotation window")
+	}
+
+	resetJWTSecret()
+	t.Setenv("JWT_SECRET", "")
+	if VerifySignature("share", "42", oldSig) {
+		t.Error("signature from a removed key still verifies")
+	}
+}
@@ -68,12 +68,16 @@ func Initialize() (*gorm.DB, error) {
 		return nil, fmt.Errorf("invalid SSL mode: %s (must be one of: disable, require, verify-ca, verify-full)", dbSSLMode)
 	}
 
-	// Add connection timeout to prevent hanging if database is unreachable
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s connect_timeout=10",
+	// Add connection timeout to prevent hanging if database is unreachable.
+	// Sessions run in UTC so timestamps are stored and read back the same
+	// whatever the server's or database's own time zone; groups and users
+	// choose the zone times are shown and scheduled in.
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s connect_timeout=10 TimeZone=UTC",
 		dbHost, dbPort, dbUser, dbPassword, dbName, dbSSLMode)
 
 	// Configure GORM logger level via env var to control verbosity
-	// Accepted values: silent, error, warn, info
+	// Accepted values: silent, error, warn, info. Query logs go through the
+	// structured logger with the request's ID; see gormLogger.
 	var logLevel logger.LogLevel
 	switch strings.ToLower(os.Getenv("DB_LOG_LEVEL")) {
 	case "silent":
@@ -90,11 +94,15 @@ func Initialize() (*gorm.DB, error) {
 	}
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logLevel),
+		Logger:  newGormLogger(logLevel, slowQueryThresholdFromEnv()),
+		NowFunc: func() time.Time { return time.Now().UTC() },
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	if err := models.SetupJoinTables(db); err != nil {
+		return nil, fmt.Errorf("failed to set up join tables: %w", err)
+	}
 
 	// Tracing is an observability nicety, not a startup requirement — a
 	// failure here must not take down the whole app, matching telemetry.Init's
@@ -168,41 +176,130 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
-// RunMigrations runs all database migrations
-func RunMigrations(db *gorm.DB) error {
-	logging.Info("Running database migrations...")
-
-	// CRITICAL: Drop legacy single-column unique indexes BEFORE AutoMigrate
-	// These old indexes conflict with the new composite indexes (group_id, name)
-	// GORM AutoMigrate won't remove old indexes when index names change
-	if err := dropLegacyIndexes(db); err != nil {
-		logging.WithField("error", err.Error()).Warn("Failed to drop legacy indexes (may not exist)")
-	}
-
-	err := db.AutoMigrate(
+// MigrationModels lists every model RunMigrations auto-migrates, in
+// dependency order. Shared with PendingMigrations so the readiness check
+// compares the live schema against exactly what a migration would create,
+// and exported so tests can migrate the full schema.
+func MigrationModels() []interface{} {
+	return []interface{}{
+		&models.Organization{},
+		&models.OrganizationSetting{},
 		&models.User{},
 		&models.Group{},
 		&models.UserGroup{},
+		&models.GroupJoinRequest{},
+		&models.WeeklyStatsReport{},
+		&models.StatusAlertRule{},
+		&models.StatusAlertNotice{},
+		&models.GroupFieldVisibility{},
+		&models.GroupRequiredField{},
+		&models.StatusChecklistItem{},
+		&models.OnboardingItem{},
+		&models.OnboardingCompletion{},
+		&models.MemberOnboarding{},
 		// Script must come before Animal so that the animal_scripts many2many
 		// join table can be created with a valid FK to the scripts table.
 		&models.Script{},
 		&models.Animal{},
 		&models.Update{},
+		&models.Discussion{},
+		&models.DiscussionReply{},
+		&models.DiscussionParticipant{},
 		&models.Announcement{},
+		&models.AnnouncementRead{},
+		&models.AnnouncementEmailEvent{},
+		&models.EmergencyBroadcast{},
+		&models.EmergencyBroadcastDelivery{},
 		&models.CommentTag{},
 		&models.AnimalComment{},
 		&models.CommentHistory{},
+		&models.CommentReaction{},
 		&models.SiteSetting{},
+		&models.EmailTemplate{},
 		&models.Protocol{},
+		&models.ProtocolVersion{},
+		&models.ProtocolAcknowledgment{},
+		&models.ProtocolAttachment{},
 		&models.AnimalTag{},
+		&models.Breed{},
+		&models.UserQualification{},
+		&models.AnimalStatus{},
+		&models.AnimalCustomField{},
 		&models.UserSkillTag{},
 		&models.AnimalImage{},
+		&models.AnimalImageVariant{},
+		&models.ProxiedImage{},
 		&models.AnimalVideo{},
 		&models.AnimalNameHistory{},
 		&models.AnimalBQIncident{},
+		&models.WeightEntry{},
+		&models.AnimalRelationship{},
+		&models.BehaviorAssessment{},
+		&models.SavedFilter{},
+		&models.FosterProfile{},
+		&models.AnimalView{},
 		&models.GroupDocument{},
 		&models.APIToken{},
-	)
+		&models.APIAccessRestriction{},
+		&models.UsernameHistory{},
+		&models.UserMerge{},
+		&models.UserIdentity{},
+		&models.Job{},
+		&models.DataExport{},
+		&models.AnimalChange{},
+		&models.AnimalShareLink{},
+		&models.KennelCardTemplate{},
+	}
+}
+
+// PendingMigrations reports tables and columns that RunMigrations would
+// create but that are missing from the live schema, e.g. when a new
+// release is pointed at a database whose migrations failed or were skipped.
+// Entries look like "animals" (missing table) or "animals.status" (missing
+// column). An empty result means the schema is up to date.
+func PendingMigrations(db *gorm.DB) ([]string, error) {
+	var pending []string
+	migrator := db.Migrator()
+	for _, model := range MigrationModels() {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("failed to parse model %T: %w", model, err)
+		}
+		table := stmt.Schema.Table
+		if !migrator.HasTable(table) {
+			pending = append(pending, table)
+			continue
+		}
+		columnTypes, err := migrator.ColumnTypes(table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read columns for %s: %w", table, err)
+		}
+		existing := make(map[string]bool, len(columnTypes))
+		for _, ct := range columnTypes {
+			existing[strings.ToLower(ct.Name())] = true
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" || existing[strings.ToLower(field.DBName)] {
+				continue
+			}
+			pending = append(pending, table+"."+field.DBName)
+		}
+	}
+	return pending, nil
+}
+
+// RunMigrations runs all database migrations
+func RunMigrations(db *gorm.DB) error {
+	logging.Info("Running database migrations...")
+
+	// CRITICAL: Drop legacy single-column unique indexes BEFORE AutoMigrate
+	// These old indexes conflict with the new composite indexes (group_id, name)
+	// GORM AutoMigrate won't remove old indexes when index names change
+	if err := dropLegacyIndexes(db); err != nil {
+		logging.WithField("error", err.Error()).Warn("Failed to drop legacy indexes (may not exist)")
+	}
+
+	err := db.AutoMigrate(MigrationModels()...)
 	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
@@ -471,13 +568,47 @@ func createCustomIndexes(db *gorm.DB) error {
 		logging.Info("Created partial unique index idx_user_skill_tag_group_name_active")
 	}
 
+	// Group slugs name public feed URLs, so they're unique among live groups
+	// that have one
+	groupSlugIndexQuery := `
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_groups_slug_active
+		ON groups (slug)
+		WHERE slug <> '' AND deleted_at IS NULL
+	`
+	if err := db.Exec(groupSlugIndexQuery).Error; err != nil {
+		logging.WithField("error", err.Error()).Warn("Failed to create partial unique index on groups.slug")
+	} else {
+		logging.Info("Created partial unique index idx_groups_slug_active")
+	}
+
+	// The animal_comment_tags join table's primary key leads with the comment,
+	// so filtering comments by tag (comment search's tag filter) needs the
+	// reverse lookup
+	commentTagLookupIndexQuery := `
+		CREATE INDEX IF NOT EXISTS idx_animal_comment_tags_tag
+		ON animal_comment_tags (comment_tag_id, animal_comment_id)
+	`
+	if err := db.Exec(commentTagLookupIndexQuery).Error; err != nil {
+		logging.WithField("error", err.Error()).Warn("Failed to create index on animal_comment_tags.comment_tag_id")
+	} else {
+		logging.Info("Created index idx_animal_comment_tags_tag")
+	}
+
+	// Everything below needs Postgres extensions or column types, so other
+	// dialects (SQLite in tests) stop at the portable indexes above
+	if !DialectOf(db).FullText() {
+		logging.Info("Custom indexes creation completed")
+		return nil
+	}
+
 	// pg_trgm powers trigram similarity matching. Only the extension and the
 	// GIN index below are set up so far — it currently accelerates the
-	// existing LOWER(name) LIKE '%...%' substring search in GetAnimals/
-	// GetAllAnimals (a plain B-tree index can't serve a leading-wildcard
-	// LIKE), but nothing yet queries via the actual %/similarity() fuzzy
-	// operators, so true typo-tolerant matching ("Rax" surfacing "Rex")
-	// isn't implemented yet — the infrastructure is just in place for it.
+	// name ILIKE '%...%' substring search in GetAnimals/GetAllAnimals (see
+	// Dialect.ContainsFold; a plain B-tree index can't serve a
+	// leading-wildcard match), but nothing yet queries via the actual
+	// %/similarity() fuzzy operators, so true typo-tolerant matching ("Rax"
+	// surfacing "Rex") isn't implemented yet — the infrastructure is just in
+	// place for it.
 	trgmExtensionQuery := `CREATE EXTENSION IF NOT EXISTS pg_trgm`
 	if err := db.Exec(trgmExtensionQuery).Error; err != nil {
 		logging.WithField("error", err.Error()).Warn("Failed to create pg_trgm extension")
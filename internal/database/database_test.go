@@ -200,3 +200,43 @@ func TestConfigureTracing_RegistersPluginWithoutError(t *testing.T) {
 	}
 }
 
+
+func TestPendingMigrations(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open in-memory sqlite db: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := db.AutoMigrate(MigrationModels()...); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	pending, err := PendingMigrations(db)
+	if err != nil {
+		t.Fatalf("PendingMigrations returned error: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("expected no pending migrations after AutoMigrate, got %v", pending)
+	}
+
+	if err := db.Migrator().DropColumn(&models.User{}, "email_verified_at"); err != nil {
+		t.Fatalf("failed to drop column: %v", err)
+	}
+	if err := db.Migrator().DropTable(&models.APIToken{}); err != nil {
+		t.Fatalf("failed to drop table: %v", err)
+	}
+	pending, err = PendingMigrations(db)
+	if err != nil {
+		t.Fatalf("PendingMigrations returned error: %v", err)
+	}
+	want := map[string]bool{"users.email_verified_at": true, "api_tokens": true}
+	if len(pending) != len(want) {
+		t.Fatalf("expected %v pending, got %v", want, pending)
+	}
+	for _, p := range pending {
+		if !want[p] {
+			t.Errorf("unexpected pending migration %q", p)
+		}
+	}
+}
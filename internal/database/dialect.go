@@ -0,0 +1,135 @@
+package database
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Dialect names, as reported by gorm's Dialector.Name()
+const (
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+)
+
+// Dialect builds the SQL fragments that differ between Postgres, which the
+// app runs on, and SQLite, which the unit tests run on. Code that needs
+// pattern matching, JSON fields, or date formatting asks the dialect for the
+// fragment instead of writing SQL that only one of the two accepts (or,
+// worse, that both accept but evaluate differently — LIKE is case-sensitive
+// in Postgres and ASCII case-insensitive in SQLite).
+type Dialect interface {
+	// Name is the gorm dialector name, e.g. DialectPostgres.
+	Name() string
+
+	// ContainsFold matches rows where column contains s, ignoring case.
+	// s is matched literally; % and _ are not wildcards.
+	ContainsFold(column, s string) clause.Expr
+
+	// HasPrefix matches rows where column starts with prefix, respecting
+	// case. prefix is matched literally.
+	HasPrefix(column, prefix string) clause.Expr
+
+	// JSONText returns an expression for key in a JSON column, as text, or
+	// NULL when the key is absent. key must be a constant.
+	JSONText(column, key string) string
+
+	// Day returns an expression formatting a timestamp column as its
+	// YYYY-MM-DD date in UTC.
+	Day(column string) string
+
+	// FullText reports whether the Postgres-only search features
+	// createCustomIndexes sets up (pg_trgm, tsvector columns, pgvector) can
+	// exist on this database.
+	FullText() bool
+}
+
+// DialectOf returns the Dialect for db's driver. Anything other than
+// Postgres is treated as SQLite, the only other driver the project uses.
+func DialectOf(db *gorm.DB) Dialect {
+	if db.Dialector.Name() == DialectPostgres {
+		return postgresDialect{}
+	}
+	return sqliteDialect{}
+}
+
+// EscapeLike escapes the LIKE wildcards in s so it matches literally. Both
+// dialects pair it with an explicit ESCAPE '\' clause, since SQLite has no
+// default escape character.
+func EscapeLike(s string) string {
+	// Escape backslash first to prevent double-escaping
+	result := strings.ReplaceAll(s, "\\", "\\\\")
+	result = strings.ReplaceAll(result, "%", "\\%")
+	result = strings.ReplaceAll(result, "_", "\\_")
+	return result
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return DialectPostgres }
+
+// ContainsFold uses ILIKE on the bare column so the idx_animals_name_trgm
+// GIN index can serve leading-wildcard searches on animal names; a
+// LOWER(name) expression would bypass it.
+func (postgresDialect) ContainsFold(column, s string) clause.Expr {
+	return clause.Expr{SQL: column + ` ILIKE ? ESCAPE '\'`, Vars: []interface{}{"%" + EscapeLike(s) + "%"}}
+}
+
+func (postgresDialect) HasPrefix(column, prefix string) clause.Expr {
+	return clause.Expr{SQL: column + ` LIKE ? ESCAPE '\'`, Vars: []interface{}{EscapeLike(prefix) + "%"}}
+}
+
+func (postgresDialect) JSONText(column, key string) string {
+	return column + "->>'" + key + "'"
+}
+
+func (postgresDialect) Day(column string) string {
+	return "TO_CHAR(" + column + " AT TIME ZONE 'UTC', 'YYYY-MM-DD')"
+}
+
+func (postgresDialect) FullText() bool { return true }
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return DialectSQLite }
+
+// ContainsFold relies on SQLite's LIKE already ignoring case. It only folds
+// ASCII letters, unlike Postgres' ILIKE, so tests shouldn't depend on
+// case-insensitive matching of accented names.
+func (sqliteDialect) ContainsFold(column, s string) clause.Expr {
+	return clause.Expr{SQL: column + ` LIKE ? ESCAPE '\'`, Vars: []interface{}{"%" + EscapeLike(s) + "%"}}
+}
+
+// HasPrefix uses GLOB, since SQLite's LIKE can't be made case-sensitive per
+// query.
+func (sqliteDialect) HasPrefix(column, prefix string) clause.Expr {
+	return clause.Expr{SQL: column + " GLOB ?", Vars: []interface{}{escapeGlob(prefix) + "*"}}
+}
+
+func (sqliteDialect) JSONText(column, key string) string {
+	return "json_extract(CAST(" + column + " AS TEXT), '$." + key + "')"
+}
+
+func (sqliteDialect) Day(column string) string {
+	return "strftime('%Y-%m-%d', " + column + ")"
+}
+
+func (sqliteDialect) FullText() bool { return false }
+
+// escapeGlob makes s match literally in a GLOB pattern by wrapping each
+// metacharacter in a one-character class.
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[':
+			b.WriteByte('[')
+			b.WriteRune(r)
+			b.WriteByte(']')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
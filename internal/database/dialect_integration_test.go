@@ -0,0 +1,68 @@
+//go:build integration
+
+package database
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// openIntegrationPostgres is openDatabaseTestPostgres for the integration
+// build, where a missing database is a failure rather than a skip. Run the
+// suite with `make test-integration`.
+func openIntegrationPostgres(t *testing.T) *gorm.DB {
+	t.Helper()
+	addr := net.JoinHostPort(envOrDefault("DB_HOST", "localhost"), envOrDefault("DB_PORT", "5432"))
+	conn, err := net.DialTimeout("tcp", addr, 2*time.Second)
+	if err != nil {
+		t.Fatalf("no Postgres reachable at %s (run `make test-integration`, or set DB_HOST/DB_PORT)", addr)
+	}
+	_ = conn.Close()
+	return openDatabaseTestPostgres(t)
+}
+
+func TestPostgresDialect(t *testing.T) {
+	db := openIntegrationPostgres(t)
+	if got := DialectOf(db).Name(); got != DialectPostgres {
+		t.Fatalf("DialectOf(postgres) = %q", got)
+	}
+	checkDialect(t, db)
+}
+
+func TestPostgresMigrations_Idempotent(t *testing.T) {
+	db := openIntegrationPostgres(t)
+
+	// Deploys run migrations on every start, so a second run must be clean
+	for i := 0; i < 2; i++ {
+		if err := RunMigrations(db); err != nil {
+			t.Fatalf("RunMigrations run %d: %v", i+1, err)
+		}
+	}
+	pending, err := PendingMigrations(db)
+	if err != nil {
+		t.Fatalf("PendingMigrations: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("pending migrations after RunMigrations: %v", pending)
+	}
+
+	for _, index := range []string{
+		"idx_users_username_lower",
+		"idx_animals_name_lower",
+		"idx_animal_images_profile_partial",
+		"idx_user_skill_tag_group_name_active",
+		"idx_animals_name_trgm",
+		"idx_animals_search_vector",
+	} {
+		var count int64
+		if err := db.Raw("SELECT COUNT(*) FROM pg_indexes WHERE indexname = ?", index).Scan(&count).Error; err != nil {
+			t.Fatalf("look up index %s: %v", index, err)
+		}
+		if count != 1 {
+			t.Errorf("index %s was not created", index)
+		}
+	}
+}
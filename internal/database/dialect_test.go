@@ -0,0 +1,250 @@
+package database
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+// TestEscapeLike tests the SQL wildcard escaping function
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "no special characters",
+			input:    "normal text",
+			expected: "normal text",
+		},
+		{
+			name:     "percent sign",
+			input:    "test%",
+			expected: "test\\%",
+		},
+		{
+			name:     "underscore",
+			input:    "test_name",
+			expected: "test\\_name",
+		},
+		{
+			name:     "both percent and underscore",
+			input:    "test%_name",
+			expected: "test\\%\\_name",
+		},
+		{
+			name:     "multiple percent signs",
+			input:    "%%test%%",
+			expected: "\\%\\%test\\%\\%",
+		},
+		{
+			name:     "backslash",
+			input:    "test\\escape",
+			expected: "test\\\\escape",
+		},
+		{
+			name:     "backslash and percent",
+			input:    "test\\%",
+			expected: "test\\\\\\%",
+		},
+		{
+			name:     "empty string",
+			input:    "",
+			expected: "",
+		},
+		{
+			name:     "SQL injection attempt with wildcards",
+			input:    "%' OR '1'='1",
+			expected: "\\%' OR '1'='1",
+		},
+		{
+			name:     "realistic animal name with special chars",
+			input:    "fluffy_dog%",
+			expected: "fluffy\\_dog\\%",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := EscapeLike(tt.input)
+			if result != tt.expected {
+				t.Errorf("EscapeLike(%q) = %q, expected %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestEscapeLike_Performance tests that escaping is efficient
+func TestEscapeLike_Performance(t *testing.T) {
+	longInput := strings.Repeat("test%_string", 100)
+	result := EscapeLike(longInput)
+	if len(result) != len(longInput)+200 {
+		t.Errorf("escaped %d characters to %d, want %d", len(longInput), len(result), len(longInput)+200)
+	}
+}
+
+func TestDialectOf(t *testing.T) {
+	db := openDialectTestSQLite(t)
+	if got := DialectOf(db).Name(); got != DialectSQLite {
+		t.Errorf("DialectOf(sqlite) = %q", got)
+	}
+	if DialectOf(db).FullText() {
+		t.Errorf("sqlite should not report full-text support")
+	}
+}
+
+func TestSQLiteDialect(t *testing.T) {
+	checkDialect(t, openDialectTestSQLite(t))
+}
+
+func openDialectTestSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open in-memory sqlite db: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	return db
+}
+
+// dialectProbe is the table checkDialect queries. It's migrated like any
+// model, so each database picks the same column types it would for real
+// tables (jsonb and timestamptz on Postgres).
+type dialectProbe struct {
+	ID        uint
+	Name      string
+	Metadata  *string `gorm:"type:jsonb"`
+	CreatedAt time.Time
+}
+
+// checkDialect runs the same assertions against any database, so the SQLite
+// unit tests and the Postgres integration tests agree on what each Dialect
+// fragment means.
+func checkDialect(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	if err := db.Migrator().DropTable(&dialectProbe{}); err != nil {
+		t.Fatalf("drop probe table: %v", err)
+	}
+	if err := db.AutoMigrate(&dialectProbe{}); err != nil {
+		t.Fatalf("create probe table: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Migrator().DropTable(&dialectProbe{}) })
+
+	meta := `{"session_rating": 4, "behavior_notes": "calm"}`
+	late := time.Date(2026, 10, 16, 23, 30, 0, 0, time.UTC)
+	rows := []dialectProbe{
+		{Name: "Rex", Metadata: &meta, CreatedAt: late},
+		{Name: "REX the dog"},
+		{Name: "T-Rex"},
+		{Name: "test%"},
+		{Name: "tester"},
+		{Name: "fluffy_dog"},
+		{Name: "fluffyAdog"},
+		{Name: `back\slash`},
+		{Name: "synthetic_user_1"},
+		{Name: "SYNTHETIC_USER_2"},
+		{Name: "syntheticXuserX3"},
+		{Name: "a*b"},
+		{Name: "abc"},
+	}
+	for i := range rows {
+		if rows[i].CreatedAt.IsZero() {
+			rows[i].CreatedAt = late.Add(-48 * time.Hour)
+		}
+	}
+	if err := db.Create(&rows).Error; err != nil {
+		t.Fatalf("insert probe rows: %v", err)
+	}
+
+	d := DialectOf(db)
+	names := func(cond clause.Expr) []string {
+		t.Helper()
+		var got []string
+		if err := db.Model(&dialectProbe{}).Where(cond).Pluck("name", &got).Error; err != nil {
+			t.Fatalf("query %q: %v", cond.SQL, err)
+		}
+		sort.Strings(got)
+		return got
+	}
+	matchTests := []struct {
+		name string
+		cond clause.Expr
+		want []string
+	}{
+		{"contains ignores case", d.ContainsFold("name", "rEx"), []string{"REX the dog", "Rex", "T-Rex"}},
+		{"percent is literal", d.ContainsFold("name", "test%"), []string{"test%"}},
+		{"underscore is literal", d.ContainsFold("name", "fluffy_dog"), []string{"fluffy_dog"}},
+		{"backslash is literal", d.ContainsFold("name", `k\s`), []string{`back\slash`}},
+		{"prefix respects case and underscores", d.HasPrefix("name", "synthetic_user_"), []string{"synthetic_user_1"}},
+		{"prefix metacharacters are literal", d.HasPrefix("name", "a*"), []string{"a*b"}},
+	}
+	for _, tt := range matchTests {
+		got := names(tt.cond)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: matched %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	var fields struct {
+		Rating  int
+		Notes   string
+		Missing *string
+		Day     string
+	}
+	if err := db.Model(&dialectProbe{}).
+		Select("CAST("+d.JSONText("metadata", "session_rating")+" AS INTEGER) AS rating, "+
+			d.JSONText("metadata", "behavior_notes")+" AS notes, "+
+			d.JSONText("metadata", "medical_notes")+" AS missing, "+
+			d.Day("created_at")+" AS day").
+		Where("name = ?", "Rex").Scan(&fields).Error; err != nil {
+		t.Fatalf("select JSON and day fields: %v", err)
+	}
+	if fields.Rating != 4 || fields.Notes != "calm" || fields.Missing != nil {
+		t.Errorf("JSONText read rating=%d notes=%q missing=%v", fields.Rating, fields.Notes, fields.Missing)
+	}
+	if fields.Day != "2026-10-16" {
+		t.Errorf("Day = %q, want the UTC date 2026-10-16", fields.Day)
+	}
+}
+
+func TestEscapeGlob(t *testing.T) {
+	if got := escapeGlob("a*b?[c]"); got != "a[*]b[?][[]c]" {
+		t.Errorf("escapeGlob = %q", got)
+	}
+}
+
+func TestCreateCustomIndexes_SQLite(t *testing.T) {
+	db := openDialectTestSQLite(t)
+	if err := db.AutoMigrate(MigrationModels()...); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	if err := createCustomIndexes(db); err != nil {
+		t.Fatalf("createCustomIndexes: %v", err)
+	}
+
+	// The expression and partial indexes are portable; the Postgres-only
+	// search indexes are skipped rather than attempted
+	for table, index := range map[string]string{
+		"users":           "idx_users_username_lower",
+		"animals":         "idx_animals_name_lower",
+		"animal_images":   "idx_animal_images_profile_partial",
+		"user_skill_tags": "idx_user_skill_tag_group_name_active",
+	} {
+		if !db.Migrator().HasIndex(table, index) {
+			t.Errorf("index %s was not created on %s", index, table)
+		}
+	}
+	if db.Migrator().HasIndex("animals", "idx_animals_name_trgm") {
+		t.Errorf("trigram index should only exist on Postgres")
+	}
+}
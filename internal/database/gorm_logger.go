@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// defaultSlowQueryThreshold is how long a query may run before it is logged
+// as slow, unless DB_SLOW_QUERY_THRESHOLD_MS says otherwise.
+const defaultSlowQueryThreshold = time.Second
+
+// gormLogger sends GORM's logs through the logging package, so each query
+// log carries the request ID, user, and trace of the request that ran it.
+// SQL is logged with placeholders rather than values, keeping passwords and
+// personal data out of the logs.
+//
+// Failed queries are logged at the Error level and slow ones at Warn. Every
+// query is logged at Info with DB_LOG_LEVEL=info, or at Debug for a request
+// with per-request debug logging on (see middleware.DebugLogHeader).
+type gormLogger struct {
+	level         logger.LogLevel
+	slowThreshold time.Duration // 0 disables slow query logging
+}
+
+func newGormLogger(level logger.LogLevel, slowThreshold time.Duration) *gormLogger {
+	return &gormLogger{level: level, slowThreshold: slowThreshold}
+}
+
+// slowQueryThresholdFromEnv reads DB_SLOW_QUERY_THRESHOLD_MS. Setting
+// DB_QUERY_MONITORING_ENABLED=false turns slow query logging off.
+func slowQueryThresholdFromEnv() time.Duration {
+	if enabled := os.Getenv("DB_QUERY_MONITORING_ENABLED"); enabled == "false" || enabled == "0" {
+		return 0
+	}
+	ms := getEnvAsInt("DB_SLOW_QUERY_THRESHOLD_MS", int(defaultSlowQueryThreshold.Milliseconds()))
+	if ms <= 0 {
+		return defaultSlowQueryThreshold
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+func (l *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+func (l *gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Info {
+		logging.FromContext(ctx).Infof(msg, data...)
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Warn {
+		logging.FromContext(ctx).Warnf(msg, data...)
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Error {
+		logging.FromContext(ctx).Errorf(msg, data...)
+	}
+}
+
+// ParamsFilter drops query values, so fc in Trace returns SQL with
+// placeholders.
+func (l *gormLogger) ParamsFilter(_ context.Context, sql string, _ ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+// Scan and Row run their query through GORM's trace recorder, which filters
+// params with logger.RecorderParamsFilter rather than ours.
+func init() {
+	logger.RecorderParamsFilter = func(_ context.Context, sql string, _ ...interface{}) (string, []interface{}) {
+		return sql, nil
+	}
+}
+
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	fields := func() map[string]interface{} {
+		sql, rows := fc()
+		return map[string]interface{}{
+			"sql":         sql,
+			"rows":        rows,
+			"duration_ms": float64(elapsed.Microseconds()) / 1000,
+		}
+	}
+
+	log := logging.FromContext(ctx)
+	switch {
+	case err != nil && l.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		log.WithFields(fields()).Error("Database query failed", err)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= logger.Warn:
+		fs := fields()
+		fs["threshold_ms"] = l.slowThreshold.Milliseconds()
+		log.WithFields(fs).Warn("Slow database query")
+	case l.level >= logger.Info:
+		log.WithFields(fields()).Info("Database query")
+	case logging.DebugRequested(ctx):
+		log.WithFields(fields()).Debug("Database query")
+	}
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// captureLogs routes the default logger to a buffer for the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	old := logging.GetDefaultLogger()
+	logging.SetDefaultLogger(logging.New(logging.INFO, buf, true))
+	t.Cleanup(func() { logging.SetDefaultLogger(old) })
+	return buf
+}
+
+func TestGormLogger_Trace(t *testing.T) {
+	sql := func() (string, int64) { return "SELECT * FROM users WHERE email = ?", 1 }
+	ctx := logging.ContextWithRequestID(context.Background(), "req-42")
+
+	t.Run("fast query is not logged at warn level", func(t *testing.T) {
+		buf := captureLogs(t)
+		newGormLogger(logger.Warn, time.Second).Trace(ctx, time.Now(), sql, nil)
+		if buf.Len() > 0 {
+			t.Errorf("Expected no output, got: %s", buf.String())
+		}
+	})
+
+	t.Run("slow query is logged with the request ID", func(t *testing.T) {
+		buf := captureLogs(t)
+		newGormLogger(logger.Warn, 10*time.Millisecond).Trace(ctx, time.Now().Add(-50*time.Millisecond), sql, nil)
+		out := buf.String()
+		for _, want := range []string{"Slow database query", `"request_id":"req-42"`, "email = ?", `"threshold_ms":10`} {
+			if !strings.Contains(out, want) {
+				t.Errorf("Expected output to contain %q, got: %s", want, out)
+			}
+		}
+	})
+
+	t.Run("failed query is logged but not found is not", func(t *testing.T) {
+		buf := captureLogs(t)
+		l := newGormLogger(logger.Warn, time.Second)
+		l.Trace(ctx, time.Now(), sql, gorm.ErrRecordNotFound)
+		if buf.Len() > 0 {
+			t.Errorf("Expected no output for ErrRecordNotFound, got: %s", buf.String())
+		}
+		l.Trace(ctx, time.Now(), sql, errors.New("connection reset"))
+		if !strings.Contains(buf.String(), "Database query failed") {
+			t.Errorf("Expected failed query to be logged, got: %s", buf.String())
+		}
+	})
+
+	t.Run("per-request debug logs every query", func(t *testing.T) {
+		buf := captureLogs(t)
+		newGormLogger(logger.Warn, time.Second).Trace(logging.ContextWithDebug(ctx), time.Now(), sql, nil)
+		if !strings.Contains(buf.String(), `"level":"DEBUG"`) {
+			t.Errorf("Expected a debug query log, got: %s", buf.String())
+		}
+	})
+}
+
+func TestGormLogger_OmitsQueryValues(t *testing.T) {
+	buf := captureLogs(t)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: newGormLogger(logger.Info, time.Second)})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	var n int64
+	db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE name = ?", "s3cret-value").Scan(&n)
+
+	out := buf.String()
+	if !strings.Contains(out, "name = ?") {
+		t.Errorf("Expected SQL with placeholders, got: %s", out)
+	}
+	if strings.Contains(out, "s3cret-value") {
+		t.Errorf("Expected query values to be left out, got: %s", out)
+	}
+}
+
+func TestSlowQueryThresholdFromEnv(t *testing.T) {
+	t.Setenv("DB_SLOW_QUERY_THRESHOLD_MS", "250")
+	if got := slowQueryThresholdFromEnv(); got != 250*time.Millisecond {
+		t.Errorf("Expected 250ms, got %v", got)
+	}
+	t.Setenv("DB_QUERY_MONITORING_ENABLED", "false")
+	if got := slowQueryThresholdFromEnv(); got != 0 {
+		t.Errorf("Expected slow query logging to be off, got %v", got)
+	}
+}
@@ -0,0 +1,454 @@
+package database
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Synthetic rows are recognised by these name patterns, so they can be
+// wiped and regenerated without touching demo or real data.
+const (
+	syntheticGroupPrefix = "Synthetic Group "
+	syntheticUserPrefix  = "synthetic_user_"
+	syntheticEmailDomain = "@synthetic.invalid"
+
+	// SyntheticUserPassword is the password shared by every synthetic user.
+	SyntheticUserPassword = "synthetic1234"
+
+	syntheticCommentsPerAnimal = 6
+	syntheticBatchSize         = 500
+)
+
+// Entities that SyntheticOptions.Only can name.
+const (
+	SyntheticGroups   = "groups"
+	SyntheticUsers    = "users"
+	SyntheticAnimals  = "animals"
+	SyntheticComments = "comments"
+)
+
+// SyntheticOptions sizes a generated load-testing dataset.
+type SyntheticOptions struct {
+	Groups          int
+	AnimalsPerGroup int
+	Users           int
+	WithComments    bool
+	Seed            int64 // Same seed and sizes always produce the same data
+
+	// Only reseeds a single entity and everything that depends on it:
+	// groups → memberships, animals, comments; users → memberships,
+	// comments; animals → comments; comments → comments. Empty reseeds all.
+	Only string
+}
+
+// SyntheticResult counts the rows SeedSynthetic created.
+type SyntheticResult struct {
+	Groups      int
+	Users       int
+	Memberships int
+	Animals     int
+	Comments    int
+}
+
+// Validate checks the options before anything is deleted.
+func (o SyntheticOptions) Validate() error {
+	if o.Groups < 0 || o.AnimalsPerGroup < 0 || o.Users < 0 {
+		return fmt.Errorf("dataset sizes cannot be negative")
+	}
+	switch o.Only {
+	case "", SyntheticGroups, SyntheticUsers, SyntheticAnimals, SyntheticComments:
+	default:
+		return fmt.Errorf("unknown entity %q (want %s, %s, %s, or %s)", o.Only,
+			SyntheticGroups, SyntheticUsers, SyntheticAnimals, SyntheticComments)
+	}
+	if o.Only == SyntheticComments && !o.WithComments {
+		return fmt.Errorf("reseeding comments requires comments to be enabled")
+	}
+	return nil
+}
+
+// reseeds reports whether entity is regenerated under o.Only.
+func (o SyntheticOptions) reseeds(entity string) bool {
+	switch o.Only {
+	case "":
+		return true
+	case SyntheticGroups:
+		return entity != SyntheticUsers
+	case SyntheticUsers:
+		return entity == SyntheticUsers || entity == SyntheticComments
+	case SyntheticAnimals:
+		return entity == SyntheticAnimals || entity == SyntheticComments
+	default:
+		return entity == o.Only
+	}
+}
+
+// rng returns the random source for one entity. Each entity gets its own
+// stream, so reseeding only animals produces exactly the animals a full run
+// would have.
+func (o SyntheticOptions) rng(entity string) *rand.Rand {
+	h := fnv.New64a()
+	h.Write([]byte(entity))
+	return rand.New(rand.NewSource(o.Seed ^ int64(h.Sum64())))
+}
+
+// SeedSynthetic replaces the synthetic dataset (or the part selected by
+// opts.Only) with freshly generated rows. Existing synthetic rows are
+// hard-deleted first; other data is never touched.
+func SeedSynthetic(db *gorm.DB, opts SyntheticOptions) (SyntheticResult, error) {
+	var result SyntheticResult
+	if err := opts.Validate(); err != nil {
+		return result, err
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteSynthetic(tx, opts); err != nil {
+			return err
+		}
+
+		groups, err := syntheticGroupRows(tx, opts, &result)
+		if err != nil {
+			return err
+		}
+		users, err := syntheticUserRows(tx, opts, &result)
+		if err != nil {
+			return err
+		}
+		if opts.reseeds(SyntheticUsers) || opts.reseeds(SyntheticGroups) {
+			if result.Memberships, err = seedSyntheticMemberships(tx, opts, groups, users); err != nil {
+				return fmt.Errorf("failed to seed memberships: %w", err)
+			}
+		}
+
+		var animals []models.Animal
+		if opts.reseeds(SyntheticAnimals) {
+			if animals, err = seedSyntheticAnimals(tx, opts, groups); err != nil {
+				return fmt.Errorf("failed to seed animals: %w", err)
+			}
+			result.Animals = len(animals)
+		} else if err := tx.Where("group_id IN (?)", syntheticGroupIDs(tx)).Order("id").Find(&animals).Error; err != nil {
+			return fmt.Errorf("failed to load animals: %w", err)
+		}
+
+		if opts.WithComments && opts.reseeds(SyntheticComments) {
+			if result.Comments, err = seedSyntheticComments(tx, opts, animals); err != nil {
+				return fmt.Errorf("failed to seed comments: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return SyntheticResult{}, err
+	}
+
+	logging.WithFields(map[string]interface{}{
+		"groups": result.Groups, "users": result.Users, "memberships": result.Memberships,
+		"animals": result.Animals, "comments": result.Comments, "only": opts.Only,
+	}).Info("Synthetic dataset seeded")
+	return result, nil
+}
+
+// syntheticGroupNames matches synthetic group names. It's a prefix match
+// rather than a plain LIKE so that user-created groups differing only in
+// case are left alone on SQLite too.
+func syntheticGroupNames(db *gorm.DB) clause.Expr {
+	return DialectOf(db).HasPrefix("name", syntheticGroupPrefix)
+}
+
+// syntheticUsernames matches synthetic usernames. The prefix's underscores
+// are matched literally.
+func syntheticUsernames(db *gorm.DB) clause.Expr {
+	return DialectOf(db).HasPrefix("username", syntheticUserPrefix)
+}
+
+func syntheticGroupIDs(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Model(&models.Group{}).Select("id").Where(syntheticGroupNames(db))
+}
+
+func syntheticUserIDs(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Model(&models.User{}).Select("id").Where(syntheticUsernames(db))
+}
+
+func syntheticAnimalIDs(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Model(&models.Animal{}).Select("id").Where("group_id IN (?)", syntheticGroupIDs(db))
+}
+
+// syntheticDelete is one statement run by deleteSynthetic when its entity
+// is being reseeded.
+type syntheticDelete struct {
+	entity string
+	table  string
+	where  string
+	arg    interface{}
+}
+
+// deleteSynthetic hard-deletes the synthetic rows opts is about to
+// regenerate, plus rows the app may have attached to them during a load
+// test, children first so foreign keys hold.
+func deleteSynthetic(db *gorm.DB, opts SyntheticOptions) error {
+	// Comments on synthetic animals, and when users are reseeded, anything
+	// synthetic users wrote elsewhere
+	comments := db.Unscoped().Model(&models.AnimalComment{}).Select("id")
+	switch {
+	case opts.Only == SyntheticUsers:
+		comments = comments.Where("user_id IN (?)", syntheticUserIDs(db))
+	case opts.reseeds(SyntheticUsers):
+		comments = comments.Where("animal_id IN (?) OR user_id IN (?)", syntheticAnimalIDs(db), syntheticUserIDs(db))
+	default:
+		comments = comments.Where("animal_id IN (?)", syntheticAnimalIDs(db))
+	}
+
+	steps := []syntheticDelete{
+		{SyntheticComments, "animal_comment_tags", "animal_comment_id IN (?)", comments},
+		{SyntheticComments, "comment_histories", "comment_id IN (?)", comments},
+		{SyntheticComments, "animal_comments", "id IN (?)", comments},
+	}
+	for _, table := range []string{"animal_comments", "animal_animal_tags", "animal_name_histories", "animal_bq_incidents",
+		"animal_images", "animal_videos", "weight_entries", "animal_views", "animal_share_links"} {
+		steps = append(steps, syntheticDelete{SyntheticAnimals, table, "animal_id IN (?)", syntheticAnimalIDs(db)})
+	}
+	steps = append(steps,
+		syntheticDelete{SyntheticAnimals, "animals", "group_id IN (?)", syntheticGroupIDs(db)},
+		syntheticDelete{SyntheticUsers, "user_groups", "user_id IN (?)", syntheticUserIDs(db)},
+		syntheticDelete{SyntheticUsers, "api_tokens", "user_id IN (?)", syntheticUserIDs(db)},
+		syntheticDelete{SyntheticUsers, "animal_views", "user_id IN (?)", syntheticUserIDs(db)},
+		syntheticDelete{SyntheticUsers, "users", "?", syntheticUsernames(db)},
+		syntheticDelete{SyntheticGroups, "user_groups", "group_id IN (?)", syntheticGroupIDs(db)},
+	)
+	for _, table := range []string{"updates", "comment_tags", "animal_tags", "animal_statuses", "user_skill_tags"} {
+		steps = append(steps, syntheticDelete{SyntheticGroups, table, "group_id IN (?)", syntheticGroupIDs(db)})
+	}
+	steps = append(steps, syntheticDelete{SyntheticGroups, "groups", "?", syntheticGroupNames(db)})
+
+	if opts.reseeds(SyntheticGroups) {
+		if err := db.Unscoped().Model(&models.User{}).Where("default_group_id IN (?)", syntheticGroupIDs(db)).
+			Update("default_group_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to clear default groups: %w", err)
+		}
+	}
+	for _, step := range steps {
+		if !opts.reseeds(step.entity) {
+			continue
+		}
+		if err := db.Exec("DELETE FROM "+step.table+" WHERE "+step.where, step.arg).Error; err != nil {
+			return fmt.Errorf("failed to clear synthetic %s from %s: %w", step.entity, step.table, err)
+		}
+	}
+	return nil
+}
+
+// syntheticGroupRows creates the synthetic groups, or loads the existing
+// ones when groups aren't being reseeded.
+func syntheticGroupRows(db *gorm.DB, opts SyntheticOptions, result *SyntheticResult) ([]models.Group, error) {
+	var groups []models.Group
+	if !opts.reseeds(SyntheticGroups) {
+		if err := db.Where(syntheticGroupNames(db)).Order("name").Find(&groups).Error; err != nil {
+			return nil, fmt.Errorf("failed to load groups: %w", err)
+		}
+		return groups, nil
+	}
+
+	r := opts.rng(SyntheticGroups)
+	for i := 1; i <= opts.Groups; i++ {
+		groups = append(groups, models.Group{
+			Name:        fmt.Sprintf("%s%03d", syntheticGroupPrefix, i),
+			Description: fmt.Sprintf("Load-testing group for %s.", pick(r, syntheticGroupFocus)),
+		})
+	}
+	if len(groups) > 0 {
+		if err := db.CreateInBatches(&groups, syntheticBatchSize).Error; err != nil {
+			return nil, fmt.Errorf("failed to seed groups: %w", err)
+		}
+	}
+	result.Groups = len(groups)
+	return groups, nil
+}
+
+// syntheticUserRows creates the synthetic users, or loads the existing ones
+// when users aren't being reseeded. All share SyntheticUserPassword, hashed
+// once: bcrypt per user would dominate the run time for large datasets.
+func syntheticUserRows(db *gorm.DB, opts SyntheticOptions, result *SyntheticResult) ([]models.User, error) {
+	var users []models.User
+	if !opts.reseeds(SyntheticUsers) {
+		if err := db.Where(syntheticUsernames(db)).Order("username").Find(&users).Error; err != nil {
+			return nil, fmt.Errorf("failed to load users: %w", err)
+		}
+		return users, nil
+	}
+	if opts.Users == 0 {
+		return nil, nil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(SyntheticUserPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	r := opts.rng(SyntheticUsers)
+	for i := 1; i <= opts.Users; i++ {
+		username := fmt.Sprintf("%s%05d", syntheticUserPrefix, i)
+		users = append(users, models.User{
+			Username:    username,
+			FirstName:   pick(r, syntheticFirstNames),
+			LastName:    pick(r, syntheticLastNames),
+			Email:       username + syntheticEmailDomain,
+			Password:    string(hash),
+			PhoneNumber: fmt.Sprintf("(555) %03d-%04d", 200+i/10000, i%10000),
+		})
+	}
+	if err := db.CreateInBatches(&users, syntheticBatchSize).Error; err != nil {
+		return nil, fmt.Errorf("failed to seed users: %w", err)
+	}
+	result.Users = len(users)
+	return users, nil
+}
+
+// seedSyntheticMemberships puts every user in one to three groups. The
+// first user of each group is its group admin.
+func seedSyntheticMemberships(db *gorm.DB, opts SyntheticOptions, groups []models.Group, users []models.User) (int, error) {
+	if len(groups) == 0 || len(users) == 0 {
+		return 0, nil
+	}
+	r := opts.rng("memberships")
+	adminOf := make(map[uint]bool)
+	var rows []models.UserGroup
+	for i, u := range users {
+		home := i % len(groups)
+		picked := map[int]bool{home: true}
+		for extra := r.Intn(3); extra > 0; extra-- {
+			picked[r.Intn(len(groups))] = true
+		}
+		for g := 0; g < len(groups); g++ {
+			if !picked[g] {
+				continue
+			}
+			gid := groups[g].ID
+			rows = append(rows, models.UserGroup{UserID: u.ID, GroupID: gid, IsGroupAdmin: g == home && !adminOf[gid]})
+			if g == home {
+				adminOf[gid] = true
+			}
+		}
+	}
+	if err := db.CreateInBatches(&rows, syntheticBatchSize).Error; err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+func seedSyntheticAnimals(db *gorm.DB, opts SyntheticOptions, groups []models.Group) ([]models.Animal, error) {
+	r := opts.rng(SyntheticAnimals)
+	now := time.Now()
+	var animals []models.Animal
+	for _, g := range groups {
+		for i := 0; i < opts.AnimalsPerGroup; i++ {
+			arrival := now.AddDate(0, 0, -r.Intn(365))
+			birth := arrival.AddDate(-r.Intn(12), -r.Intn(12), 0)
+			animal := models.Animal{
+				GroupID:            g.ID,
+				Name:               pick(r, syntheticAnimalNames),
+				Species:            "Dog",
+				Breed:              pick(r, syntheticBreeds),
+				EstimatedBirthDate: &birth,
+				Description:        pick(r, syntheticDescriptions),
+				Status:             "available",
+				ArrivalDate:        &arrival,
+				LastStatusChange:   &arrival,
+			}
+			// Roughly 70% available, the rest spread over the other built-in statuses
+			switch n := r.Intn(10); {
+			case n == 7:
+				animal.Status = "foster"
+				animal.FosterStartDate = &now
+			case n == 8:
+				animal.Status = "under_vet_care"
+			case n == 9:
+				animal.Status = "archived"
+				animal.ArchivedDate = &now
+			}
+			animals = append(animals, animal)
+		}
+	}
+	if len(animals) > 0 {
+		if err := db.CreateInBatches(&animals, syntheticBatchSize).Error; err != nil {
+			return nil, err
+		}
+	}
+	return animals, nil
+}
+
+// seedSyntheticComments gives each animal up to twice
+// syntheticCommentsPerAnimal comments, written by members of its group over
+// the past 90 days.
+func seedSyntheticComments(db *gorm.DB, opts SyntheticOptions, animals []models.Animal) (int, error) {
+	var memberships []models.UserGroup
+	if err := db.Where("user_id IN (?)", syntheticUserIDs(db)).Order("group_id, user_id").Find(&memberships).Error; err != nil {
+		return 0, err
+	}
+	members := make(map[uint][]uint)
+	for _, m := range memberships {
+		members[m.GroupID] = append(members[m.GroupID], m.UserID)
+	}
+
+	r := opts.rng(SyntheticComments)
+	now := time.Now()
+	var comments []models.AnimalComment
+	for _, a := range animals {
+		authors := members[a.GroupID]
+		if len(authors) == 0 {
+			continue
+		}
+		for n := r.Intn(2*syntheticCommentsPerAnimal + 1); n > 0; n-- {
+			created := now.Add(-time.Duration(r.Intn(90*24*60)) * time.Minute)
+			comments = append(comments, models.AnimalComment{
+				AnimalID:  a.ID,
+				UserID:    authors[r.Intn(len(authors))],
+				Content:   fmt.Sprintf(pick(r, syntheticCommentTemplates), a.Name),
+				CreatedAt: created,
+				UpdatedAt: created,
+			})
+		}
+	}
+	if len(comments) > 0 {
+		if err := db.CreateInBatches(&comments, syntheticBatchSize).Error; err != nil {
+			return 0, err
+		}
+	}
+	return len(comments), nil
+}
+
+func pick(r *rand.Rand, options []string) string {
+	return options[r.Intn(len(options))]
+}
+
+var (
+	syntheticGroupFocus   = []string{"dog walking", "cat socialization", "kennel enrichment", "foster coordination", "adoption events", "transport"}
+	syntheticFirstNames   = []string{"Avery", "Blake", "Cameron", "Dakota", "Emerson", "Finley", "Harper", "Jamie", "Kendall", "Logan", "Morgan", "Parker", "Quinn", "Reese", "Riley", "Rowan", "Sawyer", "Skyler", "Taylor", "Wren"}
+	syntheticLastNames    = []string{"Alvarez", "Bennett", "Chen", "Dubois", "Evans", "Fischer", "Garcia", "Haddad", "Ito", "Johansson", "Kim", "Larsen", "Mensah", "Novak", "Okafor", "Patel", "Rossi", "Schmidt", "Tanaka", "Walsh"}
+	syntheticAnimalNames  = []string{"Apollo", "Bailey", "Biscuit", "Blue", "Coco", "Daisy", "Diesel", "Duke", "Ginger", "Hank", "Juno", "Koda", "Loki", "Lola", "Maple", "Milo", "Nala", "Oreo", "Peanut", "Pepper", "Rosie", "Scout", "Sadie", "Tank", "Winnie", "Ziggy"}
+	syntheticBreeds       = []string{"Labrador Retriever mix", "Pit Bull Terrier", "German Shepherd mix", "Boxer", "Beagle", "Husky mix", "Chihuahua", "Cattle Dog mix", "Hound mix", "Terrier mix"}
+	syntheticDescriptions = []string{
+		"Friendly and energetic, loves long walks and squeaky toys.",
+		"Shy at first but warms up quickly with treats and patience.",
+		"Knows sit and down, working on loose-leash walking.",
+		"Gentle with other dogs; prefers a calm home without cats.",
+		"Couch potato who enjoys short strolls and belly rubs.",
+		"High drive and very smart — would thrive with an active family.",
+	}
+	syntheticCommentTemplates = []string{
+		"Took %s for a long walk today, great on the leash.",
+		"%s was a bit nervous around the new volunteers but settled down.",
+		"Worked on sit and stay with %s, good progress.",
+		"%s played well in yard group this afternoon.",
+		"Gave %s a bath — very tolerant of grooming.",
+		"%s met a potential adopter today, went well!",
+		"Noticed %s limping slightly after play, flagging for staff.",
+		"%s napped in the office for an hour, very relaxed.",
+	}
+)
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openSyntheticTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open in-memory sqlite db: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(MigrationModels()...); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func syntheticAnimalNamesInDB(t *testing.T, db *gorm.DB) []string {
+	t.Helper()
+	var names []string
+	if err := db.Model(&models.Animal{}).Order("id").Pluck("name", &names).Error; err != nil {
+		t.Fatalf("failed to list animals: %v", err)
+	}
+	return names
+}
+
+func TestSeedSynthetic(t *testing.T) {
+	db := openSyntheticTestDB(t)
+
+	// Pre-existing data that reseeding must leave alone
+	realGroup := models.Group{Name: "modsquad"}
+	db.Create(&realGroup)
+	realUser := models.User{Username: "realuser", Email: "real@example.org", Password: "x"}
+	db.Create(&realUser)
+
+	opts := SyntheticOptions{Groups: 3, AnimalsPerGroup: 4, Users: 10, WithComments: true, Seed: 7}
+	result, err := SeedSynthetic(db, opts)
+	if err != nil {
+		t.Fatalf("SeedSynthetic: %v", err)
+	}
+	if result.Groups != 3 || result.Users != 10 || result.Animals != 12 || result.Memberships < 10 || result.Comments == 0 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	var admins int64
+	db.Model(&models.UserGroup{}).Where("is_group_admin = ?", true).Count(&admins)
+	if admins != 3 {
+		t.Errorf("%d group admins, want one per group", admins)
+	}
+	firstNames := syntheticAnimalNamesInDB(t, db)
+
+	// Reseeding with the same options replaces the data with identical data
+	again, err := SeedSynthetic(db, opts)
+	if err != nil {
+		t.Fatalf("second SeedSynthetic: %v", err)
+	}
+	if again != result {
+		t.Errorf("reseed result %+v, want %+v", again, result)
+	}
+	if got := syntheticAnimalNamesInDB(t, db); len(got) != len(firstNames) {
+		t.Errorf("reseed produced %d animals, want %d", len(got), len(firstNames))
+	} else {
+		for i := range got {
+			if got[i] != firstNames[i] {
+				t.Fatalf("reseed is not deterministic: %v vs %v", got, firstNames)
+			}
+		}
+	}
+
+	// --only=animals keeps users and memberships, regenerates animals and comments
+	var userIDsBefore []uint
+	db.Model(&models.User{}).Order("id").Pluck("id", &userIDsBefore)
+	onlyAnimals := opts
+	onlyAnimals.Only = SyntheticAnimals
+	partial, err := SeedSynthetic(db, onlyAnimals)
+	if err != nil {
+		t.Fatalf("SeedSynthetic(only animals): %v", err)
+	}
+	if partial.Users != 0 || partial.Groups != 0 || partial.Memberships != 0 || partial.Animals != 12 || partial.Comments != result.Comments {
+		t.Errorf("only=animals result %+v", partial)
+	}
+	var userIDsAfter []uint
+	db.Model(&models.User{}).Order("id").Pluck("id", &userIDsAfter)
+	if len(userIDsAfter) != len(userIDsBefore) || userIDsAfter[0] != userIDsBefore[0] || userIDsAfter[len(userIDsAfter)-1] != userIDsBefore[len(userIDsBefore)-1] {
+		t.Errorf("only=animals touched users: %v -> %v", userIDsBefore, userIDsAfter)
+	}
+
+	// --only=comments with a different seed changes only comments
+	onlyComments := opts
+	onlyComments.Only = SyntheticComments
+	onlyComments.Seed = 8
+	if _, err := SeedSynthetic(db, onlyComments); err != nil {
+		t.Fatalf("SeedSynthetic(only comments): %v", err)
+	}
+	var animalCount int64
+	db.Model(&models.Animal{}).Count(&animalCount)
+	if animalCount != 12 {
+		t.Errorf("only=comments changed animals: %d", animalCount)
+	}
+
+	// Non-synthetic rows survive every reseed
+	if err := db.First(&models.Group{}, realGroup.ID).Error; err != nil {
+		t.Errorf("real group deleted: %v", err)
+	}
+	if err := db.First(&models.User{}, realUser.ID).Error; err != nil {
+		t.Errorf("real user deleted: %v", err)
+	}
+}
+
+func TestSyntheticOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    SyntheticOptions
+		wantErr bool
+	}{
+		{"defaults", SyntheticOptions{Groups: 1}, false},
+		{"negative size", SyntheticOptions{Users: -1}, true},
+		{"unknown entity", SyntheticOptions{Only: "protocols"}, true},
+		{"comments without comments enabled", SyntheticOptions{Only: SyntheticComments}, true},
+		{"comments", SyntheticOptions{Only: SyntheticComments, WithComments: true}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
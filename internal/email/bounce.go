@@ -0,0 +1,64 @@
+package email
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SuppressionReason says why a provider reported an address as undeliverable
+type SuppressionReason string
+
+const (
+	SuppressionBounce    SuppressionReason = "bounce"    // Permanent (hard) bounce
+	SuppressionComplaint SuppressionReason = "complaint" // Recipient marked an email as spam
+)
+
+// Suppression is one address a provider reported as undeliverable. Future
+// sends to it are suppressed until the address changes or an admin clears it.
+type Suppression struct {
+	Email  string
+	Reason SuppressionReason
+	Detail string // Provider's diagnostic, e.g. "smtp; 550 5.1.1 user unknown"
+}
+
+var (
+	// ErrRecipientSuppressed is returned by SendEmail when the recipient's
+	// address is marked undeliverable
+	ErrRecipientSuppressed = errors.New("recipient address is marked undeliverable")
+	// ErrInvalidWebhookSignature is returned when a bounce webhook's
+	// signature doesn't verify
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+)
+
+// maxWebhookClockSkew is how far a bounce webhook's signed timestamp may be
+// from now. Requests outside it are rejected, so a captured request can't be
+// replayed later.
+const maxWebhookClockSkew = 5 * time.Minute
+
+// checkWebhookTimestamp rejects a signed timestamp more than
+// maxWebhookClockSkew from now
+func checkWebhookTimestamp(signed time.Time) error {
+	if skew := time.Since(signed); skew > maxWebhookClockSkew || skew < -maxWebhookClockSkew {
+		return fmt.Errorf("%w: timestamp %s is outside the allowed window", ErrInvalidWebhookSignature, signed.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// maxSuppressionDetail bounds the stored provider diagnostic
+const maxSuppressionDetail = 500
+
+// newSuppression normalizes a reported address and diagnostic. It returns
+// false for an empty address.
+func newSuppression(address string, reason SuppressionReason, detail string) (Suppression, bool) {
+	address = strings.ToLower(strings.TrimSpace(address))
+	if address == "" {
+		return Suppression{}, false
+	}
+	detail = strings.TrimSpace(detail)
+	if len(detail) > maxSuppressionDetail {
+		detail = detail[:maxSuppressionDetail]
+	}
+	return Suppression{Email: address, Reason: reason, Detail: detail}, true
+}
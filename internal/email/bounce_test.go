@@ -0,0 +1,331 @@
+package email
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+)
+
+func TestSendGridBounceWebhook(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	webhook, err := newSendGridBounceWebhook(base64.StdEncoding.EncodeToString(der))
+	if err != nil {
+		t.Fatalf("newSendGridBounceWebhook: %v", err)
+	}
+
+	sign := func(timestamp string, body []byte) string {
+		digest := sha256.Sum256(append([]byte(timestamp), body...))
+		sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.StdEncoding.EncodeToString(sig)
+	}
+
+	body := []byte(`[
+		{"email": "Gone@Example.com", "event": "bounce", "type": "bounce", "reason": "550 5.1.1 user unknown"},
+		{"email": "busy@example.com", "event": "bounce", "type": "blocked", "reason": "421 try again later"},
+		{"email": "angry@example.com", "event": "spamreport"},
+		{"email": "fine@example.com", "event": "delivered"}
+	]`)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	suppressions, err := webhook.Parse(body, sign(now, body), now)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := []Suppression{
+		{Email: "gone@example.com", Reason: SuppressionBounce, Detail: "550 5.1.1 user unknown"},
+		{Email: "angry@example.com", Reason: SuppressionComplaint},
+	}
+	if len(suppressions) != len(want) {
+		t.Fatalf("got %+v, want %+v", suppressions, want)
+	}
+	for i := range want {
+		if suppressions[i] != want[i] {
+			t.Errorf("suppression %d = %+v, want %+v", i, suppressions[i], want[i])
+		}
+	}
+
+	// The timestamp is part of the signed content
+	later := strconv.FormatInt(time.Now().Unix()+1, 10)
+	if _, err := webhook.Parse(body, sign(now, body), later); !errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Errorf("altered timestamp: got %v, want ErrInvalidWebhookSignature", err)
+	}
+	tampered := []byte(`[{"email": "admin@example.com", "event": "bounce"}]`)
+	if _, err := webhook.Parse(tampered, sign(now, body), now); !errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Errorf("altered body: got %v, want ErrInvalidWebhookSignature", err)
+	}
+	// A correctly signed request is rejected once it's stale, so a captured
+	// request can't be replayed
+	for _, ts := range []time.Time{time.Now().Add(-6 * time.Minute), time.Now().Add(6 * time.Minute)} {
+		stale := strconv.FormatInt(ts.Unix(), 10)
+		if _, err := webhook.Parse(body, sign(stale, body), stale); !errors.Is(err, ErrInvalidWebhookSignature) {
+			t.Errorf("timestamp %s: got %v, want ErrInvalidWebhookSignature", stale, err)
+		}
+	}
+	if _, err := webhook.Parse(body, sign("soon", body), "soon"); !errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Errorf("non-numeric timestamp: got %v, want ErrInvalidWebhookSignature", err)
+	}
+	if _, err := webhook.Parse(body, "", ""); !errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Errorf("unsigned: got %v, want ErrInvalidWebhookSignature", err)
+	}
+
+	if _, err := newSendGridBounceWebhook("not base64!"); err == nil {
+		t.Error("expected an error for an invalid key")
+	}
+}
+
+// snsTestServer serves a self-signed SNS signing certificate and counts
+// subscription confirmations
+type snsTestServer struct {
+	*httptest.Server
+	key       *rsa.PrivateKey
+	confirmed atomic.Int32
+}
+
+func newSNSTestServer(t *testing.T) *snsTestServer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.amazonaws.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	s := &snsTestServer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/SimpleNotificationService-test.pem", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(certPEM)
+	})
+	mux.HandleFunc("/confirm", func(w http.ResponseWriter, r *http.Request) {
+		s.confirmed.Add(1)
+	})
+	s.Server = httptest.NewTLSServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// webhook returns an SESBounceWebhook that trusts the test server's host
+func (s *snsTestServer) webhook(topics ...string) *SESBounceWebhook {
+	return &SESBounceWebhook{
+		TopicARNs:   topics,
+		client:      s.Client(),
+		hostPattern: regexp.MustCompile(`^127\.0\.0\.1$`),
+		certs:       map[string]*x509.Certificate{},
+	}
+}
+
+// sign fills in the message's signature fields and returns it as JSON
+func (s *snsTestServer) sign(t *testing.T, m snsMessage, version string) []byte {
+	t.Helper()
+	m.SignatureVersion = version
+	m.SigningCertURL = s.URL + "/SimpleNotificationService-test.pem"
+	var (
+		hash   crypto.Hash
+		digest []byte
+	)
+	if version == "1" {
+		sum := sha1.Sum([]byte(m.stringToSign()))
+		hash, digest = crypto.SHA1, sum[:]
+	} else {
+		sum := sha256.Sum256([]byte(m.stringToSign()))
+		hash, digest = crypto.SHA256, sum[:]
+	}
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, hash, digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Signature = base64.StdEncoding.EncodeToString(sig)
+	body, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func TestSESBounceWebhook(t *testing.T) {
+	const topic = "arn:aws:sns:us-east-1:123456789012:ses-bounces"
+	server := newSNSTestServer(t)
+	webhook := server.webhook(topic)
+	ctx := context.Background()
+
+	notification := func(message string) snsMessage {
+		return snsMessage{
+			Type:      "Notification",
+			MessageID: "22b80b92-fdea-4c2c-8f9d-bdfb0c7bf324",
+			TopicArn:  topic,
+			Message:   message,
+			Timestamp: time.Now().UTC().Format("2006-01-02T15:04:05.000Z"),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		message string
+		want    []Suppression
+	}{
+		{
+			name:    "permanent bounce",
+			message: `{"notificationType":"Bounce","bounce":{"bounceType":"Permanent","bouncedRecipients":[{"emailAddress":"Gone@Example.com","diagnosticCode":"smtp; 550 5.1.1 user unknown"}]}}`,
+			want:    []Suppression{{Email: "gone@example.com", Reason: SuppressionBounce, Detail: "smtp; 550 5.1.1 user unknown"}},
+		},
+		{
+			name:    "transient bounce is ignored",
+			message: `{"notificationType":"Bounce","bounce":{"bounceType":"Transient","bouncedRecipients":[{"emailAddress":"full@example.com"}]}}`,
+		},
+		{
+			name:    "complaint from event publishing",
+			message: `{"eventType":"Complaint","complaint":{"complaintFeedbackType":"abuse","complainedRecipients":[{"emailAddress":"angry@example.com"}]}}`,
+			want:    []Suppression{{Email: "angry@example.com", Reason: SuppressionComplaint, Detail: "abuse"}},
+		},
+		{
+			name:    "delivery is ignored",
+			message: `{"notificationType":"Delivery"}`,
+		},
+	}
+	for _, tt := range tests {
+		for _, version := range []string{"1", "2"} {
+			t.Run(tt.name+" v"+version, func(t *testing.T) {
+				got, err := webhook.Parse(ctx, server.sign(t, notification(tt.message), version))
+				if err != nil {
+					t.Fatalf("Parse: %v", err)
+				}
+				if len(got) != len(tt.want) {
+					t.Fatalf("got %+v, want %+v", got, tt.want)
+				}
+				for i := range tt.want {
+					if got[i] != tt.want[i] {
+						t.Errorf("suppression %d = %+v, want %+v", i, got[i], tt.want[i])
+					}
+				}
+			})
+		}
+	}
+
+	t.Run("tampered message is rejected", func(t *testing.T) {
+		var m snsMessage
+		if err := json.Unmarshal(server.sign(t, notification(tests[0].message), "2"), &m); err != nil {
+			t.Fatal(err)
+		}
+		m.Message = `{"notificationType":"Bounce","bounce":{"bounceType":"Permanent","bouncedRecipients":[{"emailAddress":"admin@example.com"}]}}`
+		body, _ := json.Marshal(m)
+		if _, err := webhook.Parse(ctx, body); !errors.Is(err, ErrInvalidWebhookSignature) {
+			t.Errorf("got %v, want ErrInvalidWebhookSignature", err)
+		}
+	})
+
+	t.Run("other topics are rejected", func(t *testing.T) {
+		m := notification(tests[0].message)
+		m.TopicArn = "arn:aws:sns:us-east-1:999999999999:someone-else"
+		if _, err := webhook.Parse(ctx, server.sign(t, m, "2")); !errors.Is(err, ErrInvalidWebhookSignature) {
+			t.Errorf("got %v, want ErrInvalidWebhookSignature", err)
+		}
+	})
+
+	t.Run("stale messages are rejected", func(t *testing.T) {
+		for _, ts := range []time.Time{time.Now().Add(-6 * time.Minute), time.Now().Add(6 * time.Minute)} {
+			m := notification(tests[0].message)
+			m.Timestamp = ts.UTC().Format("2006-01-02T15:04:05.000Z")
+			if _, err := webhook.Parse(ctx, server.sign(t, m, "2")); !errors.Is(err, ErrInvalidWebhookSignature) {
+				t.Errorf("timestamp %s: got %v, want ErrInvalidWebhookSignature", m.Timestamp, err)
+			}
+		}
+	})
+
+	t.Run("certificates must come from SNS", func(t *testing.T) {
+		untrusted := &SESBounceWebhook{
+			TopicARNs:   []string{topic},
+			client:      server.Client(),
+			hostPattern: snsHostPattern,
+			certs:       map[string]*x509.Certificate{},
+		}
+		if _, err := untrusted.Parse(ctx, server.sign(t, notification(tests[0].message), "2")); !errors.Is(err, ErrInvalidWebhookSignature) {
+			t.Errorf("got %v, want ErrInvalidWebhookSignature", err)
+		}
+	})
+
+	t.Run("subscription confirmation", func(t *testing.T) {
+		m := snsMessage{
+			Type:         "SubscriptionConfirmation",
+			MessageID:    "165545c9-2a5c-472c-8df2-7ff2be2b3b1b",
+			Token:        "2336412f37f",
+			TopicArn:     topic,
+			Message:      "You have chosen to subscribe to the topic.",
+			SubscribeURL: server.URL + "/confirm",
+			Timestamp:    time.Now().UTC().Format("2006-01-02T15:04:05.000Z"),
+		}
+		got, err := webhook.Parse(ctx, server.sign(t, m, "1"))
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		if len(got) != 0 {
+			t.Errorf("expected no suppressions, got %+v", got)
+		}
+		if server.confirmed.Load() != 1 {
+			t.Errorf("expected the subscription to be confirmed once, got %d", server.confirmed.Load())
+		}
+	})
+}
+
+func TestSendEmail_SuppressesUndeliverableRecipients(t *testing.T) {
+	db := setupTestDB(t)
+	if err := db.AutoMigrate(&models.User{}); err != nil {
+		t.Fatalf("Failed to migrate users: %v", err)
+	}
+	users := []models.User{
+		{Username: "gone", Email: "Gone@example.com", Password: "x", EmailUndeliverable: true, EmailUndeliverableReason: "bounce"},
+		{Username: "fine", Email: "fine@example.com", Password: "x"},
+	}
+	if err := db.Create(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	provider := &mockEmailProvider{configured: true}
+	service := NewServiceWithProvider(provider, db)
+
+	if err := service.SendEmail(context.Background(), "gone@example.com", "Hi", "<p>Hi</p>"); !errors.Is(err, ErrRecipientSuppressed) {
+		t.Errorf("got %v, want ErrRecipientSuppressed", err)
+	}
+	if err := service.SendEmail(context.Background(), "fine@example.com", "Hi", "<p>Hi</p>"); err != nil {
+		t.Errorf("SendEmail: %v", err)
+	}
+	if len(provider.sentEmails) != 1 || provider.sentEmails[0].to != "fine@example.com" {
+		t.Errorf("expected only fine@example.com to be emailed, got %+v", provider.sentEmails)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"html"
+	htmltemplate "html/template"
 	"net/url"
 	"os"
 	"regexp"
@@ -36,12 +37,13 @@ func NewService(db *gorm.DB) *Service {
 	if err != nil {
 		// If provider creation fails, return a service with nil provider
 		// This allows the application to start even if email is misconfigured
+		logging.Warn(fmt.Sprintf("Email disabled: %v", err))
 		return &Service{provider: nil, db: db}
 	}
 	s := &Service{provider: provider, db: db}
 	// Preload cache synchronously on initialization
 	if db != nil {
-		s.refreshSettingsCache()
+		s.refreshSettingsCache(context.Background())
 	}
 	return s
 }
@@ -53,7 +55,7 @@ func NewServiceWithProvider(provider Provider, db *gorm.DB) *Service {
 	s := &Service{provider: provider, db: db}
 	// Preload cache synchronously on initialization
 	if db != nil {
-		s.refreshSettingsCache()
+		s.refreshSettingsCache(context.Background())
 	}
 	return s
 }
@@ -63,6 +65,27 @@ func (s *Service) IsConfigured() bool {
 	return s.provider != nil && s.provider.IsConfigured()
 }
 
+// ProviderName returns the configured provider's name for diagnostics, or
+// "none" when no provider could be created.
+func (s *Service) ProviderName() string {
+	if s.provider == nil {
+		return "none"
+	}
+	return s.provider.GetProviderName()
+}
+
+// CheckHealth runs the provider's health check, if it has one. It returns
+// nil for providers that can't check their own health.
+func (s *Service) CheckHealth(ctx context.Context) error {
+	if s.provider == nil {
+		return fmt.Errorf("email provider is not configured")
+	}
+	if hc, ok := s.provider.(HealthChecker); ok {
+		return hc.CheckHealth(ctx)
+	}
+	return nil
+}
+
 // isValidEmail validates an email address using basic RFC 5322 rules
 func isValidEmail(email string) bool {
 	return emailRegex.MatchString(email)
@@ -70,14 +93,15 @@ func isValidEmail(email string) bool {
 
 // refreshSettingsCache fetches all site settings from the database and caches them
 // with a 5-minute TTL. Called on service initialization and when cache expires.
-func (s *Service) refreshSettingsCache() {
+func (s *Service) refreshSettingsCache(ctx context.Context) {
 	if s.db == nil {
 		return
 	}
+	logger := logging.FromContext(ctx)
 
 	var settings []models.SiteSetting
-	if err := s.db.Find(&settings).Error; err != nil {
-		logging.WithFields(map[string]interface{}{
+	if err := s.db.WithContext(ctx).Find(&settings).Error; err != nil {
+		logger.WithFields(map[string]interface{}{
 			"error": err.Error(),
 		}).Error("Failed to refresh site settings cache", err)
 		// On error, keep existing cache or leave empty
@@ -96,7 +120,7 @@ func (s *Service) refreshSettingsCache() {
 	s.cacheExpiry = time.Now().Add(5 * time.Minute)
 	s.cacheMu.Unlock()
 
-	logging.WithFields(map[string]interface{}{
+	logger.WithFields(map[string]interface{}{
 		"settings_count": len(settings),
 		"cache_expiry":   s.cacheExpiry.Format(time.RFC3339),
 	}).Debug("Site settings cache refreshed successfully")
@@ -104,7 +128,7 @@ func (s *Service) refreshSettingsCache() {
 
 // getSiteName fetches the site name from cache, falls back to default if not found
 // Cache is refreshed automatically when expired (5-minute TTL).
-func (s *Service) getSiteName() string {
+func (s *Service) getSiteName(ctx context.Context) string {
 	if s.db == nil {
 		return models.DefaultSiteName
 	}
@@ -122,7 +146,7 @@ func (s *Service) getSiteName() string {
 	// Slow path: cache expired or empty - refresh needed
 	// Use atomic flag to prevent thundering herd (multiple goroutines refreshing simultaneously)
 	if s.refreshing.CompareAndSwap(false, true) {
-		s.refreshSettingsCache()
+		s.refreshSettingsCache(ctx)
 		s.refreshing.Store(false)
 	}
 
@@ -150,6 +174,12 @@ func (s *Service) SendEmail(ctx context.Context, to, subject, htmlBody string) e
 		return fmt.Errorf("invalid email address: %s", to)
 	}
 
+	// Providers reported this address as bouncing or complaining; sending
+	// again only hurts the sender's reputation
+	if s.isSuppressed(ctx, to) {
+		return ErrRecipientSuppressed
+	}
+
 	// Bound the send with its own timeout. context.WithoutCancel detaches
 	// from the caller's cancellation signal — a client disconnecting mid
 	// -request must not abort an in-flight password-reset/invite email send
@@ -158,7 +188,34 @@ func (s *Service) SendEmail(ctx context.Context, to, subject, htmlBody string) e
 	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
 	defer cancel()
 
-	return s.provider.SendEmail(ctx, to, subject, htmlBody)
+	// Recipients and subjects stay out of the logs; the request ID ties a
+	// send to the request that triggered it.
+	start := time.Now()
+	err := s.provider.SendEmail(ctx, to, subject, htmlBody)
+	logger := logging.FromContext(ctx).WithFields(map[string]interface{}{
+		"email_provider": s.provider.GetProviderName(),
+		"duration_ms":    time.Since(start).Milliseconds(),
+	})
+	if err != nil {
+		// Callers decide whether a failed send is an error worth alerting on
+		logger.WithField("error", err.Error()).Warn("Email send failed")
+		return err
+	}
+	logger.Debug("Email sent")
+	return nil
+}
+
+// isSuppressed reports whether a user with this address is marked
+// undeliverable. A lookup failure doesn't block the send.
+func (s *Service) isSuppressed(ctx context.Context, to string) bool {
+	if s.db == nil {
+		return false
+	}
+	var count int64
+	err := s.db.WithContext(ctx).Model(&models.User{}).
+		Where("LOWER(email) = ? AND email_undeliverable = ?", strings.ToLower(to), true).
+		Count(&count).Error
+	return err == nil && count > 0
 }
 
 // SendPasswordResetEmail sends a password reset email
@@ -170,8 +227,48 @@ func (s *Service) SendPasswordResetEmail(ctx context.Context, to, username, rese
 
 	resetLink := fmt.Sprintf("%s/reset-password?token=%s", baseURL, resetToken)
 
-	siteName := s.getSiteName()
-	subject := fmt.Sprintf("Password Reset Request - %s", siteName)
+	subject, body, err := s.renderEmail(ctx, TemplatePasswordReset, map[string]interface{}{
+		"Username":  username,
+		"ResetLink": resetLink,
+	})
+	if err != nil {
+		return err
+	}
+	return s.SendEmail(ctx, to, subject, body)
+}
+
+// SendPasswordSetupEmail sends a password setup email for new user invitations
+func (s *Service) SendPasswordSetupEmail(ctx context.Context, to, username, setupToken string) error {
+	baseURL := os.Getenv("FRONTEND_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:5173"
+	}
+
+	// URL-encode the token for safe transmission
+	encodedToken := url.QueryEscape(setupToken)
+	setupLink := fmt.Sprintf("%s/setup-password?token=%s", baseURL, encodedToken)
+
+	subject, body, err := s.renderEmail(ctx, TemplateInvitation, map[string]interface{}{
+		"Username":  username,
+		"SetupLink": setupLink,
+	})
+	if err != nil {
+		return err
+	}
+	return s.SendEmail(ctx, to, subject, body)
+}
+
+// SendEmailVerificationEmail sends a link that confirms the user owns the address
+func (s *Service) SendEmailVerificationEmail(ctx context.Context, to, username, verificationToken string) error {
+	baseURL := os.Getenv("FRONTEND_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:5173"
+	}
+
+	verifyLink := fmt.Sprintf("%s/verify-email?token=%s", baseURL, url.QueryEscape(verificationToken))
+
+	siteName := s.getSiteName(ctx)
+	subject := fmt.Sprintf("Verify Your Email - %s", siteName)
 	body := fmt.Sprintf(`
 <!DOCTYPE html>
 <html>
@@ -188,19 +285,18 @@ func (s *Service) SendPasswordResetEmail(ctx context.Context, to, username, rese
 <body>
     <div class="container">
         <div class="header">
-            <h1>Password Reset Request</h1>
+            <h1>Verify Your Email</h1>
         </div>
         <div class="content">
             <p>Hello %s,</p>
-            <p>We received a request to reset your password for your %s account.</p>
-            <p>Click the button below to reset your password:</p>
+            <p>Please confirm this is the email address for your %s account.</p>
             <p style="text-align: center;">
-                <a href="%s" class="button">Reset Password</a>
+                <a href="%s" class="button">Verify Email</a>
             </p>
             <p>Or copy and paste this link into your browser:</p>
             <p style="word-break: break-all; color: #0e6c55;">%s</p>
-            <p><strong>This link will expire in 1 hour.</strong></p>
-            <p>If you didn't request a password reset, you can safely ignore this email.</p>
+            <p><strong>This link will expire in 24 hours.</strong></p>
+            <p>If you didn't create an account or change your email, you can safely ignore this email.</p>
         </div>
         <div class="footer">
             <p>© %s - This is an automated message, please do not reply.</p>
@@ -208,24 +304,24 @@ func (s *Service) SendPasswordResetEmail(ctx context.Context, to, username, rese
     </div>
 </body>
 </html>
-`, username, siteName, resetLink, resetLink, siteName)
+`, html.EscapeString(username), siteName, verifyLink, verifyLink, siteName)
 
 	return s.SendEmail(ctx, to, subject, body)
 }
 
-// SendPasswordSetupEmail sends a password setup email for new user invitations
-func (s *Service) SendPasswordSetupEmail(ctx context.Context, to, username, setupToken string) error {
+// SendReplyToVerificationEmail sends a link that confirms the address a
+// group admin set as groupName's reply-to address accepts mail. Group emails
+// don't use the address until it's verified.
+func (s *Service) SendReplyToVerificationEmail(ctx context.Context, to, groupName, verificationToken string) error {
 	baseURL := os.Getenv("FRONTEND_URL")
 	if baseURL == "" {
 		baseURL = "http://localhost:5173"
 	}
 
-	// URL-encode the token for safe transmission
-	encodedToken := url.QueryEscape(setupToken)
-	setupLink := fmt.Sprintf("%s/setup-password?token=%s", baseURL, encodedToken)
+	verifyLink := fmt.Sprintf("%s/verify-reply-to?token=%s", baseURL, url.QueryEscape(verificationToken))
 
-	siteName := s.getSiteName()
-	subject := fmt.Sprintf("Welcome to %s - Set Your Password", siteName)
+	siteName := s.getSiteName(ctx)
+	subject := fmt.Sprintf("Confirm the reply-to address for %s - %s", groupName, siteName)
 	body := fmt.Sprintf(`
 <!DOCTYPE html>
 <html>
@@ -237,27 +333,22 @@ func (s *Service) SendPasswordSetupEmail(ctx context.Context, to, username, setu
         .content { padding: 20px; background-color: #f8fafc; }
         .button { display: inline-block; padding: 12px 24px; background-color: #0e6c55; color: white; text-decoration: none; border-radius: 4px; margin: 20px 0; }
         .footer { text-align: center; padding: 20px; font-size: 12px; color: #666; }
-        .welcome { font-size: 18px; font-weight: bold; color: #0e6c55; margin-bottom: 10px; }
     </style>
 </head>
 <body>
     <div class="container">
         <div class="header">
-            <h1>Welcome to %s!</h1>
+            <h1>Confirm Reply-To Address</h1>
         </div>
         <div class="content">
-            <p class="welcome">Hello %s,</p>
-            <p>Your username for signing in is: <strong>%s</strong></p>
-            <p>Your account has been created for %s. We're excited to have you join our team!</p>
-            <p>To get started, please click the button below to set your password:</p>
+            <p>An admin of %s on %s asked for replies to the group's emails to come to this address.</p>
             <p style="text-align: center;">
-                <a href="%s" class="button">Set Your Password</a>
+                <a href="%s" class="button">Confirm Address</a>
             </p>
             <p>Or copy and paste this link into your browser:</p>
             <p style="word-break: break-all; color: #0e6c55;">%s</p>
-            <p><strong>This link will expire in 7 days.</strong></p>
-            <p>Once you've set your password, you'll be able to sign in and start contributing to our mission of helping animals in need.</p>
-            <p>If you have any questions or didn't expect this invitation, please contact your administrator.</p>
+            <p><strong>This link will expire in 24 hours.</strong></p>
+            <p>If you don't expect this, you can safely ignore this email; replies won't come here.</p>
         </div>
         <div class="footer">
             <p>© %s - This is an automated message, please do not reply.</p>
@@ -265,19 +356,111 @@ func (s *Service) SendPasswordSetupEmail(ctx context.Context, to, username, setu
     </div>
 </body>
 </html>
-`, siteName, username, username, siteName, setupLink, setupLink, siteName)
+`, html.EscapeString(groupName), siteName, verifyLink, verifyLink, siteName)
 
 	return s.SendEmail(ctx, to, subject, body)
 }
 
 // SendAnnouncementEmail sends an announcement email
 func (s *Service) SendAnnouncementEmail(ctx context.Context, to, title, content string) error {
-	siteName := s.getSiteName()
-	subject := fmt.Sprintf("Announcement: %s - %s", title, siteName)
+	return s.sendAnnouncementEmail(ctx, to, title, content, nil)
+}
+
+// EmailTracking adds open and click tracking to an email
+type EmailTracking struct {
+	PixelURL  string                   // Loaded by a 1x1 image at the end of the email
+	TrackLink func(link string) string // The URL a link in the email goes through instead
+}
+
+// trackedLinkRegex matches the web links in an announcement's plain text
+var trackedLinkRegex = regexp.MustCompile(`https?://[^\s<>"]+`)
+
+// SendTrackedAnnouncementEmail sends an announcement email with open and
+// click tracking. Web links in the content are turned into links that go
+// through tracking.TrackLink.
+func (s *Service) SendTrackedAnnouncementEmail(ctx context.Context, to, title, content string, tracking EmailTracking) error {
+	return s.sendAnnouncementEmail(ctx, to, title, content, &tracking)
+}
+
+func (s *Service) sendAnnouncementEmail(ctx context.Context, to, title, content string, tracking *EmailTracking) error {
+	// Escape HTML in content and convert newlines to HTML line breaks
+	htmlContent := html.EscapeString(content)
+	if tracking != nil && tracking.TrackLink != nil {
+		htmlContent = trackedLinks(content, tracking.TrackLink)
+	}
+	htmlContent = strings.ReplaceAll(htmlContent, "\n", "<br>")
+
+	subject, body, err := s.renderEmail(ctx, TemplateAnnouncement, map[string]interface{}{
+		"Title":   title,
+		"Content": htmltemplate.HTML(htmlContent),
+	})
+	if err != nil {
+		return err
+	}
+	if tracking != nil && tracking.PixelURL != "" {
+		body = withTrackingPixel(body, tracking.PixelURL)
+	}
+	return s.SendEmail(ctx, to, subject, body)
+}
+
+// trackedLinks escapes plain text as HTML, turning its web links into
+// anchors to trackLink(link). Punctuation ending a sentence isn't part of
+// the link.
+func trackedLinks(text string, trackLink func(string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range trackedLinkRegex.FindAllStringIndex(text, -1) {
+		start, end := m[0], m[1]
+		end = start + len(strings.TrimRight(text[start:end], ".,;:!?)'"))
+		link := text[start:end]
+		b.WriteString(html.EscapeString(text[last:start]))
+		b.WriteString(`<a href="` + html.EscapeString(trackLink(link)) + `">` + html.EscapeString(link) + `</a>`)
+		last = end
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+	return b.String()
+}
+
+// withTrackingPixel adds a 1x1 image loading pixelURL to the end of an HTML
+// email body
+func withTrackingPixel(body, pixelURL string) string {
+	pixel := `<img src="` + html.EscapeString(pixelURL) + `" width="1" height="1" alt="" style="border:0">`
+	if i := strings.LastIndex(strings.ToLower(body), "</body>"); i >= 0 {
+		return body[:i] + pixel + body[i:]
+	}
+	return body + pixel
+}
+
+// SendWelcomeEmail welcomes a new member to groupName with the group's
+// welcome text and the labels of its onboarding checklist. link opens the
+// member's checklist.
+func (s *Service) SendWelcomeEmail(ctx context.Context, to, username, groupName, welcomeText string, checklist []string, link string) error {
+	var items strings.Builder
+	items.WriteString("<ul>")
+	for _, label := range checklist {
+		items.WriteString("<li>" + html.EscapeString(label) + "</li>")
+	}
+	items.WriteString("</ul>")
+
+	subject, body, err := s.renderEmail(ctx, TemplateWelcome, map[string]interface{}{
+		"Username":    username,
+		"GroupName":   groupName,
+		"WelcomeText": htmltemplate.HTML(strings.ReplaceAll(html.EscapeString(welcomeText), "\n", "<br>")),
+		"Checklist":   htmltemplate.HTML(items.String()),
+		"Link":        link,
+	})
+	if err != nil {
+		return err
+	}
+	return s.SendEmail(ctx, to, subject, body)
+}
 
-	// Escape HTML in title and convert newlines to HTML line breaks in content
-	escapedTitle := html.EscapeString(title)
-	htmlContent := strings.ReplaceAll(html.EscapeString(content), "\n", "<br>")
+// SendCommentReactionEmail tells a comment's author that someone reacted to
+// it. reaction is the emoji shown in the header, and link opens the animal
+// the comment is on.
+func (s *Service) SendCommentReactionEmail(ctx context.Context, to, reactorName, reaction, animalName, link string) error {
+	siteName := s.getSiteName(ctx)
+	subject := fmt.Sprintf("%s reacted to your comment on %s - %s", reactorName, animalName, siteName)
 
 	body := fmt.Sprintf(`
 <!DOCTYPE html>
@@ -288,16 +471,116 @@ func (s *Service) SendAnnouncementEmail(ctx context.Context, to, title, content
         .container { max-width: 600px; margin: 0 auto; padding: 20px; }
         .header { background-color: #0e6c55; color: white; padding: 20px; text-align: center; }
         .content { padding: 20px; background-color: #f8fafc; }
+        .button { display: inline-block; padding: 12px 24px; background-color: #0e6c55; color: white; text-decoration: none; border-radius: 4px; margin: 20px 0; }
         .footer { text-align: center; padding: 20px; font-size: 12px; color: #666; }
     </style>
 </head>
 <body>
     <div class="container">
         <div class="header">
-            <h1>%s</h1>
+            <h1>%s New Reaction</h1>
+        </div>
+        <div class="content">
+            <p>%s reacted to your comment on %s.</p>
+            <p style="text-align: center;">
+                <a href="%s" class="button">View %s</a>
+            </p>
+        </div>
+        <div class="footer">
+            <p>© %s - You're receiving this because you opted in to email notifications.</p>
+            <p>You can manage your email preferences in your account settings.</p>
+        </div>
+    </div>
+</body>
+</html>
+`, html.EscapeString(reaction), html.EscapeString(reactorName), html.EscapeString(animalName),
+		html.EscapeString(link), html.EscapeString(animalName), siteName)
+
+	return s.SendEmail(ctx, to, subject, body)
+}
+
+// SendDiscussionReplyEmail tells someone taking part in a discussion that
+// replierName replied to it. excerpt is the start of the reply, and link
+// opens the discussion.
+func (s *Service) SendDiscussionReplyEmail(ctx context.Context, to, replierName, title, excerpt, link string) error {
+	siteName := s.getSiteName(ctx)
+	subject := fmt.Sprintf("%s replied to \"%s\" - %s", replierName, title, siteName)
+
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background-color: #0e6c55; color: white; padding: 20px; text-align: center; }
+        .content { padding: 20px; background-color: #f8fafc; }
+        .quote { border-left: 4px solid #0e6c55; padding-left: 12px; color: #555; white-space: pre-wrap; }
+        .button { display: inline-block; padding: 12px 24px; background-color: #0e6c55; color: white; text-decoration: none; border-radius: 4px; margin: 20px 0; }
+        .footer { text-align: center; padding: 20px; font-size: 12px; color: #666; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>New Reply</h1>
         </div>
         <div class="content">
+            <p>%s replied to <strong>%s</strong>:</p>
+            <p class="quote">%s</p>
+            <p style="text-align: center;">
+                <a href="%s" class="button">View Discussion</a>
+            </p>
+        </div>
+        <div class="footer">
+            <p>© %s - You're receiving this because you took part in this discussion.</p>
+            <p>You can mute the discussion, or manage your email preferences in your account settings.</p>
+        </div>
+    </div>
+</body>
+</html>
+`, html.EscapeString(replierName), html.EscapeString(title), html.EscapeString(excerpt),
+		html.EscapeString(link), siteName)
+
+	return s.SendEmail(ctx, to, subject, body)
+}
+
+// SendJoinRequestEmail tells a group admin that requesterName asked to join
+// groupName. message is the requester's optional note, and link opens the
+// group's pending requests.
+func (s *Service) SendJoinRequestEmail(ctx context.Context, to, requesterName, groupName, message, link string) error {
+	siteName := s.getSiteName(ctx)
+	subject := fmt.Sprintf("%s asked to join %s - %s", requesterName, groupName, siteName)
+
+	note := ""
+	if message != "" {
+		note = "<blockquote>" + strings.ReplaceAll(html.EscapeString(message), "\n", "<br>") + "</blockquote>"
+	}
+
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background-color: #0e6c55; color: white; padding: 20px; text-align: center; }
+        .content { padding: 20px; background-color: #f8fafc; }
+        .button { display: inline-block; padding: 12px 24px; background-color: #0e6c55; color: white; text-decoration: none; border-radius: 4px; margin: 20px 0; }
+        .footer { text-align: center; padding: 20px; font-size: 12px; color: #666; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>New Join Request</h1>
+        </div>
+        <div class="content">
+            <p>%s asked to join %s.</p>
             %s
+            <p style="text-align: center;">
+                <a href="%s" class="button">Review Requests</a>
+            </p>
         </div>
         <div class="footer">
             <p>© %s - You're receiving this because you opted in to email notifications.</p>
@@ -306,7 +589,259 @@ func (s *Service) SendAnnouncementEmail(ctx context.Context, to, title, content
     </div>
 </body>
 </html>
-`, escapedTitle, htmlContent, siteName)
+`, html.EscapeString(requesterName), html.EscapeString(groupName), note, html.EscapeString(link), siteName)
+
+	return s.SendEmail(ctx, to, subject, body)
+}
+
+// SendJoinRequestDecisionEmail tells a user whether their request to join
+// groupName was approved. reason is the admin's optional note on a denial,
+// and link opens the group once approved or the site otherwise.
+func (s *Service) SendJoinRequestDecisionEmail(ctx context.Context, to, groupName string, approved bool, reason, link string) error {
+	siteName := s.getSiteName(ctx)
+	subject := fmt.Sprintf("Your request to join %s was declined - %s", groupName, siteName)
+	heading := "Join Request Declined"
+	text := fmt.Sprintf("Your request to join %s was declined.", html.EscapeString(groupName))
+	button := "Visit " + siteName
+	if approved {
+		subject = fmt.Sprintf("Welcome to %s - %s", groupName, siteName)
+		heading = "Join Request Approved"
+		text = fmt.Sprintf("Your request to join %s was approved. You can now see its animals and updates.", html.EscapeString(groupName))
+		button = "Open " + groupName
+	}
+	if !approved && reason != "" {
+		text += "<blockquote>" + strings.ReplaceAll(html.EscapeString(reason), "\n", "<br>") + "</blockquote>"
+	}
+
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background-color: #0e6c55; color: white; padding: 20px; text-align: center; }
+        .content { padding: 20px; background-color: #f8fafc; }
+        .button { display: inline-block; padding: 12px 24px; background-color: #0e6c55; color: white; text-decoration: none; border-radius: 4px; margin: 20px 0; }
+        .footer { text-align: center; padding: 20px; font-size: 12px; color: #666; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>%s</h1>
+        </div>
+        <div class="content">
+            <p>%s</p>
+            <p style="text-align: center;">
+                <a href="%s" class="button">%s</a>
+            </p>
+        </div>
+        <div class="footer">
+            <p>© %s - You're receiving this because you asked to join a group.</p>
+        </div>
+    </div>
+</body>
+</html>
+`, heading, text, html.EscapeString(link), html.EscapeString(button), siteName)
+
+	return s.SendEmail(ctx, to, subject, body)
+}
+
+// SendAnimalChangeEmail tells a group admin which fields of an animal
+// editorName changed, with the old and new values. link opens the animal.
+func (s *Service) SendAnimalChangeEmail(ctx context.Context, to, editorName, animalName string, changes []models.AnimalFieldChange, link string) error {
+	siteName := s.getSiteName(ctx)
+	subject := fmt.Sprintf("%s updated %s - %s", editorName, animalName, siteName)
+
+	var rows strings.Builder
+	for _, change := range changes {
+		fmt.Fprintf(&rows, "<tr><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+			html.EscapeString(change.Field), html.EscapeString(change.Old), html.EscapeString(change.New))
+	}
+
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background-color: #0e6c55; color: white; padding: 20px; text-align: center; }
+        .content { padding: 20px; background-color: #f8fafc; }
+        table { width: 100%%; border-collapse: collapse; }
+        th, td { text-align: left; padding: 6px; border-bottom: 1px solid #e2e8f0; vertical-align: top; }
+        .button { display: inline-block; padding: 12px 24px; background-color: #0e6c55; color: white; text-decoration: none; border-radius: 4px; margin: 20px 0; }
+        .footer { text-align: center; padding: 20px; font-size: 12px; color: #666; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>%s Updated</h1>
+        </div>
+        <div class="content">
+            <p>%s changed %s:</p>
+            <table>
+                <tr><th>Field</th><th>Before</th><th>After</th></tr>
+                %s
+            </table>
+            <p style="text-align: center;">
+                <a href="%s" class="button">View %s</a>
+            </p>
+        </div>
+        <div class="footer">
+            <p>© %s - You're receiving this because you asked to be told about changes to your group's animals.</p>
+            <p>You can manage your email preferences in your account settings.</p>
+        </div>
+    </div>
+</body>
+</html>
+`, html.EscapeString(animalName), html.EscapeString(editorName), html.EscapeString(animalName), rows.String(),
+		html.EscapeString(link), html.EscapeString(animalName), siteName)
+
+	return s.SendEmail(ctx, to, subject, body)
+}
+
+// WeeklyStatsVolunteer is one of the most active volunteers in a
+// WeeklyStatsSummary
+type WeeklyStatsVolunteer struct {
+	Name     string
+	Comments int64
+}
+
+// WeeklyStatsSummary is the content of a group's weekly statistics email.
+// WeekEnd is exclusive. InactiveAnimals names the animals in care nobody
+// commented on during the week.
+type WeeklyStatsSummary struct {
+	GroupName       string
+	WeekStart       time.Time
+	WeekEnd         time.Time
+	NewAnimals      int64
+	Adoptions       int64
+	Comments        int64
+	TopVolunteers   []WeeklyStatsVolunteer
+	InactiveAnimals []string
+}
+
+// SendWeeklyStatsEmail sends a group admin the summary of their group's
+// week. link opens the group.
+func (s *Service) SendWeeklyStatsEmail(ctx context.Context, to string, summary WeeklyStatsSummary, link string) error {
+	siteName := s.getSiteName(ctx)
+	week := fmt.Sprintf("%s - %s", summary.WeekStart.Format("Jan 2"), summary.WeekEnd.AddDate(0, 0, -1).Format("Jan 2, 2006"))
+	subject := fmt.Sprintf("%s weekly summary, %s - %s", summary.GroupName, week, siteName)
+
+	volunteers := "<p>Nobody commented this week.</p>"
+	if len(summary.TopVolunteers) > 0 {
+		var rows strings.Builder
+		for _, v := range summary.TopVolunteers {
+			fmt.Fprintf(&rows, "<tr><td>%s</td><td>%d</td></tr>\n", html.EscapeString(v.Name), v.Comments)
+		}
+		volunteers = "<table><tr><th>Volunteer</th><th>Comments</th></tr>\n" + rows.String() + "</table>"
+	}
+	inactive := "<p>Every animal in care got at least one comment.</p>"
+	if len(summary.InactiveAnimals) > 0 {
+		var items strings.Builder
+		for _, name := range summary.InactiveAnimals {
+			fmt.Fprintf(&items, "<li>%s</li>\n", html.EscapeString(name))
+		}
+		inactive = "<ul>\n" + items.String() + "</ul>"
+	}
+
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background-color: #0e6c55; color: white; padding: 20px; text-align: center; }
+        .content { padding: 20px; background-color: #f8fafc; }
+        table { width: 100%%; border-collapse: collapse; }
+        th, td { text-align: left; padding: 6px; border-bottom: 1px solid #e2e8f0; vertical-align: top; }
+        .button { display: inline-block; padding: 12px 24px; background-color: #0e6c55; color: white; text-decoration: none; border-radius: 4px; margin: 20px 0; }
+        .footer { text-align: center; padding: 20px; font-size: 12px; color: #666; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>%s This Week</h1>
+            <p>%s</p>
+        </div>
+        <div class="content">
+            <table>
+                <tr><td>New animals</td><td>%d</td></tr>
+                <tr><td>Adoptions</td><td>%d</td></tr>
+                <tr><td>Comments</td><td>%d</td></tr>
+            </table>
+            <h3>Most Active Volunteers</h3>
+            %s
+            <h3>No Comments This Week</h3>
+            %s
+            <p style="text-align: center;">
+                <a href="%s" class="button">Open %s</a>
+            </p>
+        </div>
+        <div class="footer">
+            <p>© %s - You're receiving this because you asked for weekly summaries of the groups you manage.</p>
+            <p>You can manage your email preferences in your account settings.</p>
+        </div>
+    </div>
+</body>
+</html>
+`, html.EscapeString(summary.GroupName), week, summary.NewAnimals, summary.Adoptions, summary.Comments,
+		volunteers, inactive, html.EscapeString(link), html.EscapeString(summary.GroupName), siteName)
+
+	return s.SendEmail(ctx, to, subject, body)
+}
+
+// SendStatusAlertEmail tells a group admin that animalName has been in
+// statusLabel for days days, past the group's limit of maxDays. message is
+// the rule's note on what to do, and link opens the animal.
+func (s *Service) SendStatusAlertEmail(ctx context.Context, to, animalName, statusLabel string, days, maxDays int, message, link string) error {
+	siteName := s.getSiteName(ctx)
+	subject := fmt.Sprintf("%s has been %s for %d days - %s", animalName, statusLabel, days, siteName)
+
+	note := ""
+	if message != "" {
+		note = fmt.Sprintf("<p><strong>%s</strong></p>", html.EscapeString(message))
+	}
+
+	body := fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background-color: #0e6c55; color: white; padding: 20px; text-align: center; }
+        .content { padding: 20px; background-color: #f8fafc; }
+        .button { display: inline-block; padding: 12px 24px; background-color: #0e6c55; color: white; text-decoration: none; border-radius: 4px; margin: 20px 0; }
+        .footer { text-align: center; padding: 20px; font-size: 12px; color: #666; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Follow-Up Needed</h1>
+        </div>
+        <div class="content">
+            <p>%s has been %s for %d days. Your group asks for a follow-up after %d days.</p>
+            %s
+            <p style="text-align: center;">
+                <a href="%s" class="button">View %s</a>
+            </p>
+        </div>
+        <div class="footer">
+            <p>© %s - You're receiving this because you manage this group and opted in to email notifications.</p>
+            <p>You can manage your email preferences in your account settings.</p>
+        </div>
+    </div>
+</body>
+</html>
+`, html.EscapeString(animalName), html.EscapeString(statusLabel), days, maxDays, note,
+		html.EscapeString(link), html.EscapeString(animalName), siteName)
 
 	return s.SendEmail(ctx, to, subject, body)
 }
@@ -3,6 +3,7 @@ package email
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
@@ -279,6 +280,35 @@ func TestService_SendAnnouncementEmail_WithConfiguredProvider(t *testing.T) {
 	}
 }
 
+func TestService_SendTrackedAnnouncementEmail(t *testing.T) {
+	mockProvider := &mockEmailProvider{configured: true}
+	service := &Service{provider: mockProvider}
+
+	tracking := EmailTracking{
+		PixelURL: "https://example.org/open.gif?t=1&u=2",
+		TrackLink: func(link string) string {
+			return "https://example.org/click?url=" + url.QueryEscape(link)
+		},
+	}
+	content := "Sign up at https://example.org/events?id=5&x=<b>.\nThanks & see you"
+	if err := service.SendTrackedAnnouncementEmail(context.Background(), "user@example.com", "Event", content, tracking); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(mockProvider.sentEmails) != 1 {
+		t.Fatalf("Expected 1 email to be sent, got %d", len(mockProvider.sentEmails))
+	}
+	body := mockProvider.sentEmails[0].body
+
+	wantLink := `<a href="https://example.org/click?url=https%3A%2F%2Fexample.org%2Fevents%3Fid%3D5%26x%3D">https://example.org/events?id=5&amp;x=</a>&lt;b&gt;.<br>Thanks &amp; see you`
+	if !strings.Contains(body, wantLink) {
+		t.Errorf("Expected body to contain tracked link %q, got:\n%s", wantLink, body)
+	}
+	wantPixel := `<img src="https://example.org/open.gif?t=1&amp;u=2" width="1" height="1" alt="" style="border:0"></body>`
+	if !strings.Contains(body, wantPixel) {
+		t.Errorf("Expected body to end with the tracking pixel, got:\n%s", body)
+	}
+}
+
 // Mock provider for testing
 type mockEmailProvider struct {
 	configured bool
@@ -343,7 +373,7 @@ func TestGetSiteName_WithDatabase(t *testing.T) {
 		mockProvider := &mockEmailProvider{configured: true}
 		service := NewServiceWithProvider(mockProvider, db)
 
-		siteName := service.getSiteName()
+		siteName := service.getSiteName(context.Background())
 
 		if siteName != "Test Organization" {
 			t.Errorf("Expected site name 'Test Organization', got '%s'", siteName)
@@ -357,7 +387,7 @@ func TestGetSiteName_WithDatabase(t *testing.T) {
 		mockProvider := &mockEmailProvider{configured: true}
 		service := NewServiceWithProvider(mockProvider, db)
 
-		siteName := service.getSiteName()
+		siteName := service.getSiteName(context.Background())
 
 		if siteName != models.DefaultSiteName {
 			t.Errorf("Expected default site name '%s', got '%s'", models.DefaultSiteName, siteName)
@@ -368,7 +398,7 @@ func TestGetSiteName_WithDatabase(t *testing.T) {
 		mockProvider := &mockEmailProvider{configured: true}
 		service := NewServiceWithProvider(mockProvider, nil)
 
-		siteName := service.getSiteName()
+		siteName := service.getSiteName(context.Background())
 
 		if siteName != models.DefaultSiteName {
 			t.Errorf("Expected default site name '%s', got '%s'", models.DefaultSiteName, siteName)
@@ -391,7 +421,7 @@ func TestGetSiteName_WithDatabase(t *testing.T) {
 		service := NewServiceWithProvider(mockProvider, db)
 
 		// First call - should fetch from DB and cache
-		siteName1 := service.getSiteName()
+		siteName1 := service.getSiteName(context.Background())
 		if siteName1 != "Cached Name" {
 			t.Errorf("Expected 'Cached Name', got '%s'", siteName1)
 		}
@@ -400,7 +430,7 @@ func TestGetSiteName_WithDatabase(t *testing.T) {
 		db.Model(&models.SiteSetting{}).Where("key = ?", "site_name").Update("value", "Updated Name")
 
 		// Second call - should still return cached value (not expired yet)
-		siteName2 := service.getSiteName()
+		siteName2 := service.getSiteName(context.Background())
 		if siteName2 != "Cached Name" {
 			t.Errorf("Expected cached 'Cached Name', got '%s'", siteName2)
 		}
@@ -422,7 +452,7 @@ func TestGetSiteName_WithDatabase(t *testing.T) {
 		service := NewServiceWithProvider(mockProvider, db)
 
 		// First call - should fetch from DB and cache
-		siteName1 := service.getSiteName()
+		siteName1 := service.getSiteName(context.Background())
 		if siteName1 != "Original Name" {
 			t.Errorf("Expected 'Original Name', got '%s'", siteName1)
 		}
@@ -436,7 +466,7 @@ func TestGetSiteName_WithDatabase(t *testing.T) {
 		service.cacheMu.Unlock()
 
 		// Next call should refresh cache and get new value
-		siteName2 := service.getSiteName()
+		siteName2 := service.getSiteName(context.Background())
 		if siteName2 != "Refreshed Name" {
 			t.Errorf("Expected refreshed 'Refreshed Name', got '%s'", siteName2)
 		}
@@ -0,0 +1,46 @@
+package email
+
+import (
+	"context"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+)
+
+// LogProvider implements the Provider interface by writing each email to the
+// application log instead of sending it. It is meant for local development:
+// message bodies, including password reset and invitation links, end up in
+// the log, so never use it in production.
+type LogProvider struct{}
+
+// NewLogProvider creates a new log-only provider
+func NewLogProvider() *LogProvider {
+	return &LogProvider{}
+}
+
+// IsConfigured always returns true; the log provider needs no configuration
+func (p *LogProvider) IsConfigured() bool {
+	return true
+}
+
+// GetProviderName returns the provider name for logging
+func (p *LogProvider) GetProviderName() string {
+	return "log"
+}
+
+// SendEmail logs the email instead of sending it
+func (p *LogProvider) SendEmail(ctx context.Context, to, subject, htmlBody string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	fields := map[string]interface{}{
+		"to":      to,
+		"subject": subject,
+		"body":    htmlBody,
+	}
+	if name, replyTo := senderFields(ctx, ""); name != "" || replyTo != "" {
+		fields["from_name"] = name
+		fields["reply_to"] = replyTo
+	}
+	logging.WithContext(ctx).WithFields(fields).Info("Email logged instead of sent (EMAIL_PROVIDER=log)")
+	return nil
+}
@@ -20,12 +20,23 @@ type Provider interface {
 	GetProviderName() string
 }
 
+// HealthChecker is implemented by providers that can verify, cheaply, that
+// sending would currently succeed (the service is reachable and accepts the
+// credentials). Used by the readiness endpoint; providers that don't
+// implement it are reported as ok whenever they are configured.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
 // ProviderType represents the type of email provider
 type ProviderType string
 
 const (
-	ProviderTypeSMTP   ProviderType = "smtp"
-	ProviderTypeResend ProviderType = "resend"
+	ProviderTypeSMTP     ProviderType = "smtp"
+	ProviderTypeResend   ProviderType = "resend"
+	ProviderTypeSES      ProviderType = "ses"
+	ProviderTypeSendGrid ProviderType = "sendgrid"
+	ProviderTypeLog      ProviderType = "log" // Development only: logs emails instead of sending them
 )
 
 // NewProvider creates an email provider based on environment configuration
@@ -47,6 +58,12 @@ func NewProvider() (Provider, error) {
 		return NewSMTPProvider(), nil
 	case ProviderTypeResend:
 		return NewResendProvider(), nil
+	case ProviderTypeSES:
+		return NewSESProvider(), nil
+	case ProviderTypeSendGrid:
+		return NewSendGridProvider(), nil
+	case ProviderTypeLog:
+		return NewLogProvider(), nil
 	default:
 		return nil, fmt.Errorf("unsupported email provider: %s", providerType)
 	}
@@ -47,6 +47,30 @@ func TestNewProvider(t *testing.T) {
 			wantNil:     false,
 			wantErr:     false,
 		},
+		{
+			name:        "set to SES",
+			envProvider: "ses",
+			envEnabled:  "",
+			wantType:    "ses",
+			wantNil:     false,
+			wantErr:     false,
+		},
+		{
+			name:        "set to SendGrid",
+			envProvider: "sendgrid",
+			envEnabled:  "",
+			wantType:    "sendgrid",
+			wantNil:     false,
+			wantErr:     false,
+		},
+		{
+			name:        "set to log-only",
+			envProvider: "log",
+			envEnabled:  "",
+			wantType:    "log",
+			wantNil:     false,
+			wantErr:     false,
+		},
 		{
 			name:        "email disabled with false",
 			envProvider: "smtp",
@@ -65,7 +89,7 @@ func TestNewProvider(t *testing.T) {
 		},
 		{
 			name:        "unsupported provider",
-			envProvider: "sendgrid",
+			envProvider: "mailgun",
 			envEnabled:  "",
 			wantType:    "",
 			wantNil:     false,
@@ -125,6 +149,18 @@ func TestProviderInterface(t *testing.T) {
 			name:     "Resend",
 			provider: NewResendProvider(),
 		},
+		{
+			name:     "SES",
+			provider: NewSESProvider(),
+		},
+		{
+			name:     "SendGrid",
+			provider: NewSendGridProvider(),
+		},
+		{
+			name:     "Log",
+			provider: NewLogProvider(),
+		},
 	}
 
 	for _, tt := range providers {
@@ -69,6 +69,7 @@ type ResendEmailRequest struct {
 	To      []string `json:"to"`
 	Subject string `json:"subject"`
 	HTML    string `json:"html"`
+	ReplyTo string `json:"reply_to,omitempty"`
 }
 
 // ResendEmailResponse represents the Resend API response structure
@@ -91,10 +92,8 @@ func (p *ResendProvider) SendEmail(ctx context.Context, to, subject, htmlBody st
 	defer span.End()
 
 	// Construct from address
-	from := p.FromEmail
-	if p.FromName != "" {
-		from = fmt.Sprintf("%s <%s>", p.FromName, p.FromEmail)
-	}
+	fromName, replyTo := senderFields(ctx, p.FromName)
+	from := formatFrom(fromName, p.FromEmail)
 
 	// Create request payload
 	payload := ResendEmailRequest{
@@ -102,6 +101,7 @@ func (p *ResendProvider) SendEmail(ctx context.Context, to, subject, htmlBody st
 		To:      []string{to},
 		Subject: subject,
 		HTML:    htmlBody,
+		ReplyTo: replyTo,
 	}
 
 	jsonData, err := json.Marshal(payload)
@@ -0,0 +1,73 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MaxSenderNameLength bounds a Sender's display name
+const MaxSenderNameLength = 100
+
+// Sender is how a group's notification emails present themselves: a display
+// name in place of the provider's FromName, and the address replies go to.
+// The address mail is sent from never changes, since providers can only send
+// from the address they are configured (and verified with the service) for.
+type Sender struct {
+	Name    string
+	ReplyTo string
+}
+
+type senderContextKey struct{}
+
+// WithSender returns a context whose emails are sent as sender. Empty fields
+// keep the provider's defaults.
+func WithSender(ctx context.Context, sender Sender) context.Context {
+	return context.WithValue(ctx, senderContextKey{}, sender)
+}
+
+// SenderFromContext returns the sender set with WithSender, or the zero
+// Sender if there is none
+func SenderFromContext(ctx context.Context) Sender {
+	sender, _ := ctx.Value(senderContextKey{}).(Sender)
+	return sender
+}
+
+// ValidateSender checks a sender's name and reply-to address can safely go
+// in email headers. Both may be empty.
+func ValidateSender(sender Sender) error {
+	if len(sender.Name) > MaxSenderNameLength {
+		return fmt.Errorf("sender name must be at most %d characters", MaxSenderNameLength)
+	}
+	if strings.ContainsAny(sender.Name, `<>"`) || strings.IndexFunc(sender.Name, unicode.IsControl) >= 0 {
+		return fmt.Errorf("sender name can't contain quotes, angle brackets, or line breaks")
+	}
+	if sender.ReplyTo != "" && !isValidEmail(sender.ReplyTo) {
+		return fmt.Errorf("reply-to must be a valid email address")
+	}
+	return nil
+}
+
+// senderFields returns the display name and reply-to address to send with:
+// the context's sender where it sets them, defaultName and no reply-to
+// otherwise. Values that fail ValidateSender are ignored.
+func senderFields(ctx context.Context, defaultName string) (name, replyTo string) {
+	sender := SenderFromContext(ctx)
+	if ValidateSender(sender) != nil {
+		return defaultName, ""
+	}
+	name = defaultName
+	if sender.Name != "" {
+		name = sender.Name
+	}
+	return name, sender.ReplyTo
+}
+
+// formatFrom formats a From address with an optional display name
+func formatFrom(name, address string) string {
+	if name == "" {
+		return address
+	}
+	return fmt.Sprintf("%s <%s>", name, address)
+}
@@ -0,0 +1,88 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidateSender(t *testing.T) {
+	tests := []struct {
+		name    string
+		sender  Sender
+		wantErr bool
+	}{
+		{"empty keeps the defaults", Sender{}, false},
+		{"name and reply-to", Sender{Name: "Dog Walkers", ReplyTo: "dogs@example.org"}, false},
+		{"header injection", Sender{Name: "Dogs\r\nBcc: everyone@example.com"}, true},
+		{"angle brackets", Sender{Name: "Dogs <admin@example.com>"}, true},
+		{"too long", Sender{Name: strings.Repeat("a", MaxSenderNameLength+1)}, true},
+		{"bad reply-to", Sender{ReplyTo: "dogs@"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateSender(tt.sender); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSender(%+v) = %v, wantErr %v", tt.sender, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProviders_SendAsContextSender(t *testing.T) {
+	ctx := WithSender(context.Background(), Sender{Name: "Dog Walkers", ReplyTo: "dogs@example.org"})
+
+	t.Run("sendgrid", func(t *testing.T) {
+		var got SendGridEmailRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&got)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+		if err := newTestSendGridProvider(server).SendEmail(ctx, "to@example.com", "Hello", "<p>Hi</p>"); err != nil {
+			t.Fatalf("SendEmail: %v", err)
+		}
+		if got.From.Email != "noreply@example.com" || got.From.Name != "Dog Walkers" {
+			t.Errorf("expected the configured address with the group's name, got %+v", got.From)
+		}
+		if got.ReplyTo == nil || got.ReplyTo.Email != "dogs@example.org" {
+			t.Errorf("unexpected reply-to %+v", got.ReplyTo)
+		}
+	})
+
+	t.Run("ses", func(t *testing.T) {
+		var got SESEmailRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&got)
+			_, _ = w.Write([]byte(`{"MessageId":"abc"}`))
+		}))
+		defer server.Close()
+		if err := newTestSESProvider(server).SendEmail(ctx, "to@example.com", "Hello", "<p>Hi</p>"); err != nil {
+			t.Fatalf("SendEmail: %v", err)
+		}
+		if got.FromEmailAddress != "Dog Walkers <noreply@example.com>" {
+			t.Errorf("unexpected from %q", got.FromEmailAddress)
+		}
+		if len(got.ReplyToAddresses) != 1 || got.ReplyToAddresses[0] != "dogs@example.org" {
+			t.Errorf("unexpected reply-to %v", got.ReplyToAddresses)
+		}
+	})
+
+	t.Run("invalid senders are ignored", func(t *testing.T) {
+		var got SendGridEmailRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&got)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+		bad := WithSender(context.Background(), Sender{Name: "Dogs\nBcc: x@example.com", ReplyTo: "dogs@example.org"})
+		if err := newTestSendGridProvider(server).SendEmail(bad, "to@example.com", "Hello", "<p>Hi</p>"); err != nil {
+			t.Fatalf("SendEmail: %v", err)
+		}
+		if got.From.Name != "Volunteers" || got.ReplyTo != nil {
+			t.Errorf("expected the provider's defaults, got from %+v reply-to %+v", got.From, got.ReplyTo)
+		}
+	})
+}
@@ -0,0 +1,177 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/telemetry"
+)
+
+const (
+	defaultSendGridAPIURL = "https://api.sendgrid.com"
+)
+
+// SendGridProvider implements the Provider interface using the SendGrid v3 API
+type SendGridProvider struct {
+	APIKey    string
+	FromEmail string
+	FromName  string
+	client    *http.Client
+	apiURL    string // Configurable API base URL for testing
+}
+
+// NewSendGridProvider creates a new SendGrid provider from environment variables
+func NewSendGridProvider() *SendGridProvider {
+	apiURL := os.Getenv("SENDGRID_API_URL")
+	if apiURL == "" {
+		apiURL = defaultSendGridAPIURL
+	}
+
+	return &SendGridProvider{
+		APIKey:    os.Getenv("SENDGRID_API_KEY"),
+		FromEmail: os.Getenv("SENDGRID_FROM_EMAIL"),
+		FromName:  os.Getenv("SENDGRID_FROM_NAME"),
+		apiURL:    strings.TrimRight(apiURL, "/"),
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// IsConfigured checks if the SendGrid provider is properly configured
+func (p *SendGridProvider) IsConfigured() bool {
+	return p.APIKey != "" && p.FromEmail != ""
+}
+
+// GetProviderName returns the provider name for logging
+func (p *SendGridProvider) GetProviderName() string {
+	return "sendgrid"
+}
+
+// sendGridAddress is a SendGrid email address with optional display name
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+// sendGridPersonalization lists the recipients of one copy of the message
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+// sendGridContent is one body part of the message
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// SendGridEmailRequest represents the SendGrid mail/send request structure
+type SendGridEmailRequest struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	ReplyTo          *sendGridAddress          `json:"reply_to,omitempty"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+}
+
+// SendGridErrorResponse represents the SendGrid API error structure
+type SendGridErrorResponse struct {
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// sendGridError reads the first message from a SendGrid error response
+func sendGridError(status int, body []byte) error {
+	var resp SendGridErrorResponse
+	if err := json.Unmarshal(body, &resp); err == nil && len(resp.Errors) > 0 && resp.Errors[0].Message != "" {
+		return fmt.Errorf("SendGrid API error: %s", resp.Errors[0].Message)
+	}
+	return fmt.Errorf("SendGrid API error: status %d", status)
+}
+
+// SendEmail sends an email using the SendGrid API
+func (p *SendGridProvider) SendEmail(ctx context.Context, to, subject, htmlBody string) error {
+	if !p.IsConfigured() {
+		return fmt.Errorf("SendGrid provider is not configured")
+	}
+
+	ctx, span := tracer.Start(ctx, "email.sendgrid.send", trace.WithAttributes(
+		attribute.Int("email.body_size_bytes", len(htmlBody)),
+	))
+	defer span.End()
+
+	fromName, replyTo := senderFields(ctx, p.FromName)
+	payload := SendGridEmailRequest{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{{Email: to}}}},
+		From:             sendGridAddress{Email: p.FromEmail, Name: fromName},
+		Subject:          subject,
+		Content:          []sendGridContent{{Type: "text/html", Value: htmlBody}},
+	}
+	if replyTo != "" {
+		payload.ReplyTo = &sendGridAddress{Email: replyTo}
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return telemetry.Fail(span, fmt.Errorf("failed to marshal request: %w", err), "failed to marshal request")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.apiURL+"/v3/mail/send", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return telemetry.Fail(span, fmt.Errorf("failed to create request: %w", err), "failed to create request")
+	}
+	req.Header.Set("Authorization", "Bearer "+p.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return telemetry.Fail(span, fmt.Errorf("failed to send request: %w", err), "request failed")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return telemetry.Fail(span, fmt.Errorf("failed to read response: %w", err), "failed to read response")
+	}
+
+	// SendGrid accepts mail for delivery with 202
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		return telemetry.Fail(span, sendGridError(resp.StatusCode, body), "non-2xx response")
+	}
+	return nil
+}
+
+// CheckHealth verifies that SendGrid is reachable and accepts the API key by
+// listing the key's scopes.
+func (p *SendGridProvider) CheckHealth(ctx context.Context) error {
+	if !p.IsConfigured() {
+		return fmt.Errorf("SendGrid provider is not configured")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.apiURL+"/v3/scopes", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.APIKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return sendGridError(resp.StatusCode, body)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestSendGridProvider(server *httptest.Server) *SendGridProvider {
+	return &SendGridProvider{
+		APIKey:    "SG.test",
+		FromEmail: "noreply@example.com",
+		FromName:  "Volunteers",
+		client:    server.Client(),
+		apiURL:    server.URL,
+	}
+}
+
+func TestSendGridProvider_SendEmail(t *testing.T) {
+	var got SendGridEmailRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v3/mail/send" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer SG.test" {
+			t.Errorf("unexpected Authorization header %q", r.Header.Get("Authorization"))
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	provider := newTestSendGridProvider(server)
+	if err := provider.SendEmail(context.Background(), "to@example.com", "Hello", "<p>Hi</p>"); err != nil {
+		t.Fatalf("SendEmail: %v", err)
+	}
+	if got.From.Email != "noreply@example.com" || got.From.Name != "Volunteers" || got.Subject != "Hello" {
+		t.Errorf("unexpected sender or subject %+v", got)
+	}
+	if len(got.Personalizations) != 1 || got.Personalizations[0].To[0].Email != "to@example.com" {
+		t.Errorf("unexpected recipients %+v", got.Personalizations)
+	}
+	if len(got.Content) != 1 || got.Content[0].Type != "text/html" || got.Content[0].Value != "<p>Hi</p>" {
+		t.Errorf("unexpected content %+v", got.Content)
+	}
+}
+
+func TestSendGridProvider_Errors(t *testing.T) {
+	validKey := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v3/scopes" && validKey {
+			_, _ = w.Write([]byte(`{"scopes":["mail.send"]}`))
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"errors":[{"message":"The provided authorization grant is invalid"}]}`))
+	}))
+	defer server.Close()
+
+	provider := newTestSendGridProvider(server)
+	err := provider.SendEmail(context.Background(), "to@example.com", "Hello", "<p>Hi</p>")
+	if err == nil || !strings.Contains(err.Error(), "authorization grant is invalid") {
+		t.Errorf("expected the SendGrid error message, got %v", err)
+	}
+
+	if err := provider.CheckHealth(context.Background()); err != nil {
+		t.Errorf("CheckHealth: %v", err)
+	}
+	validKey = false
+	if err := provider.CheckHealth(context.Background()); err == nil {
+		t.Error("expected CheckHealth to fail with a rejected key")
+	}
+}
@@ -0,0 +1,263 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/telemetry"
+)
+
+// SESProvider implements the Provider interface using the Amazon SES v2 API.
+// Requests are signed with AWS Signature Version 4 using static credentials;
+// instance profiles and other credential chains are not supported.
+type SESProvider struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // Optional, for temporary credentials
+	FromEmail       string
+	FromName        string
+	client          *http.Client
+	endpoint        string // Configurable endpoint for testing
+	now             func() time.Time
+}
+
+// NewSESProvider creates a new SES provider from environment variables.
+// SES_REGION falls back to AWS_REGION.
+func NewSESProvider() *SESProvider {
+	region := os.Getenv("SES_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	endpoint := os.Getenv("SES_ENDPOINT")
+	if endpoint == "" && region != "" {
+		endpoint = fmt.Sprintf("https://email.%s.amazonaws.com", region)
+	}
+
+	return &SESProvider{
+		Region:          region,
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		FromEmail:       os.Getenv("SES_FROM_EMAIL"),
+		FromName:        os.Getenv("SES_FROM_NAME"),
+		endpoint:        strings.TrimRight(endpoint, "/"),
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		now: time.Now,
+	}
+}
+
+// IsConfigured checks if the SES provider is properly configured
+func (p *SESProvider) IsConfigured() bool {
+	return p.Region != "" && p.AccessKeyID != "" && p.SecretAccessKey != "" && p.FromEmail != "" && p.endpoint != ""
+}
+
+// GetProviderName returns the provider name for logging
+func (p *SESProvider) GetProviderName() string {
+	return "ses"
+}
+
+// sesContent is an SES v2 Content.Simple text part
+type sesContent struct {
+	Data    string `json:"Data"`
+	Charset string `json:"Charset"`
+}
+
+// SESEmailRequest represents the SES v2 SendEmail request structure
+type SESEmailRequest struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses []string `json:"ToAddresses"`
+	} `json:"Destination"`
+	ReplyToAddresses []string `json:"ReplyToAddresses,omitempty"`
+	Content          struct {
+		Simple struct {
+			Subject sesContent `json:"Subject"`
+			Body    struct {
+				HTML sesContent `json:"Html"`
+			} `json:"Body"`
+		} `json:"Simple"`
+	} `json:"Content"`
+}
+
+// sesError reads the message from an SES v2 error response
+func sesError(status int, body []byte) error {
+	var resp struct {
+		Message      string `json:"message"`
+		MessageUpper string `json:"Message"`
+	}
+	if err := json.Unmarshal(body, &resp); err == nil {
+		if resp.Message != "" {
+			return fmt.Errorf("SES API error: %s", resp.Message)
+		}
+		if resp.MessageUpper != "" {
+			return fmt.Errorf("SES API error: %s", resp.MessageUpper)
+		}
+	}
+	return fmt.Errorf("SES API error: status %d", status)
+}
+
+// do sends a signed request to the SES v2 API and returns the response body
+func (p *SESProvider) do(ctx context.Context, method, path string, payload []byte) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, p.endpoint+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	signAWSRequestV4(req, payload, p.AccessKeyID, p.SecretAccessKey, p.SessionToken, p.Region, "ses", p.now())
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to read response: %w", err)
+	}
+	return body, resp.StatusCode, nil
+}
+
+// SendEmail sends an email using the SES v2 API
+func (p *SESProvider) SendEmail(ctx context.Context, to, subject, htmlBody string) error {
+	if !p.IsConfigured() {
+		return fmt.Errorf("SES provider is not configured")
+	}
+
+	ctx, span := tracer.Start(ctx, "email.ses.send", trace.WithAttributes(
+		attribute.Int("email.body_size_bytes", len(htmlBody)),
+	))
+	defer span.End()
+
+	fromName, replyTo := senderFields(ctx, p.FromName)
+
+	var payload SESEmailRequest
+	payload.FromEmailAddress = formatFrom(fromName, p.FromEmail)
+	payload.Destination.ToAddresses = []string{to}
+	if replyTo != "" {
+		payload.ReplyToAddresses = []string{replyTo}
+	}
+	payload.Content.Simple.Subject = sesContent{Data: subject, Charset: "UTF-8"}
+	payload.Content.Simple.Body.HTML = sesContent{Data: htmlBody, Charset: "UTF-8"}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return telemetry.Fail(span, fmt.Errorf("failed to marshal request: %w", err), "failed to marshal request")
+	}
+
+	body, status, err := p.do(ctx, http.MethodPost, "/v2/email/outbound-emails", jsonData)
+	if err != nil {
+		return telemetry.Fail(span, err, "request failed")
+	}
+	if status != http.StatusOK {
+		return telemetry.Fail(span, sesError(status, body), "non-200 response")
+	}
+	return nil
+}
+
+// CheckHealth verifies the credentials with SES GetAccount and that sending
+// hasn't been paused for the account.
+func (p *SESProvider) CheckHealth(ctx context.Context) error {
+	if !p.IsConfigured() {
+		return fmt.Errorf("SES provider is not configured")
+	}
+	body, status, err := p.do(ctx, http.MethodGet, "/v2/email/account", nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return sesError(status, body)
+	}
+	var account struct {
+		SendingEnabled bool `json:"SendingEnabled"`
+	}
+	if err := json.Unmarshal(body, &account); err != nil {
+		return fmt.Errorf("failed to parse SES account: %w", err)
+	}
+	if !account.SendingEnabled {
+		return errors.New("SES sending is paused for this account")
+	}
+	return nil
+}
+
+// signAWSRequestV4 adds AWS Signature Version 4 headers to req. The Host,
+// Content-Type, and X-Amz-* headers are signed.
+func signAWSRequestV4(req *http.Request, payload []byte, accessKey, secretKey, sessionToken, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	payloadHash := sha256.Sum256(payload)
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + secretKey)
+	for _, part := range []string{date, region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
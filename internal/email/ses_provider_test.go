@@ -0,0 +1,98 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAWSRequestV4(t *testing.T) {
+	// The example request from the AWS Signature Version 4 documentation
+	req, _ := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	signAWSRequestV4(req, nil, "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "", "us-east-1", "iam",
+		time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-date, " +
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %s\nwant %s", got, want)
+	}
+	if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+		t.Errorf("X-Amz-Date = %s", got)
+	}
+}
+
+func newTestSESProvider(server *httptest.Server) *SESProvider {
+	return &SESProvider{
+		Region:          "us-east-1",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "secret",
+		SessionToken:    "session",
+		FromEmail:       "noreply@example.com",
+		FromName:        "Volunteers",
+		client:          server.Client(),
+		endpoint:        server.URL,
+		now:             time.Now,
+	}
+}
+
+func TestSESProvider_SendEmail(t *testing.T) {
+	var got SESEmailRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v2/email/outbound-emails" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") ||
+			!strings.Contains(auth, "/us-east-1/ses/aws4_request") || !strings.Contains(auth, "x-amz-security-token") {
+			t.Errorf("unexpected Authorization header %q", auth)
+		}
+		if r.Header.Get("X-Amz-Security-Token") != "session" {
+			t.Error("session token not sent")
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"MessageId":"abc"}`))
+	}))
+	defer server.Close()
+
+	provider := newTestSESProvider(server)
+	if err := provider.SendEmail(context.Background(), "to@example.com", "Hello", "<p>Hi</p>"); err != nil {
+		t.Fatalf("SendEmail: %v", err)
+	}
+	if got.FromEmailAddress != "Volunteers <noreply@example.com>" || len(got.Destination.ToAddresses) != 1 ||
+		got.Destination.ToAddresses[0] != "to@example.com" || got.Content.Simple.Body.HTML.Data != "<p>Hi</p>" {
+		t.Errorf("unexpected request body %+v", got)
+	}
+}
+
+func TestSESProvider_Errors(t *testing.T) {
+	sendingEnabled := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/email/account" {
+			_ = json.NewEncoder(w).Encode(map[string]bool{"SendingEnabled": sendingEnabled})
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"message":"Email address is not verified."}`))
+	}))
+	defer server.Close()
+
+	provider := newTestSESProvider(server)
+	err := provider.SendEmail(context.Background(), "to@example.com", "Hello", "<p>Hi</p>")
+	if err == nil || !strings.Contains(err.Error(), "not verified") {
+		t.Errorf("expected the SES error message, got %v", err)
+	}
+
+	if err := provider.CheckHealth(context.Background()); err != nil {
+		t.Errorf("CheckHealth: %v", err)
+	}
+	sendingEnabled = false
+	if err := provider.CheckHealth(context.Background()); err == nil {
+		t.Error("expected CheckHealth to fail when sending is paused")
+	}
+}
@@ -0,0 +1,300 @@
+package email
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// snsHostPattern matches the hosts SNS signing certificates and subscription
+// confirmation links are served from.
+var snsHostPattern = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+// maxSNSCertificateSize bounds a downloaded SNS signing certificate
+const maxSNSCertificateSize = 64 << 10
+
+// SESBounceWebhook verifies and reads the Amazon SNS notifications SES sends
+// for bounces and complaints. Messages must be signed by SNS and come from
+// one of the configured topics; anyone can create an SNS topic, so the topic
+// allowlist is what ties a notification to this deployment.
+type SESBounceWebhook struct {
+	TopicARNs   []string
+	client      *http.Client
+	hostPattern *regexp.Regexp
+
+	mu    sync.Mutex
+	certs map[string]*x509.Certificate
+}
+
+// NewSESBounceWebhook creates an SES bounce webhook from the comma-separated
+// SES_SNS_TOPIC_ARNS environment variable. Returns nil if it's unset.
+func NewSESBounceWebhook() *SESBounceWebhook {
+	var arns []string
+	for _, arn := range strings.Split(os.Getenv("SES_SNS_TOPIC_ARNS"), ",") {
+		if arn = strings.TrimSpace(arn); arn != "" {
+			arns = append(arns, arn)
+		}
+	}
+	if len(arns) == 0 {
+		return nil
+	}
+	return &SESBounceWebhook{
+		TopicARNs:   arns,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		hostPattern: snsHostPattern,
+		certs:       map[string]*x509.Certificate{},
+	}
+}
+
+// snsMessage is an SNS HTTP(S) delivery
+type snsMessage struct {
+	Type             string `json:"Type"`
+	MessageID        string `json:"MessageId"`
+	Token            string `json:"Token"`
+	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject"`
+	Message          string `json:"Message"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+	SubscribeURL     string `json:"SubscribeURL"`
+}
+
+// stringToSign builds the canonical text SNS signs for the message's type
+func (m *snsMessage) stringToSign() string {
+	var b strings.Builder
+	add := func(key, value string) {
+		b.WriteString(key + "\n" + value + "\n")
+	}
+	add("Message", m.Message)
+	add("MessageId", m.MessageID)
+	if m.Type == "Notification" {
+		if m.Subject != "" {
+			add("Subject", m.Subject)
+		}
+		add("Timestamp", m.Timestamp)
+		add("TopicArn", m.TopicArn)
+		add("Type", m.Type)
+	} else {
+		add("SubscribeURL", m.SubscribeURL)
+		add("Timestamp", m.Timestamp)
+		add("Token", m.Token)
+		add("TopicArn", m.TopicArn)
+		add("Type", m.Type)
+	}
+	return b.String()
+}
+
+// snsURL parses raw and checks that it's an https URL on an SNS host
+func (w *SESBounceWebhook) snsURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || !w.hostPattern.MatchString(u.Hostname()) {
+		return nil, fmt.Errorf("untrusted SNS URL %q", raw)
+	}
+	return u, nil
+}
+
+// certificate returns the SNS signing certificate at certURL, downloading it
+// the first time
+func (w *SESBounceWebhook) certificate(ctx context.Context, certURL string) (*x509.Certificate, error) {
+	u, err := w.snsURL(certURL)
+	if err != nil || !strings.HasSuffix(u.Path, ".pem") {
+		return nil, fmt.Errorf("untrusted signing certificate URL %q", certURL)
+	}
+	w.mu.Lock()
+	cert, ok := w.certs[certURL]
+	w.mu.Unlock()
+	if ok {
+		return cert, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download signing certificate: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download signing certificate: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSNSCertificateSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download signing certificate: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing certificate is not PEM")
+	}
+	cert, err = x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing certificate: %w", err)
+	}
+
+	w.mu.Lock()
+	w.certs[certURL] = cert
+	w.mu.Unlock()
+	return cert, nil
+}
+
+// verify checks the message's SNS signature
+func (w *SESBounceWebhook) verify(ctx context.Context, m *snsMessage) error {
+	var hash crypto.Hash
+	switch m.SignatureVersion {
+	case "1":
+		hash = crypto.SHA1
+	case "2":
+		hash = crypto.SHA256
+	default:
+		return ErrInvalidWebhookSignature
+	}
+	sig, err := base64.StdEncoding.DecodeString(m.Signature)
+	if err != nil {
+		return ErrInvalidWebhookSignature
+	}
+	cert, err := w.certificate(ctx, m.SigningCertURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhookSignature, err)
+	}
+	publicKey, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return ErrInvalidWebhookSignature
+	}
+
+	var digest []byte
+	if hash == crypto.SHA1 {
+		sum := sha1.Sum([]byte(m.stringToSign())) // SignatureVersion 1 is SHA1withRSA
+		digest = sum[:]
+	} else {
+		sum := sha256.Sum256([]byte(m.stringToSign()))
+		digest = sum[:]
+	}
+	if err := rsa.VerifyPKCS1v15(publicKey, hash, digest, sig); err != nil {
+		return ErrInvalidWebhookSignature
+	}
+	return nil
+}
+
+// sesNotification is the part of an SES bounce or complaint notification the
+// webhook reads. SES notifications name their type in notificationType;
+// configuration set event publishing uses eventType.
+type sesNotification struct {
+	NotificationType string `json:"notificationType"`
+	EventType        string `json:"eventType"`
+	Bounce           struct {
+		BounceType        string `json:"bounceType"`
+		BouncedRecipients []struct {
+			EmailAddress   string `json:"emailAddress"`
+			DiagnosticCode string `json:"diagnosticCode"`
+		} `json:"bouncedRecipients"`
+	} `json:"bounce"`
+	Complaint struct {
+		ComplaintFeedbackType string `json:"complaintFeedbackType"`
+		ComplainedRecipients  []struct {
+			EmailAddress string `json:"emailAddress"`
+		} `json:"complainedRecipients"`
+	} `json:"complaint"`
+}
+
+// Parse verifies an SNS delivery and returns the addresses it reports as
+// undeliverable: permanent bounces and complaints. A subscription
+// confirmation is confirmed with SNS and reports no addresses. Messages
+// whose signed Timestamp is more than maxWebhookClockSkew from now are
+// rejected.
+func (w *SESBounceWebhook) Parse(ctx context.Context, body []byte) ([]Suppression, error) {
+	var m snsMessage
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse SNS message: %w", err)
+	}
+	if !slices.Contains(w.TopicARNs, m.TopicArn) {
+		return nil, fmt.Errorf("%w: unexpected topic %q", ErrInvalidWebhookSignature, m.TopicArn)
+	}
+	if err := w.verify(ctx, &m); err != nil {
+		return nil, err
+	}
+	signed, err := time.Parse(time.RFC3339, m.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid timestamp %q", ErrInvalidWebhookSignature, m.Timestamp)
+	}
+	if err := checkWebhookTimestamp(signed); err != nil {
+		return nil, err
+	}
+
+	switch m.Type {
+	case "SubscriptionConfirmation":
+		return nil, w.confirmSubscription(ctx, m.SubscribeURL)
+	case "Notification":
+	default:
+		return nil, nil
+	}
+
+	var n sesNotification
+	if err := json.Unmarshal([]byte(m.Message), &n); err != nil {
+		return nil, fmt.Errorf("failed to parse SES notification: %w", err)
+	}
+	kind := n.NotificationType
+	if kind == "" {
+		kind = n.EventType
+	}
+	var suppressions []Suppression
+	switch kind {
+	case "Bounce":
+		// Transient bounces (full mailbox, greylisting) may succeed later
+		if n.Bounce.BounceType != "Permanent" {
+			return nil, nil
+		}
+		for _, r := range n.Bounce.BouncedRecipients {
+			if s, ok := newSuppression(r.EmailAddress, SuppressionBounce, r.DiagnosticCode); ok {
+				suppressions = append(suppressions, s)
+			}
+		}
+	case "Complaint":
+		for _, r := range n.Complaint.ComplainedRecipients {
+			if s, ok := newSuppression(r.EmailAddress, SuppressionComplaint, n.Complaint.ComplaintFeedbackType); ok {
+				suppressions = append(suppressions, s)
+			}
+		}
+	}
+	return suppressions, nil
+}
+
+// confirmSubscription visits the SubscribeURL of a verified subscription
+// confirmation, which starts delivery to this endpoint
+func (w *SESBounceWebhook) confirmSubscription(ctx context.Context, subscribeURL string) error {
+	u, err := w.snsURL(subscribeURL)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to confirm SNS subscription: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to confirm SNS subscription: status %d", resp.StatusCode)
+	}
+	return nil
+}
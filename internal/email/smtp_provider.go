@@ -90,19 +90,22 @@ func (p *SMTPProvider) SendEmail(ctx context.Context, to, subject, htmlBody stri
 	))
 	defer span.End()
 
-	from := p.FromEmail
-	if p.FromName != "" {
-		from = fmt.Sprintf("%s <%s>", p.FromName, p.FromEmail)
+	fromName, replyTo := senderFields(ctx, p.FromName)
+	from := formatFrom(fromName, p.FromEmail)
+	replyToHeader := ""
+	if replyTo != "" {
+		replyToHeader = "Reply-To: " + replyTo + "\r\n"
 	}
 
 	// Build email message
 	msg := []byte(fmt.Sprintf("From: %s\r\n"+
+		"%s"+
 		"To: %s\r\n"+
 		"Subject: %s\r\n"+
 		"MIME-Version: 1.0\r\n"+
 		"Content-Type: text/html; charset=UTF-8\r\n"+
 		"\r\n"+
-		"%s\r\n", from, to, subject, htmlBody))
+		"%s\r\n", from, replyToHeader, to, subject, htmlBody))
 
 	// Set up authentication
 	auth := smtp.PlainAuth("", p.Username, p.Password, p.Host)
@@ -144,6 +147,19 @@ func (p *SMTPProvider) SendEmail(ctx context.Context, to, subject, htmlBody stri
 	return nil
 }
 
+// CheckHealth verifies that the SMTP server accepts connections. It doesn't
+// authenticate, so bad credentials only show up when sending.
+func (p *SMTPProvider) CheckHealth(ctx context.Context) error {
+	if !p.IsConfigured() {
+		return fmt.Errorf("SMTP provider is not configured")
+	}
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", net.JoinHostPort(p.Host, p.Port))
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	return conn.Close()
+}
+
 // smtpStepError identifies which step of the SMTP client command sequence
 // (see runSMTPCommands) failed, so callers can attribute the failure to a
 // specific step without duplicating the sequence per caller.
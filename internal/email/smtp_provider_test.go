@@ -2,6 +2,7 @@ package email
 
 import (
 	"context"
+	"net"
 	"os"
 	"strings"
 	"testing"
@@ -244,3 +245,20 @@ func TestSMTPDeadline(t *testing.T) {
 		}
 	})
 }
+
+func TestSMTPProvider_CheckHealth(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	provider := &SMTPProvider{Host: host, Port: port, Username: "user", Password: "pass", FromEmail: "noreply@example.com"}
+
+	if err := provider.CheckHealth(context.Background()); err != nil {
+		t.Errorf("CheckHealth with a listening server: %v", err)
+	}
+	listener.Close()
+	if err := provider.CheckHealth(context.Background()); err == nil {
+		t.Error("expected CheckHealth to fail once the server stops listening")
+	}
+}
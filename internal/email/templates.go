@@ -0,0 +1,336 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// TemplateType names an email whose content admins can replace
+type TemplateType string
+
+const (
+	TemplateInvitation    TemplateType = "invitation"     // A new user's password setup link
+	TemplatePasswordReset TemplateType = "password_reset" // A password reset link
+	TemplateAnnouncement  TemplateType = "announcement"   // An announcement sent to opted-in users
+	TemplateWelcome       TemplateType = "welcome"        // A new group member's welcome and onboarding checklist
+)
+
+// TemplateVariable documents a value a template can use as {{.Name}}
+type TemplateVariable struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Example     string `json:"example"`
+}
+
+// TemplateDefinition describes an email type and the built-in content used
+// until an admin saves their own
+type TemplateDefinition struct {
+	Type           TemplateType       `json:"type"`
+	Description    string             `json:"description"`
+	Variables      []TemplateVariable `json:"variables"`
+	DefaultSubject string             `json:"default_subject"`
+	DefaultBody    string             `json:"default_body"`
+}
+
+// ErrUnknownTemplate is returned for a template type that doesn't exist
+var ErrUnknownTemplate = errors.New("unknown email template")
+
+// templateStyle is the stylesheet shared by the built-in templates
+const templateStyle = `
+    <style>
+        body { font-family: Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
+        .header { background-color: #0e6c55; color: white; padding: 20px; text-align: center; }
+        .content { padding: 20px; background-color: #f8fafc; }
+        .button { display: inline-block; padding: 12px 24px; background-color: #0e6c55; color: white; text-decoration: none; border-radius: 4px; margin: 20px 0; }
+        .footer { text-align: center; padding: 20px; font-size: 12px; color: #666; }
+        .welcome { font-size: 18px; font-weight: bold; color: #0e6c55; margin-bottom: 10px; }
+    </style>`
+
+var siteNameVariable = TemplateVariable{Name: "SiteName", Description: "The site name from site settings", Example: models.DefaultSiteName}
+
+var templateDefinitions = []TemplateDefinition{
+	{
+		Type:        TemplateInvitation,
+		Description: "Sent when an admin creates an account, with a link to set a password",
+		Variables: []TemplateVariable{
+			siteNameVariable,
+			{Name: "Username", Description: "The new user's username", Example: "jsmith"},
+			{Name: "SetupLink", Description: "Link to set a password; expires in 7 days", Example: "https://example.org/setup-password?token=example"},
+		},
+		DefaultSubject: "Welcome to {{.SiteName}} - Set Your Password",
+		DefaultBody: `
+<!DOCTYPE html>
+<html>
+<head>` + templateStyle + `
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Welcome to {{.SiteName}}!</h1>
+        </div>
+        <div class="content">
+            <p class="welcome">Hello {{.Username}},</p>
+            <p>Your username for signing in is: <strong>{{.Username}}</strong></p>
+            <p>Your account has been created for {{.SiteName}}. We're excited to have you join our team!</p>
+            <p>To get started, please click the button below to set your password:</p>
+            <p style="text-align: center;">
+                <a href="{{.SetupLink}}" class="button">Set Your Password</a>
+            </p>
+            <p>Or copy and paste this link into your browser:</p>
+            <p style="word-break: break-all; color: #0e6c55;">{{.SetupLink}}</p>
+            <p><strong>This link will expire in 7 days.</strong></p>
+            <p>Once you've set your password, you'll be able to sign in and start contributing to our mission of helping animals in need.</p>
+            <p>If you have any questions or didn't expect this invitation, please contact your administrator.</p>
+        </div>
+        <div class="footer">
+            <p>© {{.SiteName}} - This is an automated message, please do not reply.</p>
+        </div>
+    </div>
+</body>
+</html>
+`,
+	},
+	{
+		Type:        TemplatePasswordReset,
+		Description: "Sent when a user asks to reset their password, or an admin forces a reset",
+		Variables: []TemplateVariable{
+			siteNameVariable,
+			{Name: "Username", Description: "The user's username", Example: "jsmith"},
+			{Name: "ResetLink", Description: "Link to choose a new password; expires in 1 hour", Example: "https://example.org/reset-password?token=example"},
+		},
+		DefaultSubject: "Password Reset Request - {{.SiteName}}",
+		DefaultBody: `
+<!DOCTYPE html>
+<html>
+<head>` + templateStyle + `
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Password Reset Request</h1>
+        </div>
+        <div class="content">
+            <p>Hello {{.Username}},</p>
+            <p>We received a request to reset your password for your {{.SiteName}} account.</p>
+            <p>Click the button below to reset your password:</p>
+            <p style="text-align: center;">
+                <a href="{{.ResetLink}}" class="button">Reset Password</a>
+            </p>
+            <p>Or copy and paste this link into your browser:</p>
+            <p style="word-break: break-all; color: #0e6c55;">{{.ResetLink}}</p>
+            <p><strong>This link will expire in 1 hour.</strong></p>
+            <p>If you didn't request a password reset, you can safely ignore this email.</p>
+        </div>
+        <div class="footer">
+            <p>© {{.SiteName}} - This is an automated message, please do not reply.</p>
+        </div>
+    </div>
+</body>
+</html>
+`,
+	},
+	{
+		Type:        TemplateAnnouncement,
+		Description: "Sent to users who opted in to email when an announcement is posted",
+		Variables: []TemplateVariable{
+			siteNameVariable,
+			{Name: "Title", Description: "The announcement's title", Example: "Adoption event this Saturday"},
+			{Name: "Content", Description: "The announcement's text, with its line breaks kept", Example: "Volunteers needed from 10am to 2pm."},
+		},
+		DefaultSubject: "Announcement: {{.Title}} - {{.SiteName}}",
+		DefaultBody: `
+<!DOCTYPE html>
+<html>
+<head>` + templateStyle + `
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>{{.Title}}</h1>
+        </div>
+        <div class="content">
+            {{.Content}}
+        </div>
+        <div class="footer">
+            <p>© {{.SiteName}} - You're receiving this because you opted in to email notifications.</p>
+            <p>You can manage your email preferences in your account settings.</p>
+        </div>
+    </div>
+</body>
+</html>
+`,
+	},
+	{
+		Type:        TemplateWelcome,
+		Description: "Sent to a new member of a group with an onboarding checklist",
+		Variables: []TemplateVariable{
+			siteNameVariable,
+			{Name: "Username", Description: "The new member's username", Example: "jsmith"},
+			{Name: "GroupName", Description: "The group they joined", Example: "Dogs"},
+			{Name: "WelcomeText", Description: "The group's welcome text, with its line breaks kept; may be empty", Example: "Thanks for joining the dog team!"},
+			{Name: "Checklist", Description: "The group's onboarding checklist, as a list", Example: "Read the protocols, Complete your profile, Attend an orientation"},
+			{Name: "Link", Description: "Link to the member's onboarding checklist", Example: "https://example.org/groups/1/onboarding"},
+		},
+		DefaultSubject: "Welcome to {{.GroupName}} - {{.SiteName}}",
+		DefaultBody: `
+<!DOCTYPE html>
+<html>
+<head>` + templateStyle + `
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Welcome to {{.GroupName}}!</h1>
+        </div>
+        <div class="content">
+            <p class="welcome">Hello {{.Username}},</p>
+            {{if .WelcomeText}}<p>{{.WelcomeText}}</p>{{end}}
+            <p>To get started, please work through these steps:</p>
+            {{.Checklist}}
+            <p style="text-align: center;">
+                <a href="{{.Link}}" class="button">Open Your Checklist</a>
+            </p>
+        </div>
+        <div class="footer">
+            <p>© {{.SiteName}} - You're receiving this because you joined {{.GroupName}}.</p>
+        </div>
+    </div>
+</body>
+</html>
+`,
+	},
+}
+
+// TemplateDefinitions returns every email type admins can customize
+func TemplateDefinitions() []TemplateDefinition {
+	return templateDefinitions
+}
+
+// LookupTemplate returns the definition for an email type
+func LookupTemplate(t TemplateType) (TemplateDefinition, bool) {
+	for _, d := range templateDefinitions {
+		if d.Type == t {
+			return d, true
+		}
+	}
+	return TemplateDefinition{}, false
+}
+
+// sampleData fills each of the definition's variables with its example
+func (d TemplateDefinition) sampleData(siteName string) map[string]interface{} {
+	data := make(map[string]interface{}, len(d.Variables))
+	for _, v := range d.Variables {
+		data[v.Name] = v.Example
+	}
+	data[siteNameVariable.Name] = siteName
+	return data
+}
+
+// RenderTemplate renders a subject and HTML body with data. Values in the
+// body are HTML-escaped. Using a variable that isn't in data is an error.
+func RenderTemplate(subject, body string, data map[string]interface{}) (string, string, error) {
+	subjectTmpl, err := texttemplate.New("subject").Option("missingkey=error").Parse(subject)
+	if err != nil {
+		return "", "", fmt.Errorf("subject: %w", err)
+	}
+	bodyTmpl, err := htmltemplate.New("body").Option("missingkey=error").Parse(body)
+	if err != nil {
+		return "", "", fmt.Errorf("body: %w", err)
+	}
+	var subjectOut, bodyOut strings.Builder
+	if err := subjectTmpl.Execute(&subjectOut, data); err != nil {
+		return "", "", fmt.Errorf("subject: %w", err)
+	}
+	if err := bodyTmpl.Execute(&bodyOut, data); err != nil {
+		return "", "", fmt.Errorf("body: %w", err)
+	}
+	// Subjects are a single header line
+	renderedSubject := strings.Join(strings.Fields(subjectOut.String()), " ")
+	return renderedSubject, bodyOut.String(), nil
+}
+
+// ValidateTemplate checks that a subject and body for an email type parse
+// and use only its documented variables
+func ValidateTemplate(t TemplateType, subject, body string) error {
+	def, ok := LookupTemplate(t)
+	if !ok {
+		return ErrUnknownTemplate
+	}
+	if strings.TrimSpace(subject) == "" || strings.TrimSpace(body) == "" {
+		return errors.New("subject and body are required")
+	}
+	_, _, err := RenderTemplate(subject, body, def.sampleData(models.DefaultSiteName))
+	return err
+}
+
+// activeTemplate returns the saved template in use for an email type, if an
+// admin saved one
+func (s *Service) activeTemplate(ctx context.Context, t TemplateType) (models.EmailTemplate, bool) {
+	var tmpl models.EmailTemplate
+	if s.db == nil {
+		return tmpl, false
+	}
+	err := s.db.WithContext(ctx).Where("type = ? AND active = ?", string(t), true).Order("version DESC").First(&tmpl).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logging.FromContext(ctx).WithField("error", err.Error()).Warn("Failed to load email template; using built-in")
+	}
+	return tmpl, err == nil
+}
+
+// renderEmail renders an email type with data and the site name, using
+// the admin's saved template when there is one and the built-in otherwise.
+// A saved template that fails to render falls back to the built-in, so a
+// bad edit can't stop password resets from going out.
+func (s *Service) renderEmail(ctx context.Context, t TemplateType, data map[string]interface{}) (string, string, error) {
+	def, ok := LookupTemplate(t)
+	if !ok {
+		return "", "", ErrUnknownTemplate
+	}
+	data[siteNameVariable.Name] = s.getSiteName(ctx)
+	if tmpl, ok := s.activeTemplate(ctx, t); ok {
+		subject, body, err := RenderTemplate(tmpl.Subject, tmpl.Body, data)
+		if err == nil {
+			return subject, body, nil
+		}
+		logging.FromContext(ctx).WithFields(map[string]interface{}{
+			"template": string(t),
+			"version":  tmpl.Version,
+			"error":    err.Error(),
+		}).Warn("Failed to render email template; using built-in")
+	}
+	return RenderTemplate(def.DefaultSubject, def.DefaultBody, data)
+}
+
+// PreviewTemplate renders a subject and body for an email type with each
+// variable's example value. Empty subject and body preview the template
+// in use.
+func (s *Service) PreviewTemplate(ctx context.Context, t TemplateType, subject, body string) (string, string, error) {
+	def, ok := LookupTemplate(t)
+	if !ok {
+		return "", "", ErrUnknownTemplate
+	}
+	data := def.sampleData(s.getSiteName(ctx))
+	if subject == "" && body == "" {
+		return s.renderEmail(ctx, t, data)
+	}
+	return RenderTemplate(subject, body, data)
+}
+
+// SendTestTemplate sends a preview of an email type to one address, with
+// "[Test]" in front of its subject
+func (s *Service) SendTestTemplate(ctx context.Context, to string, t TemplateType, subject, body string) error {
+	renderedSubject, renderedBody, err := s.PreviewTemplate(ctx, t, subject, body)
+	if err != nil {
+		return err
+	}
+	return s.SendEmail(ctx, to, "[Test] "+renderedSubject, renderedBody)
+}
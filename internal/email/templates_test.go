@@ -0,0 +1,87 @@
+package email
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+)
+
+func TestValidateTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		t       TemplateType
+		subject string
+		body    string
+		wantErr bool
+	}{
+		{"documented variables", TemplatePasswordReset, "Reset for {{.Username}}", `<a href="{{.ResetLink}}">Reset</a>`, false},
+		{"another type's variable", TemplatePasswordReset, "Hi", "{{.SetupLink}}", true},
+		{"unparsable", TemplateInvitation, "Hi {{.Username", "Body", true},
+		{"empty body", TemplateAnnouncement, "{{.Title}}", " ", true},
+		{"unknown type", TemplateType("newsletter"), "Hi", "Body", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTemplate(tt.t, tt.subject, tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// The built-ins are held to the same rules
+	for _, def := range TemplateDefinitions() {
+		if err := ValidateTemplate(def.Type, def.DefaultSubject, def.DefaultBody); err != nil {
+			t.Errorf("built-in %s template is invalid: %v", def.Type, err)
+		}
+	}
+}
+
+func TestSavedTemplatesReplaceBuiltIns(t *testing.T) {
+	db := setupTestDB(t)
+	if err := db.AutoMigrate(&models.EmailTemplate{}); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	provider := &mockEmailProvider{configured: true}
+	service := NewServiceWithProvider(provider, db)
+	ctx := context.Background()
+
+	saved := models.EmailTemplate{Type: string(TemplatePasswordReset), Version: 1, Active: true,
+		Subject: "Reset your {{.SiteName}} password", Body: "<p>Hi {{.Username}}, go to {{.ResetLink}}</p>"}
+	if err := db.Create(&saved).Error; err != nil {
+		t.Fatalf("Failed to save template: %v", err)
+	}
+	if err := service.SendPasswordResetEmail(ctx, "user@example.com", "<b>sam</b>", "tok"); err != nil {
+		t.Fatalf("SendPasswordResetEmail() error = %v", err)
+	}
+	sent := provider.sentEmails[len(provider.sentEmails)-1]
+	if sent.subject != "Reset your "+models.DefaultSiteName+" password" {
+		t.Errorf("subject = %q", sent.subject)
+	}
+	if !strings.Contains(sent.body, "Hi &lt;b&gt;sam&lt;/b&gt;") {
+		t.Errorf("variables should be HTML-escaped, got %q", sent.body)
+	}
+
+	// A saved template that no longer renders falls back to the built-in
+	if err := db.Model(&saved).Update("body", "{{.Token}}").Error; err != nil {
+		t.Fatalf("Failed to update template: %v", err)
+	}
+	if err := service.SendPasswordResetEmail(ctx, "user@example.com", "sam", "tok"); err != nil {
+		t.Fatalf("SendPasswordResetEmail() error = %v", err)
+	}
+	sent = provider.sentEmails[len(provider.sentEmails)-1]
+	if !strings.Contains(sent.body, "Password Reset Request") {
+		t.Errorf("expected the built-in body, got %q", sent.body)
+	}
+
+	// Other types keep their built-ins
+	if err := service.SendAnnouncementEmail(ctx, "user@example.com", "Hello", "Line 1\nLine 2"); err != nil {
+		t.Fatalf("SendAnnouncementEmail() error = %v", err)
+	}
+	sent = provider.sentEmails[len(provider.sentEmails)-1]
+	if !strings.Contains(sent.body, "Line 1<br>Line 2") {
+		t.Errorf("announcement content should keep line breaks, got %q", sent.body)
+	}
+}
@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/auth"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/maintenance"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// DeactivateAccountRequest confirms a self-service account deactivation.
+type DeactivateAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// AccountExportProfile is the user's own profile, without credentials.
+type AccountExportProfile struct {
+	ID                        uint       `json:"id"`
+	Username                  string     `json:"username"`
+	FirstName                 string     `json:"first_name"`
+	LastName                  string     `json:"last_name"`
+	Email                     string     `json:"email"`
+	PhoneNumber               string     `json:"phone_number"`
+	HideEmail                 bool       `json:"hide_email"`
+	HidePhoneNumber           bool       `json:"hide_phone_number"`
+	EmailNotificationsEnabled bool       `json:"email_notifications_enabled"`
+	EmailVerifiedAt           *time.Time `json:"email_verified_at"`
+	LastLogin                 *time.Time `json:"last_login"`
+	CreatedAt                 time.Time  `json:"created_at"`
+}
+
+// AccountExportGroup is one of the user's group memberships.
+type AccountExportGroup struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	IsGroupAdmin bool   `json:"is_group_admin"`
+}
+
+// AccountExportComment is one comment the user wrote.
+type AccountExportComment struct {
+	ID         uint      `json:"id"`
+	GroupID    uint      `json:"group_id"`
+	AnimalID   uint      `json:"animal_id"`
+	AnimalName string    `json:"animal_name"`
+	Content    string    `json:"content"`
+	ImageURL   string    `json:"image_url,omitempty"`
+	Tags       []string  `json:"tags"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// AccountExport is everything GET /api/me/export returns.
+type AccountExport struct {
+	ExportedAt time.Time              `json:"exported_at"`
+	Profile    AccountExportProfile   `json:"profile"`
+	Groups     []AccountExportGroup   `json:"groups"`
+	Comments   []AccountExportComment `json:"comments"`
+}
+
+// buildAccountExport collects the user's profile, group memberships, and
+// comments.
+func buildAccountExport(db *gorm.DB, user models.User) (*AccountExport, error) {
+	export := &AccountExport{
+		ExportedAt: time.Now().UTC(),
+		Profile: AccountExportProfile{
+			ID:                        user.ID,
+			Username:                  user.Username,
+			FirstName:                 user.FirstName,
+			LastName:                  user.LastName,
+			Email:                     user.Email,
+			PhoneNumber:               user.PhoneNumber,
+			HideEmail:                 user.HideEmail,
+			HidePhoneNumber:           user.HidePhoneNumber,
+			EmailNotificationsEnabled: user.EmailNotificationsEnabled,
+			EmailVerifiedAt:           user.EmailVerifiedAt,
+			LastLogin:                 user.LastLogin,
+			CreatedAt:                 user.CreatedAt,
+		},
+		Groups:   []AccountExportGroup{},
+		Comments: []AccountExportComment{},
+	}
+
+	if err := db.Table("user_groups").
+		Select("groups.id, groups.name, user_groups.is_group_admin").
+		Joins("JOIN groups ON groups.id = user_groups.group_id AND groups.deleted_at IS NULL").
+		Where("user_groups.user_id = ?", user.ID).
+		Order("groups.name").
+		Scan(&export.Groups).Error; err != nil {
+		return nil, err
+	}
+
+	var comments []models.AnimalComment
+	if err := db.Preload("Tags").Where("user_id = ?", user.ID).Order("created_at").Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	animalIDs := make([]uint, 0, len(comments))
+	for _, comment := range comments {
+		animalIDs = append(animalIDs, comment.AnimalID)
+	}
+	var animals []models.Animal
+	if len(animalIDs) > 0 {
+		// Unscoped so comments on since-deleted animals keep their animal name
+		if err := db.Unscoped().Select("id", "name", "group_id").Where("id IN ?", animalIDs).Find(&animals).Error; err != nil {
+			return nil, err
+		}
+	}
+	animalsByID := make(map[uint]models.Animal, len(animals))
+	for _, animal := range animals {
+		animalsByID[animal.ID] = animal
+	}
+
+	for _, comment := range comments {
+		tags := make([]string, 0, len(comment.Tags))
+		for _, tag := range comment.Tags {
+			tags = append(tags, tag.Name)
+		}
+		animal := animalsByID[comment.AnimalID]
+		export.Comments = append(export.Comments, AccountExportComment{
+			ID:         comment.ID,
+			GroupID:    animal.GroupID,
+			AnimalID:   comment.AnimalID,
+			AnimalName: animal.Name,
+			Content:    comment.Content,
+			ImageURL:   comment.ImageURL,
+			Tags:       tags,
+			CreatedAt:  comment.CreatedAt,
+			UpdatedAt:  comment.UpdatedAt,
+		})
+	}
+	return export, nil
+}
+
+// writeAccountExportZip writes the export as a ZIP holding account.json and
+// comments.csv.
+func writeAccountExportZip(export *AccountExport) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	jsonFile, err := zw.Create("account.json")
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(jsonFile)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(export); err != nil {
+		return nil, err
+	}
+
+	csvFile, err := zw.Create("comments.csv")
+	if err != nil {
+		return nil, err
+	}
+	w := csv.NewWriter(csvFile)
+	_ = w.Write([]string{"comment_id", "created_at", "group_id", "animal_id", "animal_name", "tags", "content"})
+	for _, comment := range export.Comments {
+		_ = w.Write([]string{
+			fmt.Sprintf("%d", comment.ID),
+			comment.CreatedAt.Format(time.RFC3339),
+			fmt.Sprintf("%d", comment.GroupID),
+			fmt.Sprintf("%d", comment.AnimalID),
+			comment.AnimalName,
+			strings.Join(comment.Tags, ", "),
+			comment.Content,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ExportCurrentUserData downloads the current user's profile, group
+// memberships, and comments as JSON, or as a ZIP with ?format=zip.
+// Route: GET /api/me/export
+func ExportCurrentUserData(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+
+		format := c.DefaultQuery("format", "json")
+		if format != "json" && format != "zip" {
+			respondBadRequest(c, "format must be json or zip")
+			return
+		}
+
+		var user models.User
+		if err := db.First(&user, userID).Error; err != nil {
+			respondNotFound(c, "User not found")
+			return
+		}
+
+		export, err := buildAccountExport(db, user)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to build account export", err)
+			respondInternalError(c, "Failed to export account data")
+			return
+		}
+
+		filename := fmt.Sprintf("account-export-%d-%s", user.ID, export.ExportedAt.Format("2006-01-02"))
+		if format == "zip" {
+			data, err := writeAccountExportZip(export)
+			if err != nil {
+				middleware.GetLogger(c).Error("Failed to write account export archive", err)
+				respondInternalError(c, "Failed to export account data")
+				return
+			}
+			c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.zip", filename))
+			c.Data(http.StatusOK, "application/zip", data)
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.json", filename))
+		respondOK(c, export)
+	}
+}
+
+// DeactivateCurrentUser closes the current user's account. The account is
+// soft-deleted immediately, so the user can no longer log in, and an admin can
+// restore it during the grace period. After that its personal data is
+// anonymized by the account purge (see maintenance.PurgeDeactivatedAccounts).
+// Route: POST /api/me/deactivate
+func DeactivateCurrentUser(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+
+		var req DeactivateAccountRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+
+		var user models.User
+		if err := db.First(&user, userID).Error; err != nil {
+			respondNotFound(c, "User not found")
+			return
+		}
+		if err := auth.CheckPassword(user.Password, req.Password); err != nil {
+			respondError(c, http.StatusUnauthorized, ErrCodeUnauthorized, "Password is incorrect")
+			return
+		}
+
+		if user.IsAdmin {
+			var otherAdmins int64
+			if err := db.Model(&models.User{}).Where("is_admin = ? AND id != ?", true, user.ID).Count(&otherAdmins).Error; err != nil {
+				respondInternalError(c, "Failed to deactivate account")
+				return
+			}
+			if otherAdmins == 0 {
+				respondError(c, http.StatusConflict, ErrCodeConflict, "The last site admin can't deactivate their account")
+				return
+			}
+		}
+
+		now := time.Now()
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&user).Update("deactivation_requested_at", now).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("user_id = ?", user.ID).Delete(&models.APIToken{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&user).Error
+		}); err != nil {
+			middleware.GetLogger(c).Error("Failed to deactivate account", err)
+			respondInternalError(c, "Failed to deactivate account")
+			return
+		}
+
+		logging.WithField("user_id", user.ID).Info("User deactivated their account")
+		respondOK(c, gin.H{
+			"message":     "Account deactivated",
+			"purge_after": now.Add(maintenance.AccountDeletionGracePeriod()),
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/maintenance"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func accountTestContext(userID uint, isAdmin bool, method, target string, body any) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", userID)
+	c.Set("is_admin", isAdmin)
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	c.Request = httptest.NewRequest(method, target, &buf)
+	c.Request.Header.Set("Content-Type", "application/json")
+	return c, w
+}
+
+func TestDeactivateCurrentUser(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	volunteer := CreateTestUser(t, db, "volunteer", "volunteer@example.com", "password123", false)
+	require.NoError(t, db.Create(&models.APIToken{UserID: volunteer.ID, Name: "cli", TokenHash: "hash"}).Error)
+
+	c, w := accountTestContext(volunteer.ID, false, http.MethodPost, "/api/me/deactivate", map[string]string{"password": "wrong-password"})
+	DeactivateCurrentUser(db)(c)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	c, w = accountTestContext(admin.ID, true, http.MethodPost, "/api/me/deactivate", map[string]string{"password": "password123"})
+	DeactivateCurrentUser(db)(c)
+	assert.Equal(t, http.StatusConflict, w.Code, "the last site admin can't deactivate")
+
+	c, w = accountTestContext(volunteer.ID, false, http.MethodPost, "/api/me/deactivate", map[string]string{"password": "password123"})
+	DeactivateCurrentUser(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var user models.User
+	require.NoError(t, db.Unscoped().First(&user, volunteer.ID).Error)
+	assert.True(t, user.DeletedAt.Valid)
+	assert.NotNil(t, user.DeactivationRequestedAt)
+	var tokens int64
+	db.Model(&models.APIToken{}).Where("user_id = ?", volunteer.ID).Count(&tokens)
+	assert.Zero(t, tokens, "API tokens are revoked")
+}
+
+func TestExportCurrentUserData(t *testing.T) {
+	db := SetupTestDB(t)
+	user := CreateTestUser(t, db, "volunteer", "volunteer@example.com", "password123", false)
+	other := CreateTestUser(t, db, "other", "other@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "Dog group")
+	AddUserToGroupWithAdmin(t, db, user.ID, group.ID, true)
+	animal := models.Animal{GroupID: group.ID, Name: "Rex", Status: "available"}
+	require.NoError(t, db.Create(&animal).Error)
+	tag := models.CommentTag{Name: "behavior"}
+	require.NoError(t, db.Create(&tag).Error)
+	require.NoError(t, db.Create(&models.AnimalComment{AnimalID: animal.ID, UserID: user.ID, Content: "Great walk", Tags: []models.CommentTag{tag}}).Error)
+	require.NoError(t, db.Create(&models.AnimalComment{AnimalID: animal.ID, UserID: other.ID, Content: "Not mine"}).Error)
+
+	c, w := accountTestContext(user.ID, false, http.MethodGet, "/api/me/export", nil)
+	ExportCurrentUserData(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Disposition"), ".json")
+	assert.NotContains(t, w.Body.String(), "password")
+
+	var export AccountExport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &export))
+	assert.Equal(t, "volunteer@example.com", export.Profile.Email)
+	require.Len(t, export.Groups, 1)
+	assert.True(t, export.Groups[0].IsGroupAdmin)
+	require.Len(t, export.Comments, 1)
+	assert.Equal(t, "Rex", export.Comments[0].AnimalName)
+	assert.Equal(t, []string{"behavior"}, export.Comments[0].Tags)
+
+	c, w = accountTestContext(user.ID, false, http.MethodGet, "/api/me/export?format=zip", nil)
+	ExportCurrentUserData(db)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	require.NoError(t, err)
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		if f.Name == "comments.csv" {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			data, _ := io.ReadAll(rc)
+			rc.Close()
+			assert.Contains(t, string(data), "Great walk")
+		}
+	}
+	assert.ElementsMatch(t, []string{"account.json", "comments.csv"}, names)
+
+	c, w = accountTestContext(user.ID, false, http.MethodGet, "/api/me/export?format=xml", nil)
+	ExportCurrentUserData(db)(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPurgeDeactivatedAccounts(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	expired := CreateTestUser(t, db, "leaving", "leaving@example.com", "password123", false)
+	recent := CreateTestUser(t, db, "recent", "recent@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "Dog group")
+	AddUserToGroupWithAdmin(t, db, expired.ID, group.ID, false)
+	animal := models.Animal{GroupID: group.ID, Name: "Rex", Status: "available"}
+	require.NoError(t, db.Create(&animal).Error)
+	comment := models.AnimalComment{AnimalID: animal.ID, UserID: expired.ID, Content: "Good boy"}
+	require.NoError(t, db.Create(&comment).Error)
+	require.NoError(t, db.Create(&models.UsernameHistory{UserID: expired.ID, OldUsername: "leaving-old", NewUsername: "leaving", ChangedBy: expired.ID}).Error)
+
+	deactivate := func(user *models.User, at time.Time) {
+		db.Model(user).Update("deactivation_requested_at", at)
+		db.Delete(user)
+	}
+	deactivate(expired, time.Now().AddDate(0, 0, -31))
+	deactivate(recent, time.Now().AddDate(0, 0, -1))
+
+	purged, err := maintenance.PurgeDeactivatedAccounts(db, 30*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	var anonymized models.User
+	require.NoError(t, db.Unscoped().First(&anonymized, expired.ID).Error)
+	assert.Equal(t, fmt.Sprintf("deleted-user-%d", expired.ID), anonymized.Username)
+	assert.NotContains(t, anonymized.Email, "leaving")
+	assert.NotNil(t, anonymized.AnonymizedAt)
+	var memberships int64
+	db.Model(&models.UserGroup{}).Where("user_id = ?", expired.ID).Count(&memberships)
+	assert.Zero(t, memberships)
+	var history int64
+	db.Model(&models.UsernameHistory{}).Where("user_id = ?", expired.ID).Count(&history)
+	assert.Zero(t, history, "old usernames are personal data too")
+
+	var kept models.AnimalComment
+	require.NoError(t, db.First(&kept, comment.ID).Error)
+	assert.Equal(t, "Good boy", kept.Content, "comments stay, attributed to the anonymized account")
+
+	var stillPending models.User
+	require.NoError(t, db.Unscoped().First(&stillPending, recent.ID).Error)
+	assert.Equal(t, "recent", stillPending.Username, "accounts within the grace period are untouched")
+
+	// Anonymized accounts can't be restored
+	c, w := accountTestContext(admin.ID, true, http.MethodPost, "/", nil)
+	c.Params = gin.Params{{Key: "userId", Value: fmt.Sprint(expired.ID)}}
+	RestoreUser(db)(c)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	c, w = accountTestContext(admin.ID, true, http.MethodPost, "/", nil)
+	c.Params = gin.Params{{Key: "userId", Value: fmt.Sprint(recent.ID)}}
+	RestoreUser(db)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var restored models.User
+	require.NoError(t, db.First(&restored, recent.ID).Error)
+	assert.Nil(t, restored.DeactivationRequestedAt, "restoring cancels the pending purge")
+}
@@ -1,13 +1,17 @@
 package handlers
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"net/http"
-	"sort"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/database"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
 	"gorm.io/gorm"
@@ -15,18 +19,21 @@ import (
 
 // ActivityItem represents a unified activity feed item
 type ActivityItem struct {
-	ID        uint                    `json:"id"`
-	Type      string                  `json:"type"` // "comment", "announcement"
-	CreatedAt time.Time               `json:"created_at"`
-	UserID    uint                    `json:"user_id"`
-	User      *models.User            `json:"user,omitempty"`
-	Content   string                  `json:"content"`
-	Title     string                  `json:"title,omitempty"` // For announcements
-	ImageURL  string                  `json:"image_url,omitempty"`
-	AnimalID  *uint                   `json:"animal_id,omitempty"` // For comments
-	Animal    *models.Animal          `json:"animal,omitempty"`    // For comments
-	Tags      []models.CommentTag     `json:"tags,omitempty"`      // For comments
-	Metadata  *models.SessionMetadata `json:"metadata,omitempty"`  // For session reports
+	ID           uint                       `json:"id"`
+	Type         string                     `json:"type"` // "comment", "announcement", "animal_change", "name_change", "photo", "discussion", "discussion_reply"
+	CreatedAt    time.Time                  `json:"created_at"`
+	UserID       uint                       `json:"user_id"`
+	User         *models.User               `json:"user,omitempty"`
+	Content      string                     `json:"content"`
+	Title        string                     `json:"title,omitempty"` // For announcements and discussions
+	ImageURL     string                     `json:"image_url,omitempty"`
+	AnimalID     *uint                      `json:"animal_id,omitempty"`     // For items about an animal
+	Animal       *models.Animal             `json:"animal,omitempty"`        // For items about an animal
+	Tags         []models.CommentTag        `json:"tags,omitempty"`          // For comments
+	Reactions    []models.ReactionCount     `json:"reactions,omitempty"`     // For comments
+	Metadata     *models.SessionMetadata    `json:"metadata,omitempty"`      // For session reports
+	Changes      []models.AnimalFieldChange `json:"changes,omitempty"`       // For animal changes and name changes
+	DiscussionID *uint                      `json:"discussion_id,omitempty"` // For discussions and their replies
 }
 
 // ActivityFeedSummary provides quick stats about concerns
@@ -36,234 +43,664 @@ type ActivityFeedSummary struct {
 	PoorSessionsCount     int `json:"poor_sessions_count"` // Sessions rated 1-2
 }
 
-// GetGroupActivityFeed returns a unified activity feed combining updates/announcements and comments
-func GetGroupActivityFeed(db *gorm.DB) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		db := middleware.GetDB(c, db)
-		groupID := c.Param("id")
-		userID, _ := c.Get("user_id")
-		isAdmin, _ := c.Get("is_admin")
+// Feed item kinds as stored in the unified feed query. Higher kinds sort
+// first among items created at the same instant.
+const (
+	feedKindComment         = 0
+	feedKindAnnouncement    = 1
+	feedKindAnimalChange    = 2
+	feedKindNameChange      = 3 // Animal timeline only
+	feedKindPhoto           = 4 // Animal timeline only
+	feedKindDiscussion      = 5
+	feedKindDiscussionReply = 6
+)
 
-		// Check group access
-		if !checkGroupAccess(db, userID, isAdmin, groupID) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
-			return
+// feedCursor identifies the last item of a page; the next page starts
+// strictly after it in (created_at, kind, id) descending order.
+type feedCursor struct {
+	CreatedAt time.Time `json:"t"`
+	Kind      int       `json:"k"`
+	ID        uint      `json:"id"`
+}
+
+func (fc feedCursor) encode() string {
+	data, _ := json.Marshal(fc)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeFeedCursor(s string) (*feedCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	var fc feedCursor
+	if err := json.Unmarshal(data, &fc); err != nil || fc.CreatedAt.IsZero() {
+		return nil, errors.New("invalid cursor")
+	}
+	return &fc, nil
+}
+
+// feedRef is one row of the unified feed query, before hydration.
+type feedRef struct {
+	ID        uint
+	CreatedAt time.Time
+	Kind      int
+}
+
+// commentMetadataField returns a SQL expression for key in the
+// animal_comments.metadata JSON column, as text. key must be a constant.
+func commentMetadataField(db *gorm.DB, key string) string {
+	return database.DialectOf(db).JSONText("ac.metadata", key)
+}
+
+// feedBranch is one source of feed items: a FROM/WHERE clause whose rows
+// expose an id and created_at column, all of the same kind.
+type feedBranch struct {
+	kind      int
+	idCol     string
+	createdAt string
+	from      string
+	args      []interface{}
+}
+
+func (b feedBranch) selectSQL() string {
+	return "SELECT " + b.idCol + " AS id, " + b.createdAt + " AS created_at, " + strconv.Itoa(b.kind) + " AS kind " + b.from
+}
+
+// after returns the branch's part of the keyset condition for cursor. The
+// kind is constant within a branch, so the condition reduces to one on
+// (created_at, id) that the branch's index can serve.
+func (b feedBranch) after(cursor feedCursor) (string, []interface{}) {
+	switch {
+	case b.kind < cursor.Kind:
+		return b.createdAt + " <= ?", []interface{}{cursor.CreatedAt}
+	case b.kind > cursor.Kind:
+		return b.createdAt + " < ?", []interface{}{cursor.CreatedAt}
+	default:
+		return "(" + b.createdAt + " < ? OR (" + b.createdAt + " = ? AND " + b.idCol + " < ?))",
+			[]interface{}{cursor.CreatedAt, cursor.CreatedAt, cursor.ID}
+	}
+}
+
+// activityFeedQuery builds the SQL for a group's activity feed from its
+// announcement and comment branches, so pages can be read with one keyset
+// query over a UNION ALL instead of loading every row into memory.
+type activityFeedQuery struct {
+	branches []feedBranch
+
+	// The comment branch, reused by the summary query
+	comments *feedBranch
+}
+
+// newActivityFeedQuery builds a group's feed query, filtered by the feed's
+// query parameters in params. Comments on and changes to animals the viewer
+// can't see (see visibleAnimals) are left out.
+func newActivityFeedQuery(c *gin.Context, db *gorm.DB, groupID string, params url.Values) activityFeedQuery {
+	var q activityFeedQuery
+	viewerID, restricted := restrictedTagViewer(c, db, groupID)
+	hideRestricted := func(from string, args []interface{}) (string, []interface{}) {
+		if restricted {
+			return from + " AND NOT " + restrictedAnimalSQL("a.id"), append(args, true, viewerID)
 		}
+		return from, args
+	}
+	filterType := params.Get("type")     // all, comments, announcements, changes, discussions
+	filterAnimal := params.Get("animal") // animal ID
+	filterTags := params.Get("tags")     // comma-separated tag names
+	filterRating := params.Get("rating") // 1-5 or "poor" (1-2)
+
+	// Invalid dates are ignored, as they always have been
+	var dateFrom, dateTo *time.Time
+	if t, err := time.Parse(time.RFC3339, params.Get("from")); err == nil {
+		dateFrom = &t
+	}
+	if t, err := time.Parse(time.RFC3339, params.Get("to")); err == nil {
+		dateTo = &t
+	}
+	dateFilter := func(column string, args *[]interface{}) string {
+		sql := ""
+		if dateFrom != nil {
+			sql += " AND " + column + " >= ?"
+			*args = append(*args, *dateFrom)
+		}
+		if dateTo != nil {
+			sql += " AND " + column + " <= ?"
+			*args = append(*args, *dateTo)
+		}
+		return sql
+	}
 
-		// Get pagination parameters
-		limit := 20
-		if limitParam := c.Query("limit"); limitParam != "" {
-			if parsedLimit, err := strconv.Atoi(limitParam); err == nil && parsedLimit > 0 {
-				limit = parsedLimit
-				if limit > 100 {
-					limit = 100
-				}
+	if filterType == "" || filterType == "all" || filterType == "announcements" {
+		args := []interface{}{groupID}
+		from := "FROM updates u WHERE u.group_id = ? AND u.deleted_at IS NULL" + dateFilter("u.created_at", &args)
+		q.branches = append(q.branches, feedBranch{
+			kind: feedKindAnnouncement, idCol: "u.id", createdAt: "u.created_at", from: from, args: args,
+		})
+	}
+
+	if filterType == "" || filterType == "all" || filterType == "comments" {
+		args := []interface{}{groupID}
+		from := "FROM animal_comments ac JOIN animals a ON a.id = ac.animal_id AND a.deleted_at IS NULL " +
+			"WHERE a.group_id = ? AND ac.deleted_at IS NULL"
+		from, args = hideRestricted(from, args)
+		if filterAnimal != "" {
+			from += " AND ac.animal_id = ?"
+			args = append(args, filterAnimal)
+		}
+		from += dateFilter("ac.created_at", &args)
+		if tags := splitAndTrim(filterTags); len(tags) > 0 {
+			from += " AND EXISTS (SELECT 1 FROM animal_comment_tags act JOIN comment_tags ct ON ct.id = act.comment_tag_id " +
+				"WHERE act.animal_comment_id = ac.id AND ct.name IN ?)"
+			args = append(args, tags)
+		}
+		if filterRating != "" {
+			rating := "CAST(" + commentMetadataField(db, "session_rating") + " AS INTEGER)"
+			from += " AND " + rating + " > 0"
+			if filterRating == "poor" {
+				from += " AND " + rating + " <= 2"
+			} else if ratingVal, err := strconv.Atoi(filterRating); err == nil {
+				from += " AND " + rating + " = ?"
+				args = append(args, ratingVal)
 			}
 		}
+		q.branches = append(q.branches, feedBranch{
+			kind: feedKindComment, idCol: "ac.id", createdAt: "ac.created_at", from: from, args: args,
+		})
+		q.comments = &q.branches[len(q.branches)-1]
+	}
 
-		offset := 0
-		if offsetParam := c.Query("offset"); offsetParam != "" {
-			if parsedOffset, err := strconv.Atoi(offsetParam); err == nil && parsedOffset >= 0 {
-				offset = parsedOffset
-			}
+	// Tag and rating filters only make sense for comments
+	if (filterType == "" || filterType == "all" || filterType == "changes") && filterTags == "" && filterRating == "" {
+		args := []interface{}{groupID}
+		from := "FROM animal_changes ch JOIN animals a ON a.id = ch.animal_id AND a.deleted_at IS NULL " +
+			"WHERE ch.group_id = ?"
+		from, args = hideRestricted(from, args)
+		if filterAnimal != "" {
+			from += " AND ch.animal_id = ?"
+			args = append(args, filterAnimal)
 		}
+		from += dateFilter("ch.created_at", &args)
+		q.branches = append(q.branches, feedBranch{
+			kind: feedKindAnimalChange, idCol: "ch.id", createdAt: "ch.created_at", from: from, args: args,
+		})
+	}
 
-		// Get filter parameters
-		filterType := c.Query("type")     // all, comments, announcements
-		filterAnimal := c.Query("animal") // animal ID
-		filterTags := c.Query("tags")     // comma-separated tag names
-		filterRating := c.Query("rating") // 1-5 or "poor" (1-2)
-		filterDateFrom := c.Query("from") // ISO date
-		filterDateTo := c.Query("to")     // ISO date
+	// Discussions aren't about an animal, so any comment filter excludes them
+	if (filterType == "" || filterType == "all" || filterType == "discussions") && filterAnimal == "" && filterTags == "" && filterRating == "" {
+		args := []interface{}{groupID}
+		from := "FROM discussions d WHERE d.group_id = ? AND d.deleted_at IS NULL" + dateFilter("d.created_at", &args)
+		q.branches = append(q.branches, feedBranch{
+			kind: feedKindDiscussion, idCol: "d.id", createdAt: "d.created_at", from: from, args: args,
+		})
 
-		// Initialize with empty slice to ensure we never return nil
-		activityItems := make([]ActivityItem, 0)
+		args = []interface{}{groupID}
+		from = "FROM discussion_replies dr JOIN discussions d ON d.id = dr.discussion_id AND d.deleted_at IS NULL " +
+			"WHERE d.group_id = ? AND dr.deleted_at IS NULL" + dateFilter("dr.created_at", &args)
+		q.branches = append(q.branches, feedBranch{
+			kind: feedKindDiscussionReply, idCol: "dr.id", createdAt: "dr.created_at", from: from, args: args,
+		})
+	}
+	return q
+}
 
-		// Parse date filters
-		var dateFrom, dateTo *time.Time
-		if filterDateFrom != "" {
-			if t, err := time.Parse(time.RFC3339, filterDateFrom); err == nil {
-				dateFrom = &t
-			}
+// page returns up to limit+1 feed refs after cursor (or skipping offset when
+// there is no cursor), newest first. Each branch is limited on its own
+// before the merge, so neither has to be read past the page.
+func (q activityFeedQuery) page(db *gorm.DB, cursor *feedCursor, offset, limit int) ([]feedRef, error) {
+	refs := []feedRef{}
+	if len(q.branches) == 0 {
+		return refs, nil
+	}
+	if cursor != nil {
+		offset = 0
+	}
+	parts := make([]string, 0, len(q.branches))
+	var args []interface{}
+	for _, b := range q.branches {
+		sql := b.selectSQL()
+		args = append(args, b.args...)
+		if cursor != nil {
+			cond, condArgs := b.after(*cursor)
+			sql += " AND " + cond
+			args = append(args, condArgs...)
 		}
-		if filterDateTo != "" {
-			if t, err := time.Parse(time.RFC3339, filterDateTo); err == nil {
-				dateTo = &t
-			}
+		sql += " ORDER BY " + b.createdAt + " DESC, " + b.idCol + " DESC LIMIT ?"
+		args = append(args, offset+limit+1)
+		parts = append(parts, "SELECT * FROM ("+sql+") b"+strconv.Itoa(len(parts)))
+	}
+	sql := "SELECT id, created_at, kind FROM (" + strings.Join(parts, " UNION ALL ") + ") feed" +
+		" ORDER BY created_at DESC, kind DESC, id DESC LIMIT ? OFFSET ?"
+	args = append(args, limit+1, offset)
+	err := db.Raw(sql, args...).Scan(&refs).Error
+	return refs, err
+}
+
+func (q activityFeedQuery) total(db *gorm.DB) (int64, error) {
+	var total int64
+	for _, b := range q.branches {
+		var count int64
+		if err := db.Raw("SELECT COUNT(*) "+b.from, b.args...).Scan(&count).Error; err != nil {
+			return 0, err
 		}
+		total += count
+	}
+	return total, nil
+}
 
-		// Fetch announcements (Updates) if not filtering for comments only
-		if filterType == "" || filterType == "all" || filterType == "announcements" {
-			var updates []models.Update
-			query := db.Where("group_id = ?", groupID)
+func (q activityFeedQuery) summary(db *gorm.DB) (ActivityFeedSummary, error) {
+	var summary ActivityFeedSummary
+	if q.comments == nil {
+		return summary, nil
+	}
+	behavior := commentMetadataField(db, "behavior_notes")
+	medical := commentMetadataField(db, "medical_notes")
+	rating := "CAST(" + commentMetadataField(db, "session_rating") + " AS INTEGER)"
+	err := db.Raw("SELECT "+
+		"COUNT(CASE WHEN "+behavior+" <> '' THEN 1 END) AS behavior_concerns_count, "+
+		"COUNT(CASE WHEN "+medical+" <> '' THEN 1 END) AS medical_concerns_count, "+
+		"COUNT(CASE WHEN "+rating+" BETWEEN 1 AND 2 THEN 1 END) AS poor_sessions_count "+
+		q.comments.from, q.comments.args...).Scan(&summary).Error
+	return summary, err
+}
 
-			if dateFrom != nil {
-				query = query.Where("created_at >= ?", dateFrom)
-			}
-			if dateTo != nil {
-				query = query.Where("created_at <= ?", dateTo)
-			}
+// hydrateFeed loads the announcements, comments, animal changes, renames,
+// photos, discussions, and discussion replies behind refs and returns them as activity items in ref order. Comment
+// reactions are marked as the viewer's own where they are.
+func hydrateFeed(db *gorm.DB, refs []feedRef, viewerID uint) ([]ActivityItem, error) {
+	var updateIDs, commentIDs, changeIDs, renameIDs, photoIDs, discussionIDs, replyIDs []uint
+	for _, ref := range refs {
+		switch ref.Kind {
+		case feedKindAnnouncement:
+			updateIDs = append(updateIDs, ref.ID)
+		case feedKindAnimalChange:
+			changeIDs = append(changeIDs, ref.ID)
+		case feedKindNameChange:
+			renameIDs = append(renameIDs, ref.ID)
+		case feedKindPhoto:
+			photoIDs = append(photoIDs, ref.ID)
+		case feedKindDiscussion:
+			discussionIDs = append(discussionIDs, ref.ID)
+		case feedKindDiscussionReply:
+			replyIDs = append(replyIDs, ref.ID)
+		default:
+			commentIDs = append(commentIDs, ref.ID)
+		}
+	}
 
-			err := query.Preload("User").
-				Order("created_at DESC").
-				Find(&updates).Error
+	updates := make(map[uint]models.Update, len(updateIDs))
+	if len(updateIDs) > 0 {
+		var rows []models.Update
+		if err := db.Preload("User").Where("id IN ?", updateIDs).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, u := range rows {
+			updates[u.ID] = u
+		}
+	}
 
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch announcements"})
-				return
-			}
+	var animalIDs []uint
+	changes := make(map[uint]models.AnimalChange, len(changeIDs))
+	if len(changeIDs) > 0 {
+		var rows []models.AnimalChange
+		if err := db.Preload("User").Where("id IN ?", changeIDs).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, ch := range rows {
+			changes[ch.ID] = ch
+			animalIDs = append(animalIDs, ch.AnimalID)
+		}
+	}
 
-			for _, update := range updates {
-				activityItems = append(activityItems, ActivityItem{
-					ID:        update.ID,
-					Type:      "announcement",
-					CreatedAt: update.CreatedAt,
-					UserID:    update.UserID,
-					User:      &update.User,
-					Content:   update.Content,
-					Title:     update.Title,
-					ImageURL:  update.ImageURL,
-				})
-			}
+	comments := make(map[uint]models.AnimalComment, len(commentIDs))
+	if len(commentIDs) > 0 {
+		var rows []models.AnimalComment
+		if err := db.Preload("User").Preload("Tags").Where("id IN ?", commentIDs).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		if err := attachReactionCounts(db, rows, viewerID); err != nil {
+			return nil, err
 		}
+		for _, cm := range rows {
+			comments[cm.ID] = cm
+			animalIDs = append(animalIDs, cm.AnimalID)
+		}
+	}
 
-		// Fetch comments if not filtering for announcements only
-		if filterType == "" || filterType == "all" || filterType == "comments" {
-			// First get all animals in this group
-			var animals []models.Animal
-			animalQuery := db.Where("group_id = ?", groupID)
+	renames := make(map[uint]models.AnimalNameHistory, len(renameIDs))
+	renamers := make(map[uint]models.User)
+	if len(renameIDs) > 0 {
+		var rows []models.AnimalNameHistory
+		if err := db.Where("id IN ?", renameIDs).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		userIDs := make([]uint, 0, len(rows))
+		for _, nh := range rows {
+			renames[nh.ID] = nh
+			animalIDs = append(animalIDs, nh.AnimalID)
+			userIDs = append(userIDs, nh.ChangedBy)
+		}
+		var users []models.User
+		if err := db.Unscoped().Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			renamers[u.ID] = u
+		}
+	}
 
-			// Filter by specific animal if requested
-			if filterAnimal != "" {
-				animalQuery = animalQuery.Where("id = ?", filterAnimal)
+	photos := make(map[uint]models.AnimalImage, len(photoIDs))
+	if len(photoIDs) > 0 {
+		var rows []models.AnimalImage
+		if err := db.Preload("User").
+			Select("id, created_at, animal_id, user_id, image_url, caption").
+			Where("id IN ?", photoIDs).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, img := range rows {
+			photos[img.ID] = img
+			if img.AnimalID != nil {
+				animalIDs = append(animalIDs, *img.AnimalID)
 			}
+		}
+	}
+
+	replies := make(map[uint]models.DiscussionReply, len(replyIDs))
+	if len(replyIDs) > 0 {
+		var rows []models.DiscussionReply
+		if err := db.Preload("User").Where("id IN ?", replyIDs).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			replies[r.ID] = r
+			discussionIDs = append(discussionIDs, r.DiscussionID)
+		}
+	}
+
+	// Replies show the title of the discussion they belong to
+	discussions := make(map[uint]models.Discussion, len(discussionIDs))
+	if len(discussionIDs) > 0 {
+		var rows []models.Discussion
+		if err := db.Preload("User").Where("id IN ?", discussionIDs).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, d := range rows {
+			discussions[d.ID] = d
+		}
+	}
+
+	animals := make(map[uint]models.Animal)
+	if len(animalIDs) > 0 {
+		var animalRows []models.Animal
+		if err := db.Where("id IN ?", animalIDs).Find(&animalRows).Error; err != nil {
+			return nil, err
+		}
+		for _, a := range animalRows {
+			animals[a.ID] = a
+		}
+	}
 
-			if err := animalQuery.Find(&animals).Error; err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch animals"})
-				return
+	items := make([]ActivityItem, 0, len(refs))
+	for _, ref := range refs {
+		if ref.Kind == feedKindAnnouncement {
+			update, ok := updates[ref.ID]
+			if !ok {
+				continue // Deleted between the page query and hydration
+			}
+			items = append(items, ActivityItem{
+				ID:        update.ID,
+				Type:      "announcement",
+				CreatedAt: update.CreatedAt,
+				UserID:    update.UserID,
+				User:      &update.User,
+				Content:   update.Content,
+				Title:     update.Title,
+				ImageURL:  update.ImageURL,
+			})
+			continue
+		}
+		if ref.Kind == feedKindAnimalChange {
+			change, ok := changes[ref.ID]
+			if !ok {
+				continue
 			}
+			animal := animals[change.AnimalID]
+			items = append(items, ActivityItem{
+				ID:        change.ID,
+				Type:      "animal_change",
+				CreatedAt: change.CreatedAt,
+				UserID:    change.UserID,
+				User:      &change.User,
+				AnimalID:  &change.AnimalID,
+				Animal:    &animal,
+				Changes:   change.Changes,
+			})
+			continue
+		}
+		if ref.Kind == feedKindNameChange {
+			rename, ok := renames[ref.ID]
+			if !ok {
+				continue
+			}
+			animal := animals[rename.AnimalID]
+			renamer := renamers[rename.ChangedBy]
+			items = append(items, ActivityItem{
+				ID:        rename.ID,
+				Type:      "name_change",
+				CreatedAt: rename.CreatedAt,
+				UserID:    rename.ChangedBy,
+				User:      &renamer,
+				AnimalID:  &rename.AnimalID,
+				Animal:    &animal,
+				Changes:   []models.AnimalFieldChange{{Field: "name", Old: rename.OldName, New: rename.NewName}},
+			})
+			continue
+		}
+		if ref.Kind == feedKindPhoto {
+			photo, ok := photos[ref.ID]
+			if !ok || photo.AnimalID == nil {
+				continue
+			}
+			animal := animals[*photo.AnimalID]
+			items = append(items, ActivityItem{
+				ID:        photo.ID,
+				Type:      "photo",
+				CreatedAt: photo.CreatedAt,
+				UserID:    photo.UserID,
+				User:      &photo.User,
+				Content:   photo.Caption,
+				ImageURL:  photo.ImageURL,
+				AnimalID:  photo.AnimalID,
+				Animal:    &animal,
+			})
+			continue
+		}
+		if ref.Kind == feedKindDiscussion {
+			discussion, ok := discussions[ref.ID]
+			if !ok {
+				continue
+			}
+			items = append(items, ActivityItem{
+				ID:           discussion.ID,
+				Type:         "discussion",
+				CreatedAt:    discussion.CreatedAt,
+				UserID:       discussion.UserID,
+				User:         &discussion.User,
+				Content:      discussion.Content,
+				Title:        discussion.Title,
+				DiscussionID: &discussion.ID,
+			})
+			continue
+		}
+		if ref.Kind == feedKindDiscussionReply {
+			reply, ok := replies[ref.ID]
+			if !ok {
+				continue
+			}
+			discussion, ok := discussions[reply.DiscussionID]
+			if !ok {
+				continue
+			}
+			items = append(items, ActivityItem{
+				ID:           reply.ID,
+				Type:         "discussion_reply",
+				CreatedAt:    reply.CreatedAt,
+				UserID:       reply.UserID,
+				User:         &reply.User,
+				Content:      reply.Content,
+				Title:        discussion.Title,
+				DiscussionID: &reply.DiscussionID,
+			})
+			continue
+		}
+		comment, ok := comments[ref.ID]
+		if !ok {
+			continue
+		}
+		animal := animals[comment.AnimalID]
+		items = append(items, ActivityItem{
+			ID:        comment.ID,
+			Type:      "comment",
+			CreatedAt: comment.CreatedAt,
+			UserID:    comment.UserID,
+			User:      &comment.User,
+			Content:   comment.Content,
+			ImageURL:  comment.ImageURL,
+			AnimalID:  &comment.AnimalID,
+			Animal:    &animal,
+			Tags:      comment.Tags,
+			Reactions: comment.Reactions,
+			Metadata:  comment.Metadata,
+		})
+	}
+	return items, nil
+}
 
-			// Get animal IDs
-			var animalIDs []uint
-			animalMap := make(map[uint]models.Animal)
-			for _, animal := range animals {
-				animalIDs = append(animalIDs, animal.ID)
-				animalMap[animal.ID] = animal
+// hideFeedChanges drops the field changes hidden from the viewer, and the
+// animal changes left with none
+func hideFeedChanges(items []ActivityItem, policy fieldPolicy) []ActivityItem {
+	visible := items[:0]
+	for _, item := range items {
+		if item.Type == "animal_change" {
+			item.Changes = policy.Changes(item.Changes)
+			if len(item.Changes) == 0 {
+				continue
 			}
+		}
+		visible = append(visible, item)
+	}
+	return visible
+}
 
-			if len(animalIDs) > 0 {
-				// Get comments from these animals
-				var comments []models.AnimalComment
-				commentQuery := db.Where("animal_id IN ?", animalIDs)
-
-				// Apply date filters
-				if dateFrom != nil {
-					commentQuery = commentQuery.Where("created_at >= ?", dateFrom)
-				}
-				if dateTo != nil {
-					commentQuery = commentQuery.Where("created_at <= ?", dateTo)
-				}
-
-				// Apply tag filter if specified
-				if filterTags != "" {
-					commentQuery = applyTagFilter(commentQuery, splitAndTrim(filterTags))
-				}
-
-				err := commentQuery.Preload("User").
-					Preload("Tags").
-					Order("created_at DESC").
-					Find(&comments).Error
-
-				if err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
-					return
-				}
-
-				for _, comment := range comments {
-					// Apply rating filter if specified
-					if filterRating != "" {
-						if comment.Metadata == nil || comment.Metadata.SessionRating == 0 {
-							continue // Skip if no rating
-						}
-
-						if filterRating == "poor" {
-							if comment.Metadata.SessionRating > 2 {
-								continue // Skip if not poor rating (1-2)
-							}
-						} else {
-							if ratingVal, err := strconv.Atoi(filterRating); err == nil {
-								if comment.Metadata.SessionRating != ratingVal {
-									continue // Skip if rating doesn't match
-								}
-							}
-						}
-					}
-
-					animal := animalMap[comment.AnimalID]
-					activityItems = append(activityItems, ActivityItem{
-						ID:        comment.ID,
-						Type:      "comment",
-						CreatedAt: comment.CreatedAt,
-						UserID:    comment.UserID,
-						User:      &comment.User,
-						Content:   comment.Content,
-						ImageURL:  comment.ImageURL,
-						AnimalID:  &comment.AnimalID,
-						Animal:    &animal,
-						Tags:      comment.Tags,
-						Metadata:  comment.Metadata,
-					})
-				}
+// feedPageParams reads ?limit= (default 20, at most 100), ?offset=, and
+// ?cursor= for a feed. It responds 400 for an invalid cursor.
+func feedPageParams(c *gin.Context) (limit, offset int, cursor *feedCursor, ok bool) {
+	limit = 20
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if parsedLimit, err := strconv.Atoi(limitParam); err == nil && parsedLimit > 0 {
+			limit = parsedLimit
+			if limit > 100 {
+				limit = 100
 			}
 		}
+	}
 
-		// Sort all items by creation time (newest first) - O(n log n)
-		sort.Slice(activityItems, func(i, j int) bool {
-			return activityItems[i].CreatedAt.After(activityItems[j].CreatedAt)
-		})
+	if offsetParam := c.Query("offset"); offsetParam != "" {
+		if parsedOffset, err := strconv.Atoi(offsetParam); err == nil && parsedOffset >= 0 {
+			offset = parsedOffset
+		}
+	}
 
-		// Calculate summary statistics
-		summary := ActivityFeedSummary{}
-		for _, item := range activityItems {
-			if item.Type == "comment" && item.Metadata != nil {
-				if item.Metadata.BehaviorNotes != "" {
-					summary.BehaviorConcernsCount++
-				}
-				if item.Metadata.MedicalNotes != "" {
-					summary.MedicalConcernsCount++
-				}
-				if item.Metadata.SessionRating > 0 && item.Metadata.SessionRating <= 2 {
-					summary.PoorSessionsCount++
-				}
-			}
+	if cursorParam := c.Query("cursor"); cursorParam != "" {
+		var err error
+		if cursor, err = decodeFeedCursor(cursorParam); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return 0, 0, nil, false
+		}
+	}
+	return limit, offset, cursor, true
+}
+
+// GetGroupActivityFeed returns a unified activity feed combining updates/announcements, comments,
+// animal changes, and discussions.
+// Pages with ?cursor=<next_cursor from the previous page>; ?offset is still
+// accepted for older clients but is slower on large groups.
+func GetGroupActivityFeed(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		// Check group access
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+			return
+		}
+
+		limit, offset, cursor, ok := feedPageParams(c)
+		if !ok {
+			return
 		}
 
-		// Apply pagination
-		total := len(activityItems)
-		start := offset
-		if start > total {
-			start = total
+		query := newActivityFeedQuery(c, db, groupID, c.Request.URL.Query())
+
+		refs, err := query.page(db, cursor, offset, limit)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to fetch activity feed", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch activity feed"})
+			return
 		}
-		end := start + limit
-		if end > total {
-			end = total
+		hasMore := len(refs) > limit
+		if hasMore {
+			refs = refs[:limit]
 		}
 
-		paginatedItems := activityItems[start:end]
+		viewerID, _ := middleware.GetUserID(c)
+		items, err := hydrateFeed(db, refs, viewerID)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to load activity feed items", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch activity feed"})
+			return
+		}
+		gid, _ := strconv.ParseUint(groupID, 10, 32)
+		policy, err := loadFieldPolicy(c, db, uint(gid))
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to load field visibility", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch activity feed"})
+			return
+		}
+		items = hideFeedChanges(items, policy)
+
+		total, err := query.total(db)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to count activity feed", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch activity feed"})
+			return
+		}
+
+		summary, err := query.summary(db)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to summarize activity feed", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch activity feed"})
+			return
+		}
 
-		// Ensure we return an empty array instead of nil
-		if paginatedItems == nil {
-			paginatedItems = []ActivityItem{}
+		var nextCursor *string
+		if hasMore {
+			last := refs[len(refs)-1]
+			encoded := feedCursor{CreatedAt: last.CreatedAt, Kind: last.Kind, ID: last.ID}.encode()
+			nextCursor = &encoded
 		}
 
 		// Return response with pagination metadata and summary
 		c.JSON(http.StatusOK, gin.H{
-			"items":   paginatedItems,
-			"total":   total,
-			"limit":   limit,
-			"offset":  offset,
-			"hasMore": end < total,
-			"summary": summary,
+			"items":       items,
+			"total":       total,
+			"limit":       limit,
+			"offset":      offset,
+			"hasMore":     hasMore,
+			"next_cursor": nextCursor,
+			"summary":     summary,
 		})
 	}
 }
@@ -1,13 +1,17 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -24,8 +28,15 @@ func setupActivityFeedTestDB(t *testing.T) *gorm.DB {
 		&models.Group{},
 		&models.Animal{},
 		&models.AnimalComment{},
+		&models.CommentReaction{},
 		&models.Update{},
 		&models.CommentTag{},
+		&models.AnimalChange{},
+		&models.Discussion{},
+		&models.DiscussionReply{},
+		&models.AnimalTag{},
+		&models.UserQualification{},
+		&models.GroupFieldVisibility{},
 	)
 	if err != nil {
 		t.Fatalf("Failed to migrate database: %v", err)
@@ -173,3 +184,177 @@ func TestGetGroupActivityFeed(t *testing.T) {
 		})
 	}
 }
+
+type activityFeedTestResponse struct {
+	Items      []ActivityItem      `json:"items"`
+	Total      int64               `json:"total"`
+	HasMore    bool                `json:"hasMore"`
+	NextCursor *string             `json:"next_cursor"`
+	Summary    ActivityFeedSummary `json:"summary"`
+}
+
+func getActivityFeed(t testing.TB, db *gorm.DB, query string) (int, activityFeedTestResponse) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/groups/1/activity-feed"+query, nil)
+	c.Set("user_id", uint(1))
+	c.Set("is_admin", false)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	GetGroupActivityFeed(db)(c)
+
+	var resp activityFeedTestResponse
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w.Code, resp
+}
+
+func TestGetGroupActivityFeed_CursorPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupActivityFeedTestDB(t)
+
+	// Several items share a timestamp, including a comment and an
+	// announcement, so the cursor has to break ties by kind and id
+	base := time.Now().Add(-time.Hour).UTC()
+	for i := 0; i < 25; i++ {
+		at := base.Add(time.Duration(i/3) * time.Minute)
+		require.NoError(t, db.Create(&models.AnimalComment{AnimalID: 1, UserID: 1, Content: fmt.Sprintf("comment %d", i), CreatedAt: at}).Error)
+		if i%5 == 0 {
+			require.NoError(t, db.Create(&models.Update{GroupID: 1, UserID: 1, Title: "Update", Content: fmt.Sprintf("update %d", i), CreatedAt: at}).Error)
+		}
+	}
+
+	seen := map[string]bool{}
+	var all []ActivityItem
+	cursor := ""
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 10, "pagination should terminate")
+		code, resp := getActivityFeed(t, db, "?limit=7"+cursor)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, int64(32), resp.Total)
+		for _, item := range resp.Items {
+			key := fmt.Sprintf("%s-%d", item.Type, item.ID)
+			assert.False(t, seen[key], "duplicate item %s", key)
+			seen[key] = true
+		}
+		all = append(all, resp.Items...)
+		if !resp.HasMore {
+			assert.Nil(t, resp.NextCursor)
+			break
+		}
+		require.NotNil(t, resp.NextCursor)
+		cursor = "&cursor=" + *resp.NextCursor
+	}
+
+	require.Len(t, all, 32, "every item is returned exactly once")
+	for i := 1; i < len(all); i++ {
+		assert.False(t, all[i].CreatedAt.After(all[i-1].CreatedAt), "items are newest first")
+	}
+
+	// Offset paging still works for older clients
+	code, resp := getActivityFeed(t, db, "?limit=7&offset=7")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Items, 7)
+	assert.Equal(t, all[7].ID, resp.Items[0].ID)
+	assert.Equal(t, all[7].Type, resp.Items[0].Type)
+}
+
+func TestGetGroupActivityFeed_Filters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupActivityFeedTestDB(t)
+
+	tag := models.CommentTag{Name: "behavior"}
+	require.NoError(t, db.Create(&tag).Error)
+	require.NoError(t, db.Create(&models.AnimalComment{
+		AnimalID: 1, UserID: 1, Content: "Pulled on leash",
+		Metadata: &models.SessionMetadata{SessionRating: 2, BehaviorNotes: "Pulls"},
+		Tags:     []models.CommentTag{tag},
+	}).Error)
+	require.NoError(t, db.Create(&models.AnimalComment{
+		AnimalID: 1, UserID: 1, Content: "Great session",
+		Metadata: &models.SessionMetadata{SessionRating: 5, MedicalNotes: "Limping"},
+	}).Error)
+
+	code, resp := getActivityFeed(t, db, "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, int64(4), resp.Total)
+	assert.Equal(t, ActivityFeedSummary{BehaviorConcernsCount: 1, MedicalConcernsCount: 1, PoorSessionsCount: 1}, resp.Summary)
+
+	code, resp = getActivityFeed(t, db, "?tags=behavior")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Items, 2, "the tag filter only applies to comments")
+	assert.Equal(t, "Pulled on leash", resp.Items[0].Content)
+	assert.Equal(t, "announcement", resp.Items[1].Type)
+
+	code, resp = getActivityFeed(t, db, "?type=comments&rating=poor")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Items, 1)
+	assert.Equal(t, "Pulled on leash", resp.Items[0].Content)
+
+	code, resp = getActivityFeed(t, db, "?type=comments&rating=5")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Items, 1)
+	assert.Equal(t, "Great session", resp.Items[0].Content)
+
+	code, resp = getActivityFeed(t, db, "?type=announcements")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Items, 1)
+	assert.Equal(t, "Test Update", resp.Items[0].Title)
+	assert.Equal(t, ActivityFeedSummary{}, resp.Summary)
+
+	code, _ = getActivityFeed(t, db, "?cursor=not-a-cursor")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+// BenchmarkGetGroupActivityFeed pages through the feed of a group with 50k
+// comments and 500 announcements.
+func BenchmarkGetGroupActivityFeed(b *testing.B) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(b, err)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1) // One connection keeps a single in-memory database
+	require.NoError(b, db.AutoMigrate(&models.User{}, &models.Group{}, &models.Animal{}, &models.AnimalComment{}, &models.CommentReaction{}, &models.Update{}, &models.CommentTag{}, &models.AnimalChange{}, &models.Discussion{}, &models.DiscussionReply{}, &models.AnimalTag{}, &models.UserQualification{}))
+
+	user := models.User{Username: "testuser", Email: "test@example.com", Password: "hashedpassword"}
+	require.NoError(b, db.Create(&user).Error)
+	group := models.Group{Name: "Test Group"}
+	require.NoError(b, db.Create(&group).Error)
+	require.NoError(b, db.Model(&user).Association("Groups").Append(&group))
+
+	animals := make([]models.Animal, 50)
+	for i := range animals {
+		animals[i] = models.Animal{Name: fmt.Sprintf("Animal %d", i), GroupID: group.ID, Status: "available"}
+	}
+	require.NoError(b, db.Create(&animals).Error)
+
+	base := time.Now().Add(-24 * time.Hour)
+	comments := make([]models.AnimalComment, 50000)
+	for i := range comments {
+		comments[i] = models.AnimalComment{
+			AnimalID:  animals[i%len(animals)].ID,
+			UserID:    user.ID,
+			Content:   "Walked well",
+			CreatedAt: base.Add(time.Duration(i) * time.Second),
+			Metadata:  &models.SessionMetadata{SessionRating: i%5 + 1},
+		}
+	}
+	require.NoError(b, db.CreateInBatches(&comments, 1000).Error)
+	updates := make([]models.Update, 500)
+	for i := range updates {
+		updates[i] = models.Update{GroupID: group.ID, UserID: user.ID, Title: "Update", Content: "News", CreatedAt: base.Add(time.Duration(i*100) * time.Second)}
+	}
+	require.NoError(b, db.CreateInBatches(&updates, 500).Error)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cursor := ""
+		for page := 0; page < 5; page++ {
+			code, resp := getActivityFeed(b, db, "?limit=50"+cursor)
+			if code != http.StatusOK || resp.NextCursor == nil {
+				b.Fatalf("unexpected page %d: status %d", page, code)
+			}
+			cursor = "&cursor=" + *resp.NextCursor
+		}
+	}
+}
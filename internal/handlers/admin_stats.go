@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/database"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"gorm.io/gorm"
+)
+
+// maxStatsRangeDays bounds the daily comment series.
+const maxStatsRangeDays = 366
+
+// VolunteerStats counts user accounts. Active means the user logged in or
+// commented during the range.
+type VolunteerStats struct {
+	Total  int64 `json:"total"`
+	Active int64 `json:"active"`
+	New    int64 `json:"new"`
+}
+
+// InvitationStats counts invited users who haven't set a password yet.
+type InvitationStats struct {
+	Pending int64 `json:"pending"`
+	Expired int64 `json:"expired"` // Pending, but the setup link has expired
+}
+
+// GroupAnimalStats is one group's current animal count by status.
+type GroupAnimalStats struct {
+	GroupID   uint             `json:"group_id"`
+	GroupName string           `json:"group_name"`
+	Total     int64            `json:"total"`
+	ByStatus  map[string]int64 `json:"by_status"`
+}
+
+// DailyCount is a count for one UTC day.
+type DailyCount struct {
+	Date  string `json:"date"` // YYYY-MM-DD
+	Count int64  `json:"count"`
+}
+
+// AdminStats is the response for GET /api/admin/stats and
+// GET /api/groups/:id/stats.
+type AdminStats struct {
+	From               time.Time          `json:"from"`
+	To                 time.Time          `json:"to"`
+	GroupID            *uint              `json:"group_id,omitempty"`
+	Volunteers         VolunteerStats     `json:"volunteers"`
+	AnimalsByGroup     []GroupAnimalStats `json:"animals_by_group"`
+	CommentsTotal      int64              `json:"comments_total"`
+	CommentVolume      []DailyCount       `json:"comment_volume"`
+	PendingInvitations InvitationStats    `json:"pending_invitations"`
+	LockedAccounts     int64              `json:"locked_accounts"`
+}
+
+// buildAdminStats computes the stats for [from, to), for one group's
+// members and animals when groupID is set, otherwise site-wide. Volunteer,
+// invitation, and lockout counts are current; the animal counts are a
+// snapshot.
+func buildAdminStats(db *gorm.DB, groupID *uint, from, to, now time.Time) (*AdminStats, error) {
+	stats := &AdminStats{From: from, To: to, GroupID: groupID}
+
+	// Users, invitations, and lockouts in one pass over users
+	userScope, commentScope := "", ""
+	userArgs := []interface{}{from, to, from, to, from, to, true, true, now, now}
+	if groupID != nil {
+		userScope = " AND EXISTS (SELECT 1 FROM user_groups ug WHERE ug.user_id = u.id AND ug.group_id = ?)"
+		commentScope = " AND ac.animal_id IN (SELECT id FROM animals WHERE group_id = ?)"
+		userArgs = []interface{}{from, to, from, to, from, to, *groupID, true, true, now, now, *groupID}
+	}
+	var users struct {
+		Total              int64
+		New                int64
+		Active             int64
+		PendingInvitations int64
+		ExpiredInvitations int64
+		LockedAccounts     int64
+	}
+	if err := db.Raw(`
+		SELECT
+			COUNT(*) AS total,
+			COUNT(CASE WHEN u.created_at >= ? AND u.created_at < ? THEN 1 END) AS new,
+			COUNT(CASE WHEN (u.last_login >= ? AND u.last_login < ?) OR EXISTS (
+				SELECT 1 FROM animal_comments ac
+				WHERE ac.user_id = u.id AND ac.deleted_at IS NULL AND ac.created_at >= ? AND ac.created_at < ?`+commentScope+`
+			) THEN 1 END) AS active,
+			COUNT(CASE WHEN u.requires_password_setup = ? THEN 1 END) AS pending_invitations,
+			COUNT(CASE WHEN u.requires_password_setup = ? AND u.setup_token_expiry < ? THEN 1 END) AS expired_invitations,
+			COUNT(CASE WHEN u.locked_until > ? THEN 1 END) AS locked_accounts
+		FROM users u
+		WHERE u.deleted_at IS NULL`+userScope, userArgs...).Scan(&users).Error; err != nil {
+		return nil, err
+	}
+	stats.Volunteers = VolunteerStats{Total: users.Total, Active: users.Active, New: users.New}
+	stats.PendingInvitations = InvitationStats{Pending: users.PendingInvitations, Expired: users.ExpiredInvitations}
+	stats.LockedAccounts = users.LockedAccounts
+
+	// Animals by status per group
+	animalQuery := db.Table("animals a").
+		Select("a.group_id, g.name AS group_name, a.status, COUNT(*) AS count").
+		Joins("JOIN groups g ON g.id = a.group_id AND g.deleted_at IS NULL").
+		Where("a.deleted_at IS NULL")
+	if groupID != nil {
+		animalQuery = animalQuery.Where("a.group_id = ?", *groupID)
+	}
+	var statusRows []struct {
+		GroupID   uint
+		GroupName string
+		Status    string
+		Count     int64
+	}
+	if err := animalQuery.Group("a.group_id, g.name, a.status").Order("g.name, a.status").Scan(&statusRows).Error; err != nil {
+		return nil, err
+	}
+	stats.AnimalsByGroup = []GroupAnimalStats{}
+	for _, row := range statusRows {
+		n := len(stats.AnimalsByGroup)
+		if n == 0 || stats.AnimalsByGroup[n-1].GroupID != row.GroupID {
+			stats.AnimalsByGroup = append(stats.AnimalsByGroup, GroupAnimalStats{
+				GroupID: row.GroupID, GroupName: row.GroupName, ByStatus: map[string]int64{},
+			})
+			n++
+		}
+		stats.AnimalsByGroup[n-1].ByStatus[row.Status] = row.Count
+		stats.AnimalsByGroup[n-1].Total += row.Count
+	}
+
+	// Comments per day, with days without comments filled in
+	day := database.DialectOf(db).Day("ac.created_at")
+	commentQuery := db.Table("animal_comments ac").
+		Select(day+" AS date, COUNT(*) AS count").
+		Where("ac.deleted_at IS NULL AND ac.created_at >= ? AND ac.created_at < ?", from, to)
+	if groupID != nil {
+		commentQuery = commentQuery.Joins("JOIN animals a ON a.id = ac.animal_id").Where("a.group_id = ?", *groupID)
+	}
+	var dailyRows []DailyCount
+	if err := commentQuery.Group(day).Scan(&dailyRows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(dailyRows))
+	for _, row := range dailyRows {
+		counts[row.Date] = row.Count
+	}
+	stats.CommentVolume = []DailyCount{}
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		stats.CommentVolume = append(stats.CommentVolume, DailyCount{Date: date, Count: counts[date]})
+		stats.CommentsTotal += counts[date]
+	}
+
+	return stats, nil
+}
+
+// respondAdminStats parses the date range and writes the stats for groupID
+// (or site-wide when nil).
+func respondAdminStats(c *gin.Context, db *gorm.DB, groupID *uint) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	from, to, ok := parseAnalyticsRange(c, now)
+	if !ok {
+		return
+	}
+	if to.Sub(from) > maxStatsRangeDays*24*time.Hour {
+		respondBadRequest(c, "The date range can't be longer than "+strconv.Itoa(maxStatsRangeDays)+" days")
+		return
+	}
+
+	stats, err := buildAdminStats(db.WithContext(ctx), groupID, from, to, now)
+	if err != nil {
+		middleware.GetLogger(c).Error("Failed to compute stats", err)
+		respondInternalError(c, "Failed to compute stats")
+		return
+	}
+	respondOK(c, stats)
+}
+
+// GetAdminStats returns a site-wide snapshot for admins: volunteer activity,
+// animals by status per group, daily comment volume, pending invitations,
+// and locked accounts. Query params: from, to (YYYY-MM-DD, default the last
+// 30 days), group_id.
+// Route: GET /api/admin/stats
+func GetAdminStats(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		var groupID *uint
+		if v := c.Query("group_id"); v != "" {
+			gid, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+				return
+			}
+			id := uint(gid)
+			groupID = &id
+		}
+		respondAdminStats(c, db, groupID)
+	}
+}
+
+// GetGroupStats returns the same snapshot as GetAdminStats for one group's
+// members and animals (group admin or site admin).
+// Route: GET /api/groups/:id/stats
+func GetGroupStats(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		gid, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+		if !IsGroupAdminOrSiteAdmin(c, db, uint(gid)) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Group admin access required")
+			return
+		}
+		groupID := uint(gid)
+		respondAdminStats(c, db, &groupID)
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAdminStats(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	active := CreateTestUser(t, db, "active", "active@example.com", "password123", false)
+	invited := CreateTestUser(t, db, "invited", "invited@example.com", "password123", false)
+	locked := CreateTestUser(t, db, "locked", "locked@example.com", "password123", false)
+	dogs := CreateTestGroup(t, db, "Dogs", "Dog group")
+	cats := CreateTestGroup(t, db, "Cats", "Cat group")
+	AddUserToGroupWithAdmin(t, db, active.ID, dogs.ID, true)
+	AddUserToGroupWithAdmin(t, db, invited.ID, cats.ID, false)
+
+	now := time.Now().UTC()
+	db.Model(invited).Updates(map[string]interface{}{"requires_password_setup": true, "setup_token_expiry": now.Add(-time.Hour)})
+	db.Model(locked).Update("locked_until", now.Add(time.Hour))
+
+	rex := CreateTestAnimal(t, db, dogs.ID, "Rex", "Dog")
+	CreateTestAnimal(t, db, dogs.ID, "Fido", "Dog")
+	db.Model(rex).Update("status", "foster")
+	CreateTestAnimal(t, db, cats.ID, "Tom", "Cat")
+
+	today := now.Truncate(24 * time.Hour)
+	for _, at := range []time.Time{today.Add(time.Hour), today.Add(2 * time.Hour), today.AddDate(0, 0, -2)} {
+		comment := models.AnimalComment{AnimalID: rex.ID, UserID: active.ID, Content: "Walked"}
+		require.NoError(t, db.Create(&comment).Error)
+		db.Model(&comment).Update("created_at", at)
+	}
+
+	from := today.AddDate(0, 0, -6).Format("2006-01-02")
+	to := today.Format("2006-01-02")
+	c, w := accountTestContext(admin.ID, true, http.MethodGet, "/api/admin/stats?from="+from+"&to="+to, nil)
+	GetAdminStats(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var stats AdminStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, int64(4), stats.Volunteers.Total)
+	assert.Equal(t, int64(1), stats.Volunteers.Active)
+	assert.Equal(t, int64(1), stats.PendingInvitations.Pending)
+	assert.Equal(t, int64(1), stats.PendingInvitations.Expired)
+	assert.Equal(t, int64(1), stats.LockedAccounts)
+
+	require.Len(t, stats.AnimalsByGroup, 2)
+	assert.Equal(t, "Cats", stats.AnimalsByGroup[0].GroupName)
+	assert.Equal(t, map[string]int64{"available": 1, "foster": 1}, stats.AnimalsByGroup[1].ByStatus)
+	assert.Equal(t, int64(2), stats.AnimalsByGroup[1].Total)
+
+	require.Len(t, stats.CommentVolume, 7, "one entry per day, including days without comments")
+	assert.Equal(t, int64(3), stats.CommentsTotal)
+	assert.Equal(t, to, stats.CommentVolume[6].Date)
+	assert.Equal(t, int64(2), stats.CommentVolume[6].Count)
+	assert.Equal(t, int64(1), stats.CommentVolume[4].Count)
+
+	c, w = accountTestContext(admin.ID, true, http.MethodGet, "/api/admin/stats?from=2020-01-01&to=2024-01-01", nil)
+	GetAdminStats(db)(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "ranges over a year are rejected")
+}
+
+func TestGetGroupStats(t *testing.T) {
+	db := SetupTestDB(t)
+	groupAdmin := CreateTestUser(t, db, "lead", "lead@example.com", "password123", false)
+	member := CreateTestUser(t, db, "member", "member@example.com", "password123", false)
+	outsider := CreateTestUser(t, db, "outsider", "outsider@example.com", "password123", false)
+	dogs := CreateTestGroup(t, db, "Dogs", "Dog group")
+	cats := CreateTestGroup(t, db, "Cats", "Cat group")
+	AddUserToGroupWithAdmin(t, db, groupAdmin.ID, dogs.ID, true)
+	AddUserToGroupWithAdmin(t, db, member.ID, dogs.ID, false)
+	AddUserToGroupWithAdmin(t, db, outsider.ID, cats.ID, true)
+	CreateTestAnimal(t, db, dogs.ID, "Rex", "Dog")
+	tom := CreateTestAnimal(t, db, cats.ID, "Tom", "Cat")
+	require.NoError(t, db.Create(&models.AnimalComment{AnimalID: tom.ID, UserID: member.ID, Content: "Visited the cats"}).Error)
+
+	request := func(userID uint) (*AdminStats, int) {
+		c, w := accountTestContext(userID, false, http.MethodGet, "/", nil)
+		c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(dogs.ID)}}
+		GetGroupStats(db)(c)
+		var stats AdminStats
+		_ = json.Unmarshal(w.Body.Bytes(), &stats)
+		return &stats, w.Code
+	}
+
+	_, code := request(member.ID)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	stats, code := request(groupAdmin.ID)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, int64(2), stats.Volunteers.Total, "only group members are counted")
+	assert.Zero(t, stats.Volunteers.Active, "comments on other groups' animals don't count")
+	assert.Zero(t, stats.CommentsTotal)
+	require.Len(t, stats.AnimalsByGroup, 1)
+	assert.Equal(t, dogs.ID, stats.AnimalsByGroup[0].GroupID)
+}
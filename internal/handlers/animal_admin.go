@@ -3,10 +3,12 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/database"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
@@ -44,12 +46,44 @@ func UpdateAnimalAdmin(db *gorm.DB, emailService *email.Service, embedder embedd
 			c.JSON(http.StatusNotFound, gin.H{"error": "Animal not found"})
 			return
 		}
+		if animal.LegalHold && ((req.Status != "" && req.Status != animal.Status) || (req.GroupID != 0 && req.GroupID != animal.GroupID)) {
+			respondLegalHold(c, animal)
+			return
+		}
+
+		// Statuses, dates, and custom fields follow the group the animal will end up in
+		targetGroupID := animal.GroupID
+		if req.GroupID != 0 {
+			targetGroupID = req.GroupID
+		}
+		var statusDef models.AnimalStatus
+		if req.Status != "" && req.Status != animal.Status {
+			var ok bool
+			if statusDef, ok = validateAnimalStatusForGroup(c, dbCtx, targetGroupID, req.Status); !ok {
+				return
+			}
+		}
+		req.localizeDates(groupLocationByID(c.Request.Context(), dbCtx, targetGroupID))
+
+		// Names are checked in the group the animal will end up in
+		targetName, targetStatus := animal.Name, animal.Status
+		if req.Name != "" {
+			targetName = req.Name
+		}
+		if req.Status != "" {
+			targetStatus = req.Status
+		}
+		if animalNameClaimChanged(animal, targetName, targetGroupID, targetStatus) &&
+			!checkAnimalName(c, dbCtx, targetGroupID, targetName, targetStatus, animal.ID) {
+			return
+		}
 
 		// Captured before any field mutations below so it can be compared
 		// against the post-update text to decide whether re-embedding is
 		// actually necessary — mirrors the same pattern in
 		// animal_crud.go's UpdateAnimal.
 		oldEmbeddingText := animalEmbeddingText(animal)
+		before := animal
 
 		// Build update map with only provided fields
 		updates := make(map[string]interface{})
@@ -60,15 +94,31 @@ func UpdateAnimalAdmin(db *gorm.DB, emailService *email.Service, embedder embedd
 			updates["species"] = req.Species
 		}
 		if req.Breed != "" {
-			updates["breed"] = req.Breed
+			species := animal.Species
+			if req.Species != "" {
+				species = req.Species
+			}
+			breed, ok := normalizeAnimalBreed(c, dbCtx, species, req.Breed)
+			if !ok {
+				return
+			}
+			updates["breed"] = breed
 		}
-		if req.Age >= 0 && req.Age != animal.Age {
-			updates["age"] = req.Age
+		// Unlike UpdateAnimal, an age that differs from the stored one is an
+		// edit here and re-estimates the birth date
+		currentBirthDate := animal.EstimatedBirthDate
+		if req.Age > 0 && req.Age != animal.Age {
+			currentBirthDate = nil
+		}
+		birthDate, err := resolveBirthDate(req, currentBirthDate, time.Now())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		}
-		if req.EstimatedBirthDate.Valid && req.EstimatedBirthDate.Time != nil {
-			updates["estimated_birth_date"] = *req.EstimatedBirthDate.Time
+		if birthDate != animal.EstimatedBirthDate {
+			updates["estimated_birth_date"] = *birthDate
 			// Auto-compute Age from birth date to keep fields in sync
-			tempAnimal := models.Animal{EstimatedBirthDate: req.EstimatedBirthDate.Time}
+			tempAnimal := models.Animal{EstimatedBirthDate: birthDate}
 			updates["age"] = tempAnimal.AgeYearsFromBirthDate()
 		}
 		// Always include trainer_notes so it can be cleared by setting an empty value
@@ -153,6 +203,10 @@ func UpdateAnimalAdmin(db *gorm.DB, emailService *email.Service, embedder embedd
 				updates["quarantine_approval_date"] = nil
 				updates["archived_date"] = nil
 				updates["quarantine_incident_details"] = ""
+			default:
+				for k, v := range customStatusUpdates(statusDef, now) {
+					updates[k] = v
+				}
 			}
 		} else if animal.Status == "bite_quarantine" {
 			// Update approval status only when explicitly provided (nil = not sent = no change)
@@ -185,6 +239,59 @@ func UpdateAnimalAdmin(db *gorm.DB, emailService *email.Service, embedder embedd
 		if req.GroupID != 0 {
 			updates["group_id"] = req.GroupID
 		}
+		if req.CustomFields != nil {
+			customFields, ok := resolveCustomFields(c, dbCtx, targetGroupID, animal.CustomFields, req.CustomFields, false)
+			if !ok {
+				return
+			}
+			updates["custom_fields"] = customFields
+		}
+		if req.IntakeSource != nil {
+			if !validIntakeSource(*req.IntakeSource) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "intake_source must be one of: " + strings.Join(models.IntakeSources, ", ")})
+				return
+			}
+			updates["intake_source"] = *req.IntakeSource
+		}
+		if err := validateAnimalTraits(req.Size, req.EnergyLevel); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if req.Size != nil {
+			updates["size"] = *req.Size
+		}
+		if req.EnergyLevel != nil {
+			updates["energy_level"] = *req.EnergyLevel
+		}
+		outcome, outcomeDate, err := resolveOutcome(animal, targetStatus, req.Outcome, now)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !checkBondedAdoption(c, dbCtx, animal, outcome, nil) {
+			return
+		}
+		if outcome != animal.Outcome || outcomeDate != animal.OutcomeDate {
+			updates["outcome"] = outcome
+			updates["outcome_date"] = outcomeDate
+		}
+		// Moving groups checks the numbers against the new group
+		if req.MicrochipNumber != nil || req.LicenseNumber != nil || (req.GroupID != 0 && req.GroupID != animal.GroupID) {
+			target := animal
+			if req.GroupID != 0 {
+				target.GroupID = req.GroupID
+			}
+			microchip, license, ok := resolveRegistryNumbers(c, dbCtx, target, req.MicrochipNumber, req.LicenseNumber)
+			if !ok {
+				return
+			}
+			if microchip != animal.MicrochipNumber {
+				updates["microchip_number"] = microchip
+			}
+			if license != animal.LicenseNumber {
+				updates["license_number"] = license
+			}
+		}
 
 		if len(updates) == 0 {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "No updates provided"})
@@ -202,6 +309,7 @@ func UpdateAnimalAdmin(db *gorm.DB, emailService *email.Service, embedder embedd
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload animal"})
 			return
 		}
+		recordAnimalChange(c, dbCtx, before, animal, models.AnimalChangeAdminEdit)
 
 		// db is the unscoped *gorm.DB (see the comment on dbCtx above) so the
 		// detached embed goroutine isn't tied to this request's context.
@@ -267,7 +375,10 @@ type BulkUpdateAnimalsRequest struct {
 	Status    *string `json:"status,omitempty"`
 }
 
-// BulkUpdateAnimals updates multiple animals at once (admin or group admin)
+// BulkUpdateAnimals updates multiple animals at once (admin or group admin).
+// With ?dry_run=true the request is checked as usual but nothing is saved;
+// the response counts the animals it would update and shows what would
+// change on up to 20 of them.
 func BulkUpdateAnimals(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		db := middleware.GetDB(c, db)
@@ -330,6 +441,20 @@ func BulkUpdateAnimals(db *gorm.DB) gin.HandlerFunc {
 			updates["group_id"] = *req.GroupID
 		}
 		if req.Status != nil {
+			// Validate against every group the animals will end up in.
+			var targetGroupIDs []uint
+			if req.GroupID != nil {
+				targetGroupIDs = []uint{*req.GroupID}
+			} else if err := db.Model(&models.Animal{}).Where("id IN ?", req.AnimalIDs).
+				Distinct("group_id").Pluck("group_id", &targetGroupIDs).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load animals"})
+				return
+			}
+			for _, gid := range targetGroupIDs {
+				if _, ok := validateAnimalStatusForGroup(c, db, gid, *req.Status); !ok {
+					return
+				}
+			}
 			updates["status"] = *req.Status
 		}
 
@@ -337,6 +462,63 @@ func BulkUpdateAnimals(db *gorm.DB) gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "No updates provided"})
 			return
 		}
+		held, err := heldAnimals(db, req.AnimalIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load animals"})
+			return
+		}
+		if len(held) > 0 {
+			respondLegalHold(c, held...)
+			return
+		}
+
+		var before []models.Animal
+		if err := db.Where("id IN ?", req.AnimalIDs).Find(&before).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load animals"})
+			return
+		}
+
+		now := time.Now()
+		after := make([]models.Animal, len(before))
+		for i, animal := range before {
+			after[i] = animal
+			if req.GroupID != nil {
+				after[i].GroupID = *req.GroupID
+			}
+			if req.Status != nil {
+				after[i].Status = *req.Status
+				// Outcomes follow the status, as on single edits
+				after[i].Outcome, after[i].OutcomeDate, _ = resolveOutcome(animal, after[i].Status, nil, now)
+			}
+		}
+		// Bonded partners in the request leave care with each other
+		var leftBehind []models.RelatedAnimal
+		for i, animal := range before {
+			left, err := bondedPartnersLeftBehind(db, animal, after[i].Outcome, req.AnimalIDs)
+			if err != nil {
+				logger.Error("Failed to check bonded partners", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check bonded partners"})
+				return
+			}
+			for _, partner := range left {
+				if !slices.ContainsFunc(leftBehind, func(r models.RelatedAnimal) bool { return r.AnimalID == partner.AnimalID }) {
+					leftBehind = append(leftBehind, partner)
+				}
+			}
+		}
+		if len(leftBehind) > 0 {
+			respondBondedPartnersInCare(c, leftBehind)
+			return
+		}
+		if c.Query("dry_run") == "true" {
+			c.JSON(http.StatusOK, gin.H{
+				"message": fmt.Sprintf("%d animals would be updated", len(before)),
+				"dry_run": true,
+				"count":   len(before),
+				"changes": sampleAnimalDiffs(nil, before, after),
+			})
+			return
+		}
 
 		// Perform bulk update
 		if err := db.Model(&models.Animal{}).Where("id IN ?", req.AnimalIDs).Updates(updates).Error; err != nil {
@@ -344,6 +526,15 @@ func BulkUpdateAnimals(db *gorm.DB) gin.HandlerFunc {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update animals"})
 			return
 		}
+		for i, animal := range before {
+			if after[i].Outcome != animal.Outcome || after[i].OutcomeDate != animal.OutcomeDate {
+				if err := db.Model(&models.Animal{}).Where("id = ?", animal.ID).
+					Updates(map[string]interface{}{"outcome": after[i].Outcome, "outcome_date": after[i].OutcomeDate}).Error; err != nil {
+					logger.Error("Failed to update animal outcome", err)
+				}
+			}
+			recordAnimalChange(c, db, animal, after[i], models.AnimalChangeBulk)
+		}
 
 		logger.WithFields(map[string]interface{}{
 			"count":    len(req.AnimalIDs),
@@ -358,7 +549,8 @@ func BulkUpdateAnimals(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-// GetAllAnimals returns all animals (admin or group admin, for bulk edit page)
+// GetAllAnimals returns all animals (admin or group admin, for bulk edit page),
+// ordered by group and name unless ?sort= is given
 func GetAllAnimals(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		db := middleware.GetDB(c, db)
@@ -413,13 +605,22 @@ func GetAllAnimals(db *gorm.DB) gin.HandlerFunc {
 		// Name search filter
 		nameSearch := c.Query("name")
 		if nameSearch != "" {
-			// Escape SQL wildcards to prevent unintended pattern matching
-			escaped := escapeSQLWildcards(strings.ToLower(nameSearch))
-			query = query.Where("LOWER(name) LIKE ?", "%"+escaped+"%")
+			query = query.Where(database.DialectOf(db).ContainsFold("name", nameSearch))
+		}
+
+		params := c.Request.URL.Query()
+		query, err := applyAnimalSort(params, query, "animals.group_id", "animals.name")
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		query, ok = applyAnimalPage(c, params, query, query.Session(&gorm.Session{}))
+		if !ok {
+			return
 		}
 
 		var animals []models.Animal
-		if err := query.Preload("Tags").Order("group_id, name").Find(&animals).Error; err != nil {
+		if err := query.Preload("Tags").Find(&animals).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch animals"})
 			return
 		}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// animalViewThrottle is how long after a user's recorded view of an animal
+// further views of it by the same user are not counted.
+const animalViewThrottle = 30 * time.Minute
+
+// defaultAnalyticsRangeDays is the date range used when ?from is omitted.
+const defaultAnalyticsRangeDays = 30
+
+// AnimalEngagement is one animal's engagement over the requested range.
+type AnimalEngagement struct {
+	AnimalID         uint   `json:"animal_id"`
+	Name             string `json:"name"`
+	GroupID          uint   `json:"group_id"`
+	Status           string `json:"status"`
+	Views            int64  `json:"views"`
+	UniqueViewers    int64  `json:"unique_viewers"`
+	Comments         int64  `json:"comments"`
+	DaysInStatus     int    `json:"days_in_status"`
+	DaysSinceArrival int    `json:"days_since_arrival"`
+}
+
+// StatusDuration summarizes how long animals have been in one status.
+type StatusDuration struct {
+	Count   int     `json:"count"`
+	AvgDays float64 `json:"avg_days"`
+	MaxDays int     `json:"max_days"`
+}
+
+// AnimalAnalyticsSummary aggregates engagement across all reported animals.
+type AnimalAnalyticsSummary struct {
+	AnimalCount         int                       `json:"animal_count"`
+	TotalViews          int64                     `json:"total_views"`
+	TotalComments       int64                     `json:"total_comments"`
+	AvgDaysSinceArrival float64                   `json:"avg_days_since_arrival"`
+	DaysInStatus        map[string]StatusDuration `json:"days_in_status"`
+}
+
+// AnimalAnalytics is the response for GET /api/admin/analytics/animals.
+type AnimalAnalytics struct {
+	From    time.Time              `json:"from"`
+	To      time.Time              `json:"to"`
+	GroupID *uint                  `json:"group_id,omitempty"`
+	Summary AnimalAnalyticsSummary `json:"summary"`
+	Animals []AnimalEngagement     `json:"animals"`
+}
+
+// recordAnimalView records that userID viewed animalID, unless they already
+// did so within animalViewThrottle.
+func recordAnimalView(db *gorm.DB, animalID, userID uint, now time.Time) error {
+	var recent int64
+	if err := db.Model(&models.AnimalView{}).
+		Where("animal_id = ? AND user_id = ? AND created_at > ?", animalID, userID, now.Add(-animalViewThrottle)).
+		Count(&recent).Error; err != nil {
+		return err
+	}
+	if recent > 0 {
+		return nil
+	}
+	return db.Create(&models.AnimalView{AnimalID: animalID, UserID: userID, CreatedAt: now}).Error
+}
+
+// daysSince returns the whole days elapsed between t and now.
+func daysSince(t, now time.Time) int {
+	if now.Before(t) {
+		return 0
+	}
+	return int(now.Sub(t).Hours() / 24)
+}
+
+// parseAnalyticsRange reads ?from and ?to (YYYY-MM-DD, both inclusive) and
+// returns the range as [from, to) instants. from defaults to
+// defaultAnalyticsRangeDays before to; to defaults to today.
+func parseAnalyticsRange(c *gin.Context, now time.Time) (from, to time.Time, ok bool) {
+	to = now.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	if v := c.Query("to"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			respondBadRequest(c, "to must be a date in YYYY-MM-DD format")
+			return
+		}
+		to = d.AddDate(0, 0, 1)
+	}
+	from = to.AddDate(0, 0, -defaultAnalyticsRangeDays)
+	if v := c.Query("from"); v != "" {
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			respondBadRequest(c, "from must be a date in YYYY-MM-DD format")
+			return
+		}
+		from = d
+	}
+	if !from.Before(to) {
+		respondBadRequest(c, "from must not be after to")
+		return
+	}
+	return from, to, true
+}
+
+// GetAnimalAnalytics reports views and comments per animal over a date
+// range, with how long each animal has been in its status and since arrival
+// (admin only). Archived animals are excluded unless include_archived=true.
+// Query params: group_id, from, to (YYYY-MM-DD), include_archived.
+// Route: GET /api/admin/analytics/animals
+func GetAnimalAnalytics(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+		defer cancel()
+		db := middleware.GetDB(c, db).WithContext(ctx)
+		now := time.Now()
+
+		from, to, ok := parseAnalyticsRange(c, now)
+		if !ok {
+			return
+		}
+		report := AnimalAnalytics{From: from, To: to.AddDate(0, 0, -1)}
+
+		animalQuery := db.Model(&models.Animal{})
+		if v := c.Query("group_id"); v != "" {
+			gid, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group_id")
+				return
+			}
+			groupID := uint(gid)
+			report.GroupID = &groupID
+			animalQuery = animalQuery.Where("group_id = ?", groupID)
+		}
+		if c.Query("include_archived") != "true" {
+			animalQuery = animalQuery.Where("status != ?", "archived")
+		}
+
+		var animals []models.Animal
+		if err := animalQuery.Select("id", "name", "group_id", "status", "arrival_date", "last_status_change", "created_at").
+			Find(&animals).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to fetch animals for analytics", err)
+			respondInternalError(c, "Failed to fetch animal analytics")
+			return
+		}
+
+		ids := make([]uint, len(animals))
+		for i, a := range animals {
+			ids[i] = a.ID
+		}
+
+		type viewCount struct {
+			AnimalID      uint
+			Views         int64
+			UniqueViewers int64
+		}
+		var views []viewCount
+		type commentCount struct {
+			AnimalID uint
+			Comments int64
+		}
+		var comments []commentCount
+		if len(ids) > 0 {
+			if err := db.Model(&models.AnimalView{}).
+				Select("animal_id, COUNT(*) AS views, COUNT(DISTINCT user_id) AS unique_viewers").
+				Where("animal_id IN ? AND created_at >= ? AND created_at < ?", ids, from, to).
+				Group("animal_id").Scan(&views).Error; err != nil {
+				middleware.GetLogger(c).Error("Failed to count animal views", err)
+				respondInternalError(c, "Failed to fetch animal analytics")
+				return
+			}
+			if err := db.Model(&models.AnimalComment{}).
+				Select("animal_id, COUNT(*) AS comments").
+				Where("animal_id IN ? AND created_at >= ? AND created_at < ?", ids, from, to).
+				Group("animal_id").Scan(&comments).Error; err != nil {
+				middleware.GetLogger(c).Error("Failed to count animal comments", err)
+				respondInternalError(c, "Failed to fetch animal analytics")
+				return
+			}
+		}
+		viewsByAnimal := make(map[uint]viewCount, len(views))
+		for _, v := range views {
+			viewsByAnimal[v.AnimalID] = v
+		}
+		commentsByAnimal := make(map[uint]int64, len(comments))
+		for _, cc := range comments {
+			commentsByAnimal[cc.AnimalID] = cc.Comments
+		}
+
+		report.Animals = make([]AnimalEngagement, 0, len(animals))
+		report.Summary.DaysInStatus = make(map[string]StatusDuration)
+		totalArrivalDays := 0
+		for _, a := range animals {
+			arrived := a.CreatedAt
+			if a.ArrivalDate != nil {
+				arrived = *a.ArrivalDate
+			}
+			statusSince := arrived
+			if a.LastStatusChange != nil {
+				statusSince = *a.LastStatusChange
+			}
+
+			e := AnimalEngagement{
+				AnimalID:         a.ID,
+				Name:             a.Name,
+				GroupID:          a.GroupID,
+				Status:           a.Status,
+				Views:            viewsByAnimal[a.ID].Views,
+				UniqueViewers:    viewsByAnimal[a.ID].UniqueViewers,
+				Comments:         commentsByAnimal[a.ID],
+				DaysInStatus:     daysSince(statusSince, now),
+				DaysSinceArrival: daysSince(arrived, now),
+			}
+			report.Animals = append(report.Animals, e)
+
+			report.Summary.TotalViews += e.Views
+			report.Summary.TotalComments += e.Comments
+			totalArrivalDays += e.DaysSinceArrival
+			d := report.Summary.DaysInStatus[e.Status]
+			d.AvgDays = (d.AvgDays*float64(d.Count) + float64(e.DaysInStatus)) / float64(d.Count+1)
+			d.Count++
+			if e.DaysInStatus > d.MaxDays {
+				d.MaxDays = e.DaysInStatus
+			}
+			report.Summary.DaysInStatus[e.Status] = d
+		}
+		report.Summary.AnimalCount = len(report.Animals)
+		if n := len(report.Animals); n > 0 {
+			report.Summary.AvgDaysSinceArrival = float64(totalArrivalDays) / float64(n)
+		}
+
+		// Most viewed first, so the animals getting the least attention are
+		// at the end
+		sort.SliceStable(report.Animals, func(i, j int) bool {
+			a, b := report.Animals[i], report.Animals[j]
+			if a.Views != b.Views {
+				return a.Views > b.Views
+			}
+			if a.Comments != b.Comments {
+				return a.Comments > b.Comments
+			}
+			return a.AnimalID < b.AnimalID
+		})
+
+		respondOK(c, report)
+	}
+}
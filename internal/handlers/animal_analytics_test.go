@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAnimalView_Throttled(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	user, group := createAnimalTestUser(t, db, "member", "member@example.com", false)
+	animal := createTestAnimal(t, db, group.ID, "Rex", "Dog")
+	now := time.Now()
+
+	require.NoError(t, recordAnimalView(db, animal.ID, user.ID, now))
+	require.NoError(t, recordAnimalView(db, animal.ID, user.ID, now.Add(10*time.Minute)))
+	require.NoError(t, recordAnimalView(db, animal.ID, user.ID, now.Add(animalViewThrottle+time.Minute)))
+
+	var count int64
+	db.Model(&models.AnimalView{}).Count(&count)
+	assert.Equal(t, int64(2), count, "views within the throttle window are not recorded")
+}
+
+func TestGetAnimal_RecordsView(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	user, group := createAnimalTestUser(t, db, "member", "member@example.com", false)
+	animal := createTestAnimal(t, db, group.ID, "Rex", "Dog")
+
+	for i := 0; i < 2; i++ {
+		c, w := setupAnimalTestContext(user.ID, false)
+		c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}, {Key: "animalId", Value: fmt.Sprint(animal.ID)}}
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		GetAnimal(db)(c)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	var count int64
+	db.Model(&models.AnimalView{}).Where("animal_id = ? AND user_id = ?", animal.ID, user.ID).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestGetAnimalAnalytics(t *testing.T) {
+	db := SetupTestDB(t)
+	admin, group := createAnimalTestUser(t, db, "admin", "admin@example.com", true)
+	other := CreateTestGroup(t, db, "Cats", "Cat group")
+	viewer := CreateTestUser(t, db, "viewer", "viewer@example.com", "password123", false)
+
+	rex := createTestAnimal(t, db, group.ID, "Rex", "Dog")
+	fido := createTestAnimal(t, db, group.ID, "Fido", "Dog")
+	tom := createTestAnimal(t, db, other.ID, "Tom", "Cat")
+	now := time.Now()
+	arrived, changed := now.AddDate(0, 0, -40), now.AddDate(0, 0, -12)
+	db.Model(rex).Updates(map[string]interface{}{"arrival_date": arrived, "last_status_change": changed})
+
+	for _, v := range []models.AnimalView{
+		{AnimalID: fido.ID, UserID: admin.ID, CreatedAt: now.AddDate(0, 0, -2)},
+		{AnimalID: fido.ID, UserID: viewer.ID, CreatedAt: now.AddDate(0, 0, -1)},
+		{AnimalID: fido.ID, UserID: viewer.ID, CreatedAt: now.AddDate(0, 0, -60)}, // Outside the default range
+		{AnimalID: rex.ID, UserID: viewer.ID, CreatedAt: now.AddDate(0, 0, -3)},
+		{AnimalID: tom.ID, UserID: viewer.ID, CreatedAt: now.AddDate(0, 0, -1)},
+	} {
+		require.NoError(t, db.Create(&v).Error)
+	}
+	require.NoError(t, db.Create(&models.AnimalComment{AnimalID: rex.ID, UserID: viewer.ID, Content: "Good walk"}).Error)
+
+	get := func(query string) (int, AnimalAnalytics) {
+		c, w := setupAnimalTestContext(admin.ID, true)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/admin/analytics/animals"+query, nil)
+		GetAnimalAnalytics(db)(c)
+		var report AnimalAnalytics
+		_ = json.Unmarshal(w.Body.Bytes(), &report)
+		return w.Code, report
+	}
+
+	code, report := get(fmt.Sprintf("?group_id=%d", group.ID))
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, report.Animals, 2)
+	assert.Equal(t, fido.ID, report.Animals[0].AnimalID, "most viewed first")
+	assert.Equal(t, int64(2), report.Animals[0].Views)
+	assert.Equal(t, int64(2), report.Animals[0].UniqueViewers)
+	assert.Equal(t, int64(1), report.Animals[1].Comments)
+	assert.Equal(t, 12, report.Animals[1].DaysInStatus)
+	assert.Equal(t, 40, report.Animals[1].DaysSinceArrival)
+	assert.Equal(t, int64(3), report.Summary.TotalViews)
+	assert.Equal(t, 2, report.Summary.DaysInStatus["available"].Count)
+	assert.Equal(t, 12, report.Summary.DaysInStatus["available"].MaxDays)
+
+	code, report = get("?from=" + now.AddDate(0, 0, -90).Format("2006-01-02"))
+	require.Equal(t, http.StatusOK, code)
+	assert.Len(t, report.Animals, 3)
+	assert.Equal(t, int64(5), report.Summary.TotalViews)
+
+	code, _ = get("?from=2026-10-10&to=2026-10-01")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = get("?group_id=abc")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// JobAnimalChangeEmail is the background job type that tells a group admin
+// which fields of an animal someone changed.
+const JobAnimalChangeEmail = "animal_change_email"
+
+// animalChangeEmailJob is the payload of a JobAnimalChangeEmail job.
+type animalChangeEmailJob struct {
+	ChangeID uint `json:"change_id"`
+	UserID   uint `json:"user_id"`
+}
+
+// formatChangeDate formats an optional date for an AnimalFieldChange
+func formatChangeDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// diffAnimal returns the user-visible fields that differ between before and
+// after, keyed by their JSON names, then the changed custom fields as
+// "custom_fields.<key>". Dates are compared by day.
+func diffAnimal(before, after models.Animal) models.AnimalFieldChanges {
+	fields := []struct {
+		name     string
+		old, new string
+	}{
+		{"name", before.Name, after.Name},
+		{"species", before.Species, after.Species},
+		{"breed", before.Breed, after.Breed},
+		{"estimated_birth_date", formatChangeDate(before.EstimatedBirthDate), formatChangeDate(after.EstimatedBirthDate)},
+		{"description", before.Description, after.Description},
+		{"trainer_notes", before.TrainerNotes, after.TrainerNotes},
+		{"image_url", before.ImageURL, after.ImageURL},
+		{"status", before.Status, after.Status},
+		{"group_id", strconv.FormatUint(uint64(before.GroupID), 10), strconv.FormatUint(uint64(after.GroupID), 10)},
+		{"arrival_date", formatChangeDate(before.ArrivalDate), formatChangeDate(after.ArrivalDate)},
+		{"quarantine_end_date", formatChangeDate(before.QuarantineEndDate), formatChangeDate(after.QuarantineEndDate)},
+		{"is_returned", strconv.FormatBool(before.IsReturned), strconv.FormatBool(after.IsReturned)},
+		{"intake_source", before.IntakeSource, after.IntakeSource},
+		{"size", before.Size, after.Size},
+		{"energy_level", before.EnergyLevel, after.EnergyLevel},
+		{"microchip_number", before.MicrochipNumber, after.MicrochipNumber},
+		{"license_number", before.LicenseNumber, after.LicenseNumber},
+		{"outcome", before.Outcome, after.Outcome},
+	}
+	var changes models.AnimalFieldChanges
+	for _, f := range fields {
+		if f.old != f.new {
+			changes = append(changes, models.AnimalFieldChange{Field: f.name, Old: f.old, New: f.new})
+		}
+	}
+
+	keys := make([]string, 0, len(before.CustomFields)+len(after.CustomFields))
+	for key := range before.CustomFields {
+		keys = append(keys, key)
+	}
+	for key := range after.CustomFields {
+		if _, ok := before.CustomFields[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if oldValue, newValue := before.CustomFields[key], after.CustomFields[key]; oldValue != newValue {
+			changes = append(changes, models.AnimalFieldChange{Field: "custom_fields." + key, Old: oldValue, New: newValue})
+		}
+	}
+	return changes
+}
+
+// recordAnimalChange stores the fields an edit changed in the activity feed
+// of the animal's group, and of its old group when it moved. Single edits
+// also email the group's admins who opted in; bulk updates only reach the
+// feed. Failures are logged, never returned: the edit itself has succeeded.
+func recordAnimalChange(c *gin.Context, db *gorm.DB, before, after models.Animal, source string) {
+	changes := diffAnimal(before, after)
+	if len(changes) == 0 {
+		return
+	}
+	logger := middleware.GetLogger(c)
+	userID, _ := middleware.GetUserID(c)
+
+	groupIDs := []uint{after.GroupID}
+	if before.GroupID != after.GroupID {
+		groupIDs = append(groupIDs, before.GroupID)
+	}
+	for _, groupID := range groupIDs {
+		change := models.AnimalChange{
+			GroupID:  groupID,
+			AnimalID: after.ID,
+			UserID:   userID,
+			Source:   source,
+			Changes:  changes,
+		}
+		if err := db.Create(&change).Error; err != nil {
+			logger.Error("Failed to record animal change", err)
+			continue
+		}
+		if source == models.AnimalChangeBulk {
+			continue
+		}
+
+		var adminIDs []uint
+		if err := notifiableUsers(db.Model(&models.User{})).
+			Joins("JOIN user_groups ON user_groups.user_id = users.id").
+			Where("user_groups.group_id = ? AND user_groups.is_group_admin = ?", groupID, true).
+			Where("users.animal_change_emails_enabled = ? AND users.id <> ?", true, userID).
+			Pluck("users.id", &adminIDs).Error; err != nil {
+			logger.Error("Failed to load admins for animal change email", err)
+			continue
+		}
+		payloads := make([]interface{}, len(adminIDs))
+		for i, id := range adminIDs {
+			payloads[i] = animalChangeEmailJob{ChangeID: change.ID, UserID: id}
+		}
+		if err := jobs.EnqueueMany(db, JobAnimalChangeEmail, payloads); err != nil {
+			logger.Error("Failed to queue animal change emails", err)
+		}
+	}
+}
+
+// animalChangeEmailJobHandler sends a JobAnimalChangeEmail. Nothing is sent
+// if the change, animal, or recipient is gone, or the recipient has since
+// turned the emails off.
+func animalChangeEmailJobHandler(db *gorm.DB, emailService *email.Service) jobs.Handler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var job animalChangeEmailJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return jobs.Permanent(err)
+		}
+		if emailService == nil || !emailService.IsConfigured() {
+			return errors.New("email service is not configured")
+		}
+		db := db.WithContext(ctx)
+
+		var change models.AnimalChange
+		var animal models.Animal
+		var recipient models.User
+		for _, load := range []func() error{
+			func() error { return db.Preload("User").First(&change, job.ChangeID).Error },
+			func() error { return db.First(&animal, change.AnimalID).Error },
+			func() error {
+				return notifiableUsers(db).Where("animal_change_emails_enabled = ?", true).First(&recipient, job.UserID).Error
+			},
+		} {
+			if err := load(); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil
+				}
+				return err
+			}
+		}
+
+		link := fmt.Sprintf("%s/groups/%d/animals/%d/view", frontendURL(), animal.GroupID, animal.ID)
+		ctx = groupSenderContext(ctx, db, animal.GroupID)
+		return emailService.SendAnimalChangeEmail(ctx, recipient.Email, change.User.Username, animal.Name, change.Changes, link)
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffAnimal(t *testing.T) {
+	arrival := time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC)
+	sameDay := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	before := models.Animal{Name: "Rex", Status: "available", GroupID: 1, ArrivalDate: &arrival}
+	after := models.Animal{Name: "Rex", Status: "foster", GroupID: 2, ArrivalDate: &sameDay, IsReturned: true}
+
+	assert.Empty(t, diffAnimal(before, before))
+	assert.Equal(t, models.AnimalFieldChanges{
+		{Field: "status", Old: "available", New: "foster"},
+		{Field: "group_id", Old: "1", New: "2"},
+		{Field: "is_returned", Old: "false", New: "true"},
+	}, diffAnimal(before, after))
+}
+
+func TestAnimalChangeNotifications(t *testing.T) {
+	db := SetupTestDB(t)
+	editor := CreateTestUser(t, db, "editor", "editor@example.com", "password123", false)
+	subscriber := CreateTestUser(t, db, "subscriber", "subscriber@example.com", "password123", false)
+	quiet := CreateTestUser(t, db, "quiet", "quiet@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	for _, user := range []*models.User{editor, subscriber, quiet} {
+		AddUserToGroupWithAdmin(t, db, user.ID, group.ID, true)
+		require.NoError(t, db.Model(user).Update("email_notifications_enabled", true).Error)
+	}
+	for _, user := range []*models.User{editor, subscriber} {
+		require.NoError(t, db.Model(user).Update("animal_change_emails_enabled", true).Error)
+	}
+	animal := CreateTestAnimal(t, db, group.ID, "Rex", "Dog")
+	params := gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}, {Key: "animalId", Value: fmt.Sprint(animal.ID)}}
+
+	c, w := accountTestContext(editor.ID, false, http.MethodPut, "/", AnimalRequest{
+		Name: "Rex", Species: "Dog", Breed: "Beagle", Status: "available",
+	})
+	c.Params = params
+	UpdateAnimal(db, nil, &embedding.StubEmbedder{})(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// The feed shows the change with its old and new values
+	c, w = accountTestContext(subscriber.ID, false, http.MethodGet, "/?type=changes", nil)
+	c.Params = params[:1]
+	GetGroupActivityFeed(db)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var feed struct {
+		Items []ActivityItem `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &feed))
+	require.Len(t, feed.Items, 1)
+	assert.Equal(t, "animal_change", feed.Items[0].Type)
+	assert.Equal(t, editor.ID, feed.Items[0].UserID)
+	assert.Equal(t, []models.AnimalFieldChange{{Field: "breed", Old: "", New: "Beagle"}}, feed.Items[0].Changes)
+
+	// Only opted-in admins other than the editor are emailed
+	provider := &recordingEmailProvider{}
+	queue := jobs.NewQueue(db)
+	RegisterJobHandlers(queue, db, email.NewServiceWithProvider(provider, db), nil)
+	assert.Equal(t, 1, queue.RunDue(context.Background()))
+	assert.Equal(t, []string{"subscriber@example.com"}, provider.sentTo)
+
+	// Bulk updates reach the feed but send no email
+	c, w = accountTestContext(editor.ID, false, http.MethodPut, "/", BulkUpdateAnimalsRequest{
+		AnimalIDs: []uint{animal.ID}, Status: stringPtr("foster"),
+	})
+	BulkUpdateAnimals(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var changes []models.AnimalChange
+	require.NoError(t, db.Order("id").Find(&changes).Error)
+	require.Len(t, changes, 2)
+	assert.Equal(t, models.AnimalChangeBulk, changes[1].Source)
+	assert.Equal(t, models.AnimalFieldChanges{{Field: "status", Old: "available", New: "foster"}}, changes[1].Changes)
+	var queued int64
+	require.NoError(t, db.Model(&models.Job{}).Where("type = ?", JobAnimalChangeEmail).Count(&queued).Error)
+	assert.Equal(t, int64(1), queued)
+}
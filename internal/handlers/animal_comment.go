@@ -6,9 +6,11 @@ import (
 	"net/http"
 	"regexp"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/maintenance"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
 	"gorm.io/gorm"
@@ -111,9 +113,10 @@ func GetAnimalComments(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// Verify animal exists and belongs to group
+		// Verify animal exists, belongs to group, and isn't restricted
 		var animal models.Animal
-		if err := db.Where("id = ? AND group_id = ?", animalID, groupID).First(&animal).Error; err != nil {
+		animalQuery := db.Where("id = ? AND group_id = ?", animalID, groupID)
+		if err := visibleAnimals(c, db, animalQuery, groupID).First(&animal).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Animal not found"})
 			return
 		}
@@ -164,13 +167,18 @@ func GetAnimalComments(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		var comments []models.AnimalComment
-		// A secondary tie-break on id is required: without it, comments
-		// sharing an identical created_at (bulk-inserted/seeded data, or
-		// coarse client clocks) sort in whatever order Postgres happens to
-		// return them in, which can differ from GetAnimalCommentPosition's
-		// offset computation below and misalign which page a given comment
-		// actually lands on.
-		if err := query.Order("animal_comments.created_at " + sortOrder + ", animal_comments.id " + sortOrder).Limit(limit).Offset(offset).Find(&comments).Error; err != nil {
+		// Pinned comments come first. A secondary tie-break on id is
+		// required: without it, comments sharing an identical created_at
+		// (bulk-inserted/seeded data, or coarse client clocks) sort in
+		// whatever order Postgres happens to return them in, which can
+		// differ from GetAnimalCommentPosition's offset computation below
+		// and misalign which page a given comment actually lands on.
+		if err := query.Order(commentOrder(sortOrder)).Limit(limit).Offset(offset).Find(&comments).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+			return
+		}
+		viewerID, _ := middleware.GetUserID(c)
+		if err := attachReactionCounts(db, comments, viewerID); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
 			return
 		}
@@ -223,7 +231,7 @@ func GetAnimalCommentPosition(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		var animal models.Animal
-		if err := db.Where("id = ? AND group_id = ?", animalID, groupID).First(&animal).Error; err != nil {
+		if err := visibleAnimals(c, db, db.Where("id = ? AND group_id = ?", animalID, groupID), groupID).First(&animal).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Animal not found"})
 			return
 		}
@@ -266,20 +274,10 @@ func GetAnimalCommentPosition(db *gorm.DB) gin.HandlerFunc {
 			countQuery = applyTagFilter(countQuery, splitAndTrim(tagFilter))
 		}
 		// Position = how many rows sort strictly before the target under the
-		// same ORDER BY (created_at, id) GetAnimalComments uses for this
-		// sortOrder — the id tie-break matters whenever another comment
-		// shares the target's exact created_at.
-		if sortOrder == "ASC" {
-			countQuery = countQuery.Where(
-				"(animal_comments.created_at < ? OR (animal_comments.created_at = ? AND animal_comments.id < ?))",
-				target.CreatedAt, target.CreatedAt, target.ID,
-			)
-		} else {
-			countQuery = countQuery.Where(
-				"(animal_comments.created_at > ? OR (animal_comments.created_at = ? AND animal_comments.id > ?))",
-				target.CreatedAt, target.CreatedAt, target.ID,
-			)
-		}
+		// same ORDER BY (pinned, pinned_at, created_at, id) GetAnimalComments
+		// uses for this sortOrder — the id tie-break matters whenever another
+		// comment shares the target's exact created_at.
+		countQuery = commentsBefore(countQuery, target, sortOrder)
 
 		var position int64
 		if err := countQuery.Count(&position).Error; err != nil {
@@ -291,7 +289,8 @@ func GetAnimalCommentPosition(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-// CreateAnimalComment creates a new comment on an animal
+// CreateAnimalComment creates a new comment on an animal. Animals with a
+// restricted tag are not found for members without a matching qualification.
 func CreateAnimalComment(db *gorm.DB, embedder embedding.Embedder) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// rawDB is captured before the shadow below so the detached
@@ -312,9 +311,9 @@ func CreateAnimalComment(db *gorm.DB, embedder embedding.Embedder) gin.HandlerFu
 			return
 		}
 
-		// Verify animal exists and belongs to group
+		// Verify animal exists, belongs to group, and is visible to the user
 		var animal models.Animal
-		if err := db.Where("id = ? AND group_id = ?", animalID, groupID).First(&animal).Error; err != nil {
+		if err := visibleAnimals(c, db, db.Where("id = ? AND group_id = ?", animalID, groupID), groupID).First(&animal).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Animal not found"})
 			return
 		}
@@ -407,7 +406,7 @@ func UpdateAnimalComment(db *gorm.DB, embedder embedding.Embedder) gin.HandlerFu
 
 		// Verify animal exists and belongs to group
 		var animal models.Animal
-		if err := db.Where("id = ? AND group_id = ?", animalID, groupID).First(&animal).Error; err != nil {
+		if err := visibleAnimals(c, db, db.Where("id = ? AND group_id = ?", animalID, groupID), groupID).First(&animal).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Animal not found"})
 			return
 		}
@@ -582,9 +581,9 @@ func GetGroupLatestComments(db *gorm.DB) gin.HandlerFunc {
 			}
 		}
 
-		// Get animals in this group first
+		// Get the animals in this group the user can see first
 		var animals []models.Animal
-		if err := db.Where("group_id = ?", groupID).Find(&animals).Error; err != nil {
+		if err := visibleAnimals(c, db, db.Where("group_id = ?", groupID), groupID).Find(&animals).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch animals"})
 			return
 		}
@@ -656,7 +655,7 @@ func DeleteAnimalComment(db *gorm.DB) gin.HandlerFunc {
 
 		// Verify animal exists and belongs to group
 		var animal models.Animal
-		if err := db.Where("id = ? AND group_id = ?", animalID, groupID).First(&animal).Error; err != nil {
+		if err := visibleAnimals(c, db, db.Where("id = ? AND group_id = ?", animalID, groupID), groupID).First(&animal).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Animal not found"})
 			return
 		}
@@ -680,7 +679,14 @@ func DeleteAnimalComment(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// Soft delete the comment
+		// Soft delete the comment. It's unpinned first so restoring it can't
+		// push the animal past its pinned comment limit.
+		if comment.Pinned {
+			if err := db.Model(&comment).UpdateColumns(map[string]interface{}{"pinned": false, "pinned_at": nil, "pinned_by_id": nil}).Error; err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete comment"})
+				return
+			}
+		}
 		if err := db.Delete(&comment).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete comment"})
 			return
@@ -690,8 +696,13 @@ func DeleteAnimalComment(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-// GetDeletedComments returns all soft-deleted comments (group admin or site admin)
+// GetDeletedComments returns soft-deleted comments that can still be
+// restored, newest deletion first (group admin or site admin). On the
+// per-animal route only that animal's comments are listed.
+// Routes: GET /api/groups/:id/deleted-comments, GET /api/groups/:id/animals/:animalId/comments/deleted
 func GetDeletedComments(db *gorm.DB) gin.HandlerFunc {
+	retention := maintenance.DeletedCommentRetention()
+
 	return func(c *gin.Context) {
 		db := middleware.GetDB(c, db)
 		groupID := c.Param("id")
@@ -706,7 +717,11 @@ func GetDeletedComments(db *gorm.DB) gin.HandlerFunc {
 
 		// Get animals in this group
 		var animals []models.Animal
-		if err := db.Where("group_id = ?", groupID).Find(&animals).Error; err != nil {
+		animalQuery := db.Where("group_id = ?", groupID)
+		if animalID := c.Param("animalId"); animalID != "" {
+			animalQuery = animalQuery.Where("id = ?", animalID)
+		}
+		if err := animalQuery.Find(&animals).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch animals"})
 			return
 		}
@@ -740,7 +755,9 @@ func GetDeletedComments(db *gorm.DB) gin.HandlerFunc {
 		// Build response with animal information
 		type DeletedCommentWithAnimal struct {
 			models.AnimalComment
-			Animal models.Animal `json:"animal"`
+			Animal     models.Animal `json:"animal"`
+			DeletedAt  time.Time     `json:"deleted_at"`
+			PurgeAfter time.Time     `json:"purge_after"` // When the comment purge removes it for good
 		}
 
 		var results []DeletedCommentWithAnimal
@@ -749,6 +766,8 @@ func GetDeletedComments(db *gorm.DB) gin.HandlerFunc {
 				results = append(results, DeletedCommentWithAnimal{
 					AnimalComment: comment,
 					Animal:        animal,
+					DeletedAt:     comment.DeletedAt.Time,
+					PurgeAfter:    comment.DeletedAt.Time.Add(retention),
 				})
 			}
 		}
@@ -756,3 +775,49 @@ func GetDeletedComments(db *gorm.DB) gin.HandlerFunc {
 		c.JSON(http.StatusOK, results)
 	}
 }
+
+// RestoreAnimalComment undeletes a soft-deleted comment (group admin or site
+// admin). Comments can be restored until the comment purge removes them.
+// Route: POST /api/groups/:id/animals/:animalId/comments/:commentId/restore
+func RestoreAnimalComment(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
+			return
+		}
+
+		var animal models.Animal
+		if err := visibleAnimals(c, db, db.Where("id = ? AND group_id = ?", c.Param("animalId"), groupID), groupID).First(&animal).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeAnimalNotFound, "Animal not found")
+			return
+		}
+
+		var comment models.AnimalComment
+		if err := db.Unscoped().Where("id = ? AND animal_id = ?", c.Param("commentId"), animal.ID).First(&comment).Error; err != nil {
+			respondNotFound(c, "Comment not found")
+			return
+		}
+		if !comment.DeletedAt.Valid {
+			respondError(c, http.StatusConflict, ErrCodeConflict, "Comment is not deleted")
+			return
+		}
+
+		if err := db.Unscoped().Model(&comment).Update("deleted_at", nil).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to restore comment", err)
+			respondInternalError(c, "Failed to restore comment")
+			return
+		}
+
+		if err := db.Preload("User").Preload("Tags").First(&comment, comment.ID).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to reload restored comment", err)
+			respondInternalError(c, "Failed to restore comment")
+			return
+		}
+		respondOK(c, comment)
+	}
+}
@@ -0,0 +1,358 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// Outcomes of one comment in a batch
+const (
+	CommentBatchCreated   = "created"   // Saved now
+	CommentBatchDuplicate = "duplicate" // Saved by an earlier request with the same client_id
+	CommentBatchConflict  = "conflict"  // The client_id belongs to another comment, or one since deleted
+	CommentBatchError     = "error"     // Invalid; resending it unchanged won't help
+	CommentBatchFailed    = "failed"    // Not saved because of a server error; resend it later
+)
+
+// CommentBatchItem is one comment written offline. ClientID is a UUID the
+// client generates when the comment is written and keeps across retries.
+type CommentBatchItem struct {
+	ClientID string `json:"client_id"`
+	AnimalID uint   `json:"animal_id"`
+	AnimalCommentRequest
+}
+
+// CommentBatchRequest submits up to 50 comments written offline, in the
+// order they were written
+type CommentBatchRequest struct {
+	Comments []CommentBatchItem `json:"comments" binding:"required,min=1,max=50"`
+}
+
+// CommentBatchResult is the outcome of the comment at Index of a batch
+type CommentBatchResult struct {
+	Index    int                   `json:"index"`
+	ClientID string                `json:"client_id"`
+	Status   string                `json:"status"`
+	Comment  *models.AnimalComment `json:"comment,omitempty"`
+	Error    string                `json:"error,omitempty"`
+}
+
+// CommentSyncPage is a page of comment changes since a sync cursor
+type CommentSyncPage struct {
+	Comments   []models.AnimalComment `json:"comments"`
+	DeletedIDs []uint                 `json:"deleted_ids"`
+	NextCursor *string                `json:"next_cursor"`
+	HasMore    bool                   `json:"has_more"`
+}
+
+// commentSyncCursor identifies the last change of a sync page; the next
+// page starts strictly after it in (changed_at, id) order
+type commentSyncCursor struct {
+	ChangedAt time.Time `json:"t"`
+	ID        uint      `json:"id"`
+}
+
+func (sc commentSyncCursor) encode() string {
+	data, _ := json.Marshal(sc)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCommentSyncCursor(s string) (*commentSyncCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	var sc commentSyncCursor
+	if err := json.Unmarshal(data, &sc); err != nil || sc.ChangedAt.IsZero() {
+		return nil, errors.New("invalid cursor")
+	}
+	return &sc, nil
+}
+
+// commentChangedAt is when a comment last changed: its deletion, or else
+// its last save. Restoring a comment clears deleted_at and bumps updated_at.
+const commentChangedAt = "COALESCE(animal_comments.deleted_at, animal_comments.updated_at)"
+
+// visibleGroupAnimalIDs is a subquery of the ids of a group's animals the
+// requesting user can see
+func visibleGroupAnimalIDs(c *gin.Context, db *gorm.DB, groupID string) *gorm.DB {
+	return visibleAnimals(c, db, db.Model(&models.Animal{}).Select("animals.id").Where("animals.group_id = ?", groupID), groupID)
+}
+
+// validateCommentBatchItem checks the fields of a batch item that don't
+// need the database
+func validateCommentBatchItem(item CommentBatchItem) error {
+	if _, err := uuid.Parse(item.ClientID); err != nil {
+		return errors.New("client_id must be a UUID")
+	}
+	if item.AnimalID == 0 {
+		return errors.New("animal_id is required")
+	}
+	if strings.TrimSpace(item.Content) == "" {
+		return errors.New("content is required")
+	}
+	return validateSessionMetadata(item.Metadata)
+}
+
+// existingBatchComment resolves a batch item whose client_id is already
+// saved: the same author on the same animal is a retry, anything else a
+// conflict
+func existingBatchComment(db *gorm.DB, item CommentBatchItem, clientID string, userID uint) (CommentBatchResult, bool, error) {
+	var existing models.AnimalComment
+	if err := db.Unscoped().Where("client_id = ?", clientID).First(&existing).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return CommentBatchResult{}, false, nil
+		}
+		return CommentBatchResult{}, false, err
+	}
+	switch {
+	case existing.UserID != userID || existing.AnimalID != item.AnimalID:
+		return CommentBatchResult{Status: CommentBatchConflict, Error: "client_id is already used by another comment"}, true, nil
+	case existing.DeletedAt.Valid:
+		return CommentBatchResult{Status: CommentBatchConflict, Error: "This comment was deleted"}, true, nil
+	}
+	if err := db.Preload("User").Preload("Tags").First(&existing, existing.ID).Error; err != nil {
+		return CommentBatchResult{}, false, err
+	}
+	return CommentBatchResult{Status: CommentBatchDuplicate, Comment: &existing}, true, nil
+}
+
+// CreateAnimalCommentBatch saves comments written offline, each on its own
+// so one bad comment doesn't hold back the rest. Comments are timestamped
+// when they arrive. Each one's client_id makes resending it safe: a comment
+// already saved comes back as a duplicate instead of being saved twice. The
+// response lists a result per comment, in request order.
+// Route: POST /api/groups/:id/comments/batch
+func CreateAnimalCommentBatch(db *gorm.DB, embedder embedding.Embedder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// See CreateAnimalComment: embedCommentAsync outlives the request
+		rawDB := db
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+		uid, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+
+		var req CommentBatchRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+
+		animalIDs := make([]uint, 0, len(req.Comments))
+		for _, item := range req.Comments {
+			animalIDs = append(animalIDs, item.AnimalID)
+		}
+		var visible []uint
+		if err := visibleGroupAnimalIDs(c, db, groupID).Where("animals.id IN ?", animalIDs).Pluck("animals.id", &visible).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to load animals for comment batch", err)
+			respondInternalError(c, "Failed to save comments")
+			return
+		}
+		animalVisible := make(map[uint]bool, len(visible))
+		for _, id := range visible {
+			animalVisible[id] = true
+		}
+
+		results := make([]CommentBatchResult, len(req.Comments))
+		seen := make(map[string]bool, len(req.Comments))
+		for i, item := range req.Comments {
+			result, err := createBatchComment(db, rawDB, embedder, groupID, item, uid, animalVisible, seen)
+			if err != nil {
+				middleware.GetLogger(c).Error("Failed to save batched comment", err)
+				result = CommentBatchResult{Status: CommentBatchFailed, Error: "Failed to save comment"}
+			}
+			result.Index = i
+			result.ClientID = item.ClientID
+			results[i] = result
+		}
+
+		respondOK(c, gin.H{"results": results})
+	}
+}
+
+// createBatchComment saves one comment of a batch. Invalid comments are a
+// result with CommentBatchError; an error return means the comment may
+// save if resent.
+func createBatchComment(db, rawDB *gorm.DB, embedder embedding.Embedder, groupID string, item CommentBatchItem, userID uint, animalVisible map[uint]bool, seen map[string]bool) (CommentBatchResult, error) {
+	invalid := func(msg string) (CommentBatchResult, error) {
+		return CommentBatchResult{Status: CommentBatchError, Error: msg}, nil
+	}
+	if err := validateCommentBatchItem(item); err != nil {
+		return invalid(err.Error())
+	}
+	clientID := strings.ToLower(item.ClientID)
+	if seen[clientID] {
+		return invalid("client_id is repeated in this batch")
+	}
+	seen[clientID] = true
+	if !animalVisible[item.AnimalID] {
+		return invalid("Animal not found")
+	}
+
+	if result, found, err := existingBatchComment(db, item, clientID, userID); err != nil || found {
+		return result, err
+	}
+
+	sanitizeSessionMetadata(item.Metadata)
+	comment := models.AnimalComment{
+		AnimalID: item.AnimalID,
+		UserID:   userID,
+		Content:  item.Content,
+		ImageURL: item.ImageURL,
+		Metadata: item.Metadata,
+		ClientID: &clientID,
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&comment).Error; err != nil {
+			return err
+		}
+		if len(item.TagIDs) == 0 {
+			return nil
+		}
+		var tags []models.CommentTag
+		if err := tx.Where("id IN ? AND group_id = ?", item.TagIDs, groupID).Find(&tags).Error; err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+		return tx.Model(&comment).Association("Tags").Append(&tags)
+	})
+	if err != nil {
+		// A concurrent retry of the same comment may have won the race
+		// for the client_id
+		if result, found, lookupErr := existingBatchComment(db, item, clientID, userID); lookupErr == nil && found {
+			return result, nil
+		}
+		return CommentBatchResult{}, err
+	}
+
+	embedCommentAsync(rawDB, embedder, comment)
+	if err := db.Preload("User").Preload("Tags").First(&comment, comment.ID).Error; err != nil {
+		return CommentBatchResult{}, fmt.Errorf("reloading comment: %w", err)
+	}
+	return CommentBatchResult{Status: CommentBatchCreated, Comment: &comment}, nil
+}
+
+// SyncAnimalComments returns the comments on a group's animals that were
+// added, edited, restored, or deleted since a cursor, oldest change first,
+// so an offline client can bring its copy up to date. Start without a
+// cursor, then pass next_cursor as ?since= until has_more is false, and
+// keep the last next_cursor for the next sync. Deleted comments are listed
+// by id in deleted_ids. Deleted comments are purged after their retention
+// period, so a client that hasn't synced for longer than that should start
+// over without a cursor. Query params: since, limit (default 100, max 500).
+// Route: GET /api/groups/:id/comments/sync
+func SyncAnimalComments(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+
+		limit := 100
+		if limitParam := c.Query("limit"); limitParam != "" {
+			if parsedLimit, err := strconv.Atoi(limitParam); err == nil && parsedLimit > 0 {
+				limit = min(parsedLimit, 500)
+			}
+		}
+		var since *commentSyncCursor
+		if sinceParam := c.Query("since"); sinceParam != "" {
+			var err error
+			if since, err = decodeCommentSyncCursor(sinceParam); err != nil {
+				respondBadRequest(c, "Invalid cursor")
+				return
+			}
+		}
+
+		query := db.Unscoped().Model(&models.AnimalComment{}).
+			Where("animal_comments.animal_id IN (?)", visibleGroupAnimalIDs(c, db, groupID))
+		if since != nil {
+			query = query.Where("("+commentChangedAt+" > ? OR ("+commentChangedAt+" = ? AND animal_comments.id > ?))",
+				since.ChangedAt, since.ChangedAt, since.ID)
+		}
+		var changed []models.AnimalComment
+		if err := query.Order(commentChangedAt + ", animal_comments.id").Limit(limit + 1).Find(&changed).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to sync comments", err)
+			respondInternalError(c, "Failed to sync comments")
+			return
+		}
+
+		page := CommentSyncPage{Comments: []models.AnimalComment{}, DeletedIDs: []uint{}, HasMore: len(changed) > limit}
+		if page.HasMore {
+			changed = changed[:limit]
+		}
+		var liveIDs []uint
+		for _, comment := range changed {
+			if comment.DeletedAt.Valid {
+				page.DeletedIDs = append(page.DeletedIDs, comment.ID)
+			} else {
+				liveIDs = append(liveIDs, comment.ID)
+			}
+		}
+		if len(liveIDs) > 0 {
+			var live []models.AnimalComment
+			if err := db.Preload("User").Preload("Tags").Where("id IN ?", liveIDs).Find(&live).Error; err != nil {
+				respondInternalError(c, "Failed to sync comments")
+				return
+			}
+			byID := make(map[uint]models.AnimalComment, len(live))
+			for _, comment := range live {
+				byID[comment.ID] = comment
+			}
+			for _, id := range liveIDs {
+				if comment, ok := byID[id]; ok {
+					page.Comments = append(page.Comments, comment)
+				}
+			}
+			viewerID, _ := middleware.GetUserID(c)
+			if err := attachReactionCounts(db, page.Comments, viewerID); err != nil {
+				respondInternalError(c, "Failed to sync comments")
+				return
+			}
+		}
+
+		// With nothing new, the client keeps the cursor it has
+		if len(changed) > 0 {
+			last := changed[len(changed)-1]
+			changedAt := last.UpdatedAt
+			if last.DeletedAt.Valid {
+				changedAt = last.DeletedAt.Time
+			}
+			next := commentSyncCursor{ChangedAt: changedAt, ID: last.ID}.encode()
+			page.NextCursor = &next
+		} else if since != nil {
+			current := since.encode()
+			page.NextCursor = &current
+		}
+		respondOK(c, page)
+	}
+}
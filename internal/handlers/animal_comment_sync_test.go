@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAnimalCommentBatch(t *testing.T) {
+	db := SetupTestDB(t)
+	user := CreateTestUser(t, db, "volunteer", "volunteer@example.com", "password123", false)
+	other := CreateTestUser(t, db, "other", "other@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "Dog group")
+	otherGroup := CreateTestGroup(t, db, "Cats", "Cat group")
+	AddUserToGroupWithAdmin(t, db, user.ID, group.ID, false)
+	AddUserToGroupWithAdmin(t, db, other.ID, group.ID, false)
+	rex := CreateTestAnimal(t, db, group.ID, "Rex", "Dog")
+	tom := CreateTestAnimal(t, db, otherGroup.ID, "Tom", "Cat")
+	tag := models.CommentTag{GroupID: group.ID, Name: "walk"}
+	require.NoError(t, db.Create(&tag).Error)
+
+	submit := func(userID uint, items ...CommentBatchItem) []CommentBatchResult {
+		c, w := accountTestContext(userID, false, http.MethodPost, "/", CommentBatchRequest{Comments: items})
+		c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}}
+		CreateAnimalCommentBatch(db, &embedding.StubEmbedder{})(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp struct {
+			Results []CommentBatchResult `json:"results"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Results, len(items))
+		return resp.Results
+	}
+	item := func(clientID string, animalID uint, content string) CommentBatchItem {
+		return CommentBatchItem{ClientID: clientID, AnimalID: animalID, AnimalCommentRequest: AnimalCommentRequest{Content: content}}
+	}
+	first := item("6f1c1a52-3f7e-4c0b-9d4e-2b8f0a1e7c11", rex.ID, "Walked twice")
+	second := item("0b7d6a3e-9f2c-4e1a-8b5d-7c3e2f1a0d22", rex.ID, "Ate breakfast")
+	second.TagIDs = []uint{tag.ID}
+
+	results := submit(user.ID,
+		first,
+		item("not-a-uuid", rex.ID, "Hello"),
+		item("2c9e4b1f-5a6d-4e3c-9b2a-1d0f8e7c6b33", tom.ID, "Wrong group"),
+		second,
+		item("6F1C1A52-3F7E-4C0B-9D4E-2B8F0A1E7C11", rex.ID, "Same comment, upper case"),
+	)
+	statuses := make([]string, len(results))
+	for i, r := range results {
+		statuses[i] = r.Status
+		assert.Equal(t, i, r.Index)
+	}
+	assert.Equal(t, []string{CommentBatchCreated, CommentBatchError, CommentBatchError, CommentBatchCreated, CommentBatchError}, statuses)
+	require.NotNil(t, results[3].Comment)
+	require.Len(t, results[3].Comment.Tags, 1)
+	assert.Equal(t, "walk", results[3].Comment.Tags[0].Name)
+	assert.Equal(t, "Animal not found", results[2].Error)
+
+	// Resending after a lost response saves nothing new
+	results = submit(user.ID, first, second)
+	assert.Equal(t, CommentBatchDuplicate, results[0].Status)
+	assert.Equal(t, CommentBatchDuplicate, results[1].Status)
+	var count int64
+	require.NoError(t, db.Model(&models.AnimalComment{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	// Another author can't reuse a client_id, and a deleted comment isn't revived
+	assert.Equal(t, CommentBatchConflict, submit(other.ID, first)[0].Status)
+	require.NoError(t, db.Delete(&models.AnimalComment{}, results[1].Comment.ID).Error)
+	assert.Equal(t, CommentBatchConflict, submit(user.ID, second)[0].Status)
+
+	c, w := accountTestContext(user.ID, false, http.MethodPost, "/", CommentBatchRequest{})
+	c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}}
+	CreateAnimalCommentBatch(db, &embedding.StubEmbedder{})(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	c, w = accountTestContext(user.ID, false, http.MethodPost, "/", CommentBatchRequest{Comments: []CommentBatchItem{first}})
+	c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(otherGroup.ID)}}
+	CreateAnimalCommentBatch(db, &embedding.StubEmbedder{})(c)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestSyncAnimalComments(t *testing.T) {
+	db := SetupTestDB(t)
+	user := CreateTestUser(t, db, "volunteer", "volunteer@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "Dog group")
+	otherGroup := CreateTestGroup(t, db, "Cats", "Cat group")
+	AddUserToGroupWithAdmin(t, db, user.ID, group.ID, false)
+	rex := CreateTestAnimal(t, db, group.ID, "Rex", "Dog")
+	tom := CreateTestAnimal(t, db, otherGroup.ID, "Tom", "Cat")
+
+	comment := func(animalID uint, content string) models.AnimalComment {
+		cm := models.AnimalComment{AnimalID: animalID, UserID: user.ID, Content: content}
+		require.NoError(t, db.Create(&cm).Error)
+		return cm
+	}
+	a := comment(rex.ID, "A")
+	b := comment(rex.ID, "B")
+	comment(tom.ID, "Other group")
+
+	sync := func(query string) CommentSyncPage {
+		c, w := accountTestContext(user.ID, false, http.MethodGet, "/?"+query, nil)
+		c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}}
+		SyncAnimalComments(db)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var page CommentSyncPage
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		return page
+	}
+	ids := func(comments []models.AnimalComment) []uint {
+		out := []uint{}
+		for _, cm := range comments {
+			out = append(out, cm.ID)
+		}
+		return out
+	}
+
+	page := sync("limit=1")
+	assert.Equal(t, []uint{a.ID}, ids(page.Comments))
+	assert.True(t, page.HasMore)
+	require.NotNil(t, page.NextCursor)
+	page = sync("limit=1&since=" + *page.NextCursor)
+	assert.Equal(t, []uint{b.ID}, ids(page.Comments))
+	require.NotNil(t, page.NextCursor)
+	cursor := *page.NextCursor
+
+	page = sync("since=" + cursor)
+	assert.Empty(t, page.Comments)
+	assert.False(t, page.HasMore)
+	assert.Equal(t, cursor, *page.NextCursor, "with nothing new the cursor stays put")
+
+	require.NoError(t, db.Model(&a).Update("content", "A, edited").Error)
+	require.NoError(t, db.Delete(&b).Error)
+	c := comment(rex.ID, "C")
+	page = sync("since=" + cursor)
+	assert.Equal(t, []uint{a.ID, c.ID}, ids(page.Comments))
+	assert.Equal(t, "A, edited", page.Comments[0].Content)
+	assert.Equal(t, []uint{b.ID}, page.DeletedIDs)
+
+	ctx, w := accountTestContext(user.ID, false, http.MethodGet, "/?since=garbage", nil)
+	ctx.Params = gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}}
+	SyncAnimalComments(db)(ctx)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -11,8 +11,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/maintenance"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -45,7 +47,9 @@ func setupAnimalCommentTestDB(t *testing.T) *gorm.DB {
 		&models.Group{},
 		&models.Animal{},
 		&models.AnimalComment{},
+		&models.CommentReaction{},
 		&models.CommentTag{},
+		&models.UserQualification{},
 	)
 	if err != nil {
 		t.Fatalf("Failed to migrate database: %v", err)
@@ -553,3 +557,85 @@ func TestGetGroupLatestComments(t *testing.T) {
 		})
 	}
 }
+
+func TestRestoreAnimalComment(t *testing.T) {
+	db := SetupTestDB(t)
+	groupAdmin := CreateTestUser(t, db, "groupadmin", "groupadmin@example.com", "password123", false)
+	author := CreateTestUser(t, db, "author", "author@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	AddUserToGroupWithAdmin(t, db, groupAdmin.ID, group.ID, true)
+	AddUserToGroupWithAdmin(t, db, author.ID, group.ID, false)
+	animal := CreateTestAnimal(t, db, group.ID, "Rex", "Dog")
+	other := CreateTestAnimal(t, db, group.ID, "Fido", "Dog")
+	comment := models.AnimalComment{AnimalID: animal.ID, UserID: author.ID, Content: "Good walk"}
+	require.NoError(t, db.Create(&comment).Error)
+	otherComment := models.AnimalComment{AnimalID: other.ID, UserID: author.ID, Content: "Nap time"}
+	require.NoError(t, db.Create(&otherComment).Error)
+	require.NoError(t, db.Delete(&comment).Error)
+	require.NoError(t, db.Delete(&otherComment).Error)
+
+	params := gin.Params{
+		{Key: "id", Value: fmt.Sprint(group.ID)},
+		{Key: "animalId", Value: fmt.Sprint(animal.ID)},
+		{Key: "commentId", Value: fmt.Sprint(comment.ID)},
+	}
+	restore := func(userID uint) *httptest.ResponseRecorder {
+		c, w := accountTestContext(userID, false, http.MethodPost, "/", nil)
+		c.Params = params
+		RestoreAnimalComment(db)(c)
+		return w
+	}
+
+	// The per-animal listing only shows that animal's comments
+	c, w := accountTestContext(groupAdmin.ID, false, http.MethodGet, "/", nil)
+	c.Params = params[:2]
+	GetDeletedComments(db)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var deleted []struct {
+		ID         uint      `json:"id"`
+		DeletedAt  time.Time `json:"deleted_at"`
+		PurgeAfter time.Time `json:"purge_after"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &deleted))
+	require.Len(t, deleted, 1)
+	assert.Equal(t, comment.ID, deleted[0].ID)
+	assert.Equal(t, 30*24*time.Hour, deleted[0].PurgeAfter.Sub(deleted[0].DeletedAt))
+
+	assert.Equal(t, http.StatusForbidden, restore(author.ID).Code, "only group admins can restore")
+	require.Equal(t, http.StatusOK, restore(groupAdmin.ID).Code)
+	assert.Equal(t, http.StatusConflict, restore(groupAdmin.ID).Code)
+
+	var restored models.AnimalComment
+	require.NoError(t, db.First(&restored, comment.ID).Error, "restored comment is visible to normal queries")
+}
+
+func TestPurgeDeletedComments(t *testing.T) {
+	db := SetupTestDB(t)
+	user := CreateTestUser(t, db, "author", "author@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	animal := CreateTestAnimal(t, db, group.ID, "Rex", "Dog")
+
+	old := models.AnimalComment{AnimalID: animal.ID, UserID: user.ID, Content: "Old"}
+	recent := models.AnimalComment{AnimalID: animal.ID, UserID: user.ID, Content: "Recent"}
+	live := models.AnimalComment{AnimalID: animal.ID, UserID: user.ID, Content: "Live"}
+	for _, comment := range []*models.AnimalComment{&old, &recent, &live} {
+		require.NoError(t, db.Create(comment).Error)
+	}
+	require.NoError(t, db.Create(&models.CommentReaction{CommentID: old.ID, UserID: user.ID, Type: models.ReactionHeart}).Error)
+	require.NoError(t, db.Create(&models.CommentHistory{CommentID: old.ID, Content: "Older", EditedBy: user.ID}).Error)
+	require.NoError(t, db.Model(&old).Update("deleted_at", time.Now().Add(-31*24*time.Hour)).Error)
+	require.NoError(t, db.Delete(&recent).Error)
+
+	purged, err := maintenance.PurgeDeletedComments(db, 30*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	var remaining []uint
+	require.NoError(t, db.Unscoped().Model(&models.AnimalComment{}).Order("id").Pluck("id", &remaining).Error)
+	assert.Equal(t, []uint{recent.ID, live.ID}, remaining)
+	var dependents int64
+	db.Model(&models.CommentReaction{}).Where("comment_id = ?", old.ID).Count(&dependents)
+	assert.Zero(t, dependents)
+	db.Model(&models.CommentHistory{}).Where("comment_id = ?", old.ID).Count(&dependents)
+	assert.Zero(t, dependents)
+}
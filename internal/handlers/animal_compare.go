@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	minCompareAnimals = 2
+	maxCompareAnimals = 6
+
+	// compareRecentDays is the window for the "recent" activity counts
+	compareRecentDays = 30
+)
+
+// CompareTag is a tag on a compared animal
+type CompareTag struct {
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Color    string `json:"color"`
+}
+
+// CompareActivity counts how much attention a compared animal has had
+type CompareActivity struct {
+	Comments       int64      `json:"comments"`
+	RecentComments int64      `json:"recent_comments"` // In the last 30 days
+	LastCommentAt  *time.Time `json:"last_comment_at"`
+	RecentViews    int64      `json:"recent_views"` // Detail page visits in the last 30 days
+	Photos         int64      `json:"photos"`
+}
+
+// CompareBehavior is a compared animal's latest behavior assessment
+type CompareBehavior struct {
+	Score      int       `json:"score"`
+	AssessedAt time.Time `json:"assessed_at"`
+}
+
+// AnimalComparison is one column of the comparison grid. Values are
+// normalized so columns line up: ages in months, weights in pounds, and
+// durations in whole days.
+type AnimalComparison struct {
+	ID            uint                      `json:"id"`
+	Name          string                    `json:"name"`
+	Species       string                    `json:"species"`
+	Breed         string                    `json:"breed"`
+	Status        string                    `json:"status"`
+	ImageURL      string                    `json:"image_url"`
+	AgeMonths     *int                      `json:"age_months"` // nil when neither an age nor a birth date is recorded
+	WeightLB      *float64                  `json:"weight_lb"`
+	DaysInShelter *int                      `json:"days_in_shelter"` // Since arrival_date
+	DaysInStatus  *int                      `json:"days_in_status"`  // Since last_status_change
+	IsReturned    bool                      `json:"is_returned"`
+	Tags          []CompareTag              `json:"tags"`
+	CustomFields  models.AnimalCustomValues `json:"custom_fields"`
+	Behavior      *CompareBehavior          `json:"behavior_assessment"`
+	Activity      CompareActivity           `json:"activity"`
+}
+
+// parseCompareIDs parses ?ids=1,2,3 into distinct animal IDs
+func parseCompareIDs(value string) ([]uint, bool) {
+	var ids []uint
+	seen := make(map[uint]bool)
+	for _, part := range strings.Split(value, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32)
+		if err != nil || id == 0 {
+			return nil, false
+		}
+		if !seen[uint(id)] {
+			seen[uint(id)] = true
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids, len(ids) >= minCompareAnimals && len(ids) <= maxCompareAnimals
+}
+
+// wholeDaysSince returns whole days from t to now, or nil if t is nil
+func wholeDaysSince(t *time.Time, now time.Time) *int {
+	if t == nil {
+		return nil
+	}
+	days := int(now.Sub(*t).Hours() / 24)
+	if days < 0 {
+		days = 0
+	}
+	return &days
+}
+
+// loadCompareActivity fills in comment, view, and photo counts for animals
+func loadCompareActivity(db *gorm.DB, ids []uint, since time.Time) (map[uint]*CompareActivity, error) {
+	activity := make(map[uint]*CompareActivity, len(ids))
+	for _, id := range ids {
+		activity[id] = &CompareActivity{}
+	}
+
+	// MAX(created_at) comes back from SQLite as text; see parseTimestamp
+	var comments []struct {
+		AnimalID      uint
+		Total         int64
+		Recent        int64
+		LastCommentAt *string
+	}
+	if err := db.Model(&models.AnimalComment{}).
+		Select("animal_id, COUNT(*) AS total, SUM(CASE WHEN created_at >= ? THEN 1 ELSE 0 END) AS recent, MAX(created_at) AS last_comment_at", since).
+		Where("animal_id IN ?", ids).Group("animal_id").Scan(&comments).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range comments {
+		a := activity[row.AnimalID]
+		a.Comments, a.RecentComments = row.Total, row.Recent
+		if row.LastCommentAt != nil {
+			a.LastCommentAt = parseTimestamp(*row.LastCommentAt)
+		}
+	}
+
+	type count struct {
+		AnimalID uint
+		Total    int64
+	}
+	var views []count
+	if err := db.Model(&models.AnimalView{}).Select("animal_id, COUNT(*) AS total").
+		Where("animal_id IN ? AND created_at >= ?", ids, since).Group("animal_id").Scan(&views).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range views {
+		activity[row.AnimalID].RecentViews = row.Total
+	}
+
+	var photos []count
+	if err := db.Model(&models.AnimalImage{}).Select("animal_id, COUNT(*) AS total").
+		Where("animal_id IN ? AND moderation_status = ?", ids, models.ImageModerationApproved).
+		Group("animal_id").Scan(&photos).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range photos {
+		activity[row.AnimalID].Photos = row.Total
+	}
+	return activity, nil
+}
+
+// CompareAnimals returns 2 to 6 of a group's animals side by side, with
+// normalized attributes, tags, latest behavior assessment score, and
+// activity counts, in the order the IDs were given
+// Route: GET /api/groups/:id/animals/compare?ids=1,2,3
+func CompareAnimals(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, c.Param("id")) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+
+		ids, ok := parseCompareIDs(c.Query("ids"))
+		if !ok {
+			respondBadRequest(c, "ids must list 2 to 6 animal IDs, separated by commas")
+			return
+		}
+
+		var animals []models.Animal
+		query := db.Preload("Tags").Where("id IN ? AND group_id = ?", ids, c.Param("id"))
+		if err := visibleAnimals(c, db, query, c.Param("id")).Find(&animals).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to fetch animals to compare", err)
+			respondInternalError(c, "Failed to compare animals")
+			return
+		}
+		if len(animals) != len(ids) {
+			respondError(c, http.StatusNotFound, ErrCodeAnimalNotFound, "Animal not found")
+			return
+		}
+		byID := make(map[uint]models.Animal, len(animals))
+		for _, a := range animals {
+			byID[a.ID] = a
+		}
+
+		now := time.Now()
+		behavior, err := latestBehaviorAssessments(db, ids)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to fetch behavior assessments", err)
+			respondInternalError(c, "Failed to compare animals")
+			return
+		}
+		activity, err := loadCompareActivity(db, ids, now.AddDate(0, 0, -compareRecentDays))
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to fetch animal activity", err)
+			respondInternalError(c, "Failed to compare animals")
+			return
+		}
+
+		policy, err := loadFieldPolicy(c, db, animals[0].GroupID)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to load field visibility", err)
+			respondInternalError(c, "Failed to compare animals")
+			return
+		}
+
+		result := make([]AnimalComparison, 0, len(ids))
+		for _, id := range ids {
+			animal := byID[id]
+			item := AnimalComparison{
+				ID:            animal.ID,
+				Name:          animal.Name,
+				Species:       animal.Species,
+				Breed:         animal.Breed,
+				Status:        animal.Status,
+				ImageURL:      animal.ImageURL,
+				DaysInShelter: wholeDaysSince(animal.ArrivalDate, now),
+				DaysInStatus:  wholeDaysSince(animal.LastStatusChange, now),
+				IsReturned:    animal.IsReturned,
+				Tags:          make([]CompareTag, len(animal.Tags)),
+				CustomFields:  policy.CustomFields(animal.CustomFields),
+				Activity:      *activity[id],
+			}
+			if animal.EstimatedBirthDate != nil || animal.Age > 0 {
+				months := animal.AgeYears*12 + animal.AgeMonths
+				item.AgeMonths = &months
+			}
+			for i, tag := range animal.Tags {
+				item.Tags[i] = CompareTag{Name: tag.Name, Category: tag.Category, Color: tag.Color}
+			}
+			weight, err := latestWeightEntry(db, id)
+			if err != nil {
+				middleware.GetLogger(c).Error("Failed to fetch animal weight", err)
+				respondInternalError(c, "Failed to compare animals")
+				return
+			}
+			if weight != nil && !policy.Hides("current_weight") {
+				lb := convertWeight(weight.Weight, weight.Unit, models.WeightUnitLB)
+				item.WeightLB = &lb
+			}
+			if b, ok := behavior[id]; ok && !policy.Hides("behavior_assessments") {
+				item.Behavior = &CompareBehavior{Score: b.Score, AssessedAt: b.AssessedAt}
+			}
+			result = append(result, item)
+		}
+
+		respondOK(c, gin.H{"animals": result})
+	}
+}
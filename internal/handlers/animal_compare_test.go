@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBehaviorAssessments(t *testing.T) {
+	db := SetupTestDB(t)
+	lead := CreateTestUser(t, db, "lead", "lead@example.com", "password123", false)
+	volunteer := CreateTestUser(t, db, "volunteer", "volunteer@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "Dog group")
+	AddUserToGroupWithAdmin(t, db, lead.ID, group.ID, true)
+	AddUserToGroupWithAdmin(t, db, volunteer.ID, group.ID, false)
+	animal := CreateTestAnimal(t, db, group.ID, "Buddy", "Dog")
+
+	record := func(userID uint, body gin.H) int {
+		c, w := accountTestContext(userID, false, http.MethodPost, "/api/groups/1/animals/1/behavior-assessments", body)
+		c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}, {Key: "animalId", Value: fmt.Sprint(animal.ID)}}
+		CreateBehaviorAssessment(db)(c)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusForbidden, record(volunteer.ID, gin.H{"score": 4}))
+	assert.Equal(t, http.StatusBadRequest, record(lead.ID, gin.H{"score": 6}))
+	assert.Equal(t, http.StatusBadRequest, record(lead.ID, gin.H{"score": 3, "assessed_at": "2999-01-01"}))
+	assert.Equal(t, http.StatusCreated, record(lead.ID, gin.H{"score": 2, "assessed_at": "2026-01-10"}))
+	assert.Equal(t, http.StatusCreated, record(lead.ID, gin.H{"score": 4, "assessed_at": "2026-03-02", "notes": "calmer on leash"}))
+
+	c, w := accountTestContext(volunteer.ID, false, http.MethodGet, "/api/groups/1/animals/1/behavior-assessments", nil)
+	c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}, {Key: "animalId", Value: fmt.Sprint(animal.ID)}}
+	GetBehaviorAssessments(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var assessments []models.BehaviorAssessment
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &assessments))
+	require.Len(t, assessments, 2)
+	assert.Equal(t, 4, assessments[0].Score)
+	assert.Equal(t, "calmer on leash", assessments[0].Notes)
+	require.NotNil(t, assessments[0].AssessedBy)
+	assert.Equal(t, "lead", assessments[0].AssessedBy.Username)
+}
+
+func TestCompareAnimals(t *testing.T) {
+	db := SetupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AnimalImage{}))
+	user := CreateTestUser(t, db, "counselor", "counselor@example.com", "password123", false)
+	dogs := CreateTestGroup(t, db, "Dogs", "Dog group")
+	cats := CreateTestGroup(t, db, "Cats", "Cat group")
+	AddUserToGroupWithAdmin(t, db, user.ID, dogs.ID, false)
+	buddy := CreateTestAnimal(t, db, dogs.ID, "Buddy", "Dog")
+	rex := CreateTestAnimal(t, db, dogs.ID, "Rex", "Dog")
+	whiskers := CreateTestAnimal(t, db, cats.ID, "Whiskers", "Cat")
+
+	now := time.Now()
+	arrived := now.AddDate(0, 0, -45)
+	birth := now.AddDate(-2, -3, -1)
+	require.NoError(t, db.Model(buddy).Updates(map[string]interface{}{"arrival_date": arrived, "estimated_birth_date": birth}).Error)
+	tag := models.AnimalTag{GroupID: dogs.ID, Name: "dog-friendly", Category: "behavior", Color: "#10b981"}
+	require.NoError(t, db.Create(&tag).Error)
+	require.NoError(t, db.Model(buddy).Association("Tags").Append(&tag))
+	require.NoError(t, db.Create(&models.WeightEntry{AnimalID: buddy.ID, Weight: 20, Unit: models.WeightUnitKG, RecordedAt: now, RecordedByID: user.ID}).Error)
+	require.NoError(t, db.Create(&models.BehaviorAssessment{AnimalID: buddy.ID, Score: 2, AssessedAt: now.AddDate(0, -2, 0), AssessedByID: user.ID}).Error)
+	require.NoError(t, db.Create(&models.BehaviorAssessment{AnimalID: buddy.ID, Score: 5, AssessedAt: now.AddDate(0, 0, -1), AssessedByID: user.ID}).Error)
+	require.NoError(t, db.Create(&models.AnimalComment{AnimalID: buddy.ID, UserID: user.ID, Content: "old", CreatedAt: now.AddDate(0, 0, -90)}).Error)
+	require.NoError(t, db.Create(&models.AnimalComment{AnimalID: buddy.ID, UserID: user.ID, Content: "new"}).Error)
+	require.NoError(t, db.Create(&models.AnimalView{AnimalID: rex.ID, UserID: user.ID}).Error)
+	animalID := buddy.ID
+	require.NoError(t, db.Create(&models.AnimalImage{AnimalID: &animalID, UserID: user.ID, ImageURL: "/api/images/1"}).Error)
+	require.NoError(t, db.Create(&models.AnimalImage{AnimalID: &animalID, UserID: user.ID, ImageURL: "/api/images/2", ModerationStatus: models.ImageModerationPending}).Error)
+
+	compare := func(ids string) (int, []AnimalComparison) {
+		c, w := accountTestContext(user.ID, false, http.MethodGet, "/api/groups/1/animals/compare?ids="+ids, nil)
+		c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(dogs.ID)}}
+		CompareAnimals(db)(c)
+		var resp struct {
+			Animals []AnimalComparison `json:"animals"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp.Animals
+	}
+
+	code, animals := compare(fmt.Sprintf("%d,%d", rex.ID, buddy.ID))
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, animals, 2)
+	assert.Equal(t, "Rex", animals[0].Name, "animals come back in the order requested")
+	assert.Equal(t, int64(1), animals[0].Activity.RecentViews)
+	assert.Nil(t, animals[0].Behavior)
+	assert.Nil(t, animals[0].WeightLB)
+
+	b := animals[1]
+	require.NotNil(t, b.AgeMonths)
+	assert.Equal(t, 27, *b.AgeMonths)
+	require.NotNil(t, b.WeightLB)
+	assert.Equal(t, 44.09, *b.WeightLB)
+	require.NotNil(t, b.DaysInShelter)
+	assert.Equal(t, 45, *b.DaysInShelter)
+	assert.Equal(t, []CompareTag{{Name: "dog-friendly", Category: "behavior", Color: "#10b981"}}, b.Tags)
+	require.NotNil(t, b.Behavior)
+	assert.Equal(t, 5, b.Behavior.Score)
+	assert.Equal(t, int64(2), b.Activity.Comments)
+	assert.Equal(t, int64(1), b.Activity.RecentComments)
+	assert.NotNil(t, b.Activity.LastCommentAt)
+	assert.Equal(t, int64(1), b.Activity.Photos)
+
+	code, _ = compare(fmt.Sprint(buddy.ID))
+	assert.Equal(t, http.StatusBadRequest, code, "one animal isn't a comparison")
+	code, _ = compare("1,x")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = compare(fmt.Sprintf("%d,%d", buddy.ID, whiskers.ID))
+	assert.Equal(t, http.StatusNotFound, code, "animals from another group aren't compared")
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/database"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
@@ -18,22 +19,13 @@ import (
 	"gorm.io/gorm"
 )
 
-// escapeSQLWildcards escapes SQL wildcard characters (%, _) in user input
-// to prevent unintended pattern matching in LIKE queries
-func escapeSQLWildcards(input string) string {
-	// Escape backslash first to prevent double-escaping
-	result := strings.ReplaceAll(input, "\\", "\\\\")
-	// Escape SQL wildcard characters
-	result = strings.ReplaceAll(result, "%", "\\%")
-	result = strings.ReplaceAll(result, "_", "\\_")
-	return result
-}
-
-// animalWithCounts extends Animal with photo/video counts for the list endpoint.
+// animalWithCounts extends Animal with photo/video counts and the group's
+// status alerts for the list endpoint.
 type animalWithCounts struct {
 	models.Animal
-	ImageCount int `json:"image_count"`
-	VideoCount int `json:"video_count"`
+	ImageCount int           `json:"image_count"`
+	VideoCount int           `json:"video_count"`
+	Alerts     []StatusAlert `json:"alerts"`
 }
 
 // buildQuarantineEmail returns the subject and body for a bite-quarantine
@@ -56,7 +48,7 @@ func buildQuarantineEmail(animal *models.Animal) (string, string) {
 	return title, body
 }
 
-// sendQuarantineNotificationEmail asynchronously emails group members about a
+// sendQuarantineNotificationEmail queues emails to group members about a
 // new bite-quarantine incident. It is a no-op when the email service is
 // unavailable or there are no incident details to report.
 func sendQuarantineNotificationEmail(db *gorm.DB, emailService *email.Service, animal *models.Animal) {
@@ -67,16 +59,22 @@ func sendQuarantineNotificationEmail(db *gorm.DB, emailService *email.Service, a
 		return
 	}
 	title, content := buildQuarantineEmail(animal)
-	groupID := animal.GroupID
-	go func() {
-		bgCtx := context.Background()
-		if err := sendGroupAnnouncementEmails(bgCtx, db, emailService, groupID, title, content); err != nil {
-			logging.WithContext(bgCtx).Error("Error sending bite quarantine notification emails", err)
-		}
-	}()
+	ctx := context.Background()
+	if _, err := enqueueAnnouncementEmails(ctx, db, []uint{animal.GroupID}, title, content); err != nil {
+		logging.WithContext(ctx).Error("Error queueing bite quarantine notification emails", err)
+	}
 }
 
-// GetAnimals returns all animals in a group with optional filtering
+// GetAnimals returns all animals in a group with optional filtering, sorting,
+// and paging. With no filters given, the user's default saved filter for the
+// group applies; the X-Saved-Filter-Id header names the saved filter used, if
+// any. Without a status filter, the group's default status filter applies.
+// Without ?sort= the group's default sort applies, or animals are listed in
+// the order they were added. size and energy_level each take several
+// values; with ?facets=true the animals come wrapped as
+// {"animals": [...], "facets": AnimalFacetCounts}.
+// Animals with a restricted tag are left out for members without a matching
+// qualification, and fields above the viewer's group role are omitted.
 func GetAnimals(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		db := middleware.GetDB(c, db)
@@ -86,18 +84,36 @@ func GetAnimals(db *gorm.DB) gin.HandlerFunc {
 
 		// Check access
 		if !checkGroupAccess(db, userID, isAdmin, groupID) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
 			return
 		}
 
+		params, savedFilter, ok := animalListParams(c, db)
+		if !ok {
+			return
+		}
+		if savedFilter != nil {
+			c.Header("X-Saved-Filter-Id", strconv.FormatUint(uint64(savedFilter.ID), 10))
+		}
+
+		// The group's display settings choose the status filter and sort
+		// when the request doesn't
+		listQuery := c.Request.URL.Query()
+		var group models.Group
+		if err := db.Select("id", "default_status_filter", "default_sort", "default_sort_order").First(&group, groupID).Error; err != nil {
+			// Best-effort: fall back to the app's defaults
+			middleware.GetLogger(c).Error("Failed to load group display settings", err)
+		}
+		applyGroupListDefaults(group, params, listQuery)
+
 		// Build query with filters
-		query := db.Where("group_id = ?", groupID)
+		query := visibleAnimals(c, db, db.Where("group_id = ?", groupID), groupID)
 
 		// Status filter (default to "available", "bite_quarantine", and "under_vet_care" if not specified)
-		status := c.Query("status")
+		status := params.Get("status")
 		if status == "" {
 			// Default: show available, bite_quarantine, and under_vet_care animals
-			query = query.Where("status IN ?", []string{"available", "bite_quarantine", "under_vet_care"})
+			query = query.Where("status IN ?", defaultAnimalStatuses)
 		} else if status != "all" {
 			// Support comma-separated statuses for multiple filters
 			if strings.Contains(status, ",") {
@@ -109,16 +125,48 @@ func GetAnimals(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		// Name search filter
-		nameSearch := c.Query("name")
+		nameSearch := params.Get("name")
 		if nameSearch != "" {
-			// Escape SQL wildcards to prevent unintended pattern matching
-			escaped := escapeSQLWildcards(strings.ToLower(nameSearch))
-			query = query.Where("LOWER(name) LIKE ?", "%"+escaped+"%")
+			query = query.Where(database.DialectOf(db).ContainsFold("name", nameSearch))
+		}
+
+		// Custom field filters: field.<key>=<value>
+		query, err := applyCustomFieldFilters(params, db, query)
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+
+		// Facet filters: size=small,medium&energy_level=low
+		facetFilters, err := animalFacetFilters(params)
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		var facets AnimalFacetCounts
+		if withFacets, _ := strconv.ParseBool(c.Query("facets")); withFacets {
+			if facets, err = countAnimalFacets(query.Session(&gorm.Session{}), facetFilters); err != nil {
+				respondInternalError(c, "Failed to count animals")
+				return
+			}
+		}
+		query = applyAnimalFacetFilters(query, facetFilters, "")
+
+		// Sorting and paging come from the request even when a saved filter
+		// supplies the filters
+		query, err = applyAnimalSort(listQuery, query.Model(&models.Animal{}))
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		query, ok = applyAnimalPage(c, listQuery, query, query.Session(&gorm.Session{}))
+		if !ok {
+			return
 		}
 
 		var baseAnimals []models.Animal
 		if err := query.Preload("Tags").Find(&baseAnimals).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch animals"})
+			respondInternalError(c, "Failed to fetch animals")
 			return
 		}
 
@@ -152,20 +200,40 @@ func GetAnimals(db *gorm.DB) gin.HandlerFunc {
 			countMap[cr.AnimalID] = cr
 		}
 
+		// Best-effort: the list still renders without its alerts
+		alerts, err := statusAlertsFor(db, group.ID, baseAnimals, time.Now())
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to evaluate status alerts", err)
+		}
+
 		animals := make([]animalWithCounts, len(baseAnimals))
 		for i, a := range baseAnimals {
 			animals[i] = animalWithCounts{
 				Animal:     a,
 				ImageCount: countMap[a.ID].ImageCount,
 				VideoCount: countMap[a.ID].VideoCount,
+				Alerts:     alerts[a.ID],
+			}
+			if animals[i].Alerts == nil {
+				animals[i].Alerts = []StatusAlert{}
 			}
 		}
 
-		c.JSON(http.StatusOK, animals)
+		if facets == nil {
+			respondRedacted(c, db, group.ID, animals)
+			return
+		}
+		redacted, ok := redactForViewer(c, db, group.ID, animals)
+		if !ok {
+			return
+		}
+		respondOK(c, gin.H{"animals": redacted, "facets": facets})
 	}
 }
 
-// GetAnimal returns a specific animal by ID
+// GetAnimal returns a specific animal by ID. Animals with a restricted tag
+// are not found for members without a matching qualification, and fields
+// above the viewer's group role are omitted.
 func GetAnimal(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		db := middleware.GetDB(c, db)
@@ -176,17 +244,43 @@ func GetAnimal(db *gorm.DB) gin.HandlerFunc {
 
 		// Check access
 		if !checkGroupAccess(db, userID, isAdmin, groupID) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
 			return
 		}
 
 		var animal models.Animal
-		if err := db.Preload("Tags").Preload("NameHistory").Preload("Scripts").Preload("BQIncidents", "end_date IS NOT NULL").Where("id = ? AND group_id = ?", animalID, groupID).First(&animal).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Animal not found"})
+		query := visibleAnimals(c, db, db.Where("id = ? AND group_id = ?", animalID, groupID), groupID)
+		if err := query.Preload("Tags").Preload("NameHistory").Preload("Scripts").Preload("BQIncidents", "end_date IS NOT NULL").First(&animal).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeAnimalNotFound, "Animal not found")
 			return
 		}
 
-		c.JSON(http.StatusOK, animal)
+		current, err := latestWeightEntry(db, animal.ID)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to fetch current weight", err)
+		}
+		animal.CurrentWeight = current
+
+		tagCounts, err := commentTagCounts(db, animal.ID)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to count comment tags", err)
+		}
+		animal.CommentTagCounts = tagCounts
+
+		if animal.PinnedComments, err = pinnedComments(db, animal.ID); err != nil {
+			middleware.GetLogger(c).Error("Failed to fetch pinned comments", err)
+		}
+		if animal.RelatedAnimals, err = visibleRelatedAnimals(c, db, animal); err != nil {
+			middleware.GetLogger(c).Error("Failed to fetch related animals", err)
+		}
+
+		if uid, ok := middleware.GetUserID(c); ok {
+			if err := recordAnimalView(db, animal.ID, uid, time.Now()); err != nil {
+				middleware.GetLogger(c).Error("Failed to record animal view", err)
+			}
+		}
+
+		respondRedacted(c, db, animal.GroupID, animal)
 	}
 }
 
@@ -206,23 +300,35 @@ func CreateAnimal(db *gorm.DB, emailService *email.Service, embedder embedding.E
 
 		// Check for group admin or site admin access
 		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
 			return
 		}
 
 		var req AnimalRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": formatValidationError(err)})
+			respondValidationError(c, err)
 			return
 		}
 		if !isValidApprovalStatus(req.QuarantineApprovalStatus) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quarantine_approval_status: must be '', 'requested', or 'granted'"})
+			respondBadRequest(c, "invalid quarantine_approval_status: must be '', 'requested', or 'granted'")
 			return
 		}
 
 		gid, err := strconv.ParseUint(groupID, 10, 32)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+
+		statusDef, ok := validateAnimalStatusForGroup(c, db, uint(gid), req.Status)
+		if !ok {
+			return
+		}
+		req.localizeDates(groupLocationByID(c.Request.Context(), db, uint(gid)))
+		if req.Breed, ok = normalizeAnimalBreed(c, db, req.Species, req.Breed); !ok {
+			return
+		}
+		if !checkRequiredAnimalFields(c, db, uint(gid), req) {
 			return
 		}
 
@@ -248,9 +354,13 @@ func CreateAnimal(db *gorm.DB, emailService *email.Service, embedder embedding.E
 			LastStatusChange: &now,
 		}
 
-		// Set estimated birth date if provided
-		if req.EstimatedBirthDate.Valid && req.EstimatedBirthDate.Time != nil {
-			animal.EstimatedBirthDate = req.EstimatedBirthDate.Time
+		birthDate, err := resolveBirthDate(req, nil, now)
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		if birthDate != nil {
+			animal.EstimatedBirthDate = birthDate
 			// Auto-compute Age (whole years) from birth date for backward compatibility
 			animal.Age = animal.AgeYearsFromBirthDate()
 		}
@@ -266,7 +376,7 @@ func CreateAnimal(db *gorm.DB, emailService *email.Service, embedder embedding.E
 		case "bite_quarantine":
 			startDate, endDate, err := resolveNewQuarantineDates(now, req)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				respondBadRequest(c, err.Error())
 				return
 			}
 			animal.QuarantineStartDate = &startDate
@@ -283,14 +393,64 @@ func CreateAnimal(db *gorm.DB, emailService *email.Service, embedder embedding.E
 			animal.ArchivedDate = &now
 		case "under_vet_care":
 			// No dedicated date field for vet care; LastStatusChange (set elsewhere) is sufficient.
+		default:
+			// Group-configured status: stamp whichever date field it drives.
+			applyCustomStatus(&animal, statusDef, now)
 		}
 
 		if req.IsReturned != nil {
 			animal.IsReturned = *req.IsReturned
 		}
+		if req.IntakeSource != nil {
+			if !validIntakeSource(*req.IntakeSource) {
+				respondBadRequest(c, "intake_source must be one of: "+strings.Join(models.IntakeSources, ", "))
+				return
+			}
+			animal.IntakeSource = *req.IntakeSource
+		}
+		if err := validateAnimalTraits(req.Size, req.EnergyLevel); err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		if req.Size != nil {
+			animal.Size = *req.Size
+		}
+		if req.EnergyLevel != nil {
+			animal.EnergyLevel = *req.EnergyLevel
+		}
+		animal.Outcome, animal.OutcomeDate, err = resolveOutcome(models.Animal{}, animal.Status, req.Outcome, now)
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		if animal.MicrochipNumber, animal.LicenseNumber, ok = resolveRegistryNumbers(c, db, animal, req.MicrochipNumber, req.LicenseNumber); !ok {
+			return
+		}
+		if !checkAnimalName(c, db, animal.GroupID, animal.Name, animal.Status, 0) {
+			return
+		}
+
+		if animal.CustomFields, ok = resolveCustomFields(c, db, animal.GroupID, nil, req.CustomFields, true); !ok {
+			return
+		}
+
+		// Volunteers sometimes add an animal that's already on file; make them
+		// confirm with force=true before creating a likely duplicate
+		if force, _ := strconv.ParseBool(c.Query("force")); !force {
+			candidates, err := findDuplicateCandidates(db, &animal)
+			if err != nil {
+				middleware.GetLogger(c).Error("Failed to check for duplicate animals", err)
+				respondInternalError(c, "Failed to check for duplicates")
+				return
+			}
+			if len(candidates) > 0 {
+				respondPossibleDuplicates(c, candidates)
+				return
+			}
+		}
 
 		if err := db.Create(&animal).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create animal"})
+			respondInternalError(c, "Failed to create animal")
 			return
 		}
 
@@ -347,31 +507,96 @@ func UpdateAnimal(db *gorm.DB, emailService *email.Service, embedder embedding.E
 
 		// Check for group admin or site admin access
 		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
 			return
 		}
 
 		var req AnimalRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": formatValidationError(err)})
+			respondValidationError(c, err)
 			return
 		}
 		if !isValidApprovalStatus(req.QuarantineApprovalStatus) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quarantine_approval_status: must be '', 'requested', or 'granted'"})
+			respondBadRequest(c, "invalid quarantine_approval_status: must be '', 'requested', or 'granted'")
 			return
 		}
 
 		var animal models.Animal
 		if err := db.Preload("Tags").Where("id = ? AND group_id = ?", animalID, groupID).First(&animal).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Animal not found"})
+			respondError(c, http.StatusNotFound, ErrCodeAnimalNotFound, "Animal not found")
+			return
+		}
+		if animal.LegalHold && req.Status != "" && req.Status != animal.Status {
+			respondLegalHold(c, animal)
+			return
+		}
+
+		var statusDef models.AnimalStatus
+		if req.Status != "" && req.Status != animal.Status {
+			var ok bool
+			if statusDef, ok = validateAnimalStatusForGroup(c, db, animal.GroupID, req.Status); !ok {
+				return
+			}
+		}
+		req.localizeDates(groupLocationByID(c.Request.Context(), db, animal.GroupID))
+		breed, ok := normalizeAnimalBreed(c, db, req.Species, req.Breed)
+		if !ok {
 			return
 		}
+		req.Breed = breed
 
 		// Captured before any field mutations below so it can be compared
 		// against the post-save text to decide whether re-embedding is
 		// actually necessary (e.g. a pure quarantine-status/approval-status
 		// edit doesn't change the embedded text at all).
 		oldEmbeddingText := animalEmbeddingText(animal)
+		before := animal
+
+		birthDate, err := resolveBirthDate(req, animal.EstimatedBirthDate, time.Now())
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		customFields, ok := resolveCustomFields(c, db, animal.GroupID, animal.CustomFields, req.CustomFields, false)
+		if !ok {
+			return
+		}
+		if req.IntakeSource != nil && !validIntakeSource(*req.IntakeSource) {
+			respondBadRequest(c, "intake_source must be one of: "+strings.Join(models.IntakeSources, ", "))
+			return
+		}
+		if err := validateAnimalTraits(req.Size, req.EnergyLevel); err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		targetStatus := animal.Status
+		if req.Status != "" {
+			targetStatus = req.Status
+		}
+		outcome, outcomeDate, err := resolveOutcome(animal, targetStatus, req.Outcome, time.Now())
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		if !checkBondedAdoption(c, db, animal, outcome, nil) {
+			return
+		}
+		microchip, license, ok := resolveRegistryNumbers(c, db, animal, req.MicrochipNumber, req.LicenseNumber)
+		if !ok {
+			return
+		}
+		if animalNameClaimChanged(animal, req.Name, animal.GroupID, targetStatus) &&
+			!checkAnimalName(c, db, animal.GroupID, req.Name, targetStatus, animal.ID) {
+			return
+		}
+		if targetStatus != animal.Status {
+			pending := animal
+			pending.Name, pending.ImageURL = req.Name, req.ImageURL
+			pending.CustomFields, pending.MicrochipNumber = customFields, microchip
+			if !checkStatusChecklist(c, db, pending, targetStatus, req.OverrideChecklist) {
+				return
+			}
+		}
 
 		// Track name changes
 		oldName := animal.Name
@@ -379,7 +604,7 @@ func UpdateAnimal(db *gorm.DB, emailService *email.Service, embedder embedding.E
 			// Create name history record
 			changedByID, ok := middleware.GetUserID(c)
 			if !ok {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "User context not found"})
+				respondInternalError(c, "User context not found")
 				return
 			}
 			nameHistory := models.AnimalNameHistory{
@@ -387,6 +612,7 @@ func UpdateAnimal(db *gorm.DB, emailService *email.Service, embedder embedding.E
 				OldName:   oldName,
 				NewName:   req.Name,
 				ChangedBy: changedByID,
+				Source:    models.NameChangeEdit,
 			}
 			if err := db.Create(&nameHistory).Error; err != nil {
 				// Log error but don't fail the update
@@ -404,7 +630,7 @@ func UpdateAnimal(db *gorm.DB, emailService *email.Service, embedder embedding.E
 		if leftQuarantine {
 			resolvedEndDate, err := resolveBQExitEndDate(req.QuarantineEndDate, animal.QuarantineEndDate, animal.QuarantineStartDate, now)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				respondBadRequest(c, err.Error())
 				return
 			}
 			incidentEndDateAtExit = resolvedEndDate
@@ -441,7 +667,7 @@ func UpdateAnimal(db *gorm.DB, emailService *email.Service, embedder embedding.E
 			case "bite_quarantine":
 				startDate, endDate, err := resolveNewQuarantineDates(now, req)
 				if err != nil {
-					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+					respondBadRequest(c, err.Error())
 					return
 				}
 				animal.QuarantineStartDate = &startDate
@@ -473,6 +699,8 @@ func UpdateAnimal(db *gorm.DB, emailService *email.Service, embedder embedding.E
 				animal.QuarantineApprovalDate = nil
 				animal.ArchivedDate = nil
 				animal.QuarantineIncidentDetails = ""
+			default:
+				applyCustomStatus(&animal, statusDef, now)
 			}
 			animal.Status = newStatus
 		} else if animal.Status == "bite_quarantine" {
@@ -490,7 +718,7 @@ func UpdateAnimal(db *gorm.DB, emailService *email.Service, embedder embedding.E
 			// Update quarantine start/end dates independently — both fields can change in one request
 			newStart, newEnd, err := resolveQuarantineDateEdits(animal.QuarantineStartDate, req)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				respondBadRequest(c, err.Error())
 				return
 			}
 			if newStart != nil {
@@ -514,9 +742,18 @@ func UpdateAnimal(db *gorm.DB, emailService *email.Service, embedder embedding.E
 			animal.ArrivalDate = req.ArrivalDate.Time
 		}
 
-		// Update estimated birth date if provided
-		if req.EstimatedBirthDate.Valid && req.EstimatedBirthDate.Time != nil {
-			animal.EstimatedBirthDate = req.EstimatedBirthDate.Time
+		animal.EstimatedBirthDate = birthDate
+		animal.CustomFields = customFields
+		animal.Outcome, animal.OutcomeDate = outcome, outcomeDate
+		animal.MicrochipNumber, animal.LicenseNumber = microchip, license
+		if req.IntakeSource != nil {
+			animal.IntakeSource = *req.IntakeSource
+		}
+		if req.Size != nil {
+			animal.Size = *req.Size
+		}
+		if req.EnergyLevel != nil {
+			animal.EnergyLevel = *req.EnergyLevel
 		}
 
 		// Update other fields
@@ -534,9 +771,10 @@ func UpdateAnimal(db *gorm.DB, emailService *email.Service, embedder embedding.E
 		}
 
 		if err := db.Save(&animal).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update animal"})
+			respondInternalError(c, "Failed to update animal")
 			return
 		}
+		recordAnimalChange(c, db, before, animal, models.AnimalChangeEdit)
 
 		// Skip the embed call entirely when none of the embedded fields
 		// actually changed (e.g. a pure quarantine/approval-status edit) —
@@ -614,7 +852,8 @@ func UpdateAnimal(db *gorm.DB, emailService *email.Service, embedder embedding.E
 	}
 }
 
-// DeleteAnimal deletes an animal
+// DeleteAnimal deletes an animal. With ?dry_run=true it only returns the
+// animal it would delete.
 func DeleteAnimal(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		db := middleware.GetDB(c, db)
@@ -625,12 +864,41 @@ func DeleteAnimal(db *gorm.DB) gin.HandlerFunc {
 
 		// Check for group admin or site admin access
 		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
+			return
+		}
+
+		var held []models.Animal
+		if err := db.Where("id = ? AND group_id = ? AND legal_hold = ?", animalID, groupID, true).Find(&held).Error; err != nil {
+			respondInternalError(c, "Failed to delete animal")
+			return
+		}
+		if len(held) > 0 {
+			respondLegalHold(c, held...)
+			return
+		}
+
+		if c.Query("dry_run") == "true" {
+			var animals []models.Animal
+			if err := db.Where("id = ? AND group_id = ?", animalID, groupID).Find(&animals).Error; err != nil {
+				respondInternalError(c, "Failed to delete animal")
+				return
+			}
+			changes := []AnimalDiff{}
+			for _, a := range animals {
+				changes = append(changes, AnimalDiff{AnimalID: a.ID, Name: a.Name, Action: AnimalDiffDelete, Changes: models.AnimalFieldChanges{}})
+			}
+			c.JSON(http.StatusOK, gin.H{
+				"message": fmt.Sprintf("%d animals would be deleted", len(animals)),
+				"dry_run": true,
+				"count":   len(animals),
+				"changes": changes,
+			})
 			return
 		}
 
 		if err := db.Where("id = ? AND group_id = ?", animalID, groupID).Delete(&models.Animal{}).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete animal"})
+			respondInternalError(c, "Failed to delete animal")
 			return
 		}
 
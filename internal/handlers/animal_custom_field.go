@@ -0,0 +1,370 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/database"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrCodeInvalidCustomField is returned for an invalid custom field
+// definition, or an animal value that doesn't match its definition.
+const ErrCodeInvalidCustomField ErrorCode = "INVALID_CUSTOM_FIELD"
+
+const (
+	maxCustomFieldsPerGroup   = 50
+	maxCustomFieldValueLength = 1000
+	maxCustomFieldOptions     = 100
+)
+
+var customFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// customFieldSearchClause matches animals with a custom field value like the
+// bound pattern (Postgres only, as is the rest of Search).
+const customFieldSearchClause = `EXISTS (SELECT 1 FROM jsonb_each_text(animals.custom_fields) cf WHERE cf.value ILIKE ? ESCAPE '\')`
+
+var customFieldTypes = map[string]bool{
+	models.CustomFieldText:    true,
+	models.CustomFieldNumber:  true,
+	models.CustomFieldDate:    true,
+	models.CustomFieldBoolean: true,
+	models.CustomFieldSelect:  true,
+}
+
+// AnimalCustomFieldInput is one entry of an AnimalCustomFieldsRequest.
+type AnimalCustomFieldInput struct {
+	Key      string   `json:"key" binding:"required"`
+	Name     string   `json:"name" binding:"max=100"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options"`
+}
+
+// AnimalCustomFieldsRequest replaces a group's custom field definitions.
+// Order in the list becomes each field's order_index.
+type AnimalCustomFieldsRequest struct {
+	Fields []AnimalCustomFieldInput `json:"fields" binding:"dive"`
+}
+
+// groupCustomFields returns a group's custom field definitions in order.
+func groupCustomFields(db *gorm.DB, groupID uint) ([]models.AnimalCustomField, error) {
+	fields := []models.AnimalCustomField{}
+	err := db.Where("group_id = ?", groupID).Order("order_index, id").Find(&fields).Error
+	return fields, err
+}
+
+// buildAnimalCustomFields validates req and converts it to rows for groupID.
+// Returns a user-facing error on invalid input.
+func buildAnimalCustomFields(req AnimalCustomFieldsRequest, groupID uint) ([]models.AnimalCustomField, error) {
+	if len(req.Fields) > maxCustomFieldsPerGroup {
+		return nil, fmt.Errorf("a group can have at most %d custom fields", maxCustomFieldsPerGroup)
+	}
+	seen := make(map[string]bool, len(req.Fields))
+	rows := make([]models.AnimalCustomField, 0, len(req.Fields))
+	for i, in := range req.Fields {
+		key := strings.TrimSpace(in.Key)
+		if !customFieldKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid field key %q: use lowercase letters, digits, and underscores (max 50)", key)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate field key %q", key)
+		}
+		seen[key] = true
+
+		fieldType := strings.TrimSpace(in.Type)
+		if fieldType == "" {
+			fieldType = models.CustomFieldText
+		}
+		if !customFieldTypes[fieldType] {
+			return nil, fmt.Errorf("invalid type %q for field %q: must be text, number, date, boolean, or select", fieldType, key)
+		}
+
+		var options models.StringList
+		if fieldType == models.CustomFieldSelect {
+			seenOption := make(map[string]bool, len(in.Options))
+			for _, option := range in.Options {
+				option = strings.TrimSpace(option)
+				if option == "" || seenOption[option] {
+					continue
+				}
+				seenOption[option] = true
+				options = append(options, option)
+			}
+			if len(options) == 0 {
+				return nil, fmt.Errorf("select field %q needs at least one option", key)
+			}
+			if len(options) > maxCustomFieldOptions {
+				return nil, fmt.Errorf("select field %q can have at most %d options", key, maxCustomFieldOptions)
+			}
+		} else if len(in.Options) > 0 {
+			return nil, fmt.Errorf("only select fields have options; %q is a %s field", key, fieldType)
+		}
+
+		name := strings.TrimSpace(in.Name)
+		if name == "" {
+			name = key
+		}
+
+		rows = append(rows, models.AnimalCustomField{
+			GroupID:    groupID,
+			Key:        key,
+			Name:       name,
+			Type:       fieldType,
+			Required:   in.Required,
+			Options:    options,
+			OrderIndex: i,
+		})
+	}
+	return rows, nil
+}
+
+// customFieldInputString converts a JSON value sent for a custom field to
+// its string form. nil clears the field.
+func customFieldInputString(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return strings.TrimSpace(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	return "", fmt.Errorf("must be a string, number, or boolean")
+}
+
+// normalizeCustomFieldValue checks value against field's type and returns
+// its canonical stored form.
+func normalizeCustomFieldValue(field models.AnimalCustomField, value string) (string, error) {
+	switch field.Type {
+	case models.CustomFieldNumber:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", fmt.Errorf("must be a number")
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	case models.CustomFieldDate:
+		t, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			if t, err = time.Parse(time.RFC3339, value); err != nil {
+				return "", fmt.Errorf("must be a date (YYYY-MM-DD)")
+			}
+		}
+		return t.Format("2006-01-02"), nil
+	case models.CustomFieldBoolean:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("must be true or false")
+		}
+		return strconv.FormatBool(b), nil
+	case models.CustomFieldSelect:
+		for _, option := range field.Options {
+			if value == option {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("must be one of: %s", strings.Join(field.Options, ", "))
+	}
+	if len(value) > maxCustomFieldValueLength {
+		return "", fmt.Errorf("must be at most %d characters", maxCustomFieldValueLength)
+	}
+	return value, nil
+}
+
+// applyCustomFieldValues returns current with input applied: each key must be
+// one of fields, and an empty value removes it. With checkRequired, every
+// required field must have a value afterwards. Values of fields the group
+// has since removed are kept as they are.
+func applyCustomFieldValues(fields []models.AnimalCustomField, current models.AnimalCustomValues, input map[string]string, checkRequired bool) (models.AnimalCustomValues, error) {
+	byKey := make(map[string]models.AnimalCustomField, len(fields))
+	for _, f := range fields {
+		byKey[f.Key] = f
+	}
+
+	values := make(models.AnimalCustomValues, len(current)+len(input))
+	for k, v := range current {
+		values[k] = v
+	}
+	for key, value := range input {
+		field, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("unknown custom field %q", key)
+		}
+		if value == "" {
+			delete(values, key)
+			continue
+		}
+		normalized, err := normalizeCustomFieldValue(field, value)
+		if err != nil {
+			return nil, fmt.Errorf("custom field %q %s", key, err.Error())
+		}
+		values[key] = normalized
+	}
+
+	if checkRequired {
+		for _, f := range fields {
+			if f.Required && values[f.Key] == "" {
+				return nil, fmt.Errorf("custom field %q is required", f.Key)
+			}
+		}
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return values, nil
+}
+
+// resolveCustomFields applies the custom_fields of an animal write to
+// current, validated against groupID's definitions. input is nil when the
+// request didn't send custom_fields; required fields are then only checked
+// on create. Writes a 400 INVALID_CUSTOM_FIELD response and returns false
+// on invalid input.
+func resolveCustomFields(c *gin.Context, db *gorm.DB, groupID uint, current models.AnimalCustomValues, input map[string]interface{}, isCreate bool) (models.AnimalCustomValues, bool) {
+	if input == nil && !isCreate {
+		return current, true
+	}
+	fields, err := groupCustomFields(db, groupID)
+	if err != nil {
+		respondInternalError(c, "Failed to load custom fields")
+		return nil, false
+	}
+	strValues := make(map[string]string, len(input))
+	for key, v := range input {
+		s, err := customFieldInputString(v)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidCustomField, fmt.Sprintf("custom field %q %s", key, err.Error()))
+			return nil, false
+		}
+		strValues[key] = s
+	}
+	values, err := applyCustomFieldValues(fields, current, strValues, true)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidCustomField, err.Error())
+		return nil, false
+	}
+	return values, true
+}
+
+// applyCustomFieldFilters narrows query to animals whose custom fields match
+// every field.<key>=<value> parameter. Text comparisons ignore case.
+func applyCustomFieldFilters(params url.Values, db, query *gorm.DB) (*gorm.DB, error) {
+	dialect := database.DialectOf(db)
+	for param, values := range params {
+		key, ok := strings.CutPrefix(param, "field.")
+		if !ok || len(values) == 0 {
+			continue
+		}
+		// The key is interpolated into the JSON path, so it must be a
+		// valid field key
+		if !customFieldKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid custom field filter %q", param)
+		}
+		query = query.Where("LOWER("+dialect.JSONText("animals.custom_fields", key)+") = LOWER(?)", strings.TrimSpace(values[0]))
+	}
+	return query, nil
+}
+
+// GetAnimalCustomFields returns a group's custom field definitions
+// Route: GET /api/groups/:id/animal-fields
+func GetAnimalCustomFields(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+
+		fields, err := groupCustomFields(db, uint(gid))
+		if err != nil {
+			respondInternalError(c, "Failed to load custom fields")
+			return
+		}
+
+		respondOK(c, fields)
+	}
+}
+
+// UpdateGroupAnimalCustomFields replaces a group's custom field definitions
+// (group admin or site admin). Animals keep their values for removed fields,
+// so re-adding a field with the same key brings them back.
+// Route: PUT /api/groups/:id/animal-fields
+func UpdateGroupAnimalCustomFields(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		logger := middleware.GetLogger(c)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
+			return
+		}
+
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+		groupIDUint := uint(gid)
+
+		var req AnimalCustomFieldsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+
+		rows, err := buildAnimalCustomFields(req, groupIDUint)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidCustomField, err.Error())
+			return
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("group_id = ?", groupIDUint).Delete(&models.AnimalCustomField{}).Error; err != nil {
+				return err
+			}
+			if len(rows) == 0 {
+				return nil
+			}
+			return tx.Create(&rows).Error
+		})
+		if err != nil {
+			logger.Error("Failed to update animal custom fields", err)
+			respondInternalError(c, "Failed to update custom fields")
+			return
+		}
+
+		logger.WithFields(map[string]interface{}{
+			"group_id": groupIDUint,
+			"count":    len(rows),
+		}).Info("Updated animal custom fields")
+
+		fields, err := groupCustomFields(db, groupIDUint)
+		if err != nil {
+			respondInternalError(c, "Failed to load custom fields")
+			return
+		}
+		respondOK(c, fields)
+	}
+}
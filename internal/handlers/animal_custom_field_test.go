@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyCustomFieldValues(t *testing.T) {
+	fields := []models.AnimalCustomField{
+		{Key: "microchip", Type: models.CustomFieldText, Required: true},
+		{Key: "weight_kg", Type: models.CustomFieldNumber},
+		{Key: "heartworm_test", Type: models.CustomFieldDate},
+		{Key: "heartworm_positive", Type: models.CustomFieldBoolean},
+		{Key: "kennel_bay", Type: models.CustomFieldSelect, Options: models.StringList{"A", "B"}},
+	}
+
+	values, err := applyCustomFieldValues(fields, nil, map[string]string{
+		"microchip":          "985112",
+		"weight_kg":          "12.50",
+		"heartworm_test":     "2026-09-01T10:00:00Z",
+		"heartworm_positive": "1",
+		"kennel_bay":         "B",
+	}, true)
+	require.NoError(t, err)
+	assert.Equal(t, models.AnimalCustomValues{
+		"microchip":          "985112",
+		"weight_kg":          "12.5",
+		"heartworm_test":     "2026-09-01",
+		"heartworm_positive": "true",
+		"kennel_bay":         "B",
+	}, values)
+
+	// An empty value clears the field; values of removed fields are kept
+	current := models.AnimalCustomValues{"microchip": "985112", "weight_kg": "12.5", "old_field": "x"}
+	values, err = applyCustomFieldValues(fields, current, map[string]string{"weight_kg": ""}, true)
+	require.NoError(t, err)
+	assert.Equal(t, models.AnimalCustomValues{"microchip": "985112", "old_field": "x"}, values)
+
+	for name, input := range map[string]map[string]string{
+		"unknown key":      {"microchip": "1", "color": "red"},
+		"bad number":       {"microchip": "1", "weight_kg": "heavy"},
+		"bad date":         {"microchip": "1", "heartworm_test": "09/01/2026"},
+		"bad boolean":      {"microchip": "1", "heartworm_positive": "maybe"},
+		"unknown option":   {"microchip": "1", "kennel_bay": "C"},
+		"missing required": {"weight_kg": "3"},
+	} {
+		_, err := applyCustomFieldValues(fields, nil, input, true)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestAnimalCustomFields(t *testing.T) {
+	db := SetupTestDB(t)
+	groupAdmin := CreateTestUser(t, db, "groupadmin", "groupadmin@example.com", "password123", false)
+	member := CreateTestUser(t, db, "member", "member@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	AddUserToGroupWithAdmin(t, db, groupAdmin.ID, group.ID, true)
+	AddUserToGroupWithAdmin(t, db, member.ID, group.ID, false)
+	groupParam := gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}}
+
+	define := func(userID uint, body AnimalCustomFieldsRequest) *httptest.ResponseRecorder {
+		c, w := accountTestContext(userID, false, http.MethodPut, "/", body)
+		c.Params = groupParam
+		UpdateGroupAnimalCustomFields(db)(c)
+		return w
+	}
+	fields := AnimalCustomFieldsRequest{Fields: []AnimalCustomFieldInput{
+		{Key: "microchip", Name: "Microchip number", Required: true},
+		{Key: "kennel_bay", Name: "Kennel bay", Type: models.CustomFieldSelect, Options: []string{"A", "B"}},
+	}}
+	assert.Equal(t, http.StatusForbidden, define(member.ID, fields).Code)
+	assert.Equal(t, http.StatusBadRequest, define(groupAdmin.ID, AnimalCustomFieldsRequest{Fields: []AnimalCustomFieldInput{
+		{Key: "kennel_bay", Type: models.CustomFieldSelect},
+	}}).Code, "select fields need options")
+	w := define(groupAdmin.ID, fields)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	c, w := accountTestContext(member.ID, false, http.MethodGet, "/", nil)
+	c.Params = groupParam
+	GetAnimalCustomFields(db)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var defined []models.AnimalCustomField
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &defined))
+	require.Len(t, defined, 2)
+	assert.Equal(t, models.CustomFieldText, defined[0].Type)
+	assert.Equal(t, models.StringList{"A", "B"}, defined[1].Options)
+
+	create := func(customFields map[string]interface{}) *httptest.ResponseRecorder {
+		c, w := accountTestContext(groupAdmin.ID, false, http.MethodPost, "/?force=true", AnimalRequest{
+			Name: "Rex", Species: "Dog", CustomFields: customFields,
+		})
+		c.Params = groupParam
+		CreateAnimal(db, nil, &embedding.StubEmbedder{})(c)
+		return w
+	}
+	assert.Equal(t, http.StatusBadRequest, create(nil).Code, "microchip is required")
+	assert.Equal(t, http.StatusBadRequest, create(map[string]interface{}{"microchip": "1", "kennel_bay": "Z"}).Code)
+	w = create(map[string]interface{}{"microchip": 985112, "kennel_bay": "A"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var rex models.Animal
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rex))
+	assert.Equal(t, models.AnimalCustomValues{"microchip": "985112", "kennel_bay": "A"}, rex.CustomFields)
+
+	// Updates without custom_fields leave the values alone; sent values merge
+	update := func(customFields map[string]interface{}) *httptest.ResponseRecorder {
+		c, w := accountTestContext(groupAdmin.ID, false, http.MethodPut, "/", AnimalRequest{
+			Name: "Rex", Species: "Dog", Status: "available", CustomFields: customFields,
+		})
+		c.Params = append(groupParam, gin.Param{Key: "animalId", Value: fmt.Sprint(rex.ID)})
+		UpdateAnimal(db, nil, &embedding.StubEmbedder{})(c)
+		return w
+	}
+	require.Equal(t, http.StatusOK, update(nil).Code)
+	w = update(map[string]interface{}{"kennel_bay": "B"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, http.StatusBadRequest, update(map[string]interface{}{"microchip": nil}).Code, "required fields can't be cleared")
+	var stored models.Animal
+	require.NoError(t, db.First(&stored, rex.ID).Error)
+	assert.Equal(t, models.AnimalCustomValues{"microchip": "985112", "kennel_bay": "B"}, stored.CustomFields)
+
+	// The list can filter on a custom field
+	CreateTestAnimal(t, db, group.ID, "Fido", "Dog")
+	list := func(query string) []animalWithCounts {
+		c, w := accountTestContext(member.ID, false, http.MethodGet, "/"+query, nil)
+		c.Params = groupParam
+		GetAnimals(db)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var animals []animalWithCounts
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &animals))
+		return animals
+	}
+	assert.Len(t, list(""), 2)
+	filtered := list("?field.kennel_bay=b")
+	require.Len(t, filtered, 1)
+	assert.Equal(t, rex.ID, filtered[0].ID)
+
+	// Custom fields round-trip through CSV export and import
+	c, w = accountTestContext(groupAdmin.ID, true, http.MethodGet, fmt.Sprintf("/?group_id=%d", group.ID), nil)
+	ExportAnimalsCSV(db)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	records, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, append(append([]string{}, animalCSVHeader...), "custom.microchip", "custom.kennel_bay"), records[0])
+	assert.Equal(t, []string{"985112", "B"}, records[1][len(animalCSVHeader):])
+
+	w = importAnimalsCSVForTest(t, db, groupAdmin.ID, "", fmt.Sprintf("group_id,name,custom.microchip,custom.kennel_bay\n%d,Buddy,,A\n%d,Max,4242,B", group.ID, group.ID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var imported struct {
+		Count    int      `json:"count"`
+		Warnings []string `json:"warnings"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &imported))
+	assert.Equal(t, 1, imported.Count)
+	require.Len(t, imported.Warnings, 1)
+	assert.Contains(t, imported.Warnings[0], `"microchip" is required`)
+	var maxDog models.Animal
+	require.NoError(t, db.Where("name = ?", "Max").First(&maxDog).Error)
+	assert.Equal(t, models.AnimalCustomValues{"microchip": "4242", "kennel_bay": "B"}, maxDog.CustomFields)
+}
@@ -51,7 +51,7 @@ func UploadAnimalProtocolDocument(db *gorm.DB, storageProvider storage.Provider)
 
 		// Check if animal exists and belongs to the group
 		var animal models.Animal
-		if err := db.Where("id = ? AND group_id = ?", animalID, groupID).First(&animal).Error; err != nil {
+		if err := visibleAnimals(c, db, db.Where("id = ? AND group_id = ?", animalID, groupID), groupIDStr).First(&animal).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
 				c.JSON(http.StatusNotFound, gin.H{"error": "Animal not found in this group"})
 			} else {
@@ -277,7 +277,7 @@ func DeleteAnimalProtocolDocument(db *gorm.DB, storageProvider storage.Provider)
 
 		// Check if animal exists and belongs to the group
 		var animal models.Animal
-		if err := db.Where("id = ? AND group_id = ?", animalID, groupID).First(&animal).Error; err != nil {
+		if err := visibleAnimals(c, db, db.Where("id = ? AND group_id = ?", animalID, groupID), groupIDStr).First(&animal).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
 				c.JSON(http.StatusNotFound, gin.H{"error": "Animal not found in this group"})
 			} else {
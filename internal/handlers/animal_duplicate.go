@@ -0,0 +1,400 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrCodePossibleDuplicate is returned by CreateAnimal when the group already
+// has an animal that looks like the one being created.
+const ErrCodePossibleDuplicate ErrorCode = "POSSIBLE_DUPLICATE"
+
+// maxDuplicateCandidates caps how many likely duplicates are returned.
+const maxDuplicateCandidates = 10
+
+// DuplicateCandidate is an existing animal that looks like one being created
+type DuplicateCandidate struct {
+	ID                 uint       `json:"id"`
+	Name               string     `json:"name"`
+	Species            string     `json:"species"`
+	Breed              string     `json:"breed"`
+	Age                int        `json:"age"`
+	EstimatedBirthDate *time.Time `json:"estimated_birth_date"`
+	Status             string     `json:"status"`
+	ImageURL           string     `json:"image_url"`
+	ArrivalDate        *time.Time `json:"arrival_date"`
+	MatchedOn          []string   `json:"matched_on"` // Which of name, species, breed, and age agree
+}
+
+// possibleDuplicateResponse is CreateAnimal's 409 body. Code is only set for
+// clients that opt in to structured errors, as with respondError.
+type possibleDuplicateResponse struct {
+	Error      string               `json:"error"`
+	Code       ErrorCode            `json:"code,omitempty"`
+	Candidates []DuplicateCandidate `json:"candidates"`
+}
+
+// MergeAnimalsRequest names the duplicate to fold into the :animalId animal
+type MergeAnimalsRequest struct {
+	DuplicateID uint `json:"duplicate_id" binding:"required"`
+}
+
+// AnimalMergeResult is the kept animal after a merge, with how many records
+// of each kind were moved onto it
+type AnimalMergeResult struct {
+	Animal models.Animal    `json:"animal"`
+	Moved  map[string]int64 `json:"moved"`
+}
+
+// normalizeForMatch lowercases s and drops everything but letters and
+// digits, so "Mr. Biscuit" and "mr biscuit" compare equal.
+func normalizeForMatch(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// editDistance is the Levenshtein distance between a and b, in runes.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// animalNamesSimilar reports whether two names are probably the same name
+// typed differently. Short names must match exactly once normalized; longer
+// ones may differ by a typo or two.
+func animalNamesSimilar(a, b string) bool {
+	na, nb := normalizeForMatch(a), normalizeForMatch(b)
+	if na == "" || nb == "" {
+		return false
+	}
+	if na == nb {
+		return true
+	}
+	shorter := min(len([]rune(na)), len([]rune(nb)))
+	switch {
+	case shorter < 3:
+		return false
+	case shorter < 8:
+		return editDistance(na, nb) <= 1
+	default:
+		return editDistance(na, nb) <= 2
+	}
+}
+
+// knownAgeYears returns an animal's age in whole years, if it was recorded.
+// An Age of 0 without a birth date means unknown rather than newborn.
+func knownAgeYears(a *models.Animal) (int, bool) {
+	if a.EstimatedBirthDate != nil {
+		years, _ := a.AgeDisplay()
+		return years, true
+	}
+	return a.Age, a.Age > 0
+}
+
+// duplicateMatch reports whether existing looks like the same animal as
+// incoming. Names must be similar, and species, breed, and age must not
+// contradict each other where both records have them. It returns which
+// fields agreed.
+func duplicateMatch(incoming, existing *models.Animal) ([]string, bool) {
+	if !animalNamesSimilar(incoming.Name, existing.Name) {
+		return nil, false
+	}
+	matched := []string{"name"}
+
+	if a, b := normalizeForMatch(incoming.Species), normalizeForMatch(existing.Species); a != "" && b != "" {
+		if a != b {
+			return nil, false
+		}
+		matched = append(matched, "species")
+	}
+	// Breeds are free text, so "Lab" and "Lab mix" count as the same
+	if a, b := normalizeForMatch(incoming.Breed), normalizeForMatch(existing.Breed); a != "" && b != "" {
+		if !strings.Contains(a, b) && !strings.Contains(b, a) {
+			return nil, false
+		}
+		matched = append(matched, "breed")
+	}
+	// Ages are estimates; allow a year either way
+	if a, ok := knownAgeYears(incoming); ok {
+		if b, ok := knownAgeYears(existing); ok {
+			if a-b > 1 || b-a > 1 {
+				return nil, false
+			}
+			matched = append(matched, "age")
+		}
+	}
+	return matched, true
+}
+
+// findDuplicateCandidates returns animals in the incoming animal's group, in
+// any status, that look like it.
+func findDuplicateCandidates(db *gorm.DB, incoming *models.Animal) ([]DuplicateCandidate, error) {
+	var existing []models.Animal
+	if err := db.Select("id, name, species, breed, age, estimated_birth_date, status, image_url, arrival_date").
+		Where("group_id = ?", incoming.GroupID).Order("id").Find(&existing).Error; err != nil {
+		return nil, err
+	}
+
+	var candidates []DuplicateCandidate
+	for i := range existing {
+		a := &existing[i]
+		matched, ok := duplicateMatch(incoming, a)
+		if !ok {
+			continue
+		}
+		candidates = append(candidates, DuplicateCandidate{
+			ID:                 a.ID,
+			Name:               a.Name,
+			Species:            a.Species,
+			Breed:              a.Breed,
+			Age:                a.Age,
+			EstimatedBirthDate: a.EstimatedBirthDate,
+			Status:             a.Status,
+			ImageURL:           a.ImageURL,
+			ArrivalDate:        a.ArrivalDate,
+			MatchedOn:          matched,
+		})
+		if len(candidates) == maxDuplicateCandidates {
+			break
+		}
+	}
+	return candidates, nil
+}
+
+func respondPossibleDuplicates(c *gin.Context, candidates []DuplicateCandidate) {
+	resp := possibleDuplicateResponse{
+		Error:      "This animal may already exist. Review the matches, or resend with force=true to create it anyway",
+		Candidates: candidates,
+	}
+	if structuredErrorsRequested(c) {
+		resp.Code = ErrCodePossibleDuplicate
+	}
+	c.JSON(http.StatusConflict, resp)
+}
+
+// animalMergeMoves lists the records that belong to an animal through an
+// animal_id column and move wholesale to the kept animal on merge.
+var animalMergeMoves = []struct {
+	key   string
+	model interface{}
+}{
+	{"comments", &models.AnimalComment{}},
+	{"images", &models.AnimalImage{}},
+	{"videos", &models.AnimalVideo{}},
+	{"weights", &models.WeightEntry{}},
+	{"name_history", &models.AnimalNameHistory{}},
+	{"bq_incidents", &models.AnimalBQIncident{}},
+	{"share_links", &models.AnimalShareLink{}},
+	{"views", &models.AnimalView{}},
+}
+
+// mergeAnimals folds dup into keep inside tx: dup's comments, photos, videos,
+// and other history move to keep, its tags and relationships are added to
+// keep's, blank fields on keep are filled from dup, and dup is deleted.
+// keep's name and status are left alone.
+func mergeAnimals(tx *gorm.DB, keep, dup *models.Animal, userID uint) (map[string]int64, error) {
+	moved := make(map[string]int64, len(animalMergeMoves)+1)
+
+	// Keep a single profile picture: the kept animal's, if it has one
+	if keep.ImageURL != "" {
+		if err := tx.Unscoped().Model(&models.AnimalImage{}).Where("animal_id = ?", dup.ID).
+			Update("is_profile_picture", false).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// Unscoped so soft-deleted comments and photos stay with their animal
+	// for moderation
+	for _, m := range animalMergeMoves {
+		res := tx.Unscoped().Model(m.model).Where("animal_id = ?", dup.ID).Update("animal_id", keep.ID)
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		moved[m.key] = res.RowsAffected
+	}
+
+	var keepTags, dupTags []models.AnimalTag
+	if err := tx.Model(keep).Association("Tags").Find(&keepTags); err != nil {
+		return nil, err
+	}
+	if err := tx.Model(dup).Association("Tags").Find(&dupTags); err != nil {
+		return nil, err
+	}
+	has := make(map[uint]bool, len(keepTags))
+	for _, t := range keepTags {
+		has[t.ID] = true
+	}
+	var newTags []models.AnimalTag
+	for _, t := range dupTags {
+		if !has[t.ID] {
+			newTags = append(newTags, t)
+		}
+	}
+	if len(newTags) > 0 {
+		if err := tx.Model(keep).Association("Tags").Append(&newTags); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Model(dup).Association("Tags").Clear(); err != nil {
+		return nil, err
+	}
+	moved["tags"] = int64(len(newTags))
+
+	relationships, err := moveAnimalRelationships(tx, keep.ID, dup.ID)
+	if err != nil {
+		return nil, err
+	}
+	moved["relationships"] = relationships
+
+	updates := map[string]interface{}{}
+	fill := func(column, keepValue, dupValue string) {
+		if strings.TrimSpace(keepValue) == "" && strings.TrimSpace(dupValue) != "" {
+			updates[column] = dupValue
+		}
+	}
+	fill("species", keep.Species, dup.Species)
+	fill("breed", keep.Breed, dup.Breed)
+	fill("description", keep.Description, dup.Description)
+	fill("trainer_notes", keep.TrainerNotes, dup.TrainerNotes)
+	fill("image_url", keep.ImageURL, dup.ImageURL)
+	fill("external_id", keep.ExternalID, dup.ExternalID)
+	fill("microchip_number", keep.MicrochipNumber, dup.MicrochipNumber)
+	fill("license_number", keep.LicenseNumber, dup.LicenseNumber)
+	if keep.EstimatedBirthDate == nil && dup.EstimatedBirthDate != nil {
+		updates["estimated_birth_date"] = dup.EstimatedBirthDate
+	}
+	if keep.Age == 0 && dup.Age > 0 {
+		updates["age"] = dup.Age
+	}
+	// The animal arrived when the first of the two records says it did
+	if dup.ArrivalDate != nil && (keep.ArrivalDate == nil || dup.ArrivalDate.Before(*keep.ArrivalDate)) {
+		updates["arrival_date"] = dup.ArrivalDate
+	}
+	if len(updates) > 0 {
+		if err := tx.Model(keep).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// Record the duplicate's name so searches for it still lead somewhere
+	if !strings.EqualFold(strings.TrimSpace(keep.Name), strings.TrimSpace(dup.Name)) {
+		if err := tx.Create(&models.AnimalNameHistory{
+			AnimalID:  keep.ID,
+			OldName:   dup.Name,
+			NewName:   keep.Name,
+			ChangedBy: userID,
+			Source:    models.NameChangeMerge,
+		}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Delete(dup).Error; err != nil {
+		return nil, err
+	}
+	return moved, nil
+}
+
+// MergeAnimals folds a duplicate animal record into the :animalId animal
+// (group admin or site admin). Both must belong to the :id group.
+// Route: POST /api/groups/:id/animals/:animalId/merge
+func MergeAnimals(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		logger := middleware.GetLogger(c)
+
+		groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+		if !IsGroupAdminOrSiteAdmin(c, db, uint(groupID)) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Group admin access required")
+			return
+		}
+		userID, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+
+		keep, ok := findGroupAnimal(c, db)
+		if !ok {
+			return
+		}
+
+		var req MergeAnimalsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		if req.DuplicateID == keep.ID {
+			respondBadRequest(c, "An animal can't be merged into itself")
+			return
+		}
+		var dup models.Animal
+		if err := db.Where("id = ? AND group_id = ?", req.DuplicateID, keep.GroupID).First(&dup).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeAnimalNotFound, "Duplicate animal not found")
+			return
+		}
+		// Merging deletes the duplicate
+		if dup.LegalHold {
+			respondLegalHold(c, dup)
+			return
+		}
+
+		var moved map[string]int64
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			moved, err = mergeAnimals(tx, keep, &dup, userID)
+			return err
+		}); err != nil {
+			logger.Error("Failed to merge animals", err)
+			respondInternalError(c, "Failed to merge animals")
+			return
+		}
+
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventAnimalMerged, userID, map[string]interface{}{
+			"group_id":     keep.GroupID,
+			"animal_id":    keep.ID,
+			"duplicate_id": dup.ID,
+		})
+
+		var merged models.Animal
+		if err := db.Preload("Tags").First(&merged, keep.ID).Error; err != nil {
+			respondInternalError(c, "Failed to load merged animal")
+			return
+		}
+		respondOK(c, AnimalMergeResult{Animal: merged, Moved: moved})
+	}
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestAnimalNamesSimilar(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"Biscuit", "biscuit", true},
+		{"Mr. Biscuit", "mr biscuit", true},
+		{"Biscuit", "Biscut", true}, // one typo
+		{"Rex", "Rexy", true},       // one letter added
+		{"Rex", "Max", false},       // two letters different
+		{"Bo", "Bob", false},        // very short names must match exactly
+		{"Bartholomew", "Bartholemew", true},
+		{"Bartholomew", "Bartlomiej", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, animalNamesSimilar(tt.a, tt.b), "%q vs %q", tt.a, tt.b)
+	}
+}
+
+func TestDuplicateMatch(t *testing.T) {
+	born := func(years int) *time.Time {
+		d := time.Now().AddDate(-years, 0, -1)
+		return &d
+	}
+	existing := models.Animal{Name: "Biscuit", Species: "Dog", Breed: "Lab Mix", Age: 3}
+	tests := []struct {
+		name        string
+		incoming    models.Animal
+		wantMatch   bool
+		wantMatched []string
+	}{
+		{"name only", models.Animal{Name: "biscuit"}, true, []string{"name"}},
+		{"all fields agree", models.Animal{Name: "Biscut", Species: "dog", Breed: "Lab", Age: 4},
+			true, []string{"name", "species", "breed", "age"}},
+		{"age from birth date", models.Animal{Name: "Biscuit", EstimatedBirthDate: born(2)}, true, []string{"name", "age"}},
+		{"different species", models.Animal{Name: "Biscuit", Species: "Cat"}, false, nil},
+		{"different breed", models.Animal{Name: "Biscuit", Breed: "Beagle"}, false, nil},
+		{"ages too far apart", models.Animal{Name: "Biscuit", Age: 9}, false, nil},
+		{"different name", models.Animal{Name: "Gravy", Species: "Dog", Breed: "Lab Mix", Age: 3}, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, ok := duplicateMatch(&tt.incoming, &existing)
+			assert.Equal(t, tt.wantMatch, ok)
+			assert.Equal(t, tt.wantMatched, matched)
+		})
+	}
+}
+
+func createAnimalRequest(t *testing.T, db *gorm.DB, userID, groupID uint, query string, req AnimalRequest) *httptest.ResponseRecorder {
+	t.Helper()
+	body, _ := json.Marshal(req)
+	c, w := setupAnimalTestContext(userID, false)
+	c.Params = gin.Params{{Key: "id", Value: fmt.Sprintf("%d", groupID)}}
+	c.Request = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/groups/%d/animals%s", groupID, query), bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("X-API-Version", "2")
+	CreateAnimal(db, nil, &embedding.StubEmbedder{})(c)
+	return w
+}
+
+func TestCreateAnimal_DuplicateDetection(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	user, group := createAnimalTestUser(t, db, "groupadmin", "groupadmin@example.com", false)
+	existing := models.Animal{GroupID: group.ID, Name: "Biscuit", Species: "Dog", Breed: "Lab Mix", Age: 3, Status: "archived"}
+	require.NoError(t, db.Create(&existing).Error)
+
+	var before int64
+	db.Model(&models.Animal{}).Count(&before)
+
+	w := createAnimalRequest(t, db, user.ID, group.ID, "", AnimalRequest{Name: "Biscut", Species: "Dog", Breed: "Lab", Age: 4})
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	var resp possibleDuplicateResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, ErrCodePossibleDuplicate, resp.Code)
+	require.Len(t, resp.Candidates, 1, "archived animals are still candidates")
+	assert.Equal(t, existing.ID, resp.Candidates[0].ID)
+	assert.Equal(t, []string{"name", "species", "breed", "age"}, resp.Candidates[0].MatchedOn)
+
+	var after int64
+	db.Model(&models.Animal{}).Count(&after)
+	assert.Equal(t, before, after, "nothing is created when duplicates are found")
+
+	w = createAnimalRequest(t, db, user.ID, group.ID, "?force=true", AnimalRequest{Name: "Biscut", Species: "Dog", Breed: "Lab", Age: 4})
+	assert.Equal(t, http.StatusCreated, w.Code, "force=true creates the animal anyway")
+
+	w = createAnimalRequest(t, db, user.ID, group.ID, "", AnimalRequest{Name: "Biscuit", Species: "Cat"})
+	assert.Equal(t, http.StatusCreated, w.Code, "a cat named Biscuit is not the same animal")
+
+	// Animals in other groups are never candidates
+	other := CreateTestGroup(t, db, "other", "")
+	AddUserToGroupWithAdmin(t, db, user.ID, other.ID, true)
+	w = createAnimalRequest(t, db, user.ID, other.ID, "", AnimalRequest{Name: "Biscuit", Species: "Dog"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func mergeRequest(db *gorm.DB, userID, groupID, animalID, duplicateID uint) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(MergeAnimalsRequest{DuplicateID: duplicateID})
+	c, w := setupAnimalTestContext(userID, false)
+	c.Params = gin.Params{
+		{Key: "id", Value: fmt.Sprintf("%d", groupID)},
+		{Key: "animalId", Value: fmt.Sprintf("%d", animalID)},
+	}
+	c.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	MergeAnimals(db)(c)
+	return w
+}
+
+func TestMergeAnimals(t *testing.T) {
+	// Merging touches every table that hangs off an animal
+	db := SetupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AnimalImage{}, &models.AnimalVideo{}, &models.AnimalBQIncident{}))
+	admin, group := createAnimalTestUser(t, db, "groupadmin", "groupadmin@example.com", false)
+	member := CreateTestUser(t, db, "walker", "walker@example.com", "password123", false)
+	AddUserToGroupWithAdmin(t, db, member.ID, group.ID, false)
+
+	early := time.Now().AddDate(0, -2, 0)
+	keep := models.Animal{GroupID: group.ID, Name: "Biscuit", Species: "Dog", ImageURL: "/api/images/keep", Status: "available"}
+	dup := models.Animal{GroupID: group.ID, Name: "Biscut", Species: "Dog", Breed: "Lab Mix", Description: "Loves naps",
+		ImageURL: "/api/images/dup", Status: "available", ArrivalDate: &early}
+	require.NoError(t, db.Create(&keep).Error)
+	require.NoError(t, db.Create(&dup).Error)
+
+	shared := models.AnimalTag{GroupID: group.ID, Name: "Shy", Category: "behavior"}
+	extra := models.AnimalTag{GroupID: group.ID, Name: "Good with cats", Category: "behavior"}
+	require.NoError(t, db.Create(&shared).Error)
+	require.NoError(t, db.Create(&extra).Error)
+	require.NoError(t, db.Model(&keep).Association("Tags").Append(&shared))
+	require.NoError(t, db.Model(&dup).Association("Tags").Append([]models.AnimalTag{shared, extra}))
+
+	for _, content := range []string{"First walk", "Second walk"} {
+		require.NoError(t, db.Create(&models.AnimalComment{AnimalID: dup.ID, UserID: member.ID, Content: content}).Error)
+	}
+	dupID := dup.ID
+	require.NoError(t, db.Create(&models.AnimalImage{AnimalID: &dupID, UserID: member.ID, ImageURL: "/api/images/dup", IsProfilePicture: true}).Error)
+	require.NoError(t, db.Create(&models.WeightEntry{AnimalID: dup.ID, Weight: 50, Unit: "lb", RecordedAt: time.Now(), RecordedByID: member.ID}).Error)
+
+	t.Run("validation", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, mergeRequest(db, member.ID, group.ID, keep.ID, dup.ID).Code)
+		assert.Equal(t, http.StatusBadRequest, mergeRequest(db, admin.ID, group.ID, keep.ID, keep.ID).Code)
+
+		other := CreateTestGroup(t, db, "other", "")
+		stranger := CreateTestAnimal(t, db, other.ID, "Biscuit", "Dog")
+		assert.Equal(t, http.StatusNotFound, mergeRequest(db, admin.ID, group.ID, keep.ID, stranger.ID).Code,
+			"animals from another group can't be merged in")
+	})
+
+	w := mergeRequest(db, admin.ID, group.ID, keep.ID, dup.ID)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var result AnimalMergeResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, int64(2), result.Moved["comments"])
+	assert.Equal(t, int64(1), result.Moved["images"])
+	assert.Equal(t, int64(1), result.Moved["weights"])
+	assert.Equal(t, int64(1), result.Moved["tags"], "only the tag keep didn't already have is added")
+
+	// Name and profile picture stay; blanks are filled from the duplicate
+	merged := result.Animal
+	assert.Equal(t, "Biscuit", merged.Name)
+	assert.Equal(t, "/api/images/keep", merged.ImageURL)
+	assert.Equal(t, "Lab Mix", merged.Breed)
+	assert.Equal(t, "Loves naps", merged.Description)
+	require.NotNil(t, merged.ArrivalDate)
+	assert.WithinDuration(t, early, *merged.ArrivalDate, time.Second)
+	assert.Len(t, merged.Tags, 2)
+
+	var comments int64
+	db.Model(&models.AnimalComment{}).Where("animal_id = ?", keep.ID).Count(&comments)
+	assert.Equal(t, int64(2), comments)
+	var image models.AnimalImage
+	require.NoError(t, db.Where("image_url = ?", "/api/images/dup").First(&image).Error)
+	assert.Equal(t, keep.ID, *image.AnimalID)
+	assert.False(t, image.IsProfilePicture, "keep's profile picture wins")
+
+	var history models.AnimalNameHistory
+	require.NoError(t, db.Where("animal_id = ?", keep.ID).First(&history).Error)
+	assert.Equal(t, "Biscut", history.OldName)
+
+	assert.ErrorIs(t, db.First(&models.Animal{}, dup.ID).Error, gorm.ErrRecordNotFound, "the duplicate is deleted")
+	assert.Zero(t, db.Model(&dup).Association("Tags").Count())
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// facetUnknown filters on, and counts, animals with no value recorded
+const facetUnknown = "unknown"
+
+// animalFacet is an enum field of Animal that GET /animals can filter on
+// with several values at once and count by
+type animalFacet struct {
+	param  string   // Query parameter and JSON key
+	column string   // Column in animals
+	values []string // Accepted values, in display order
+}
+
+// animalFacets are the facets of the animal list
+var animalFacets = []animalFacet{
+	{param: "size", column: "size", values: models.AnimalSizes},
+	{param: "energy_level", column: "energy_level", values: models.EnergyLevels},
+}
+
+// AnimalFacetCounts holds, per facet, how many animals have each value,
+// including facetUnknown. A facet's counts apply every filter but its own,
+// so they show what choosing another value would match.
+type AnimalFacetCounts map[string]map[string]int64
+
+// validAnimalSize reports whether s is empty (not recorded) or an accepted size
+func validAnimalSize(s string) bool {
+	return s == "" || slices.Contains(models.AnimalSizes, s)
+}
+
+// validEnergyLevel reports whether s is empty (not recorded) or an accepted
+// energy level
+func validEnergyLevel(s string) bool {
+	return s == "" || slices.Contains(models.EnergyLevels, s)
+}
+
+// validateAnimalTraits checks the size and energy level of an animal
+// request; nil means not provided
+func validateAnimalTraits(size, energyLevel *string) error {
+	if size != nil && !validAnimalSize(*size) {
+		return fmt.Errorf("size must be one of: %s", strings.Join(models.AnimalSizes, ", "))
+	}
+	if energyLevel != nil && !validEnergyLevel(*energyLevel) {
+		return fmt.Errorf("energy_level must be one of: %s", strings.Join(models.EnergyLevels, ", "))
+	}
+	return nil
+}
+
+// isAnimalFacetParam reports whether name is a facet's query parameter
+func isAnimalFacetParam(name string) bool {
+	for _, f := range animalFacets {
+		if f.param == name {
+			return true
+		}
+	}
+	return false
+}
+
+// facetValues returns the values chosen for f, which may be given
+// comma-separated or as repeated parameters, lower-cased and without
+// repeats. None means no filter.
+func (f animalFacet) facetValues(params url.Values) ([]string, error) {
+	var values []string
+	for _, raw := range params[f.param] {
+		for _, v := range splitAndTrim(strings.ToLower(raw)) {
+			if v != facetUnknown && !slices.Contains(f.values, v) {
+				return nil, fmt.Errorf("%s must be one of: %s, %s", f.param, strings.Join(f.values, ", "), facetUnknown)
+			}
+			if !slices.Contains(values, v) {
+				values = append(values, v)
+			}
+		}
+	}
+	return values, nil
+}
+
+// filter narrows query to animals with one of values
+func (f animalFacet) filter(query *gorm.DB, values []string) *gorm.DB {
+	column := "animals." + f.column
+	known := slices.DeleteFunc(slices.Clone(values), func(v string) bool { return v == facetUnknown })
+	if len(known) == len(values) {
+		return query.Where(column+" IN ?", known)
+	}
+	unknown := column + " IS NULL OR " + column + " = ''"
+	if len(known) == 0 {
+		return query.Where("(" + unknown + ")")
+	}
+	return query.Where("("+column+" IN ? OR "+unknown+")", known)
+}
+
+// animalFacetFilters reads the facet filters of an animal list request,
+// keyed by facet parameter
+func animalFacetFilters(params url.Values) (map[string][]string, error) {
+	filters := map[string][]string{}
+	for _, f := range animalFacets {
+		values, err := f.facetValues(params)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) > 0 {
+			filters[f.param] = values
+		}
+	}
+	return filters, nil
+}
+
+// applyAnimalFacetFilters narrows query by filters, skipping the facet
+// named by except
+func applyAnimalFacetFilters(query *gorm.DB, filters map[string][]string, except string) *gorm.DB {
+	for _, f := range animalFacets {
+		if values, ok := filters[f.param]; ok && f.param != except {
+			query = f.filter(query, values)
+		}
+	}
+	return query
+}
+
+// countAnimalFacets counts the animals matched by base, which has every
+// filter but the facet filters, by each facet's values. Every accepted
+// value appears, with zero when no animal has it.
+func countAnimalFacets(base *gorm.DB, filters map[string][]string) (AnimalFacetCounts, error) {
+	counts := AnimalFacetCounts{}
+	for _, f := range animalFacets {
+		var rows []struct {
+			Value string
+			Count int64
+		}
+		query := applyAnimalFacetFilters(base.Session(&gorm.Session{}), filters, f.param)
+		if err := query.Model(&models.Animal{}).
+			Select("COALESCE(animals." + f.column + ", '') AS value, COUNT(*) AS count").
+			Group("COALESCE(animals." + f.column + ", '')").
+			Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		facet := map[string]int64{facetUnknown: 0}
+		for _, v := range f.values {
+			facet[v] = 0
+		}
+		for _, row := range rows {
+			if row.Value == "" {
+				facet[facetUnknown] += row.Count
+			} else {
+				facet[row.Value] += row.Count
+			}
+		}
+		counts[f.param] = facet
+	}
+	return counts, nil
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnimalFacets(t *testing.T) {
+	db := SetupTestDB(t)
+	user := CreateTestUser(t, db, "counselor", "counselor@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "Dog group")
+	AddUserToGroupWithAdmin(t, db, user.ID, group.ID, true)
+	params := gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}}
+
+	animal := func(name, size, energy string) *models.Animal {
+		a := CreateTestAnimal(t, db, group.ID, name, "Dog")
+		require.NoError(t, db.Model(a).Updates(map[string]interface{}{"size": size, "energy_level": energy}).Error)
+		return a
+	}
+	animal("Ace", "small", "low")
+	animal("Bella", "small", "high")
+	animal("Cooper", "medium", "low")
+	animal("Duke", "large", "low")
+	rex := animal("Rex", "", "")
+
+	list := func(query string) (int, []byte) {
+		c, w := accountTestContext(user.ID, false, http.MethodGet, "/api/groups/1/animals?saved_filter=none&"+query, nil)
+		c.Params = params
+		GetAnimals(db)(c)
+		return w.Code, w.Body.Bytes()
+	}
+	names := func(animals []models.Animal) []string {
+		out := make([]string, len(animals))
+		for i, a := range animals {
+			out[i] = a.Name
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"size=small", []string{"Ace", "Bella"}},
+		{"size=small,medium", []string{"Ace", "Bella", "Cooper"}},
+		{"size=SMALL&size=large", []string{"Ace", "Bella", "Duke"}},
+		{"size=small,medium&energy_level=low", []string{"Ace", "Cooper"}},
+		{"size=unknown", []string{"Rex"}},
+		{"energy_level=unknown,high", []string{"Bella", "Rex"}},
+	}
+	for _, tt := range tests {
+		code, body := list(tt.query)
+		require.Equal(t, http.StatusOK, code, tt.query)
+		var animals []models.Animal
+		require.NoError(t, json.Unmarshal(body, &animals), tt.query)
+		assert.Equal(t, tt.want, names(animals), tt.query)
+	}
+	for _, query := range []string{"size=huge", "energy_level=medium,frantic"} {
+		code, _ := list(query)
+		assert.Equal(t, http.StatusBadRequest, code, query)
+	}
+
+	// Each facet's counts leave out its own filter
+	code, body := list("size=small,medium&energy_level=low&facets=true")
+	require.Equal(t, http.StatusOK, code, string(body))
+	var result struct {
+		Animals []models.Animal   `json:"animals"`
+		Facets  AnimalFacetCounts `json:"facets"`
+	}
+	require.NoError(t, json.Unmarshal(body, &result))
+	assert.Equal(t, []string{"Ace", "Cooper"}, names(result.Animals))
+	assert.Equal(t, map[string]int64{"small": 1, "medium": 1, "large": 1, "unknown": 0}, result.Facets["size"])
+	assert.Equal(t, map[string]int64{"low": 2, "medium": 0, "high": 1, "unknown": 0}, result.Facets["energy_level"])
+
+	// Facet filters can be saved like the others
+	c, w := accountTestContext(user.ID, false, http.MethodPost, "/", SavedFilterRequest{Name: "Calm", Params: map[string]string{"energy_level": "low"}})
+	c.Params = params
+	CreateSavedFilter(db)(c)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	c, w = accountTestContext(user.ID, false, http.MethodPost, "/", SavedFilterRequest{Name: "Bad", Params: map[string]string{"size": "huge"}})
+	c.Params = params
+	CreateSavedFilter(db)(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	update := func(req AnimalRequest) int {
+		c, w := accountTestContext(user.ID, false, http.MethodPut, "/", req)
+		c.Params = append(params, gin.Param{Key: "animalId", Value: fmt.Sprint(rex.ID)})
+		UpdateAnimal(db, nil, &embedding.StubEmbedder{})(c)
+		return w.Code
+	}
+	large, high, bouncy := "large", "high", "bouncy"
+	require.Equal(t, http.StatusOK, update(AnimalRequest{Name: "Rex", Species: "Dog", Status: "available", Size: &large, EnergyLevel: &high}))
+	assert.Equal(t, http.StatusBadRequest, update(AnimalRequest{Name: "Rex", Species: "Dog", Status: "available", EnergyLevel: &bouncy}))
+	require.Equal(t, http.StatusOK, update(AnimalRequest{Name: "Rex", Species: "Dog", Status: "available"}))
+	var saved models.Animal
+	require.NoError(t, db.First(&saved, rex.ID).Error)
+	assert.Equal(t, "large", saved.Size, "leaving size out keeps it")
+	assert.Equal(t, "high", saved.EnergyLevel)
+}
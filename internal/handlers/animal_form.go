@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// Input types of animal form fields
+const (
+	formFieldText     = "text"
+	formFieldTextarea = "textarea"
+	formFieldNumber   = "number"
+	formFieldDate     = "date"
+	formFieldSelect   = "select"
+	formFieldImage    = "image"
+)
+
+// requirableAnimalField is an AnimalRequest field a group can require when
+// creating an animal
+type requirableAnimalField struct {
+	field    string // JSON name in AnimalRequest
+	label    string
+	kind     string // One of the formField types
+	options  []string
+	provided func(req AnimalRequest) bool
+}
+
+func nullableTimeSet(t NullableTime) bool { return t.Valid && t.Time != nil }
+
+func optionalStringSet(s *string) bool { return s != nil && strings.TrimSpace(*s) != "" }
+
+// requirableAnimalFields are the animal fields a group can require, in form
+// order. Name is always required, so it isn't listed. Age is met by a birth
+// date too, since the age is then worked out from it.
+var requirableAnimalFields = []requirableAnimalField{
+	{field: "species", label: "Species", kind: formFieldText, provided: func(r AnimalRequest) bool { return strings.TrimSpace(r.Species) != "" }},
+	{field: "breed", label: "Breed", kind: formFieldText, provided: func(r AnimalRequest) bool { return strings.TrimSpace(r.Breed) != "" }},
+	{field: "age", label: "Age", kind: formFieldNumber, provided: func(r AnimalRequest) bool {
+		return r.Age > 0 || nullableTimeSet(r.EstimatedBirthDate) || nullableTimeSet(r.BirthDate)
+	}},
+	{field: "estimated_birth_date", label: "Birth date", kind: formFieldDate, provided: func(r AnimalRequest) bool {
+		return nullableTimeSet(r.EstimatedBirthDate) || nullableTimeSet(r.BirthDate)
+	}},
+	{field: "size", label: "Size", kind: formFieldSelect, options: models.AnimalSizes, provided: func(r AnimalRequest) bool { return optionalStringSet(r.Size) }},
+	{field: "energy_level", label: "Energy level", kind: formFieldSelect, options: models.EnergyLevels, provided: func(r AnimalRequest) bool { return optionalStringSet(r.EnergyLevel) }},
+	{field: "arrival_date", label: "Arrival date", kind: formFieldDate, provided: func(r AnimalRequest) bool { return nullableTimeSet(r.ArrivalDate) }},
+	{field: "intake_source", label: "Intake source", kind: formFieldSelect, options: models.IntakeSources, provided: func(r AnimalRequest) bool { return optionalStringSet(r.IntakeSource) }},
+	{field: "microchip_number", label: "Microchip number", kind: formFieldText, provided: func(r AnimalRequest) bool { return optionalStringSet(r.MicrochipNumber) }},
+	{field: "license_number", label: "License number", kind: formFieldText, provided: func(r AnimalRequest) bool { return optionalStringSet(r.LicenseNumber) }},
+	{field: "image_url", label: "Photo", kind: formFieldImage, provided: func(r AnimalRequest) bool { return strings.TrimSpace(r.ImageURL) != "" }},
+	{field: "description", label: "Description", kind: formFieldTextarea, provided: func(r AnimalRequest) bool { return strings.TrimSpace(r.Description) != "" }},
+	{field: "trainer_notes", label: "Trainer notes", kind: formFieldTextarea, provided: func(r AnimalRequest) bool { return strings.TrimSpace(r.TrainerNotes) != "" }},
+}
+
+// AnimalFormField describes one field of a group's animal form
+type AnimalFormField struct {
+	Field        string   `json:"field"`
+	Label        string   `json:"label"`
+	Type         string   `json:"type"`
+	Options      []string `json:"options,omitempty"`
+	Required     bool     `json:"required"`
+	Configurable bool     `json:"configurable"` // Whether group admins can change Required
+}
+
+// AnimalForm describes the fields of a group's new animal form, so clients
+// can render it without hard-coding which fields the group requires
+type AnimalForm struct {
+	Fields       []AnimalFormField          `json:"fields"`
+	CustomFields []models.AnimalCustomField `json:"custom_fields"`
+}
+
+// AnimalFormRequest replaces the fields a group requires when creating an
+// animal
+type AnimalFormRequest struct {
+	Required []string `json:"required"`
+}
+
+// groupRequiredFields returns the fields a group requires on new animals
+func groupRequiredFields(db *gorm.DB, groupID uint) (map[string]bool, error) {
+	var fields []string
+	if err := db.Model(&models.GroupRequiredField{}).Where("group_id = ?", groupID).Pluck("field", &fields).Error; err != nil {
+		return nil, err
+	}
+	required := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		required[f] = true
+	}
+	return required, nil
+}
+
+// missingRequiredFields returns an error detail for each field of required
+// that req leaves out
+func missingRequiredFields(req AnimalRequest, required map[string]bool) []FieldError {
+	var missing []FieldError
+	for _, f := range requirableAnimalFields {
+		if required[f.field] && !f.provided(req) {
+			missing = append(missing, FieldError{Field: f.field, Rule: "required", Message: f.label + " is required"})
+		}
+	}
+	return missing
+}
+
+// checkRequiredAnimalFields responds 400 with a detail per missing field
+// when req leaves out a field the group requires of new animals
+func checkRequiredAnimalFields(c *gin.Context, db *gorm.DB, groupID uint, req AnimalRequest) bool {
+	required, err := groupRequiredFields(db, groupID)
+	if err != nil {
+		respondInternalError(c, "Failed to load required fields")
+		return false
+	}
+	missing := missingRequiredFields(req, required)
+	if len(missing) == 0 {
+		return true
+	}
+	fields := make([]string, len(missing))
+	for i, m := range missing {
+		fields[i] = m.Field
+	}
+	respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, "Missing required fields: "+strings.Join(fields, ", "), missing...)
+	return false
+}
+
+// groupAnimalForm builds a group's animal form
+func groupAnimalForm(db *gorm.DB, groupID uint) (AnimalForm, error) {
+	required, err := groupRequiredFields(db, groupID)
+	if err != nil {
+		return AnimalForm{}, err
+	}
+	customFields, err := groupCustomFields(db, groupID)
+	if err != nil {
+		return AnimalForm{}, err
+	}
+	form := AnimalForm{
+		Fields:       []AnimalFormField{{Field: "name", Label: "Name", Type: formFieldText, Required: true}},
+		CustomFields: customFields,
+	}
+	for _, f := range requirableAnimalFields {
+		form.Fields = append(form.Fields, AnimalFormField{
+			Field: f.field, Label: f.label, Type: f.kind, Options: f.options,
+			Required: required[f.field], Configurable: true,
+		})
+	}
+	return form, nil
+}
+
+// GetAnimalForm returns a group's new animal form: each field with its type
+// and whether the group requires it, and the group's custom fields
+// Route: GET /api/groups/:id/animal-form
+func GetAnimalForm(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+
+		form, err := groupAnimalForm(db, uint(gid))
+		if err != nil {
+			respondInternalError(c, "Failed to load animal form")
+			return
+		}
+		respondOK(c, form)
+	}
+}
+
+// UpdateAnimalForm replaces the fields a group requires when creating an
+// animal (group admin or site admin). Existing animals aren't affected.
+// Route: PUT /api/groups/:id/animal-form
+func UpdateAnimalForm(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
+			return
+		}
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+
+		var req AnimalFormRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		requirable := make(map[string]bool, len(requirableAnimalFields))
+		names := make([]string, len(requirableAnimalFields))
+		for i, f := range requirableAnimalFields {
+			requirable[f.field] = true
+			names[i] = f.field
+		}
+		rows := make([]models.GroupRequiredField, 0, len(req.Required))
+		seen := make(map[string]bool, len(req.Required))
+		for _, field := range req.Required {
+			field = strings.TrimSpace(field)
+			if !requirable[field] {
+				respondBadRequest(c, fmt.Sprintf("%q can't be required; choose from: %s", field, strings.Join(names, ", ")))
+				return
+			}
+			if !seen[field] {
+				seen[field] = true
+				rows = append(rows, models.GroupRequiredField{GroupID: uint(gid), Field: field})
+			}
+		}
+
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("group_id = ?", gid).Delete(&models.GroupRequiredField{}).Error; err != nil {
+				return err
+			}
+			if len(rows) == 0 {
+				return nil
+			}
+			return tx.Create(&rows).Error
+		}); err != nil {
+			middleware.GetLogger(c).Error("Failed to update required fields", err)
+			respondInternalError(c, "Failed to update required fields")
+			return
+		}
+
+		uid, _ := middleware.GetUserID(c)
+		fields := make([]string, len(rows))
+		for i, r := range rows {
+			fields[i] = r.Field
+		}
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventGroupUpdated, uid, map[string]interface{}{
+			"group_id":        gid,
+			"change":          "required_fields",
+			"required_fields": fields,
+		})
+
+		form, err := groupAnimalForm(db, uint(gid))
+		if err != nil {
+			respondInternalError(c, "Failed to load animal form")
+			return
+		}
+		respondOK(c, form)
+	}
+}
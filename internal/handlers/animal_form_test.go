@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnimalFormRequiredFields(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "coordinator", "coordinator@example.com", "password123", false)
+	volunteer := CreateTestUser(t, db, "volunteer", "volunteer@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "Dog group")
+	AddUserToGroupWithAdmin(t, db, admin.ID, group.ID, true)
+	AddUserToGroupWithAdmin(t, db, volunteer.ID, group.ID, false)
+	params := gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}}
+
+	update := func(userID uint, required ...string) (int, AnimalForm) {
+		c, w := accountTestContext(userID, false, http.MethodPut, "/", AnimalFormRequest{Required: required})
+		c.Params = params
+		UpdateAnimalForm(db)(c)
+		var form AnimalForm
+		_ = json.Unmarshal(w.Body.Bytes(), &form)
+		return w.Code, form
+	}
+	code, _ := update(volunteer.ID, "arrival_date")
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = update(admin.ID, "name")
+	assert.Equal(t, http.StatusBadRequest, code, "name is always required")
+	code, form := update(admin.ID, "arrival_date", "intake_source", "arrival_date")
+	require.Equal(t, http.StatusOK, code)
+
+	c, w := accountTestContext(volunteer.ID, false, http.MethodGet, "/", nil)
+	c.Params = params
+	GetAnimalForm(db)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &form))
+	required := map[string]bool{}
+	for _, f := range form.Fields {
+		if f.Required {
+			required[f.Field] = true
+		}
+		if f.Field == "name" {
+			assert.False(t, f.Configurable)
+		}
+	}
+	assert.Equal(t, map[string]bool{"name": true, "arrival_date": true, "intake_source": true}, required)
+
+	create := func(body string) (int, ErrorResponse) {
+		c, w := accountTestContext(admin.ID, false, http.MethodPost, "/", json.RawMessage(body))
+		c.Request.Header.Set("Accept", StructuredErrorsMediaType)
+		c.Params = params
+		CreateAnimal(db, nil, &embedding.StubEmbedder{})(c)
+		var resp ErrorResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+	code, resp := create(`{"name": "Rex", "species": "Dog", "intake_source": ""}`)
+	require.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, ErrCodeValidationFailed, resp.Code)
+	require.Len(t, resp.Details, 2)
+	assert.Equal(t, FieldError{Field: "arrival_date", Rule: "required", Message: "Arrival date is required"}, resp.Details[0])
+	assert.Equal(t, "intake_source", resp.Details[1].Field)
+
+	code, _ = create(`{"name": "Rex", "species": "Dog", "arrival_date": "2026-10-01", "intake_source": "stray"}`)
+	assert.Equal(t, http.StatusCreated, code)
+
+	// Clearing the requirements makes the fields optional again
+	code, _ = update(admin.ID)
+	require.Equal(t, http.StatusOK, code)
+	code, _ = create(`{"name": "Bella", "species": "Dog"}`)
+	assert.Equal(t, http.StatusCreated, code)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,8 +17,9 @@ import (
 // NullableTime is a custom type that handles empty strings from JSON
 // Empty strings are treated as nil, while valid timestamps are parsed normally
 type NullableTime struct {
-	Time  *time.Time
-	Valid bool
+	Time     *time.Time
+	Valid    bool
+	DateOnly bool // Parsed from YYYY-MM-DD, so Time is midnight UTC; see inLocation
 }
 
 // UnmarshalJSON implements custom unmarshaling for NullableTime
@@ -50,6 +52,7 @@ func (nt *NullableTime) UnmarshalJSON(data []byte) error {
 		if parsedDate, parseErr := time.Parse("2006-01-02", s); parseErr == nil {
 			nt.Time = &parsedDate
 			nt.Valid = true
+			nt.DateOnly = true
 			return nil
 		}
 		// If both fail, return the original error
@@ -71,22 +74,31 @@ func (nt NullableTime) MarshalJSON() ([]byte, error) {
 
 // AnimalRequest represents the request structure for creating/updating animals
 type AnimalRequest struct {
-	Name                      string       `json:"name" binding:"required"`
-	Species                   string       `json:"species"`
-	Breed                     string       `json:"breed"`
-	Age                       int          `json:"age"`
-	EstimatedBirthDate        NullableTime `json:"estimated_birth_date,omitempty"` // Estimated date of birth for real-time age
-	Description               string       `json:"description"`
-	TrainerNotes              string       `json:"trainer_notes"`
-	ImageURL                  string       `json:"image_url,omitempty"`
-	Status                    string       `json:"status"`
-	GroupID                   uint         `json:"group_id,omitempty"`
-	ArrivalDate               NullableTime `json:"arrival_date,omitempty"` // Date animal entered shelter
-	QuarantineStartDate       NullableTime `json:"quarantine_start_date,omitempty"`
-	QuarantineEndDate         NullableTime `json:"quarantine_end_date,omitempty"`
-	QuarantineApprovalStatus  *string      `json:"quarantine_approval_status,omitempty"`  // nil = not provided; "" | "requested" | "granted" when set
-	QuarantineIncidentDetails *string      `json:"quarantine_incident_details,omitempty"` // nil = not provided; set when entering bite quarantine
-	IsReturned                *bool        `json:"is_returned,omitempty"`                 // Pointer to distinguish null from false
+	Name                      string                 `json:"name" binding:"required"`
+	Species                   string                 `json:"species"`
+	Breed                     string                 `json:"breed"`
+	Age                       int                    `json:"age"`
+	EstimatedBirthDate        NullableTime           `json:"estimated_birth_date,omitempty"` // Estimated date of birth for real-time age
+	BirthDate                 NullableTime           `json:"birth_date,omitempty"`           // Alias for estimated_birth_date; wins when both are sent
+	Description               string                 `json:"description"`
+	TrainerNotes              string                 `json:"trainer_notes"`
+	ImageURL                  string                 `json:"image_url,omitempty"`
+	Status                    string                 `json:"status"`
+	GroupID                   uint                   `json:"group_id,omitempty"`
+	ArrivalDate               NullableTime           `json:"arrival_date,omitempty"` // Date animal entered shelter
+	QuarantineStartDate       NullableTime           `json:"quarantine_start_date,omitempty"`
+	QuarantineEndDate         NullableTime           `json:"quarantine_end_date,omitempty"`
+	QuarantineApprovalStatus  *string                `json:"quarantine_approval_status,omitempty"`  // nil = not provided; "" | "requested" | "granted" when set
+	QuarantineIncidentDetails *string                `json:"quarantine_incident_details,omitempty"` // nil = not provided; set when entering bite quarantine
+	IsReturned                *bool                  `json:"is_returned,omitempty"`                 // Pointer to distinguish null from false
+	CustomFields              map[string]interface{} `json:"custom_fields,omitempty"`               // nil = not provided; values by custom field key, null or "" clears one
+	IntakeSource              *string                `json:"intake_source,omitempty"`               // nil = not provided; one of models.IntakeSources, or "" for unknown
+	Outcome                   *string                `json:"outcome,omitempty"`                     // nil = not provided (entering an outcome status still sets it); "" clears it
+	Size                      *string                `json:"size,omitempty"`                        // nil = not provided; one of models.AnimalSizes, or "" for not recorded
+	EnergyLevel               *string                `json:"energy_level,omitempty"`                // nil = not provided; one of models.EnergyLevels, or "" for not recorded
+	MicrochipNumber           *string                `json:"microchip_number,omitempty"`            // nil = not provided; "" clears it
+	LicenseNumber             *string                `json:"license_number,omitempty"`              // nil = not provided; "" clears it
+	OverrideChecklist         bool                   `json:"override_checklist,omitempty"`          // Change status even if the group's checklist for it isn't complete
 }
 
 // DuplicateNameInfo represents information about animals with duplicate names
@@ -105,6 +117,50 @@ func isValidApprovalStatus(s *string) bool {
 	return *s == "" || *s == "requested" || *s == "granted"
 }
 
+// maxAnimalAgeYears bounds the age and birth date accepted on animal writes.
+const maxAnimalAgeYears = 40
+
+// resolveBirthDate determines the birth date to store from an AnimalRequest.
+// birth_date (or estimated_birth_date) is used when sent. Otherwise a stored
+// birth date is kept, and for an animal without one a non-zero age is turned
+// into an estimated birth date so the age keeps counting up. current is the
+// stored birth date, or nil on create. Used by CreateAnimal, UpdateAnimal, and UpdateAnimalAdmin.
+func resolveBirthDate(req AnimalRequest, current *time.Time, now time.Time) (*time.Time, error) {
+	if req.Age < 0 || req.Age > maxAnimalAgeYears {
+		return nil, fmt.Errorf("age must be between 0 and %d", maxAnimalAgeYears)
+	}
+
+	birth := req.EstimatedBirthDate
+	if req.BirthDate.Valid && req.BirthDate.Time != nil {
+		birth = req.BirthDate
+	}
+	if birth.Valid && birth.Time != nil {
+		if birth.Time.After(now) {
+			return nil, fmt.Errorf("birth date cannot be in the future")
+		}
+		if birth.Time.Before(now.AddDate(-maxAnimalAgeYears, 0, 0)) {
+			return nil, fmt.Errorf("birth date cannot be more than %d years ago", maxAnimalAgeYears)
+		}
+		return birth.Time, nil
+	}
+
+	if req.Age == 0 || current != nil {
+		return current, nil
+	}
+	estimated := models.BirthDateFromAge(req.Age, now)
+	return &estimated, nil
+}
+
+// localizeDates reads the request's date-only arrival and quarantine dates as
+// midnight in loc, the animal's group's time zone, rather than UTC. Called
+// by CreateAnimal, UpdateAnimal, and UpdateAnimalAdmin before the dates are
+// resolved, so weekend checks and stored instants use the group's calendar.
+func (req *AnimalRequest) localizeDates(loc *time.Location) {
+	req.ArrivalDate.inLocation(loc)
+	req.QuarantineStartDate.inLocation(loc)
+	req.QuarantineEndDate.inLocation(loc)
+}
+
 // resolveQuarantineEndDate returns the quarantine end date to store: an explicit
 // override from reqEnd when provided (validated against start), otherwise the
 // computed default (models.ComputeQuarantineEndDate). Used by CreateAnimal,
@@ -209,6 +265,33 @@ func quarantineEndBeforeStart(end, start time.Time) bool {
 	return endDay.Before(startDay)
 }
 
+// groupClaims returns the request's group memberships from fresh token
+// claims, if db is scoped to a request that has them
+func groupClaims(db *gorm.DB) *middleware.GroupMemberships {
+	if db.Statement == nil {
+		return nil
+	}
+	return middleware.GroupMembershipsFromContext(db.Statement.Context)
+}
+
+// claimsShowMembership reports whether the request's token claims show
+// userID in groupID, with admin rights if admin is set. False means the
+// caller has to check the database.
+func claimsShowMembership(db *gorm.DB, userID interface{}, groupID string, admin bool) bool {
+	uid, ok := userID.(uint)
+	if !ok {
+		return false
+	}
+	gid, err := strconv.ParseUint(groupID, 10, 32)
+	if err != nil {
+		return false
+	}
+	if admin {
+		return groupClaims(db).IsGroupAdmin(uid, uint(gid))
+	}
+	return groupClaims(db).IsMember(uid, uint(gid))
+}
+
 // checkGroupAccess verifies if the user has access to a specific group
 func checkGroupAccess(db *gorm.DB, userID interface{}, isAdmin interface{}, groupID string) bool {
 	adminBool, ok := isAdmin.(bool)
@@ -218,12 +301,15 @@ func checkGroupAccess(db *gorm.DB, userID interface{}, isAdmin interface{}, grou
 	if adminBool {
 		return true
 	}
+	if claimsShowMembership(db, userID, groupID, false) {
+		return true
+	}
 
 	var user models.User
 	if err := db.Preload("Groups", "id = ?", groupID).First(&user, userID).Error; err != nil {
 		return false
 	}
-	return len(user.Groups) > 0
+	return len(user.Groups) > 0 || isOrgAdminForGroup(db, userID, groupID)
 }
 
 // checkGroupAdminAccess verifies if the user has admin access to a specific group
@@ -239,6 +325,9 @@ func checkGroupAdminAccess(db *gorm.DB, userID interface{}, isAdmin interface{},
 	if adminBool {
 		return true
 	}
+	if claimsShowMembership(db, userID, groupID, true) {
+		return true
+	}
 
 	// Check if user is a group admin for this specific group
 	userIDUint, ok := userID.(uint)
@@ -247,10 +336,11 @@ func checkGroupAdminAccess(db *gorm.DB, userID interface{}, isAdmin interface{},
 	}
 
 	var userGroup models.UserGroup
-	if err := db.Where("user_id = ? AND group_id = ?", userIDUint, groupID).First(&userGroup).Error; err != nil {
-		return false
+	if err := db.Where("user_id = ? AND group_id = ?", userIDUint, groupID).First(&userGroup).Error; err == nil && userGroup.IsGroupAdmin {
+		return true
 	}
-	return userGroup.IsGroupAdmin
+	// Organization admins administer every group in their organization
+	return isOrgAdminForGroup(db, userIDUint, groupID)
 }
 
 // CheckDuplicateNames checks if any animals in a group have duplicate names
@@ -264,12 +354,12 @@ func CheckDuplicateNames(db *gorm.DB) gin.HandlerFunc {
 
 		// Check access
 		if !checkGroupAccess(db, userID, isAdmin, groupID) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
 			return
 		}
 
 		if name == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Name parameter is required"})
+			respondBadRequest(c, "Name parameter is required")
 			return
 		}
 
@@ -278,7 +368,7 @@ func CheckDuplicateNames(db *gorm.DB) gin.HandlerFunc {
 		// Includes all statuses (available, foster, quarantine, archived) to properly detect duplicates
 		query := db.Where("group_id = ? AND LOWER(name) = ?", groupID, strings.ToLower(name))
 		if err := query.Find(&animals).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check for duplicates"})
+			respondInternalError(c, "Failed to check for duplicates")
 			return
 		}
 
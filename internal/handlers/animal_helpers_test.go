@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"net/http/httptest"
 	"os"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/auth"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -39,10 +41,26 @@ func setupAnimalTestDB(t *testing.T) *gorm.DB {
 		&models.UserGroup{},
 		&models.Animal{},
 		&models.AnimalTag{},
+		&models.UserQualification{},
+		&models.AnimalStatus{},
+		&models.AnimalCustomField{},
+		&models.GroupRequiredField{},
 		&models.AnimalNameHistory{},
 		&models.AnimalBQIncident{},
+		&models.WeightEntry{},
+		&models.AnimalRelationship{},
+		&models.AnimalView{},
 		&models.AnimalImage{},
 		&models.AnimalVideo{},
+		&models.AnimalShareLink{},
+		&models.KennelCardTemplate{},
+		&models.AnimalComment{},
+		&models.CommentReaction{},
+		&models.CommentTag{},
+		&models.GroupFieldVisibility{},
+		&models.StatusChecklistItem{},
+		&models.SiteSetting{},
+		&models.Breed{},
 	)
 	if err != nil {
 		t.Fatalf("Failed to run migrations: %v", err)
@@ -339,6 +357,54 @@ func TestResolveQuarantineEndDate(t *testing.T) {
 	})
 }
 
+func TestResolveBirthDate(t *testing.T) {
+	now := time.Date(2026, 10, 16, 15, 0, 0, 0, time.UTC)
+	date := func(y int, m time.Month, d int) *time.Time {
+		v := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		return &v
+	}
+	stored := date(2020, 5, 1)
+
+	tests := []struct {
+		name    string
+		req     AnimalRequest
+		current *time.Time
+		want    *time.Time
+		wantErr string
+	}{
+		{"age only estimates a birth date", AnimalRequest{Age: 3}, nil, date(2023, 10, 16), ""},
+		{"no age keeps the stored birth date", AnimalRequest{}, stored, stored, ""},
+		{"age does not replace a stored birth date", AnimalRequest{Age: 3}, stored, stored, ""},
+		{"estimated_birth_date is used", AnimalRequest{EstimatedBirthDate: NullableTime{Time: date(2024, 1, 2), Valid: true}}, stored, date(2024, 1, 2), ""},
+		{"birth_date wins over estimated_birth_date and age", AnimalRequest{
+			Age:                7,
+			EstimatedBirthDate: NullableTime{Time: date(2024, 1, 2), Valid: true},
+			BirthDate:          NullableTime{Time: date(2025, 3, 4), Valid: true},
+		}, nil, date(2025, 3, 4), ""},
+		{"negative age is rejected", AnimalRequest{Age: -1}, nil, nil, "age must be between 0 and 40"},
+		{"implausible age is rejected", AnimalRequest{Age: 41}, nil, nil, "age must be between 0 and 40"},
+		{"future birth date is rejected", AnimalRequest{BirthDate: NullableTime{Time: date(2026, 10, 17), Valid: true}}, nil, nil, "birth date cannot be in the future"},
+		{"ancient birth date is rejected", AnimalRequest{BirthDate: NullableTime{Time: date(1980, 1, 1), Valid: true}}, nil, nil, "birth date cannot be more than 40 years ago"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveBirthDate(tt.req, tt.current, now)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got == nil || !got.Equal(*tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
 func TestResolveBQExitEndDate(t *testing.T) {
 	t.Run("explicit end date after start is honored verbatim", func(t *testing.T) {
 		start := time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)
@@ -422,3 +488,45 @@ func TestResolveBQExitEndDate(t *testing.T) {
 		}
 	})
 }
+
+func TestCheckGroupAccessUsesTokenClaims(t *testing.T) {
+	db := SetupTestDB(t)
+	user := CreateTestUser(t, db, "claims", "claims@example.com", "password123", false)
+	member := CreateTestGroup(t, db, "Member", "Group in the database")
+	claimed := CreateTestGroup(t, db, "Claimed", "Group only in the token")
+	AddUserToGroupWithAdmin(t, db, user.ID, member.ID, false)
+
+	ctx := middleware.WithGroupMemberships(context.Background(), &middleware.GroupMemberships{
+		UserID:      user.ID,
+		GroupClaims: auth.GroupClaims{GroupIDs: []uint{claimed.ID}, AdminGroupIDs: []uint{claimed.ID}},
+	})
+	scoped := db.WithContext(ctx)
+	id := func(g *models.Group) string { return fmt.Sprint(g.ID) }
+
+	// Claims answer without the database...
+	if !checkGroupAccess(scoped, user.ID, false, id(claimed)) {
+		t.Error("checkGroupAccess() = false for a group in the token claims")
+	}
+	if !checkGroupAdminAccess(scoped, user.ID, false, id(claimed)) {
+		t.Error("checkGroupAdminAccess() = false for an admin group in the token claims")
+	}
+	if !IsGroupAdmin(scoped, user.ID, claimed.ID) {
+		t.Error("IsGroupAdmin() = false for an admin group in the token claims")
+	}
+	// ...but a group missing from them is still checked there
+	if !checkGroupAccess(scoped, user.ID, false, id(member)) {
+		t.Error("checkGroupAccess() = false for a membership newer than the token")
+	}
+	if checkGroupAdminAccess(scoped, user.ID, false, id(member)) {
+		t.Error("checkGroupAdminAccess() = true for a non-admin membership")
+	}
+	// Claims only speak for their own user
+	other := CreateTestUser(t, db, "other", "other@example.com", "password123", false)
+	if checkGroupAccess(scoped, other.ID, false, id(claimed)) {
+		t.Error("checkGroupAccess() used another user's claims")
+	}
+	// Without claims, only the database counts
+	if checkGroupAccess(db, user.ID, false, id(claimed)) {
+		t.Error("checkGroupAccess() = true without claims or a membership")
+	}
+}
@@ -1,23 +1,20 @@
 package handlers
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
-	"image"
-	_ "image/gif"
-	"image/jpeg"
-	_ "image/png"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/maintenance"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/moderation"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/upload"
-	"github.com/nfnt/resize"
 	"gorm.io/gorm"
 )
 
@@ -44,16 +41,16 @@ func GetAnimalImages(db *gorm.DB) gin.HandlerFunc {
 
 		// Verify animal exists and belongs to group
 		var animal models.Animal
-		if err := db.Where("id = ? AND group_id = ?", animalID, groupID).First(&animal).Error; err != nil {
+		if err := visibleAnimals(c, db, db.Where("id = ? AND group_id = ?", animalID, groupID), groupID).First(&animal).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Animal not found"})
 			return
 		}
 
-		// Get all images for this animal (exclude the binary data for listing)
+		// Get all published images for this animal (exclude the binary data for listing)
 		var images []models.AnimalImage
 		if err := db.Preload("User").
 			Select("id, created_at, updated_at, animal_id, user_id, image_url, caption, is_profile_picture, width, height, file_size").
-			Where("animal_id = ?", animalID).
+			Where("animal_id = ? AND moderation_status = ?", animalID, models.ImageModerationApproved).
 			Order("is_profile_picture DESC, created_at DESC").
 			Find(&images).Error; err != nil {
 			logger.Error("Failed to fetch animal images", err)
@@ -67,12 +64,15 @@ func GetAnimalImages(db *gorm.DB) gin.HandlerFunc {
 
 // UploadAnimalImageToGallery handles image uploads to animal gallery (authenticated users)
 // POST /api/groups/:id/animals/:animalId/images
-// Images are stored using the configured storage provider
-func UploadAnimalImageToGallery(db *gorm.DB, storageProvider storage.Provider) gin.HandlerFunc {
+// Images are stored using the configured storage provider. Images the
+// moderator flags are saved with moderation_status "pending" and stay hidden
+// until an admin approves them.
+func UploadAnimalImageToGallery(db *gorm.DB, storageProvider storage.Provider, imageConfig *upload.ImageConfigStore, moderator moderation.Moderator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
 		db := middleware.GetDB(c, db)
 		logger := middleware.GetLogger(c)
+		cfg := imageConfig.Get(ctx)
 		groupID := c.Param("id")
 		animalID := c.Param("animalId")
 		userIDUint, ok := middleware.GetUserID(c)
@@ -90,7 +90,7 @@ func UploadAnimalImageToGallery(db *gorm.DB, storageProvider storage.Provider) g
 
 		// Verify animal exists and belongs to group
 		var animal models.Animal
-		if err := db.Where("id = ? AND group_id = ?", animalID, groupID).First(&animal).Error; err != nil {
+		if err := visibleAnimals(c, db, db.Where("id = ? AND group_id = ?", animalID, groupID), groupID).First(&animal).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Animal not found"})
 			return
 		}
@@ -104,7 +104,7 @@ func UploadAnimalImageToGallery(db *gorm.DB, storageProvider storage.Provider) g
 		}
 
 		// Validate file upload (size, type, content)
-		if err := upload.ValidateImageUpload(file, upload.MaxImageSize); err != nil {
+		if err := upload.ValidateImageUpload(file, cfg.MaxUploadBytes); err != nil {
 			logger.Error("File validation failed", err)
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file: " + err.Error()})
 			return
@@ -119,56 +119,27 @@ func UploadAnimalImageToGallery(db *gorm.DB, storageProvider storage.Provider) g
 		}
 		defer src.Close()
 
-		// Decode the image
-		img, format, err := image.Decode(src)
+		// Resize and re-encode using the configured image settings, with
+		// smaller copies for list views
+		processed, variants, err := upload.ProcessImageWithVariants(src, cfg.MaxDimension, cfg)
 		if err != nil {
-			logger.Error("Failed to decode image", err)
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image file"})
-			return
-		}
-
-		bounds := img.Bounds()
-		originalWidth := bounds.Dx()
-		originalHeight := bounds.Dy()
-
-		logger.WithFields(map[string]interface{}{
-			"format": format,
-			"width":  originalWidth,
-			"height": originalHeight,
-		}).Debug("Received image for upload")
-
-		// Resize image if it's larger than 1200px on the longest side
-		maxDimension := uint(1200)
-		var resizedImg image.Image
-
-		width := uint(originalWidth)
-		height := uint(originalHeight)
-
-		if width > maxDimension || height > maxDimension {
-			if width > height {
-				resizedImg = resize.Resize(maxDimension, 0, img, resize.Lanczos3)
-			} else {
-				resizedImg = resize.Resize(0, maxDimension, img, resize.Lanczos3)
+			if errors.Is(err, upload.ErrInvalidFile) {
+				logger.Error("Failed to decode image", err)
+				c.JSON(http.StatusBadRequest, gin.H{"error": imageDecodeErrorMessage(err)})
+				return
 			}
-			logger.WithFields(map[string]interface{}{
-				"new_width":  resizedImg.Bounds().Dx(),
-				"new_height": resizedImg.Bounds().Dy(),
-			}).Debug("Image resized")
-		} else {
-			resizedImg = img
-			logger.Debug("Image dimensions acceptable, no resizing needed")
-		}
-
-		// Encode image to JPEG bytes
-		var buf bytes.Buffer
-		if err := jpeg.Encode(&buf, resizedImg, &jpeg.Options{Quality: 85}); err != nil {
 			logger.Error("Failed to encode image", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process image"})
 			return
 		}
+		logger.WithFields(map[string]interface{}{
+			"format": processed.SourceFormat,
+			"width":  processed.Width,
+			"height": processed.Height,
+		}).Debug("Processed uploaded image")
 
-		imageData := buf.Bytes()
-		finalBounds := resizedImg.Bounds()
+		imageData := processed.Data
+		moderationStatus, moderationLabels := moderateUpload(c, moderator, imageData, processed.MimeType)
 
 		// Generate unique image identifier
 		imageUUID := uuid.New().String()
@@ -178,12 +149,12 @@ func UploadAnimalImageToGallery(db *gorm.DB, storageProvider storage.Provider) g
 
 		// Upload to storage provider
 		metadata := map[string]string{
-			"width":   strconv.Itoa(finalBounds.Dx()),
-			"height":  strconv.Itoa(finalBounds.Dy()),
+			"width":   strconv.Itoa(processed.Width),
+			"height":  strconv.Itoa(processed.Height),
 			"caption": caption,
 		}
 
-		storageURL, blobUUID, blobExt, err := storageProvider.UploadImage(ctx, imageData, "image/jpeg", metadata)
+		storageURL, blobUUID, blobExt, err := storageProvider.UploadImage(ctx, imageData, processed.MimeType, metadata)
 		var imageURL string
 		var imageDataForDB []byte
 		var storageProviderName string
@@ -213,20 +184,22 @@ func UploadAnimalImageToGallery(db *gorm.DB, storageProvider storage.Provider) g
 		animalIDVal := uint(animalIDUint)
 
 		animalImage := models.AnimalImage{
-			AnimalID:        &animalIDVal,
-			UserID:          userIDUint,
-			ImageURL:        imageURL,
-			ImageData:       imageDataForDB,
-			MimeType:        "image/jpeg",
-			Caption:         caption,
-			Width:           finalBounds.Dx(),
-			Height:          finalBounds.Dy(),
-			FileSize:        int64(len(imageData)),
-			StorageProvider: storageProviderName,
-			BlobIdentifier:  blobIdentifier,
-			BlobExtension:   blobExt,
-			CreatedAt:       time.Now(),
-			UpdatedAt:       time.Now(),
+			AnimalID:         &animalIDVal,
+			UserID:           userIDUint,
+			ImageURL:         imageURL,
+			ImageData:        imageDataForDB,
+			MimeType:         processed.MimeType,
+			Caption:          caption,
+			Width:            processed.Width,
+			Height:           processed.Height,
+			FileSize:         int64(len(imageData)),
+			StorageProvider:  storageProviderName,
+			BlobIdentifier:   blobIdentifier,
+			BlobExtension:    blobExt,
+			ModerationStatus: moderationStatus,
+			ModerationLabels: moderationLabels,
+			CreatedAt:        time.Now(),
+			UpdatedAt:        time.Now(),
 		}
 
 		if err := db.Create(&animalImage).Error; err != nil {
@@ -234,6 +207,11 @@ func UploadAnimalImageToGallery(db *gorm.DB, storageProvider storage.Provider) g
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save image"})
 			return
 		}
+		// Clients fall back to the full image for any size that's missing, so
+		// a failure here doesn't fail the upload
+		if err := maintenance.StoreImageVariants(ctx, db, storageProvider, animalImage, variants); err != nil {
+			logger.Error("Failed to save image variants", err)
+		}
 
 		// Preload user for response
 		db.Preload("User").First(&animalImage, animalImage.ID)
@@ -244,6 +222,7 @@ func UploadAnimalImageToGallery(db *gorm.DB, storageProvider storage.Provider) g
 			"url":              imageURL,
 			"size":             len(imageData),
 			"storage_provider": storageProviderName,
+			"moderation":       moderationStatus,
 		}).Info("Image uploaded and stored")
 
 		c.JSON(http.StatusOK, animalImage)
@@ -273,6 +252,12 @@ func DeleteAnimalImage(db *gorm.DB, storageProvider storage.Provider) gin.Handle
 			return
 		}
 
+		var animal models.Animal
+		if err := visibleAnimals(c, db, db.Where("id = ? AND group_id = ?", animalID, groupID), groupID).First(&animal).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Animal not found"})
+			return
+		}
+
 		// Get the image
 		var animalImage models.AnimalImage
 		if err := db.Where("id = ? AND animal_id = ?", imageID, animalID).First(&animalImage).Error; err != nil {
@@ -301,6 +286,9 @@ func DeleteAnimalImage(db *gorm.DB, storageProvider storage.Provider) gin.Handle
 				}).Warn("Failed to delete image from storage provider, continuing with database deletion")
 			}
 		}
+		if err := maintenance.DeleteImageVariants(ctx, db, storageProvider, animalImage.ID); err != nil {
+			logger.Error("Failed to delete image variants", err)
+		}
 
 		// Delete from database (soft delete)
 		if err := db.Delete(&animalImage).Error; err != nil {
@@ -336,6 +324,10 @@ func SetAnimalProfilePicture(db *gorm.DB) gin.HandlerFunc {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
 			return
 		}
+		if animalImage.ModerationStatus == models.ImageModerationPending {
+			respondError(c, http.StatusConflict, ErrCodeImagePendingReview, "This image is waiting for admin review")
+			return
+		}
 
 		// Start transaction
 		tx := db.Begin()
@@ -418,7 +410,7 @@ func SetAnimalProfilePictureGroupScoped(db *gorm.DB) gin.HandlerFunc {
 
 		// Verify the animal belongs to this group
 		var animal models.Animal
-		if err := db.Where("id = ? AND group_id = ?", animalID, groupID).First(&animal).Error; err != nil {
+		if err := visibleAnimals(c, db, db.Where("id = ? AND group_id = ?", animalID, groupID), groupID).First(&animal).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Animal not found in this group"})
 			return
 		}
@@ -429,6 +421,10 @@ func SetAnimalProfilePictureGroupScoped(db *gorm.DB) gin.HandlerFunc {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
 			return
 		}
+		if animalImage.ModerationStatus == models.ImageModerationPending {
+			respondError(c, http.StatusConflict, ErrCodeImagePendingReview, "This image is waiting for admin review")
+			return
+		}
 
 		// Start transaction
 		tx := db.Begin()
@@ -23,7 +23,7 @@ func TestSetAnimalProfilePictureGroupScoped(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Auto-migrate
-	err = db.AutoMigrate(&models.User{}, &models.Group{}, &models.UserGroup{}, &models.Animal{}, &models.AnimalImage{})
+	err = db.AutoMigrate(&models.User{}, &models.Group{}, &models.UserGroup{}, &models.Animal{}, &models.AnimalImage{}, &models.AnimalTag{}, &models.UserQualification{})
 	assert.NoError(t, err)
 
 	// Create test data
@@ -245,7 +245,7 @@ func TestSetAnimalProfilePicture_Transaction(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Auto-migrate
-	err = db.AutoMigrate(&models.User{}, &models.Group{}, &models.UserGroup{}, &models.Animal{}, &models.AnimalImage{})
+	err = db.AutoMigrate(&models.User{}, &models.Group{}, &models.UserGroup{}, &models.Animal{}, &models.AnimalImage{}, &models.AnimalTag{}, &models.UserQualification{})
 	assert.NoError(t, err)
 
 	// Create test data
@@ -324,7 +324,7 @@ func TestSetAnimalProfilePicture_ConcurrentRequests(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Auto-migrate
-	err = db.AutoMigrate(&models.User{}, &models.Group{}, &models.UserGroup{}, &models.Animal{}, &models.AnimalImage{})
+	err = db.AutoMigrate(&models.User{}, &models.Group{}, &models.UserGroup{}, &models.Animal{}, &models.AnimalImage{}, &models.AnimalTag{}, &models.UserQualification{})
 	assert.NoError(t, err)
 
 	// Create test data
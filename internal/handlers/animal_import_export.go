@@ -11,12 +11,17 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/upload"
 	"gorm.io/gorm"
 )
 
-// ExportAnimalsCSV exports animals to CSV format
+// ExportAnimalsCSV exports animals to CSV format. ?columns= limits the
+// export to the named columns, in that order, and ?exclude_pii=true leaves
+// out microchip and license numbers.
 func ExportAnimalsCSV(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		db := middleware.GetDB(c, db)
@@ -39,6 +44,25 @@ func ExportAnimalsCSV(db *gorm.DB) gin.HandlerFunc {
 			"group_id": groupID,
 		}).Info("Exporting animals to CSV")
 
+		var groupFilter *uint
+		if groupID != "" {
+			if gid, err := strconv.ParseUint(groupID, 10, 32); err == nil {
+				id := uint(gid)
+				groupFilter = &id
+			}
+		}
+		customKeys, err := animalCustomFieldKeys(db, groupFilter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch custom fields"})
+			return
+		}
+		header := animalCSVHeaderWith(customKeys)
+		columns, err := selectCSVColumns(c, header, animalCSVPIIColumns)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		// Set response headers for CSV download
 		c.Header("Content-Type", "text/csv")
 		c.Header("Content-Disposition", "attachment; filename=animals.csv")
@@ -47,33 +71,14 @@ func ExportAnimalsCSV(db *gorm.DB) gin.HandlerFunc {
 		defer writer.Flush()
 
 		// Write CSV header
-		if err := writer.Write([]string{"id", "group_id", "name", "species", "breed", "age", "estimated_birth_date", "description", "trainer_notes", "status", "image_url"}); err != nil {
+		if err := writer.Write(columns.pick(header)); err != nil {
 			logger.Error("Failed to write CSV header", err)
 			return
 		}
 
 		// Write animal data
 		for _, animal := range animals {
-			// Format estimated birth date as ISO date string
-			estimatedBirthDate := ""
-			if animal.EstimatedBirthDate != nil {
-				estimatedBirthDate = animal.EstimatedBirthDate.Format("2006-01-02")
-			}
-
-			record := []string{
-				strconv.FormatUint(uint64(animal.ID), 10),
-				strconv.FormatUint(uint64(animal.GroupID), 10),
-				animal.Name,
-				animal.Species,
-				animal.Breed,
-				strconv.Itoa(animal.Age),
-				estimatedBirthDate,
-				animal.Description,
-				animal.TrainerNotes,
-				animal.Status,
-				animal.ImageURL,
-			}
-			if err := writer.Write(record); err != nil {
+			if err := writer.Write(columns.pick(animalCSVRecord(animal, customKeys))); err != nil {
 				logger.Error("Failed to write CSV record", err)
 				return
 			}
@@ -81,8 +86,98 @@ func ExportAnimalsCSV(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-// ImportAnimalsCSV imports animals from CSV file
-func ImportAnimalsCSV(db *gorm.DB, embedder embedding.Embedder) gin.HandlerFunc {
+// animalCSVHeader is the header row of an animals export, and the columns
+// ImportAnimalsCSV reads back.
+var animalCSVHeader = []string{"id", "group_id", "name", "species", "breed", "age", "estimated_birth_date", "description", "trainer_notes", "status", "image_url", "microchip_number", "license_number", "size", "energy_level"}
+
+// customFieldColumnPrefix prefixes the CSV column of each custom field key,
+// so a field can't collide with a built-in column.
+const customFieldColumnPrefix = "custom."
+
+// animalCustomFieldKeys returns the custom field keys defined for groupID, or
+// for every group when groupID is nil, in definition order without repeats.
+func animalCustomFieldKeys(db *gorm.DB, groupID *uint) ([]string, error) {
+	query := db.Model(&models.AnimalCustomField{})
+	if groupID != nil {
+		query = query.Where("group_id = ?", *groupID)
+	}
+	var fields []models.AnimalCustomField
+	if err := query.Order("group_id, order_index, id").Find(&fields).Error; err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(fields))
+	var keys []string
+	for _, f := range fields {
+		if !seen[f.Key] {
+			seen[f.Key] = true
+			keys = append(keys, f.Key)
+		}
+	}
+	return keys, nil
+}
+
+// animalCSVHeaderWith returns animalCSVHeader followed by a column for each
+// of customKeys.
+func animalCSVHeaderWith(customKeys []string) []string {
+	header := append([]string{}, animalCSVHeader...)
+	for _, key := range customKeys {
+		header = append(header, customFieldColumnPrefix+key)
+	}
+	return header
+}
+
+// animalCSVRecord returns the export row for animal, matching
+// animalCSVHeaderWith(customKeys).
+func animalCSVRecord(animal models.Animal, customKeys []string) []string {
+	// Format estimated birth date as ISO date string
+	estimatedBirthDate := ""
+	if animal.EstimatedBirthDate != nil {
+		estimatedBirthDate = animal.EstimatedBirthDate.Format("2006-01-02")
+	}
+
+	record := []string{
+		strconv.FormatUint(uint64(animal.ID), 10),
+		strconv.FormatUint(uint64(animal.GroupID), 10),
+		animal.Name,
+		animal.Species,
+		animal.Breed,
+		strconv.Itoa(animal.Age),
+		estimatedBirthDate,
+		animal.Description,
+		animal.TrainerNotes,
+		animal.Status,
+		animal.ImageURL,
+		animal.MicrochipNumber,
+		animal.LicenseNumber,
+		animal.Size,
+		animal.EnergyLevel,
+	}
+	for _, key := range customKeys {
+		record = append(record, animal.CustomFields[key])
+	}
+	return record
+}
+
+// CSV import modes
+const (
+	importModeInsert = "insert" // Every row creates a new animal
+	importModeUpsert = "upsert" // Rows matching an existing animal update it
+)
+
+// ImportAnimalsCSV imports animals from CSV file. With ?mode=upsert, a row
+// updates the existing animal with the same external_id in its group (or,
+// without an external_id, the same name) instead of creating a duplicate.
+// Empty cells leave the existing animal's value unchanged. With
+// ?fetch_images=true, remote image_url values are downloaded, checked, and
+// stored like uploads, and the animal gets the local URL; a row whose image
+// can't be fetched is imported without it, with a warning. With
+// ?require_images=true, rows without an image are skipped instead.
+// Breeds are normalized against the managed breed list as on create; in
+// strict mode a row with an unknown breed is skipped with an error. With
+// ?dry_run=true the file is checked and nothing is saved or fetched; the
+// response counts what would be created and updated, lists the rows that
+// would be skipped, and shows what would change on up to 20 animals.
+func ImportAnimalsCSV(db *gorm.DB, embedder embedding.Embedder, storageProvider storage.Provider, imageConfig *upload.ImageConfigStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// rawDB is captured before the shadow below so the detached embed
 		// goroutines spawned below get the unscoped db, not one bound to
@@ -93,13 +188,39 @@ func ImportAnimalsCSV(db *gorm.DB, embedder embedding.Embedder) gin.HandlerFunc
 		db := middleware.GetDB(c, db)
 		logger := middleware.GetLogger(c)
 
+		mode := c.DefaultQuery("mode", importModeInsert)
+		if mode != importModeInsert && mode != importModeUpsert {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be insert or upsert"})
+			return
+		}
+
+		var format *animalImportFormat
+		if name := c.Query("format"); name != "" {
+			f, ok := animalImportFormats[strings.ToLower(name)]
+			if !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "format must be one of " + strings.Join(animalImportFormatNames(), ", ")})
+				return
+			}
+			format = &f
+		}
+		defaultGroupID := strings.TrimSpace(c.Query("group_id"))
+		fetchImages := c.Query("fetch_images") == "true"
+		requireImages := c.Query("require_images") == "true"
+		dryRun := c.Query("dry_run") == "true"
+		if fetchImages && storageProvider == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Image fetching isn't available"})
+			return
+		}
+		imageCfg := imageConfig.Get(c.Request.Context())
+		userID, _ := middleware.GetUserID(c)
+
 		file, err := c.FormFile("file")
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
 			return
 		}
 
-		logger.WithField("filename", file.Filename).Info("Processing CSV import")
+		logger.WithFields(map[string]interface{}{"filename": file.Filename, "format": c.Query("format")}).Info("Processing CSV import")
 
 		// Validate file extension
 		if !strings.HasSuffix(strings.ToLower(file.Filename), ".csv") {
@@ -125,9 +246,15 @@ func ImportAnimalsCSV(db *gorm.DB, embedder embedding.Embedder) gin.HandlerFunc
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read CSV header"})
 			return
 		}
+		var sources [][]int
+		if format != nil {
+			// Exports from other systems vary in column count per row
+			reader.FieldsPerRecord = -1
+			header, sources = format.translateHeader(header)
+		}
 
 		// Validate header has minimum required fields
-		if len(header) < 2 { // At minimum: group_id, name
+		if len(header) < 2 && defaultGroupID == "" { // At minimum: group_id, name
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid CSV format. Expected headers: group_id, name, species, breed, age, description, status, image_url"})
 			return
 		}
@@ -138,6 +265,12 @@ func ImportAnimalsCSV(db *gorm.DB, embedder embedding.Embedder) gin.HandlerFunc
 			headerMap[strings.TrimSpace(strings.ToLower(h))] = i
 		}
 
+		// Exports from other systems have no group_id column; ?group_id=
+		// puts every row in one group
+		if _, ok := headerMap["group_id"]; !ok && defaultGroupID != "" {
+			headerMap["group_id"] = len(header)
+		}
+
 		// Validate required headers
 		if _, ok := headerMap["group_id"]; !ok {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing required column: group_id"})
@@ -148,9 +281,20 @@ func ImportAnimalsCSV(db *gorm.DB, embedder embedding.Embedder) gin.HandlerFunc
 			return
 		}
 
-		var animals []models.Animal
+		breeds, err := loadBreedIndex(db)
+		if err != nil {
+			logger.Error("Failed to load breed list", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process file"})
+			return
+		}
+
+		var rows []importedAnimalRow
 		var errors []string
 		lineNum := 1
+		customFieldsByGroup := map[uint][]models.AnimalCustomField{}
+		registryLines := map[string]int{}    // "group/column/number" -> first line using it
+		fetchedImages := map[string]string{} // image_url -> local URL
+		failedImages := map[string]string{}  // image_url -> why it couldn't be fetched
 
 		// Read data rows
 		for {
@@ -164,6 +308,12 @@ func ImportAnimalsCSV(db *gorm.DB, embedder embedding.Embedder) gin.HandlerFunc
 				continue
 			}
 			lineNum++
+			if format != nil {
+				record = format.translateRecord(header, sources, record)
+			}
+			if headerMap["group_id"] == len(header) {
+				record = append(record, defaultGroupID)
+			}
 
 			// Parse group_id
 			groupIDStr := strings.TrimSpace(record[headerMap["group_id"]])
@@ -184,13 +334,19 @@ func ImportAnimalsCSV(db *gorm.DB, embedder embedding.Embedder) gin.HandlerFunc
 				GroupID: uint(groupID),
 				Name:    name,
 			}
+			if idx, ok := headerMap["external_id"]; ok && idx < len(record) {
+				animal.ExternalID = strings.TrimSpace(record[idx])
+			}
 
 			// Parse optional fields
 			if idx, ok := headerMap["species"]; ok && idx < len(record) {
 				animal.Species = strings.TrimSpace(record[idx])
 			}
 			if idx, ok := headerMap["breed"]; ok && idx < len(record) {
-				animal.Breed = strings.TrimSpace(record[idx])
+				if animal.Breed, err = breeds.normalize(animal.Species, record[idx]); err != nil {
+					errors = append(errors, fmt.Sprintf("Line %d: %s", lineNum, err.Error()))
+					continue
+				}
 			}
 			if idx, ok := headerMap["age"]; ok && idx < len(record) {
 				ageStr := strings.TrimSpace(record[idx])
@@ -237,14 +393,141 @@ func ImportAnimalsCSV(db *gorm.DB, embedder embedding.Embedder) gin.HandlerFunc
 					}
 				}
 			}
+			if animal.EstimatedBirthDate == nil && animal.Age > 0 {
+				// Store an estimate so the age keeps counting up after import
+				birthDate := models.BirthDateFromAge(animal.Age, time.Now())
+				animal.EstimatedBirthDate = &birthDate
+			}
 			if idx, ok := headerMap["trainer_notes"]; ok && idx < len(record) {
 				animal.TrainerNotes = strings.TrimSpace(record[idx])
 			}
+			if idx, ok := headerMap["size"]; ok && idx < len(record) {
+				animal.Size = strings.ToLower(strings.TrimSpace(record[idx]))
+			}
+			if idx, ok := headerMap["energy_level"]; ok && idx < len(record) {
+				animal.EnergyLevel = strings.ToLower(strings.TrimSpace(record[idx]))
+			}
+			if err := validateAnimalTraits(&animal.Size, &animal.EnergyLevel); err != nil {
+				errors = append(errors, fmt.Sprintf("Line %d: %s", lineNum, err.Error()))
+				continue
+			}
+			if idx, ok := headerMap["microchip_number"]; ok && idx < len(record) {
+				if animal.MicrochipNumber, err = normalizeMicrochip(record[idx]); err != nil {
+					errors = append(errors, fmt.Sprintf("Line %d: %s", lineNum, err.Error()))
+					continue
+				}
+			}
+			if idx, ok := headerMap["license_number"]; ok && idx < len(record) {
+				if animal.LicenseNumber, err = normalizeLicense(record[idx]); err != nil {
+					errors = append(errors, fmt.Sprintf("Line %d: %s", lineNum, err.Error()))
+					continue
+				}
+			}
+			var registryKeys []string
+			duplicateLine := 0
+			for _, number := range [][2]string{{"microchip_number", animal.MicrochipNumber}, {"license_number", animal.LicenseNumber}} {
+				if number[1] == "" {
+					continue
+				}
+				key := fmt.Sprintf("%d/%s/%s", animal.GroupID, number[0], number[1])
+				if line, seen := registryLines[key]; seen && duplicateLine == 0 {
+					duplicateLine = line
+				}
+				registryKeys = append(registryKeys, key)
+			}
+			if duplicateLine != 0 {
+				errors = append(errors, fmt.Sprintf("Line %d: Same microchip or license number as line %d", lineNum, duplicateLine))
+				continue
+			}
+			// Upsert rows are checked once it's known which animal they update
+			if mode == importModeInsert {
+				conflict, err := registryConflict(db, animal.GroupID, animal.MicrochipNumber, animal.LicenseNumber, 0)
+				if err != nil {
+					logger.Error("Failed to check registry numbers", err)
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process file"})
+					return
+				}
+				if conflict != "" {
+					errors = append(errors, fmt.Sprintf("Line %d: %s", lineNum, conflict))
+					continue
+				}
+			}
 
-			animals = append(animals, animal)
+			customInput := map[string]string{}
+			for column, idx := range headerMap {
+				if key, ok := strings.CutPrefix(column, customFieldColumnPrefix); ok && idx < len(record) {
+					if value := strings.TrimSpace(record[idx]); value != "" {
+						customInput[key] = value
+					}
+				}
+			}
+			fields, ok := customFieldsByGroup[animal.GroupID]
+			if !ok {
+				if fields, err = groupCustomFields(db, animal.GroupID); err != nil {
+					logger.Error("Failed to load custom fields", err)
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process file"})
+					return
+				}
+				customFieldsByGroup[animal.GroupID] = fields
+			}
+			// Required fields are checked here for insert mode, and by
+			// upsertImportedAnimals for rows that create an animal
+			animal.CustomFields, err = applyCustomFieldValues(fields, nil, customInput, mode == importModeInsert)
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("Line %d: %s", lineNum, err.Error()))
+				continue
+			}
+			missingRequired := ""
+			for _, f := range fields {
+				if f.Required && animal.CustomFields[f.Key] == "" {
+					missingRequired = f.Key
+					break
+				}
+			}
+
+			imageFailed := false
+			if fetchImages && !dryRun && isRemoteImageURL(animal.ImageURL) {
+				remote := animal.ImageURL
+				local, fetched := fetchedImages[remote]
+				// A URL that failed isn't fetched again for later rows
+				reason, failed := failedImages[remote]
+				if !fetched && !failed {
+					local, err = importRemoteImage(c.Request.Context(), db, storageProvider, imageCfg, remote, userID)
+					if err != nil {
+						reason, failed = err.Error(), true
+						failedImages[remote] = reason
+					} else {
+						fetchedImages[remote] = local
+					}
+				}
+				if failed {
+					errors = append(errors, fmt.Sprintf("Line %d: image_url %s", lineNum, reason))
+					imageFailed = true
+				}
+				animal.ImageURL = local
+			}
+			if requireImages && animal.ImageURL == "" {
+				if !imageFailed {
+					errors = append(errors, fmt.Sprintf("Line %d: An image_url is required", lineNum))
+				}
+				continue
+			}
+
+			row := importedAnimalRow{line: lineNum, animal: animal, set: map[string]bool{}, missingRequired: missingRequired}
+			for column, idx := range headerMap {
+				row.set[column] = idx < len(record) && strings.TrimSpace(record[idx]) != ""
+			}
+			// An upserted animal keeps its image when the new one can't be fetched
+			if imageFailed {
+				row.set["image_url"] = false
+			}
+			for _, key := range registryKeys {
+				registryLines[key] = lineNum
+			}
+			rows = append(rows, row)
 		}
 
-		if len(animals) == 0 {
+		if len(rows) == 0 {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error":  "No valid animals to import",
 				"errors": errors,
@@ -252,29 +535,115 @@ func ImportAnimalsCSV(db *gorm.DB, embedder embedding.Embedder) gin.HandlerFunc
 			return
 		}
 
-		// Insert animals in batch
-		if err := db.Create(&animals).Error; err != nil {
+		// A dry run makes the same changes and rolls them back
+		var created, updated, previous []models.Animal
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if mode == importModeUpsert {
+				var warnings []string
+				var upsertErr error
+				created, updated, previous, warnings, upsertErr = upsertImportedAnimals(tx, rows, userID)
+				errors = append(errors, warnings...)
+				if upsertErr != nil {
+					return upsertErr
+				}
+			} else {
+				created = make([]models.Animal, 0, len(rows))
+				claims := importNameClaims{}
+				for _, row := range rows {
+					conflict, nameErr := claims.conflict(tx, row.animal.GroupID, row.animal.Name, row.animal.Status, 0)
+					if nameErr != nil {
+						return nameErr
+					}
+					if conflict != "" {
+						errors = append(errors, fmt.Sprintf("Line %d: %s", row.line, conflict))
+						continue
+					}
+					created = append(created, row.animal)
+				}
+				// Insert animals in batch
+				if len(created) > 0 {
+					if err := tx.Create(&created).Error; err != nil {
+						return err
+					}
+				}
+			}
+			if dryRun {
+				return errDryRun
+			}
+			return nil
+		})
+		if err == errDryRun {
+			count := len(created) + len(updated)
+			message := fmt.Sprintf("%d animals ready to import", count)
+			if mode == importModeUpsert {
+				message = fmt.Sprintf("%d animals ready to import (%d to create, %d to update)", count, len(created), len(updated))
+			}
+			response := gin.H{
+				"message": message,
+				"dry_run": true,
+				"count":   count,
+				"created": len(created),
+				"updated": len(updated),
+				"changes": sampleAnimalDiffs(created, previous, updated),
+			}
+			if len(errors) > 0 {
+				response["warnings"] = errors
+			}
+			c.JSON(http.StatusOK, response)
+			return
+		}
+		if err != nil {
 			logger.Error("Failed to import animals", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import animals"})
 			return
 		}
 
-		// db.Create above populates each animal's ID, so these are safe to
+		// db.Create populates each animal's ID, so these are safe to
 		// embed now. Uses rawDB (not the request-scoped db) since
 		// embedAnimalAsync's goroutines outlive this request — see the same
 		// pattern in animal_crud.go's CreateAnimal.
-		for _, animal := range animals {
+		for _, animal := range append(created, updated...) {
 			embedAnimalAsync(rawDB, embedder, animal)
 		}
 
+		// Fetched images were stored unlinked, like uploads; link each to
+		// the first animal using it
+		if len(fetchedImages) > 0 {
+			for _, animal := range append(created, updated...) {
+				if animal.ImageURL == "" {
+					continue
+				}
+				if err := db.Model(&models.AnimalImage{}).
+					Where("image_url = ? AND animal_id IS NULL AND user_id = ?", animal.ImageURL, userID).
+					Update("animal_id", animal.ID).Error; err != nil {
+					logger.WithFields(map[string]interface{}{
+						"animal_id": animal.ID,
+						"image_url": animal.ImageURL,
+					}).Error("Failed to link imported image to animal", err)
+				}
+			}
+		}
+
+		count := len(created) + len(updated)
 		logger.WithFields(map[string]interface{}{
-			"count":    len(animals),
+			"mode":     mode,
+			"created":  len(created),
+			"updated":  len(updated),
 			"warnings": len(errors),
 		}).Info("Successfully imported animals from CSV")
 
 		response := gin.H{
-			"message": fmt.Sprintf("Successfully imported %d animals", len(animals)),
-			"count":   len(animals),
+			"message": fmt.Sprintf("Successfully imported %d animals", count),
+			"count":   count,
+			"created": len(created),
+			"updated": len(updated),
+			"dry_run": false,
+		}
+		if fetchImages {
+			response["images_fetched"] = len(fetchedImages)
+		}
+		if mode == importModeUpsert {
+			response["message"] = fmt.Sprintf("Successfully imported %d animals (%d created, %d updated)", count, len(created), len(updated))
 		}
 		if len(errors) > 0 {
 			response["warnings"] = errors
@@ -284,11 +653,198 @@ func ImportAnimalsCSV(db *gorm.DB, embedder embedding.Embedder) gin.HandlerFunc
 	}
 }
 
-// ExportAnimalCommentsCSV exports all animal comments with animal details to CSV format (admin only)
+// importedAnimalRow is one parsed CSV row, its line number, and which
+// columns had a value. missingRequired names a required custom field the
+// row has no value for, which only matters if the row creates an animal.
+type importedAnimalRow struct {
+	line            int
+	animal          models.Animal
+	set             map[string]bool
+	missingRequired string
+}
+
+// upsertImportedAnimals creates or updates an animal for each row. A row
+// matches the animal in its group with the same external_id; rows without
+// one, or whose external_id isn't known yet, match an animal with the same
+// name and no external_id. Rows matching several animals are skipped with
+// a warning, as are status changes into or out of bite quarantine, which
+// need incident details only the animal page collects, new animals
+// missing a required custom field, and names an active animal already has
+// in a group with unique animal names. Empty cells leave the existing value
+// unchanged. previous holds each updated animal as it was before its row.
+func upsertImportedAnimals(tx *gorm.DB, rows []importedAnimalRow, userID uint) (created, updated, previous []models.Animal, warnings []string, err error) {
+	now := time.Now()
+	claims := importNameClaims{}
+
+	for _, row := range rows {
+		in, has := row.animal, func(column string) bool { return row.set[column] }
+		var matches []models.Animal
+		if in.ExternalID != "" {
+			if err := tx.Where("group_id = ? AND external_id = ?", in.GroupID, in.ExternalID).Limit(2).Find(&matches).Error; err != nil {
+				return nil, nil, nil, nil, err
+			}
+		}
+		if len(matches) == 0 {
+			if err := tx.Where("group_id = ? AND LOWER(name) = LOWER(?) AND (external_id IS NULL OR external_id = '')", in.GroupID, in.Name).
+				Limit(2).Find(&matches).Error; err != nil {
+				return nil, nil, nil, nil, err
+			}
+		}
+
+		var excludeID uint
+		if len(matches) == 1 {
+			excludeID = matches[0].ID
+		}
+		if conflict, err := registryConflict(tx, in.GroupID, in.MicrochipNumber, in.LicenseNumber, excludeID); err != nil {
+			return nil, nil, nil, nil, err
+		} else if conflict != "" {
+			warnings = append(warnings, fmt.Sprintf("Line %d: %s", row.line, conflict))
+			continue
+		}
+
+		switch len(matches) {
+		case 0:
+			if row.missingRequired != "" {
+				warnings = append(warnings, fmt.Sprintf("Line %d: custom field %q is required for new animals", row.line, row.missingRequired))
+				continue
+			}
+			if conflict, err := claims.conflict(tx, in.GroupID, in.Name, in.Status, 0); err != nil {
+				return nil, nil, nil, nil, err
+			} else if conflict != "" {
+				warnings = append(warnings, fmt.Sprintf("Line %d: %s", row.line, conflict))
+				continue
+			}
+			if err := tx.Create(&in).Error; err != nil {
+				return nil, nil, nil, nil, err
+			}
+			created = append(created, in)
+			continue
+		case 1:
+		default:
+			warnings = append(warnings, fmt.Sprintf("Line %d: Matches more than one animal named '%s'; add an external_id to choose one", row.line, in.Name))
+			continue
+		}
+
+		existing := matches[0]
+		finalStatus := existing.Status
+		if has("status") {
+			finalStatus = in.Status
+		}
+		if animalNameClaimChanged(existing, in.Name, existing.GroupID, finalStatus) {
+			if conflict, err := claims.conflict(tx, existing.GroupID, in.Name, finalStatus, existing.ID); err != nil {
+				return nil, nil, nil, nil, err
+			} else if conflict != "" {
+				warnings = append(warnings, fmt.Sprintf("Line %d: %s", row.line, conflict))
+				continue
+			}
+		}
+		changes := map[string]interface{}{}
+		if in.ExternalID != "" {
+			changes["external_id"] = in.ExternalID
+		}
+		if in.Name != existing.Name {
+			changes["name"] = in.Name
+		}
+		if has("species") {
+			changes["species"] = in.Species
+		}
+		if has("breed") {
+			changes["breed"] = in.Breed
+		}
+		// An age-only row doesn't replace a stored birth date
+		if in.EstimatedBirthDate != nil && (has("estimated_birth_date") || existing.EstimatedBirthDate == nil) {
+			changes["estimated_birth_date"] = in.EstimatedBirthDate
+			changes["age"] = in.Age
+		} else if has("age") {
+			changes["age"] = in.Age
+		}
+		if has("description") {
+			changes["description"] = in.Description
+		}
+		if has("trainer_notes") {
+			changes["trainer_notes"] = in.TrainerNotes
+		}
+		if has("image_url") {
+			changes["image_url"] = in.ImageURL
+		}
+		if has("microchip_number") {
+			changes["microchip_number"] = in.MicrochipNumber
+		}
+		if has("license_number") {
+			changes["license_number"] = in.LicenseNumber
+		}
+		if has("size") {
+			changes["size"] = in.Size
+		}
+		if has("energy_level") {
+			changes["energy_level"] = in.EnergyLevel
+		}
+		if len(in.CustomFields) > 0 {
+			customFields := models.AnimalCustomValues{}
+			for key, value := range existing.CustomFields {
+				customFields[key] = value
+			}
+			for key, value := range in.CustomFields {
+				customFields[key] = value
+			}
+			changes["custom_fields"] = customFields
+		}
+		if has("status") && in.Status != existing.Status {
+			if existing.LegalHold {
+				warnings = append(warnings, fmt.Sprintf("Line %d: Can't change the status of '%s' while it's under a legal hold", row.line, existing.Name))
+				continue
+			}
+			if in.Status == "bite_quarantine" || existing.Status == "bite_quarantine" {
+				warnings = append(warnings, fmt.Sprintf("Line %d: Can't change the status of '%s' to or from bite_quarantine by import", row.line, existing.Name))
+				continue
+			}
+			changes["status"] = in.Status
+			changes["last_status_change"] = now
+			switch in.Status {
+			case "foster":
+				changes["foster_start_date"] = now
+			case "archived":
+				changes["archived_date"] = now
+			case "available":
+				changes["foster_start_date"] = nil
+				changes["archived_date"] = nil
+				if existing.Status == "archived" {
+					changes["arrival_date"] = now
+				}
+			}
+		}
+
+		if len(changes) > 0 {
+			if err := tx.Model(&models.Animal{}).Where("id = ?", existing.ID).Updates(changes).Error; err != nil {
+				return nil, nil, nil, nil, err
+			}
+		}
+		if _, renamed := changes["name"]; renamed {
+			if err := tx.Create(&models.AnimalNameHistory{
+				AnimalID:  existing.ID,
+				OldName:   existing.Name,
+				NewName:   in.Name,
+				ChangedBy: userID,
+				Source:    models.NameChangeImport,
+			}).Error; err != nil {
+				return nil, nil, nil, nil, err
+			}
+		}
+		previous = append(previous, existing)
+		if err := tx.First(&existing, existing.ID).Error; err != nil {
+			return nil, nil, nil, nil, err
+		}
+		updated = append(updated, existing)
+	}
+	return created, updated, previous, warnings, nil
+}
+
+// ExportAnimalCommentsCSV exports all animal comments with animal details to CSV format (admin only).
+// ?columns= and ?exclude_pii=true choose columns as in ExportAnimalsCSV; the
+// PII column is comment_author.
 func ExportAnimalCommentsCSV(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		db := middleware.GetDB(c, db)
-		logger := middleware.GetLogger(c)
 		groupID := c.Query("group_id")
 		animalID := c.Query("animal_id")
 		tagFilter := c.Query("tags") // Comma-separated tag names
@@ -310,131 +866,204 @@ func ExportAnimalCommentsCSV(db *gorm.DB) gin.HandlerFunc {
 			query = applyTagFilter(query, splitAndTrim(tagFilter))
 		}
 
-		var comments []models.AnimalComment
-		if err := query.Order("animal_comments.created_at DESC").Find(&comments).Error; err != nil {
-			logger.Error("Failed to fetch comments", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+		writeAnimalCommentsCSV(c, db, query, "animal-comments.csv", groupID, animalID, tagFilter)
+	}
+}
+
+// ExportGroupAnimalCommentsCSV exports comments on one group's animals to CSV
+// format (group admin or site admin). animal_id and tags filters behave as in
+// ExportAnimalCommentsCSV but can never reach outside the group; columns and
+// exclude_pii choose columns the same way.
+// Route: GET /api/groups/:id/animals/export-comments-csv
+func ExportGroupAnimalCommentsCSV(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		animalID := c.Query("animal_id")
+		tagFilter := c.Query("tags")
+
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
 			return
 		}
 
-		// Load animal details for each comment
-		animalIDs := make([]uint, 0, len(comments))
-		for _, comment := range comments {
-			animalIDs = append(animalIDs, comment.AnimalID)
+		if !IsGroupAdminOrSiteAdmin(c, db, uint(gid)) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Group admin access required")
+			return
 		}
 
-		// Get all animals in one query
-		var animals []models.Animal
-		if len(animalIDs) > 0 {
-			if err := db.Where("id IN ?", animalIDs).Find(&animals).Error; err != nil {
-				logger.Error("Failed to fetch animals", err)
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch animal details"})
-				return
-			}
+		// The group join is unconditional so an animal_id from another
+		// group yields an empty export rather than that group's comments.
+		query := db.Preload("User").Preload("Tags").
+			Joins("JOIN animals ON animals.id = animal_comments.animal_id").
+			Where("animals.group_id = ?", gid)
+		if animalID != "" {
+			query = query.Where("animal_comments.animal_id = ?", animalID)
 		}
-
-		// Create animal lookup map
-		animalMap := make(map[uint]models.Animal)
-		for _, animal := range animals {
-			animalMap[animal.ID] = animal
+		if tagFilter != "" {
+			query = applyTagFilter(query, splitAndTrim(tagFilter))
 		}
 
-		// Get group details for animals
-		groupIDs := make([]uint, 0)
-		for _, animal := range animals {
-			groupIDs = append(groupIDs, animal.GroupID)
-		}
+		filename := fmt.Sprintf("animal-comments-group-%d.csv", gid)
+		writeAnimalCommentsCSV(c, db, query, filename, groupID, animalID, tagFilter)
+	}
+}
 
-		var groups []models.Group
-		if len(groupIDs) > 0 {
-			if err := db.Where("id IN ?", groupIDs).Find(&groups).Error; err != nil {
-				logger.Error("Failed to fetch groups", err)
-				// Continue without group names
-			}
-		}
+// writeAnimalCommentsCSV runs query and streams the matching comments, with
+// their animal and group details, as a CSV attachment named filename. The
+// filter arguments are only used for logging.
+func writeAnimalCommentsCSV(c *gin.Context, db *gorm.DB, query *gorm.DB, filename, groupID, animalID, tagFilter string) {
+	logger := middleware.GetLogger(c)
 
-		// Create group lookup map
-		groupMap := make(map[uint]string)
-		for _, group := range groups {
-			groupMap[group.ID] = group.Name
-		}
+	columns, err := selectCSVColumns(c, animalCommentCSVHeader, animalCommentCSVPIIColumns)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-		logger.WithFields(map[string]interface{}{
-			"comment_count": len(comments),
-			"group_id":      groupID,
-			"animal_id":     animalID,
-			"tag_filter":    tagFilter,
-		}).Info("Exporting animal comments to CSV")
+	var comments []models.AnimalComment
+	if err := query.Order("animal_comments.created_at DESC").Find(&comments).Error; err != nil {
+		logger.Error("Failed to fetch comments", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+		return
+	}
 
-		// Set response headers for CSV download
-		c.Header("Content-Type", "text/csv")
-		c.Header("Content-Disposition", "attachment; filename=animal-comments.csv")
+	animalMap, groupMap, err := loadAnimalCommentCSVDetails(db, comments)
+	if err != nil {
+		logger.Error("Failed to fetch animals", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch animal details"})
+		return
+	}
 
-		writer := csv.NewWriter(c.Writer)
-		defer writer.Flush()
+	logger.WithFields(map[string]interface{}{
+		"comment_count": len(comments),
+		"group_id":      groupID,
+		"animal_id":     animalID,
+		"tag_filter":    tagFilter,
+	}).Info("Exporting animal comments to CSV")
 
-		// Write CSV header
-		header := []string{
-			"comment_id",
-			"animal_id",
-			"animal_name",
-			"animal_species",
-			"animal_breed",
-			"animal_status",
-			"group_id",
-			"group_name",
-			"comment_content",
-			"comment_author",
-			"comment_tags",
-			"created_at",
-			"updated_at",
-		}
-		if err := writer.Write(header); err != nil {
-			logger.Error("Failed to write CSV header", err)
+	// Set response headers for CSV download
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+
+	writer := csv.NewWriter(c.Writer)
+	defer writer.Flush()
+
+	// Write CSV header
+	if err := writer.Write(columns.pick(animalCommentCSVHeader)); err != nil {
+		logger.Error("Failed to write CSV header", err)
+		return
+	}
+
+	// Write comment data
+	for _, comment := range comments {
+		animal, ok := animalMap[comment.AnimalID]
+		if !ok {
+			// Skip if animal not found
+			continue
+		}
+		if err := writer.Write(columns.pick(animalCommentCSVRecord(comment, animal, groupMap[animal.GroupID]))); err != nil {
+			logger.Error("Failed to write CSV record", err)
 			return
 		}
+	}
+}
 
-		// Write comment data
-		for _, comment := range comments {
-			animal, ok := animalMap[comment.AnimalID]
-			if !ok {
-				// Skip if animal not found
-				continue
-			}
+// animalCommentCSVHeader is the header row of a comments export.
+var animalCommentCSVHeader = []string{
+	"comment_id",
+	"animal_id",
+	"animal_name",
+	"animal_species",
+	"animal_breed",
+	"animal_status",
+	"group_id",
+	"group_name",
+	"comment_content",
+	"comment_author",
+	"comment_tags",
+	"created_at",
+	"updated_at",
+}
 
-			groupName := groupMap[animal.GroupID]
+// loadAnimalCommentCSVDetails loads the animals the comments belong to, by
+// ID, and their group names. Failing to load group names isn't an error; the
+// column is left blank.
+func loadAnimalCommentCSVDetails(db *gorm.DB, comments []models.AnimalComment) (map[uint]models.Animal, map[uint]string, error) {
+	// Load animal details for each comment
+	animalIDs := make([]uint, 0, len(comments))
+	for _, comment := range comments {
+		animalIDs = append(animalIDs, comment.AnimalID)
+	}
 
-			// Collect tag names
-			tagNames := make([]string, 0, len(comment.Tags))
-			for _, tag := range comment.Tags {
-				tagNames = append(tagNames, tag.Name)
-			}
-			tagsStr := strings.Join(tagNames, "; ")
+	// Get all animals in one query
+	var animals []models.Animal
+	if len(animalIDs) > 0 {
+		if err := db.Where("id IN ?", animalIDs).Find(&animals).Error; err != nil {
+			return nil, nil, err
+		}
+	}
 
-			authorName := ""
-			if comment.User.Username != "" {
-				authorName = comment.User.Username
-			}
+	// Create animal lookup map
+	animalMap := make(map[uint]models.Animal)
+	for _, animal := range animals {
+		animalMap[animal.ID] = animal
+	}
 
-			record := []string{
-				strconv.FormatUint(uint64(comment.ID), 10),
-				strconv.FormatUint(uint64(animal.ID), 10),
-				animal.Name,
-				animal.Species,
-				animal.Breed,
-				animal.Status,
-				strconv.FormatUint(uint64(animal.GroupID), 10),
-				groupName,
-				comment.Content,
-				authorName,
-				tagsStr,
-				comment.CreatedAt.Format(time.RFC3339),
-				comment.UpdatedAt.Format(time.RFC3339),
-			}
-			if err := writer.Write(record); err != nil {
-				logger.Error("Failed to write CSV record", err)
-				return
-			}
+	// Get group details for animals
+	groupIDs := make([]uint, 0)
+	for _, animal := range animals {
+		groupIDs = append(groupIDs, animal.GroupID)
+	}
+
+	var groups []models.Group
+	if len(groupIDs) > 0 {
+		if err := db.Where("id IN ?", groupIDs).Find(&groups).Error; err != nil {
+			logging.WithContext(db.Statement.Context).Error("Failed to fetch groups", err)
+			// Continue without group names
 		}
 	}
+
+	// Create group lookup map
+	groupMap := make(map[uint]string)
+	for _, group := range groups {
+		groupMap[group.ID] = group.Name
+	}
+	return animalMap, groupMap, nil
+}
+
+// commentAuthor names who wrote comment: the original author of an imported
+// comment, or its user
+func commentAuthor(comment models.AnimalComment) string {
+	if comment.AuthorName != "" {
+		return comment.AuthorName
+	}
+	return comment.User.Username
+}
+
+// animalCommentCSVRecord returns the export row for comment, matching
+// animalCommentCSVHeader.
+func animalCommentCSVRecord(comment models.AnimalComment, animal models.Animal, groupName string) []string {
+	// Collect tag names
+	tagNames := make([]string, 0, len(comment.Tags))
+	for _, tag := range comment.Tags {
+		tagNames = append(tagNames, tag.Name)
+	}
+
+	return []string{
+		strconv.FormatUint(uint64(comment.ID), 10),
+		strconv.FormatUint(uint64(animal.ID), 10),
+		animal.Name,
+		animal.Species,
+		animal.Breed,
+		animal.Status,
+		strconv.FormatUint(uint64(animal.GroupID), 10),
+		groupName,
+		comment.Content,
+		commentAuthor(comment),
+		strings.Join(tagNames, "; "),
+		comment.CreatedAt.Format(time.RFC3339),
+		comment.UpdatedAt.Format(time.RFC3339),
+	}
 }
@@ -11,8 +11,10 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/gin-gonic/gin"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
 )
 
 // TestExportAnimalsCSV_Success tests successful CSV export
@@ -59,7 +61,7 @@ func TestExportAnimalsCSV_Success(t *testing.T) {
 	}
 
 	// Check header
-	expectedHeader := []string{"id", "group_id", "name", "species", "breed", "age", "estimated_birth_date", "description", "trainer_notes", "status", "image_url"}
+	expectedHeader := []string{"id", "group_id", "name", "species", "breed", "age", "estimated_birth_date", "description", "trainer_notes", "status", "image_url", "microchip_number", "license_number", "size", "energy_level"}
 	if len(records[0]) != len(expectedHeader) {
 		t.Errorf("Expected %d header columns, got %d", len(expectedHeader), len(records[0]))
 	}
@@ -128,7 +130,7 @@ func TestImportAnimalsCSV_Success(t *testing.T) {
 	c.Request = httptest.NewRequest("POST", "/api/v1/admin/animals/import-csv", body)
 	c.Request.Header.Set("Content-Type", writer.FormDataContentType())
 
-	handler := ImportAnimalsCSV(db, &embedding.StubEmbedder{})
+	handler := ImportAnimalsCSV(db, &embedding.StubEmbedder{}, nil, nil)
 	handler(c)
 
 	if w.Code != http.StatusOK {
@@ -174,7 +176,7 @@ func TestImportAnimalsCSV_UnderVetCareStatus(t *testing.T) {
 	c.Request = httptest.NewRequest("POST", "/api/v1/admin/animals/import-csv", body)
 	c.Request.Header.Set("Content-Type", writer.FormDataContentType())
 
-	handler := ImportAnimalsCSV(db, &embedding.StubEmbedder{})
+	handler := ImportAnimalsCSV(db, &embedding.StubEmbedder{}, nil, nil)
 	handler(c)
 
 	if w.Code != http.StatusOK {
@@ -191,6 +193,108 @@ func TestImportAnimalsCSV_UnderVetCareStatus(t *testing.T) {
 	}
 }
 
+// importAnimalsCSVForTest posts csvContent to ImportAnimalsCSV with the given
+// query string and returns the recorder
+func importAnimalsCSVForTest(t *testing.T, db *gorm.DB, userID uint, query, csvContent string) *httptest.ResponseRecorder {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "animals.csv")
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write([]byte(csvContent))
+	writer.Close()
+
+	c, w := setupAnimalTestContext(userID, true)
+	c.Request = httptest.NewRequest("POST", "/api/v1/admin/animals/import-csv"+query, body)
+	c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	ImportAnimalsCSV(db, &embedding.StubEmbedder{}, nil, nil)(c)
+	return w
+}
+
+// TestImportAnimalsCSV_Upsert tests that re-importing updates existing animals
+func TestImportAnimalsCSV_Upsert(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	user, group := createAnimalTestUser(t, db, "admin", "admin@example.com", true)
+	legacy := createTestAnimal(t, db, group.ID, "Fluffy", "Cat")
+
+	first := fmt.Sprintf(`external_id,group_id,name,species,breed,status
+A-100,%d,Rex,Dog,Golden Retriever,available
+,%d,fluffy,Cat,Persian,`, group.ID, group.ID)
+	w := importAnimalsCSVForTest(t, db, user.ID, "?mode=upsert", first)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response["created"].(float64) != 1 || response["updated"].(float64) != 1 {
+		t.Errorf("Expected 1 created and 1 updated, got %v", response)
+	}
+
+	// The existing animal was matched by name, and its empty status cell
+	// left the status alone
+	var fluffy models.Animal
+	db.First(&fluffy, legacy.ID)
+	if fluffy.Breed != "Persian" || fluffy.Status != legacy.Status {
+		t.Errorf("Expected Fluffy's breed updated and status unchanged, got breed '%s' status '%s'", fluffy.Breed, fluffy.Status)
+	}
+
+	// Re-importing matches on external_id, so a rename doesn't duplicate
+	second := fmt.Sprintf(`external_id,group_id,name,status
+A-100,%d,Rexy,foster`, group.ID)
+	w = importAnimalsCSVForTest(t, db, user.ID, "?mode=upsert", second)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var animals []models.Animal
+	db.Where("group_id = ?", group.ID).Order("id").Find(&animals)
+	if len(animals) != 2 {
+		t.Fatalf("Expected 2 animals after re-import, got %d", len(animals))
+	}
+	rex := animals[1]
+	if rex.Name != "Rexy" || rex.Breed != "Golden Retriever" || rex.Status != "foster" || rex.FosterStartDate == nil {
+		t.Errorf("Expected Rex renamed to Rexy and moved to foster with breed kept, got %+v", rex)
+	}
+	var history []models.AnimalNameHistory
+	db.Where("animal_id = ?", rex.ID).Find(&history)
+	if len(history) != 1 || history[0].OldName != "Rex" {
+		t.Errorf("Expected one name history entry from Rex, got %v", history)
+	}
+}
+
+// TestImportAnimalsCSV_UpsertAmbiguousName tests that a name matching several
+// animals is skipped rather than guessed
+func TestImportAnimalsCSV_UpsertAmbiguousName(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	user, group := createAnimalTestUser(t, db, "admin", "admin@example.com", true)
+	createTestAnimal(t, db, group.ID, "Buddy", "Dog")
+	createTestAnimal(t, db, group.ID, "Buddy", "Dog")
+
+	csvContent := fmt.Sprintf(`group_id,name,breed
+%d,Buddy,Beagle
+%d,Max,Boxer`, group.ID, group.ID)
+	w := importAnimalsCSVForTest(t, db, user.ID, "?mode=upsert", csvContent)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "more than one animal") {
+		t.Errorf("Expected an ambiguity warning, got %s", w.Body.String())
+	}
+
+	var beagles int64
+	db.Model(&models.Animal{}).Where("breed = ?", "Beagle").Count(&beagles)
+	if beagles != 0 {
+		t.Errorf("Expected ambiguous row to be skipped, got %d updated", beagles)
+	}
+
+	w = importAnimalsCSVForTest(t, db, user.ID, "?mode=merge", csvContent)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an unknown mode, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
 // TestImportAnimalsCSV_InvalidFile tests importing non-CSV file
 func TestImportAnimalsCSV_InvalidFile(t *testing.T) {
 	db := setupAnimalTestDB(t)
@@ -207,7 +311,7 @@ func TestImportAnimalsCSV_InvalidFile(t *testing.T) {
 	c.Request = httptest.NewRequest("POST", "/api/v1/admin/animals/import-csv", body)
 	c.Request.Header.Set("Content-Type", writer.FormDataContentType())
 
-	handler := ImportAnimalsCSV(db, &embedding.StubEmbedder{})
+	handler := ImportAnimalsCSV(db, &embedding.StubEmbedder{}, nil, nil)
 	handler(c)
 
 	if w.Code != http.StatusBadRequest {
@@ -240,7 +344,7 @@ func TestImportAnimalsCSV_MissingRequiredColumn(t *testing.T) {
 	c.Request = httptest.NewRequest("POST", "/api/v1/admin/animals/import-csv", body)
 	c.Request.Header.Set("Content-Type", writer.FormDataContentType())
 
-	handler := ImportAnimalsCSV(db, &embedding.StubEmbedder{})
+	handler := ImportAnimalsCSV(db, &embedding.StubEmbedder{}, nil, nil)
 	handler(c)
 
 	if w.Code != http.StatusBadRequest {
@@ -275,7 +379,7 @@ invalid,Rex,Dog
 	c.Request = httptest.NewRequest("POST", "/api/v1/admin/animals/import-csv", body)
 	c.Request.Header.Set("Content-Type", writer.FormDataContentType())
 
-	handler := ImportAnimalsCSV(db, &embedding.StubEmbedder{})
+	handler := ImportAnimalsCSV(db, &embedding.StubEmbedder{}, nil, nil)
 	handler(c)
 
 	if w.Code != http.StatusOK {
@@ -309,7 +413,7 @@ func TestImportAnimalsCSV_NoFile(t *testing.T) {
 	c, w := setupAnimalTestContext(user.ID, true)
 	c.Request = httptest.NewRequest("POST", "/api/v1/admin/animals/import-csv", nil)
 
-	handler := ImportAnimalsCSV(db, &embedding.StubEmbedder{})
+	handler := ImportAnimalsCSV(db, &embedding.StubEmbedder{}, nil, nil)
 	handler(c)
 
 	if w.Code != http.StatusBadRequest {
@@ -495,3 +599,130 @@ func TestExportAnimalCommentsCSV_WithAnimalFilter(t *testing.T) {
 		t.Error("Expected comment for Rex in output")
 	}
 }
+
+// TestExportGroupAnimalCommentsCSV tests the group-scoped comment export
+func TestExportGroupAnimalCommentsCSV(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	db.AutoMigrate(&models.CommentTag{}, &models.AnimalComment{})
+
+	groupAdmin, group1 := createAnimalTestUser(t, db, "groupadmin", "groupadmin@example.com", false)
+	member, group2 := createAnimalTestUser(t, db, "member", "member@example.com", false)
+	db.Create(&models.UserGroup{UserID: member.ID, GroupID: group1.ID})
+
+	animal1 := createTestAnimal(t, db, group1.ID, "Rex", "Dog")
+	animal2 := createTestAnimal(t, db, group2.ID, "Fluffy", "Cat")
+	db.Create(&models.AnimalComment{AnimalID: animal1.ID, UserID: groupAdmin.ID, Content: "Comment for Rex"})
+	db.Create(&models.AnimalComment{AnimalID: animal2.ID, UserID: member.ID, Content: "Comment for Fluffy"})
+
+	export := func(userID uint, isAdmin bool, query string) (int, [][]string) {
+		c, w := setupAnimalTestContext(userID, isAdmin)
+		c.Params = gin.Params{{Key: "id", Value: fmt.Sprintf("%d", group1.ID)}}
+		c.Request = httptest.NewRequest("GET", fmt.Sprintf("/api/groups/%d/animals/export-comments-csv%s", group1.ID, query), nil)
+		ExportGroupAnimalCommentsCSV(db)(c)
+		if w.Code != http.StatusOK {
+			return w.Code, nil
+		}
+		records, err := csv.NewReader(w.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("Failed to parse CSV: %v", err)
+		}
+		return w.Code, records
+	}
+
+	t.Run("group admin gets only their group's comments", func(t *testing.T) {
+		code, records := export(groupAdmin.ID, false, "")
+		if code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, code)
+		}
+		if len(records) != 2 || !strings.Contains(records[1][8], "Comment for Rex") {
+			t.Errorf("Expected only the comment for Rex, got %v", records)
+		}
+	})
+
+	t.Run("animal_id outside the group is not exported", func(t *testing.T) {
+		code, records := export(groupAdmin.ID, false, fmt.Sprintf("?animal_id=%d", animal2.ID))
+		if code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, code)
+		}
+		if len(records) != 1 {
+			t.Errorf("Expected header only, got %d rows", len(records))
+		}
+	})
+
+	t.Run("regular member is forbidden", func(t *testing.T) {
+		if code, _ := export(member.ID, false, ""); code != http.StatusForbidden {
+			t.Errorf("Expected status %d, got %d", http.StatusForbidden, code)
+		}
+	})
+
+	t.Run("site admin is allowed", func(t *testing.T) {
+		if code, _ := export(member.ID, true, ""); code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, code)
+		}
+	})
+}
+
+// TestExportAnimalsCSV_ColumnSelection tests ?columns= and ?exclude_pii=true
+func TestExportAnimalsCSV_ColumnSelection(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	user, group := createAnimalTestUser(t, db, "admin", "admin@example.com", true)
+	animal := createTestAnimal(t, db, group.ID, "Rex", "Dog")
+	db.Model(animal).Updates(map[string]interface{}{"status": "available", "microchip_number": "985112345678901"})
+
+	export := func(query string) (int, [][]string) {
+		c, w := setupAnimalTestContext(user.ID, true)
+		c.Request = httptest.NewRequest("GET", "/api/v1/admin/animals/export-csv"+query, nil)
+		ExportAnimalsCSV(db)(c)
+		if w.Code != http.StatusOK {
+			return w.Code, nil
+		}
+		records, err := csv.NewReader(w.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("Failed to parse CSV: %v", err)
+		}
+		return w.Code, records
+	}
+
+	_, records := export("?columns=status,name,status")
+	if want := [][]string{{"status", "name"}, {"available", "Rex"}}; fmt.Sprint(records) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, records)
+	}
+
+	_, records = export("?exclude_pii=true")
+	for _, column := range records[0] {
+		if column == "microchip_number" || column == "license_number" {
+			t.Errorf("Expected %s to be excluded, got header %v", column, records[0])
+		}
+	}
+	if len(records[0]) != len(animalCSVHeader)-2 {
+		t.Errorf("Expected %d columns, got %d", len(animalCSVHeader)-2, len(records[0]))
+	}
+
+	for _, query := range []string{"?columns=name,owner", "?columns=name,microchip_number&exclude_pii=true", "?columns=,"} {
+		if code, _ := export(query); code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", query, http.StatusBadRequest, code)
+		}
+	}
+}
+
+// TestExportAnimalCommentsCSV_ExcludePII tests that exclude_pii drops comment authors
+func TestExportAnimalCommentsCSV_ExcludePII(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	db.AutoMigrate(&models.CommentTag{}, &models.AnimalComment{})
+	user, group := createAnimalTestUser(t, db, "admin", "admin@example.com", true)
+	animal := createTestAnimal(t, db, group.ID, "Rex", "Dog")
+	db.Create(&models.AnimalComment{AnimalID: animal.ID, UserID: user.ID, Content: "Walked well"})
+
+	c, w := setupAnimalTestContext(user.ID, true)
+	c.Request = httptest.NewRequest("GET", "/api/v1/admin/animals/export-comments-csv?exclude_pii=true", nil)
+	ExportAnimalCommentsCSV(db)(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "comment_author") || strings.Contains(w.Body.String(), ",admin,") {
+		t.Errorf("Expected no author in export, got:\n%s", w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "Walked well") {
+		t.Errorf("Expected comment content in export, got:\n%s", w.Body.String())
+	}
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// animalImportFormat translates another shelter system's CSV export into
+// the columns and values ImportAnimalsCSV reads
+type animalImportFormat struct {
+	columns     map[string]string // Export header, lower case -> our column. Several headers may feed one column.
+	statuses    map[string]string // Export status, lower case -> our status
+	dateLayouts []string          // Layouts the export writes dates in
+	ageInMonths bool              // A bare number in the age column counts months, not years
+}
+
+// usDateLayouts are how US shelter software writes dates in its exports
+var usDateLayouts = []string{"01/02/2006", "1/2/2006", "01/02/2006 15:04", "1/2/2006 3:04 PM", "01/02/2006 03:04:05 PM", "2006-01-02", "2006-01-02 15:04:05"}
+
+// animalImportFormats are the exports ImportAnimalsCSV accepts with
+// ?format=. Statuses for animals that have left care map to archived.
+var animalImportFormats = map[string]animalImportFormat{
+	"petpoint": {
+		columns: map[string]string{
+			"animal #":         "external_id",
+			"animal id":        "external_id",
+			"animal name":      "name",
+			"species":          "species",
+			"primary breed":    "breed",
+			"secondary breed":  "breed",
+			"age":              "age",
+			"date of birth":    "estimated_birth_date",
+			"stage":            "status",
+			"microchip number": "microchip_number",
+			"microchip #":      "microchip_number",
+			"description":      "description",
+		},
+		statuses: map[string]string{
+			"available":                "available",
+			"adoptable":                "available",
+			"evaluate":                 "available",
+			"in foster":                "foster",
+			"foster":                   "foster",
+			"bite hold":                "bite_quarantine",
+			"bite quarantine":          "bite_quarantine",
+			"quarantine":               "bite_quarantine",
+			"medical hold":             "under_vet_care",
+			"hold - medical":           "under_vet_care",
+			"in surgery":               "under_vet_care",
+			"adopted":                  "archived",
+			"released":                 "archived",
+			"returned to owner":        "archived",
+			"transferred out":          "archived",
+			"euthanized":               "archived",
+			"died":                     "archived",
+			"deceased":                 "archived",
+			"missing":                  "archived",
+			"return to owner complete": "archived",
+		},
+		dateLayouts: usDateLayouts,
+	},
+	"shelterluv": {
+		columns: map[string]string{
+			"animal id":        "external_id",
+			"id":               "external_id",
+			"name":             "name",
+			"type":             "species",
+			"species":          "species",
+			"breed":            "breed",
+			"secondary breed":  "breed",
+			"dob":              "estimated_birth_date",
+			"date of birth":    "estimated_birth_date",
+			"age (months)":     "age",
+			"status":           "status",
+			"microchip":        "microchip_number",
+			"microchip number": "microchip_number",
+			"description":      "description",
+			"bio":              "description",
+		},
+		statuses: map[string]string{
+			"available for adoption": "available",
+			"available":              "available",
+			"available in foster":    "foster",
+			"in foster":              "foster",
+			"bite quarantine":        "bite_quarantine",
+			"in quarantine":          "bite_quarantine",
+			"medical hold":           "under_vet_care",
+			"in treatment":           "under_vet_care",
+			"healthy in home":        "archived",
+			"adopted":                "archived",
+			"transferred out":        "archived",
+			"returned to owner":      "archived",
+			"euthanized":             "archived",
+			"deceased":               "archived",
+			"lost/stolen":            "archived",
+		},
+		dateLayouts: usDateLayouts,
+		ageInMonths: true,
+	},
+}
+
+// animalImportFormatNames returns the ?format= values, sorted
+func animalImportFormatNames() []string {
+	names := make([]string, 0, len(animalImportFormats))
+	for name := range animalImportFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// translateHeader maps an export's header to our column names. sources[i]
+// lists the export columns that feed our column header[i]. Columns the
+// format doesn't know keep their own name, so our columns (such as
+// group_id or custom.<key>) can be added to an export by hand.
+func (f animalImportFormat) translateHeader(exportHeader []string) (header []string, sources [][]int) {
+	index := map[string]int{}
+	for i, h := range exportHeader {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		column, ok := f.columns[h]
+		if !ok {
+			column = h
+		}
+		if j, seen := index[column]; seen {
+			sources[j] = append(sources[j], i)
+			continue
+		}
+		index[column] = len(header)
+		header = append(header, column)
+		sources = append(sources, []int{i})
+	}
+	return header, sources
+}
+
+// translateRecord rewrites an export row into the columns from
+// translateHeader, converting statuses, dates, and ages. Several export
+// columns feeding one of ours (such as primary and secondary breed) are
+// joined with " / ". Values the format can't translate are passed through
+// for the import to report.
+func (f animalImportFormat) translateRecord(header []string, sources [][]int, record []string) []string {
+	out := make([]string, len(header))
+	for i, column := range header {
+		var parts []string
+		for _, idx := range sources[i] {
+			if idx < len(record) {
+				if value := strings.TrimSpace(record[idx]); value != "" {
+					parts = append(parts, value)
+				}
+			}
+		}
+		value := strings.Join(parts, " / ")
+		if value == "" {
+			continue
+		}
+		switch column {
+		case "status":
+			if status, ok := f.statuses[strings.ToLower(value)]; ok {
+				value = status
+			}
+		case "estimated_birth_date":
+			value = f.translateDate(value)
+		case "age":
+			value = f.translateAge(value)
+		}
+		out[i] = value
+	}
+	return out
+}
+
+// translateDate rewrites a date in one of the format's layouts as
+// YYYY-MM-DD
+func (f animalImportFormat) translateDate(value string) string {
+	for _, layout := range f.dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	return value
+}
+
+var agePartPattern = regexp.MustCompile(`(?i)(\d+)\s*(years?|yrs?|y|months?|mos?|m|weeks?|wks?|w)\b`)
+
+// translateAge rewrites an export's age, such as "2 years 3 months" or
+// "2Y 3M", as whole years
+func (f animalImportFormat) translateAge(value string) string {
+	if n, err := strconv.Atoi(value); err == nil {
+		if f.ageInMonths {
+			n /= 12
+		}
+		return strconv.Itoa(n)
+	}
+	matches := agePartPattern.FindAllStringSubmatch(value, -1)
+	if matches == nil {
+		return value
+	}
+	months := 0
+	for _, m := range matches {
+		n, _ := strconv.Atoi(m[1])
+		switch strings.ToLower(m[2])[0] {
+		case 'y':
+			months += n * 12
+		case 'm':
+			months += n
+		}
+	}
+	return strconv.Itoa(months / 12)
+}
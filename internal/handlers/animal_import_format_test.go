@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type formatImportResult struct {
+	Count    int      `json:"count"`
+	Warnings []string `json:"warnings"`
+}
+
+func importFixture(t *testing.T, db *gorm.DB, userID uint, query, fixture string) formatImportResult {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", fixture))
+	require.NoError(t, err)
+	w := importAnimalsCSVForTest(t, db, userID, query, string(data))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var result formatImportResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	return result
+}
+
+func importedAnimal(t *testing.T, db *gorm.DB, externalID string) models.Animal {
+	t.Helper()
+	var animal models.Animal
+	require.NoError(t, db.Where("external_id = ?", externalID).First(&animal).Error)
+	return animal
+}
+
+func TestImportAnimalsCSV_PetPointFormat(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	user, group := createAnimalTestUser(t, db, "admin", "admin@example.com", true)
+
+	result := importFixture(t, db, user.ID, "?format=petpoint&group_id="+itoa(group.ID), "petpoint_animals.csv")
+	assert.Equal(t, 4, result.Count)
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0], "Invalid status 'Stray Hold'", "unmapped statuses are reported, not guessed")
+
+	biscuit := importedAnimal(t, db, "A41230001")
+	assert.Equal(t, group.ID, biscuit.GroupID)
+	assert.Equal(t, "Biscuit", biscuit.Name)
+	assert.Equal(t, "Labrador Retriever / Mix", biscuit.Breed)
+	assert.Equal(t, "available", biscuit.Status)
+	assert.Equal(t, "985112345678901", biscuit.MicrochipNumber)
+	require.NotNil(t, biscuit.EstimatedBirthDate)
+	assert.Equal(t, "2023-03-14", biscuit.EstimatedBirthDate.Format("2006-01-02"))
+
+	pepper := importedAnimal(t, db, "A41230002")
+	assert.Equal(t, "foster", pepper.Status)
+	assert.Equal(t, "Domestic Shorthair", pepper.Breed)
+	assert.Equal(t, 4, pepper.Age)
+
+	assert.Equal(t, "bite_quarantine", importedAnimal(t, db, "A41230003").Status)
+	assert.Equal(t, "archived", importedAnimal(t, db, "A41230004").Status)
+
+	// The same export can be imported again with upsert
+	result = importFixture(t, db, user.ID, "?format=petpoint&mode=upsert&group_id="+itoa(group.ID), "petpoint_animals.csv")
+	assert.Equal(t, 4, result.Count)
+	var count int64
+	db.Model(&models.Animal{}).Where("group_id = ?", group.ID).Count(&count)
+	assert.Equal(t, int64(4), count)
+}
+
+func TestImportAnimalsCSV_ShelterluvFormat(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	user, group := createAnimalTestUser(t, db, "admin", "admin@example.com", true)
+
+	result := importFixture(t, db, user.ID, "?format=shelterluv&group_id="+itoa(group.ID), "shelterluv_animals.csv")
+	assert.Equal(t, 4, result.Count)
+	assert.Empty(t, result.Warnings)
+
+	nova := importedAnimal(t, db, "SL-A-1001")
+	assert.Equal(t, "Dog", nova.Species)
+	assert.Equal(t, "available", nova.Status)
+	assert.Equal(t, "Loves fetch", nova.Description)
+	require.NotNil(t, nova.EstimatedBirthDate)
+	assert.Equal(t, "2021-06-01", nova.EstimatedBirthDate.Format("2006-01-02"))
+
+	ziggy := importedAnimal(t, db, "SL-A-1002")
+	assert.Equal(t, "foster", ziggy.Status)
+	require.NotNil(t, ziggy.EstimatedBirthDate)
+	assert.Equal(t, "2024-07-04", ziggy.EstimatedBirthDate.Format("2006-01-02"))
+
+	moose := importedAnimal(t, db, "SL-A-1003")
+	assert.Equal(t, "under_vet_care", moose.Status)
+	assert.Equal(t, 2, moose.Age, "Shelterluv ages are in months")
+
+	assert.Equal(t, "archived", importedAnimal(t, db, "SL-A-1004").Status)
+}
+
+func TestImportAnimalsCSV_UnknownFormat(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	user, group := createAnimalTestUser(t, db, "admin", "admin@example.com", true)
+
+	w := importAnimalsCSVForTest(t, db, user.ID, "?format=rescuegroups", "group_id,name\n"+itoa(group.ID)+",Rex\n")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "petpoint, shelterluv")
+
+	// Without group_id, a vendor export has nowhere to go
+	w = importAnimalsCSVForTest(t, db, user.ID, "?format=shelterluv", "Animal ID,Name\nSL-1,Rex\n")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "group_id")
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/upload"
+	"gorm.io/gorm"
+)
+
+// importImageTimeout bounds fetching one image_url during a CSV import
+const importImageTimeout = 15 * time.Second
+
+// errBlockedImageAddress is returned for an image_url that resolves to a
+// loopback, private, or link-local address
+var errBlockedImageAddress = errors.New("points to a non-public address")
+
+// importImageAllowPrivate lets image_url fetches reach non-public
+// addresses. Only tests turn it on, to fetch from httptest servers.
+var importImageAllowPrivate = false
+
+// importImageDialControl refuses connections to non-public addresses. It
+// runs after DNS resolution, so a public hostname can't point the server
+// at its own network.
+func importImageDialControl(_, address string, _ syscall.RawConn) error {
+	if importImageAllowPrivate {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+		return errBlockedImageAddress
+	}
+	return nil
+}
+
+// importImageClient fetches image_url values. Redirects are followed, and
+// each hop is held to the same address check.
+var importImageClient = &http.Client{
+	Timeout: importImageTimeout,
+	Transport: &http.Transport{
+		Proxy:                 nil,
+		DialContext:           (&net.Dialer{Timeout: 5 * time.Second, Control: importImageDialControl}).DialContext,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: 10 * time.Second,
+	},
+}
+
+// isRemoteImageURL reports whether an image_url points at another site
+// rather than an image already in this one's store
+func isRemoteImageURL(raw string) bool {
+	lower := strings.ToLower(raw)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// fetchImportImage downloads an image_url, refusing anything over maxBytes
+// or that isn't an image
+func fetchImportImage(ctx context.Context, raw string, maxBytes int64) ([]byte, error) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("isn't an http or https URL")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.New("isn't an http or https URL")
+	}
+	req.Header.Set("Accept", "image/*")
+	resp, err := importImageClient.Do(req)
+	if err != nil {
+		if errors.Is(err, errBlockedImageAddress) {
+			return nil, errBlockedImageAddress
+		}
+		return nil, errors.New("couldn't be downloaded")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("returned HTTP %d", resp.StatusCode)
+	}
+	if resp.ContentLength > maxBytes {
+		return nil, fmt.Errorf("is larger than %d MB", maxBytes/(1024*1024))
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, errors.New("couldn't be downloaded")
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("is larger than %d MB", maxBytes/(1024*1024))
+	}
+	if !strings.HasPrefix(http.DetectContentType(data), "image/") && !upload.IsHEIF(data) {
+		return nil, errors.New("isn't an image")
+	}
+	return data, nil
+}
+
+// importRemoteImage fetches an image_url and stores it like an upload:
+// resized and re-encoded with the site's image settings, then saved to the
+// upload store. It returns the local URL, which the caller links to the
+// animal once it exists.
+func importRemoteImage(ctx context.Context, db *gorm.DB, storageProvider storage.Provider, cfg upload.ImageConfig, raw string, userID uint) (string, error) {
+	data, err := fetchImportImage(ctx, raw, cfg.MaxUploadBytes)
+	if err != nil {
+		return "", err
+	}
+	processed, err := upload.ProcessImage(bytes.NewReader(data), cfg.MaxDimension, cfg)
+	if err != nil {
+		return "", errors.New("isn't an image this site can read")
+	}
+	localURL, err := storeUnlinkedImage(ctx, db, storageProvider, processed.Data, processed.MimeType, userID)
+	if err != nil {
+		return "", errors.New("couldn't be saved")
+	}
+	return localURL, nil
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportAnimalsCSV_FetchImages(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	user, group := createAnimalTestUser(t, db, "admin", "admin@example.com", true)
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	img.Set(1, 1, color.RGBA{R: 255, A: 255})
+	var pngData bytes.Buffer
+	require.NoError(t, png.Encode(&pngData, img))
+	var goneRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone.png" {
+			goneRequests.Add(1)
+		}
+		switch r.URL.Path {
+		case "/rex.png":
+			w.Write(pngData.Bytes())
+		case "/page.html":
+			w.Write([]byte("<html><body>Not an image</body></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	importWithImages := func(query, csvContent string) map[string]interface{} {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("file", "animals.csv")
+		require.NoError(t, err)
+		part.Write([]byte(csvContent))
+		writer.Close()
+
+		c, w := setupAnimalTestContext(user.ID, true)
+		c.Request = httptest.NewRequest("POST", "/api/v1/admin/animals/import-csv?fetch_images=true"+query, body)
+		c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+		ImportAnimalsCSV(db, &embedding.StubEmbedder{}, storage.NewPostgresProvider(db), nil)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+
+	t.Run("non-public addresses are refused", func(t *testing.T) {
+		resp := importWithImages("", fmt.Sprintf("group_id,name,image_url\n%d,Blocked,%s/rex.png\n", group.ID, server.URL))
+		require.Len(t, resp["warnings"], 1)
+		assert.Contains(t, resp["warnings"].([]interface{})[0], "non-public address")
+	})
+
+	importImageAllowPrivate = true
+	defer func() { importImageAllowPrivate = false }()
+
+	csvContent := fmt.Sprintf(`group_id,name,image_url
+%[1]d,Rex,%[2]s/rex.png
+%[1]d,Missing,%[2]s/gone.png
+%[1]d,Page,%[2]s/page.html
+%[1]d,Local,/api/images/already-here
+%[1]d,Also Missing,%[2]s/gone.png
+`, group.ID, server.URL)
+	resp := importWithImages("", csvContent)
+	assert.Equal(t, float64(1), resp["images_fetched"])
+	warnings := resp["warnings"].([]interface{})
+	require.Len(t, warnings, 3)
+	assert.Contains(t, warnings[0], "Line 3: image_url returned HTTP 404")
+	assert.Contains(t, warnings[1], "Line 4: image_url isn't an image")
+	assert.Contains(t, warnings[2], "Line 6: image_url returned HTTP 404")
+	assert.Equal(t, int32(1), goneRequests.Load(), "a failed URL isn't fetched again")
+
+	var rex models.Animal
+	require.NoError(t, db.Where("name = ?", "Rex").First(&rex).Error)
+	assert.True(t, strings.HasPrefix(rex.ImageURL, "/api/images/"), rex.ImageURL)
+	var stored models.AnimalImage
+	require.NoError(t, db.Where("image_url = ?", rex.ImageURL).First(&stored).Error)
+	require.NotNil(t, stored.AnimalID)
+	assert.Equal(t, rex.ID, *stored.AnimalID)
+
+	var missing, local models.Animal
+	require.NoError(t, db.Where("name = ?", "Missing").First(&missing).Error)
+	assert.Empty(t, missing.ImageURL, "a failed fetch imports the animal without an image")
+	require.NoError(t, db.Where("name = ?", "Local").First(&local).Error)
+	assert.Equal(t, "/api/images/already-here", local.ImageURL)
+
+	t.Run("rows without an image can be required to have one", func(t *testing.T) {
+		resp := importWithImages("&require_images=true", fmt.Sprintf("group_id,name,image_url\n%[1]d,Bare,\n%[1]d,Broken,%[2]s/gone.png\n%[1]d,Pictured,%[2]s/rex.png\n", group.ID, server.URL))
+		assert.Equal(t, float64(1), resp["count"])
+		assert.Equal(t, []interface{}{"Line 2: An image_url is required", "Line 3: image_url returned HTTP 404"}, resp["warnings"])
+	})
+}
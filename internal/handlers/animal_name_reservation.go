@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrCodeAnimalNameTaken is returned when a group with unique animal names
+// already has an active animal by the name
+const ErrCodeAnimalNameTaken ErrorCode = "ANIMAL_NAME_TAKEN"
+
+// maxNameSuggestions bounds how many free names a name conflict suggests
+const maxNameSuggestions = 3
+
+// nameSuffixes number repeated names the way shelters usually do
+var nameSuffixes = []string{"II", "III", "IV", "V", "VI", "VII", "VIII", "IX", "X"}
+
+type animalNameTakenResponse struct {
+	Error       string    `json:"error"`
+	Code        ErrorCode `json:"code,omitempty"`
+	Suggestions []string  `json:"suggestions"`
+}
+
+// requiresUniqueAnimalNames reports whether a group reserves each active
+// animal's name
+func requiresUniqueAnimalNames(db *gorm.DB, groupID uint) (bool, error) {
+	var unique []bool
+	if err := db.Model(&models.Group{}).Where("id = ?", groupID).Limit(1).Pluck("unique_animal_names", &unique).Error; err != nil {
+		return false, err
+	}
+	return len(unique) > 0 && unique[0], nil
+}
+
+// activeAnimalNameTaken reports whether another animal in the group that
+// hasn't left care has name, ignoring case and surrounding spaces.
+// excludeID is the animal being saved, 0 for a new one.
+func activeAnimalNameTaken(db *gorm.DB, groupID uint, name string, excludeID uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.Animal{}).
+		Where("group_id = ? AND id <> ? AND LOWER(TRIM(name)) = ? AND status NOT IN ?",
+			groupID, excludeID, strings.ToLower(strings.TrimSpace(name)), exitStatuses()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// animalNameConflict returns a message and suggested free names if saving
+// an animal with name and status would clash in a group with unique animal
+// names, or "" if it wouldn't. Animals that have left care don't hold
+// their name.
+func animalNameConflict(db *gorm.DB, groupID uint, name, status string, excludeID uint) (string, []string, error) {
+	if strings.TrimSpace(name) == "" || isExitStatus(status) {
+		return "", nil, nil
+	}
+	unique, err := requiresUniqueAnimalNames(db, groupID)
+	if err != nil || !unique {
+		return "", nil, err
+	}
+	taken, err := activeAnimalNameTaken(db, groupID, name, excludeID)
+	if err != nil || !taken {
+		return "", nil, err
+	}
+
+	base := strings.TrimSpace(name)
+	suggestions := []string{}
+	for _, suffix := range nameSuffixes {
+		candidate := base + " " + suffix
+		taken, err := activeAnimalNameTaken(db, groupID, candidate, excludeID)
+		if err != nil {
+			return "", nil, err
+		}
+		if !taken {
+			suggestions = append(suggestions, candidate)
+			if len(suggestions) == maxNameSuggestions {
+				break
+			}
+		}
+	}
+	return fmt.Sprintf("An active animal in this group is already named %s", base), suggestions, nil
+}
+
+// animalNameClaimChanged reports whether saving animal with name, groupID,
+// and status could newly clash with another animal's name: the name or
+// group changes, or the animal comes back into care. Edits that leave all
+// of these alone aren't held up by clashes from before the group turned on
+// unique names.
+func animalNameClaimChanged(animal models.Animal, name string, groupID uint, status string) bool {
+	return !strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(animal.Name)) ||
+		groupID != animal.GroupID ||
+		(isExitStatus(animal.Status) && !isExitStatus(status))
+}
+
+// checkAnimalName responds with 409 and suggested names when an animal
+// write would reuse an active animal's name in a group with unique animal
+// names. Returns false if it responded.
+func checkAnimalName(c *gin.Context, db *gorm.DB, groupID uint, name, status string, excludeID uint) bool {
+	conflict, suggestions, err := animalNameConflict(db, groupID, name, status, excludeID)
+	if err != nil {
+		middleware.GetLogger(c).Error("Failed to check animal name", err)
+		respondInternalError(c, "Failed to check animal name")
+		return false
+	}
+	if conflict == "" {
+		return true
+	}
+	resp := animalNameTakenResponse{Error: conflict, Suggestions: suggestions}
+	if structuredErrorsRequested(c) {
+		resp.Code = ErrCodeAnimalNameTaken
+	}
+	c.JSON(http.StatusConflict, resp)
+	return false
+}
+
+// importNameClaims tracks the active names rows of one CSV import have
+// claimed, since rows imported together don't see each other in the
+// database
+type importNameClaims map[string]bool
+
+// conflict returns why a row can't give an animal name and status, or ""
+// and claims the name for the row. excludeID is the animal the row
+// updates, 0 for a new one.
+func (claims importNameClaims) conflict(db *gorm.DB, groupID uint, name, status string, excludeID uint) (string, error) {
+	conflict, suggestions, err := animalNameConflict(db, groupID, name, status, excludeID)
+	if err != nil || conflict != "" {
+		if len(suggestions) > 0 {
+			conflict += "; try " + suggestions[0]
+		}
+		return conflict, err
+	}
+	if strings.TrimSpace(name) == "" || isExitStatus(status) {
+		return "", nil
+	}
+	unique, err := requiresUniqueAnimalNames(db, groupID)
+	if err != nil || !unique {
+		return "", err
+	}
+	key := fmt.Sprintf("%d/%s", groupID, strings.ToLower(strings.TrimSpace(name)))
+	if claims[key] {
+		return fmt.Sprintf("Another row in this file already names an active animal %s", strings.TrimSpace(name)), nil
+	}
+	claims[key] = true
+	return "", nil
+}
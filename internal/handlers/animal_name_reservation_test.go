@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUniqueAnimalNames(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	user, group := createAnimalTestUser(t, db, "admin", "admin@example.com", true)
+	params := gin.Params{{Key: "id", Value: itoa(group.ID)}}
+
+	createAnimal := func(body gin.H) (int, animalNameTakenResponse) {
+		data, _ := json.Marshal(body)
+		c, w := setupAnimalTestContext(user.ID, true)
+		c.Params = params
+		c.Request = httptest.NewRequest(http.MethodPost, "/?force=true", bytes.NewBuffer(data))
+		c.Request.Header.Set("Content-Type", "application/json")
+		CreateAnimal(db, nil, &embedding.StubEmbedder{})(c)
+		var resp animalNameTakenResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	luna := createTestAnimal(t, db, group.ID, "Luna", "Dog")
+	code, _ := createAnimal(gin.H{"name": "Luna", "species": "Dog"})
+	require.Equal(t, http.StatusCreated, code, "names are free until the group turns the rule on")
+
+	c, w := accountTestContext(user.ID, true, http.MethodPut, "/", gin.H{"unique_animal_names": true})
+	c.Params = params
+	UpdateGroupDisplaySettings(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	code, resp := createAnimal(gin.H{"name": " luna ", "species": "Dog"})
+	require.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, []string{"luna II", "luna III", "luna IV"}, resp.Suggestions)
+	require.NoError(t, db.Model(&models.Animal{}).Where("id <> ?", luna.ID).Where("name = ?", "Luna").Update("name", "Luna II").Error)
+	_, resp = createAnimal(gin.H{"name": "Luna", "species": "Dog"})
+	assert.Equal(t, []string{"Luna III", "Luna IV", "Luna V"}, resp.Suggestions, "suggestions skip names in use")
+
+	code, _ = createAnimal(gin.H{"name": "Luna", "species": "Dog", "status": "archived"})
+	assert.Equal(t, http.StatusCreated, code, "animals that have left care don't claim a name")
+
+	// Renaming onto an active name is refused; other edits aren't
+	update := func(animal *models.Animal, body gin.H) int {
+		data, _ := json.Marshal(body)
+		c, w := setupAnimalTestContext(user.ID, true)
+		c.Params = append(params, gin.Param{Key: "animalId", Value: itoa(animal.ID)})
+		c.Request = httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(data))
+		c.Request.Header.Set("Content-Type", "application/json")
+		UpdateAnimal(db, nil, &embedding.StubEmbedder{})(c)
+		return w.Code
+	}
+	rex := createTestAnimal(t, db, group.ID, "Rex", "Dog")
+	assert.Equal(t, http.StatusConflict, update(rex, gin.H{"name": "LUNA", "species": "Dog", "status": "available"}))
+	assert.Equal(t, http.StatusOK, update(rex, gin.H{"name": "Rex", "species": "Dog", "status": "foster"}))
+	require.NoError(t, db.Model(luna).Update("status", "adopted").Error)
+	assert.Equal(t, http.StatusOK, update(rex, gin.H{"name": "Luna", "species": "Dog", "status": "foster"}), "Luna was adopted")
+
+	// Coming back into care needs a free name too
+	assert.Equal(t, http.StatusConflict, update(luna, gin.H{"name": "Luna", "species": "Dog", "status": "available"}))
+
+	// Imports skip rows that would clash, including with each other
+	csvContent := fmt.Sprintf("group_id,name,species,status\n%d,Luna,Dog,available\n%d,Bella,Dog,available\n%d,bella,Dog,available\n%d,Luna,Dog,archived\n",
+		group.ID, group.ID, group.ID, group.ID)
+	w = importAnimalsCSVForTest(t, db, user.ID, "", csvContent)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var result struct {
+		Count    int      `json:"count"`
+		Warnings []string `json:"warnings"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, 2, result.Count)
+	require.Len(t, result.Warnings, 2)
+	assert.Contains(t, result.Warnings[0], "try Luna III")
+	assert.Contains(t, result.Warnings[1], "Line 4")
+
+	w = importAnimalsCSVForTest(t, db, user.ID, "?mode=upsert", fmt.Sprintf("group_id,name,species\n%d,Bella,Dog\n%d,Daisy,Dog\n", group.ID, group.ID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	result.Warnings = nil
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, 2, result.Count, "matching rows update the animal that holds the name")
+	assert.Empty(t, result.Warnings)
+}
@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// maxIntakeOutcomeMonths bounds the range of the monthly intake/outcome report
+const maxIntakeOutcomeMonths = 24
+
+// statusOutcomes maps status keys that mean an animal left care to the
+// outcome entering them records. Groups opt in by adding these keys to
+// their status taxonomy; animals archived without one get the outcome the
+// request gives, if any.
+var statusOutcomes = map[string]string{
+	"adopted":           models.OutcomeAdopted,
+	"returned_to_owner": models.OutcomeReturnedToOwner,
+	"transferred":       models.OutcomeTransferred,
+	"euthanized":        models.OutcomeEuthanized,
+	"deceased":          models.OutcomeDied,
+	"lost":              models.OutcomeLost,
+}
+
+// isExitStatus reports whether animals in status have left care
+func isExitStatus(status string) bool {
+	_, ok := statusOutcomes[status]
+	return ok || status == "archived"
+}
+
+// validIntakeSource reports whether s is empty (unknown) or an accepted source
+func validIntakeSource(s string) bool {
+	return s == "" || slices.Contains(models.IntakeSources, s)
+}
+
+// resolveOutcome returns the outcome and outcome date animal should have
+// once an edit moves it to status (its current status when unchanged).
+// Entering an outcome status records that outcome, and going back into care
+// clears it. requested is the outcome the request gave: nil leaves it to the
+// status, "" clears it, and anything else must be an accepted outcome on an
+// animal that has left care. The date only changes with the outcome.
+func resolveOutcome(animal models.Animal, status string, requested *string, now time.Time) (string, *time.Time, error) {
+	outcome, date := animal.Outcome, animal.OutcomeDate
+	if status != animal.Status {
+		if implied, ok := statusOutcomes[status]; ok && implied != outcome {
+			outcome, date = implied, &now
+		} else if !isExitStatus(status) {
+			outcome, date = "", nil
+		}
+	}
+	if requested == nil {
+		return outcome, date, nil
+	}
+	switch {
+	case *requested == "":
+		return "", nil, nil
+	case !slices.Contains(models.AnimalOutcomes, *requested):
+		return "", nil, errors.New("outcome must be one of: " + strings.Join(models.AnimalOutcomes, ", "))
+	case !isExitStatus(status):
+		return "", nil, errors.New("outcome can only be set on an animal that has left care, such as an archived one")
+	case *requested != outcome || date == nil:
+		return *requested, &now, nil
+	}
+	return outcome, date, nil
+}
+
+// IntakeOutcomeCounts are intake and outcome totals for a period. The live
+// release rate follows the Asilomar Accords: live outcomes divided by live
+// outcomes plus euthanasias. Animals that died or were lost in care don't
+// count toward it.
+type IntakeOutcomeCounts struct {
+	Intakes         int64            `json:"intakes"`
+	IntakesBySource map[string]int64 `json:"intakes_by_source"` // "unknown" for animals without an intake source
+	Outcomes        int64            `json:"outcomes"`
+	OutcomesByType  map[string]int64 `json:"outcomes_by_type"`
+	LiveOutcomes    int64            `json:"live_outcomes"`
+	LiveReleaseRate *float64         `json:"live_release_rate"` // 0 to 1; nil without live outcomes or euthanasias
+	euthanized      int64
+}
+
+func newIntakeOutcomeCounts() IntakeOutcomeCounts {
+	return IntakeOutcomeCounts{IntakesBySource: map[string]int64{}, OutcomesByType: map[string]int64{}}
+}
+
+func (c *IntakeOutcomeCounts) addIntake(source string) {
+	if source == "" {
+		source = "unknown"
+	}
+	c.Intakes++
+	c.IntakesBySource[source]++
+}
+
+func (c *IntakeOutcomeCounts) addOutcome(outcome string) {
+	c.Outcomes++
+	c.OutcomesByType[outcome]++
+	if slices.Contains(models.LiveOutcomes, outcome) {
+		c.LiveOutcomes++
+	} else if outcome == models.OutcomeEuthanized {
+		c.euthanized++
+	}
+}
+
+func (c *IntakeOutcomeCounts) finish() {
+	if denominator := c.LiveOutcomes + c.euthanized; denominator > 0 {
+		rate := math.Round(float64(c.LiveOutcomes)/float64(denominator)*1000) / 1000
+		c.LiveReleaseRate = &rate
+	}
+}
+
+// MonthlyIntakeOutcome is one calendar month (UTC) of the report
+type MonthlyIntakeOutcome struct {
+	Month string `json:"month"` // YYYY-MM
+	IntakeOutcomeCounts
+}
+
+// IntakeOutcomeStats is the response for GET /api/admin/stats/intake-outcome
+type IntakeOutcomeStats struct {
+	From    string                 `json:"from"` // YYYY-MM, inclusive
+	To      string                 `json:"to"`   // YYYY-MM, inclusive
+	GroupID *uint                  `json:"group_id,omitempty"`
+	Months  []MonthlyIntakeOutcome `json:"months"`
+	Totals  IntakeOutcomeCounts    `json:"totals"`
+}
+
+// buildIntakeOutcomeStats counts intakes by arrival_date and outcomes by
+// outcome_date per month in [from, to), where both are first-of-month UTC
+func buildIntakeOutcomeStats(db *gorm.DB, groupID *uint, from, to time.Time) (*IntakeOutcomeStats, error) {
+	stats := &IntakeOutcomeStats{
+		From:    from.Format("2006-01"),
+		To:      to.AddDate(0, -1, 0).Format("2006-01"),
+		GroupID: groupID,
+		Totals:  newIntakeOutcomeCounts(),
+	}
+	index := make(map[string]int)
+	for m := from; m.Before(to); m = m.AddDate(0, 1, 0) {
+		index[m.Format("2006-01")] = len(stats.Months)
+		stats.Months = append(stats.Months, MonthlyIntakeOutcome{Month: m.Format("2006-01"), IntakeOutcomeCounts: newIntakeOutcomeCounts()})
+	}
+	scope := func() *gorm.DB {
+		q := db.Model(&models.Animal{})
+		if groupID != nil {
+			q = q.Where("group_id = ?", *groupID)
+		}
+		return q
+	}
+
+	var intakes []struct {
+		ArrivalDate  time.Time
+		IntakeSource string
+	}
+	if err := scope().Select("arrival_date, intake_source").
+		Where("arrival_date >= ? AND arrival_date < ?", from, to).Scan(&intakes).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range intakes {
+		if i, ok := index[row.ArrivalDate.UTC().Format("2006-01")]; ok {
+			stats.Months[i].addIntake(row.IntakeSource)
+			stats.Totals.addIntake(row.IntakeSource)
+		}
+	}
+
+	var outcomes []struct {
+		OutcomeDate time.Time
+		Outcome     string
+	}
+	if err := scope().Select("outcome_date, outcome").
+		Where("outcome <> '' AND outcome_date >= ? AND outcome_date < ?", from, to).Scan(&outcomes).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range outcomes {
+		if i, ok := index[row.OutcomeDate.UTC().Format("2006-01")]; ok {
+			stats.Months[i].addOutcome(row.Outcome)
+			stats.Totals.addOutcome(row.Outcome)
+		}
+	}
+
+	for i := range stats.Months {
+		stats.Months[i].finish()
+	}
+	stats.Totals.finish()
+	return stats, nil
+}
+
+// parseMonthRange reads ?from= and ?to= (YYYY-MM, inclusive, default the
+// last 12 months) and returns [from, to) as first-of-month UTC times
+func parseMonthRange(c *gin.Context, now time.Time) (from, to time.Time, ok bool) {
+	now = now.UTC()
+	to = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+	if v := c.Query("to"); v != "" {
+		m, err := time.Parse("2006-01", v)
+		if err != nil {
+			respondBadRequest(c, "to must be a month in YYYY-MM format")
+			return
+		}
+		to = m.AddDate(0, 1, 0)
+	}
+	from = to.AddDate(0, -12, 0)
+	if v := c.Query("from"); v != "" {
+		m, err := time.Parse("2006-01", v)
+		if err != nil {
+			respondBadRequest(c, "from must be a month in YYYY-MM format")
+			return
+		}
+		from = m
+	}
+	if !from.Before(to) {
+		respondBadRequest(c, "from must not be after to")
+		return
+	}
+	if from.AddDate(0, maxIntakeOutcomeMonths, 0).Before(to) {
+		respondBadRequest(c, "The range can't be longer than "+strconv.Itoa(maxIntakeOutcomeMonths)+" months")
+		return
+	}
+	return from, to, true
+}
+
+// GetIntakeOutcomeStats returns monthly intake counts by source and outcome
+// counts by type, with the live release rate, for shelter reporting (admin
+// only). Query params: from, to (YYYY-MM, default the last 12 months),
+// group_id.
+// Route: GET /api/admin/stats/intake-outcome
+func GetIntakeOutcomeStats(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+		defer cancel()
+		db := middleware.GetDB(c, db).WithContext(ctx)
+
+		var groupID *uint
+		if v := c.Query("group_id"); v != "" {
+			gid, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+				return
+			}
+			id := uint(gid)
+			groupID = &id
+		}
+		from, to, ok := parseMonthRange(c, time.Now())
+		if !ok {
+			return
+		}
+
+		stats, err := buildIntakeOutcomeStats(db, groupID, from, to)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to compute intake and outcome stats", err)
+			respondInternalError(c, "Failed to compute stats")
+			return
+		}
+		respondOK(c, stats)
+	}
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveOutcome(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	earlier := now.AddDate(0, -1, 0)
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name        string
+		animal      models.Animal
+		status      string
+		requested   *string
+		wantOutcome string
+		wantDate    *time.Time
+		wantErr     bool
+	}{
+		{"entering an outcome status records it", models.Animal{Status: "available"}, "adopted", nil, models.OutcomeAdopted, &now, false},
+		{"archiving keeps the outcome", models.Animal{Status: "adopted", Outcome: models.OutcomeAdopted, OutcomeDate: &earlier}, "archived", nil, models.OutcomeAdopted, &earlier, false},
+		{"archiving without one leaves it unset", models.Animal{Status: "available"}, "archived", nil, "", nil, false},
+		{"back in care clears it", models.Animal{Status: "archived", Outcome: models.OutcomeTransferred, OutcomeDate: &earlier}, "available", nil, "", nil, false},
+		{"explicit outcome on archive", models.Animal{Status: "available"}, "archived", str(models.OutcomeEuthanized), models.OutcomeEuthanized, &now, false},
+		{"repeating the outcome keeps its date", models.Animal{Status: "archived", Outcome: models.OutcomeDied, OutcomeDate: &earlier}, "archived", str(models.OutcomeDied), models.OutcomeDied, &earlier, false},
+		{"explicit outcome overrides the status", models.Animal{Status: "available"}, "adopted", str(models.OutcomeOtherLive), models.OutcomeOtherLive, &now, false},
+		{"empty clears it", models.Animal{Status: "archived", Outcome: models.OutcomeLost, OutcomeDate: &earlier}, "archived", str(""), "", nil, false},
+		{"unknown outcome", models.Animal{Status: "archived"}, "archived", str("escaped"), "", nil, true},
+		{"outcome while in care", models.Animal{Status: "available"}, "foster", str(models.OutcomeAdopted), "", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome, date, err := resolveOutcome(tt.animal, tt.status, tt.requested, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOutcome, outcome)
+			assert.Equal(t, tt.wantDate, date)
+		})
+	}
+}
+
+func TestAnimalWrites_TrackIntakeAndOutcome(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	user := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	gid := group.ID
+	require.NoError(t, db.Create(&[]models.AnimalStatus{
+		{GroupID: &gid, Key: "available", Label: "Available", OrderIndex: 0},
+		{GroupID: &gid, Key: "adopted", Label: "Adopted", OrderIndex: 1},
+		{GroupID: &gid, Key: "archived", Label: "Archived", OrderIndex: 2},
+	}).Error)
+
+	send := func(method string, animalID uint, req AnimalRequest) (int, models.Animal) {
+		body, _ := json.Marshal(req)
+		c, w := setupAnimalTestContext(user.ID, true)
+		c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}, {Key: "animalId", Value: fmt.Sprint(animalID)}}
+		c.Request = httptest.NewRequest(method, "/animals", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		if method == http.MethodPost {
+			CreateAnimal(db, nil, &embedding.StubEmbedder{})(c)
+		} else {
+			UpdateAnimal(db, nil, &embedding.StubEmbedder{})(c)
+		}
+		var animal models.Animal
+		_ = json.Unmarshal(w.Body.Bytes(), &animal)
+		return w.Code, animal
+	}
+	str := func(s string) *string { return &s }
+
+	code, _ := send(http.MethodPost, 0, AnimalRequest{Name: "Rex", Status: "available", IntakeSource: str("found_in_park")})
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, animal := send(http.MethodPost, 0, AnimalRequest{Name: "Rex", Status: "available", IntakeSource: str(models.IntakeStray)})
+	require.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, models.IntakeStray, animal.IntakeSource)
+	assert.Empty(t, animal.Outcome)
+
+	code, _ = send(http.MethodPut, animal.ID, AnimalRequest{Name: "Rex", Outcome: str(models.OutcomeAdopted)})
+	assert.Equal(t, http.StatusBadRequest, code, "an animal in care has no outcome")
+
+	code, animal = send(http.MethodPut, animal.ID, AnimalRequest{Name: "Rex", Status: "adopted"})
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, models.OutcomeAdopted, animal.Outcome)
+	require.NotNil(t, animal.OutcomeDate)
+	assert.Equal(t, models.IntakeStray, animal.IntakeSource, "edits without intake_source keep it")
+
+	code, animal = send(http.MethodPut, animal.ID, AnimalRequest{Name: "Rex", Status: "available", IntakeSource: str(models.IntakeReturnedAdoption)})
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, animal.Outcome)
+	assert.Nil(t, animal.OutcomeDate)
+	assert.Equal(t, models.IntakeReturnedAdoption, animal.IntakeSource)
+
+	// Bulk status changes set outcomes too
+	body, _ := json.Marshal(BulkUpdateAnimalsRequest{AnimalIDs: []uint{animal.ID}, Status: str("adopted")})
+	c, w := setupAnimalTestContext(user.ID, true)
+	c.Request = httptest.NewRequest(http.MethodPost, "/admin/animals/bulk-update", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	BulkUpdateAnimals(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var stored models.Animal
+	require.NoError(t, db.First(&stored, animal.ID).Error)
+	assert.Equal(t, models.OutcomeAdopted, stored.Outcome)
+	assert.NotNil(t, stored.OutcomeDate)
+
+	// Admin edits: archiving with an explicit outcome
+	body, _ = json.Marshal(AnimalRequest{Name: "Rex", Status: "archived", Outcome: str(models.OutcomeTransferred)})
+	c, w = setupAnimalTestContext(user.ID, true)
+	c.Params = gin.Params{{Key: "animalId", Value: fmt.Sprint(animal.ID)}}
+	c.Request = httptest.NewRequest(http.MethodPut, "/admin/animals", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	UpdateAnimalAdmin(db, nil, &embedding.StubEmbedder{})(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, db.First(&stored, animal.ID).Error)
+	assert.Equal(t, models.OutcomeTransferred, stored.Outcome)
+}
+
+func TestGetIntakeOutcomeStats(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	dogs := CreateTestGroup(t, db, "Dogs", "")
+	cats := CreateTestGroup(t, db, "Cats", "")
+
+	day := func(month time.Month, d int) *time.Time {
+		t := time.Date(2026, month, d, 15, 0, 0, 0, time.UTC)
+		return &t
+	}
+	for _, a := range []models.Animal{
+		{GroupID: dogs.ID, Name: "A", ArrivalDate: day(1, 5), IntakeSource: models.IntakeStray, Status: "archived", Outcome: models.OutcomeAdopted, OutcomeDate: day(2, 1)},
+		{GroupID: dogs.ID, Name: "B", ArrivalDate: day(1, 20), IntakeSource: models.IntakeOwnerSurrender, Status: "archived", Outcome: models.OutcomeEuthanized, OutcomeDate: day(2, 10)},
+		{GroupID: dogs.ID, Name: "C", ArrivalDate: day(2, 3), Status: "archived", Outcome: models.OutcomeTransferred, OutcomeDate: day(2, 20)},
+		{GroupID: dogs.ID, Name: "D", ArrivalDate: day(2, 4), Status: "archived", Outcome: models.OutcomeDied, OutcomeDate: day(2, 21)},
+		{GroupID: dogs.ID, Name: "E", ArrivalDate: day(2, 5), IntakeSource: models.IntakeStray, Status: "available"},
+		{GroupID: cats.ID, Name: "F", ArrivalDate: day(1, 9), IntakeSource: models.IntakeTransfer, Status: "archived", Outcome: models.OutcomeAdopted, OutcomeDate: day(1, 30)},
+		{GroupID: dogs.ID, Name: "Later", ArrivalDate: day(12, 1), Status: "available"},
+	} {
+		require.NoError(t, db.Create(&a).Error)
+	}
+
+	get := func(query string) (int, IntakeOutcomeStats) {
+		c, w := accountTestContext(admin.ID, true, http.MethodGet, "/api/admin/stats/intake-outcome"+query, nil)
+		GetIntakeOutcomeStats(db)(c)
+		var stats IntakeOutcomeStats
+		_ = json.Unmarshal(w.Body.Bytes(), &stats)
+		return w.Code, stats
+	}
+
+	code, stats := get("?from=2026-01&to=2026-03")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, stats.Months, 3)
+	jan, feb, mar := stats.Months[0], stats.Months[1], stats.Months[2]
+	assert.Equal(t, "2026-01", jan.Month)
+	assert.Equal(t, int64(3), jan.Intakes)
+	assert.Equal(t, map[string]int64{"stray": 1, "owner_surrender": 1, "transfer": 1}, jan.IntakesBySource)
+	assert.Equal(t, int64(1), jan.Outcomes)
+	assert.Equal(t, map[string]int64{"unknown": 2, "stray": 1}, feb.IntakesBySource)
+	assert.Equal(t, int64(4), feb.Outcomes)
+	assert.Equal(t, int64(2), feb.LiveOutcomes)
+	require.NotNil(t, feb.LiveReleaseRate)
+	assert.Equal(t, 0.667, *feb.LiveReleaseRate, "died in care isn't counted")
+	assert.Nil(t, mar.LiveReleaseRate)
+	assert.Equal(t, int64(6), stats.Totals.Intakes)
+	assert.Equal(t, int64(5), stats.Totals.Outcomes)
+	require.NotNil(t, stats.Totals.LiveReleaseRate)
+	assert.Equal(t, 0.75, *stats.Totals.LiveReleaseRate)
+
+	code, stats = get(fmt.Sprintf("?from=2026-01&to=2026-02&group_id=%d", cats.ID))
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, int64(1), stats.Totals.Intakes)
+	assert.Equal(t, map[string]int64{"adopted": 1}, stats.Totals.OutcomesByType)
+
+	code, _ = get("?from=2026-13")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = get("?from=2026-03&to=2026-01")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = get("?from=2023-01&to=2026-01")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrCodeDuplicateRegistryNumber is returned when another animal in the
+// group already has the microchip or license number
+const ErrCodeDuplicateRegistryNumber ErrorCode = "DUPLICATE_REGISTRY_NUMBER"
+
+var (
+	// microchipPattern accepts 15-digit ISO 11784/11785 chips, 9-digit AVID
+	// chips, and 10-character hexadecimal FECAVA chips
+	microchipPattern = regexp.MustCompile(`^(\d{15}|\d{9}|[0-9A-F]{10})$`)
+	// licensePattern accepts the letters, digits, and separators license
+	// tags use
+	licensePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9/-]{0,31}$`)
+	// microchipSeparators are stripped from microchip numbers, which are
+	// often printed in groups
+	microchipSeparators = strings.NewReplacer(" ", "", "-", "", ".", "")
+)
+
+// normalizeMicrochip returns raw without separators and in upper case, or
+// an error if it isn't a microchip number. Empty input stays empty.
+func normalizeMicrochip(raw string) (string, error) {
+	chip := strings.ToUpper(microchipSeparators.Replace(strings.TrimSpace(raw)))
+	if chip != "" && !microchipPattern.MatchString(chip) {
+		return "", errors.New("microchip_number must be 15 digits, 9 digits, or 10 hexadecimal characters")
+	}
+	return chip, nil
+}
+
+// normalizeLicense returns raw trimmed and in upper case, or an error if it
+// isn't a license number. Empty input stays empty.
+func normalizeLicense(raw string) (string, error) {
+	license := strings.ToUpper(strings.TrimSpace(raw))
+	if license != "" && !licensePattern.MatchString(license) {
+		return "", errors.New("license_number must be up to 32 letters, digits, dashes, or slashes")
+	}
+	return license, nil
+}
+
+// registryConflict describes how microchip or license clashes with another
+// animal in groupID, or returns "" if both are free. excludeID is the
+// animal being saved, 0 for a new one.
+func registryConflict(db *gorm.DB, groupID uint, microchip, license string, excludeID uint) (string, error) {
+	for _, number := range []struct{ column, label, value string }{
+		{"microchip_number", "microchip", microchip},
+		{"license_number", "license", license},
+	} {
+		if number.value == "" {
+			continue
+		}
+		var owners []string
+		if err := db.Model(&models.Animal{}).
+			Where("group_id = ? AND "+number.column+" = ? AND id <> ?", groupID, number.value, excludeID).
+			Limit(1).Pluck("name", &owners).Error; err != nil {
+			return "", err
+		}
+		if len(owners) > 0 {
+			return fmt.Sprintf("%s already has %s number %s in this group", owners[0], number.label, number.value), nil
+		}
+	}
+	return "", nil
+}
+
+// resolveRegistryNumbers validates the microchip and license numbers an
+// animal write asks for, returning what the animal should store: its
+// current values where the request gave nil. The numbers must be unique in
+// the group the animal ends up in, given by animal.GroupID. Responds with
+// the error and returns ok=false when they aren't usable.
+func resolveRegistryNumbers(c *gin.Context, db *gorm.DB, animal models.Animal, microchip, license *string) (string, string, bool) {
+	chip, lic := animal.MicrochipNumber, animal.LicenseNumber
+	var err error
+	if microchip != nil {
+		if chip, err = normalizeMicrochip(*microchip); err != nil {
+			respondBadRequest(c, err.Error())
+			return "", "", false
+		}
+	}
+	if license != nil {
+		if lic, err = normalizeLicense(*license); err != nil {
+			respondBadRequest(c, err.Error())
+			return "", "", false
+		}
+	}
+
+	conflict, err := registryConflict(db, animal.GroupID, chip, lic, animal.ID)
+	if err != nil {
+		middleware.GetLogger(c).Error("Failed to check registry numbers", err)
+		respondInternalError(c, "Failed to check registry numbers")
+		return "", "", false
+	}
+	if conflict != "" {
+		respondError(c, http.StatusConflict, ErrCodeDuplicateRegistryNumber, conflict)
+		return "", "", false
+	}
+	return chip, lic, true
+}
+
+// AnimalLookupResult is an animal found by its microchip or license number
+type AnimalLookupResult struct {
+	ID              uint   `json:"id"`
+	GroupID         uint   `json:"group_id"`
+	GroupName       string `json:"group_name"`
+	Name            string `json:"name"`
+	Species         string `json:"species"`
+	Breed           string `json:"breed"`
+	Status          string `json:"status"`
+	ImageURL        string `json:"image_url"`
+	MicrochipNumber string `json:"microchip_number"`
+	LicenseNumber   string `json:"license_number"`
+}
+
+// LookupAnimals finds animals in every group by microchip or license number
+// so staff can identify a found animal (admin only). Give ?microchip= or
+// ?license=; numbers are matched the way they're stored, so separators and
+// case don't matter. Animals that have left care are included.
+// Route: GET /api/admin/animals/lookup
+func LookupAnimals(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+
+		chip, err := normalizeMicrochip(c.Query("microchip"))
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		license, err := normalizeLicense(c.Query("license"))
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		if chip == "" && license == "" {
+			respondBadRequest(c, "microchip or license is required")
+			return
+		}
+
+		query := db.Table("animals a").
+			Select("a.id, a.group_id, g.name AS group_name, a.name, a.species, a.breed, a.status, a.image_url, a.microchip_number, a.license_number").
+			Joins("JOIN groups g ON g.id = a.group_id AND g.deleted_at IS NULL").
+			Where("a.deleted_at IS NULL")
+		if chip != "" {
+			query = query.Where("a.microchip_number = ?", chip)
+		}
+		if license != "" {
+			query = query.Where("a.license_number = ?", license)
+		}
+
+		results := []AnimalLookupResult{}
+		if err := query.Order("g.name ASC, a.name ASC").Scan(&results).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to look up animals", err)
+			respondInternalError(c, "Failed to look up animals")
+			return
+		}
+		respondOK(c, results)
+	}
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeMicrochip(t *testing.T) {
+	tests := []struct {
+		raw, want string
+		valid     bool
+	}{
+		{"985 112 003 456 789", "985112003456789", true},
+		{"985-112-003-456-789", "985112003456789", true},
+		{"012.345.678", "012345678", true},
+		{"0a1b2c3d4e", "0A1B2C3D4E", true},
+		{"", "", true},
+		{"98511200345678", "", false},
+		{"0A1B2C3D4G", "", false},
+		{"chip", "", false},
+	}
+	for _, tt := range tests {
+		got, err := normalizeMicrochip(tt.raw)
+		if tt.valid {
+			assert.NoError(t, err, tt.raw)
+			assert.Equal(t, tt.want, got, tt.raw)
+		} else {
+			assert.Error(t, err, tt.raw)
+		}
+	}
+
+	license, err := normalizeLicense(" dl-2026/0042 ")
+	assert.NoError(t, err)
+	assert.Equal(t, "DL-2026/0042", license)
+	_, err = normalizeLicense("tag #42")
+	assert.Error(t, err)
+}
+
+func TestAnimalRegistryNumbers(t *testing.T) {
+	db := SetupTestDB(t)
+	lead := CreateTestUser(t, db, "lead", "lead@example.com", "password123", false)
+	dogs := CreateTestGroup(t, db, "Dogs", "")
+	cats := CreateTestGroup(t, db, "Cats", "")
+	AddUserToGroupWithAdmin(t, db, lead.ID, dogs.ID, true)
+	AddUserToGroupWithAdmin(t, db, lead.ID, cats.ID, true)
+
+	create := func(groupID uint, name, chip string) (int, models.Animal) {
+		c, w := accountTestContext(lead.ID, false, http.MethodPost, "/?force=true", AnimalRequest{
+			Name: name, Species: "Dog", MicrochipNumber: &chip,
+		})
+		c.Params = gin.Params{{Key: "id", Value: itoa(groupID)}}
+		CreateAnimal(db, nil, &embedding.StubEmbedder{})(c)
+		var animal models.Animal
+		_ = json.Unmarshal(w.Body.Bytes(), &animal)
+		return w.Code, animal
+	}
+
+	code, rex := create(dogs.ID, "Rex", "985 112 003 456 789")
+	require.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, "985112003456789", rex.MicrochipNumber)
+	code, _ = create(dogs.ID, "Buddy", "not a chip")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = create(dogs.ID, "Buddy", "985-112-003-456-789")
+	assert.Equal(t, http.StatusConflict, code, "microchips are unique in a group")
+	code, _ = create(cats.ID, "Rex", "985112003456789")
+	assert.Equal(t, http.StatusCreated, code, "other groups may reuse a number")
+	code, buddy := create(dogs.ID, "Buddy", "")
+	require.Equal(t, http.StatusCreated, code)
+
+	update := func(animal models.Animal, license string) int {
+		c, w := accountTestContext(lead.ID, false, http.MethodPut, "/", AnimalRequest{
+			Name: animal.Name, Species: "Dog", LicenseNumber: &license,
+		})
+		c.Params = gin.Params{{Key: "id", Value: itoa(dogs.ID)}, {Key: "animalId", Value: itoa(animal.ID)}}
+		UpdateAnimal(db, nil, &embedding.StubEmbedder{})(c)
+		return w.Code
+	}
+	require.Equal(t, http.StatusOK, update(rex, "dl-42"))
+	require.Equal(t, http.StatusOK, update(rex, "DL-42"), "an animal keeps its own number")
+	assert.Equal(t, http.StatusConflict, update(buddy, "dl-42"))
+	var saved models.Animal
+	require.NoError(t, db.First(&saved, rex.ID).Error)
+	assert.Equal(t, "985112003456789", saved.MicrochipNumber, "leaving microchip_number out keeps it")
+	assert.Equal(t, "DL-42", saved.LicenseNumber)
+
+	lookup := func(query string) (int, []AnimalLookupResult) {
+		c, w := accountTestContext(lead.ID, true, http.MethodGet, "/api/admin/animals/lookup"+query, nil)
+		LookupAnimals(db)(c)
+		var results []AnimalLookupResult
+		_ = json.Unmarshal(w.Body.Bytes(), &results)
+		return w.Code, results
+	}
+	code, _ = lookup("")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, results := lookup("?microchip=985.112.003.456.789")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, results, 2)
+	assert.Equal(t, []string{"Cats", "Dogs"}, []string{results[0].GroupName, results[1].GroupName})
+	code, results = lookup("?license=dl-42")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, results, 1)
+	assert.Equal(t, rex.ID, results[0].ID)
+}
+
+func TestImportAnimalsCSV_RegistryNumbers(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	user, group := createAnimalTestUser(t, db, "admin", "admin@example.com", true)
+	existing := createTestAnimal(t, db, group.ID, "Rex", "Dog")
+	require.NoError(t, db.Model(existing).Update("microchip_number", "985112003456789").Error)
+
+	csv := fmt.Sprintf(`group_id,name,microchip_number,license_number
+%d,Buddy,985112003456789,
+%d,Max,012345678,dl-1
+%d,Luna,012-345-678,
+%d,Bella,chip,
+%d,Daisy,,DL-2`, group.ID, group.ID, group.ID, group.ID, group.ID)
+	w := importAnimalsCSVForTest(t, db, user.ID, "", csv)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response struct {
+		Created  int      `json:"created"`
+		Warnings []string `json:"warnings"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Created)
+	require.Len(t, response.Warnings, 3)
+	assert.Contains(t, response.Warnings[0], "Rex already has microchip number")
+	assert.Contains(t, response.Warnings[1], "as line 3")
+	assert.Contains(t, response.Warnings[2], "microchip_number must be")
+
+	var max models.Animal
+	require.NoError(t, db.Where("name = ?", "Max").First(&max).Error)
+	assert.Equal(t, "DL-1", max.LicenseNumber)
+
+	// Exports carry the numbers
+	c, rec := setupAnimalTestContext(user.ID, true)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	ExportAnimalsCSV(db)(c)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "012345678,DL-1")
+}
@@ -0,0 +1,419 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrCodeBondedPartnerInCare is returned, with 409 Conflict, when adopting
+// an animal would leave a bonded partner behind in a group that adopts
+// bonded partners together
+const ErrCodeBondedPartnerInCare ErrorCode = "BONDED_PARTNER_IN_CARE"
+
+// Relations a RelatedAnimal can have to the animal it's seen from, besides
+// the two-way relationship types
+const (
+	relationParent    = models.AnimalRelationshipParent // The related animal is this animal's parent
+	relationOffspring = "offspring"                     // The related animal is this animal's offspring
+)
+
+// AnimalRelationshipRequest links an animal to another. Type is how the
+// related animal relates to this one: bonded_pair, littermate, parent, or
+// offspring.
+type AnimalRelationshipRequest struct {
+	RelatedAnimalID uint   `json:"related_animal_id" binding:"required"`
+	Type            string `json:"type" binding:"required,oneof=bonded_pair littermate parent offspring"`
+	Notes           string `json:"notes" binding:"max=500"`
+}
+
+// UpdateAnimalRelationshipRequest changes a relationship's type or notes
+type UpdateAnimalRelationshipRequest struct {
+	Type  string `json:"type" binding:"required,oneof=bonded_pair littermate parent offspring"`
+	Notes string `json:"notes" binding:"max=500"`
+}
+
+// storedRelationship returns how a relationship in which related has relation
+// to animalID is stored: parents first, and two-way relationships with the
+// lower animal ID first
+func storedRelationship(animalID, relatedID uint, relation string) (from, to uint, relType string) {
+	switch relation {
+	case relationParent:
+		return relatedID, animalID, models.AnimalRelationshipParent
+	case relationOffspring:
+		return animalID, relatedID, models.AnimalRelationshipParent
+	}
+	return min(animalID, relatedID), max(animalID, relatedID), relation
+}
+
+// relationFrom returns how the other animal of rel relates to animalID
+func relationFrom(rel models.AnimalRelationship, animalID uint) string {
+	if rel.Type != models.AnimalRelationshipParent {
+		return rel.Type
+	}
+	if rel.AnimalID == animalID {
+		return relationOffspring
+	}
+	return relationParent
+}
+
+// relatedAnimals returns the animals linked to animalID, bonded partners
+// first, then littermates, parents, and offspring, each by name. query
+// scopes which related animals are returned; deleted ones never are.
+func relatedAnimals(db, query *gorm.DB, animalID uint) ([]models.RelatedAnimal, error) {
+	var rels []models.AnimalRelationship
+	if err := db.Where("animal_id = ? OR related_animal_id = ?", animalID, animalID).Find(&rels).Error; err != nil {
+		return nil, err
+	}
+	if len(rels) == 0 {
+		return []models.RelatedAnimal{}, nil
+	}
+	otherIDs := make([]uint, len(rels))
+	for i, rel := range rels {
+		otherIDs[i] = rel.AnimalID + rel.RelatedAnimalID - animalID
+	}
+	var others []models.Animal
+	if err := query.Where("id IN ?", otherIDs).Find(&others).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Animal, len(others))
+	for _, a := range others {
+		byID[a.ID] = a
+	}
+
+	related := []models.RelatedAnimal{}
+	for i, rel := range rels {
+		other, ok := byID[otherIDs[i]]
+		if !ok {
+			continue
+		}
+		related = append(related, toRelatedAnimal(rel, animalID, other))
+	}
+	order := []string{models.AnimalRelationshipBondedPair, models.AnimalRelationshipLittermate, relationParent, relationOffspring}
+	sort.SliceStable(related, func(i, j int) bool {
+		if related[i].Relation != related[j].Relation {
+			return slices.Index(order, related[i].Relation) < slices.Index(order, related[j].Relation)
+		}
+		return strings.ToLower(related[i].Name) < strings.ToLower(related[j].Name)
+	})
+	return related, nil
+}
+
+func toRelatedAnimal(rel models.AnimalRelationship, animalID uint, other models.Animal) models.RelatedAnimal {
+	return models.RelatedAnimal{
+		RelationshipID: rel.ID,
+		Relation:       relationFrom(rel, animalID),
+		Notes:          rel.Notes,
+		AnimalID:       other.ID,
+		GroupID:        other.GroupID,
+		Name:           other.Name,
+		Status:         other.Status,
+		ImageURL:       other.ImageURL,
+	}
+}
+
+// visibleRelatedAnimals returns the animals linked to animal that the user
+// may see: those in the animal's group, minus any restricted ones, and
+// those in other groups the user belongs to
+func visibleRelatedAnimals(c *gin.Context, db *gorm.DB, animal models.Animal) ([]models.RelatedAnimal, error) {
+	groupID := fmt.Sprint(animal.GroupID)
+	related, err := relatedAnimals(db, visibleAnimals(c, db, db.Model(&models.Animal{}), groupID), animal.ID)
+	if err != nil {
+		return nil, err
+	}
+	userID, _ := c.Get("user_id")
+	isAdmin, _ := c.Get("is_admin")
+	access := map[uint]bool{animal.GroupID: true}
+	visible := related[:0]
+	for _, r := range related {
+		allowed, checked := access[r.GroupID]
+		if !checked {
+			allowed = checkGroupAccess(db, userID, isAdmin, fmt.Sprint(r.GroupID))
+			access[r.GroupID] = allowed
+		}
+		if allowed {
+			visible = append(visible, r)
+		}
+	}
+	return visible, nil
+}
+
+// loadAnimalRelationship loads the :relationshipId relationship of animal,
+// responding 404 if the animal isn't part of it
+func loadAnimalRelationship(c *gin.Context, db *gorm.DB, animal *models.Animal) (*models.AnimalRelationship, bool) {
+	var rel models.AnimalRelationship
+	if err := db.Where("id = ? AND (animal_id = ? OR related_animal_id = ?)", c.Param("relationshipId"), animal.ID, animal.ID).
+		First(&rel).Error; err != nil {
+		respondNotFound(c, "Relationship not found")
+		return nil, false
+	}
+	return &rel, true
+}
+
+// bondedPartnersLeftBehind returns the bonded partners that adopting animal,
+// which an edit gives outcome, would leave in care, in a group that adopts
+// bonded partners together. Partners in moving leave care with it.
+func bondedPartnersLeftBehind(db *gorm.DB, animal models.Animal, outcome string, moving []uint) ([]models.RelatedAnimal, error) {
+	if outcome != models.OutcomeAdopted || animal.Outcome == models.OutcomeAdopted {
+		return nil, nil
+	}
+	var together []bool
+	if err := db.Model(&models.Group{}).Where("id = ?", animal.GroupID).Limit(1).Pluck("adopt_bonded_together", &together).Error; err != nil {
+		return nil, err
+	}
+	if len(together) == 0 || !together[0] {
+		return nil, nil
+	}
+	related, err := relatedAnimals(db, db, animal.ID)
+	if err != nil {
+		return nil, err
+	}
+	var left []models.RelatedAnimal
+	for _, r := range related {
+		if r.Relation == models.AnimalRelationshipBondedPair && !isExitStatus(r.Status) && !slices.Contains(moving, r.AnimalID) {
+			left = append(left, r)
+		}
+	}
+	return left, nil
+}
+
+// respondBondedPartnersInCare responds 409 naming the bonded partners an
+// adoption would leave behind. Structured errors list each partner in
+// details, with rule "bonded_pair" and the partner's name as the message.
+// partners must not be empty.
+func respondBondedPartnersInCare(c *gin.Context, partners []models.RelatedAnimal) {
+	names := make([]string, len(partners))
+	details := make([]FieldError, len(partners))
+	for i, p := range partners {
+		names[i] = p.Name
+		details[i] = animalFieldError(p.AnimalID, models.AnimalRelationshipBondedPair, p.Name)
+	}
+	respondError(c, http.StatusConflict, ErrCodeBondedPartnerInCare,
+		"Bonded partners must be adopted together; include "+strings.Join(names, ", ")+" in a bulk update", details...)
+}
+
+// checkBondedAdoption responds 409 and returns false if giving animal
+// outcome would leave a bonded partner behind
+func checkBondedAdoption(c *gin.Context, db *gorm.DB, animal models.Animal, outcome string, moving []uint) bool {
+	left, err := bondedPartnersLeftBehind(db, animal, outcome, moving)
+	if err != nil {
+		middleware.GetLogger(c).Error("Failed to check bonded partners", err)
+		respondInternalError(c, "Failed to check bonded partners")
+		return false
+	}
+	if len(left) > 0 {
+		respondBondedPartnersInCare(c, left)
+		return false
+	}
+	return true
+}
+
+// moveAnimalRelationships moves dup's relationships to keep on merge,
+// dropping any between the two and any keep already has with the same
+// animal. It returns how many moved.
+func moveAnimalRelationships(tx *gorm.DB, keepID, dupID uint) (int64, error) {
+	var rels []models.AnimalRelationship
+	if err := tx.Where("animal_id = ? OR related_animal_id = ?", dupID, dupID).Find(&rels).Error; err != nil {
+		return 0, err
+	}
+	var moved int64
+	for _, rel := range rels {
+		otherID := rel.AnimalID + rel.RelatedAnimalID - dupID
+		var existing int64
+		if err := tx.Model(&models.AnimalRelationship{}).
+			Where("(animal_id = ? AND related_animal_id = ?) OR (animal_id = ? AND related_animal_id = ?)", keepID, otherID, otherID, keepID).
+			Count(&existing).Error; err != nil {
+			return 0, err
+		}
+		if otherID == keepID || existing > 0 {
+			if err := tx.Delete(&rel).Error; err != nil {
+				return 0, err
+			}
+			continue
+		}
+		from, to, relType := storedRelationship(keepID, otherID, relationFrom(rel, dupID))
+		if err := tx.Model(&rel).Updates(map[string]interface{}{"animal_id": from, "related_animal_id": to, "type": relType}).Error; err != nil {
+			return 0, err
+		}
+		moved++
+	}
+	return moved, nil
+}
+
+// GetAnimalRelationships lists the animals linked to an animal
+// Route: GET /api/groups/:id/animals/:animalId/relationships
+func GetAnimalRelationships(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+		if !checkGroupAccess(db, userID, isAdmin, c.Param("id")) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+		animal, ok := findGroupAnimal(c, db)
+		if !ok {
+			return
+		}
+
+		related, err := visibleRelatedAnimals(c, db, *animal)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to fetch related animals", err)
+			respondInternalError(c, "Failed to fetch related animals")
+			return
+		}
+		respondOK(c, related)
+	}
+}
+
+// CreateAnimalRelationship links an animal to another one, e.g. as a bonded
+// pair or littermates (group admin or site admin). An animal in another
+// group can be linked by admins of both groups. Two animals can only be
+// linked once.
+// Route: POST /api/groups/:id/animals/:animalId/relationships
+func CreateAnimalRelationship(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+		if !checkGroupAdminAccess(db, userID, isAdmin, c.Param("id")) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
+			return
+		}
+		animal, ok := findGroupAnimal(c, db)
+		if !ok {
+			return
+		}
+
+		var req AnimalRelationshipRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		if req.RelatedAnimalID == animal.ID {
+			respondBadRequest(c, "An animal can't be linked to itself")
+			return
+		}
+		var related models.Animal
+		if err := db.First(&related, req.RelatedAnimalID).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeAnimalNotFound, "Related animal not found")
+			return
+		}
+		if related.GroupID != animal.GroupID && !checkGroupAdminAccess(db, userID, isAdmin, fmt.Sprint(related.GroupID)) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access to the related animal's group required")
+			return
+		}
+
+		var existing int64
+		if err := db.Model(&models.AnimalRelationship{}).
+			Where("(animal_id = ? AND related_animal_id = ?) OR (animal_id = ? AND related_animal_id = ?)", animal.ID, related.ID, related.ID, animal.ID).
+			Count(&existing).Error; err != nil {
+			respondInternalError(c, "Failed to link animals")
+			return
+		}
+		if existing > 0 {
+			respondError(c, http.StatusConflict, ErrCodeConflict, "These animals are already linked")
+			return
+		}
+
+		uid, _ := middleware.GetUserID(c)
+		from, to, relType := storedRelationship(animal.ID, related.ID, req.Type)
+		rel := models.AnimalRelationship{
+			AnimalID:        from,
+			RelatedAnimalID: to,
+			Type:            relType,
+			Notes:           strings.TrimSpace(req.Notes),
+			CreatedByID:     uid,
+		}
+		if err := db.Create(&rel).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to create animal relationship", err)
+			respondInternalError(c, "Failed to link animals")
+			return
+		}
+		respondCreated(c, toRelatedAnimal(rel, animal.ID, related))
+	}
+}
+
+// UpdateAnimalRelationship changes how two linked animals are related, or
+// the relationship's notes (group admin or site admin)
+// Route: PUT /api/groups/:id/animals/:animalId/relationships/:relationshipId
+func UpdateAnimalRelationship(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+		if !checkGroupAdminAccess(db, userID, isAdmin, c.Param("id")) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
+			return
+		}
+		animal, ok := findGroupAnimal(c, db)
+		if !ok {
+			return
+		}
+		rel, ok := loadAnimalRelationship(c, db, animal)
+		if !ok {
+			return
+		}
+
+		var req UpdateAnimalRelationshipRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		var related models.Animal
+		if err := db.Unscoped().First(&related, rel.AnimalID+rel.RelatedAnimalID-animal.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				respondNotFound(c, "Relationship not found")
+				return
+			}
+			respondInternalError(c, "Failed to update relationship")
+			return
+		}
+
+		rel.AnimalID, rel.RelatedAnimalID, rel.Type = storedRelationship(animal.ID, related.ID, req.Type)
+		rel.Notes = strings.TrimSpace(req.Notes)
+		if err := db.Model(rel).Select("animal_id", "related_animal_id", "type", "notes").Updates(rel).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to update animal relationship", err)
+			respondInternalError(c, "Failed to update relationship")
+			return
+		}
+		respondOK(c, toRelatedAnimal(*rel, animal.ID, related))
+	}
+}
+
+// DeleteAnimalRelationship unlinks two animals (group admin or site admin)
+// Route: DELETE /api/groups/:id/animals/:animalId/relationships/:relationshipId
+func DeleteAnimalRelationship(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+		if !checkGroupAdminAccess(db, userID, isAdmin, c.Param("id")) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
+			return
+		}
+		animal, ok := findGroupAnimal(c, db)
+		if !ok {
+			return
+		}
+		rel, ok := loadAnimalRelationship(c, db, animal)
+		if !ok {
+			return
+		}
+
+		if err := db.Delete(rel).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to delete animal relationship", err)
+			respondInternalError(c, "Failed to unlink animals")
+			return
+		}
+		respondOK(c, gin.H{"message": "Relationship deleted"})
+	}
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnimalRelationships(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.UserQualification{}))
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", false)
+	volunteer := CreateTestUser(t, db, "volunteer", "volunteer@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "Dog group")
+	otherGroup := CreateTestGroup(t, db, "Cats", "Cat group")
+	AddUserToGroupWithAdmin(t, db, admin.ID, group.ID, true)
+	AddUserToGroupWithAdmin(t, db, volunteer.ID, group.ID, false)
+	rex := CreateTestAnimal(t, db, group.ID, "Rex", "Dog")
+	bella := CreateTestAnimal(t, db, group.ID, "Bella", "Dog")
+	max := CreateTestAnimal(t, db, group.ID, "Max", "Dog")
+	mom := CreateTestAnimal(t, db, group.ID, "Mom", "Dog")
+	tom := CreateTestAnimal(t, db, otherGroup.ID, "Tom", "Cat")
+	animalParams := func(animalID uint, extra ...gin.Param) gin.Params {
+		return append(gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}, {Key: "animalId", Value: fmt.Sprint(animalID)}}, extra...)
+	}
+
+	link := func(userID, animalID uint, req AnimalRelationshipRequest) (int, models.RelatedAnimal) {
+		c, w := accountTestContext(userID, false, http.MethodPost, "/", req)
+		c.Params = animalParams(animalID)
+		CreateAnimalRelationship(db)(c)
+		var related models.RelatedAnimal
+		_ = json.Unmarshal(w.Body.Bytes(), &related)
+		return w.Code, related
+	}
+	list := func(animalID uint) []models.RelatedAnimal {
+		c, w := accountTestContext(volunteer.ID, false, http.MethodGet, "/", nil)
+		c.Params = animalParams(animalID)
+		GetAnimalRelationships(db)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var related []models.RelatedAnimal
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &related))
+		return related
+	}
+	relations := func(related []models.RelatedAnimal) []string {
+		out := []string{}
+		for _, r := range related {
+			out = append(out, r.Relation+":"+r.Name)
+		}
+		return out
+	}
+
+	code, _ := link(volunteer.ID, rex.ID, AnimalRelationshipRequest{RelatedAnimalID: bella.ID, Type: "bonded_pair"})
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = link(admin.ID, rex.ID, AnimalRelationshipRequest{RelatedAnimalID: rex.ID, Type: "bonded_pair"})
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = link(admin.ID, rex.ID, AnimalRelationshipRequest{RelatedAnimalID: tom.ID, Type: "littermate"})
+	assert.Equal(t, http.StatusForbidden, code, "linking into another group takes admin access to it")
+
+	code, bonded := link(admin.ID, rex.ID, AnimalRelationshipRequest{RelatedAnimalID: bella.ID, Type: "bonded_pair", Notes: "Inseparable"})
+	require.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, models.RelatedAnimal{RelationshipID: bonded.RelationshipID, Relation: "bonded_pair", Notes: "Inseparable",
+		AnimalID: bella.ID, GroupID: group.ID, Name: "Bella", Status: "available"}, bonded)
+	code, _ = link(admin.ID, bella.ID, AnimalRelationshipRequest{RelatedAnimalID: rex.ID, Type: "littermate"})
+	assert.Equal(t, http.StatusConflict, code, "two animals are linked once")
+	code, _ = link(admin.ID, rex.ID, AnimalRelationshipRequest{RelatedAnimalID: max.ID, Type: "littermate"})
+	require.Equal(t, http.StatusCreated, code)
+	code, _ = link(admin.ID, rex.ID, AnimalRelationshipRequest{RelatedAnimalID: mom.ID, Type: "parent"})
+	require.Equal(t, http.StatusCreated, code)
+
+	assert.Equal(t, []string{"bonded_pair:Bella", "littermate:Max", "parent:Mom"}, relations(list(rex.ID)))
+	assert.Equal(t, []string{"offspring:Rex"}, relations(list(mom.ID)))
+
+	c, w := accountTestContext(volunteer.ID, false, http.MethodGet, "/", nil)
+	c.Params = animalParams(bella.ID)
+	GetAnimal(db)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var detail models.Animal
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &detail))
+	assert.Equal(t, []string{"bonded_pair:Rex"}, relations(detail.RelatedAnimals))
+
+	// Turning a relationship around
+	momLink := list(mom.ID)[0]
+	c, w = accountTestContext(admin.ID, false, http.MethodPut, "/", UpdateAnimalRelationshipRequest{Type: "parent"})
+	c.Params = animalParams(mom.ID, gin.Param{Key: "relationshipId", Value: fmt.Sprint(momLink.RelationshipID)})
+	UpdateAnimalRelationship(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []string{"bonded_pair:Bella", "littermate:Max", "offspring:Mom"}, relations(list(rex.ID)))
+
+	c, w = accountTestContext(admin.ID, false, http.MethodDelete, "/", nil)
+	c.Params = animalParams(max.ID, gin.Param{Key: "relationshipId", Value: fmt.Sprint(momLink.RelationshipID)})
+	DeleteAnimalRelationship(db)(c)
+	assert.Equal(t, http.StatusNotFound, w.Code, "Max isn't part of Mom's relationship")
+	c, w = accountTestContext(admin.ID, false, http.MethodDelete, "/", nil)
+	c.Params = animalParams(mom.ID, gin.Param{Key: "relationshipId", Value: fmt.Sprint(momLink.RelationshipID)})
+	DeleteAnimalRelationship(db)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, list(mom.ID))
+}
+
+func TestBondedAdoption(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	group := CreateTestGroup(t, db, "Dogs", "Dog group")
+	gid := group.ID
+	require.NoError(t, db.Create(&[]models.AnimalStatus{
+		{GroupID: &gid, Key: "available", Label: "Available", OrderIndex: 0},
+		{GroupID: &gid, Key: "adopted", Label: "Adopted", OrderIndex: 1},
+		{GroupID: &gid, Key: "archived", Label: "Archived", OrderIndex: 2},
+	}).Error)
+	rex := CreateTestAnimal(t, db, group.ID, "Rex", "Dog")
+	bella := CreateTestAnimal(t, db, group.ID, "Bella", "Dog")
+	max := CreateTestAnimal(t, db, group.ID, "Max", "Dog")
+	require.NoError(t, db.Create(&models.AnimalRelationship{AnimalID: rex.ID, RelatedAnimalID: bella.ID, Type: models.AnimalRelationshipBondedPair}).Error)
+
+	adopt := func(animalID uint) *ErrorResponse {
+		c, w := accountTestContext(admin.ID, true, http.MethodPut, "/", map[string]string{"name": "Rex", "status": "adopted"})
+		c.Request.Header.Set("Accept", StructuredErrorsMediaType)
+		c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}, {Key: "animalId", Value: fmt.Sprint(animalID)}}
+		UpdateAnimal(db, nil, &embedding.StubEmbedder{})(c)
+		if w.Code == http.StatusOK {
+			return nil
+		}
+		require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+		var resp ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return &resp
+	}
+	bulkAdopt := func(target string, ids ...uint) int {
+		status := "adopted"
+		c, w := accountTestContext(admin.ID, true, http.MethodPost, target, BulkUpdateAnimalsRequest{AnimalIDs: ids, Status: &status})
+		BulkUpdateAnimals(db)(c)
+		return w.Code
+	}
+
+	// The rule is off by default
+	assert.Equal(t, http.StatusOK, bulkAdopt("/?dry_run=true", rex.ID))
+	c, w := accountTestContext(admin.ID, true, http.MethodPut, "/", gin.H{"adopt_bonded_together": true})
+	c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}}
+	UpdateGroupDisplaySettings(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	resp := adopt(rex.ID)
+	require.NotNil(t, resp)
+	assert.Equal(t, ErrCodeBondedPartnerInCare, resp.Code)
+	assert.Equal(t, []FieldError{{Field: fmt.Sprintf("animal:%d", bella.ID), Rule: "bonded_pair", Message: "Bella"}}, resp.Details)
+	assert.Equal(t, http.StatusConflict, bulkAdopt("/", rex.ID, max.ID))
+
+	require.Equal(t, http.StatusOK, bulkAdopt("/", rex.ID, bella.ID))
+	var adopted []models.Animal
+	require.NoError(t, db.Where("id IN ?", []uint{rex.ID, bella.ID}).Find(&adopted).Error)
+	for _, a := range adopted {
+		assert.Equal(t, models.OutcomeAdopted, a.Outcome)
+	}
+
+	// A partner who has already left care doesn't hold the other back
+	require.NoError(t, db.Model(&models.Animal{}).Where("id = ?", rex.ID).
+		Updates(map[string]interface{}{"status": "available", "outcome": ""}).Error)
+	assert.Nil(t, adopt(rex.ID))
+}
@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/auth"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/qrcode"
+	"gorm.io/gorm"
+)
+
+// shareLinkSignaturePurpose scopes share link signatures so they can't be
+// confused with any other value signed with the server key.
+const shareLinkSignaturePurpose = "animal_share_link"
+
+const (
+	defaultShareQRScale = 8
+	maxShareQRScale     = 20
+)
+
+// animalShareLinkResponse is a share link as returned to group admins, with
+// its public URL and token filled in.
+type animalShareLinkResponse struct {
+	models.AnimalShareLink
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// sharedAnimalResponse is the public, read-only view of an animal. It is
+// deliberately narrow: nothing beyond what a kennel card visitor should see.
+type sharedAnimalResponse struct {
+	Name        string `json:"name"`
+	Species     string `json:"species"`
+	Breed       string `json:"breed"`
+	Description string `json:"description"`
+	ImageURL    string `json:"image_url"`
+	Status      string `json:"status"`
+	StatusLabel string `json:"status_label"`
+	GroupName   string `json:"group_name"`
+}
+
+// shareLinkToken returns the public token for a share link: its ID and a
+// signature of that ID.
+func shareLinkToken(id uint) (string, error) {
+	value := strconv.FormatUint(uint64(id), 10)
+	sig, err := auth.Sign(shareLinkSignaturePurpose, value)
+	if err != nil {
+		return "", err
+	}
+	return value + "." + sig, nil
+}
+
+// parseShareLinkToken verifies a token's signature and returns the share
+// link ID it names.
+func parseShareLinkToken(token string) (uint, bool) {
+	value, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || !auth.VerifySignature(shareLinkSignaturePurpose, value, sig) {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// shareLinkURL is the frontend page a share token opens.
+func shareLinkURL(token string) string {
+	baseURL := os.Getenv("FRONTEND_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:5173"
+	}
+	return strings.TrimRight(baseURL, "/") + "/share/" + token
+}
+
+func toAnimalShareLinkResponse(link models.AnimalShareLink) (animalShareLinkResponse, error) {
+	token, err := shareLinkToken(link.ID)
+	if err != nil {
+		return animalShareLinkResponse{}, err
+	}
+	return animalShareLinkResponse{AnimalShareLink: link, Token: token, URL: shareLinkURL(token)}, nil
+}
+
+// requireShareLinkAdmin checks group admin access and loads the :animalId
+// animal, responding with an error if either fails.
+func requireShareLinkAdmin(c *gin.Context, db *gorm.DB) (*models.Animal, bool) {
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+		return nil, false
+	}
+	if !IsGroupAdminOrSiteAdmin(c, db, uint(groupID)) {
+		respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Group admin access required")
+		return nil, false
+	}
+	return findGroupAnimal(c, db)
+}
+
+// findAnimalShareLink loads the :linkId share link of an animal, responding
+// 404 if it doesn't exist.
+func findAnimalShareLink(c *gin.Context, db *gorm.DB, animalID uint) (*models.AnimalShareLink, bool) {
+	var link models.AnimalShareLink
+	if err := db.Where("id = ? AND animal_id = ?", c.Param("linkId"), animalID).First(&link).Error; err != nil {
+		respondNotFound(c, "Share link not found")
+		return nil, false
+	}
+	return &link, true
+}
+
+// CreateAnimalShareLink creates a public share link for an animal (group
+// admin or site admin). The group must have public sharing turned on.
+// Route: POST /api/groups/:id/animals/:animalId/share-links
+func CreateAnimalShareLink(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		animal, ok := requireShareLinkAdmin(c, db)
+		if !ok {
+			return
+		}
+
+		var group models.Group
+		if err := db.First(&group, animal.GroupID).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, "Group not found")
+			return
+		}
+		if !group.PublicSharing {
+			respondError(c, http.StatusConflict, ErrCodeConflict, "Public sharing is not enabled for this group")
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		link := models.AnimalShareLink{AnimalID: animal.ID, GroupID: animal.GroupID, CreatedByID: userID}
+		if err := db.Create(&link).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to create share link", err)
+			respondInternalError(c, "Failed to create share link")
+			return
+		}
+
+		resp, err := toAnimalShareLinkResponse(link)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to sign share link", err)
+			respondInternalError(c, "Failed to create share link")
+			return
+		}
+		c.JSON(http.StatusCreated, resp)
+	}
+}
+
+// GetAnimalShareLinks lists an animal's share links, newest first, including
+// revoked ones (group admin or site admin).
+// Route: GET /api/groups/:id/animals/:animalId/share-links
+func GetAnimalShareLinks(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		animal, ok := requireShareLinkAdmin(c, db)
+		if !ok {
+			return
+		}
+
+		var links []models.AnimalShareLink
+		if err := db.Where("animal_id = ?", animal.ID).Order("id DESC").Find(&links).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to list share links", err)
+			respondInternalError(c, "Failed to list share links")
+			return
+		}
+
+		resp := make([]animalShareLinkResponse, 0, len(links))
+		for _, link := range links {
+			r, err := toAnimalShareLinkResponse(link)
+			if err != nil {
+				middleware.GetLogger(c).Error("Failed to sign share link", err)
+				respondInternalError(c, "Failed to list share links")
+				return
+			}
+			resp = append(resp, r)
+		}
+		respondOK(c, resp)
+	}
+}
+
+// RevokeAnimalShareLink permanently disables a share link (group admin or
+// site admin). Revoking an already revoked link is a no-op.
+// Route: DELETE /api/groups/:id/animals/:animalId/share-links/:linkId
+func RevokeAnimalShareLink(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		animal, ok := requireShareLinkAdmin(c, db)
+		if !ok {
+			return
+		}
+		link, ok := findAnimalShareLink(c, db, animal.ID)
+		if !ok {
+			return
+		}
+
+		if link.RevokedAt == nil {
+			now := time.Now()
+			if err := db.Model(link).Update("revoked_at", now).Error; err != nil {
+				middleware.GetLogger(c).Error("Failed to revoke share link", err)
+				respondInternalError(c, "Failed to revoke share link")
+				return
+			}
+			link.RevokedAt = &now
+		}
+
+		resp, err := toAnimalShareLinkResponse(*link)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to sign share link", err)
+			respondInternalError(c, "Failed to revoke share link")
+			return
+		}
+		respondOK(c, resp)
+	}
+}
+
+// GetAnimalShareLinkQRCode renders a share link's URL as a QR code PNG for
+// printing on kennel cards (group admin or site admin). Query param: scale,
+// pixels per module (default 8, max 20).
+// Route: GET /api/groups/:id/animals/:animalId/share-links/:linkId/qr.png
+func GetAnimalShareLinkQRCode(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		animal, ok := requireShareLinkAdmin(c, db)
+		if !ok {
+			return
+		}
+		link, ok := findAnimalShareLink(c, db, animal.ID)
+		if !ok {
+			return
+		}
+		if link.RevokedAt != nil {
+			respondError(c, http.StatusConflict, ErrCodeConflict, "Share link has been revoked")
+			return
+		}
+
+		scale := defaultShareQRScale
+		if v := c.Query("scale"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > maxShareQRScale {
+				respondBadRequest(c, fmt.Sprintf("scale must be between 1 and %d", maxShareQRScale))
+				return
+			}
+			scale = n
+		}
+
+		resp, err := toAnimalShareLinkResponse(*link)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to sign share link", err)
+			respondInternalError(c, "Failed to generate QR code")
+			return
+		}
+		code, err := qrcode.Encode(resp.URL)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to encode share link QR code", err)
+			respondInternalError(c, "Failed to generate QR code")
+			return
+		}
+		png, err := code.PNG(scale)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to render share link QR code", err)
+			respondInternalError(c, "Failed to generate QR code")
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="share-link-%d.png"`, link.ID))
+		c.Data(http.StatusOK, "image/png", png)
+	}
+}
+
+// GetSharedAnimal returns the public view of the animal a share token points
+// to. No authentication: the token's signature is the credential. Invalid,
+// revoked, and disabled links all get the same 404 so a token's state isn't
+// revealed.
+// Route: GET /api/share/:token
+func GetSharedAnimal(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		notFound := func() { respondNotFound(c, "Share link not found") }
+
+		id, ok := parseShareLinkToken(c.Param("token"))
+		if !ok {
+			notFound()
+			return
+		}
+
+		var link models.AnimalShareLink
+		if err := db.Where("id = ? AND revoked_at IS NULL", id).First(&link).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				middleware.GetLogger(c).Error("Failed to load share link", err)
+			}
+			notFound()
+			return
+		}
+
+		var group models.Group
+		if err := db.First(&group, link.GroupID).Error; err != nil || !group.PublicSharing {
+			notFound()
+			return
+		}
+		var animal models.Animal
+		if err := db.Where("id = ? AND group_id = ?", link.AnimalID, link.GroupID).First(&animal).Error; err != nil {
+			notFound()
+			return
+		}
+
+		statusLabel := animal.Status
+		if def, ok, err := lookupAnimalStatus(db, animal.GroupID, animal.Status); err == nil && ok {
+			statusLabel = def.Label
+		}
+
+		if err := db.Model(&link).Updates(map[string]interface{}{
+			"view_count":     gorm.Expr("view_count + 1"),
+			"last_viewed_at": time.Now(),
+		}).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to record share link view", err)
+		}
+
+		respondOK(c, sharedAnimalResponse{
+			Name:        animal.Name,
+			Species:     animal.Species,
+			Breed:       animal.Breed,
+			Description: animal.Description,
+			ImageURL:    animal.ImageURL,
+			Status:      animal.Status,
+			StatusLabel: statusLabel,
+			GroupName:   group.Name,
+		})
+	}
+}
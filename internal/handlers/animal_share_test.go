@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func shareLinkTestContext(userID uint, groupID, animalID, linkID uint, method, target string) (*gin.Context, *httptest.ResponseRecorder) {
+	c, w := setupAnimalTestContext(userID, false)
+	c.Params = gin.Params{
+		{Key: "id", Value: fmt.Sprintf("%d", groupID)},
+		{Key: "animalId", Value: fmt.Sprintf("%d", animalID)},
+	}
+	if linkID != 0 {
+		c.Params = append(c.Params, gin.Param{Key: "linkId", Value: fmt.Sprintf("%d", linkID)})
+	}
+	c.Request = httptest.NewRequest(method, target, nil)
+	return c, w
+}
+
+func getSharedAnimal(db *gorm.DB, token string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "token", Value: token}}
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/share/"+token, nil)
+	GetSharedAnimal(db)(c)
+	return w
+}
+
+func TestAnimalShareLinks(t *testing.T) {
+	t.Setenv("FRONTEND_URL", "https://volunteers.example.org/")
+	db := setupAnimalTestDB(t)
+	admin, group := createAnimalTestUser(t, db, "groupadmin", "groupadmin@example.com", false)
+	member := CreateTestUser(t, db, "walker", "walker@example.com", "password123", false)
+	AddUserToGroupWithAdmin(t, db, member.ID, group.ID, false)
+	animal := createTestAnimal(t, db, group.ID, "Rex", "Dog")
+	require.NoError(t, db.Model(animal).Updates(map[string]interface{}{
+		"description": "Loves tennis balls", "image_url": "/api/images/abc", "trainer_notes": "internal only",
+	}).Error)
+
+	// Sharing is opt-in per group
+	c, w := shareLinkTestContext(admin.ID, group.ID, animal.ID, 0, http.MethodPost, "/")
+	CreateAnimalShareLink(db)(c)
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+
+	require.NoError(t, db.Model(group).Update("public_sharing", true).Error)
+
+	c, w = shareLinkTestContext(member.ID, group.ID, animal.ID, 0, http.MethodPost, "/")
+	CreateAnimalShareLink(db)(c)
+	assert.Equal(t, http.StatusForbidden, w.Code, "non-admin members cannot create share links")
+
+	c, w = shareLinkTestContext(admin.ID, group.ID, animal.ID, 0, http.MethodPost, "/")
+	CreateAnimalShareLink(db)(c)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var link animalShareLinkResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &link))
+	assert.Equal(t, "https://volunteers.example.org/share/"+link.Token, link.URL)
+	assert.True(t, strings.HasPrefix(link.Token, fmt.Sprintf("%d.", link.ID)))
+
+	t.Run("public view shows only shareable fields", func(t *testing.T) {
+		w := getSharedAnimal(db, link.Token)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.NotContains(t, w.Body.String(), "internal only")
+		var shared sharedAnimalResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &shared))
+		assert.Equal(t, sharedAnimalResponse{
+			Name: "Rex", Species: "Dog", Breed: "Test Breed", Description: "Loves tennis balls", ImageURL: "/api/images/abc",
+			Status: "available", StatusLabel: "Available", GroupName: group.Name,
+		}, shared)
+
+		var stored models.AnimalShareLink
+		require.NoError(t, db.First(&stored, link.ID).Error)
+		assert.Equal(t, 1, stored.ViewCount)
+		assert.NotNil(t, stored.LastViewedAt)
+	})
+
+	t.Run("forged and tampered tokens are rejected", func(t *testing.T) {
+		_, sig, _ := strings.Cut(link.Token, ".")
+		for _, token := range []string{"garbage", fmt.Sprintf("%d.", link.ID), fmt.Sprintf("%d.%s", link.ID+1, sig), link.Token + "x"} {
+			assert.Equal(t, http.StatusNotFound, getSharedAnimal(db, token).Code, token)
+		}
+	})
+
+	t.Run("QR code encodes a PNG", func(t *testing.T) {
+		c, w := shareLinkTestContext(admin.ID, group.ID, animal.ID, link.ID, http.MethodGet, "/?scale=2")
+		GetAnimalShareLinkQRCode(db)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+		_, err := png.Decode(bytes.NewReader(w.Body.Bytes()))
+		assert.NoError(t, err)
+
+		c, w = shareLinkTestContext(admin.ID, group.ID, animal.ID, link.ID, http.MethodGet, "/?scale=100")
+		GetAnimalShareLinkQRCode(db)(c)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("disabling group sharing hides existing links", func(t *testing.T) {
+		require.NoError(t, db.Model(group).Update("public_sharing", false).Error)
+		assert.Equal(t, http.StatusNotFound, getSharedAnimal(db, link.Token).Code)
+		require.NoError(t, db.Model(group).Update("public_sharing", true).Error)
+		assert.Equal(t, http.StatusOK, getSharedAnimal(db, link.Token).Code)
+	})
+
+	t.Run("revoked links stop working", func(t *testing.T) {
+		c, w := shareLinkTestContext(admin.ID, group.ID, animal.ID, link.ID, http.MethodDelete, "/")
+		RevokeAnimalShareLink(db)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, http.StatusNotFound, getSharedAnimal(db, link.Token).Code)
+
+		c, w = shareLinkTestContext(admin.ID, group.ID, animal.ID, link.ID, http.MethodGet, "/")
+		GetAnimalShareLinkQRCode(db)(c)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		c, w = shareLinkTestContext(admin.ID, group.ID, animal.ID, 0, http.MethodGet, "/")
+		GetAnimalShareLinks(db)(c)
+		require.Equal(t, http.StatusOK, w.Code)
+		var links []animalShareLinkResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &links))
+		require.Len(t, links, 1)
+		assert.NotNil(t, links[0].RevokedAt)
+	})
+}
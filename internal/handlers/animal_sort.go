@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	maxAnimalPageSize = 500
+)
+
+// animalSortKey describes one whitelisted ?sort= value for animal lists.
+// order returns the ORDER BY expressions for a direction. Columns are
+// qualified with the animals table because some lists join other tables.
+type animalSortKey struct {
+	defaultDesc bool
+	order       func(dir string) []string
+}
+
+// nullsLast orders column in dir with NULLs after every value, which
+// Postgres and SQLite both support through the IS NULL expression.
+func nullsLast(column, dir string) []string {
+	return []string{column + " IS NULL", column + " " + dir}
+}
+
+// reverse flips an ASC/DESC direction.
+func reverse(dir string) string {
+	if dir == "ASC" {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+// animalSortKeys is the whitelist of ?sort= values for GET /animals and
+// GET /admin/animals. Only these expressions ever reach ORDER BY.
+var animalSortKeys = map[string]animalSortKey{
+	"name": {order: func(dir string) []string {
+		return []string{"LOWER(animals.name) " + dir}
+	}},
+	"arrival_date": {defaultDesc: true, order: func(dir string) []string {
+		return nullsLast("animals.arrival_date", dir)
+	}},
+	"last_status_change": {defaultDesc: true, order: func(dir string) []string {
+		return nullsLast("animals.last_status_change", dir)
+	}},
+	// Ascending is youngest first. Animals with an estimated birth date sort
+	// by it; the rest follow, by their recorded age.
+	"age": {order: func(dir string) []string {
+		return append(nullsLast("animals.estimated_birth_date", reverse(dir)), "animals.age "+dir)
+	}},
+	"recently_commented": {defaultDesc: true, order: func(dir string) []string {
+		return nullsLast("(SELECT MAX(animal_comments.created_at) FROM animal_comments WHERE animal_comments.animal_id = animals.id AND animal_comments.deleted_at IS NULL)", dir)
+	}},
+}
+
+// animalSortNames returns the valid ?sort= values, for error messages.
+func animalSortNames() string {
+	names := make([]string, 0, len(animalSortKeys))
+	for name := range animalSortKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// applyAnimalSort orders query by ?sort= and ?order= (asc or desc; each sort
+// has its own default). With no sort, defaultOrder applies. Every order ends
+// with the animal ID so pages don't overlap when sort values tie.
+func applyAnimalSort(params url.Values, query *gorm.DB, defaultOrder ...string) (*gorm.DB, error) {
+	name := params.Get("sort")
+	order := strings.ToLower(params.Get("order"))
+	if order != "" && order != "asc" && order != "desc" {
+		return nil, fmt.Errorf("order must be asc or desc")
+	}
+
+	var expressions []string
+	if name == "" {
+		expressions = defaultOrder
+	} else {
+		key, ok := animalSortKeys[name]
+		if !ok {
+			return nil, fmt.Errorf("sort must be one of: %s", animalSortNames())
+		}
+		dir := "ASC"
+		if order == "desc" || (order == "" && key.defaultDesc) {
+			dir = "DESC"
+		}
+		expressions = key.order(dir)
+	}
+
+	for _, expr := range expressions {
+		query = query.Order(expr)
+	}
+	return query.Order("animals.id ASC"), nil
+}
+
+// animalPage reads ?limit= and ?offset= for animal lists. Without a limit the
+// whole list is returned and offset is ignored.
+func animalPage(params url.Values) (limit, offset int, err error) {
+	if v := params.Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxAnimalPageSize {
+			return 0, 0, fmt.Errorf("limit must be between 1 and %d", maxAnimalPageSize)
+		}
+	}
+	if v := params.Get("offset"); v != "" {
+		offset, err = strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("offset must be a non-negative number")
+		}
+	}
+	return limit, offset, nil
+}
+
+// applyAnimalPage applies ?limit= and ?offset= to query. When a limit is
+// given, the number of animals matching before paging is sent in the
+// X-Total-Count header. It responds 400 on invalid paging.
+func applyAnimalPage(c *gin.Context, params url.Values, query, countQuery *gorm.DB) (*gorm.DB, bool) {
+	limit, offset, err := animalPage(params)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return nil, false
+	}
+	if limit == 0 {
+		return query, true
+	}
+	var total int64
+	if err := countQuery.Count(&total).Error; err != nil {
+		respondInternalError(c, "Failed to count animals")
+		return nil, false
+	}
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	return query.Limit(limit).Offset(offset), true
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnimalListSorting(t *testing.T) {
+	db := SetupTestDB(t)
+	user := CreateTestUser(t, db, "volunteer", "volunteer@example.com", "password123", false)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	group := CreateTestGroup(t, db, "Dogs", "Dog group")
+	AddUserToGroupWithAdmin(t, db, user.ID, group.ID, false)
+
+	day := func(n int) *time.Time {
+		d := time.Date(2026, 1, n, 0, 0, 0, 0, time.UTC)
+		return &d
+	}
+	animal := func(name string, arrival, birth *time.Time, age int) *models.Animal {
+		a := CreateTestAnimal(t, db, group.ID, name, "Dog")
+		require.NoError(t, db.Model(a).Updates(map[string]interface{}{
+			"arrival_date": arrival, "estimated_birth_date": birth, "age": age,
+		}).Error)
+		return a
+	}
+	bella := animal("bella", day(5), day(1), 0)
+	animal("Charlie", day(10), nil, 3)
+	zeus := animal("Zeus", nil, day(20), 0)
+	animal("Apollo", day(1), nil, 7)
+	require.NoError(t, db.Create(&models.AnimalComment{AnimalID: bella.ID, UserID: user.ID, Content: "old", CreatedAt: *day(2)}).Error)
+	require.NoError(t, db.Create(&models.AnimalComment{AnimalID: zeus.ID, UserID: user.ID, Content: "new", CreatedAt: *day(3)}).Error)
+
+	list := func(query string) (int, []string, string) {
+		c, w := accountTestContext(user.ID, false, http.MethodGet, "/api/groups/1/animals?saved_filter=none&"+query, nil)
+		c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}}
+		GetAnimals(db)(c)
+		var animals []models.Animal
+		_ = json.Unmarshal(w.Body.Bytes(), &animals)
+		names := make([]string, len(animals))
+		for i, a := range animals {
+			names[i] = a.Name
+		}
+		return w.Code, names, w.Header().Get("X-Total-Count")
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"bella", "Charlie", "Zeus", "Apollo"}},
+		{"sort=name", []string{"Apollo", "bella", "Charlie", "Zeus"}},
+		{"sort=name&order=DESC", []string{"Zeus", "Charlie", "bella", "Apollo"}},
+		{"sort=arrival_date", []string{"Charlie", "bella", "Apollo", "Zeus"}},
+		{"sort=arrival_date&order=asc", []string{"Apollo", "bella", "Charlie", "Zeus"}},
+		{"sort=age", []string{"Zeus", "bella", "Charlie", "Apollo"}},
+		{"sort=age&order=desc", []string{"bella", "Zeus", "Apollo", "Charlie"}},
+		{"sort=recently_commented", []string{"Zeus", "bella", "Charlie", "Apollo"}},
+	}
+	for _, tt := range tests {
+		code, names, _ := list(tt.query)
+		require.Equal(t, http.StatusOK, code, tt.query)
+		assert.Equal(t, tt.want, names, tt.query)
+	}
+
+	code, names, total := list("sort=name&limit=2&offset=1")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"bella", "Charlie"}, names)
+	assert.Equal(t, "4", total)
+
+	for _, query := range []string{"sort=weight", "sort=name%3BDROP%20TABLE%20animals", "sort=name&order=sideways", "limit=0", "limit=501", "offset=-1"} {
+		code, _, _ := list(query)
+		assert.Equal(t, http.StatusBadRequest, code, query)
+	}
+
+	// The admin list sorts and pages the same way
+	c, w := accountTestContext(admin.ID, true, http.MethodGet, "/api/admin/animals?sort=arrival_date&order=asc&limit=3", nil)
+	GetAllAnimals(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var animals []models.Animal
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &animals))
+	require.Len(t, animals, 3)
+	assert.Equal(t, "Apollo", animals[0].Name)
+	assert.Equal(t, "4", w.Header().Get("X-Total-Count"))
+}
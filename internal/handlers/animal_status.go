@@ -0,0 +1,447 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// Error codes returned by the animal status taxonomy endpoints and by the
+// animal write paths that validate against it.
+const (
+	ErrCodeInvalidStatus ErrorCode = "INVALID_STATUS"
+	ErrCodeStatusInUse   ErrorCode = "STATUS_IN_USE"
+)
+
+var (
+	statusKeyPattern   = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+	statusColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+)
+
+// builtinStatusDateFields pins the date field of each built-in status: the
+// write paths give these keys dedicated handling (bite-quarantine incidents,
+// arrival-date reset on un-archiving), so a configured row can relabel or
+// recolor them but not change which date they drive.
+var builtinStatusDateFields = func() map[string]string {
+	m := make(map[string]string, len(models.DefaultAnimalStatuses))
+	for _, s := range models.DefaultAnimalStatuses {
+		m[s.Key] = s.DateField
+	}
+	return m
+}()
+
+// customStatusDateFields lists the date fields a non-built-in status may
+// drive. quarantine_start_date is excluded: it is reserved for bite_quarantine,
+// whose incident tracking assumes it is the only status that sets it.
+var customStatusDateFields = map[string]bool{
+	"":                             true,
+	models.StatusDateFieldFoster:   true,
+	models.StatusDateFieldArchived: true,
+}
+
+// AnimalStatusInput is one entry of an AnimalStatusesRequest.
+type AnimalStatusInput struct {
+	Key       string `json:"key" binding:"required"`
+	Label     string `json:"label" binding:"max=50"`
+	Color     string `json:"color"`
+	DateField string `json:"date_field"`
+}
+
+// AnimalStatusesRequest replaces a group's or the site's status taxonomy.
+// Order in the list becomes each status's order_index.
+type AnimalStatusesRequest struct {
+	Statuses []AnimalStatusInput `json:"statuses" binding:"dive"`
+}
+
+// effectiveAnimalStatuses returns the status taxonomy that applies to a group:
+// the group's own rows if any exist, otherwise the site-wide rows, otherwise
+// models.DefaultAnimalStatuses.
+func effectiveAnimalStatuses(db *gorm.DB, groupID uint) ([]models.AnimalStatus, error) {
+	var statuses []models.AnimalStatus
+	if err := db.Where("group_id = ?", groupID).Order("order_index, id").Find(&statuses).Error; err != nil {
+		return nil, err
+	}
+	if len(statuses) > 0 {
+		return statuses, nil
+	}
+	if err := db.Where("group_id IS NULL").Order("order_index, id").Find(&statuses).Error; err != nil {
+		return nil, err
+	}
+	if len(statuses) > 0 {
+		return statuses, nil
+	}
+	return models.DefaultAnimalStatuses, nil
+}
+
+// lookupAnimalStatus finds key in the taxonomy that applies to groupID.
+// ok is false when the key is not an allowed status for that group.
+func lookupAnimalStatus(db *gorm.DB, groupID uint, key string) (status models.AnimalStatus, ok bool, err error) {
+	statuses, err := effectiveAnimalStatuses(db, groupID)
+	if err != nil {
+		return models.AnimalStatus{}, false, err
+	}
+	for _, s := range statuses {
+		if s.Key == key {
+			return s, true, nil
+		}
+	}
+	return models.AnimalStatus{}, false, nil
+}
+
+// allowedStatusKeys renders the keys of a taxonomy for error messages.
+func allowedStatusKeys(statuses []models.AnimalStatus) string {
+	keys := make([]string, len(statuses))
+	for i, s := range statuses {
+		keys[i] = s.Key
+	}
+	return strings.Join(keys, ", ")
+}
+
+// validateAnimalStatusForGroup writes a 400 INVALID_STATUS response and
+// returns false when status is not allowed for groupID. An empty status is
+// always accepted (callers treat it as "unchanged" or "default").
+func validateAnimalStatusForGroup(c *gin.Context, db *gorm.DB, groupID uint, status string) (models.AnimalStatus, bool) {
+	if status == "" {
+		return models.AnimalStatus{}, true
+	}
+	def, ok, err := lookupAnimalStatus(db, groupID, status)
+	if err != nil {
+		respondInternalError(c, "Failed to load animal statuses")
+		return models.AnimalStatus{}, false
+	}
+	if !ok {
+		statuses, _ := effectiveAnimalStatuses(db, groupID)
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidStatus,
+			fmt.Sprintf("invalid status %q: must be one of: %s", status, allowedStatusKeys(statuses)))
+		return models.AnimalStatus{}, false
+	}
+	return def, true
+}
+
+// isBuiltinAnimalStatus reports whether key is one of the built-in statuses
+// whose side effects are hard-coded in the animal write paths.
+func isBuiltinAnimalStatus(key string) bool {
+	_, ok := builtinStatusDateFields[key]
+	return ok
+}
+
+// customStatusUpdates returns the column updates for moving an animal into a
+// configured (non-built-in) status: every status-specific field is cleared,
+// as for under_vet_care, and then the status's DateField (if any) is stamped.
+func customStatusUpdates(def models.AnimalStatus, now time.Time) map[string]interface{} {
+	updates := map[string]interface{}{
+		"foster_start_date":           nil,
+		"quarantine_start_date":       nil,
+		"quarantine_end_date":         nil,
+		"quarantine_approval_status":  "",
+		"quarantine_approval_date":    nil,
+		"archived_date":               nil,
+		"quarantine_incident_details": "",
+	}
+	if def.DateField != "" {
+		updates[def.DateField] = now
+	}
+	return updates
+}
+
+// applyCustomStatus is the struct-based counterpart of customStatusUpdates,
+// used by write paths that mutate a models.Animal and then Save it.
+func applyCustomStatus(animal *models.Animal, def models.AnimalStatus, now time.Time) {
+	animal.FosterStartDate = nil
+	animal.QuarantineStartDate = nil
+	animal.QuarantineEndDate = nil
+	animal.QuarantineApprovalStatus = ""
+	animal.QuarantineApprovalDate = nil
+	animal.ArchivedDate = nil
+	animal.QuarantineIncidentDetails = ""
+	switch def.DateField {
+	case models.StatusDateFieldFoster:
+		animal.FosterStartDate = &now
+	case models.StatusDateFieldArchived:
+		animal.ArchivedDate = &now
+	}
+}
+
+// buildAnimalStatuses validates req and converts it to rows for groupID (nil
+// for the site-wide taxonomy). Returns a user-facing error on invalid input.
+func buildAnimalStatuses(req AnimalStatusesRequest, groupID *uint) ([]models.AnimalStatus, error) {
+	if len(req.Statuses) == 0 {
+		return nil, nil
+	}
+	seen := make(map[string]bool, len(req.Statuses))
+	rows := make([]models.AnimalStatus, 0, len(req.Statuses))
+	for i, in := range req.Statuses {
+		key := strings.TrimSpace(in.Key)
+		if !statusKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid status key %q: use lowercase letters, digits, and underscores (max 50)", key)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate status key %q", key)
+		}
+		seen[key] = true
+
+		color := strings.TrimSpace(in.Color)
+		if color == "" {
+			color = "#6b7280"
+		}
+		if !statusColorPattern.MatchString(color) {
+			return nil, fmt.Errorf("invalid color %q for status %q: must be a hex color like #22c55e", color, key)
+		}
+
+		dateField := strings.TrimSpace(in.DateField)
+		if builtin, ok := builtinStatusDateFields[key]; ok {
+			if dateField != "" && dateField != builtin {
+				return nil, fmt.Errorf("status %q is built in and always drives %q", key, builtin)
+			}
+			dateField = builtin
+		} else if !customStatusDateFields[dateField] {
+			return nil, fmt.Errorf("invalid date_field %q for status %q: must be one of: %s, %s, or empty",
+				dateField, key, models.StatusDateFieldFoster, models.StatusDateFieldArchived)
+		}
+
+		label := strings.TrimSpace(in.Label)
+		if label == "" {
+			label = key
+		}
+
+		rows = append(rows, models.AnimalStatus{
+			GroupID:    groupID,
+			Key:        key,
+			Label:      label,
+			Color:      color,
+			DateField:  dateField,
+			OrderIndex: i,
+		})
+	}
+	if !seen["available"] {
+		return nil, fmt.Errorf("status list must include %q, the default status for new animals", "available")
+	}
+	return rows, nil
+}
+
+// statusKeysInUse returns the distinct statuses currently held by animals
+// matching scope that are missing from rows.
+func statusKeysInUse(scope *gorm.DB, rows []models.AnimalStatus) ([]string, error) {
+	var used []string
+	if err := scope.Model(&models.Animal{}).Distinct("status").Pluck("status", &used).Error; err != nil {
+		return nil, err
+	}
+	allowed := make(map[string]bool, len(rows))
+	for _, r := range rows {
+		allowed[r.Key] = true
+	}
+	var missing []string
+	for _, s := range used {
+		if s != "" && !allowed[s] {
+			missing = append(missing, s)
+		}
+	}
+	return missing, nil
+}
+
+// replaceAnimalStatuses swaps the rows for groupID (nil = site-wide) inside a
+// transaction. An empty rows slice removes the override entirely.
+func replaceAnimalStatuses(db *gorm.DB, groupID *uint, rows []models.AnimalStatus) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		del := tx.Where("group_id IS NULL")
+		if groupID != nil {
+			del = tx.Where("group_id = ?", *groupID)
+		}
+		if err := del.Delete(&models.AnimalStatus{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.Create(&rows).Error
+	})
+}
+
+// GetAnimalStatuses returns the status taxonomy in effect for a group
+// Route: GET /api/groups/:id/animal-statuses
+func GetAnimalStatuses(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+
+		statuses, err := effectiveAnimalStatuses(db, uint(gid))
+		if err != nil {
+			respondInternalError(c, "Failed to load animal statuses")
+			return
+		}
+
+		respondOK(c, statuses)
+	}
+}
+
+// UpdateGroupAnimalStatuses replaces a group's status taxonomy (group admin or
+// site admin). Sending an empty list removes the override so the group falls
+// back to the site-wide taxonomy.
+// Route: PUT /api/groups/:id/animal-statuses
+func UpdateGroupAnimalStatuses(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		logger := middleware.GetLogger(c)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
+			return
+		}
+
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+		groupIDUint := uint(gid)
+
+		var req AnimalStatusesRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+
+		rows, err := buildAnimalStatuses(req, &groupIDUint)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidStatus, err.Error())
+			return
+		}
+
+		// Validate removals against the taxonomy the group would actually end
+		// up with (its new rows, or the site-wide fallback when cleared).
+		effective := rows
+		if len(effective) == 0 {
+			if err := db.Where("group_id IS NULL").Find(&effective).Error; err != nil {
+				respondInternalError(c, "Failed to load animal statuses")
+				return
+			}
+			if len(effective) == 0 {
+				effective = models.DefaultAnimalStatuses
+			}
+		}
+		inUse, err := statusKeysInUse(db.Where("group_id = ?", groupIDUint), effective)
+		if err != nil {
+			respondInternalError(c, "Failed to check animal statuses in use")
+			return
+		}
+		if len(inUse) > 0 {
+			respondError(c, http.StatusConflict, ErrCodeStatusInUse,
+				"Cannot remove statuses still assigned to animals: "+strings.Join(inUse, ", "))
+			return
+		}
+
+		if err := replaceAnimalStatuses(db, &groupIDUint, rows); err != nil {
+			logger.Error("Failed to update group animal statuses", err)
+			respondInternalError(c, "Failed to update animal statuses")
+			return
+		}
+
+		statuses, err := effectiveAnimalStatuses(db, groupIDUint)
+		if err != nil {
+			respondInternalError(c, "Failed to load animal statuses")
+			return
+		}
+
+		logger.WithFields(map[string]interface{}{
+			"group_id": groupIDUint,
+			"count":    len(rows),
+		}).Info("Updated group animal statuses")
+
+		respondOK(c, statuses)
+	}
+}
+
+// GetSiteAnimalStatuses returns the site-wide status taxonomy (admin only),
+// or the built-in defaults when none is configured.
+// Route: GET /api/admin/animal-statuses
+func GetSiteAnimalStatuses(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+
+		var statuses []models.AnimalStatus
+		if err := db.Where("group_id IS NULL").Order("order_index, id").Find(&statuses).Error; err != nil {
+			respondInternalError(c, "Failed to load animal statuses")
+			return
+		}
+		if len(statuses) == 0 {
+			statuses = models.DefaultAnimalStatuses
+		}
+
+		respondOK(c, statuses)
+	}
+}
+
+// UpdateSiteAnimalStatuses replaces the site-wide status taxonomy (admin only).
+// Groups with their own taxonomy are unaffected. Sending an empty list
+// restores the built-in defaults.
+// Route: PUT /api/admin/animal-statuses
+func UpdateSiteAnimalStatuses(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		logger := middleware.GetLogger(c)
+
+		var req AnimalStatusesRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+
+		rows, err := buildAnimalStatuses(req, nil)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidStatus, err.Error())
+			return
+		}
+
+		effective := rows
+		if len(effective) == 0 {
+			effective = models.DefaultAnimalStatuses
+		}
+		// Only groups without their own taxonomy inherit the site-wide one.
+		scope := db.Where("group_id NOT IN (?)",
+			db.Model(&models.AnimalStatus{}).Distinct("group_id").Where("group_id IS NOT NULL"))
+		inUse, err := statusKeysInUse(scope, effective)
+		if err != nil {
+			respondInternalError(c, "Failed to check animal statuses in use")
+			return
+		}
+		if len(inUse) > 0 {
+			respondError(c, http.StatusConflict, ErrCodeStatusInUse,
+				"Cannot remove statuses still assigned to animals: "+strings.Join(inUse, ", "))
+			return
+		}
+
+		if err := replaceAnimalStatuses(db, nil, rows); err != nil {
+			logger.Error("Failed to update site animal statuses", err)
+			respondInternalError(c, "Failed to update animal statuses")
+			return
+		}
+
+		logger.WithField("count", len(rows)).Info("Updated site-wide animal statuses")
+
+		respondOK(c, effective)
+	}
+}
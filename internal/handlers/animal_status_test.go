@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func putAnimalStatuses(t *testing.T, handler gin.HandlerFunc, userID uint, isAdmin bool, groupID uint, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	c, w := setupAnimalTestContext(userID, isAdmin)
+	c.Params = gin.Params{{Key: "id", Value: fmt.Sprintf("%d", groupID)}}
+	c.Request = httptest.NewRequest(http.MethodPut, "/animal-statuses", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handler(c)
+	return w
+}
+
+func TestEffectiveAnimalStatuses_Fallbacks(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	group := CreateTestGroup(t, db, "Dogs", "")
+
+	statuses, err := effectiveAnimalStatuses(db, group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DefaultAnimalStatuses, statuses, "no rows should fall back to built-in defaults")
+
+	require.NoError(t, db.Create(&[]models.AnimalStatus{
+		{Key: "available", Label: "Ready", OrderIndex: 0},
+		{Key: "hold", Label: "On Hold", OrderIndex: 1},
+	}).Error)
+	statuses, err = effectiveAnimalStatuses(db, group.ID)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2, "site-wide rows should apply when the group has none")
+	assert.Equal(t, "hold", statuses[1].Key)
+
+	gid := group.ID
+	require.NoError(t, db.Create(&models.AnimalStatus{GroupID: &gid, Key: "available", Label: "Available"}).Error)
+	statuses, err = effectiveAnimalStatuses(db, group.ID)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1, "group rows should override site-wide rows")
+}
+
+func TestBuildAnimalStatuses(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"valid custom status", `{"statuses":[{"key":"available"},{"key":"trial_adoption","date_field":"foster_start_date"}]}`, ""},
+		{"missing available", `{"statuses":[{"key":"foster"}]}`, "must include"},
+		{"duplicate key", `{"statuses":[{"key":"available"},{"key":"available"}]}`, "duplicate"},
+		{"bad key", `{"statuses":[{"key":"available"},{"key":"On Hold"}]}`, "invalid status key"},
+		{"bad color", `{"statuses":[{"key":"available","color":"red"}]}`, "invalid color"},
+		{"reserved date field", `{"statuses":[{"key":"available"},{"key":"isolation","date_field":"quarantine_start_date"}]}`, "invalid date_field"},
+		{"builtin date field changed", `{"statuses":[{"key":"available"},{"key":"foster","date_field":"archived_date"}]}`, "built in"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req AnimalStatusesRequest
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &req))
+			rows, err := buildAnimalStatuses(req, nil)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "available", rows[0].Label, "label should default to key")
+			assert.Equal(t, "#6b7280", rows[0].Color, "color should default to gray")
+			assert.Equal(t, 1, rows[1].OrderIndex)
+		})
+	}
+}
+
+func TestUpdateGroupAnimalStatuses(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	member := CreateTestUser(t, db, "member", "member@example.com", "password123", false)
+	groupAdmin := CreateTestUser(t, db, "gadmin", "gadmin@example.com", "password123", false)
+	AddUserToGroupWithAdmin(t, db, member.ID, group.ID, false)
+	AddUserToGroupWithAdmin(t, db, groupAdmin.ID, group.ID, true)
+
+	body := `{"statuses":[{"key":"available","label":"Adoptable"},{"key":"trial_adoption","label":"Trial Adoption","date_field":"foster_start_date"}]}`
+
+	w := putAnimalStatuses(t, UpdateGroupAnimalStatuses(db), member.ID, false, group.ID, body)
+	assert.Equal(t, http.StatusForbidden, w.Code, "plain members cannot edit statuses")
+
+	w = putAnimalStatuses(t, UpdateGroupAnimalStatuses(db), groupAdmin.ID, false, group.ID, body)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var statuses []models.AnimalStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &statuses))
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "Adoptable", statuses[0].Label)
+	assert.Equal(t, models.StatusDateFieldFoster, statuses[1].DateField)
+
+	// Removing a status still held by an animal is refused.
+	animal := CreateTestAnimal(t, db, group.ID, "Rex", "Dog")
+	require.NoError(t, db.Model(animal).Update("status", "trial_adoption").Error)
+	w = putAnimalStatuses(t, UpdateGroupAnimalStatuses(db), groupAdmin.ID, false, group.ID, `{"statuses":[{"key":"available"}]}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "trial_adoption")
+
+	// Clearing the override also checks against the inherited taxonomy.
+	w = putAnimalStatuses(t, UpdateGroupAnimalStatuses(db), groupAdmin.ID, false, group.ID, `{"statuses":[]}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	require.NoError(t, db.Model(animal).Update("status", "available").Error)
+	w = putAnimalStatuses(t, UpdateGroupAnimalStatuses(db), groupAdmin.ID, false, group.ID, `{"statuses":[]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &statuses))
+	assert.Len(t, statuses, len(models.DefaultAnimalStatuses), "empty list should revert to the defaults")
+}
+
+func TestAnimalWrites_ValidateStatusAgainstGroupTaxonomy(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	user := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+
+	create := func(status string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(AnimalRequest{Name: "Rex", Species: "Dog", Status: status})
+		c, w := setupAnimalTestContext(user.ID, true)
+		c.Params = gin.Params{{Key: "id", Value: fmt.Sprintf("%d", group.ID)}}
+		c.Request = httptest.NewRequest(http.MethodPost, "/animals", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.Header.Set("X-API-Version", "2")
+		CreateAnimal(db, nil, &embedding.StubEmbedder{})(c)
+		return w
+	}
+
+	w := create("trial_adoption")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	var errResp ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResp))
+	assert.Equal(t, ErrCodeInvalidStatus, errResp.Code)
+
+	gid := group.ID
+	require.NoError(t, db.Create(&[]models.AnimalStatus{
+		{GroupID: &gid, Key: "available", Label: "Available", OrderIndex: 0},
+		{GroupID: &gid, Key: "trial_adoption", Label: "Trial Adoption", DateField: models.StatusDateFieldFoster, OrderIndex: 1},
+	}).Error)
+
+	w = create("trial_adoption")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var animal models.Animal
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &animal))
+	assert.Equal(t, "trial_adoption", animal.Status)
+	assert.NotNil(t, animal.FosterStartDate, "custom status should stamp its configured date field")
+
+	w = create("foster")
+	assert.Equal(t, http.StatusBadRequest, w.Code, "statuses dropped by the group override are rejected")
+}
@@ -15,6 +15,9 @@ type AnimalTagRequest struct {
 	Name     string `json:"name" binding:"required,min=1,max=50"`
 	Category string `json:"category" binding:"required,oneof=behavior walker_status"`
 	Color    string `json:"color" binding:"required"`
+	// Restricted hides animals with the tag from members without a matching
+	// qualification. Omitted on update, it is left unchanged.
+	Restricted *bool `json:"restricted"`
 }
 
 // GetAnimalTags returns all animal tags for a specific group
@@ -76,6 +79,9 @@ func CreateAnimalTag(db *gorm.DB) gin.HandlerFunc {
 			Category: req.Category,
 			Color:    req.Color,
 		}
+		if req.Restricted != nil {
+			tag.Restricted = *req.Restricted
+		}
 
 		if err := db.Create(&tag).Error; err != nil {
 			logger.Error("Failed to create animal tag", err)
@@ -126,6 +132,9 @@ func UpdateAnimalTag(db *gorm.DB) gin.HandlerFunc {
 		tag.Name = req.Name
 		tag.Category = req.Category
 		tag.Color = req.Color
+		if req.Restricted != nil {
+			tag.Restricted = *req.Restricted
+		}
 
 		if err := db.Save(&tag).Error; err != nil {
 			logger.Error("Failed to update animal tag", err)
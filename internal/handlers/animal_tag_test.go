@@ -22,7 +22,7 @@ func setupAnimalTagTestDB(t *testing.T) *gorm.DB {
 	}
 
 	// Migrate models
-	err = db.AutoMigrate(&models.AnimalTag{}, &models.Animal{}, &models.Group{}, &models.User{}, &models.UserGroup{})
+	err = db.AutoMigrate(&models.AnimalTag{}, &models.AnimalStatus{}, &models.Animal{}, &models.Group{}, &models.User{}, &models.UserGroup{})
 	if err != nil {
 		t.Fatalf("Failed to migrate database: %v", err)
 	}
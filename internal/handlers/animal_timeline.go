@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// newAnimalTimelineQuery builds the feed branches for one animal's history:
+// comments, edits (which include status and name changes), renames from
+// merges and imports, and approved photos. ?type= narrows it to one of
+// comments, changes, name_changes, or photos.
+func newAnimalTimelineQuery(c *gin.Context, animalID uint) activityFeedQuery {
+	var q activityFeedQuery
+	filterType := c.Query("type")
+	include := func(kind string) bool {
+		return filterType == "" || filterType == "all" || filterType == kind
+	}
+
+	if include("comments") {
+		q.branches = append(q.branches, feedBranch{
+			kind: feedKindComment, idCol: "ac.id", createdAt: "ac.created_at",
+			from: "FROM animal_comments ac WHERE ac.animal_id = ? AND ac.deleted_at IS NULL",
+			args: []interface{}{animalID},
+		})
+	}
+	if include("changes") {
+		// An edit that moves the animal between groups is recorded once per
+		// group; skip the old group's copy, whose group_id is the change's
+		// old value.
+		q.branches = append(q.branches, feedBranch{
+			kind: feedKindAnimalChange, idCol: "ch.id", createdAt: "ch.created_at",
+			from: "FROM animal_changes ch WHERE ch.animal_id = ? AND ch.changes NOT LIKE " +
+				`'%{"field":"group_id","old":"' || CAST(ch.group_id AS TEXT) || '","new":"%'`,
+			args: []interface{}{animalID},
+		})
+	}
+	if include("name_changes") {
+		// Renames made by an edit are already in that edit's change
+		q.branches = append(q.branches, feedBranch{
+			kind: feedKindNameChange, idCol: "nh.id", createdAt: "nh.created_at",
+			from: "FROM animal_name_histories nh WHERE nh.animal_id = ? AND COALESCE(nh.source, '') <> ?",
+			args: []interface{}{animalID, models.NameChangeEdit},
+		})
+	}
+	if include("photos") {
+		q.branches = append(q.branches, feedBranch{
+			kind: feedKindPhoto, idCol: "ai.id", createdAt: "ai.created_at",
+			from: "FROM animal_images ai WHERE ai.animal_id = ? AND ai.deleted_at IS NULL AND ai.moderation_status = ?",
+			args: []interface{}{animalID, models.ImageModerationApproved},
+		})
+	}
+	return q
+}
+
+// GetAnimalTimeline returns one animal's history, newest first, as activity
+// feed items of type comment, animal_change, name_change, and photo. Pages
+// the same way as the group activity feed: ?limit=, then ?cursor=<next_cursor>.
+// Route: GET /api/groups/:id/animals/:animalId/timeline
+func GetAnimalTimeline(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := middleware.GetUserID(c)
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+
+		var animal models.Animal
+		if err := visibleAnimals(c, db, db.Select("id", "group_id").Where("id = ? AND group_id = ?", c.Param("animalId"), groupID), groupID).First(&animal).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeAnimalNotFound, "Animal not found")
+			return
+		}
+
+		limit, offset, cursor, ok := feedPageParams(c)
+		if !ok {
+			return
+		}
+		switch c.Query("type") {
+		case "", "all", "comments", "changes", "name_changes", "photos":
+		default:
+			respondBadRequest(c, "type must be one of: all, comments, changes, name_changes, photos")
+			return
+		}
+
+		query := newAnimalTimelineQuery(c, animal.ID)
+		refs, err := query.page(db, cursor, offset, limit)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to fetch animal timeline", err)
+			respondInternalError(c, "Failed to fetch timeline")
+			return
+		}
+		hasMore := len(refs) > limit
+		if hasMore {
+			refs = refs[:limit]
+		}
+
+		items, err := hydrateFeed(db, refs, userID)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to load animal timeline items", err)
+			respondInternalError(c, "Failed to fetch timeline")
+			return
+		}
+		policy, err := loadFieldPolicy(c, db, animal.GroupID)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to load field visibility", err)
+			respondInternalError(c, "Failed to fetch timeline")
+			return
+		}
+		items = hideFeedChanges(items, policy)
+		// Every item is about this animal; don't repeat it on each one
+		for i := range items {
+			items[i].Animal = nil
+		}
+
+		total, err := query.total(db)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to count animal timeline", err)
+			respondInternalError(c, "Failed to fetch timeline")
+			return
+		}
+
+		var nextCursor *string
+		if hasMore {
+			last := refs[len(refs)-1]
+			encoded := feedCursor{CreatedAt: last.CreatedAt, Kind: last.Kind, ID: last.ID}.encode()
+			nextCursor = &encoded
+		}
+
+		respondOK(c, gin.H{
+			"items":       items,
+			"total":       total,
+			"limit":       limit,
+			"offset":      offset,
+			"hasMore":     hasMore,
+			"next_cursor": nextCursor,
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAnimalTimeline(t *testing.T) {
+	db := SetupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AnimalImage{}))
+	user := CreateTestUser(t, db, "volunteer", "volunteer@example.com", "password123", false)
+	outsider := CreateTestUser(t, db, "outsider", "outsider@example.com", "password123", false)
+	dogs := CreateTestGroup(t, db, "Dogs", "Dog group")
+	cats := CreateTestGroup(t, db, "Cats", "Cat group")
+	AddUserToGroupWithAdmin(t, db, user.ID, dogs.ID, false)
+	AddUserToGroupWithAdmin(t, db, outsider.ID, cats.ID, false)
+	buddy := CreateTestAnimal(t, db, dogs.ID, "Buddy", "Dog")
+	other := CreateTestAnimal(t, db, dogs.ID, "Other", "Dog")
+
+	at := func(minutes int) time.Time {
+		return time.Date(2026, 3, 1, 12, minutes, 0, 0, time.UTC)
+	}
+	animalID := buddy.ID
+	require.NoError(t, db.Create(&models.AnimalComment{AnimalID: buddy.ID, UserID: user.ID, Content: "first walk", CreatedAt: at(1)}).Error)
+	require.NoError(t, db.Create(&models.AnimalComment{AnimalID: other.ID, UserID: user.ID, Content: "not buddy", CreatedAt: at(2)}).Error)
+	require.NoError(t, db.Create(&models.AnimalImage{AnimalID: &animalID, UserID: user.ID, ImageURL: "/api/images/1", Caption: "at the park", CreatedAt: at(3)}).Error)
+	require.NoError(t, db.Create(&models.AnimalImage{AnimalID: &animalID, UserID: user.ID, ImageURL: "/api/images/2", ModerationStatus: models.ImageModerationPending, CreatedAt: at(4)}).Error)
+	// A rename through an edit is recorded twice; the edit's change covers it
+	require.NoError(t, db.Create(&models.AnimalNameHistory{AnimalID: buddy.ID, OldName: "Bud", NewName: "Buddy", ChangedBy: user.ID, Source: models.NameChangeEdit, CreatedAt: at(5)}).Error)
+	require.NoError(t, db.Create(&models.AnimalChange{GroupID: dogs.ID, AnimalID: buddy.ID, UserID: user.ID, Source: models.AnimalChangeEdit, CreatedAt: at(5),
+		Changes: models.AnimalFieldChanges{{Field: "name", Old: "Bud", New: "Buddy"}, {Field: "status", Old: "available", New: "foster"}}}).Error)
+	// A move between groups is recorded in both groups
+	move := models.AnimalFieldChanges{{Field: "group_id", Old: fmt.Sprint(cats.ID), New: fmt.Sprint(dogs.ID)}}
+	require.NoError(t, db.Create(&models.AnimalChange{GroupID: dogs.ID, AnimalID: buddy.ID, UserID: user.ID, Source: models.AnimalChangeAdminEdit, CreatedAt: at(6), Changes: move}).Error)
+	require.NoError(t, db.Create(&models.AnimalChange{GroupID: cats.ID, AnimalID: buddy.ID, UserID: user.ID, Source: models.AnimalChangeAdminEdit, CreatedAt: at(6), Changes: move}).Error)
+	require.NoError(t, db.Create(&models.AnimalNameHistory{AnimalID: buddy.ID, OldName: "Buddy Too", NewName: "Buddy", ChangedBy: user.ID, Source: models.NameChangeMerge, CreatedAt: at(7)}).Error)
+
+	type timelineResponse struct {
+		Items      []ActivityItem `json:"items"`
+		Total      int64          `json:"total"`
+		HasMore    bool           `json:"hasMore"`
+		NextCursor *string        `json:"next_cursor"`
+	}
+	get := func(userID uint, groupID, animalID uint, query string) (int, timelineResponse) {
+		c, w := accountTestContext(userID, false, http.MethodGet, "/api/groups/1/animals/1/timeline"+query, nil)
+		c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(groupID)}, {Key: "animalId", Value: fmt.Sprint(animalID)}}
+		GetAnimalTimeline(db)(c)
+		var resp timelineResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+	types := func(items []ActivityItem) []string {
+		out := make([]string, len(items))
+		for i, item := range items {
+			out[i] = item.Type
+		}
+		return out
+	}
+
+	code, resp := get(user.ID, dogs.ID, buddy.ID, "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"name_change", "animal_change", "animal_change", "photo", "comment"}, types(resp.Items))
+	assert.Equal(t, int64(5), resp.Total)
+	assert.False(t, resp.HasMore)
+	assert.Equal(t, []models.AnimalFieldChange{{Field: "name", Old: "Buddy Too", New: "Buddy"}}, resp.Items[0].Changes)
+	assert.Equal(t, "at the park", resp.Items[3].Content)
+	assert.Equal(t, "/api/images/1", resp.Items[3].ImageURL)
+	require.NotNil(t, resp.Items[4].User)
+	assert.Equal(t, "volunteer", resp.Items[4].User.Username)
+	for _, item := range resp.Items {
+		assert.Nil(t, item.Animal)
+	}
+
+	// Cursor paging walks the same items
+	var paged []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 5, "pagination should terminate")
+		code, resp := get(user.ID, dogs.ID, buddy.ID, "?limit=2"+cursor)
+		require.Equal(t, http.StatusOK, code)
+		paged = append(paged, types(resp.Items)...)
+		if !resp.HasMore {
+			break
+		}
+		require.NotNil(t, resp.NextCursor)
+		cursor = "&cursor=" + *resp.NextCursor
+	}
+	assert.Equal(t, []string{"name_change", "animal_change", "animal_change", "photo", "comment"}, paged)
+
+	code, resp = get(user.ID, dogs.ID, buddy.ID, "?type=photos")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"photo"}, types(resp.Items))
+
+	code, _ = get(user.ID, dogs.ID, buddy.ID, "?type=tasks")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = get(outsider.ID, dogs.ID, buddy.ID, "")
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = get(outsider.ID, cats.ID, buddy.ID, "")
+	assert.Equal(t, http.StatusNotFound, code)
+}
@@ -1,33 +1,43 @@
 package handlers
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
-	"image"
-	_ "image/gif" // Register GIF format
-	"image/jpeg"
-	_ "image/png" // Register PNG format
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/maintenance"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/moderation"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/upload"
-	"github.com/nfnt/resize"
 	"gorm.io/gorm"
 )
 
+// unsupportedHEIFMessage tells the uploader how to get a HEIC photo in when
+// the server can't convert it
+const unsupportedHEIFMessage = "HEIC/HEIF photos can't be converted on this server. Upload a JPEG or PNG instead, or set the iPhone camera format to Most Compatible."
+
+// imageDecodeErrorMessage is the response for an upload ProcessImage
+// couldn't decode
+func imageDecodeErrorMessage(err error) string {
+	if errors.Is(err, upload.ErrUnsupportedCodec) {
+		return unsupportedHEIFMessage
+	}
+	return "Invalid image file"
+}
+
 // UploadAnimalImage handles secure animal image uploads with optimization
 // Images are stored in the database for persistence across container restarts
-func UploadAnimalImage(db *gorm.DB) gin.HandlerFunc {
+func UploadAnimalImage(db *gorm.DB, imageConfig *upload.ImageConfigStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		db := middleware.GetDB(c, db)
 		logger := middleware.GetLogger(c)
+		cfg := imageConfig.Get(c.Request.Context())
 
 		// Get animal ID from URL parameter
 		animalIDStr := c.Param("animalId")
@@ -52,7 +62,7 @@ func UploadAnimalImage(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		// Validate file upload (size, type, content)
-		if err := upload.ValidateImageUpload(file, upload.MaxImageSize); err != nil {
+		if err := upload.ValidateImageUpload(file, cfg.MaxUploadBytes); err != nil {
 			logger.Error("File validation failed", err)
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file: " + err.Error()})
 			return
@@ -67,52 +77,26 @@ func UploadAnimalImage(db *gorm.DB) gin.HandlerFunc {
 		}
 		defer src.Close()
 
-		// Decode the image
-		img, format, err := image.Decode(src)
+		// Resize and re-encode using the configured image settings, with
+		// smaller copies for list views
+		processed, variants, err := upload.ProcessImageWithVariants(src, cfg.MaxDimension, cfg)
 		if err != nil {
-			logger.Error("Failed to decode image", err)
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image file"})
-			return
-		}
-		logger.WithFields(map[string]interface{}{
-			"format": format,
-			"width":  img.Bounds().Dx(),
-			"height": img.Bounds().Dy(),
-		}).Debug("Received image for upload")
-
-		// Resize image if it's larger than 1200px on the longest side
-		maxDimension := uint(1200)
-		var resizedImg image.Image
-
-		bounds := img.Bounds()
-		width := uint(bounds.Dx())
-		height := uint(bounds.Dy())
-
-		if width > maxDimension || height > maxDimension {
-			if width > height {
-				resizedImg = resize.Resize(maxDimension, 0, img, resize.Lanczos3)
-			} else {
-				resizedImg = resize.Resize(0, maxDimension, img, resize.Lanczos3)
+			if errors.Is(err, upload.ErrInvalidFile) {
+				logger.Error("Failed to decode image", err)
+				c.JSON(http.StatusBadRequest, gin.H{"error": imageDecodeErrorMessage(err)})
+				return
 			}
-			logger.WithFields(map[string]interface{}{
-				"new_width":  resizedImg.Bounds().Dx(),
-				"new_height": resizedImg.Bounds().Dy(),
-			}).Debug("Image resized")
-		} else {
-			resizedImg = img
-			logger.Debug("Image dimensions acceptable, no resizing needed")
-		}
-
-		// Encode image to JPEG bytes
-		var buf bytes.Buffer
-		if err := jpeg.Encode(&buf, resizedImg, &jpeg.Options{Quality: 85}); err != nil {
 			logger.Error("Failed to encode image", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process image"})
 			return
 		}
+		logger.WithFields(map[string]interface{}{
+			"format": processed.SourceFormat,
+			"width":  processed.Width,
+			"height": processed.Height,
+		}).Debug("Processed uploaded image")
 
-		imageData := buf.Bytes()
-		finalBounds := resizedImg.Bounds()
+		imageData := processed.Data
 
 		// Generate unique image identifier
 		imageUUID := uuid.New().String()
@@ -125,9 +109,9 @@ func UploadAnimalImage(db *gorm.DB) gin.HandlerFunc {
 			UserID:    userID,
 			ImageURL:  imageURL,
 			ImageData: imageData,
-			MimeType:  "image/jpeg",
-			Width:     finalBounds.Dx(),
-			Height:    finalBounds.Dy(),
+			MimeType:  processed.MimeType,
+			Width:     processed.Width,
+			Height:    processed.Height,
 			FileSize:  int64(len(imageData)),
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
@@ -138,6 +122,11 @@ func UploadAnimalImage(db *gorm.DB) gin.HandlerFunc {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save image"})
 			return
 		}
+		// Clients fall back to the full image for any size that's missing, so
+		// a failure here doesn't fail the upload
+		if err := maintenance.StoreImageVariants(c.Request.Context(), db, nil, animalImage, variants); err != nil {
+			logger.Error("Failed to save image variants", err)
+		}
 
 		logger.WithFields(map[string]interface{}{
 			"image_id":  animalImage.ID,
@@ -149,13 +138,16 @@ func UploadAnimalImage(db *gorm.DB) gin.HandlerFunc {
 		c.JSON(http.StatusOK, gin.H{
 			"url":      imageURL,
 			"image_id": animalImage.ID,
-			"width":    finalBounds.Dx(),
-			"height":   finalBounds.Dy(),
+			"width":    processed.Width,
+			"height":   processed.Height,
+			"variants": animalImage.Variants,
 		})
 	}
 }
 
-// ServeImage serves an image using the configured storage provider
+// ServeImage serves an image using the configured storage provider, or a
+// smaller copy of it with ?size=thumb or ?size=card. Images waiting for
+// moderation are reported as not found.
 func ServeImage(db *gorm.DB, storageProvider storage.Provider) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
@@ -208,6 +200,29 @@ func ServeImage(db *gorm.DB, storageProvider storage.Provider) gin.HandlerFunc {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
 			return
 		}
+		if animalImage.ModerationStatus == models.ImageModerationPending {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+			return
+		}
+
+		// ?size= picks a smaller copy; images without one (too small to need
+		// it, or not backfilled yet) are served at full size
+		if size := c.Query("size"); size != "" && size != "full" {
+			if !upload.IsImageVariant(size) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "size must be thumb, card, or full"})
+				return
+			}
+			var variant models.AnimalImageVariant
+			err := db.Where("image_id = ? AND size = ?", animalImage.ID, size).First(&variant).Error
+			if err == nil {
+				serveImageVariant(c, storageProvider, variant)
+				return
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve image"})
+				return
+			}
+		}
 
 		// Check which storage provider was used for this image
 		if animalImage.StorageProvider == "azure" && animalImage.BlobIdentifier != "" {
@@ -243,6 +258,31 @@ func ServeImage(db *gorm.DB, storageProvider storage.Provider) gin.HandlerFunc {
 	}
 }
 
+// serveImageVariant writes a variant from wherever it's stored
+func serveImageVariant(c *gin.Context, storageProvider storage.Provider, variant models.AnimalImageVariant) {
+	data, mimeType := variant.ImageData, variant.MimeType
+	if variant.StorageProvider == storage.ProviderAzure && variant.BlobIdentifier != "" {
+		var err error
+		data, mimeType, err = storageProvider.GetImage(c.Request.Context(), variant.BlobIdentifier)
+		if err != nil {
+			if err == storage.ErrNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Image not found in storage"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve image"})
+			}
+			return
+		}
+	}
+	if len(data) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image data not available"})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=31536000")
+	c.Header("Content-Type", mimeType)
+	c.Header("Content-Length", strconv.Itoa(len(data)))
+	c.Data(http.StatusOK, mimeType, data)
+}
+
 // ServeVideo serves a video blob through the backend proxy.
 // GET /api/videos/:uuid
 func ServeVideo(db *gorm.DB, storageProvider storage.Provider) gin.HandlerFunc {
@@ -287,12 +327,14 @@ func serveVideoBlob(c *gin.Context, storageProvider storage.Provider, blobIdenti
 }
 
 // UploadAnimalImageSimple handles simple image upload without animal context
-// Used for profile picture uploads before animal is fully created
-func UploadAnimalImageSimple(db *gorm.DB, storageProvider storage.Provider) gin.HandlerFunc {
+// Used for profile picture uploads before animal is fully created. Images the
+// moderator flags aren't served until an admin approves them.
+func UploadAnimalImageSimple(db *gorm.DB, storageProvider storage.Provider, imageConfig *upload.ImageConfigStore, moderator moderation.Moderator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
 		db := middleware.GetDB(c, db)
 		logger := middleware.GetLogger(c)
+		cfg := imageConfig.Get(ctx)
 
 		// Get user ID from context
 		userIDVal, exists := c.Get("user_id")
@@ -317,7 +359,7 @@ func UploadAnimalImageSimple(db *gorm.DB, storageProvider storage.Provider) gin.
 		}
 
 		// Validate file upload (size, type, content)
-		if err := upload.ValidateImageUpload(file, upload.MaxImageSize); err != nil {
+		if err := upload.ValidateImageUpload(file, cfg.MaxUploadBytes); err != nil {
 			logger.Error("File validation failed", err)
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file: " + err.Error()})
 			return
@@ -332,58 +374,38 @@ func UploadAnimalImageSimple(db *gorm.DB, storageProvider storage.Provider) gin.
 		}
 		defer src.Close()
 
-		// Decode the image
-		img, format, err := image.Decode(src)
+		// Resize and re-encode using the configured image settings, with
+		// smaller copies for list views
+		processed, variants, err := upload.ProcessImageWithVariants(src, cfg.MaxDimension, cfg)
 		if err != nil {
-			logger.Error("Failed to decode image", err)
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image file"})
-			return
-		}
-		logger.WithFields(map[string]interface{}{
-			"format": format,
-			"width":  img.Bounds().Dx(),
-			"height": img.Bounds().Dy(),
-		}).Debug("Received image for upload")
-
-		// Resize image if it's larger than 1200px on the longest side
-		maxDimension := uint(1200)
-		var resizedImg image.Image
-
-		bounds := img.Bounds()
-		width := uint(bounds.Dx())
-		height := uint(bounds.Dy())
-
-		if width > maxDimension || height > maxDimension {
-			if width > height {
-				resizedImg = resize.Resize(maxDimension, 0, img, resize.Lanczos3)
-			} else {
-				resizedImg = resize.Resize(0, maxDimension, img, resize.Lanczos3)
+			if errors.Is(err, upload.ErrInvalidFile) {
+				logger.Error("Failed to decode image", err)
+				c.JSON(http.StatusBadRequest, gin.H{"error": imageDecodeErrorMessage(err)})
+				return
 			}
-		} else {
-			resizedImg = img
-		}
-
-		// Encode image to JPEG bytes
-		var buf bytes.Buffer
-		if err := jpeg.Encode(&buf, resizedImg, &jpeg.Options{Quality: 85}); err != nil {
 			logger.Error("Failed to encode image", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process image"})
 			return
 		}
+		logger.WithFields(map[string]interface{}{
+			"format": processed.SourceFormat,
+			"width":  processed.Width,
+			"height": processed.Height,
+		}).Debug("Processed uploaded image")
 
-		imageData := buf.Bytes()
-		finalBounds := resizedImg.Bounds()
+		imageData := processed.Data
+		moderationStatus, moderationLabels := moderateUpload(c, moderator, imageData, processed.MimeType)
 
 		// Generate unique image identifier
 		imageUUID := uuid.New().String()
 
 		// Upload to storage provider
 		metadata := map[string]string{
-			"width":  strconv.Itoa(finalBounds.Dx()),
-			"height": strconv.Itoa(finalBounds.Dy()),
+			"width":  strconv.Itoa(processed.Width),
+			"height": strconv.Itoa(processed.Height),
 		}
 
-		storageURL, blobUUID, blobExt, err := storageProvider.UploadImage(ctx, imageData, "image/jpeg", metadata)
+		storageURL, blobUUID, blobExt, err := storageProvider.UploadImage(ctx, imageData, processed.MimeType, metadata)
 		var imageURL string
 		var imageDataForDB []byte
 		var storageProviderName string
@@ -410,19 +432,21 @@ func UploadAnimalImageSimple(db *gorm.DB, storageProvider storage.Provider) gin.
 
 		// Create image record in database with AnimalID = nil (will be linked later)
 		animalImage := models.AnimalImage{
-			AnimalID:        nil, // Will be linked when animal is created/updated
-			UserID:          userID,
-			ImageURL:        imageURL,
-			ImageData:       imageDataForDB,
-			MimeType:        "image/jpeg",
-			Width:           finalBounds.Dx(),
-			Height:          finalBounds.Dy(),
-			FileSize:        int64(len(imageData)),
-			StorageProvider: storageProviderName,
-			BlobIdentifier:  blobIdentifier,
-			BlobExtension:   blobExt,
-			CreatedAt:       time.Now(),
-			UpdatedAt:       time.Now(),
+			AnimalID:         nil, // Will be linked when animal is created/updated
+			UserID:           userID,
+			ImageURL:         imageURL,
+			ImageData:        imageDataForDB,
+			MimeType:         processed.MimeType,
+			Width:            processed.Width,
+			Height:           processed.Height,
+			FileSize:         int64(len(imageData)),
+			StorageProvider:  storageProviderName,
+			BlobIdentifier:   blobIdentifier,
+			BlobExtension:    blobExt,
+			ModerationStatus: moderationStatus,
+			ModerationLabels: moderationLabels,
+			CreatedAt:        time.Now(),
+			UpdatedAt:        time.Now(),
 		}
 
 		if err := db.Create(&animalImage).Error; err != nil {
@@ -430,14 +454,20 @@ func UploadAnimalImageSimple(db *gorm.DB, storageProvider storage.Provider) gin.
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save image"})
 			return
 		}
+		// Clients fall back to the full image for any size that's missing, so
+		// a failure here doesn't fail the upload
+		if err := maintenance.StoreImageVariants(ctx, db, storageProvider, animalImage, variants); err != nil {
+			logger.Error("Failed to save image variants", err)
+		}
 
 		logger.WithFields(map[string]interface{}{
 			"image_id":         animalImage.ID,
 			"url":              imageURL,
 			"size":             len(imageData),
 			"storage_provider": storageProviderName,
+			"moderation":       moderationStatus,
 		}).Info("Image uploaded and stored (unlinked)")
 
-		c.JSON(http.StatusOK, gin.H{"url": imageURL})
+		c.JSON(http.StatusOK, gin.H{"url": imageURL, "variants": animalImage.Variants, "moderation_status": moderationStatus})
 	}
 }
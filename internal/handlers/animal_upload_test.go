@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/upload"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadAnimalImage_HEIC(t *testing.T) {
+	db := SetupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AnimalImage{}, &models.AnimalImageVariant{}))
+	user := CreateTestUser(t, db, "vol", "vol@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	animal := CreateTestAnimal(t, db, group.ID, "Buddy", "Dog")
+
+	heic, err := os.ReadFile(filepath.Join("..", "upload", "testdata", "photo.heic"))
+	require.NoError(t, err)
+	decoded, err := os.ReadFile(filepath.Join("..", "upload", "testdata", "photo-decoded.png"))
+	require.NoError(t, err)
+
+	send := func() *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		part, err := mw.CreateFormFile("image", "IMG_0001.HEIC")
+		require.NoError(t, err)
+		_, _ = part.Write(heic)
+		require.NoError(t, mw.Close())
+
+		c, w := setupAnimalTestContext(user.ID, false)
+		c.Params = gin.Params{{Key: "animalId", Value: itoa(animal.ID)}}
+		c.Request = httptest.NewRequest(http.MethodPost, "/animals/upload", &body)
+		c.Request.Header.Set("Content-Type", mw.FormDataContentType())
+		UploadAnimalImage(db, nil)(c)
+		return w
+	}
+
+	// Without a HEIF decoder the uploader is told what to do instead
+	w := send()
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "HEIC/HEIF photos can't be converted")
+
+	upload.RegisterHEIFDecoder(func([]byte) (image.Image, error) {
+		return png.Decode(bytes.NewReader(decoded))
+	})
+	t.Cleanup(func() { upload.RegisterHEIFDecoder(nil) })
+
+	w = send()
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		ImageID uint `json:"image_id"`
+		Width   int  `json:"width"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 8, resp.Width)
+
+	var stored models.AnimalImage
+	require.NoError(t, db.First(&stored, resp.ImageID).Error)
+	assert.Equal(t, "image/jpeg", stored.MimeType)
+}
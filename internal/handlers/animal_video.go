@@ -43,7 +43,7 @@ func GetAnimalMedia(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		var animal models.Animal
-		if err := db.Where("id = ? AND group_id = ?", animalID, groupID).First(&animal).Error; err != nil {
+		if err := visibleAnimals(c, db, db.Where("id = ? AND group_id = ?", animalID, groupID), groupID).First(&animal).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Animal not found"})
 			return
 		}
@@ -51,7 +51,7 @@ func GetAnimalMedia(db *gorm.DB) gin.HandlerFunc {
 		var images []models.AnimalImage
 		if err := db.Preload("User").
 			Select("id, created_at, updated_at, animal_id, user_id, image_url, caption, is_profile_picture, width, height, file_size").
-			Where("animal_id = ?", animalID).
+			Where("animal_id = ? AND moderation_status = ?", animalID, models.ImageModerationApproved).
 			Order("is_profile_picture DESC, created_at DESC").
 			Find(&images).Error; err != nil {
 			logger.Error("Failed to fetch animal images", err)
@@ -101,7 +101,7 @@ func UploadAnimalVideo(db *gorm.DB, storageProvider storage.Provider) gin.Handle
 		}
 
 		var animal models.Animal
-		if err := db.Where("id = ? AND group_id = ?", animalID, groupID).First(&animal).Error; err != nil {
+		if err := visibleAnimals(c, db, db.Where("id = ? AND group_id = ?", animalID, groupID), groupID).First(&animal).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Animal not found"})
 			return
 		}
@@ -294,7 +294,7 @@ func DeleteAnimalVideo(db *gorm.DB, storageProvider storage.Provider) gin.Handle
 		}
 
 		var animal models.Animal
-		if err := db.Where("id = ? AND group_id = ?", animalID, groupID).First(&animal).Error; err != nil {
+		if err := visibleAnimals(c, db, db.Where("id = ? AND group_id = ?", animalID, groupID), groupID).First(&animal).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Animal not found"})
 			return
 		}
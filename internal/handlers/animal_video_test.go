@@ -31,6 +31,8 @@ func setupVideoTestDB(t *testing.T) *gorm.DB {
 		&models.Animal{},
 		&models.AnimalImage{},
 		&models.AnimalVideo{},
+		&models.AnimalTag{},
+		&models.UserQualification{},
 	))
 	return db
 }
@@ -0,0 +1,374 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// lbPerKG converts between the two supported weight units.
+const lbPerKG = 2.20462262
+
+// WeightEntryRequest is the body for creating or updating a weight entry.
+// RecordedAt is a YYYY-MM-DD date and defaults to today.
+type WeightEntryRequest struct {
+	Weight     float64 `json:"weight" binding:"required,gt=0,lte=1000"`
+	Unit       string  `json:"unit" binding:"omitempty,oneof=lb kg"`
+	RecordedAt string  `json:"recorded_at"`
+	Notes      string  `json:"notes" binding:"max=1000"`
+}
+
+// WeightPoint is one point in a weight time series, in the series' unit.
+type WeightPoint struct {
+	EntryID    uint      `json:"entry_id"`
+	RecordedAt time.Time `json:"recorded_at"`
+	Weight     float64   `json:"weight"`
+}
+
+// WeightSeries is chart-ready weight history for one animal, oldest first.
+type WeightSeries struct {
+	AnimalID uint          `json:"animal_id"`
+	Unit     string        `json:"unit"`
+	Points   []WeightPoint `json:"points"`
+	Current  *float64      `json:"current"`
+	Min      *float64      `json:"min"`
+	Max      *float64      `json:"max"`
+	Change   *float64      `json:"change"` // Last point minus first point
+}
+
+// convertWeight converts weight from one unit to another, rounded to 0.01.
+func convertWeight(weight float64, from, to string) float64 {
+	switch {
+	case from == to:
+	case from == models.WeightUnitKG && to == models.WeightUnitLB:
+		weight *= lbPerKG
+	case from == models.WeightUnitLB && to == models.WeightUnitKG:
+		weight /= lbPerKG
+	}
+	return math.Round(weight*100) / 100
+}
+
+// parseWeightDate parses a YYYY-MM-DD recorded_at value, defaulting to
+// today. Dates in the future are rejected.
+func parseWeightDate(value string) (time.Time, error) {
+	return parsePastDate("recorded_at", value)
+}
+
+// parsePastDate parses a YYYY-MM-DD value of field, defaulting to today.
+// Dates in the future are rejected.
+func parsePastDate(field, value string) (time.Time, error) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if value == "" {
+		return today, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, errors.New(field + " must be a date in YYYY-MM-DD format")
+	}
+	if date.After(today) {
+		return time.Time{}, errors.New(field + " cannot be in the future")
+	}
+	return date, nil
+}
+
+// findGroupAnimal loads the animal named by :animalId if it belongs to the
+// :id group and the user can see it (see visibleAnimals), responding 404
+// otherwise.
+func findGroupAnimal(c *gin.Context, db *gorm.DB) (*models.Animal, bool) {
+	var animal models.Animal
+	query := db.Where("id = ? AND group_id = ?", c.Param("animalId"), c.Param("id"))
+	if err := visibleAnimals(c, db, query, c.Param("id")).First(&animal).Error; err != nil {
+		respondError(c, http.StatusNotFound, ErrCodeAnimalNotFound, "Animal not found")
+		return nil, false
+	}
+	return &animal, true
+}
+
+// checkWeightsVisible responds 403 when the group hides current_weight from
+// the caller's role
+func checkWeightsVisible(c *gin.Context, db *gorm.DB, animal *models.Animal) bool {
+	policy, err := loadFieldPolicy(c, db, animal.GroupID)
+	if err != nil {
+		middleware.GetLogger(c).Error("Failed to load field visibility", err)
+		respondInternalError(c, "Failed to fetch weight entries")
+		return false
+	}
+	if policy.Hides("current_weight") {
+		respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Weights aren't visible to your role")
+		return false
+	}
+	return true
+}
+
+// latestWeightEntry returns the animal's most recent weigh-in, or nil if it
+// has none.
+func latestWeightEntry(db *gorm.DB, animalID uint) (*models.WeightEntry, error) {
+	var entry models.WeightEntry
+	err := db.Where("animal_id = ?", animalID).Order("recorded_at DESC, id DESC").First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetAnimalWeights returns an animal's weight entries, newest first
+// Route: GET /api/groups/:id/animals/:animalId/weights
+func GetAnimalWeights(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, c.Param("id")) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+
+		animal, ok := findGroupAnimal(c, db)
+		if !ok || !checkWeightsVisible(c, db, animal) {
+			return
+		}
+
+		var entries []models.WeightEntry
+		if err := db.Preload("RecordedBy").Where("animal_id = ?", animal.ID).
+			Order("recorded_at DESC, id DESC").Find(&entries).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to fetch weight entries", err)
+			respondInternalError(c, "Failed to fetch weight entries")
+			return
+		}
+
+		respondOK(c, entries)
+	}
+}
+
+// CreateAnimalWeight records a weigh-in for an animal (any group member)
+// Route: POST /api/groups/:id/animals/:animalId/weights
+func CreateAnimalWeight(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, c.Param("id")) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+
+		uid, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+
+		animal, ok := findGroupAnimal(c, db)
+		if !ok {
+			return
+		}
+
+		var req WeightEntryRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		recordedAt, err := parseWeightDate(req.RecordedAt)
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		if req.Unit == "" {
+			req.Unit = models.WeightUnitLB
+		}
+
+		entry := models.WeightEntry{
+			AnimalID:     animal.ID,
+			Weight:       req.Weight,
+			Unit:         req.Unit,
+			RecordedAt:   recordedAt,
+			RecordedByID: uid,
+			Notes:        req.Notes,
+		}
+		if err := db.Create(&entry).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to create weight entry", err)
+			respondInternalError(c, "Failed to record weight")
+			return
+		}
+		db.Preload("RecordedBy").First(&entry, entry.ID)
+
+		respondCreated(c, entry)
+	}
+}
+
+// loadEditableWeightEntry loads the :weightId entry for the animal and checks
+// that the caller recorded it or is a group admin.
+func loadEditableWeightEntry(c *gin.Context, db *gorm.DB) (*models.WeightEntry, bool) {
+	userID, _ := c.Get("user_id")
+	isAdmin, _ := c.Get("is_admin")
+
+	if !checkGroupAccess(db, userID, isAdmin, c.Param("id")) {
+		respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+		return nil, false
+	}
+
+	animal, ok := findGroupAnimal(c, db)
+	if !ok {
+		return nil, false
+	}
+
+	var entry models.WeightEntry
+	if err := db.Where("id = ? AND animal_id = ?", c.Param("weightId"), animal.ID).First(&entry).Error; err != nil {
+		respondNotFound(c, "Weight entry not found")
+		return nil, false
+	}
+
+	uid, _ := middleware.GetUserID(c)
+	if entry.RecordedByID != uid && !checkGroupAdminAccess(db, userID, isAdmin, c.Param("id")) {
+		respondForbidden(c, "You can only change weight entries you recorded")
+		return nil, false
+	}
+	return &entry, true
+}
+
+// UpdateAnimalWeight corrects a weight entry (recorder or group admin)
+// Route: PUT /api/groups/:id/animals/:animalId/weights/:weightId
+func UpdateAnimalWeight(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+
+		entry, ok := loadEditableWeightEntry(c, db)
+		if !ok {
+			return
+		}
+
+		var req WeightEntryRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		recordedAt := entry.RecordedAt
+		if req.RecordedAt != "" {
+			var err error
+			if recordedAt, err = parseWeightDate(req.RecordedAt); err != nil {
+				respondBadRequest(c, err.Error())
+				return
+			}
+		}
+		if req.Unit == "" {
+			req.Unit = entry.Unit
+		}
+
+		if err := db.Model(entry).Updates(map[string]interface{}{
+			"weight":      req.Weight,
+			"unit":        req.Unit,
+			"recorded_at": recordedAt,
+			"notes":       req.Notes,
+		}).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to update weight entry", err)
+			respondInternalError(c, "Failed to update weight entry")
+			return
+		}
+		db.Preload("RecordedBy").First(entry, entry.ID)
+
+		respondOK(c, entry)
+	}
+}
+
+// DeleteAnimalWeight removes a weight entry (recorder or group admin)
+// Route: DELETE /api/groups/:id/animals/:animalId/weights/:weightId
+func DeleteAnimalWeight(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+
+		entry, ok := loadEditableWeightEntry(c, db)
+		if !ok {
+			return
+		}
+
+		if err := db.Delete(entry).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to delete weight entry", err)
+			respondInternalError(c, "Failed to delete weight entry")
+			return
+		}
+
+		respondOK(c, gin.H{"message": "Weight entry deleted"})
+	}
+}
+
+// GetAnimalWeightSeries returns an animal's weights as a chart-ready time
+// series, oldest first, converted to one unit. The unit defaults to that of
+// the most recent entry; ?since=YYYY-MM-DD limits the range.
+// Route: GET /api/groups/:id/animals/:animalId/weights/series
+func GetAnimalWeightSeries(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, c.Param("id")) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+
+		animal, ok := findGroupAnimal(c, db)
+		if !ok || !checkWeightsVisible(c, db, animal) {
+			return
+		}
+
+		unit := c.Query("unit")
+		if unit != "" && unit != models.WeightUnitLB && unit != models.WeightUnitKG {
+			respondBadRequest(c, "unit must be lb or kg")
+			return
+		}
+
+		query := db.Where("animal_id = ?", animal.ID)
+		if since := c.Query("since"); since != "" {
+			sinceDate, err := time.Parse("2006-01-02", since)
+			if err != nil {
+				respondBadRequest(c, "since must be a date in YYYY-MM-DD format")
+				return
+			}
+			query = query.Where("recorded_at >= ?", sinceDate)
+		}
+
+		var entries []models.WeightEntry
+		if err := query.Order("recorded_at ASC, id ASC").Find(&entries).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to fetch weight entries", err)
+			respondInternalError(c, "Failed to fetch weight entries")
+			return
+		}
+
+		if unit == "" {
+			unit = models.WeightUnitLB
+			if len(entries) > 0 {
+				unit = entries[len(entries)-1].Unit
+			}
+		}
+
+		series := WeightSeries{AnimalID: animal.ID, Unit: unit, Points: make([]WeightPoint, 0, len(entries))}
+		for _, e := range entries {
+			w := convertWeight(e.Weight, e.Unit, unit)
+			series.Points = append(series.Points, WeightPoint{EntryID: e.ID, RecordedAt: e.RecordedAt, Weight: w})
+			if series.Min == nil || w < *series.Min {
+				series.Min = &w
+			}
+			if series.Max == nil || w > *series.Max {
+				series.Max = &w
+			}
+		}
+		if n := len(series.Points); n > 0 {
+			current := series.Points[n-1].Weight
+			change := math.Round((current-series.Points[0].Weight)*100) / 100
+			series.Current, series.Change = &current, &change
+		}
+
+		respondOK(c, series)
+	}
+}
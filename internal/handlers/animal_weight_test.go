@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func weightTestContext(userID uint, isAdmin bool, groupID, animalID, weightID uint, method, target string, body any) (*gin.Context, *httptest.ResponseRecorder) {
+	c, w := setupAnimalTestContext(userID, isAdmin)
+	c.Params = gin.Params{
+		{Key: "id", Value: fmt.Sprintf("%d", groupID)},
+		{Key: "animalId", Value: fmt.Sprintf("%d", animalID)},
+	}
+	if weightID != 0 {
+		c.Params = append(c.Params, gin.Param{Key: "weightId", Value: fmt.Sprintf("%d", weightID)})
+	}
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	c.Request = httptest.NewRequest(method, target, &buf)
+	c.Request.Header.Set("Content-Type", "application/json")
+	return c, w
+}
+
+func addWeight(t *testing.T, db *gorm.DB, animalID, userID uint, weight float64, unit string, daysAgo int) models.WeightEntry {
+	t.Helper()
+	entry := models.WeightEntry{
+		AnimalID:     animalID,
+		Weight:       weight,
+		Unit:         unit,
+		RecordedAt:   time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -daysAgo),
+		RecordedByID: userID,
+	}
+	require.NoError(t, db.Create(&entry).Error)
+	return entry
+}
+
+func TestCreateAnimalWeight(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	_, group := createAnimalTestUser(t, db, "groupadmin", "groupadmin@example.com", false)
+	member := CreateTestUser(t, db, "walker", "walker@example.com", "password123", false)
+	AddUserToGroupWithAdmin(t, db, member.ID, group.ID, false)
+	outsider := CreateTestUser(t, db, "outsider", "outsider@example.com", "password123", false)
+	animal := createTestAnimal(t, db, group.ID, "Rex", "Dog")
+
+	tests := []struct {
+		name           string
+		userID         uint
+		body           map[string]interface{}
+		expectedStatus int
+	}{
+		{"member records weight with default unit and date", member.ID, map[string]interface{}{"weight": 52.4}, http.StatusCreated},
+		{"explicit kg and date", member.ID, map[string]interface{}{"weight": 23.8, "unit": "kg", "recorded_at": time.Now().AddDate(0, 0, -7).Format("2006-01-02")}, http.StatusCreated},
+		{"future date rejected", member.ID, map[string]interface{}{"weight": 50, "recorded_at": time.Now().AddDate(0, 0, 2).Format("2006-01-02")}, http.StatusBadRequest},
+		{"invalid unit rejected", member.ID, map[string]interface{}{"weight": 50, "unit": "stone"}, http.StatusBadRequest},
+		{"non-positive weight rejected", member.ID, map[string]interface{}{"weight": 0}, http.StatusBadRequest},
+		{"non-member forbidden", outsider.ID, map[string]interface{}{"weight": 50}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := weightTestContext(tt.userID, false, group.ID, animal.ID, 0, http.MethodPost, "/", tt.body)
+			CreateAnimalWeight(db)(c)
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+		})
+	}
+
+	var entries []models.WeightEntry
+	require.NoError(t, db.Where("animal_id = ?", animal.ID).Order("id").Find(&entries).Error)
+	require.Len(t, entries, 2)
+	assert.Equal(t, models.WeightUnitLB, entries[0].Unit)
+	assert.Equal(t, member.ID, entries[0].RecordedByID)
+}
+
+func TestUpdateDeleteAnimalWeight_Permissions(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	groupAdmin, group := createAnimalTestUser(t, db, "groupadmin", "groupadmin@example.com", false)
+	recorder := CreateTestUser(t, db, "recorder", "recorder@example.com", "password123", false)
+	other := CreateTestUser(t, db, "other", "other@example.com", "password123", false)
+	AddUserToGroupWithAdmin(t, db, recorder.ID, group.ID, false)
+	AddUserToGroupWithAdmin(t, db, other.ID, group.ID, false)
+	animal := createTestAnimal(t, db, group.ID, "Rex", "Dog")
+	entry := addWeight(t, db, animal.ID, recorder.ID, 50, models.WeightUnitLB, 1)
+
+	update := map[string]interface{}{"weight": 51.5}
+	c, w := weightTestContext(other.ID, false, group.ID, animal.ID, entry.ID, http.MethodPut, "/", update)
+	UpdateAnimalWeight(db)(c)
+	assert.Equal(t, http.StatusForbidden, w.Code, "other members can't edit")
+
+	c, w = weightTestContext(recorder.ID, false, group.ID, animal.ID, entry.ID, http.MethodPut, "/", update)
+	UpdateAnimalWeight(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var updated models.WeightEntry
+	require.NoError(t, db.First(&updated, entry.ID).Error)
+	assert.Equal(t, 51.5, updated.Weight)
+	assert.Equal(t, models.WeightUnitLB, updated.Unit, "unit is kept when omitted")
+	assert.True(t, updated.RecordedAt.Equal(entry.RecordedAt), "date is kept when omitted")
+
+	c, w = weightTestContext(groupAdmin.ID, false, group.ID, animal.ID, entry.ID, http.MethodDelete, "/", nil)
+	DeleteAnimalWeight(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var count int64
+	db.Model(&models.WeightEntry{}).Count(&count)
+	assert.Zero(t, count)
+}
+
+func TestGetAnimalWeightSeries(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	user, group := createAnimalTestUser(t, db, "member", "member@example.com", false)
+	animal := createTestAnimal(t, db, group.ID, "Rex", "Dog")
+	addWeight(t, db, animal.ID, user.ID, 20, models.WeightUnitKG, 21)
+	addWeight(t, db, animal.ID, user.ID, 46.3, models.WeightUnitLB, 14)
+	addWeight(t, db, animal.ID, user.ID, 48, models.WeightUnitLB, 7)
+
+	series := func(query string) (int, WeightSeries) {
+		c, w := weightTestContext(user.ID, false, group.ID, animal.ID, 0, http.MethodGet, "/series"+query, nil)
+		GetAnimalWeightSeries(db)(c)
+		var s WeightSeries
+		_ = json.Unmarshal(w.Body.Bytes(), &s)
+		return w.Code, s
+	}
+
+	code, s := series("")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, models.WeightUnitLB, s.Unit, "defaults to the latest entry's unit")
+	require.Len(t, s.Points, 3)
+	assert.Equal(t, 44.09, s.Points[0].Weight, "kg entries are converted")
+	assert.Equal(t, 48.0, *s.Current)
+	assert.Equal(t, 44.09, *s.Min)
+	assert.Equal(t, 3.91, *s.Change)
+
+	code, s = series("?unit=kg&since=" + time.Now().AddDate(0, 0, -15).Format("2006-01-02"))
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, s.Points, 2)
+	assert.Equal(t, 21.0, s.Points[0].Weight)
+
+	code, _ = series("?unit=stone")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestGetAnimal_IncludesCurrentWeight(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	user, group := createAnimalTestUser(t, db, "member", "member@example.com", false)
+	animal := createTestAnimal(t, db, group.ID, "Rex", "Dog")
+	addWeight(t, db, animal.ID, user.ID, 46, models.WeightUnitLB, 14)
+	latest := addWeight(t, db, animal.ID, user.ID, 48, models.WeightUnitLB, 0)
+	addWeight(t, db, animal.ID, user.ID, 47, models.WeightUnitLB, 7)
+
+	c, w := weightTestContext(user.ID, false, group.ID, animal.ID, 0, http.MethodGet, "/", nil)
+	GetAnimal(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp models.Animal
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotNil(t, resp.CurrentWeight)
+	assert.Equal(t, latest.ID, resp.CurrentWeight.ID, "current weight is by recorded date, not insertion order")
+}
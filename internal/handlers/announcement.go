@@ -2,12 +2,16 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/groupme"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
@@ -15,28 +19,146 @@ import (
 )
 
 type AnnouncementRequest struct {
-	Title       string `json:"title" binding:"required,min=2,max=200"`
-	Content     string `json:"content" binding:"required,min=10"`
-	SendEmail   bool   `json:"send_email"`
-	SendGroupMe bool   `json:"send_groupme"`
+	Title       string     `json:"title" binding:"required,min=2,max=200"`
+	Content     string     `json:"content" binding:"required,min=10"`
+	SendEmail   bool       `json:"send_email"`
+	SendGroupMe bool       `json:"send_groupme"`
+	PublishAt   *time.Time `json:"publish_at"` // RFC 3339; omit or use a past time to publish immediately
+	ExpiresAt   *time.Time `json:"expires_at"` // RFC 3339; omit to never expire
+	// Groups to target; omit for site-wide. Ignored on the group announcement route
+	GroupIDs []uint `json:"group_ids" binding:"omitempty,max=50,dive,gt=0"`
 }
 
-// GetAnnouncements returns all announcements (accessible to all authenticated users)
+// schedule returns the publish and expiry times to store. A publish time
+// that isn't in the future is treated as "now" and stored as nil.
+func (req AnnouncementRequest) schedule(now time.Time) (publishAt, expiresAt *time.Time, err error) {
+	if req.PublishAt != nil && req.PublishAt.After(now) {
+		publishAt = req.PublishAt
+	}
+	if req.ExpiresAt != nil {
+		start := now
+		if publishAt != nil {
+			start = *publishAt
+		}
+		if !req.ExpiresAt.After(start) {
+			return nil, nil, errors.New("expires_at must be after the publish time")
+		}
+		expiresAt = req.ExpiresAt
+	}
+	return publishAt, expiresAt, nil
+}
+
+// preloadAnnouncementGroups loads the id and name of an announcement's
+// target groups.
+func preloadAnnouncementGroups(db *gorm.DB) *gorm.DB {
+	return db.Preload("Groups", func(db *gorm.DB) *gorm.DB {
+		return db.Select("groups.id", "groups.name").Order("groups.name")
+	})
+}
+
+// visibleAnnouncements limits query to site-wide announcements and those
+// posted to, or targeting, a group the user belongs to.
+func visibleAnnouncements(query *gorm.DB, userID uint) *gorm.DB {
+	return query.Where(`(announcements.group_id IS NULL AND NOT EXISTS (SELECT 1 FROM announcement_groups ag WHERE ag.announcement_id = announcements.id))
+		OR announcements.group_id IN (SELECT group_id FROM user_groups WHERE user_id = ?)
+		OR EXISTS (SELECT 1 FROM announcement_groups ag JOIN user_groups ug ON ug.group_id = ag.group_id WHERE ag.announcement_id = announcements.id AND ug.user_id = ?)`,
+		userID, userID)
+}
+
+// announcementGroupIDs returns the groups whose members an announcement is
+// for, or nil when it is site-wide.
+func announcementGroupIDs(a models.Announcement) []uint {
+	if a.GroupID != nil {
+		return []uint{*a.GroupID}
+	}
+	var ids []uint
+	for _, group := range a.Groups {
+		ids = append(ids, group.ID)
+	}
+	return ids
+}
+
+// liveAnnouncements limits query to published, unexpired announcements.
+func liveAnnouncements(query *gorm.DB, now time.Time) *gorm.DB {
+	return query.Where("(announcements.publish_at IS NULL OR announcements.publish_at <= ?) AND (announcements.expires_at IS NULL OR announcements.expires_at > ?)", now, now)
+}
+
+// GetAnnouncements returns recent announcements (accessible to all authenticated users).
+// Non-admins only see published, unexpired announcements that are site-wide or
+// for one of their groups; site admins see everything, including scheduled and
+// expired ones. Each announcement says whether the user has read it, and the
+// X-Unread-Count header holds how many live announcements they haven't read.
 func GetAnnouncements(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		db := middleware.GetDB(c, db)
+		userID, ok := middleware.GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "User context not found"})
+			return
+		}
+		isSiteAdmin := middleware.IsSiteAdmin(c)
+		now := time.Now()
+
+		query := preloadAnnouncementGroups(db.Preload("User"))
+		if !isSiteAdmin {
+			query = visibleAnnouncements(liveAnnouncements(query, now), userID)
+		}
 
 		var announcements []models.Announcement
-		if err := db.Preload("User").Order("created_at DESC").Limit(10).Find(&announcements).Error; err != nil {
+		if err := query.Order("COALESCE(publish_at, created_at) DESC").Limit(10).Find(&announcements).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch announcements"})
 			return
 		}
 
+		markReadAnnouncements(db, userID, announcements)
+		if unread, err := countUnreadAnnouncements(db, userID, isSiteAdmin, now); err == nil {
+			c.Header("X-Unread-Count", strconv.FormatInt(unread, 10))
+		} else {
+			middleware.GetLogger(c).Error("Failed to count unread announcements", err)
+		}
+
 		c.JSON(http.StatusOK, announcements)
 	}
 }
 
-// CreateAnnouncement creates a new announcement and optionally sends emails and GroupMe messages (admin only)
+// announcementTargets loads the groups an announcement targets. Site admins
+// may target any groups or none (site-wide); group admins must target at
+// least one group and administer every group they target.
+func announcementTargets(c *gin.Context, db *gorm.DB, groupIDs []uint) ([]models.Group, int, string) {
+	if len(groupIDs) == 0 {
+		if !middleware.IsSiteAdmin(c) {
+			return nil, http.StatusForbidden, "Only site admins can post site-wide announcements"
+		}
+		return nil, 0, ""
+	}
+
+	seen := make(map[uint]bool, len(groupIDs))
+	var ids []uint
+	for _, id := range groupIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, id := range ids {
+		if !IsGroupAdminOrSiteAdmin(c, db, id) {
+			return nil, http.StatusForbidden, "Admin access required for every targeted group"
+		}
+	}
+
+	var groups []models.Group
+	if err := db.Where("id IN ?", ids).Find(&groups).Error; err != nil {
+		return nil, http.StatusInternalServerError, "Failed to load groups"
+	}
+	if len(groups) != len(ids) {
+		return nil, http.StatusNotFound, "Group not found"
+	}
+	return groups, 0, ""
+}
+
+// CreateAnnouncement creates a new announcement and optionally sends emails and GroupMe messages.
+// Site admins may post site-wide or to any groups in group_ids; group admins may
+// post to groups they administer.
 func CreateAnnouncement(db *gorm.DB, emailService *email.Service, groupMeService *groupme.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		db := middleware.GetDB(c, db)
@@ -52,42 +174,62 @@ func CreateAnnouncement(db *gorm.DB, emailService *email.Service, groupMeService
 			return
 		}
 
+		groups, status, msg := announcementTargets(c, db, req.GroupIDs)
+		if status != 0 {
+			c.JSON(status, gin.H{"error": msg})
+			return
+		}
+
+		now := time.Now()
+		publishAt, expiresAt, err := req.schedule(now)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		announcement := models.Announcement{
 			UserID:      userIDUint,
 			Title:       req.Title,
 			Content:     req.Content,
 			SendEmail:   req.SendEmail,
 			SendGroupMe: req.SendGroupMe,
+			PublishAt:   publishAt,
+			ExpiresAt:   expiresAt,
+			Groups:      groups,
+		}
+		groupIDs := announcementGroupIDs(announcement)
+		// Scheduled announcements are sent by the announcement scheduler
+		// once publish_at passes; immediate ones are sent below.
+		publishNow := publishAt == nil
+		if publishNow && (req.SendEmail || req.SendGroupMe) {
+			announcement.NotifiedAt = &now
 		}
 
-		if err := db.Create(&announcement).Error; err != nil {
+		// Only link the existing groups; never write the group rows themselves
+		if err := db.Omit("Groups.*").Create(&announcement).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create announcement"})
 			return
 		}
 
-		// Load the user information for the response
-		if err := db.Preload("User").First(&announcement, announcement.ID).Error; err != nil {
+		// Load the user and group information for the response
+		if err := preloadAnnouncementGroups(db.Preload("User")).First(&announcement, announcement.ID).Error; err != nil {
 			logger := middleware.GetLogger(c)
 			logger.Error("Failed to load announcement user", err)
 		}
 
-		// Send emails if requested and email service is configured
-		if req.SendEmail && emailService != nil && emailService.IsConfigured() {
-			// Use background context for async email sending
-			go func() {
-				bgCtx := context.Background()
-				if err := sendAnnouncementEmails(bgCtx, db, emailService, announcement.Title, announcement.Content); err != nil {
-					logging.WithContext(bgCtx).Error("Error sending announcement emails", err)
-				}
-			}()
+		// Queue emails if requested and email service is configured
+		if publishNow && req.SendEmail && emailService != nil && emailService.IsConfigured() {
+			if _, err := enqueueTrackedAnnouncementEmails(c.Request.Context(), db, groupIDs, announcement); err != nil {
+				middleware.GetLogger(c).Error("Error queueing announcement emails", err)
+			}
 		}
 
 		// Send GroupMe messages if requested
-		if req.SendGroupMe && groupMeService != nil {
+		if publishNow && req.SendGroupMe && groupMeService != nil {
 			// Use background context for async GroupMe sending
 			go func() {
 				bgCtx := context.Background()
-				if err := sendAnnouncementToGroupMe(bgCtx, db, groupMeService, announcement.Title, announcement.Content); err != nil {
+				if err := sendAnnouncementToGroupMe(bgCtx, db, groupMeService, groupIDs, announcement.Title, announcement.Content); err != nil {
 					logging.WithContext(bgCtx).Error("Error sending announcement to GroupMe", err)
 				}
 			}()
@@ -116,40 +258,130 @@ func DeleteAnnouncement(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-// sendAnnouncementEmails sends announcement emails to all users who have opted in
-func sendAnnouncementEmails(ctx context.Context, db *gorm.DB, emailService *email.Service, title, content string) error {
+// JobAnnouncementEmail is the background job type that sends one
+// announcement email to one user.
+const JobAnnouncementEmail = "announcement_email"
+
+// announcementEmailJob is the payload of a JobAnnouncementEmail job.
+type announcementEmailJob struct {
+	UserID         uint   `json:"user_id"`
+	Title          string `json:"title"`
+	Content        string `json:"content"`
+	GroupID        uint   `json:"group_id,omitempty"`        // Set when sent to one group's members, to send as its email sender
+	AnnouncementID uint   `json:"announcement_id,omitempty"` // Set for announcements, whose delivery, opens, and clicks are recorded
+}
+
+// enqueueAnnouncementEmails queues an announcement email for every user who
+// has opted in (and verified their address, when REQUIRE_EMAIL_VERIFICATION
+// is set), limited to members of groupIDs when any are given. Members of
+// several targeted groups get one email. One job per recipient means a retry
+// never re-sends to users who already got it. Emails to a single group's
+// members are sent as that group's email sender.
+// Returns the number of emails queued.
+func enqueueAnnouncementEmails(ctx context.Context, db *gorm.DB, groupIDs []uint, title, content string) (int, error) {
+	return queueAnnouncementEmails(ctx, db, groupIDs, announcementEmailJob{Title: title, Content: content})
+}
+
+// enqueueTrackedAnnouncementEmails queues an announcement's emails like
+// enqueueAnnouncementEmails, recording their delivery and, while the
+// email_tracking setting is on, their opens and clicks
+func enqueueTrackedAnnouncementEmails(ctx context.Context, db *gorm.DB, groupIDs []uint, a models.Announcement) (int, error) {
+	return queueAnnouncementEmails(ctx, db, groupIDs, announcementEmailJob{Title: a.Title, Content: a.Content, AnnouncementID: a.ID})
+}
+
+// queueAnnouncementEmails queues a copy of job for each recipient
+func queueAnnouncementEmails(ctx context.Context, db *gorm.DB, groupIDs []uint, job announcementEmailJob) (int, error) {
 	logger := logging.WithContext(ctx)
 
-	var users []models.User
-	if err := db.WithContext(ctx).Where("email_notifications_enabled = ?", true).Find(&users).Error; err != nil {
+	query := notifiableUsers(db.WithContext(ctx).Model(&models.User{}))
+	if len(groupIDs) > 0 {
+		query = query.Where("users.id IN (?)", db.WithContext(ctx).Table("user_groups").Select("user_id").Where("group_id IN ?", groupIDs))
+	}
+	var userIDs []uint
+	if err := query.Pluck("users.id", &userIDs).Error; err != nil {
 		logger.Error("Failed to fetch users for email notifications", err)
-		return err
+		return 0, err
 	}
 
-	logger.WithField("user_count", len(users)).Info("Sending announcement emails to users")
-	successCount := 0
-	for _, user := range users {
-		if err := emailService.SendAnnouncementEmail(ctx, user.Email, title, content); err != nil {
-			// Don't log email addresses to prevent PII leakage - just log the error
-			logger.Error("Failed to send announcement email to user", err)
+	if len(groupIDs) == 1 {
+		job.GroupID = groupIDs[0]
+	}
+	payloads := make([]interface{}, len(userIDs))
+	for i, id := range userIDs {
+		job.UserID = id
+		payloads[i] = job
+	}
+	if err := jobs.EnqueueMany(db.WithContext(ctx), JobAnnouncementEmail, payloads); err != nil {
+		logger.Error("Failed to queue announcement emails", err)
+		return 0, err
+	}
+
+	fields := map[string]interface{}{"user_count": len(userIDs)}
+	if len(groupIDs) > 0 {
+		fields["group_ids"] = groupIDs
+	}
+	logger.WithFields(fields).Info("Queued announcement emails")
+	return len(userIDs), nil
+}
+
+// announcementEmailJobHandler sends a JobAnnouncementEmail. Users who have
+// opted out or been deleted since the job was queued are skipped.
+func announcementEmailJobHandler(db *gorm.DB, emailService *email.Service) jobs.Handler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var job announcementEmailJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return jobs.Permanent(err)
+		}
+		if emailService == nil || !emailService.IsConfigured() {
+			return errors.New("email service is not configured")
+		}
+
+		var user models.User
+		err := notifiableUsers(db.WithContext(ctx)).First(&user, job.UserID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if job.GroupID != 0 {
+			ctx = groupSenderContext(ctx, db, job.GroupID)
+		}
+		if job.AnnouncementID == 0 {
+			return emailService.SendAnnouncementEmail(ctx, user.Email, job.Title, job.Content)
+		}
+
+		if emailTrackingEnabled(db.WithContext(ctx)) {
+			tracking, trackErr := announcementEmailTracking(job.AnnouncementID, user.ID)
+			if trackErr != nil {
+				return trackErr
+			}
+			err = emailService.SendTrackedAnnouncementEmail(ctx, user.Email, job.Title, job.Content, tracking)
 		} else {
-			successCount++
+			err = emailService.SendAnnouncementEmail(ctx, user.Email, job.Title, job.Content)
+		}
+		if err != nil {
+			return err
 		}
+		if err := recordAnnouncementEmailEvent(db.WithContext(ctx), job.AnnouncementID, user.ID, models.EmailEventDelivered, ""); err != nil {
+			logging.WithContext(ctx).WithField("announcement_id", job.AnnouncementID).Error("Failed to record announcement email delivery", err)
+		}
+		return nil
 	}
-	logger.WithFields(map[string]interface{}{
-		"success_count": successCount,
-		"total_count":   len(users),
-	}).Info("Announcement email sending completed")
-	return nil
 }
 
-// sendAnnouncementToGroupMe sends announcement to all GroupMe-enabled groups
-func sendAnnouncementToGroupMe(ctx context.Context, db *gorm.DB, groupMeService *groupme.Service, title, content string) error {
+// sendAnnouncementToGroupMe sends announcement to the GroupMe-enabled groups
+// among groupIDs, or to all of them when groupIDs is empty
+func sendAnnouncementToGroupMe(ctx context.Context, db *gorm.DB, groupMeService *groupme.Service, groupIDs []uint, title, content string) error {
 	logger := logging.WithContext(ctx)
 
-	// Fetch all groups with GroupMe enabled
+	// Fetch the groups with GroupMe enabled
+	query := db.WithContext(ctx).Where("groupme_enabled = ? AND groupme_bot_id != ?", true, "")
+	if len(groupIDs) > 0 {
+		query = query.Where("id IN ?", groupIDs)
+	}
 	var groups []models.Group
-	if err := db.WithContext(ctx).Where("groupme_enabled = ? AND groupme_bot_id != ?", true, "").Find(&groups).Error; err != nil {
+	if err := query.Find(&groups).Error; err != nil {
 		logger.Error("Failed to fetch GroupMe-enabled groups", err)
 		return err
 	}
@@ -220,12 +452,26 @@ func CreateGroupAnnouncement(db *gorm.DB, emailService *email.Service, groupMeSe
 			return
 		}
 
+		now := time.Now()
+		publishAt, expiresAt, err := req.schedule(now)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		announcement := models.Announcement{
 			UserID:      userIDUint,
+			GroupID:     &group.ID,
 			Title:       req.Title,
 			Content:     req.Content,
 			SendEmail:   req.SendEmail,
 			SendGroupMe: req.SendGroupMe,
+			PublishAt:   publishAt,
+			ExpiresAt:   expiresAt,
+		}
+		publishNow := publishAt == nil
+		if publishNow && (req.SendEmail || req.SendGroupMe) {
+			announcement.NotifiedAt = &now
 		}
 
 		if err := db.Create(&announcement).Error; err != nil {
@@ -239,19 +485,16 @@ func CreateGroupAnnouncement(db *gorm.DB, emailService *email.Service, groupMeSe
 			logger.Error("Failed to load announcement user", err)
 		}
 
-		// Send emails if requested and email service is configured
+		// Queue emails if requested and email service is configured
 		// Only send to group members who have opted in
-		if req.SendEmail && emailService != nil && emailService.IsConfigured() {
-			go func() {
-				bgCtx := context.Background()
-				if err := sendGroupAnnouncementEmails(bgCtx, db, emailService, group.ID, announcement.Title, announcement.Content); err != nil {
-					logging.WithContext(bgCtx).Error("Error sending group announcement emails", err)
-				}
-			}()
+		if publishNow && req.SendEmail && emailService != nil && emailService.IsConfigured() {
+			if _, err := enqueueTrackedAnnouncementEmails(c.Request.Context(), db, []uint{group.ID}, announcement); err != nil {
+				middleware.GetLogger(c).Error("Error queueing group announcement emails", err)
+			}
 		}
 
 		// Send GroupMe message if requested and group has GroupMe enabled
-		if req.SendGroupMe && groupMeService != nil && group.GroupMeEnabled && group.GroupMeBotID != "" {
+		if publishNow && req.SendGroupMe && groupMeService != nil && group.GroupMeEnabled && group.GroupMeBotID != "" {
 			go func() {
 				bgCtx := context.Background()
 				if err := groupMeService.SendAnnouncement(bgCtx, group.GroupMeBotID, announcement.Title, announcement.Content); err != nil {
@@ -266,39 +509,3 @@ func CreateGroupAnnouncement(db *gorm.DB, emailService *email.Service, groupMeSe
 		c.JSON(http.StatusCreated, announcement)
 	}
 }
-
-// sendGroupAnnouncementEmails sends announcement emails to group members who have opted in
-func sendGroupAnnouncementEmails(ctx context.Context, db *gorm.DB, emailService *email.Service, groupID uint, title, content string) error {
-	logger := logging.WithContext(ctx)
-
-	// Fetch group members who have email notifications enabled
-	var users []models.User
-	if err := db.WithContext(ctx).
-		Joins("JOIN user_groups ON user_groups.user_id = users.id").
-		Where("user_groups.group_id = ? AND users.email_notifications_enabled = ?", groupID, true).
-		Find(&users).Error; err != nil {
-		logger.Error("Failed to fetch group members for email notifications", err)
-		return err
-	}
-
-	logger.WithFields(map[string]interface{}{
-		"user_count": len(users),
-		"group_id":   groupID,
-	}).Info("Sending group announcement emails to members")
-
-	successCount := 0
-	for _, user := range users {
-		if err := emailService.SendAnnouncementEmail(ctx, user.Email, title, content); err != nil {
-			// Don't log email addresses to prevent PII leakage - just log the error
-			logger.Error("Failed to send announcement email to user", err)
-		} else {
-			successCount++
-		}
-	}
-	logger.WithFields(map[string]interface{}{
-		"success_count": successCount,
-		"total_count":   len(users),
-		"group_id":      groupID,
-	}).Info("Group announcement email sending completed")
-	return nil
-}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// AnnouncementReader is a user in an announcement read report.
+type AnnouncementReader struct {
+	ID        uint       `json:"id"`
+	Username  string     `json:"username"`
+	FirstName string     `json:"first_name"`
+	LastName  string     `json:"last_name"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+}
+
+// AnnouncementReadReport reports who among an announcement's audience has
+// and hasn't read it.
+type AnnouncementReadReport struct {
+	AnnouncementID uint                 `json:"announcement_id"`
+	Title          string               `json:"title"`
+	AudienceCount  int                  `json:"audience_count"`
+	ReadCount      int                  `json:"read_count"`
+	Read           []AnnouncementReader `json:"read"`
+	Unread         []AnnouncementReader `json:"unread"`
+}
+
+// markReadAnnouncements sets IsRead on each announcement the user has read.
+// Errors leave the flags false; the listing itself should not fail because
+// of them.
+func markReadAnnouncements(db *gorm.DB, userID uint, announcements []models.Announcement) {
+	if len(announcements) == 0 {
+		return
+	}
+	ids := make([]uint, len(announcements))
+	for i, a := range announcements {
+		ids[i] = a.ID
+	}
+	var readIDs []uint
+	if err := db.Model(&models.AnnouncementRead{}).
+		Where("user_id = ? AND announcement_id IN ?", userID, ids).
+		Pluck("announcement_id", &readIDs).Error; err != nil {
+		return
+	}
+	read := make(map[uint]bool, len(readIDs))
+	for _, id := range readIDs {
+		read[id] = true
+	}
+	for i := range announcements {
+		announcements[i].IsRead = read[announcements[i].ID]
+	}
+}
+
+// countUnreadAnnouncements counts the live announcements the user can see
+// and hasn't read. Site admins can see every announcement.
+func countUnreadAnnouncements(db *gorm.DB, userID uint, isSiteAdmin bool, now time.Time) (int64, error) {
+	query := liveAnnouncements(db.Model(&models.Announcement{}), now).
+		Where("NOT EXISTS (SELECT 1 FROM announcement_reads ar WHERE ar.announcement_id = announcements.id AND ar.user_id = ?)", userID)
+	if !isSiteAdmin {
+		query = visibleAnnouncements(query, userID)
+	}
+	var unread int64
+	err := query.Count(&unread).Error
+	return unread, err
+}
+
+// MarkAnnouncementRead records that the current user has read an
+// announcement they can see. Repeating the call is a no-op.
+// Route: POST /api/announcements/:id/read
+func MarkAnnouncementRead(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		announcementID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid announcement ID")
+			return
+		}
+		userID, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+
+		query := db.Where("announcements.id = ?", announcementID)
+		if !middleware.IsSiteAdmin(c) {
+			query = visibleAnnouncements(liveAnnouncements(query, time.Now()), userID)
+		}
+		var announcement models.Announcement
+		if err := query.First(&announcement).Error; err != nil {
+			respondNotFound(c, "Announcement not found")
+			return
+		}
+
+		read := models.AnnouncementRead{AnnouncementID: announcement.ID, UserID: userID}
+		if err := db.Where(read).FirstOrCreate(&read).Error; err != nil {
+			respondInternalError(c, "Failed to mark announcement read")
+			return
+		}
+
+		respondOK(c, read)
+	}
+}
+
+// GetAnnouncementReads reports which users the announcement is for have and
+// haven't read it (admin only). Site-wide announcements are for every user;
+// group announcements are for the members of their groups.
+// Route: GET /api/admin/announcements/:id/reads
+func GetAnnouncementReads(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		announcementID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid announcement ID")
+			return
+		}
+
+		var announcement models.Announcement
+		if err := db.Preload("Groups").First(&announcement, announcementID).Error; err != nil {
+			respondNotFound(c, "Announcement not found")
+			return
+		}
+
+		audience := db.Model(&models.User{}).Select("users.id, users.username, users.first_name, users.last_name")
+		if groupIDs := announcementGroupIDs(announcement); len(groupIDs) > 0 {
+			audience = audience.Where("users.id IN (SELECT user_id FROM user_groups WHERE group_id IN ?)", groupIDs)
+		}
+		var users []AnnouncementReader
+		if err := audience.Order("users.username ASC").Scan(&users).Error; err != nil {
+			respondInternalError(c, "Failed to fetch announcement audience")
+			return
+		}
+
+		var reads []models.AnnouncementRead
+		if err := db.Where("announcement_id = ?", announcement.ID).Find(&reads).Error; err != nil {
+			respondInternalError(c, "Failed to fetch announcement reads")
+			return
+		}
+		readAt := make(map[uint]time.Time, len(reads))
+		for _, r := range reads {
+			readAt[r.UserID] = r.CreatedAt
+		}
+
+		report := AnnouncementReadReport{
+			AnnouncementID: announcement.ID,
+			Title:          announcement.Title,
+			AudienceCount:  len(users),
+			Read:           []AnnouncementReader{},
+			Unread:         []AnnouncementReader{},
+		}
+		for _, u := range users {
+			if at, ok := readAt[u.ID]; ok {
+				u.ReadAt = &at
+				report.Read = append(report.Read, u)
+			} else {
+				report.Unread = append(report.Unread, u)
+			}
+		}
+		report.ReadCount = len(report.Read)
+
+		respondOK(c, report)
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnouncementReadReceipts(t *testing.T) {
+	db := setupAnnouncementTestDB(t)
+	admin := createAnnouncementTestUser(t, db, "admin", "admin@example.com", true)
+	alice := createAnnouncementTestUser(t, db, "alice", "alice@example.com", false)
+	bob := createAnnouncementTestUser(t, db, "bob", "bob@example.com", false)
+	dogs := CreateTestGroup(t, db, "Dogs", "")
+	require.NoError(t, db.Create(&models.UserGroup{UserID: alice.ID, GroupID: dogs.ID}).Error)
+
+	siteWide := createTestAnnouncement(t, db, admin.ID, "Vet appointment reminder", "Bring records")
+	dogsOnly := createTestAnnouncement(t, db, admin.ID, "Dog walk rota", "New rota")
+	require.NoError(t, db.Model(dogsOnly).Association("Groups").Append(dogs))
+	future := time.Now().Add(time.Hour)
+	createScheduledAnnouncement(t, db, models.Announcement{UserID: admin.ID, Title: "Scheduled", PublishAt: &future})
+
+	list := func(userID uint) (map[string]bool, string) {
+		c, w := setupAnnouncementTestContext(userID, false)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/announcements", nil)
+		GetAnnouncements(db)(c)
+		require.Equal(t, http.StatusOK, w.Code)
+		var announcements []models.Announcement
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &announcements))
+		read := make(map[string]bool)
+		for _, a := range announcements {
+			read[a.Title] = a.IsRead
+		}
+		return read, w.Header().Get("X-Unread-Count")
+	}
+	markRead := func(userID, announcementID uint) int {
+		c, w := setupAnnouncementTestContext(userID, false)
+		c.Params = gin.Params{{Key: "id", Value: itoa(announcementID)}}
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/announcements/"+itoa(announcementID)+"/read", nil)
+		MarkAnnouncementRead(db)(c)
+		return w.Code
+	}
+
+	read, unread := list(alice.ID)
+	assert.Equal(t, map[string]bool{"Vet appointment reminder": false, "Dog walk rota": false}, read)
+	assert.Equal(t, "2", unread)
+
+	assert.Equal(t, http.StatusOK, markRead(alice.ID, siteWide.ID))
+	assert.Equal(t, http.StatusOK, markRead(alice.ID, siteWide.ID), "marking twice is a no-op")
+	assert.Equal(t, http.StatusNotFound, markRead(bob.ID, dogsOnly.ID), "not in the audience")
+	assert.Equal(t, http.StatusNotFound, markRead(bob.ID, 999))
+
+	read, unread = list(alice.ID)
+	assert.True(t, read["Vet appointment reminder"])
+	assert.False(t, read["Dog walk rota"])
+	assert.Equal(t, "1", unread)
+	var count int64
+	require.NoError(t, db.Model(&models.AnnouncementRead{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	reads := func(announcementID uint) AnnouncementReadReport {
+		c, w := setupAnnouncementTestContext(admin.ID, true)
+		c.Params = gin.Params{{Key: "id", Value: itoa(announcementID)}}
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/admin/announcements/"+itoa(announcementID)+"/reads", nil)
+		GetAnnouncementReads(db)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var report AnnouncementReadReport
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		return report
+	}
+
+	report := reads(siteWide.ID)
+	assert.Equal(t, 3, report.AudienceCount, "site-wide announcements are for everyone")
+	assert.Equal(t, 1, report.ReadCount)
+	require.Len(t, report.Read, 1)
+	assert.Equal(t, "alice", report.Read[0].Username)
+	assert.NotNil(t, report.Read[0].ReadAt)
+	assert.Len(t, report.Unread, 2)
+
+	report = reads(dogsOnly.ID)
+	assert.Equal(t, 1, report.AudienceCount, "group announcements are for members")
+	assert.Empty(t, report.Read)
+	require.Len(t, report.Unread, 1)
+	assert.Equal(t, "alice", report.Unread[0].Username)
+}
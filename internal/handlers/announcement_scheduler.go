@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/groupme"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// announcementSchedulerStopTimeout bounds how long stop() waits for an
+// in-flight tick to finish, matching the embedding sweep's shutdown wait.
+const announcementSchedulerStopTimeout = 10 * time.Second
+
+// StartAnnouncementScheduler periodically sends the GroupMe notifications,
+// and queues the emails, for scheduled announcements whose publish_at has
+// passed.
+// Announcements that expire before they are sent are skipped. Returns a stop
+// function; call it during graceful shutdown, before closing the database.
+func StartAnnouncementScheduler(db *gorm.DB, emailService *email.Service, groupMeService *groupme.Service, interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		for {
+			select {
+			case <-ticker.C:
+				publishDueAnnouncements(context.Background(), db, emailService, groupMeService, time.Now())
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		select {
+		case <-finished:
+		case <-time.After(announcementSchedulerStopTimeout):
+			logging.Warn(fmt.Sprintf("Announcement scheduler did not stop within %s of shutdown signal; proceeding with shutdown anyway", announcementSchedulerStopTimeout))
+		}
+	}
+}
+
+// publishDueAnnouncements sends notifications for every due, unsent
+// announcement and returns how many it sent. Each row is claimed by setting
+// notified_at before sending, so when several replicas run the scheduler an
+// announcement is only sent once.
+func publishDueAnnouncements(ctx context.Context, db *gorm.DB, emailService *email.Service, groupMeService *groupme.Service, now time.Time) int {
+	logger := logging.WithContext(ctx)
+
+	var due []models.Announcement
+	if err := db.WithContext(ctx).Preload("Groups").
+		Where("publish_at IS NOT NULL AND publish_at <= ? AND notified_at IS NULL", now).
+		Where("send_email = ? OR send_group_me = ?", true, true).
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		Order("publish_at ASC").
+		Find(&due).Error; err != nil {
+		logger.Error("Failed to fetch scheduled announcements", err)
+		return 0
+	}
+
+	sent := 0
+	for _, announcement := range due {
+		result := db.WithContext(ctx).Model(&models.Announcement{}).
+			Where("id = ? AND notified_at IS NULL", announcement.ID).
+			Update("notified_at", now)
+		if result.Error != nil {
+			logger.WithField("announcement_id", announcement.ID).Error("Failed to claim scheduled announcement", result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue // Another replica claimed it
+		}
+		sendScheduledAnnouncement(ctx, db, emailService, groupMeService, announcement)
+		sent++
+	}
+	return sent
+}
+
+// sendScheduledAnnouncement sends a claimed announcement to the members of
+// its groups, or site-wide when it isn't for any groups.
+func sendScheduledAnnouncement(ctx context.Context, db *gorm.DB, emailService *email.Service, groupMeService *groupme.Service, a models.Announcement) {
+	logger := logging.WithContext(ctx).WithField("announcement_id", a.ID)
+	groupIDs := announcementGroupIDs(a)
+
+	if a.SendEmail && emailService != nil && emailService.IsConfigured() {
+		if _, err := enqueueTrackedAnnouncementEmails(ctx, db, groupIDs, a); err != nil {
+			logger.Error("Error queueing scheduled announcement emails", err)
+		}
+	}
+	if a.SendGroupMe && groupMeService != nil {
+		if err := sendAnnouncementToGroupMe(ctx, db, groupMeService, groupIDs, a.Title, a.Content); err != nil {
+			logger.Error("Error sending scheduled announcement to GroupMe", err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// recordingEmailProvider records the recipients of every email sent.
+type recordingEmailProvider struct {
+	sentTo []string
+}
+
+func (p *recordingEmailProvider) SendEmail(_ context.Context, to, _, _ string) error {
+	p.sentTo = append(p.sentTo, to)
+	return nil
+}
+func (p *recordingEmailProvider) IsConfigured() bool      { return true }
+func (p *recordingEmailProvider) GetProviderName() string { return "recording" }
+
+func createScheduledAnnouncement(t *testing.T, db *gorm.DB, a models.Announcement) models.Announcement {
+	t.Helper()
+	if a.Content == "" {
+		a.Content = "Scheduled announcement content"
+	}
+	require.NoError(t, db.Create(&a).Error)
+	return a
+}
+
+func TestGetAnnouncements_HidesUnpublishedAndExpired(t *testing.T) {
+	db := setupAnnouncementTestDB(t)
+	admin := createAnnouncementTestUser(t, db, "admin", "admin@example.com", true)
+	now := time.Now()
+	future, past := now.Add(time.Hour), now.Add(-time.Hour)
+
+	createScheduledAnnouncement(t, db, models.Announcement{UserID: admin.ID, Title: "live"})
+	createScheduledAnnouncement(t, db, models.Announcement{UserID: admin.ID, Title: "published", PublishAt: &past, ExpiresAt: &future})
+	createScheduledAnnouncement(t, db, models.Announcement{UserID: admin.ID, Title: "scheduled", PublishAt: &future})
+	createScheduledAnnouncement(t, db, models.Announcement{UserID: admin.ID, Title: "expired", ExpiresAt: &past})
+
+	titles := func(isAdmin bool) []string {
+		c, w := setupAnnouncementTestContext(admin.ID, isAdmin)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/announcements", nil)
+		GetAnnouncements(db)(c)
+		require.Equal(t, http.StatusOK, w.Code)
+		var announcements []models.Announcement
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &announcements))
+		var out []string
+		for _, a := range announcements {
+			out = append(out, a.Title)
+		}
+		return out
+	}
+
+	assert.ElementsMatch(t, []string{"live", "published"}, titles(false))
+	assert.ElementsMatch(t, []string{"live", "published", "scheduled", "expired"}, titles(true))
+}
+
+func TestCreateAnnouncement_Scheduling(t *testing.T) {
+	db := setupAnnouncementTestDB(t)
+	admin := createAnnouncementTestUser(t, db, "admin", "admin@example.com", true)
+	now := time.Now()
+
+	create := func(body map[string]interface{}) (*httptest.ResponseRecorder, models.Announcement) {
+		c, w := setupAnnouncementTestContext(admin.ID, true)
+		payload, _ := json.Marshal(body)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/admin/announcements", bytes.NewReader(payload))
+		c.Request.Header.Set("Content-Type", "application/json")
+		CreateAnnouncement(db, nil, nil)(c)
+		var a models.Announcement
+		_ = json.Unmarshal(w.Body.Bytes(), &a)
+		return w, a
+	}
+
+	w, a := create(map[string]interface{}{
+		"title": "Future", "content": "Announcement for later", "send_email": true,
+		"publish_at": now.Add(time.Hour), "expires_at": now.Add(48 * time.Hour),
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.NotNil(t, a.PublishAt)
+	assert.Nil(t, a.NotifiedAt, "scheduled announcements are left for the scheduler")
+
+	w, a = create(map[string]interface{}{
+		"title": "Backdated", "content": "Publish time in the past", "send_email": true,
+		"publish_at": now.Add(-time.Hour),
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Nil(t, a.PublishAt, "a past publish time publishes immediately")
+	assert.NotNil(t, a.NotifiedAt)
+
+	w, _ = create(map[string]interface{}{
+		"title": "Bad window", "content": "Expires before it publishes",
+		"publish_at": now.Add(2 * time.Hour), "expires_at": now.Add(time.Hour),
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPublishDueAnnouncements(t *testing.T) {
+	db := setupAnnouncementTestDB(t)
+	admin := createAnnouncementTestUser(t, db, "admin", "admin@example.com", true)
+	group := CreateTestGroup(t, db, "Dogs", "Dog group")
+	member := createAnnouncementTestUser(t, db, "member", "member@example.com", false)
+	outsider := createAnnouncementTestUser(t, db, "outsider", "outsider@example.com", false)
+	AddUserToGroupWithAdmin(t, db, member.ID, group.ID, false)
+	db.Model(&models.User{}).Where("id IN ?", []uint{member.ID, outsider.ID}).Update("email_notifications_enabled", true)
+
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+	due := createScheduledAnnouncement(t, db, models.Announcement{UserID: admin.ID, Title: "Group news", GroupID: &group.ID, SendEmail: true, PublishAt: &past})
+	createScheduledAnnouncement(t, db, models.Announcement{UserID: admin.ID, Title: "Not yet", SendEmail: true, PublishAt: &future})
+	createScheduledAnnouncement(t, db, models.Announcement{UserID: admin.ID, Title: "Expired", SendEmail: true, PublishAt: &past, ExpiresAt: &past})
+	createScheduledAnnouncement(t, db, models.Announcement{UserID: admin.ID, Title: "No notifications", PublishAt: &past})
+
+	provider := &recordingEmailProvider{}
+	emailService := email.NewServiceWithProvider(provider, db)
+	queue := jobs.NewQueue(db)
+	RegisterJobHandlers(queue, db, emailService, nil)
+
+	assert.Equal(t, 1, publishDueAnnouncements(context.Background(), db, emailService, nil, now))
+	assert.Empty(t, provider.sentTo, "emails are queued, not sent inline")
+	assert.Equal(t, 1, queue.RunDue(context.Background()))
+	assert.Equal(t, []string{"member@example.com"}, provider.sentTo, "group announcements only go to group members")
+
+	var reloaded models.Announcement
+	require.NoError(t, db.First(&reloaded, due.ID).Error)
+	assert.NotNil(t, reloaded.NotifiedAt)
+
+	assert.Zero(t, publishDueAnnouncements(context.Background(), db, emailService, nil, now), "announcements are only sent once")
+	assert.Zero(t, queue.RunDue(context.Background()))
+	assert.Len(t, provider.sentTo, 1)
+}
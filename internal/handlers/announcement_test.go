@@ -8,11 +8,15 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/groupme"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 )
 
@@ -327,18 +331,17 @@ func TestDeleteAnnouncement(t *testing.T) {
 	}
 }
 
-// TestSendAnnouncementEmails tests the sendAnnouncementEmails function directly
-func TestSendAnnouncementEmails(t *testing.T) {
+// TestEnqueueAnnouncementEmails tests the enqueueAnnouncementEmails function directly
+func TestEnqueueAnnouncementEmails(t *testing.T) {
 	tests := []struct {
 		name          string
 		setupFunc     func(*gorm.DB)
-		emailService  func(*gorm.DB) *email.Service
 		title         string
 		content       string
-		expectedError bool
+		expectedCount int
 	}{
 		{
-			name: "successfully send emails to opted-in users",
+			name: "queues emails to opted-in users",
 			setupFunc: func(db *gorm.DB) {
 				// Create users with email notifications enabled
 				user1 := createAnnouncementTestUser(t, db, "user1", "user1@example.com", false)
@@ -347,10 +350,9 @@ func TestSendAnnouncementEmails(t *testing.T) {
 				user2 := createAnnouncementTestUser(t, db, "user2", "user2@example.com", false)
 				db.Model(&models.User{}).Where("id = ?", user2.ID).Update("email_notifications_enabled", true)
 			},
-			emailService:  func(db *gorm.DB) *email.Service { return createTestEmailService(true, db) },
 			title:         "Test Announcement",
 			content:       "This is a test announcement content.",
-			expectedError: false,
+			expectedCount: 2,
 		},
 		{
 			name: "no users with email notifications enabled",
@@ -359,20 +361,18 @@ func TestSendAnnouncementEmails(t *testing.T) {
 				createAnnouncementTestUser(t, db, "user3", "user3@example.com", false)
 				createAnnouncementTestUser(t, db, "user4", "user4@example.com", false)
 			},
-			emailService:  func(db *gorm.DB) *email.Service { return createTestEmailService(true, db) },
 			title:         "Test Announcement",
 			content:       "This is a test announcement content.",
-			expectedError: false,
+			expectedCount: 0,
 		},
 		{
 			name: "empty database",
 			setupFunc: func(db *gorm.DB) {
 				// No users
 			},
-			emailService:  func(db *gorm.DB) *email.Service { return createTestEmailService(true, db) },
 			title:         "Test Announcement",
 			content:       "This is a test announcement content.",
-			expectedError: false,
+			expectedCount: 0,
 		},
 	}
 
@@ -384,15 +384,19 @@ func TestSendAnnouncementEmails(t *testing.T) {
 				tt.setupFunc(db)
 			}
 
-			emailService := tt.emailService(db)
 			ctx := context.Background()
-			err := sendAnnouncementEmails(ctx, db, emailService, tt.title, tt.content)
-
-			if tt.expectedError && err == nil {
-				t.Error("Expected error but got nil")
+			count, err := enqueueAnnouncementEmails(ctx, db, nil, tt.title, tt.content)
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
 			}
-			if !tt.expectedError && err != nil {
-				t.Errorf("Expected no error but got: %v", err)
+			if count != tt.expectedCount {
+				t.Errorf("Expected %d emails queued, got %d", tt.expectedCount, count)
+			}
+
+			var queued int64
+			db.Model(&models.Job{}).Where("type = ? AND status = ?", JobAnnouncementEmail, models.JobStatusPending).Count(&queued)
+			if int(queued) != tt.expectedCount {
+				t.Errorf("Expected %d pending jobs, got %d", tt.expectedCount, queued)
 			}
 		})
 	}
@@ -580,7 +584,7 @@ func TestSendAnnouncementToGroupMe(t *testing.T) {
 
 			groupMeService := groupme.NewService()
 			ctx := context.Background()
-			err := sendAnnouncementToGroupMe(ctx, db, groupMeService, tt.title, tt.content)
+			err := sendAnnouncementToGroupMe(ctx, db, groupMeService, nil, tt.title, tt.content)
 
 			if tt.expectedError && err == nil {
 				t.Error("Expected error but got nil")
@@ -705,3 +709,84 @@ func TestCreateAnnouncementWithGroupMe(t *testing.T) {
 		})
 	}
 }
+
+// TestAnnouncementGroupTargeting tests group_ids targeting, group admin
+// permissions, and visibility filtering
+func TestAnnouncementGroupTargeting(t *testing.T) {
+	db := setupAnnouncementTestDB(t)
+	admin := createAnnouncementTestUser(t, db, "admin", "admin@example.com", true)
+	dogs := CreateTestGroup(t, db, "Dogs", "Dog group")
+	cats := CreateTestGroup(t, db, "Cats", "Cat group")
+	dogAdmin := createAnnouncementTestUser(t, db, "dogadmin", "dogadmin@example.com", false)
+	catMember := createAnnouncementTestUser(t, db, "catmember", "catmember@example.com", false)
+	outsider := createAnnouncementTestUser(t, db, "outsider", "outsider@example.com", false)
+	AddUserToGroupWithAdmin(t, db, dogAdmin.ID, dogs.ID, true)
+	AddUserToGroupWithAdmin(t, db, dogAdmin.ID, cats.ID, false)
+	AddUserToGroupWithAdmin(t, db, catMember.ID, cats.ID, false)
+	db.Model(&models.User{}).Where("id IN ?", []uint{dogAdmin.ID, catMember.ID, outsider.ID}).Update("email_notifications_enabled", true)
+
+	provider := &recordingEmailProvider{}
+	emailService := email.NewServiceWithProvider(provider, db)
+	create := func(userID uint, isAdmin bool, body map[string]interface{}) (int, models.Announcement) {
+		c, w := setupAnnouncementTestContext(userID, isAdmin)
+		payload, _ := json.Marshal(body)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/announcements", bytes.NewReader(payload))
+		c.Request.Header.Set("Content-Type", "application/json")
+		CreateAnnouncement(db, emailService, nil)(c)
+		var a models.Announcement
+		_ = json.Unmarshal(w.Body.Bytes(), &a)
+		return w.Code, a
+	}
+	body := func(title string, groupIDs ...uint) map[string]interface{} {
+		return map[string]interface{}{"title": title, "content": "Announcement content for " + title, "group_ids": groupIDs}
+	}
+
+	code, _ := create(dogAdmin.ID, false, body("Site-wide"))
+	assert.Equal(t, http.StatusForbidden, code, "group admins can't post site-wide")
+	code, _ = create(dogAdmin.ID, false, body("Both", dogs.ID, cats.ID))
+	assert.Equal(t, http.StatusForbidden, code, "group admins can only target groups they administer")
+	code, _ = create(admin.ID, true, body("Missing", 9999))
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, a := create(dogAdmin.ID, false, body("Dogs only", dogs.ID, dogs.ID))
+	require.Equal(t, http.StatusCreated, code)
+	require.Len(t, a.Groups, 1)
+	assert.Equal(t, "Dogs", a.Groups[0].Name)
+	code, _ = create(admin.ID, true, body("Everyone"))
+	require.Equal(t, http.StatusCreated, code)
+	createTestAnnouncement(t, db, admin.ID, "Legacy cats", "Posted from the group page")
+	db.Model(&models.Announcement{}).Where("title = ?", "Legacy cats").Update("group_id", cats.ID)
+
+	// A scheduled announcement for both groups emails each member once
+	publishAt := time.Now().Add(time.Hour)
+	code, _ = create(admin.ID, true, map[string]interface{}{
+		"title": "Both groups", "content": "Announcement for both groups", "group_ids": []uint{dogs.ID, cats.ID},
+		"send_email": true, "publish_at": publishAt,
+	})
+	require.Equal(t, http.StatusCreated, code)
+	queue := jobs.NewQueue(db)
+	RegisterJobHandlers(queue, db, emailService, nil)
+	assert.Equal(t, 1, publishDueAnnouncements(context.Background(), db, emailService, nil, publishAt.Add(time.Minute)))
+	assert.Equal(t, 2, queue.RunDue(context.Background()))
+	assert.ElementsMatch(t, []string{"dogadmin@example.com", "catmember@example.com"}, provider.sentTo)
+
+	visible := func(userID uint, isAdmin bool) []string {
+		c, w := setupAnnouncementTestContext(userID, isAdmin)
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/announcements", nil)
+		GetAnnouncements(db)(c)
+		require.Equal(t, http.StatusOK, w.Code)
+		var announcements []models.Announcement
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &announcements))
+		var titles []string
+		for _, a := range announcements {
+			if a.PublishAt == nil {
+				titles = append(titles, a.Title)
+			}
+		}
+		return titles
+	}
+	assert.ElementsMatch(t, []string{"Dogs only", "Everyone", "Legacy cats"}, visible(dogAdmin.ID, false))
+	assert.ElementsMatch(t, []string{"Everyone", "Legacy cats"}, visible(catMember.ID, false))
+	assert.ElementsMatch(t, []string{"Everyone"}, visible(outsider.ID, false))
+	assert.ElementsMatch(t, []string{"Dogs only", "Everyone", "Legacy cats"}, visible(admin.ID, true))
+}
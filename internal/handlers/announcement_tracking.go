@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/auth"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// emailTrackingSetting is the site setting that turns on open and click
+// tracking of announcement emails. It is off unless set to true.
+const emailTrackingSetting = "email_tracking"
+
+// emailTrackingPurpose and emailLinkPurpose scope tracking token and link
+// signatures so they can't be confused with any other value signed with the
+// server key.
+const (
+	emailTrackingPurpose = "announcement_email_tracking"
+	emailLinkPurpose     = "announcement_email_link"
+)
+
+// trackingPixel is a transparent 1x1 GIF
+var trackingPixel = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+// AnnouncementLinkStats counts the clicks on one link of an announcement
+// email
+type AnnouncementLinkStats struct {
+	URL        string `json:"url"`
+	Clicks     int64  `json:"clicks"`
+	Recipients int64  `json:"recipients"` // Recipients who clicked it at least once
+}
+
+// AnnouncementEmailStats summarizes how an announcement's emails were
+// received. Recipients who clicked a link count as having opened the email,
+// since their email client may have blocked the tracking pixel.
+type AnnouncementEmailStats struct {
+	AnnouncementID  uint                    `json:"announcement_id"`
+	Title           string                  `json:"title"`
+	TrackingEnabled bool                    `json:"tracking_enabled"`
+	Delivered       int64                   `json:"delivered"`
+	Opened          int64                   `json:"opened"`
+	Clicked         int64                   `json:"clicked"`
+	TotalOpens      int64                   `json:"total_opens"`
+	TotalClicks     int64                   `json:"total_clicks"`
+	OpenRate        float64                 `json:"open_rate"`  // Opened / Delivered; 0 when nothing was delivered
+	ClickRate       float64                 `json:"click_rate"` // Clicked / Delivered; 0 when nothing was delivered
+	Links           []AnnouncementLinkStats `json:"links"`
+}
+
+// validateEmailTrackingSetting checks an email_tracking value
+func validateEmailTrackingSetting(value string) error {
+	if _, err := strconv.ParseBool(strings.TrimSpace(value)); err != nil {
+		return fmt.Errorf("%s must be true or false", emailTrackingSetting)
+	}
+	return nil
+}
+
+// emailTrackingEnabled reports whether the email_tracking setting is on
+func emailTrackingEnabled(db *gorm.DB) bool {
+	var values []string
+	if err := db.Model(&models.SiteSetting{}).Where("key = ?", emailTrackingSetting).Pluck("value", &values).Error; err != nil || len(values) == 0 {
+		return false
+	}
+	enabled, _ := strconv.ParseBool(strings.TrimSpace(values[0]))
+	return enabled
+}
+
+// emailTrackingToken returns the token naming one recipient's copy of an
+// announcement email: the announcement and user IDs and a signature of them.
+func emailTrackingToken(announcementID, userID uint) (string, error) {
+	value := fmt.Sprintf("%d.%d", announcementID, userID)
+	sig, err := auth.Sign(emailTrackingPurpose, value)
+	if err != nil {
+		return "", err
+	}
+	return value + "." + sig, nil
+}
+
+// parseEmailTrackingToken verifies a token's signature and returns the
+// announcement and user IDs it names
+func parseEmailTrackingToken(token string) (announcementID, userID uint, ok bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, 0, false
+	}
+	aid, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return 0, 0, false
+	}
+	uid, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil || !auth.VerifySignature(emailTrackingPurpose, parts[0]+"."+parts[1], parts[2]) {
+		return 0, 0, false
+	}
+	return uint(aid), uint(uid), true
+}
+
+// announcementEmailTracking returns the tracking pixel and link wrapping for
+// one recipient's copy of an announcement email. Links are signed, so the
+// click endpoint only redirects to links that were in an email.
+func announcementEmailTracking(announcementID, userID uint) (email.EmailTracking, error) {
+	token, err := emailTrackingToken(announcementID, userID)
+	if err != nil {
+		return email.EmailTracking{}, err
+	}
+	base := frontendURL() + "/api/email-tracking/" + token
+	return email.EmailTracking{
+		PixelURL: base + "/open.gif",
+		TrackLink: func(link string) string {
+			sig, err := auth.Sign(emailLinkPurpose, token+"\x00"+link)
+			if err != nil {
+				return link
+			}
+			return base + "/click?" + url.Values{"url": {link}, "sig": {sig}}.Encode()
+		},
+	}, nil
+}
+
+// recordAnnouncementEmailEvent stores an event for one recipient of an
+// announcement email
+func recordAnnouncementEmailEvent(db *gorm.DB, announcementID, userID uint, eventType, link string) error {
+	return db.Create(&models.AnnouncementEmailEvent{
+		AnnouncementID: announcementID,
+		UserID:         userID,
+		Type:           eventType,
+		URL:            link,
+	}).Error
+}
+
+// TrackAnnouncementEmailOpen serves an announcement email's tracking pixel
+// and records the open while email tracking is on. The pixel is served
+// whatever the token, so a bad one doesn't show a broken image.
+// Route: GET /api/email-tracking/:token/open.gif
+func TrackAnnouncementEmailOpen(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		if announcementID, userID, ok := parseEmailTrackingToken(c.Param("token")); ok && emailTrackingEnabled(db) {
+			if err := recordAnnouncementEmailEvent(db, announcementID, userID, models.EmailEventOpened, ""); err != nil {
+				middleware.GetLogger(c).Error("Failed to record email open", err)
+			}
+		}
+		c.Header("Cache-Control", "no-store, max-age=0")
+		c.Data(http.StatusOK, "image/gif", trackingPixel)
+	}
+}
+
+// TrackAnnouncementEmailClick records a click on a link in an announcement
+// email while email tracking is on, and redirects to the link. Only links
+// signed for the token are followed.
+// Route: GET /api/email-tracking/:token/click?url=...&sig=...
+func TrackAnnouncementEmailClick(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		token := c.Param("token")
+		link := c.Query("url")
+		announcementID, userID, ok := parseEmailTrackingToken(token)
+		if !ok || link == "" || !auth.VerifySignature(emailLinkPurpose, token+"\x00"+link, c.Query("sig")) {
+			respondBadRequest(c, "Invalid link")
+			return
+		}
+
+		if emailTrackingEnabled(db) {
+			if err := recordAnnouncementEmailEvent(db, announcementID, userID, models.EmailEventClicked, link); err != nil {
+				middleware.GetLogger(c).Error("Failed to record email click", err)
+			}
+		}
+		c.Header("Cache-Control", "no-store, max-age=0")
+		c.Redirect(http.StatusFound, link)
+	}
+}
+
+// GetAnnouncementEmailStats reports how many recipients an announcement's
+// emails were delivered to, opened by, and clicked through by, and the
+// clicks on each link (admin only)
+// Route: GET /api/admin/announcements/:id/stats
+func GetAnnouncementEmailStats(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		announcementID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid announcement ID")
+			return
+		}
+		var announcement models.Announcement
+		if err := db.First(&announcement, announcementID).Error; err != nil {
+			respondNotFound(c, "Announcement not found")
+			return
+		}
+
+		stats := AnnouncementEmailStats{
+			AnnouncementID:  announcement.ID,
+			Title:           announcement.Title,
+			TrackingEnabled: emailTrackingEnabled(db),
+			Links:           []AnnouncementLinkStats{},
+		}
+		events := db.Model(&models.AnnouncementEmailEvent{}).Where("announcement_id = ?", announcement.ID)
+		var counts []struct {
+			Type       string
+			Total      int64
+			Recipients int64
+		}
+		if err := events.Session(&gorm.Session{}).
+			Select("type, COUNT(*) AS total, COUNT(DISTINCT user_id) AS recipients").
+			Group("type").Scan(&counts).Error; err != nil {
+			respondInternalError(c, "Failed to fetch email stats")
+			return
+		}
+		for _, count := range counts {
+			switch count.Type {
+			case models.EmailEventDelivered:
+				stats.Delivered = count.Recipients
+			case models.EmailEventOpened:
+				stats.TotalOpens = count.Total
+			case models.EmailEventClicked:
+				stats.TotalClicks = count.Total
+				stats.Clicked = count.Recipients
+			}
+		}
+		if err := events.Session(&gorm.Session{}).
+			Where("type IN ?", []string{models.EmailEventOpened, models.EmailEventClicked}).
+			Distinct("user_id").Count(&stats.Opened).Error; err != nil {
+			respondInternalError(c, "Failed to fetch email stats")
+			return
+		}
+		if err := events.Session(&gorm.Session{}).
+			Select("url, COUNT(*) AS clicks, COUNT(DISTINCT user_id) AS recipients").
+			Where("type = ?", models.EmailEventClicked).
+			Group("url").Order("clicks DESC, url").Scan(&stats.Links).Error; err != nil {
+			respondInternalError(c, "Failed to fetch email stats")
+			return
+		}
+		if stats.Delivered > 0 {
+			stats.OpenRate = float64(stats.Opened) / float64(stats.Delivered)
+			stats.ClickRate = float64(stats.Clicked) / float64(stats.Delivered)
+		}
+
+		respondOK(c, stats)
+	}
+}
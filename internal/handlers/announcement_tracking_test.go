@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bodyRecordingEmailProvider struct {
+	bodies map[string]string
+}
+
+func (p *bodyRecordingEmailProvider) SendEmail(_ context.Context, to, _, body string) error {
+	p.bodies[to] = body
+	return nil
+}
+func (p *bodyRecordingEmailProvider) IsConfigured() bool      { return true }
+func (p *bodyRecordingEmailProvider) GetProviderName() string { return "recording" }
+
+var (
+	trackingPixelRegex = regexp.MustCompile(`<img src="([^"]+/open\.gif)"`)
+	trackedLinkRegex   = regexp.MustCompile(`<a href="([^"]+)">`)
+)
+
+func TestAnnouncementEmailTracking(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	alice := CreateTestUser(t, db, "alice", "alice@example.com", "password123", false)
+	bob := CreateTestUser(t, db, "bob", "bob@example.com", "password123", false)
+	require.NoError(t, db.Model(&models.User{}).Where("id IN ?", []uint{alice.ID, bob.ID}).Update("email_notifications_enabled", true).Error)
+	require.NoError(t, db.Create(&models.SiteSetting{Key: emailTrackingSetting, Value: "true"}).Error)
+	announcement := createTestAnnouncement(t, db, admin.ID, "Adoption event", "Details at https://example.org/event.")
+
+	provider := &bodyRecordingEmailProvider{bodies: map[string]string{}}
+	queue := jobs.NewQueue(db)
+	RegisterJobHandlers(queue, db, email.NewServiceWithProvider(provider, db), nil)
+	_, err := enqueueTrackedAnnouncementEmails(context.Background(), db, nil, *announcement)
+	require.NoError(t, err)
+	require.Equal(t, 2, queue.RunDue(context.Background()))
+
+	token := func(u string) string {
+		parsed, err := url.Parse(strings.ReplaceAll(u, "&amp;", "&"))
+		require.NoError(t, err)
+		return strings.Split(strings.TrimPrefix(parsed.Path, "/api/email-tracking/"), "/")[0]
+	}
+	body := provider.bodies["alice@example.com"]
+	pixel := trackingPixelRegex.FindStringSubmatch(body)
+	require.NotNil(t, pixel, body)
+	link := trackedLinkRegex.FindStringSubmatch(body)
+	require.NotNil(t, link, body)
+	clickURL, err := url.Parse(strings.ReplaceAll(link[1], "&amp;", "&"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.org/event", clickURL.Query().Get("url"))
+
+	open := func(tok string) {
+		c, w := accountTestContext(0, false, http.MethodGet, "/", nil)
+		c.Params = gin.Params{{Key: "token", Value: tok}}
+		TrackAnnouncementEmailOpen(db)(c)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/gif", w.Header().Get("Content-Type"))
+	}
+	click := func(tok, query string) *http.Response {
+		c, w := accountTestContext(0, false, http.MethodGet, "/?"+query, nil)
+		c.Params = gin.Params{{Key: "token", Value: tok}}
+		TrackAnnouncementEmailClick(db)(c)
+		return w.Result()
+	}
+	open(token(pixel[1]))
+	open(token(pixel[1]))
+	open("1.2.forged")
+	resp := click(token(link[1]), clickURL.RawQuery)
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+	assert.Equal(t, "https://example.org/event", resp.Header.Get("Location"))
+
+	// Links not in the email aren't followed
+	tampered := clickURL.Query()
+	tampered.Set("url", "https://evil.example.com")
+	assert.Equal(t, http.StatusBadRequest, click(token(link[1]), tampered.Encode()).StatusCode)
+	bobToken := token(trackingPixelRegex.FindStringSubmatch(provider.bodies["bob@example.com"])[1])
+	assert.Equal(t, http.StatusBadRequest, click(bobToken, clickURL.RawQuery).StatusCode)
+
+	c, w := accountTestContext(admin.ID, true, http.MethodGet, "/", nil)
+	c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(announcement.ID)}}
+	GetAnnouncementEmailStats(db)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var stats AnnouncementEmailStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.True(t, stats.TrackingEnabled)
+	assert.Equal(t, int64(2), stats.Delivered)
+	assert.Equal(t, int64(1), stats.Opened)
+	assert.Equal(t, int64(1), stats.Clicked)
+	assert.Equal(t, int64(2), stats.TotalOpens)
+	assert.Equal(t, 0.5, stats.OpenRate)
+	assert.Equal(t, []AnnouncementLinkStats{{URL: "https://example.org/event", Clicks: 1, Recipients: 1}}, stats.Links)
+
+	// With tracking off, opens aren't recorded and new emails aren't tracked
+	require.NoError(t, db.Model(&models.SiteSetting{}).Where("key = ?", emailTrackingSetting).Update("value", "false").Error)
+	open(bobToken)
+	var opens int64
+	require.NoError(t, db.Model(&models.AnnouncementEmailEvent{}).Where("type = ?", models.EmailEventOpened).Count(&opens).Error)
+	assert.Equal(t, int64(2), opens)
+	_, err = enqueueTrackedAnnouncementEmails(context.Background(), db, nil, *announcement)
+	require.NoError(t, err)
+	require.Equal(t, 2, queue.RunDue(context.Background()))
+	assert.NotContains(t, provider.bodies["alice@example.com"], "email-tracking")
+	assert.Contains(t, provider.bodies["alice@example.com"], "https://example.org/event")
+}
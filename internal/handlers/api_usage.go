@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultTopAPIConsumers and maxTopAPIConsumers bound ?limit= on the
+	// top consumers report
+	defaultTopAPIConsumers = 10
+	maxTopAPIConsumers     = 100
+	// maxAPIRestrictionMinutes caps how long a restriction may be set for;
+	// leave duration_minutes out for one that lasts until it's lifted
+	maxAPIRestrictionMinutes = 90 * 24 * 60
+)
+
+// UserAPIUsageResponse is one user's API usage on this replica and their
+// current restriction, if any
+type UserAPIUsageResponse struct {
+	UserID      uint                         `json:"user_id"`
+	Username    string                       `json:"username"`
+	Email       string                       `json:"email"`
+	Since       time.Time                    `json:"since"` // When this replica started counting
+	Usage       *middleware.APIUsage         `json:"usage"` // nil if the user hasn't made a request recently
+	Restriction *models.APIAccessRestriction `json:"restriction"`
+}
+
+// APIConsumer is a user in the top consumers report
+type APIConsumer struct {
+	middleware.APIUsage
+	Username        string `json:"username"`
+	Email           string `json:"email"`
+	RestrictionMode string `json:"restriction_mode,omitempty"`
+}
+
+// TopAPIConsumersResponse lists the users with the most requests in the
+// last hour
+type TopAPIConsumersResponse struct {
+	Since     time.Time     `json:"since"`
+	Consumers []APIConsumer `json:"consumers"`
+}
+
+// APIRestrictionRequest throttles or suspends a user's API access
+type APIRestrictionRequest struct {
+	Mode              string `json:"mode" binding:"required,oneof=throttle suspend"`
+	RequestsPerMinute int    `json:"requests_per_minute" binding:"min=0,max=10000"`
+	DurationMinutes   int    `json:"duration_minutes" binding:"min=0"` // 0 lasts until lifted
+	Reason            string `json:"reason" binding:"max=500"`
+}
+
+// findAPIUsageUser loads the :userId user, responding with the error and
+// returning ok=false if there isn't one.
+func findAPIUsageUser(c *gin.Context, db *gorm.DB) (models.User, bool) {
+	var user models.User
+	targetID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid user ID")
+		return user, false
+	}
+	if err := db.First(&user, targetID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondNotFound(c, "User not found")
+			return user, false
+		}
+		middleware.GetLogger(c).Error("Failed to fetch user", err)
+		respondInternalError(c, "Failed to fetch user")
+		return user, false
+	}
+	return user, true
+}
+
+// GetUserAPIUsage returns how many API requests a user has made to this
+// replica, minute by minute over the last hour, and which routes they call
+// most (admin only). Counts are in memory, so they cover one replica and
+// reset when it restarts.
+// Route: GET /api/admin/users/:userId/usage
+func GetUserAPIUsage(db *gorm.DB, tracker *middleware.APIUsageTracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		user, ok := findAPIUsageUser(c, db)
+		if !ok {
+			return
+		}
+
+		response := UserAPIUsageResponse{
+			UserID:      user.ID,
+			Username:    user.Username,
+			Email:       user.Email,
+			Since:       tracker.Since(),
+			Restriction: tracker.Restriction(c.Request.Context(), user.ID),
+		}
+		if usage, ok := tracker.Usage(user.ID); ok {
+			response.Usage = &usage
+		}
+		respondOK(c, response)
+	}
+}
+
+// GetTopAPIConsumers lists the users with the most API requests to this
+// replica in the last hour (admin only). Use ?limit= for up to 100 users;
+// the default is 10.
+// Route: GET /api/admin/api-usage/top
+func GetTopAPIConsumers(db *gorm.DB, tracker *middleware.APIUsageTracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		limit := defaultTopAPIConsumers
+		if raw := c.Query("limit"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed < 1 || parsed > maxTopAPIConsumers {
+				respondBadRequest(c, "limit must be between 1 and 100")
+				return
+			}
+			limit = parsed
+		}
+
+		top := tracker.TopConsumers(limit)
+		ids := make([]uint, len(top))
+		for i, usage := range top {
+			ids[i] = usage.UserID
+		}
+		var users []models.User
+		if len(ids) > 0 {
+			if err := db.Unscoped().Select("id, username, email").Where("id IN ?", ids).Find(&users).Error; err != nil {
+				middleware.GetLogger(c).Error("Failed to fetch API consumers", err)
+				respondInternalError(c, "Failed to fetch API consumers")
+				return
+			}
+		}
+		byID := make(map[uint]models.User, len(users))
+		for _, u := range users {
+			byID[u.ID] = u
+		}
+
+		response := TopAPIConsumersResponse{Since: tracker.Since(), Consumers: make([]APIConsumer, len(top))}
+		for i, usage := range top {
+			consumer := APIConsumer{APIUsage: usage, Username: byID[usage.UserID].Username, Email: byID[usage.UserID].Email}
+			if r := tracker.Restriction(c.Request.Context(), usage.UserID); r != nil {
+				consumer.RestrictionMode = r.Mode
+			}
+			response.Consumers[i] = consumer
+		}
+		respondOK(c, response)
+	}
+}
+
+// RestrictUserAPIAccess throttles a user to a number of requests per minute,
+// or suspends their API access entirely, for duration_minutes or until it's
+// lifted (admin only). Replaces any restriction the user already has. Site
+// admins can't be restricted.
+// Route: PUT /api/admin/users/:userId/api-restriction
+func RestrictUserAPIAccess(db *gorm.DB, tracker *middleware.APIUsageTracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		var req APIRestrictionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		if req.Mode == models.APIRestrictionThrottle && req.RequestsPerMinute < 1 {
+			respondBadRequest(c, "requests_per_minute is required to throttle")
+			return
+		}
+		if req.DurationMinutes > maxAPIRestrictionMinutes {
+			respondBadRequest(c, "duration_minutes can be at most 90 days; leave it out to restrict until lifted")
+			return
+		}
+
+		user, ok := findAPIUsageUser(c, db)
+		if !ok {
+			return
+		}
+		if user.IsAdmin {
+			respondBadRequest(c, "Site admins can't be restricted; demote them first")
+			return
+		}
+
+		adminID, _ := middleware.GetUserID(c)
+		restriction := models.APIAccessRestriction{UserID: user.ID}
+		if err := db.Where("user_id = ?", user.ID).First(&restriction).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			middleware.GetLogger(c).Error("Failed to fetch API restriction", err)
+			respondInternalError(c, "Failed to restrict API access")
+			return
+		}
+		restriction.Mode = req.Mode
+		restriction.RequestsPerMinute = 0
+		if req.Mode == models.APIRestrictionThrottle {
+			restriction.RequestsPerMinute = req.RequestsPerMinute
+		}
+		restriction.Reason = req.Reason
+		restriction.ExpiresAt = nil
+		if req.DurationMinutes > 0 {
+			expiresAt := time.Now().Add(time.Duration(req.DurationMinutes) * time.Minute)
+			restriction.ExpiresAt = &expiresAt
+		}
+		restriction.CreatedByID = adminID
+		if err := db.Save(&restriction).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to save API restriction", err)
+			respondInternalError(c, "Failed to restrict API access")
+			return
+		}
+		tracker.InvalidateRestrictions()
+
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventAPIAccessRestricted, adminID, map[string]interface{}{
+			"target_user_id":      user.ID,
+			"mode":                restriction.Mode,
+			"requests_per_minute": restriction.RequestsPerMinute,
+			"expires_at":          restriction.ExpiresAt,
+			"reason":              restriction.Reason,
+		})
+		respondOK(c, restriction)
+	}
+}
+
+// LiftUserAPIRestriction restores a user's normal API access (admin only)
+// Route: DELETE /api/admin/users/:userId/api-restriction
+func LiftUserAPIRestriction(db *gorm.DB, tracker *middleware.APIUsageTracker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		user, ok := findAPIUsageUser(c, db)
+		if !ok {
+			return
+		}
+		result := db.Where("user_id = ?", user.ID).Delete(&models.APIAccessRestriction{})
+		if result.Error != nil {
+			middleware.GetLogger(c).Error("Failed to lift API restriction", result.Error)
+			respondInternalError(c, "Failed to lift API restriction")
+			return
+		}
+		if result.RowsAffected == 0 {
+			respondNotFound(c, "User's API access isn't restricted")
+			return
+		}
+		tracker.InvalidateRestrictions()
+
+		adminID, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventAPIAccessRestored, adminID, map[string]interface{}{
+			"target_user_id": user.ID,
+		})
+		respondNoContent(c)
+	}
+}
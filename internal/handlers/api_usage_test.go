@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserAPIRestrictions(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	scraper := CreateTestUser(t, db, "scraper", "scraper@example.com", "password123", false)
+	tracker := middleware.NewAPIUsageTracker(db)
+
+	// Route the scraper's requests through the tracker
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", scraper.ID) }, tracker.Track())
+	router.GET("/api/animals", func(c *gin.Context) { c.Status(http.StatusOK) })
+	browse := func() int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/animals", nil))
+		return w.Code
+	}
+	for i := 0; i < 5; i++ {
+		require.Equal(t, http.StatusOK, browse())
+	}
+
+	c, w := accountTestContext(admin.ID, true, http.MethodGet, "/", nil)
+	GetTopAPIConsumers(db, tracker)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var top TopAPIConsumersResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &top))
+	require.Len(t, top.Consumers, 1)
+	assert.Equal(t, "scraper", top.Consumers[0].Username)
+	assert.Equal(t, 5, top.Consumers[0].RequestsLastHour)
+
+	restrict := func(targetID uint, body gin.H) int {
+		c, w := accountTestContext(admin.ID, true, http.MethodPut, "/", body)
+		c.Params = gin.Params{{Key: "userId", Value: itoa(targetID)}}
+		RestrictUserAPIAccess(db, tracker)(c)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusBadRequest, restrict(scraper.ID, gin.H{"mode": "throttle"}), "throttling needs a budget")
+	assert.Equal(t, http.StatusBadRequest, restrict(admin.ID, gin.H{"mode": "suspend"}))
+	require.Equal(t, http.StatusOK, restrict(scraper.ID, gin.H{"mode": "suspend", "duration_minutes": 60, "reason": "scraping"}))
+	assert.Equal(t, http.StatusForbidden, browse())
+
+	c, w = accountTestContext(admin.ID, true, http.MethodGet, "/", nil)
+	c.Params = gin.Params{{Key: "userId", Value: itoa(scraper.ID)}}
+	GetUserAPIUsage(db, tracker)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var usage UserAPIUsageResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &usage))
+	require.NotNil(t, usage.Usage)
+	assert.Equal(t, 6, usage.Usage.RequestsLastHour)
+	assert.Equal(t, 1, usage.Usage.RejectedLastHour)
+	require.NotNil(t, usage.Restriction)
+	assert.Equal(t, models.APIRestrictionSuspend, usage.Restriction.Mode)
+	assert.NotNil(t, usage.Restriction.ExpiresAt)
+
+	// Restricting again replaces the suspension with a throttle
+	require.Equal(t, http.StatusOK, restrict(scraper.ID, gin.H{"mode": "throttle", "requests_per_minute": 100}))
+	var count int64
+	db.Model(&models.APIAccessRestriction{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+	assert.Equal(t, http.StatusOK, browse())
+
+	lift := func() int {
+		c, _ := accountTestContext(admin.ID, true, http.MethodDelete, "/", nil)
+		c.Params = gin.Params{{Key: "userId", Value: itoa(scraper.ID)}}
+		LiftUserAPIRestriction(db, tracker)(c)
+		return c.Writer.Status()
+	}
+	assert.Equal(t, http.StatusNoContent, lift())
+	assert.Equal(t, http.StatusNotFound, lift())
+	assert.Nil(t, tracker.Restriction(c.Request.Context(), scraper.ID))
+}
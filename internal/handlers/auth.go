@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/auth"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
@@ -24,16 +27,24 @@ type RegisterRequest struct {
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+	// Session "bearer" asks for the JWT even when logins start cookie
+	// sessions, for API clients
+	Session string `json:"session" binding:"omitempty,oneof=bearer cookie"`
 }
 
+// AuthResponse carries the JWT of a bearer session, or the CSRF token of a
+// cookie session, whose JWT is only in its HttpOnly cookie.
 type AuthResponse struct {
-	Token     string      `json:"token"`
+	Token     string      `json:"token,omitempty"`
+	CSRFToken string      `json:"csrf_token,omitempty"`
 	User      models.User `json:"user"`
 	LastLogin *time.Time  `json:"last_login,omitempty"`
 }
 
-// Register creates a new user account
-func Register(db *gorm.DB) gin.HandlerFunc {
+// Register creates a new user account and emails a verification link to the
+// supplied address. The account is usable immediately; only notification
+// delivery is gated on verification (see emailVerificationRequired).
+func Register(db *gorm.DB, emailService *email.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
 		db := middleware.GetDB(c, db)
@@ -52,6 +63,15 @@ func Register(db *gorm.DB) gin.HandlerFunc {
 			c.JSON(http.StatusConflict, gin.H{"error": "Username or email already exists"})
 			return
 		}
+		// Usernames given up by other users stay reserved for a while
+		if err := validateUsernameNotReleased(ctx, db, req.Username, 0, time.Now()); err != nil {
+			if errors.Is(err, ErrUsernameRecentlyUsed) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate username"})
+			}
+			return
+		}
 
 		// Hash password
 		hashedPassword, err := auth.HashPassword(req.Password)
@@ -78,8 +98,15 @@ func Register(db *gorm.DB) gin.HandlerFunc {
 		// Audit log: user registration
 		logging.LogRegistration(ctx, user.ID, user.Username, user.Email, c.ClientIP())
 
+		if emailService != nil && emailService.IsConfigured() {
+			if err := sendEmailVerification(ctx, db, emailService, &user); err != nil {
+				// Registration still succeeds; the user can request a new link.
+				middleware.GetLogger(c).Error("Failed to send verification email", err)
+			}
+		}
+
 		// Generate token
-		token, err := auth.GenerateToken(user.ID, user.IsAdmin)
+		token, err := generateSessionToken(db, user.ID, user.Username, user.IsAdmin)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 			return
@@ -92,8 +119,12 @@ func Register(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-// Login authenticates a user and returns a token
-func Login(db *gorm.DB) gin.HandlerFunc {
+// Login authenticates a user and starts a session: a cookie session when
+// SESSION_MODE is cookie and the client didn't ask for a bearer token, and
+// otherwise a bearer session. Repeated failures lock the account according
+// to the lockout policy in securityConfig.
+func Login(db *gorm.DB, securityConfig *middleware.SecurityConfigStore) gin.HandlerFunc {
+	sessions := middleware.SessionConfigFromEnv()
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
 		db := middleware.GetDB(c, db)
@@ -150,29 +181,36 @@ func Login(db *gorm.DB) gin.HandlerFunc {
 
 		// Check password
 		if err := auth.CheckPassword(user.Password, req.Password); err != nil {
+			policy := securityConfig.Get(ctx)
+
 			// Increment failed login attempts
 			user.FailedLoginAttempts++
 
-			// Lock account if max failed attempts reached
-			if user.FailedLoginAttempts >= MaxFailedLoginAttempts {
-				lockUntil := time.Now().Add(AccountLockoutDuration)
+			// Lock account if max failed attempts reached. Each lockout since
+			// the last successful login lasts longer (progressive backoff).
+			if user.FailedLoginAttempts >= policy.LockoutMaxAttempts {
+				duration := policy.LockoutDuration(user.LockoutCount)
+				lockUntil := time.Now().Add(duration)
 				user.LockedUntil = &lockUntil
+				user.LockoutCount++
 
 				if err := db.Model(&user).Updates(map[string]interface{}{
 					"failed_login_attempts": user.FailedLoginAttempts,
 					"locked_until":          lockUntil,
+					"lockout_count":         user.LockoutCount,
 				}).Error; err != nil {
 					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
 					return
 				}
 
 				// Audit log: account locked
-				logging.LogAccountLocked(ctx, user.ID, user.Username, c.ClientIP(), user.FailedLoginAttempts)
+				logging.LogAccountLocked(ctx, user.ID, user.Username, c.ClientIP(), user.FailedLoginAttempts, user.LockoutCount, lockUntil)
 
+				retryMins := int(duration.Minutes())
 				c.JSON(http.StatusForbidden, gin.H{
-					"error":         "Account has been locked due to too many failed login attempts. Please try again in 30 minutes or reset your password.",
+					"error":         fmt.Sprintf("Account has been locked due to too many failed login attempts. Please try again in %d minutes or reset your password.", retryMins),
 					"locked_until":  lockUntil,
-					"retry_in_mins": int(AccountLockoutDuration.Minutes()),
+					"retry_in_mins": retryMins,
 				})
 				return
 			}
@@ -186,7 +224,7 @@ func Login(db *gorm.DB) gin.HandlerFunc {
 			// Audit log: failed login attempt
 			logging.LogAuthFailure(ctx, req.Username, c.ClientIP(), "invalid_password")
 
-			attemptsRemaining := MaxFailedLoginAttempts - user.FailedLoginAttempts
+			attemptsRemaining := policy.LockoutMaxAttempts - user.FailedLoginAttempts
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":              "Invalid credentials",
 				"attempts_remaining": attemptsRemaining,
@@ -194,41 +232,120 @@ func Login(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// Successful login - record last login timestamp and reset failed attempts if needed
-		now := time.Now().UTC()
-		updates := map[string]interface{}{
-			"last_login": now,
-		}
-		if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
-			updates["failed_login_attempts"] = 0
-			updates["locked_until"] = nil
-			user.FailedLoginAttempts = 0
-			user.LockedUntil = nil
+		if !passwordLoginAllowed(&user, securityConfig.Get(ctx)) {
+			logging.LogAuthFailure(ctx, req.Username, c.ClientIP(), "password_login_disabled")
+			c.JSON(http.StatusForbidden, gin.H{"error": passwordLoginDisabledMsg})
+			return
 		}
-		if err := db.Model(&user).Updates(updates).Error; err != nil {
+
+		if err := recordSuccessfulLogin(db, &user); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
 			return
 		}
-		user.LastLogin = &now
 
 		// Audit log: successful login
 		logging.LogAuthSuccess(ctx, user.ID, user.Username, c.ClientIP())
 
 		// Generate token
-		token, err := auth.GenerateToken(user.ID, user.IsAdmin)
+		token, err := generateSessionToken(db, user.ID, user.Username, user.IsAdmin)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 			return
 		}
+		token, csrfToken, err := startSession(c, sessions, token, sessions.Cookies() && req.Session != middleware.SessionModeBearer)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start session"})
+			return
+		}
 
 		c.JSON(http.StatusOK, AuthResponse{
 			Token:     token,
+			CSRFToken: csrfToken,
 			User:      user,
 			LastLogin: user.LastLogin,
 		})
 	}
 }
 
+// LoginAttemptFailed reports whether a Login response status is a failed
+// attempt for per-IP throttling: bad credentials, or a locked or otherwise
+// refused account.
+func LoginAttemptFailed(status int) bool {
+	return status == http.StatusUnauthorized || status == http.StatusForbidden
+}
+
+// PasswordResetAttempted reports whether a RequestPasswordReset response
+// counts toward per-IP throttling. The response never reveals whether the
+// email exists, so every request that isn't a server error does.
+func PasswordResetAttempted(status int) bool {
+	return status < http.StatusInternalServerError
+}
+
+// recordSuccessfulLogin sets user's last login time and clears any failed
+// attempts and lockouts.
+func recordSuccessfulLogin(db *gorm.DB, user *models.User) error {
+	now := time.Now().UTC()
+	updates := map[string]interface{}{
+		"last_login": now,
+	}
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil || user.LockoutCount > 0 {
+		updates["failed_login_attempts"] = 0
+		updates["locked_until"] = nil
+		updates["lockout_count"] = 0
+		user.FailedLoginAttempts = 0
+		user.LockedUntil = nil
+		user.LockoutCount = 0
+	}
+	if err := db.Model(user).Updates(updates).Error; err != nil {
+		return err
+	}
+	user.LastLogin = &now
+	return nil
+}
+
+// generateSessionToken issues a JWT carrying the user's current group
+// memberships, so access checks can skip the database until it's refreshed
+func generateSessionToken(db *gorm.DB, userID uint, username string, isAdmin bool) (string, error) {
+	var memberships []models.UserGroup
+	if err := db.Select("user_groups.group_id", "user_groups.is_group_admin").
+		Joins("JOIN groups ON groups.id = user_groups.group_id AND groups.deleted_at IS NULL").
+		Where("user_groups.user_id = ?", userID).
+		Order("user_groups.group_id").
+		Find(&memberships).Error; err != nil {
+		return "", err
+	}
+	groups := &auth.GroupClaims{GroupIDs: make([]uint, 0, len(memberships))}
+	for _, m := range memberships {
+		groups.GroupIDs = append(groups.GroupIDs, m.GroupID)
+		if m.IsGroupAdmin {
+			groups.AdminGroupIDs = append(groups.AdminGroupIDs, m.GroupID)
+		}
+	}
+	return auth.GenerateUserTokenWithGroups(userID, username, isAdmin, groups)
+}
+
+// startSession hands token to the client. A cookie session keeps it in the
+// session cookie and returns only its CSRF token; otherwise token is
+// returned as is.
+func startSession(c *gin.Context, sessions middleware.SessionConfig, token string, cookie bool) (string, string, error) {
+	if !cookie {
+		return token, "", nil
+	}
+	csrfToken, err := sessions.StartCookieSession(c, token)
+	return "", csrfToken, err
+}
+
+// Logout ends a cookie session by clearing its cookies. Bearer JWTs can't be
+// revoked; clients discard them.
+// Route: POST /api/logout
+func Logout() gin.HandlerFunc {
+	sessions := middleware.SessionConfigFromEnv()
+	return func(c *gin.Context) {
+		sessions.EndCookieSession(c)
+		respondOK(c, gin.H{"message": "Logged out"})
+	}
+}
+
 // GetCurrentUser returns the current authenticated user
 func GetCurrentUser(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -263,6 +380,9 @@ func GetCurrentUser(db *gorm.DB) gin.HandlerFunc {
 			"default_group_id":            user.DefaultGroupID,
 			"groups":                      user.Groups,
 			"email_notifications_enabled": user.EmailNotificationsEnabled,
+			"email_verified_at":           user.EmailVerifiedAt,
+			"avatar_url":                  user.AvatarURL,
+			"avatar_thumbnail_url":        user.AvatarThumbnailURL,
 			"is_group_admin":              len(userGroups) > 0,
 			"created_at":                  user.CreatedAt,
 			"updated_at":                  user.UpdatedAt,
@@ -272,3 +392,46 @@ func GetCurrentUser(db *gorm.DB) gin.HandlerFunc {
 		c.JSON(http.StatusOK, response)
 	}
 }
+
+// RefreshToken issues a new JWT for the current user, re-reading their
+// username and admin flag so the new claims reflect changes made since the
+// old token was issued. A cookie session gets it in a new cookie, with a
+// new CSRF token. API tokens can't be exchanged for a JWT.
+// Route: POST /api/refresh
+func RefreshToken(db *gorm.DB) gin.HandlerFunc {
+	sessions := middleware.SessionConfigFromEnv()
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+		if c.GetBool("api_token_auth") {
+			respondForbidden(c, "API tokens can't be refreshed")
+			return
+		}
+
+		var user models.User
+		if err := db.First(&user, userID).Error; err != nil {
+			respondUnauthorized(c, "User not found")
+			return
+		}
+
+		token, err := generateSessionToken(db, user.ID, user.Username, user.IsAdmin)
+		if err != nil {
+			respondInternalError(c, "Failed to generate token")
+			return
+		}
+		token, csrfToken, err := startSession(c, sessions, token, middleware.CookieSessionAuth(c))
+		if err != nil {
+			respondInternalError(c, "Failed to start session")
+			return
+		}
+		if csrfToken != "" {
+			respondOK(c, gin.H{"csrf_token": csrfToken})
+			return
+		}
+		respondOK(c, gin.H{"token": token})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
 	"gorm.io/gorm"
 )
@@ -157,7 +158,7 @@ func TestRegister(t *testing.T) {
 			c.Request = httptest.NewRequest("POST", "/api/v1/auth/register", bytes.NewBuffer(jsonBytes))
 			c.Request.Header.Set("Content-Type", "application/json")
 
-			handler := Register(db)
+			handler := Register(db, nil)
 			handler(c)
 
 			if w.Code != tt.expectedStatus {
@@ -374,7 +375,7 @@ func TestLogin(t *testing.T) {
 			c.Request = httptest.NewRequest("POST", "/api/v1/auth/login", bytes.NewBuffer(jsonBytes))
 			c.Request.Header.Set("Content-Type", "application/json")
 
-			handler := Login(db)
+			handler := Login(db, nil)
 			handler(c)
 
 			if w.Code != tt.expectedStatus {
@@ -425,7 +426,7 @@ func TestLoginSoftDeletedGroups(t *testing.T) {
 	c.Request.Body = io.NopCloser(bytes.NewReader(body))
 	c.Request.Header.Set("Content-Type", "application/json")
 
-	handler := Login(db)
+	handler := Login(db, nil)
 	handler(c)
 
 	if w.Code != http.StatusOK {
@@ -593,3 +594,141 @@ func TestGetCurrentUserSoftDeletedGroups(t *testing.T) {
 		}
 	}
 }
+
+// TestLogin_LockoutPolicy verifies the configurable lockout threshold and
+// progressive backoff
+func TestLogin_LockoutPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, env := range []string{"LOCKOUT_MAX_ATTEMPTS", "LOCKOUT_DURATION_MINUTES", "LOCKOUT_BACKOFF_MULTIPLIER", "LOCKOUT_MAX_DURATION_MINUTES"} {
+		t.Setenv(env, "")
+	}
+
+	db := setupTestDB(t)
+	db.Create(&models.SiteSetting{Key: middleware.SettingLockoutMaxAttempts, Value: "2"})
+	db.Create(&models.SiteSetting{Key: middleware.SettingLockoutDuration, Value: "10"})
+	db.Create(&models.SiteSetting{Key: middleware.SettingLockoutBackoff, Value: "3"})
+	user := createTestUser(t, db, "testuser", "test@example.com", "password123", false)
+	handler := Login(db, middleware.NewSecurityConfigStore(db))
+
+	login := func(password string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		body, _ := json.Marshal(map[string]string{"username": "testuser", "password": password})
+		c.Request = httptest.NewRequest("POST", "/api/login", bytes.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		handler(c)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	if code, resp := login("wrong"); code != http.StatusUnauthorized || resp["attempts_remaining"] != float64(1) {
+		t.Fatalf("first failure: got %d %v, want 401 with 1 attempt remaining", code, resp)
+	}
+	if code, resp := login("wrong"); code != http.StatusForbidden || resp["retry_in_mins"] != float64(10) {
+		t.Fatalf("second failure: got %d %v, want 403 locked for 10 minutes", code, resp)
+	}
+
+	// Let the lock expire; the next lockout lasts three times as long
+	db.Model(user).Update("locked_until", time.Now().Add(-time.Minute))
+	login("wrong")
+	if code, resp := login("wrong"); code != http.StatusForbidden || resp["retry_in_mins"] != float64(30) {
+		t.Fatalf("second lockout: got %d %v, want 403 locked for 30 minutes", code, resp)
+	}
+
+	var locked models.User
+	db.First(&locked, user.ID)
+	if locked.LockoutCount != 2 {
+		t.Errorf("LockoutCount = %d, want 2", locked.LockoutCount)
+	}
+
+	// A successful login resets the backoff
+	db.Model(user).Update("locked_until", time.Now().Add(-time.Minute))
+	if code, _ := login("password123"); code != http.StatusOK {
+		t.Fatalf("login after lock expired: got %d, want 200", code)
+	}
+	db.First(&locked, user.ID)
+	if locked.LockoutCount != 0 || locked.FailedLoginAttempts != 0 {
+		t.Errorf("lockout state not reset: count %d, attempts %d", locked.LockoutCount, locked.FailedLoginAttempts)
+	}
+}
+
+func TestLogin_CookieSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("SESSION_MODE", "cookie")
+	db := setupTestDB(t)
+	createTestUser(t, db, "testuser", "test@example.com", "password123", false)
+
+	router := gin.New()
+	api := router.Group("/api")
+	api.POST("/login", Login(db, nil))
+	api.POST("/logout", Logout())
+	protected := api.Group("/")
+	protected.Use(middleware.AuthRequired(db), middleware.CSRFRequired())
+	protected.GET("/me", GetCurrentUser(db))
+	protected.POST("/refresh", RefreshToken(db))
+
+	var cookies []*http.Cookie
+	do := func(method, path string, body interface{}, csrfToken string) (int, map[string]interface{}) {
+		var reader io.Reader
+		if body != nil {
+			data, _ := json.Marshal(body)
+			reader = bytes.NewReader(data)
+		}
+		req := httptest.NewRequest(method, path, reader)
+		req.Header.Set("Content-Type", "application/json")
+		if csrfToken != "" {
+			req.Header.Set(middleware.CSRFHeader, csrfToken)
+		}
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if set := w.Result().Cookies(); len(set) > 0 {
+			cookies = nil
+			for _, cookie := range set {
+				if cookie.MaxAge >= 0 {
+					cookies = append(cookies, cookie)
+				}
+			}
+		}
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	code, resp := do("POST", "/api/login", map[string]string{"username": "testuser", "password": "password123"}, "")
+	if code != http.StatusOK || resp["token"] != nil || resp["csrf_token"] == "" {
+		t.Fatalf("login: got %d %v, want a CSRF token and no JWT", code, resp)
+	}
+	csrfToken, _ := resp["csrf_token"].(string)
+	if len(cookies) != 2 {
+		t.Fatalf("login set %d cookies, want the session and CSRF cookies", len(cookies))
+	}
+
+	if code, resp := do("GET", "/api/me", nil, ""); code != http.StatusOK || resp["username"] != "testuser" {
+		t.Fatalf("GET /me with the session cookie: got %d %v", code, resp)
+	}
+	if code, _ := do("POST", "/api/refresh", nil, ""); code != http.StatusForbidden {
+		t.Fatalf("refresh without the CSRF token: got %d, want 403", code)
+	}
+	code, resp = do("POST", "/api/refresh", nil, csrfToken)
+	if code != http.StatusOK || resp["token"] != nil || resp["csrf_token"] == nil {
+		t.Fatalf("refresh: got %d %v, want a new CSRF token and no JWT", code, resp)
+	}
+
+	// API clients can still ask for a bearer token
+	saved := cookies
+	if code, resp := do("POST", "/api/login", map[string]string{"username": "testuser", "password": "password123", "session": "bearer"}, ""); code != http.StatusOK || resp["token"] == nil {
+		t.Fatalf("bearer login: got %d %v, want a JWT", code, resp)
+	}
+	cookies = saved
+
+	if code, _ := do("POST", "/api/logout", nil, ""); code != http.StatusOK || len(cookies) != 0 {
+		t.Fatalf("logout: got %d, %d cookies left", code, len(cookies))
+	}
+	if code, _ := do("GET", "/api/me", nil, ""); code != http.StatusUnauthorized {
+		t.Fatalf("GET /me after logout: got %d, want 401", code)
+	}
+}
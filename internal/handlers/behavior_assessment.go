@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// BehaviorAssessmentRequest is the body for recording a behavior assessment.
+// AssessedAt is a YYYY-MM-DD date and defaults to today.
+type BehaviorAssessmentRequest struct {
+	Score      int    `json:"score" binding:"required,min=1,max=5"`
+	AssessedAt string `json:"assessed_at"`
+	Notes      string `json:"notes" binding:"max=2000"`
+}
+
+// latestBehaviorAssessments returns each animal's most recent assessment,
+// keyed by animal ID. Animals never assessed are missing from the map.
+func latestBehaviorAssessments(db *gorm.DB, animalIDs []uint) (map[uint]models.BehaviorAssessment, error) {
+	var assessments []models.BehaviorAssessment
+	if err := db.Where("animal_id IN ?", animalIDs).
+		Order("assessed_at DESC, id DESC").Find(&assessments).Error; err != nil {
+		return nil, err
+	}
+	latest := make(map[uint]models.BehaviorAssessment, len(animalIDs))
+	for _, a := range assessments {
+		if _, seen := latest[a.AnimalID]; !seen {
+			latest[a.AnimalID] = a
+		}
+	}
+	return latest, nil
+}
+
+// GetBehaviorAssessments returns an animal's behavior assessments, newest
+// first. Members below the group's minimum role for them are refused.
+// Route: GET /api/groups/:id/animals/:animalId/behavior-assessments
+func GetBehaviorAssessments(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, c.Param("id")) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+
+		animal, ok := findGroupAnimal(c, db)
+		if !ok {
+			return
+		}
+		policy, err := loadFieldPolicy(c, db, animal.GroupID)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to load field visibility", err)
+			respondInternalError(c, "Failed to fetch behavior assessments")
+			return
+		}
+		if policy.Hides("behavior_assessments") {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Behavior assessments aren't visible to your role")
+			return
+		}
+
+		var assessments []models.BehaviorAssessment
+		if err := db.Preload("AssessedBy").Where("animal_id = ?", animal.ID).
+			Order("assessed_at DESC, id DESC").Find(&assessments).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to fetch behavior assessments", err)
+			respondInternalError(c, "Failed to fetch behavior assessments")
+			return
+		}
+
+		respondOK(c, assessments)
+	}
+}
+
+// CreateBehaviorAssessment records a behavior assessment score from 1 to 5
+// (group admin or site admin)
+// Route: POST /api/groups/:id/animals/:animalId/behavior-assessments
+func CreateBehaviorAssessment(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAdminAccess(db, userID, isAdmin, c.Param("id")) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Only group admins can record behavior assessments")
+			return
+		}
+
+		uid, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+
+		animal, ok := findGroupAnimal(c, db)
+		if !ok {
+			return
+		}
+
+		var req BehaviorAssessmentRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		assessedAt, err := parsePastDate("assessed_at", req.AssessedAt)
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+
+		assessment := models.BehaviorAssessment{
+			AnimalID:     animal.ID,
+			Score:        req.Score,
+			AssessedAt:   assessedAt,
+			AssessedByID: uid,
+			Notes:        req.Notes,
+		}
+		if err := db.Create(&assessment).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to create behavior assessment", err)
+			respondInternalError(c, "Failed to record behavior assessment")
+			return
+		}
+		db.Preload("AssessedBy").First(&assessment, assessment.ID)
+
+		respondCreated(c, assessment)
+	}
+}
@@ -0,0 +1,649 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// breedNormalizationSetting is the site setting choosing how animal breeds
+// are checked against the managed breed list on create, update and import.
+const breedNormalizationSetting = "breed_normalization"
+
+const (
+	// breedNormalizationOff stores breeds exactly as entered
+	breedNormalizationOff = "off"
+	// breedNormalizationNormalize replaces a breed matching a canonical name
+	// or alias with the canonical name, keeping anything else as entered
+	breedNormalizationNormalize = "normalize"
+	// breedNormalizationStrict also rejects breeds that match nothing, for
+	// species that have a managed list
+	breedNormalizationStrict = "strict"
+)
+
+var breedNormalizationModes = []string{breedNormalizationOff, breedNormalizationNormalize, breedNormalizationStrict}
+
+const (
+	defaultBreedSuggestions = 10
+	maxBreedSuggestions     = 50
+)
+
+// breedMixSuffixes mark a mixed breed, e.g. "Lab mix", which normalizes to
+// the canonical name plus " Mix" when the rest matches a breed
+var breedMixSuffixes = []string{" mixed", " mix"}
+
+// cutBreedMixSuffix returns breed without a trailing mix suffix, and whether
+// it had one. The original spelling is kept when the suffix is separated by
+// a space; otherwise, as in "Lab-mix", the result is a match key.
+func cutBreedMixSuffix(breed string) (string, bool) {
+	breed = strings.TrimSpace(breed)
+	key := breedKey(breed)
+	for _, suffix := range breedMixSuffixes {
+		if !strings.HasSuffix(key, suffix) {
+			continue
+		}
+		if n := len(breed) - len(suffix); n > 0 && strings.EqualFold(breed[n:], suffix) {
+			return strings.TrimSpace(breed[:n]), true
+		}
+		return strings.TrimSuffix(key, suffix), true
+	}
+	return breed, false
+}
+
+// validateBreedNormalizationSetting checks a breed_normalization value
+func validateBreedNormalizationSetting(value string) error {
+	value = strings.TrimSpace(value)
+	for _, mode := range breedNormalizationModes {
+		if value == mode {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s must be one of: %s", breedNormalizationSetting, strings.Join(breedNormalizationModes, ", "))
+}
+
+// breedKey folds a species or breed for matching: lower case, punctuation
+// dropped and whitespace collapsed, so "Pit-Bull" and "pit bull" match
+func breedKey(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || r == '-' || r == '_' || r == '/':
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// breedIndex is the managed breed list keyed for matching, loaded once per
+// request so an import doesn't query it for every row
+type breedIndex struct {
+	mode      string
+	bySpecies map[string][]models.Breed          // species key -> breeds
+	byKey     map[string]map[string]models.Breed // species key -> name or alias key -> breed
+}
+
+// loadBreedIndex loads the breed list and the breed_normalization mode,
+// which defaults to normalize
+func loadBreedIndex(db *gorm.DB) (*breedIndex, error) {
+	idx := &breedIndex{
+		mode:      breedNormalizationNormalize,
+		bySpecies: map[string][]models.Breed{},
+		byKey:     map[string]map[string]models.Breed{},
+	}
+	var values []string
+	if err := db.Model(&models.SiteSetting{}).Where("key = ?", breedNormalizationSetting).Pluck("value", &values).Error; err != nil {
+		return nil, err
+	}
+	if len(values) > 0 && validateBreedNormalizationSetting(values[0]) == nil {
+		idx.mode = strings.TrimSpace(values[0])
+	}
+
+	var breeds []models.Breed
+	if err := db.Order("name").Find(&breeds).Error; err != nil {
+		return nil, err
+	}
+	for _, breed := range breeds {
+		species := breedKey(breed.Species)
+		idx.bySpecies[species] = append(idx.bySpecies[species], breed)
+		if idx.byKey[species] == nil {
+			idx.byKey[species] = map[string]models.Breed{}
+		}
+		for _, alias := range breed.Aliases {
+			idx.byKey[species][breedKey(alias)] = breed
+		}
+	}
+	// Names win over another breed's alias
+	for species, breeds := range idx.bySpecies {
+		for _, breed := range breeds {
+			idx.byKey[species][breedKey(breed.Name)] = breed
+		}
+	}
+	return idx, nil
+}
+
+// match returns the canonical form of breed for species, if it matches a
+// breed's name or alias, with or without a mix suffix
+func (idx *breedIndex) match(species, breed string) (string, *models.Breed) {
+	keys := idx.byKey[breedKey(species)]
+	key := breedKey(breed)
+	if key == "" || keys == nil {
+		return "", nil
+	}
+	if found, ok := keys[key]; ok {
+		return found.Name, &found
+	}
+	if base, mixed := cutBreedMixSuffix(breed); mixed {
+		if found, ok := keys[breedKey(base)]; ok {
+			return found.Name + " Mix", &found
+		}
+	}
+	return "", nil
+}
+
+// normalize applies the breed_normalization mode to a breed entered for an
+// animal of species. Species without a managed list accept any breed.
+func (idx *breedIndex) normalize(species, breed string) (string, error) {
+	breed = strings.TrimSpace(breed)
+	if breed == "" || idx.mode == breedNormalizationOff || len(idx.bySpecies[breedKey(species)]) == 0 {
+		return breed, nil
+	}
+	if canonical, _ := idx.match(species, breed); canonical != "" {
+		return canonical, nil
+	}
+	if idx.mode == breedNormalizationStrict {
+		return "", fmt.Errorf("breed %q isn't in the breed list for %s", breed, species)
+	}
+	return breed, nil
+}
+
+// suggest returns the breed an unmatched free-text breed most likely means:
+// one whose name or alias starts with it, or that it starts with, preferring
+// the longest overlap
+func (idx *breedIndex) suggest(species, breed string) *models.Breed {
+	base, _ := cutBreedMixSuffix(breed)
+	key := breedKey(base)
+	if key == "" {
+		return nil
+	}
+	var best *models.Breed
+	bestLen := 0
+	for candidate, b := range idx.byKey[breedKey(species)] {
+		overlap := 0
+		switch {
+		case strings.HasPrefix(candidate, key):
+			overlap = len(key)
+		case strings.HasPrefix(key, candidate+" "):
+			overlap = len(candidate)
+		}
+		if overlap > bestLen || (overlap == bestLen && overlap > 0 && b.Name < best.Name) {
+			best, bestLen = &b, overlap
+		}
+	}
+	return best
+}
+
+// normalizeAnimalBreed normalizes a breed entered on an animal, writing a
+// 400 and returning false when strict mode rejects it
+func normalizeAnimalBreed(c *gin.Context, db *gorm.DB, species, breed string) (string, bool) {
+	if strings.TrimSpace(breed) == "" {
+		return breed, true
+	}
+	idx, err := loadBreedIndex(db)
+	if err != nil {
+		middleware.GetLogger(c).Error("Failed to load breed list", err)
+		respondInternalError(c, "Failed to check breed")
+		return "", false
+	}
+	normalized, err := idx.normalize(species, breed)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return "", false
+	}
+	return normalized, true
+}
+
+// BreedRequest represents a request to create or update a managed breed
+type BreedRequest struct {
+	Species string   `json:"species" binding:"required,min=1,max=50"`
+	Name    string   `json:"name" binding:"required,min=1,max=100"`
+	Aliases []string `json:"aliases" binding:"max=50,dive,max=100"`
+}
+
+// cleanBreedAliases trims aliases and drops blanks, duplicates and ones
+// that match the name anyway
+func cleanBreedAliases(name string, aliases []string) models.StringList {
+	seen := map[string]bool{breedKey(name): true}
+	out := models.StringList{}
+	for _, alias := range aliases {
+		alias = strings.TrimSpace(alias)
+		if key := breedKey(alias); key != "" && !seen[key] {
+			seen[key] = true
+			out = append(out, alias)
+		}
+	}
+	return out
+}
+
+// breedConflict returns the breed of the same species, other than id, whose
+// name or aliases collide with breed's, if any
+func breedConflict(db *gorm.DB, breed models.Breed) (*models.Breed, error) {
+	idx, err := loadBreedIndex(db)
+	if err != nil {
+		return nil, err
+	}
+	keys := idx.byKey[breedKey(breed.Species)]
+	for _, value := range append([]string{breed.Name}, breed.Aliases...) {
+		if found, ok := keys[breedKey(value)]; ok && found.ID != breed.ID {
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetBreeds returns the managed breed list, optionally for one species. With
+// ?q=, it returns autocomplete suggestions instead: breeds whose name or
+// alias starts with q first, then other word and substring matches, up to
+// ?limit= (default 10, max 50).
+// Route: GET /api/breeds
+func GetBreeds(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		query := db.Order("species, name")
+		species := breedKey(c.Query("species"))
+
+		var breeds []models.Breed
+		if err := query.Find(&breeds).Error; err != nil {
+			respondInternalError(c, "Failed to fetch breeds")
+			return
+		}
+		if species != "" {
+			filtered := breeds[:0]
+			for _, breed := range breeds {
+				if breedKey(breed.Species) == species {
+					filtered = append(filtered, breed)
+				}
+			}
+			breeds = filtered
+		}
+
+		q := breedKey(c.Query("q"))
+		if q == "" {
+			respondOK(c, breeds)
+			return
+		}
+		limit := defaultBreedSuggestions
+		if raw := c.Query("limit"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 1 {
+				respondBadRequest(c, "limit must be a positive number")
+				return
+			}
+			limit = min(n, maxBreedSuggestions)
+		}
+
+		type ranked struct {
+			breed models.Breed
+			rank  int
+		}
+		var matches []ranked
+		for _, breed := range breeds {
+			rank := breedMatchRank(q, breed)
+			if rank >= 0 {
+				matches = append(matches, ranked{breed, rank})
+			}
+		}
+		sort.SliceStable(matches, func(i, j int) bool { return matches[i].rank < matches[j].rank })
+		suggestions := make([]models.Breed, 0, limit)
+		for _, m := range matches {
+			if len(suggestions) == limit {
+				break
+			}
+			suggestions = append(suggestions, m.breed)
+		}
+		respondOK(c, suggestions)
+	}
+}
+
+// breedMatchRank orders autocomplete matches for q: 0 for a name prefix, 1
+// for an alias prefix, 2 for a later word of the name starting with q, 3 for
+// any other substring, and -1 for no match
+func breedMatchRank(q string, breed models.Breed) int {
+	name := breedKey(breed.Name)
+	if strings.HasPrefix(name, q) {
+		return 0
+	}
+	for _, alias := range breed.Aliases {
+		if strings.HasPrefix(breedKey(alias), q) {
+			return 1
+		}
+	}
+	if strings.Contains(name, " "+q) {
+		return 2
+	}
+	if strings.Contains(name, q) {
+		return 3
+	}
+	for _, alias := range breed.Aliases {
+		if strings.Contains(breedKey(alias), q) {
+			return 3
+		}
+	}
+	return -1
+}
+
+// CreateBreed adds a breed to the managed list (admin only)
+// Route: POST /api/admin/breeds
+func CreateBreed(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		var req BreedRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		breed := models.Breed{
+			Species: strings.TrimSpace(req.Species),
+			Name:    strings.TrimSpace(req.Name),
+		}
+		breed.Aliases = cleanBreedAliases(breed.Name, req.Aliases)
+		if !saveBreed(c, db, &breed) {
+			return
+		}
+		respondCreated(c, breed)
+	}
+}
+
+// UpdateBreed renames a managed breed or changes its aliases (admin only).
+// Animals keep their breed text; run the breed migration to move them.
+// Route: PUT /api/admin/breeds/:breedId
+func UpdateBreed(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		var breed models.Breed
+		if err := db.First(&breed, c.Param("breedId")).Error; err != nil {
+			respondNotFound(c, "Breed not found")
+			return
+		}
+		var req BreedRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		breed.Species = strings.TrimSpace(req.Species)
+		breed.Name = strings.TrimSpace(req.Name)
+		breed.Aliases = cleanBreedAliases(breed.Name, req.Aliases)
+		if !saveBreed(c, db, &breed) {
+			return
+		}
+		respondOK(c, breed)
+	}
+}
+
+// saveBreed creates or updates breed, writing a 409 if its name or an alias
+// is already used by another breed of the same species
+func saveBreed(c *gin.Context, db *gorm.DB, breed *models.Breed) bool {
+	conflict, err := breedConflict(db, *breed)
+	if err != nil {
+		respondInternalError(c, "Failed to save breed")
+		return false
+	}
+	if conflict != nil {
+		respondError(c, http.StatusConflict, ErrCodeConflict, fmt.Sprintf("%q is already used by the %s breed %q", breed.Name, conflict.Species, conflict.Name))
+		return false
+	}
+	if err := db.Save(breed).Error; err != nil {
+		middleware.GetLogger(c).Error("Failed to save breed", err)
+		respondInternalError(c, "Failed to save breed")
+		return false
+	}
+	return true
+}
+
+// DeleteBreed removes a breed from the managed list (admin only). Animals
+// keep their breed text.
+// Route: DELETE /api/admin/breeds/:breedId
+func DeleteBreed(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		result := db.Delete(&models.Breed{}, c.Param("breedId"))
+		if result.Error != nil {
+			respondInternalError(c, "Failed to delete breed")
+			return
+		}
+		if result.RowsAffected == 0 {
+			respondNotFound(c, "Breed not found")
+			return
+		}
+		respondNoContent(c)
+	}
+}
+
+// Breed report statuses
+const (
+	breedStatusCanonical = "canonical" // Already the canonical name
+	breedStatusMatched   = "matched"   // Matches a name or alias with different text
+	breedStatusUnmatched = "unmatched" // Matches nothing; Suggestion is a guess
+)
+
+// BreedReportEntry is one distinct free-text breed in use
+type BreedReportEntry struct {
+	Species     string        `json:"species"`
+	Breed       string        `json:"breed"`
+	AnimalCount int64         `json:"animal_count"`
+	Status      string        `gorm:"-" json:"status"`
+	Canonical   string        `gorm:"-" json:"canonical,omitempty"`  // What a matched breed migrates to
+	Suggestion  *models.Breed `gorm:"-" json:"suggestion,omitempty"` // Likely breed for an unmatched one
+}
+
+// breedReport lists each distinct species and breed on animals and how it
+// relates to the managed list
+func breedReport(db *gorm.DB, idx *breedIndex) ([]BreedReportEntry, error) {
+	var entries []BreedReportEntry
+	if err := db.Model(&models.Animal{}).
+		Select("species, breed, COUNT(*) AS animal_count").
+		Where("breed <> ''").
+		Group("species, breed").
+		Order("species, breed").
+		Scan(&entries).Error; err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		e := &entries[i]
+		canonical, _ := idx.match(e.Species, e.Breed)
+		switch {
+		case canonical == e.Breed:
+			e.Status = breedStatusCanonical
+		case canonical != "":
+			e.Status, e.Canonical = breedStatusMatched, canonical
+		default:
+			e.Status = breedStatusUnmatched
+			e.Suggestion = idx.suggest(e.Species, e.Breed)
+		}
+	}
+	return entries, nil
+}
+
+// GetBreedReport lists the free-text breeds animals use, marking which
+// already match the managed list and suggesting a breed for the rest (admin
+// only)
+// Route: GET /api/admin/breeds/report
+func GetBreedReport(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		idx, err := loadBreedIndex(db)
+		if err != nil {
+			respondInternalError(c, "Failed to build breed report")
+			return
+		}
+		entries, err := breedReport(db, idx)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to build breed report", err)
+			respondInternalError(c, "Failed to build breed report")
+			return
+		}
+		respondOK(c, entries)
+	}
+}
+
+// BreedMapping maps one free-text breed, as listed in the breed report, to a
+// managed breed
+type BreedMapping struct {
+	Species string `json:"species" binding:"required"`
+	Breed   string `json:"breed" binding:"required"`
+	BreedID uint   `json:"breed_id" binding:"required"`
+}
+
+// MigrateBreedsRequest chooses which free-text breeds to rewrite
+type MigrateBreedsRequest struct {
+	// ApplyMatches rewrites every breed the report marks as matched
+	ApplyMatches bool           `json:"apply_matches"`
+	Mappings     []BreedMapping `json:"mappings" binding:"max=500,dive"`
+	// AddAliases records each mapped breed text as an alias of its target,
+	// so later entries normalize without another migration
+	AddAliases bool `json:"add_aliases"`
+	DryRun     bool `json:"dry_run"`
+}
+
+// BreedMigrationChange is one rewrite made (or, in a dry run, to be made)
+type BreedMigrationChange struct {
+	Species     string `json:"species"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	AnimalCount int64  `json:"animal_count"`
+}
+
+// MigrateBreedsResult is the response to a breed migration
+type MigrateBreedsResult struct {
+	DryRun         bool                   `json:"dry_run"`
+	Changes        []BreedMigrationChange `json:"changes"`
+	AnimalsUpdated int64                  `json:"animals_updated"`
+}
+
+// MigrateBreeds rewrites existing animals' free-text breeds to canonical
+// names: the matches from the breed report, explicit mappings, or both
+// (admin only). A dry run reports the same changes without making them. A mix suffix carries over, so "Lab mix" mapped to
+// "Labrador Retriever" becomes "Labrador Retriever Mix".
+// Route: POST /api/admin/breeds/migrate
+func MigrateBreeds(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		var req MigrateBreedsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		if !req.ApplyMatches && len(req.Mappings) == 0 {
+			respondBadRequest(c, "Nothing to migrate: set apply_matches or mappings")
+			return
+		}
+
+		result := MigrateBreedsResult{DryRun: req.DryRun, Changes: []BreedMigrationChange{}}
+		errNotFound := errors.New("breed not found")
+		err := db.Transaction(func(tx *gorm.DB) error {
+			idx, err := loadBreedIndex(tx)
+			if err != nil {
+				return err
+			}
+			entries, err := breedReport(tx, idx)
+			if err != nil {
+				return err
+			}
+			type target struct {
+				to    string
+				breed *models.Breed
+			}
+			targets := map[[2]string]target{}
+			mapped := map[uint]*models.Breed{} // Shared so aliases added for several mappings accumulate
+			if req.ApplyMatches {
+				for _, e := range entries {
+					if e.Status == breedStatusMatched {
+						targets[[2]string{e.Species, e.Breed}] = target{to: e.Canonical}
+					}
+				}
+			}
+			for _, m := range req.Mappings {
+				breed, ok := mapped[m.BreedID]
+				if !ok {
+					breed = &models.Breed{}
+					if err := tx.First(breed, m.BreedID).Error; err != nil {
+						if errors.Is(err, gorm.ErrRecordNotFound) {
+							return fmt.Errorf("%w: %d", errNotFound, m.BreedID)
+						}
+						return err
+					}
+					mapped[m.BreedID] = breed
+				}
+				if breedKey(breed.Species) != breedKey(m.Species) {
+					return fmt.Errorf("%w: %d", errNotFound, m.BreedID)
+				}
+				to := breed.Name
+				if base, mixed := cutBreedMixSuffix(m.Breed); mixed && breedKey(base) != breedKey(breed.Name) {
+					to += " Mix"
+				}
+				targets[[2]string{m.Species, m.Breed}] = target{to: to, breed: breed}
+			}
+
+			for _, e := range entries {
+				t, ok := targets[[2]string{e.Species, e.Breed}]
+				if !ok || t.to == e.Breed {
+					continue
+				}
+				change := BreedMigrationChange{Species: e.Species, From: e.Breed, To: t.to, AnimalCount: e.AnimalCount}
+				if !req.DryRun {
+					res := tx.Model(&models.Animal{}).Where("species = ? AND breed = ?", e.Species, e.Breed).Update("breed", t.to)
+					if res.Error != nil {
+						return res.Error
+					}
+					change.AnimalCount = res.RowsAffected
+					if req.AddAliases && t.breed != nil {
+						if canonical, _ := idx.match(e.Species, e.Breed); canonical == "" {
+							// "Lab mix" teaches "Lab"; the mix suffix is matched anyway
+							alias, _ := cutBreedMixSuffix(e.Breed)
+							t.breed.Aliases = cleanBreedAliases(t.breed.Name, append(t.breed.Aliases, alias))
+							if err := tx.Model(t.breed).Update("aliases", t.breed.Aliases).Error; err != nil {
+								return err
+							}
+						}
+					}
+				}
+				result.Changes = append(result.Changes, change)
+				result.AnimalsUpdated += change.AnimalCount
+			}
+			return nil
+		})
+		if errors.Is(err, errNotFound) {
+			respondNotFound(c, "Breed not found for mapping: "+strings.TrimPrefix(err.Error(), errNotFound.Error()+": "))
+			return
+		}
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to migrate breeds", err)
+			respondInternalError(c, "Failed to migrate breeds")
+			return
+		}
+		if !req.DryRun && result.AnimalsUpdated > 0 {
+			userID, _ := middleware.GetUserID(c)
+			logging.LogAdminAction(c.Request.Context(), logging.AuditEventBreedsMigrated, userID, map[string]interface{}{
+				"breeds_changed":  len(result.Changes),
+				"animals_updated": result.AnimalsUpdated,
+			})
+		}
+		respondOK(c, result)
+	}
+}
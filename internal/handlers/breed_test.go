@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreedIndex_Normalize(t *testing.T) {
+	db := SetupTestDB(t)
+	require.NoError(t, db.Create(&models.Breed{Species: "Dog", Name: "Labrador Retriever", Aliases: models.StringList{"Lab", "Labrador"}}).Error)
+	require.NoError(t, db.Create(&models.Breed{Species: "Dog", Name: "Pit Bull Terrier", Aliases: models.StringList{"Pitbull"}}).Error)
+
+	idx, err := loadBreedIndex(db)
+	require.NoError(t, err)
+	tests := []struct {
+		species, breed, want string
+	}{
+		{"Dog", "lab", "Labrador Retriever"},
+		{"dog", "  LABRADOR retriever ", "Labrador Retriever"},
+		{"Dog", "Lab mix", "Labrador Retriever Mix"},
+		{"Dog", "pit-bull terrier", "Pit Bull Terrier"},
+		{"Dog", "Beagle", "Beagle"}, // Unknown breeds are kept in normalize mode
+		{"Cat", "lab", "lab"},       // Cats have no managed list
+		{"Dog", "", ""},
+	}
+	for _, tt := range tests {
+		got, err := idx.normalize(tt.species, tt.breed)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "%s / %q", tt.species, tt.breed)
+	}
+
+	idx.mode = breedNormalizationStrict
+	_, err = idx.normalize("Dog", "Beagle")
+	assert.Error(t, err)
+	got, err := idx.normalize("Cat", "Tabby")
+	assert.NoError(t, err, "species without a list accept anything")
+	assert.Equal(t, "Tabby", got)
+
+	idx.mode = breedNormalizationOff
+	got, _ = idx.normalize("Dog", "lab")
+	assert.Equal(t, "lab", got)
+}
+
+func TestBreedsAdminAndAutocomplete(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	call := func(handler gin.HandlerFunc, method, target string, params gin.Params, body interface{}) (int, []byte) {
+		c, w := accountTestContext(admin.ID, true, method, target, body)
+		c.Params = params
+		handler(c)
+		return w.Code, w.Body.Bytes()
+	}
+
+	code, body := call(CreateBreed(db), http.MethodPost, "/", nil, gin.H{
+		"species": "Dog", "name": "Labrador Retriever", "aliases": []string{"Lab", " lab ", "labrador retriever", ""},
+	})
+	require.Equal(t, http.StatusCreated, code, string(body))
+	var lab models.Breed
+	require.NoError(t, json.Unmarshal(body, &lab))
+	assert.Equal(t, models.StringList{"Lab"}, lab.Aliases, "blank, duplicate and name-matching aliases are dropped")
+
+	code, _ = call(CreateBreed(db), http.MethodPost, "/", nil, gin.H{"species": "dog", "name": "lab"})
+	assert.Equal(t, http.StatusConflict, code, "a name can't reuse another breed's alias")
+	for _, name := range []string{"Labradoodle", "Black Labrador Cross", "Golden Retriever"} {
+		code, body = call(CreateBreed(db), http.MethodPost, "/", nil, gin.H{"species": "Dog", "name": name})
+		require.Equal(t, http.StatusCreated, code, string(body))
+	}
+	code, _ = call(CreateBreed(db), http.MethodPost, "/", nil, gin.H{"species": "Cat", "name": "Labrador Cat"})
+	require.Equal(t, http.StatusCreated, code)
+
+	suggest := func(query string) []string {
+		code, body := call(GetBreeds(db), http.MethodGet, "/?"+query, nil, nil)
+		require.Equal(t, http.StatusOK, code, string(body))
+		var breeds []models.Breed
+		require.NoError(t, json.Unmarshal(body, &breeds))
+		names := make([]string, len(breeds))
+		for i, b := range breeds {
+			names[i] = b.Name
+		}
+		return names
+	}
+	assert.Equal(t, []string{"Labradoodle", "Labrador Retriever", "Black Labrador Cross"}, suggest("species=dog&q=lab"))
+	assert.Equal(t, []string{"Golden Retriever", "Labrador Retriever"}, suggest("species=Dog&q=retr"))
+	assert.Equal(t, []string{"Labradoodle"}, suggest("species=Dog&q=lab&limit=1"))
+	assert.Len(t, suggest(""), 5)
+
+	code, _ = call(UpdateBreed(db), http.MethodPut, "/", gin.Params{{Key: "breedId", Value: itoa(lab.ID)}}, gin.H{
+		"species": "Dog", "name": "Labrador Retriever", "aliases": []string{"Lab", "Labradoodle"},
+	})
+	assert.Equal(t, http.StatusConflict, code)
+}
+
+func TestBreedReportAndMigration(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	lab := models.Breed{Species: "Dog", Name: "Labrador Retriever", Aliases: models.StringList{"Lab"}}
+	pit := models.Breed{Species: "Dog", Name: "Pit Bull Terrier"}
+	require.NoError(t, db.Create(&lab).Error)
+	require.NoError(t, db.Create(&pit).Error)
+	for _, breed := range []string{"Lab", "lab", "Lab mix", "Labrador Retriever", "pitty", "pitty mix", "Pit", "Husky"} {
+		require.NoError(t, db.Create(&models.Animal{GroupID: group.ID, Name: breed, Species: "Dog", Breed: breed, Status: "available"}).Error)
+	}
+	call := func(handler gin.HandlerFunc, body interface{}) (int, []byte) {
+		c, w := accountTestContext(admin.ID, true, http.MethodPost, "/", body)
+		handler(c)
+		return w.Code, w.Body.Bytes()
+	}
+
+	code, body := call(GetBreedReport(db), nil)
+	require.Equal(t, http.StatusOK, code, string(body))
+	var report []BreedReportEntry
+	require.NoError(t, json.Unmarshal(body, &report))
+	byBreed := map[string]BreedReportEntry{}
+	for _, e := range report {
+		byBreed[e.Breed] = e
+	}
+	assert.Equal(t, breedStatusMatched, byBreed["Lab mix"].Status)
+	assert.Equal(t, "Labrador Retriever Mix", byBreed["Lab mix"].Canonical)
+	assert.Equal(t, breedStatusCanonical, byBreed["Labrador Retriever"].Status)
+	assert.Equal(t, breedStatusUnmatched, byBreed["Pit"].Status)
+	require.NotNil(t, byBreed["Pit"].Suggestion)
+	assert.Equal(t, pit.ID, byBreed["Pit"].Suggestion.ID)
+	assert.Nil(t, byBreed["Husky"].Suggestion)
+
+	mappings := []BreedMapping{
+		{Species: "Dog", Breed: "pitty", BreedID: pit.ID},
+		{Species: "Dog", Breed: "pitty mix", BreedID: pit.ID},
+		{Species: "Dog", Breed: "Pit", BreedID: pit.ID},
+	}
+	code, body = call(MigrateBreeds(db), MigrateBreedsRequest{ApplyMatches: true, Mappings: mappings, AddAliases: true, DryRun: true})
+	require.Equal(t, http.StatusOK, code, string(body))
+	var result MigrateBreedsResult
+	require.NoError(t, json.Unmarshal(body, &result))
+	assert.Equal(t, int64(6), result.AnimalsUpdated)
+	var unchanged int64
+	db.Model(&models.Animal{}).Where("breed = ?", "pitty").Count(&unchanged)
+	assert.Equal(t, int64(1), unchanged, "a dry run changes nothing")
+
+	code, body = call(MigrateBreeds(db), MigrateBreedsRequest{ApplyMatches: true, Mappings: mappings, AddAliases: true})
+	require.Equal(t, http.StatusOK, code, string(body))
+	var breeds []string
+	db.Model(&models.Animal{}).Order("id").Pluck("breed", &breeds)
+	assert.Equal(t, []string{
+		"Labrador Retriever", "Labrador Retriever", "Labrador Retriever Mix", "Labrador Retriever",
+		"Pit Bull Terrier", "Pit Bull Terrier Mix", "Pit Bull Terrier", "Husky",
+	}, breeds)
+	require.NoError(t, db.First(&pit, pit.ID).Error)
+	assert.Equal(t, models.StringList{"Pit", "pitty"}, pit.Aliases, "mapped spellings become aliases")
+
+	code, _ = call(MigrateBreeds(db), MigrateBreedsRequest{Mappings: []BreedMapping{{Species: "Cat", Breed: "Husky", BreedID: pit.ID}}})
+	assert.Equal(t, http.StatusNotFound, code, "a mapping must target a breed of the same species")
+}
+
+func TestCreateAnimal_StrictBreeds(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	require.NoError(t, db.Create(&models.Breed{Species: "Dog", Name: "Labrador Retriever", Aliases: models.StringList{"Lab"}}).Error)
+	require.NoError(t, db.Create(&models.SiteSetting{Key: breedNormalizationSetting, Value: breedNormalizationStrict}).Error)
+
+	create := func(breed string) (int, models.Animal) {
+		c, w := accountTestContext(admin.ID, true, http.MethodPost, "/?force=true", AnimalRequest{Name: "Rex " + breed, Species: "Dog", Breed: breed})
+		c.Params = gin.Params{{Key: "id", Value: itoa(group.ID)}}
+		CreateAnimal(db, nil, &embedding.StubEmbedder{})(c)
+		var animal models.Animal
+		_ = json.Unmarshal(w.Body.Bytes(), &animal)
+		return w.Code, animal
+	}
+	code, animal := create("lab")
+	require.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, "Labrador Retriever", animal.Breed)
+	code, _ = create("Beagle")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
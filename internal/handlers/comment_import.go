@@ -0,0 +1,413 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// commentImportColumns maps each column ImportAnimalCommentsCSV reads to the
+// header names it accepts. The comment export's names are accepted too, so
+// an export can be imported elsewhere.
+var commentImportColumns = map[string][]string{
+	"animal_id":   {"animal_id"},
+	"external_id": {"external_id"},
+	"group_id":    {"group_id"},
+	"content":     {"content", "comment_content"},
+	"author":      {"author", "comment_author"},
+	"created_at":  {"created_at"},
+	"tags":        {"tags", "comment_tags"},
+}
+
+// commentImportTimeLayouts are the created_at formats accepted, tried in
+// order. Layouts without an offset are read in the animal's group's time
+// zone.
+var commentImportTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"1/2/2006 15:04:05",
+	"1/2/2006 15:04",
+	"1/2/2006",
+}
+
+// commentImportBatchSize is how many comments are inserted per statement
+const commentImportBatchSize = 100
+
+// parseCommentImportTime parses value in one of commentImportTimeLayouts
+func parseCommentImportTime(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range commentImportTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid created_at %q (use RFC 3339, YYYY-MM-DD HH:MM, or YYYY-MM-DD)", value)
+}
+
+// commentImportLookups caches what ImportAnimalCommentsCSV looks up per row
+type commentImportLookups struct {
+	db       *gorm.DB
+	animals  map[string]*models.Animal // "id:<id>" or "ext:<group>/<external_id>"; nil when not found
+	groups   map[uint]*time.Location
+	authors  map[string]uint // lowercased author -> user ID; 0 when no account matches
+	tags     map[uint]map[string]models.CommentTag
+	existing map[string]bool // commentImportKey of comments already stored, per animal loaded
+	loaded   map[uint]bool   // animals whose existing comments are in existing
+}
+
+// commentImportKey identifies a comment for duplicate detection, so
+// importing the same file twice doesn't double up
+func commentImportKey(animalID uint, createdAt time.Time, content string) string {
+	return fmt.Sprintf("%d|%d|%s", animalID, createdAt.Unix(), content)
+}
+
+func (l *commentImportLookups) animal(key string, find func(*gorm.DB) *gorm.DB) (*models.Animal, error) {
+	if animal, ok := l.animals[key]; ok {
+		return animal, nil
+	}
+	var animal models.Animal
+	err := find(l.db.Select("id", "group_id", "name")).First(&animal).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		l.animals[key] = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	l.animals[key] = &animal
+	return &animal, nil
+}
+
+func (l *commentImportLookups) location(groupID uint) *time.Location {
+	if loc, ok := l.groups[groupID]; ok {
+		return loc
+	}
+	loc := groupLocationByID(l.db.Statement.Context, l.db, groupID)
+	l.groups[groupID] = loc
+	return loc
+}
+
+// author returns the ID of the user whose username or email is name, or 0
+func (l *commentImportLookups) author(name string) (uint, error) {
+	key := strings.ToLower(name)
+	if id, ok := l.authors[key]; ok {
+		return id, nil
+	}
+	var user models.User
+	err := l.db.Select("id").Where("LOWER(username) = ? OR LOWER(email) = ?", key, key).First(&user).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+	l.authors[key] = user.ID
+	return user.ID, nil
+}
+
+// tag returns the comment tag called name in groupID, matching
+// case-insensitively
+func (l *commentImportLookups) tag(groupID uint, name string) (models.CommentTag, bool, error) {
+	tags, ok := l.tags[groupID]
+	if !ok {
+		var rows []models.CommentTag
+		if err := l.db.Where("group_id = ?", groupID).Find(&rows).Error; err != nil {
+			return models.CommentTag{}, false, err
+		}
+		tags = make(map[string]models.CommentTag, len(rows))
+		for _, t := range rows {
+			tags[strings.ToLower(t.Name)] = t
+		}
+		l.tags[groupID] = tags
+	}
+	tag, found := tags[strings.ToLower(name)]
+	return tag, found, nil
+}
+
+// isDuplicate reports whether the comment is already stored, or earlier in
+// the file, and remembers it if not
+func (l *commentImportLookups) isDuplicate(animalID uint, createdAt time.Time, content string) (bool, error) {
+	if !l.loaded[animalID] {
+		var rows []models.AnimalComment
+		if err := l.db.Select("animal_id", "created_at", "content").Where("animal_id = ?", animalID).Find(&rows).Error; err != nil {
+			return false, err
+		}
+		for _, r := range rows {
+			l.existing[commentImportKey(r.AnimalID, r.CreatedAt, r.Content)] = true
+		}
+		l.loaded[animalID] = true
+	}
+	key := commentImportKey(animalID, createdAt, content)
+	if l.existing[key] {
+		return true, nil
+	}
+	l.existing[key] = true
+	return false, nil
+}
+
+// ImportAnimalCommentsCSV imports comments from a CSV file, for migrating
+// notes kept elsewhere (admin only). Rows name their animal by animal_id, or
+// by external_id with group_id. created_at is kept as the comment's time. An
+// author matching a user's username or email is credited to them; other
+// authors are kept as author_name on a comment owned by the importing admin.
+// Invalid rows and comments already present are skipped with a warning.
+// With ?dry_run=true the file is checked and nothing is saved.
+// Route: POST /api/admin/animals/import-comments-csv
+func ImportAnimalCommentsCSV(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		logger := middleware.GetLogger(c)
+		dryRun := c.Query("dry_run") == "true"
+
+		importerID, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+
+		file, err := c.FormFile("file")
+		if err != nil {
+			respondBadRequest(c, "No file uploaded")
+			return
+		}
+		if !strings.HasSuffix(strings.ToLower(file.Filename), ".csv") {
+			respondBadRequest(c, "File must be a CSV")
+			return
+		}
+		src, err := file.Open()
+		if err != nil {
+			logger.Error("Failed to open uploaded file", err)
+			respondInternalError(c, "Failed to process file")
+			return
+		}
+		defer src.Close()
+
+		reader := csv.NewReader(src)
+		reader.FieldsPerRecord = -1
+		header, err := reader.Read()
+		if err != nil {
+			respondBadRequest(c, "Failed to read CSV header")
+			return
+		}
+		columns := map[string]int{}
+		for i, h := range header {
+			name := strings.TrimSpace(strings.ToLower(strings.TrimPrefix(h, "\ufeff")))
+			for column, aliases := range commentImportColumns {
+				for _, alias := range aliases {
+					if name == alias {
+						if _, seen := columns[column]; !seen {
+							columns[column] = i
+						}
+					}
+				}
+			}
+		}
+		for _, required := range []string{"content", "created_at"} {
+			if _, ok := columns[required]; !ok {
+				respondBadRequest(c, "Missing required column: "+required)
+				return
+			}
+		}
+		_, hasAnimalID := columns["animal_id"]
+		_, hasExternalID := columns["external_id"]
+		_, hasGroupID := columns["group_id"]
+		if !hasAnimalID && !(hasExternalID && hasGroupID) {
+			respondBadRequest(c, "Missing required column: animal_id, or external_id and group_id")
+			return
+		}
+
+		lookups := &commentImportLookups{
+			db:       db,
+			animals:  map[string]*models.Animal{},
+			groups:   map[uint]*time.Location{},
+			authors:  map[string]uint{},
+			tags:     map[uint]map[string]models.CommentTag{},
+			existing: map[string]bool{},
+			loaded:   map[uint]bool{},
+		}
+		cell := func(record []string, column string) string {
+			if idx, ok := columns[column]; ok && idx < len(record) {
+				return strings.TrimSpace(record[idx])
+			}
+			return ""
+		}
+		failed := func(err error) {
+			logger.Error("Failed to check imported comments", err)
+			respondInternalError(c, "Failed to process file")
+		}
+
+		var comments []models.AnimalComment
+		var warnings []string
+		duplicates := 0
+		unmatched := map[string]int{}
+		now := time.Now()
+		lineNum := 1
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			lineNum++
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("Line %d: Failed to read row", lineNum))
+				continue
+			}
+
+			var animal *models.Animal
+			if id := cell(record, "animal_id"); id != "" {
+				animalID, err := strconv.ParseUint(id, 10, 32)
+				if err != nil {
+					warnings = append(warnings, fmt.Sprintf("Line %d: Invalid animal_id '%s'", lineNum, id))
+					continue
+				}
+				if animal, err = lookups.animal("id:"+id, func(q *gorm.DB) *gorm.DB { return q.Where("id = ?", animalID) }); err != nil {
+					failed(err)
+					return
+				}
+			} else if ext := cell(record, "external_id"); ext != "" {
+				groupID, err := strconv.ParseUint(cell(record, "group_id"), 10, 32)
+				if err != nil {
+					warnings = append(warnings, fmt.Sprintf("Line %d: external_id needs a valid group_id", lineNum))
+					continue
+				}
+				key := fmt.Sprintf("ext:%d/%s", groupID, ext)
+				if animal, err = lookups.animal(key, func(q *gorm.DB) *gorm.DB {
+					return q.Where("group_id = ? AND external_id = ?", groupID, ext)
+				}); err != nil {
+					failed(err)
+					return
+				}
+			} else {
+				warnings = append(warnings, fmt.Sprintf("Line %d: animal_id or external_id is required", lineNum))
+				continue
+			}
+			if animal == nil {
+				warnings = append(warnings, fmt.Sprintf("Line %d: Animal not found", lineNum))
+				continue
+			}
+
+			content := cell(record, "content")
+			if content == "" {
+				warnings = append(warnings, fmt.Sprintf("Line %d: content is required", lineNum))
+				continue
+			}
+			createdAt, err := parseCommentImportTime(cell(record, "created_at"), lookups.location(animal.GroupID))
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("Line %d: %s", lineNum, err.Error()))
+				continue
+			}
+			if createdAt.After(now) {
+				warnings = append(warnings, fmt.Sprintf("Line %d: created_at is in the future", lineNum))
+				continue
+			}
+			createdAt = createdAt.UTC()
+
+			duplicate, err := lookups.isDuplicate(animal.ID, createdAt, content)
+			if err != nil {
+				failed(err)
+				return
+			}
+			if duplicate {
+				duplicates++
+				warnings = append(warnings, fmt.Sprintf("Line %d: Already imported; skipped", lineNum))
+				continue
+			}
+
+			comment := models.AnimalComment{
+				AnimalID:  animal.ID,
+				UserID:    importerID,
+				Content:   content,
+				CreatedAt: createdAt,
+				UpdatedAt: createdAt,
+			}
+			if author := cell(record, "author"); author != "" {
+				userID, err := lookups.author(author)
+				if err != nil {
+					failed(err)
+					return
+				}
+				if userID != 0 {
+					comment.UserID = userID
+				} else {
+					comment.AuthorName = author
+					unmatched[author]++
+				}
+			}
+			for _, name := range strings.Split(cell(record, "tags"), ";") {
+				name = strings.TrimSpace(name)
+				if name == "" {
+					continue
+				}
+				tag, found, err := lookups.tag(animal.GroupID, name)
+				if err != nil {
+					failed(err)
+					return
+				}
+				if !found {
+					warnings = append(warnings, fmt.Sprintf("Line %d: Unknown comment tag '%s' for %s's group; imported without it", lineNum, name, animal.Name))
+					continue
+				}
+				comment.Tags = append(comment.Tags, tag)
+			}
+			comments = append(comments, comment)
+		}
+
+		if len(comments) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":  "No valid comments to import",
+				"errors": warnings,
+			})
+			return
+		}
+
+		unmatchedAuthors := make([]string, 0, len(unmatched))
+		for name := range unmatched {
+			unmatchedAuthors = append(unmatchedAuthors, name)
+		}
+		sort.Strings(unmatchedAuthors)
+
+		if !dryRun {
+			// New comments are embedded for search by the reconciliation
+			// sweep rather than one goroutine per row
+			if err := db.CreateInBatches(&comments, commentImportBatchSize).Error; err != nil {
+				logger.Error("Failed to import comments", err)
+				respondInternalError(c, "Failed to import comments")
+				return
+			}
+		}
+
+		logger.WithFields(map[string]interface{}{
+			"dry_run":           dryRun,
+			"count":             len(comments),
+			"duplicates":        duplicates,
+			"unmatched_authors": len(unmatchedAuthors),
+			"warnings":          len(warnings),
+		}).Info("Imported animal comments from CSV")
+
+		message := fmt.Sprintf("Successfully imported %d comments", len(comments))
+		if dryRun {
+			message = fmt.Sprintf("%d comments ready to import", len(comments))
+		}
+		response := gin.H{
+			"message":           message,
+			"dry_run":           dryRun,
+			"count":             len(comments),
+			"duplicates":        duplicates,
+			"unmatched_authors": unmatchedAuthors,
+		}
+		if len(warnings) > 0 {
+			response["warnings"] = warnings
+		}
+		respondOK(c, response)
+	}
+}
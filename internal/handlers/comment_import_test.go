@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type commentImportResult struct {
+	Count            int      `json:"count"`
+	DryRun           bool     `json:"dry_run"`
+	Duplicates       int      `json:"duplicates"`
+	UnmatchedAuthors []string `json:"unmatched_authors"`
+	Warnings         []string `json:"warnings"`
+}
+
+func importCommentsCSVForTest(t *testing.T, db *gorm.DB, userID uint, query, csvContent string) (int, commentImportResult) {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "walk-notes.csv")
+	require.NoError(t, err)
+	_, _ = part.Write([]byte(csvContent))
+	require.NoError(t, writer.Close())
+
+	c, w := setupAnimalTestContext(userID, true)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/admin/animals/import-comments-csv"+query, body)
+	c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+	ImportAnimalCommentsCSV(db)(c)
+	var result commentImportResult
+	_ = json.Unmarshal(w.Body.Bytes(), &result)
+	return w.Code, result
+}
+
+func TestImportAnimalCommentsCSV(t *testing.T) {
+	t.Setenv("DEFAULT_TIME_ZONE", "UTC")
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	walker := CreateTestUser(t, db, "walker", "walker@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	require.NoError(t, db.Model(group).Update("time_zone", "America/Chicago").Error)
+	rocky := CreateTestAnimal(t, db, group.ID, "Rocky", "Dog")
+	luna := CreateTestAnimal(t, db, group.ID, "Luna", "Dog")
+	require.NoError(t, db.Model(luna).Update("external_id", "A-1001").Error)
+	tag := models.CommentTag{GroupID: group.ID, Name: "Behavior"}
+	require.NoError(t, db.Create(&tag).Error)
+
+	csvContent := "animal_id,external_id,group_id,content,author,created_at,tags\n" +
+		itoa(rocky.ID) + ",,,Pulled on leash,Walker,2019-06-01T08:30:00-05:00,behavior\n" +
+		",A-1001," + itoa(group.ID) + ",Loves the creek,Pat (2019 volunteer),2019-06-02 17:00,Shy; Behavior\n" +
+		"999999,,,Unknown animal,Pat,2019-06-03,\n" +
+		itoa(rocky.ID) + ",,,,Pat,2019-06-03,\n" +
+		itoa(rocky.ID) + ",,,Next year's note,Pat,2099-01-01,\n" +
+		itoa(rocky.ID) + ",,,Bad date,Pat,June 3rd,\n"
+
+	// A dry run checks the file without saving anything
+	code, result := importCommentsCSVForTest(t, db, admin.ID, "?dry_run=true", csvContent)
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 2, result.Count)
+	assert.Equal(t, []string{"Pat (2019 volunteer)"}, result.UnmatchedAuthors)
+	assert.Len(t, result.Warnings, 5, result.Warnings)
+	var stored int64
+	db.Model(&models.AnimalComment{}).Count(&stored)
+	assert.Zero(t, stored)
+
+	code, result = importCommentsCSVForTest(t, db, admin.ID, "", csvContent)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 2, result.Count)
+
+	var comments []models.AnimalComment
+	require.NoError(t, db.Preload("Tags").Order("created_at").Find(&comments).Error)
+	require.Len(t, comments, 2)
+	assert.Equal(t, walker.ID, comments[0].UserID, "authors with accounts are credited")
+	assert.Empty(t, comments[0].AuthorName)
+	assert.True(t, comments[0].CreatedAt.Equal(time.Date(2019, 6, 1, 13, 30, 0, 0, time.UTC)), comments[0].CreatedAt)
+	require.Len(t, comments[0].Tags, 1)
+	assert.Equal(t, tag.ID, comments[0].Tags[0].ID)
+
+	assert.Equal(t, luna.ID, comments[1].AnimalID, "matched by external_id")
+	assert.Equal(t, admin.ID, comments[1].UserID)
+	assert.Equal(t, "Pat (2019 volunteer)", comments[1].AuthorName)
+	assert.True(t, comments[1].CreatedAt.Equal(time.Date(2019, 6, 2, 22, 0, 0, 0, time.UTC)), "times without an offset are in the group's zone")
+	assert.Equal(t, "Pat (2019 volunteer)", commentAuthor(comments[1]))
+
+	// Importing the same file again doesn't duplicate anything
+	code, result = importCommentsCSVForTest(t, db, admin.ID, "", csvContent)
+	assert.Equal(t, http.StatusBadRequest, code)
+	db.Model(&models.AnimalComment{}).Count(&stored)
+	assert.Equal(t, int64(2), stored)
+
+	code, _ = importCommentsCSVForTest(t, db, admin.ID, "", "animal_id,content\n1,Hi\n")
+	assert.Equal(t, http.StatusBadRequest, code, "created_at is required")
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// maxPinnedComments bounds the pinned comments per animal, so pinning
+// stays reserved for notes like allergies and handling warnings
+const maxPinnedComments = 3
+
+// commentOrder is the ORDER BY for an animal's comments: pinned comments
+// first, newest pin first, then the rest by created_at in sortOrder. The id
+// tie-breaks keep it in step with GetAnimalCommentPosition.
+func commentOrder(sortOrder string) string {
+	return "animal_comments.pinned DESC, animal_comments.pinned_at DESC, " +
+		"animal_comments.created_at " + sortOrder + ", animal_comments.id " + sortOrder
+}
+
+// commentsBefore scopes a query to the comments that sort before target
+// under commentOrder
+func commentsBefore(query *gorm.DB, target models.AnimalComment, sortOrder string) *gorm.DB {
+	cmp := ">"
+	if sortOrder == "ASC" {
+		cmp = "<"
+	}
+	sameTier := fmt.Sprintf("(animal_comments.created_at %[1]s ? OR (animal_comments.created_at = ? AND animal_comments.id %[1]s ?))", cmp)
+	if !target.Pinned || target.PinnedAt == nil {
+		return query.Where("(animal_comments.pinned = ? OR (animal_comments.pinned = ? AND "+sameTier+"))",
+			true, false, target.CreatedAt, target.CreatedAt, target.ID)
+	}
+	return query.Where("animal_comments.pinned = ? AND (animal_comments.pinned_at > ? OR (animal_comments.pinned_at = ? AND "+sameTier+"))",
+		true, *target.PinnedAt, *target.PinnedAt, target.CreatedAt, target.CreatedAt, target.ID)
+}
+
+// pinnedComments returns an animal's pinned comments, newest pin first
+func pinnedComments(db *gorm.DB, animalID uint) ([]models.AnimalComment, error) {
+	comments := []models.AnimalComment{}
+	err := db.Preload("User").Preload("Tags").
+		Where("animal_id = ? AND pinned = ?", animalID, true).
+		Order(commentOrder("DESC")).Find(&comments).Error
+	return comments, err
+}
+
+// setCommentPinned pins or unpins a comment on an animal (group admin or
+// site admin)
+func setCommentPinned(db *gorm.DB, pin bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		animalID := c.Param("animalId")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
+			return
+		}
+
+		var animal models.Animal
+		if err := db.Where("id = ? AND group_id = ?", animalID, groupID).First(&animal).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeAnimalNotFound, "Animal not found")
+			return
+		}
+		var comment models.AnimalComment
+		if err := db.Where("id = ? AND animal_id = ?", c.Param("commentId"), animal.ID).First(&comment).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeNotFound, "Comment not found")
+			return
+		}
+
+		if comment.Pinned != pin {
+			updates := map[string]interface{}{"pinned": false, "pinned_at": nil, "pinned_by_id": nil}
+			if pin {
+				var pinned int64
+				if err := db.Model(&models.AnimalComment{}).Where("animal_id = ? AND pinned = ?", animal.ID, true).Count(&pinned).Error; err != nil {
+					respondInternalError(c, "Failed to pin comment")
+					return
+				}
+				if pinned >= maxPinnedComments {
+					respondError(c, http.StatusConflict, ErrCodeConflict,
+						fmt.Sprintf("An animal can have at most %d pinned comments; unpin one first", maxPinnedComments))
+					return
+				}
+				uid, _ := middleware.GetUserID(c)
+				updates = map[string]interface{}{"pinned": true, "pinned_at": time.Now(), "pinned_by_id": uid}
+			}
+			// UpdateColumns leaves updated_at alone; pinning isn't an edit
+			if err := db.Model(&comment).UpdateColumns(updates).Error; err != nil {
+				middleware.GetLogger(c).Error("Failed to update comment pin", err)
+				respondInternalError(c, "Failed to update comment")
+				return
+			}
+		}
+
+		if err := db.Preload("User").Preload("Tags").First(&comment, comment.ID).Error; err != nil {
+			respondInternalError(c, "Failed to load comment")
+			return
+		}
+		respondOK(c, comment)
+	}
+}
+
+// PinAnimalComment pins a comment so it's listed first on the animal
+// (group admin or site admin). An animal can have up to maxPinnedComments.
+// Route: PUT /api/groups/:id/animals/:animalId/comments/:commentId/pin
+func PinAnimalComment(db *gorm.DB) gin.HandlerFunc {
+	return setCommentPinned(db, true)
+}
+
+// UnpinAnimalComment unpins a comment (group admin or site admin)
+// Route: DELETE /api/groups/:id/animals/:animalId/comments/:commentId/pin
+func UnpinAnimalComment(db *gorm.DB) gin.HandlerFunc {
+	return setCommentPinned(db, false)
+}
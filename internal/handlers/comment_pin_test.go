@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommentPinning(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupAnimalCommentTestDB(t)
+	defer func() {
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	}()
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	comments := make([]models.AnimalComment, 5)
+	for i := range comments {
+		comments[i] = models.AnimalComment{AnimalID: 1, UserID: 1, Content: fmt.Sprintf("comment %d", i), CreatedAt: base.Add(time.Duration(i) * time.Hour)}
+		require.NoError(t, db.Create(&comments[i]).Error)
+	}
+
+	setPin := func(handler func() gin.HandlerFunc, isAdmin bool, commentID uint) (int, models.AnimalComment) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPut, "/", nil)
+		c.Set("user_id", uint(1))
+		c.Set("is_admin", isAdmin)
+		c.Params = gin.Params{
+			{Key: "id", Value: "1"},
+			{Key: "animalId", Value: "1"},
+			{Key: "commentId", Value: fmt.Sprintf("%d", commentID)},
+		}
+		handler()(c)
+		var comment models.AnimalComment
+		_ = json.Unmarshal(w.Body.Bytes(), &comment)
+		return w.Code, comment
+	}
+	pin := func() gin.HandlerFunc { return PinAnimalComment(db) }
+	unpin := func() gin.HandlerFunc { return UnpinAnimalComment(db) }
+
+	listComments := func(query string) []models.AnimalComment {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/groups/1/animals/1/comments"+query, nil)
+		c.Set("user_id", uint(1))
+		c.Set("is_admin", false)
+		c.Params = gin.Params{{Key: "id", Value: "1"}, {Key: "animalId", Value: "1"}}
+		GetAnimalComments(db)(c)
+		require.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Comments []models.AnimalComment `json:"comments"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return body.Comments
+	}
+
+	t.Run("members can't pin", func(t *testing.T) {
+		status, _ := setPin(pin, false, comments[0].ID)
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("pinned comments are listed first", func(t *testing.T) {
+		status, pinned := setPin(pin, true, comments[0].ID)
+		require.Equal(t, http.StatusOK, status)
+		assert.True(t, pinned.Pinned)
+		assert.NotNil(t, pinned.PinnedAt)
+
+		for _, order := range []string{"", "?order=asc"} {
+			listed := listComments(order)
+			require.Len(t, listed, 5)
+			assert.Equal(t, comments[0].ID, listed[0].ID)
+			assert.True(t, listed[0].Pinned)
+			assert.False(t, listed[1].Pinned)
+		}
+		// The rest keep their order
+		assert.Equal(t, comments[4].ID, listComments("")[1].ID)
+	})
+
+	t.Run("positions account for pinned comments", func(t *testing.T) {
+		position := func(commentID uint) float64 {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			c.Set("user_id", uint(1))
+			c.Set("is_admin", false)
+			c.Params = gin.Params{{Key: "id", Value: "1"}, {Key: "animalId", Value: "1"}, {Key: "commentId", Value: fmt.Sprintf("%d", commentID)}}
+			GetAnimalCommentPosition(db)(c)
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			return body["offset"].(float64)
+		}
+		assert.Equal(t, float64(0), position(comments[0].ID))
+		assert.Equal(t, float64(1), position(comments[4].ID))
+		assert.Equal(t, float64(4), position(comments[1].ID))
+	})
+
+	t.Run("pins are limited per animal", func(t *testing.T) {
+		for _, comment := range comments[1:maxPinnedComments] {
+			status, _ := setPin(pin, true, comment.ID)
+			require.Equal(t, http.StatusOK, status)
+		}
+		status, _ := setPin(pin, true, comments[4].ID)
+		assert.Equal(t, http.StatusConflict, status)
+
+		// Re-pinning a pinned comment is a no-op, not a conflict
+		status, _ = setPin(pin, true, comments[0].ID)
+		assert.Equal(t, http.StatusOK, status)
+	})
+
+	t.Run("unpinning frees a slot", func(t *testing.T) {
+		status, unpinned := setPin(unpin, true, comments[0].ID)
+		require.Equal(t, http.StatusOK, status)
+		assert.False(t, unpinned.Pinned)
+		assert.Nil(t, unpinned.PinnedAt)
+
+		status, _ = setPin(pin, true, comments[4].ID)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, comments[4].ID, listComments("")[0].ID, "the newest pin comes first")
+	})
+
+	t.Run("pinned comments are returned with the animal", func(t *testing.T) {
+		pinned, err := pinnedComments(db, 1)
+		require.NoError(t, err)
+		require.Len(t, pinned, maxPinnedComments)
+		assert.Equal(t, comments[4].ID, pinned[0].ID)
+	})
+}
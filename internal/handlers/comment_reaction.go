@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// reactionTypes lists the reaction types in display order
+var reactionTypes = []string{models.ReactionThumbsUp, models.ReactionHeart}
+
+var reactionEmoji = map[string]string{
+	models.ReactionThumbsUp: "👍",
+	models.ReactionHeart:    "❤️",
+}
+
+// JobCommentReactionEmail is the background job type that tells a comment's
+// author about the first reaction to their comment.
+const JobCommentReactionEmail = "comment_reaction_email"
+
+// commentReactionEmailJob is the payload of a JobCommentReactionEmail job.
+type commentReactionEmailJob struct {
+	CommentID uint   `json:"comment_id"`
+	ReactorID uint   `json:"reactor_id"`
+	Type      string `json:"type"`
+}
+
+// CommentReactionRequest is the body of a reaction POST
+type CommentReactionRequest struct {
+	Type string `json:"type" binding:"required"`
+}
+
+// commentReactionCounts returns the reaction counts for each of commentIDs,
+// in reactionTypes order. Comments without reactions are left out.
+func commentReactionCounts(db *gorm.DB, commentIDs []uint, viewerID uint) (map[uint][]models.ReactionCount, error) {
+	counts := make(map[uint][]models.ReactionCount, len(commentIDs))
+	if len(commentIDs) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		CommentID uint
+		Type      string
+		Count     int64
+		Reacted   int64
+	}
+	if err := db.Model(&models.CommentReaction{}).
+		Select("comment_id, type, COUNT(*) AS count, SUM(CASE WHEN user_id = ? THEN 1 ELSE 0 END) AS reacted", viewerID).
+		Where("comment_id IN ?", commentIDs).
+		Group("comment_id, type").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	byType := make(map[uint]map[string]models.ReactionCount)
+	for _, row := range rows {
+		if byType[row.CommentID] == nil {
+			byType[row.CommentID] = make(map[string]models.ReactionCount)
+		}
+		byType[row.CommentID][row.Type] = models.ReactionCount{Type: row.Type, Count: row.Count, Reacted: row.Reacted > 0}
+	}
+	for commentID, types := range byType {
+		for _, t := range reactionTypes {
+			if rc, ok := types[t]; ok {
+				counts[commentID] = append(counts[commentID], rc)
+			}
+		}
+	}
+	return counts, nil
+}
+
+// attachReactionCounts fills in Reactions on each comment
+func attachReactionCounts(db *gorm.DB, comments []models.AnimalComment, viewerID uint) error {
+	ids := make([]uint, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.ID
+	}
+	counts, err := commentReactionCounts(db, ids, viewerID)
+	if err != nil {
+		return err
+	}
+	for i := range comments {
+		comments[i].Reactions = counts[comments[i].ID]
+	}
+	return nil
+}
+
+// loadReactableComment loads the comment named by the route, checking the
+// caller can see its group. It responds and returns false on failure.
+func loadReactableComment(c *gin.Context, db *gorm.DB) (models.AnimalComment, bool) {
+	var comment models.AnimalComment
+	userID, _ := c.Get("user_id")
+	isAdmin, _ := c.Get("is_admin")
+	if !checkGroupAccess(db, userID, isAdmin, c.Param("id")) {
+		respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+		return comment, false
+	}
+	query := models.NonDeletedAnimalCommentsQuery(db).
+		Where("animal_comments.id = ? AND animal_comments.animal_id = ? AND animals.group_id = ?",
+			c.Param("commentId"), c.Param("animalId"), c.Param("id"))
+	err := visibleAnimals(c, db, query, c.Param("id")).First(&comment).Error
+	if err != nil {
+		respondNotFound(c, "Comment not found")
+		return comment, false
+	}
+	return comment, true
+}
+
+// respondReactionCounts responds with the comment's current reaction counts
+func respondReactionCounts(c *gin.Context, db *gorm.DB, commentID, viewerID uint) {
+	counts, err := commentReactionCounts(db, []uint{commentID}, viewerID)
+	if err != nil {
+		middleware.GetLogger(c).Error("Failed to count reactions", err)
+		respondInternalError(c, "Failed to load reactions")
+		return
+	}
+	reactions := counts[commentID]
+	if reactions == nil {
+		reactions = []models.ReactionCount{}
+	}
+	respondOK(c, gin.H{"comment_id": commentID, "reactions": reactions})
+}
+
+// AddCommentReaction adds the caller's reaction to a comment. Reacting twice
+// with the same type is a no-op. The comment's first reaction queues an
+// email to its author, if they've opted in.
+// Route: POST /api/groups/:id/animals/:animalId/comments/:commentId/reactions
+func AddCommentReaction(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		var req CommentReactionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		if _, ok := reactionEmoji[req.Type]; !ok {
+			respondBadRequest(c, "type must be thumbs_up or heart")
+			return
+		}
+		comment, ok := loadReactableComment(c, db)
+		if !ok {
+			return
+		}
+		userID, _ := middleware.GetUserID(c)
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.CommentReaction{
+				CommentID: comment.ID,
+				UserID:    userID,
+				Type:      req.Type,
+			})
+			if result.Error != nil || result.RowsAffected == 0 || comment.UserID == userID {
+				return result.Error
+			}
+			// Only the first reaction from someone other than the author notifies
+			var total int64
+			if err := tx.Model(&models.CommentReaction{}).
+				Where("comment_id = ? AND user_id <> ?", comment.ID, comment.UserID).
+				Count(&total).Error; err != nil {
+				return err
+			}
+			if total > 1 {
+				return nil
+			}
+			_, err := jobs.Enqueue(tx, JobCommentReactionEmail, commentReactionEmailJob{
+				CommentID: comment.ID,
+				ReactorID: userID,
+				Type:      req.Type,
+			})
+			return err
+		})
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to add reaction", err)
+			respondInternalError(c, "Failed to add reaction")
+			return
+		}
+		respondReactionCounts(c, db, comment.ID, userID)
+	}
+}
+
+// RemoveCommentReaction removes the caller's reaction of one type from a
+// comment. Removing a reaction that isn't there is a no-op.
+// Route: DELETE /api/groups/:id/animals/:animalId/comments/:commentId/reactions/:type
+func RemoveCommentReaction(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		reactionType := c.Param("type")
+		if _, ok := reactionEmoji[reactionType]; !ok {
+			respondBadRequest(c, "type must be thumbs_up or heart")
+			return
+		}
+		comment, ok := loadReactableComment(c, db)
+		if !ok {
+			return
+		}
+		userID, _ := middleware.GetUserID(c)
+
+		if err := db.Where("comment_id = ? AND user_id = ? AND type = ?", comment.ID, userID, reactionType).
+			Delete(&models.CommentReaction{}).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to remove reaction", err)
+			respondInternalError(c, "Failed to remove reaction")
+			return
+		}
+		respondReactionCounts(c, db, comment.ID, userID)
+	}
+}
+
+// commentReactionEmailJobHandler sends a JobCommentReactionEmail. Nothing is
+// sent if the comment is gone, or its author has opted out or been deleted.
+func commentReactionEmailJobHandler(db *gorm.DB, emailService *email.Service) jobs.Handler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var job commentReactionEmailJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return jobs.Permanent(err)
+		}
+		if emailService == nil || !emailService.IsConfigured() {
+			return errors.New("email service is not configured")
+		}
+		db := db.WithContext(ctx)
+
+		var comment models.AnimalComment
+		var animal models.Animal
+		var author, reactor models.User
+		for _, load := range []func() error{
+			func() error { return db.First(&comment, job.CommentID).Error },
+			func() error { return db.First(&animal, comment.AnimalID).Error },
+			func() error { return notifiableUsers(db).First(&author, comment.UserID).Error },
+			func() error { return db.First(&reactor, job.ReactorID).Error },
+		} {
+			if err := load(); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil
+				}
+				return err
+			}
+		}
+
+		link := fmt.Sprintf("%s/groups/%d/animals/%d/view", frontendURL(), animal.GroupID, animal.ID)
+		ctx = groupSenderContext(ctx, db, animal.GroupID)
+		return emailService.SendCommentReactionEmail(ctx, author.Email, reactor.Username, reactionEmoji[job.Type], animal.Name, link)
+	}
+}
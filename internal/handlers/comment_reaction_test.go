@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommentReactions(t *testing.T) {
+	db := SetupTestDB(t)
+	author := CreateTestUser(t, db, "author", "author@example.com", "password123", false)
+	reactor := CreateTestUser(t, db, "reactor", "reactor@example.com", "password123", false)
+	outsider := CreateTestUser(t, db, "outsider", "outsider@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	AddUserToGroupWithAdmin(t, db, author.ID, group.ID, false)
+	AddUserToGroupWithAdmin(t, db, reactor.ID, group.ID, false)
+	require.NoError(t, db.Model(author).Update("email_notifications_enabled", true).Error)
+	animal := models.Animal{GroupID: group.ID, Name: "Rex", Status: "available"}
+	require.NoError(t, db.Create(&animal).Error)
+	comment := models.AnimalComment{AnimalID: animal.ID, UserID: author.ID, Content: "Good walk"}
+	require.NoError(t, db.Create(&comment).Error)
+	base := fmt.Sprintf("/api/groups/%d/animals/%d/comments/%d/reactions", group.ID, animal.ID, comment.ID)
+	params := gin.Params{
+		{Key: "id", Value: fmt.Sprint(group.ID)},
+		{Key: "animalId", Value: fmt.Sprint(animal.ID)},
+		{Key: "commentId", Value: fmt.Sprint(comment.ID)},
+	}
+
+	react := func(userID uint, reactionType string) (int, []models.ReactionCount) {
+		c, w := accountTestContext(userID, false, http.MethodPost, base, map[string]string{"type": reactionType})
+		c.Params = params
+		AddCommentReaction(db)(c)
+		var resp struct {
+			Reactions []models.ReactionCount `json:"reactions"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp.Reactions
+	}
+
+	code, _ := react(reactor.ID, "party")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = react(outsider.ID, models.ReactionHeart)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	// The author's own reaction doesn't notify them
+	code, _ = react(author.ID, models.ReactionHeart)
+	require.Equal(t, http.StatusOK, code)
+	code, reactions := react(reactor.ID, models.ReactionHeart)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []models.ReactionCount{{Type: models.ReactionHeart, Count: 2, Reacted: true}}, reactions)
+
+	// Reacting again is a no-op, and later reactions don't notify again
+	react(reactor.ID, models.ReactionHeart)
+	code, reactions = react(reactor.ID, models.ReactionThumbsUp)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []models.ReactionCount{
+		{Type: models.ReactionThumbsUp, Count: 1, Reacted: true},
+		{Type: models.ReactionHeart, Count: 2, Reacted: true},
+	}, reactions)
+
+	var queued []models.Job
+	require.NoError(t, db.Where("type = ?", JobCommentReactionEmail).Find(&queued).Error)
+	require.Len(t, queued, 1)
+
+	provider := &recordingEmailProvider{}
+	queue := jobs.NewQueue(db)
+	RegisterJobHandlers(queue, db, email.NewServiceWithProvider(provider, db), nil)
+	assert.Equal(t, 1, queue.RunDue(context.Background()))
+	assert.Equal(t, []string{"author@example.com"}, provider.sentTo)
+
+	// Counts show up on the comment list, marked for the viewer
+	c, w := accountTestContext(author.ID, false, http.MethodGet, "/", nil)
+	c.Params = params[:2]
+	GetAnimalComments(db)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Comments []models.AnimalComment `json:"comments"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Comments, 1)
+	assert.Equal(t, []models.ReactionCount{
+		{Type: models.ReactionThumbsUp, Count: 1, Reacted: false},
+		{Type: models.ReactionHeart, Count: 2, Reacted: true},
+	}, list.Comments[0].Reactions)
+
+	c, w = accountTestContext(reactor.ID, false, http.MethodDelete, base+"/heart", nil)
+	c.Params = append(params, gin.Param{Key: "type", Value: models.ReactionHeart})
+	RemoveCommentReaction(db)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Reactions []models.ReactionCount `json:"reactions"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []models.ReactionCount{
+		{Type: models.ReactionThumbsUp, Count: 1, Reacted: true},
+		{Type: models.ReactionHeart, Count: 1, Reacted: false},
+	}, resp.Reactions)
+}
@@ -0,0 +1,377 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/database"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// maxCommentSearchQueryLength bounds q on comment search
+const maxCommentSearchQueryLength = 200
+
+// commentSnippetRunes is roughly how much of a comment the SQLite fallback
+// shows around the first match; Postgres' ts_headline is told to aim for a
+// similar length via commentHeadlineOptions.
+const commentSnippetRunes = 240
+
+// ts_headline marks matches with control characters no one types into a
+// comment, which parseHeadline then turns into highlighted segments. Plain
+// text segments, rather than HTML <b> tags, mean clients never have to
+// render comment text as markup.
+const (
+	headlineStartSel = "\x02"
+	headlineStopSel  = "\x03"
+)
+
+var commentHeadlineOptions = "StartSel=" + headlineStartSel + ", StopSel=" + headlineStopSel +
+	", MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=\" … \""
+
+// SnippetSegment is a run of snippet text, highlighted when it matched the
+// search
+type SnippetSegment struct {
+	Text      string `json:"text"`
+	Highlight bool   `json:"highlight,omitempty"`
+}
+
+// CommentSearchHit is one comment matching a group comment search
+type CommentSearchHit struct {
+	CommentID  uint             `json:"comment_id"`
+	AnimalID   uint             `json:"animal_id"`
+	AnimalName string           `json:"animal_name"`
+	UserID     uint             `json:"user_id"`
+	Username   string           `json:"username"`
+	CreatedAt  time.Time        `json:"created_at"`
+	Tags       []string         `json:"tags"`
+	Snippet    []SnippetSegment `json:"snippet"`
+}
+
+// commentSearchRow is what the search query scans per comment. Headline is
+// only filled on Postgres.
+type commentSearchRow struct {
+	ID         uint
+	AnimalID   uint
+	AnimalName string
+	UserID     uint
+	Username   string
+	CreatedAt  time.Time
+	Content    string
+	Headline   string
+}
+
+// SearchGroupComments searches the comments on a group's animals, newest
+// first, with a highlighted snippet of each match. q uses Postgres'
+// websearch syntax ("quoted phrases", -excluded words) and matches word
+// stems, so "vaccinate" finds "vaccinated"; on SQLite every word of q must
+// appear in the comment instead. Optional filters: animal_id, author
+// (username, case-insensitive), tag (comma-separated comment tag names, any
+// of which may match), and from/to (YYYY-MM-DD, inclusive). Comments on
+// animals the user can't see because of restricted tags are left out.
+// Route: GET /api/groups/:id/comments/search
+func SearchGroupComments(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+
+		q := strings.TrimSpace(c.Query("q"))
+		if q == "" {
+			respondBadRequest(c, "q parameter is required")
+			return
+		}
+		if len(q) > maxCommentSearchQueryLength {
+			respondBadRequest(c, "q must be at most "+strconv.Itoa(maxCommentSearchQueryLength)+" characters")
+			return
+		}
+
+		limit := 20
+		if limitParam := c.Query("limit"); limitParam != "" {
+			if parsed, err := strconv.Atoi(limitParam); err == nil && parsed > 0 {
+				limit = parsed
+				if limit > 100 {
+					limit = 100
+				}
+			}
+		}
+		offset := 0
+		if offsetParam := c.Query("offset"); offsetParam != "" {
+			if parsed, err := strconv.Atoi(offsetParam); err == nil && parsed >= 0 {
+				offset = parsed
+			}
+		}
+
+		dialect := database.DialectOf(db)
+		query := models.NonDeletedAnimalCommentsQuery(db).
+			Joins("LEFT JOIN users ON users.id = animal_comments.user_id").
+			Where("animals.group_id = ?", groupID)
+		query = visibleAnimals(c, db, query, groupID)
+
+		terms := commentSearchTerms(q)
+		if dialect.FullText() {
+			query = query.Where("animal_comments.search_vector @@ websearch_to_tsquery('english', ?)", q)
+		} else {
+			if len(terms) == 0 {
+				respondBadRequest(c, "q must contain at least one word")
+				return
+			}
+			for _, term := range terms {
+				query = query.Where(dialect.ContainsFold("animal_comments.content", term))
+			}
+		}
+
+		if v := c.Query("animal_id"); v != "" {
+			animalID, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				respondBadRequest(c, "animal_id must be a number")
+				return
+			}
+			query = query.Where("animal_comments.animal_id = ?", animalID)
+		}
+		if author := strings.TrimSpace(c.Query("author")); author != "" {
+			query = query.Where("LOWER(users.username) = ?", strings.ToLower(author))
+		}
+		if tags := splitAndTrim(c.Query("tag")); len(tags) > 0 {
+			query = query.Where(`EXISTS (
+				SELECT 1 FROM animal_comment_tags act
+				JOIN comment_tags ct ON ct.id = act.comment_tag_id
+				WHERE act.animal_comment_id = animal_comments.id AND ct.name IN ?)`, tags)
+		}
+		if v := c.Query("from"); v != "" {
+			from, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				respondBadRequest(c, "from must be a date in YYYY-MM-DD format")
+				return
+			}
+			query = query.Where("animal_comments.created_at >= ?", from)
+		}
+		if v := c.Query("to"); v != "" {
+			to, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				respondBadRequest(c, "to must be a date in YYYY-MM-DD format")
+				return
+			}
+			query = query.Where("animal_comments.created_at < ?", to.AddDate(0, 0, 1))
+		}
+
+		var total int64
+		if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+			respondInternalError(c, "Failed to count matching comments")
+			return
+		}
+
+		selectColumns := "animal_comments.id, animal_comments.animal_id, animals.name AS animal_name, " +
+			"animal_comments.user_id, users.username, animal_comments.created_at, animal_comments.content"
+		page := query.Session(&gorm.Session{})
+		if dialect.FullText() {
+			// ts_headline re-parses each comment, so it only runs on the page
+			// being returned, never on every match
+			page = page.Select(selectColumns+", ts_headline('english', animal_comments.content, websearch_to_tsquery('english', ?), ?) AS headline",
+				q, commentHeadlineOptions)
+		} else {
+			page = page.Select(selectColumns)
+		}
+		// See GetAnimalComments for why the id tie-break is required
+		var rows []commentSearchRow
+		if err := page.Order("animal_comments.created_at DESC, animal_comments.id DESC").
+			Limit(limit).Offset(offset).Scan(&rows).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to search comments", err)
+			respondInternalError(c, "Failed to search comments")
+			return
+		}
+
+		tagsByComment, err := commentTagNames(db, rows)
+		if err != nil {
+			respondInternalError(c, "Failed to search comments")
+			return
+		}
+
+		hits := make([]CommentSearchHit, 0, len(rows))
+		for _, row := range rows {
+			snippet := parseHeadline(row.Headline)
+			if !dialect.FullText() {
+				snippet = highlightSnippet(row.Content, terms)
+			}
+			tags := tagsByComment[row.ID]
+			if tags == nil {
+				tags = []string{}
+			}
+			hits = append(hits, CommentSearchHit{
+				CommentID:  row.ID,
+				AnimalID:   row.AnimalID,
+				AnimalName: row.AnimalName,
+				UserID:     row.UserID,
+				Username:   row.Username,
+				CreatedAt:  row.CreatedAt,
+				Tags:       tags,
+				Snippet:    snippet,
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"results": hits,
+			"total":   total,
+			"limit":   limit,
+			"offset":  offset,
+			"hasMore": offset+len(hits) < int(total),
+		})
+	}
+}
+
+// commentTagNames returns the tag names on each of rows' comments
+func commentTagNames(db *gorm.DB, rows []commentSearchRow) (map[uint][]string, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	var links []struct {
+		AnimalCommentID uint
+		Name            string
+	}
+	if err := db.Table("animal_comment_tags").
+		Select("animal_comment_tags.animal_comment_id, comment_tags.name").
+		Joins("JOIN comment_tags ON comment_tags.id = animal_comment_tags.comment_tag_id").
+		Where("animal_comment_tags.animal_comment_id IN ?", ids).
+		Order("comment_tags.name").
+		Scan(&links).Error; err != nil {
+		return nil, err
+	}
+	names := make(map[uint][]string, len(rows))
+	for _, link := range links {
+		names[link.AnimalCommentID] = append(names[link.AnimalCommentID], link.Name)
+	}
+	return names, nil
+}
+
+// commentSearchTerms splits q into the words the SQLite fallback matches,
+// dropping websearch syntax it doesn't understand (quotes, a leading - or
+// the word "or")
+func commentSearchTerms(q string) []string {
+	var terms []string
+	for _, word := range strings.Fields(q) {
+		word = strings.Trim(word, `"`)
+		if word == "" || strings.HasPrefix(word, "-") || strings.EqualFold(word, "or") {
+			continue
+		}
+		terms = append(terms, word)
+	}
+	return terms
+}
+
+// parseHeadline splits a ts_headline result into segments at its
+// StartSel/StopSel markers
+func parseHeadline(headline string) []SnippetSegment {
+	segments := []SnippetSegment{}
+	for headline != "" {
+		start := strings.Index(headline, headlineStartSel)
+		if start < 0 {
+			segments = append(segments, SnippetSegment{Text: headline})
+			break
+		}
+		if start > 0 {
+			segments = append(segments, SnippetSegment{Text: headline[:start]})
+		}
+		headline = headline[start+len(headlineStartSel):]
+		stop := strings.Index(headline, headlineStopSel)
+		if stop < 0 {
+			stop = len(headline)
+		}
+		if stop > 0 {
+			segments = append(segments, SnippetSegment{Text: headline[:stop], Highlight: true})
+		}
+		headline = strings.TrimPrefix(headline[stop:], headlineStopSel)
+	}
+	return segments
+}
+
+// highlightSnippet cuts a window of content around the first match of any
+// term and highlights every case-insensitive occurrence of the terms in it.
+// It stands in for ts_headline where Postgres full-text search isn't
+// available.
+func highlightSnippet(content string, terms []string) []SnippetSegment {
+	text := []rune(content)
+	// unicode.ToLower maps rune to rune, so indexes into folded line up with
+	// text
+	folded := make([]rune, len(text))
+	for i, r := range text {
+		folded[i] = unicode.ToLower(r)
+	}
+	needles := make([][]rune, 0, len(terms))
+	for _, term := range terms {
+		needle := []rune(strings.ToLower(term))
+		if len(needle) > 0 {
+			needles = append(needles, needle)
+		}
+	}
+	matchAt := func(i int) int {
+		for _, needle := range needles {
+			if i+len(needle) <= len(folded) && string(folded[i:i+len(needle)]) == string(needle) {
+				return len(needle)
+			}
+		}
+		return 0
+	}
+
+	first := 0
+	for i := range folded {
+		if matchAt(i) > 0 {
+			first = i
+			break
+		}
+	}
+	start := max(0, first-commentSnippetRunes/3)
+	end := min(len(text), start+commentSnippetRunes)
+	start = max(0, min(start, end-commentSnippetRunes))
+	// Don't cut words in half at either edge
+	for start > 0 && !unicode.IsSpace(text[start-1]) && start < first {
+		start++
+	}
+	if cut := end; cut < len(text) {
+		for cut > first && !unicode.IsSpace(text[cut]) {
+			cut--
+		}
+		if cut > first {
+			end = cut
+		}
+	}
+
+	segments := []SnippetSegment{}
+	appendText := func(s string, highlight bool) {
+		if n := len(segments); n > 0 && segments[n-1].Highlight == highlight {
+			segments[n-1].Text += s
+			return
+		}
+		segments = append(segments, SnippetSegment{Text: s, Highlight: highlight})
+	}
+	if start > 0 {
+		appendText("…", false)
+	}
+	for i := start; i < end; {
+		if n := matchAt(i); n > 0 && i+n <= end {
+			appendText(string(text[i:i+n]), true)
+			i += n
+			continue
+		}
+		appendText(string(text[i]), false)
+		i++
+	}
+	if end < len(text) {
+		appendText("…", false)
+	}
+	return segments
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchGroupComments(t *testing.T) {
+	db := SetupTestDB(t)
+	vet := CreateTestUser(t, db, "VetTech", "vet@example.com", "password123", false)
+	walker := CreateTestUser(t, db, "walker", "walker@example.com", "password123", false)
+	outsider := CreateTestUser(t, db, "outsider", "outsider@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	other := CreateTestGroup(t, db, "Cats", "")
+	AddUserToGroupWithAdmin(t, db, vet.ID, group.ID, false)
+	AddUserToGroupWithAdmin(t, db, walker.ID, group.ID, false)
+	AddUserToGroupWithAdmin(t, db, outsider.ID, other.ID, false)
+
+	bella := CreateTestAnimal(t, db, group.ID, "Bella", "Dog")
+	rex := CreateTestAnimal(t, db, group.ID, "Rex", "Dog")
+	tom := CreateTestAnimal(t, db, other.ID, "Tom", "Cat")
+	medical := models.CommentTag{GroupID: group.ID, Name: "medical"}
+	require.NoError(t, db.Create(&medical).Error)
+
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 12, 0, 0, 0, time.UTC) }
+	comment := func(animal *models.Animal, author *models.User, at time.Time, content string, tags ...models.CommentTag) {
+		require.NoError(t, db.Create(&models.AnimalComment{
+			AnimalID: animal.ID, UserID: author.ID, Content: content, CreatedAt: at, Tags: tags,
+		}).Error)
+	}
+	comment(bella, vet, day(1), "Gave Bella her Heartgard dose with dinner", medical)
+	comment(bella, walker, day(5), "Bella pulled on the leash; remind the vet about heartgard next month")
+	comment(rex, vet, day(3), "Rex got his HEARTGARD too")
+	comment(rex, walker, day(4), "Long walk, no issues")
+	comment(tom, outsider, day(2), "Tom's heartgard is due")
+
+	search := func(userID uint, query string) (int, []CommentSearchHit, int64) {
+		c, w := accountTestContext(userID, false, http.MethodGet, "/?"+query, nil)
+		c.Params = gin.Params{{Key: "id", Value: itoa(group.ID)}}
+		SearchGroupComments(db)(c)
+		var response struct {
+			Results []CommentSearchHit `json:"results"`
+			Total   int64              `json:"total"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response.Results, response.Total
+	}
+	animals := func(hits []CommentSearchHit) []string {
+		names := []string{}
+		for _, h := range hits {
+			names = append(names, h.AnimalName)
+		}
+		return names
+	}
+
+	code, _, _ := search(outsider.ID, "q=heartgard")
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _, _ = search(vet.ID, "q=")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _, _ = search(vet.ID, "q=heartgard&from=March")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, hits, total := search(vet.ID, "q=heartgard")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, int64(3), total, "other groups' comments aren't searched")
+	assert.Equal(t, []string{"Bella", "Rex", "Bella"}, animals(hits), "newest first")
+
+	_, hits, _ = search(vet.ID, "q=heartgard&animal_id="+itoa(bella.ID))
+	assert.Equal(t, []string{"Bella", "Bella"}, animals(hits))
+	_, hits, _ = search(vet.ID, "q=heartgard&author=vettech")
+	assert.Equal(t, []string{"Rex", "Bella"}, animals(hits))
+	_, hits, _ = search(vet.ID, "q=heartgard&tag=medical")
+	require.Len(t, hits, 1)
+	assert.Equal(t, []string{"medical"}, hits[0].Tags)
+	assert.Equal(t, "VetTech", hits[0].Username)
+	_, hits, _ = search(vet.ID, "q=heartgard&from=2026-03-02&to=2026-03-03")
+	assert.Equal(t, []string{"Rex"}, animals(hits), "to is inclusive")
+	_, hits, _ = search(vet.ID, "q=heartgard+bella")
+	assert.Len(t, hits, 2, "every word must match")
+
+	_, hits, total = search(vet.ID, "q=heartgard&limit=1&offset=1")
+	assert.Equal(t, int64(3), total)
+	assert.Equal(t, []string{"Rex"}, animals(hits))
+
+	assert.Equal(t, []SnippetSegment{
+		{Text: "Rex got his "}, {Text: "HEARTGARD", Highlight: true}, {Text: " too"},
+	}, hits[0].Snippet)
+}
+
+func TestHighlightSnippet(t *testing.T) {
+	long := strings.Repeat("walked well ", 40) + "then took Heartgard " + strings.Repeat("and napped ", 40)
+	snippet := highlightSnippet(long, []string{"heartgard"})
+	require.Greater(t, len(snippet), 2)
+	assert.Equal(t, "…", snippet[0].Text[:len("…")], "text cut before the match is marked")
+	assert.True(t, strings.HasSuffix(snippet[len(snippet)-1].Text, "…"))
+	var highlighted []string
+	for _, s := range snippet {
+		if s.Highlight {
+			highlighted = append(highlighted, s.Text)
+		}
+	}
+	assert.Equal(t, []string{"Heartgard"}, highlighted)
+	assert.NotContains(t, snippet[0].Text, "…alked", "words aren't cut in half")
+}
+
+func TestParseHeadline(t *testing.T) {
+	assert.Equal(t, []SnippetSegment{
+		{Text: "Gave her "}, {Text: "Heartgard", Highlight: true}, {Text: " … next "}, {Text: "dose", Highlight: true},
+	}, parseHeadline("Gave her \x02Heartgard\x03 … next \x02dose\x03"))
+	assert.Equal(t, []SnippetSegment{}, parseHeadline(""))
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/database"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// Buckets for GetCommentTagVolume
+const (
+	commentTagVolumeDay  = "day"
+	commentTagVolumeWeek = "week"
+)
+
+// CommentTagVolume is tagged comment volume for a group over a date range.
+type CommentTagVolume struct {
+	From     time.Time          `json:"from"`
+	To       time.Time          `json:"to"` // Exclusive
+	Interval string             `json:"interval"`
+	Tags     []CommentTagSeries `json:"tags"`
+}
+
+// CommentTagSeries is one comment tag's comment counts per bucket. Each
+// point's date is the first day of its bucket; weekly buckets start on the
+// range's from date. PreviousTotal covers the same length of time just
+// before from, so a spike shows up as Total well above PreviousTotal.
+type CommentTagSeries struct {
+	TagID         uint         `json:"tag_id"`
+	Name          string       `json:"name"`
+	Color         string       `json:"color"`
+	Total         int64        `json:"total"`
+	PreviousTotal int64        `json:"previous_total"`
+	Points        []DailyCount `json:"points"`
+}
+
+// commentTagCounts returns how many of an animal's comments carry each
+// comment tag, most used first. Tags with no comments are left out.
+func commentTagCounts(db *gorm.DB, animalID uint) ([]models.CommentTagCount, error) {
+	counts := []models.CommentTagCount{}
+	err := db.Raw(`
+		SELECT ct.id AS tag_id, ct.name, ct.color, COUNT(*) AS count
+		FROM animal_comment_tags act
+		JOIN animal_comments ac ON ac.id = act.animal_comment_id AND ac.deleted_at IS NULL
+		JOIN comment_tags ct ON ct.id = act.comment_tag_id AND ct.deleted_at IS NULL
+		WHERE ac.animal_id = ?
+		GROUP BY ct.id, ct.name, ct.color
+		ORDER BY count DESC, ct.name`, animalID).Scan(&counts).Error
+	return counts, err
+}
+
+// buildCommentTagVolume counts a group's tagged comments per tag and bucket
+// over [from, to). When names is non-empty only those tags are reported.
+func buildCommentTagVolume(db *gorm.DB, groupID uint, names []string, from, to time.Time, interval string) (*CommentTagVolume, error) {
+	volume := &CommentTagVolume{From: from, To: to, Interval: interval, Tags: []CommentTagSeries{}}
+
+	tagQuery := db.Where("group_id = ?", groupID)
+	if len(names) > 0 {
+		tagQuery = tagQuery.Where("name IN ?", names)
+	}
+	var tags []models.CommentTag
+	if err := tagQuery.Order("is_system DESC, name ASC").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		return volume, nil
+	}
+	tagIDs := make([]uint, len(tags))
+	for i, tag := range tags {
+		tagIDs[i] = tag.ID
+	}
+
+	// Only comments on the group's own animals count; tags are per group,
+	// but this keeps a stray cross-group tag assignment out of the numbers
+	tagged := func(start, end time.Time) *gorm.DB {
+		return db.Table("animal_comment_tags act").
+			Joins("JOIN animal_comments ac ON ac.id = act.animal_comment_id AND ac.deleted_at IS NULL").
+			Joins("JOIN animals a ON a.id = ac.animal_id").
+			Where("act.comment_tag_id IN ? AND a.group_id = ? AND ac.created_at >= ? AND ac.created_at < ?", tagIDs, groupID, start, end)
+	}
+
+	day := database.DialectOf(db).Day("ac.created_at")
+	var daily []struct {
+		TagID uint
+		Date  string
+		Count int64
+	}
+	if err := tagged(from, to).Select("act.comment_tag_id AS tag_id, " + day + " AS date, COUNT(*) AS count").
+		Group("act.comment_tag_id, " + day).Scan(&daily).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[uint]map[string]int64, len(tags))
+	for _, row := range daily {
+		if counts[row.TagID] == nil {
+			counts[row.TagID] = map[string]int64{}
+		}
+		counts[row.TagID][row.Date] = row.Count
+	}
+
+	var previous []struct {
+		TagID uint
+		Count int64
+	}
+	if err := tagged(from.Add(-to.Sub(from)), from).Select("act.comment_tag_id AS tag_id, COUNT(*) AS count").
+		Group("act.comment_tag_id").Scan(&previous).Error; err != nil {
+		return nil, err
+	}
+	previousCounts := make(map[uint]int64, len(previous))
+	for _, row := range previous {
+		previousCounts[row.TagID] = row.Count
+	}
+
+	step := 1
+	if interval == commentTagVolumeWeek {
+		step = 7
+	}
+	for _, tag := range tags {
+		series := CommentTagSeries{
+			TagID: tag.ID, Name: tag.Name, Color: tag.Color,
+			PreviousTotal: previousCounts[tag.ID], Points: []DailyCount{},
+		}
+		for start := from; start.Before(to); start = start.AddDate(0, 0, step) {
+			point := DailyCount{Date: start.Format("2006-01-02")}
+			for d := start; d.Before(start.AddDate(0, 0, step)) && d.Before(to); d = d.AddDate(0, 0, 1) {
+				point.Count += counts[tag.ID][d.Format("2006-01-02")]
+			}
+			series.Points = append(series.Points, point)
+			series.Total += point.Count
+		}
+		volume.Tags = append(volume.Tags, series)
+	}
+	return volume, nil
+}
+
+// GetCommentTagVolume reports how many of a group's comments carried each
+// comment tag per day or week, e.g. to spot a rise in medical comments.
+// Query params: from, to (YYYY-MM-DD, default the last 30 days), interval
+// (day or week, default day), tags (comma-separated names, default all).
+// Route: GET /api/groups/:id/comment-tags/volume
+func GetCommentTagVolume(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+		defer cancel()
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+
+		interval := c.DefaultQuery("interval", commentTagVolumeDay)
+		if interval != commentTagVolumeDay && interval != commentTagVolumeWeek {
+			respondBadRequest(c, "interval must be day or week")
+			return
+		}
+		from, to, ok := parseAnalyticsRange(c, time.Now())
+		if !ok {
+			return
+		}
+		if to.Sub(from) > maxStatsRangeDays*24*time.Hour {
+			respondBadRequest(c, "The date range can't be longer than "+strconv.Itoa(maxStatsRangeDays)+" days")
+			return
+		}
+
+		volume, err := buildCommentTagVolume(db.WithContext(ctx), uint(gid), splitAndTrim(c.Query("tags")), from, to, interval)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to compute comment tag volume", err)
+			respondInternalError(c, "Failed to compute comment tag volume")
+			return
+		}
+		respondOK(c, volume)
+	}
+}
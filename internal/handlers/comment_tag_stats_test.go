@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// createTaggedComment adds a comment on animal at createdAt carrying tags.
+func createTaggedComment(t *testing.T, db *gorm.DB, animal *models.Animal, userID uint, createdAt time.Time, tags ...models.CommentTag) {
+	t.Helper()
+	comment := models.AnimalComment{AnimalID: animal.ID, UserID: userID, Content: "note", Tags: tags, CreatedAt: createdAt, UpdatedAt: createdAt}
+	require.NoError(t, db.Create(&comment).Error)
+}
+
+func TestGetAnimal_CommentTagCounts(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	user, group := createAnimalTestUser(t, db, "walker", "walker@example.com", false)
+	animal := createTestAnimal(t, db, group.ID, "Rex", "Dog")
+	medical := models.CommentTag{GroupID: group.ID, Name: "medical", Color: "#ef4444"}
+	behavior := models.CommentTag{GroupID: group.ID, Name: "behavior", Color: "#3b82f6"}
+	require.NoError(t, db.Create(&medical).Error)
+	require.NoError(t, db.Create(&behavior).Error)
+
+	now := time.Now()
+	createTaggedComment(t, db, animal, user.ID, now, medical)
+	createTaggedComment(t, db, animal, user.ID, now, medical, behavior)
+	createTaggedComment(t, db, animal, user.ID, now)
+	deleted := models.AnimalComment{AnimalID: animal.ID, UserID: user.ID, Content: "gone", Tags: []models.CommentTag{behavior}}
+	require.NoError(t, db.Create(&deleted).Error)
+	require.NoError(t, db.Delete(&deleted).Error)
+
+	c, w := setupAnimalTestContext(user.ID, false)
+	c.Params = gin.Params{{Key: "id", Value: fmt.Sprintf("%d", group.ID)}, {Key: "animalId", Value: fmt.Sprintf("%d", animal.ID)}}
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	GetAnimal(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp models.Animal
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []models.CommentTagCount{
+		{TagID: medical.ID, Name: "medical", Color: "#ef4444", Count: 2},
+		{TagID: behavior.ID, Name: "behavior", Color: "#3b82f6", Count: 1},
+	}, resp.CommentTagCounts, "deleted comments are not counted")
+}
+
+func TestGetCommentTagVolume(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	user, group := createAnimalTestUser(t, db, "walker", "walker@example.com", false)
+	outsider, _ := createAnimalTestUser(t, db, "outsider", "outsider@example.com", false)
+	animal := createTestAnimal(t, db, group.ID, "Rex", "Dog")
+	medical := models.CommentTag{GroupID: group.ID, Name: "medical", Color: "#ef4444", IsSystem: true}
+	behavior := models.CommentTag{GroupID: group.ID, Name: "behavior", Color: "#3b82f6", IsSystem: true}
+	require.NoError(t, db.Create(&medical).Error)
+	require.NoError(t, db.Create(&behavior).Error)
+
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 12, 0, 0, 0, time.UTC) }
+	createTaggedComment(t, db, animal, user.ID, day(1), medical) // before the range
+	createTaggedComment(t, db, animal, user.ID, day(8), medical)
+	createTaggedComment(t, db, animal, user.ID, day(9), medical, behavior)
+	createTaggedComment(t, db, animal, user.ID, day(9), medical)
+	createTaggedComment(t, db, animal, user.ID, day(14), behavior)
+
+	get := func(userID uint, query string) (*httptest.ResponseRecorder, CommentTagVolume) {
+		c, w := setupAnimalTestContext(userID, false)
+		c.Params = gin.Params{{Key: "id", Value: fmt.Sprintf("%d", group.ID)}}
+		c.Request = httptest.NewRequest(http.MethodGet, "/?"+query, nil)
+		GetCommentTagVolume(db)(c)
+		var volume CommentTagVolume
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &volume))
+		}
+		return w, volume
+	}
+
+	t.Run("daily", func(t *testing.T) {
+		w, volume := get(user.ID, "from=2026-10-08&to=2026-10-14")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "day", volume.Interval)
+		require.Len(t, volume.Tags, 2)
+
+		// System tags sort first, then by name
+		beh := volume.Tags[0]
+		assert.Equal(t, "behavior", beh.Name)
+		assert.Equal(t, int64(2), beh.Total)
+
+		med := volume.Tags[1]
+		assert.Equal(t, "medical", med.Name)
+		assert.Equal(t, int64(3), med.Total)
+		assert.Equal(t, int64(1), med.PreviousTotal, "the week before the range")
+		require.Len(t, med.Points, 7)
+		assert.Equal(t, DailyCount{Date: "2026-10-08", Count: 1}, med.Points[0])
+		assert.Equal(t, DailyCount{Date: "2026-10-09", Count: 2}, med.Points[1])
+		assert.Equal(t, int64(0), med.Points[6].Count)
+	})
+
+	t.Run("weekly with tag filter", func(t *testing.T) {
+		w, volume := get(user.ID, "from=2026-10-01&to=2026-10-14&interval=week&tags=medical")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.Len(t, volume.Tags, 1)
+		assert.Equal(t, []DailyCount{{Date: "2026-10-01", Count: 1}, {Date: "2026-10-08", Count: 3}}, volume.Tags[0].Points)
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		w, _ := get(user.ID, "interval=month")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w, _ = get(user.ID, "from=2026-10-14&to=2026-10-01")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w, _ = get(outsider.ID, "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
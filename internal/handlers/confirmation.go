@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/auth"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+)
+
+// confirmationTokenPurpose scopes confirmation token signatures so they can't
+// be confused with any other value signed with the server key.
+const confirmationTokenPurpose = "destructive_action_confirmation"
+
+// confirmationTokenTTL is how long an admin has to repeat a destructive
+// request with its confirmation token.
+const confirmationTokenTTL = 5 * time.Minute
+
+// Error codes for two-step destructive actions.
+const (
+	ErrCodeConfirmationRequired ErrorCode = "CONFIRMATION_REQUIRED"
+	ErrCodeInvalidConfirmation  ErrorCode = "INVALID_CONFIRMATION_TOKEN"
+)
+
+// ConfirmationRequiredResponse is returned, with 428 Precondition Required,
+// by the first call to a destructive endpoint. Repeating the call with
+// ?confirmation_token=<token> before ExpiresAt performs the action.
+type ConfirmationRequiredResponse struct {
+	Error             string    `json:"error"`
+	Code              ErrorCode `json:"code"`
+	ConfirmationToken string    `json:"confirmation_token"`
+	ExpiresAt         time.Time `json:"expires_at"`
+	Impact            any       `json:"impact"`
+}
+
+// confirmationValue is the signed value behind a confirmation token. It
+// binds the token to one action on one resource by one admin.
+func confirmationValue(action string, resourceID, adminID uint, expiresAt int64) string {
+	return fmt.Sprintf("%s:%d:%d:%d", action, resourceID, adminID, expiresAt)
+}
+
+// issueConfirmationToken returns a token confirming action on resourceID by
+// adminID, and when it expires. The token is its expiry and a signature, so
+// nothing is stored server-side.
+func issueConfirmationToken(action string, resourceID, adminID uint, now time.Time) (string, time.Time, error) {
+	expiresAt := now.Add(confirmationTokenTTL).Truncate(time.Second)
+	sig, err := auth.Sign(confirmationTokenPurpose, confirmationValue(action, resourceID, adminID, expiresAt.Unix()))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return strconv.FormatInt(expiresAt.Unix(), 10) + "." + sig, expiresAt, nil
+}
+
+// verifyConfirmationToken reports whether token confirms action on
+// resourceID by adminID and hasn't expired.
+func verifyConfirmationToken(token, action string, resourceID, adminID uint, now time.Time) bool {
+	expiry, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || now.Unix() > expiresAt {
+		return false
+	}
+	return auth.VerifySignature(confirmationTokenPurpose, confirmationValue(action, resourceID, adminID, expiresAt), sig)
+}
+
+// requireConfirmation gates a destructive action behind a second call. It
+// returns true when the request carries a valid confirmation_token for
+// action on resourceID. Otherwise it responds 428 with a fresh token and the
+// summary returned by impact, or 400 if the token given is invalid or
+// expired, and returns false.
+func requireConfirmation(c *gin.Context, action string, resourceID uint, impact func() (any, error)) bool {
+	adminID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondInternalError(c, "User context not found")
+		return false
+	}
+	now := time.Now()
+
+	if token := c.Query("confirmation_token"); token != "" {
+		if verifyConfirmationToken(token, action, resourceID, adminID, now) {
+			return true
+		}
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidConfirmation, "Confirmation token is invalid or expired; request a new one")
+		return false
+	}
+
+	summary, err := impact()
+	if err != nil {
+		middleware.GetLogger(c).Error("Failed to summarize impact of "+action, err)
+		respondInternalError(c, "Failed to prepare confirmation")
+		return false
+	}
+	token, expiresAt, err := issueConfirmationToken(action, resourceID, adminID, now)
+	if err != nil {
+		middleware.GetLogger(c).Error("Failed to sign confirmation token", err)
+		respondInternalError(c, "Failed to prepare confirmation")
+		return false
+	}
+	c.JSON(http.StatusPreconditionRequired, ConfirmationRequiredResponse{
+		Error:             "Confirmation required",
+		Code:              ErrCodeConfirmationRequired,
+		ConfirmationToken: token,
+		ExpiresAt:         expiresAt,
+		Impact:            summary,
+	})
+	return false
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Columns left out of exports with ?exclude_pii=true: comment authors, and
+// microchip and license numbers, which registries tie to owners' contact
+// details.
+var (
+	animalCSVPIIColumns        = map[string]bool{"microchip_number": true, "license_number": true}
+	animalCommentCSVPIIColumns = map[string]bool{"comment_author": true}
+)
+
+// csvColumnSelection is the columns an export writes, as indexes into its
+// full header, in the order they're written.
+type csvColumnSelection []int
+
+// selectCSVColumns reads ?columns=, a comma-separated list of header names,
+// and ?exclude_pii=true. Without columns every column is written, in header
+// order, less piiColumns when PII is excluded. A name not in header, or a
+// PII column requested along with exclude_pii, is an error.
+func selectCSVColumns(c *gin.Context, header []string, piiColumns map[string]bool) (csvColumnSelection, error) {
+	excludePII := c.Query("exclude_pii") == "true"
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[name] = i
+	}
+
+	var selection csvColumnSelection
+	raw := strings.TrimSpace(c.Query("columns"))
+	if raw == "" {
+		for i, name := range header {
+			if !excludePII || !piiColumns[name] {
+				selection = append(selection, i)
+			}
+		}
+		return selection, nil
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range splitAndTrim(raw) {
+		i, ok := index[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q; columns are: %s", name, strings.Join(header, ", "))
+		}
+		if excludePII && piiColumns[name] {
+			return nil, fmt.Errorf("column %q holds personal information and can't be exported with exclude_pii", name)
+		}
+		if !seen[name] {
+			seen[name] = true
+			selection = append(selection, i)
+		}
+	}
+	if len(selection) == 0 {
+		return nil, fmt.Errorf("columns must name at least one column")
+	}
+	return selection, nil
+}
+
+// pick returns the selected cells of a full row, or names of a full header
+func (s csvColumnSelection) pick(row []string) []string {
+	out := make([]string, len(s))
+	for i, col := range s {
+		out[i] = row[col]
+	}
+	return out
+}
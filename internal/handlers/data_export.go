@@ -0,0 +1,438 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/maintenance"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
+	"gorm.io/gorm"
+)
+
+// JobDataExport is the background job type that builds the CSV file for a
+// DataExport.
+const JobDataExport = "data_export"
+
+// dataExportJob is the payload of a JobDataExport job.
+type dataExportJob struct {
+	ExportID uint `json:"export_id"`
+}
+
+// dataExportBatchSize is how many rows are loaded, and progress saved, at a
+// time.
+const dataExportBatchSize = 500
+
+// DataExportRequest is the body of CreateDataExport and
+// CreateGroupDataExport. AnimalID and Tags only apply to comment exports.
+type DataExportRequest struct {
+	Kind     string `json:"kind" binding:"required,oneof=animals comments"`
+	GroupID  *uint  `json:"group_id"`
+	AnimalID *uint  `json:"animal_id"`
+	Tags     string `json:"tags"` // Comma-separated tag names
+}
+
+// dataExportResponse is a DataExport with its progress, and a download
+// link once the file is ready.
+type dataExportResponse struct {
+	models.DataExport
+	Progress    int    `json:"progress"` // Percent of rows written
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+func newDataExportResponse(export models.DataExport) dataExportResponse {
+	resp := dataExportResponse{DataExport: export}
+	switch {
+	case export.Status == models.JobStatusSucceeded:
+		resp.Progress = 100
+		if export.ExpiresAt == nil || export.ExpiresAt.After(time.Now()) {
+			resp.DownloadURL = fmt.Sprintf("/api/exports/%d/download", export.ID)
+		}
+	case export.RowsTotal > 0:
+		resp.Progress = min(export.RowsDone*100/export.RowsTotal, 99)
+	}
+	return resp
+}
+
+// CreateDataExport starts a CSV export of animals or animal comments in the
+// background (admin only). group_id narrows either kind to one group;
+// animal_id and tags filter comments as in ExportAnimalCommentsCSV. Poll
+// GetDataExport for progress.
+// Route: POST /api/admin/exports
+func CreateDataExport(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		var req DataExportRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		createDataExport(c, db, req)
+	}
+}
+
+// CreateGroupDataExport starts a CSV export of one group's animal comments
+// in the background (group admin or site admin). Only comment exports are
+// available per group, matching ExportGroupAnimalCommentsCSV.
+// Route: POST /api/groups/:id/animals/exports
+func CreateGroupDataExport(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		gid, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+		if !IsGroupAdminOrSiteAdmin(c, db, uint(gid)) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Group admin access required")
+			return
+		}
+
+		var req DataExportRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		if req.Kind != models.DataExportComments {
+			respondBadRequest(c, "Only comment exports are available for a group")
+			return
+		}
+		groupID := uint(gid)
+		req.GroupID = &groupID
+		createDataExport(c, db, req)
+	}
+}
+
+// createDataExport stores the export and queues the job that builds it, in
+// one transaction, then responds 202 with the pending export.
+func createDataExport(c *gin.Context, db *gorm.DB, req DataExportRequest) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondInternalError(c, "User context not found")
+		return
+	}
+
+	export := models.DataExport{
+		UserID:   userID,
+		Kind:     req.Kind,
+		GroupID:  req.GroupID,
+		Status:   models.JobStatusPending,
+		FileName: dataExportFileName(req.Kind, req.GroupID),
+	}
+	if req.Kind == models.DataExportComments {
+		export.AnimalID = req.AnimalID
+		export.Tags = req.Tags
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&export).Error; err != nil {
+			return err
+		}
+		_, err := jobs.Enqueue(tx, JobDataExport, dataExportJob{ExportID: export.ID})
+		return err
+	})
+	if err != nil {
+		middleware.GetLogger(c).Error("Failed to queue data export", err)
+		respondInternalError(c, "Failed to start export")
+		return
+	}
+
+	middleware.GetLogger(c).WithFields(map[string]interface{}{
+		"export_id": export.ID,
+		"kind":      export.Kind,
+	}).Info("Queued data export")
+	c.JSON(http.StatusAccepted, newDataExportResponse(export))
+}
+
+// dataExportFileName matches the file names of the synchronous exports.
+func dataExportFileName(kind string, groupID *uint) string {
+	name := "animals"
+	if kind == models.DataExportComments {
+		name = "animal-comments"
+	}
+	if groupID != nil {
+		return fmt.Sprintf("%s-group-%d.csv", name, *groupID)
+	}
+	return name + ".csv"
+}
+
+// loadDataExport loads the export named by :exportId if the current user
+// requested it or is a site admin, responding 404 otherwise.
+func loadDataExport(c *gin.Context, db *gorm.DB) (*models.DataExport, bool) {
+	id, err := strconv.ParseUint(c.Param("exportId"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid export ID")
+		return nil, false
+	}
+	userID, _ := middleware.GetUserID(c)
+
+	var export models.DataExport
+	if err := db.Omit("file_data").First(&export, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondNotFound(c, "Export not found")
+		} else {
+			middleware.GetLogger(c).Error("Failed to load data export", err)
+			respondInternalError(c, "Failed to load export")
+		}
+		return nil, false
+	}
+	if export.UserID != userID && !middleware.IsSiteAdmin(c) {
+		respondNotFound(c, "Export not found")
+		return nil, false
+	}
+	return &export, true
+}
+
+// GetDataExport returns an export's status and progress (the user who
+// requested it, or a site admin).
+// Route: GET /api/exports/:exportId
+func GetDataExport(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		export, ok := loadDataExport(c, db)
+		if !ok {
+			return
+		}
+		respondOK(c, newDataExportResponse(*export))
+	}
+}
+
+// DownloadDataExport serves a finished export's CSV file (the user who
+// requested it, or a site admin). Responds 409 while the export is still
+// running and 410 once it has expired.
+// Route: GET /api/exports/:exportId/download
+func DownloadDataExport(db *gorm.DB, storageProvider storage.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		export, ok := loadDataExport(c, db)
+		if !ok {
+			return
+		}
+		if export.Status != models.JobStatusSucceeded {
+			respondError(c, http.StatusConflict, ErrCodeConflict, "Export is not ready")
+			return
+		}
+		if export.ExpiresAt != nil && !export.ExpiresAt.After(time.Now()) {
+			respondError(c, http.StatusGone, ErrCodeNotFound, "Export has expired")
+			return
+		}
+
+		var data []byte
+		if export.FileProvider == storage.ProviderPostgres {
+			var file models.DataExport
+			if err := db.Select("file_data").First(&file, export.ID).Error; err != nil {
+				middleware.GetLogger(c).Error("Failed to load export file", err)
+				respondInternalError(c, "Failed to load export file")
+				return
+			}
+			data = file.FileData
+		} else {
+			var err error
+			data, _, err = storageProvider.GetDocument(c.Request.Context(), export.FileBlobIdentifier)
+			if err != nil {
+				middleware.GetLogger(c).Error("Failed to load export file from storage", err)
+				respondInternalError(c, "Failed to load export file")
+				return
+			}
+		}
+
+		c.Header("Content-Disposition", "attachment; filename="+export.FileName)
+		c.Data(http.StatusOK, "text/csv", data)
+	}
+}
+
+// dataExportJobHandler builds a JobDataExport's CSV file and stores it. A
+// failed export is marked failed rather than retried; the user can start
+// another.
+func dataExportJobHandler(db *gorm.DB, storageProvider storage.Provider) jobs.Handler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var job dataExportJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return jobs.Permanent(err)
+		}
+		db := db.WithContext(ctx)
+
+		var export models.DataExport
+		if err := db.First(&export, job.ExportID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil // Purged before it ran
+			}
+			return err
+		}
+		if err := db.Model(&export).Updates(map[string]interface{}{"status": models.JobStatusRunning, "rows_done": 0}).Error; err != nil {
+			return err
+		}
+
+		if err := runDataExport(ctx, db, storageProvider, &export); err != nil {
+			if updateErr := db.Model(&export).Updates(map[string]interface{}{
+				"status": models.JobStatusFailed,
+				"error":  err.Error(),
+			}).Error; updateErr != nil {
+				logging.WithContext(ctx).WithField("export_id", export.ID).Error("Failed to mark data export failed", updateErr)
+			}
+			return jobs.Permanent(err)
+		}
+		return nil
+	}
+}
+
+// runDataExport writes export's rows to a CSV file, saving progress after
+// each batch, then stores the file the way group documents are stored.
+func runDataExport(ctx context.Context, db *gorm.DB, storageProvider storage.Provider, export *models.DataExport) error {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	progress := func(done, total int) error {
+		return db.Model(export).Updates(map[string]interface{}{"rows_done": done, "rows_total": total}).Error
+	}
+
+	var err error
+	switch export.Kind {
+	case models.DataExportAnimals:
+		err = writeAnimalsExport(db, writer, export, progress)
+	case models.DataExportComments:
+		err = writeAnimalCommentsExport(db, writer, export, progress)
+	default:
+		err = fmt.Errorf("unknown export kind %q", export.Kind)
+	}
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+
+	data := buf.Bytes()
+	updates := map[string]interface{}{"file_size": len(data)}
+	_, blobUUID, blobExt, uploadErr := storageProvider.UploadDocument(ctx, data, "text/csv", export.FileName)
+	if uploadErr != nil || storageProvider.Name() == storage.ProviderPostgres {
+		if uploadErr != nil {
+			logging.WithContext(ctx).WithField("error", uploadErr.Error()).
+				Warn("Failed to upload export to storage provider, falling back to PostgreSQL")
+		}
+		updates["file_provider"] = storage.ProviderPostgres
+		updates["file_data"] = data
+	} else {
+		updates["file_provider"] = storageProvider.Name()
+		updates["file_blob_identifier"] = blobUUID + blobExt
+	}
+
+	now := time.Now()
+	updates["status"] = models.JobStatusSucceeded
+	updates["completed_at"] = now
+	updates["expires_at"] = now.Add(maintenance.DataExportRetention())
+	return db.Model(export).Updates(updates).Error
+}
+
+// writeAnimalsExport writes the animals export rows, in ID order.
+func writeAnimalsExport(db *gorm.DB, writer *csv.Writer, export *models.DataExport, progress func(done, total int) error) error {
+	query := func() *gorm.DB {
+		q := db.Model(&models.Animal{})
+		if export.GroupID != nil {
+			q = q.Where("group_id = ?", *export.GroupID)
+		}
+		return q
+	}
+
+	var total int64
+	if err := query().Count(&total).Error; err != nil {
+		return err
+	}
+	customKeys, err := animalCustomFieldKeys(db, export.GroupID)
+	if err != nil {
+		return err
+	}
+	if err := writer.Write(animalCSVHeaderWith(customKeys)); err != nil {
+		return err
+	}
+
+	done := 0
+	for {
+		var animals []models.Animal
+		if err := query().Order("id").Limit(dataExportBatchSize).Offset(done).Find(&animals).Error; err != nil {
+			return err
+		}
+		for _, animal := range animals {
+			if err := writer.Write(animalCSVRecord(animal, customKeys)); err != nil {
+				return err
+			}
+		}
+		done += len(animals)
+		if err := progress(done, int(total)); err != nil {
+			return err
+		}
+		if len(animals) < dataExportBatchSize {
+			return nil
+		}
+	}
+}
+
+// writeAnimalCommentsExport writes the comments export rows, newest first.
+// group_id restricts the export to that group's animals even when animal_id
+// is also set.
+func writeAnimalCommentsExport(db *gorm.DB, writer *csv.Writer, export *models.DataExport, progress func(done, total int) error) error {
+	query := func() *gorm.DB {
+		q := db.Model(&models.AnimalComment{})
+		if export.GroupID != nil {
+			q = q.Joins("JOIN animals ON animals.id = animal_comments.animal_id").
+				Where("animals.group_id = ?", *export.GroupID)
+		}
+		if export.AnimalID != nil {
+			q = q.Where("animal_comments.animal_id = ?", *export.AnimalID)
+		}
+		if export.Tags != "" {
+			q = applyTagFilter(q, splitAndTrim(export.Tags))
+		}
+		return q
+	}
+
+	// applyTagFilter groups by comment, so count the grouped rows
+	var total int64
+	if err := db.Table("(?) AS export_comments", query().Select("animal_comments.id")).Count(&total).Error; err != nil {
+		return err
+	}
+	if err := writer.Write(animalCommentCSVHeader); err != nil {
+		return err
+	}
+
+	done := 0
+	for {
+		var comments []models.AnimalComment
+		if err := query().Preload("User").Preload("Tags").
+			Order("animal_comments.created_at DESC, animal_comments.id DESC").
+			Limit(dataExportBatchSize).Offset(done).Find(&comments).Error; err != nil {
+			return err
+		}
+		animalMap, groupMap, err := loadAnimalCommentCSVDetails(db, comments)
+		if err != nil {
+			return err
+		}
+		for _, comment := range comments {
+			animal, ok := animalMap[comment.AnimalID]
+			if !ok {
+				continue
+			}
+			if err := writer.Write(animalCommentCSVRecord(comment, animal, groupMap[animal.GroupID])); err != nil {
+				return err
+			}
+		}
+		done += len(comments)
+		if err := progress(done, int(total)); err != nil {
+			return err
+		}
+		if len(comments) < dataExportBatchSize {
+			return nil
+		}
+	}
+}
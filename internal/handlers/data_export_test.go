@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/maintenance"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataExport(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	other := CreateTestUser(t, db, "other", "other@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	for _, name := range []string{"Rex", "Fido", "Buddy"} {
+		CreateTestAnimal(t, db, group.ID, name, "Dog")
+	}
+	provider := storage.NewPostgresProvider(db)
+
+	c, w := accountTestContext(admin.ID, true, http.MethodPost, "/", DataExportRequest{Kind: models.DataExportAnimals})
+	CreateDataExport(db)(c)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var created dataExportResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, models.JobStatusPending, created.Status)
+	assert.Equal(t, "animals.csv", created.FileName)
+
+	get := func(userID uint, isAdmin bool, path string) *httptest.ResponseRecorder {
+		c, w := accountTestContext(userID, isAdmin, http.MethodGet, "/", nil)
+		c.Params = gin.Params{{Key: "exportId", Value: fmt.Sprint(created.ID)}}
+		if path == "download" {
+			DownloadDataExport(db, provider)(c)
+		} else {
+			GetDataExport(db)(c)
+		}
+		return w
+	}
+
+	assert.Equal(t, http.StatusNotFound, get(other.ID, false, "").Code, "other users can't see the export")
+	assert.Equal(t, http.StatusConflict, get(admin.ID, true, "download").Code, "not ready before the job runs")
+
+	queue := jobs.NewQueue(db)
+	RegisterJobHandlers(queue, db, nil, provider)
+	assert.Equal(t, 1, queue.RunDue(context.Background()))
+
+	w = get(admin.ID, true, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var status dataExportResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Equal(t, models.JobStatusSucceeded, status.Status)
+	assert.Equal(t, 100, status.Progress)
+	assert.Equal(t, 3, status.RowsTotal)
+	assert.Equal(t, 3, status.RowsDone)
+	assert.Equal(t, fmt.Sprintf("/api/exports/%d/download", created.ID), status.DownloadURL)
+	require.NotNil(t, status.ExpiresAt)
+
+	w = get(admin.ID, true, "download")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "attachment; filename=animals.csv", w.Header().Get("Content-Disposition"))
+	records, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	assert.Equal(t, animalCSVHeader, records[0])
+	assert.Equal(t, "Rex", records[1][2])
+
+	// Expired exports can't be downloaded and are purged
+	require.NoError(t, db.Model(&models.DataExport{}).Where("id = ?", created.ID).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error)
+	assert.Equal(t, http.StatusGone, get(admin.ID, true, "download").Code)
+	purged, err := maintenance.PurgeExpiredExports(db, provider, maintenance.DataExportRetention())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	assert.Equal(t, http.StatusNotFound, get(admin.ID, true, "").Code)
+}
+
+func TestCreateGroupDataExport(t *testing.T) {
+	db := SetupTestDB(t)
+	groupAdmin := CreateTestUser(t, db, "groupadmin", "groupadmin@example.com", "password123", false)
+	member := CreateTestUser(t, db, "member", "member@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	otherGroup := CreateTestGroup(t, db, "Cats", "")
+	AddUserToGroupWithAdmin(t, db, groupAdmin.ID, group.ID, true)
+	AddUserToGroupWithAdmin(t, db, member.ID, group.ID, false)
+
+	rex := CreateTestAnimal(t, db, group.ID, "Rex", "Dog")
+	tom := CreateTestAnimal(t, db, otherGroup.ID, "Tom", "Cat")
+	require.NoError(t, db.Create(&models.AnimalComment{AnimalID: rex.ID, UserID: member.ID, Content: "Good walk"}).Error)
+	require.NoError(t, db.Create(&models.AnimalComment{AnimalID: tom.ID, UserID: member.ID, Content: "Other group"}).Error)
+
+	create := func(userID uint, body DataExportRequest) *httptest.ResponseRecorder {
+		c, w := accountTestContext(userID, false, http.MethodPost, "/", body)
+		c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}}
+		CreateGroupDataExport(db)(c)
+		return w
+	}
+
+	assert.Equal(t, http.StatusForbidden, create(member.ID, DataExportRequest{Kind: models.DataExportComments}).Code)
+	assert.Equal(t, http.StatusBadRequest, create(groupAdmin.ID, DataExportRequest{Kind: models.DataExportAnimals}).Code)
+
+	// animal_id can't reach outside the group
+	w := create(groupAdmin.ID, DataExportRequest{Kind: models.DataExportComments, AnimalID: &tom.ID})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	w = create(groupAdmin.ID, DataExportRequest{Kind: models.DataExportComments})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+	provider := storage.NewPostgresProvider(db)
+	queue := jobs.NewQueue(db)
+	RegisterJobHandlers(queue, db, nil, provider)
+	assert.Equal(t, 2, queue.RunDue(context.Background()))
+
+	var exports []models.DataExport
+	require.NoError(t, db.Order("id").Find(&exports).Error)
+	require.Len(t, exports, 2)
+	assert.Equal(t, 0, exports[0].RowsTotal)
+	assert.Equal(t, 1, exports[1].RowsTotal)
+	assert.Equal(t, fmt.Sprintf("animal-comments-group-%d.csv", group.ID), exports[1].FileName)
+
+	records, err := csv.NewReader(strings.NewReader(string(exports[1].FileData))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "Good walk", records[1][8])
+}
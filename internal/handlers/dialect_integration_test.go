@@ -0,0 +1,132 @@
+//go:build integration
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// These tests run the handler queries built from database.Dialect against
+// Postgres, to catch SQL that passes on the SQLite unit-test database but
+// behaves differently in production. They only build with the integration
+// tag — run them with `make test-integration`, which starts the
+// postgres_test container — and, unlike the *_postgres_test.go suites,
+// fail instead of skipping when no Postgres is reachable.
+func openIntegrationPostgres(t *testing.T) *gorm.DB {
+	t.Helper()
+	host := envOrDefault("DB_HOST", "localhost")
+	port := envOrDefault("DB_PORT", "5432")
+	if !tcpReachable(host, port, 2*time.Second) {
+		t.Fatalf("no Postgres reachable at %s:%s (run `make test-integration`, or set DB_HOST/DB_PORT)", host, port)
+	}
+	return openSearchTestPostgres(t)
+}
+
+func (f *searchTestFixture) getRequest(groupID uint, params url.Values) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", f.user.ID)
+	c.Set("is_admin", false)
+	c.Params = gin.Params{{Key: "id", Value: itoa(groupID)}}
+	c.Request = httptest.NewRequest(http.MethodGet, "/test?"+params.Encode(), nil)
+	return c, w
+}
+
+func TestIntegration_AnimalNameSearch(t *testing.T) {
+	f := newSearchTestFixture(t, openIntegrationPostgres(t))
+	for _, name := range []string{"Rex", "T-REX", "fluffy_dog", "fluffyAdog", "100% Good Boy", "100 Good"} {
+		require.NoError(t, f.tx.Create(&models.Animal{GroupID: f.groupA.ID, Name: name, Status: "available"}).Error)
+	}
+
+	tests := []struct {
+		search string
+		want   []string
+	}{
+		{"rex", []string{"Rex", "T-REX"}},
+		{"fluffy_dog", []string{"fluffy_dog"}},
+		{"100%", []string{"100% Good Boy"}},
+	}
+	for _, tt := range tests {
+		c, w := f.getRequest(f.groupA.ID, url.Values{"name": {tt.search}})
+		GetAnimals(f.tx)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var animals []models.Animal
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &animals))
+		var got []string
+		for _, a := range animals {
+			got = append(got, a.Name)
+		}
+		sort.Strings(got)
+		assert.Equal(t, tt.want, got, "name=%q", tt.search)
+	}
+}
+
+func TestIntegration_ActivityFeedMetadataFilters(t *testing.T) {
+	f := newSearchTestFixture(t, openIntegrationPostgres(t))
+	animal := models.Animal{GroupID: f.groupA.ID, Name: "Rex", Status: "available"}
+	require.NoError(t, f.tx.Create(&animal).Error)
+	comments := []models.AnimalComment{
+		{AnimalID: animal.ID, UserID: f.user.ID, Content: "rough walk", Metadata: &models.SessionMetadata{SessionRating: 2, BehaviorNotes: "pulled hard"}},
+		{AnimalID: animal.ID, UserID: f.user.ID, Content: "great walk", Metadata: &models.SessionMetadata{SessionRating: 5}},
+		{AnimalID: animal.ID, UserID: f.user.ID, Content: "no session details"},
+	}
+	require.NoError(t, f.tx.Create(&comments).Error)
+
+	feed := func(params url.Values) activityFeedTestResponse {
+		t.Helper()
+		c, w := f.getRequest(f.groupA.ID, params)
+		GetGroupActivityFeed(f.tx)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp activityFeedTestResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+
+	poor := feed(url.Values{"type": {"comments"}, "rating": {"poor"}})
+	require.Len(t, poor.Items, 1)
+	assert.Equal(t, "rough walk", poor.Items[0].Content)
+
+	great := feed(url.Values{"type": {"comments"}, "rating": {"5"}})
+	require.Len(t, great.Items, 1)
+	assert.Equal(t, "great walk", great.Items[0].Content)
+
+	all := feed(url.Values{"type": {"comments"}})
+	assert.Equal(t, int64(3), all.Total)
+	assert.Equal(t, 1, all.Summary.BehaviorConcernsCount)
+	assert.Equal(t, 1, all.Summary.PoorSessionsCount)
+}
+
+func TestIntegration_AdminStatsCommentVolume(t *testing.T) {
+	f := newSearchTestFixture(t, openIntegrationPostgres(t))
+	animal := models.Animal{GroupID: f.groupA.ID, Name: "Rex", Status: "available"}
+	require.NoError(t, f.tx.Create(&animal).Error)
+
+	// Both comments fall on the same UTC day, whatever the session time zone
+	day := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	for _, at := range []time.Time{day.Add(time.Hour), day.Add(23*time.Hour + 30*time.Minute)} {
+		require.NoError(t, f.tx.Create(&models.AnimalComment{
+			AnimalID: animal.ID, UserID: f.user.ID, Content: "walk", CreatedAt: at, UpdatedAt: at,
+		}).Error)
+	}
+
+	groupID := f.groupA.ID
+	from, to := day.AddDate(0, 0, -1), day.AddDate(0, 0, 2)
+	stats, err := buildAdminStats(f.tx, &groupID, from, to, to)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats.CommentsTotal)
+	require.Len(t, stats.CommentVolume, 3)
+	assert.Equal(t, []DailyCount{{"2026-10-14", 0}, {"2026-10-15", 2}, {"2026-10-16", 0}}, stats.CommentVolume)
+}
@@ -0,0 +1,520 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobDiscussionReplyEmail is the background job type that tells someone
+// taking part in a discussion about a new reply.
+const JobDiscussionReplyEmail = "discussion_reply_email"
+
+// discussionReplyEmailJob is the payload of a JobDiscussionReplyEmail job.
+type discussionReplyEmailJob struct {
+	ReplyID uint `json:"reply_id"`
+	UserID  uint `json:"user_id"`
+}
+
+const (
+	defaultDiscussionListLimit = 20
+	maxDiscussionListLimit     = 100
+
+	// discussionExcerptLength bounds the reply text quoted in an email
+	discussionExcerptLength = 300
+)
+
+// DiscussionRequest is the body for starting or editing a discussion
+type DiscussionRequest struct {
+	Title   string `json:"title" binding:"required,min=2,max=200"`
+	Content string `json:"content" binding:"required,min=1,max=10000"`
+}
+
+// DiscussionReplyRequest is the body for posting or editing a reply.
+// ParentID is ignored when editing.
+type DiscussionReplyRequest struct {
+	Content  string `json:"content" binding:"required,min=1,max=10000"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+// DiscussionDetail is a discussion with all of its replies, oldest first,
+// and whether the viewer muted it
+type DiscussionDetail struct {
+	models.Discussion
+	Replies []models.DiscussionReply `json:"replies"`
+	Muted   bool                     `json:"muted"`
+}
+
+// loadGroupDiscussion checks the caller can see the group and loads the
+// :discussionId discussion in it, writing the error response if not
+func loadGroupDiscussion(c *gin.Context, db *gorm.DB) (models.Discussion, bool) {
+	groupID := c.Param("id")
+	userID, _ := c.Get("user_id")
+	isAdmin, _ := c.Get("is_admin")
+	var discussion models.Discussion
+	if !checkGroupAccess(db, userID, isAdmin, groupID) {
+		respondForbidden(c, "Access denied")
+		return discussion, false
+	}
+	if err := db.Where("id = ? AND group_id = ?", c.Param("discussionId"), groupID).First(&discussion).Error; err != nil {
+		respondNotFound(c, "Discussion not found")
+		return discussion, false
+	}
+	return discussion, true
+}
+
+// joinDiscussion records userID as taking part in a discussion. A user who
+// muted it stays muted.
+func joinDiscussion(tx *gorm.DB, discussionID, userID uint) error {
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.DiscussionParticipant{
+		DiscussionID: discussionID,
+		UserID:       userID,
+	}).Error
+}
+
+// GetDiscussions lists a group's discussions, most recently active first,
+// without their replies. Query params: limit (default 20, max 100), offset.
+// Route: GET /api/groups/:id/discussions
+func GetDiscussions(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			respondForbidden(c, "Access denied")
+			return
+		}
+
+		limit, offset := defaultDiscussionListLimit, 0
+		if v := c.Query("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				respondBadRequest(c, "limit must be a positive number")
+				return
+			}
+			limit = min(n, maxDiscussionListLimit)
+		}
+		if v := c.Query("offset"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				respondBadRequest(c, "offset must be zero or more")
+				return
+			}
+			offset = n
+		}
+
+		query := db.Model(&models.Discussion{}).Where("group_id = ?", groupID)
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			respondInternalError(c, "Failed to fetch discussions")
+			return
+		}
+		discussions := []models.Discussion{}
+		if err := query.Preload("User").
+			Order("COALESCE(last_reply_at, created_at) DESC, id DESC").
+			Limit(limit).Offset(offset).
+			Find(&discussions).Error; err != nil {
+			respondInternalError(c, "Failed to fetch discussions")
+			return
+		}
+		respondOK(c, gin.H{
+			"items":   discussions,
+			"total":   total,
+			"limit":   limit,
+			"offset":  offset,
+			"hasMore": int64(offset+len(discussions)) < total,
+		})
+	}
+}
+
+// GetDiscussion returns a discussion with its replies. Replies carry their
+// parent_id so clients can nest them; a reply whose parent was deleted keeps
+// the parent_id and is shown at the top level.
+// Route: GET /api/groups/:id/discussions/:discussionId
+func GetDiscussion(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		discussion, ok := loadGroupDiscussion(c, db)
+		if !ok {
+			return
+		}
+		if err := db.Model(&discussion).Association("User").Find(&discussion.User); err != nil {
+			respondInternalError(c, "Failed to fetch discussion")
+			return
+		}
+		detail := DiscussionDetail{Discussion: discussion, Replies: []models.DiscussionReply{}}
+		if err := db.Preload("User").Where("discussion_id = ?", discussion.ID).
+			Order("created_at, id").Find(&detail.Replies).Error; err != nil {
+			respondInternalError(c, "Failed to fetch discussion")
+			return
+		}
+		userID, _ := middleware.GetUserID(c)
+		var muted int64
+		if err := db.Model(&models.DiscussionParticipant{}).
+			Where("discussion_id = ? AND user_id = ? AND muted = ?", discussion.ID, userID, true).
+			Count(&muted).Error; err != nil {
+			respondInternalError(c, "Failed to fetch discussion")
+			return
+		}
+		detail.Muted = muted > 0
+		respondOK(c, detail)
+	}
+}
+
+// CreateDiscussion starts a discussion in a group (any member)
+// Route: POST /api/groups/:id/discussions
+func CreateDiscussion(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			respondForbidden(c, "Access denied")
+			return
+		}
+		var req DiscussionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+		uid, _ := middleware.GetUserID(c)
+
+		discussion := models.Discussion{GroupID: uint(gid), UserID: uid, Title: req.Title, Content: req.Content}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&discussion).Error; err != nil {
+				return err
+			}
+			return joinDiscussion(tx, discussion.ID, uid)
+		})
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to create discussion", err)
+			respondInternalError(c, "Failed to create discussion")
+			return
+		}
+		if err := db.Preload("User").First(&discussion, discussion.ID).Error; err != nil {
+			respondInternalError(c, "Failed to load discussion")
+			return
+		}
+		respondCreated(c, discussion)
+	}
+}
+
+// UpdateDiscussion edits a discussion's title and opening message (its
+// author only)
+// Route: PUT /api/groups/:id/discussions/:discussionId
+func UpdateDiscussion(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		discussion, ok := loadGroupDiscussion(c, db)
+		if !ok {
+			return
+		}
+		if uid, _ := middleware.GetUserID(c); discussion.UserID != uid {
+			respondForbidden(c, "You can only edit your own discussions")
+			return
+		}
+		var req DiscussionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		if err := db.Model(&discussion).Updates(map[string]interface{}{
+			"title": req.Title, "content": req.Content, "is_edited": true,
+		}).Error; err != nil {
+			respondInternalError(c, "Failed to update discussion")
+			return
+		}
+		if err := db.Preload("User").First(&discussion, discussion.ID).Error; err != nil {
+			respondInternalError(c, "Failed to load discussion")
+			return
+		}
+		respondOK(c, discussion)
+	}
+}
+
+// DeleteDiscussion deletes a discussion and, with it, its replies (its
+// author, group admins, or site admins)
+// Route: DELETE /api/groups/:id/discussions/:discussionId
+func DeleteDiscussion(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		discussion, ok := loadGroupDiscussion(c, db)
+		if !ok {
+			return
+		}
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+		if uid, _ := middleware.GetUserID(c); discussion.UserID != uid && !checkGroupAdminAccess(db, userID, isAdmin, c.Param("id")) {
+			respondForbidden(c, "You can only delete your own discussions")
+			return
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("discussion_id = ?", discussion.ID).Delete(&models.DiscussionReply{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&discussion).Error
+		})
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to delete discussion", err)
+			respondInternalError(c, "Failed to delete discussion")
+			return
+		}
+		respondOK(c, gin.H{"message": "Discussion deleted successfully"})
+	}
+}
+
+// CreateDiscussionReply replies to a discussion, or with parent_id to
+// another reply in it (any member). Everyone else taking part in the
+// discussion who hasn't muted it and has email notifications on is emailed.
+// Route: POST /api/groups/:id/discussions/:discussionId/replies
+func CreateDiscussionReply(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		discussion, ok := loadGroupDiscussion(c, db)
+		if !ok {
+			return
+		}
+		var req DiscussionReplyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		if req.ParentID != nil {
+			var parents int64
+			if err := db.Model(&models.DiscussionReply{}).
+				Where("id = ? AND discussion_id = ?", *req.ParentID, discussion.ID).
+				Count(&parents).Error; err != nil {
+				respondInternalError(c, "Failed to create reply")
+				return
+			}
+			if parents == 0 {
+				respondBadRequest(c, "parent_id must be a reply in this discussion")
+				return
+			}
+		}
+		uid, _ := middleware.GetUserID(c)
+
+		reply := models.DiscussionReply{DiscussionID: discussion.ID, ParentID: req.ParentID, UserID: uid, Content: req.Content}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&reply).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&discussion).UpdateColumns(map[string]interface{}{
+				"reply_count":   gorm.Expr("reply_count + 1"),
+				"last_reply_at": reply.CreatedAt,
+			}).Error; err != nil {
+				return err
+			}
+			if err := joinDiscussion(tx, discussion.ID, uid); err != nil {
+				return err
+			}
+
+			// Participants who left the group no longer hear about it
+			var recipientIDs []uint
+			if err := notifiableUsers(tx.Model(&models.User{})).
+				Joins("JOIN discussion_participants dp ON dp.user_id = users.id").
+				Joins("JOIN user_groups ON user_groups.user_id = users.id AND user_groups.group_id = ?", discussion.GroupID).
+				Where("dp.discussion_id = ? AND dp.muted = ? AND users.id <> ?", discussion.ID, false, uid).
+				Pluck("users.id", &recipientIDs).Error; err != nil {
+				return err
+			}
+			payloads := make([]interface{}, len(recipientIDs))
+			for i, id := range recipientIDs {
+				payloads[i] = discussionReplyEmailJob{ReplyID: reply.ID, UserID: id}
+			}
+			return jobs.EnqueueMany(tx, JobDiscussionReplyEmail, payloads)
+		})
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to create discussion reply", err)
+			respondInternalError(c, "Failed to create reply")
+			return
+		}
+		if err := db.Preload("User").First(&reply, reply.ID).Error; err != nil {
+			respondInternalError(c, "Failed to load reply")
+			return
+		}
+		respondCreated(c, reply)
+	}
+}
+
+// loadDiscussionReply loads the :replyId reply in discussion, writing a 404
+// if there isn't one
+func loadDiscussionReply(c *gin.Context, db *gorm.DB, discussion models.Discussion) (models.DiscussionReply, bool) {
+	var reply models.DiscussionReply
+	if err := db.Where("id = ? AND discussion_id = ?", c.Param("replyId"), discussion.ID).First(&reply).Error; err != nil {
+		respondNotFound(c, "Reply not found")
+		return reply, false
+	}
+	return reply, true
+}
+
+// UpdateDiscussionReply edits a reply (its author only)
+// Route: PUT /api/groups/:id/discussions/:discussionId/replies/:replyId
+func UpdateDiscussionReply(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		discussion, ok := loadGroupDiscussion(c, db)
+		if !ok {
+			return
+		}
+		reply, ok := loadDiscussionReply(c, db, discussion)
+		if !ok {
+			return
+		}
+		if uid, _ := middleware.GetUserID(c); reply.UserID != uid {
+			respondForbidden(c, "You can only edit your own replies")
+			return
+		}
+		var req DiscussionReplyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		if err := db.Model(&reply).Updates(map[string]interface{}{"content": req.Content, "is_edited": true}).Error; err != nil {
+			respondInternalError(c, "Failed to update reply")
+			return
+		}
+		if err := db.Preload("User").First(&reply, reply.ID).Error; err != nil {
+			respondInternalError(c, "Failed to load reply")
+			return
+		}
+		respondOK(c, reply)
+	}
+}
+
+// DeleteDiscussionReply deletes a reply (its author, group admins, or site
+// admins). Replies to it stay.
+// Route: DELETE /api/groups/:id/discussions/:discussionId/replies/:replyId
+func DeleteDiscussionReply(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		discussion, ok := loadGroupDiscussion(c, db)
+		if !ok {
+			return
+		}
+		reply, ok := loadDiscussionReply(c, db, discussion)
+		if !ok {
+			return
+		}
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+		if uid, _ := middleware.GetUserID(c); reply.UserID != uid && !checkGroupAdminAccess(db, userID, isAdmin, c.Param("id")) {
+			respondForbidden(c, "You can only delete your own replies")
+			return
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Delete(&reply).Error; err != nil {
+				return err
+			}
+			return tx.Model(&discussion).UpdateColumn("reply_count", gorm.Expr("CASE WHEN reply_count > 0 THEN reply_count - 1 ELSE 0 END")).Error
+		})
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to delete discussion reply", err)
+			respondInternalError(c, "Failed to delete reply")
+			return
+		}
+		respondOK(c, gin.H{"message": "Reply deleted successfully"})
+	}
+}
+
+// MuteDiscussion stops (or, with {"muted": false}, resumes) reply emails
+// for the caller in one discussion
+// Route: PUT /api/groups/:id/discussions/:discussionId/mute
+func MuteDiscussion(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		discussion, ok := loadGroupDiscussion(c, db)
+		if !ok {
+			return
+		}
+		var req struct {
+			Muted *bool `json:"muted" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		uid, _ := middleware.GetUserID(c)
+		if err := db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "discussion_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"muted"}),
+		}).Create(&models.DiscussionParticipant{DiscussionID: discussion.ID, UserID: uid, Muted: *req.Muted}).Error; err != nil {
+			respondInternalError(c, "Failed to update discussion")
+			return
+		}
+		respondOK(c, gin.H{"muted": *req.Muted})
+	}
+}
+
+// discussionExcerpt shortens content for an email, on a rune boundary
+func discussionExcerpt(content string) string {
+	if utf8.RuneCountInString(content) <= discussionExcerptLength {
+		return content
+	}
+	runes := []rune(content)
+	return string(runes[:discussionExcerptLength]) + "…"
+}
+
+// discussionReplyEmailJobHandler sends a JobDiscussionReplyEmail. Nothing
+// is sent if the reply or discussion is gone, or the recipient has since
+// muted the discussion, opted out, or been deleted.
+func discussionReplyEmailJobHandler(db *gorm.DB, emailService *email.Service) jobs.Handler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var job discussionReplyEmailJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return jobs.Permanent(err)
+		}
+		if emailService == nil || !emailService.IsConfigured() {
+			return errors.New("email service is not configured")
+		}
+		db := db.WithContext(ctx)
+
+		var reply models.DiscussionReply
+		var discussion models.Discussion
+		var recipient models.User
+		for _, load := range []func() error{
+			func() error { return db.Preload("User").First(&reply, job.ReplyID).Error },
+			func() error { return db.First(&discussion, reply.DiscussionID).Error },
+			func() error { return notifiableUsers(db).First(&recipient, job.UserID).Error },
+		} {
+			if err := load(); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil
+				}
+				return err
+			}
+		}
+		var muted int64
+		if err := db.Model(&models.DiscussionParticipant{}).
+			Where("discussion_id = ? AND user_id = ? AND muted = ?", discussion.ID, recipient.ID, true).
+			Count(&muted).Error; err != nil {
+			return err
+		}
+		if muted > 0 {
+			return nil
+		}
+
+		link := fmt.Sprintf("%s/groups/%d/discussions/%d", frontendURL(), discussion.GroupID, discussion.ID)
+		ctx = groupSenderContext(ctx, db, discussion.GroupID)
+		return emailService.SendDiscussionReplyEmail(ctx, recipient.Email, reply.User.Username, discussion.Title, discussionExcerpt(reply.Content), link)
+	}
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscussions(t *testing.T) {
+	db := SetupTestDB(t)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	author := CreateTestUser(t, db, "author", "author@example.com", "password123", false)
+	replier := CreateTestUser(t, db, "replier", "replier@example.com", "password123", false)
+	lead := CreateTestUser(t, db, "lead", "lead@example.com", "password123", false)
+	outsider := CreateTestUser(t, db, "outsider", "outsider@example.com", "password123", false)
+	AddUserToGroupWithAdmin(t, db, author.ID, group.ID, false)
+	AddUserToGroupWithAdmin(t, db, replier.ID, group.ID, false)
+	AddUserToGroupWithAdmin(t, db, lead.ID, group.ID, true)
+	require.NoError(t, db.Model(&models.User{}).Where("id IN ?", []uint{author.ID, replier.ID}).
+		Update("email_notifications_enabled", true).Error)
+
+	call := func(handler gin.HandlerFunc, userID uint, method string, params gin.Params, body interface{}) (int, []byte) {
+		c, w := accountTestContext(userID, false, method, "/", body)
+		c.Params = append(gin.Params{{Key: "id", Value: itoa(group.ID)}}, params...)
+		handler(c)
+		return w.Code, w.Body.Bytes()
+	}
+
+	code, _ := call(CreateDiscussion(db), outsider.ID, http.MethodPost, nil, DiscussionRequest{Title: "Hi", Content: "Hello"})
+	assert.Equal(t, http.StatusForbidden, code)
+	code, body := call(CreateDiscussion(db), author.ID, http.MethodPost, nil, DiscussionRequest{Title: "Saturday shifts", Content: "Who can cover?"})
+	require.Equal(t, http.StatusCreated, code, string(body))
+	var discussion models.Discussion
+	require.NoError(t, json.Unmarshal(body, &discussion))
+	thread := gin.Params{{Key: "discussionId", Value: itoa(discussion.ID)}}
+
+	code, _ = call(UpdateDiscussion(db), replier.ID, http.MethodPut, thread, DiscussionRequest{Title: "Mine now", Content: "x"})
+	assert.Equal(t, http.StatusForbidden, code, "only the author edits")
+
+	reply := func(userID uint, content string, parentID *uint) models.DiscussionReply {
+		code, body := call(CreateDiscussionReply(db), userID, http.MethodPost, thread, DiscussionReplyRequest{Content: content, ParentID: parentID})
+		require.Equal(t, http.StatusCreated, code, string(body))
+		var r models.DiscussionReply
+		require.NoError(t, json.Unmarshal(body, &r))
+		return r
+	}
+	first := reply(replier.ID, "I can", nil)
+	reply(author.ID, "Thanks!", &first.ID)
+
+	other := models.Discussion{GroupID: group.ID, UserID: lead.ID, Title: "Other", Content: "x"}
+	require.NoError(t, db.Create(&other).Error)
+	otherReply := models.DiscussionReply{DiscussionID: other.ID, UserID: lead.ID, Content: "x"}
+	require.NoError(t, db.Create(&otherReply).Error)
+	code, _ = call(CreateDiscussionReply(db), replier.ID, http.MethodPost, thread, DiscussionReplyRequest{Content: "x", ParentID: &otherReply.ID})
+	assert.Equal(t, http.StatusBadRequest, code, "a parent must be in the same discussion")
+
+	// The author heard about the first reply, the replier about the second
+	var queued []models.Job
+	require.NoError(t, db.Where("type = ?", JobDiscussionReplyEmail).Order("id").Find(&queued).Error)
+	require.Len(t, queued, 2)
+
+	code, _ = call(MuteDiscussion(db), replier.ID, http.MethodPut, thread, gin.H{"muted": true})
+	require.Equal(t, http.StatusOK, code)
+	reply(lead.ID, "Great", nil)
+	require.NoError(t, db.Where("type = ?", JobDiscussionReplyEmail).Find(&queued).Error)
+	assert.Len(t, queued, 3, "only the author is told about the lead's reply")
+
+	provider := &recordingEmailProvider{}
+	queue := jobs.NewQueue(db)
+	RegisterJobHandlers(queue, db, email.NewServiceWithProvider(provider, db), nil)
+	assert.Equal(t, 3, queue.RunDue(context.Background()))
+	assert.ElementsMatch(t, []string{"author@example.com", "author@example.com"}, provider.sentTo,
+		"the replier muted before their job ran")
+
+	code, body = call(GetDiscussion(db), replier.ID, http.MethodGet, thread, nil)
+	require.Equal(t, http.StatusOK, code, string(body))
+	var detail DiscussionDetail
+	require.NoError(t, json.Unmarshal(body, &detail))
+	assert.True(t, detail.Muted)
+	assert.Equal(t, 3, detail.ReplyCount)
+	require.Len(t, detail.Replies, 3)
+	assert.Equal(t, &first.ID, detail.Replies[1].ParentID)
+
+	code, _ = call(DeleteDiscussionReply(db), replier.ID, http.MethodDelete,
+		append(thread, gin.Param{Key: "replyId", Value: itoa(detail.Replies[2].ID)}), nil)
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = call(DeleteDiscussionReply(db), lead.ID, http.MethodDelete,
+		append(thread, gin.Param{Key: "replyId", Value: itoa(first.ID)}), nil)
+	require.Equal(t, http.StatusOK, code, "group admins moderate")
+
+	code, body = call(GetDiscussions(db), author.ID, http.MethodGet, nil, nil)
+	require.Equal(t, http.StatusOK, code, string(body))
+	var list struct {
+		Items []models.Discussion `json:"items"`
+		Total int64               `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(body, &list))
+	assert.Equal(t, int64(2), list.Total)
+	require.Len(t, list.Items, 2)
+	assert.Equal(t, discussion.ID, list.Items[0].ID, "the most recently replied-to thread comes first")
+	assert.Equal(t, 2, list.Items[0].ReplyCount)
+
+	code, _ = call(DeleteDiscussion(db), author.ID, http.MethodDelete, thread, nil)
+	require.Equal(t, http.StatusOK, code)
+	var remaining int64
+	db.Model(&models.DiscussionReply{}).Where("discussion_id = ?", discussion.ID).Count(&remaining)
+	assert.Zero(t, remaining, "replies go with their discussion")
+}
+
+func TestGroupActivityFeed_Discussions(t *testing.T) {
+	db := SetupTestDB(t)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	user := CreateTestUser(t, db, "member", "member@example.com", "password123", false)
+	AddUserToGroupWithAdmin(t, db, user.ID, group.ID, false)
+	discussion := models.Discussion{GroupID: group.ID, UserID: user.ID, Title: "Supplies", Content: "Need towels"}
+	require.NoError(t, db.Create(&discussion).Error)
+	require.NoError(t, db.Create(&models.DiscussionReply{DiscussionID: discussion.ID, UserID: user.ID, Content: "On it"}).Error)
+
+	feed := func(query string) []ActivityItem {
+		c, w := accountTestContext(user.ID, false, http.MethodGet, "/"+query, nil)
+		c.Params = gin.Params{{Key: "id", Value: itoa(group.ID)}}
+		GetGroupActivityFeed(db)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp struct {
+			Items []ActivityItem `json:"items"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Items
+	}
+
+	items := feed("?type=discussions")
+	require.Len(t, items, 2)
+	assert.Equal(t, "discussion_reply", items[0].Type)
+	assert.Equal(t, "Supplies", items[0].Title)
+	assert.Equal(t, &discussion.ID, items[0].DiscussionID)
+	assert.Equal(t, "discussion", items[1].Type)
+
+	assert.Len(t, feed(""), 2)
+	assert.Empty(t, feed("?type=comments"))
+	assert.Empty(t, feed("?animal=1"), "animal filters leave discussions out")
+}
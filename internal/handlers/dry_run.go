@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+)
+
+// dryRunSampleSize caps the per-animal diffs a dry run returns
+const dryRunSampleSize = 20
+
+// errDryRun rolls back a transaction that was only run to see what it would
+// change
+var errDryRun = errors.New("dry run")
+
+// Actions of an AnimalDiff
+const (
+	AnimalDiffCreate = "create"
+	AnimalDiffUpdate = "update"
+	AnimalDiffDelete = "delete"
+)
+
+// AnimalDiff is what a bulk change would do to one animal. AnimalID is 0
+// for an animal an import would create.
+type AnimalDiff struct {
+	AnimalID uint                      `json:"animal_id,omitempty"`
+	Name     string                    `json:"name"`
+	Action   string                    `json:"action"`
+	Changes  models.AnimalFieldChanges `json:"changes"`
+}
+
+// sampleAnimalDiffs returns the diffs of up to dryRunSampleSize animals,
+// pairing before[i] with after[i]. Animals in created have no before.
+func sampleAnimalDiffs(created, before, after []models.Animal) []AnimalDiff {
+	samples := []AnimalDiff{}
+	for _, animal := range created {
+		if len(samples) == dryRunSampleSize {
+			return samples
+		}
+		samples = append(samples, AnimalDiff{Name: animal.Name, Action: AnimalDiffCreate, Changes: diffAnimal(models.Animal{}, animal)})
+	}
+	for i := range after {
+		if len(samples) == dryRunSampleSize {
+			return samples
+		}
+		changes := diffAnimal(before[i], after[i])
+		if changes == nil {
+			changes = models.AnimalFieldChanges{}
+		}
+		samples = append(samples, AnimalDiff{AnimalID: after[i].ID, Name: before[i].Name, Action: AnimalDiffUpdate, Changes: changes})
+	}
+	return samples
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type dryRunResponse struct {
+	DryRun   bool         `json:"dry_run"`
+	Count    int          `json:"count"`
+	Created  int          `json:"created"`
+	Updated  int          `json:"updated"`
+	Warnings []string     `json:"warnings"`
+	Changes  []AnimalDiff `json:"changes"`
+}
+
+func changedFields(diff AnimalDiff) map[string]string {
+	fields := map[string]string{}
+	for _, change := range diff.Changes {
+		fields[change.Field] = change.New
+	}
+	return fields
+}
+
+func TestDryRun(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	volunteer := CreateTestUser(t, db, "volunteer", "volunteer@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "Dog group")
+	rex := CreateTestAnimal(t, db, group.ID, "Rex", "Dog")
+	bella := CreateTestAnimal(t, db, group.ID, "Bella", "Dog")
+	countAnimals := func() int64 {
+		var n int64
+		require.NoError(t, db.Model(&models.Animal{}).Count(&n).Error)
+		return n
+	}
+
+	t.Run("bulk update", func(t *testing.T) {
+		status := "foster"
+		c, w := accountTestContext(admin.ID, true, http.MethodPost, "/?dry_run=true", BulkUpdateAnimalsRequest{AnimalIDs: []uint{rex.ID, bella.ID}, Status: &status})
+		BulkUpdateAnimals(db)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp dryRunResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.True(t, resp.DryRun)
+		assert.Equal(t, 2, resp.Count)
+		require.Len(t, resp.Changes, 2)
+		assert.Equal(t, AnimalDiffUpdate, resp.Changes[0].Action)
+		assert.Equal(t, "foster", changedFields(resp.Changes[0])["status"])
+
+		var unchanged models.Animal
+		require.NoError(t, db.First(&unchanged, rex.ID).Error)
+		assert.Equal(t, "available", unchanged.Status)
+		var changes int64
+		require.NoError(t, db.Model(&models.AnimalChange{}).Count(&changes).Error)
+		assert.Zero(t, changes)
+
+		// Invalid requests fail as they would without dry_run
+		bad := "missing"
+		c, w = accountTestContext(admin.ID, true, http.MethodPost, "/?dry_run=true", BulkUpdateAnimalsRequest{AnimalIDs: []uint{rex.ID}, Status: &bad})
+		BulkUpdateAnimals(db)(c)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("csv import", func(t *testing.T) {
+		csv := fmt.Sprintf("group_id,name,breed,status\n%d,Rex,Beagle,\n%d,Max,,available\n%d,Bad,,lost", group.ID, group.ID, group.ID)
+		w := importAnimalsCSVForTest(t, db, admin.ID, "?mode=upsert&dry_run=true", csv)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp dryRunResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.True(t, resp.DryRun)
+		assert.Equal(t, 1, resp.Created)
+		assert.Equal(t, 1, resp.Updated)
+		require.Len(t, resp.Warnings, 1)
+		assert.Contains(t, resp.Warnings[0], "Invalid status 'lost'")
+		require.Len(t, resp.Changes, 2)
+		assert.Equal(t, AnimalDiffCreate, resp.Changes[0].Action)
+		assert.Zero(t, resp.Changes[0].AnimalID, "new animals have no ID yet")
+		assert.Equal(t, "Max", changedFields(resp.Changes[0])["name"])
+		assert.Equal(t, rex.ID, resp.Changes[1].AnimalID)
+		assert.Equal(t, models.AnimalFieldChanges{{Field: "breed", Old: "", New: "Beagle"}}, resp.Changes[1].Changes)
+
+		assert.Equal(t, int64(2), countAnimals())
+		var unchanged models.Animal
+		require.NoError(t, db.First(&unchanged, rex.ID).Error)
+		assert.Empty(t, unchanged.Breed)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		c, w := accountTestContext(admin.ID, true, http.MethodDelete, "/?dry_run=true", nil)
+		c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}, {Key: "animalId", Value: fmt.Sprint(bella.ID)}}
+		DeleteAnimal(db)(c)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp dryRunResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, 1, resp.Count)
+		assert.Equal(t, []AnimalDiff{{AnimalID: bella.ID, Name: "Bella", Action: AnimalDiffDelete, Changes: models.AnimalFieldChanges{}}}, resp.Changes)
+		assert.Equal(t, int64(2), countAnimals())
+	})
+
+	t.Run("bulk users", func(t *testing.T) {
+		c, w := accountTestContext(admin.ID, true, http.MethodPost, "/?dry_run=true", gin.H{"action": "delete", "user_ids": []uint{volunteer.ID}})
+		BulkUserAction(db, email.NewServiceWithProvider(&recordingEmailProvider{}, db))(c)
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp BulkUserActionResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.True(t, resp.DryRun)
+		assert.False(t, resp.Applied)
+		assert.Equal(t, 1, resp.Succeeded)
+		var user models.User
+		assert.NoError(t, db.First(&user, volunteer.ID).Error, "the user isn't deleted")
+	})
+}
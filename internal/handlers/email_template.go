@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrCodeInvalidTemplate is returned for an email template that doesn't
+// parse or uses variables its type doesn't have
+const ErrCodeInvalidTemplate ErrorCode = "INVALID_TEMPLATE"
+
+// maxEmailTemplateBodyLength bounds a saved email template body
+const maxEmailTemplateBodyLength = 100000
+
+// EmailTemplateRequest saves a new version of an email template
+type EmailTemplateRequest struct {
+	Subject string `json:"subject" binding:"required,max=255"`
+	Body    string `json:"body" binding:"required"`
+}
+
+// EmailTemplatePreviewRequest renders an unsaved subject and body. Leaving
+// both empty previews the template in use.
+type EmailTemplatePreviewRequest struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// EmailTemplateSummary is an email type and the version in use
+type EmailTemplateSummary struct {
+	email.TemplateDefinition
+	Customized bool                  `json:"customized"` // An admin's version is in use instead of the built-in
+	Current    *models.EmailTemplate `json:"current,omitempty"`
+}
+
+// emailTemplateType returns the :type route parameter, responding 404 if
+// it isn't a known email type
+func emailTemplateType(c *gin.Context) (email.TemplateDefinition, bool) {
+	def, ok := email.LookupTemplate(email.TemplateType(c.Param("type")))
+	if !ok {
+		respondError(c, http.StatusNotFound, ErrCodeNotFound, "Unknown email template")
+	}
+	return def, ok
+}
+
+// activeEmailTemplate returns the version of an email type in use, or nil
+// for the built-in
+func activeEmailTemplate(db *gorm.DB, t email.TemplateType) (*models.EmailTemplate, error) {
+	var tmpl models.EmailTemplate
+	err := db.Preload("CreatedBy").Where("type = ? AND active = ?", string(t), true).Order("version DESC").First(&tmpl).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
+// saveEmailTemplateVersion stores subject and body as the next version of
+// an email type and makes it the one in use
+func saveEmailTemplateVersion(db *gorm.DB, t email.TemplateType, subject, body string, userID uint) (models.EmailTemplate, error) {
+	tmpl := models.EmailTemplate{Type: string(t), Subject: subject, Body: body, Active: true, CreatedByID: userID}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var latest int
+		if err := tx.Model(&models.EmailTemplate{}).Where("type = ?", string(t)).
+			Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.EmailTemplate{}).Where("type = ?", string(t)).Update("active", false).Error; err != nil {
+			return err
+		}
+		tmpl.Version = latest + 1
+		return tx.Create(&tmpl).Error
+	})
+	return tmpl, err
+}
+
+// GetEmailTemplates lists the email types admins can customize, with their
+// variables and the version in use (admin only)
+// Route: GET /api/admin/email-templates
+func GetEmailTemplates(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+
+		defs := email.TemplateDefinitions()
+		summaries := make([]EmailTemplateSummary, 0, len(defs))
+		for _, def := range defs {
+			current, err := activeEmailTemplate(db, def.Type)
+			if err != nil {
+				respondInternalError(c, "Failed to load email templates")
+				return
+			}
+			summaries = append(summaries, EmailTemplateSummary{TemplateDefinition: def, Customized: current != nil, Current: current})
+		}
+		respondOK(c, summaries)
+	}
+}
+
+// GetEmailTemplate returns one email type, its built-in content, and the
+// version in use (admin only)
+// Route: GET /api/admin/email-templates/:type
+func GetEmailTemplate(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		def, ok := emailTemplateType(c)
+		if !ok {
+			return
+		}
+		current, err := activeEmailTemplate(db, def.Type)
+		if err != nil {
+			respondInternalError(c, "Failed to load email template")
+			return
+		}
+		respondOK(c, EmailTemplateSummary{TemplateDefinition: def, Customized: current != nil, Current: current})
+	}
+}
+
+// GetEmailTemplateVersions returns every saved version of an email type,
+// newest first (admin only)
+// Route: GET /api/admin/email-templates/:type/versions
+func GetEmailTemplateVersions(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		def, ok := emailTemplateType(c)
+		if !ok {
+			return
+		}
+		versions := []models.EmailTemplate{}
+		if err := db.Preload("CreatedBy").Where("type = ?", string(def.Type)).Order("version DESC").Find(&versions).Error; err != nil {
+			respondInternalError(c, "Failed to load email template versions")
+			return
+		}
+		respondOK(c, versions)
+	}
+}
+
+// UpdateEmailTemplate saves a new version of an email type and starts
+// sending it (admin only). Earlier versions are kept.
+// Route: PUT /api/admin/email-templates/:type
+func UpdateEmailTemplate(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		def, ok := emailTemplateType(c)
+		if !ok {
+			return
+		}
+		var req EmailTemplateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		if len(req.Body) > maxEmailTemplateBodyLength {
+			respondBadRequest(c, "Body must be at most 100000 characters")
+			return
+		}
+		if err := email.ValidateTemplate(def.Type, req.Subject, req.Body); err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidTemplate, err.Error())
+			return
+		}
+
+		uid, _ := middleware.GetUserID(c)
+		tmpl, err := saveEmailTemplateVersion(db, def.Type, req.Subject, req.Body, uid)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to save email template", err)
+			respondInternalError(c, "Failed to save email template")
+			return
+		}
+
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventEmailTemplateUpdated, uid, map[string]interface{}{
+			"template": string(def.Type),
+			"version":  tmpl.Version,
+		})
+		respondOK(c, tmpl)
+	}
+}
+
+// RestoreEmailTemplateVersion makes an earlier version of an email type the
+// one in use by saving a copy of it as the next version (admin only)
+// Route: POST /api/admin/email-templates/:type/versions/:version/restore
+func RestoreEmailTemplateVersion(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		def, ok := emailTemplateType(c)
+		if !ok {
+			return
+		}
+		version, err := strconv.Atoi(c.Param("version"))
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid version")
+			return
+		}
+		var old models.EmailTemplate
+		if err := db.Where("type = ? AND version = ?", string(def.Type), version).First(&old).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeNotFound, "Version not found")
+			return
+		}
+		// An email type's variables may have changed since the version was
+		// saved; one that no longer renders isn't put back in use
+		if err := email.ValidateTemplate(def.Type, old.Subject, old.Body); err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidTemplate, err.Error())
+			return
+		}
+
+		uid, _ := middleware.GetUserID(c)
+		tmpl, err := saveEmailTemplateVersion(db, def.Type, old.Subject, old.Body, uid)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to restore email template", err)
+			respondInternalError(c, "Failed to restore email template")
+			return
+		}
+
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventEmailTemplateUpdated, uid, map[string]interface{}{
+			"template":      string(def.Type),
+			"version":       tmpl.Version,
+			"restored_from": version,
+		})
+		respondOK(c, tmpl)
+	}
+}
+
+// ResetEmailTemplate goes back to sending the built-in email (admin only).
+// Saved versions are kept and can be restored.
+// Route: DELETE /api/admin/email-templates/:type
+func ResetEmailTemplate(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		def, ok := emailTemplateType(c)
+		if !ok {
+			return
+		}
+		if err := db.Model(&models.EmailTemplate{}).Where("type = ?", string(def.Type)).Update("active", false).Error; err != nil {
+			respondInternalError(c, "Failed to reset email template")
+			return
+		}
+
+		uid, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventEmailTemplateUpdated, uid, map[string]interface{}{
+			"template": string(def.Type),
+			"version":  0,
+		})
+		respondOK(c, gin.H{"message": "Email template reset to the built-in default"})
+	}
+}
+
+// PreviewEmailTemplate renders a subject and body, or the version in use,
+// with each variable's example value (admin only)
+// Route: POST /api/admin/email-templates/:type/preview
+func PreviewEmailTemplate(emailService *email.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		def, ok := emailTemplateType(c)
+		if !ok {
+			return
+		}
+		var req EmailTemplatePreviewRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		subject, body, err := emailService.PreviewTemplate(c.Request.Context(), def.Type, req.Subject, req.Body)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidTemplate, err.Error())
+			return
+		}
+		respondOK(c, gin.H{"subject": subject, "html": body})
+	}
+}
+
+// SendTestEmailTemplate emails a preview to the admin making the request
+// (admin only). Test emails only go to the admin's own address.
+// Route: POST /api/admin/email-templates/:type/test
+func SendTestEmailTemplate(db *gorm.DB, emailService *email.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		def, ok := emailTemplateType(c)
+		if !ok {
+			return
+		}
+		var req EmailTemplatePreviewRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		if emailService == nil || !emailService.IsConfigured() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Email is not configured"})
+			return
+		}
+		uid, _ := middleware.GetUserID(c)
+		var user models.User
+		if err := db.First(&user, uid).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeUserNotFound, "User not found")
+			return
+		}
+		if req.Subject != "" || req.Body != "" {
+			if err := email.ValidateTemplate(def.Type, req.Subject, req.Body); err != nil {
+				respondError(c, http.StatusBadRequest, ErrCodeInvalidTemplate, err.Error())
+				return
+			}
+		}
+
+		if err := emailService.SendTestTemplate(c.Request.Context(), user.Email, def.Type, req.Subject, req.Body); err != nil {
+			middleware.GetLogger(c).Error("Failed to send test email", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to send test email"})
+			return
+		}
+		respondOK(c, gin.H{"message": "Test email sent to " + user.Email})
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailTemplateManagement(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	provider := &senderRecordingEmailProvider{}
+	emailService := email.NewServiceWithProvider(provider, db)
+	resetParams := gin.Params{{Key: "type", Value: string(email.TemplatePasswordReset)}}
+
+	call := func(handler gin.HandlerFunc, method string, params gin.Params, body interface{}) (int, []byte) {
+		c, w := accountTestContext(admin.ID, true, method, "/", body)
+		c.Params = params
+		handler(c)
+		return w.Code, w.Body.Bytes()
+	}
+
+	code, body := call(GetEmailTemplates(db), http.MethodGet, nil, nil)
+	require.Equal(t, http.StatusOK, code)
+	var summaries []EmailTemplateSummary
+	require.NoError(t, json.Unmarshal(body, &summaries))
+	require.Len(t, summaries, 4)
+	for _, s := range summaries {
+		assert.False(t, s.Customized)
+		assert.NotEmpty(t, s.Variables)
+		assert.NotEmpty(t, s.DefaultBody)
+	}
+
+	code, _ = call(GetEmailTemplate(db), http.MethodGet, gin.Params{{Key: "type", Value: "newsletter"}}, nil)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	save := func(subject, body string) (int, models.EmailTemplate) {
+		code, resp := call(UpdateEmailTemplate(db), http.MethodPut, resetParams, gin.H{"subject": subject, "body": body})
+		var tmpl models.EmailTemplate
+		_ = json.Unmarshal(resp, &tmpl)
+		return code, tmpl
+	}
+	code, _ = save("Reset", "<p>{{.SetupLink}}</p>")
+	assert.Equal(t, http.StatusBadRequest, code, "password resets have no setup link")
+	code, _ = save("Reset", "<p>{{if .Username}}</p>")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, v1 := save("Reset your password", `<p>First draft for {{.Username}}: <a href="{{.ResetLink}}">reset</a></p>`)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 1, v1.Version)
+	code, v2 := save("{{.SiteName}} password reset", `<p>Hi {{.Username}}, <a href="{{.ResetLink}}">reset</a></p>`)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 2, v2.Version)
+
+	require.NoError(t, emailService.SendPasswordResetEmail(context.Background(), "user@example.com", "sam", "tok"))
+	require.Len(t, provider.sent, 1)
+	assert.Contains(t, provider.sent[0].body, "Hi sam")
+
+	// Previews render unsaved drafts with the example values
+	code, body = call(PreviewEmailTemplate(emailService), http.MethodPost, resetParams, gin.H{"subject": "Hi {{.Username}}", "body": "<p>{{.ResetLink}}</p>"})
+	require.Equal(t, http.StatusOK, code, string(body))
+	var preview struct {
+		Subject string `json:"subject"`
+		HTML    string `json:"html"`
+	}
+	require.NoError(t, json.Unmarshal(body, &preview))
+	assert.Equal(t, "Hi jsmith", preview.Subject)
+	assert.Contains(t, preview.HTML, "https://example.org/reset-password")
+
+	// Test sends only go to the admin
+	code, _ = call(SendTestEmailTemplate(db, emailService), http.MethodPost, resetParams, gin.H{})
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, provider.sent, 2)
+	assert.Equal(t, "admin@example.com", provider.sent[1].to)
+	assert.Contains(t, provider.sent[1].body, "Hi jsmith")
+
+	// Restoring copies an old version forward
+	code, body = call(RestoreEmailTemplateVersion(db), http.MethodPost, append(resetParams, gin.Param{Key: "version", Value: "1"}), nil)
+	require.Equal(t, http.StatusOK, code, string(body))
+	code, body = call(GetEmailTemplateVersions(db), http.MethodGet, resetParams, nil)
+	require.Equal(t, http.StatusOK, code)
+	var versions []models.EmailTemplate
+	require.NoError(t, json.Unmarshal(body, &versions))
+	require.Len(t, versions, 3)
+	assert.Equal(t, 3, versions[0].Version)
+	assert.True(t, versions[0].Active)
+	assert.Equal(t, v1.Body, versions[0].Body)
+	assert.False(t, versions[1].Active)
+
+	// Resetting goes back to the built-in and keeps the history
+	code, _ = call(ResetEmailTemplate(db), http.MethodDelete, resetParams, nil)
+	require.Equal(t, http.StatusOK, code)
+	require.NoError(t, emailService.SendPasswordResetEmail(context.Background(), "user@example.com", "sam", "tok"))
+	assert.Contains(t, provider.sent[len(provider.sent)-1].body, "Password Reset Request")
+	var count int64
+	db.Model(&models.EmailTemplate{}).Count(&count)
+	assert.Equal(t, int64(3), count)
+}
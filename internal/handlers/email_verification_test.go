@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/auth"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// setVerificationToken stores a verification token for user the same way
+// sendEmailVerification does and returns the plaintext token.
+func setVerificationToken(t *testing.T, db *gorm.DB, user *models.User, expiry time.Time) string {
+	t.Helper()
+	token, err := generateSecureToken()
+	require.NoError(t, err)
+	hashed, err := auth.HashPassword(token)
+	require.NoError(t, err)
+	require.NoError(t, db.Model(user).Updates(map[string]interface{}{
+		"email_verification_token":  hashed,
+		"email_verification_lookup": token[:TokenLookupPrefixLength],
+		"email_verification_expiry": expiry,
+	}).Error)
+	return token
+}
+
+func postVerifyEmail(db *gorm.DB, token string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	body, _ := json.Marshal(map[string]string{"token": token})
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/verify-email", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	VerifyEmail(db)(c)
+	return w
+}
+
+func TestVerifyEmail(t *testing.T) {
+	t.Run("valid token marks email verified", func(t *testing.T) {
+		db := SetupTestDB(t)
+		user := CreateTestUser(t, db, "alice", "alice@example.com", "password123", false)
+		token := setVerificationToken(t, db, user, time.Now().Add(time.Hour))
+
+		w := postVerifyEmail(db, token)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var updated models.User
+		require.NoError(t, db.First(&updated, user.ID).Error)
+		assert.NotNil(t, updated.EmailVerifiedAt)
+		assert.Empty(t, updated.EmailVerificationToken, "token should be single-use")
+
+		w = postVerifyEmail(db, token)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "reusing a consumed token should fail")
+	})
+
+	t.Run("expired token is rejected", func(t *testing.T) {
+		db := SetupTestDB(t)
+		user := CreateTestUser(t, db, "bob", "bob@example.com", "password123", false)
+		token := setVerificationToken(t, db, user, time.Now().Add(-time.Minute))
+
+		w := postVerifyEmail(db, token)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "expired")
+	})
+
+	t.Run("unknown and short tokens are rejected", func(t *testing.T) {
+		db := SetupTestDB(t)
+		user := CreateTestUser(t, db, "carol", "carol@example.com", "password123", false)
+		token := setVerificationToken(t, db, user, time.Now().Add(time.Hour))
+
+		assert.Equal(t, http.StatusBadRequest, postVerifyEmail(db, "short").Code)
+		assert.Equal(t, http.StatusBadRequest, postVerifyEmail(db, token[:TokenLookupPrefixLength]+"tampered").Code)
+	})
+}
+
+func TestResendEmailVerification(t *testing.T) {
+	tests := []struct {
+		name           string
+		verified       bool
+		emailService   func(db *gorm.DB) *email.Service
+		expectedStatus int
+	}{
+		{"issues a new token", false, func(db *gorm.DB) *email.Service { return email.NewServiceWithProvider(&mockEmailProvider{}, db) }, http.StatusOK},
+		{"already verified", true, func(db *gorm.DB) *email.Service { return email.NewServiceWithProvider(&mockEmailProvider{}, db) }, http.StatusConflict},
+		{"email not configured", false, func(db *gorm.DB) *email.Service { return createTestEmailService(false, db) }, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := SetupTestDB(t)
+			user := CreateTestUser(t, db, "dave", "dave@example.com", "password123", false)
+			if tt.verified {
+				require.NoError(t, db.Model(user).Update("email_verified_at", time.Now()).Error)
+			}
+
+			gin.SetMode(gin.TestMode)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set("user_id", user.ID)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/resend-verification", nil)
+			ResendEmailVerification(db, tt.emailService(db))(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			if tt.expectedStatus == http.StatusOK {
+				var updated models.User
+				require.NoError(t, db.First(&updated, user.ID).Error)
+				assert.NotEmpty(t, updated.EmailVerificationToken)
+				assert.NotNil(t, updated.EmailVerificationExpiry)
+			}
+		})
+	}
+}
+
+func TestNotifiableUsers_EnforcesVerificationWhenConfigured(t *testing.T) {
+	db := SetupTestDB(t)
+	verified := CreateTestUser(t, db, "verified", "verified@example.com", "password123", false)
+	unverified := CreateTestUser(t, db, "unverified", "unverified@example.com", "password123", false)
+	CreateTestUser(t, db, "optedout", "optedout@example.com", "password123", false)
+	require.NoError(t, db.Model(&models.User{}).Where("id IN ?", []uint{verified.ID, unverified.ID}).
+		Update("email_notifications_enabled", true).Error)
+	require.NoError(t, db.Model(verified).Update("email_verified_at", time.Now()).Error)
+
+	var users []models.User
+	require.NoError(t, notifiableUsers(db).Find(&users).Error)
+	assert.Len(t, users, 2, "without enforcement every opted-in user is notified")
+
+	t.Setenv("REQUIRE_EMAIL_VERIFICATION", "true")
+	users = nil
+	require.NoError(t, notifiableUsers(db).Find(&users).Error)
+	require.Len(t, users, 1)
+	assert.Equal(t, verified.ID, users[0].ID)
+}
+
+func TestEmailChange_QueuesVerification(t *testing.T) {
+	db := SetupTestDB(t)
+	user := CreateTestUser(t, db, "erin", "erin@example.com", "password123", false)
+	require.NoError(t, db.Model(user).Update("email_verified_at", time.Now()).Error)
+
+	updateEmail := func(address string) {
+		c, w := accountTestContext(user.ID, false, http.MethodPut, "/api/users/me/profile", gin.H{"email": address})
+		UpdateCurrentUserProfile(db)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+	queued := func() []models.Job {
+		var queued []models.Job
+		require.NoError(t, db.Where("type = ?", JobEmailVerificationEmail).Order("id").Find(&queued).Error)
+		return queued
+	}
+
+	updateEmail("erin@example.com")
+	assert.Empty(t, queued(), "an unchanged address isn't verified again")
+
+	updateEmail("erin@example.org")
+	var updated models.User
+	require.NoError(t, db.First(&updated, user.ID).Error)
+	assert.Nil(t, updated.EmailVerifiedAt, "a new address starts unverified")
+	require.Len(t, queued(), 1)
+
+	provider := &recordingEmailProvider{}
+	handler := emailVerificationEmailJobHandler(db, email.NewServiceWithProvider(provider, db))
+	require.NoError(t, handler(context.Background(), json.RawMessage(queued()[0].Payload)))
+	assert.Equal(t, []string{"erin@example.org"}, provider.sentTo)
+	require.NoError(t, db.First(&updated, user.ID).Error)
+	assert.NotEmpty(t, updated.EmailVerificationToken)
+
+	// A link for an address the user has since replaced isn't sent
+	updateEmail("erin@example.net")
+	require.Len(t, queued(), 2)
+	provider.sentTo = nil
+	require.NoError(t, handler(context.Background(), json.RawMessage(queued()[0].Payload)))
+	assert.Empty(t, provider.sentTo)
+	require.NoError(t, handler(context.Background(), json.RawMessage(queued()[1].Payload)))
+	assert.Equal(t, []string{"erin@example.net"}, provider.sentTo)
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	ErrCodeInvalidWebhookSignature ErrorCode = "INVALID_WEBHOOK_SIGNATURE"
+
+	// maxEmailWebhookBody bounds a bounce notification; SendGrid batches
+	// events but stays well under this
+	maxEmailWebhookBody = 1 << 20
+)
+
+// markEmailsUndeliverable flags the users whose addresses the provider
+// reported. Addresses already flagged keep their first report. Reports for
+// addresses that don't belong to a user are ignored.
+func markEmailsUndeliverable(c *gin.Context, db *gorm.DB, provider string, suppressions []email.Suppression) error {
+	now := time.Now()
+	for _, s := range suppressions {
+		result := db.Model(&models.User{}).
+			Where("LOWER(email) = ? AND email_undeliverable = ?", s.Email, false).
+			Updates(map[string]interface{}{
+				"email_undeliverable":        true,
+				"email_undeliverable_reason": string(s.Reason),
+				"email_undeliverable_detail": s.Detail,
+				"email_undeliverable_at":     now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			middleware.GetLogger(c).WithFields(map[string]interface{}{
+				"email_provider": provider,
+				"reason":         string(s.Reason),
+			}).Info("Marked user email as undeliverable")
+		}
+	}
+	return nil
+}
+
+// readEmailWebhookBody reads a webhook request body, responding 400 if it's
+// unreadable or too large
+func readEmailWebhookBody(c *gin.Context) ([]byte, bool) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxEmailWebhookBody+1))
+	if err != nil || len(body) > maxEmailWebhookBody {
+		respondBadRequest(c, "Invalid request body")
+		return nil, false
+	}
+	return body, true
+}
+
+// respondEmailWebhookError maps a webhook parse error to a response: 403 for
+// a bad signature or unexpected topic, 400 for anything else
+func respondEmailWebhookError(c *gin.Context, provider string, err error) {
+	logger := middleware.GetLogger(c).WithFields(map[string]interface{}{
+		"email_provider": provider,
+		"error":          err.Error(),
+	})
+	if errors.Is(err, email.ErrInvalidWebhookSignature) {
+		logger.Warn("Rejected email webhook with invalid signature")
+		respondError(c, http.StatusForbidden, ErrCodeInvalidWebhookSignature, "Invalid webhook signature")
+		return
+	}
+	logger.Warn("Rejected malformed email webhook")
+	respondBadRequest(c, "Invalid webhook payload")
+}
+
+// SESEmailWebhook receives Amazon SES bounce and complaint notifications
+// delivered through SNS. Permanent bounces and complaints mark the matching
+// users' addresses undeliverable.
+// Route: POST /api/webhooks/email/ses
+func SESEmailWebhook(db *gorm.DB, webhook *email.SESBounceWebhook) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		body, ok := readEmailWebhookBody(c)
+		if !ok {
+			return
+		}
+		suppressions, err := webhook.Parse(c.Request.Context(), body)
+		if err != nil {
+			respondEmailWebhookError(c, "ses", err)
+			return
+		}
+		if err := markEmailsUndeliverable(c, db, "ses", suppressions); err != nil {
+			middleware.GetLogger(c).Error("Failed to record email bounces", err)
+			respondInternalError(c, "Failed to record bounces")
+			return
+		}
+		respondNoContent(c)
+	}
+}
+
+// SendGridEmailWebhook receives SendGrid Event Webhook batches. Hard bounces
+// and spam reports mark the matching users' addresses undeliverable; other
+// events are acknowledged and ignored.
+// Route: POST /api/webhooks/email/sendgrid
+func SendGridEmailWebhook(db *gorm.DB, webhook *email.SendGridBounceWebhook) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		body, ok := readEmailWebhookBody(c)
+		if !ok {
+			return
+		}
+		suppressions, err := webhook.Parse(body,
+			c.GetHeader(email.SendGridSignatureHeader),
+			c.GetHeader(email.SendGridTimestampHeader))
+		if err != nil {
+			respondEmailWebhookError(c, "sendgrid", err)
+			return
+		}
+		if err := markEmailsUndeliverable(c, db, "sendgrid", suppressions); err != nil {
+			middleware.GetLogger(c).Error("Failed to record email bounces", err)
+			respondInternalError(c, "Failed to record bounces")
+			return
+		}
+		respondNoContent(c)
+	}
+}
+
+// ClearEmailUndeliverable lifts send suppression for a user, e.g. after they
+// fix a full mailbox or a mail server misconfiguration (site admin only).
+// Changing the user's email address also clears it.
+// Route: DELETE /api/admin/users/:userId/email-undeliverable
+func ClearEmailUndeliverable(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		targetID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid user ID")
+			return
+		}
+		var user models.User
+		if err := db.First(&user, targetID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				respondNotFound(c, "User not found")
+				return
+			}
+			respondInternalError(c, "Failed to fetch user")
+			return
+		}
+		if err := db.Model(&user).Updates(map[string]interface{}{
+			"email_undeliverable":        false,
+			"email_undeliverable_reason": "",
+			"email_undeliverable_detail": "",
+			"email_undeliverable_at":     nil,
+		}).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to clear email suppression", err)
+			respondInternalError(c, "Failed to clear email suppression")
+			return
+		}
+
+		adminID, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventEmailSuppressionCleared, adminID, map[string]interface{}{
+			"target_user_id": user.ID,
+		})
+		respondOK(c, toAdminUserResponse(user))
+	}
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendGridEmailWebhook(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	gone := CreateTestUser(t, db, "gone", "Gone@example.com", "password123", false)
+	fine := CreateTestUser(t, db, "fine", "fine@example.com", "password123", false)
+	require.NoError(t, db.Model(&models.User{}).Where("id IN ?", []uint{gone.ID, fine.ID}).
+		Update("email_notifications_enabled", true).Error)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	t.Setenv("SENDGRID_WEBHOOK_PUBLIC_KEY", base64.StdEncoding.EncodeToString(der))
+	webhook, err := email.NewSendGridBounceWebhook()
+	require.NoError(t, err)
+	require.NotNil(t, webhook)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/webhooks/email/sendgrid", SendGridEmailWebhook(db, webhook))
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	post := func(body []byte, signature string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/webhooks/email/sendgrid", bytes.NewReader(body))
+		req.Header.Set(email.SendGridSignatureHeader, signature)
+		req.Header.Set(email.SendGridTimestampHeader, timestamp)
+		router.ServeHTTP(w, req)
+		return w
+	}
+	sign := func(body []byte) string {
+		digest := sha256.Sum256(append([]byte(timestamp), body...))
+		sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+		require.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(sig)
+	}
+
+	body := []byte(`[{"email": "gone@example.com", "event": "bounce", "reason": "550 user unknown"}, {"email": "fine@example.com", "event": "delivered"}]`)
+
+	// Unsigned requests change nothing
+	w := post(body, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	var user models.User
+	require.NoError(t, db.First(&user, gone.ID).Error)
+	assert.False(t, user.EmailUndeliverable)
+
+	w = post(body, sign(body))
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	require.NoError(t, db.First(&user, gone.ID).Error)
+	assert.True(t, user.EmailUndeliverable)
+	assert.Equal(t, "bounce", user.EmailUndeliverableReason)
+	assert.Equal(t, "550 user unknown", user.EmailUndeliverableDetail)
+	assert.NotNil(t, user.EmailUndeliverableAt)
+
+	// Bouncing addresses no longer receive notifications
+	var recipients []models.User
+	require.NoError(t, notifiableUsers(db.Model(&models.User{})).Find(&recipients).Error)
+	require.Len(t, recipients, 1)
+	assert.Equal(t, fine.ID, recipients[0].ID)
+
+	// Admin user lists show and filter on the flag
+	c, w := accountTestContext(admin.ID, true, http.MethodGet, "/api/admin/users?email_undeliverable=true", nil)
+	GetAllUsers(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list struct {
+		Data []struct {
+			ID                       uint   `json:"id"`
+			EmailUndeliverable       bool   `json:"email_undeliverable"`
+			EmailUndeliverableReason string `json:"email_undeliverable_reason"`
+		} `json:"data"`
+		Total int64 `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, int64(1), list.Total)
+	assert.Equal(t, gone.ID, list.Data[0].ID)
+	assert.True(t, list.Data[0].EmailUndeliverable)
+	assert.Equal(t, "bounce", list.Data[0].EmailUndeliverableReason)
+
+	// The flag stays out of non-admin responses
+	userJSON, err := json.Marshal(user)
+	require.NoError(t, err)
+	assert.NotContains(t, string(userJSON), "undeliverable")
+
+	// An admin can lift the suppression
+	c, w = accountTestContext(admin.ID, true, http.MethodDelete, "/api/admin/users/1/email-undeliverable", nil)
+	c.Params = gin.Params{{Key: "userId", Value: fmt.Sprint(gone.ID)}}
+	ClearEmailUndeliverable(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var cleared models.User
+	require.NoError(t, db.First(&cleared, gone.ID).Error)
+	assert.False(t, cleared.EmailUndeliverable)
+	assert.Empty(t, cleared.EmailUndeliverableReason)
+	assert.Nil(t, cleared.EmailUndeliverableAt)
+}
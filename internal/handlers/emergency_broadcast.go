@@ -0,0 +1,503 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/sms"
+	"gorm.io/gorm"
+)
+
+const (
+	ErrCodeSMSNotConfigured   ErrorCode = "SMS_NOT_CONFIGURED"
+	ErrCodeInvalidPhoneNumber ErrorCode = "INVALID_PHONE_NUMBER"
+
+	// JobEmergencyBroadcastSMS sends one recipient's copy of an emergency
+	// broadcast
+	JobEmergencyBroadcastSMS = "emergency_broadcast_sms"
+)
+
+type emergencyBroadcastSMSJob struct {
+	DeliveryID uint `json:"delivery_id"`
+}
+
+// SMSPreferences is the current user's emergency broadcast SMS opt-in
+type SMSPreferences struct {
+	PhoneNumber string `json:"phone_number"`
+	SMSOptIn    bool   `json:"sms_opt_in"`
+}
+
+// UpdateSMSPreferencesRequest changes the SMS opt-in and, optionally, the
+// phone number texts go to
+type UpdateSMSPreferencesRequest struct {
+	SMSOptIn    bool    `json:"sms_opt_in"`
+	PhoneNumber *string `json:"phone_number" binding:"omitempty,max=32"`
+}
+
+// CreateEmergencyBroadcastRequest is the text of an emergency broadcast,
+// kept to a few SMS segments
+type CreateEmergencyBroadcastRequest struct {
+	Message string `json:"message" binding:"required,min=1,max=480"`
+}
+
+// EmergencyBroadcastResponse is a broadcast with a count of its deliveries
+// by status (pending, sent, delivered, failed)
+type EmergencyBroadcastResponse struct {
+	models.EmergencyBroadcast
+	StatusCounts map[string]int64 `json:"status_counts"`
+}
+
+// RegisterSMSJobHandlers registers the emergency broadcast SMS job. It is
+// separate from RegisterJobHandlers because it needs the SMS provider,
+// which is nil when SMS isn't configured; queued texts then fail.
+func RegisterSMSJobHandlers(queue *jobs.Queue, db *gorm.DB, provider sms.Provider) {
+	queue.Register(JobEmergencyBroadcastSMS, emergencyBroadcastSMSJobHandler(db, provider))
+}
+
+// GetSMSPreferences returns the current user's emergency broadcast SMS opt-in
+// Route: GET /api/me/sms-preferences
+func GetSMSPreferences(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+
+		var user models.User
+		if err := db.Select("id", "phone_number", "sms_opt_in").First(&user, userID).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeUserNotFound, "User not found")
+			return
+		}
+		respondOK(c, SMSPreferences{PhoneNumber: user.PhoneNumber, SMSOptIn: user.SMSOptIn})
+	}
+}
+
+// UpdateSMSPreferences opts the current user in to or out of emergency
+// broadcast texts. Opting in needs a phone number that can be converted to
+// E.164. A number sent while opting out must be valid too, or empty to
+// clear it; numbers are stored in E.164 form.
+// Route: PUT /api/me/sms-preferences
+func UpdateSMSPreferences(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+		var req UpdateSMSPreferencesRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+
+		var user models.User
+		if err := db.Select("id", "phone_number", "sms_opt_in").First(&user, userID).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeUserNotFound, "User not found")
+			return
+		}
+
+		phone := user.PhoneNumber
+		if req.PhoneNumber != nil {
+			phone = strings.TrimSpace(*req.PhoneNumber)
+		}
+		// A number supplied while opting out is stored in the same form, so
+		// opting back in later can use it; only clearing it skips the check
+		if req.SMSOptIn || (req.PhoneNumber != nil && phone != "") {
+			normalized, err := sms.NormalizePhoneNumber(phone, sms.DefaultCountryCode())
+			if err != nil {
+				respondError(c, http.StatusBadRequest, ErrCodeInvalidPhoneNumber, "A valid mobile phone number is required to receive texts")
+				return
+			}
+			phone = normalized
+		}
+
+		if err := db.Model(&user).Updates(map[string]interface{}{
+			"phone_number": phone,
+			"sms_opt_in":   req.SMSOptIn,
+		}).Error; err != nil {
+			respondInternalError(c, "Failed to update SMS preferences")
+			return
+		}
+		respondOK(c, SMSPreferences{PhoneNumber: phone, SMSOptIn: req.SMSOptIn})
+	}
+}
+
+// smsRecipients selects the opted-in users with a phone number, limited to
+// the members of groupID unless it is nil
+func smsRecipients(db *gorm.DB, groupID *uint) *gorm.DB {
+	query := db.Model(&models.User{}).Where("users.sms_opt_in = ? AND users.phone_number <> ''", true)
+	if groupID != nil {
+		query = query.Where("users.id IN (SELECT user_id FROM user_groups WHERE group_id = ?)", *groupID)
+	}
+	return query
+}
+
+// createEmergencyBroadcast records a broadcast and queues a text to each
+// recipient, responding with the broadcast
+func createEmergencyBroadcast(c *gin.Context, db *gorm.DB, provider sms.Provider, group *models.Group) {
+	if provider == nil || !provider.IsConfigured() {
+		respondError(c, http.StatusServiceUnavailable, ErrCodeSMSNotConfigured, "SMS is not configured")
+		return
+	}
+	var req CreateEmergencyBroadcastRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		respondInternalError(c, "User context not found")
+		return
+	}
+
+	broadcast := models.EmergencyBroadcast{SentByID: userID, Message: req.Message}
+	if group != nil {
+		broadcast.GroupID = &group.ID
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var recipients []models.User
+		if err := smsRecipients(tx, broadcast.GroupID).Select("users.id", "users.phone_number").Find(&recipients).Error; err != nil {
+			return err
+		}
+		broadcast.RecipientCount = len(recipients)
+		if err := tx.Create(&broadcast).Error; err != nil {
+			return err
+		}
+		if len(recipients) == 0 {
+			return nil
+		}
+
+		deliveries := make([]models.EmergencyBroadcastDelivery, len(recipients))
+		for i, u := range recipients {
+			deliveries[i] = models.EmergencyBroadcastDelivery{
+				BroadcastID: broadcast.ID,
+				UserID:      u.ID,
+				PhoneNumber: u.PhoneNumber,
+				Status:      models.SMSDeliveryPending,
+			}
+		}
+		if err := tx.Create(&deliveries).Error; err != nil {
+			return err
+		}
+		payloads := make([]interface{}, len(deliveries))
+		for i, d := range deliveries {
+			payloads[i] = emergencyBroadcastSMSJob{DeliveryID: d.ID}
+		}
+		return jobs.EnqueueMany(tx, JobEmergencyBroadcastSMS, payloads)
+	})
+	if err != nil {
+		middleware.GetLogger(c).Error("Failed to create emergency broadcast", err)
+		respondInternalError(c, "Failed to send emergency broadcast")
+		return
+	}
+
+	fields := map[string]interface{}{
+		"broadcast_id":    broadcast.ID,
+		"recipient_count": broadcast.RecipientCount,
+	}
+	if group != nil {
+		fields["group_id"] = group.ID
+	}
+	logging.LogAdminAction(c.Request.Context(), logging.AuditEventEmergencyBroadcast, userID, fields)
+
+	counts := newDeliveryStatusCounts()
+	counts[models.SMSDeliveryPending] = int64(broadcast.RecipientCount)
+	respondCreated(c, EmergencyBroadcastResponse{EmergencyBroadcast: broadcast, StatusCounts: counts})
+}
+
+// CreateGroupEmergencyBroadcast texts an urgent message to the group's
+// members who opted in to SMS (group admin or site admin). Sends are rate
+// limited and written to the audit log.
+// Route: POST /api/groups/:id/emergency-broadcasts
+func CreateGroupEmergencyBroadcast(db *gorm.DB, provider sms.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+		if !checkGroupAdminAccess(db, userID, isAdmin, c.Param("id")) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
+			return
+		}
+		var group models.Group
+		if err := db.First(&group, c.Param("id")).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, "Group not found")
+			return
+		}
+		createEmergencyBroadcast(c, db, provider, &group)
+	}
+}
+
+// CreateEmergencyBroadcast texts an urgent message to every user who opted
+// in to SMS (site admin only). Sends are rate limited and written to the
+// audit log.
+// Route: POST /api/admin/emergency-broadcasts
+func CreateEmergencyBroadcast(db *gorm.DB, provider sms.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		createEmergencyBroadcast(c, middleware.GetDB(c, db), provider, nil)
+	}
+}
+
+// newDeliveryStatusCounts returns a zero count for every delivery status
+func newDeliveryStatusCounts() map[string]int64 {
+	return map[string]int64{
+		models.SMSDeliveryPending:   0,
+		models.SMSDeliverySent:      0,
+		models.SMSDeliveryDelivered: 0,
+		models.SMSDeliveryFailed:    0,
+	}
+}
+
+// deliveryStatusCounts counts each broadcast's deliveries by status
+func deliveryStatusCounts(db *gorm.DB, broadcastIDs []uint) (map[uint]map[string]int64, error) {
+	var rows []struct {
+		BroadcastID uint
+		Status      string
+		Count       int64
+	}
+	if err := db.Model(&models.EmergencyBroadcastDelivery{}).
+		Select("broadcast_id, status, COUNT(*) AS count").
+		Where("broadcast_id IN ?", broadcastIDs).
+		Group("broadcast_id, status").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[uint]map[string]int64, len(broadcastIDs))
+	for _, id := range broadcastIDs {
+		counts[id] = newDeliveryStatusCounts()
+	}
+	for _, r := range rows {
+		counts[r.BroadcastID][r.Status] = r.Count
+	}
+	return counts, nil
+}
+
+// listEmergencyBroadcasts responds with the newest 50 broadcasts matching
+// query and their delivery counts
+func listEmergencyBroadcasts(c *gin.Context, db *gorm.DB, query *gorm.DB) {
+	var broadcasts []models.EmergencyBroadcast
+	if err := query.Preload("SentBy", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "username", "first_name", "last_name")
+	}).Order("created_at DESC, id DESC").Limit(50).Find(&broadcasts).Error; err != nil {
+		respondInternalError(c, "Failed to fetch emergency broadcasts")
+		return
+	}
+
+	ids := make([]uint, len(broadcasts))
+	for i, b := range broadcasts {
+		ids[i] = b.ID
+	}
+	counts, err := deliveryStatusCounts(db, ids)
+	if err != nil {
+		respondInternalError(c, "Failed to fetch emergency broadcasts")
+		return
+	}
+
+	response := make([]EmergencyBroadcastResponse, len(broadcasts))
+	for i, b := range broadcasts {
+		response[i] = EmergencyBroadcastResponse{EmergencyBroadcast: b, StatusCounts: counts[b.ID]}
+	}
+	respondOK(c, response)
+}
+
+// GetGroupEmergencyBroadcasts lists a group's recent emergency broadcasts
+// with delivery counts (group admin or site admin).
+// Route: GET /api/groups/:id/emergency-broadcasts
+func GetGroupEmergencyBroadcasts(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+		if !checkGroupAdminAccess(db, userID, isAdmin, c.Param("id")) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
+			return
+		}
+		listEmergencyBroadcasts(c, db, db.Where("group_id = ?", c.Param("id")))
+	}
+}
+
+// GetEmergencyBroadcasts lists recent emergency broadcasts, site-wide and
+// per group, with delivery counts (site admin only).
+// Route: GET /api/admin/emergency-broadcasts
+func GetEmergencyBroadcasts(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		listEmergencyBroadcasts(c, db, db.Preload("Group", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "name")
+		}))
+	}
+}
+
+// GetEmergencyBroadcast returns a broadcast with each recipient's delivery
+// status. Group admins can see their groups' broadcasts; site admins can
+// see all of them.
+// Route: GET /api/emergency-broadcasts/:broadcastId
+func GetEmergencyBroadcast(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		broadcastID, err := strconv.ParseUint(c.Param("broadcastId"), 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid broadcast ID")
+			return
+		}
+
+		var broadcast models.EmergencyBroadcast
+		if err := db.Preload("SentBy", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "username", "first_name", "last_name")
+		}).Preload("Deliveries", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).Preload("Deliveries.User", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "username", "first_name", "last_name")
+		}).First(&broadcast, broadcastID).Error; err != nil {
+			respondNotFound(c, "Emergency broadcast not found")
+			return
+		}
+		allowed := middleware.IsSiteAdmin(c)
+		if !allowed && broadcast.GroupID != nil {
+			allowed = IsGroupAdminOrSiteAdmin(c, db, *broadcast.GroupID)
+		}
+		if !allowed {
+			respondNotFound(c, "Emergency broadcast not found")
+			return
+		}
+
+		counts, err := deliveryStatusCounts(db, []uint{broadcast.ID})
+		if err != nil {
+			respondInternalError(c, "Failed to fetch emergency broadcast")
+			return
+		}
+		respondOK(c, EmergencyBroadcastResponse{EmergencyBroadcast: broadcast, StatusCounts: counts[broadcast.ID]})
+	}
+}
+
+// deliveryStatusRank orders delivery statuses so that late or repeated
+// status callbacks never move a delivery backwards
+var deliveryStatusRank = map[string]int{
+	models.SMSDeliveryPending:   0,
+	models.SMSDeliverySent:      1,
+	models.SMSDeliveryDelivered: 2,
+	models.SMSDeliveryFailed:    2,
+}
+
+// TwilioSMSStatusWebhook receives Twilio delivery status callbacks for
+// emergency broadcast texts. Callbacks for unknown messages are
+// acknowledged and ignored.
+// Route: POST /api/webhooks/sms/twilio
+func TwilioSMSStatusWebhook(db *gorm.DB, webhook *sms.TwilioStatusWebhook) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxEmailWebhookBody)
+		if err := c.Request.ParseForm(); err != nil {
+			respondBadRequest(c, "Invalid request body")
+			return
+		}
+		update, err := webhook.Parse(c.Request.PostForm, c.GetHeader("X-Twilio-Signature"))
+		if err != nil {
+			logger := middleware.GetLogger(c).WithField("error", err.Error())
+			if errors.Is(err, sms.ErrInvalidWebhookSignature) {
+				logger.Warn("Rejected SMS webhook with invalid signature")
+				respondError(c, http.StatusForbidden, ErrCodeInvalidWebhookSignature, "Invalid webhook signature")
+				return
+			}
+			logger.Warn("Rejected malformed SMS webhook")
+			respondBadRequest(c, "Invalid webhook payload")
+			return
+		}
+
+		var delivery models.EmergencyBroadcastDelivery
+		if err := db.Where("provider_message_id = ?", update.MessageID).First(&delivery).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				respondNoContent(c)
+				return
+			}
+			respondInternalError(c, "Failed to record delivery status")
+			return
+		}
+		if deliveryStatusRank[update.Status] <= deliveryStatusRank[delivery.Status] {
+			respondNoContent(c)
+			return
+		}
+		updates := map[string]interface{}{"status": update.Status}
+		if update.Status == models.SMSDeliveryFailed && update.ErrorCode != "" {
+			updates["error"] = "Provider error " + update.ErrorCode
+		}
+		if err := db.Model(&delivery).Updates(updates).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to record SMS delivery status", err)
+			respondInternalError(c, "Failed to record delivery status")
+			return
+		}
+		respondNoContent(c)
+	}
+}
+
+func emergencyBroadcastSMSJobHandler(db *gorm.DB, provider sms.Provider) jobs.Handler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var job emergencyBroadcastSMSJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return jobs.Permanent(err)
+		}
+		db := db.WithContext(ctx)
+
+		var delivery models.EmergencyBroadcastDelivery
+		if err := db.First(&delivery, job.DeliveryID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		if delivery.Status != models.SMSDeliveryPending {
+			return nil
+		}
+		fail := func(reason error) error {
+			return db.Model(&delivery).Updates(map[string]interface{}{
+				"status": models.SMSDeliveryFailed,
+				"error":  reason.Error(),
+			}).Error
+		}
+		if provider == nil || !provider.IsConfigured() {
+			return fail(errors.New("SMS is not configured"))
+		}
+
+		var broadcast models.EmergencyBroadcast
+		if err := db.Preload("Group").First(&broadcast, delivery.BroadcastID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		to, err := sms.NormalizePhoneNumber(delivery.PhoneNumber, sms.DefaultCountryCode())
+		if err != nil {
+			return fail(err)
+		}
+		body := broadcast.Message
+		if broadcast.Group != nil {
+			body = fmt.Sprintf("[%s] %s", broadcast.Group.Name, body)
+		}
+
+		messageID, err := provider.Send(ctx, to, body)
+		if errors.Is(err, sms.ErrRecipientRejected) {
+			return fail(err)
+		}
+		if err != nil {
+			_ = db.Model(&delivery).Update("error", err.Error()).Error
+			return err
+		}
+		return db.Model(&delivery).Updates(map[string]interface{}{
+			"status":              models.SMSDeliverySent,
+			"provider_message_id": messageID,
+			"error":               "",
+		}).Error
+	}
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/sms"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSMSProvider records texts instead of sending them, rejecting
+// numbers in reject
+type recordingSMSProvider struct {
+	sent   []string
+	reject map[string]bool
+}
+
+func (p *recordingSMSProvider) Send(_ context.Context, to, body string) (string, error) {
+	if p.reject[to] {
+		return "", fmt.Errorf("%w: unsubscribed", sms.ErrRecipientRejected)
+	}
+	p.sent = append(p.sent, to+": "+body)
+	return fmt.Sprintf("SM%d", len(p.sent)), nil
+}
+func (p *recordingSMSProvider) IsConfigured() bool      { return true }
+func (p *recordingSMSProvider) GetProviderName() string { return "recording" }
+
+func TestUpdateSMSPreferences(t *testing.T) {
+	db := SetupTestDB(t)
+	user := CreateTestUser(t, db, "vol", "vol@example.com", "password123", false)
+
+	update := func(body any) (int, SMSPreferences) {
+		c, w := accountTestContext(user.ID, false, http.MethodPut, "/api/me/sms-preferences", body)
+		UpdateSMSPreferences(db)(c)
+		var prefs SMSPreferences
+		_ = json.Unmarshal(w.Body.Bytes(), &prefs)
+		return w.Code, prefs
+	}
+
+	code, _ := update(gin.H{"sms_opt_in": true})
+	assert.Equal(t, http.StatusBadRequest, code, "opting in needs a phone number")
+	code, _ = update(gin.H{"sms_opt_in": true, "phone_number": "555-CALL"})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, prefs := update(gin.H{"sms_opt_in": true, "phone_number": "(555) 123-4567"})
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, SMSPreferences{PhoneNumber: "+15551234567", SMSOptIn: true}, prefs)
+
+	code, prefs = update(gin.H{"sms_opt_in": false})
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, SMSPreferences{PhoneNumber: "+15551234567", SMSOptIn: false}, prefs, "opting out keeps the number")
+
+	// A number sent while opting out is normalized like one sent to opt in
+	code, prefs = update(gin.H{"sms_opt_in": false, "phone_number": "555.987.6543"})
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, SMSPreferences{PhoneNumber: "+15559876543", SMSOptIn: false}, prefs)
+	code, _ = update(gin.H{"sms_opt_in": false, "phone_number": "555-CALL"})
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, prefs = update(gin.H{"sms_opt_in": false, "phone_number": ""})
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, SMSPreferences{SMSOptIn: false}, prefs, "an empty number clears it")
+
+	var stored models.User
+	require.NoError(t, db.First(&stored, user.ID).Error)
+	assert.Empty(t, stored.PhoneNumber)
+}
+
+func TestGroupEmergencyBroadcast(t *testing.T) {
+	db := SetupTestDB(t)
+	lead := CreateTestUser(t, db, "lead", "lead@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	AddUserToGroupWithAdmin(t, db, lead.ID, group.ID, true)
+	gid := fmt.Sprint(group.ID)
+
+	for _, m := range []struct {
+		name, phone string
+		optIn       bool
+	}{
+		{"alice", "+15551110001", true},
+		{"bob", "+15551110002", true},
+		{"carol", "+15551110003", false},
+		{"dave", "", true},
+	} {
+		u := CreateTestUser(t, db, m.name, m.name+"@example.com", "password123", false)
+		AddUserToGroupWithAdmin(t, db, u.ID, group.ID, false)
+		require.NoError(t, db.Model(u).Updates(map[string]interface{}{"phone_number": m.phone, "sms_opt_in": m.optIn}).Error)
+	}
+	outsider := CreateTestUser(t, db, "outsider", "outsider@example.com", "password123", false)
+	require.NoError(t, db.Model(outsider).Updates(map[string]interface{}{"phone_number": "+15551110009", "sms_opt_in": true}).Error)
+
+	provider := &recordingSMSProvider{reject: map[string]bool{"+15551110002": true}}
+	queue := jobs.NewQueue(db)
+	RegisterSMSJobHandlers(queue, db, provider)
+
+	broadcast := func(userID uint, provider sms.Provider) (int, EmergencyBroadcastResponse) {
+		c, w := accountTestContext(userID, false, http.MethodPost, "/api/groups/"+gid+"/emergency-broadcasts",
+			gin.H{"message": "Buddy got out near the east gate"})
+		c.Params = gin.Params{{Key: "id", Value: gid}}
+		CreateGroupEmergencyBroadcast(db, provider)(c)
+		var resp EmergencyBroadcastResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	code, _ := broadcast(lead.ID, nil)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	var alice models.User
+	require.NoError(t, db.Where("username = ?", "alice").First(&alice).Error)
+	code, _ = broadcast(alice.ID, provider)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	// Only opted-in members with a number are texted
+	code, created := broadcast(lead.ID, provider)
+	require.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, 2, created.RecipientCount)
+	assert.Equal(t, int64(2), created.StatusCounts[models.SMSDeliveryPending])
+
+	assert.Equal(t, 2, queue.RunDue(context.Background()))
+	assert.Equal(t, []string{"+15551110001: [Dogs] Buddy got out near the east gate"}, provider.sent)
+
+	get := func() EmergencyBroadcastResponse {
+		c, w := accountTestContext(lead.ID, false, http.MethodGet, "/", nil)
+		c.Params = gin.Params{{Key: "broadcastId", Value: fmt.Sprint(created.ID)}}
+		GetEmergencyBroadcast(db)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp EmergencyBroadcastResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+	detail := get()
+	assert.Equal(t, map[string]int64{"pending": 0, "sent": 1, "delivered": 0, "failed": 1}, detail.StatusCounts)
+	require.Len(t, detail.Deliveries, 2)
+	assert.Equal(t, "alice", detail.Deliveries[0].User.Username)
+	assert.Equal(t, "SM1", detail.Deliveries[0].ProviderMessageID)
+	assert.Contains(t, detail.Deliveries[1].Error, "unsubscribed")
+
+	c, w := accountTestContext(outsider.ID, false, http.MethodGet, "/", nil)
+	c.Params = gin.Params{{Key: "broadcastId", Value: fmt.Sprint(created.ID)}}
+	GetEmergencyBroadcast(db)(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Status callbacks move deliveries forward, never back
+	webhook := &sms.TwilioStatusWebhook{AuthToken: "secret", CallbackURL: "https://example.org/api/webhooks/sms/twilio"}
+	callback := func(status, signatureKey string) int {
+		form := url.Values{"MessageSid": {"SM1"}, "MessageStatus": {status}}
+		req := httptest.NewRequest(http.MethodPost, "/api/webhooks/sms/twilio", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Twilio-Signature", sms.TwilioSignature(signatureKey, webhook.CallbackURL, form))
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		TwilioSMSStatusWebhook(db, webhook)(c)
+		return c.Writer.Status()
+	}
+	assert.Equal(t, http.StatusForbidden, callback("delivered", "wrong"))
+	assert.Equal(t, http.StatusNoContent, callback("delivered", "secret"))
+	assert.Equal(t, http.StatusNoContent, callback("sent", "secret"))
+	assert.Equal(t, int64(1), get().StatusCounts[models.SMSDeliveryDelivered])
+}
@@ -0,0 +1,460 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// customFieldVisibilityPrefix marks a field visibility entry for one of the
+// group's custom fields, as in "field.microchip_vendor"
+const customFieldVisibilityPrefix = "field."
+
+// restrictableAnimalFields are the animal fields a group can restrict to a
+// minimum role. behavior_assessments covers the behavior assessment
+// endpoint and the score in animal comparisons.
+var restrictableAnimalFields = map[string]bool{
+	"trainer_notes":               true,
+	"quarantine_incident_details": true,
+	"bq_incidents":                true,
+	"quarantine_start_date":       true,
+	"quarantine_end_date":         true,
+	"quarantine_approval_status":  true,
+	"quarantine_approval_date":    true,
+	"microchip_number":            true,
+	"license_number":              true,
+	"intake_source":               true,
+	"outcome":                     true,
+	"current_weight":              true,
+	"comment_tag_counts":          true,
+	"behavior_assessments":        true,
+}
+
+// defaultFieldVisibility keeps bite history, trainer notes, and behavior
+// assessments from brand-new volunteers unless a group says otherwise.
+// Fields not listed are visible to every member.
+var defaultFieldVisibility = map[string]string{
+	"trainer_notes":               models.GroupRoleVolunteer,
+	"quarantine_incident_details": models.GroupRoleVolunteer,
+	"bq_incidents":                models.GroupRoleVolunteer,
+	"behavior_assessments":        models.GroupRoleVolunteer,
+}
+
+// FieldVisibilityRequest replaces a group's field visibility overrides.
+// Fields maps an animal field, or "field.<key>" for a custom field, to the
+// lowest role that sees it: new_volunteer, volunteer, or group_admin.
+type FieldVisibilityRequest struct {
+	Fields map[string]string `json:"fields"`
+}
+
+// MemberRoleRequest sets a member's role in a group
+type MemberRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// groupRoleRank orders the group roles; unknown roles rank lowest
+func groupRoleRank(role string) int {
+	for i, r := range models.GroupRoles {
+		if r == role {
+			return i
+		}
+	}
+	return 0
+}
+
+// viewerGroupRole returns the requesting user's role in a group. Members
+// without a recorded role are volunteers; users with no membership row get
+// the lowest role.
+func viewerGroupRole(c *gin.Context, db *gorm.DB, groupID uint) (string, error) {
+	if middleware.IsSiteAdmin(c) {
+		return models.GroupRoleSiteAdmin, nil
+	}
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return models.GroupRoleNewVolunteer, nil
+	}
+	if groupClaims(db).IsGroupAdmin(userID, groupID) {
+		return models.GroupRoleAdmin, nil
+	}
+	var userGroup models.UserGroup
+	err := db.Where("user_id = ? AND group_id = ?", userID, groupID).Limit(1).Find(&userGroup).Error
+	if err != nil {
+		return "", err
+	}
+	if userGroup.UserID == 0 {
+		return models.GroupRoleNewVolunteer, nil
+	}
+	return memberGroupRole(userGroup), nil
+}
+
+// memberGroupRole returns a member's effective role: group admins rank as
+// such whatever their recorded role, and members without one are volunteers
+func memberGroupRole(userGroup models.UserGroup) string {
+	switch {
+	case userGroup.IsGroupAdmin:
+		return models.GroupRoleAdmin
+	case userGroup.Role == "":
+		return models.GroupRoleVolunteer
+	}
+	return userGroup.Role
+}
+
+// groupFieldVisibility returns a group's effective field visibility: the
+// defaults with the group's overrides applied
+func groupFieldVisibility(db *gorm.DB, groupID uint) (map[string]string, error) {
+	var overrides []models.GroupFieldVisibility
+	if err := db.Where("group_id = ?", groupID).Find(&overrides).Error; err != nil {
+		return nil, err
+	}
+	visibility := make(map[string]string, len(defaultFieldVisibility)+len(overrides))
+	for field, role := range defaultFieldVisibility {
+		visibility[field] = role
+	}
+	for _, o := range overrides {
+		visibility[o.Field] = o.MinRole
+	}
+	return visibility, nil
+}
+
+// fieldPolicy is the set of animal fields hidden from one viewer
+type fieldPolicy struct {
+	hidden map[string]bool
+}
+
+// loadFieldPolicy returns the fields hidden from the requesting user in a
+// group
+func loadFieldPolicy(c *gin.Context, db *gorm.DB, groupID uint) (fieldPolicy, error) {
+	role, err := viewerGroupRole(c, db, groupID)
+	if err != nil {
+		return fieldPolicy{}, err
+	}
+	visibility, err := groupFieldVisibility(db, groupID)
+	if err != nil {
+		return fieldPolicy{}, err
+	}
+	policy := fieldPolicy{hidden: map[string]bool{}}
+	rank := groupRoleRank(role)
+	for field, minRole := range visibility {
+		if rank < groupRoleRank(minRole) {
+			policy.hidden[field] = true
+		}
+	}
+	return policy, nil
+}
+
+// Hides reports whether field is hidden from the viewer
+func (p fieldPolicy) Hides(field string) bool {
+	return p.hidden[field]
+}
+
+// CustomFields returns values without the custom fields hidden from the
+// viewer
+func (p fieldPolicy) CustomFields(values models.AnimalCustomValues) models.AnimalCustomValues {
+	if len(p.hidden) == 0 || values == nil {
+		return values
+	}
+	visible := make(models.AnimalCustomValues, len(values))
+	for key, value := range values {
+		if !p.hidden[customFieldVisibilityPrefix+key] {
+			visible[key] = value
+		}
+	}
+	return visible
+}
+
+// Changes returns changes without the entries for fields hidden from the
+// viewer. Custom fields are recorded as "custom_fields.<key>".
+func (p fieldPolicy) Changes(changes []models.AnimalFieldChange) []models.AnimalFieldChange {
+	if len(p.hidden) == 0 || changes == nil {
+		return changes
+	}
+	visible := make([]models.AnimalFieldChange, 0, len(changes))
+	for _, change := range changes {
+		field := change.Field
+		if key, ok := strings.CutPrefix(field, "custom_fields."); ok {
+			field = customFieldVisibilityPrefix + key
+		}
+		if !p.hidden[field] {
+			visible = append(visible, change)
+		}
+	}
+	return visible
+}
+
+// Redact returns v, an animal or a list of animals, as JSON without the
+// fields hidden from the viewer. v is returned unchanged when nothing is
+// hidden.
+func (p fieldPolicy) Redact(v interface{}) (interface{}, error) {
+	if len(p.hidden) == 0 {
+		return v, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(string(data), "[") {
+		var items []map[string]json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if err := p.redactObject(item); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	var item map[string]json.RawMessage
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, err
+	}
+	if err := p.redactObject(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (p fieldPolicy) redactObject(item map[string]json.RawMessage) error {
+	for field := range p.hidden {
+		if !strings.HasPrefix(field, customFieldVisibilityPrefix) {
+			delete(item, field)
+		}
+	}
+	raw, ok := item["custom_fields"]
+	if !ok {
+		return nil
+	}
+	var values models.AnimalCustomValues
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return err
+	}
+	data, err := json.Marshal(p.CustomFields(values))
+	if err != nil {
+		return err
+	}
+	item["custom_fields"] = data
+	return nil
+}
+
+// respondRedacted responds with v redacted for the viewer's role in a group
+func respondRedacted(c *gin.Context, db *gorm.DB, groupID uint, v interface{}) {
+	redacted, ok := redactForViewer(c, db, groupID, v)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, redacted)
+}
+
+// redactForViewer is respondRedacted for a value that goes into a larger
+// response. It responds with an error itself when it returns false.
+func redactForViewer(c *gin.Context, db *gorm.DB, groupID uint, v interface{}) (interface{}, bool) {
+	policy, err := loadFieldPolicy(c, db, groupID)
+	if err != nil {
+		middleware.GetLogger(c).Error("Failed to load field visibility", err)
+		respondInternalError(c, "Failed to load field visibility")
+		return nil, false
+	}
+	redacted, err := policy.Redact(v)
+	if err != nil {
+		middleware.GetLogger(c).Error("Failed to apply field visibility", err)
+		respondInternalError(c, "Failed to apply field visibility")
+		return nil, false
+	}
+	return redacted, true
+}
+
+// buildFieldVisibility validates req and converts it to rows for groupID.
+// Returns a user-facing error on invalid input.
+func buildFieldVisibility(groupID uint, req FieldVisibilityRequest, customFields []models.AnimalCustomField) ([]models.GroupFieldVisibility, error) {
+	customKeys := make(map[string]bool, len(customFields))
+	for _, f := range customFields {
+		customKeys[f.Key] = true
+	}
+	fields := make([]string, 0, len(req.Fields))
+	for field := range req.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	rows := make([]models.GroupFieldVisibility, 0, len(fields))
+	for _, field := range fields {
+		if key, ok := strings.CutPrefix(field, customFieldVisibilityPrefix); ok {
+			if !customKeys[key] {
+				return nil, fmt.Errorf("unknown custom field %q", key)
+			}
+		} else if !restrictableAnimalFields[field] {
+			return nil, fmt.Errorf("field %q can't be restricted", field)
+		}
+		role := req.Fields[field]
+		switch role {
+		case models.GroupRoleNewVolunteer, models.GroupRoleVolunteer, models.GroupRoleAdmin:
+		default:
+			return nil, fmt.Errorf("%s: min role must be new_volunteer, volunteer, or group_admin", field)
+		}
+		rows = append(rows, models.GroupFieldVisibility{GroupID: groupID, Field: field, MinRole: role})
+	}
+	return rows, nil
+}
+
+// GetFieldVisibility returns a group's effective field visibility and the
+// requesting user's role in the group
+// Route: GET /api/groups/:id/field-visibility
+func GetFieldVisibility(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+
+		visibility, err := groupFieldVisibility(db, uint(gid))
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to load field visibility", err)
+			respondInternalError(c, "Failed to load field visibility")
+			return
+		}
+		role, err := viewerGroupRole(c, db, uint(gid))
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to load group role", err)
+			respondInternalError(c, "Failed to load field visibility")
+			return
+		}
+		respondOK(c, gin.H{"fields": visibility, "role": role})
+	}
+}
+
+// UpdateFieldVisibility replaces a group's field visibility overrides
+// (group admin or site admin). Fields left out fall back to the defaults.
+// Route: PUT /api/groups/:id/field-visibility
+func UpdateFieldVisibility(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
+			return
+		}
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+
+		var req FieldVisibilityRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		customFields, err := groupCustomFields(db, uint(gid))
+		if err != nil {
+			respondInternalError(c, "Failed to load custom fields")
+			return
+		}
+		rows, err := buildFieldVisibility(uint(gid), req, customFields)
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("group_id = ?", gid).Delete(&models.GroupFieldVisibility{}).Error; err != nil {
+				return err
+			}
+			if len(rows) == 0 {
+				return nil
+			}
+			return tx.Create(&rows).Error
+		}); err != nil {
+			middleware.GetLogger(c).Error("Failed to update field visibility", err)
+			respondInternalError(c, "Failed to update field visibility")
+			return
+		}
+
+		visibility, err := groupFieldVisibility(db, uint(gid))
+		if err != nil {
+			respondInternalError(c, "Failed to load field visibility")
+			return
+		}
+
+		uid, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventGroupUpdated, uid, map[string]interface{}{
+			"group_id": gid,
+			"change":   "field_visibility",
+			"fields":   len(rows),
+		})
+		respondOK(c, gin.H{"fields": visibility})
+	}
+}
+
+// SetMemberRole sets a member's role in a group to new_volunteer or
+// volunteer (group admin or site admin). Group admins are promoted and
+// demoted separately.
+// Route: PUT /api/groups/:id/members/:userId/role
+func SetMemberRole(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		targetUserID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid user ID")
+			return
+		}
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
+			return
+		}
+
+		var req MemberRoleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		if req.Role != models.GroupRoleNewVolunteer && req.Role != models.GroupRoleVolunteer {
+			respondBadRequest(c, "role must be new_volunteer or volunteer")
+			return
+		}
+
+		var userGroup models.UserGroup
+		if err := db.Where("user_id = ? AND group_id = ?", targetUserID, groupID).First(&userGroup).Error; err != nil {
+			respondBadRequest(c, "User is not a member of this group")
+			return
+		}
+		if err := db.Model(&userGroup).Update("role", req.Role).Error; err != nil {
+			respondInternalError(c, "Failed to update member role")
+			return
+		}
+
+		uid, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventGroupUpdated, uid, map[string]interface{}{
+			"group_id":       userGroup.GroupID,
+			"change":         "member_role",
+			"target_user_id": targetUserID,
+			"role":           req.Role,
+		})
+		respondOK(c, gin.H{"user_id": targetUserID, "group_id": userGroup.GroupID, "role": req.Role})
+	}
+}
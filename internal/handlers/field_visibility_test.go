@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldVisibilityByRole(t *testing.T) {
+	db := SetupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AnimalNameHistory{}, &models.AnimalBQIncident{}, &models.WeightEntry{}, &models.AnimalView{}, &models.BehaviorAssessment{}, &models.AnimalImage{}))
+	siteAdmin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	lead := CreateTestUser(t, db, "lead", "lead@example.com", "password123", false)
+	regular := CreateTestUser(t, db, "regular", "regular@example.com", "password123", false)
+	newbie := CreateTestUser(t, db, "newbie", "newbie@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	AddUserToGroupWithAdmin(t, db, lead.ID, group.ID, true)
+	AddUserToGroupWithAdmin(t, db, regular.ID, group.ID, false)
+	AddUserToGroupWithAdmin(t, db, newbie.ID, group.ID, false)
+	params := gin.Params{{Key: "id", Value: itoa(group.ID)}}
+
+	require.NoError(t, db.Create(&models.AnimalCustomField{GroupID: group.ID, Key: "bite_level", Name: "Bite level", Type: models.CustomFieldText}).Error)
+	rocky := CreateTestAnimal(t, db, group.ID, "Rocky", "Dog")
+	require.NoError(t, db.Model(rocky).Updates(map[string]interface{}{
+		"trainer_notes":               "Muzzle for vet visits",
+		"quarantine_incident_details": "Bit a handler on intake",
+		"microchip_number":            "985112345678901",
+		"custom_fields":               models.AnimalCustomValues{"bite_level": "3", "color": "brindle"},
+	}).Error)
+	require.NoError(t, db.Create(&models.BehaviorAssessment{AnimalID: rocky.ID, Score: 2, AssessedByID: lead.ID, AssessedAt: time.Now()}).Error)
+	addWeight(t, db, rocky.ID, lead.ID, 62, models.WeightUnitLB, 0)
+	require.NoError(t, db.Create(&models.AnimalChange{AnimalID: rocky.ID, GroupID: group.ID, UserID: lead.ID, Source: models.AnimalChangeEdit,
+		Changes: models.AnimalFieldChanges{
+			{Field: "status", Old: "available", New: "foster"},
+			{Field: "microchip_number", Old: "", New: "985112345678901"},
+			{Field: "custom_fields.bite_level", Old: "", New: "3"},
+		}}).Error)
+	require.NoError(t, db.Create(&models.AnimalChange{AnimalID: rocky.ID, GroupID: group.ID, UserID: lead.ID, Source: models.AnimalChangeEdit,
+		Changes: models.AnimalFieldChanges{{Field: "microchip_number", Old: "985112345678901", New: "985112345678902"}}}).Error)
+
+	// Group admins choose which members are new
+	setRole := func(userID, target uint, role string) int {
+		c, w := accountTestContext(userID, false, http.MethodPut, "/", gin.H{"role": role})
+		c.Params = append(params, gin.Param{Key: "userId", Value: itoa(target)})
+		SetMemberRole(db)(c)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusForbidden, setRole(regular.ID, newbie.ID, models.GroupRoleNewVolunteer))
+	assert.Equal(t, http.StatusBadRequest, setRole(lead.ID, newbie.ID, models.GroupRoleAdmin), "promotion has its own endpoint")
+	require.Equal(t, http.StatusOK, setRole(lead.ID, newbie.ID, models.GroupRoleNewVolunteer))
+
+	setVisibility := func(userID uint, fields gin.H) int {
+		c, w := accountTestContext(userID, false, http.MethodPut, "/", gin.H{"fields": fields})
+		c.Params = params
+		UpdateFieldVisibility(db)(c)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusForbidden, setVisibility(regular.ID, gin.H{"microchip_number": "group_admin"}))
+	assert.Equal(t, http.StatusBadRequest, setVisibility(lead.ID, gin.H{"name": "group_admin"}))
+	assert.Equal(t, http.StatusBadRequest, setVisibility(lead.ID, gin.H{"field.unknown": "volunteer"}))
+	assert.Equal(t, http.StatusBadRequest, setVisibility(lead.ID, gin.H{"microchip_number": "site_admin"}))
+	require.Equal(t, http.StatusOK, setVisibility(lead.ID, gin.H{"microchip_number": "group_admin", "field.bite_level": "volunteer", "current_weight": "volunteer"}))
+
+	getAnimal := func(userID uint, isAdmin bool) map[string]interface{} {
+		c, w := accountTestContext(userID, isAdmin, http.MethodGet, "/", nil)
+		c.Params = append(params, gin.Param{Key: "animalId", Value: itoa(rocky.ID)})
+		GetAnimal(db)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var animal map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &animal))
+		return animal
+	}
+	customFields := func(animal map[string]interface{}) map[string]interface{} {
+		fields, _ := animal["custom_fields"].(map[string]interface{})
+		return fields
+	}
+
+	animal := getAnimal(newbie.ID, false)
+	assert.Equal(t, "Rocky", animal["name"])
+	assert.NotContains(t, animal, "trainer_notes")
+	assert.NotContains(t, animal, "quarantine_incident_details")
+	assert.NotContains(t, animal, "microchip_number")
+	assert.Equal(t, map[string]interface{}{"color": "brindle"}, customFields(animal))
+
+	animal = getAnimal(regular.ID, false)
+	assert.Equal(t, "Muzzle for vet visits", animal["trainer_notes"])
+	assert.Equal(t, "Bit a handler on intake", animal["quarantine_incident_details"])
+	assert.NotContains(t, animal, "microchip_number")
+	assert.Equal(t, "3", customFields(animal)["bite_level"])
+
+	for _, viewer := range []struct {
+		id      uint
+		isAdmin bool
+	}{{lead.ID, false}, {siteAdmin.ID, true}} {
+		animal = getAnimal(viewer.id, viewer.isAdmin)
+		assert.Equal(t, "985112345678901", animal["microchip_number"])
+		assert.Equal(t, "Muzzle for vet visits", animal["trainer_notes"])
+	}
+
+	// Lists are redacted the same way
+	c, w := accountTestContext(newbie.ID, false, http.MethodGet, "/?status=all", nil)
+	c.Params = params
+	GetAnimals(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var animals []map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &animals))
+	require.Len(t, animals, 1)
+	assert.NotContains(t, animals[0], "trainer_notes")
+	assert.Contains(t, animals[0], "alerts")
+
+	// Behavior assessments are refused below their minimum role
+	getAssessments := func(userID uint) int {
+		c, w := accountTestContext(userID, false, http.MethodGet, "/", nil)
+		c.Params = append(params, gin.Param{Key: "animalId", Value: itoa(rocky.ID)})
+		GetBehaviorAssessments(db)(c)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusForbidden, getAssessments(newbie.ID))
+	assert.Equal(t, http.StatusOK, getAssessments(regular.ID))
+
+	// So are weights when current_weight is hidden
+	for name, handler := range map[string]gin.HandlerFunc{"weights": GetAnimalWeights(db), "series": GetAnimalWeightSeries(db)} {
+		c, w := accountTestContext(newbie.ID, false, http.MethodGet, "/", nil)
+		c.Params = append(params, gin.Param{Key: "animalId", Value: itoa(rocky.ID)})
+		handler(c)
+		assert.Equal(t, http.StatusForbidden, w.Code, name)
+		c, w = accountTestContext(regular.ID, false, http.MethodGet, "/", nil)
+		c.Params = append(params, gin.Param{Key: "animalId", Value: itoa(rocky.ID)})
+		handler(c)
+		assert.Equal(t, http.StatusOK, w.Code, name)
+	}
+
+	// Change history leaves out hidden fields, and changes with nothing left
+	changeItems := func(handler gin.HandlerFunc, userID uint) [][]models.AnimalFieldChange {
+		c, w := accountTestContext(userID, false, http.MethodGet, "/", nil)
+		c.Params = append(params, gin.Param{Key: "animalId", Value: itoa(rocky.ID)})
+		handler(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var feed struct {
+			Items []ActivityItem `json:"items"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &feed))
+		var changes [][]models.AnimalFieldChange
+		for _, item := range feed.Items {
+			if item.Type == "animal_change" {
+				changes = append(changes, item.Changes)
+			}
+		}
+		return changes
+	}
+	for name, handler := range map[string]gin.HandlerFunc{"feed": GetGroupActivityFeed(db), "timeline": GetAnimalTimeline(db)} {
+		changes := changeItems(handler, newbie.ID)
+		require.Len(t, changes, 1, name)
+		assert.Equal(t, []models.AnimalFieldChange{{Field: "status", Old: "available", New: "foster"}}, changes[0], name)
+
+		changes = changeItems(handler, regular.ID)
+		require.Len(t, changes, 1, name)
+		assert.Len(t, changes[0], 2, name)
+
+		assert.Len(t, changeItems(handler, lead.ID), 2, name)
+	}
+
+	// Every member can see the policy and their own role
+	c, w = accountTestContext(newbie.ID, false, http.MethodGet, "/", nil)
+	c.Params = params
+	GetFieldVisibility(db)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var policy struct {
+		Fields map[string]string `json:"fields"`
+		Role   string            `json:"role"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &policy))
+	assert.Equal(t, models.GroupRoleNewVolunteer, policy.Role)
+	assert.Equal(t, models.GroupRoleAdmin, policy.Fields["microchip_number"])
+	assert.Equal(t, models.GroupRoleVolunteer, policy.Fields["trainer_notes"], "defaults apply unless overridden")
+
+	// Overrides can open a default up to everyone
+	require.Equal(t, http.StatusOK, setVisibility(lead.ID, gin.H{"trainer_notes": "new_volunteer"}))
+	animal = getAnimal(newbie.ID, false)
+	assert.Equal(t, "Muzzle for vet visits", animal["trainer_notes"])
+	assert.Equal(t, "985112345678901", animal["microchip_number"], "fields left out go back to their defaults")
+}
@@ -0,0 +1,403 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxFosterSpecies   = 10
+	maxFosterBlackouts = 50
+
+	// maxFosterMatchDays bounds the from-to window of a foster match query
+	maxFosterMatchDays = 366
+
+	// Weight boundaries, in lb, between the foster sizes
+	fosterSizeMediumLB = 25
+	fosterSizeLargeLB  = 60
+)
+
+// Reasons a foster doesn't match an animal and dates, in FosterMatch.Reasons
+const (
+	FosterReasonUnavailable   = "unavailable"   // Not taking fosters
+	FosterReasonFull          = "full"          // No open slots
+	FosterReasonBlackout      = "blackout"      // A blackout overlaps the dates
+	FosterReasonSpecies       = "species"       // Doesn't take the animal's species
+	FosterReasonSize          = "size"          // Doesn't take the animal's size
+	FosterReasonQualification = "qualification" // Lacks a qualification for one of the animal's restricted tags
+)
+
+// fosterSizes are the valid FosterProfile.Sizes values
+var fosterSizes = map[string]bool{
+	models.FosterSizeSmall:  true,
+	models.FosterSizeMedium: true,
+	models.FosterSizeLarge:  true,
+}
+
+// FosterProfileRequest replaces the caller's foster profile in a group.
+// Blackout dates that have already ended are dropped.
+type FosterProfileRequest struct {
+	Available     bool                    `json:"available"`
+	Capacity      int                     `json:"capacity" binding:"min=0,max=20"`
+	CurrentCount  int                     `json:"current_count" binding:"min=0,max=20"`
+	Species       []string                `json:"species"`
+	Sizes         []string                `json:"sizes"`
+	BlackoutDates []models.FosterBlackout `json:"blackout_dates"`
+	Notes         string                  `json:"notes" binding:"max=1000"`
+}
+
+// FosterMatch is one volunteer's foster profile, judged against an animal
+// and a date range. Match is true when Reasons is empty.
+type FosterMatch struct {
+	Profile   models.FosterProfile `json:"profile"`
+	OpenSlots int                  `json:"open_slots"`
+	Match     bool                 `json:"match"`
+	Reasons   []string             `json:"reasons,omitempty"`
+}
+
+// FosterMatches is the response of GetFosterMatches
+type FosterMatches struct {
+	AnimalID   *uint         `json:"animal_id,omitempty"`
+	AnimalSize string        `json:"animal_size,omitempty"` // The animal's size, or else from its latest weigh-in; empty when it has neither
+	From       string        `json:"from"`
+	To         string        `json:"to"`
+	Items      []FosterMatch `json:"items"`
+}
+
+// fosterSize returns the foster size for a weight, or "" for no weight
+func fosterSize(entry *models.WeightEntry) string {
+	if entry == nil {
+		return ""
+	}
+	lb := convertWeight(entry.Weight, entry.Unit, models.WeightUnitLB)
+	switch {
+	case lb < fosterSizeMediumLB:
+		return models.FosterSizeSmall
+	case lb <= fosterSizeLargeLB:
+		return models.FosterSizeMedium
+	default:
+		return models.FosterSizeLarge
+	}
+}
+
+// buildFosterProfile validates req and copies it onto profile. today is a
+// YYYY-MM-DD date in the group's time zone.
+func buildFosterProfile(profile *models.FosterProfile, req FosterProfileRequest, today string) error {
+	species := models.StringList{}
+	seen := map[string]bool{}
+	for _, s := range req.Species {
+		s = strings.TrimSpace(s)
+		if s == "" || seen[strings.ToLower(s)] {
+			continue
+		}
+		if len(s) > 50 {
+			return errors.New("species names must be at most 50 characters")
+		}
+		seen[strings.ToLower(s)] = true
+		species = append(species, s)
+	}
+	if len(species) > maxFosterSpecies {
+		return fmt.Errorf("at most %d species can be listed", maxFosterSpecies)
+	}
+
+	sizes := models.StringList{}
+	seen = map[string]bool{}
+	for _, s := range req.Sizes {
+		if !fosterSizes[s] {
+			return fmt.Errorf("unknown size %q; use small, medium, or large", s)
+		}
+		if !seen[s] {
+			seen[s] = true
+			sizes = append(sizes, s)
+		}
+	}
+
+	blackouts := models.FosterBlackouts{}
+	for i, b := range req.BlackoutDates {
+		start, err := time.Parse("2006-01-02", b.Start)
+		if err != nil {
+			return fmt.Errorf("blackout_dates[%d].start must be a date in YYYY-MM-DD format", i)
+		}
+		if b.End == "" {
+			b.End = b.Start
+		}
+		end, err := time.Parse("2006-01-02", b.End)
+		if err != nil {
+			return fmt.Errorf("blackout_dates[%d].end must be a date in YYYY-MM-DD format", i)
+		}
+		if end.Before(start) {
+			return fmt.Errorf("blackout_dates[%d] ends before it starts", i)
+		}
+		if len(b.Note) > 200 {
+			return fmt.Errorf("blackout_dates[%d].note must be at most 200 characters", i)
+		}
+		if b.End < today {
+			continue
+		}
+		blackouts = append(blackouts, b)
+	}
+	if len(blackouts) > maxFosterBlackouts {
+		return fmt.Errorf("at most %d blackout ranges can be listed", maxFosterBlackouts)
+	}
+	sort.Slice(blackouts, func(i, j int) bool { return blackouts[i].Start < blackouts[j].Start })
+
+	profile.Available = req.Available
+	profile.Capacity = req.Capacity
+	profile.CurrentCount = req.CurrentCount
+	profile.Species = species
+	profile.Sizes = sizes
+	profile.BlackoutDates = blackouts
+	profile.Notes = strings.TrimSpace(req.Notes)
+	return nil
+}
+
+// GetMyFosterProfile returns the caller's foster profile in a group. A
+// caller without one gets an empty, unavailable profile.
+// Route: GET /api/groups/:id/foster-profile
+func GetMyFosterProfile(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+		uid, _ := middleware.GetUserID(c)
+
+		profile := models.FosterProfile{UserID: uid, GroupID: uint(gid)}
+		err = db.Where("user_id = ? AND group_id = ?", uid, gid).First(&profile).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			respondInternalError(c, "Failed to fetch foster profile")
+			return
+		}
+		respondOK(c, profile)
+	}
+}
+
+// UpdateMyFosterProfile creates or replaces the caller's foster profile in
+// a group
+// Route: PUT /api/groups/:id/foster-profile
+func UpdateMyFosterProfile(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+		var req FosterProfileRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+		uid, _ := middleware.GetUserID(c)
+
+		today := time.Now().In(groupLocationByID(c.Request.Context(), db, uint(gid))).Format("2006-01-02")
+		profile := models.FosterProfile{UserID: uid, GroupID: uint(gid)}
+		if err := buildFosterProfile(&profile, req, today); err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		err = db.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}, {Name: "group_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"updated_at", "available", "capacity", "current_count", "species", "sizes", "blackout_dates", "notes",
+			}),
+		}).Create(&profile).Error
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to save foster profile", err)
+			respondInternalError(c, "Failed to save foster profile")
+			return
+		}
+		if err := db.Where("user_id = ? AND group_id = ?", uid, gid).First(&profile).Error; err != nil {
+			respondInternalError(c, "Failed to load foster profile")
+			return
+		}
+		respondOK(c, profile)
+	}
+}
+
+// GetFosterMatches lists the group's members' foster profiles, judged
+// against a date range and optionally an animal: its species, its recorded
+// size or else its size from the latest weigh-in, and qualifications for
+// its restricted tags. Matches
+// come first, most open slots first. Query params: animal_id, from
+// (YYYY-MM-DD, default today), to (default from), all (include
+// non-matches; default false). Group admins only.
+// Route: GET /api/groups/:id/fosters
+func GetFosterMatches(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Group admin access required")
+			return
+		}
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+
+		result := FosterMatches{Items: []FosterMatch{}}
+		result.From = c.DefaultQuery("from", time.Now().In(groupLocationByID(c.Request.Context(), db, uint(gid))).Format("2006-01-02"))
+		result.To = c.DefaultQuery("to", result.From)
+		from, err := time.Parse("2006-01-02", result.From)
+		if err != nil {
+			respondBadRequest(c, "from must be a date in YYYY-MM-DD format")
+			return
+		}
+		to, err := time.Parse("2006-01-02", result.To)
+		if err != nil {
+			respondBadRequest(c, "to must be a date in YYYY-MM-DD format")
+			return
+		}
+		if to.Before(from) || to.Sub(from) > maxFosterMatchDays*24*time.Hour {
+			respondBadRequest(c, fmt.Sprintf("to must be on or after from, and at most %d days later", maxFosterMatchDays))
+			return
+		}
+
+		var animal *models.Animal
+		var restrictedTags []uint
+		if v := c.Query("animal_id"); v != "" {
+			var a models.Animal
+			if err := db.Where("id = ? AND group_id = ?", v, gid).First(&a).Error; err != nil {
+				respondError(c, http.StatusNotFound, ErrCodeAnimalNotFound, "Animal not found")
+				return
+			}
+			animal = &a
+			result.AnimalID = &a.ID
+			result.AnimalSize = a.Size
+			if result.AnimalSize == "" {
+				weight, err := latestWeightEntry(db, a.ID)
+				if err != nil {
+					respondInternalError(c, "Failed to load animal")
+					return
+				}
+				result.AnimalSize = fosterSize(weight)
+			}
+			if err := db.Table("animal_animal_tags aat").
+				Joins("JOIN animal_tags t ON t.id = aat.animal_tag_id").
+				Where("aat.animal_id = ? AND t.restricted = ? AND t.deleted_at IS NULL", a.ID, true).
+				Pluck("t.id", &restrictedTags).Error; err != nil {
+				respondInternalError(c, "Failed to load animal")
+				return
+			}
+		}
+
+		var profiles []models.FosterProfile
+		if err := db.Preload("User", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "username", "first_name", "last_name", "email", "phone_number")
+		}).
+			Joins("JOIN user_groups ug ON ug.user_id = foster_profiles.user_id AND ug.group_id = foster_profiles.group_id").
+			Joins("JOIN users u ON u.id = foster_profiles.user_id AND u.deleted_at IS NULL").
+			Where("foster_profiles.group_id = ?", gid).
+			Find(&profiles).Error; err != nil {
+			respondInternalError(c, "Failed to fetch foster profiles")
+			return
+		}
+
+		// user ID -> restricted tags of the animal the user is qualified for
+		qualified := map[uint]int{}
+		if len(restrictedTags) > 0 {
+			var rows []struct {
+				UserID uint
+				Count  int
+			}
+			if err := db.Model(&models.UserQualification{}).
+				Select("user_id, COUNT(*) AS count").
+				Where("animal_tag_id IN ?", restrictedTags).
+				Group("user_id").Scan(&rows).Error; err != nil {
+				respondInternalError(c, "Failed to fetch qualifications")
+				return
+			}
+			for _, r := range rows {
+				qualified[r.UserID] = r.Count
+			}
+		}
+
+		includeAll := c.Query("all") == "true"
+		for _, p := range profiles {
+			m := FosterMatch{Profile: p, OpenSlots: max(p.Capacity-p.CurrentCount, 0)}
+			if !p.Available {
+				m.Reasons = append(m.Reasons, FosterReasonUnavailable)
+			}
+			if m.OpenSlots == 0 {
+				m.Reasons = append(m.Reasons, FosterReasonFull)
+			}
+			for _, b := range p.BlackoutDates {
+				if b.Start <= result.To && b.End >= result.From {
+					m.Reasons = append(m.Reasons, FosterReasonBlackout)
+					break
+				}
+			}
+			if animal != nil {
+				if animal.Species != "" && !fosterAccepts(p.Species, animal.Species) {
+					m.Reasons = append(m.Reasons, FosterReasonSpecies)
+				}
+				if result.AnimalSize != "" && !fosterAccepts(p.Sizes, result.AnimalSize) {
+					m.Reasons = append(m.Reasons, FosterReasonSize)
+				}
+				if qualified[p.UserID] < len(restrictedTags) {
+					m.Reasons = append(m.Reasons, FosterReasonQualification)
+				}
+			}
+			m.Match = len(m.Reasons) == 0
+			if m.Match || includeAll {
+				result.Items = append(result.Items, m)
+			}
+		}
+		sort.SliceStable(result.Items, func(i, j int) bool {
+			a, b := result.Items[i], result.Items[j]
+			if a.Match != b.Match {
+				return a.Match
+			}
+			if a.OpenSlots != b.OpenSlots {
+				return a.OpenSlots > b.OpenSlots
+			}
+			return a.Profile.UserID < b.Profile.UserID
+		})
+		respondOK(c, result)
+	}
+}
+
+// fosterAccepts reports whether a profile's list of accepted values, where
+// empty accepts anything, includes value, ignoring case
+func fosterAccepts(accepted models.StringList, value string) bool {
+	if len(accepted) == 0 {
+		return true
+	}
+	for _, a := range accepted {
+		if strings.EqualFold(a, strings.TrimSpace(value)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFosterProfiles(t *testing.T) {
+	db := SetupTestDB(t)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	lead := CreateTestUser(t, db, "lead", "lead@example.com", "password123", false)
+	AddUserToGroupWithAdmin(t, db, lead.ID, group.ID, true)
+	call := func(handler gin.HandlerFunc, userID uint, method, query string, body interface{}) (int, []byte) {
+		c, w := accountTestContext(userID, false, method, "/"+query, body)
+		c.Params = gin.Params{{Key: "id", Value: itoa(group.ID)}}
+		handler(c)
+		return w.Code, w.Body.Bytes()
+	}
+	member := func(name string, req FosterProfileRequest) *models.User {
+		user := CreateTestUser(t, db, name, name+"@example.com", "password123", false)
+		AddUserToGroupWithAdmin(t, db, user.ID, group.ID, false)
+		code, body := call(UpdateMyFosterProfile(db), user.ID, http.MethodPut, "", req)
+		require.Equal(t, http.StatusOK, code, string(body))
+		return user
+	}
+
+	today := time.Now().UTC()
+	date := func(days int) string { return today.AddDate(0, 0, days).Format("2006-01-02") }
+	ready := member("ready", FosterProfileRequest{Available: true, Capacity: 2, CurrentCount: 1, Species: []string{"dog"}})
+	roomy := member("roomy", FosterProfileRequest{Available: true, Capacity: 3, Sizes: []string{"large"}})
+	away := member("away", FosterProfileRequest{Available: true, Capacity: 1, BlackoutDates: []models.FosterBlackout{
+		{Start: date(-10), End: date(-5)},
+		{Start: date(1), End: date(3), Note: "Vacation"},
+	}})
+	member("full", FosterProfileRequest{Available: true, Capacity: 1, CurrentCount: 1})
+	member("catsonly", FosterProfileRequest{Available: true, Capacity: 1, Species: []string{"Cat"}})
+
+	code, body := call(GetMyFosterProfile(db), away.ID, http.MethodGet, "", nil)
+	require.Equal(t, http.StatusOK, code)
+	var profile models.FosterProfile
+	require.NoError(t, json.Unmarshal(body, &profile))
+	require.Len(t, profile.BlackoutDates, 1, "blackouts that already ended are dropped")
+	assert.Equal(t, "Vacation", profile.BlackoutDates[0].Note)
+
+	code, body = call(GetMyFosterProfile(db), lead.ID, http.MethodGet, "", nil)
+	require.Equal(t, http.StatusOK, code)
+	require.NoError(t, json.Unmarshal(body, &profile))
+	assert.Zero(t, profile.ID)
+	assert.False(t, profile.Available, "no profile reads as unavailable")
+
+	for _, bad := range []FosterProfileRequest{
+		{Sizes: []string{"huge"}},
+		{BlackoutDates: []models.FosterBlackout{{Start: date(3), End: date(1)}}},
+		{BlackoutDates: []models.FosterBlackout{{Start: "next week"}}},
+	} {
+		code, _ = call(UpdateMyFosterProfile(db), ready.ID, http.MethodPut, "", bad)
+		assert.Equal(t, http.StatusBadRequest, code)
+	}
+
+	animal := models.Animal{GroupID: group.ID, Name: "Rex", Species: "Dog"}
+	require.NoError(t, db.Create(&animal).Error)
+	require.NoError(t, db.Create(&models.WeightEntry{AnimalID: animal.ID, Weight: 35, Unit: models.WeightUnitKG, RecordedAt: today, RecordedByID: lead.ID}).Error)
+
+	matches := func(userID uint, query string) (int, FosterMatches) {
+		code, body := call(GetFosterMatches(db), userID, http.MethodGet, query, nil)
+		var result FosterMatches
+		_ = json.Unmarshal(body, &result)
+		return code, result
+	}
+	code, _ = matches(ready.ID, "")
+	assert.Equal(t, http.StatusForbidden, code, "group admins only")
+
+	code, result := matches(lead.ID, "?animal_id="+itoa(animal.ID)+"&from="+date(2)+"&to="+date(4))
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, models.FosterSizeLarge, result.AnimalSize, "35 kg is a large dog")
+	require.Len(t, result.Items, 2)
+	assert.Equal(t, roomy.ID, result.Items[0].Profile.UserID, "most open slots first")
+	assert.Equal(t, 3, result.Items[0].OpenSlots)
+	assert.Equal(t, ready.ID, result.Items[1].Profile.UserID)
+	assert.Equal(t, "ready", result.Items[1].Profile.User.Username)
+
+	_, result = matches(lead.ID, "?animal_id="+itoa(animal.ID)+"&from="+date(2)+"&to="+date(4)+"&all=true")
+	reasons := map[string][]string{}
+	for _, m := range result.Items {
+		reasons[m.Profile.User.Username] = m.Reasons
+	}
+	assert.Equal(t, []string{FosterReasonBlackout}, reasons["away"])
+	assert.Equal(t, []string{FosterReasonFull}, reasons["full"])
+	assert.Equal(t, []string{FosterReasonSpecies}, reasons["catsonly"])
+
+	_, result = matches(lead.ID, "?from="+date(5))
+	assert.Len(t, result.Items, 4, "without an animal, only availability counts")
+
+	tag := models.AnimalTag{GroupID: group.ID, Name: "bite history", Category: "behavior", Restricted: true}
+	require.NoError(t, db.Create(&tag).Error)
+	require.NoError(t, db.Model(&animal).Association("Tags").Append(&tag))
+	require.NoError(t, db.Create(&models.UserQualification{GroupID: group.ID, UserID: roomy.ID, AnimalTagID: tag.ID}).Error)
+	_, result = matches(lead.ID, "?animal_id="+itoa(animal.ID)+"&from="+date(5))
+	require.Len(t, result.Items, 1, "restricted animals need a qualified foster")
+	assert.Equal(t, roomy.ID, result.Items[0].Profile.UserID)
+
+	require.NoError(t, db.Where("user_id = ?", roomy.ID).Delete(&models.UserGroup{}).Error)
+	_, result = matches(lead.ID, "?animal_id="+itoa(animal.ID)+"&from="+date(5))
+	assert.Empty(t, result.Items, "former members aren't offered")
+
+	code, _ = matches(lead.ID, "?from="+date(3)+"&to="+date(1))
+	assert.Equal(t, http.StatusBadRequest, code)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/graph"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"gorm.io/gorm"
+)
+
+const (
+	// graphQLMaxLimit caps the limit argument of GraphQL list fields
+	graphQLMaxLimit = 200
+	// graphQLComplexityLimit caps the number of fields a GraphQL query selects
+	graphQLComplexityLimit = 500
+)
+
+var (
+	errGraphQLNotFound     = errors.New("not found")
+	errGraphQLAccessDenied = errors.New("access denied")
+)
+
+// graphQLResolver is the root of the GraphQL resolvers. Groups and animals
+// are only handed out by the Query resolvers, after checkGroupAccess, so
+// the nested field resolvers don't check access again.
+type graphQLResolver struct {
+	db *gorm.DB
+}
+
+// graphQLContextKey stores the request's gin context in the context
+// resolvers receive
+type graphQLContextKey struct{}
+
+// GraphQL serves the read-only GraphQL API (internal/graph/schema.graphqls).
+// Resolvers apply the same group access checks and restricted-animal
+// filtering as the REST handlers. Introspection is only enabled in
+// development.
+// Route: POST /api/graphql
+func GraphQL(db *gorm.DB) gin.HandlerFunc {
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: &graphQLResolver{db: db}}))
+	srv.AddTransport(transport.POST{})
+	srv.Use(extension.FixedComplexityLimit(graphQLComplexityLimit))
+	if os.Getenv("ENV") == "development" {
+		srv.Use(extension.Introspection{})
+	}
+
+	return func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), graphQLContextKey{}, c)
+		srv.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+	}
+}
+
+// request returns the gin context of the GraphQL request, and its database
+func (r *graphQLResolver) request(ctx context.Context) (*gin.Context, *gorm.DB) {
+	c := ctx.Value(graphQLContextKey{}).(*gin.Context)
+	return c, middleware.GetDB(c, r.db)
+}
+
+// graphQLPage returns a list field's limit, between 1 and graphQLMaxLimit
+// (def when unset), and its offset
+func graphQLPage(limit, offset *int, def int) (int, int) {
+	l, o := def, 0
+	if limit != nil && *limit > 0 {
+		l = min(*limit, graphQLMaxLimit)
+	}
+	if offset != nil && *offset > 0 {
+		o = *offset
+	}
+	return l, o
+}
+
+func graphQLID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
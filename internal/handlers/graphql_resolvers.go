@@ -0,0 +1,191 @@
+package handlers
+
+// This file will be automatically regenerated based on the schema, any resolver
+// implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.90
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/graph"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/graph/model"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+)
+
+// Comments is the resolver for the comments field.
+func (r *animalGraphQLResolver) Comments(ctx context.Context, obj *models.Animal, tags []string, limit *int, offset *int) ([]*models.AnimalComment, error) {
+	_, db := r.request(ctx)
+	l, o := graphQLPage(limit, offset, 50)
+
+	query := db.Preload("User").Preload("Tags").Where("animal_id = ?", obj.ID)
+	if len(tags) > 0 {
+		query = applyTagFilter(query, tags)
+	}
+	comments := []*models.AnimalComment{}
+	if err := query.Order(commentOrder("DESC")).Limit(l).Offset(o).Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// Author is the resolver for the author field.
+func (r *commentGraphQLResolver) Author(ctx context.Context, obj *models.AnimalComment) (*models.User, error) {
+	if obj.User.ID == 0 {
+		return nil, nil
+	}
+	return &obj.User, nil
+}
+
+// Animals is the resolver for the animals field.
+func (r *groupGraphQLResolver) Animals(ctx context.Context, obj *models.Group, status *string, limit *int, offset *int) ([]*models.Animal, error) {
+	c, db := r.request(ctx)
+	l, o := graphQLPage(limit, offset, 100)
+
+	groupID := graphQLID(obj.ID)
+	query := visibleAnimals(c, db, db.Preload("Tags").Where("group_id = ?", obj.ID), groupID)
+	switch {
+	case status == nil || *status == "":
+		query = query.Where("status IN ?", defaultAnimalStatuses)
+	case *status != "all":
+		query = query.Where("status IN ?", splitAndTrim(*status))
+	}
+	animals := []*models.Animal{}
+	if err := query.Order("name ASC, id ASC").Limit(l).Offset(o).Find(&animals).Error; err != nil {
+		return nil, err
+	}
+	return animals, nil
+}
+
+// CommentTags is the resolver for the commentTags field.
+func (r *groupGraphQLResolver) CommentTags(ctx context.Context, obj *models.Group) ([]*models.CommentTag, error) {
+	_, db := r.request(ctx)
+	tags := []*models.CommentTag{}
+	if err := db.Where("group_id = ?", obj.ID).Order("is_system DESC, name ASC").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// Activity is the resolver for the activity field.
+func (r *groupGraphQLResolver) Activity(ctx context.Context, obj *models.Group, limit *int, offset *int) ([]*model.ActivityItem, error) {
+	c, db := r.request(ctx)
+	l, o := graphQLPage(limit, offset, 50)
+
+	refs, err := newActivityFeedQuery(c, db, graphQLID(obj.ID), url.Values{}).page(db, nil, o, l)
+	if err != nil {
+		return nil, err
+	}
+	if len(refs) > l {
+		refs = refs[:l]
+	}
+	viewerID, _ := middleware.GetUserID(c)
+	items, err := hydrateFeed(db, refs, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := make([]*model.ActivityItem, len(items))
+	for i, item := range items {
+		a := &model.ActivityItem{
+			ID:        item.ID,
+			Type:      item.Type,
+			CreatedAt: item.CreatedAt,
+			Content:   item.Content,
+			Author:    item.User,
+			Animal:    item.Animal,
+			Tags:      make([]*models.CommentTag, len(item.Tags)),
+		}
+		if item.Title != "" {
+			a.Title = &item.Title
+		}
+		for j := range item.Tags {
+			a.Tags[j] = &item.Tags[j]
+		}
+		activity[i] = a
+	}
+	return activity, nil
+}
+
+// Groups is the resolver for the groups field.
+func (r *queryGraphQLResolver) Groups(ctx context.Context) ([]*models.Group, error) {
+	c, db := r.request(ctx)
+	groups := []*models.Group{}
+
+	// The same groups GetGroups lists
+	switch orgID := middleware.GetOrganizationID(c); {
+	case middleware.GetIsAdmin(c):
+		if err := db.Order("name ASC").Find(&groups).Error; err != nil {
+			return nil, err
+		}
+	case orgID != nil && middleware.GetIsOrgAdmin(c):
+		if err := db.Where("organization_id = ?", *orgID).Order("name ASC").Find(&groups).Error; err != nil {
+			return nil, err
+		}
+	default:
+		userID, _ := middleware.GetUserID(c)
+		var user models.User
+		if err := db.Preload("Groups", activeGroupsPreload).First(&user, userID).Error; err != nil {
+			return nil, err
+		}
+		for i := range user.Groups {
+			groups = append(groups, &user.Groups[i])
+		}
+	}
+	return groups, nil
+}
+
+// Group is the resolver for the group field.
+func (r *queryGraphQLResolver) Group(ctx context.Context, id uint) (*models.Group, error) {
+	c, db := r.request(ctx)
+	var group models.Group
+	if err := db.First(&group, id).Error; err != nil {
+		return nil, errGraphQLNotFound
+	}
+	userID, _ := c.Get("user_id")
+	isAdmin, _ := c.Get("is_admin")
+	if !checkGroupAccess(db, userID, isAdmin, graphQLID(id)) {
+		return nil, errGraphQLAccessDenied
+	}
+	return &group, nil
+}
+
+// Animal is the resolver for the animal field.
+func (r *queryGraphQLResolver) Animal(ctx context.Context, id uint) (*models.Animal, error) {
+	c, db := r.request(ctx)
+	var ref models.Animal
+	if err := db.Select("id", "group_id").First(&ref, id).Error; err != nil {
+		return nil, errGraphQLNotFound
+	}
+	groupID := graphQLID(ref.GroupID)
+	userID, _ := c.Get("user_id")
+	isAdmin, _ := c.Get("is_admin")
+	if !checkGroupAccess(db, userID, isAdmin, groupID) {
+		return nil, errGraphQLAccessDenied
+	}
+	// A restricted animal is reported missing, as GetAnimal does
+	var animal models.Animal
+	if err := visibleAnimals(c, db, db.Preload("Tags").Where("animals.id = ?", id), groupID).First(&animal).Error; err != nil {
+		return nil, errGraphQLNotFound
+	}
+	return &animal, nil
+}
+
+// Animal returns graph.AnimalResolver implementation.
+func (r *graphQLResolver) Animal() graph.AnimalResolver { return &animalGraphQLResolver{r} }
+
+// Comment returns graph.CommentResolver implementation.
+func (r *graphQLResolver) Comment() graph.CommentResolver { return &commentGraphQLResolver{r} }
+
+// Group returns graph.GroupResolver implementation.
+func (r *graphQLResolver) Group() graph.GroupResolver { return &groupGraphQLResolver{r} }
+
+// Query returns graph.QueryResolver implementation.
+func (r *graphQLResolver) Query() graph.QueryResolver { return &queryGraphQLResolver{r} }
+
+type animalGraphQLResolver struct{ *graphQLResolver }
+type commentGraphQLResolver struct{ *graphQLResolver }
+type groupGraphQLResolver struct{ *graphQLResolver }
+type queryGraphQLResolver struct{ *graphQLResolver }
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type graphQLTestResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func TestGraphQL(t *testing.T) {
+	t.Setenv("ENV", "test")
+	db := SetupTestDB(t)
+	member := CreateTestUser(t, db, "member", "member@example.com", "password123", false)
+	outsider := CreateTestUser(t, db, "outsider", "outsider@example.com", "password123", false)
+	dogs := CreateTestGroup(t, db, "Dogs", "")
+	cats := CreateTestGroup(t, db, "Cats", "")
+	AddUserToGroupWithAdmin(t, db, member.ID, dogs.ID, false)
+	AddUserToGroupWithAdmin(t, db, outsider.ID, cats.ID, false)
+
+	restricted := models.AnimalTag{GroupID: dogs.ID, Name: "experienced only", Category: "walker_status", Restricted: true}
+	require.NoError(t, db.Create(&restricted).Error)
+	rex := CreateTestAnimal(t, db, dogs.ID, "Rex", "Dog")
+	buddy := CreateTestAnimal(t, db, dogs.ID, "Buddy", "Dog")
+	require.NoError(t, db.Model(rex).Association("Tags").Append(&restricted))
+	tag := models.CommentTag{GroupID: dogs.ID, Name: "behavior", Color: "#f00"}
+	require.NoError(t, db.Create(&tag).Error)
+	comment := models.AnimalComment{AnimalID: buddy.ID, UserID: member.ID, Content: "Great walk", Tags: []models.CommentTag{tag}}
+	require.NoError(t, db.Create(&comment).Error)
+	require.NoError(t, db.Create(&models.AnimalComment{AnimalID: buddy.ID, UserID: member.ID, Content: "Ate well"}).Error)
+
+	call := func(userID uint, q string) (int, graphQLTestResponse) {
+		c, w := accountTestContext(userID, false, http.MethodPost, "/api/graphql", gin.H{"query": q})
+		GraphQL(db)(c)
+		var resp graphQLTestResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), w.Body.String())
+		return w.Code, resp
+	}
+	query := func(userID uint, q string) graphQLTestResponse {
+		code, resp := call(userID, q)
+		require.Equal(t, http.StatusOK, code)
+		return resp
+	}
+
+	t.Run("nested group data in one query", func(t *testing.T) {
+		resp := query(member.ID, `{ group(id: "`+itoa(dogs.ID)+`") {
+			name
+			animals { name comments(tags: ["behavior"]) { content author { username } tags { name } } }
+			commentTags { name }
+			activity { type content animal { name } }
+		} }`)
+		require.Empty(t, resp.Errors)
+		var data struct {
+			Group struct {
+				Name    string
+				Animals []struct {
+					Name     string
+					Comments []struct {
+						Content string
+						Author  struct{ Username string }
+						Tags    []struct{ Name string }
+					}
+				}
+				CommentTags []struct{ Name string }
+				Activity    []struct {
+					Type    string
+					Content string
+					Animal  struct{ Name string }
+				}
+			}
+		}
+		require.NoError(t, json.Unmarshal(resp.Data, &data))
+		assert.Equal(t, "Dogs", data.Group.Name)
+		require.Len(t, data.Group.Animals, 1, "the restricted animal is hidden")
+		assert.Equal(t, "Buddy", data.Group.Animals[0].Name)
+		require.Len(t, data.Group.Animals[0].Comments, 1, "comments are filtered by tag")
+		assert.Equal(t, "Great walk", data.Group.Animals[0].Comments[0].Content)
+		assert.Equal(t, "member", data.Group.Animals[0].Comments[0].Author.Username)
+		assert.Equal(t, "behavior", data.Group.Animals[0].Comments[0].Tags[0].Name)
+		assert.Len(t, data.Group.CommentTags, 1)
+		assert.Len(t, data.Group.Activity, 2)
+	})
+
+	t.Run("groups lists the caller's groups", func(t *testing.T) {
+		resp := query(outsider.ID, `{ groups { name } }`)
+		require.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"groups":[{"name":"Cats"}]}`, string(resp.Data))
+	})
+
+	t.Run("other groups are denied", func(t *testing.T) {
+		resp := query(outsider.ID, `{ group(id: "`+itoa(dogs.ID)+`") { name } }`)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "access denied", resp.Errors[0].Message)
+		assert.JSONEq(t, `{"group":null}`, string(resp.Data))
+
+		resp = query(outsider.ID, `{ animal(id: "`+itoa(buddy.ID)+`") { name } }`)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "access denied", resp.Errors[0].Message)
+	})
+
+	t.Run("restricted animals are not found", func(t *testing.T) {
+		resp := query(member.ID, `{ animal(id: "`+itoa(rex.ID)+`") { name } }`)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "not found", resp.Errors[0].Message)
+
+		resp = query(member.ID, `{ animal(id: "`+itoa(buddy.ID)+`") { name } }`)
+		require.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"animal":{"name":"Buddy"}}`, string(resp.Data))
+	})
+
+	t.Run("private user fields aren't in the schema", func(t *testing.T) {
+		code, resp := call(member.ID, `{ animal(id: "`+itoa(buddy.ID)+`") { comments { author { email } } } }`)
+		assert.Equal(t, http.StatusUnprocessableEntity, code)
+		require.NotEmpty(t, resp.Errors)
+		assert.Contains(t, resp.Errors[0].Message, `Cannot query field "email"`)
+	})
+
+	t.Run("introspection is disabled outside development", func(t *testing.T) {
+		resp := query(member.ID, `{ __schema { types { name } } }`)
+		require.Len(t, resp.Errors, 1)
+		assert.Contains(t, resp.Errors[0].Message, "introspection disabled")
+	})
+}
@@ -2,6 +2,7 @@
 package handlers
 
 import (
+	"fmt"
 	"io"
 	"net/http"
 	"path/filepath"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
@@ -25,6 +27,7 @@ type GroupRequest struct {
 	HasProtocols   bool   `json:"has_protocols"`
 	GroupMeBotID   string `json:"groupme_bot_id,omitempty"`
 	GroupMeEnabled bool   `json:"groupme_enabled"`
+	PublicSharing  bool   `json:"public_sharing"`
 }
 
 // adminGroupResponse wraps Group to expose GroupMeBotID which is hidden on the
@@ -67,22 +70,23 @@ func isValidGroupMeBotID(id string) bool {
 }
 
 // UploadGroupImage handles secure group image uploads (admin only)
-func UploadGroupImage(storageProvider storage.Provider) gin.HandlerFunc {
+func UploadGroupImage(storageProvider storage.Provider, imageConfig *upload.ImageConfigStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
 		logger := middleware.GetLogger(c)
+		cfg := imageConfig.Get(ctx)
 
 		file, err := c.FormFile("image")
 		if err != nil {
 			logger.Error("Failed to get form file", err)
-			c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+			respondBadRequest(c, "No file uploaded")
 			return
 		}
 
 		// Validate file upload (size, type, content)
-		if err := upload.ValidateImageUpload(file, upload.MaxImageSize); err != nil {
+		if err := upload.ValidateImageUpload(file, cfg.MaxUploadBytes); err != nil {
 			logger.Error("File validation failed", err)
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file: " + err.Error()})
+			respondBadRequest(c, "Invalid file: "+err.Error())
 			return
 		}
 
@@ -90,7 +94,7 @@ func UploadGroupImage(storageProvider storage.Provider) gin.HandlerFunc {
 		src, err := file.Open()
 		if err != nil {
 			logger.Error("Failed to open file", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read image"})
+			respondInternalError(c, "Failed to read image")
 			return
 		}
 		defer src.Close()
@@ -98,7 +102,7 @@ func UploadGroupImage(storageProvider storage.Provider) gin.HandlerFunc {
 		data, err := io.ReadAll(src)
 		if err != nil {
 			logger.Error("Failed to read file bytes", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read image"})
+			respondInternalError(c, "Failed to read image")
 			return
 		}
 
@@ -112,11 +116,19 @@ func UploadGroupImage(storageProvider storage.Provider) gin.HandlerFunc {
 			}
 		}
 
+		// Resize and re-encode using the configured image settings
+		data, mimeType, err = upload.ProcessImageOrOriginal(data, mimeType, cfg.MaxDimension, cfg)
+		if err != nil {
+			logger.Error("Failed to process image", err)
+			respondInternalError(c, "Failed to process image")
+			return
+		}
+
 		// Upload to storage provider
 		imageURL, _, _, err := storageProvider.UploadImage(ctx, data, mimeType, nil)
 		if err != nil {
 			logger.Error("Failed to upload image to storage", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload image"})
+			respondInternalError(c, "Failed to upload image")
 			return
 		}
 
@@ -131,13 +143,13 @@ func GetGroups(db *gorm.DB) gin.HandlerFunc {
 		db := middleware.GetDB(c, db)
 		userID, exists := c.Get("user_id")
 		if !exists {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "User context not found"})
+			respondInternalError(c, "User context not found")
 			return
 		}
 
 		isAdmin, exists := c.Get("is_admin")
 		if !exists {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Admin context not found"})
+			respondInternalError(c, "Admin context not found")
 			return
 		}
 
@@ -145,13 +157,23 @@ func GetGroups(db *gorm.DB) gin.HandlerFunc {
 
 		adminFlag, ok := isAdmin.(bool)
 		if !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid admin flag"})
+			respondInternalError(c, "Invalid admin flag")
 			return
 		}
 		if adminFlag {
 			// Admins can see all groups (with bot ID included)
 			if err := db.Find(&groups).Error; err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch groups"})
+				respondInternalError(c, "Failed to fetch groups")
+				return
+			}
+			c.JSON(http.StatusOK, toAdminGroupResponses(groups))
+			return
+		}
+
+		// Organization admins see all of their organization's groups
+		if orgID := middleware.GetOrganizationID(c); orgID != nil && middleware.GetIsOrgAdmin(c) {
+			if err := db.Where("organization_id = ?", *orgID).Find(&groups).Error; err != nil {
+				respondInternalError(c, "Failed to fetch groups")
 				return
 			}
 			c.JSON(http.StatusOK, toAdminGroupResponses(groups))
@@ -161,7 +183,7 @@ func GetGroups(db *gorm.DB) gin.HandlerFunc {
 		// Regular users see only their groups (bot ID omitted)
 		var user models.User
 		if err := db.Preload("Groups", activeGroupsPreload).First(&user, userID).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user groups"})
+			respondInternalError(c, "Failed to fetch user groups")
 			return
 		}
 		groups = user.Groups
@@ -177,13 +199,13 @@ func GetGroup(db *gorm.DB) gin.HandlerFunc {
 		groupID := c.Param("id")
 		userIDUint, ok := middleware.GetUserID(c)
 		if !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "User context not found"})
+			respondInternalError(c, "User context not found")
 			return
 		}
 
 		var group models.Group
 		if err := db.First(&group, groupID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+			respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, "Group not found")
 			return
 		}
 
@@ -191,11 +213,11 @@ func GetGroup(db *gorm.DB) gin.HandlerFunc {
 		if !middleware.GetIsAdmin(c) {
 			var user models.User
 			if err := db.Preload("Groups", "id = ?", groupID).First(&user, userIDUint).Error; err != nil {
-				c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+				respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
 				return
 			}
 			if len(user.Groups) == 0 {
-				c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+				respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
 				return
 			}
 			// Regular group members do not see the bot ID
@@ -214,7 +236,7 @@ func CreateGroup(db *gorm.DB) gin.HandlerFunc {
 		db := middleware.GetDB(c, db)
 		var req GroupRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": formatValidationError(err)})
+			respondValidationError(c, err)
 			return
 		}
 
@@ -226,7 +248,7 @@ func CreateGroup(db *gorm.DB) gin.HandlerFunc {
 
 		// Validate GroupMeBotID
 		if !isValidGroupMeBotID(req.GroupMeBotID) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid GroupMe bot ID. Must be a 26-character hexadecimal string."})
+			respondBadRequest(c, "Invalid GroupMe bot ID. Must be a 26-character hexadecimal string.")
 			return
 		}
 
@@ -238,10 +260,11 @@ func CreateGroup(db *gorm.DB) gin.HandlerFunc {
 			HasProtocols:   req.HasProtocols,
 			GroupMeBotID:   req.GroupMeBotID,
 			GroupMeEnabled: req.GroupMeEnabled,
+			PublicSharing:  req.PublicSharing,
 		}
 
 		if err := db.Create(&group).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create group"})
+			respondInternalError(c, "Failed to create group")
 			return
 		}
 
@@ -256,13 +279,13 @@ func UpdateGroup(db *gorm.DB) gin.HandlerFunc {
 		groupID := c.Param("id")
 		var req GroupRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": formatValidationError(err)})
+			respondValidationError(c, err)
 			return
 		}
 
 		var group models.Group
 		if err := db.First(&group, groupID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+			respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, "Group not found")
 			return
 		}
 
@@ -273,14 +296,15 @@ func UpdateGroup(db *gorm.DB) gin.HandlerFunc {
 		group.HasProtocols = req.HasProtocols
 		// Validate GroupMeBotID
 		if !isValidGroupMeBotID(req.GroupMeBotID) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid GroupMe bot ID. Must be a 26-character hexadecimal string."})
+			respondBadRequest(c, "Invalid GroupMe bot ID. Must be a 26-character hexadecimal string.")
 			return
 		}
 		group.GroupMeBotID = req.GroupMeBotID
 		group.GroupMeEnabled = req.GroupMeEnabled
+		group.PublicSharing = req.PublicSharing
 
 		if err := db.Save(&group).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update group"})
+			respondInternalError(c, "Failed to update group")
 			return
 		}
 
@@ -288,17 +312,71 @@ func UpdateGroup(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
-// DeleteGroup deletes a group (admin only)
+// GroupDeletionImpact summarizes what deleting a group affects, shown to
+// the admin before they confirm.
+type GroupDeletionImpact struct {
+	GroupName     string `json:"group_name"`
+	MemberCount   int64  `json:"member_count"`
+	AnimalCount   int64  `json:"animal_count"`
+	ProtocolCount int64  `json:"protocol_count"`
+	UpdateCount   int64  `json:"update_count"`
+}
+
+// groupDeletionImpact counts the group's members, animals, protocols, and
+// updates.
+func groupDeletionImpact(db *gorm.DB, group models.Group) (GroupDeletionImpact, error) {
+	impact := GroupDeletionImpact{GroupName: group.Name}
+	counts := []struct {
+		model any
+		dest  *int64
+	}{
+		{&models.UserGroup{}, &impact.MemberCount},
+		{&models.Animal{}, &impact.AnimalCount},
+		{&models.Protocol{}, &impact.ProtocolCount},
+		{&models.Update{}, &impact.UpdateCount},
+	}
+	for _, count := range counts {
+		if err := db.Model(count.model).Where("group_id = ?", group.ID).Count(count.dest).Error; err != nil {
+			return impact, err
+		}
+	}
+	return impact, nil
+}
+
+// DeleteGroup deletes a group (admin only). It takes two calls: the first
+// responds 428 with a confirmation token and a GroupDeletionImpact, and
+// repeating the call with ?confirmation_token=<token> deletes the group.
 func DeleteGroup(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		db := middleware.GetDB(c, db)
-		groupID := c.Param("id")
+		groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+
+		var group models.Group
+		if err := db.First(&group, uint(groupID)).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, "Group not found")
+			return
+		}
+
+		if !requireConfirmation(c, "delete_group", group.ID, func() (any, error) {
+			return groupDeletionImpact(db, group)
+		}) {
+			return
+		}
 
-		if err := db.Delete(&models.Group{}, groupID).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete group"})
+		if err := db.Delete(&group).Error; err != nil {
+			respondInternalError(c, "Failed to delete group")
 			return
 		}
 
+		adminID, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventGroupDeleted, adminID, map[string]interface{}{
+			"group_id":   group.ID,
+			"group_name": group.Name,
+		})
 		c.JSON(http.StatusOK, gin.H{"message": "Group deleted successfully"})
 	}
 }
@@ -309,29 +387,32 @@ func AddUserToGroup(db *gorm.DB) gin.HandlerFunc {
 		db := middleware.GetDB(c, db)
 		userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid user ID")
 			return
 		}
 		groupID, err := strconv.ParseUint(c.Param("groupId"), 10, 32)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
 			return
 		}
 
 		var user models.User
 		if err := db.First(&user, uint(userID)).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			respondError(c, http.StatusNotFound, ErrCodeUserNotFound, "User not found")
 			return
 		}
 
 		var group models.Group
 		if err := db.First(&group, uint(groupID)).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+			respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, "Group not found")
+			return
+		}
+		if !checkGroupsInUserOrganization(c, db, user, []uint{group.ID}) {
 			return
 		}
 
 		if err := db.Model(&user).Association("Groups").Append(&group); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add user to group"})
+			respondInternalError(c, "Failed to add user to group")
 			return
 		}
 
@@ -345,29 +426,29 @@ func RemoveUserFromGroup(db *gorm.DB) gin.HandlerFunc {
 		db := middleware.GetDB(c, db)
 		userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid user ID")
 			return
 		}
 		groupID, err := strconv.ParseUint(c.Param("groupId"), 10, 32)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
 			return
 		}
 
 		var user models.User
 		if err := db.First(&user, uint(userID)).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			respondError(c, http.StatusNotFound, ErrCodeUserNotFound, "User not found")
 			return
 		}
 
 		var group models.Group
 		if err := db.First(&group, uint(groupID)).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+			respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, "Group not found")
 			return
 		}
 
 		if err := db.Model(&user).Association("Groups").Delete(&group); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove user from group"})
+			respondInternalError(c, "Failed to remove user from group")
 			return
 		}
 
@@ -375,14 +456,154 @@ func RemoveUserFromGroup(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
+// logMembershipChange records a user's group membership changes in the
+// audit log.
+func logMembershipChange(c *gin.Context, event logging.AuditEvent, userID uint, groupIDs []uint) {
+	if len(groupIDs) == 0 {
+		return
+	}
+	adminID, _ := middleware.GetUserID(c)
+	logging.LogAdminAction(c.Request.Context(), event, adminID, map[string]interface{}{
+		"user_id":   userID,
+		"group_ids": groupIDs,
+	})
+}
+
+// UserGroupsRequest is the full set of groups a user should belong to. An
+// omitted is_group_admin keeps an existing member's admin rights, and makes
+// a new member a regular member.
+type UserGroupsRequest struct {
+	Groups []UserGroupEntry `json:"groups" binding:"required,dive"`
+}
+
+// UserGroupEntry is one group in a UserGroupsRequest
+type UserGroupEntry struct {
+	GroupID      uint  `json:"group_id" binding:"required"`
+	IsGroupAdmin *bool `json:"is_group_admin"`
+}
+
+// SetUserGroups makes the user a member of exactly the requested groups,
+// adding and removing memberships and setting group admin rights in one
+// transaction (admin only). Memberships of deleted groups are left alone.
+// Route: PUT /api/admin/users/:userId/groups
+func SetUserGroups(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid user ID")
+			return
+		}
+		var req UserGroupsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+
+		var user models.User
+		if err := db.First(&user, uint(userID)).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeUserNotFound, "User not found")
+			return
+		}
+
+		desired := make(map[uint]*bool, len(req.Groups))
+		desiredIDs := make([]uint, 0, len(req.Groups))
+		for _, entry := range req.Groups {
+			if _, dup := desired[entry.GroupID]; !dup {
+				desiredIDs = append(desiredIDs, entry.GroupID)
+			}
+			desired[entry.GroupID] = entry.IsGroupAdmin
+		}
+		if len(desiredIDs) > 0 {
+			var found []uint
+			if err := db.Model(&models.Group{}).Where("id IN ?", desiredIDs).Pluck("id", &found).Error; err != nil {
+				middleware.GetLogger(c).Error("Failed to look up groups", err)
+				respondInternalError(c, "Failed to update user groups")
+				return
+			}
+			if len(found) != len(desiredIDs) {
+				exists := make(map[uint]bool, len(found))
+				for _, id := range found {
+					exists[id] = true
+				}
+				for _, id := range desiredIDs {
+					if !exists[id] {
+						respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, fmt.Sprintf("Group %d not found", id))
+						return
+					}
+				}
+			}
+			if !checkGroupsInUserOrganization(c, db, user, desiredIDs) {
+				return
+			}
+		}
+
+		added, removed, adminChanged := []uint{}, []uint{}, []uint{}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			var current []models.UserGroup
+			if err := tx.Joins("JOIN groups ON groups.id = user_groups.group_id AND groups.deleted_at IS NULL").
+				Where("user_groups.user_id = ?", user.ID).Find(&current).Error; err != nil {
+				return err
+			}
+			currentAdmin := make(map[uint]bool, len(current))
+			for _, m := range current {
+				currentAdmin[m.GroupID] = m.IsGroupAdmin
+				if _, keep := desired[m.GroupID]; !keep {
+					removed = append(removed, m.GroupID)
+				}
+			}
+			for _, groupID := range desiredIDs {
+				wantAdmin := desired[groupID]
+				isAdmin, member := currentAdmin[groupID]
+				switch {
+				case !member:
+					added = append(added, groupID)
+					membership := models.UserGroup{UserID: user.ID, GroupID: groupID, IsGroupAdmin: wantAdmin != nil && *wantAdmin}
+					if err := tx.Create(&membership).Error; err != nil {
+						return err
+					}
+				case wantAdmin != nil && *wantAdmin != isAdmin:
+					adminChanged = append(adminChanged, groupID)
+					if err := tx.Model(&models.UserGroup{}).Where("user_id = ? AND group_id = ?", user.ID, groupID).
+						Update("is_group_admin", *wantAdmin).Error; err != nil {
+						return err
+					}
+				}
+			}
+			if len(removed) == 0 {
+				return nil
+			}
+			return tx.Where("user_id = ? AND group_id IN ?", user.ID, removed).Delete(&models.UserGroup{}).Error
+		})
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to update user groups", err)
+			respondInternalError(c, "Failed to update user groups")
+			return
+		}
+
+		logMembershipChange(c, logging.AuditEventUserAddedToGroup, user.ID, added)
+		logMembershipChange(c, logging.AuditEventUserRemovedFromGroup, user.ID, removed)
+
+		respondOK(c, gin.H{
+			"user_id":       user.ID,
+			"added":         added,
+			"removed":       removed,
+			"admin_changed": adminChanged,
+		})
+	}
+}
+
 // IsGroupAdmin checks if a user is an admin for a specific group
 // Returns true if user is a site admin OR a group admin for the specified group
 func IsGroupAdmin(db *gorm.DB, userID uint, groupID uint) bool {
+	if groupClaims(db).IsGroupAdmin(userID, groupID) {
+		return true
+	}
 	var userGroup models.UserGroup
-	if err := db.Where("user_id = ? AND group_id = ?", userID, groupID).First(&userGroup).Error; err != nil {
-		return false
+	if err := db.Where("user_id = ? AND group_id = ?", userID, groupID).First(&userGroup).Error; err == nil && userGroup.IsGroupAdmin {
+		return true
 	}
-	return userGroup.IsGroupAdmin
+	return isOrgAdminForGroup(db, userID, groupID)
 }
 
 // IsGroupAdminOrSiteAdmin checks if a user is a site admin OR a group admin for the specified group
@@ -423,26 +644,26 @@ func PromoteGroupAdmin(db *gorm.DB) gin.HandlerFunc {
 
 		userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid user ID")
 			return
 		}
 		groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
 			return
 		}
 
 		// Get current user
 		currentUserID, exists := c.Get("user_id")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			respondUnauthorized(c, "Unauthorized")
 			return
 		}
 
 		// Check authorization: must be site admin OR group admin of this group
 		var currentUser models.User
 		if err := db.First(&currentUser, currentUserID).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+			respondInternalError(c, "Failed to fetch user")
 			return
 		}
 
@@ -451,40 +672,40 @@ func PromoteGroupAdmin(db *gorm.DB) gin.HandlerFunc {
 				"current_user_id": currentUserID,
 				"group_id":        groupID,
 			}).Warn("Unauthorized attempt to promote group admin")
-			c.JSON(http.StatusForbidden, gin.H{"error": "You must be a site admin or group admin to promote users"})
+			respondForbidden(c, "You must be a site admin or group admin to promote users")
 			return
 		}
 
 		// Verify user exists
 		var user models.User
 		if err := db.First(&user, uint(userID)).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			respondError(c, http.StatusNotFound, ErrCodeUserNotFound, "User not found")
 			return
 		}
 
 		// Verify group exists
 		var group models.Group
 		if err := db.First(&group, uint(groupID)).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+			respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, "Group not found")
 			return
 		}
 
 		// Check if user is a member of the group
 		var userGroup models.UserGroup
 		if err := db.Where("user_id = ? AND group_id = ?", userID, groupID).First(&userGroup).Error; err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "User is not a member of this group"})
+			respondBadRequest(c, "User is not a member of this group")
 			return
 		}
 
 		// Check if already a group admin
 		if userGroup.IsGroupAdmin {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "User is already a group admin"})
+			respondBadRequest(c, "User is already a group admin")
 			return
 		}
 
 		// Promote to group admin
 		if err := db.Model(&userGroup).Update("is_group_admin", true).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to promote user to group admin"})
+			respondInternalError(c, "Failed to promote user to group admin")
 			return
 		}
 
@@ -501,26 +722,26 @@ func DemoteGroupAdmin(db *gorm.DB) gin.HandlerFunc {
 
 		userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid user ID")
 			return
 		}
 		groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
 			return
 		}
 
 		// Get current user
 		currentUserID, exists := c.Get("user_id")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			respondUnauthorized(c, "Unauthorized")
 			return
 		}
 
 		// Check authorization: must be site admin OR group admin of this group
 		var currentUser models.User
 		if err := db.First(&currentUser, currentUserID).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+			respondInternalError(c, "Failed to fetch user")
 			return
 		}
 
@@ -529,40 +750,40 @@ func DemoteGroupAdmin(db *gorm.DB) gin.HandlerFunc {
 				"current_user_id": currentUserID,
 				"group_id":        groupID,
 			}).Warn("Unauthorized attempt to demote group admin")
-			c.JSON(http.StatusForbidden, gin.H{"error": "You must be a site admin or group admin to demote users"})
+			respondForbidden(c, "You must be a site admin or group admin to demote users")
 			return
 		}
 
 		// Verify user exists
 		var user models.User
 		if err := db.First(&user, uint(userID)).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			respondError(c, http.StatusNotFound, ErrCodeUserNotFound, "User not found")
 			return
 		}
 
 		// Verify group exists
 		var group models.Group
 		if err := db.First(&group, uint(groupID)).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+			respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, "Group not found")
 			return
 		}
 
 		// Check if user is a member of the group
 		var userGroup models.UserGroup
 		if err := db.Where("user_id = ? AND group_id = ?", userID, groupID).First(&userGroup).Error; err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "User is not a member of this group"})
+			respondBadRequest(c, "User is not a member of this group")
 			return
 		}
 
 		// Check if user is a group admin
 		if !userGroup.IsGroupAdmin {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "User is not a group admin"})
+			respondBadRequest(c, "User is not a group admin")
 			return
 		}
 
 		// Demote from group admin
 		if err := db.Model(&userGroup).Update("is_group_admin", false).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to demote user from group admin"})
+			respondInternalError(c, "Failed to demote user from group admin")
 			return
 		}
 
@@ -576,7 +797,7 @@ func GetGroupMembers(db *gorm.DB) gin.HandlerFunc {
 		db := middleware.GetDB(c, db)
 		groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
 			return
 		}
 
@@ -588,7 +809,7 @@ func GetGroupMembers(db *gorm.DB) gin.HandlerFunc {
 		if !isSiteAdmin {
 			var userGroup models.UserGroup
 			if err := db.Where("user_id = ? AND group_id = ?", currentUserID, groupID).First(&userGroup).Error; err != nil {
-				c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+				respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
 				return
 			}
 			currentUserGroupAdmin = userGroup.IsGroupAdmin
@@ -597,7 +818,7 @@ func GetGroupMembers(db *gorm.DB) gin.HandlerFunc {
 		// Get all members with their group admin status
 		var userGroups []models.UserGroup
 		if err := db.Preload("User").Where("group_id = ?", groupID).Find(&userGroups).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch group members"})
+			respondInternalError(c, "Failed to fetch group members")
 			return
 		}
 
@@ -616,7 +837,7 @@ func GetGroupMembers(db *gorm.DB) gin.HandlerFunc {
 			Joins("JOIN user_skill_tags t ON t.id = a.user_skill_tag_id").
 			Where("a.user_id IN ? AND t.group_id = ? AND t.deleted_at IS NULL", userIDs, groupID).
 			Scan(&rawTags).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch skill tags"})
+			respondInternalError(c, "Failed to fetch skill tags")
 			return
 		}
 		skillTagsByUser := make(map[uint][]models.UserSkillTag)
@@ -632,8 +853,11 @@ func GetGroupMembers(db *gorm.DB) gin.HandlerFunc {
 			LastName              string                `json:"last_name"`
 			Email                 string                `json:"email"`
 			PhoneNumber           string                `json:"phone_number"`
+			AvatarURL             string                `json:"avatar_url"`
+			AvatarThumbnailURL    string                `json:"avatar_thumbnail_url"`
 			IsGroupAdmin          bool                  `json:"is_group_admin"`
 			IsSiteAdmin           bool                  `json:"is_site_admin"`
+			Role                  string                `json:"role"`
 			SkillTags             []models.UserSkillTag `json:"skill_tags"`
 			LastLogin             *time.Time            `json:"last_login,omitempty"`
 			RequiresPasswordSetup bool                  `json:"requires_password_setup,omitempty"`
@@ -678,8 +902,10 @@ func GetGroupMembers(db *gorm.DB) gin.HandlerFunc {
 				PhoneNumber:  phoneNumber,
 				IsGroupAdmin: ug.IsGroupAdmin,
 				IsSiteAdmin:  ug.User.IsAdmin,
+				Role:         memberGroupRole(ug),
 				SkillTags:    tags,
 			}
+			member.AvatarURL, member.AvatarThumbnailURL = ug.User.AvatarURL, ug.User.AvatarThumbnailURL
 
 			// Expose admin-only fields to site admins and group admins
 			if isSiteAdmin || currentUserGroupAdmin {
@@ -701,13 +927,13 @@ func GetGroupMembership(db *gorm.DB) gin.HandlerFunc {
 		db := middleware.GetDB(c, db)
 		groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
 			return
 		}
 
 		userID, ok := middleware.GetUserID(c)
 		if !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "User context not found"})
+			respondInternalError(c, "User context not found")
 			return
 		}
 
@@ -725,10 +951,16 @@ func GetGroupMembership(db *gorm.DB) gin.HandlerFunc {
 					"is_member":      false,
 					"is_group_admin": false,
 					"is_site_admin":  true,
+					"role":           models.GroupRoleSiteAdmin,
 				})
 				return
 			}
-			c.JSON(http.StatusForbidden, gin.H{"error": "Not a member of this group"})
+			respondForbidden(c, "Not a member of this group")
+			return
+		}
+		role, err := viewerGroupRole(c, db, uint(groupID))
+		if err != nil {
+			respondInternalError(c, "Failed to load group role")
 			return
 		}
 
@@ -738,6 +970,7 @@ func GetGroupMembership(db *gorm.DB) gin.HandlerFunc {
 			"is_member":      true,
 			"is_group_admin": userGroup.IsGroupAdmin,
 			"is_site_admin":  isSiteAdmin,
+			"role":           role,
 		})
 	}
 }
@@ -750,7 +983,7 @@ func AddMemberToGroup(db *gorm.DB) gin.HandlerFunc {
 		groupID := c.Param("id")
 		targetUserID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid user ID")
 			return
 		}
 
@@ -759,34 +992,38 @@ func AddMemberToGroup(db *gorm.DB) gin.HandlerFunc {
 
 		// Check for group admin or site admin access
 		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
 			return
 		}
 
 		// Verify target user exists
 		var targetUser models.User
 		if err := db.First(&targetUser, uint(targetUserID)).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			respondError(c, http.StatusNotFound, ErrCodeUserNotFound, "User not found")
 			return
 		}
 
 		// Verify group exists
 		var group models.Group
 		if err := db.First(&group, groupID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+			respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, "Group not found")
+			return
+		}
+
+		if !checkGroupsInUserOrganization(c, db, targetUser, []uint{group.ID}) {
 			return
 		}
 
 		// Check if user is already a member
 		var existingMembership models.UserGroup
 		if err := db.Where("user_id = ? AND group_id = ?", targetUserID, groupID).First(&existingMembership).Error; err == nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "User is already a member of this group"})
+			respondBadRequest(c, "User is already a member of this group")
 			return
 		}
 
 		// Add user to group
 		if err := db.Model(&targetUser).Association("Groups").Append(&group); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add user to group"})
+			respondInternalError(c, "Failed to add user to group")
 			return
 		}
 
@@ -802,7 +1039,7 @@ func RemoveMemberFromGroup(db *gorm.DB) gin.HandlerFunc {
 		groupID := c.Param("id")
 		targetUserID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid user ID")
 			return
 		}
 
@@ -811,34 +1048,34 @@ func RemoveMemberFromGroup(db *gorm.DB) gin.HandlerFunc {
 
 		// Check for group admin or site admin access
 		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
 			return
 		}
 
 		// Verify target user exists
 		var targetUser models.User
 		if err := db.First(&targetUser, uint(targetUserID)).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			respondError(c, http.StatusNotFound, ErrCodeUserNotFound, "User not found")
 			return
 		}
 
 		// Verify group exists
 		var group models.Group
 		if err := db.First(&group, groupID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+			respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, "Group not found")
 			return
 		}
 
 		// Check if user is a member
 		var existingMembership models.UserGroup
 		if err := db.Where("user_id = ? AND group_id = ?", targetUserID, groupID).First(&existingMembership).Error; err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "User is not a member of this group"})
+			respondBadRequest(c, "User is not a member of this group")
 			return
 		}
 
 		// Remove user from group
 		if err := db.Model(&targetUser).Association("Groups").Delete(&group); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove user from group"})
+			respondInternalError(c, "Failed to remove user from group")
 			return
 		}
 
@@ -853,7 +1090,7 @@ func PromoteMemberToGroupAdmin(db *gorm.DB) gin.HandlerFunc {
 		groupID := c.Param("id")
 		targetUserID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid user ID")
 			return
 		}
 
@@ -862,40 +1099,40 @@ func PromoteMemberToGroupAdmin(db *gorm.DB) gin.HandlerFunc {
 
 		// Check for group admin or site admin access
 		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
 			return
 		}
 
 		// Verify target user exists
 		var targetUser models.User
 		if err := db.First(&targetUser, uint(targetUserID)).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			respondError(c, http.StatusNotFound, ErrCodeUserNotFound, "User not found")
 			return
 		}
 
 		// Verify group exists
 		var group models.Group
 		if err := db.First(&group, groupID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+			respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, "Group not found")
 			return
 		}
 
 		// Check if user is a member of the group
 		var userGroup models.UserGroup
 		if err := db.Where("user_id = ? AND group_id = ?", targetUserID, groupID).First(&userGroup).Error; err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "User is not a member of this group"})
+			respondBadRequest(c, "User is not a member of this group")
 			return
 		}
 
 		// Check if already a group admin
 		if userGroup.IsGroupAdmin {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "User is already a group admin"})
+			respondBadRequest(c, "User is already a group admin")
 			return
 		}
 
 		// Promote to group admin
 		if err := db.Model(&userGroup).Update("is_group_admin", true).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to promote user to group admin"})
+			respondInternalError(c, "Failed to promote user to group admin")
 			return
 		}
 
@@ -910,7 +1147,7 @@ func DemoteMemberFromGroupAdmin(db *gorm.DB) gin.HandlerFunc {
 		groupID := c.Param("id")
 		targetUserID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid user ID")
 			return
 		}
 
@@ -919,40 +1156,40 @@ func DemoteMemberFromGroupAdmin(db *gorm.DB) gin.HandlerFunc {
 
 		// Check for group admin or site admin access
 		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
 			return
 		}
 
 		// Verify target user exists
 		var targetUser models.User
 		if err := db.First(&targetUser, uint(targetUserID)).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			respondError(c, http.StatusNotFound, ErrCodeUserNotFound, "User not found")
 			return
 		}
 
 		// Verify group exists
 		var group models.Group
 		if err := db.First(&group, groupID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+			respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, "Group not found")
 			return
 		}
 
 		// Check if user is a member of the group
 		var userGroup models.UserGroup
 		if err := db.Where("user_id = ? AND group_id = ?", targetUserID, groupID).First(&userGroup).Error; err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "User is not a member of this group"})
+			respondBadRequest(c, "User is not a member of this group")
 			return
 		}
 
 		// Check if user is a group admin
 		if !userGroup.IsGroupAdmin {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "User is not a group admin"})
+			respondBadRequest(c, "User is not a group admin")
 			return
 		}
 
 		// Demote from group admin
 		if err := db.Model(&userGroup).Update("is_group_admin", false).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to demote user from group admin"})
+			respondInternalError(c, "Failed to demote user from group admin")
 			return
 		}
 
@@ -971,19 +1208,19 @@ func UpdateGroupSettings(db *gorm.DB) gin.HandlerFunc {
 
 		// Check for group admin or site admin access
 		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
 			return
 		}
 
 		var req GroupRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": formatValidationError(err)})
+			respondValidationError(c, err)
 			return
 		}
 
 		var group models.Group
 		if err := db.First(&group, groupID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+			respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, "Group not found")
 			return
 		}
 
@@ -994,14 +1231,15 @@ func UpdateGroupSettings(db *gorm.DB) gin.HandlerFunc {
 		group.HasProtocols = req.HasProtocols
 		// Validate GroupMeBotID
 		if !isValidGroupMeBotID(req.GroupMeBotID) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid GroupMe bot ID. Must be a 26-character hexadecimal string."})
+			respondBadRequest(c, "Invalid GroupMe bot ID. Must be a 26-character hexadecimal string.")
 			return
 		}
 		group.GroupMeBotID = req.GroupMeBotID
 		group.GroupMeEnabled = req.GroupMeEnabled
+		group.PublicSharing = req.PublicSharing
 
 		if err := db.Save(&group).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update group"})
+			respondInternalError(c, "Failed to update group")
 			return
 		}
 
@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/upload"
+	"gorm.io/gorm"
+)
+
+// Group logo constraints. Logos are shown small, so uploads are capped well
+// below the general image limit and scaled down to groupLogoMaxDimension.
+const (
+	groupLogoMaxBytes     = 2 * 1024 * 1024
+	groupLogoMaxDimension = 512
+	groupLogoMinDimension = 32
+	groupLogoMaxAspect    = 4 // Longest side may be at most 4x the shortest
+)
+
+// GroupBranding is a group's public look: what GET /api/groups/:id/branding
+// returns.
+type GroupBranding struct {
+	GroupID      uint   `json:"group_id"`
+	Name         string `json:"name"`
+	AccentColor  string `json:"accent_color"`
+	LogoURL      string `json:"logo_url"`
+	HeroImageURL string `json:"hero_image_url"`
+	WelcomeText  string `json:"welcome_text"`
+}
+
+// GroupBrandingRequest replaces a group's accent color and welcome text. An
+// empty accent color falls back to the site default.
+type GroupBrandingRequest struct {
+	AccentColor string `json:"accent_color" binding:"omitempty,hexcolor"`
+	WelcomeText string `json:"welcome_text" binding:"max=2000"`
+}
+
+func toGroupBranding(g models.Group) GroupBranding {
+	return GroupBranding{
+		GroupID:      g.ID,
+		Name:         g.Name,
+		AccentColor:  g.AccentColor,
+		LogoURL:      g.LogoURL,
+		HeroImageURL: g.HeroImageURL,
+		WelcomeText:  g.WelcomeText,
+	}
+}
+
+// validateGroupLogo checks a decoded logo's dimensions against the group
+// logo constraints.
+func validateGroupLogo(cfg image.Config) error {
+	short, long := cfg.Width, cfg.Height
+	if short > long {
+		short, long = long, short
+	}
+	if short < groupLogoMinDimension {
+		return fmt.Errorf("logo must be at least %dx%d pixels", groupLogoMinDimension, groupLogoMinDimension)
+	}
+	if long > short*groupLogoMaxAspect {
+		return fmt.Errorf("logo can't be more than %d times wider than it is tall, or the other way round", groupLogoMaxAspect)
+	}
+	return nil
+}
+
+// loadBrandingGroup parses :id and loads the group, writing the error
+// response and returning false if either fails.
+func loadBrandingGroup(c *gin.Context, db *gorm.DB) (models.Group, bool) {
+	var group models.Group
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+		return group, false
+	}
+	if err := db.First(&group, groupID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, "Group not found")
+		} else {
+			respondInternalError(c, "Failed to fetch group")
+		}
+		return group, false
+	}
+	return group, true
+}
+
+// GetGroupBranding returns a group's name, colors, logo, and welcome text.
+// It needs no login, so the login page and public share pages can be themed
+// for the group.
+// Route: GET /api/groups/:id/branding
+func GetGroupBranding(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		group, ok := loadBrandingGroup(c, db)
+		if !ok {
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=300")
+		respondOK(c, toGroupBranding(group))
+	}
+}
+
+// UpdateGroupBranding sets a group's accent color and welcome text (group
+// admin or site admin). The logo has its own upload endpoint.
+// Route: PUT /api/groups/:id/branding
+func UpdateGroupBranding(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		group, ok := loadBrandingGroup(c, db)
+		if !ok {
+			return
+		}
+		if !IsGroupAdminOrSiteAdmin(c, db, group.ID) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Group admin access required")
+			return
+		}
+
+		var req GroupBrandingRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+
+		updates := map[string]interface{}{
+			"accent_color": strings.ToLower(req.AccentColor),
+			"welcome_text": strings.TrimSpace(req.WelcomeText),
+		}
+		if err := db.Model(&group).Updates(updates).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to update group branding", err)
+			respondInternalError(c, "Failed to update group branding")
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventGroupUpdated, userID, map[string]interface{}{
+			"group_id": group.ID,
+			"change":   "branding",
+		})
+		respondOK(c, toGroupBranding(group))
+	}
+}
+
+// UploadGroupLogo replaces a group's logo (group admin or site admin). The
+// upload must be a JPEG, PNG, or GIF image of at most 2 MB, at least
+// 32 pixels on its shortest side, and no more than 4:1 wide or tall. It is
+// scaled to fit 512 pixels, keeping any transparency.
+// Route: POST /api/groups/:id/branding/logo
+func UploadGroupLogo(db *gorm.DB, storageProvider storage.Provider, imageConfig *upload.ImageConfigStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		db := middleware.GetDB(c, db)
+		logger := middleware.GetLogger(c)
+		group, ok := loadBrandingGroup(c, db)
+		if !ok {
+			return
+		}
+		if !IsGroupAdminOrSiteAdmin(c, db, group.ID) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Group admin access required")
+			return
+		}
+
+		file, err := c.FormFile("image")
+		if err != nil {
+			respondBadRequest(c, "No file uploaded")
+			return
+		}
+		cfg := imageConfig.Get(ctx)
+		if err := upload.ValidateImageUpload(file, min(cfg.MaxUploadBytes, groupLogoMaxBytes)); err != nil {
+			respondBadRequest(c, "Invalid file: "+err.Error())
+			return
+		}
+
+		src, err := file.Open()
+		if err != nil {
+			logger.Error("Failed to open file", err)
+			respondInternalError(c, "Failed to read image")
+			return
+		}
+		defer src.Close()
+		data, err := io.ReadAll(src)
+		if err != nil {
+			logger.Error("Failed to read file bytes", err)
+			respondInternalError(c, "Failed to read image")
+			return
+		}
+
+		// Unlike photos, a logo the server can't decode (e.g. HEIC or WebP)
+		// isn't stored as uploaded: its dimensions can't be checked
+		imgCfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			respondBadRequest(c, "Logo must be a JPEG, PNG, or GIF image")
+			return
+		}
+		if err := validateGroupLogo(imgCfg); err != nil {
+			respondBadRequest(c, "Invalid logo: "+err.Error())
+			return
+		}
+
+		cfg.PreserveTransparency = true
+		processed, err := upload.ProcessImage(bytes.NewReader(data), groupLogoMaxDimension, cfg)
+		if err != nil {
+			if errors.Is(err, upload.ErrInvalidFile) {
+				respondBadRequest(c, "Logo must be a JPEG, PNG, or GIF image")
+				return
+			}
+			logger.Error("Failed to process logo", err)
+			respondInternalError(c, "Failed to process image")
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logoURL, err := storeUnlinkedImage(ctx, db, storageProvider, processed.Data, processed.MimeType, userID)
+		if err != nil {
+			logger.Error("Failed to store group logo", err)
+			respondInternalError(c, "Failed to upload image")
+			return
+		}
+		if err := db.Model(&group).Update("logo_url", logoURL).Error; err != nil {
+			logger.Error("Failed to save group logo", err)
+			respondInternalError(c, "Failed to save logo")
+			return
+		}
+
+		logging.LogAdminAction(ctx, logging.AuditEventGroupUpdated, userID, map[string]interface{}{
+			"group_id": group.ID,
+			"change":   "logo",
+		})
+		respondOK(c, toGroupBranding(group))
+	}
+}
+
+// DeleteGroupLogo removes a group's logo (group admin or site admin).
+// Route: DELETE /api/groups/:id/branding/logo
+func DeleteGroupLogo(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		group, ok := loadBrandingGroup(c, db)
+		if !ok {
+			return
+		}
+		if !IsGroupAdminOrSiteAdmin(c, db, group.ID) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Group admin access required")
+			return
+		}
+
+		if err := db.Model(&group).Update("logo_url", "").Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to remove group logo", err)
+			respondInternalError(c, "Failed to remove logo")
+			return
+		}
+		respondOK(c, toGroupBranding(group))
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testLogoPNG encodes a transparent w x h PNG.
+func testLogoPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	img.Set(0, 0, color.NRGBA{R: 0xff, A: 0xff})
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestGroupBranding(t *testing.T) {
+	db := SetupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AnimalImage{}))
+	groupAdmin := CreateTestUser(t, db, "lead", "lead@example.com", "password123", false)
+	member := CreateTestUser(t, db, "member", "member@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "Dog group")
+	AddUserToGroupWithAdmin(t, db, groupAdmin.ID, group.ID, true)
+	AddUserToGroupWithAdmin(t, db, member.ID, group.ID, false)
+	params := gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}}
+
+	update := func(userID uint, body any) *httptest.ResponseRecorder {
+		c, w := accountTestContext(userID, false, http.MethodPut, "/", body)
+		c.Params = params
+		UpdateGroupBranding(db)(c)
+		return w
+	}
+	uploadLogo := func(userID uint, filename string, content []byte) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", userID)
+		c.Set("is_admin", false)
+		c.Params = params
+		c.Request = createImageMultipartRequest(t, "image", filename, content)
+		UploadGroupLogo(db, &mockStorageProvider{}, nil)(c)
+		return w
+	}
+
+	t.Run("update requires a group admin", func(t *testing.T) {
+		w := update(member.ID, map[string]string{"accent_color": "#112233"})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("accent color must be hex", func(t *testing.T) {
+		w := update(groupAdmin.ID, map[string]string{"accent_color": "blue"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("update and read publicly", func(t *testing.T) {
+		w := update(groupAdmin.ID, map[string]string{"accent_color": "#1E40AF", "welcome_text": "  Welcome, dog walkers!  "})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		// No user in the context: the endpoint is public
+		w = httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = params
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		GetGroupBranding(db)(c)
+		require.Equal(t, http.StatusOK, w.Code)
+		var branding GroupBranding
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &branding))
+		assert.Equal(t, "Dogs", branding.Name)
+		assert.Equal(t, "#1e40af", branding.AccentColor)
+		assert.Equal(t, "Welcome, dog walkers!", branding.WelcomeText)
+	})
+
+	t.Run("logo constraints", func(t *testing.T) {
+		w := uploadLogo(groupAdmin.ID, "tiny.png", testLogoPNG(t, 16, 16))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "at least 32x32")
+
+		w = uploadLogo(groupAdmin.ID, "banner.png", testLogoPNG(t, 500, 100))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = uploadLogo(groupAdmin.ID, "logo.png", minimalPNG)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "undecodable images are rejected")
+
+		w = uploadLogo(member.ID, "logo.png", testLogoPNG(t, 64, 64))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("upload and remove logo", func(t *testing.T) {
+		w := uploadLogo(groupAdmin.ID, "logo.png", testLogoPNG(t, 1024, 512))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var branding GroupBranding
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &branding))
+		assert.Contains(t, branding.LogoURL, "/api/images/test-uuid")
+
+		var stored models.AnimalImage
+		require.NoError(t, db.Where("image_url = ?", branding.LogoURL).First(&stored).Error)
+		assert.Nil(t, stored.AnimalID)
+		assert.Equal(t, "image/png", stored.MimeType, "transparency is kept")
+		cfg, err := png.DecodeConfig(bytes.NewReader(stored.ImageData))
+		require.NoError(t, err)
+		assert.Equal(t, 512, cfg.Width, "scaled to fit 512 pixels")
+
+		c, w := accountTestContext(groupAdmin.ID, false, http.MethodDelete, "/", nil)
+		c.Params = params
+		DeleteGroupLogo(db)(c)
+		require.Equal(t, http.StatusOK, w.Code)
+		var group models.Group
+		require.NoError(t, db.First(&group, branding.GroupID).Error)
+		assert.Empty(t, group.LogoURL)
+	})
+
+	t.Run("unknown group", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: "9999"}}
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		GetGroupBranding(db)(c)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
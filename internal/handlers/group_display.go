@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// maxCardFields bounds how many fields an animal card can show
+const maxCardFields = 12
+
+// defaultAnimalStatuses are the statuses GET /animals lists when neither the
+// request nor the group's display settings choose any
+var defaultAnimalStatuses = []string{"available", "bite_quarantine", "under_vet_care"}
+
+// animalCardFields are the animal fields a group can show on list cards, on
+// top of its custom fields as "field.<key>"
+var animalCardFields = map[string]bool{
+	"species":             true,
+	"breed":               true,
+	"age":                 true,
+	"status":              true,
+	"arrival_date":        true,
+	"foster_start_date":   true,
+	"quarantine_end_date": true,
+	"last_status_change":  true,
+	"intake_source":       true,
+	"size":                true,
+	"energy_level":        true,
+	"microchip_number":    true,
+	"description":         true,
+	"trainer_notes":       true,
+	"tags":                true,
+	"image_count":         true,
+	"video_count":         true,
+}
+
+// GroupDisplaySettings is a group's animal list defaults, card fields, time
+// zone, animal name rule, and bonded adoption rule
+type GroupDisplaySettings struct {
+	GroupID             uint     `json:"group_id"`
+	DefaultStatusFilter string   `json:"default_status_filter"`
+	DefaultSort         string   `json:"default_sort"`
+	DefaultSortOrder    string   `json:"default_sort_order"`
+	CardFields          []string `json:"card_fields"`
+	TimeZone            string   `json:"time_zone"`
+	UniqueAnimalNames   bool     `json:"unique_animal_names"`
+	AdoptBondedTogether bool     `json:"adopt_bonded_together"`
+}
+
+// GroupDisplaySettingsRequest replaces a group's display settings. Empty
+// values restore the app's defaults.
+type GroupDisplaySettingsRequest struct {
+	DefaultStatusFilter string   `json:"default_status_filter" binding:"max=500"`
+	DefaultSort         string   `json:"default_sort"`
+	DefaultSortOrder    string   `json:"default_sort_order" binding:"omitempty,oneof=asc desc"`
+	CardFields          []string `json:"card_fields"`
+	TimeZone            string   `json:"time_zone"`
+	UniqueAnimalNames   *bool    `json:"unique_animal_names"`   // nil leaves it unchanged
+	AdoptBondedTogether *bool    `json:"adopt_bonded_together"` // nil leaves it unchanged
+}
+
+func toGroupDisplaySettings(g models.Group) GroupDisplaySettings {
+	cardFields := []string(g.CardFields)
+	if cardFields == nil {
+		cardFields = []string{}
+	}
+	return GroupDisplaySettings{
+		GroupID:             g.ID,
+		DefaultStatusFilter: g.DefaultStatusFilter,
+		DefaultSort:         g.DefaultSort,
+		DefaultSortOrder:    g.DefaultSortOrder,
+		CardFields:          cardFields,
+		TimeZone:            g.TimeZone,
+		UniqueAnimalNames:   g.UniqueAnimalNames,
+		AdoptBondedTogether: g.AdoptBondedTogether,
+	}
+}
+
+// normalizeStatusFilter trims a comma-separated status filter and checks
+// each status is one the group uses. "all" stays as is.
+func normalizeStatusFilter(db *gorm.DB, groupID uint, filter string) (string, error) {
+	filter = strings.TrimSpace(filter)
+	if filter == "" || filter == "all" {
+		return filter, nil
+	}
+	statuses, err := effectiveAnimalStatuses(db, groupID)
+	if err != nil {
+		return "", err
+	}
+	known := make(map[string]bool, len(statuses))
+	for _, s := range statuses {
+		known[s.Key] = true
+	}
+	var keys []string
+	seen := map[string]bool{}
+	for _, key := range strings.Split(filter, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" || seen[key] {
+			continue
+		}
+		if !known[key] {
+			return "", fmt.Errorf("default_status_filter: unknown status %q; must be one of: %s", key, allowedStatusKeys(statuses))
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return strings.Join(keys, ","), nil
+}
+
+// normalizeCardFields checks each card field is an animal field cards can
+// show or one of the group's custom fields, dropping repeats.
+func normalizeCardFields(db *gorm.DB, groupID uint, fields []string) (models.StringList, error) {
+	if len(fields) > maxCardFields {
+		return nil, fmt.Errorf("card_fields can have at most %d fields", maxCardFields)
+	}
+	var customKeys map[string]bool
+	out := models.StringList{}
+	seen := map[string]bool{}
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if seen[field] {
+			continue
+		}
+		if key, ok := strings.CutPrefix(field, "field."); ok {
+			if customKeys == nil {
+				defs, err := groupCustomFields(db, groupID)
+				if err != nil {
+					return nil, err
+				}
+				customKeys = make(map[string]bool, len(defs))
+				for _, def := range defs {
+					customKeys[def.Key] = true
+				}
+			}
+			if !customKeys[key] {
+				return nil, fmt.Errorf("card_fields: the group has no custom field %q", key)
+			}
+		} else if !animalCardFields[field] {
+			return nil, fmt.Errorf("card_fields: %q can't be shown on cards", field)
+		}
+		seen[field] = true
+		out = append(out, field)
+	}
+	return out, nil
+}
+
+// applyGroupListDefaults fills in the group's default status filter and sort
+// where the request didn't choose its own. params are the resolved filters
+// (after any saved filter); listQuery holds the request's sort and paging.
+func applyGroupListDefaults(group models.Group, params, listQuery url.Values) {
+	if params.Get("status") == "" && group.DefaultStatusFilter != "" {
+		params.Set("status", group.DefaultStatusFilter)
+	}
+	if listQuery.Get("sort") == "" && group.DefaultSort != "" {
+		listQuery.Set("sort", group.DefaultSort)
+		if listQuery.Get("order") == "" && group.DefaultSortOrder != "" {
+			listQuery.Set("order", group.DefaultSortOrder)
+		}
+	}
+}
+
+// UpdateGroupDisplaySettings sets the status filter and sort a group's
+// animal list uses when a request gives none, the fields animal cards show,
+// the group's time zone, whether active animals need unique names, and
+// whether bonded partners must be adopted together (group admin or site
+// admin). Turning unique names on doesn't rename animals that already share
+// a name. Statuses must be
+// ones the group uses; card fields may name the group's custom fields as
+// "field.<key>".
+// Route: PUT /api/groups/:id/display-settings
+func UpdateGroupDisplaySettings(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		group, ok := loadBrandingGroup(c, db)
+		if !ok {
+			return
+		}
+		if !IsGroupAdminOrSiteAdmin(c, db, group.ID) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Group admin access required")
+			return
+		}
+
+		var req GroupDisplaySettingsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+
+		statusFilter, err := normalizeStatusFilter(db, group.ID, req.DefaultStatusFilter)
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		sortKey := strings.TrimSpace(req.DefaultSort)
+		if _, ok := animalSortKeys[sortKey]; sortKey != "" && !ok {
+			respondBadRequest(c, "default_sort must be one of: "+animalSortNames())
+			return
+		}
+		sortOrder := req.DefaultSortOrder
+		if sortKey == "" {
+			sortOrder = ""
+		}
+		cardFields, err := normalizeCardFields(db, group.ID, req.CardFields)
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		timeZone := strings.TrimSpace(req.TimeZone)
+		if err := validateTimeZone(timeZone); err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+
+		group.DefaultStatusFilter = statusFilter
+		group.DefaultSort = sortKey
+		group.DefaultSortOrder = sortOrder
+		group.CardFields = cardFields
+		group.TimeZone = timeZone
+		if req.UniqueAnimalNames != nil {
+			group.UniqueAnimalNames = *req.UniqueAnimalNames
+		}
+		if req.AdoptBondedTogether != nil {
+			group.AdoptBondedTogether = *req.AdoptBondedTogether
+		}
+		if err := db.Model(&group).Select("default_status_filter", "default_sort", "default_sort_order", "card_fields", "time_zone", "unique_animal_names", "adopt_bonded_together").
+			Updates(&group).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to update group display settings", err)
+			respondInternalError(c, "Failed to update display settings")
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventGroupUpdated, userID, map[string]interface{}{
+			"group_id": group.ID,
+			"change":   "display_settings",
+		})
+		respondOK(c, toGroupDisplaySettings(group))
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupDisplaySettings(t *testing.T) {
+	db := SetupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AnimalImage{}, &models.AnimalVideo{}))
+	lead := CreateTestUser(t, db, "lead", "lead@example.com", "password123", false)
+	member := CreateTestUser(t, db, "member", "member@example.com", "password123", false)
+	cats := CreateTestGroup(t, db, "Cats", "")
+	AddUserToGroupWithAdmin(t, db, lead.ID, cats.ID, true)
+	AddUserToGroupWithAdmin(t, db, member.ID, cats.ID, false)
+	require.NoError(t, db.Create(&models.AnimalCustomField{GroupID: cats.ID, Key: "litter_trained", Name: "Litter trained", Type: models.CustomFieldBoolean}).Error)
+
+	for _, a := range []struct{ name, status string }{
+		{"Whiskers", "available"}, {"Felix", "bite_quarantine"}, {"Tom", "foster"}, {"Alley", "available"},
+	} {
+		require.NoError(t, db.Create(&models.Animal{GroupID: cats.ID, Name: a.name, Species: "Cat", Status: a.status}).Error)
+	}
+
+	update := func(userID uint, body gin.H) (int, GroupDisplaySettings) {
+		c, w := accountTestContext(userID, false, http.MethodPut, "/", body)
+		c.Params = gin.Params{{Key: "id", Value: itoa(cats.ID)}}
+		UpdateGroupDisplaySettings(db)(c)
+		var settings GroupDisplaySettings
+		_ = json.Unmarshal(w.Body.Bytes(), &settings)
+		return w.Code, settings
+	}
+	list := func(query string) []string {
+		c, w := setupAnimalTestContext(member.ID, false)
+		c.Params = gin.Params{{Key: "id", Value: itoa(cats.ID)}}
+		c.Request = httptest.NewRequest(http.MethodGet, "/"+query, nil)
+		GetAnimals(db)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var animals []models.Animal
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &animals))
+		names := []string{}
+		for _, a := range animals {
+			names = append(names, a.Name)
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"Whiskers", "Felix", "Alley"}, list(""), "the app's defaults apply until the group sets its own")
+
+	code, _ := update(member.ID, gin.H{"default_status_filter": "available"})
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = update(lead.ID, gin.H{"default_status_filter": "available,napping"})
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = update(lead.ID, gin.H{"default_sort": "weight"})
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = update(lead.ID, gin.H{"card_fields": []string{"breed", "field.declawed"}})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, settings := update(lead.ID, gin.H{
+		"default_status_filter": " available ",
+		"default_sort":          "name",
+		"card_fields":           []string{"breed", "field.litter_trained", "breed"},
+	})
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "available", settings.DefaultStatusFilter)
+	assert.Equal(t, []string{"breed", "field.litter_trained"}, settings.CardFields)
+
+	var group models.Group
+	require.NoError(t, db.First(&group, cats.ID).Error)
+	assert.Equal(t, models.StringList{"breed", "field.litter_trained"}, group.CardFields)
+
+	assert.Equal(t, []string{"Alley", "Whiskers"}, list(""))
+	assert.Equal(t, []string{"Tom"}, list("?status=foster"), "the request's own filter wins")
+	assert.Equal(t, []string{"Whiskers", "Alley"}, list("?sort=arrival_date"), "and so does its sort")
+	assert.Equal(t, []string{"Whiskers", "Alley"}, list("?order=desc"))
+
+	// Clearing the settings restores the app's defaults
+	code, settings = update(lead.ID, gin.H{})
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, settings.CardFields)
+	assert.Equal(t, []string{"Whiskers", "Felix", "Alley"}, list(""))
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/auth"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// GroupEmailSender is how a group's notification emails present themselves
+type GroupEmailSender struct {
+	GroupID           uint       `json:"group_id"`
+	SenderName        string     `json:"sender_name"`
+	ReplyTo           string     `json:"reply_to"`
+	ReplyToVerified   bool       `json:"reply_to_verified"`
+	ReplyToVerifiedAt *time.Time `json:"reply_to_verified_at"`
+	VerificationSent  bool       `json:"verification_sent,omitempty"`
+}
+
+// GroupEmailSenderRequest replaces a group's email sender identity. Empty
+// values restore the site's defaults.
+type GroupEmailSenderRequest struct {
+	SenderName string `json:"sender_name"`
+	ReplyTo    string `json:"reply_to"`
+}
+
+func toGroupEmailSender(g models.Group) GroupEmailSender {
+	return GroupEmailSender{
+		GroupID:           g.ID,
+		SenderName:        g.EmailSenderName,
+		ReplyTo:           g.EmailReplyTo,
+		ReplyToVerified:   g.EmailReplyTo != "" && g.EmailReplyToVerifiedAt != nil,
+		ReplyToVerifiedAt: g.EmailReplyToVerifiedAt,
+	}
+}
+
+// withGroupSender returns ctx set to send as group's email sender identity,
+// if it has one. The reply-to address is only used once verified.
+func withGroupSender(ctx context.Context, group models.Group) context.Context {
+	sender := email.Sender{Name: group.EmailSenderName}
+	if group.EmailReplyToVerifiedAt != nil {
+		sender.ReplyTo = group.EmailReplyTo
+	}
+	if sender == (email.Sender{}) {
+		return ctx
+	}
+	return email.WithSender(ctx, sender)
+}
+
+// groupSenderContext loads groupID's email sender identity into ctx. If the
+// group can't be loaded the email goes out with the site's defaults.
+func groupSenderContext(ctx context.Context, db *gorm.DB, groupID uint) context.Context {
+	var group models.Group
+	if err := db.WithContext(ctx).Select("id", "email_sender_name", "email_reply_to", "email_reply_to_verified_at").
+		First(&group, groupID).Error; err != nil {
+		logging.WithContext(ctx).WithField("group_id", groupID).Warnf("Failed to load group email sender: %v", err)
+		return ctx
+	}
+	return withGroupSender(ctx, group)
+}
+
+// sendReplyToVerification issues a fresh verification token for group's
+// reply-to address, replacing any outstanding one, and emails it there.
+// Tokens are stored the same way as user email verification tokens.
+func sendReplyToVerification(ctx context.Context, db *gorm.DB, emailService *email.Service, group *models.Group) error {
+	if emailService == nil || !emailService.IsConfigured() {
+		return fmt.Errorf("email service is not configured")
+	}
+
+	token, err := generateSecureToken()
+	if err != nil {
+		return fmt.Errorf("failed to generate verification token: %w", err)
+	}
+	hashedToken, err := auth.HashPassword(token)
+	if err != nil {
+		return fmt.Errorf("failed to hash verification token: %w", err)
+	}
+
+	if err := db.WithContext(ctx).Model(group).Updates(map[string]interface{}{
+		"email_reply_to_token":  hashedToken,
+		"email_reply_to_lookup": token[:TokenLookupPrefixLength],
+		"email_reply_to_expiry": time.Now().Add(EmailVerificationExpiry),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to store verification token: %w", err)
+	}
+
+	return emailService.SendReplyToVerificationEmail(ctx, group.EmailReplyTo, group.Name, token)
+}
+
+// loadEmailSenderGroup loads the group in the path for its admins (group
+// admin or site admin), responding with an error otherwise
+func loadEmailSenderGroup(c *gin.Context, db *gorm.DB) (models.Group, bool) {
+	group, ok := loadBrandingGroup(c, db)
+	if !ok {
+		return group, false
+	}
+	if !IsGroupAdminOrSiteAdmin(c, db, group.ID) {
+		respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Group admin access required")
+		return group, false
+	}
+	return group, true
+}
+
+// GetGroupEmailSender returns the display name and reply-to address of a
+// group's notification emails (group admin or site admin)
+// Route: GET /api/groups/:id/email-sender
+func GetGroupEmailSender(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		group, ok := loadEmailSenderGroup(c, db)
+		if !ok {
+			return
+		}
+		respondOK(c, toGroupEmailSender(group))
+	}
+}
+
+// UpdateGroupEmailSender sets the display name and reply-to address of a
+// group's notification emails (group admin or site admin). A new reply-to
+// address is sent a verification link and isn't used until it's followed.
+// Route: PUT /api/groups/:id/email-sender
+func UpdateGroupEmailSender(db *gorm.DB, emailService *email.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		db := middleware.GetDB(c, db)
+		logger := middleware.GetLogger(c)
+		group, ok := loadEmailSenderGroup(c, db)
+		if !ok {
+			return
+		}
+
+		var req GroupEmailSenderRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		sender := email.Sender{Name: strings.TrimSpace(req.SenderName), ReplyTo: strings.TrimSpace(req.ReplyTo)}
+		if err := email.ValidateSender(sender); err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+
+		updates := map[string]interface{}{"email_sender_name": sender.Name}
+		replyToChanged := !strings.EqualFold(sender.ReplyTo, group.EmailReplyTo)
+		if replyToChanged {
+			if sender.ReplyTo != "" && (emailService == nil || !emailService.IsConfigured()) {
+				respondError(c, http.StatusServiceUnavailable, ErrCodeEmailNotConfigured, "Email service is not configured, so the reply-to address can't be verified")
+				return
+			}
+			updates["email_reply_to"] = sender.ReplyTo
+			updates["email_reply_to_verified_at"] = nil
+			updates["email_reply_to_token"] = ""
+			updates["email_reply_to_lookup"] = ""
+			updates["email_reply_to_expiry"] = nil
+		}
+		if err := db.Model(&group).Updates(updates).Error; err != nil {
+			logger.Error("Failed to update group email sender", err)
+			respondInternalError(c, "Failed to update email sender")
+			return
+		}
+		group.EmailSenderName = sender.Name
+		if replyToChanged {
+			group.EmailReplyTo = sender.ReplyTo
+			group.EmailReplyToVerifiedAt = nil
+		}
+
+		response := toGroupEmailSender(group)
+		if replyToChanged && sender.ReplyTo != "" {
+			// The address is saved either way; a failed send can be retried
+			// with the resend endpoint
+			if err := sendReplyToVerification(ctx, db, emailService, &group); err != nil {
+				logger.Error("Failed to send reply-to verification email", err)
+			} else {
+				response.VerificationSent = true
+			}
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(ctx, logging.AuditEventGroupUpdated, userID, map[string]interface{}{
+			"group_id": group.ID,
+			"change":   "email_sender",
+		})
+		respondOK(c, response)
+	}
+}
+
+// ResendGroupReplyToVerification emails a new verification link to a
+// group's unverified reply-to address (group admin or site admin)
+// Route: POST /api/groups/:id/email-sender/resend-verification
+func ResendGroupReplyToVerification(db *gorm.DB, emailService *email.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		group, ok := loadEmailSenderGroup(c, db)
+		if !ok {
+			return
+		}
+		if group.EmailReplyTo == "" {
+			respondBadRequest(c, "The group has no reply-to address")
+			return
+		}
+		if group.EmailReplyToVerifiedAt != nil {
+			respondError(c, http.StatusConflict, ErrCodeConflict, "Reply-to address is already verified")
+			return
+		}
+		if emailService == nil || !emailService.IsConfigured() {
+			respondError(c, http.StatusServiceUnavailable, ErrCodeEmailNotConfigured, "Email service is not configured")
+			return
+		}
+
+		if err := sendReplyToVerification(c.Request.Context(), db, emailService, &group); err != nil {
+			middleware.GetLogger(c).Error("Failed to send reply-to verification email", err)
+			respondInternalError(c, "Failed to send verification email")
+			return
+		}
+		respondOK(c, gin.H{"message": "Verification email sent"})
+	}
+}
+
+// VerifyGroupReplyTo marks the reply-to address the token was sent to as
+// verified, so the group's emails start using it
+// Route: POST /api/verify-reply-to
+func VerifyGroupReplyTo(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		var req VerifyEmailRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+
+		// Guard against tokens shorter than the lookup prefix length
+		if len(req.Token) < TokenLookupPrefixLength {
+			respondBadRequest(c, "Invalid or expired verification token")
+			return
+		}
+
+		var group models.Group
+		if err := db.Where(
+			"email_reply_to_lookup = ? AND email_reply_to_token IS NOT NULL AND email_reply_to_token != ''",
+			req.Token[:TokenLookupPrefixLength],
+		).First(&group).Error; err != nil {
+			respondBadRequest(c, "Invalid or expired verification token")
+			return
+		}
+
+		if err := auth.CheckPassword(group.EmailReplyToToken, req.Token); err != nil {
+			respondBadRequest(c, "Invalid or expired verification token")
+			return
+		}
+
+		if group.EmailReplyToExpiry == nil || group.EmailReplyToExpiry.Before(time.Now()) {
+			respondBadRequest(c, "Verification token has expired. Ask a group admin to send a new one.")
+			return
+		}
+
+		now := time.Now()
+		if err := db.Model(&group).Updates(map[string]interface{}{
+			"email_reply_to_verified_at": now,
+			"email_reply_to_token":       "",
+			"email_reply_to_lookup":      "",
+			"email_reply_to_expiry":      nil,
+		}).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to mark reply-to address verified", err)
+			respondInternalError(c, "Failed to verify reply-to address")
+			return
+		}
+
+		respondOK(c, gin.H{"message": "Reply-to address verified", "group_id": group.ID, "reply_to": group.EmailReplyTo})
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// senderRecordingEmailProvider records the sender each email went out as
+type senderRecordingEmailProvider struct {
+	sent []sentEmail
+}
+
+type sentEmail struct {
+	to     string
+	body   string
+	sender email.Sender
+}
+
+func (p *senderRecordingEmailProvider) SendEmail(ctx context.Context, to, _, body string) error {
+	p.sent = append(p.sent, sentEmail{to: to, body: body, sender: email.SenderFromContext(ctx)})
+	return nil
+}
+func (p *senderRecordingEmailProvider) IsConfigured() bool      { return true }
+func (p *senderRecordingEmailProvider) GetProviderName() string { return "recording" }
+
+func TestGroupEmailSender(t *testing.T) {
+	db := SetupTestDB(t)
+	lead := CreateTestUser(t, db, "lead", "lead@example.com", "password123", false)
+	member := CreateTestUser(t, db, "member", "member@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	AddUserToGroupWithAdmin(t, db, lead.ID, group.ID, true)
+	AddUserToGroupWithAdmin(t, db, member.ID, group.ID, false)
+	require.NoError(t, db.Model(&models.User{}).Where("id = ?", member.ID).Update("email_notifications_enabled", true).Error)
+
+	provider := &senderRecordingEmailProvider{}
+	emailService := email.NewServiceWithProvider(provider, db)
+	params := gin.Params{{Key: "id", Value: itoa(group.ID)}}
+
+	update := func(userID uint, body gin.H) (int, GroupEmailSender) {
+		c, w := accountTestContext(userID, false, http.MethodPut, "/", body)
+		c.Params = params
+		UpdateGroupEmailSender(db, emailService)(c)
+		var settings GroupEmailSender
+		_ = json.Unmarshal(w.Body.Bytes(), &settings)
+		return w.Code, settings
+	}
+	verify := func(token string) int {
+		c, w := accountTestContext(0, false, http.MethodPost, "/", gin.H{"token": token})
+		VerifyGroupReplyTo(db)(c)
+		return w.Code
+	}
+	resend := func() int {
+		c, w := accountTestContext(lead.ID, false, http.MethodPost, "/", nil)
+		c.Params = params
+		ResendGroupReplyToVerification(db, emailService)(c)
+		return w.Code
+	}
+	// sendUpdate emails the group's members and returns the sender used
+	queue := jobs.NewQueue(db)
+	RegisterJobHandlers(queue, db, emailService, nil)
+	sendUpdate := func(groupIDs []uint) email.Sender {
+		provider.sent = nil
+		_, err := enqueueAnnouncementEmails(context.Background(), db, groupIDs, "Walk schedule", "New times")
+		require.NoError(t, err)
+		require.Equal(t, 1, queue.RunDue(context.Background()))
+		require.Len(t, provider.sent, 1)
+		return provider.sent[0].sender
+	}
+
+	assert.Equal(t, email.Sender{}, sendUpdate([]uint{group.ID}), "groups use the site's sender until they set their own")
+
+	code, _ := update(member.ID, gin.H{"sender_name": "Dog Walkers"})
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = update(lead.ID, gin.H{"sender_name": "Dogs\r\nBcc: everyone@example.com"})
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = update(lead.ID, gin.H{"reply_to": "not-an-address"})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	provider.sent = nil
+	code, settings := update(lead.ID, gin.H{"sender_name": " Dog Walkers ", "reply_to": "dogs@example.org"})
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, GroupEmailSender{GroupID: group.ID, SenderName: "Dog Walkers", ReplyTo: "dogs@example.org", VerificationSent: true}, settings)
+	require.Len(t, provider.sent, 1)
+	assert.Equal(t, "dogs@example.org", provider.sent[0].to)
+	token := regexp.MustCompile(`token=([0-9a-f]+)`).FindStringSubmatch(provider.sent[0].body)
+	require.Len(t, token, 2)
+
+	assert.Equal(t, email.Sender{Name: "Dog Walkers"}, sendUpdate([]uint{group.ID}), "an unverified reply-to isn't used")
+
+	assert.Equal(t, http.StatusBadRequest, verify(token[1][:TokenLookupPrefixLength]+"0000"))
+	require.Equal(t, http.StatusOK, verify(token[1]))
+	assert.Equal(t, http.StatusBadRequest, verify(token[1]), "tokens work once")
+	assert.Equal(t, http.StatusConflict, resend())
+
+	assert.Equal(t, email.Sender{Name: "Dog Walkers", ReplyTo: "dogs@example.org"}, sendUpdate([]uint{group.ID}))
+	assert.Equal(t, email.Sender{}, sendUpdate(nil), "site-wide emails keep the site's sender")
+
+	// Renaming keeps the verified address; changing the address needs a new check
+	code, settings = update(lead.ID, gin.H{"sender_name": "Dog Team", "reply_to": "DOGS@example.org"})
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, settings.ReplyToVerified)
+	assert.False(t, settings.VerificationSent)
+	code, settings = update(lead.ID, gin.H{"sender_name": "Dog Team", "reply_to": "walkers@example.org"})
+	require.Equal(t, http.StatusOK, code)
+	assert.False(t, settings.ReplyToVerified)
+	assert.Equal(t, email.Sender{Name: "Dog Team"}, sendUpdate([]uint{group.ID}))
+	assert.Equal(t, http.StatusOK, resend())
+}
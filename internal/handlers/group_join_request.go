@@ -0,0 +1,347 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobJoinRequestEmail is the background job type that emails one user about
+// a group join request: a group admin about a new request, or the requester
+// about the decision.
+const JobJoinRequestEmail = "join_request_email"
+
+// joinRequestEmailJob is the payload of a JobJoinRequestEmail job.
+type joinRequestEmailJob struct {
+	RequestID uint `json:"request_id"`
+	UserID    uint `json:"user_id"` // Recipient
+}
+
+// errJoinRequestReviewed is returned when a request was reviewed already
+var errJoinRequestReviewed = errors.New("join request already reviewed")
+
+// CreateJoinRequestRequest is the body of a join request; it may be omitted
+type CreateJoinRequestRequest struct {
+	Message string `json:"message" binding:"max=1000"`
+}
+
+// ReviewJoinRequestRequest is the optional body of a denial
+type ReviewJoinRequestRequest struct {
+	Reason string `json:"reason" binding:"max=1000"`
+}
+
+// joinRequestReviewers returns the users told about a new request to join
+// groupID: its group admins, or the site admins if it has none. Only users
+// who get notification emails are included.
+func joinRequestReviewers(db *gorm.DB, groupID uint) ([]uint, error) {
+	var ids []uint
+	if err := notifiableUsers(db.Model(&models.User{})).
+		Joins("JOIN user_groups ON user_groups.user_id = users.id").
+		Where("user_groups.group_id = ? AND user_groups.is_group_admin = ?", groupID, true).
+		Pluck("users.id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) > 0 {
+		return ids, nil
+	}
+	var admins int64
+	if err := db.Model(&models.UserGroup{}).
+		Where("group_id = ? AND is_group_admin = ?", groupID, true).Count(&admins).Error; err != nil || admins > 0 {
+		return nil, err
+	}
+	err := notifiableUsers(db.Model(&models.User{})).Where("users.is_admin = ?", true).Pluck("users.id", &ids).Error
+	return ids, err
+}
+
+// CreateJoinRequest asks to join a group the current user isn't a member of.
+// The group's admins are emailed about it.
+// Route: POST /api/groups/:id/join-requests
+func CreateJoinRequest(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		var req CreateJoinRequestRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				respondValidationError(c, err)
+				return
+			}
+		}
+		userID, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+
+		var group models.Group
+		if err := db.First(&group, c.Param("id")).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, "Group not found")
+			return
+		}
+
+		var existing int64
+		if err := db.Model(&models.UserGroup{}).Where("user_id = ? AND group_id = ?", userID, group.ID).Count(&existing).Error; err != nil {
+			respondInternalError(c, "Failed to check membership")
+			return
+		}
+		if existing > 0 {
+			respondError(c, http.StatusConflict, ErrCodeConflict, "You are already a member of this group")
+			return
+		}
+		if err := db.Model(&models.GroupJoinRequest{}).
+			Where("user_id = ? AND group_id = ? AND status = ?", userID, group.ID, models.JoinRequestPending).
+			Count(&existing).Error; err != nil {
+			respondInternalError(c, "Failed to check join requests")
+			return
+		}
+		if existing > 0 {
+			respondError(c, http.StatusConflict, ErrCodeConflict, "You already have a pending request to join this group")
+			return
+		}
+
+		request := models.GroupJoinRequest{
+			GroupID: group.ID,
+			UserID:  userID,
+			Message: req.Message,
+			Status:  models.JoinRequestPending,
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&request).Error; err != nil {
+				return err
+			}
+			reviewers, err := joinRequestReviewers(tx, group.ID)
+			if err != nil {
+				return err
+			}
+			payloads := make([]interface{}, len(reviewers))
+			for i, id := range reviewers {
+				payloads[i] = joinRequestEmailJob{RequestID: request.ID, UserID: id}
+			}
+			return jobs.EnqueueMany(tx, JobJoinRequestEmail, payloads)
+		})
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to create join request", err)
+			respondInternalError(c, "Failed to create join request")
+			return
+		}
+
+		respondCreated(c, request)
+	}
+}
+
+// GetJoinRequests lists a group's join requests, oldest first (group admin
+// or site admin). Query param status: pending (default), approved, denied,
+// or all.
+// Route: GET /api/groups/:id/join-requests
+func GetJoinRequests(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
+			return
+		}
+
+		query := db.Preload("User").Where("group_id = ?", groupID)
+		switch status := c.DefaultQuery("status", models.JoinRequestPending); status {
+		case "all":
+		case models.JoinRequestPending, models.JoinRequestApproved, models.JoinRequestDenied:
+			query = query.Where("status = ?", status)
+		default:
+			respondBadRequest(c, "status must be pending, approved, denied, or all")
+			return
+		}
+
+		requests := []models.GroupJoinRequest{}
+		if err := query.Order("created_at ASC, id ASC").Find(&requests).Error; err != nil {
+			respondInternalError(c, "Failed to fetch join requests")
+			return
+		}
+
+		respondOK(c, requests)
+	}
+}
+
+// GetMyJoinRequests lists the current user's join requests, newest first
+// Route: GET /api/me/join-requests
+func GetMyJoinRequests(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+
+		requests := []models.GroupJoinRequest{}
+		if err := db.Preload("Group", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "name")
+		}).Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&requests).Error; err != nil {
+			respondInternalError(c, "Failed to fetch join requests")
+			return
+		}
+
+		respondOK(c, requests)
+	}
+}
+
+// ApproveJoinRequest approves a pending join request, adding the requester
+// to the group, and emails them (group admin or site admin).
+// Route: POST /api/groups/:id/join-requests/:requestId/approve
+func ApproveJoinRequest(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reviewJoinRequest(c, middleware.GetDB(c, db), true, "")
+	}
+}
+
+// DenyJoinRequest denies a pending join request and emails the requester,
+// with the optional reason (group admin or site admin).
+// Route: POST /api/groups/:id/join-requests/:requestId/deny
+func DenyJoinRequest(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ReviewJoinRequestRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				respondValidationError(c, err)
+				return
+			}
+		}
+		reviewJoinRequest(c, middleware.GetDB(c, db), false, req.Reason)
+	}
+}
+
+// reviewJoinRequest approves or denies the :requestId join request to group
+// :id and responds with it.
+func reviewJoinRequest(c *gin.Context, db *gorm.DB, approve bool, reason string) {
+	groupID := c.Param("id")
+	userID, _ := c.Get("user_id")
+	isAdmin, _ := c.Get("is_admin")
+
+	if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
+		respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
+		return
+	}
+	requestID, err := strconv.ParseUint(c.Param("requestId"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid join request ID")
+		return
+	}
+	reviewerID, _ := middleware.GetUserID(c)
+
+	var request models.GroupJoinRequest
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND group_id = ?", requestID, groupID).First(&request).Error; err != nil {
+			return err
+		}
+		if request.Status != models.JoinRequestPending {
+			return errJoinRequestReviewed
+		}
+
+		now := time.Now()
+		request.Status = models.JoinRequestDenied
+		request.DenyReason = reason
+		if approve {
+			request.Status = models.JoinRequestApproved
+			// The user may have been added directly since asking
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&models.UserGroup{UserID: request.UserID, GroupID: request.GroupID}).Error; err != nil {
+				return err
+			}
+		}
+		request.ReviewedByID = &reviewerID
+		request.ReviewedAt = &now
+		// Guard on the status so two admins reviewing at once can't both win
+		result := tx.Model(&request).Where("status = ?", models.JoinRequestPending).Updates(map[string]interface{}{
+			"status":         request.Status,
+			"deny_reason":    request.DenyReason,
+			"reviewed_by_id": request.ReviewedByID,
+			"reviewed_at":    request.ReviewedAt,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errJoinRequestReviewed
+		}
+		_, err := jobs.Enqueue(tx, JobJoinRequestEmail, joinRequestEmailJob{RequestID: request.ID, UserID: request.UserID})
+		return err
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondNotFound(c, "Join request not found")
+	case errors.Is(err, errJoinRequestReviewed):
+		respondError(c, http.StatusConflict, ErrCodeConflict, "This join request has already been reviewed")
+	case err != nil:
+		middleware.GetLogger(c).Error("Failed to review join request", err)
+		respondInternalError(c, "Failed to review join request")
+	default:
+		respondOK(c, request)
+	}
+}
+
+// joinRequestEmailJobHandler sends a JobJoinRequestEmail. The requester gets
+// the decision; anyone else is a reviewer and gets the new request, unless
+// it was reviewed already or they have turned notification emails off.
+func joinRequestEmailJobHandler(db *gorm.DB, emailService *email.Service) jobs.Handler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var job joinRequestEmailJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return jobs.Permanent(err)
+		}
+		if emailService == nil || !emailService.IsConfigured() {
+			return errors.New("email service is not configured")
+		}
+		db := db.WithContext(ctx)
+
+		var request models.GroupJoinRequest
+		var recipient models.User
+		for _, load := range []func() error{
+			func() error { return db.Preload("User").Preload("Group").First(&request, job.RequestID).Error },
+			func() error {
+				if job.UserID == request.UserID {
+					return db.First(&recipient, job.UserID).Error
+				}
+				return notifiableUsers(db).First(&recipient, job.UserID).Error
+			},
+		} {
+			if err := load(); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil
+				}
+				return err
+			}
+		}
+
+		ctx = withGroupSender(ctx, request.Group)
+		if job.UserID == request.UserID {
+			if request.Status == models.JoinRequestPending {
+				return nil
+			}
+			link := frontendURL()
+			if request.Status == models.JoinRequestApproved {
+				link = fmt.Sprintf("%s/groups/%d", frontendURL(), request.GroupID)
+			}
+			return emailService.SendJoinRequestDecisionEmail(ctx, recipient.Email, request.Group.Name,
+				request.Status == models.JoinRequestApproved, request.DenyReason, link)
+		}
+		if request.Status != models.JoinRequestPending {
+			return nil
+		}
+		link := fmt.Sprintf("%s/groups/%d", frontendURL(), request.GroupID)
+		return emailService.SendJoinRequestEmail(ctx, recipient.Email, request.User.Username, request.Group.Name, request.Message, link)
+	}
+}
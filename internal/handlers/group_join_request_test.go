@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupJoinRequests(t *testing.T) {
+	db := SetupTestDB(t)
+	groupAdmin := CreateTestUser(t, db, "lead", "lead@example.com", "password123", false)
+	volunteer := CreateTestUser(t, db, "newbie", "newbie@example.com", "password123", false)
+	other := CreateTestUser(t, db, "other", "other@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	AddUserToGroupWithAdmin(t, db, groupAdmin.ID, group.ID, true)
+	require.NoError(t, db.Model(groupAdmin).Update("email_notifications_enabled", true).Error)
+	gid := fmt.Sprint(group.ID)
+
+	provider := &recordingEmailProvider{}
+	queue := jobs.NewQueue(db)
+	RegisterJobHandlers(queue, db, email.NewServiceWithProvider(provider, db), nil)
+
+	create := func(userID uint, body any) (int, models.GroupJoinRequest) {
+		c, w := accountTestContext(userID, false, http.MethodPost, "/api/groups/"+gid+"/join-requests", body)
+		c.Params = gin.Params{{Key: "id", Value: gid}}
+		CreateJoinRequest(db)(c)
+		var request models.GroupJoinRequest
+		_ = json.Unmarshal(w.Body.Bytes(), &request)
+		return w.Code, request
+	}
+	review := func(userID uint, requestID uint, action string, body any) (int, models.GroupJoinRequest) {
+		c, w := accountTestContext(userID, false, http.MethodPost, "/", body)
+		c.Params = gin.Params{{Key: "id", Value: gid}, {Key: "requestId", Value: fmt.Sprint(requestID)}}
+		if action == "approve" {
+			ApproveJoinRequest(db)(c)
+		} else {
+			DenyJoinRequest(db)(c)
+		}
+		var request models.GroupJoinRequest
+		_ = json.Unmarshal(w.Body.Bytes(), &request)
+		return w.Code, request
+	}
+	pending := func(userID uint) (int, []models.GroupJoinRequest) {
+		c, w := accountTestContext(userID, false, http.MethodGet, "/api/groups/"+gid+"/join-requests", nil)
+		c.Params = gin.Params{{Key: "id", Value: gid}}
+		GetJoinRequests(db)(c)
+		var requests []models.GroupJoinRequest
+		_ = json.Unmarshal(w.Body.Bytes(), &requests)
+		return w.Code, requests
+	}
+
+	code, request := create(volunteer.ID, gin.H{"message": "I walk dogs on weekends"})
+	require.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, models.JoinRequestPending, request.Status)
+	code, _ = create(volunteer.ID, nil)
+	assert.Equal(t, http.StatusConflict, code, "one pending request at a time")
+	code, _ = create(groupAdmin.ID, nil)
+	assert.Equal(t, http.StatusConflict, code, "members can't ask to join")
+
+	// The group admin is emailed about the request
+	assert.Equal(t, 1, queue.RunDue(context.Background()))
+	assert.Equal(t, []string{"lead@example.com"}, provider.sentTo)
+
+	code, requests := pending(groupAdmin.ID)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, requests, 1)
+	assert.Equal(t, "newbie", requests[0].User.Username)
+	assert.Equal(t, "I walk dogs on weekends", requests[0].Message)
+	code, _ = pending(volunteer.ID)
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = review(volunteer.ID, request.ID, "approve", nil)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	// Approving adds the member and tells them
+	code, request = review(groupAdmin.ID, request.ID, "approve", nil)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, models.JoinRequestApproved, request.Status)
+	require.NotNil(t, request.ReviewedByID)
+	assert.Equal(t, groupAdmin.ID, *request.ReviewedByID)
+	var membership models.UserGroup
+	require.NoError(t, db.Where("user_id = ? AND group_id = ?", volunteer.ID, group.ID).First(&membership).Error)
+	assert.False(t, membership.IsGroupAdmin)
+	code, _ = review(groupAdmin.ID, request.ID, "deny", nil)
+	assert.Equal(t, http.StatusConflict, code, "reviewed requests can't be reviewed again")
+	assert.Equal(t, 1, queue.RunDue(context.Background()))
+	assert.Equal(t, []string{"lead@example.com", "newbie@example.com"}, provider.sentTo)
+
+	// Denials carry the reason; the user can see their requests and ask again
+	_, request = create(other.ID, nil)
+	code, request = review(groupAdmin.ID, request.ID, "deny", gin.H{"reason": "We're full until spring"})
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, models.JoinRequestDenied, request.Status)
+	assert.Equal(t, "We're full until spring", request.DenyReason)
+	assert.Equal(t, 2, queue.RunDue(context.Background()), "a new request email and the decision")
+
+	c, w := accountTestContext(other.ID, false, http.MethodGet, "/api/me/join-requests", nil)
+	GetMyJoinRequests(db)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var mine []models.GroupJoinRequest
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &mine))
+	require.Len(t, mine, 1)
+	assert.Equal(t, "Dogs", mine[0].Group.Name)
+	assert.Equal(t, models.JoinRequestDenied, mine[0].Status)
+
+	code, _ = create(other.ID, nil)
+	assert.Equal(t, http.StatusCreated, code)
+	code, _ = review(groupAdmin.ID, 999, "approve", nil)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestJoinRequestReviewers_FallBackToSiteAdmins(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	CreateTestUser(t, db, "quietadmin", "quiet@example.com", "password123", true)
+	require.NoError(t, db.Model(admin).Update("email_notifications_enabled", true).Error)
+	group := CreateTestGroup(t, db, "Cats", "")
+
+	ids, err := joinRequestReviewers(db, group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{admin.ID}, ids, "groups without admins go to opted-in site admins")
+
+	lead := CreateTestUser(t, db, "lead", "lead@example.com", "password123", false)
+	AddUserToGroupWithAdmin(t, db, lead.ID, group.ID, true)
+	ids, err = joinRequestReviewers(db, group.ID)
+	require.NoError(t, err)
+	assert.Empty(t, ids, "a group admin who opted out isn't replaced by site admins")
+}
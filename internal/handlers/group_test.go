@@ -10,6 +10,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/auth"
@@ -625,61 +626,99 @@ func TestUpdateGroup(t *testing.T) {
 	}
 }
 
-// TestDeleteGroup tests deleting groups (admin only)
+// TestDeleteGroup tests the two-step group deletion (admin only)
 func TestDeleteGroup(t *testing.T) {
-	tests := []struct {
-		name           string
-		setupFunc      func(*gorm.DB) uint
-		expectedStatus int
-		shouldExist    bool
-	}{
-		{
-			name: "delete existing group",
-			setupFunc: func(db *gorm.DB) uint {
-				group := createTestGroup(t, db, "Group to Delete", "Will be deleted")
-				return group.ID
-			},
-			expectedStatus: http.StatusOK,
-			shouldExist:    false,
-		},
-		{
-			name: "delete non-existent group (idempotent)",
-			setupFunc: func(db *gorm.DB) uint {
-				return 99999
-			},
-			expectedStatus: http.StatusOK, // GORM Delete is idempotent
-			shouldExist:    false,
-		},
+	db := setupGroupTestDB(t)
+	if err := db.AutoMigrate(&models.Animal{}, &models.Protocol{}, &models.Update{}); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+	admin := createGroupTestUser(t, db, "admin", "admin@example.com", true)
+	otherAdmin := createGroupTestUser(t, db, "admin2", "admin2@example.com", true)
+	member := createGroupTestUser(t, db, "member", "member@example.com", false)
+	group := createTestGroup(t, db, "Group to Delete", "Will be deleted")
+	other := createTestGroup(t, db, "Other Group", "Stays")
+	if err := db.Create(&models.UserGroup{UserID: member.ID, GroupID: group.ID}).Error; err != nil {
+		t.Fatalf("Failed to add member: %v", err)
+	}
+	for _, name := range []string{"Rex", "Buddy"} {
+		if err := db.Create(&models.Animal{GroupID: group.ID, Name: name, Status: "available"}).Error; err != nil {
+			t.Fatalf("Failed to create animal: %v", err)
+		}
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			db := setupGroupTestDB(t)
-			user := createGroupTestUser(t, db, "admin", "admin@example.com", true)
-
-			groupID := tt.setupFunc(db)
+	deleteGroup := func(userID, groupID uint, token string) *httptest.ResponseRecorder {
+		c, w := setupGroupTestContext(userID, true)
+		c.Params = gin.Params{{Key: "id", Value: fmt.Sprintf("%d", groupID)}}
+		target := fmt.Sprintf("/api/admin/groups/%d", groupID)
+		if token != "" {
+			target += "?confirmation_token=" + token
+		}
+		c.Request = httptest.NewRequest("DELETE", target, nil)
+		DeleteGroup(db)(c)
+		return w
+	}
+	groupExists := func(groupID uint) bool {
+		var count int64
+		db.Model(&models.Group{}).Where("id = ?", groupID).Count(&count)
+		return count > 0
+	}
 
-			c, w := setupGroupTestContext(user.ID, true)
-			c.Params = gin.Params{{Key: "id", Value: fmt.Sprintf("%d", groupID)}}
-			c.Request = httptest.NewRequest("DELETE", fmt.Sprintf("/api/v1/groups/%d", groupID), nil)
+	if w := deleteGroup(admin.ID, 99999, ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing group, got %d", w.Code)
+	}
 
-			handler := DeleteGroup(db)
-			handler(c)
+	// The first call only describes the impact
+	w := deleteGroup(admin.ID, group.ID, "")
+	if w.Code != http.StatusPreconditionRequired {
+		t.Fatalf("Expected 428, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Code              ErrorCode           `json:"code"`
+		ConfirmationToken string              `json:"confirmation_token"`
+		Impact            GroupDeletionImpact `json:"impact"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if resp.Code != ErrCodeConfirmationRequired || resp.ConfirmationToken == "" {
+		t.Errorf("Expected a confirmation token, got %+v", resp)
+	}
+	if resp.Impact.MemberCount != 1 || resp.Impact.AnimalCount != 2 {
+		t.Errorf("Expected 1 member and 2 animals, got %+v", resp.Impact)
+	}
+	if !groupExists(group.ID) {
+		t.Fatal("Group was deleted without confirmation")
+	}
 
-			if w.Code != tt.expectedStatus {
-				t.Errorf("Expected status %d, got %d. Body: %s", tt.expectedStatus, w.Code, w.Body.String())
-			}
+	// The token is bound to the admin and the group
+	for _, tc := range []struct {
+		userID, groupID uint
+		token           string
+	}{
+		{otherAdmin.ID, group.ID, resp.ConfirmationToken},
+		{admin.ID, other.ID, resp.ConfirmationToken},
+		{admin.ID, group.ID, "123.forged"},
+	} {
+		if w := deleteGroup(tc.userID, tc.groupID, tc.token); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for a token that doesn't match, got %d", w.Code)
+		}
+	}
+	expired, _, err := issueConfirmationToken("delete_group", group.ID, admin.ID, time.Now().Add(-confirmationTokenTTL-time.Second))
+	if err != nil {
+		t.Fatalf("Failed to issue token: %v", err)
+	}
+	if w := deleteGroup(admin.ID, group.ID, expired); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an expired token, got %d", w.Code)
+	}
+	if !groupExists(group.ID) || !groupExists(other.ID) {
+		t.Fatal("Group was deleted with an invalid token")
+	}
 
-			// Verify deletion
-			var group models.Group
-			err := db.First(&group, groupID).Error
-			if tt.shouldExist && err != nil {
-				t.Error("Expected group to exist but it doesn't")
-			}
-			if !tt.shouldExist && err == nil {
-				t.Error("Expected group to be deleted but it still exists")
-			}
-		})
+	if w := deleteGroup(admin.ID, group.ID, resp.ConfirmationToken); w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	if groupExists(group.ID) {
+		t.Error("Expected group to be deleted but it still exists")
 	}
 }
 
@@ -876,6 +915,87 @@ func TestRemoveUserFromGroup(t *testing.T) {
 	}
 }
 
+// TestSetUserGroups tests replacing a user's memberships in one request
+func TestSetUserGroups(t *testing.T) {
+	db := setupGroupTestDB(t)
+	admin := createGroupTestUser(t, db, "admin", "admin@example.com", true)
+	user := createGroupTestUser(t, db, "regularuser", "user@example.com", false)
+	dogs := createTestGroup(t, db, "Dogs", "")
+	cats := createTestGroup(t, db, "Cats", "")
+	birds := createTestGroup(t, db, "Birds", "")
+	archived := createTestGroup(t, db, "Archived", "")
+	for _, m := range []models.UserGroup{
+		{UserID: user.ID, GroupID: dogs.ID, IsGroupAdmin: true},
+		{UserID: user.ID, GroupID: cats.ID},
+		{UserID: user.ID, GroupID: archived.ID},
+	} {
+		if err := db.Create(&m).Error; err != nil {
+			t.Fatalf("Failed to create membership: %v", err)
+		}
+	}
+	if err := db.Delete(archived).Error; err != nil {
+		t.Fatalf("Failed to delete group: %v", err)
+	}
+
+	setGroups := func(body string) *httptest.ResponseRecorder {
+		c, w := setupGroupTestContext(admin.ID, true)
+		c.Params = gin.Params{{Key: "userId", Value: fmt.Sprintf("%d", user.ID)}}
+		c.Request = httptest.NewRequest("PUT", "/api/admin/users/1/groups", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		SetUserGroups(db)(c)
+		return w
+	}
+
+	if w := setGroups(`{"groups": [{"group_id": 99999}]}`); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown group, got %d", w.Code)
+	}
+	if w := setGroups(`{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without groups, got %d", w.Code)
+	}
+
+	// Keep dogs (admin rights untouched), drop cats, add birds as group admin
+	body := fmt.Sprintf(`{"groups": [{"group_id": %d}, {"group_id": %d, "is_group_admin": true}]}`, dogs.ID, birds.ID)
+	w := setGroups(body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Added        []uint `json:"added"`
+		Removed      []uint `json:"removed"`
+		AdminChanged []uint `json:"admin_changed"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if fmt.Sprint(resp.Added) != fmt.Sprint([]uint{birds.ID}) || fmt.Sprint(resp.Removed) != fmt.Sprint([]uint{cats.ID}) || len(resp.AdminChanged) != 0 {
+		t.Errorf("Unexpected diff: %+v", resp)
+	}
+
+	var memberships []models.UserGroup
+	if err := db.Where("user_id = ?", user.ID).Order("group_id").Find(&memberships).Error; err != nil {
+		t.Fatalf("Failed to load memberships: %v", err)
+	}
+	want := map[uint]bool{dogs.ID: true, birds.ID: true, archived.ID: false}
+	if len(memberships) != len(want) {
+		t.Fatalf("Expected %d memberships, got %+v", len(want), memberships)
+	}
+	for _, m := range memberships {
+		if isAdmin, ok := want[m.GroupID]; !ok || isAdmin != m.IsGroupAdmin {
+			t.Errorf("Unexpected membership %+v", m)
+		}
+	}
+
+	// Same set again, demoting dogs: only the admin flag changes
+	body = fmt.Sprintf(`{"groups": [{"group_id": %d, "is_group_admin": false}, {"group_id": %d}]}`, dogs.ID, birds.ID)
+	w = setGroups(body)
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if len(resp.Added) != 0 || len(resp.Removed) != 0 || fmt.Sprint(resp.AdminChanged) != fmt.Sprint([]uint{dogs.ID}) {
+		t.Errorf("Unexpected diff: %+v", resp)
+	}
+}
+
 // Unit tests for isValidGroupMeBotID
 func TestIsValidGroupMeBotID(t *testing.T) {
 	tests := []struct {
@@ -1524,7 +1644,7 @@ func TestUploadGroupImage(t *testing.T) {
 			c.Set("user_id", uint(1))
 			c.Set("is_admin", true)
 
-			handler := UploadGroupImage(tt.provider)
+			handler := UploadGroupImage(tt.provider, nil)
 			handler(c)
 
 			if w.Code != tt.expectedStatus {
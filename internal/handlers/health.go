@@ -1,14 +1,44 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/database"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
 	"gorm.io/gorm"
 )
 
+// Readiness check statuses. A failing critical check (database, migrations)
+// makes the endpoint return 503 so Kubernetes stops routing traffic to the
+// pod; a failing optional check (email, storage) only marks it "degraded",
+// since those outages are shared by every replica and pulling them all out
+// of rotation would turn a partial outage into a full one.
+const (
+	checkStatusOK            = "ok"
+	checkStatusFail          = "fail"
+	checkStatusNotConfigured = "not_configured"
+)
+
+const (
+	readinessCheckTimeout = 2 * time.Second
+	// migrationCheckTTL bounds how often the schema is re-inspected; the
+	// schema only changes on deploy, while probes run every few seconds.
+	migrationCheckTTL = time.Minute
+	// emailCheckTTL bounds how often the email provider is contacted, so
+	// probes don't spend the provider's API rate limit.
+	emailCheckTTL = time.Minute
+	// storageCheckTTL bounds how often upload storage is probed; Azure's
+	// probe writes a blob, which probes every few seconds shouldn't do.
+	storageCheckTTL = time.Minute
+)
+
 // HealthCheck returns basic health status
 func HealthCheck() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -19,36 +49,167 @@ func HealthCheck() gin.HandlerFunc {
 	}
 }
 
-// ReadinessCheck checks if the application is ready to serve traffic
-// This includes checking database connectivity
-func ReadinessCheck(db *gorm.DB) gin.HandlerFunc {
+// readinessCheck is one dependency's entry in the readiness response. The
+// endpoint is public, so it carries no details: why a check failed (a
+// pending migration's name, a provider's error) is logged instead.
+type readinessCheck struct {
+	Status   string `json:"status"`
+	Critical bool   `json:"critical"`
+}
+
+// migrationStatusCache memoizes database.PendingMigrations for
+// migrationCheckTTL per ReadinessCheck handler.
+type migrationStatusCache struct {
+	mu        sync.Mutex
+	checkedAt time.Time
+	pending   []string
+	err       error
+}
+
+func (m *migrationStatusCache) get(db *gorm.DB) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.checkedAt.IsZero() || time.Since(m.checkedAt) > migrationCheckTTL || m.err != nil {
+		m.pending, m.err = database.PendingMigrations(db)
+		m.checkedAt = time.Now()
+		switch {
+		case m.err != nil:
+			logging.WithField("error", m.err.Error()).Warn("Failed to inspect schema for pending migrations")
+		case len(m.pending) > 0:
+			logging.WithField("pending", m.pending).Warn("Schema has pending migrations")
+		}
+	}
+	return m.pending, m.err
+}
+
+// emailHealthCache memoizes the email provider's health check for
+// emailCheckTTL per ReadinessCheck handler.
+type emailHealthCache struct {
+	mu        sync.Mutex
+	checkedAt time.Time
+	err       error
+}
+
+func (e *emailHealthCache) get(ctx context.Context, emailService *email.Service) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.checkedAt.IsZero() || time.Since(e.checkedAt) > emailCheckTTL {
+		e.err = emailService.CheckHealth(ctx)
+		e.checkedAt = time.Now()
+		if e.err != nil {
+			logging.WithContext(ctx).WithField("provider", emailService.ProviderName()).Warnf("Email provider health check failed: %v", e.err)
+		}
+	}
+	return e.err
+}
+
+// storageHealthCache memoizes the storage provider's writability check for
+// storageCheckTTL per ReadinessCheck handler.
+type storageHealthCache struct {
+	mu        sync.Mutex
+	checkedAt time.Time
+	err       error
+}
+
+func (s *storageHealthCache) get(ctx context.Context, provider storage.Provider, hc storage.HealthChecker) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.checkedAt.IsZero() || time.Since(s.checkedAt) > storageCheckTTL {
+		s.err = hc.CheckWritable(ctx)
+		s.checkedAt = time.Now()
+		if s.err != nil {
+			logging.WithContext(ctx).WithField("provider", provider.Name()).Warnf("Storage writability check failed: %v", s.err)
+		}
+	}
+	return s.err
+}
+
+// ReadinessCheck checks if the application is ready to serve traffic.
+// It reports database connectivity, pending schema migrations, email
+// provider health, and upload storage writability. emailService and
+// storageProvider may be nil, in which case they report "not_configured".
+// The response only says whether each check passed; failures are logged.
+func ReadinessCheck(db *gorm.DB, emailService *email.Service, storageProvider storage.Provider) gin.HandlerFunc {
+	migrations := &migrationStatusCache{}
+	emailHealth := &emailHealthCache{}
+	storageHealth := &storageHealthCache{}
+
 	return func(c *gin.Context) {
-		ctx := c.Request.Context()
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readinessCheckTimeout)
+		defer cancel()
 		db := middleware.GetDB(c, db)
 
-		// Check database connectivity
-		sqlDB, err := db.DB()
-		if err != nil {
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"status": "not ready",
-				"error":  "database connection unavailable",
-			})
-			return
+		checks := map[string]readinessCheck{
+			"database": checkDatabase(ctx, db),
 		}
+		if checks["database"].Status == checkStatusOK {
+			checks["migrations"] = checkMigrations(db, migrations)
+		} else {
+			checks["migrations"] = readinessCheck{Status: checkStatusFail, Critical: true}
+		}
+		checks["email"] = checkEmail(ctx, emailService, emailHealth)
+		checks["storage"] = checkStorage(ctx, storageProvider, storageHealth)
 
-		// Ping database with context timeout
-		if err := sqlDB.PingContext(ctx); err != nil {
-			c.JSON(http.StatusServiceUnavailable, gin.H{
-				"status": "not ready",
-				"error":  "database ping failed",
-			})
-			return
+		status, code := "ready", http.StatusOK
+		for _, check := range checks {
+			if check.Status != checkStatusFail {
+				continue
+			}
+			if check.Critical {
+				status, code = "not ready", http.StatusServiceUnavailable
+				break
+			}
+			status = "degraded"
 		}
 
-		c.JSON(http.StatusOK, gin.H{
-			"status":   "ready",
-			"time":     time.Now().UTC().Format(time.RFC3339),
-			"database": "connected",
+		c.JSON(code, gin.H{
+			"status": status,
+			"checks": checks,
 		})
 	}
 }
+
+func checkDatabase(ctx context.Context, db *gorm.DB) readinessCheck {
+	check := readinessCheck{Status: checkStatusOK, Critical: true}
+	sqlDB, err := db.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	if err != nil {
+		logging.WithContext(ctx).Warnf("Readiness database check failed: %v", err)
+		check.Status = checkStatusFail
+	}
+	return check
+}
+
+func checkMigrations(db *gorm.DB, cache *migrationStatusCache) readinessCheck {
+	check := readinessCheck{Status: checkStatusOK, Critical: true}
+	if pending, err := cache.get(db); err != nil || len(pending) > 0 {
+		check.Status = checkStatusFail
+	}
+	return check
+}
+
+func checkEmail(ctx context.Context, emailService *email.Service, cache *emailHealthCache) readinessCheck {
+	if emailService == nil || !emailService.IsConfigured() {
+		return readinessCheck{Status: checkStatusNotConfigured}
+	}
+	check := readinessCheck{Status: checkStatusOK}
+	if err := cache.get(ctx, emailService); err != nil {
+		check.Status = checkStatusFail
+	}
+	return check
+}
+
+func checkStorage(ctx context.Context, provider storage.Provider, cache *storageHealthCache) readinessCheck {
+	if provider == nil {
+		return readinessCheck{Status: checkStatusNotConfigured}
+	}
+	check := readinessCheck{Status: checkStatusOK}
+	if hc, ok := provider.(storage.HealthChecker); ok {
+		if err := cache.get(ctx, provider, hc); err != nil {
+			check.Status = checkStatusFail
+		}
+	}
+	return check
+}
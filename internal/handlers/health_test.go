@@ -1,12 +1,19 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/database"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -59,6 +66,11 @@ func TestReadinessCheck(t *testing.T) {
 				if err != nil {
 					t.Fatalf("Failed to open database: %v", err)
 				}
+				sqlDB, _ := db.DB()
+				sqlDB.SetMaxOpenConns(1)
+				if err := db.AutoMigrate(database.MigrationModels()...); err != nil {
+					t.Fatalf("Failed to migrate database: %v", err)
+				}
 				return db
 			},
 			expectedStatus: http.StatusOK,
@@ -90,7 +102,7 @@ func TestReadinessCheck(t *testing.T) {
 			c.Request = httptest.NewRequest("GET", "/ready", nil)
 
 			// Execute
-			handler := ReadinessCheck(db)
+			handler := ReadinessCheck(db, nil, nil)
 			handler(c)
 
 			// Assert
@@ -118,9 +130,145 @@ func TestReadinessCheck_DBConnectionError(t *testing.T) {
 	c, _ := gin.CreateTestContext(w)
 	c.Request = httptest.NewRequest("GET", "/ready", nil)
 
-	handler := ReadinessCheck(db)
+	handler := ReadinessCheck(db, nil, nil)
 	handler(c)
 
 	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
 	assert.Contains(t, w.Body.String(), "not ready")
 }
+
+// fakeHealthStorage is a storage.Provider whose CheckWritable result is configurable.
+type fakeHealthStorage struct {
+	mockStorageProvider
+	writeErr error
+	probes   int
+}
+
+func (f *fakeHealthStorage) CheckWritable(_ context.Context) error {
+	f.probes++
+	return f.writeErr
+}
+
+// fakeHealthEmail is an email.Provider whose CheckHealth result is configurable.
+type fakeHealthEmail struct {
+	mockEmailProvider
+	healthErr error
+}
+
+func (f *fakeHealthEmail) CheckHealth(_ context.Context) error { return f.healthErr }
+
+func TestReadinessCheck_Dependencies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	openDB := func(t *testing.T, migrate bool) *gorm.DB {
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+		require.NoError(t, err)
+		sqlDB, _ := db.DB()
+		sqlDB.SetMaxOpenConns(1)
+		t.Cleanup(func() { sqlDB.Close() })
+		if migrate {
+			require.NoError(t, db.AutoMigrate(database.MigrationModels()...))
+		}
+		return db
+	}
+
+	tests := []struct {
+		name           string
+		migrate        bool
+		emailService   *email.Service
+		storage        storage.Provider
+		expectedStatus int
+		expectedState  string
+		checkBody      func(t *testing.T, body string, checks map[string]readinessCheck)
+	}{
+		{
+			name:           "all dependencies healthy",
+			migrate:        true,
+			emailService:   email.NewServiceWithProvider(&mockEmailProvider{}, nil),
+			storage:        &fakeHealthStorage{},
+			expectedStatus: http.StatusOK,
+			expectedState:  "ready",
+			checkBody: func(t *testing.T, body string, checks map[string]readinessCheck) {
+				assert.Equal(t, checkStatusOK, checks["database"].Status)
+				assert.Equal(t, checkStatusOK, checks["migrations"].Status)
+				assert.Equal(t, checkStatusOK, checks["email"].Status)
+				assert.Equal(t, checkStatusOK, checks["storage"].Status)
+				assert.NotContains(t, body, "mock", "provider names are not exposed")
+			},
+		},
+		{
+			name:           "pending migrations are critical",
+			migrate:        false,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedState:  "not ready",
+			checkBody: func(t *testing.T, body string, checks map[string]readinessCheck) {
+				assert.Equal(t, checkStatusFail, checks["migrations"].Status)
+				assert.True(t, checks["migrations"].Critical)
+				assert.NotContains(t, body, "users", "pending migrations are not exposed")
+			},
+		},
+		{
+			name:           "unwritable storage degrades without failing the probe",
+			migrate:        true,
+			storage:        &fakeHealthStorage{writeErr: errors.New("read-only")},
+			expectedStatus: http.StatusOK,
+			expectedState:  "degraded",
+			checkBody: func(t *testing.T, body string, checks map[string]readinessCheck) {
+				assert.Equal(t, checkStatusFail, checks["storage"].Status)
+				assert.Equal(t, checkStatusNotConfigured, checks["email"].Status)
+				assert.NotContains(t, body, "read-only", "storage errors are not exposed")
+			},
+		},
+		{
+			name:           "unhealthy email provider degrades without failing the probe",
+			migrate:        true,
+			emailService:   email.NewServiceWithProvider(&fakeHealthEmail{healthErr: errors.New("invalid API key")}, nil),
+			storage:        &fakeHealthStorage{},
+			expectedStatus: http.StatusOK,
+			expectedState:  "degraded",
+			checkBody: func(t *testing.T, body string, checks map[string]readinessCheck) {
+				assert.Equal(t, checkStatusFail, checks["email"].Status)
+				assert.NotContains(t, body, "API key", "provider errors are not exposed")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openDB(t, tt.migrate)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/ready", nil)
+
+			ReadinessCheck(db, tt.emailService, tt.storage)(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code, w.Body.String())
+			var resp struct {
+				Status string                    `json:"status"`
+				Checks map[string]readinessCheck `json:"checks"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.expectedState, resp.Status)
+			tt.checkBody(t, w.Body.String(), resp.Checks)
+		})
+	}
+}
+
+func TestReadinessCheck_CachesStorageProbe(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	store := &fakeHealthStorage{}
+	handler := ReadinessCheck(db, nil, store)
+	for range 3 {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/ready", nil)
+		handler(c)
+	}
+	assert.Equal(t, 1, store.probes, "probes within storageCheckTTL reuse the result")
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/maintenance"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/moderation"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
+	"gorm.io/gorm"
+)
+
+// ErrCodeImagePendingReview is returned when an action needs an image an admin
+// hasn't approved yet.
+const ErrCodeImagePendingReview ErrorCode = "IMAGE_PENDING_REVIEW"
+
+// quarantinedImageColumns lists the AnimalImage columns returned by the
+// review queue, leaving out the binary data.
+const quarantinedImageColumns = "id, created_at, updated_at, animal_id, user_id, image_url, caption, width, height, file_size, moderation_status, moderation_labels"
+
+// moderateUpload runs an image, as it will be stored, past the moderator and
+// returns the moderation status and labels to store with it. A nil moderator
+// approves everything. A moderator that fails quarantines the image, so an
+// outage never publishes unscreened photos.
+func moderateUpload(c *gin.Context, moderator moderation.Moderator, data []byte, mimeType string) (string, models.StringList) {
+	if moderator == nil {
+		return models.ImageModerationApproved, nil
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+	result, err := moderator.ModerateImage(ctx, data, mimeType)
+	if err != nil {
+		middleware.GetLogger(c).WithFields(map[string]interface{}{
+			"provider": moderator.GetProviderName(),
+			"error":    err.Error(),
+		}).Warn("Image moderation failed, quarantining image for review")
+		return models.ImageModerationPending, models.StringList{"moderation_unavailable"}
+	}
+	if result.Quarantined() {
+		return models.ImageModerationPending, models.StringList(result.Labels)
+	}
+	return models.ImageModerationApproved, nil
+}
+
+// GetQuarantinedImages returns images waiting for admin review, oldest first
+// Route: GET /api/admin/images/quarantined
+func GetQuarantinedImages(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+
+		var images []models.AnimalImage
+		if err := db.Select(quarantinedImageColumns).
+			Preload("User").
+			Preload("Animal").
+			Where("moderation_status = ?", models.ImageModerationPending).
+			Order("created_at ASC").
+			Find(&images).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to fetch quarantined images", err)
+			respondInternalError(c, "Failed to fetch quarantined images")
+			return
+		}
+
+		respondOK(c, images)
+	}
+}
+
+// loadQuarantinedImage loads the :imageId image if it is waiting for review,
+// responding 404 otherwise.
+func loadQuarantinedImage(c *gin.Context, db *gorm.DB) (*models.AnimalImage, bool) {
+	var image models.AnimalImage
+	if err := db.Where("id = ? AND moderation_status = ?", c.Param("imageId"), models.ImageModerationPending).
+		First(&image).Error; err != nil {
+		respondNotFound(c, "Quarantined image not found")
+		return nil, false
+	}
+	return &image, true
+}
+
+// GetQuarantinedImageContent serves a quarantined image to an admin reviewing
+// it. ServeImage hides pending images from everyone else.
+// Route: GET /api/admin/images/quarantined/:imageId/content
+func GetQuarantinedImageContent(db *gorm.DB, storageProvider storage.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		image, ok := loadQuarantinedImage(c, db)
+		if !ok {
+			return
+		}
+
+		data, mimeType := image.ImageData, image.MimeType
+		if image.StorageProvider == storage.ProviderAzure && image.BlobIdentifier != "" {
+			var err error
+			data, mimeType, err = storageProvider.GetImage(c.Request.Context(), image.BlobIdentifier)
+			if err != nil {
+				middleware.GetLogger(c).Error("Failed to retrieve quarantined image", err)
+				respondInternalError(c, "Failed to retrieve image")
+				return
+			}
+		}
+		if len(data) == 0 {
+			respondNotFound(c, "Image data not available")
+			return
+		}
+
+		c.Header("Cache-Control", "private, no-store")
+		c.Data(http.StatusOK, mimeType, data)
+	}
+}
+
+// ApproveQuarantinedImage publishes a quarantined image
+// Route: POST /api/admin/images/quarantined/:imageId/approve
+func ApproveQuarantinedImage(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		image, ok := loadQuarantinedImage(c, db)
+		if !ok {
+			return
+		}
+		adminID, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+
+		now := time.Now()
+		if err := db.Model(image).Updates(map[string]interface{}{
+			"moderation_status": models.ImageModerationApproved,
+			"moderated_by_id":   adminID,
+			"moderated_at":      now,
+		}).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to approve image", err)
+			respondInternalError(c, "Failed to approve image")
+			return
+		}
+
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventImageApproved, adminID, map[string]interface{}{
+			"image_id": image.ID,
+			"labels":   []string(image.ModerationLabels),
+		})
+		respondOK(c, gin.H{"message": "Image approved"})
+	}
+}
+
+// RejectQuarantinedImage deletes a quarantined image. Animals that were given
+// the image as their photo before it was reviewed lose it.
+// Route: POST /api/admin/images/quarantined/:imageId/reject
+func RejectQuarantinedImage(db *gorm.DB, storageProvider storage.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		logger := middleware.GetLogger(c)
+		image, ok := loadQuarantinedImage(c, db)
+		if !ok {
+			return
+		}
+		adminID, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&models.Animal{}).Where("image_url = ?", image.ImageURL).
+				Update("image_url", "").Error; err != nil {
+				return err
+			}
+			return tx.Delete(image).Error
+		}); err != nil {
+			logger.Error("Failed to reject image", err)
+			respondInternalError(c, "Failed to reject image")
+			return
+		}
+
+		if image.StorageProvider == storage.ProviderAzure && image.BlobIdentifier != "" {
+			if err := storageProvider.DeleteImage(c.Request.Context(), image.BlobIdentifier); err != nil {
+				logger.WithFields(map[string]interface{}{
+					"error":           err.Error(),
+					"blob_identifier": image.BlobIdentifier,
+				}).Warn("Failed to delete rejected image from storage provider")
+			}
+		}
+		if err := maintenance.DeleteImageVariants(c.Request.Context(), db, storageProvider, image.ID); err != nil {
+			logger.Error("Failed to delete rejected image's variants", err)
+		}
+
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventImageRejected, adminID, map[string]interface{}{
+			"image_id": image.ID,
+			"user_id":  image.UserID,
+			"labels":   []string(image.ModerationLabels),
+		})
+		respondOK(c, gin.H{"message": "Image rejected"})
+	}
+}
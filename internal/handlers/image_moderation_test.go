@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/moderation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeModerator returns a fixed result or error for every image
+type fakeModerator struct {
+	result moderation.Result
+	err    error
+}
+
+func (m *fakeModerator) ModerateImage(context.Context, []byte, string) (moderation.Result, error) {
+	return m.result, m.err
+}
+
+func (m *fakeModerator) GetProviderName() string { return "fake" }
+
+func TestImageModeration(t *testing.T) {
+	db := SetupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AnimalImage{}, &models.AnimalImageVariant{}))
+	user := CreateTestUser(t, db, "volunteer", "volunteer@example.com", "password123", false)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	group := CreateTestGroup(t, db, "Dogs", "Dog group")
+	AddUserToGroupWithAdmin(t, db, user.ID, group.ID, false)
+	animal := CreateTestAnimal(t, db, group.ID, "Rex", "Dog")
+	provider := &mockStorageProvider{ProviderName: "azure", GetImageData: []byte("image"), GetImageMime: "image/png"}
+	animalParams := gin.Params{
+		{Key: "id", Value: fmt.Sprint(group.ID)},
+		{Key: "animalId", Value: fmt.Sprint(animal.ID)},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 10, 10))))
+	upload := func(moderator moderation.Moderator) models.AnimalImage {
+		c, w := accountTestContext(user.ID, false, http.MethodPost, "/api/groups/1/animals/1/images", nil)
+		c.Request = createImageMultipartRequest(t, "image", "rex.png", buf.Bytes())
+		c.Params = animalParams
+		UploadAnimalImageToGallery(db, provider, nil, moderator)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var img models.AnimalImage
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &img))
+		return img
+	}
+	serve := func(img models.AnimalImage) int {
+		r := gin.New()
+		r.GET("/api/images/:uuid", ServeImage(db, provider))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, img.ImageURL, nil))
+		return w.Code
+	}
+	gallery := func() int {
+		c, w := accountTestContext(user.ID, false, http.MethodGet, "/api/groups/1/animals/1/images", nil)
+		c.Params = animalParams
+		GetAnimalImages(db)(c)
+		var images []models.AnimalImage
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &images))
+		return len(images)
+	}
+	adminCall := func(handler gin.HandlerFunc, img models.AnimalImage) *httptest.ResponseRecorder {
+		c, w := accountTestContext(admin.ID, true, http.MethodPost, "/api/admin/images/quarantined", nil)
+		c.Params = gin.Params{{Key: "imageId", Value: fmt.Sprint(img.ID)}}
+		handler(c)
+		return w
+	}
+
+	approved := upload(nil)
+	assert.Equal(t, models.ImageModerationApproved, approved.ModerationStatus, "no moderator publishes everything")
+	assert.Equal(t, http.StatusOK, serve(approved))
+
+	flagged := upload(&fakeModerator{result: moderation.Result{Verdict: moderation.VerdictQuarantine, Labels: []string{"nudity"}}})
+	assert.Equal(t, models.ImageModerationPending, flagged.ModerationStatus)
+	assert.Equal(t, models.StringList{"nudity"}, flagged.ModerationLabels)
+	assert.Equal(t, http.StatusNotFound, serve(flagged), "quarantined images aren't served")
+	assert.Equal(t, 1, gallery(), "quarantined images are hidden from the gallery")
+
+	c, w := accountTestContext(user.ID, false, http.MethodPut, "/api/groups/1/animals/1/images/1/set-profile", nil)
+	c.Params = append(animalParams, gin.Param{Key: "imageId", Value: fmt.Sprint(flagged.ID)})
+	SetAnimalProfilePictureGroupScoped(db)(c)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// Admins can review the queue and see the image
+	c, w = accountTestContext(admin.ID, true, http.MethodGet, "/api/admin/images/quarantined", nil)
+	GetQuarantinedImages(db)(c)
+	var queue []models.AnimalImage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &queue))
+	require.Len(t, queue, 1)
+	assert.Equal(t, flagged.ID, queue[0].ID)
+	w = adminCall(GetQuarantinedImageContent(db, provider), flagged)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image", w.Body.String())
+
+	w = adminCall(ApproveQuarantinedImage(db), flagged)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, http.StatusOK, serve(flagged))
+	assert.Equal(t, 2, gallery())
+	w = adminCall(ApproveQuarantinedImage(db), flagged)
+	assert.Equal(t, http.StatusNotFound, w.Code, "approved images leave the queue")
+
+	// A moderator outage quarantines rather than publishes
+	unscreened := upload(&fakeModerator{err: errors.New("timeout")})
+	assert.Equal(t, models.ImageModerationPending, unscreened.ModerationStatus)
+	require.NoError(t, db.Model(animal).Update("image_url", unscreened.ImageURL).Error)
+
+	w = adminCall(RejectQuarantinedImage(db, provider), unscreened)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, provider.DeletedBlobs, unscreened.ImageURL[len("/api/images/"):]+".png")
+	var count int64
+	require.NoError(t, db.Model(&models.AnimalImage{}).Where("id = ?", unscreened.ID).Count(&count).Error)
+	assert.Zero(t, count)
+	require.NoError(t, db.First(animal, animal.ID).Error)
+	assert.Empty(t, animal.ImageURL, "animals lose rejected photos")
+}
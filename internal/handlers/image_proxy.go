@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/auth"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/upload"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// imageProxySignaturePurpose scopes image proxy signatures so a proxy URL
+// can't be minted from any other signed value.
+const imageProxySignaturePurpose = "image_proxy"
+
+// defaultImageProxyHosts is the allow-list used when IMAGE_PROXY_HOSTS is
+// unset: the host the seed data and most pasted links point at.
+const defaultImageProxyHosts = "images.unsplash.com"
+
+// defaultImageProxyCacheMB caps the proxy cache when IMAGE_PROXY_CACHE_MB is
+// unset
+const defaultImageProxyCacheMB = 256
+
+// imageProxyTouchInterval limits how often a cache hit rewrites the entry's
+// last_used_at, so busy images don't cost a write per request
+const imageProxyTouchInterval = time.Hour
+
+// imageProxyHosts returns the hosts the proxy fetches from, from
+// IMAGE_PROXY_HOSTS (comma-separated; "none" turns the proxy off).
+func imageProxyHosts() map[string]bool {
+	raw := strings.TrimSpace(os.Getenv("IMAGE_PROXY_HOSTS"))
+	if raw == "" {
+		raw = defaultImageProxyHosts
+	}
+	if strings.EqualFold(raw, "none") {
+		return nil
+	}
+	hosts := map[string]bool{}
+	for _, h := range strings.Split(raw, ",") {
+		if h = strings.ToLower(strings.TrimSpace(h)); h != "" {
+			hosts[h] = true
+		}
+	}
+	return hosts
+}
+
+// imageProxyCacheBytes returns the proxy cache limit, from
+// IMAGE_PROXY_CACHE_MB (default 256).
+func imageProxyCacheBytes() int64 {
+	mb := defaultImageProxyCacheMB
+	if v := os.Getenv("IMAGE_PROXY_CACHE_MB"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed >= 1 {
+			mb = parsed
+		} else {
+			logging.WithField("value", v).Warn("Invalid IMAGE_PROXY_CACHE_MB, using default")
+		}
+	}
+	return int64(mb) * 1024 * 1024
+}
+
+// proxyableImageURL parses an external image URL, or returns nil when the
+// proxy won't fetch it: it must be https on an allow-listed host.
+func proxyableImageURL(raw string, hosts map[string]bool) *url.URL {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.User != nil || !hosts[strings.ToLower(u.Hostname())] {
+		return nil
+	}
+	return u
+}
+
+func imageProxyValue(imageURL, size string) string {
+	return imageURL + "\x00" + size
+}
+
+// ProxiedImageURL returns the signed image proxy URL for an external image
+// at size (a variant name, or "" for full size), or "" when the proxy is off
+// or won't fetch from the image's host. It is installed as
+// models.ExternalImageProxy so external animal and group images come with
+// proxy variant URLs.
+func ProxiedImageURL(imageURL, size string) string {
+	if proxyableImageURL(imageURL, imageProxyHosts()) == nil {
+		return ""
+	}
+	sig, err := auth.Sign(imageProxySignaturePurpose, imageProxyValue(imageURL, size))
+	if err != nil {
+		return ""
+	}
+	q := url.Values{"url": {imageURL}, "sig": {sig}}
+	if size != "" {
+		q.Set("size", size)
+	}
+	return "/api/image-proxy?" + q.Encode()
+}
+
+// ServeProxiedImage serves an external image through this server, so
+// viewers' browsers never contact the remote host and a changed or removed
+// remote image keeps working once cached. The first request for a URL and
+// size fetches the image, resizes and re-encodes it like an upload, and
+// caches it; the cache is trimmed least recently used first to
+// IMAGE_PROXY_CACHE_MB. Only URLs signed by ProxiedImageURL are served.
+// Route: GET /api/image-proxy?url=...&size=...&sig=...
+func ServeProxiedImage(db *gorm.DB, imageConfig *upload.ImageConfigStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		hosts := imageProxyHosts()
+		if len(hosts) == 0 {
+			respondNotFound(c, "Image proxy is disabled")
+			return
+		}
+
+		raw := c.Query("url")
+		size := c.Query("size")
+		if size != "" && !upload.IsImageVariant(size) {
+			respondBadRequest(c, "Unknown image size")
+			return
+		}
+		if !auth.VerifySignature(imageProxySignaturePurpose, imageProxyValue(raw, size), c.Query("sig")) {
+			respondForbidden(c, "Invalid image proxy signature")
+			return
+		}
+		// Checked again on every request so removing a host from the
+		// allow-list stops links already handed out
+		if proxyableImageURL(raw, hosts) == nil {
+			respondForbidden(c, "Image host is not allowed")
+			return
+		}
+
+		sum := sha256.Sum256([]byte(raw))
+		hash := hex.EncodeToString(sum[:])
+		var cached models.ProxiedImage
+		err := db.Where("source_hash = ? AND size = ?", hash, size).First(&cached).Error
+		if err == nil {
+			if time.Since(cached.LastUsedAt) > imageProxyTouchInterval {
+				db.Model(&models.ProxiedImage{}).Where("id = ?", cached.ID).Update("last_used_at", time.Now())
+			}
+			serveProxiedImage(c, &cached)
+			return
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			respondInternalError(c, "Failed to load image")
+			return
+		}
+
+		logger := middleware.GetLogger(c).WithField("image_url", raw)
+		cfg := imageConfig.Get(c.Request.Context())
+		data, err := fetchImportImage(c.Request.Context(), raw, max(cfg.MaxUploadBytes, cfg.MaxHeroUploadBytes))
+		if err != nil {
+			logger.WithField("error", err.Error()).Warn("Image proxy fetch failed")
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to fetch image"})
+			return
+		}
+		maxDimension := max(cfg.MaxDimension, cfg.MaxHeroDimension)
+		for _, v := range upload.ImageVariants {
+			if v.Name == size {
+				maxDimension = v.MaxDimension
+			}
+		}
+		processed, err := upload.ProcessImage(bytes.NewReader(data), maxDimension, cfg)
+		if err != nil {
+			logger.WithField("error", err.Error()).Warn("Image proxy couldn't process image")
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to fetch image"})
+			return
+		}
+
+		entry := models.ProxiedImage{
+			SourceHash: hash,
+			Size:       size,
+			SourceURL:  raw,
+			MimeType:   processed.MimeType,
+			Bytes:      int64(len(processed.Data)),
+			Data:       processed.Data,
+			LastUsedAt: time.Now(),
+		}
+		// A concurrent request for the same image may have stored it first;
+		// either copy is fine
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&entry).Error; err != nil {
+			logger.Error("Failed to cache proxied image", err)
+		} else if err := evictProxiedImages(db, imageProxyCacheBytes(), entry.ID); err != nil {
+			logger.Error("Failed to trim image proxy cache", err)
+		}
+		serveProxiedImage(c, &entry)
+	}
+}
+
+func serveProxiedImage(c *gin.Context, img *models.ProxiedImage) {
+	// The URL is signed over the source, so its content never changes
+	c.Header("Cache-Control", "public, max-age=31536000") // 1 year
+	c.Header("Content-Length", strconv.Itoa(len(img.Data)))
+	c.Data(http.StatusOK, img.MimeType, img.Data)
+}
+
+// evictProxiedImages deletes the least recently used cache entries until
+// the cache fits in limit bytes. keep, the entry just stored, is never
+// evicted, so an image bigger than the whole cache is still served once.
+func evictProxiedImages(db *gorm.DB, limit int64, keep uint) error {
+	var total int64
+	if err := db.Model(&models.ProxiedImage{}).Select("COALESCE(SUM(bytes), 0)").Scan(&total).Error; err != nil {
+		return err
+	}
+	if total <= limit {
+		return nil
+	}
+	var entries []models.ProxiedImage
+	if err := db.Select("id", "bytes").Where("id <> ?", keep).
+		Order("last_used_at ASC, id ASC").Find(&entries).Error; err != nil {
+		return err
+	}
+	var ids []uint
+	for _, e := range entries {
+		if total <= limit {
+			break
+		}
+		ids = append(ids, e.ID)
+		total -= e.Bytes
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return db.Where("id IN ?", ids).Delete(&models.ProxiedImage{}).Error
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/upload"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageProxy(t *testing.T) {
+	os.Setenv("JWT_SECRET", "aB3dE5fG7hI9jK1lM3nO5pQ7rS9tU1vW3xY5zA7bC9dE1fG3hI5jK7lM9nO1pQ3")
+	var photo bytes.Buffer
+	require.NoError(t, png.Encode(&photo, image.NewRGBA(image.Rect(0, 0, 800, 400))))
+	var fetches atomic.Int32
+	remote := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if r.URL.Path == "/missing.png" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write(photo.Bytes())
+	}))
+	defer remote.Close()
+	client := importImageClient
+	importImageClient = remote.Client()
+	defer func() { importImageClient = client }()
+	remoteURL, _ := url.Parse(remote.URL)
+	t.Setenv("IMAGE_PROXY_HOSTS", remoteURL.Hostname())
+
+	db := SetupTestDB(t)
+	serve := func(proxyURL string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, proxyURL, nil)
+		ServeProxiedImage(db, upload.NewImageConfigStore(nil))(c)
+		return w
+	}
+
+	photoURL := remote.URL + "/dog.png"
+	thumbURL := ProxiedImageURL(photoURL, models.ImageVariantThumb)
+	require.NotEmpty(t, thumbURL)
+
+	w := serve(thumbURL)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "public, max-age=31536000", w.Header().Get("Cache-Control"))
+	img, _, err := image.Decode(bytes.NewReader(w.Body.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 200, img.Bounds().Dx(), "resized to the thumb size")
+
+	w = serve(thumbURL)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int32(1), fetches.Load(), "the second request is served from the cache")
+
+	w = serve(ProxiedImageURL(photoURL, ""))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int32(2), fetches.Load(), "each size is cached separately")
+
+	t.Run("signatures are checked", func(t *testing.T) {
+		other := url.Values{"url": {remote.URL + "/other.png"}, "size": {"thumb"}}
+		signed, _ := url.Parse(thumbURL)
+		other.Set("sig", signed.Query().Get("sig"))
+		assert.Equal(t, http.StatusForbidden, serve("/api/image-proxy?"+other.Encode()).Code)
+
+		resized := signed.Query()
+		resized.Set("size", models.ImageVariantCard)
+		assert.Equal(t, http.StatusForbidden, serve("/api/image-proxy?"+resized.Encode()).Code)
+	})
+
+	t.Run("hosts must be allow-listed", func(t *testing.T) {
+		assert.Empty(t, ProxiedImageURL("https://evil.example.com/x.png", ""))
+		assert.Empty(t, ProxiedImageURL("http://"+remoteURL.Host+"/dog.png", ""), "https only")
+
+		t.Setenv("IMAGE_PROXY_HOSTS", "images.unsplash.com")
+		assert.Equal(t, http.StatusForbidden, serve(thumbURL).Code, "cached images stop once their host is removed")
+		t.Setenv("IMAGE_PROXY_HOSTS", "none")
+		assert.Equal(t, http.StatusNotFound, serve(thumbURL).Code)
+	})
+
+	t.Run("failed fetches are not cached", func(t *testing.T) {
+		missing := remote.URL + "/missing.png"
+		assert.Equal(t, http.StatusBadGateway, serve(ProxiedImageURL(missing, "")).Code)
+		var count int64
+		db.Model(&models.ProxiedImage{}).Where("source_url = ?", missing).Count(&count)
+		assert.Zero(t, count)
+	})
+
+	t.Run("variant URLs point at the proxy", func(t *testing.T) {
+		models.ExternalImageProxy = ProxiedImageURL
+		defer func() { models.ExternalImageProxy = nil }()
+		group := models.Group{Name: "Dogs", ImageURL: photoURL, HeroImageURL: "https://evil.example.com/x.png"}
+		require.NoError(t, db.Create(&group).Error)
+		var loaded models.Group
+		require.NoError(t, db.First(&loaded, group.ID).Error)
+		require.NotNil(t, loaded.ImageVariants)
+		assert.Equal(t, thumbURL, loaded.ImageVariants.Thumb)
+		assert.Nil(t, loaded.HeroImageVariants)
+	})
+}
+
+func TestEvictProxiedImages(t *testing.T) {
+	db := SetupTestDB(t)
+	now := time.Now()
+	var ids []uint
+	for i, age := range []time.Duration{3 * time.Hour, time.Hour, 2 * time.Hour, 0} {
+		entry := models.ProxiedImage{SourceHash: itoa(uint(i)), SourceURL: "https://example.com", Bytes: 100, LastUsedAt: now.Add(-age)}
+		require.NoError(t, db.Create(&entry).Error)
+		ids = append(ids, entry.ID)
+	}
+
+	require.NoError(t, evictProxiedImages(db, 250, ids[3]))
+	var left []uint
+	require.NoError(t, db.Model(&models.ProxiedImage{}).Order("id").Pluck("id", &left).Error)
+	assert.Equal(t, []uint{ids[1], ids[3]}, left, "the least recently used go first")
+
+	require.NoError(t, evictProxiedImages(db, 50, ids[3]))
+	require.NoError(t, db.Model(&models.ProxiedImage{}).Pluck("id", &left).Error)
+	assert.Equal(t, []uint{ids[3]}, left, "the image just stored is kept")
+}
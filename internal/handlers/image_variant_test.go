@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/maintenance"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/upload"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageVariants(t *testing.T) {
+	db := SetupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AnimalImage{}, &models.AnimalImageVariant{}, &models.AnimalBQIncident{}))
+	user := CreateTestUser(t, db, "volunteer", "volunteer@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	AddUserToGroupWithAdmin(t, db, user.ID, group.ID, false)
+	animal := CreateTestAnimal(t, db, group.ID, "Rex", "Dog")
+	// Uploads fall back to the database, which keeps the bytes to check
+	provider := &mockStorageProvider{UploadImageErr: errors.New("storage down")}
+	animalParams := gin.Params{{Key: "id", Value: itoa(group.ID)}, {Key: "animalId", Value: itoa(animal.ID)}}
+
+	photo := func(width, height int) []byte {
+		img := image.NewNRGBA(image.Rect(0, 0, width, height))
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				img.Set(x, y, color.NRGBA{R: 120, G: 80, B: 40, A: 255})
+			}
+		}
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, img))
+		return buf.Bytes()
+	}
+	uploadPhoto := func(data []byte) models.AnimalImage {
+		c, w := accountTestContext(user.ID, false, http.MethodPost, "/", nil)
+		c.Request = createImageMultipartRequest(t, "image", "rex.png", data)
+		c.Params = animalParams
+		UploadAnimalImageToGallery(db, provider, nil, nil)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var img models.AnimalImage
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &img))
+		return img
+	}
+	router := gin.New()
+	router.GET("/api/images/:uuid", ServeImage(db, provider))
+	servedWidth := func(url string) (int, int) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		if w.Code != http.StatusOK {
+			return w.Code, 0
+		}
+		cfg, _, err := image.DecodeConfig(w.Body)
+		require.NoError(t, err)
+		return w.Code, cfg.Width
+	}
+
+	big := uploadPhoto(photo(1600, 800))
+	require.NotNil(t, big.Variants)
+	assert.Equal(t, big.ImageURL+"?size=thumb", big.Variants.Thumb)
+	for url, want := range map[string]int{
+		big.Variants.Thumb: 200,
+		big.Variants.Card:  600,
+		big.Variants.Full:  1200,
+	} {
+		code, width := servedWidth(url)
+		require.Equal(t, http.StatusOK, code, url)
+		assert.Equal(t, want, width, url)
+	}
+	code, _ := servedWidth(big.ImageURL + "?size=poster")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	small := uploadPhoto(photo(150, 100))
+	var count int64
+	db.Model(&models.AnimalImageVariant{}).Where("image_id = ?", small.ID).Count(&count)
+	assert.Zero(t, count, "images no bigger than a size get no copy for it")
+	_, width := servedWidth(small.Variants.Thumb)
+	assert.Equal(t, 150, width, "and are served at full size")
+
+	// Animal responses carry the profile image's sizes
+	require.NoError(t, db.Model(animal).Update("image_url", big.ImageURL).Error)
+	c, w := setupAnimalTestContext(user.ID, false)
+	c.Params = animalParams
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	GetAnimal(db)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var fetched models.Animal
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &fetched))
+	require.NotNil(t, fetched.ImageVariants)
+	assert.Equal(t, big.ImageURL+"?size=card", fetched.ImageVariants.Card)
+
+	// Images uploaded before variants existed are backfilled
+	require.NoError(t, db.Where("image_id = ?", big.ID).Delete(&models.AnimalImageVariant{}).Error)
+	require.NoError(t, db.Model(&models.AnimalImage{}).Where("id = ?", big.ID).Updates(map[string]interface{}{"width": 0, "height": 0}).Error)
+	cfg := upload.NewImageConfigStore(nil).Get(context.Background())
+	result, err := maintenance.BackfillImageVariants(context.Background(), db, provider, cfg, 1, 0, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.ImagesChecked)
+	result, err = maintenance.BackfillImageVariants(context.Background(), db, provider, cfg, 1, 0, false)
+	require.NoError(t, err)
+	assert.Equal(t, maintenance.VariantBackfillResult{ImagesChecked: 1, VariantsCreated: 2}, result)
+	_, width = servedWidth(big.Variants.Card)
+	assert.Equal(t, 600, width)
+	var backfilled models.AnimalImage
+	require.NoError(t, db.First(&backfilled, big.ID).Error)
+	assert.Equal(t, 1200, backfilled.Width, "the image's size is recorded")
+	result, err = maintenance.BackfillImageVariants(context.Background(), db, provider, cfg, 1, 0, false)
+	require.NoError(t, err)
+	assert.Zero(t, result.ImagesChecked, "a rerun has nothing to do")
+
+	c, _ = accountTestContext(user.ID, false, http.MethodDelete, "/", nil)
+	c.Params = append(animalParams, gin.Param{Key: "imageId", Value: itoa(big.ID)})
+	DeleteAnimalImage(db, provider)(c)
+	db.Model(&models.AnimalImageVariant{}).Where("image_id = ?", big.ID).Count(&count)
+	assert.Zero(t, count, "deleting an image deletes its copies")
+}
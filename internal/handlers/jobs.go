@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultJobListLimit = 50
+	maxJobListLimit     = 200
+)
+
+// RegisterJobHandlers registers the handlers for every background job type
+// enqueued by this package.
+func RegisterJobHandlers(queue *jobs.Queue, db *gorm.DB, emailService *email.Service, storageProvider storage.Provider) {
+	queue.Register(JobAnnouncementEmail, announcementEmailJobHandler(db, emailService))
+	queue.Register(JobCommentReactionEmail, commentReactionEmailJobHandler(db, emailService))
+	queue.Register(JobDataExport, dataExportJobHandler(db, storageProvider))
+	queue.Register(JobAnimalChangeEmail, animalChangeEmailJobHandler(db, emailService))
+	queue.Register(JobJoinRequestEmail, joinRequestEmailJobHandler(db, emailService))
+	queue.Register(JobWeeklyStatsEmail, weeklyStatsEmailJobHandler(db, emailService))
+	queue.Register(JobStatusAlertEmail, statusAlertEmailJobHandler(db, emailService))
+	queue.Register(JobDiscussionReplyEmail, discussionReplyEmailJobHandler(db, emailService))
+	queue.Register(JobOnboardingWelcomeEmail, onboardingWelcomeEmailJobHandler(db, emailService))
+	queue.Register(JobEmailVerificationEmail, emailVerificationEmailJobHandler(db, emailService))
+}
+
+// ListJobs returns background jobs, newest first, with a count per status
+// (admin only). Query params: status, type, limit (default 50, max 200).
+// Route: GET /api/admin/jobs
+func ListJobs(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+
+		query := db.Model(&models.Job{})
+		if status := c.Query("status"); status != "" {
+			switch status {
+			case models.JobStatusPending, models.JobStatusRunning, models.JobStatusSucceeded, models.JobStatusFailed:
+				query = query.Where("status = ?", status)
+			default:
+				respondBadRequest(c, "status must be pending, running, succeeded, or failed")
+				return
+			}
+		}
+		if jobType := c.Query("type"); jobType != "" {
+			query = query.Where("type = ?", jobType)
+		}
+		limit := defaultJobListLimit
+		if v := c.Query("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				respondBadRequest(c, "limit must be a positive number")
+				return
+			}
+			limit = min(n, maxJobListLimit)
+		}
+
+		jobList := []models.Job{}
+		if err := query.Order("id DESC").Limit(limit).Find(&jobList).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to list jobs", err)
+			respondInternalError(c, "Failed to list jobs")
+			return
+		}
+
+		var statusCounts []struct {
+			Status string
+			Count  int64
+		}
+		if err := db.Model(&models.Job{}).Select("status, COUNT(*) AS count").Group("status").Scan(&statusCounts).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to count jobs", err)
+			respondInternalError(c, "Failed to list jobs")
+			return
+		}
+		counts := map[string]int64{
+			models.JobStatusPending:   0,
+			models.JobStatusRunning:   0,
+			models.JobStatusSucceeded: 0,
+			models.JobStatusFailed:    0,
+		}
+		for _, sc := range statusCounts {
+			counts[sc.Status] = sc.Count
+		}
+
+		respondOK(c, gin.H{"jobs": jobList, "counts": counts})
+	}
+}
+
+// RequeueJob resets a failed job so the workers run it again (admin only).
+// Route: POST /api/admin/jobs/:id/requeue
+func RequeueJob(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid job ID")
+			return
+		}
+
+		job, err := jobs.Requeue(db, uint(id))
+		switch {
+		case errors.Is(err, jobs.ErrJobNotFound):
+			respondNotFound(c, "Job not found")
+		case errors.Is(err, jobs.ErrJobNotFailed):
+			respondError(c, http.StatusConflict, ErrCodeConflict, "Only failed jobs can be requeued")
+		case err != nil:
+			middleware.GetLogger(c).Error("Failed to requeue job", err)
+			respondInternalError(c, "Failed to requeue job")
+		default:
+			respondOK(c, job)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAndRequeueJobs(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+
+	queue := jobs.NewQueue(db)
+	queue.Register("flaky", func(context.Context, json.RawMessage) error {
+		return jobs.Permanent(errors.New("smtp rejected the message"))
+	})
+	queue.Register("ok", func(context.Context, json.RawMessage) error { return nil })
+	failed, err := jobs.Enqueue(db, "flaky", map[string]int{"user_id": 1})
+	require.NoError(t, err)
+	_, err = jobs.Enqueue(db, "ok", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, queue.RunDue(context.Background()))
+
+	c, w := accountTestContext(admin.ID, true, http.MethodGet, "/api/admin/jobs?status=failed", nil)
+	ListJobs(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list struct {
+		Jobs   []models.Job     `json:"jobs"`
+		Counts map[string]int64 `json:"counts"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Jobs, 1)
+	assert.Equal(t, "smtp rejected the message", list.Jobs[0].LastError)
+	assert.Equal(t, int64(1), list.Counts[models.JobStatusFailed])
+	assert.Equal(t, int64(1), list.Counts[models.JobStatusSucceeded])
+
+	c, w = accountTestContext(admin.ID, true, http.MethodGet, "/api/admin/jobs?status=stuck", nil)
+	ListJobs(db)(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	requeue := func(id uint) int {
+		c, w := accountTestContext(admin.ID, true, http.MethodPost, "/", nil)
+		c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(id)}}
+		RequeueJob(db)(c)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusOK, requeue(failed.ID))
+	var job models.Job
+	require.NoError(t, db.First(&job, failed.ID).Error)
+	assert.Equal(t, models.JobStatusPending, job.Status)
+	assert.Zero(t, job.Attempts)
+
+	assert.Equal(t, http.StatusConflict, requeue(failed.ID), "only failed jobs can be requeued")
+	assert.Equal(t, http.StatusNotFound, requeue(9999))
+}
+
+func TestAnnouncementEmailJobHandler(t *testing.T) {
+	db := SetupTestDB(t)
+	member := CreateTestUser(t, db, "member", "member@example.com", "password123", false)
+	optedOut := CreateTestUser(t, db, "quiet", "quiet@example.com", "password123", false)
+	db.Model(member).Update("email_notifications_enabled", true)
+
+	provider := &recordingEmailProvider{}
+	handler := announcementEmailJobHandler(db, email.NewServiceWithProvider(provider, db))
+
+	send := func(userID uint) error {
+		payload, _ := json.Marshal(announcementEmailJob{UserID: userID, Title: "News", Content: "Adoption event on Saturday"})
+		return handler(context.Background(), payload)
+	}
+	require.NoError(t, send(member.ID))
+	require.NoError(t, send(optedOut.ID), "users who opted out after the job was queued are skipped")
+	assert.Equal(t, []string{"member@example.com"}, provider.sentTo)
+
+	err := handler(context.Background(), json.RawMessage(`{"user_id": "x"}`))
+	assert.Error(t, err, "malformed payloads fail permanently")
+}
@@ -0,0 +1,487 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"  // Register GIF format
+	_ "image/jpeg" // Register JPEG format
+	_ "image/png"  // Register PNG format
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/pdf"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/qrcode"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
+	"github.com/nfnt/resize"
+	"gorm.io/gorm"
+)
+
+// kennelCardPhotoMaxPixels bounds the longest side of the embedded photo;
+// more than this adds file size without printing any sharper.
+const kennelCardPhotoMaxPixels = 800
+
+var kennelCardPageSizes = map[string]pdf.Size{
+	models.KennelCardPageLetter:     pdf.Letter,
+	models.KennelCardPageHalfLetter: pdf.HalfLetter,
+	models.KennelCardPageA4:         pdf.A4,
+}
+
+// KennelCardTemplateRequest replaces a group's kennel card template
+type KennelCardTemplateRequest struct {
+	PageSize        string `json:"page_size" binding:"required,oneof=letter half_letter a4"`
+	Heading         string `json:"heading" binding:"max=80"`
+	FooterText      string `json:"footer_text" binding:"max=300"`
+	AccentColor     string `json:"accent_color" binding:"required,hexcolor"`
+	ShowPhoto       bool   `json:"show_photo"`
+	ShowBreed       bool   `json:"show_breed"`
+	ShowAge         bool   `json:"show_age"`
+	ShowTags        bool   `json:"show_tags"`
+	ShowDescription bool   `json:"show_description"`
+	ShowQRCode      bool   `json:"show_qr_code"`
+}
+
+// kennelCard is everything that goes on one printed card.
+type kennelCard struct {
+	Template  models.KennelCardTemplate
+	GroupName string
+	Animal    models.Animal // with Tags loaded
+	Photo     image.Image   // nil when there is none or it can't be shown
+	QRCode    *qrcode.Code  // nil when there is no share link to print
+}
+
+// loadKennelCardTemplate returns the group's saved template, or the default.
+func loadKennelCardTemplate(db *gorm.DB, groupID uint) (models.KennelCardTemplate, error) {
+	var tpl models.KennelCardTemplate
+	err := db.Where("group_id = ?", groupID).First(&tpl).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultKennelCardTemplate(groupID), nil
+	}
+	return tpl, err
+}
+
+// loadKennelCardPhoto fetches and decodes an animal's profile picture from
+// whichever storage backend holds it, scaled down for printing.
+func loadKennelCardPhoto(ctx context.Context, db *gorm.DB, storageProvider storage.Provider, imageURL string) (image.Image, error) {
+	var animalImage models.AnimalImage
+	if err := db.Where("image_url = ?", imageURL).First(&animalImage).Error; err != nil {
+		return nil, err
+	}
+
+	data := animalImage.ImageData
+	if animalImage.StorageProvider == "azure" && animalImage.BlobIdentifier != "" {
+		var err error
+		if data, _, err = storageProvider.GetImage(ctx, animalImage.BlobIdentifier); err != nil {
+			return nil, err
+		}
+	}
+	if len(data) == 0 {
+		return nil, errors.New("image data not available")
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return resize.Thumbnail(kennelCardPhotoMaxPixels, kennelCardPhotoMaxPixels, img, resize.Lanczos3), nil
+}
+
+// kennelCardQRCode encodes the URL of the animal's newest active share link,
+// or returns nil if the group doesn't share publicly or the animal has no
+// link. Printing a card never creates a link.
+func kennelCardQRCode(db *gorm.DB, group models.Group, animalID uint) (*qrcode.Code, error) {
+	if !group.PublicSharing {
+		return nil, nil
+	}
+	var link models.AnimalShareLink
+	err := db.Where("animal_id = ? AND revoked_at IS NULL", animalID).Order("id DESC").First(&link).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	token, err := shareLinkToken(link.ID)
+	if err != nil {
+		return nil, err
+	}
+	return qrcode.Encode(shareLinkURL(token))
+}
+
+// parseHexColor parses #rgb or #rrggbb, falling back to fallback.
+func parseHexColor(s string, fallback color.RGBA) color.RGBA {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if len(s) != 6 || err != nil {
+		return fallback
+	}
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff}
+}
+
+func pluralize(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// formatKennelCardAge describes an animal's age for the card, or "" if it
+// isn't known.
+func formatKennelCardAge(animal *models.Animal) string {
+	years, months := animal.AgeDisplay()
+	switch {
+	case animal.EstimatedBirthDate == nil && years == 0:
+		return ""
+	case years > 0 && months > 0:
+		return pluralize(years, "year") + ", " + pluralize(months, "month")
+	case years > 0:
+		return pluralize(years, "year")
+	case months > 0:
+		return pluralize(months, "month")
+	default:
+		return "Under 1 month"
+	}
+}
+
+// renderKennelCard lays the card out top to bottom: banner, photo, name,
+// details, tags, and description, with the footer and QR code pinned to the
+// bottom. Sizes are given for a letter page and scaled to the template's.
+func renderKennelCard(card kennelCard) ([]byte, error) {
+	tpl := card.Template
+	size, ok := kennelCardPageSizes[tpl.PageSize]
+	if !ok {
+		size = pdf.Letter
+	}
+	s := size.Width / pdf.Letter.Width
+	margin := 36 * s
+	contentWidth := size.Width - 2*margin
+	dark := color.RGBA{0x1f, 0x29, 0x37, 0xff}
+	muted := color.RGBA{0x4b, 0x55, 0x63, 0xff}
+	accent := parseHexColor(tpl.AccentColor, color.RGBA{0x1f, 0x6f, 0x8b, 0xff})
+
+	doc := pdf.New(size)
+	doc.AddPage()
+
+	// Banner
+	heading := tpl.Heading
+	if heading == "" {
+		heading = card.GroupName
+	}
+	bannerHeight := 56 * s
+	doc.SetFillColor(accent)
+	doc.Rect(0, 0, size.Width, bannerHeight)
+	doc.SetFillColor(color.White)
+	headingSize := 20 * s
+	doc.Text(pdf.HelveticaBold, headingSize, margin, (bannerHeight+0.7*headingSize)/2,
+		pdf.Truncate(pdf.HelveticaBold, headingSize, heading, contentWidth))
+
+	// Bottom block: footer text on the left, QR code on the right
+	qrSize := 0.0
+	captionSize := 8 * s
+	if card.QRCode != nil {
+		qrSize = 108 * s
+	}
+	footerSize, footerLeading := 10*s, 13*s
+	footerWidth := contentWidth
+	if qrSize > 0 {
+		footerWidth -= qrSize + 16*s
+	}
+	var footerLines []string
+	if strings.TrimSpace(tpl.FooterText) != "" {
+		footerLines = pdf.WrapText(pdf.Helvetica, footerSize, strings.TrimSpace(tpl.FooterText), footerWidth)
+	}
+	bottomHeight := float64(len(footerLines)) * footerLeading
+	if card.QRCode != nil {
+		bottomHeight = math.Max(bottomHeight, qrSize+captionSize+6*s)
+	}
+	bottomTop := size.Height - margin - bottomHeight
+
+	y := bannerHeight + 24*s
+
+	// Photo, fitted inside the content width and a share of the page height
+	if tpl.ShowPhoto && card.Photo != nil {
+		b := card.Photo.Bounds()
+		maxHeight := size.Height * 0.38
+		scale := math.Min(contentWidth/float64(b.Dx()), maxHeight/float64(b.Dy()))
+		w, h := float64(b.Dx())*scale, float64(b.Dy())*scale
+		if err := doc.Image(card.Photo, margin+(contentWidth-w)/2, y, w, h); err != nil {
+			return nil, err
+		}
+		y += h + 20*s
+	}
+
+	// Name
+	nameSize := 32 * s
+	doc.SetFillColor(dark)
+	doc.Text(pdf.HelveticaBold, nameSize, margin, y+nameSize*0.75,
+		pdf.Truncate(pdf.HelveticaBold, nameSize, card.Animal.Name, contentWidth))
+	y += nameSize + 4*s
+
+	// Species, breed, and age
+	var details []string
+	if card.Animal.Species != "" {
+		details = append(details, card.Animal.Species)
+	}
+	if tpl.ShowBreed && card.Animal.Breed != "" {
+		details = append(details, card.Animal.Breed)
+	}
+	if tpl.ShowAge {
+		if age := formatKennelCardAge(&card.Animal); age != "" {
+			details = append(details, age)
+		}
+	}
+	if len(details) > 0 {
+		detailSize := 14 * s
+		doc.SetFillColor(muted)
+		doc.Text(pdf.Helvetica, detailSize, margin, y+detailSize*0.75,
+			pdf.Truncate(pdf.Helvetica, detailSize, strings.Join(details, " · "), contentWidth))
+		y += detailSize + 12*s
+	}
+
+	// Tags as colored pills, wrapping onto as many rows as needed
+	if tpl.ShowTags && len(card.Animal.Tags) > 0 {
+		tagSize, pillHeight, pad, gap := 10*s, 18*s, 7*s, 6*s
+		x := margin
+		for _, tag := range card.Animal.Tags {
+			label := pdf.Truncate(pdf.HelveticaBold, tagSize, tag.Name, contentWidth-2*pad)
+			w := pdf.TextWidth(pdf.HelveticaBold, tagSize, label) + 2*pad
+			if x > margin && x+w > margin+contentWidth {
+				x = margin
+				y += pillHeight + gap
+			}
+			doc.SetFillColor(parseHexColor(tag.Color, muted))
+			doc.Rect(x, y, w, pillHeight)
+			doc.SetFillColor(color.White)
+			doc.Text(pdf.HelveticaBold, tagSize, x+pad, y+(pillHeight+0.7*tagSize)/2, label)
+			x += w + gap
+		}
+		y += pillHeight + 16*s
+	}
+
+	// Description, cut off with an ellipsis where the bottom block starts
+	if tpl.ShowDescription && strings.TrimSpace(card.Animal.Description) != "" {
+		bodySize, leading := 12*s, 16*s
+		lines := pdf.WrapText(pdf.Helvetica, bodySize, strings.TrimSpace(card.Animal.Description), contentWidth)
+		fit := int((bottomTop - 12*s - y) / leading)
+		if fit < 0 {
+			fit = 0
+		}
+		if len(lines) > fit {
+			lines = lines[:fit]
+			if fit > 0 {
+				lines[fit-1] = pdf.Truncate(pdf.Helvetica, bodySize, lines[fit-1]+"…", contentWidth)
+			}
+		}
+		doc.SetFillColor(dark)
+		for _, line := range lines {
+			y += leading
+			doc.Text(pdf.Helvetica, bodySize, margin, y, line)
+		}
+	}
+
+	if len(footerLines) > 0 {
+		doc.SetFillColor(muted)
+		baseline := size.Height - margin - float64(len(footerLines)-1)*footerLeading
+		for i, line := range footerLines {
+			doc.Text(pdf.Helvetica, footerSize, margin, baseline+float64(i)*footerLeading, line)
+		}
+	}
+
+	if card.QRCode != nil {
+		qrX := size.Width - margin - qrSize
+		qrY := size.Height - margin - captionSize - 6*s - qrSize
+		drawKennelCardQRCode(doc, card.QRCode, qrX, qrY, qrSize)
+		caption := "Scan to meet " + card.Animal.Name
+		caption = pdf.Truncate(pdf.Helvetica, captionSize, caption, qrSize+16*s)
+		captionWidth := pdf.TextWidth(pdf.Helvetica, captionSize, caption)
+		doc.SetFillColor(muted)
+		doc.Text(pdf.Helvetica, captionSize, qrX+(qrSize-captionWidth)/2, size.Height-margin, caption)
+	}
+
+	return doc.Bytes(), nil
+}
+
+// drawKennelCardQRCode draws a QR code as vector rectangles, one per run of
+// dark modules in a row, so it stays sharp at any print size. The margin
+// around the card stands in for the quiet zone.
+func drawKennelCardQRCode(doc *pdf.Document, code *qrcode.Code, x, y, size float64) {
+	module := size / float64(code.Size)
+	doc.SetFillColor(color.Black)
+	for row := 0; row < code.Size; row++ {
+		for col := 0; col < code.Size; {
+			if !code.Dark(col, row) {
+				col++
+				continue
+			}
+			start := col
+			for col < code.Size && code.Dark(col, row) {
+				col++
+			}
+			doc.Rect(x+float64(start)*module, y+float64(row)*module, float64(col-start)*module, module)
+		}
+	}
+}
+
+// GetKennelCardTemplate returns a group's kennel card template, or the
+// default if its admins haven't saved one (any group member).
+// Route: GET /api/groups/:id/kennel-card-template
+func GetKennelCardTemplate(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, c.Param("id")) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+		groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+
+		tpl, err := loadKennelCardTemplate(db, uint(groupID))
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to load kennel card template", err)
+			respondInternalError(c, "Failed to load kennel card template")
+			return
+		}
+		respondOK(c, tpl)
+	}
+}
+
+// UpdateKennelCardTemplate replaces a group's kennel card template (group
+// admin or site admin).
+// Route: PUT /api/groups/:id/kennel-card-template
+func UpdateKennelCardTemplate(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+		if !IsGroupAdminOrSiteAdmin(c, db, uint(groupID)) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Group admin access required")
+			return
+		}
+
+		var req KennelCardTemplateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+
+		var group models.Group
+		if err := db.First(&group, groupID).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, "Group not found")
+			return
+		}
+
+		tpl, err := loadKennelCardTemplate(db, group.ID)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to load kennel card template", err)
+			respondInternalError(c, "Failed to save kennel card template")
+			return
+		}
+		tpl.PageSize = req.PageSize
+		tpl.Heading = strings.TrimSpace(req.Heading)
+		tpl.FooterText = strings.TrimSpace(req.FooterText)
+		tpl.AccentColor = strings.ToLower(req.AccentColor)
+		tpl.ShowPhoto = req.ShowPhoto
+		tpl.ShowBreed = req.ShowBreed
+		tpl.ShowAge = req.ShowAge
+		tpl.ShowTags = req.ShowTags
+		tpl.ShowDescription = req.ShowDescription
+		tpl.ShowQRCode = req.ShowQRCode
+
+		if err := db.Save(&tpl).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to save kennel card template", err)
+			respondInternalError(c, "Failed to save kennel card template")
+			return
+		}
+		respondOK(c, tpl)
+	}
+}
+
+// GetAnimalKennelCard renders a printable kennel card PDF for an animal using
+// the group's template (any group member).
+// Route: GET /api/groups/:id/animals/:animalId/kennel-card.pdf
+func GetAnimalKennelCard(db *gorm.DB, storageProvider storage.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		logger := middleware.GetLogger(c)
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, c.Param("id")) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+
+		animal, ok := findGroupAnimal(c, db)
+		if !ok {
+			return
+		}
+		if err := db.Model(animal).Order("name").Association("Tags").Find(&animal.Tags); err != nil {
+			logger.Error("Failed to load animal tags", err)
+			respondInternalError(c, "Failed to generate kennel card")
+			return
+		}
+
+		var group models.Group
+		if err := db.First(&group, animal.GroupID).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, "Group not found")
+			return
+		}
+		tpl, err := loadKennelCardTemplate(db, group.ID)
+		if err != nil {
+			logger.Error("Failed to load kennel card template", err)
+			respondInternalError(c, "Failed to generate kennel card")
+			return
+		}
+
+		card := kennelCard{Template: tpl, GroupName: group.Name, Animal: *animal}
+
+		// A missing or unreadable photo shouldn't stop the card from printing
+		if tpl.ShowPhoto && animal.ImageURL != "" {
+			photo, err := loadKennelCardPhoto(c.Request.Context(), db, storageProvider, animal.ImageURL)
+			if err != nil {
+				logger.WithField("animal_id", animal.ID).Warnf("Kennel card printed without photo: %v", err)
+			} else {
+				card.Photo = photo
+			}
+		}
+		if tpl.ShowQRCode {
+			code, err := kennelCardQRCode(db, group, animal.ID)
+			if err != nil {
+				logger.WithField("animal_id", animal.ID).Warnf("Kennel card printed without QR code: %v", err)
+			} else {
+				card.QRCode = code
+			}
+		}
+
+		doc, err := renderKennelCard(card)
+		if err != nil {
+			logger.Error("Failed to render kennel card", err)
+			respondInternalError(c, "Failed to generate kennel card")
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="kennel-card-%d.pdf"`, animal.ID))
+		c.Header("Cache-Control", "no-store")
+		c.Data(http.StatusOK, "application/pdf", doc)
+	}
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func kennelCardTestContext(userID, groupID, animalID uint, method string, body interface{}) (*gin.Context, *httptest.ResponseRecorder) {
+	c, w := setupAnimalTestContext(userID, false)
+	c.Params = gin.Params{{Key: "id", Value: fmt.Sprintf("%d", groupID)}}
+	if animalID != 0 {
+		c.Params = append(c.Params, gin.Param{Key: "animalId", Value: fmt.Sprintf("%d", animalID)})
+	}
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	c.Request = httptest.NewRequest(method, "/", &buf)
+	c.Request.Header.Set("Content-Type", "application/json")
+	return c, w
+}
+
+func TestKennelCardTemplate(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	admin, group := createAnimalTestUser(t, db, "groupadmin", "groupadmin@example.com", false)
+	member := CreateTestUser(t, db, "walker", "walker@example.com", "password123", false)
+	AddUserToGroupWithAdmin(t, db, member.ID, group.ID, false)
+
+	c, w := kennelCardTestContext(member.ID, group.ID, 0, http.MethodGet, nil)
+	GetKennelCardTemplate(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var tpl models.KennelCardTemplate
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tpl))
+	assert.Equal(t, models.DefaultKennelCardTemplate(group.ID), tpl, "groups start with the default template")
+
+	req := KennelCardTemplateRequest{
+		PageSize:    models.KennelCardPageHalfLetter,
+		Heading:     " Adopt Me! ",
+		FooterText:  "Ask at the front desk",
+		AccentColor: "#AA3300",
+		ShowPhoto:   true,
+	}
+
+	c, w = kennelCardTestContext(member.ID, group.ID, 0, http.MethodPut, req)
+	UpdateKennelCardTemplate(db)(c)
+	assert.Equal(t, http.StatusForbidden, w.Code, "non-admin members cannot change the template")
+
+	bad := req
+	bad.AccentColor = "orange"
+	c, w = kennelCardTestContext(admin.ID, group.ID, 0, http.MethodPut, bad)
+	UpdateKennelCardTemplate(db)(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	bad = req
+	bad.PageSize = "poster"
+	c, w = kennelCardTestContext(admin.ID, group.ID, 0, http.MethodPut, bad)
+	UpdateKennelCardTemplate(db)(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Saving twice updates the same row
+	for i := 0; i < 2; i++ {
+		c, w = kennelCardTestContext(admin.ID, group.ID, 0, http.MethodPut, req)
+		UpdateKennelCardTemplate(db)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+	var count int64
+	db.Model(&models.KennelCardTemplate{}).Where("group_id = ?", group.ID).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	stored, err := loadKennelCardTemplate(db, group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Adopt Me!", stored.Heading)
+	assert.Equal(t, "#aa3300", stored.AccentColor)
+	assert.True(t, stored.ShowPhoto)
+	assert.False(t, stored.ShowTags, "unchecked options must persist as false")
+	assert.False(t, stored.ShowQRCode)
+}
+
+func TestGetAnimalKennelCard(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	admin, group := createAnimalTestUser(t, db, "groupadmin", "groupadmin@example.com", false)
+	member := CreateTestUser(t, db, "walker", "walker@example.com", "password123", false)
+	AddUserToGroupWithAdmin(t, db, member.ID, group.ID, false)
+	outsider, _ := createAnimalTestUser(t, db, "outsider", "outsider@example.com", false)
+	animal := createTestAnimal(t, db, group.ID, "Rex", "Dog")
+
+	// A profile photo held in blob storage
+	photo := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	for x := 0; x < 40; x++ {
+		photo.Set(x, 10, color.RGBA{200, 100, 0, 255})
+	}
+	var photoPNG bytes.Buffer
+	require.NoError(t, png.Encode(&photoPNG, photo))
+	animalID := animal.ID
+	require.NoError(t, db.Create(&models.AnimalImage{
+		AnimalID: &animalID, UserID: admin.ID, ImageURL: "/api/images/rex", StorageProvider: "azure", BlobIdentifier: "rex",
+	}).Error)
+	storageProvider := &mockStorageProvider{GetImageData: photoPNG.Bytes(), GetImageMime: "image/png"}
+
+	tag := models.AnimalTag{GroupID: group.ID, Name: "Good with cats", Category: "behavior", Color: "#10b981"}
+	require.NoError(t, db.Create(&tag).Error)
+	require.NoError(t, db.Model(animal).Association("Tags").Append(&tag))
+	birth := time.Now().AddDate(-3, -2, -1)
+	require.NoError(t, db.Model(animal).Updates(map[string]interface{}{
+		"image_url": "/api/images/rex", "description": "Loves (tennis) balls", "estimated_birth_date": birth,
+	}).Error)
+
+	require.NoError(t, db.Model(group).Update("public_sharing", true).Error)
+	require.NoError(t, db.Create(&models.AnimalShareLink{AnimalID: animal.ID, GroupID: group.ID, CreatedByID: admin.ID}).Error)
+
+	c, w := kennelCardTestContext(member.ID, group.ID, animal.ID, http.MethodGet, nil)
+	GetAnimalKennelCard(db, storageProvider)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), fmt.Sprintf("kennel-card-%d.pdf", animal.ID))
+	doc := w.Body.String()
+	require.True(t, strings.HasPrefix(doc, "%PDF-"))
+	for _, want := range []string{
+		"(Rex) Tj",
+		"(Dog \xb7 Test Breed \xb7 3 years, 2 months) Tj",
+		"(Good with cats) Tj",
+		"(Loves \\(tennis\\) balls) Tj",
+		"(Scan to meet Rex) Tj",
+		"/Im1 Do",
+		"/Width 40 /Height 30",
+		"/MediaBox [0 0 612.00 792.00]",
+	} {
+		assert.Contains(t, doc, want)
+	}
+
+	t.Run("template options are honoured", func(t *testing.T) {
+		tpl := models.DefaultKennelCardTemplate(group.ID)
+		tpl.PageSize = models.KennelCardPageA4
+		tpl.ShowPhoto = false
+		tpl.ShowBreed = false
+		tpl.ShowQRCode = false
+		tpl.FooterText = "Adoption fee $150"
+		require.NoError(t, db.Create(&tpl).Error)
+		defer db.Delete(&tpl)
+
+		c, w := kennelCardTestContext(member.ID, group.ID, animal.ID, http.MethodGet, nil)
+		GetAnimalKennelCard(db, storageProvider)(c)
+		require.Equal(t, http.StatusOK, w.Code)
+		doc := w.Body.String()
+		assert.Contains(t, doc, "/MediaBox [0 0 595.28 841.89]")
+		assert.Contains(t, doc, "(Adoption fee $150) Tj")
+		assert.NotContains(t, doc, "Test Breed")
+		assert.NotContains(t, doc, "/Im1")
+		assert.NotContains(t, doc, "Scan to meet")
+	})
+
+	t.Run("no QR code without public sharing", func(t *testing.T) {
+		require.NoError(t, db.Model(group).Update("public_sharing", false).Error)
+		defer db.Model(group).Update("public_sharing", true)
+
+		c, w := kennelCardTestContext(member.ID, group.ID, animal.ID, http.MethodGet, nil)
+		GetAnimalKennelCard(db, storageProvider)(c)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "Scan to meet")
+	})
+
+	t.Run("unreadable photo is skipped", func(t *testing.T) {
+		broken := &mockStorageProvider{GetImageData: []byte("not an image")}
+		c, w := kennelCardTestContext(member.ID, group.ID, animal.ID, http.MethodGet, nil)
+		GetAnimalKennelCard(db, broken)(c)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "/Im1")
+	})
+
+	t.Run("outsiders are denied", func(t *testing.T) {
+		c, w := kennelCardTestContext(outsider.ID, group.ID, animal.ID, http.MethodGet, nil)
+		GetAnimalKennelCard(db, storageProvider)(c)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestRenderKennelCard_LongDescriptionStaysOnPage(t *testing.T) {
+	card := kennelCard{
+		Template:  models.DefaultKennelCardTemplate(1),
+		GroupName: "Dogs",
+		Animal:    models.Animal{Name: "Rex", Description: strings.Repeat("Rex is a very good boy. ", 400)},
+	}
+	card.Template.FooterText = "Visit us Saturdays"
+	doc, err := renderKennelCard(card)
+	require.NoError(t, err)
+
+	s := string(doc)
+	assert.Contains(t, s, "\x85) Tj", "truncated description ends with an ellipsis")
+	assert.Contains(t, s, "(Visit us Saturdays) Tj")
+	assert.Equal(t, 1, strings.Count(s, "/Type /Page "), "description must not spill onto another page")
+
+	// Description lines (12pt) must stay clear of the footer, whose single
+	// line sits on the bottom margin 36pt up from the page edge
+	lines := regexp.MustCompile(`/F1 12\.00 Tf [\d.]+ ([\d.]+) Td`).FindAllStringSubmatch(s, -1)
+	require.NotEmpty(t, lines)
+	for _, m := range lines {
+		y, _ := strconv.ParseFloat(m[1], 64)
+		assert.Greater(t, y, 36+12.0, "description line at y=%v overlaps the footer", y)
+	}
+}
+
+func TestFormatKennelCardAge(t *testing.T) {
+	ago := func(years, months int) *time.Time {
+		d := time.Now().AddDate(-years, -months, -1)
+		return &d
+	}
+	tests := []struct {
+		animal models.Animal
+		want   string
+	}{
+		{models.Animal{}, ""},
+		{models.Animal{Age: 4}, "4 years"},
+		{models.Animal{EstimatedBirthDate: ago(1, 1)}, "1 year, 1 month"},
+		{models.Animal{EstimatedBirthDate: ago(0, 5)}, "5 months"},
+		{models.Animal{EstimatedBirthDate: ago(0, 0)}, "Under 1 month"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, formatKennelCardAge(&tt.animal))
+	}
+}
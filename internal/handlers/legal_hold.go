@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrCodeLegalHold is returned, with 423 Locked, when a change is blocked by
+// an animal's legal hold
+const ErrCodeLegalHold ErrorCode = "LEGAL_HOLD"
+
+// LegalHoldRequest places a legal hold on an animal
+type LegalHoldRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// HeldAnimal is an animal under a legal hold, the impact of lifting it
+type HeldAnimal struct {
+	AnimalID uint   `json:"animal_id"`
+	Name     string `json:"name"`
+	Reason   string `json:"reason"`
+}
+
+// respondLegalHold responds 423 naming the held animals and their hold
+// reasons. Structured errors list each held animal in details, with rule
+// "legal_hold" and its name and reason as the message. held must not be
+// empty.
+func respondLegalHold(c *gin.Context, held ...models.Animal) {
+	details := make([]FieldError, len(held))
+	reasons := make([]string, len(held))
+	for i, a := range held {
+		reasons[i] = fmt.Sprintf("%s (%s)", a.Name, a.LegalHoldReason)
+		details[i] = animalFieldError(a.ID, "legal_hold", reasons[i])
+	}
+	respondError(c, http.StatusLocked, ErrCodeLegalHold, "Under legal hold: "+strings.Join(reasons, ", "), details...)
+}
+
+// heldAnimals returns the animals of ids that are under a legal hold
+func heldAnimals(db *gorm.DB, ids []uint) ([]models.Animal, error) {
+	var held []models.Animal
+	err := db.Where("id IN ? AND legal_hold = ?", ids, true).Order("id").Find(&held).Error
+	return held, err
+}
+
+// PlaceLegalHold puts an animal under a legal hold, e.g. for a bite case
+// (site admin only). While held, the animal's status and group can't change
+// and it can't be deleted or merged away. Placing a hold on a held animal
+// updates the reason.
+// Route: PUT /api/admin/animals/:animalId/legal-hold
+func PlaceLegalHold(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		var req LegalHoldRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		req.Reason = strings.TrimSpace(req.Reason)
+		if req.Reason == "" {
+			respondBadRequest(c, "A reason is required")
+			return
+		}
+
+		var animal models.Animal
+		if err := db.First(&animal, c.Param("animalId")).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeAnimalNotFound, "Animal not found")
+			return
+		}
+
+		uid, _ := middleware.GetUserID(c)
+		updates := map[string]interface{}{"legal_hold_reason": req.Reason}
+		if !animal.LegalHold {
+			updates["legal_hold"] = true
+			updates["legal_hold_at"] = time.Now()
+			updates["legal_hold_by_id"] = uid
+		}
+		if err := db.Model(&animal).Updates(updates).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to place legal hold", err)
+			respondInternalError(c, "Failed to place legal hold")
+			return
+		}
+
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventLegalHoldPlaced, uid, map[string]interface{}{
+			"group_id":  animal.GroupID,
+			"animal_id": animal.ID,
+			"reason":    req.Reason,
+		})
+		if err := db.Preload("Tags").First(&animal, animal.ID).Error; err != nil {
+			respondInternalError(c, "Failed to reload animal")
+			return
+		}
+		respondOK(c, animal)
+	}
+}
+
+// LiftLegalHold lifts an animal's legal hold (site admin only). It takes two
+// calls: the first responds 428 with a confirmation token and the hold being
+// lifted, and repeating the call with ?confirmation_token=<token> lifts it.
+// Route: DELETE /api/admin/animals/:animalId/legal-hold
+func LiftLegalHold(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		var animal models.Animal
+		if err := db.First(&animal, c.Param("animalId")).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeAnimalNotFound, "Animal not found")
+			return
+		}
+		if !animal.LegalHold {
+			respondError(c, http.StatusConflict, ErrCodeConflict, "Animal isn't under a legal hold")
+			return
+		}
+
+		if !requireConfirmation(c, "lift_legal_hold", animal.ID, func() (any, error) {
+			return HeldAnimal{AnimalID: animal.ID, Name: animal.Name, Reason: animal.LegalHoldReason}, nil
+		}) {
+			return
+		}
+
+		if err := db.Model(&animal).Updates(map[string]interface{}{
+			"legal_hold":        false,
+			"legal_hold_reason": "",
+			"legal_hold_at":     nil,
+			"legal_hold_by_id":  nil,
+		}).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to lift legal hold", err)
+			respondInternalError(c, "Failed to lift legal hold")
+			return
+		}
+
+		uid, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventLegalHoldLifted, uid, map[string]interface{}{
+			"group_id":  animal.GroupID,
+			"animal_id": animal.ID,
+			"reason":    animal.LegalHoldReason,
+		})
+		if err := db.Preload("Tags").First(&animal, animal.ID).Error; err != nil {
+			respondInternalError(c, "Failed to reload animal")
+			return
+		}
+		respondOK(c, animal)
+	}
+}
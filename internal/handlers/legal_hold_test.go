@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLegalHold(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	coordinator := CreateTestUser(t, db, "coordinator", "coordinator@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "Dog group")
+	otherGroup := CreateTestGroup(t, db, "Cats", "Cat group")
+	AddUserToGroupWithAdmin(t, db, coordinator.ID, group.ID, true)
+	rex := CreateTestAnimal(t, db, group.ID, "Rex", "Dog")
+	bella := CreateTestAnimal(t, db, group.ID, "Bella", "Dog")
+	animalParams := gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}, {Key: "animalId", Value: fmt.Sprint(rex.ID)}}
+
+	place := func(reason string) int {
+		c, w := accountTestContext(admin.ID, true, http.MethodPut, "/", LegalHoldRequest{Reason: reason})
+		c.Params = gin.Params{{Key: "animalId", Value: fmt.Sprint(rex.ID)}}
+		PlaceLegalHold(db)(c)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusBadRequest, place("  "))
+	require.Equal(t, http.StatusOK, place("Bite case 2026-114"))
+	var held models.Animal
+	require.NoError(t, db.First(&held, rex.ID).Error)
+	assert.True(t, held.LegalHold)
+	require.NotNil(t, held.LegalHoldByID)
+	assert.Equal(t, admin.ID, *held.LegalHoldByID)
+
+	// Status changes are locked, other edits aren't
+	c, w := accountTestContext(coordinator.ID, false, http.MethodPut, "/", map[string]string{"name": "Rex", "status": "archived"})
+	c.Request.Header.Set("Accept", StructuredErrorsMediaType)
+	c.Params = animalParams
+	UpdateAnimal(db, nil, &embedding.StubEmbedder{})(c)
+	require.Equal(t, http.StatusLocked, w.Code)
+	var resp ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, ErrCodeLegalHold, resp.Code)
+	assert.Equal(t, []FieldError{{Field: fmt.Sprintf("animal:%d", rex.ID), Rule: "legal_hold", Message: "Rex (Bite case 2026-114)"}}, resp.Details)
+
+	c, w = accountTestContext(coordinator.ID, false, http.MethodPut, "/", map[string]string{"name": "Rex", "description": "Quiet"})
+	c.Params = animalParams
+	UpdateAnimal(db, nil, &embedding.StubEmbedder{})(c)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	c, w = accountTestContext(admin.ID, true, http.MethodPut, "/", map[string]interface{}{"name": "Rex", "group_id": otherGroup.ID})
+	c.Params = gin.Params{{Key: "animalId", Value: fmt.Sprint(rex.ID)}}
+	UpdateAnimalAdmin(db, nil, &embedding.StubEmbedder{})(c)
+	assert.Equal(t, http.StatusLocked, w.Code, "transfers are locked")
+
+	status := "archived"
+	c, w = accountTestContext(admin.ID, true, http.MethodPost, "/", BulkUpdateAnimalsRequest{AnimalIDs: []uint{bella.ID, rex.ID}, Status: &status})
+	BulkUpdateAnimals(db)(c)
+	assert.Equal(t, http.StatusLocked, w.Code)
+	assert.JSONEq(t, `{"error": "Under legal hold: Rex (Bite case 2026-114)"}`, w.Body.String(), "legacy clients get the plain error")
+	var unchanged models.Animal
+	require.NoError(t, db.First(&unchanged, bella.ID).Error)
+	assert.Equal(t, "available", unchanged.Status, "a locked bulk update changes nothing")
+
+	c, w = accountTestContext(coordinator.ID, false, http.MethodPost, "/", MergeAnimalsRequest{DuplicateID: rex.ID})
+	c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}, {Key: "animalId", Value: fmt.Sprint(bella.ID)}}
+	MergeAnimals(db)(c)
+	assert.Equal(t, http.StatusLocked, w.Code)
+
+	c, w = accountTestContext(coordinator.ID, false, http.MethodDelete, "/", nil)
+	c.Params = animalParams
+	DeleteAnimal(db)(c)
+	assert.Equal(t, http.StatusLocked, w.Code)
+
+	// Lifting takes a confirmation
+	lift := func(target string) (int, ConfirmationRequiredResponse) {
+		c, w := accountTestContext(admin.ID, true, http.MethodDelete, target, nil)
+		c.Params = gin.Params{{Key: "animalId", Value: fmt.Sprint(rex.ID)}}
+		LiftLegalHold(db)(c)
+		var confirm ConfirmationRequiredResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &confirm)
+		return w.Code, confirm
+	}
+	code, confirm := lift("/")
+	require.Equal(t, http.StatusPreconditionRequired, code)
+	require.NotEmpty(t, confirm.ConfirmationToken)
+	code, _ = lift("/?confirmation_token=" + confirm.ConfirmationToken)
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = lift("/")
+	assert.Equal(t, http.StatusConflict, code)
+
+	c, w = accountTestContext(coordinator.ID, false, http.MethodDelete, "/", nil)
+	c.Params = animalParams
+	DeleteAnimal(db)(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,393 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/auth"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/oidc"
+	"gorm.io/gorm"
+)
+
+// The OIDC login flow keeps its state, nonce, and PKCE verifier in a signed,
+// short-lived cookie scoped to the callback, so any replica can finish a
+// login another one started.
+const (
+	oidcStateCookie  = "oidc_state"
+	oidcStateTTL     = 10 * time.Minute
+	oidcStatePurpose = "oidc-state"
+	oidcCookiePath   = "/api/auth/oidc"
+)
+
+// Error codes the login page shows after a failed OIDC login, passed as
+// ?oidc_error=
+const (
+	oidcErrUnknownProvider   = "unknown_provider"
+	oidcErrUnavailable       = "provider_unavailable"
+	oidcErrInvalidState      = "invalid_state"
+	oidcErrCancelled         = "cancelled"
+	oidcErrVerification      = "verification_failed"
+	oidcErrEmailNotVerified  = "email_not_verified"
+	oidcErrNoAccount         = "no_account"
+	oidcErrAccountLocked     = "account_locked"
+	oidcErrServer            = "server_error"
+	passwordLoginDisabledMsg = "Password sign-in is disabled for this account. Sign in with Google or Microsoft instead."
+)
+
+var (
+	// errOIDCNoAccount means no user is linked to the identity or has its
+	// verified email. Accounts are invite-only, so none is created.
+	errOIDCNoAccount = errors.New("no account for this identity")
+	// errOIDCEmailNotVerified means the identity isn't linked yet and the
+	// provider didn't vouch for its email, so it can't be matched to a user.
+	errOIDCEmailNotVerified = errors.New("email not verified by the provider")
+)
+
+type oidcState struct {
+	Provider string `json:"p"`
+	State    string `json:"s"`
+	Nonce    string `json:"n"`
+	Verifier string `json:"v"`
+	Redirect string `json:"r,omitempty"`
+	Expires  int64  `json:"e"`
+}
+
+func encodeOIDCState(st oidcState) (string, error) {
+	payload, err := json.Marshal(st)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	sig, err := auth.Sign(oidcStatePurpose, encoded)
+	if err != nil {
+		return "", err
+	}
+	return encoded + "." + sig, nil
+}
+
+// decodeOIDCState verifies and decodes a state cookie, rejecting expired ones.
+func decodeOIDCState(value string, now time.Time) (oidcState, bool) {
+	var st oidcState
+	encoded, sig, ok := strings.Cut(value, ".")
+	if !ok || !auth.VerifySignature(oidcStatePurpose, encoded, sig) {
+		return st, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || json.Unmarshal(payload, &st) != nil {
+		return st, false
+	}
+	return st, now.Unix() < st.Expires
+}
+
+// frontendURL is the app's public base URL, from FRONTEND_URL.
+func frontendURL() string {
+	baseURL := os.Getenv("FRONTEND_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:5173"
+	}
+	return strings.TrimRight(baseURL, "/")
+}
+
+// oidcRedirectURI is the callback URL registered with the providers:
+// OIDC_REDIRECT_URL, or the callback route under FRONTEND_URL.
+func oidcRedirectURI() string {
+	if u := strings.TrimSpace(os.Getenv("OIDC_REDIRECT_URL")); u != "" {
+		return u
+	}
+	return frontendURL() + oidcCookiePath + "/callback"
+}
+
+// safeRedirectPath returns path if it is a path on this site, so the login
+// can't be used as an open redirect, and "" otherwise.
+func safeRedirectPath(path string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.HasPrefix(path, "/\\") {
+		return ""
+	}
+	return path
+}
+
+func oidcErrorURL(code string) string {
+	return frontendURL() + "/login?oidc_error=" + url.QueryEscape(code)
+}
+
+// oidcFinishURL hands the token to the frontend in the URL fragment, which
+// browsers don't send to servers or in Referer headers. A cookie session
+// already holds its token, so the fragment says session=cookie instead.
+func oidcFinishURL(token, redirect string) string {
+	fragment := url.Values{"token": {token}}
+	if token == "" {
+		fragment = url.Values{"session": {middleware.SessionModeCookie}}
+	}
+	if redirect != "" {
+		fragment.Set("redirect", redirect)
+	}
+	return frontendURL() + "/login/oidc#" + fragment.Encode()
+}
+
+func setOIDCStateCookie(c *gin.Context, value string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode) // Sent on the provider's top-level redirect back
+	c.SetCookie(oidcStateCookie, value, maxAge, oidcCookiePath, "", strings.HasPrefix(oidcRedirectURI(), "https://"), true)
+}
+
+// ListOIDCProviders returns the configured sign-in providers and whether
+// password login is enabled site-wide, for the login page.
+// Route: GET /api/auth/oidc/providers
+func ListOIDCProviders(providers *oidc.Providers, securityConfig *middleware.SecurityConfigStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list := make([]gin.H, 0, len(providers.List()))
+		for _, p := range providers.List() {
+			list = append(list, gin.H{"name": p.Name, "display_name": p.DisplayName})
+		}
+		respondOK(c, gin.H{
+			"providers":              list,
+			"password_login_enabled": securityConfig.Get(c.Request.Context()).PasswordLoginEnabled,
+		})
+	}
+}
+
+// OIDCLogin starts an OIDC login by redirecting to the provider. An optional
+// redirect path is where the frontend goes once signed in.
+// Route: GET /api/auth/oidc/login?provider=google&redirect=/path
+func OIDCLogin(providers *oidc.Providers) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provider, ok := providers.Get(c.Query("provider"))
+		if !ok {
+			c.Redirect(http.StatusFound, oidcErrorURL(oidcErrUnknownProvider))
+			return
+		}
+
+		st := oidcState{
+			Provider: provider.Name,
+			Redirect: safeRedirectPath(c.Query("redirect")),
+			Expires:  time.Now().Add(oidcStateTTL).Unix(),
+		}
+		for _, v := range []*string{&st.State, &st.Nonce, &st.Verifier} {
+			token, err := generateSecureToken()
+			if err != nil {
+				middleware.GetLogger(c).Error("Failed to generate OIDC state", err)
+				c.Redirect(http.StatusFound, oidcErrorURL(oidcErrServer))
+				return
+			}
+			*v = token
+		}
+		cookie, err := encodeOIDCState(st)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to sign OIDC state", err)
+			c.Redirect(http.StatusFound, oidcErrorURL(oidcErrServer))
+			return
+		}
+
+		authURL, err := provider.AuthCodeURL(c.Request.Context(), oidcRedirectURI(), st.State, st.Nonce, st.Verifier)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to reach OIDC provider "+provider.Name, err)
+			c.Redirect(http.StatusFound, oidcErrorURL(oidcErrUnavailable))
+			return
+		}
+		setOIDCStateCookie(c, cookie, int(oidcStateTTL.Seconds()))
+		c.Redirect(http.StatusFound, authURL)
+	}
+}
+
+// OIDCCallback finishes an OIDC login: it checks the state, exchanges the
+// code, finds the user, and redirects to the frontend with a token, or with
+// a cookie session when SESSION_MODE is cookie. A user is found by an
+// identity linked earlier, or else by the provider-verified email, which
+// links the identity for next time.
+// Route: GET /api/auth/oidc/callback
+func OIDCCallback(db *gorm.DB, providers *oidc.Providers) gin.HandlerFunc {
+	sessions := middleware.SessionConfigFromEnv()
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		db := middleware.GetDB(c, db)
+		logger := middleware.GetLogger(c)
+		fail := func(code string) {
+			c.Redirect(http.StatusFound, oidcErrorURL(code))
+		}
+
+		cookie, _ := c.Cookie(oidcStateCookie)
+		setOIDCStateCookie(c, "", -1) // Single use
+		st, ok := decodeOIDCState(cookie, time.Now())
+		if !ok || subtle.ConstantTimeCompare([]byte(st.State), []byte(c.Query("state"))) != 1 {
+			logging.LogAuthFailure(ctx, "", c.ClientIP(), "oidc_invalid_state")
+			fail(oidcErrInvalidState)
+			return
+		}
+		if c.Query("error") != "" {
+			fail(oidcErrCancelled)
+			return
+		}
+		provider, ok := providers.Get(st.Provider)
+		if !ok {
+			fail(oidcErrUnknownProvider)
+			return
+		}
+
+		claims, err := provider.Exchange(ctx, c.Query("code"), oidcRedirectURI(), st.Verifier, st.Nonce)
+		if err != nil {
+			logger.Error("OIDC login failed for provider "+provider.Name, err)
+			logging.LogAuthFailure(ctx, "", c.ClientIP(), "oidc_verification_failed")
+			fail(oidcErrVerification)
+			return
+		}
+
+		user, err := findOIDCUser(ctx, db, provider.Name, claims)
+		switch {
+		case errors.Is(err, errOIDCEmailNotVerified):
+			logging.LogAuthFailure(ctx, claims.Email, c.ClientIP(), "oidc_email_not_verified")
+			fail(oidcErrEmailNotVerified)
+			return
+		case errors.Is(err, errOIDCNoAccount):
+			logging.LogAuthFailure(ctx, claims.Email, c.ClientIP(), "oidc_no_account")
+			fail(oidcErrNoAccount)
+			return
+		case err != nil:
+			logger.Error("Failed to look up OIDC user", err)
+			fail(oidcErrServer)
+			return
+		}
+
+		if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+			logging.LogAuthFailure(ctx, user.Username, c.ClientIP(), "account_locked")
+			fail(oidcErrAccountLocked)
+			return
+		}
+		if err := recordSuccessfulLogin(db, user); err != nil {
+			logger.Error("Failed to record login", err)
+			fail(oidcErrServer)
+			return
+		}
+		logging.LogAuthSuccess(ctx, user.ID, user.Username, c.ClientIP())
+
+		token, err := generateSessionToken(db, user.ID, user.Username, user.IsAdmin)
+		if err != nil {
+			logger.Error("Failed to generate token", err)
+			fail(oidcErrServer)
+			return
+		}
+		if token, _, err = startSession(c, sessions, token, sessions.Cookies()); err != nil {
+			logger.Error("Failed to start session", err)
+			fail(oidcErrServer)
+			return
+		}
+		c.Redirect(http.StatusFound, oidcFinishURL(token, st.Redirect))
+	}
+}
+
+// findOIDCUser returns the user linked to the provider identity in claims,
+// linking it by verified email on first use.
+func findOIDCUser(ctx context.Context, db *gorm.DB, provider string, claims *oidc.Claims) (*models.User, error) {
+	db = db.WithContext(ctx)
+	now := time.Now()
+
+	var identity models.UserIdentity
+	err := db.Where("provider = ? AND subject = ?", provider, claims.Subject).First(&identity).Error
+	if err == nil {
+		var user models.User
+		if err := db.First(&user, identity.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errOIDCNoAccount // Deactivated or deleted
+			}
+			return nil, err
+		}
+		if err := db.Model(&identity).Update("last_used_at", now).Error; err != nil {
+			return nil, err
+		}
+		return &user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if !claims.EmailVerified {
+		return nil, errOIDCEmailNotVerified
+	}
+	var user models.User
+	if err := db.Where("LOWER(email) = ?", strings.ToLower(claims.Email)).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errOIDCNoAccount
+		}
+		return nil, err
+	}
+	if err := db.Create(&models.UserIdentity{
+		UserID:     user.ID,
+		Provider:   provider,
+		Subject:    claims.Subject,
+		Email:      claims.Email,
+		LastUsedAt: &now,
+	}).Error; err != nil {
+		return nil, err
+	}
+	logging.WithFields(map[string]interface{}{
+		"user_id":  user.ID,
+		"provider": provider,
+	}).Info("Linked OIDC identity by verified email")
+	return &user, nil
+}
+
+// passwordLoginAllowed reports whether user may sign in with a password.
+// Site admins keep password login when it's disabled site-wide, so a broken
+// OIDC setup can still be fixed.
+func passwordLoginAllowed(user *models.User, cfg middleware.SecurityConfig) bool {
+	if user.PasswordLoginDisabled {
+		return false
+	}
+	return cfg.PasswordLoginEnabled || user.IsAdmin
+}
+
+// SetPasswordLoginRequest is the body of PUT
+// /api/admin/users/:userId/password-login.
+type SetPasswordLoginRequest struct {
+	Disabled *bool `json:"disabled" binding:"required"`
+}
+
+// SetUserPasswordLogin turns password login off or on for one user (admin
+// only). With it off, the user must sign in through an OIDC provider.
+// Route: PUT /api/admin/users/:userId/password-login
+func SetUserPasswordLogin(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		var req SetPasswordLoginRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		var user models.User
+		if err := db.First(&user, c.Param("userId")).Error; err != nil {
+			respondNotFound(c, "User not found")
+			return
+		}
+		if err := db.Model(&user).Update("password_login_disabled", *req.Disabled).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to update password login", err)
+			respondInternalError(c, "Failed to update password login")
+			return
+		}
+
+		var linked []string
+		if err := db.Model(&models.UserIdentity{}).Where("user_id = ?", user.ID).Distinct().Pluck("provider", &linked).Error; err != nil {
+			respondInternalError(c, "Failed to load linked accounts")
+			return
+		}
+		adminID, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventPasswordLoginChanged, adminID, map[string]interface{}{
+			"user_id":                 user.ID,
+			"password_login_disabled": *req.Disabled,
+		})
+		respondOK(c, gin.H{
+			"user_id":                 user.ID,
+			"password_login_disabled": *req.Disabled,
+			"linked_providers":        linked,
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/oidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindOIDCUser(t *testing.T) {
+	db := SetupTestDB(t)
+	ctx := context.Background()
+	jane := CreateTestUser(t, db, "jane", "Jane@Example.org", "password123", false)
+
+	_, err := findOIDCUser(ctx, db, "google", &oidc.Claims{Subject: "g-1", Email: "jane@example.org"})
+	assert.ErrorIs(t, err, errOIDCEmailNotVerified, "unverified emails must not link accounts")
+
+	_, err = findOIDCUser(ctx, db, "google", &oidc.Claims{Subject: "g-2", Email: "nobody@example.org", EmailVerified: true})
+	assert.ErrorIs(t, err, errOIDCNoAccount)
+
+	user, err := findOIDCUser(ctx, db, "google", &oidc.Claims{Subject: "g-1", Email: "jane@example.org", EmailVerified: true})
+	require.NoError(t, err)
+	assert.Equal(t, jane.ID, user.ID, "email match is case-insensitive")
+
+	var identity models.UserIdentity
+	require.NoError(t, db.Where("provider = ? AND subject = ?", "google", "g-1").First(&identity).Error)
+	assert.Equal(t, jane.ID, identity.UserID)
+
+	// Once linked, the identity is found by subject even if the email changes
+	// or is no longer verified
+	user, err = findOIDCUser(ctx, db, "google", &oidc.Claims{Subject: "g-1", Email: "jane@new.example"})
+	require.NoError(t, err)
+	assert.Equal(t, jane.ID, user.ID)
+
+	// Same subject at another provider is a different identity
+	_, err = findOIDCUser(ctx, db, "microsoft", &oidc.Claims{Subject: "g-1"})
+	assert.ErrorIs(t, err, errOIDCEmailNotVerified)
+
+	require.NoError(t, db.Delete(jane).Error)
+	_, err = findOIDCUser(ctx, db, "google", &oidc.Claims{Subject: "g-1"})
+	assert.ErrorIs(t, err, errOIDCNoAccount, "deactivated users can't sign in")
+}
+
+func TestOIDCState(t *testing.T) {
+	os.Setenv("JWT_SECRET", "aB3dE5fG7hI9jK1lM3nO5pQ7rS9tU1vW3xY5zA7bC9dE1fG3hI5jK7lM9nO1pQ3")
+	now := time.Now()
+	st := oidcState{Provider: "google", State: "s", Nonce: "n", Verifier: "v", Redirect: "/groups/1", Expires: now.Add(time.Minute).Unix()}
+	value, err := encodeOIDCState(st)
+	require.NoError(t, err)
+
+	got, ok := decodeOIDCState(value, now)
+	assert.True(t, ok)
+	assert.Equal(t, st, got)
+
+	_, ok = decodeOIDCState(value, now.Add(2*time.Minute))
+	assert.False(t, ok, "expired state")
+	_, ok = decodeOIDCState(strings.Replace(value, ".", "x.", 1), now)
+	assert.False(t, ok, "tampered state")
+	_, ok = decodeOIDCState("", now)
+	assert.False(t, ok)
+
+	assert.Equal(t, "/groups/1", safeRedirectPath("/groups/1"))
+	for _, path := range []string{"https://evil.example", "//evil.example", "/\\evil.example", ""} {
+		assert.Empty(t, safeRedirectPath(path), path)
+	}
+}
+
+func TestOIDCLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	os.Setenv("JWT_SECRET", "aB3dE5fG7hI9jK1lM3nO5pQ7rS9tU1vW3xY5zA7bC9dE1fG3hI5jK7lM9nO1pQ3")
+	t.Setenv("FRONTEND_URL", "https://app.example.org")
+	t.Setenv("OIDC_REDIRECT_URL", "")
+
+	issuer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 "http://" + r.Host,
+			"authorization_endpoint": "http://" + r.Host + "/authorize",
+			"token_endpoint":         "http://" + r.Host + "/token",
+			"jwks_uri":               "http://" + r.Host + "/keys",
+		})
+	}))
+	defer issuer.Close()
+	providers := oidc.NewProviders(oidc.NewProvider("test", "Test", issuer.URL, "client-id", "secret"))
+
+	router := gin.New()
+	router.GET("/api/auth/oidc/login", OIDCLogin(providers))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login?provider=nope", nil))
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "https://app.example.org/login?oidc_error=unknown_provider", w.Header().Get("Location"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login?provider=test&redirect=//evil.example", nil))
+	require.Equal(t, http.StatusFound, w.Code)
+	location, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, issuer.URL+"/authorize", location.Scheme+"://"+location.Host+location.Path)
+	assert.Equal(t, "https://app.example.org/api/auth/oidc/callback", location.Query().Get("redirect_uri"))
+
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, oidcStateCookie, cookies[0].Name)
+	assert.True(t, cookies[0].HttpOnly)
+	assert.True(t, cookies[0].Secure, "https redirect URI means a secure cookie")
+	st, ok := decodeOIDCState(cookies[0].Value, time.Now())
+	require.True(t, ok)
+	assert.Equal(t, location.Query().Get("state"), st.State)
+	assert.Equal(t, location.Query().Get("nonce"), st.Nonce)
+	assert.Empty(t, st.Redirect, "off-site redirects are dropped")
+}
+
+func TestOIDCCallback_RejectsBadState(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	os.Setenv("JWT_SECRET", "aB3dE5fG7hI9jK1lM3nO5pQ7rS9tU1vW3xY5zA7bC9dE1fG3hI5jK7lM9nO1pQ3")
+	t.Setenv("FRONTEND_URL", "https://app.example.org")
+	db := SetupTestDB(t)
+	router := gin.New()
+	router.GET("/api/auth/oidc/callback", OIDCCallback(db, oidc.NewProviders()))
+
+	value, err := encodeOIDCState(oidcState{Provider: "test", State: "right", Expires: time.Now().Add(time.Minute).Unix()})
+	require.NoError(t, err)
+	for _, cookie := range []string{"", value} {
+		req := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/callback?state=wrong&code=x", nil)
+		if cookie != "" {
+			req.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: cookie})
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "https://app.example.org/login?oidc_error=invalid_state", w.Header().Get("Location"))
+	}
+}
+
+func TestLogin_PasswordLoginDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("PASSWORD_LOGIN_ENABLED", "")
+	db := SetupTestDB(t)
+	volunteer := CreateTestUser(t, db, "volunteer", "volunteer@example.com", "password123", false)
+	CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	store := middleware.NewSecurityConfigStore(db)
+	handler := Login(db, store)
+
+	login := func(username string) int {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		body, _ := json.Marshal(map[string]string{"username": username, "password": "password123"})
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/login", bytes.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		handler(c)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, login("volunteer"))
+
+	require.NoError(t, db.Model(volunteer).Update("password_login_disabled", true).Error)
+	assert.Equal(t, http.StatusForbidden, login("volunteer"), "disabled for the user")
+	require.NoError(t, db.Model(volunteer).Update("password_login_disabled", false).Error)
+
+	require.NoError(t, db.Create(&models.SiteSetting{Key: middleware.SettingPasswordLoginEnabled, Value: "false"}).Error)
+	store.Invalidate()
+	assert.Equal(t, http.StatusForbidden, login("volunteer"), "disabled site-wide")
+	assert.Equal(t, http.StatusOK, login("admin"), "site admins keep password login")
+}
+
+func TestSetUserPasswordLogin(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	volunteer := CreateTestUser(t, db, "volunteer", "volunteer@example.com", "password123", false)
+	require.NoError(t, db.Create(&models.UserIdentity{UserID: volunteer.ID, Provider: "google", Subject: "g-1"}).Error)
+
+	c, w := accountTestContext(admin.ID, true, http.MethodPut, "/", map[string]any{})
+	c.Params = gin.Params{{Key: "userId", Value: "9999"}}
+	SetUserPasswordLogin(db)(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "disabled is required")
+
+	c, w = accountTestContext(admin.ID, true, http.MethodPut, "/", map[string]any{"disabled": true})
+	c.Params = gin.Params{{Key: "userId", Value: "9999"}}
+	SetUserPasswordLogin(db)(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	c, w = accountTestContext(admin.ID, true, http.MethodPut, "/", map[string]any{"disabled": true})
+	c.Params = gin.Params{{Key: "userId", Value: strconv.FormatUint(uint64(volunteer.ID), 10)}}
+	SetUserPasswordLogin(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Disabled bool     `json:"password_login_disabled"`
+		Linked   []string `json:"linked_providers"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Disabled)
+	assert.Equal(t, []string{"google"}, resp.Linked)
+
+	var updated models.User
+	require.NoError(t, db.First(&updated, volunteer.ID).Error)
+	assert.True(t, updated.PasswordLoginDisabled)
+}
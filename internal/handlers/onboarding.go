@@ -0,0 +1,803 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobOnboardingWelcomeEmail is the background job type that welcomes one
+// new member to a group with its onboarding checklist
+const JobOnboardingWelcomeEmail = "onboarding_welcome_email"
+
+// onboardingWelcomeJob is the payload of a JobOnboardingWelcomeEmail job
+type onboardingWelcomeJob struct {
+	UserID  uint `json:"user_id"`
+	GroupID uint `json:"group_id"`
+}
+
+const (
+	// maxOnboardingItems bounds the onboarding items a group can define
+	maxOnboardingItems = 50
+
+	// onboardingStopTimeout bounds how long stop() waits for an in-flight
+	// tick to finish, matching the other schedulers
+	onboardingStopTimeout = 10 * time.Second
+)
+
+// defaultOnboardingLabels label automatic items that don't set their own
+var defaultOnboardingLabels = map[string]string{
+	models.OnboardingProtocols: "Read the group's protocols",
+	models.OnboardingProfile:   "Complete your profile",
+}
+
+// OnboardingItemRequest creates or replaces an onboarding item. Label is
+// required for task and confirmed items.
+type OnboardingItemRequest struct {
+	Kind        string `json:"kind" binding:"required"`
+	Label       string `json:"label" binding:"max=200"`
+	Description string `json:"description" binding:"max=2000"`
+	OrderIndex  int    `json:"order_index"`
+}
+
+// OnboardingCompleteRequest names the member whose item a group admin is
+// checking off. Members leave it out to check off their own.
+type OnboardingCompleteRequest struct {
+	UserID uint `json:"user_id"`
+}
+
+// OnboardingItemProgress is an onboarding item and whether a member has
+// done it. CompletedAt is empty for automatic items.
+type OnboardingItemProgress struct {
+	models.OnboardingItem
+	Complete    bool       `json:"complete"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// OnboardingChecklist is a member's progress through a group's onboarding.
+// Required is false for members who joined before the group had a
+// checklist; they can still work through it.
+type OnboardingChecklist struct {
+	GroupID        uint                     `json:"group_id"`
+	Required       bool                     `json:"required"`
+	StartedAt      *time.Time               `json:"started_at,omitempty"`
+	Complete       bool                     `json:"complete"`
+	CompletedCount int                      `json:"completed_count"`
+	Items          []OnboardingItemProgress `json:"items"`
+}
+
+// OnboardingMember is one new member in an onboarding report
+type OnboardingMember struct {
+	UserID             uint       `json:"user_id"`
+	Username           string     `json:"username"`
+	FirstName          string     `json:"first_name"`
+	LastName           string     `json:"last_name"`
+	Email              string     `json:"email"`
+	StartedAt          time.Time  `json:"started_at"`
+	WelcomeEmailSentAt *time.Time `json:"welcome_email_sent_at"`
+	CompletedCount     int        `json:"completed_count"`
+	Complete           bool       `json:"complete"`
+	PendingItemIDs     []uint     `json:"pending_item_ids"`
+}
+
+// OnboardingReport is a group's onboarding progress for its new members,
+// those who joined once the group had a checklist
+type OnboardingReport struct {
+	Items           []models.OnboardingItem `json:"items"`
+	CompleteCount   int                     `json:"complete_count"`
+	IncompleteCount int                     `json:"incomplete_count"`
+	Members         []OnboardingMember      `json:"members"`
+}
+
+// buildOnboardingItem validates req and copies it onto item
+func buildOnboardingItem(item *models.OnboardingItem, req OnboardingItemRequest) error {
+	kind := strings.TrimSpace(req.Kind)
+	if !slices.Contains(models.OnboardingKinds, kind) {
+		return fmt.Errorf("kind must be one of: %s", strings.Join(models.OnboardingKinds, ", "))
+	}
+	label := strings.TrimSpace(req.Label)
+	if label == "" {
+		label = defaultOnboardingLabels[kind]
+	}
+	if label == "" {
+		return errors.New("label is required for task and confirmed items")
+	}
+	item.Kind = kind
+	item.Label = label
+	item.Description = strings.TrimSpace(req.Description)
+	item.OrderIndex = req.OrderIndex
+	return nil
+}
+
+// onboardingItems returns a group's onboarding items in display order
+func onboardingItems(db *gorm.DB, groupID uint) ([]models.OnboardingItem, error) {
+	items := []models.OnboardingItem{}
+	err := db.Where("group_id = ?", groupID).Order("order_index, id").Find(&items).Error
+	return items, err
+}
+
+// onboardingProgress evaluates items for each of userIDs. Task and
+// confirmed items are complete once checked off; protocols once the user
+// has acknowledged the current version of every group protocol that asks
+// for it; profile once the user has a first name, last name, and phone
+// number.
+func onboardingProgress(db *gorm.DB, groupID uint, items []models.OnboardingItem, userIDs []uint) (map[uint][]OnboardingItemProgress, error) {
+	progress := make(map[uint][]OnboardingItemProgress, len(userIDs))
+	if len(userIDs) == 0 {
+		return progress, nil
+	}
+	itemIDs := make([]uint, len(items))
+	for i, item := range items {
+		itemIDs[i] = item.ID
+	}
+
+	var completions []models.OnboardingCompletion
+	if len(itemIDs) > 0 {
+		if err := db.Where("item_id IN ? AND user_id IN ?", itemIDs, userIDs).Find(&completions).Error; err != nil {
+			return nil, err
+		}
+	}
+	type key struct{ userID, itemID uint }
+	completedAt := make(map[key]time.Time, len(completions))
+	for _, c := range completions {
+		completedAt[key{c.UserID, c.ItemID}] = c.CreatedAt
+	}
+
+	var protocols []models.Protocol
+	if err := db.Select("id", "version").Where("group_id = ? AND requires_acknowledgment = ?", groupID, true).Find(&protocols).Error; err != nil {
+		return nil, err
+	}
+	acked := map[uint]int{} // user -> acknowledged protocols at their current version
+	if len(protocols) > 0 {
+		conds := db.Where("1 = 0")
+		for _, p := range protocols {
+			conds = conds.Or("protocol_id = ? AND version = ?", p.ID, p.Version)
+		}
+		var rows []struct {
+			UserID uint
+			Count  int
+		}
+		if err := db.Model(&models.ProtocolAcknowledgment{}).Select("user_id, COUNT(*) AS count").
+			Where("user_id IN ?", userIDs).Where(conds).Group("user_id").Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			acked[row.UserID] = row.Count
+		}
+	}
+
+	var users []models.User
+	if err := db.Select("id", "first_name", "last_name", "phone_number").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	profileDone := make(map[uint]bool, len(users))
+	for _, u := range users {
+		profileDone[u.ID] = strings.TrimSpace(u.FirstName) != "" && strings.TrimSpace(u.LastName) != "" && strings.TrimSpace(u.PhoneNumber) != ""
+	}
+
+	for _, uid := range userIDs {
+		list := make([]OnboardingItemProgress, len(items))
+		for i, item := range items {
+			list[i] = OnboardingItemProgress{OnboardingItem: item}
+			switch item.Kind {
+			case models.OnboardingProtocols:
+				list[i].Complete = acked[uid] >= len(protocols)
+			case models.OnboardingProfile:
+				list[i].Complete = profileDone[uid]
+			default:
+				if at, ok := completedAt[key{uid, item.ID}]; ok {
+					list[i].Complete = true
+					list[i].CompletedAt = &at
+				}
+			}
+		}
+		progress[uid] = list
+	}
+	return progress, nil
+}
+
+// countComplete returns how many of items are complete
+func countComplete(items []OnboardingItemProgress) int {
+	n := 0
+	for _, item := range items {
+		if item.Complete {
+			n++
+		}
+	}
+	return n
+}
+
+// startGroupOnboarding starts onboarding for the group's members who joined
+// once it had an onboarding checklist and haven't started yet, queueing a
+// welcome email for each. Group admins don't onboard. Each member is
+// claimed with a MemberOnboarding row, so when several replicas run the
+// scheduler a member is only welcomed once. Returns how many it started.
+func startGroupOnboarding(db *gorm.DB, groupID uint) (int, error) {
+	var first models.OnboardingItem
+	if err := db.Where("group_id = ?", groupID).Order("created_at, id").First(&first).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	var userIDs []uint
+	if err := db.Table("user_groups").
+		Joins("JOIN users ON users.id = user_groups.user_id AND users.deleted_at IS NULL").
+		Where("user_groups.group_id = ? AND user_groups.is_group_admin = ? AND user_groups.created_at >= ?", groupID, false, first.CreatedAt).
+		Where("NOT EXISTS (SELECT 1 FROM member_onboardings mo WHERE mo.user_id = user_groups.user_id AND mo.group_id = user_groups.group_id)").
+		Pluck("user_groups.user_id", &userIDs).Error; err != nil {
+		return 0, err
+	}
+
+	started := 0
+	for _, uid := range userIDs {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			claim := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.MemberOnboarding{UserID: uid, GroupID: groupID})
+			if claim.Error != nil || claim.RowsAffected == 0 {
+				return claim.Error // Already started
+			}
+			_, err := jobs.Enqueue(tx, JobOnboardingWelcomeEmail, onboardingWelcomeJob{UserID: uid, GroupID: groupID})
+			return err
+		})
+		if err != nil {
+			return started, err
+		}
+		started++
+	}
+	return started, nil
+}
+
+// startAllOnboarding runs startGroupOnboarding for every group with an
+// onboarding checklist and returns how many members it started
+func startAllOnboarding(ctx context.Context, db *gorm.DB) int {
+	logger := logging.WithContext(ctx)
+	db = db.WithContext(ctx)
+
+	var groupIDs []uint
+	if err := db.Model(&models.OnboardingItem{}).Distinct("group_id").Pluck("group_id", &groupIDs).Error; err != nil {
+		logger.Error("Failed to list groups with onboarding", err)
+		return 0
+	}
+	total := 0
+	for _, gid := range groupIDs {
+		n, err := startGroupOnboarding(db, gid)
+		if err != nil {
+			logger.WithField("group_id", gid).Error("Failed to start member onboarding", err)
+		}
+		total += n
+	}
+	return total
+}
+
+// StartOnboardingScheduler periodically starts onboarding, and queues the
+// welcome emails, for members who joined a group with an onboarding
+// checklist. Members also start when they or a group admin open the
+// checklist, so the interval only bounds how long a welcome email waits.
+// Returns a stop function; call it during graceful shutdown, before closing
+// the database.
+func StartOnboardingScheduler(db *gorm.DB, interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		for {
+			select {
+			case <-ticker.C:
+				startAllOnboarding(context.Background(), db)
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		select {
+		case <-finished:
+		case <-time.After(onboardingStopTimeout):
+			logging.Warn(fmt.Sprintf("Onboarding scheduler did not stop within %s of shutdown signal; proceeding with shutdown anyway", onboardingStopTimeout))
+		}
+	}
+}
+
+// onboardingWelcomeEmailJobHandler sends a JobOnboardingWelcomeEmail with
+// the group's welcome text and checklist, unless it was sent already or the
+// member has left the group
+func onboardingWelcomeEmailJobHandler(db *gorm.DB, emailService *email.Service) jobs.Handler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var job onboardingWelcomeJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return jobs.Permanent(err)
+		}
+		if emailService == nil || !emailService.IsConfigured() {
+			return errors.New("email service is not configured")
+		}
+		db := db.WithContext(ctx)
+
+		var onboarding models.MemberOnboarding
+		var user models.User
+		var group models.Group
+		for _, load := range []func() error{
+			func() error {
+				return db.Where("user_id = ? AND group_id = ?", job.UserID, job.GroupID).First(&onboarding).Error
+			},
+			func() error { return db.Where("email_undeliverable = ?", false).First(&user, job.UserID).Error },
+			func() error { return db.First(&group, job.GroupID).Error },
+			func() error {
+				return db.Where("user_id = ? AND group_id = ?", job.UserID, job.GroupID).First(&models.UserGroup{}).Error
+			},
+		} {
+			if err := load(); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil
+				}
+				return err
+			}
+		}
+		if onboarding.WelcomeEmailSentAt != nil || user.Email == "" {
+			return nil
+		}
+
+		items, err := onboardingItems(db, group.ID)
+		if err != nil {
+			return err
+		}
+		labels := make([]string, len(items))
+		for i, item := range items {
+			labels[i] = item.Label
+		}
+		link := fmt.Sprintf("%s/groups/%d/onboarding", frontendURL(), group.ID)
+		if err := emailService.SendWelcomeEmail(withGroupSender(ctx, group), user.Email, user.Username, group.Name, group.WelcomeText, labels, link); err != nil {
+			return err
+		}
+		return db.Model(&onboarding).Update("welcome_email_sent_at", time.Now()).Error
+	}
+}
+
+// loadOnboardingItem loads the :itemId item of the :id group, responding
+// 404 itself when it returns false
+func loadOnboardingItem(c *gin.Context, db *gorm.DB) (models.OnboardingItem, bool) {
+	var item models.OnboardingItem
+	if err := db.Where("id = ? AND group_id = ?", c.Param("itemId"), c.Param("id")).First(&item).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondNotFound(c, "Onboarding item not found")
+		} else {
+			respondInternalError(c, "Failed to load onboarding item")
+		}
+		return item, false
+	}
+	return item, true
+}
+
+// GetOnboardingItems returns a group's onboarding checklist
+// Route: GET /api/groups/:id/onboarding/items
+func GetOnboardingItems(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+
+		items, err := onboardingItems(db, uint(gid))
+		if err != nil {
+			respondInternalError(c, "Failed to load onboarding items")
+			return
+		}
+		respondOK(c, gin.H{"items": items})
+	}
+}
+
+// CreateOnboardingItem adds an item to a group's onboarding checklist
+// (group admin or site admin). A group's first item starts onboarding for
+// members who join from then on.
+// Route: POST /api/groups/:id/onboarding/items
+func CreateOnboardingItem(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
+			return
+		}
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+
+		var req OnboardingItemRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		item := models.OnboardingItem{GroupID: uint(gid)}
+		if err := buildOnboardingItem(&item, req); err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		var count int64
+		if err := db.Model(&models.OnboardingItem{}).Where("group_id = ?", gid).Count(&count).Error; err != nil {
+			respondInternalError(c, "Failed to create onboarding item")
+			return
+		}
+		if count >= maxOnboardingItems {
+			respondBadRequest(c, fmt.Sprintf("at most %d onboarding items are allowed", maxOnboardingItems))
+			return
+		}
+		if err := db.Create(&item).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to create onboarding item", err)
+			respondInternalError(c, "Failed to create onboarding item")
+			return
+		}
+
+		uid, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventGroupUpdated, uid, map[string]interface{}{
+			"group_id": gid,
+			"change":   "onboarding_item_created",
+			"item_id":  item.ID,
+		})
+		respondCreated(c, item)
+	}
+}
+
+// UpdateOnboardingItem replaces an onboarding item (group admin or site
+// admin). Members keep what they already checked off.
+// Route: PUT /api/groups/:id/onboarding/items/:itemId
+func UpdateOnboardingItem(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAdminAccess(db, userID, isAdmin, c.Param("id")) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
+			return
+		}
+		item, ok := loadOnboardingItem(c, db)
+		if !ok {
+			return
+		}
+
+		var req OnboardingItemRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		if err := buildOnboardingItem(&item, req); err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+		if err := db.Save(&item).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to update onboarding item", err)
+			respondInternalError(c, "Failed to update onboarding item")
+			return
+		}
+
+		uid, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventGroupUpdated, uid, map[string]interface{}{
+			"group_id": item.GroupID,
+			"change":   "onboarding_item_updated",
+			"item_id":  item.ID,
+		})
+		respondOK(c, item)
+	}
+}
+
+// DeleteOnboardingItem removes an onboarding item and every member's
+// completion of it (group admin or site admin)
+// Route: DELETE /api/groups/:id/onboarding/items/:itemId
+func DeleteOnboardingItem(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAdminAccess(db, userID, isAdmin, c.Param("id")) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
+			return
+		}
+		item, ok := loadOnboardingItem(c, db)
+		if !ok {
+			return
+		}
+
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("item_id = ?", item.ID).Delete(&models.OnboardingCompletion{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&item).Error
+		}); err != nil {
+			middleware.GetLogger(c).Error("Failed to delete onboarding item", err)
+			respondInternalError(c, "Failed to delete onboarding item")
+			return
+		}
+
+		uid, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventGroupUpdated, uid, map[string]interface{}{
+			"group_id": item.GroupID,
+			"change":   "onboarding_item_deleted",
+			"item_id":  item.ID,
+		})
+		respondOK(c, gin.H{"message": "Onboarding item deleted"})
+	}
+}
+
+// GetMyOnboarding returns the current user's progress through a group's
+// onboarding checklist
+// Route: GET /api/groups/:id/onboarding
+func GetMyOnboarding(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+		uid, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+
+		// Best-effort: a member who just joined starts now rather than at
+		// the scheduler's next tick
+		if _, err := startGroupOnboarding(db, uint(gid)); err != nil {
+			middleware.GetLogger(c).Error("Failed to start member onboarding", err)
+		}
+
+		items, err := onboardingItems(db, uint(gid))
+		if err != nil {
+			respondInternalError(c, "Failed to load onboarding items")
+			return
+		}
+		progress, err := onboardingProgress(db, uint(gid), items, []uint{uid})
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to evaluate onboarding", err)
+			respondInternalError(c, "Failed to load onboarding")
+			return
+		}
+		checklist := OnboardingChecklist{GroupID: uint(gid), Items: progress[uid]}
+		checklist.CompletedCount = countComplete(checklist.Items)
+		checklist.Complete = checklist.CompletedCount == len(checklist.Items)
+
+		var onboarding models.MemberOnboarding
+		err = db.Where("user_id = ? AND group_id = ?", uid, gid).First(&onboarding).Error
+		switch {
+		case err == nil:
+			checklist.Required = true
+			checklist.StartedAt = &onboarding.CreatedAt
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			respondInternalError(c, "Failed to load onboarding")
+			return
+		}
+		respondOK(c, checklist)
+	}
+}
+
+// setOnboardingCompletion checks off, or with done false clears, a task or
+// confirmed item. Members check off their own task items; group admins
+// check off either kind for any member with ?user_id= in the body.
+func setOnboardingCompletion(db *gorm.DB, done bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+		uid, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+		item, ok := loadOnboardingItem(c, db)
+		if !ok {
+			return
+		}
+
+		var req OnboardingCompleteRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				respondValidationError(c, err)
+				return
+			}
+		}
+		target := uid
+		if req.UserID != 0 {
+			target = req.UserID
+		}
+		groupAdmin := checkGroupAdminAccess(db, userID, isAdmin, groupID)
+
+		switch {
+		case item.Kind != models.OnboardingTask && item.Kind != models.OnboardingConfirmed:
+			respondBadRequest(c, "This item is checked automatically")
+			return
+		case (target != uid || item.Kind == models.OnboardingConfirmed) && !groupAdmin:
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Only a group admin can check off this item")
+			return
+		}
+		if target != uid {
+			var member int64
+			if err := db.Model(&models.UserGroup{}).Where("user_id = ? AND group_id = ?", target, item.GroupID).Count(&member).Error; err != nil {
+				respondInternalError(c, "Failed to update onboarding")
+				return
+			}
+			if member == 0 {
+				respondNotFound(c, "Member not found")
+				return
+			}
+		}
+
+		if done {
+			completion := models.OnboardingCompletion{ItemID: item.ID, UserID: target, CompletedByID: uid}
+			if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&completion).Error; err != nil {
+				middleware.GetLogger(c).Error("Failed to record onboarding completion", err)
+				respondInternalError(c, "Failed to update onboarding")
+				return
+			}
+		} else if err := db.Where("item_id = ? AND user_id = ?", item.ID, target).Delete(&models.OnboardingCompletion{}).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to clear onboarding completion", err)
+			respondInternalError(c, "Failed to update onboarding")
+			return
+		}
+
+		progress, err := onboardingProgress(db, item.GroupID, []models.OnboardingItem{item}, []uint{target})
+		if err != nil {
+			respondInternalError(c, "Failed to load onboarding")
+			return
+		}
+		respondOK(c, progress[target][0])
+	}
+}
+
+// CompleteOnboardingItem checks off a task or confirmed onboarding item.
+// Repeating the call is a no-op.
+// Route: POST /api/groups/:id/onboarding/items/:itemId/complete
+func CompleteOnboardingItem(db *gorm.DB) gin.HandlerFunc {
+	return setOnboardingCompletion(db, true)
+}
+
+// UncompleteOnboardingItem clears a checked-off onboarding item
+// Route: DELETE /api/groups/:id/onboarding/items/:itemId/complete
+func UncompleteOnboardingItem(db *gorm.DB) gin.HandlerFunc {
+	return setOnboardingCompletion(db, false)
+}
+
+// GetOnboardingReport returns the onboarding progress of a group's new
+// members, least complete first (group admin or site admin). Query params:
+// status (complete or incomplete).
+// Route: GET /api/groups/:id/onboarding/report
+func GetOnboardingReport(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
+			return
+		}
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+		status := c.Query("status")
+		if status != "" && status != "complete" && status != "incomplete" {
+			respondBadRequest(c, "status must be complete or incomplete")
+			return
+		}
+
+		if _, err := startGroupOnboarding(db, uint(gid)); err != nil {
+			middleware.GetLogger(c).Error("Failed to start member onboarding", err)
+		}
+		items, err := onboardingItems(db, uint(gid))
+		if err != nil {
+			respondInternalError(c, "Failed to load onboarding items")
+			return
+		}
+
+		var rows []struct {
+			models.MemberOnboarding
+			Username  string
+			FirstName string
+			LastName  string
+			Email     string
+		}
+		if err := db.Table("member_onboardings").
+			Select("member_onboardings.*, users.username, users.first_name, users.last_name, users.email").
+			Joins("JOIN users ON users.id = member_onboardings.user_id AND users.deleted_at IS NULL").
+			Joins("JOIN user_groups ON user_groups.user_id = member_onboardings.user_id AND user_groups.group_id = member_onboardings.group_id").
+			Where("member_onboardings.group_id = ?", gid).
+			Order("member_onboardings.created_at DESC, member_onboardings.id DESC").
+			Scan(&rows).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to load onboarding members", err)
+			respondInternalError(c, "Failed to load onboarding report")
+			return
+		}
+		userIDs := make([]uint, len(rows))
+		for i, row := range rows {
+			userIDs[i] = row.UserID
+		}
+		progress, err := onboardingProgress(db, uint(gid), items, userIDs)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to evaluate onboarding", err)
+			respondInternalError(c, "Failed to load onboarding report")
+			return
+		}
+
+		report := OnboardingReport{Items: items, Members: []OnboardingMember{}}
+		for _, row := range rows {
+			member := OnboardingMember{
+				UserID: row.UserID, Username: row.Username, FirstName: row.FirstName, LastName: row.LastName, Email: row.Email,
+				StartedAt: row.CreatedAt, WelcomeEmailSentAt: row.WelcomeEmailSentAt, PendingItemIDs: []uint{},
+			}
+			for _, item := range progress[row.UserID] {
+				if item.Complete {
+					member.CompletedCount++
+				} else {
+					member.PendingItemIDs = append(member.PendingItemIDs, item.ID)
+				}
+			}
+			member.Complete = len(member.PendingItemIDs) == 0
+			if member.Complete {
+				report.CompleteCount++
+			} else {
+				report.IncompleteCount++
+			}
+			if status == "" || (status == "complete") == member.Complete {
+				report.Members = append(report.Members, member)
+			}
+		}
+		slices.SortStableFunc(report.Members, func(a, b OnboardingMember) int {
+			return a.CompletedCount - b.CompletedCount
+		})
+		respondOK(c, report)
+	}
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnboarding(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "coordinator", "coordinator@example.com", "password123", false)
+	veteran := CreateTestUser(t, db, "veteran", "veteran@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "Dog group")
+	AddUserToGroupWithAdmin(t, db, admin.ID, group.ID, true)
+	AddUserToGroupWithAdmin(t, db, veteran.ID, group.ID, false)
+	groupParam := gin.Param{Key: "id", Value: fmt.Sprint(group.ID)}
+
+	createItem := func(userID uint, req OnboardingItemRequest) (int, models.OnboardingItem) {
+		c, w := accountTestContext(userID, false, http.MethodPost, "/", req)
+		c.Params = gin.Params{groupParam}
+		CreateOnboardingItem(db)(c)
+		var item models.OnboardingItem
+		_ = json.Unmarshal(w.Body.Bytes(), &item)
+		return w.Code, item
+	}
+	code, _ := createItem(veteran.ID, OnboardingItemRequest{Kind: models.OnboardingTask, Label: "Watch the video"})
+	assert.Equal(t, http.StatusForbidden, code, "members can't edit the checklist")
+	code, _ = createItem(admin.ID, OnboardingItemRequest{Kind: models.OnboardingTask})
+	assert.Equal(t, http.StatusBadRequest, code, "task items need a label")
+	code, _ = createItem(admin.ID, OnboardingItemRequest{Kind: "quiz", Label: "Quiz"})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, protocols := createItem(admin.ID, OnboardingItemRequest{Kind: models.OnboardingProtocols, OrderIndex: 1})
+	require.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, "Read the group's protocols", protocols.Label)
+	code, profile := createItem(admin.ID, OnboardingItemRequest{Kind: models.OnboardingProfile, OrderIndex: 2})
+	require.Equal(t, http.StatusCreated, code)
+	code, video := createItem(admin.ID, OnboardingItemRequest{Kind: models.OnboardingTask, Label: "Watch the safety video", OrderIndex: 3})
+	require.Equal(t, http.StatusCreated, code)
+	code, orientation := createItem(admin.ID, OnboardingItemRequest{Kind: models.OnboardingConfirmed, Label: "Attend orientation", OrderIndex: 4})
+	require.Equal(t, http.StatusCreated, code)
+
+	protocol := models.Protocol{GroupID: group.ID, Title: "Leashes", Content: "Always", RequiresAcknowledgment: true}
+	require.NoError(t, db.Create(&protocol).Error)
+
+	// Joining after the checklist exists starts onboarding and queues one welcome email
+	newbie := CreateTestUser(t, db, "newbie", "newbie@example.com", "password123", false)
+	AddUserToGroupWithAdmin(t, db, newbie.ID, group.ID, false)
+	started, err := startGroupOnboarding(db, group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, started, "only the new member starts; the veteran and group admin don't")
+	started, err = startGroupOnboarding(db, group.ID)
+	require.NoError(t, err)
+	assert.Zero(t, started)
+	var queued int64
+	require.NoError(t, db.Model(&models.Job{}).Where("type = ?", JobOnboardingWelcomeEmail).Count(&queued).Error)
+	assert.Equal(t, int64(1), queued)
+
+	mine := func(userID uint) OnboardingChecklist {
+		c, w := accountTestContext(userID, false, http.MethodGet, "/", nil)
+		c.Params = gin.Params{groupParam}
+		GetMyOnboarding(db)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var checklist OnboardingChecklist
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &checklist))
+		return checklist
+	}
+	checklist := mine(newbie.ID)
+	assert.True(t, checklist.Required)
+	assert.False(t, checklist.Complete)
+	require.Len(t, checklist.Items, 4)
+	assert.Equal(t, protocols.ID, checklist.Items[0].ID)
+	assert.False(t, mine(veteran.ID).Required)
+
+	complete := func(method string, userID uint, item models.OnboardingItem, body any) int {
+		c, w := accountTestContext(userID, false, method, "/", body)
+		c.Params = gin.Params{groupParam, {Key: "itemId", Value: fmt.Sprint(item.ID)}}
+		if method == http.MethodDelete {
+			UncompleteOnboardingItem(db)(c)
+		} else {
+			CompleteOnboardingItem(db)(c)
+		}
+		return w.Code
+	}
+	assert.Equal(t, http.StatusOK, complete(http.MethodPost, newbie.ID, video, nil))
+	assert.Equal(t, http.StatusOK, complete(http.MethodPost, newbie.ID, video, nil), "completing twice is a no-op")
+	assert.Equal(t, http.StatusForbidden, complete(http.MethodPost, newbie.ID, orientation, nil), "a group admin confirms orientation")
+	assert.Equal(t, http.StatusForbidden, complete(http.MethodPost, newbie.ID, video, OnboardingCompleteRequest{UserID: veteran.ID}))
+	assert.Equal(t, http.StatusBadRequest, complete(http.MethodPost, newbie.ID, profile, nil), "profile is checked automatically")
+	assert.Equal(t, http.StatusOK, complete(http.MethodPost, admin.ID, orientation, OnboardingCompleteRequest{UserID: newbie.ID}))
+	assert.Equal(t, http.StatusNotFound, complete(http.MethodPost, admin.ID, orientation, OnboardingCompleteRequest{UserID: 9999}))
+
+	report := func(query string) OnboardingReport {
+		c, w := accountTestContext(admin.ID, false, http.MethodGet, "/?"+query, nil)
+		c.Params = gin.Params{groupParam}
+		GetOnboardingReport(db)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var r OnboardingReport
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &r))
+		return r
+	}
+	r := report("")
+	require.Len(t, r.Members, 1)
+	assert.Equal(t, newbie.ID, r.Members[0].UserID)
+	assert.Equal(t, 2, r.Members[0].CompletedCount)
+	assert.Equal(t, []uint{protocols.ID, profile.ID}, r.Members[0].PendingItemIDs)
+
+	// Automatic items follow the member's protocol acknowledgments and profile
+	require.NoError(t, db.Create(&models.ProtocolAcknowledgment{ProtocolID: protocol.ID, Version: protocol.Version, UserID: newbie.ID}).Error)
+	require.NoError(t, db.Model(newbie).Updates(map[string]interface{}{"first_name": "New", "last_name": "Bie", "phone_number": "555-0100"}).Error)
+	assert.True(t, mine(newbie.ID).Complete)
+	r = report("status=complete")
+	assert.Equal(t, 1, r.CompleteCount)
+	require.Len(t, r.Members, 1)
+	assert.Empty(t, report("status=incomplete").Members)
+
+	// A new protocol version reopens the protocols item
+	require.NoError(t, db.Model(&protocol).Update("version", 2).Error)
+	assert.False(t, mine(newbie.ID).Complete)
+
+	assert.Equal(t, http.StatusOK, complete(http.MethodDelete, newbie.ID, video, nil))
+	assert.False(t, mine(newbie.ID).Items[2].Complete)
+
+	c, w := accountTestContext(admin.ID, false, http.MethodDelete, "/", nil)
+	c.Params = gin.Params{groupParam, {Key: "itemId", Value: fmt.Sprint(orientation.ID)}}
+	DeleteOnboardingItem(db)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var completions int64
+	require.NoError(t, db.Model(&models.OnboardingCompletion{}).Where("item_id = ?", orientation.ID).Count(&completions).Error)
+	assert.Zero(t, completions, "deleting an item drops its completions")
+	assert.Len(t, mine(newbie.ID).Items, 3)
+}
+
+func TestOnboarding_StartsMembersAddedByHandlers(t *testing.T) {
+	db := SetupTestDB(t)
+	siteAdmin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	coordinator := CreateTestUser(t, db, "coordinator", "coordinator@example.com", "password123", false)
+	byGroupAdmin := CreateTestUser(t, db, "walker", "walker@example.com", "password123", false)
+	bySiteAdmin := CreateTestUser(t, db, "fosterer", "fosterer@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "Dog group")
+	AddUserToGroupWithAdmin(t, db, coordinator.ID, group.ID, true)
+	require.NoError(t, db.Create(&models.OnboardingItem{GroupID: group.ID, Kind: models.OnboardingTask, Label: "Watch the safety video"}).Error)
+
+	c, w := accountTestContext(coordinator.ID, false, http.MethodPost, "/", nil)
+	c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}, {Key: "userId", Value: fmt.Sprint(byGroupAdmin.ID)}}
+	AddMemberToGroup(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	c, w = accountTestContext(siteAdmin.ID, true, http.MethodPost, "/", nil)
+	c.Params = gin.Params{{Key: "userId", Value: fmt.Sprint(bySiteAdmin.ID)}, {Key: "groupId", Value: fmt.Sprint(group.ID)}}
+	AddUserToGroup(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var membership models.UserGroup
+	require.NoError(t, db.Where("user_id = ? AND group_id = ?", byGroupAdmin.ID, group.ID).First(&membership).Error)
+	assert.False(t, membership.CreatedAt.IsZero(), "the join time is recorded")
+	assert.Equal(t, models.GroupRoleVolunteer, membership.Role)
+
+	started, err := startGroupOnboarding(db, group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, started)
+}
@@ -0,0 +1,558 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrCodeOrganizationMismatch is returned when a change would connect a
+// user and a group in different organizations
+const ErrCodeOrganizationMismatch ErrorCode = "ORGANIZATION_MISMATCH"
+
+// errMixedOrganizations is returned for groups that span organizations
+var errMixedOrganizations = errors.New("groups belong to different organizations")
+
+// organizationSettingKeys are the site settings an organization can
+// override; the rest (security, image limits) are instance-wide
+var organizationSettingKeys = map[string]bool{
+	"site_name":        true,
+	"site_short_name":  true,
+	"site_description": true,
+	"hero_image_url":   true,
+}
+
+// OrganizationRequest creates or renames an organization
+type OrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+	Slug string `json:"slug" binding:"required,max=60"`
+}
+
+// OrganizationAssignmentRequest moves a group or user into an organization,
+// or out of all organizations when OrganizationID is nil
+type OrganizationAssignmentRequest struct {
+	OrganizationID *uint `json:"organization_id"`
+	IsOrgAdmin     bool  `json:"is_org_admin"` // Users only
+}
+
+// OrganizationSummary is an organization with its group and user counts
+type OrganizationSummary struct {
+	models.Organization
+	GroupCount int64 `json:"group_count"`
+	UserCount  int64 `json:"user_count"`
+}
+
+// OrganizationResponse is an organization, its groups, and its setting
+// overrides
+type OrganizationResponse struct {
+	models.Organization
+	Groups   []models.Group    `json:"groups"`
+	Settings map[string]string `json:"settings"`
+}
+
+// isOrgAdminForGroup reports whether userID administers the organization
+// that owns groupID
+func isOrgAdminForGroup(db *gorm.DB, userID interface{}, groupID interface{}) bool {
+	var count int64
+	db.Model(&models.User{}).
+		Joins("JOIN groups ON groups.organization_id = users.organization_id AND groups.deleted_at IS NULL").
+		Where("users.id = ? AND users.is_org_admin = ? AND groups.id = ?", userID, true, groupID).
+		Count(&count)
+	return count > 0
+}
+
+// organizationOfGroups returns the organization that owns all of groupIDs,
+// or errMixedOrganizations if they span more than one
+func organizationOfGroups(db *gorm.DB, groupIDs []uint) (*uint, error) {
+	if len(groupIDs) == 0 {
+		return nil, nil
+	}
+	var groups []models.Group
+	if err := db.Select("id", "organization_id").Where("id IN ?", groupIDs).Find(&groups).Error; err != nil {
+		return nil, err
+	}
+	var orgID *uint
+	for i, g := range groups {
+		if i > 0 && !middleware.SameOrganization(orgID, g.OrganizationID) {
+			return nil, errMixedOrganizations
+		}
+		orgID = g.OrganizationID
+	}
+	return orgID, nil
+}
+
+// checkGroupsInUserOrganization responds 409 and returns false unless every
+// one of groupIDs is in user's organization
+func checkGroupsInUserOrganization(c *gin.Context, db *gorm.DB, user models.User, groupIDs []uint) bool {
+	var groups []models.Group
+	if err := db.Select("id", "organization_id").Where("id IN ?", groupIDs).Find(&groups).Error; err != nil {
+		respondInternalError(c, "Failed to look up groups")
+		return false
+	}
+	for _, g := range groups {
+		if !middleware.SameOrganization(user.OrganizationID, g.OrganizationID) {
+			respondError(c, http.StatusConflict, ErrCodeOrganizationMismatch,
+				fmt.Sprintf("Group %d belongs to a different organization than the user", g.ID))
+			return false
+		}
+	}
+	return true
+}
+
+// bindOrganization validates an OrganizationRequest
+func bindOrganization(c *gin.Context) (OrganizationRequest, bool) {
+	var req OrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return req, false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	req.Slug = strings.ToLower(strings.TrimSpace(req.Slug))
+	if req.Name == "" {
+		respondBadRequest(c, "Name is required")
+		return req, false
+	}
+	if !groupSlugPattern.MatchString(req.Slug) {
+		respondBadRequest(c, "Slug must be lowercase letters, numbers, and single hyphens")
+		return req, false
+	}
+	return req, true
+}
+
+// organizationNameTaken reports whether another organization uses name or slug
+func organizationNameTaken(db *gorm.DB, req OrganizationRequest, exceptID uint) (bool, error) {
+	var count int64
+	err := db.Model(&models.Organization{}).
+		Where("(LOWER(name) = ? OR slug = ?) AND id <> ?", strings.ToLower(req.Name), req.Slug, exceptID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// organizationSettings returns an organization's setting overrides by key
+func organizationSettings(db *gorm.DB, orgID uint) (map[string]string, error) {
+	var settings []models.OrganizationSetting
+	if err := db.Where("organization_id = ?", orgID).Find(&settings).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[string]string, len(settings))
+	for _, s := range settings {
+		out[s.Key] = s.Value
+	}
+	return out, nil
+}
+
+// GetOrganizations lists the instance's organizations (admin only)
+// Route: GET /api/admin/organizations
+func GetOrganizations(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		var orgs []models.Organization
+		if err := db.Order("name").Find(&orgs).Error; err != nil {
+			respondInternalError(c, "Failed to fetch organizations")
+			return
+		}
+		summaries := make([]OrganizationSummary, len(orgs))
+		for i, org := range orgs {
+			summaries[i].Organization = org
+			if err := db.Model(&models.Group{}).Where("organization_id = ?", org.ID).Count(&summaries[i].GroupCount).Error; err != nil {
+				respondInternalError(c, "Failed to fetch organizations")
+				return
+			}
+			if err := db.Model(&models.User{}).Where("organization_id = ?", org.ID).Count(&summaries[i].UserCount).Error; err != nil {
+				respondInternalError(c, "Failed to fetch organizations")
+				return
+			}
+		}
+		respondOK(c, summaries)
+	}
+}
+
+// CreateOrganization creates an organization (admin only)
+// Route: POST /api/admin/organizations
+func CreateOrganization(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		req, ok := bindOrganization(c)
+		if !ok {
+			return
+		}
+		taken, err := organizationNameTaken(db, req, 0)
+		if err != nil {
+			respondInternalError(c, "Failed to create organization")
+			return
+		}
+		if taken {
+			respondError(c, http.StatusConflict, ErrCodeConflict, "An organization with that name or slug already exists")
+			return
+		}
+
+		org := models.Organization{Name: req.Name, Slug: req.Slug}
+		if err := db.Create(&org).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to create organization", err)
+			respondInternalError(c, "Failed to create organization")
+			return
+		}
+		uid, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventOrganizationChanged, uid, map[string]interface{}{
+			"action":          "create",
+			"organization_id": org.ID,
+			"name":            org.Name,
+		})
+		c.JSON(http.StatusCreated, org)
+	}
+}
+
+// UpdateOrganization renames an organization (admin only)
+// Route: PUT /api/admin/organizations/:orgId
+func UpdateOrganization(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		var org models.Organization
+		if err := db.First(&org, c.Param("orgId")).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeNotFound, "Organization not found")
+			return
+		}
+		req, ok := bindOrganization(c)
+		if !ok {
+			return
+		}
+		taken, err := organizationNameTaken(db, req, org.ID)
+		if err != nil {
+			respondInternalError(c, "Failed to update organization")
+			return
+		}
+		if taken {
+			respondError(c, http.StatusConflict, ErrCodeConflict, "An organization with that name or slug already exists")
+			return
+		}
+
+		if err := db.Model(&org).Updates(map[string]interface{}{"name": req.Name, "slug": req.Slug}).Error; err != nil {
+			respondInternalError(c, "Failed to update organization")
+			return
+		}
+		uid, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventOrganizationChanged, uid, map[string]interface{}{
+			"action":          "update",
+			"organization_id": org.ID,
+			"name":            org.Name,
+		})
+		respondOK(c, org)
+	}
+}
+
+// DeleteOrganization deletes an organization that has no groups or users
+// left (admin only)
+// Route: DELETE /api/admin/organizations/:orgId
+func DeleteOrganization(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		var org models.Organization
+		if err := db.First(&org, c.Param("orgId")).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeNotFound, "Organization not found")
+			return
+		}
+		var groups, users int64
+		if err := db.Model(&models.Group{}).Where("organization_id = ?", org.ID).Count(&groups).Error; err != nil {
+			respondInternalError(c, "Failed to delete organization")
+			return
+		}
+		if err := db.Model(&models.User{}).Where("organization_id = ?", org.ID).Count(&users).Error; err != nil {
+			respondInternalError(c, "Failed to delete organization")
+			return
+		}
+		if groups > 0 || users > 0 {
+			respondError(c, http.StatusConflict, ErrCodeConflict, "Move the organization's groups and users out before deleting it")
+			return
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("organization_id = ?", org.ID).Delete(&models.OrganizationSetting{}).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&org).Error
+		})
+		if err != nil {
+			respondInternalError(c, "Failed to delete organization")
+			return
+		}
+		uid, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventOrganizationChanged, uid, map[string]interface{}{
+			"action":          "delete",
+			"organization_id": org.ID,
+			"name":            org.Name,
+		})
+		respondOK(c, gin.H{"message": "Organization deleted"})
+	}
+}
+
+// assignmentOrganization binds an OrganizationAssignmentRequest and checks
+// that its organization exists
+func assignmentOrganization(c *gin.Context, db *gorm.DB) (OrganizationAssignmentRequest, bool) {
+	var req OrganizationAssignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return req, false
+	}
+	if req.OrganizationID != nil {
+		if err := db.First(&models.Organization{}, *req.OrganizationID).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeNotFound, "Organization not found")
+			return req, false
+		}
+	}
+	return req, true
+}
+
+// SetGroupOrganization moves a group into an organization, or out of all
+// organizations (admin only). The group's members must already be in that
+// organization, so the move can't expose it to another organization's users.
+// Route: PUT /api/admin/groups/:id/organization
+func SetGroupOrganization(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		var group models.Group
+		if err := db.First(&group, c.Param("id")).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, "Group not found")
+			return
+		}
+		req, ok := assignmentOrganization(c, db)
+		if !ok {
+			return
+		}
+
+		members := db.Model(&models.User{}).
+			Joins("JOIN user_groups ON user_groups.user_id = users.id").
+			Where("user_groups.group_id = ?", group.ID)
+		if req.OrganizationID == nil {
+			members = members.Where("users.organization_id IS NOT NULL")
+		} else {
+			members = members.Where("users.organization_id IS NULL OR users.organization_id <> ?", *req.OrganizationID)
+		}
+		var outside int64
+		if err := members.Count(&outside).Error; err != nil {
+			respondInternalError(c, "Failed to update group")
+			return
+		}
+		if outside > 0 {
+			respondError(c, http.StatusConflict, ErrCodeOrganizationMismatch,
+				fmt.Sprintf("%d of the group's members are in a different organization; move or remove them first", outside))
+			return
+		}
+
+		if err := db.Model(&group).Update("organization_id", req.OrganizationID).Error; err != nil {
+			respondInternalError(c, "Failed to update group")
+			return
+		}
+		group.OrganizationID = req.OrganizationID
+		uid, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventOrganizationChanged, uid, map[string]interface{}{
+			"action":          "assign_group",
+			"organization_id": req.OrganizationID,
+			"group_id":        group.ID,
+		})
+		respondOK(c, toAdminGroupResponse(group))
+	}
+}
+
+// SetUserOrganization moves a user into an organization, or out of all
+// organizations, and sets whether they administer it (admin only). The
+// user's groups must already be in that organization.
+// Route: PUT /api/admin/users/:userId/organization
+func SetUserOrganization(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		var user models.User
+		if err := db.First(&user, c.Param("userId")).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeUserNotFound, "User not found")
+			return
+		}
+		req, ok := assignmentOrganization(c, db)
+		if !ok {
+			return
+		}
+		if req.OrganizationID == nil && req.IsOrgAdmin {
+			respondBadRequest(c, "An organization admin needs an organization")
+			return
+		}
+
+		var groupIDs []uint
+		if err := db.Model(&models.UserGroup{}).Where("user_id = ?", user.ID).Pluck("group_id", &groupIDs).Error; err != nil {
+			respondInternalError(c, "Failed to update user")
+			return
+		}
+		moved := user
+		moved.OrganizationID = req.OrganizationID
+		if !checkGroupsInUserOrganization(c, db, moved, groupIDs) {
+			return
+		}
+
+		if err := db.Model(&user).Updates(map[string]interface{}{
+			"organization_id": req.OrganizationID,
+			"is_org_admin":    req.IsOrgAdmin,
+		}).Error; err != nil {
+			respondInternalError(c, "Failed to update user")
+			return
+		}
+		user.OrganizationID, user.IsOrgAdmin = req.OrganizationID, req.IsOrgAdmin
+		uid, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventOrganizationChanged, uid, map[string]interface{}{
+			"action":          "assign_user",
+			"organization_id": req.OrganizationID,
+			"target_user_id":  user.ID,
+			"is_org_admin":    req.IsOrgAdmin,
+		})
+		respondOK(c, toAdminUserResponse(user))
+	}
+}
+
+// GetMyOrganization returns the current user's organization, its groups,
+// and its setting overrides
+// Route: GET /api/organization
+func GetMyOrganization(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		orgID := middleware.GetOrganizationID(c)
+		if orgID == nil {
+			respondError(c, http.StatusNotFound, ErrCodeNotFound, "You aren't in an organization")
+			return
+		}
+		var org models.Organization
+		if err := db.First(&org, *orgID).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeNotFound, "Organization not found")
+			return
+		}
+		resp := OrganizationResponse{Organization: org, Groups: []models.Group{}}
+		if err := db.Where("organization_id = ?", org.ID).Order("name").Find(&resp.Groups).Error; err != nil {
+			respondInternalError(c, "Failed to fetch organization")
+			return
+		}
+		settings, err := organizationSettings(db, org.ID)
+		if err != nil {
+			respondInternalError(c, "Failed to fetch organization")
+			return
+		}
+		resp.Settings = settings
+		respondOK(c, resp)
+	}
+}
+
+// orgAdminOrganization returns the organization the current user
+// administers, responding 403 if there isn't one
+func orgAdminOrganization(c *gin.Context) (uint, bool) {
+	orgID := middleware.GetOrganizationID(c)
+	if orgID == nil || !middleware.GetIsOrgAdmin(c) {
+		respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Organization admin access required")
+		return 0, false
+	}
+	return *orgID, true
+}
+
+// GetOrganizationUsers lists the users in the current user's organization
+// (organization admin only)
+// Route: GET /api/organization/users
+func GetOrganizationUsers(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		orgID, ok := orgAdminOrganization(c)
+		if !ok {
+			return
+		}
+		var users []models.User
+		if err := db.Preload("Groups", activeGroupsPreload).Where("organization_id = ?", orgID).Order("username").Find(&users).Error; err != nil {
+			respondInternalError(c, "Failed to fetch users")
+			return
+		}
+		out := make([]adminUserResponse, len(users))
+		for i, u := range users {
+			out[i] = toAdminUserResponse(u)
+		}
+		respondOK(c, out)
+	}
+}
+
+// orgSettingOrganization returns the organization an organization setting
+// route changes: :orgId for site admins, the user's own otherwise
+func orgSettingOrganization(c *gin.Context, db *gorm.DB) (uint, bool) {
+	if c.Param("orgId") == "" {
+		return orgAdminOrganization(c)
+	}
+	orgID, err := strconv.ParseUint(c.Param("orgId"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid organization ID")
+		return 0, false
+	}
+	if err := db.First(&models.Organization{}, orgID).Error; err != nil {
+		respondError(c, http.StatusNotFound, ErrCodeNotFound, "Organization not found")
+		return 0, false
+	}
+	return uint(orgID), true
+}
+
+// UpdateOrganizationSetting overrides a site setting for an organization
+// (organization admin, or site admin through the admin route). Only the
+// site name, short name, description, and hero image can be overridden.
+// Route: PUT /api/organization/settings/:key
+// Route: PUT /api/admin/organizations/:orgId/settings/:key
+func UpdateOrganizationSetting(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		orgID, ok := orgSettingOrganization(c, db)
+		if !ok {
+			return
+		}
+		key := c.Param("key")
+		if !organizationSettingKeys[key] {
+			respondBadRequest(c, "Organizations can only set site_name, site_short_name, site_description, and hero_image_url")
+			return
+		}
+		var req struct {
+			Value string `json:"value"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		rules := settingValidationRules[key]
+		if rules.required && strings.TrimSpace(req.Value) == "" {
+			respondBadRequest(c, fmt.Sprintf("%s is required", key))
+			return
+		}
+		if len(req.Value) > rules.maxLen {
+			respondBadRequest(c, fmt.Sprintf("%s must be %d characters or less", key, rules.maxLen))
+			return
+		}
+
+		setting := models.OrganizationSetting{OrganizationID: orgID, Key: key}
+		if err := db.Where(&setting).Assign(models.OrganizationSetting{Value: req.Value}).FirstOrCreate(&setting).Error; err != nil {
+			respondInternalError(c, "Failed to save setting")
+			return
+		}
+		respondOK(c, setting)
+	}
+}
+
+// DeleteOrganizationSetting removes an organization's override, so the
+// site setting applies again
+// Route: DELETE /api/organization/settings/:key
+// Route: DELETE /api/admin/organizations/:orgId/settings/:key
+func DeleteOrganizationSetting(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		orgID, ok := orgSettingOrganization(c, db)
+		if !ok {
+			return
+		}
+		if err := db.Where("organization_id = ? AND key = ?", orgID, c.Param("key")).Delete(&models.OrganizationSetting{}).Error; err != nil {
+			respondInternalError(c, "Failed to delete setting")
+			return
+		}
+		respondOK(c, gin.H{"message": "Setting reset to the site default"})
+	}
+}
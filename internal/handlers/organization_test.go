@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizations(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	orgAdmin := CreateTestUser(t, db, "orgadmin", "orgadmin@example.com", "password123", false)
+	volunteer := CreateTestUser(t, db, "volunteer", "volunteer@example.com", "password123", false)
+	dogs := CreateTestGroup(t, db, "Dogs", "")
+	cats := CreateTestGroup(t, db, "Cats", "")
+
+	call := func(handler gin.HandlerFunc, userID uint, isAdmin bool, method string, params gin.Params, body interface{}) (int, []byte) {
+		c, w := accountTestContext(userID, isAdmin, method, "/", body)
+		c.Params = params
+		var user models.User
+		require.NoError(t, db.First(&user, userID).Error)
+		c.Set("organization_id", user.OrganizationID)
+		c.Set("is_org_admin", user.IsOrgAdmin)
+		handler(c)
+		return w.Code, w.Body.Bytes()
+	}
+
+	code, body := call(CreateOrganization(db), admin.ID, true, http.MethodPost, nil, gin.H{"name": "North Shelter", "slug": "north"})
+	require.Equal(t, http.StatusCreated, code, string(body))
+	var north models.Organization
+	require.NoError(t, json.Unmarshal(body, &north))
+	code, _ = call(CreateOrganization(db), admin.ID, true, http.MethodPost, nil, gin.H{"name": "north shelter", "slug": "other"})
+	assert.Equal(t, http.StatusConflict, code)
+	code, _ = call(CreateOrganization(db), admin.ID, true, http.MethodPost, nil, gin.H{"name": "South", "slug": "Not A Slug!"})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	orgParam := gin.H{"organization_id": north.ID}
+	code, _ = call(SetGroupOrganization(db), admin.ID, true, http.MethodPut, gin.Params{{Key: "id", Value: itoa(dogs.ID)}}, orgParam)
+	require.Equal(t, http.StatusOK, code)
+	code, _ = call(SetUserOrganization(db), admin.ID, true, http.MethodPut, gin.Params{{Key: "userId", Value: itoa(orgAdmin.ID)}},
+		gin.H{"organization_id": north.ID, "is_org_admin": true})
+	require.Equal(t, http.StatusOK, code)
+
+	t.Run("users and groups can't cross organizations", func(t *testing.T) {
+		code, body := call(AddUserToGroup(db), admin.ID, true, http.MethodPost,
+			gin.Params{{Key: "userId", Value: itoa(volunteer.ID)}, {Key: "groupId", Value: itoa(dogs.ID)}}, nil)
+		assert.Equal(t, http.StatusConflict, code, string(body))
+
+		AddUserToGroupWithAdmin(t, db, volunteer.ID, cats.ID, false)
+		code, _ = call(SetGroupOrganization(db), admin.ID, true, http.MethodPut, gin.Params{{Key: "id", Value: itoa(cats.ID)}}, orgParam)
+		assert.Equal(t, http.StatusConflict, code, "cats has a member outside the organization")
+		code, _ = call(SetUserOrganization(db), admin.ID, true, http.MethodPut, gin.Params{{Key: "userId", Value: itoa(volunteer.ID)}}, orgParam)
+		assert.Equal(t, http.StatusConflict, code, "volunteer is in a group outside the organization")
+	})
+
+	t.Run("organization admins administer their organization's groups only", func(t *testing.T) {
+		assert.True(t, checkGroupAdminAccess(db, orgAdmin.ID, false, itoa(dogs.ID)))
+		assert.True(t, checkGroupAccess(db, orgAdmin.ID, false, itoa(dogs.ID)))
+		assert.False(t, checkGroupAccess(db, orgAdmin.ID, false, itoa(cats.ID)))
+
+		code, body := call(GetGroups(db), orgAdmin.ID, false, http.MethodGet, nil, nil)
+		require.Equal(t, http.StatusOK, code)
+		var groups []models.Group
+		require.NoError(t, json.Unmarshal(body, &groups))
+		require.Len(t, groups, 1)
+		assert.Equal(t, dogs.ID, groups[0].ID)
+	})
+
+	t.Run("organization settings override the site's", func(t *testing.T) {
+		require.NoError(t, db.Create(&models.SiteSetting{Key: "site_name", Value: "Shared Instance"}).Error)
+
+		code, _ := call(UpdateOrganizationSetting(db), volunteer.ID, false, http.MethodPut, gin.Params{{Key: "key", Value: "site_name"}}, gin.H{"value": "Nope"})
+		assert.Equal(t, http.StatusForbidden, code)
+		code, _ = call(UpdateOrganizationSetting(db), orgAdmin.ID, false, http.MethodPut, gin.Params{{Key: "key", Value: "cors_allowed_origins"}}, gin.H{"value": "*"})
+		assert.Equal(t, http.StatusBadRequest, code, "security settings stay instance-wide")
+		code, body := call(UpdateOrganizationSetting(db), orgAdmin.ID, false, http.MethodPut, gin.Params{{Key: "key", Value: "site_name"}}, gin.H{"value": "North Shelter"})
+		require.Equal(t, http.StatusOK, code, string(body))
+
+		settings := func(query string) map[string]string {
+			c, w := accountTestContext(0, false, http.MethodGet, "/api/settings"+query, nil)
+			GetSiteSettings(db)(c)
+			require.Equal(t, http.StatusOK, w.Code)
+			var out map[string]string
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &out))
+			return out
+		}
+		assert.Equal(t, "Shared Instance", settings("")["site_name"])
+		assert.Equal(t, "North Shelter", settings("?organization=north")["site_name"])
+
+		code, body = call(GetMyOrganization(db), orgAdmin.ID, false, http.MethodGet, nil, nil)
+		require.Equal(t, http.StatusOK, code)
+		var org OrganizationResponse
+		require.NoError(t, json.Unmarshal(body, &org))
+		assert.Equal(t, "North Shelter", org.Settings["site_name"])
+		assert.Len(t, org.Groups, 1)
+	})
+
+	t.Run("organizations with groups can't be deleted", func(t *testing.T) {
+		code, _ := call(DeleteOrganization(db), admin.ID, true, http.MethodDelete, gin.Params{{Key: "orgId", Value: itoa(north.ID)}}, nil)
+		assert.Equal(t, http.StatusConflict, code)
+	})
+}
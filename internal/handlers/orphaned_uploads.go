@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/maintenance"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"gorm.io/gorm"
+)
+
+// PurgeOrphanedUploadsRequest optionally limits a purge to some files
+type PurgeOrphanedUploadsRequest struct {
+	// Files are names from the orphaned upload list; empty purges them all
+	Files []string `json:"files"`
+}
+
+// GetOrphanedUploads lists files in the uploads directory that nothing
+// references, older than the grace period (site admin only)
+// Route: GET /api/admin/orphaned-uploads
+func GetOrphanedUploads(db *gorm.DB, cfg maintenance.OrphanedUploadConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		orphans, err := maintenance.FindOrphanedUploads(db, cfg, time.Now())
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to find orphaned uploads", err)
+			respondInternalError(c, "Failed to find orphaned uploads")
+			return
+		}
+		var totalBytes int64
+		for _, orphan := range orphans {
+			totalBytes += orphan.Size
+		}
+		respondOK(c, gin.H{
+			"config":      cfg,
+			"files":       orphans,
+			"total_bytes": totalBytes,
+		})
+	}
+}
+
+// PurgeOrphanedUploads deletes orphaned uploads now, all of them or the
+// listed files (site admin only). Files referenced since they were listed
+// are kept. The purge is written to the audit log.
+// Route: POST /api/admin/orphaned-uploads/purge
+func PurgeOrphanedUploads(db *gorm.DB, cfg maintenance.OrphanedUploadConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		var req PurgeOrphanedUploadsRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				respondBadRequest(c, "Invalid request body")
+				return
+			}
+		}
+
+		adminID, _ := middleware.GetUserID(c)
+		result, err := maintenance.PurgeOrphanedUploads(c.Request.Context(), db, cfg, req.Files, adminID, time.Now())
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to purge orphaned uploads", err)
+			respondInternalError(c, "Failed to purge orphaned uploads")
+			return
+		}
+		respondOK(c, result)
+	}
+}
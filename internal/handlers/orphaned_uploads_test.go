@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/maintenance"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrphanedUploadConfigFromEnv(t *testing.T) {
+	t.Setenv("UPLOADS_DIR", "/srv/uploads")
+	t.Setenv("ORPHANED_UPLOADS_GRACE_DAYS", "0")
+	t.Setenv("ORPHANED_UPLOADS_AUTO_PURGE", "true")
+	cfg := maintenance.OrphanedUploadConfigFromEnv()
+	assert.Equal(t, "/srv/uploads", cfg.Dir)
+	assert.Equal(t, 7, cfg.GraceDays, "grace period must be at least a day")
+	assert.True(t, cfg.AutoPurge)
+}
+
+func TestOrphanedUploads(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	group := CreateTestGroup(t, db, "Dogs", "Dog group")
+	animal := CreateTestAnimal(t, db, group.ID, "Buddy", "Dog")
+
+	dir := t.TempDir()
+	old := time.Now().AddDate(0, 0, -30)
+	write := func(name string, modified time.Time) {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte("image bytes"), 0o644))
+		require.NoError(t, os.Chtimes(p, modified, modified))
+	}
+	write("animal.jpg", old)
+	write("setting.png", old)
+	write("replaced.jpg", old)
+	write("groups/old-hero.jpg", old)
+	write("just-uploaded.jpg", time.Now())
+	write(".gitkeep", old)
+
+	require.NoError(t, db.Model(animal).Update("image_url", "/uploads/animal.jpg").Error)
+	require.NoError(t, db.Create(&models.SiteSetting{Key: "hero_images", Value: `["https://example.org/uploads/setting.png"]`}).Error)
+
+	cfg := maintenance.OrphanedUploadConfig{Dir: dir, GraceDays: 7}
+	list := func() []maintenance.OrphanedUpload {
+		c, w := accountTestContext(admin.ID, true, http.MethodGet, "/api/admin/orphaned-uploads", nil)
+		GetOrphanedUploads(db, cfg)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp struct {
+			Files      []maintenance.OrphanedUpload `json:"files"`
+			TotalBytes int64                        `json:"total_bytes"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.Files
+	}
+	names := func(files []maintenance.OrphanedUpload) []string {
+		out := make([]string, len(files))
+		for i, f := range files {
+			out[i] = f.Name
+		}
+		return out
+	}
+
+	files := list()
+	assert.ElementsMatch(t, []string{"replaced.jpg", "groups/old-hero.jpg"}, names(files))
+	for _, f := range files {
+		if f.Name == "groups/old-hero.jpg" {
+			assert.Equal(t, "/uploads/groups/old-hero.jpg", f.URL)
+		}
+	}
+
+	purge := func(body any) maintenance.OrphanedUploadPurge {
+		c, w := accountTestContext(admin.ID, true, http.MethodPost, "/api/admin/orphaned-uploads/purge", body)
+		PurgeOrphanedUploads(db, cfg)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var result maintenance.OrphanedUploadPurge
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		return result
+	}
+
+	// Only orphans are purged, even when other files are named
+	result := purge(gin.H{"files": []string{"replaced.jpg", "animal.jpg", "../outside.jpg"}})
+	assert.Equal(t, []string{"replaced.jpg"}, result.Deleted)
+	assert.Equal(t, int64(len("image bytes")), result.FreedBytes)
+	assert.NoFileExists(t, filepath.Join(dir, "replaced.jpg"))
+	assert.FileExists(t, filepath.Join(dir, "animal.jpg"))
+
+	// A file referenced after it was listed survives the purge
+	require.NoError(t, db.Model(&group).Update("hero_image_url", "/uploads/groups/old-hero.jpg").Error)
+	result = purge(nil)
+	assert.Empty(t, result.Deleted)
+	assert.FileExists(t, filepath.Join(dir, "groups", "old-hero.jpg"))
+	assert.FileExists(t, filepath.Join(dir, "just-uploaded.jpg"))
+	assert.FileExists(t, filepath.Join(dir, ".gitkeep"))
+	assert.Empty(t, list())
+}
@@ -27,6 +27,9 @@ type ResetPasswordRequest struct {
 type UpdateEmailPreferencesRequest struct {
 	EmailNotificationsEnabled bool `json:"email_notifications_enabled"`
 	ShowLengthOfStay          bool `json:"show_length_of_stay"`
+	// Omitted by older clients, which leave the setting unchanged
+	AnimalChangeEmailsEnabled *bool `json:"animal_change_emails_enabled"`
+	WeeklyStatsEmailsEnabled  *bool `json:"weekly_stats_emails_enabled"`
 }
 
 // generateSecureToken generates a cryptographically secure random token
@@ -161,14 +164,20 @@ func ResetPassword(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		// Update password and clear reset token and lookup
-		if err := db.Model(&targetUser).Updates(map[string]interface{}{
+		updates := map[string]interface{}{
 			"password":              hashedPassword,
 			"reset_token":           "",
 			"reset_token_lookup":    "",
 			"reset_token_expiry":    nil,
 			"failed_login_attempts": 0,
 			"locked_until":          nil,
-		}).Error; err != nil {
+			"lockout_count":         0,
+		}
+		// The token arrived by email, so the address is proven.
+		if targetUser.EmailVerifiedAt == nil {
+			updates["email_verified_at"] = time.Now()
+		}
+		if err := db.Model(&targetUser).Updates(updates).Error; err != nil {
 			logger := middleware.GetLogger(c)
 			logger.Error("Failed to update user password during reset", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
@@ -234,7 +243,7 @@ func SetupPassword(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		// Update password, clear setup token and lookup, and mark account as fully set up
-		if err := db.Model(&targetUser).Updates(map[string]interface{}{
+		updates := map[string]interface{}{
 			"password":                hashedPassword,
 			"setup_token":             "",
 			"setup_token_lookup":      "",
@@ -242,7 +251,13 @@ func SetupPassword(db *gorm.DB) gin.HandlerFunc {
 			"requires_password_setup": false, // Allow login now
 			"failed_login_attempts":   0,
 			"locked_until":            nil,
-		}).Error; err != nil {
+			"lockout_count":           0,
+		}
+		// The invite link arrived by email, so the address is proven.
+		if targetUser.EmailVerifiedAt == nil {
+			updates["email_verified_at"] = time.Now()
+		}
+		if err := db.Model(&targetUser).Updates(updates).Error; err != nil {
 			logger := middleware.GetLogger(c)
 			logger.Error("Failed to update user password during setup", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set up password"})
@@ -274,15 +289,29 @@ func UpdateEmailPreferences(db *gorm.DB) gin.HandlerFunc {
 			"email_notifications_enabled": req.EmailNotificationsEnabled,
 			"show_length_of_stay":         req.ShowLengthOfStay,
 		}
+		if req.AnimalChangeEmailsEnabled != nil {
+			updates["animal_change_emails_enabled"] = *req.AnimalChangeEmailsEnabled
+		}
+		if req.WeeklyStatsEmailsEnabled != nil {
+			updates["weekly_stats_emails_enabled"] = *req.WeeklyStatsEmailsEnabled
+		}
 		if err := db.Model(&models.User{}).Where("id = ?", userID).Updates(updates).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update preferences"})
 			return
 		}
 
+		var user models.User
+		if err := db.Select("animal_change_emails_enabled, weekly_stats_emails_enabled").First(&user, userID).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update preferences"})
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{
-			"message":                     "Preferences updated successfully",
-			"email_notifications_enabled": req.EmailNotificationsEnabled,
-			"show_length_of_stay":         req.ShowLengthOfStay,
+			"message":                      "Preferences updated successfully",
+			"email_notifications_enabled":  req.EmailNotificationsEnabled,
+			"show_length_of_stay":          req.ShowLengthOfStay,
+			"animal_change_emails_enabled": user.AnimalChangeEmailsEnabled,
+			"weekly_stats_emails_enabled":  user.WeeklyStatsEmailsEnabled,
 		})
 	}
 }
@@ -298,14 +327,16 @@ func GetEmailPreferences(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		var user models.User
-		if err := db.Select("email_notifications_enabled, show_length_of_stay").First(&user, userID).Error; err != nil {
+		if err := db.Select("email_notifications_enabled, show_length_of_stay, animal_change_emails_enabled, weekly_stats_emails_enabled").First(&user, userID).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{
-			"email_notifications_enabled": user.EmailNotificationsEnabled,
-			"show_length_of_stay":         user.ShowLengthOfStay,
+			"email_notifications_enabled":  user.EmailNotificationsEnabled,
+			"show_length_of_stay":          user.ShowLengthOfStay,
+			"animal_change_emails_enabled": user.AnimalChangeEmailsEnabled,
+			"weekly_stats_emails_enabled":  user.WeeklyStatsEmailsEnabled,
 		})
 	}
 }
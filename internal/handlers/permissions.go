@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// SitePermissions lists what the current user can do outside any one group
+type SitePermissions struct {
+	CanManageUsers             bool `json:"can_manage_users"`
+	CanManageGroups            bool `json:"can_manage_groups"`
+	CanPostSiteAnnouncements   bool `json:"can_post_site_announcements"`
+	CanSendEmergencyBroadcasts bool `json:"can_send_emergency_broadcasts"`
+	CanManageSettings          bool `json:"can_manage_settings"`
+	CanImportAnimals           bool `json:"can_import_animals"`
+	CanBulkUpdateAnimals       bool `json:"can_bulk_update_animals"`
+	CanViewStatistics          bool `json:"can_view_statistics"`
+	CanManageAPITokens         bool `json:"can_manage_api_tokens"`
+	CanReviewQuarantinedImages bool `json:"can_review_quarantined_images"`
+}
+
+// GroupPermissions lists what the current user can do in one group. Each
+// flag is computed with the access check its endpoints enforce.
+type GroupPermissions struct {
+	GroupID                      uint     `json:"group_id"`
+	GroupName                    string   `json:"group_name"`
+	Role                         string   `json:"role"`
+	IsMember                     bool     `json:"is_member"`
+	CanViewAnimals               bool     `json:"can_view_animals"`
+	CanEditAnimals               bool     `json:"can_edit_animals"`
+	CanDeleteAnimals             bool     `json:"can_delete_animals"`
+	CanComment                   bool     `json:"can_comment"`
+	CanModerateComments          bool     `json:"can_moderate_comments"`
+	CanUploadMedia               bool     `json:"can_upload_media"`
+	CanRecordWeights             bool     `json:"can_record_weights"`
+	CanViewBehaviorAssessments   bool     `json:"can_view_behavior_assessments"`
+	CanRecordBehaviorAssessments bool     `json:"can_record_behavior_assessments"`
+	CanPostAnnouncements         bool     `json:"can_post_announcements"`
+	CanSendEmergencyBroadcasts   bool     `json:"can_send_emergency_broadcasts"`
+	CanManageMembers             bool     `json:"can_manage_members"`
+	CanReviewJoinRequests        bool     `json:"can_review_join_requests"`
+	CanViewMemberActivity        bool     `json:"can_view_member_activity"`
+	CanManageSettings            bool     `json:"can_manage_settings"`
+	CanManageTags                bool     `json:"can_manage_tags"`
+	CanManageFieldVisibility     bool     `json:"can_manage_field_visibility"`
+	CanManageStatusChecklists    bool     `json:"can_manage_status_checklists"`
+	CanPinComments               bool     `json:"can_pin_comments"`
+	CanViewStatistics            bool     `json:"can_view_statistics"`
+	CanExportData                bool     `json:"can_export_data"`
+	CanManageShareLinks          bool     `json:"can_manage_share_links"`
+	HiddenFields                 []string `json:"hidden_fields"` // Animal fields left out of responses for this user
+}
+
+// PermissionsResponse is the current user's capability map
+type PermissionsResponse struct {
+	UserID      uint               `json:"user_id"`
+	IsSiteAdmin bool               `json:"is_site_admin"`
+	Site        SitePermissions    `json:"site"`
+	Groups      []GroupPermissions `json:"groups"`
+}
+
+// sitePermissions returns the site-wide capabilities for a user. Bulk
+// animal updates are open to group admins, limited to their own groups.
+func sitePermissions(isSiteAdmin, isAnyGroupAdmin bool) SitePermissions {
+	return SitePermissions{
+		CanManageUsers:             isSiteAdmin,
+		CanManageGroups:            isSiteAdmin,
+		CanPostSiteAnnouncements:   isSiteAdmin,
+		CanSendEmergencyBroadcasts: isSiteAdmin,
+		CanManageSettings:          isSiteAdmin,
+		CanImportAnimals:           isSiteAdmin,
+		CanBulkUpdateAnimals:       isSiteAdmin || isAnyGroupAdmin,
+		CanViewStatistics:          isSiteAdmin,
+		CanManageAPITokens:         isSiteAdmin,
+		CanReviewQuarantinedImages: isSiteAdmin,
+	}
+}
+
+// groupPermissions computes a user's capabilities in one group using the
+// same access checks as the group's handlers
+func groupPermissions(c *gin.Context, db *gorm.DB, userID uint, isSiteAdmin bool, group models.Group) (GroupPermissions, error) {
+	groupID := strconv.FormatUint(uint64(group.ID), 10)
+	member := checkGroupAccess(db, userID, isSiteAdmin, groupID)
+	admin := checkGroupAdminAccess(db, userID, isSiteAdmin, groupID)
+
+	role, err := viewerGroupRole(c, db, group.ID)
+	if err != nil {
+		return GroupPermissions{}, err
+	}
+	policy, err := loadFieldPolicy(c, db, group.ID)
+	if err != nil {
+		return GroupPermissions{}, err
+	}
+	hidden := make([]string, 0, len(policy.hidden))
+	for field := range policy.hidden {
+		hidden = append(hidden, field)
+	}
+	sort.Strings(hidden)
+
+	var membership int64
+	if err := db.Model(&models.UserGroup{}).Where("user_id = ? AND group_id = ?", userID, group.ID).Count(&membership).Error; err != nil {
+		return GroupPermissions{}, err
+	}
+
+	return GroupPermissions{
+		GroupID:                      group.ID,
+		GroupName:                    group.Name,
+		Role:                         role,
+		IsMember:                     membership > 0,
+		CanViewAnimals:               member,
+		CanEditAnimals:               admin,
+		CanDeleteAnimals:             admin,
+		CanComment:                   member,
+		CanModerateComments:          admin,
+		CanUploadMedia:               member,
+		CanRecordWeights:             member,
+		CanViewBehaviorAssessments:   member && !policy.Hides("behavior_assessments"),
+		CanRecordBehaviorAssessments: admin,
+		CanPostAnnouncements:         admin,
+		CanSendEmergencyBroadcasts:   admin,
+		CanManageMembers:             admin,
+		CanReviewJoinRequests:        admin,
+		CanViewMemberActivity:        admin,
+		CanManageSettings:            admin,
+		CanManageTags:                admin,
+		CanManageFieldVisibility:     admin,
+		CanManageStatusChecklists:    admin,
+		CanPinComments:               admin,
+		CanViewStatistics:            admin,
+		CanExportData:                admin,
+		CanManageShareLinks:          admin,
+		HiddenFields:                 hidden,
+	}, nil
+}
+
+// GetMyPermissions returns what the current user can do, site-wide and in
+// each of their groups (every group, for site admins), so clients don't
+// have to repeat the server's access rules
+// Route: GET /api/me/permissions
+func GetMyPermissions(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+		isSiteAdmin := middleware.IsSiteAdmin(c)
+
+		var groups []models.Group
+		if isSiteAdmin {
+			if err := db.Order("name").Find(&groups).Error; err != nil {
+				respondInternalError(c, "Failed to fetch groups")
+				return
+			}
+		} else if orgID := middleware.GetOrganizationID(c); orgID != nil && middleware.GetIsOrgAdmin(c) {
+			if err := db.Where("organization_id = ?", *orgID).Order("name").Find(&groups).Error; err != nil {
+				respondInternalError(c, "Failed to fetch groups")
+				return
+			}
+		} else {
+			var user models.User
+			if err := db.Preload("Groups", activeGroupsPreload).First(&user, userID).Error; err != nil {
+				respondError(c, http.StatusNotFound, ErrCodeUserNotFound, "User not found")
+				return
+			}
+			groups = user.Groups
+			sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+		}
+
+		resp := PermissionsResponse{UserID: userID, IsSiteAdmin: isSiteAdmin, Groups: make([]GroupPermissions, 0, len(groups))}
+		anyGroupAdmin := false
+		for _, group := range groups {
+			perms, err := groupPermissions(c, db, userID, isSiteAdmin, group)
+			if err != nil {
+				middleware.GetLogger(c).Error("Failed to compute group permissions", err)
+				respondInternalError(c, "Failed to compute permissions")
+				return
+			}
+			anyGroupAdmin = anyGroupAdmin || perms.CanManageMembers
+			resp.Groups = append(resp.Groups, perms)
+		}
+		resp.Site = sitePermissions(isSiteAdmin, anyGroupAdmin)
+
+		respondOK(c, resp)
+	}
+}
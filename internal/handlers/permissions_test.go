@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMyPermissions(t *testing.T) {
+	db := SetupTestDB(t)
+	siteAdmin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	lead := CreateTestUser(t, db, "lead", "lead@example.com", "password123", false)
+	newbie := CreateTestUser(t, db, "newbie", "newbie@example.com", "password123", false)
+	dogs := CreateTestGroup(t, db, "Dogs", "")
+	cats := CreateTestGroup(t, db, "Cats", "")
+	AddUserToGroupWithAdmin(t, db, lead.ID, dogs.ID, true)
+	AddUserToGroupWithAdmin(t, db, lead.ID, cats.ID, false)
+	AddUserToGroupWithAdmin(t, db, newbie.ID, dogs.ID, false)
+	require.NoError(t, db.Model(&models.UserGroup{}).Where("user_id = ? AND group_id = ?", newbie.ID, dogs.ID).
+		Update("role", models.GroupRoleNewVolunteer).Error)
+
+	permissions := func(userID uint, isAdmin bool) PermissionsResponse {
+		c, w := accountTestContext(userID, isAdmin, http.MethodGet, "/api/me/permissions", nil)
+		GetMyPermissions(db)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp PermissionsResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+
+	resp := permissions(lead.ID, false)
+	assert.False(t, resp.Site.CanManageUsers)
+	assert.True(t, resp.Site.CanBulkUpdateAnimals, "group admins bulk update their own groups' animals")
+	require.Len(t, resp.Groups, 2)
+	assert.Equal(t, "Cats", resp.Groups[0].GroupName)
+	assert.Equal(t, models.GroupRoleVolunteer, resp.Groups[0].Role)
+	assert.True(t, resp.Groups[0].CanComment)
+	assert.False(t, resp.Groups[0].CanManageMembers)
+	assert.False(t, resp.Groups[0].CanEditAnimals)
+	assert.Equal(t, models.GroupRoleAdmin, resp.Groups[1].Role)
+	assert.True(t, resp.Groups[1].CanManageMembers)
+	assert.True(t, resp.Groups[1].CanPostAnnouncements)
+	assert.Empty(t, resp.Groups[1].HiddenFields)
+
+	resp = permissions(newbie.ID, false)
+	assert.False(t, resp.Site.CanBulkUpdateAnimals)
+	require.Len(t, resp.Groups, 1)
+	assert.Equal(t, models.GroupRoleNewVolunteer, resp.Groups[0].Role)
+	assert.True(t, resp.Groups[0].CanViewAnimals)
+	assert.False(t, resp.Groups[0].CanViewBehaviorAssessments)
+	assert.Contains(t, resp.Groups[0].HiddenFields, "trainer_notes")
+
+	// Site admins can act in every group, member or not
+	resp = permissions(siteAdmin.ID, true)
+	assert.True(t, resp.Site.CanManageUsers)
+	require.Len(t, resp.Groups, 2)
+	for _, g := range resp.Groups {
+		assert.False(t, g.IsMember)
+		assert.True(t, g.CanManageSettings)
+		assert.Equal(t, models.GroupRoleSiteAdmin, g.Role)
+	}
+}
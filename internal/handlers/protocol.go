@@ -16,18 +16,29 @@ import (
 )
 
 type ProtocolRequest struct {
-	Title      string `json:"title" binding:"required,min=2,max=200"`
-	Content    string `json:"content" binding:"required,min=10,max=1000"`
-	ImageURL   string `json:"image_url,omitempty"`
-	OrderIndex int    `json:"order_index"`
+	Title                  string `json:"title" binding:"required,min=2,max=200"`
+	Content                string `json:"content" binding:"required,min=10,max=20000"`
+	ContentFormat          string `json:"content_format" binding:"omitempty,oneof=plain markdown"` // defaults to plain
+	ImageURL               string `json:"image_url,omitempty"`
+	OrderIndex             int    `json:"order_index"`
+	RequiresAcknowledgment bool   `json:"requires_acknowledgment"`
+}
+
+// contentFormat returns the requested content format, defaulting to plain
+func (r ProtocolRequest) contentFormat() string {
+	if r.ContentFormat == "" {
+		return models.ProtocolFormatPlain
+	}
+	return r.ContentFormat
 }
 
 // UploadProtocolImage handles secure protocol image uploads (group admin or site admin)
-func UploadProtocolImage(db *gorm.DB, storageProvider storage.Provider) gin.HandlerFunc {
+func UploadProtocolImage(db *gorm.DB, storageProvider storage.Provider, imageConfig *upload.ImageConfigStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
 		db := middleware.GetDB(c, db)
 		logger := middleware.GetLogger(c)
+		cfg := imageConfig.Get(ctx)
 		groupID := c.Param("id")
 		userID, _ := c.Get("user_id")
 		isAdmin, _ := c.Get("is_admin")
@@ -46,7 +57,7 @@ func UploadProtocolImage(db *gorm.DB, storageProvider storage.Provider) gin.Hand
 		}
 
 		// Validate file upload (size, type, content)
-		if err := upload.ValidateImageUpload(file, upload.MaxImageSize); err != nil {
+		if err := upload.ValidateImageUpload(file, cfg.MaxUploadBytes); err != nil {
 			logger.Error("File validation failed", err)
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file: " + err.Error()})
 			return
@@ -78,6 +89,14 @@ func UploadProtocolImage(db *gorm.DB, storageProvider storage.Provider) gin.Hand
 			}
 		}
 
+		// Resize and re-encode using the configured image settings
+		data, mimeType, err = upload.ProcessImageOrOriginal(data, mimeType, cfg.MaxDimension, cfg)
+		if err != nil {
+			logger.Error("Failed to process image", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process image"})
+			return
+		}
+
 		// Upload to storage provider
 		imageURL, _, _, err := storageProvider.UploadImage(ctx, data, mimeType, nil)
 		if err != nil {
@@ -118,7 +137,7 @@ func GetProtocols(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		var protocols []models.Protocol
-		if err := db.
+		if err := withProtocolAttachments(db).
 			Where("group_id = ?", groupID).
 			Order("order_index ASC, created_at ASC").
 			Find(&protocols).Error; err != nil {
@@ -126,6 +145,10 @@ func GetProtocols(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
+		if uid, ok := middleware.GetUserID(c); ok {
+			markAcknowledgedProtocols(db, uid, protocols)
+		}
+
 		c.JSON(http.StatusOK, protocols)
 	}
 }
@@ -146,11 +169,17 @@ func GetProtocol(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		var protocol models.Protocol
-		if err := db.Where("id = ? AND group_id = ?", protocolID, groupID).First(&protocol).Error; err != nil {
+		if err := withProtocolAttachments(db).Where("id = ? AND group_id = ?", protocolID, groupID).First(&protocol).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Protocol not found"})
 			return
 		}
 
+		if uid, ok := middleware.GetUserID(c); ok {
+			protocols := []models.Protocol{protocol}
+			markAcknowledgedProtocols(db, uid, protocols)
+			protocol = protocols[0]
+		}
+
 		c.JSON(http.StatusOK, protocol)
 	}
 }
@@ -194,14 +223,23 @@ func CreateProtocol(db *gorm.DB) gin.HandlerFunc {
 		}
 
 		protocol := models.Protocol{
-			GroupID:    uint(gid),
-			Title:      req.Title,
-			Content:    req.Content,
-			ImageURL:   req.ImageURL,
-			OrderIndex: req.OrderIndex,
+			GroupID:                uint(gid),
+			Title:                  req.Title,
+			Content:                req.Content,
+			ContentFormat:          req.contentFormat(),
+			ImageURL:               req.ImageURL,
+			OrderIndex:             req.OrderIndex,
+			Version:                1,
+			RequiresAcknowledgment: req.RequiresAcknowledgment,
 		}
 
-		if err := db.Create(&protocol).Error; err != nil {
+		editor := protocolEditor(c)
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&protocol).Error; err != nil {
+				return err
+			}
+			return tx.Create(protocolSnapshot(protocol, editor)).Error
+		}); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create protocol"})
 			return
 		}
@@ -237,12 +275,39 @@ func UpdateProtocol(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		protocol.Title = req.Title
-		protocol.Content = req.Content
-		protocol.ImageURL = req.ImageURL
-		protocol.OrderIndex = req.OrderIndex
+		// Reordering or toggling the acknowledgment flag is not a new version;
+		// only changes to what volunteers read are.
+		contentChanged := protocol.Title != req.Title || protocol.Content != req.Content ||
+			protocol.ContentFormat != req.contentFormat() || protocol.ImageURL != req.ImageURL
+		editor := protocolEditor(c)
+
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if contentChanged {
+				// Protocols created before versioning have no snapshot of
+				// their original content; keep it before it is overwritten.
+				outgoing := protocolSnapshot(protocol, nil)
+				if err := tx.Where(models.ProtocolVersion{ProtocolID: protocol.ID, Version: protocol.Version}).
+					FirstOrCreate(outgoing).Error; err != nil {
+					return err
+				}
+				protocol.Version++
+			}
+
+			protocol.Title = req.Title
+			protocol.Content = req.Content
+			protocol.ContentFormat = req.contentFormat()
+			protocol.ImageURL = req.ImageURL
+			protocol.OrderIndex = req.OrderIndex
+			protocol.RequiresAcknowledgment = req.RequiresAcknowledgment
 
-		if err := db.Save(&protocol).Error; err != nil {
+			if err := tx.Save(&protocol).Error; err != nil {
+				return err
+			}
+			if contentChanged {
+				return tx.Create(protocolSnapshot(protocol, editor)).Error
+			}
+			return nil
+		}); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update protocol"})
 			return
 		}
@@ -280,3 +345,79 @@ func DeleteProtocol(db *gorm.DB) gin.HandlerFunc {
 		c.JSON(http.StatusOK, gin.H{"message": "Protocol deleted successfully"})
 	}
 }
+
+// MoveProtocolRequest moves a protocol one place up or down its group's list
+type MoveProtocolRequest struct {
+	Direction string `json:"direction" binding:"required,oneof=up down"`
+}
+
+// MoveProtocol swaps a protocol with its neighbour in the group's display
+// order and renumbers the group's protocols 0..n-1, so older protocols that
+// share an order_index get distinct positions (group admin or site admin).
+// Returns the group's protocols in their new order.
+func MoveProtocol(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		protocolID := c.Param("protocolId")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			return
+		}
+
+		var req MoveProtocolRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": formatValidationError(err)})
+			return
+		}
+
+		var protocols []models.Protocol
+		notFound := false
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("group_id = ?", groupID).
+				Order("order_index ASC, created_at ASC, id ASC").
+				Find(&protocols).Error; err != nil {
+				return err
+			}
+			pos := -1
+			for i, p := range protocols {
+				if strconv.FormatUint(uint64(p.ID), 10) == protocolID {
+					pos = i
+				}
+			}
+			if pos < 0 {
+				notFound = true
+				return nil
+			}
+			// Moving past either end leaves the order as it is
+			if req.Direction == "up" && pos > 0 {
+				protocols[pos-1], protocols[pos] = protocols[pos], protocols[pos-1]
+			} else if req.Direction == "down" && pos < len(protocols)-1 {
+				protocols[pos], protocols[pos+1] = protocols[pos+1], protocols[pos]
+			}
+			for i := range protocols {
+				if protocols[i].OrderIndex == i {
+					continue
+				}
+				protocols[i].OrderIndex = i
+				if err := tx.Model(&models.Protocol{}).Where("id = ?", protocols[i].ID).
+					Update("order_index", i).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move protocol"})
+			return
+		}
+		if notFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Protocol not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, protocols)
+	}
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// ProtocolAckUser is a group member in a protocol acknowledgment report.
+type ProtocolAckUser struct {
+	ID             uint       `json:"id"`
+	Username       string     `json:"username"`
+	FirstName      string     `json:"first_name"`
+	LastName       string     `json:"last_name"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+}
+
+// ProtocolAckStatus reports who has and hasn't acknowledged the current
+// version of one protocol.
+type ProtocolAckStatus struct {
+	ProtocolID      uint              `json:"protocol_id"`
+	Title           string            `json:"title"`
+	Version         int               `json:"version"`
+	Acknowledged    []ProtocolAckUser `json:"acknowledged"`
+	NotAcknowledged []ProtocolAckUser `json:"not_acknowledged"`
+}
+
+// protocolEditor returns the calling user's ID for ProtocolVersion.EditedByID,
+// or nil when there is no user in context.
+func protocolEditor(c *gin.Context) *uint {
+	if uid, ok := middleware.GetUserID(c); ok {
+		return &uid
+	}
+	return nil
+}
+
+// protocolSnapshot builds the ProtocolVersion row for the protocol's current
+// Version.
+func protocolSnapshot(p models.Protocol, editedBy *uint) *models.ProtocolVersion {
+	return &models.ProtocolVersion{
+		ProtocolID:    p.ID,
+		Version:       p.Version,
+		Title:         p.Title,
+		Content:       p.Content,
+		ImageURL:      p.ImageURL,
+		EditedByID:    editedBy,
+		ContentFormat: p.ContentFormat,
+	}
+}
+
+// markAcknowledgedProtocols sets Acknowledged on each protocol the user has
+// acknowledged at its current version. Errors leave the flags false; the
+// listing itself should not fail because of them.
+func markAcknowledgedProtocols(db *gorm.DB, userID uint, protocols []models.Protocol) {
+	if len(protocols) == 0 {
+		return
+	}
+	ids := make([]uint, len(protocols))
+	for i, p := range protocols {
+		ids[i] = p.ID
+	}
+	var acks []models.ProtocolAcknowledgment
+	if err := db.Where("user_id = ? AND protocol_id IN ?", userID, ids).Find(&acks).Error; err != nil {
+		return
+	}
+	type key struct {
+		protocolID uint
+		version    int
+	}
+	acked := make(map[key]bool, len(acks))
+	for _, a := range acks {
+		acked[key{a.ProtocolID, a.Version}] = true
+	}
+	for i := range protocols {
+		protocols[i].Acknowledged = acked[key{protocols[i].ID, protocols[i].Version}]
+	}
+}
+
+// AcknowledgeProtocol records that the current user has read the current
+// version of a protocol. Repeating the call is a no-op.
+// Route: POST /api/groups/:id/protocols/:protocolId/acknowledge
+func AcknowledgeProtocol(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		protocolID := c.Param("protocolId")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+
+		uid, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+
+		var protocol models.Protocol
+		if err := db.Where("id = ? AND group_id = ?", protocolID, groupID).First(&protocol).Error; err != nil {
+			respondNotFound(c, "Protocol not found")
+			return
+		}
+
+		if !protocol.RequiresAcknowledgment {
+			respondBadRequest(c, "This protocol does not require acknowledgment")
+			return
+		}
+
+		ack := models.ProtocolAcknowledgment{ProtocolID: protocol.ID, Version: protocol.Version, UserID: uid}
+		if err := db.Where(ack).FirstOrCreate(&ack).Error; err != nil {
+			respondInternalError(c, "Failed to record acknowledgment")
+			return
+		}
+
+		respondOK(c, ack)
+	}
+}
+
+// GetProtocolVersions returns a protocol's version history, newest first
+// Route: GET /api/groups/:id/protocols/:protocolId/versions
+func GetProtocolVersions(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		protocolID := c.Param("protocolId")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+
+		var protocol models.Protocol
+		if err := db.Where("id = ? AND group_id = ?", protocolID, groupID).First(&protocol).Error; err != nil {
+			respondNotFound(c, "Protocol not found")
+			return
+		}
+
+		var versions []models.ProtocolVersion
+		if err := db.Where("protocol_id = ?", protocol.ID).Order("version DESC").Find(&versions).Error; err != nil {
+			respondInternalError(c, "Failed to fetch protocol versions")
+			return
+		}
+
+		respondOK(c, versions)
+	}
+}
+
+// GetProtocolAcknowledgments reports, for every protocol in the group that
+// requires acknowledgment, which members have and haven't acknowledged its
+// current version (group admin or site admin).
+// Route: GET /api/groups/:id/protocols/acknowledgments
+func GetProtocolAcknowledgments(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
+			return
+		}
+
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+
+		var protocols []models.Protocol
+		if err := db.Where("group_id = ? AND requires_acknowledgment = ?", gid, true).
+			Order("order_index ASC, created_at ASC").
+			Find(&protocols).Error; err != nil {
+			respondInternalError(c, "Failed to fetch protocols")
+			return
+		}
+
+		var members []ProtocolAckUser
+		if err := db.Model(&models.User{}).
+			Select("users.id, users.username, users.first_name, users.last_name").
+			Joins("JOIN user_groups ON user_groups.user_id = users.id").
+			Where("user_groups.group_id = ?", gid).
+			Order("users.username ASC").
+			Scan(&members).Error; err != nil {
+			respondInternalError(c, "Failed to fetch group members")
+			return
+		}
+
+		// One query for every protocol's acknowledgments, grouped by protocol.
+		// Only acknowledgments of a protocol's current version count.
+		protocolIDs := make([]uint, len(protocols))
+		versions := make(map[uint]int, len(protocols))
+		for i, p := range protocols {
+			protocolIDs[i] = p.ID
+			versions[p.ID] = p.Version
+		}
+		var acks []models.ProtocolAcknowledgment
+		if len(protocolIDs) > 0 {
+			if err := db.Where("protocol_id IN ?", protocolIDs).Find(&acks).Error; err != nil {
+				respondInternalError(c, "Failed to fetch acknowledgments")
+				return
+			}
+		}
+		ackedAt := make(map[uint]map[uint]time.Time, len(protocols))
+		for _, a := range acks {
+			if a.Version != versions[a.ProtocolID] {
+				continue
+			}
+			if ackedAt[a.ProtocolID] == nil {
+				ackedAt[a.ProtocolID] = map[uint]time.Time{}
+			}
+			ackedAt[a.ProtocolID][a.UserID] = a.CreatedAt
+		}
+
+		report := make([]ProtocolAckStatus, 0, len(protocols))
+		for _, p := range protocols {
+			status := ProtocolAckStatus{
+				ProtocolID:      p.ID,
+				Title:           p.Title,
+				Version:         p.Version,
+				Acknowledged:    []ProtocolAckUser{},
+				NotAcknowledged: []ProtocolAckUser{},
+			}
+			for _, m := range members {
+				if at, ok := ackedAt[p.ID][m.ID]; ok {
+					m.AcknowledgedAt = &at
+					status.Acknowledged = append(status.Acknowledged, m)
+				} else {
+					status.NotAcknowledged = append(status.NotAcknowledged, m)
+				}
+			}
+			report = append(report, status)
+		}
+
+		respondOK(c, report)
+	}
+}
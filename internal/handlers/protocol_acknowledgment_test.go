@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func protocolTestContext(userID uint, isAdmin bool, groupID uint, protocolID uint, method string, body any) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", userID)
+	c.Set("is_admin", isAdmin)
+	c.Params = gin.Params{{Key: "id", Value: fmt.Sprintf("%d", groupID)}}
+	if protocolID != 0 {
+		c.Params = append(c.Params, gin.Param{Key: "protocolId", Value: fmt.Sprintf("%d", protocolID)})
+	}
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	c.Request = httptest.NewRequest(method, "/", &buf)
+	c.Request.Header.Set("Content-Type", "application/json")
+	return c, w
+}
+
+func setupProtocolGroup(t *testing.T) (*gorm.DB, *models.Group, *models.User, *models.User) {
+	t.Helper()
+	db := SetupTestDB(t)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	require.NoError(t, db.Model(group).Update("has_protocols", true).Error)
+	admin := CreateTestUser(t, db, "gadmin", "gadmin@example.com", "password123", false)
+	member := CreateTestUser(t, db, "volunteer", "volunteer@example.com", "password123", false)
+	AddUserToGroupWithAdmin(t, db, admin.ID, group.ID, true)
+	AddUserToGroupWithAdmin(t, db, member.ID, group.ID, false)
+	return db, group, admin, member
+}
+
+func TestUpdateProtocol_Versioning(t *testing.T) {
+	db, group, admin, _ := setupProtocolGroup(t)
+
+	c, w := protocolTestContext(admin.ID, false, group.ID, 0, http.MethodPost, ProtocolRequest{
+		Title: "Fire evacuation", Content: "Leash dogs and exit via the east door.",
+	})
+	CreateProtocol(db)(c)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var protocol models.Protocol
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &protocol))
+	assert.Equal(t, 1, protocol.Version)
+
+	// Reordering and flag changes don't create a new version.
+	c, w = protocolTestContext(admin.ID, false, group.ID, protocol.ID, http.MethodPut, ProtocolRequest{
+		Title: protocol.Title, Content: protocol.Content, OrderIndex: 3, RequiresAcknowledgment: true,
+	})
+	UpdateProtocol(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &protocol))
+	assert.Equal(t, 1, protocol.Version)
+	assert.True(t, protocol.RequiresAcknowledgment)
+
+	c, w = protocolTestContext(admin.ID, false, group.ID, protocol.ID, http.MethodPut, ProtocolRequest{
+		Title: protocol.Title, Content: "Leash dogs and exit via the west door.", RequiresAcknowledgment: true,
+	})
+	UpdateProtocol(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &protocol))
+	assert.Equal(t, 2, protocol.Version)
+
+	c, w = protocolTestContext(admin.ID, false, group.ID, protocol.ID, http.MethodGet, nil)
+	GetProtocolVersions(db)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var versions []models.ProtocolVersion
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &versions))
+	require.Len(t, versions, 2)
+	assert.Equal(t, 2, versions[0].Version)
+	assert.Contains(t, versions[1].Content, "east door", "original content should be preserved")
+}
+
+func TestUpdateProtocol_SnapshotsPreVersioningContent(t *testing.T) {
+	db, group, admin, _ := setupProtocolGroup(t)
+	legacy := models.Protocol{GroupID: group.ID, Title: "Legacy", Content: "Content written before versioning.", Version: 1}
+	require.NoError(t, db.Create(&legacy).Error)
+
+	c, w := protocolTestContext(admin.ID, false, group.ID, legacy.ID, http.MethodPut, ProtocolRequest{
+		Title: "Legacy", Content: "Content rewritten after versioning.",
+	})
+	UpdateProtocol(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var versions []models.ProtocolVersion
+	require.NoError(t, db.Where("protocol_id = ?", legacy.ID).Order("version").Find(&versions).Error)
+	require.Len(t, versions, 2)
+	assert.Nil(t, versions[0].EditedByID)
+	assert.Equal(t, "Content written before versioning.", versions[0].Content)
+}
+
+func TestProtocolAcknowledgments(t *testing.T) {
+	db, group, admin, member := setupProtocolGroup(t)
+	protocol := models.Protocol{GroupID: group.ID, Title: "Bite response", Content: "Separate, then report.", Version: 1, RequiresAcknowledgment: true}
+	require.NoError(t, db.Create(&protocol).Error)
+	optional := models.Protocol{GroupID: group.ID, Title: "Parking", Content: "Use the back lot please.", Version: 1}
+	require.NoError(t, db.Create(&optional).Error)
+
+	c, w := protocolTestContext(member.ID, false, group.ID, optional.ID, http.MethodPost, nil)
+	AcknowledgeProtocol(db)(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "optional protocols can't be acknowledged")
+
+	for i := 0; i < 2; i++ {
+		c, w = protocolTestContext(member.ID, false, group.ID, protocol.ID, http.MethodPost, nil)
+		AcknowledgeProtocol(db)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+	var count int64
+	db.Model(&models.ProtocolAcknowledgment{}).Count(&count)
+	assert.Equal(t, int64(1), count, "acknowledging twice is idempotent")
+
+	c, w = protocolTestContext(member.ID, false, group.ID, 0, http.MethodGet, nil)
+	GetProtocols(db)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed []models.Protocol
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	for _, p := range listed {
+		assert.Equal(t, p.ID == protocol.ID, p.Acknowledged, "protocol %d", p.ID)
+	}
+
+	c, w = protocolTestContext(member.ID, false, group.ID, 0, http.MethodGet, nil)
+	GetProtocolAcknowledgments(db)(c)
+	assert.Equal(t, http.StatusForbidden, w.Code, "members can't view the report")
+
+	c, w = protocolTestContext(admin.ID, false, group.ID, 0, http.MethodGet, nil)
+	GetProtocolAcknowledgments(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var report []ProtocolAckStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	require.Len(t, report, 1)
+	require.Len(t, report[0].Acknowledged, 1)
+	assert.Equal(t, member.ID, report[0].Acknowledged[0].ID)
+	require.Len(t, report[0].NotAcknowledged, 1)
+	assert.Equal(t, admin.ID, report[0].NotAcknowledged[0].ID)
+
+	// A new version resets everyone to not acknowledged.
+	require.NoError(t, db.Model(&protocol).Update("version", 2).Error)
+	c, w = protocolTestContext(admin.ID, false, group.ID, 0, http.MethodGet, nil)
+	GetProtocolAcknowledgments(db)(c)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Empty(t, report[0].Acknowledged)
+	assert.Len(t, report[0].NotAcknowledged, 2)
+
+	// Each protocol only lists its own acknowledgments
+	leash := models.Protocol{GroupID: group.ID, Title: "Leash check", Content: "Check the clip.", Version: 1, RequiresAcknowledgment: true, OrderIndex: 1}
+	require.NoError(t, db.Create(&leash).Error)
+	c, w = protocolTestContext(admin.ID, false, group.ID, leash.ID, http.MethodPost, nil)
+	AcknowledgeProtocol(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	c, w = protocolTestContext(admin.ID, false, group.ID, 0, http.MethodGet, nil)
+	GetProtocolAcknowledgments(db)(c)
+	report = nil
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	require.Len(t, report, 2)
+	assert.Empty(t, report[0].Acknowledged)
+	require.Len(t, report[1].Acknowledged, 1)
+	assert.Equal(t, admin.ID, report[1].Acknowledged[0].ID)
+	require.Len(t, report[1].NotAcknowledged, 1)
+	assert.Equal(t, member.ID, report[1].NotAcknowledged[0].ID)
+}
@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/upload"
+	"gorm.io/gorm"
+)
+
+// maxProtocolAttachments caps how many files one protocol can carry.
+const maxProtocolAttachments = 20
+
+// protocolAttachmentColumns are the columns listed with a protocol; the file
+// bytes are only read when the attachment is downloaded.
+const protocolAttachmentColumns = "id, created_at, updated_at, protocol_id, group_id, is_image, " +
+	"file_url, file_name, file_type, file_size, file_provider, file_uploaded_by_user_id"
+
+// withProtocolAttachments preloads each protocol's attachments, oldest first.
+func withProtocolAttachments(db *gorm.DB) *gorm.DB {
+	return db.Preload("Attachments", func(tx *gorm.DB) *gorm.DB {
+		return tx.Select(protocolAttachmentColumns).Order("created_at ASC, id ASC")
+	})
+}
+
+// UploadProtocolAttachment attaches an image or document to a protocol (group
+// admin or site admin). Images go through the same validation and resizing
+// as protocol images; documents must be PDF, DOCX, or XLSX and are stored as
+// uploaded.
+func UploadProtocolAttachment(db *gorm.DB, storageProvider storage.Provider, imageConfig *upload.ImageConfigStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		db := middleware.GetDB(c, db)
+		logger := middleware.GetLogger(c)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			return
+		}
+
+		var protocol models.Protocol
+		if err := db.Where("id = ? AND group_id = ?", c.Param("protocolId"), groupID).First(&protocol).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Protocol not found"})
+			return
+		}
+
+		var count int64
+		if err := db.Model(&models.ProtocolAttachment{}).Where("protocol_id = ?", protocol.ID).Count(&count).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check attachments"})
+			return
+		}
+		if count >= maxProtocolAttachments {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("A protocol can have at most %d attachments", maxProtocolAttachments)})
+			return
+		}
+
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+			return
+		}
+
+		ext := strings.ToLower(filepath.Ext(file.Filename))
+		_, isImage := upload.AllowedImageTypes[ext]
+		cfg := imageConfig.Get(ctx)
+		if isImage {
+			err = upload.ValidateImageUpload(file, cfg.MaxUploadBytes)
+		} else {
+			err = upload.ValidateDocumentUpload(file, upload.MaxDocumentSize)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file: " + err.Error()})
+			return
+		}
+
+		src, err := file.Open()
+		if err != nil {
+			logger.Error("Failed to open uploaded file", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process file"})
+			return
+		}
+		defer src.Close()
+
+		data, err := io.ReadAll(src)
+		if err != nil {
+			logger.Error("Failed to read file data", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process file"})
+			return
+		}
+
+		mimeType := upload.MimeTypeFromFilename(file.Filename)
+		if isImage {
+			mimeType = http.DetectContentType(data)
+			if mimeType == "application/octet-stream" {
+				mimeType = upload.AllowedImageTypes[ext][0]
+			}
+			data, mimeType, err = upload.ProcessImageOrOriginal(data, mimeType, cfg.MaxDimension, cfg)
+			if err != nil {
+				logger.Error("Failed to process image", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process image"})
+				return
+			}
+		}
+
+		uploaderID, ok := userID.(uint)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user identity"})
+			return
+		}
+
+		// As with group documents, the provider URL is discarded so every
+		// download goes through ServeProtocolAttachment's membership check.
+		attachmentUUID := uuid.New().String()
+		_, blobUUID, blobExt, uploadErr := storageProvider.UploadDocument(ctx, data, mimeType, file.Filename)
+		var blobIdentifier, fileProvider string
+		var fileDataForDB []byte
+		if uploadErr != nil {
+			logger.WithFields(map[string]interface{}{"error": uploadErr.Error()}).
+				Warn("Failed to upload protocol attachment to storage provider, falling back to PostgreSQL")
+			blobIdentifier = attachmentUUID
+			fileProvider = storage.ProviderPostgres
+			fileDataForDB = data
+		} else {
+			blobIdentifier = blobUUID + blobExt
+			fileProvider = storageProvider.Name()
+			if fileProvider == storage.ProviderPostgres {
+				fileDataForDB = data
+			}
+		}
+
+		attachment := models.ProtocolAttachment{
+			ProtocolID:           protocol.ID,
+			GroupID:              protocol.GroupID,
+			IsImage:              isImage,
+			FileURL:              fmt.Sprintf("/api/protocol-attachments/%s", blobIdentifier),
+			FileName:             upload.SanitizeFilename(file.Filename),
+			FileType:             mimeType,
+			FileSize:             int64(len(data)),
+			FileProvider:         fileProvider,
+			FileBlobIdentifier:   blobIdentifier,
+			FileBlobExtension:    blobExt,
+			FileData:             fileDataForDB,
+			FileUploadedByUserID: &uploaderID,
+		}
+		if err := db.Create(&attachment).Error; err != nil {
+			logger.Error("Failed to create protocol attachment", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save attachment"})
+			return
+		}
+
+		logger.WithFields(map[string]interface{}{
+			"attachment_id": attachment.ID,
+			"protocol_id":   protocol.ID,
+			"file_name":     attachment.FileName,
+		}).Info("Protocol attachment uploaded successfully")
+
+		c.JSON(http.StatusCreated, attachment)
+	}
+}
+
+// DeleteProtocolAttachment removes an attachment and its stored file (group
+// admin or site admin).
+func DeleteProtocolAttachment(db *gorm.DB, storageProvider storage.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		db := middleware.GetDB(c, db)
+		logger := middleware.GetLogger(c)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			return
+		}
+
+		var attachment models.ProtocolAttachment
+		if err := db.Select(protocolAttachmentColumns+", file_blob_identifier").
+			Where("id = ? AND protocol_id = ? AND group_id = ?", c.Param("attachmentId"), c.Param("protocolId"), groupID).
+			First(&attachment).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+			return
+		}
+
+		if attachment.FileProvider != storage.ProviderPostgres && attachment.FileBlobIdentifier != "" {
+			if err := storageProvider.DeleteDocument(ctx, attachment.FileBlobIdentifier); err != nil {
+				logger.WithFields(map[string]interface{}{
+					"error":           err.Error(),
+					"blob_identifier": attachment.FileBlobIdentifier,
+				}).Warn("Failed to delete protocol attachment from storage, continuing with DB deletion")
+			}
+		}
+
+		// Clear stored bytes so soft-deleted rows don't keep the file around
+		if err := db.Model(&attachment).Update("file_data", nil).Error; err != nil {
+			logger.WithFields(map[string]interface{}{"attachment_id": attachment.ID}).
+				Warn("Failed to clear attachment data before delete, proceeding anyway")
+		}
+		if err := db.Delete(&attachment).Error; err != nil {
+			logger.Error("Failed to delete protocol attachment", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete attachment"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted successfully"})
+	}
+}
+
+// ServeProtocolAttachment serves the file of a protocol attachment (members
+// of the protocol's group or site admins). The URL parameter :uuid is the
+// FileBlobIdentifier. Attachments of deleted protocols are not served.
+func ServeProtocolAttachment(db *gorm.DB, storageProvider storage.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		db := middleware.GetDB(c, db)
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		var attachment models.ProtocolAttachment
+		if err := db.Where("file_blob_identifier = ?", c.Param("uuid")).First(&attachment).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+			return
+		}
+		var protocols int64
+		if err := db.Model(&models.Protocol{}).Where("id = ?", attachment.ProtocolID).Count(&protocols).Error; err != nil || protocols == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+			return
+		}
+
+		if !checkGroupAccess(db, userID, isAdmin, strconv.FormatUint(uint64(attachment.GroupID), 10)) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+			return
+		}
+
+		data, mimeType := attachment.FileData, attachment.FileType
+		if attachment.FileProvider != storage.ProviderPostgres && attachment.FileBlobIdentifier != "" {
+			var err error
+			data, mimeType, err = storageProvider.GetDocument(ctx, attachment.FileBlobIdentifier)
+			if err != nil {
+				if err == storage.ErrNotFound {
+					c.JSON(http.StatusNotFound, gin.H{"error": "Attachment file not found in storage"})
+				} else {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attachment"})
+				}
+				return
+			}
+		}
+		if len(data) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment file data not available"})
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf("inline; filename=\"%s\"", sanitizeFilename(attachment.FileName)))
+		c.Header("Cache-Control", "private, max-age=3600")
+		c.Header("X-Content-Type-Options", "nosniff")
+		c.Data(http.StatusOK, mimeType, data)
+	}
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPNG is a small decodable PNG for attachments that are printed.
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 3))))
+	return buf.Bytes()
+}
+
+func TestProtocolAttachments(t *testing.T) {
+	db, group, admin, member := setupProtocolGroup(t)
+	outsider := CreateTestUser(t, db, "outsider", "outsider@example.com", "password123", false)
+	protocol := models.Protocol{GroupID: group.ID, Title: "Intake", Content: "Scan for a microchip first."}
+	require.NoError(t, db.Create(&protocol).Error)
+
+	// Uploads fall back to PostgreSQL when the provider fails
+	provider := &mockStorageProvider{UploadDocumentErr: errors.New("blob unavailable")}
+	uploadFile := func(userID uint, name string, content []byte) (*models.ProtocolAttachment, int) {
+		c, w := protocolTestContext(userID, false, group.ID, protocol.ID, http.MethodPost, nil)
+		c.Request = createImageMultipartRequest(t, "file", name, content)
+		UploadProtocolAttachment(db, provider, nil)(c)
+		var attachment models.ProtocolAttachment
+		if w.Code == http.StatusCreated {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &attachment))
+		}
+		return &attachment, w.Code
+	}
+	_, code := uploadFile(member.ID, "intake.pdf", minimalPDF)
+	assert.Equal(t, http.StatusForbidden, code)
+	_, code = uploadFile(admin.ID, "intake.txt", []byte("plain text"))
+	assert.Equal(t, http.StatusBadRequest, code)
+	doc, code := uploadFile(admin.ID, "intake form.pdf", minimalPDF)
+	require.Equal(t, http.StatusCreated, code)
+	assert.False(t, doc.IsImage)
+	assert.Equal(t, "application/pdf", doc.FileType)
+	photo, code := uploadFile(admin.ID, "scanner.png", testPNG(t))
+	require.Equal(t, http.StatusCreated, code)
+	assert.True(t, photo.IsImage)
+
+	// Attachments are listed with the protocol
+	c, w := protocolTestContext(member.ID, false, group.ID, protocol.ID, http.MethodGet, nil)
+	GetProtocol(db)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed models.Protocol
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed.Attachments, 2)
+	assert.Equal(t, "intake form.pdf", listed.Attachments[0].FileName)
+
+	serve := func(userID uint, fileURL string) int {
+		c, w := protocolTestContext(userID, false, group.ID, 0, http.MethodGet, nil)
+		c.Params = gin.Params{{Key: "uuid", Value: fileURL[len("/api/protocol-attachments/"):]}}
+		ServeProtocolAttachment(db, provider)(c)
+		if w.Code == http.StatusOK {
+			assert.Equal(t, minimalPDF, w.Body.Bytes())
+		}
+		return w.Code
+	}
+	assert.Equal(t, http.StatusOK, serve(member.ID, doc.FileURL))
+	assert.Equal(t, http.StatusForbidden, serve(outsider.ID, doc.FileURL))
+
+	c, w = protocolTestContext(admin.ID, false, group.ID, protocol.ID, http.MethodDelete, nil)
+	c.Params = append(c.Params, gin.Param{Key: "attachmentId", Value: fmt.Sprint(doc.ID)})
+	DeleteProtocolAttachment(db, provider)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, http.StatusNotFound, serve(member.ID, doc.FileURL))
+}
+
+func TestMoveProtocol(t *testing.T) {
+	db, group, admin, member := setupProtocolGroup(t)
+	// Protocols created before ordering existed all share order_index 0
+	var ids []uint
+	for _, title := range []string{"First", "Second", "Third"} {
+		protocol := models.Protocol{GroupID: group.ID, Title: title, Content: "Content for " + title}
+		require.NoError(t, db.Create(&protocol).Error)
+		ids = append(ids, protocol.ID)
+	}
+
+	move := func(userID, protocolID uint, direction string) (int, []string) {
+		c, w := protocolTestContext(userID, false, group.ID, protocolID, http.MethodPost, MoveProtocolRequest{Direction: direction})
+		MoveProtocol(db)(c)
+		var protocols []models.Protocol
+		_ = json.Unmarshal(w.Body.Bytes(), &protocols)
+		var titles []string
+		for _, p := range protocols {
+			titles = append(titles, p.Title)
+		}
+		return w.Code, titles
+	}
+
+	code, _ := move(member.ID, ids[2], "up")
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = move(admin.ID, ids[2], "sideways")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = move(admin.ID, 9999, "up")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, titles := move(admin.ID, ids[2], "up")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"First", "Third", "Second"}, titles)
+	_, titles = move(admin.ID, ids[0], "down")
+	assert.Equal(t, []string{"Third", "First", "Second"}, titles)
+	_, titles = move(admin.ID, ids[2], "up")
+	assert.Equal(t, []string{"Third", "First", "Second"}, titles, "the first protocol can't move up")
+
+	var stored []models.Protocol
+	require.NoError(t, db.Where("group_id = ?", group.ID).Order("order_index").Find(&stored).Error)
+	for i, p := range stored {
+		assert.Equal(t, i, p.OrderIndex)
+	}
+	assert.Equal(t, ids[2], stored[0].ID)
+}
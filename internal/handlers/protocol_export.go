@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/pdf"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
+	"github.com/nfnt/resize"
+	"gorm.io/gorm"
+)
+
+// protocolBlock is one printable line group of a protocol's content: a
+// paragraph, heading, list item, or blank line (empty Text).
+type protocolBlock struct {
+	Text   string
+	Font   pdf.Font
+	Size   float64
+	Indent float64
+	Bullet string // printed in the indent, e.g. "•" or "2."
+}
+
+var (
+	markdownHeading  = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	markdownBullet   = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	markdownNumbered = regexp.MustCompile(`^\s*(\d+[.)])\s+(.*)$`)
+	markdownRule     = regexp.MustCompile(`^(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	markdownImage    = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLink     = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+	markdownStrong   = regexp.MustCompile(`(\*\*|__)(.+?)(\*\*|__)`)
+	markdownEmphasis = regexp.MustCompile(`\*([^*\s][^*]*)\*`)
+)
+
+// stripInlineMarkdown removes emphasis and code markers and prints links as
+// "text (url)", since the PDF has a single weight per line.
+func stripInlineMarkdown(s string) string {
+	s = markdownImage.ReplaceAllString(s, "$1")
+	s = markdownLink.ReplaceAllString(s, "$1 ($2)")
+	s = markdownStrong.ReplaceAllString(s, "$2")
+	s = markdownEmphasis.ReplaceAllString(s, "$1")
+	return strings.ReplaceAll(s, "`", "")
+}
+
+// protocolBlocks splits protocol content into printable blocks. Plain text
+// keeps its line breaks; markdown headings, lists, and quotes become their
+// own blocks and inline formatting is dropped. Code blocks print as written.
+func protocolBlocks(content, format string) []protocolBlock {
+	const bodySize, indent = 11.0, 16.0
+	var blocks []protocolBlock
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	if format != models.ProtocolFormatMarkdown {
+		for _, line := range lines {
+			blocks = append(blocks, protocolBlock{Text: strings.TrimSpace(line), Font: pdf.Helvetica, Size: bodySize})
+		}
+		return blocks
+	}
+
+	inCode := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+			continue
+		}
+		block := protocolBlock{Font: pdf.Helvetica, Size: bodySize}
+		if inCode {
+			block.Text, block.Indent = line, indent
+			blocks = append(blocks, block)
+			continue
+		}
+		if m := markdownHeading.FindStringSubmatch(trimmed); m != nil {
+			block.Text, block.Font = m[2], pdf.HelveticaBold
+			block.Size = math.Max(bodySize, 16-float64(len(m[1])))
+		} else if m := markdownBullet.FindStringSubmatch(line); m != nil && !markdownRule.MatchString(trimmed) {
+			block.Text, block.Indent, block.Bullet = m[1], indent, "•"
+		} else if m := markdownNumbered.FindStringSubmatch(line); m != nil {
+			block.Text, block.Indent, block.Bullet = m[2], indent, m[1]
+		} else if strings.HasPrefix(trimmed, ">") {
+			block.Text, block.Indent = strings.TrimSpace(strings.TrimLeft(trimmed, ">")), indent
+		} else if !markdownRule.MatchString(trimmed) {
+			block.Text = trimmed
+		}
+		block.Text = stripInlineMarkdown(block.Text)
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// protocolPrintout is everything in a group's "print all protocols" PDF.
+type protocolPrintout struct {
+	GroupName string
+	Printed   time.Time
+	Protocols []models.Protocol // with Attachments loaded
+	Images    map[uint]image.Image
+}
+
+// protocolPDFWriter flows text and images down letter pages, starting a new
+// page whenever the next item doesn't fit.
+type protocolPDFWriter struct {
+	doc    *pdf.Document
+	y      float64
+	margin float64
+}
+
+func (w *protocolPDFWriter) width() float64  { return w.doc.Size().Width - 2*w.margin }
+func (w *protocolPDFWriter) bottom() float64 { return w.doc.Size().Height - w.margin }
+
+// ensure starts a new page unless height points still fit on this one.
+func (w *protocolPDFWriter) ensure(height float64) {
+	if w.y+height > w.bottom() {
+		w.doc.AddPage()
+		w.y = w.margin
+	}
+}
+
+// lines prints wrapped text, indent points in from the margin.
+func (w *protocolPDFWriter) lines(font pdf.Font, size, indent float64, text string, c color.Color) {
+	leading := size * 1.35
+	w.doc.SetFillColor(c)
+	for _, line := range pdf.WrapText(font, size, text, w.width()-indent) {
+		w.ensure(leading)
+		w.y += leading
+		w.doc.Text(font, size, w.margin+indent, w.y-0.25*size, line)
+	}
+}
+
+// renderProtocolPrintout lays out every protocol in order: title, version,
+// content, and image attachments, with other attachments listed by name.
+func renderProtocolPrintout(p protocolPrintout) ([]byte, error) {
+	dark := color.RGBA{0x1f, 0x29, 0x37, 0xff}
+	muted := color.RGBA{0x4b, 0x55, 0x63, 0xff}
+	w := &protocolPDFWriter{doc: pdf.New(pdf.Letter), margin: 54}
+	w.doc.AddPage()
+	w.y = w.margin
+
+	w.lines(pdf.HelveticaBold, 22, 0, p.GroupName+" Protocols", dark)
+	w.lines(pdf.Helvetica, 10, 0, "Printed "+p.Printed.Format("January 2, 2006"), muted)
+	if len(p.Protocols) == 0 {
+		w.y += 12
+		w.lines(pdf.Helvetica, 11, 0, "This group has no protocols yet.", muted)
+	}
+
+	for i, protocol := range p.Protocols {
+		// Keep each title on the same page as the start of its content
+		w.y += 18
+		w.ensure(60)
+		w.doc.SetStrokeColor(color.RGBA{0xd1, 0xd5, 0xdb, 0xff})
+		w.doc.Line(w.margin, w.y, w.margin+w.width(), w.y, 0.75)
+		w.y += 6
+		w.lines(pdf.HelveticaBold, 15, 0, fmt.Sprintf("%d. %s", i+1, protocol.Title), dark)
+		w.lines(pdf.Helvetica, 9, 0, fmt.Sprintf("Version %d · Updated %s", protocol.Version, protocol.UpdatedAt.Format("January 2, 2006")), muted)
+		w.y += 6
+
+		for _, block := range protocolBlocks(protocol.Content, protocol.ContentFormat) {
+			if block.Text == "" {
+				w.y += block.Size * 0.6
+				continue
+			}
+			if block.Bullet != "" {
+				w.ensure(block.Size * 1.35)
+				w.doc.SetFillColor(dark)
+				w.doc.Text(block.Font, block.Size, w.margin+2, w.y+block.Size*1.1, block.Bullet)
+			}
+			w.lines(block.Font, block.Size, block.Indent, block.Text, dark)
+		}
+
+		var files []string
+		for _, attachment := range protocol.Attachments {
+			img, ok := p.Images[attachment.ID]
+			if !ok {
+				files = append(files, attachment.FileName)
+				continue
+			}
+			b := img.Bounds()
+			scale := math.Min(w.width()/float64(b.Dx()), 280/float64(b.Dy()))
+			iw, ih := float64(b.Dx())*scale, float64(b.Dy())*scale
+			w.y += 10
+			w.ensure(ih)
+			if err := w.doc.Image(img, w.margin, w.y, iw, ih); err != nil {
+				return nil, err
+			}
+			w.y += ih
+		}
+		if len(files) > 0 {
+			w.y += 8
+			w.lines(pdf.Helvetica, 9, 0, "Attachments: "+strings.Join(files, ", "), muted)
+		}
+	}
+
+	return w.doc.Bytes(), nil
+}
+
+// loadProtocolAttachmentImage fetches and decodes an image attachment,
+// scaled down for printing.
+func loadProtocolAttachmentImage(ctx context.Context, db *gorm.DB, storageProvider storage.Provider, id uint) (image.Image, error) {
+	var attachment models.ProtocolAttachment
+	if err := db.First(&attachment, id).Error; err != nil {
+		return nil, err
+	}
+	data := attachment.FileData
+	if attachment.FileProvider != storage.ProviderPostgres && attachment.FileBlobIdentifier != "" {
+		var err error
+		if data, _, err = storageProvider.GetDocument(ctx, attachment.FileBlobIdentifier); err != nil {
+			return nil, err
+		}
+	}
+	if len(data) == 0 {
+		return nil, errors.New("image data not available")
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return resize.Thumbnail(kennelCardPhotoMaxPixels, kennelCardPhotoMaxPixels, img, resize.Lanczos3), nil
+}
+
+// ExportProtocolsPDF prints all of a group's protocols, in display order, as
+// one PDF (any group member). Image attachments that can't be decoded, such
+// as HEIC, are listed by name instead.
+// Route: GET /api/groups/:id/protocols.pdf
+func ExportProtocolsPDF(db *gorm.DB, storageProvider storage.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		logger := middleware.GetLogger(c)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+			return
+		}
+
+		var group models.Group
+		if err := db.First(&group, groupID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+			return
+		}
+		if !group.HasProtocols {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Protocols not enabled for this group"})
+			return
+		}
+
+		printout := protocolPrintout{GroupName: group.Name, Printed: time.Now(), Images: map[uint]image.Image{}}
+		if err := withProtocolAttachments(db).
+			Where("group_id = ?", group.ID).
+			Order("order_index ASC, created_at ASC").
+			Find(&printout.Protocols).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch protocols"})
+			return
+		}
+
+		for _, protocol := range printout.Protocols {
+			for _, attachment := range protocol.Attachments {
+				if !attachment.IsImage {
+					continue
+				}
+				img, err := loadProtocolAttachmentImage(c.Request.Context(), db, storageProvider, attachment.ID)
+				if err != nil {
+					logger.WithField("attachment_id", attachment.ID).Warnf("Protocol image listed by name only: %v", err)
+					continue
+				}
+				printout.Images[attachment.ID] = img
+			}
+		}
+
+		doc, err := renderProtocolPrintout(printout)
+		if err != nil {
+			logger.Error("Failed to render protocols PDF", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate protocols PDF"})
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="protocols-%d.pdf"`, group.ID))
+		c.Header("Cache-Control", "no-store")
+		c.Data(http.StatusOK, "application/pdf", doc)
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/pdf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtocolBlocks(t *testing.T) {
+	content := "## Before you start\n\n- Check the **kennel card**\n2. Read [the guide](https://example.org)\n---\n```\nkeep *as is*\n```"
+	blocks := protocolBlocks(content, models.ProtocolFormatMarkdown)
+	require.Len(t, blocks, 6)
+	assert.Equal(t, protocolBlock{Text: "Before you start", Font: pdf.HelveticaBold, Size: 14}, blocks[0])
+	assert.Equal(t, "", blocks[1].Text)
+	assert.Equal(t, protocolBlock{Text: "Check the kennel card", Font: pdf.Helvetica, Size: 11, Indent: 16, Bullet: "•"}, blocks[2])
+	assert.Equal(t, "2.", blocks[3].Bullet)
+	assert.Equal(t, "Read the guide (https://example.org)", blocks[3].Text)
+	assert.Equal(t, "", blocks[4].Text, "rules print as a gap")
+	assert.Equal(t, "keep *as is*", blocks[5].Text)
+
+	// Plain text is printed as written
+	plain := protocolBlocks("## Not a heading\n- not a list", models.ProtocolFormatPlain)
+	assert.Equal(t, []string{"## Not a heading", "- not a list"}, []string{plain[0].Text, plain[1].Text})
+	assert.Empty(t, plain[0].Bullet)
+}
+
+func TestExportProtocolsPDF(t *testing.T) {
+	db, group, admin, member := setupProtocolGroup(t)
+	outsider := CreateTestUser(t, db, "outsider", "outsider@example.com", "password123", false)
+
+	c, w := protocolTestContext(admin.ID, false, group.ID, 0, http.MethodPost, ProtocolRequest{
+		Title: "Walking", Content: "# Leashes\n\n- Double-check the clip\n- Use a **martingale** collar", ContentFormat: "markdown",
+	})
+	CreateProtocol(db)(c)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var protocol models.Protocol
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &protocol))
+	assert.Equal(t, models.ProtocolFormatMarkdown, protocol.ContentFormat)
+	require.NoError(t, db.Create(&models.ProtocolAttachment{
+		ProtocolID: protocol.ID, GroupID: group.ID, IsImage: true, FileName: "clip.png",
+		FileType: "image/png", FileProvider: "postgres", FileBlobIdentifier: "clip", FileData: testPNG(t),
+	}).Error)
+
+	c, w = protocolTestContext(admin.ID, false, group.ID, 0, http.MethodPost, ProtocolRequest{
+		Title: "Feeding", Content: "Measure food with the scoop.", ContentFormat: "html",
+	})
+	CreateProtocol(db)(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "unknown content formats are rejected")
+
+	export := func(userID uint) *bytes.Buffer {
+		c, w := protocolTestContext(userID, false, group.ID, 0, http.MethodGet, nil)
+		ExportProtocolsPDF(db, &mockStorageProvider{})(c)
+		if w.Code != http.StatusOK {
+			return nil
+		}
+		assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+		return w.Body
+	}
+	assert.Nil(t, export(outsider.ID))
+	body := export(member.ID)
+	require.NotNil(t, body)
+	assert.True(t, bytes.HasPrefix(body.Bytes(), []byte("%PDF-")))
+	assert.Contains(t, body.String(), "/Subtype /Image", "image attachments are printed")
+}
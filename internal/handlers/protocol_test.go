@@ -115,7 +115,7 @@ func TestUploadProtocolImage(t *testing.T) {
 			c.Set("is_admin", tt.isAdmin)
 			c.Params = gin.Params{{Key: "id", Value: fmt.Sprintf("%d", group.ID)}}
 
-			handler := UploadProtocolImage(db, tt.provider)
+			handler := UploadProtocolImage(db, tt.provider, nil)
 			handler(c)
 
 			if w.Code != tt.expectedStatus {
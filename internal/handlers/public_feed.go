@@ -0,0 +1,399 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// Public feed formats, one route each
+const (
+	PublicFeedJSON = "json"
+	PublicFeedRSS  = "rss"
+)
+
+const (
+	// defaultPublicFeedCacheTTL is how long a built feed is served from
+	// memory (and may be cached by browsers and proxies), unless
+	// PUBLIC_FEED_CACHE_TTL_SECONDS says otherwise.
+	defaultPublicFeedCacheTTL = 5 * time.Minute
+	// publicFeedMaxAnimals caps a feed's length
+	publicFeedMaxAnimals = 500
+)
+
+var groupSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// publicFeedAnimal is an animal as the public feed shows it. Like
+// sharedAnimalResponse it is a whitelist: nothing staff-only (trainer
+// notes, quarantine details, documents) can leak into it.
+type publicFeedAnimal struct {
+	ID             uint       `json:"id"`
+	Name           string     `json:"name"`
+	Species        string     `json:"species"`
+	Breed          string     `json:"breed"`
+	AgeYears       int        `json:"age_years"`
+	AgeMonths      int        `json:"age_months"`
+	Description    string     `json:"description"`
+	ImageURL       string     `json:"image_url"`
+	AvailableSince *time.Time `json:"available_since"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// publicFeedResponse is the body of GET /public/groups/:slug/animals.json
+type publicFeedResponse struct {
+	Group   string             `json:"group"`
+	Slug    string             `json:"slug"`
+	Animals []publicFeedAnimal `json:"animals"`
+}
+
+// RSS 2.0 document, with Media RSS for the animal photos
+type rssFeed struct {
+	XMLName    xml.Name   `xml:"rss"`
+	Version    string     `xml:"version,attr"`
+	XMLNSMedia string     `xml:"xmlns:media,attr"`
+	Channel    rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string        `xml:"title"`
+	Description string        `xml:"description"`
+	GUID        rssGUID       `xml:"guid"`
+	PubDate     string        `xml:"pubDate"`
+	Media       *rssMediaItem `xml:"media:content,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssMediaItem struct {
+	URL    string `xml:"url,attr"`
+	Medium string `xml:"medium,attr"`
+}
+
+// PublicFeedRequest turns a group's public feed on or off. A slug is
+// required to turn it on; it names the feed URL.
+type PublicFeedRequest struct {
+	Enabled bool   `json:"enabled"`
+	Slug    string `json:"slug" binding:"max=60"`
+}
+
+// publicFeedSettings is a group's public feed configuration and URLs
+type publicFeedSettings struct {
+	Enabled bool   `json:"enabled"`
+	Slug    string `json:"slug"`
+	JSONURL string `json:"json_url,omitempty"`
+	RSSURL  string `json:"rss_url,omitempty"`
+}
+
+func toPublicFeedSettings(g models.Group) publicFeedSettings {
+	settings := publicFeedSettings{Enabled: g.PublicFeed, Slug: g.Slug}
+	if g.PublicFeed && g.Slug != "" {
+		base := frontendURL() + "/public/groups/" + g.Slug + "/animals."
+		settings.JSONURL = base + PublicFeedJSON
+		settings.RSSURL = base + PublicFeedRSS
+	}
+	return settings
+}
+
+// publicFeedCacheTTL reads PUBLIC_FEED_CACHE_TTL_SECONDS. Zero turns
+// caching off.
+func publicFeedCacheTTL() time.Duration {
+	if v := os.Getenv("PUBLIC_FEED_CACHE_TTL_SECONDS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return time.Duration(n) * time.Second
+		}
+	}
+	return defaultPublicFeedCacheTTL
+}
+
+// absoluteURL turns a site-relative URL such as /api/images/... into one
+// an external website can use.
+func absoluteURL(u string) string {
+	if strings.HasPrefix(u, "/") {
+		return frontendURL() + u
+	}
+	return u
+}
+
+// publicFeedEntry is one built feed
+type publicFeedEntry struct {
+	body    []byte
+	etag    string
+	expires time.Time
+}
+
+// publicFeedCache memoizes built feeds by slug for the TTL, so a popular
+// embed doesn't query the database on every page view.
+type publicFeedCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]publicFeedEntry
+}
+
+func (fc *publicFeedCache) get(slug string) (publicFeedEntry, bool) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	entry, ok := fc.entries[slug]
+	if !ok || time.Now().After(entry.expires) {
+		delete(fc.entries, slug)
+		return publicFeedEntry{}, false
+	}
+	return entry, true
+}
+
+func (fc *publicFeedCache) put(slug string, entry publicFeedEntry) {
+	if fc.ttl <= 0 {
+		return
+	}
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	entry.expires = time.Now().Add(fc.ttl)
+	fc.entries[slug] = entry
+}
+
+// etagMatches reports whether an If-None-Match header names etag
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// buildPublicFeed renders the feed of the group's available animals
+func buildPublicFeed(group models.Group, animals []models.Animal, format string) ([]byte, error) {
+	if format == PublicFeedRSS {
+		channel := rssChannel{
+			Title:       group.Name + " - Available Animals",
+			Link:        frontendURL(),
+			Description: "Animals available from " + group.Name,
+			Items:       make([]rssItem, 0, len(animals)),
+		}
+		var lastUpdate time.Time
+		for _, a := range animals {
+			published := a.CreatedAt
+			if a.ArrivalDate != nil {
+				published = *a.ArrivalDate
+			}
+			if a.UpdatedAt.After(lastUpdate) {
+				lastUpdate = a.UpdatedAt
+			}
+			item := rssItem{
+				Title:       a.Name,
+				Description: publicFeedSummary(a),
+				GUID:        rssGUID{Value: fmt.Sprintf("%s-animal-%d", group.Slug, a.ID)},
+				PubDate:     published.UTC().Format(time.RFC1123Z),
+			}
+			if a.ImageURL != "" {
+				item.Media = &rssMediaItem{URL: absoluteURL(a.ImageURL), Medium: "image"}
+			}
+			channel.Items = append(channel.Items, item)
+		}
+		if !lastUpdate.IsZero() {
+			channel.LastBuildDate = lastUpdate.UTC().Format(time.RFC1123Z)
+		}
+		body, err := xml.MarshalIndent(rssFeed{Version: "2.0", XMLNSMedia: "http://search.yahoo.com/mrss/", Channel: channel}, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(xml.Header), body...), nil
+	}
+
+	resp := publicFeedResponse{Group: group.Name, Slug: group.Slug, Animals: make([]publicFeedAnimal, 0, len(animals))}
+	for _, a := range animals {
+		years, months := a.AgeDisplay()
+		imageURL := ""
+		if a.ImageURL != "" {
+			imageURL = absoluteURL(a.ImageURL)
+		}
+		resp.Animals = append(resp.Animals, publicFeedAnimal{
+			ID:             a.ID,
+			Name:           a.Name,
+			Species:        a.Species,
+			Breed:          a.Breed,
+			AgeYears:       years,
+			AgeMonths:      months,
+			Description:    a.Description,
+			ImageURL:       imageURL,
+			AvailableSince: a.ArrivalDate,
+			UpdatedAt:      a.UpdatedAt,
+		})
+	}
+	return json.Marshal(resp)
+}
+
+// publicFeedSummary is an RSS item's description: the animal's basics,
+// then its description.
+func publicFeedSummary(a models.Animal) string {
+	var parts []string
+	for _, p := range []string{a.Species, a.Breed} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if years, months := a.AgeDisplay(); years > 0 {
+		parts = append(parts, fmt.Sprintf("%d yr", years))
+	} else if months > 0 {
+		parts = append(parts, fmt.Sprintf("%d mo", months))
+	}
+	summary := strings.Join(parts, ", ")
+	if a.Description != "" {
+		if summary != "" {
+			summary += ". "
+		}
+		summary += a.Description
+	}
+	return summary
+}
+
+// GetPublicAnimalFeed returns a group's available animals as JSON or RSS,
+// for embedding on the group's own website. No authentication: it only
+// answers for groups that turned the public feed on, and only with the
+// fields in publicFeedAnimal. Feeds are cached for
+// PUBLIC_FEED_CACHE_TTL_SECONDS (default 300), so changes, including
+// turning the feed off, can take that long to show. Responses carry an
+// ETag and answer a matching If-None-Match with 304.
+// Routes: GET /public/groups/:slug/animals.json, GET /public/groups/:slug/animals.rss
+func GetPublicAnimalFeed(db *gorm.DB, format string) gin.HandlerFunc {
+	cache := &publicFeedCache{ttl: publicFeedCacheTTL(), entries: make(map[string]publicFeedEntry)}
+	contentType := "application/json; charset=utf-8"
+	if format == PublicFeedRSS {
+		contentType = "application/rss+xml; charset=utf-8"
+	}
+
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		slug := c.Param("slug")
+
+		entry, ok := cache.get(slug)
+		if !ok {
+			var group models.Group
+			if err := db.Where("slug = ? AND public_feed = ?", slug, true).First(&group).Error; err != nil {
+				if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+					middleware.GetLogger(c).Error("Failed to load public feed group", err)
+				}
+				respondNotFound(c, "Feed not found")
+				return
+			}
+			var animals []models.Animal
+			if err := db.Where("group_id = ? AND status = ?", group.ID, "available").
+				Order("name ASC").Limit(publicFeedMaxAnimals).Find(&animals).Error; err != nil {
+				middleware.GetLogger(c).Error("Failed to load public feed animals", err)
+				respondInternalError(c, "Failed to load feed")
+				return
+			}
+			body, err := buildPublicFeed(group, animals, format)
+			if err != nil {
+				middleware.GetLogger(c).Error("Failed to build public feed", err)
+				respondInternalError(c, "Failed to load feed")
+				return
+			}
+			sum := sha256.Sum256(body)
+			entry = publicFeedEntry{body: body, etag: fmt.Sprintf(`"%x"`, sum[:16])}
+			cache.put(slug, entry)
+		}
+
+		// Any website may embed the feed; it's public and carries no cookies
+		c.Header("Access-Control-Allow-Origin", "*")
+		c.Writer.Header().Del("Access-Control-Allow-Credentials")
+		c.Header("ETag", entry.etag)
+		if cache.ttl > 0 {
+			c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(cache.ttl.Seconds())))
+		} else {
+			c.Header("Cache-Control", "no-cache")
+		}
+		if etagMatches(c.GetHeader("If-None-Match"), entry.etag) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+		c.Data(http.StatusOK, contentType, entry.body)
+	}
+}
+
+// UpdateGroupPublicFeed turns a group's public feed on or off and sets its
+// slug (group admin or site admin).
+// Route: PUT /api/groups/:id/public-feed
+func UpdateGroupPublicFeed(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		group, ok := loadBrandingGroup(c, db)
+		if !ok {
+			return
+		}
+		if !IsGroupAdminOrSiteAdmin(c, db, group.ID) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Group admin access required")
+			return
+		}
+
+		var req PublicFeedRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		slug := strings.ToLower(strings.TrimSpace(req.Slug))
+		if req.Enabled && slug == "" {
+			respondBadRequest(c, "A slug is required to turn on the public feed")
+			return
+		}
+		if slug != "" && (len(slug) < 2 || !groupSlugPattern.MatchString(slug)) {
+			respondBadRequest(c, "Slug must be at least 2 characters of lowercase letters, numbers, and single hyphens")
+			return
+		}
+		if slug != "" {
+			var taken int64
+			if err := db.Model(&models.Group{}).Where("slug = ? AND id <> ?", slug, group.ID).Count(&taken).Error; err != nil {
+				middleware.GetLogger(c).Error("Failed to check group slug", err)
+				respondInternalError(c, "Failed to update public feed")
+				return
+			}
+			if taken > 0 {
+				respondError(c, http.StatusConflict, ErrCodeConflict, "That slug is already used by another group")
+				return
+			}
+		}
+
+		if err := db.Model(&group).Updates(map[string]interface{}{
+			"public_feed": req.Enabled,
+			"slug":        slug,
+		}).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to update public feed", err)
+			respondInternalError(c, "Failed to update public feed")
+			return
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventGroupUpdated, userID, map[string]interface{}{
+			"group_id": group.ID,
+			"change":   "public_feed",
+			"enabled":  req.Enabled,
+		})
+		respondOK(c, toPublicFeedSettings(group))
+	}
+}
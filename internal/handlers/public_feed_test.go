@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateGroupPublicFeed(t *testing.T) {
+	db := SetupTestDB(t)
+	groupAdmin := CreateTestUser(t, db, "groupadmin", "groupadmin@example.com", "password123", false)
+	member := CreateTestUser(t, db, "member", "member@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	other := CreateTestGroup(t, db, "Cats", "")
+	AddUserToGroupWithAdmin(t, db, groupAdmin.ID, group.ID, true)
+	AddUserToGroupWithAdmin(t, db, member.ID, group.ID, false)
+	require.NoError(t, db.Model(other).Update("slug", "cats").Error)
+
+	update := func(userID uint, body PublicFeedRequest) *httptest.ResponseRecorder {
+		c, w := accountTestContext(userID, false, http.MethodPut, "/", body)
+		c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}}
+		UpdateGroupPublicFeed(db)(c)
+		return w
+	}
+
+	assert.Equal(t, http.StatusForbidden, update(member.ID, PublicFeedRequest{Enabled: true, Slug: "dogs"}).Code)
+	assert.Equal(t, http.StatusBadRequest, update(groupAdmin.ID, PublicFeedRequest{Enabled: true}).Code)
+	assert.Equal(t, http.StatusBadRequest, update(groupAdmin.ID, PublicFeedRequest{Enabled: true, Slug: "dogs--"}).Code)
+	assert.Equal(t, http.StatusConflict, update(groupAdmin.ID, PublicFeedRequest{Enabled: true, Slug: "cats"}).Code)
+
+	w := update(groupAdmin.ID, PublicFeedRequest{Enabled: true, Slug: " Happy-Tails "})
+	require.Equal(t, http.StatusOK, w.Code)
+	var settings publicFeedSettings
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &settings))
+	assert.Equal(t, "happy-tails", settings.Slug)
+	assert.Contains(t, settings.JSONURL, "/public/groups/happy-tails/animals.json")
+
+	var saved models.Group
+	require.NoError(t, db.First(&saved, group.ID).Error)
+	assert.True(t, saved.PublicFeed)
+	assert.Equal(t, "happy-tails", saved.Slug)
+}
+
+func TestGetPublicAnimalFeed(t *testing.T) {
+	db := SetupTestDB(t)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	hidden := CreateTestGroup(t, db, "Cats", "")
+	require.NoError(t, db.Model(group).Updates(map[string]interface{}{"public_feed": true, "slug": "dogs"}).Error)
+	require.NoError(t, db.Model(hidden).Update("slug", "cats").Error)
+	require.NoError(t, db.Create(&models.Animal{
+		GroupID: group.ID, Name: "Rex", Species: "Dog", Breed: "Lab", Status: "available",
+		Description: "Loves fetch", TrainerNotes: "Staff only", ImageURL: "/api/images/rex",
+	}).Error)
+	require.NoError(t, db.Create(&models.Animal{GroupID: group.ID, Name: "Fido", Status: "foster"}).Error)
+
+	fetch := func(format, slug, ifNoneMatch string) *httptest.ResponseRecorder {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		router := gin.New()
+		router.GET("/public/groups/:slug/animals."+format, GetPublicAnimalFeed(db, format))
+		req := httptest.NewRequest(http.MethodGet, "/public/groups/"+slug+"/animals."+format, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusNotFound, fetch(PublicFeedJSON, "cats", "").Code)
+	assert.Equal(t, http.StatusNotFound, fetch(PublicFeedJSON, "nope", "").Code)
+
+	w := fetch(PublicFeedJSON, "dogs", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "public, max-age=300", w.Header().Get("Cache-Control"))
+	assert.NotContains(t, w.Body.String(), "Staff only")
+	var feed publicFeedResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &feed))
+	require.Len(t, feed.Animals, 1, "only available animals are listed")
+	assert.Equal(t, "Rex", feed.Animals[0].Name)
+	assert.Equal(t, frontendURL()+"/api/images/rex", feed.Animals[0].ImageURL)
+
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Equal(t, http.StatusNotModified, fetch(PublicFeedJSON, "dogs", etag).Code)
+	assert.Equal(t, http.StatusOK, fetch(PublicFeedJSON, "dogs", `"stale"`).Code)
+
+	w = fetch(PublicFeedRSS, "dogs", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/rss+xml")
+	var rss rssFeed
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &rss))
+	require.Len(t, rss.Channel.Items, 1)
+	assert.Equal(t, "Rex", rss.Channel.Items[0].Title)
+	assert.Equal(t, "Dog, Lab. Loves fetch", rss.Channel.Items[0].Description)
+	assert.Contains(t, w.Body.String(), `<media:content url="`+frontendURL()+`/api/images/rex" medium="image">`)
+}
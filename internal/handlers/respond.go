@@ -1,19 +1,138 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 )
 
 // Response helpers for standardized HTTP responses within the handlers package.
 // Use these incrementally when touching a handler — do not mass-replace existing c.JSON calls.
+// Error helpers go through respondError, so every migrated call site gains a
+// machine-readable code for clients that opt in to structured errors.
 
-func respondOK(c *gin.Context, data any)              { c.JSON(http.StatusOK, data) }
-func respondCreated(c *gin.Context, data any)         { c.JSON(http.StatusCreated, data) }
-func respondNoContent(c *gin.Context)                 { c.Status(http.StatusNoContent) }
-func respondBadRequest(c *gin.Context, msg string)    { c.JSON(http.StatusBadRequest, gin.H{"error": msg}) }
-func respondUnauthorized(c *gin.Context, msg string)  { c.JSON(http.StatusUnauthorized, gin.H{"error": msg}) }
-func respondForbidden(c *gin.Context, msg string)     { c.JSON(http.StatusForbidden, gin.H{"error": msg}) }
-func respondNotFound(c *gin.Context, msg string)      { c.JSON(http.StatusNotFound, gin.H{"error": msg}) }
-func respondInternalError(c *gin.Context, msg string) { c.JSON(http.StatusInternalServerError, gin.H{"error": msg}) }
+func respondOK(c *gin.Context, data any)      { c.JSON(http.StatusOK, data) }
+func respondCreated(c *gin.Context, data any) { c.JSON(http.StatusCreated, data) }
+func respondNoContent(c *gin.Context)         { c.Status(http.StatusNoContent) }
+func respondBadRequest(c *gin.Context, msg string) {
+	respondError(c, http.StatusBadRequest, ErrCodeBadRequest, msg)
+}
+func respondUnauthorized(c *gin.Context, msg string) {
+	respondError(c, http.StatusUnauthorized, ErrCodeUnauthorized, msg)
+}
+func respondForbidden(c *gin.Context, msg string) {
+	respondError(c, http.StatusForbidden, ErrCodeForbidden, msg)
+}
+func respondNotFound(c *gin.Context, msg string) {
+	respondError(c, http.StatusNotFound, ErrCodeNotFound, msg)
+}
+func respondInternalError(c *gin.Context, msg string) {
+	respondError(c, http.StatusInternalServerError, ErrCodeInternal, msg)
+}
+
+// ErrorCode is a stable, machine-readable identifier for an API error.
+// Clients should branch on the code, never on the human-readable message,
+// which may be reworded at any time.
+type ErrorCode string
+
+// Generic error codes, one per HTTP failure class.
+const (
+	ErrCodeBadRequest       ErrorCode = "BAD_REQUEST"
+	ErrCodeValidationFailed ErrorCode = "VALIDATION_FAILED"
+	ErrCodeUnauthorized     ErrorCode = "UNAUTHORIZED"
+	ErrCodeForbidden        ErrorCode = "FORBIDDEN"
+	ErrCodeNotFound         ErrorCode = "NOT_FOUND"
+	ErrCodeConflict         ErrorCode = "CONFLICT"
+	ErrCodeInternal         ErrorCode = "INTERNAL_ERROR"
+)
+
+// Resource-specific error codes.
+const (
+	ErrCodeGroupNotFound       ErrorCode = "GROUP_NOT_FOUND"
+	ErrCodeAnimalNotFound      ErrorCode = "ANIMAL_NOT_FOUND"
+	ErrCodeUserNotFound        ErrorCode = "USER_NOT_FOUND"
+	ErrCodeGroupAccessDenied   ErrorCode = "GROUP_ACCESS_DENIED"
+	ErrCodeAdminAccessRequired ErrorCode = "ADMIN_ACCESS_REQUIRED"
+	ErrCodeInvalidID           ErrorCode = "INVALID_ID"
+)
+
+// StructuredErrorsMediaType is the Accept media type clients send to opt in
+// to structured error bodies. Sending "X-API-Version: 2" has the same effect.
+const StructuredErrorsMediaType = "application/vnd.volunteer-media.v2+json"
+
+// FieldError describes a single invalid request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+// animalFieldError is a detail naming one animal that blocked a request,
+// e.g. a held animal or a bonded partner. Field is "animal:<id>".
+func animalFieldError(animalID uint, rule, msg string) FieldError {
+	return FieldError{Field: fmt.Sprintf("animal:%d", animalID), Rule: rule, Message: msg}
+}
+
+// ErrorResponse is the structured error body returned to clients that opt in
+// via structuredErrorsRequested. Error always carries the same human-readable
+// message legacy clients receive, so the structured body is a strict superset
+// of the legacy {"error": "..."} shape.
+type ErrorResponse struct {
+	Error   string       `json:"error"`
+	Code    ErrorCode    `json:"code"`
+	Details []FieldError `json:"details,omitempty"`
+}
+
+// structuredErrorsRequested reports whether the client asked for structured
+// error bodies. Existing clients that send neither header keep receiving the
+// legacy {"error": "..."} body unchanged.
+func structuredErrorsRequested(c *gin.Context) bool {
+	if c.Request == nil {
+		return false
+	}
+	if strings.TrimSpace(c.GetHeader("X-API-Version")) == "2" {
+		return true
+	}
+	return strings.Contains(c.GetHeader("Accept"), StructuredErrorsMediaType)
+}
+
+// respondError writes an error response with a machine-readable code. The
+// code and details are only included for clients that opt in to structured
+// errors; everyone else receives {"error": msg}.
+func respondError(c *gin.Context, status int, code ErrorCode, msg string, details ...FieldError) {
+	if !structuredErrorsRequested(c) {
+		c.JSON(status, gin.H{"error": msg})
+		return
+	}
+	c.JSON(status, ErrorResponse{Error: msg, Code: code, Details: details})
+}
+
+// respondValidationError writes a 400 VALIDATION_FAILED response for a
+// request-binding error. validator.ValidationErrors are expanded into
+// per-field details; any other error (e.g. malformed JSON) is reported
+// without details.
+func respondValidationError(c *gin.Context, err error) {
+	respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, formatValidationError(err), validationDetails(err)...)
+}
+
+// validationDetails converts validator.ValidationErrors into FieldErrors,
+// reusing fieldErrMsg so detail messages match formatValidationError's.
+func validationDetails(err error) []FieldError {
+	var ve validator.ValidationErrors
+	if !errors.As(err, &ve) {
+		return nil
+	}
+	details := make([]FieldError, 0, len(ve))
+	for _, fe := range ve {
+		details = append(details, FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Message: fieldErrMsg(fe),
+		})
+	}
+	return details
+}
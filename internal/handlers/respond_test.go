@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRespondTestContext(headers map[string]string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/test", nil)
+	for k, v := range headers {
+		c.Request.Header.Set(k, v)
+	}
+	return c, w
+}
+
+func TestRespondError_LegacyClientsGetPlainErrorBody(t *testing.T) {
+	c, w := newRespondTestContext(nil)
+
+	respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, "Group not found")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, map[string]interface{}{"error": "Group not found"}, body)
+}
+
+func TestRespondError_StructuredWhenRequested(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+	}{
+		{name: "X-API-Version header", headers: map[string]string{"X-API-Version": "2"}},
+		{name: "vendor Accept media type", headers: map[string]string{"Accept": StructuredErrorsMediaType}},
+		{name: "vendor media type among others", headers: map[string]string{"Accept": "application/json, " + StructuredErrorsMediaType}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newRespondTestContext(tt.headers)
+
+			respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, "Group not found")
+
+			assert.Equal(t, http.StatusNotFound, w.Code)
+			var body ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, ErrCodeGroupNotFound, body.Code)
+			assert.Equal(t, "Group not found", body.Error)
+			assert.Empty(t, body.Details)
+		})
+	}
+}
+
+func TestRespondError_NilRequestFallsBackToLegacy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	respondForbidden(c, "Access denied")
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.JSONEq(t, `{"error":"Access denied"}`, w.Body.String())
+}
+
+func TestRespondValidationError_IncludesFieldDetails(t *testing.T) {
+	type payload struct {
+		Name  string `json:"name" binding:"required"`
+		Email string `json:"email" binding:"required,email"`
+	}
+	err := binding.Validator.ValidateStruct(payload{Email: "not-an-email"})
+	require.Error(t, err)
+
+	c, w := newRespondTestContext(map[string]string{"X-API-Version": "2"})
+	respondValidationError(c, err)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var body ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, ErrCodeValidationFailed, body.Code)
+	require.Len(t, body.Details, 2)
+	assert.Equal(t, "required", body.Details[0].Rule)
+	assert.Equal(t, "email", body.Details[1].Rule)
+	assert.Equal(t, formatValidationError(err), body.Error)
+}
+
+func TestRespondValidationError_NonValidatorErrorHasNoDetails(t *testing.T) {
+	c, w := newRespondTestContext(map[string]string{"X-API-Version": "2"})
+
+	respondValidationError(c, errors.New("unexpected EOF"))
+
+	var body ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, ErrCodeValidationFailed, body.Code)
+	assert.Equal(t, "unexpected EOF", body.Error)
+	assert.Nil(t, body.Details)
+}
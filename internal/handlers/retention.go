@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/maintenance"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
+	"gorm.io/gorm"
+)
+
+// RunRetentionRequest selects between reporting and purging
+type RunRetentionRequest struct {
+	// DryRun defaults to true so purging is always an explicit choice
+	DryRun *bool `json:"dry_run"`
+}
+
+// GetRetentionPolicy returns the configured retention rules along with a
+// dry-run report of what each would purge right now (site admin only)
+// Route: GET /api/admin/retention
+func GetRetentionPolicy(db *gorm.DB, storageProvider storage.Provider, policy maintenance.RetentionPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		adminID, _ := middleware.GetUserID(c)
+		report := maintenance.ApplyRetention(c.Request.Context(), db, storageProvider, policy, true, adminID)
+		respondOK(c, gin.H{
+			"policy": policy,
+			"report": report,
+		})
+	}
+}
+
+// RunRetentionPurge applies the retention policy now instead of waiting for
+// the scheduled run (site admin only). Pass {"dry_run": false} to actually
+// purge; otherwise it only reports. Every run is written to the audit log.
+// Route: POST /api/admin/retention/run
+func RunRetentionPurge(db *gorm.DB, storageProvider storage.Provider, policy maintenance.RetentionPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		var req RunRetentionRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				respondBadRequest(c, "Invalid request body")
+				return
+			}
+		}
+		dryRun := req.DryRun == nil || *req.DryRun
+
+		adminID, _ := middleware.GetUserID(c)
+		results := maintenance.ApplyRetention(c.Request.Context(), db, storageProvider, policy, dryRun, adminID)
+		for _, result := range results {
+			if result.Error != "" {
+				middleware.GetLogger(c).WithField("entity", result.Entity).Warn("Retention purge finished with errors")
+			}
+		}
+		respondOK(c, gin.H{
+			"dry_run": dryRun,
+			"results": results,
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/maintenance"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetentionPolicyFromEnv(t *testing.T) {
+	t.Setenv("RETENTION_DELETED_USERS_DAYS", "90")
+	t.Setenv("RETENTION_COMMENTS_DAYS", "not-a-number")
+	t.Setenv("RETENTION_DRY_RUN", "true")
+	policy := maintenance.RetentionPolicyFromEnv()
+	require.Len(t, policy.Rules, 3)
+	days := map[string]int{}
+	for _, rule := range policy.Rules {
+		days[rule.Entity] = rule.Days
+	}
+	assert.Equal(t, 90, days[maintenance.RetentionDeletedUsers])
+	assert.Equal(t, 0, days[maintenance.RetentionComments])
+	assert.Equal(t, 0, days[maintenance.RetentionStaleUploads])
+	assert.True(t, policy.DryRun)
+}
+
+func TestRunRetentionPurge(t *testing.T) {
+	db := SetupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AnimalImage{}, &models.AnimalImageVariant{}))
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	group := CreateTestGroup(t, db, "Dogs", "Dog group")
+	animal := CreateTestAnimal(t, db, group.ID, "Buddy", "Dog")
+	old := time.Now().AddDate(0, 0, -100)
+
+	longGone := CreateTestUser(t, db, "longgone", "longgone@example.com", "password123", false)
+	recentlyGone := CreateTestUser(t, db, "recentlygone", "recentlygone@example.com", "password123", false)
+	require.NoError(t, db.Delete(&models.User{}, longGone.ID).Error)
+	require.NoError(t, db.Model(&models.User{}).Unscoped().Where("id = ?", longGone.ID).Update("deleted_at", old).Error)
+	require.NoError(t, db.Delete(&models.User{}, recentlyGone.ID).Error)
+
+	oldComment := models.AnimalComment{AnimalID: animal.ID, UserID: admin.ID, Content: "old", CreatedAt: old}
+	require.NoError(t, db.Create(&oldComment).Error)
+	require.NoError(t, db.Create(&models.CommentReaction{CommentID: oldComment.ID, UserID: admin.ID, Type: "like"}).Error)
+	newComment := models.AnimalComment{AnimalID: animal.ID, UserID: admin.ID, Content: "new"}
+	require.NoError(t, db.Create(&newComment).Error)
+
+	stale := models.AnimalImage{UserID: admin.ID, ImageURL: "/api/images/stale", StorageProvider: storage.ProviderAzure, BlobIdentifier: "blob-stale", CreatedAt: old}
+	linked := models.AnimalImage{UserID: admin.ID, ImageURL: "/api/images/hero", CreatedAt: old}
+	fresh := models.AnimalImage{UserID: admin.ID, ImageURL: "/api/images/fresh"}
+	for _, img := range []*models.AnimalImage{&stale, &linked, &fresh} {
+		require.NoError(t, db.Create(img).Error)
+	}
+	require.NoError(t, db.Model(&group).Update("hero_image_url", linked.ImageURL).Error)
+
+	policy := maintenance.RetentionPolicy{Rules: []maintenance.RetentionRule{
+		{Entity: maintenance.RetentionDeletedUsers, Action: maintenance.RetentionActionAnonymize, Days: 30},
+		{Entity: maintenance.RetentionComments, Action: maintenance.RetentionActionDelete, Days: 30},
+		{Entity: maintenance.RetentionStaleUploads, Action: maintenance.RetentionActionDelete, Days: 30},
+	}}
+	storageProvider := &mockStorageProvider{}
+
+	type runResponse struct {
+		DryRun  bool                          `json:"dry_run"`
+		Results []maintenance.RetentionResult `json:"results"`
+	}
+	run := func(body any) runResponse {
+		c, w := accountTestContext(admin.ID, true, http.MethodPost, "/api/admin/retention/run", body)
+		RunRetentionPurge(db, storageProvider, policy)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp runResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp
+	}
+	counts := func(results []maintenance.RetentionResult) map[string][2]int64 {
+		out := map[string][2]int64{}
+		for _, r := range results {
+			assert.Empty(t, r.Error)
+			out[r.Entity] = [2]int64{r.Matched, r.Purged}
+		}
+		return out
+	}
+
+	// Without a body it only reports
+	resp := run(nil)
+	assert.True(t, resp.DryRun)
+	assert.Equal(t, map[string][2]int64{
+		maintenance.RetentionDeletedUsers: {1, 0},
+		maintenance.RetentionComments:     {1, 0},
+		maintenance.RetentionStaleUploads: {1, 0},
+	}, counts(resp.Results))
+	var commentCount int64
+	db.Model(&models.AnimalComment{}).Count(&commentCount)
+	assert.Equal(t, int64(2), commentCount)
+
+	resp = run(gin.H{"dry_run": false})
+	assert.False(t, resp.DryRun)
+	assert.Equal(t, map[string][2]int64{
+		maintenance.RetentionDeletedUsers: {1, 1},
+		maintenance.RetentionComments:     {1, 1},
+		maintenance.RetentionStaleUploads: {1, 1},
+	}, counts(resp.Results))
+
+	var anonymized models.User
+	require.NoError(t, db.Unscoped().First(&anonymized, longGone.ID).Error)
+	assert.NotNil(t, anonymized.AnonymizedAt)
+	assert.NotEqual(t, "longgone", anonymized.Username)
+	var kept models.User
+	require.NoError(t, db.Unscoped().First(&kept, recentlyGone.ID).Error)
+	assert.Nil(t, kept.AnonymizedAt)
+
+	var remaining []uint
+	require.NoError(t, db.Unscoped().Model(&models.AnimalComment{}).Pluck("id", &remaining).Error)
+	assert.Equal(t, []uint{newComment.ID}, remaining)
+	var reactionCount int64
+	db.Model(&models.CommentReaction{}).Count(&reactionCount)
+	assert.Zero(t, reactionCount)
+
+	require.NoError(t, db.Unscoped().Model(&models.AnimalImage{}).Order("id").Pluck("id", &remaining).Error)
+	assert.Equal(t, []uint{linked.ID, fresh.ID}, remaining)
+	assert.Equal(t, []string{"blob-stale"}, storageProvider.DeletedBlobs)
+
+	// Nothing is left past the window
+	assert.Equal(t, map[string][2]int64{
+		maintenance.RetentionDeletedUsers: {0, 0},
+		maintenance.RetentionComments:     {0, 0},
+		maintenance.RetentionStaleUploads: {0, 0},
+	}, counts(run(gin.H{"dry_run": false}).Results))
+}
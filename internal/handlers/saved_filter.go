@@ -0,0 +1,347 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	maxSavedFiltersPerGroup = 25
+	maxFilterParamLength    = 200
+)
+
+// SavedFilterRequest is the body for creating or replacing a saved filter.
+// Params are animal list query parameters: status, name, size,
+// energy_level, and field.<key>.
+type SavedFilterRequest struct {
+	Name      string            `json:"name" binding:"required,max=100"`
+	Params    map[string]string `json:"params" binding:"required"`
+	IsDefault bool              `json:"is_default"`
+}
+
+// isAnimalFilterParam reports whether name is a query parameter that
+// filters GET /animals.
+func isAnimalFilterParam(name string) bool {
+	if name == "status" || name == "name" || isAnimalFacetParam(name) {
+		return true
+	}
+	key, ok := strings.CutPrefix(name, "field.")
+	return ok && customFieldKeyPattern.MatchString(key)
+}
+
+// normalizeFilterParams trims a saved filter's values and checks that every
+// parameter is one GET /animals understands.
+func normalizeFilterParams(params map[string]string) (models.FilterParams, error) {
+	if len(params) == 0 {
+		return nil, errors.New("params must include at least one filter")
+	}
+	out := make(models.FilterParams, len(params))
+	for name, value := range params {
+		if !isAnimalFilterParam(name) {
+			return nil, fmt.Errorf("unsupported filter %q", name)
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return nil, fmt.Errorf("filter %q must have a value", name)
+		}
+		if len(value) > maxFilterParamLength {
+			return nil, fmt.Errorf("filter %q must be at most %d characters", name, maxFilterParamLength)
+		}
+		if isAnimalFacetParam(name) {
+			if _, err := animalFacetFilters(url.Values{name: {value}}); err != nil {
+				return nil, err
+			}
+		}
+		out[name] = value
+	}
+	return out, nil
+}
+
+// animalListParams returns the filters GET /animals should apply. An
+// explicit saved_filter=<id> applies that filter, with any filters in the
+// query string taking precedence over its values. With no filters in the
+// query string the user's default saved filter for the group applies, unless
+// saved_filter=none. The saved filter used, if any, is returned too.
+func animalListParams(c *gin.Context, db *gorm.DB) (url.Values, *models.SavedFilter, bool) {
+	query := c.Request.URL.Query()
+	selected := query.Get("saved_filter")
+	if selected == "none" {
+		return query, nil, true
+	}
+	uid, ok := middleware.GetUserID(c)
+	if !ok {
+		return query, nil, true
+	}
+
+	var filter models.SavedFilter
+	scope := db.Where("user_id = ? AND group_id = ?", uid, c.Param("id"))
+	if selected != "" {
+		id, err := strconv.ParseUint(selected, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid saved filter ID")
+			return nil, nil, false
+		}
+		if err := scope.Where("id = ?", id).First(&filter).Error; err != nil {
+			respondNotFound(c, "Saved filter not found")
+			return nil, nil, false
+		}
+	} else {
+		for name := range query {
+			if isAnimalFilterParam(name) {
+				return query, nil, true
+			}
+		}
+		err := scope.Where("is_default = ?", true).First(&filter).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return query, nil, true
+		}
+		if err != nil {
+			// Best-effort: fall back to the unfiltered list
+			middleware.GetLogger(c).Error("Failed to load default saved filter", err)
+			return query, nil, true
+		}
+	}
+
+	params := url.Values{}
+	for name, value := range filter.Params {
+		params.Set(name, value)
+	}
+	for name, values := range query {
+		if isAnimalFilterParam(name) {
+			params[name] = values
+		}
+	}
+	return params, &filter, true
+}
+
+// setDefaultSavedFilter makes filter the user's only default for its group.
+func setDefaultSavedFilter(tx *gorm.DB, filter *models.SavedFilter) error {
+	return tx.Model(&models.SavedFilter{}).
+		Where("user_id = ? AND group_id = ? AND id <> ? AND is_default = ?", filter.UserID, filter.GroupID, filter.ID, true).
+		Update("is_default", false).Error
+}
+
+// savedFilterNameTaken reports whether the user already has another filter
+// with this name (ignoring case) in the group.
+func savedFilterNameTaken(db *gorm.DB, userID, groupID, excludeID uint, name string) (bool, error) {
+	var count int64
+	err := db.Model(&models.SavedFilter{}).
+		Where("user_id = ? AND group_id = ? AND id <> ? AND LOWER(name) = LOWER(?)", userID, groupID, excludeID, name).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// bindSavedFilter reads and validates a SavedFilterRequest, responding 400
+// on failure.
+func bindSavedFilter(c *gin.Context) (*SavedFilterRequest, models.FilterParams, bool) {
+	var req SavedFilterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, err)
+		return nil, nil, false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		respondBadRequest(c, "Name is required")
+		return nil, nil, false
+	}
+	params, err := normalizeFilterParams(req.Params)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return nil, nil, false
+	}
+	return &req, params, true
+}
+
+// savedFilterContext checks group access and returns the current user's ID
+// and the :id group ID.
+func savedFilterContext(c *gin.Context, db *gorm.DB) (uint, uint, bool) {
+	userID, _ := c.Get("user_id")
+	isAdmin, _ := c.Get("is_admin")
+	if !checkGroupAccess(db, userID, isAdmin, c.Param("id")) {
+		respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+		return 0, 0, false
+	}
+	uid, ok := middleware.GetUserID(c)
+	if !ok {
+		respondInternalError(c, "User context not found")
+		return 0, 0, false
+	}
+	groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+		return 0, 0, false
+	}
+	return uid, uint(groupID), true
+}
+
+// GetSavedFilters returns the current user's saved filters for a group
+// Route: GET /api/groups/:id/saved-filters
+func GetSavedFilters(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		uid, groupID, ok := savedFilterContext(c, db)
+		if !ok {
+			return
+		}
+
+		var filters []models.SavedFilter
+		if err := db.Where("user_id = ? AND group_id = ?", uid, groupID).Order("LOWER(name)").Find(&filters).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to fetch saved filters", err)
+			respondInternalError(c, "Failed to fetch saved filters")
+			return
+		}
+
+		respondOK(c, filters)
+	}
+}
+
+// CreateSavedFilter saves a named animal filter for the current user
+// Route: POST /api/groups/:id/saved-filters
+func CreateSavedFilter(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		uid, groupID, ok := savedFilterContext(c, db)
+		if !ok {
+			return
+		}
+		req, params, ok := bindSavedFilter(c)
+		if !ok {
+			return
+		}
+
+		var count int64
+		if err := db.Model(&models.SavedFilter{}).Where("user_id = ? AND group_id = ?", uid, groupID).Count(&count).Error; err != nil {
+			respondInternalError(c, "Failed to save filter")
+			return
+		}
+		if count >= maxSavedFiltersPerGroup {
+			respondBadRequest(c, fmt.Sprintf("You can save at most %d filters per group", maxSavedFiltersPerGroup))
+			return
+		}
+		taken, err := savedFilterNameTaken(db, uid, groupID, 0, req.Name)
+		if err != nil {
+			respondInternalError(c, "Failed to save filter")
+			return
+		}
+		if taken {
+			respondError(c, http.StatusConflict, ErrCodeConflict, "You already have a saved filter with that name")
+			return
+		}
+
+		filter := models.SavedFilter{
+			UserID:    uid,
+			GroupID:   groupID,
+			Name:      req.Name,
+			Params:    params,
+			IsDefault: req.IsDefault,
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&filter).Error; err != nil {
+				return err
+			}
+			if filter.IsDefault {
+				return setDefaultSavedFilter(tx, &filter)
+			}
+			return nil
+		}); err != nil {
+			middleware.GetLogger(c).Error("Failed to create saved filter", err)
+			respondInternalError(c, "Failed to save filter")
+			return
+		}
+
+		respondCreated(c, filter)
+	}
+}
+
+// loadSavedFilter loads the current user's :filterId filter in the group,
+// responding 404 if there is none.
+func loadSavedFilter(c *gin.Context, db *gorm.DB, uid, groupID uint) (*models.SavedFilter, bool) {
+	var filter models.SavedFilter
+	if err := db.Where("id = ? AND user_id = ? AND group_id = ?", c.Param("filterId"), uid, groupID).First(&filter).Error; err != nil {
+		respondNotFound(c, "Saved filter not found")
+		return nil, false
+	}
+	return &filter, true
+}
+
+// UpdateSavedFilter replaces one of the current user's saved filters
+// Route: PUT /api/groups/:id/saved-filters/:filterId
+func UpdateSavedFilter(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		uid, groupID, ok := savedFilterContext(c, db)
+		if !ok {
+			return
+		}
+		filter, ok := loadSavedFilter(c, db, uid, groupID)
+		if !ok {
+			return
+		}
+		req, params, ok := bindSavedFilter(c)
+		if !ok {
+			return
+		}
+
+		taken, err := savedFilterNameTaken(db, uid, groupID, filter.ID, req.Name)
+		if err != nil {
+			respondInternalError(c, "Failed to update saved filter")
+			return
+		}
+		if taken {
+			respondError(c, http.StatusConflict, ErrCodeConflict, "You already have a saved filter with that name")
+			return
+		}
+
+		filter.Name = req.Name
+		filter.Params = params
+		filter.IsDefault = req.IsDefault
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(filter).Select("name", "params", "is_default").Updates(filter).Error; err != nil {
+				return err
+			}
+			if filter.IsDefault {
+				return setDefaultSavedFilter(tx, filter)
+			}
+			return nil
+		}); err != nil {
+			middleware.GetLogger(c).Error("Failed to update saved filter", err)
+			respondInternalError(c, "Failed to update saved filter")
+			return
+		}
+
+		respondOK(c, filter)
+	}
+}
+
+// DeleteSavedFilter removes one of the current user's saved filters
+// Route: DELETE /api/groups/:id/saved-filters/:filterId
+func DeleteSavedFilter(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		uid, groupID, ok := savedFilterContext(c, db)
+		if !ok {
+			return
+		}
+		filter, ok := loadSavedFilter(c, db, uid, groupID)
+		if !ok {
+			return
+		}
+
+		if err := db.Delete(filter).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to delete saved filter", err)
+			respondInternalError(c, "Failed to delete saved filter")
+			return
+		}
+
+		respondOK(c, gin.H{"message": "Saved filter deleted"})
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSavedFilters(t *testing.T) {
+	db := SetupTestDB(t)
+	user := CreateTestUser(t, db, "volunteer", "volunteer@example.com", "password123", false)
+	other := CreateTestUser(t, db, "other", "other@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "Dog group")
+	AddUserToGroupWithAdmin(t, db, user.ID, group.ID, false)
+	AddUserToGroupWithAdmin(t, db, other.ID, group.ID, false)
+	CreateTestAnimal(t, db, group.ID, "Rex", "Dog")
+	fostered := CreateTestAnimal(t, db, group.ID, "Buddy", "Dog")
+	require.NoError(t, db.Model(fostered).Update("status", "foster").Error)
+	groupParam := gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}}
+
+	create := func(userID uint, body any) (int, models.SavedFilter) {
+		c, w := accountTestContext(userID, false, http.MethodPost, "/api/groups/1/saved-filters", body)
+		c.Params = groupParam
+		CreateSavedFilter(db)(c)
+		var filter models.SavedFilter
+		_ = json.Unmarshal(w.Body.Bytes(), &filter)
+		return w.Code, filter
+	}
+	listAnimals := func(userID uint, query string) (*http.Response, []string) {
+		c, w := accountTestContext(userID, false, http.MethodGet, "/api/groups/1/animals"+query, nil)
+		c.Params = groupParam
+		GetAnimals(db)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var animals []models.Animal
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &animals))
+		names := make([]string, len(animals))
+		for i, a := range animals {
+			names[i] = a.Name
+		}
+		return w.Result(), names
+	}
+
+	code, _ := create(user.ID, map[string]any{"name": "Bad", "params": map[string]string{"sort": "name"}})
+	assert.Equal(t, http.StatusBadRequest, code, "only animal filters can be saved")
+	code, _ = create(user.ID, map[string]any{"name": "Empty", "params": map[string]string{}})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, fosters := create(user.ID, map[string]any{"name": "Fosters", "params": map[string]string{"status": " foster "}, "is_default": true})
+	require.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, "foster", fosters.Params["status"])
+	code, _ = create(user.ID, map[string]any{"name": "fosters", "params": map[string]string{"name": "rex"}})
+	assert.Equal(t, http.StatusConflict, code, "names are unique per user and group, ignoring case")
+
+	// The default applies when no filters are given
+	resp, names := listAnimals(user.ID, "")
+	assert.Equal(t, []string{"Buddy"}, names)
+	assert.Equal(t, fmt.Sprint(fosters.ID), resp.Header.Get("X-Saved-Filter-Id"))
+	_, names = listAnimals(user.ID, "?status=available")
+	assert.Equal(t, []string{"Rex"}, names, "explicit filters replace the default")
+	_, names = listAnimals(user.ID, "?saved_filter=none")
+	assert.Equal(t, []string{"Rex"}, names)
+	_, names = listAnimals(other.ID, "")
+	assert.Equal(t, []string{"Rex"}, names, "defaults are per user")
+
+	// Making another filter the default replaces the previous one
+	code, all := create(user.ID, map[string]any{"name": "Everyone", "params": map[string]string{"status": "all"}, "is_default": true})
+	require.Equal(t, http.StatusCreated, code)
+	require.NoError(t, db.First(&fosters, fosters.ID).Error)
+	assert.False(t, fosters.IsDefault)
+	_, names = listAnimals(user.ID, "")
+	assert.ElementsMatch(t, []string{"Rex", "Buddy"}, names)
+	_, names = listAnimals(user.ID, fmt.Sprintf("?saved_filter=%d&name=bud", fosters.ID))
+	assert.Equal(t, []string{"Buddy"}, names)
+
+	// Other users can't see, apply, or change someone else's filters
+	c, w := accountTestContext(other.ID, false, http.MethodGet, "/api/groups/1/animals", nil)
+	c.Request.URL.RawQuery = fmt.Sprintf("saved_filter=%d", all.ID)
+	c.Params = groupParam
+	GetAnimals(db)(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	c, w = accountTestContext(other.ID, false, http.MethodGet, "/api/groups/1/saved-filters", nil)
+	c.Params = groupParam
+	GetSavedFilters(db)(c)
+	assert.JSONEq(t, "[]", w.Body.String())
+	c, w = accountTestContext(other.ID, false, http.MethodDelete, "/api/groups/1/saved-filters", nil)
+	c.Params = append(groupParam, gin.Param{Key: "filterId", Value: fmt.Sprint(all.ID)})
+	DeleteSavedFilter(db)(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	c, w = accountTestContext(user.ID, false, http.MethodPut, "/api/groups/1/saved-filters", map[string]any{
+		"name": "Available", "params": map[string]string{"status": "available"},
+	})
+	c.Params = append(groupParam, gin.Param{Key: "filterId", Value: fmt.Sprint(all.ID)})
+	UpdateSavedFilter(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, db.First(&all, all.ID).Error)
+	assert.Equal(t, "Available", all.Name)
+	assert.False(t, all.IsDefault)
+	resp, _ = listAnimals(user.ID, "")
+	assert.Empty(t, resp.Header.Get("X-Saved-Filter-Id"), "no default is left")
+
+	c, w = accountTestContext(user.ID, false, http.MethodDelete, "/api/groups/1/saved-filters", nil)
+	c.Params = append(groupParam, gin.Param{Key: "filterId", Value: fmt.Sprint(all.ID)})
+	DeleteSavedFilter(db)(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var count int64
+	require.NoError(t, db.Model(&models.SavedFilter{}).Where("user_id = ?", user.ID).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
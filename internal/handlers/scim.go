@@ -0,0 +1,236 @@
+package handlers
+
+// SCIM 2.0 (RFC 7643, RFC 7644) provisioning endpoints: the subset identity
+// providers such as Okta and Entra ID use to create, update, and deactivate
+// users and to manage group membership. SCIM groups are the app's groups.
+// The routes live under /scim/v2, outside /api, and are authenticated with
+// SCIM_BEARER_TOKEN (see middleware.SCIMAuthRequired).
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+)
+
+// SCIM schema URNs
+const (
+	scimSchemaUser         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimSchemaGroup        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimSchemaListResponse = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimSchemaError        = "urn:ietf:params:scim:api:messages:2.0:Error"
+	scimSchemaSPConfig     = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+)
+
+// SCIM error types (RFC 7644 section 3.12)
+const (
+	scimErrInvalidFilter = "invalidFilter"
+	scimErrInvalidValue  = "invalidValue"
+	scimErrInvalidSyntax = "invalidSyntax"
+	scimErrUniqueness    = "uniqueness"
+	scimErrMutability    = "mutability"
+)
+
+const (
+	scimContentType  = "application/scim+json"
+	scimDefaultCount = 100
+	scimMaxCount     = 200
+)
+
+// SCIMMeta is the meta attribute every SCIM resource carries.
+type SCIMMeta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location"`
+}
+
+// SCIMMemberRef points at a group member, or at a group a user belongs to.
+type SCIMMemberRef struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// SCIMListResponse wraps the results of a SCIM query.
+type SCIMListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int64    `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []any    `json:"Resources"`
+}
+
+// SCIMPatchRequest is the body of a SCIM PATCH.
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations" binding:"required,min=1"`
+}
+
+// SCIMPatchOperation is one operation of a SCIM PATCH. Op is matched
+// case-insensitively, since Entra ID sends "Replace" where Okta sends
+// "replace".
+type SCIMPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// scimError is the SCIM error body.
+type scimError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	SCIMType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
+
+// scimRequestError is a client error found while applying a SCIM request,
+// reported as a 400 (or Status, when set) with its SCIM error type.
+type scimRequestError struct {
+	Status   int
+	SCIMType string
+	Detail   string
+}
+
+func (e *scimRequestError) Error() string { return e.Detail }
+
+func scimInvalidValue(format string, args ...any) error {
+	return &scimRequestError{Status: http.StatusBadRequest, SCIMType: scimErrInvalidValue, Detail: fmt.Sprintf(format, args...)}
+}
+
+func scimConflict(scimType, detail string) error {
+	return &scimRequestError{Status: http.StatusConflict, SCIMType: scimType, Detail: detail}
+}
+
+func scimRespond(c *gin.Context, status int, v any) {
+	c.Header("Content-Type", scimContentType)
+	c.JSON(status, v)
+}
+
+func scimRespondError(c *gin.Context, status int, scimType, detail string) {
+	scimRespond(c, status, scimError{
+		Schemas:  []string{scimSchemaError},
+		Status:   strconv.Itoa(status),
+		SCIMType: scimType,
+		Detail:   detail,
+	})
+}
+
+// scimRespondErr writes err as a SCIM error: scimRequestErrors as they are,
+// anything else as a logged 500.
+func scimRespondErr(c *gin.Context, err error, action string) {
+	var reqErr *scimRequestError
+	if errors.As(err, &reqErr) {
+		scimRespondError(c, reqErr.Status, reqErr.SCIMType, reqErr.Detail)
+		return
+	}
+	middleware.GetLogger(c).Error("SCIM: failed to "+action, err)
+	scimRespondError(c, http.StatusInternalServerError, "", "Failed to "+action)
+}
+
+// scimResourceID parses a SCIM resource id, which is the row ID as a string.
+func scimResourceID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		scimRespondError(c, http.StatusNotFound, "", "Resource not found")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func scimID(id uint) string { return strconv.FormatUint(uint64(id), 10) }
+
+// scimFilter is a parsed `attribute eq "value"` filter, the only form
+// identity providers need for matching existing users and groups.
+type scimFilter struct {
+	Attr  string // Lowercased
+	Value string
+}
+
+var scimFilterPattern = regexp.MustCompile(`(?i)^\s*([a-z][a-z0-9.]*)\s+eq\s+("(?:[^"\\]|\\.)*")\s*$`)
+
+// parseSCIMFilter parses the filter query parameter. It returns nil for an
+// empty filter.
+func parseSCIMFilter(filter string) (*scimFilter, error) {
+	if strings.TrimSpace(filter) == "" {
+		return nil, nil
+	}
+	m := scimFilterPattern.FindStringSubmatch(filter)
+	if m == nil {
+		return nil, &scimRequestError{Status: http.StatusBadRequest, SCIMType: scimErrInvalidFilter, Detail: `Only filters of the form attribute eq "value" are supported`}
+	}
+	value, err := strconv.Unquote(m[2])
+	if err != nil {
+		return nil, &scimRequestError{Status: http.StatusBadRequest, SCIMType: scimErrInvalidFilter, Detail: "Invalid filter value"}
+	}
+	return &scimFilter{Attr: strings.ToLower(m[1]), Value: value}, nil
+}
+
+// scimPage reads startIndex (1-based) and count from the query, clamping them
+// to valid values as RFC 7644 section 3.4.2.4 asks.
+func scimPage(c *gin.Context) (startIndex, count int) {
+	startIndex, err := strconv.Atoi(c.Query("startIndex"))
+	if err != nil || startIndex < 1 {
+		startIndex = 1
+	}
+	count, err = strconv.Atoi(c.Query("count"))
+	if err != nil {
+		count = scimDefaultCount
+	}
+	return startIndex, max(0, min(count, scimMaxCount))
+}
+
+// scimBool decodes a boolean attribute. Entra ID sends booleans in PATCH
+// values as the strings "True" and "False".
+func scimBool(raw json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b, nil
+		}
+	}
+	return false, scimInvalidValue("Expected a boolean, got %s", raw)
+}
+
+// scimString decodes a string attribute.
+func scimString(raw json.RawMessage) (string, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", scimInvalidValue("Expected a string, got %s", raw)
+	}
+	return s, nil
+}
+
+// GetSCIMServiceProviderConfig describes which SCIM features are supported.
+// Route: GET /scim/v2/ServiceProviderConfig
+func GetSCIMServiceProviderConfig() gin.HandlerFunc {
+	supported := func(b bool) gin.H { return gin.H{"supported": b} }
+	config := gin.H{
+		"schemas":        []string{scimSchemaSPConfig},
+		"patch":          supported(true),
+		"bulk":           gin.H{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         gin.H{"supported": true, "maxResults": scimMaxCount},
+		"changePassword": supported(false),
+		"sort":           supported(false),
+		"etag":           supported(false),
+		"authenticationSchemes": []gin.H{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer token",
+			"description": "The token configured in SCIM_BEARER_TOKEN",
+		}},
+	}
+	return func(c *gin.Context) {
+		scimRespond(c, http.StatusOK, config)
+	}
+}
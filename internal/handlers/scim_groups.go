@@ -0,0 +1,487 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SCIMGroup is the SCIM representation of a models.Group. Membership
+// provisioned here leaves group admin rights alone: those are managed in the
+// app.
+type SCIMGroup struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id,omitempty"`
+	DisplayName string          `json:"displayName"`
+	Members     []SCIMMemberRef `json:"members,omitempty"`
+	Meta        *SCIMMeta       `json:"meta,omitempty"`
+}
+
+func toSCIMGroup(g models.Group, members []SCIMMemberRef) SCIMGroup {
+	return SCIMGroup{
+		Schemas:     []string{scimSchemaGroup},
+		ID:          scimID(g.ID),
+		DisplayName: g.Name,
+		Members:     members,
+		Meta: &SCIMMeta{
+			ResourceType: "Group",
+			Created:      g.CreatedAt,
+			LastModified: g.UpdatedAt,
+			Location:     "/scim/v2/Groups/" + scimID(g.ID),
+		},
+	}
+}
+
+// loadSCIMGroup loads the group named by :id, writing a SCIM 404 and
+// returning false if there is none.
+func loadSCIMGroup(c *gin.Context, db *gorm.DB) (models.Group, bool) {
+	var group models.Group
+	id, ok := scimResourceID(c)
+	if !ok {
+		return group, false
+	}
+	if err := db.First(&group, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			scimRespondError(c, http.StatusNotFound, "", "Group not found")
+		} else {
+			scimRespondErr(c, err, "fetch group")
+		}
+		return group, false
+	}
+	return group, true
+}
+
+// scimGroupMembers returns the members of each of groupIDs that SCIM can see.
+func scimGroupMembers(db *gorm.DB, groupIDs []uint) (map[uint][]SCIMMemberRef, error) {
+	var rows []struct {
+		GroupID  uint
+		UserID   uint
+		Username string
+	}
+	if len(groupIDs) > 0 {
+		if err := db.Table("user_groups").
+			Select("user_groups.group_id, users.id AS user_id, users.username").
+			Joins("JOIN users ON users.id = user_groups.user_id AND users.anonymized_at IS NULL AND users.deactivation_requested_at IS NULL").
+			Where("user_groups.group_id IN ?", groupIDs).
+			Order("users.id").
+			Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+	}
+	members := make(map[uint][]SCIMMemberRef, len(groupIDs))
+	for _, r := range rows {
+		members[r.GroupID] = append(members[r.GroupID], SCIMMemberRef{
+			Value:   scimID(r.UserID),
+			Display: r.Username,
+			Ref:     "/scim/v2/Users/" + scimID(r.UserID),
+		})
+	}
+	return members, nil
+}
+
+// respondSCIMGroup reloads group and writes it with its members.
+func respondSCIMGroup(c *gin.Context, db *gorm.DB, status int, groupID uint) {
+	var group models.Group
+	if err := db.First(&group, groupID).Error; err != nil {
+		scimRespondErr(c, err, "fetch group")
+		return
+	}
+	members, err := scimGroupMembers(db, []uint{group.ID})
+	if err != nil {
+		scimRespondErr(c, err, "fetch group members")
+		return
+	}
+	if status == http.StatusCreated {
+		c.Header("Location", "/scim/v2/Groups/"+scimID(group.ID))
+	}
+	scimRespond(c, status, toSCIMGroup(group, members[group.ID]))
+}
+
+// scimMemberIDs resolves member references to user IDs, rejecting any that
+// don't name a user SCIM can see.
+func scimMemberIDs(db *gorm.DB, refs []SCIMMemberRef) ([]uint, error) {
+	ids := make([]uint, 0, len(refs))
+	seen := make(map[uint]bool, len(refs))
+	for _, ref := range refs {
+		id, err := strconv.ParseUint(ref.Value, 10, 32)
+		if err != nil || id == 0 {
+			return nil, scimInvalidValue("Unknown member %q", ref.Value)
+		}
+		if !seen[uint(id)] {
+			seen[uint(id)] = true
+			ids = append(ids, uint(id))
+		}
+	}
+	if len(ids) == 0 {
+		return ids, nil
+	}
+	var found int64
+	if err := scimUsers(db).Where("id IN ?", ids).Count(&found).Error; err != nil {
+		return nil, err
+	}
+	if int(found) != len(ids) {
+		return nil, scimInvalidValue("One or more members are not known users")
+	}
+	return ids, nil
+}
+
+// addSCIMGroupMembers adds userIDs to the group, leaving existing
+// memberships (and their group admin rights) as they are.
+func addSCIMGroupMembers(tx *gorm.DB, groupID uint, userIDs []uint) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	memberships := make([]models.UserGroup, len(userIDs))
+	for i, id := range userIDs {
+		memberships[i] = models.UserGroup{UserID: id, GroupID: groupID}
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&memberships).Error
+}
+
+// removeSCIMGroupMembers removes userIDs from the group.
+func removeSCIMGroupMembers(tx *gorm.DB, groupID uint, userIDs []uint) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	return tx.Where("group_id = ? AND user_id IN ?", groupID, userIDs).Delete(&models.UserGroup{}).Error
+}
+
+// replaceSCIMGroupMembers makes userIDs the group's only members.
+func replaceSCIMGroupMembers(tx *gorm.DB, groupID uint, userIDs []uint) error {
+	remove := tx.Where("group_id = ?", groupID)
+	if len(userIDs) > 0 {
+		remove = remove.Where("user_id NOT IN ?", userIDs)
+	}
+	if err := remove.Delete(&models.UserGroup{}).Error; err != nil {
+		return err
+	}
+	return addSCIMGroupMembers(tx, groupID, userIDs)
+}
+
+// validateSCIMGroupName trims name and checks that no group other than
+// groupID, deleted or not, has it.
+func validateSCIMGroupName(tx *gorm.DB, name string, groupID uint) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		return "", scimInvalidValue("displayName must be between 1 and 100 characters")
+	}
+	var count int64
+	if err := tx.Unscoped().Model(&models.Group{}).Where("LOWER(name) = ? AND id != ?", strings.ToLower(name), groupID).Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "", scimConflict(scimErrUniqueness, "A group with this displayName already exists")
+	}
+	return name, nil
+}
+
+// renameSCIMGroup renames the group.
+func renameSCIMGroup(tx *gorm.DB, group *models.Group, name string) error {
+	name, err := validateSCIMGroupName(tx, name, group.ID)
+	if err != nil || name == group.Name {
+		return err
+	}
+	return tx.Model(group).Update("name", name).Error
+}
+
+// logSCIMMembership records provisioned membership changes in the audit log.
+func logSCIMMembership(c *gin.Context, event logging.AuditEvent, groupID uint, userIDs []uint) {
+	if len(userIDs) == 0 {
+		return
+	}
+	logging.LogAdminAction(c.Request.Context(), event, 0, map[string]interface{}{
+		"group_id": groupID,
+		"user_ids": userIDs,
+		"source":   "scim",
+	})
+}
+
+// ListSCIMGroups lists groups, optionally filtered by displayName. Members
+// are left out when excludedAttributes names them, as identity providers
+// ask when they only need to match groups.
+// Route: GET /scim/v2/Groups
+func ListSCIMGroups(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		filter, err := parseSCIMFilter(c.Query("filter"))
+		if err != nil {
+			scimRespondErr(c, err, "list groups")
+			return
+		}
+		startIndex, count := scimPage(c)
+
+		query := db.Model(&models.Group{})
+		if filter != nil {
+			if filter.Attr != "displayname" {
+				scimRespondError(c, http.StatusBadRequest, scimErrInvalidFilter, "Groups can be filtered by displayName")
+				return
+			}
+			query = query.Where("LOWER(name) = ?", strings.ToLower(filter.Value))
+		}
+
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			scimRespondErr(c, err, "list groups")
+			return
+		}
+		var groups []models.Group
+		if err := query.Order("id").Offset(startIndex - 1).Limit(count).Find(&groups).Error; err != nil {
+			scimRespondErr(c, err, "list groups")
+			return
+		}
+
+		members := map[uint][]SCIMMemberRef{}
+		if !strings.Contains(strings.ToLower(c.Query("excludedAttributes")), "members") {
+			groupIDs := make([]uint, len(groups))
+			for i, g := range groups {
+				groupIDs[i] = g.ID
+			}
+			if members, err = scimGroupMembers(db, groupIDs); err != nil {
+				scimRespondErr(c, err, "list groups")
+				return
+			}
+		}
+
+		resources := make([]any, len(groups))
+		for i, g := range groups {
+			resources[i] = toSCIMGroup(g, members[g.ID])
+		}
+		scimRespond(c, http.StatusOK, SCIMListResponse{
+			Schemas:      []string{scimSchemaListResponse},
+			TotalResults: total,
+			StartIndex:   startIndex,
+			ItemsPerPage: len(resources),
+			Resources:    resources,
+		})
+	}
+}
+
+// GetSCIMGroup returns one group with its members.
+// Route: GET /scim/v2/Groups/:id
+func GetSCIMGroup(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		group, ok := loadSCIMGroup(c, db)
+		if !ok {
+			return
+		}
+		respondSCIMGroup(c, db, http.StatusOK, group.ID)
+	}
+}
+
+// CreateSCIMGroup creates a group from a directory group, with its members.
+// Route: POST /scim/v2/Groups
+func CreateSCIMGroup(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		var req SCIMGroup
+		if err := c.ShouldBindJSON(&req); err != nil {
+			scimRespondError(c, http.StatusBadRequest, scimErrInvalidSyntax, "Invalid request body")
+			return
+		}
+
+		group := models.Group{HeroImageURL: "/default-hero.svg"}
+		var memberIDs []uint
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			if memberIDs, err = scimMemberIDs(tx, req.Members); err != nil {
+				return err
+			}
+			if group.Name, err = validateSCIMGroupName(tx, req.DisplayName, 0); err != nil {
+				return err
+			}
+			if err := tx.Create(&group).Error; err != nil {
+				return err
+			}
+			return addSCIMGroupMembers(tx, group.ID, memberIDs)
+		}); err != nil {
+			scimRespondErr(c, err, "create group")
+			return
+		}
+
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventGroupCreated, 0, map[string]interface{}{
+			"group_id": group.ID,
+			"name":     group.Name,
+			"source":   "scim",
+		})
+		logSCIMMembership(c, logging.AuditEventUserAddedToGroup, group.ID, memberIDs)
+		respondSCIMGroup(c, db, http.StatusCreated, group.ID)
+	}
+}
+
+// ReplaceSCIMGroup renames a group and replaces its members.
+// Route: PUT /scim/v2/Groups/:id
+func ReplaceSCIMGroup(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		group, ok := loadSCIMGroup(c, db)
+		if !ok {
+			return
+		}
+		var req SCIMGroup
+		if err := c.ShouldBindJSON(&req); err != nil {
+			scimRespondError(c, http.StatusBadRequest, scimErrInvalidSyntax, "Invalid request body")
+			return
+		}
+
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			memberIDs, err := scimMemberIDs(tx, req.Members)
+			if err != nil {
+				return err
+			}
+			if err := renameSCIMGroup(tx, &group, req.DisplayName); err != nil {
+				return err
+			}
+			return replaceSCIMGroupMembers(tx, group.ID, memberIDs)
+		}); err != nil {
+			scimRespondErr(c, err, "update group")
+			return
+		}
+
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventGroupUpdated, 0, map[string]interface{}{
+			"group_id": group.ID,
+			"change":   "members",
+			"source":   "scim",
+		})
+		respondSCIMGroup(c, db, http.StatusOK, group.ID)
+	}
+}
+
+var scimMemberFilterPath = regexp.MustCompile(`(?i)^members\[\s*value\s+eq\s+"([^"]*)"\s*\]$`)
+
+// PatchSCIMGroup applies SCIM PATCH operations to a group: adding, removing,
+// or replacing members, and renaming.
+// Route: PATCH /scim/v2/Groups/:id
+func PatchSCIMGroup(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		group, ok := loadSCIMGroup(c, db)
+		if !ok {
+			return
+		}
+		var req SCIMPatchRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			scimRespondError(c, http.StatusBadRequest, scimErrInvalidSyntax, "Invalid request body")
+			return
+		}
+
+		var added, removed []uint
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			for _, op := range req.Operations {
+				a, r, err := applySCIMGroupPatch(tx, &group, op)
+				if err != nil {
+					return err
+				}
+				added = append(added, a...)
+				removed = append(removed, r...)
+			}
+			return nil
+		}); err != nil {
+			scimRespondErr(c, err, "update group")
+			return
+		}
+
+		logSCIMMembership(c, logging.AuditEventUserAddedToGroup, group.ID, added)
+		logSCIMMembership(c, logging.AuditEventUserRemovedFromGroup, group.ID, removed)
+		respondSCIMGroup(c, db, http.StatusOK, group.ID)
+	}
+}
+
+// applySCIMGroupPatch applies one PATCH operation, returning the user IDs
+// it added and removed. A replace of members reports the new members as
+// added.
+func applySCIMGroupPatch(tx *gorm.DB, group *models.Group, op SCIMPatchOperation) (added, removed []uint, err error) {
+	members := func() ([]uint, error) {
+		var refs []SCIMMemberRef
+		if len(op.Value) > 0 && json.Unmarshal(op.Value, &refs) != nil {
+			return nil, scimInvalidValue("members must be a list")
+		}
+		return scimMemberIDs(tx, refs)
+	}
+
+	path := strings.ToLower(op.Path)
+	switch strings.ToLower(op.Op) {
+	case "add":
+		if path != "members" {
+			return nil, nil, scimInvalidValue("Only members can be added to a group")
+		}
+		if added, err = members(); err != nil {
+			return nil, nil, err
+		}
+		return added, nil, addSCIMGroupMembers(tx, group.ID, added)
+
+	case "remove":
+		if m := scimMemberFilterPath.FindStringSubmatch(op.Path); m != nil {
+			op.Value, _ = json.Marshal([]SCIMMemberRef{{Value: m[1]}})
+			path = "members"
+		}
+		if path != "members" {
+			return nil, nil, scimInvalidValue("Only members can be removed from a group")
+		}
+		if len(op.Value) == 0 {
+			// Removing members without a value removes them all
+			return nil, nil, replaceSCIMGroupMembers(tx, group.ID, nil)
+		}
+		if removed, err = members(); err != nil {
+			return nil, nil, err
+		}
+		return nil, removed, removeSCIMGroupMembers(tx, group.ID, removed)
+
+	case "replace":
+		switch path {
+		case "members":
+			if added, err = members(); err != nil {
+				return nil, nil, err
+			}
+			return added, nil, replaceSCIMGroupMembers(tx, group.ID, added)
+		case "displayname":
+			name, err := scimString(op.Value)
+			if err != nil {
+				return nil, nil, err
+			}
+			return nil, nil, renameSCIMGroup(tx, group, name)
+		case "":
+			var value struct {
+				DisplayName *string          `json:"displayName"`
+				Members     *[]SCIMMemberRef `json:"members"`
+			}
+			if json.Unmarshal(op.Value, &value) != nil {
+				return nil, nil, scimInvalidValue("A patch without a path needs an object value")
+			}
+			if value.DisplayName != nil {
+				if err := renameSCIMGroup(tx, group, *value.DisplayName); err != nil {
+					return nil, nil, err
+				}
+			}
+			if value.Members != nil {
+				if added, err = scimMemberIDs(tx, *value.Members); err != nil {
+					return nil, nil, err
+				}
+				return added, nil, replaceSCIMGroupMembers(tx, group.ID, added)
+			}
+			return nil, nil, nil
+		}
+		return nil, nil, scimInvalidValue("Unsupported path %q", op.Path)
+	}
+	return nil, nil, &scimRequestError{Status: http.StatusBadRequest, SCIMType: scimErrInvalidSyntax, Detail: "Unsupported patch op " + op.Op}
+}
+
+// DeleteSCIMGroup is not supported: deleting a group takes its animals,
+// updates, and documents with it, so it stays a decision made in the app.
+// Unlinking the group in the identity provider stops provisioning instead.
+// Route: DELETE /scim/v2/Groups/:id
+func DeleteSCIMGroup() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scimRespondError(c, http.StatusNotImplemented, "", "Groups can't be deleted through SCIM; delete the group in the app")
+	}
+}
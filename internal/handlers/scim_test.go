@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// scimTestRouter serves the SCIM routes as main.go registers them, minus
+// the bearer token check.
+func scimTestRouter(db *gorm.DB) *gin.Engine {
+	router := gin.New()
+	scim := router.Group("/scim/v2")
+	scim.GET("/Users", ListSCIMUsers(db))
+	scim.POST("/Users", CreateSCIMUser(db, nil))
+	scim.GET("/Users/:id", GetSCIMUser(db))
+	scim.PUT("/Users/:id", ReplaceSCIMUser(db))
+	scim.PATCH("/Users/:id", PatchSCIMUser(db))
+	scim.DELETE("/Users/:id", DeleteSCIMUser(db))
+	scim.GET("/Groups", ListSCIMGroups(db))
+	scim.POST("/Groups", CreateSCIMGroup(db))
+	scim.GET("/Groups/:id", GetSCIMGroup(db))
+	scim.PATCH("/Groups/:id", PatchSCIMGroup(db))
+	scim.DELETE("/Groups/:id", DeleteSCIMGroup())
+	return router
+}
+
+func scimRequest(t *testing.T, router *gin.Engine, method, target string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req := httptest.NewRequest(method, target, reader)
+	req.Header.Set("Content-Type", scimContentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestSCIMUsers(t *testing.T) {
+	db := SetupTestDB(t)
+	router := scimTestRouter(db)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	CreateTestUser(t, db, "jdoe", "taken@example.com", "password123", false)
+
+	var created SCIMUser
+	t.Run("create", func(t *testing.T) {
+		w := scimRequest(t, router, http.MethodPost, "/scim/v2/Users", map[string]any{
+			"schemas":    []string{scimSchemaUser},
+			"userName":   "J.Doe@Shelter.org",
+			"externalId": "00u1abcd",
+			"name":       map[string]string{"givenName": "Jane", "familyName": "Doe"},
+			"emails":     []map[string]any{{"value": "j.doe@shelter.org", "primary": true}},
+			"active":     true,
+		})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		assert.Contains(t, w.Header().Get("Content-Type"), scimContentType)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		assert.Equal(t, "J.Doe@Shelter.org", created.UserName)
+		assert.Equal(t, w.Header().Get("Location"), created.Meta.Location)
+
+		var user models.User
+		require.NoError(t, db.First(&user, created.ID).Error)
+		assert.Equal(t, "j.doe", user.Username, "app username comes from the email")
+		assert.Equal(t, "00u1abcd", user.SCIMExternalID)
+		assert.True(t, user.RequiresPasswordSetup)
+		assert.NotEmpty(t, user.SetupTokenLookup)
+	})
+
+	t.Run("duplicates conflict", func(t *testing.T) {
+		w := scimRequest(t, router, http.MethodPost, "/scim/v2/Users", map[string]any{"userName": "j.doe@shelter.org"})
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), scimErrUniqueness)
+	})
+
+	t.Run("username collisions get a suffix", func(t *testing.T) {
+		w := scimRequest(t, router, http.MethodPost, "/scim/v2/Users", map[string]any{"userName": "jdoe@shelter.org"})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var su SCIMUser
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &su))
+		var user models.User
+		require.NoError(t, db.First(&user, su.ID).Error)
+		assert.Equal(t, "jdoe-2", user.Username)
+	})
+
+	t.Run("email is required", func(t *testing.T) {
+		w := scimRequest(t, router, http.MethodPost, "/scim/v2/Users", map[string]any{"userName": "no-email"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), scimErrInvalidValue)
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		w := scimRequest(t, router, http.MethodGet, "/scim/v2/Users?filter="+url.QueryEscape(`userName eq "j.doe@shelter.org"`), nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var list struct {
+			TotalResults int        `json:"totalResults"`
+			Resources    []SCIMUser `json:"Resources"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+		require.Equal(t, 1, list.TotalResults)
+		assert.Equal(t, created.ID, list.Resources[0].ID)
+
+		// Accounts created before provisioning match by email
+		w = scimRequest(t, router, http.MethodGet, "/scim/v2/Users?filter="+url.QueryEscape(`userName eq "admin@example.com"`), nil)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+		require.Equal(t, 1, list.TotalResults)
+		assert.Equal(t, scimID(admin.ID), list.Resources[0].ID)
+
+		w = scimRequest(t, router, http.MethodGet, "/scim/v2/Users?filter="+url.QueryEscape(`title co "x"`), nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), scimErrInvalidFilter)
+	})
+
+	t.Run("patch deactivates and reactivates", func(t *testing.T) {
+		patch := func(value any) *httptest.ResponseRecorder {
+			return scimRequest(t, router, http.MethodPatch, "/scim/v2/Users/"+created.ID, map[string]any{
+				"schemas":    []string{"urn:ietf:params:scim:api:messages:2.0:PatchOp"},
+				"Operations": []map[string]any{{"op": "Replace", "path": "active", "value": value}},
+			})
+		}
+		require.NoError(t, db.Create(&models.APIToken{UserID: mustUint(t, created.ID), Name: "cli", TokenHash: "hash", TokenPrefix: "vm_"}).Error)
+
+		w := patch("False") // Entra ID sends booleans as strings
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var su SCIMUser
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &su))
+		require.NotNil(t, su.Active)
+		assert.False(t, *su.Active)
+		var user models.User
+		assert.ErrorIs(t, db.First(&user, created.ID).Error, gorm.ErrRecordNotFound, "deactivated users are soft-deleted")
+		var tokens int64
+		db.Model(&models.APIToken{}).Where("user_id = ?", created.ID).Count(&tokens)
+		assert.Zero(t, tokens, "API tokens are revoked")
+
+		w = patch(true)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		require.NoError(t, db.First(&user, created.ID).Error)
+	})
+
+	t.Run("patch attributes", func(t *testing.T) {
+		w := scimRequest(t, router, http.MethodPatch, "/scim/v2/Users/"+created.ID, map[string]any{
+			"Operations": []map[string]any{
+				{"op": "replace", "value": map[string]any{"name.givenName": "Janet"}},
+				{"op": "replace", "path": `emails[type eq "work"].value`, "value": "janet@shelter.org"},
+			},
+		})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var user models.User
+		require.NoError(t, db.First(&user, created.ID).Error)
+		assert.Equal(t, "Janet", user.FirstName)
+		assert.Equal(t, "Doe", user.LastName)
+		assert.Equal(t, "janet@shelter.org", user.Email)
+		assert.Equal(t, "j.doe", user.Username, "the app username doesn't follow directory renames")
+	})
+
+	t.Run("the last site admin can't be deactivated", func(t *testing.T) {
+		w := scimRequest(t, router, http.MethodPut, "/scim/v2/Users/"+scimID(admin.ID), map[string]any{
+			"userName": "admin@example.com",
+			"active":   false,
+		})
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), scimErrMutability)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		w := scimRequest(t, router, http.MethodDelete, "/scim/v2/Users/"+created.ID, nil)
+		require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+		var user models.User
+		require.NoError(t, db.Unscoped().First(&user, created.ID).Error)
+		assert.True(t, user.DeletedAt.Valid)
+		assert.NotNil(t, user.DeactivationRequestedAt, "purged after the grace period")
+
+		w = scimRequest(t, router, http.MethodGet, "/scim/v2/Users/"+created.ID, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestSCIMGroups(t *testing.T) {
+	db := SetupTestDB(t)
+	router := scimTestRouter(db)
+	alice := CreateTestUser(t, db, "alice", "alice@example.com", "password123", false)
+	bob := CreateTestUser(t, db, "bob", "bob@example.com", "password123", false)
+	CreateTestGroup(t, db, "Dogs", "Dog group")
+
+	w := scimRequest(t, router, http.MethodPost, "/scim/v2/Groups", map[string]any{"displayName": "dogs"})
+	assert.Equal(t, http.StatusConflict, w.Code, "names are unique case-insensitively")
+
+	w = scimRequest(t, router, http.MethodPost, "/scim/v2/Groups", map[string]any{
+		"displayName": "Cats",
+		"members":     []map[string]string{{"value": scimID(alice.ID)}},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var group SCIMGroup
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &group))
+	require.Len(t, group.Members, 1)
+	groupID := mustUint(t, group.ID)
+
+	// Group admin rights set in the app survive provisioning
+	require.NoError(t, db.Model(&models.UserGroup{}).Where("user_id = ? AND group_id = ?", alice.ID, groupID).Update("is_group_admin", true).Error)
+
+	patch := func(ops ...map[string]any) *httptest.ResponseRecorder {
+		return scimRequest(t, router, http.MethodPatch, "/scim/v2/Groups/"+group.ID, map[string]any{"Operations": ops})
+	}
+	members := []map[string]string{{"value": scimID(alice.ID)}, {"value": scimID(bob.ID)}}
+	w = patch(map[string]any{"op": "add", "path": "members", "value": members})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &group))
+	assert.Len(t, group.Members, 2)
+	var membership models.UserGroup
+	require.NoError(t, db.Where("user_id = ? AND group_id = ?", alice.ID, groupID).First(&membership).Error)
+	assert.True(t, membership.IsGroupAdmin)
+
+	w = patch(map[string]any{"op": "remove", "path": fmt.Sprintf(`members[value eq "%d"]`, alice.ID)})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &group))
+	require.Len(t, group.Members, 1)
+	assert.Equal(t, scimID(bob.ID), group.Members[0].Value)
+
+	w = patch(map[string]any{"op": "replace", "value": map[string]any{"displayName": "Cat Team"}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var stored models.Group
+	require.NoError(t, db.First(&stored, groupID).Error)
+	assert.Equal(t, "Cat Team", stored.Name)
+
+	w = patch(map[string]any{"op": "add", "path": "members", "value": []map[string]string{{"value": "9999"}}})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "unknown members are rejected")
+
+	w = scimRequest(t, router, http.MethodGet, "/scim/v2/Groups?excludedAttributes=members&filter="+url.QueryEscape(`displayName eq "cat team"`), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list struct {
+		TotalResults int         `json:"totalResults"`
+		Resources    []SCIMGroup `json:"Resources"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Equal(t, 1, list.TotalResults)
+	assert.Empty(t, list.Resources[0].Members)
+
+	w = scimRequest(t, router, http.MethodDelete, "/scim/v2/Groups/"+group.ID, nil)
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
+func mustUint(t *testing.T, id string) uint {
+	t.Helper()
+	var n uint
+	_, err := fmt.Sscan(id, &n)
+	require.NoError(t, err)
+	return n
+}
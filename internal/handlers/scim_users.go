@@ -0,0 +1,698 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/auth"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// SCIMName is a SCIM user's name.
+type SCIMName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// SCIMMultiValue is one entry of a multi-valued attribute such as emails.
+type SCIMMultiValue struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// SCIMUser is the SCIM representation of a models.User. userName is the
+// identity provider's login name (usually an email address) and is stored
+// apart from the app username, which is derived once at creation so
+// renames in the directory don't change how volunteers appear in the app.
+type SCIMUser struct {
+	Schemas      []string         `json:"schemas"`
+	ID           string           `json:"id,omitempty"`
+	ExternalID   string           `json:"externalId,omitempty"`
+	UserName     string           `json:"userName"`
+	Name         *SCIMName        `json:"name,omitempty"`
+	DisplayName  string           `json:"displayName,omitempty"`
+	Emails       []SCIMMultiValue `json:"emails,omitempty"`
+	PhoneNumbers []SCIMMultiValue `json:"phoneNumbers,omitempty"`
+	Active       *bool            `json:"active,omitempty"`
+	Groups       []SCIMMemberRef  `json:"groups,omitempty"` // Read-only; membership is managed through Groups
+	Meta         *SCIMMeta        `json:"meta,omitempty"`
+}
+
+// scimUserAttrs are the validated attributes of a SCIMUser, as stored.
+type scimUserAttrs struct {
+	UserName    string
+	ExternalID  string
+	Email       string
+	FirstName   string
+	LastName    string
+	PhoneNumber string
+	Active      *bool
+}
+
+// primarySCIMValue returns the primary entry's value, or the first non-empty
+// one when none is marked primary.
+func primarySCIMValue(values []SCIMMultiValue) string {
+	for _, v := range values {
+		if v.Primary && strings.TrimSpace(v.Value) != "" {
+			return strings.TrimSpace(v.Value)
+		}
+	}
+	for _, v := range values {
+		if strings.TrimSpace(v.Value) != "" {
+			return strings.TrimSpace(v.Value)
+		}
+	}
+	return ""
+}
+
+// attrs validates u. The email is the primary email, or userName when no
+// emails are sent and userName is an address.
+func (u SCIMUser) attrs() (scimUserAttrs, error) {
+	a := scimUserAttrs{
+		UserName:    strings.TrimSpace(u.UserName),
+		ExternalID:  strings.TrimSpace(u.ExternalID),
+		Email:       primarySCIMValue(u.Emails),
+		PhoneNumber: primarySCIMValue(u.PhoneNumbers),
+		Active:      u.Active,
+	}
+	if u.Name != nil {
+		a.FirstName = strings.TrimSpace(u.Name.GivenName)
+		a.LastName = strings.TrimSpace(u.Name.FamilyName)
+	}
+	if a.Email == "" && strings.Contains(a.UserName, "@") {
+		a.Email = a.UserName
+	}
+
+	switch {
+	case a.UserName == "":
+		return a, scimInvalidValue("userName is required")
+	case len(a.UserName) > 255 || len(a.ExternalID) > 255:
+		return a, scimInvalidValue("userName and externalId must be at most 255 characters")
+	case len(a.FirstName) > 100 || len(a.LastName) > 100:
+		return a, scimInvalidValue("name.givenName and name.familyName must be at most 100 characters")
+	case len(a.PhoneNumber) > 20:
+		return a, scimInvalidValue("phoneNumbers must be at most 20 characters")
+	}
+	if addr, err := mail.ParseAddress(a.Email); err != nil || addr.Address != a.Email {
+		return a, scimInvalidValue("A valid email address is required in emails or userName")
+	}
+	return a, nil
+}
+
+func toSCIMUser(u models.User, groups []SCIMMemberRef) SCIMUser {
+	userName := u.SCIMUserName
+	if userName == "" {
+		userName = u.Username
+	}
+	displayName := strings.TrimSpace(u.FirstName + " " + u.LastName)
+	if displayName == "" {
+		displayName = u.Username
+	}
+	active := !u.DeletedAt.Valid
+	su := SCIMUser{
+		Schemas:     []string{scimSchemaUser},
+		ID:          scimID(u.ID),
+		ExternalID:  u.SCIMExternalID,
+		UserName:    userName,
+		Name:        &SCIMName{Formatted: displayName, GivenName: u.FirstName, FamilyName: u.LastName},
+		DisplayName: displayName,
+		Emails:      []SCIMMultiValue{{Value: u.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Groups:      groups,
+		Meta: &SCIMMeta{
+			ResourceType: "User",
+			Created:      u.CreatedAt,
+			LastModified: u.UpdatedAt,
+			Location:     "/scim/v2/Users/" + scimID(u.ID),
+		},
+	}
+	if u.PhoneNumber != "" {
+		su.PhoneNumbers = []SCIMMultiValue{{Value: u.PhoneNumber, Type: "mobile", Primary: true}}
+	}
+	return su
+}
+
+// scimUsers scopes a query to the users SCIM can see. Deactivated
+// (soft-deleted) users are included and reported as inactive; accounts that
+// were closed or anonymized are not.
+func scimUsers(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Model(&models.User{}).Where("anonymized_at IS NULL AND deactivation_requested_at IS NULL")
+}
+
+// loadSCIMUser loads the user named by :id, writing a SCIM 404 and
+// returning false if there is none.
+func loadSCIMUser(c *gin.Context, db *gorm.DB) (models.User, bool) {
+	var user models.User
+	id, ok := scimResourceID(c)
+	if !ok {
+		return user, false
+	}
+	if err := scimUsers(db).First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			scimRespondError(c, http.StatusNotFound, "", "User not found")
+		} else {
+			scimRespondErr(c, err, "fetch user")
+		}
+		return user, false
+	}
+	return user, true
+}
+
+// scimUserGroups returns the groups each of userIDs belongs to.
+func scimUserGroups(db *gorm.DB, userIDs []uint) (map[uint][]SCIMMemberRef, error) {
+	var rows []struct {
+		UserID  uint
+		GroupID uint
+		Name    string
+	}
+	if len(userIDs) > 0 {
+		if err := db.Table("user_groups").
+			Select("user_groups.user_id, groups.id AS group_id, groups.name").
+			Joins("JOIN groups ON groups.id = user_groups.group_id AND groups.deleted_at IS NULL").
+			Where("user_groups.user_id IN ?", userIDs).
+			Order("groups.id").
+			Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+	}
+	groups := make(map[uint][]SCIMMemberRef, len(userIDs))
+	for _, r := range rows {
+		groups[r.UserID] = append(groups[r.UserID], SCIMMemberRef{
+			Value:   scimID(r.GroupID),
+			Display: r.Name,
+			Ref:     "/scim/v2/Groups/" + scimID(r.GroupID),
+		})
+	}
+	return groups, nil
+}
+
+// respondSCIMUser reloads user and writes it with its groups.
+func respondSCIMUser(c *gin.Context, db *gorm.DB, status int, userID uint) {
+	var user models.User
+	if err := db.Unscoped().First(&user, userID).Error; err != nil {
+		scimRespondErr(c, err, "fetch user")
+		return
+	}
+	groups, err := scimUserGroups(db, []uint{user.ID})
+	if err != nil {
+		scimRespondErr(c, err, "fetch user groups")
+		return
+	}
+	if status == http.StatusCreated {
+		c.Header("Location", "/scim/v2/Users/"+scimID(user.ID))
+	}
+	scimRespond(c, status, toSCIMUser(user, groups[user.ID]))
+}
+
+// checkSCIMUserUnique returns a uniqueness error if another user, deleted or
+// not, already has attrs' userName or email.
+func checkSCIMUserUnique(db *gorm.DB, attrs scimUserAttrs, userID uint) error {
+	var count int64
+	if err := db.Unscoped().Model(&models.User{}).
+		Where("LOWER(scim_user_name) = ? AND id != ?", strings.ToLower(attrs.UserName), userID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return scimConflict(scimErrUniqueness, "userName is already in use")
+	}
+	if err := db.Unscoped().Model(&models.User{}).
+		Where("LOWER(email) = ? AND id != ?", strings.ToLower(attrs.Email), userID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return scimConflict(scimErrUniqueness, "A user with this email address already exists")
+	}
+	return nil
+}
+
+var scimUsernameInvalidChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// scimUsername derives an app username from email's local part, adding a
+// numeric suffix when it is taken or was recently given up.
+func scimUsername(ctx context.Context, db *gorm.DB, emailAddr string, now time.Time) (string, error) {
+	local, _, _ := strings.Cut(strings.ToLower(emailAddr), "@")
+	base := strings.Trim(scimUsernameInvalidChars.ReplaceAllString(local, "-"), "-.")
+	if len(base) < 3 {
+		base = strings.TrimSuffix("user-"+base, "-")
+	}
+	if len(base) > 45 {
+		base = base[:45]
+	}
+
+	for i := 1; i <= 100; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, i)
+		}
+		err := validateUsernameAvailable(ctx, db, candidate, 0, now)
+		if err == nil {
+			return candidate, nil
+		}
+		if !errors.Is(err, ErrUsernameInUse) && !errors.Is(err, ErrUsernameRecentlyUsed) {
+			return "", err
+		}
+	}
+	return "", scimConflict(scimErrUniqueness, "Could not find a free username for "+emailAddr)
+}
+
+// setSCIMUserActive reactivates or deactivates user. Deactivating
+// soft-deletes the user and revokes their API tokens, as an admin delete
+// does; the last site admin can't be deactivated.
+func setSCIMUserActive(tx *gorm.DB, user *models.User, active bool) error {
+	if active == !user.DeletedAt.Valid {
+		return nil
+	}
+	if active {
+		return tx.Unscoped().Model(user).Update("deleted_at", nil).Error
+	}
+
+	if user.IsAdmin {
+		var otherAdmins int64
+		if err := tx.Model(&models.User{}).Where("is_admin = ? AND id != ?", true, user.ID).Count(&otherAdmins).Error; err != nil {
+			return err
+		}
+		if otherAdmins == 0 {
+			return scimConflict(scimErrMutability, "The last site admin can't be deactivated")
+		}
+	}
+	if err := tx.Where("user_id = ?", user.ID).Delete(&models.APIToken{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(user).Error
+}
+
+// applySCIMUserAttrs stores attrs on user, replacing its SCIM-managed
+// attributes, and applies attrs.Active when set.
+func applySCIMUserAttrs(db *gorm.DB, user *models.User, attrs scimUserAttrs) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := checkSCIMUserUnique(tx, attrs, user.ID); err != nil {
+			return err
+		}
+		updates := map[string]interface{}{
+			"scim_user_name":   attrs.UserName,
+			"scim_external_id": attrs.ExternalID,
+			"first_name":       attrs.FirstName,
+			"last_name":        attrs.LastName,
+			"phone_number":     attrs.PhoneNumber,
+			"email":            attrs.Email,
+		}
+		emailChanged := !strings.EqualFold(attrs.Email, user.Email)
+		if emailChanged {
+			for k, v := range clearedEmailVerification() {
+				updates[k] = v
+			}
+		}
+		if err := tx.Unscoped().Model(user).Updates(updates).Error; err != nil {
+			return err
+		}
+		if emailChanged {
+			if err := enqueueEmailVerification(tx, user.ID, attrs.Email); err != nil {
+				return err
+			}
+		}
+		if attrs.Active != nil {
+			return setSCIMUserActive(tx, user, *attrs.Active)
+		}
+		return nil
+	})
+}
+
+// logSCIMUserActive records a SCIM status change in the audit log.
+func logSCIMUserActive(ctx context.Context, user models.User, attrs scimUserAttrs) {
+	if attrs.Active == nil || *attrs.Active == !user.DeletedAt.Valid {
+		return
+	}
+	event := logging.AuditEventUserDeleted
+	if *attrs.Active {
+		event = logging.AuditEventUserRestored
+	}
+	logging.LogAdminAction(ctx, event, 0, map[string]interface{}{
+		"user_id": user.ID,
+		"source":  "scim",
+	})
+}
+
+var scimEmailValuePath = regexp.MustCompile(`(?i)^emails\[.*\]\.value$`)
+var scimPhoneValuePath = regexp.MustCompile(`(?i)^phonenumbers\[.*\]\.value$`)
+
+// applySCIMUserPatch applies PATCH operations to u. Only the attributes
+// SCIMUser stores can be changed; other paths are ignored, since identity
+// providers send attributes (title, locale, ...) the app doesn't keep.
+func applySCIMUserPatch(u *SCIMUser, ops []SCIMPatchOperation) error {
+	for _, op := range ops {
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+			if op.Path == "" {
+				var values map[string]json.RawMessage
+				if err := json.Unmarshal(op.Value, &values); err != nil {
+					return scimInvalidValue("A patch without a path needs an object value")
+				}
+				for path, value := range values {
+					if err := setSCIMUserAttr(u, path, value); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if err := setSCIMUserAttr(u, op.Path, op.Value); err != nil {
+				return err
+			}
+		case "remove":
+			clearSCIMUserAttr(u, op.Path)
+		default:
+			return &scimRequestError{Status: http.StatusBadRequest, SCIMType: scimErrInvalidSyntax, Detail: "Unsupported patch op " + op.Op}
+		}
+	}
+	return nil
+}
+
+func setSCIMUserAttr(u *SCIMUser, path string, value json.RawMessage) error {
+	if u.Name == nil {
+		u.Name = &SCIMName{}
+	}
+	var err error
+	switch p := strings.ToLower(path); {
+	case p == "active":
+		var active bool
+		active, err = scimBool(value)
+		u.Active = &active
+	case p == "username":
+		u.UserName, err = scimString(value)
+	case p == "externalid":
+		u.ExternalID, err = scimString(value)
+	case p == "name.givenname":
+		u.Name.GivenName, err = scimString(value)
+	case p == "name.familyname":
+		u.Name.FamilyName, err = scimString(value)
+	case p == "name":
+		if json.Unmarshal(value, u.Name) != nil {
+			err = scimInvalidValue("name must be an object")
+		}
+	case p == "emails":
+		if json.Unmarshal(value, &u.Emails) != nil {
+			err = scimInvalidValue("emails must be a list")
+		}
+	case p == "emails.value" || scimEmailValuePath.MatchString(path):
+		var v string
+		v, err = scimString(value)
+		u.Emails = []SCIMMultiValue{{Value: v, Type: "work", Primary: true}}
+	case p == "phonenumbers":
+		if json.Unmarshal(value, &u.PhoneNumbers) != nil {
+			err = scimInvalidValue("phoneNumbers must be a list")
+		}
+	case p == "phonenumbers.value" || scimPhoneValuePath.MatchString(path):
+		var v string
+		v, err = scimString(value)
+		u.PhoneNumbers = []SCIMMultiValue{{Value: v, Type: "mobile", Primary: true}}
+	}
+	return err
+}
+
+func clearSCIMUserAttr(u *SCIMUser, path string) {
+	switch p := strings.ToLower(path); {
+	case p == "externalid":
+		u.ExternalID = ""
+	case p == "name.givenname" && u.Name != nil:
+		u.Name.GivenName = ""
+	case p == "name.familyname" && u.Name != nil:
+		u.Name.FamilyName = ""
+	case p == "phonenumbers" || p == "phonenumbers.value" || scimPhoneValuePath.MatchString(path):
+		u.PhoneNumbers = nil
+	}
+}
+
+// ListSCIMUsers lists users, optionally filtered by userName, externalId, or
+// emails.value. A userName filter also matches users created in the app
+// before provisioning by username or email, so identity providers link
+// existing accounts instead of failing to create duplicates.
+// Route: GET /scim/v2/Users
+func ListSCIMUsers(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		filter, err := parseSCIMFilter(c.Query("filter"))
+		if err != nil {
+			scimRespondErr(c, err, "list users")
+			return
+		}
+		startIndex, count := scimPage(c)
+
+		query := scimUsers(db)
+		if filter != nil {
+			value := strings.ToLower(filter.Value)
+			switch filter.Attr {
+			case "username":
+				query = query.Where("LOWER(scim_user_name) = ? OR (scim_user_name = '' AND (LOWER(username) = ? OR LOWER(email) = ?))", value, value, value)
+			case "externalid":
+				query = query.Where("scim_external_id = ?", filter.Value)
+			case "emails", "emails.value":
+				query = query.Where("LOWER(email) = ?", value)
+			default:
+				scimRespondError(c, http.StatusBadRequest, scimErrInvalidFilter, "Users can be filtered by userName, externalId, or emails.value")
+				return
+			}
+		}
+
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			scimRespondErr(c, err, "list users")
+			return
+		}
+		var users []models.User
+		if err := query.Order("id").Offset(startIndex - 1).Limit(count).Find(&users).Error; err != nil {
+			scimRespondErr(c, err, "list users")
+			return
+		}
+		userIDs := make([]uint, len(users))
+		for i, u := range users {
+			userIDs[i] = u.ID
+		}
+		groups, err := scimUserGroups(db, userIDs)
+		if err != nil {
+			scimRespondErr(c, err, "list users")
+			return
+		}
+
+		resources := make([]any, len(users))
+		for i, u := range users {
+			resources[i] = toSCIMUser(u, groups[u.ID])
+		}
+		scimRespond(c, http.StatusOK, SCIMListResponse{
+			Schemas:      []string{scimSchemaListResponse},
+			TotalResults: total,
+			StartIndex:   startIndex,
+			ItemsPerPage: len(resources),
+			Resources:    resources,
+		})
+	}
+}
+
+// GetSCIMUser returns one user.
+// Route: GET /scim/v2/Users/:id
+func GetSCIMUser(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		user, ok := loadSCIMUser(c, db)
+		if !ok {
+			return
+		}
+		respondSCIMUser(c, db, http.StatusOK, user.ID)
+	}
+}
+
+// CreateSCIMUser provisions a user. Like an admin invite, the user gets a
+// password setup link, emailed when email is configured; until then they
+// can't log in.
+// Route: POST /scim/v2/Users
+func CreateSCIMUser(db *gorm.DB, emailService *email.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		db := middleware.GetDB(c, db)
+
+		var req SCIMUser
+		if err := c.ShouldBindJSON(&req); err != nil {
+			scimRespondError(c, http.StatusBadRequest, scimErrInvalidSyntax, "Invalid request body")
+			return
+		}
+		attrs, err := req.attrs()
+		if err != nil {
+			scimRespondErr(c, err, "create user")
+			return
+		}
+		if err := checkSCIMUserUnique(db, attrs, 0); err != nil {
+			scimRespondErr(c, err, "create user")
+			return
+		}
+		username, err := scimUsername(ctx, db, attrs.Email, time.Now())
+		if err != nil {
+			scimRespondErr(c, err, "create user")
+			return
+		}
+
+		// A random password nobody knows; the user sets theirs with the setup token
+		tempPassword, err := generateSecureToken()
+		if err != nil {
+			scimRespondErr(c, err, "create user")
+			return
+		}
+		hashedPassword, err := auth.HashPassword(tempPassword)
+		if err != nil {
+			scimRespondErr(c, err, "create user")
+			return
+		}
+		setupToken, err := generateSecureToken()
+		if err != nil {
+			scimRespondErr(c, err, "create user")
+			return
+		}
+		hashedSetupToken, err := auth.HashPassword(setupToken)
+		if err != nil {
+			scimRespondErr(c, err, "create user")
+			return
+		}
+		expiry := time.Now().Add(SetupTokenExpiry)
+
+		user := models.User{
+			Username:              username,
+			SCIMUserName:          attrs.UserName,
+			SCIMExternalID:        attrs.ExternalID,
+			FirstName:             attrs.FirstName,
+			LastName:              attrs.LastName,
+			Email:                 attrs.Email,
+			PhoneNumber:           attrs.PhoneNumber,
+			Password:              hashedPassword,
+			SetupToken:            hashedSetupToken,
+			SetupTokenLookup:      setupToken[:TokenLookupPrefixLength],
+			SetupTokenExpiry:      &expiry,
+			RequiresPasswordSetup: true,
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&user).Error; err != nil {
+				return err
+			}
+			if attrs.Active != nil && !*attrs.Active {
+				return setSCIMUserActive(tx, &user, false)
+			}
+			return nil
+		}); err != nil {
+			scimRespondErr(c, err, "create user")
+			return
+		}
+
+		if !user.DeletedAt.Valid && emailService != nil && emailService.IsConfigured() {
+			if err := emailService.SendPasswordSetupEmail(ctx, user.Email, user.Username, setupToken); err != nil {
+				middleware.GetLogger(c).Error("Failed to send password setup email", err)
+			}
+		}
+
+		logging.LogAdminAction(ctx, logging.AuditEventUserCreated, 0, map[string]interface{}{
+			"user_id":  user.ID,
+			"username": user.Username,
+			"source":   "scim",
+		})
+		respondSCIMUser(c, db, http.StatusCreated, user.ID)
+	}
+}
+
+// ReplaceSCIMUser replaces a user's SCIM-managed attributes. Setting active
+// to false deactivates the user; setting it to true restores them.
+// Route: PUT /scim/v2/Users/:id
+func ReplaceSCIMUser(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		user, ok := loadSCIMUser(c, db)
+		if !ok {
+			return
+		}
+		var req SCIMUser
+		if err := c.ShouldBindJSON(&req); err != nil {
+			scimRespondError(c, http.StatusBadRequest, scimErrInvalidSyntax, "Invalid request body")
+			return
+		}
+		updateSCIMUser(c, db, user, req)
+	}
+}
+
+// PatchSCIMUser applies SCIM PATCH operations to a user. Identity providers
+// deactivate users with a patch of active to false.
+// Route: PATCH /scim/v2/Users/:id
+func PatchSCIMUser(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		user, ok := loadSCIMUser(c, db)
+		if !ok {
+			return
+		}
+		var req SCIMPatchRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			scimRespondError(c, http.StatusBadRequest, scimErrInvalidSyntax, "Invalid request body")
+			return
+		}
+		patched := toSCIMUser(user, nil)
+		if err := applySCIMUserPatch(&patched, req.Operations); err != nil {
+			scimRespondErr(c, err, "update user")
+			return
+		}
+		updateSCIMUser(c, db, user, patched)
+	}
+}
+
+// updateSCIMUser validates and stores req on user and writes the response.
+func updateSCIMUser(c *gin.Context, db *gorm.DB, user models.User, req SCIMUser) {
+	attrs, err := req.attrs()
+	if err != nil {
+		scimRespondErr(c, err, "update user")
+		return
+	}
+	before := user
+	if err := applySCIMUserAttrs(db, &user, attrs); err != nil {
+		scimRespondErr(c, err, "update user")
+		return
+	}
+	logSCIMUserActive(c.Request.Context(), before, attrs)
+	respondSCIMUser(c, db, http.StatusOK, user.ID)
+}
+
+// DeleteSCIMUser closes a user's account: the user is deactivated and, as
+// when volunteers close their own account, anonymized once the deletion
+// grace period has passed. The user is gone from SCIM straight away.
+// Route: DELETE /scim/v2/Users/:id
+func DeleteSCIMUser(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		user, ok := loadSCIMUser(c, db)
+		if !ok {
+			return
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := setSCIMUserActive(tx, &user, false); err != nil {
+				return err
+			}
+			return tx.Unscoped().Model(&user).Update("deactivation_requested_at", time.Now()).Error
+		}); err != nil {
+			scimRespondErr(c, err, "delete user")
+			return
+		}
+
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventUserDeleted, 0, map[string]interface{}{
+			"user_id": user.ID,
+			"source":  "scim",
+		})
+		respondNoContent(c)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/database"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
@@ -146,9 +147,12 @@ func Search(db *gorm.DB, embedder embedding.Embedder) gin.HandlerFunc {
 		response := gin.H{}
 
 		if searchType == "all" || searchType == "animals" {
+			// Custom field values aren't in search_vector, so they match as
+			// a case-insensitive substring instead
+			customFieldPattern := "%" + database.EscapeLike(query) + "%"
 			var totalAnimals int64
 			if err := db.Model(&models.Animal{}).
-				Where("group_id = ? AND search_vector @@ websearch_to_tsquery('english', ?)", groupID, query).
+				Where("group_id = ? AND (search_vector @@ websearch_to_tsquery('english', ?) OR "+customFieldSearchClause+")", groupID, query, customFieldPattern).
 				Count(&totalAnimals).Error; err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count matching animals"})
 				return
@@ -161,7 +165,7 @@ func Search(db *gorm.DB, embedder embedding.Embedder) gin.HandlerFunc {
 			buildAnimalsKeywordQuery := func() *gorm.DB {
 				return db.Model(&models.Animal{}).
 					Select("animals.*, ts_rank(search_vector, websearch_to_tsquery('english', ?)) AS rank", query).
-					Where("group_id = ? AND search_vector @@ websearch_to_tsquery('english', ?)", groupID, query).
+					Where("group_id = ? AND (search_vector @@ websearch_to_tsquery('english', ?) OR "+customFieldSearchClause+")", groupID, query, customFieldPattern).
 					// A tie-breaker on id is required, not cosmetic: ts_rank ties
 					// are common, and without a deterministic secondary sort key,
 					// Postgres can return tied rows in a different order between
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -8,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/alerting"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
@@ -26,7 +28,42 @@ var settingValidationRules = map[string]struct {
 	"hero_image_url":   {required: false, maxLen: 500},
 }
 
-// GetSiteSettings returns all site settings (public endpoint)
+// validateSiteSetting checks value against the rules for key and returns it
+// as it should be stored. Settings read by the security, image, alerting, and
+// breed code are trimmed; others are stored as given.
+func validateSiteSetting(key, value string) (string, error) {
+	if rules, ok := settingValidationRules[key]; ok {
+		if rules.required && strings.TrimSpace(value) == "" {
+			return "", fmt.Errorf("%s is required", key)
+		}
+		if len(value) > rules.maxLen {
+			return "", fmt.Errorf("%s must be %d characters or less", key, rules.maxLen)
+		}
+	}
+
+	var err error
+	switch {
+	case middleware.IsSecuritySetting(key):
+		err = middleware.ValidateSecuritySetting(key, value)
+	case upload.IsImageSetting(key):
+		err = upload.ValidateImageSetting(key, value)
+	case key == breedNormalizationSetting:
+		err = validateBreedNormalizationSetting(value)
+	case key == emailTrackingSetting:
+		err = validateEmailTrackingSetting(value)
+	case alerting.IsAlertSetting(key):
+		err = alerting.ValidateAlertSetting(key, value)
+	default:
+		return value, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// GetSiteSettings returns all site settings (public endpoint). With
+// ?organization=<slug>, that organization's overrides replace the site's.
 func GetSiteSettings(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		db := middleware.GetDB(c, db)
@@ -42,12 +79,31 @@ func GetSiteSettings(db *gorm.DB) gin.HandlerFunc {
 			settingsMap[setting.Key] = setting.Value
 		}
 
+		if slug := c.Query("organization"); slug != "" {
+			var org models.Organization
+			if err := db.Where("slug = ?", strings.ToLower(slug)).First(&org).Error; err != nil {
+				respondError(c, http.StatusNotFound, ErrCodeNotFound, "Organization not found")
+				return
+			}
+			overrides, err := organizationSettings(db, org.ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch site settings"})
+				return
+			}
+			for key, value := range overrides {
+				settingsMap[key] = value
+			}
+		}
+
 		c.JSON(http.StatusOK, settingsMap)
 	}
 }
 
-// UpdateSiteSetting updates a specific site setting (admin only)
-func UpdateSiteSetting(db *gorm.DB) gin.HandlerFunc {
+// UpdateSiteSetting updates a specific site setting (admin only).
+// Security settings (CORS origins, CSP, HSTS, frame options) and image upload
+// settings are validated and take effect immediately by invalidating
+// securityConfig or imageConfig.
+func UpdateSiteSetting(db *gorm.DB, securityConfig *middleware.SecurityConfigStore, imageConfig *upload.ImageConfigStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		db := middleware.GetDB(c, db)
 		key := c.Param("key")
@@ -61,19 +117,17 @@ func UpdateSiteSetting(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		// Validate setting value if validation rules exist for this key
-		if rules, ok := settingValidationRules[key]; ok {
-			trimmedValue := strings.TrimSpace(req.Value)
-
-			if rules.required && trimmedValue == "" {
-				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s is required", key)})
-				return
-			}
-
-			if len(req.Value) > rules.maxLen {
-				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s must be %d characters or less", key, rules.maxLen)})
-				return
-			}
+		value, err := validateSiteSetting(key, req.Value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		req.Value = value
+		if middleware.IsSecuritySetting(key) {
+			defer securityConfig.Invalidate()
+		}
+		if upload.IsImageSetting(key) {
+			defer imageConfig.Invalidate()
 		}
 
 		var setting models.SiteSetting
@@ -105,15 +159,85 @@ func UpdateSiteSetting(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
+// GetSecurityConfig returns the effective CORS and security header
+// configuration, including whether each value comes from an environment
+// variable, a site setting, or the built-in default (admin only)
+func GetSecurityConfig(securityConfig *middleware.SecurityConfigStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, securityConfig.Get(c.Request.Context()))
+	}
+}
+
+// GetLoginThrottleStats returns how many login and password reset attempts
+// the per-IP throttle has refused on this replica, and which IPs it is
+// throttling now (admin only)
+func GetLoginThrottleStats(throttler *middleware.LoginThrottler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, throttler.Stats())
+	}
+}
+
+// UnblockLoginThrottleIP clears an IP's failed attempts on this replica, for
+// example a shelter's shared office connection (admin only)
+func UnblockLoginThrottleIP(throttler *middleware.LoginThrottler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := c.Param("ip")
+		if !throttler.Unblock(ip) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "IP is not throttled"})
+			return
+		}
+		middleware.GetLogger(c).WithField("ip", ip).Info("Login throttle cleared for IP")
+		c.JSON(http.StatusOK, gin.H{"message": "IP unblocked"})
+	}
+}
+
+// GetImageConfig returns the effective image upload limits and processing
+// settings, including whether each value comes from an environment variable,
+// a site setting, or the built-in default (admin only)
+func GetImageConfig(imageConfig *upload.ImageConfigStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, imageConfig.Get(c.Request.Context()))
+	}
+}
+
+// GetAlertConfig returns the effective alert thresholds, including whether
+// each comes from an environment variable, a site setting, or the built-in
+// default, and which sinks are configured (admin only)
+func GetAlertConfig(alerter *alerting.Alerter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"config": alerter.Config(c.Request.Context()),
+			"sinks":  alerter.SinkNames(),
+		})
+	}
+}
+
+// SendTestAlert sends a test alert to every configured sink and reports
+// each sink's result (admin only)
+func SendTestAlert(alerter *alerting.Alerter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		results := make(map[string]string)
+		for name, err := range alerter.SendTest(c.Request.Context()) {
+			if err != nil {
+				results[name] = err.Error()
+			} else {
+				results[name] = "ok"
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{"results": results})
+	}
+}
+
 // UploadHeroImage handles hero image upload (admin only).
 // The image is persisted to durable storage (postgres bytea or Azure Blob) via
 // an AnimalImage record so that ServeImage can resolve it on subsequent requests.
 // The caller must persist the returned URL separately via PUT /api/admin/settings/hero_image_url.
-func UploadHeroImage(db *gorm.DB, storageProvider storage.Provider) gin.HandlerFunc {
+func UploadHeroImage(db *gorm.DB, storageProvider storage.Provider, imageConfig *upload.ImageConfigStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
 		db := middleware.GetDB(c, db)
 		logger := middleware.GetLogger(c)
+		cfg := imageConfig.Get(ctx)
 
 		userID := c.GetUint("user_id")
 
@@ -125,7 +249,7 @@ func UploadHeroImage(db *gorm.DB, storageProvider storage.Provider) gin.HandlerF
 		}
 
 		// Validate file upload (size, type, content) - use smaller limit for hero images
-		if err := upload.ValidateImageUpload(file, upload.MaxHeroImageSize); err != nil {
+		if err := upload.ValidateImageUpload(file, cfg.MaxHeroUploadBytes); err != nil {
 			logger.Error("File validation failed", err)
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file: " + err.Error()})
 			return
@@ -157,41 +281,18 @@ func UploadHeroImage(db *gorm.DB, storageProvider storage.Provider) gin.HandlerF
 			}
 		}
 
-		// Upload to storage provider (generates URL and, for Azure, persists the blob)
-		storageURL, blobUUID, blobExt, err := storageProvider.UploadImage(ctx, data, mimeType, nil)
+		// Resize and re-encode using the configured image settings
+		data, mimeType, err = upload.ProcessImageOrOriginal(data, mimeType, cfg.MaxHeroDimension, cfg)
 		if err != nil {
-			logger.Error("Failed to upload image to storage", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload image"})
+			logger.Error("Failed to process image", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process image"})
 			return
 		}
 
-		// Persist image data so ServeImage can resolve the /api/images/:uuid URL.
-		// For postgres the raw bytes are stored; for Azure only the blob identifier.
-		var imageDataForDB []byte
-		var storageProviderName string
-		var blobIdentifier string
-		if storageProvider.Name() == "azure" {
-			storageProviderName = "azure"
-			blobIdentifier = blobUUID + blobExt
-		} else {
-			storageProviderName = "postgres"
-			imageDataForDB = data
-		}
-
-		record := models.AnimalImage{
-			AnimalID:        nil, // Not linked to any animal — hero image
-			UserID:          userID,
-			ImageURL:        storageURL,
-			ImageData:       imageDataForDB,
-			MimeType:        mimeType,
-			FileSize:        int64(len(data)),
-			StorageProvider: storageProviderName,
-			BlobIdentifier:  blobIdentifier,
-			BlobExtension:   blobExt,
-		}
-		if err := db.Create(&record).Error; err != nil {
-			logger.Error("Failed to persist hero image record", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save image"})
+		storageURL, err := storeUnlinkedImage(ctx, db, storageProvider, data, mimeType, userID)
+		if err != nil {
+			logger.Error("Failed to store hero image", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload image"})
 			return
 		}
 
@@ -199,3 +300,42 @@ func UploadHeroImage(db *gorm.DB, storageProvider storage.Provider) gin.HandlerF
 		c.JSON(http.StatusOK, gin.H{"url": storageURL})
 	}
 }
+
+// storeUnlinkedImage uploads an image that belongs to no animal, such as the
+// hero image or a group logo, and persists an AnimalImage record for it so
+// ServeImage can resolve the returned /api/images/:uuid URL. For postgres the
+// raw bytes are stored; for Azure only the blob identifier.
+func storeUnlinkedImage(ctx context.Context, db *gorm.DB, storageProvider storage.Provider, data []byte, mimeType string, userID uint) (string, error) {
+	// Upload to storage provider (generates URL and, for Azure, persists the blob)
+	storageURL, blobUUID, blobExt, err := storageProvider.UploadImage(ctx, data, mimeType, nil)
+	if err != nil {
+		return "", fmt.Errorf("upload image to storage: %w", err)
+	}
+
+	var imageDataForDB []byte
+	var storageProviderName string
+	var blobIdentifier string
+	if storageProvider.Name() == "azure" {
+		storageProviderName = "azure"
+		blobIdentifier = blobUUID + blobExt
+	} else {
+		storageProviderName = "postgres"
+		imageDataForDB = data
+	}
+
+	record := models.AnimalImage{
+		AnimalID:        nil, // Not linked to any animal
+		UserID:          userID,
+		ImageURL:        storageURL,
+		ImageData:       imageDataForDB,
+		MimeType:        mimeType,
+		FileSize:        int64(len(data)),
+		StorageProvider: storageProviderName,
+		BlobIdentifier:  blobIdentifier,
+		BlobExtension:   blobExt,
+	}
+	if err := db.WithContext(ctx).Create(&record).Error; err != nil {
+		return "", fmt.Errorf("persist image record: %w", err)
+	}
+	return storageURL, nil
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -9,7 +10,10 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/alerting"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/upload"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
@@ -127,7 +131,7 @@ func TestUpdateSiteSetting(t *testing.T) {
 			c.Params = gin.Params{{Key: "key", Value: tt.key}}
 
 			// Execute
-			handler := UpdateSiteSetting(db)
+			handler := UpdateSiteSetting(db, nil, nil)
 			handler(c)
 
 			// Assert
@@ -315,7 +319,7 @@ func TestUpdateSiteSetting_Validation(t *testing.T) {
 			c.Params = gin.Params{{Key: "key", Value: tt.key}}
 
 			// Execute
-			handler := UpdateSiteSetting(db)
+			handler := UpdateSiteSetting(db, nil, nil)
 			handler(c)
 
 			// Assert
@@ -360,7 +364,7 @@ func TestUpdateSiteSetting_UpsertBehavior(t *testing.T) {
 	c.Request.Header.Set("Content-Type", "application/json")
 	c.Params = gin.Params{{Key: "key", Value: "new_setting_key"}}
 
-	handler := UpdateSiteSetting(db)
+	handler := UpdateSiteSetting(db, nil, nil)
 	handler(c)
 
 	// Assert success
@@ -382,7 +386,7 @@ func TestUpdateSiteSetting_UpsertBehavior(t *testing.T) {
 	c2.Request.Header.Set("Content-Type", "application/json")
 	c2.Params = gin.Params{{Key: "key", Value: "new_setting_key"}}
 
-	handler2 := UpdateSiteSetting(db)
+	handler2 := UpdateSiteSetting(db, nil, nil)
 	handler2(c2)
 
 	// Assert success
@@ -462,7 +466,7 @@ func TestUploadHeroImage(t *testing.T) {
 			c.Request = tt.request(t)
 			c.Set("user_id", uint(1))
 
-			handler := UploadHeroImage(db, tt.provider)
+			handler := UploadHeroImage(db, tt.provider, nil)
 			handler(c)
 
 			assert.Equal(t, tt.expectedStatus, w.Code)
@@ -470,3 +474,106 @@ func TestUploadHeroImage(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateSiteSetting_SecuritySettings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("ALLOWED_ORIGINS", "")
+	db := setupSettingsTestDB(t)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	store := middleware.NewSecurityConfigStore(db)
+
+	update := func(key, value string) int {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		body, _ := json.Marshal(map[string]string{"value": value})
+		c.Request = httptest.NewRequest("PUT", "/settings/"+key, bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "key", Value: key}}
+		UpdateSiteSetting(db, store, nil)(c)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusBadRequest, update(middleware.SettingAllowedOrigins, "https://ok.example.org,not-an-origin"))
+	assert.Equal(t, http.StatusBadRequest, update(middleware.SettingFrameOptions, "ALLOWALL"))
+
+	// Prime the cache, then confirm a valid update is visible immediately.
+	store.Get(context.Background())
+	require.Equal(t, http.StatusOK, update(middleware.SettingAllowedOrigins, " https://volunteers.example.org "))
+	cfg := store.Get(context.Background())
+	assert.Equal(t, []string{"https://volunteers.example.org"}, cfg.AllowedOrigins)
+	assert.Equal(t, middleware.SecuritySourceSetting, cfg.Sources[middleware.SettingAllowedOrigins])
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/admin/security-config", nil)
+	GetSecurityConfig(store)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"allowed_origins":["https://volunteers.example.org"]`)
+}
+
+func TestUpdateSiteSetting_ImageSettings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("IMAGE_MAX_DIMENSION", "")
+	db := setupSettingsTestDB(t)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	store := upload.NewImageConfigStore(db)
+
+	update := func(key, value string) int {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		body, _ := json.Marshal(map[string]string{"value": value})
+		c.Request = httptest.NewRequest("PUT", "/settings/"+key, bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "key", Value: key}}
+		UpdateSiteSetting(db, nil, store)(c)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusBadRequest, update(upload.SettingImageJPEGQuality, "150"))
+	assert.Equal(t, http.StatusBadRequest, update(upload.SettingImageOutputFormat, "bmp"))
+
+	// Prime the cache, then confirm a valid update is visible immediately.
+	store.Get(context.Background())
+	require.Equal(t, http.StatusOK, update(upload.SettingImageMaxDimension, " 1600 "))
+	cfg := store.Get(context.Background())
+	assert.Equal(t, 1600, cfg.MaxDimension)
+	assert.Equal(t, upload.ImageSourceSetting, cfg.Sources[upload.SettingImageMaxDimension])
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/admin/image-config", nil)
+	GetImageConfig(store)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"max_dimension":1600`)
+}
+
+func TestUpdateSiteSetting_AlertSettings(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("ALERT_ERROR_THRESHOLD", "")
+	db := setupSettingsTestDB(t)
+
+	update := func(key, value string) int {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		body, _ := json.Marshal(map[string]string{"value": value})
+		c.Request = httptest.NewRequest("PUT", "/settings/"+key, bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "key", Value: key}}
+		UpdateSiteSetting(db, nil, nil)(c)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusBadRequest, update(alerting.SettingAlertErrorWindow, "0"))
+	assert.Equal(t, http.StatusBadRequest, update(alerting.SettingAlertEmailAdmins, "yes"))
+	require.Equal(t, http.StatusOK, update(alerting.SettingAlertErrorThreshold, " 40 "))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/admin/alerts/config", nil)
+	GetAlertConfig(alerting.NewAlerter(alerting.NewConfigStore(db)))(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"error_threshold":40`)
+	assert.Contains(t, w.Body.String(), `"sinks":[]`)
+}
@@ -0,0 +1,691 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/upload"
+	"gorm.io/gorm"
+)
+
+// siteConfigVersion is the format version of an exported SiteConfig
+const siteConfigVersion = 1
+
+const defaultTagColor = "#6b7280"
+
+// secretSettingWords mark setting keys that hold credentials. Site settings
+// are public and secrets belong in the environment, but the export leaves
+// out any key that looks like one in case an admin stored it anyway.
+var secretSettingWords = []string{"secret", "token", "password", "api_key", "apikey", "private_key", "webhook"}
+
+func isSecretSettingKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, word := range secretSettingWords {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// SiteConfig is a site's configuration in a form that can be imported into
+// another install. Groups are matched by name rather than ID. Secrets, media,
+// and GroupMe bot IDs are never included.
+type SiteConfig struct {
+	Version    int                 `json:"version"`
+	ExportedAt time.Time           `json:"exported_at"`
+	Settings   map[string]string   `json:"settings"`
+	Statuses   []AnimalStatusInput `json:"statuses,omitempty"` // Site-wide taxonomy; omitted when the built-in defaults apply
+	Breeds     []SiteConfigBreed   `json:"breeds"`
+	Groups     []SiteConfigGroup   `json:"groups"`
+}
+
+// SiteConfigBreed is a managed breed in a SiteConfig
+type SiteConfigBreed struct {
+	Species string   `json:"species"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// SiteConfigGroup is a group's name and description with its tags and its
+// own status taxonomy, if it has one
+type SiteConfigGroup struct {
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	CommentTags []SiteConfigTag       `json:"comment_tags"`
+	AnimalTags  []SiteConfigAnimalTag `json:"animal_tags"`
+	Statuses    []AnimalStatusInput   `json:"statuses,omitempty"`
+}
+
+// SiteConfigTag is a comment tag in a SiteConfigGroup
+type SiteConfigTag struct {
+	Name     string `json:"name"`
+	Color    string `json:"color"`
+	IsSystem bool   `json:"is_system,omitempty"`
+}
+
+// SiteConfigAnimalTag is an animal tag in a SiteConfigGroup
+type SiteConfigAnimalTag struct {
+	Name       string `json:"name"`
+	Category   string `json:"category"`
+	Color      string `json:"color"`
+	Restricted bool   `json:"restricted,omitempty"`
+}
+
+// SiteConfigChange is one thing an import creates or updates
+type SiteConfigChange struct {
+	Section string   `json:"section"`          // "setting", "breed", "group", "comment_tag", "animal_tag", or "statuses"
+	Group   string   `json:"group,omitempty"`  // For tags and group statuses
+	Name    string   `json:"name,omitempty"`   // Setting key, breed, group, or tag name
+	Action  string   `json:"action"`           // "create" or "update"
+	Fields  []string `json:"fields,omitempty"` // What an update changes
+	From    string   `json:"from,omitempty"`   // Old setting value or status keys
+	To      string   `json:"to,omitempty"`     // New setting value or status keys
+}
+
+// SiteConfigImportResult lists what an import changed, or with dry_run
+// would change
+type SiteConfigImportResult struct {
+	DryRun    bool               `json:"dry_run"`
+	Changes   []SiteConfigChange `json:"changes"`
+	Unchanged int                `json:"unchanged"`
+}
+
+func statusInputs(rows []models.AnimalStatus) []AnimalStatusInput {
+	if len(rows) == 0 {
+		return nil
+	}
+	inputs := make([]AnimalStatusInput, len(rows))
+	for i, s := range rows {
+		inputs[i] = AnimalStatusInput{Key: s.Key, Label: s.Label, Color: s.Color, DateField: s.DateField}
+	}
+	return inputs
+}
+
+// ExportSiteConfig returns the site settings, status taxonomies, breeds, and
+// each group's comment and animal tags as a SiteConfig (admin only)
+// Route: GET /api/admin/site-config
+func ExportSiteConfig(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		cfg := SiteConfig{
+			Version:    siteConfigVersion,
+			ExportedAt: time.Now().UTC(),
+			Settings:   map[string]string{},
+			Breeds:     []SiteConfigBreed{},
+			Groups:     []SiteConfigGroup{},
+		}
+
+		var settings []models.SiteSetting
+		var statuses []models.AnimalStatus
+		var breeds []models.Breed
+		var groups []models.Group
+		var commentTags []models.CommentTag
+		var animalTags []models.AnimalTag
+		for _, load := range []func() error{
+			func() error { return db.Find(&settings).Error },
+			func() error { return db.Order("order_index, id").Find(&statuses).Error },
+			func() error { return db.Order("species, name").Find(&breeds).Error },
+			func() error { return db.Order("name").Find(&groups).Error },
+			func() error { return db.Order("is_system DESC, name").Find(&commentTags).Error },
+			func() error { return db.Order("category, name").Find(&animalTags).Error },
+		} {
+			if err := load(); err != nil {
+				respondInternalError(c, "Failed to export site configuration")
+				return
+			}
+		}
+
+		for _, s := range settings {
+			if !isSecretSettingKey(s.Key) {
+				cfg.Settings[s.Key] = s.Value
+			}
+		}
+		groupStatuses := map[uint][]models.AnimalStatus{}
+		var siteStatuses []models.AnimalStatus
+		for _, s := range statuses {
+			if s.GroupID == nil {
+				siteStatuses = append(siteStatuses, s)
+			} else {
+				groupStatuses[*s.GroupID] = append(groupStatuses[*s.GroupID], s)
+			}
+		}
+		cfg.Statuses = statusInputs(siteStatuses)
+		for _, b := range breeds {
+			cfg.Breeds = append(cfg.Breeds, SiteConfigBreed{Species: b.Species, Name: b.Name, Aliases: b.Aliases})
+		}
+
+		index := make(map[uint]int, len(groups))
+		for i, g := range groups {
+			index[g.ID] = i
+			cfg.Groups = append(cfg.Groups, SiteConfigGroup{
+				Name:        g.Name,
+				Description: g.Description,
+				CommentTags: []SiteConfigTag{},
+				AnimalTags:  []SiteConfigAnimalTag{},
+				Statuses:    statusInputs(groupStatuses[g.ID]),
+			})
+		}
+		for _, t := range commentTags {
+			if i, ok := index[t.GroupID]; ok {
+				cfg.Groups[i].CommentTags = append(cfg.Groups[i].CommentTags, SiteConfigTag{Name: t.Name, Color: t.Color, IsSystem: t.IsSystem})
+			}
+		}
+		for _, t := range animalTags {
+			if i, ok := index[t.GroupID]; ok {
+				cfg.Groups[i].AnimalTags = append(cfg.Groups[i].AnimalTags, SiteConfigAnimalTag{
+					Name: t.Name, Category: t.Category, Color: t.Color, Restricted: t.Restricted,
+				})
+			}
+		}
+
+		c.Header("Content-Disposition", `attachment; filename="site-config.json"`)
+		respondOK(c, cfg)
+	}
+}
+
+// siteConfigPlan collects the changes an import makes and the steps that
+// make them, in order, so validation and the dry-run preview share one pass
+// over the file with the real import
+type siteConfigPlan struct {
+	db     *gorm.DB
+	result SiteConfigImportResult
+	steps  []func(tx *gorm.DB) error
+	errs   []FieldError
+
+	securityChanged, imageChanged bool
+}
+
+func (p *siteConfigPlan) invalid(field, format string, args ...interface{}) {
+	p.errs = append(p.errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (p *siteConfigPlan) change(change SiteConfigChange, step func(tx *gorm.DB) error) {
+	p.result.Changes = append(p.result.Changes, change)
+	p.steps = append(p.steps, step)
+}
+
+// tagColor defaults an empty tag color and checks it is a hex color
+func (p *siteConfigPlan) tagColor(field, color string) string {
+	color = strings.TrimSpace(color)
+	if color == "" {
+		return defaultTagColor
+	}
+	if !statusColorPattern.MatchString(color) {
+		p.invalid(field, "must be a hex color like #22c55e")
+	}
+	return color
+}
+
+func (p *siteConfigPlan) settings(settings map[string]string) error {
+	var existing []models.SiteSetting
+	if err := p.db.Find(&existing).Error; err != nil {
+		return err
+	}
+	current := make(map[string]string, len(existing))
+	for _, s := range existing {
+		current[s.Key] = s.Value
+	}
+
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		field := "settings." + key
+		if strings.TrimSpace(key) == "" || len(key) > 100 {
+			p.invalid(field, "setting keys must be 1-100 characters")
+			continue
+		}
+		if isSecretSettingKey(key) {
+			p.invalid(field, "looks like a secret; set it in the environment instead")
+			continue
+		}
+		value, err := validateSiteSetting(key, settings[key])
+		if err != nil {
+			p.invalid(field, "%s", err.Error())
+			continue
+		}
+		old, exists := current[key]
+		if exists && old == value {
+			p.result.Unchanged++
+			continue
+		}
+		p.securityChanged = p.securityChanged || middleware.IsSecuritySetting(key)
+		p.imageChanged = p.imageChanged || upload.IsImageSetting(key)
+		if exists {
+			p.change(SiteConfigChange{Section: "setting", Name: key, Action: "update", From: old, To: value}, func(tx *gorm.DB) error {
+				return tx.Model(&models.SiteSetting{}).Where("key = ?", key).Update("value", value).Error
+			})
+		} else {
+			p.change(SiteConfigChange{Section: "setting", Name: key, Action: "create", To: value}, func(tx *gorm.DB) error {
+				return tx.Create(&models.SiteSetting{Key: key, Value: value}).Error
+			})
+		}
+	}
+	return nil
+}
+
+// statuses plans replacing the taxonomy of a group (nil for the site) whose
+// current rows are current. inUse scopes the animals whose statuses must
+// stay valid, and is nil for groups the import creates; their ID is filled
+// in by an earlier step.
+func (p *siteConfigPlan) statuses(field, groupName string, inputs []AnimalStatusInput, groupID *uint, current []models.AnimalStatus, inUse *gorm.DB) error {
+	if len(inputs) == 0 {
+		return nil
+	}
+	rows, err := buildAnimalStatuses(AnimalStatusesRequest{Statuses: inputs}, groupID)
+	if err != nil {
+		p.invalid(field, "%s", err.Error())
+		return nil
+	}
+	same := len(rows) == len(current)
+	for i := 0; same && i < len(rows); i++ {
+		r, cur := rows[i], current[i]
+		same = r.Key == cur.Key && r.Label == cur.Label && r.Color == cur.Color && r.DateField == cur.DateField
+	}
+	if same {
+		p.result.Unchanged++
+		return nil
+	}
+	if inUse != nil {
+		missing, err := statusKeysInUse(inUse, rows)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			p.invalid(field, "statuses still assigned to animals can't be removed: %s", strings.Join(missing, ", "))
+			return nil
+		}
+	}
+
+	change := SiteConfigChange{Section: "statuses", Group: groupName, Action: "update", To: allowedStatusKeys(rows)}
+	if len(current) == 0 {
+		change.Action = "create"
+	} else {
+		change.From = allowedStatusKeys(current)
+	}
+	p.change(change, func(tx *gorm.DB) error {
+		return replaceAnimalStatuses(tx, groupID, rows)
+	})
+	return nil
+}
+
+func (p *siteConfigPlan) breeds(breeds []SiteConfigBreed) error {
+	var existing []models.Breed
+	if err := p.db.Find(&existing).Error; err != nil {
+		return err
+	}
+	// The breed list after the import, by species and name key, to check
+	// imported names and aliases don't collide with other breeds
+	final := map[string]map[string]models.Breed{}
+	for _, b := range existing {
+		if final[breedKey(b.Species)] == nil {
+			final[breedKey(b.Species)] = map[string]models.Breed{}
+		}
+		final[breedKey(b.Species)][breedKey(b.Name)] = b
+	}
+
+	imported := map[string]bool{}
+	for i, in := range breeds {
+		field := fmt.Sprintf("breeds[%d]", i)
+		species, name := strings.TrimSpace(in.Species), strings.TrimSpace(in.Name)
+		if breedKey(species) == "" || len(species) > 50 || breedKey(name) == "" || len(name) > 100 {
+			p.invalid(field, "species (1-50 characters) and name (1-100 characters) are required")
+			continue
+		}
+		aliases := cleanBreedAliases(name, in.Aliases)
+		speciesKey, nameKey := breedKey(species), breedKey(name)
+		if final[speciesKey] == nil {
+			final[speciesKey] = map[string]models.Breed{}
+		}
+		if imported[speciesKey+"/"+nameKey] {
+			p.invalid(field, "%s %q is listed twice", species, name)
+			continue
+		}
+		imported[speciesKey+"/"+nameKey] = true
+		old, exists := final[speciesKey][nameKey]
+		breed := models.Breed{ID: old.ID, Species: species, Name: name, Aliases: aliases}
+		final[speciesKey][nameKey] = breed
+
+		if !exists {
+			p.change(SiteConfigChange{Section: "breed", Name: species + " / " + name, Action: "create"}, func(tx *gorm.DB) error {
+				return tx.Create(&breed).Error
+			})
+			continue
+		}
+		var fields []string
+		if old.Species != species {
+			fields = append(fields, "species")
+		}
+		if old.Name != name {
+			fields = append(fields, "name")
+		}
+		if strings.Join(old.Aliases, "\x00") != strings.Join(aliases, "\x00") {
+			fields = append(fields, "aliases")
+		}
+		if len(fields) == 0 {
+			p.result.Unchanged++
+			continue
+		}
+		p.change(SiteConfigChange{Section: "breed", Name: species + " / " + name, Action: "update", Fields: fields}, func(tx *gorm.DB) error {
+			return tx.Model(&models.Breed{ID: breed.ID}).Updates(map[string]interface{}{
+				"species": breed.Species, "name": breed.Name, "aliases": breed.Aliases,
+			}).Error
+		})
+	}
+
+	for _, bySpecies := range final {
+		aliasOf := map[string]string{}
+		for _, b := range bySpecies {
+			for _, alias := range b.Aliases {
+				key := breedKey(alias)
+				if other, ok := bySpecies[key]; ok {
+					p.invalid("breeds", "alias %q of %s %q is the name of %q", alias, b.Species, b.Name, other.Name)
+				} else if other, ok := aliasOf[key]; ok {
+					p.invalid("breeds", "alias %q is used by both %q and %q", alias, other, b.Name)
+				}
+				aliasOf[key] = b.Name
+			}
+		}
+	}
+	return nil
+}
+
+func (p *siteConfigPlan) groups(groups []SiteConfigGroup) error {
+	seen := map[string]bool{}
+	for i, in := range groups {
+		field := fmt.Sprintf("groups[%d]", i)
+		name := strings.TrimSpace(in.Name)
+		if name == "" || len(name) > 100 {
+			p.invalid(field+".name", "must be 1-100 characters")
+			continue
+		}
+		if seen[name] {
+			p.invalid(field+".name", "group %q is listed twice", name)
+			continue
+		}
+		seen[name] = true
+
+		var group models.Group
+		err := p.db.Unscoped().Where("name = ?", name).First(&group).Error
+		exists := err == nil
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if exists && group.DeletedAt.Valid {
+			p.invalid(field+".name", "group %q was deleted on this site; restore or rename it first", name)
+			continue
+		}
+
+		// groupID is filled in when a new group is created, before the
+		// steps for its tags and statuses run
+		groupID := new(uint)
+		switch {
+		case !exists:
+			p.change(SiteConfigChange{Section: "group", Name: name, Action: "create"}, func(tx *gorm.DB) error {
+				g := models.Group{Name: name, Description: in.Description}
+				if err := tx.Create(&g).Error; err != nil {
+					return err
+				}
+				*groupID = g.ID
+				return nil
+			})
+		case group.Description != in.Description:
+			*groupID = group.ID
+			p.change(SiteConfigChange{Section: "group", Name: name, Action: "update", Fields: []string{"description"}}, func(tx *gorm.DB) error {
+				return tx.Model(&models.Group{}).Where("id = ?", group.ID).Update("description", in.Description).Error
+			})
+		default:
+			*groupID = group.ID
+			p.result.Unchanged++
+		}
+
+		if err := p.commentTags(field, name, groupID, exists, in.CommentTags); err != nil {
+			return err
+		}
+		if err := p.animalTags(field, name, groupID, exists, in.AnimalTags); err != nil {
+			return err
+		}
+		var current []models.AnimalStatus
+		var inUse *gorm.DB
+		if exists {
+			if err := p.db.Where("group_id = ?", group.ID).Order("order_index, id").Find(&current).Error; err != nil {
+				return err
+			}
+			inUse = p.db.Where("group_id = ?", group.ID)
+		}
+		if err := p.statuses(field+".statuses", name, in.Statuses, groupID, current, inUse); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commentTags plans upserting a group's comment tags by name. A tag that
+// was deleted is restored rather than created again, since names stay
+// unique among deleted tags.
+func (p *siteConfigPlan) commentTags(field, groupName string, groupID *uint, groupExists bool, tags []SiteConfigTag) error {
+	current := map[string]models.CommentTag{}
+	if groupExists {
+		var rows []models.CommentTag
+		if err := p.db.Unscoped().Where("group_id = ?", *groupID).Find(&rows).Error; err != nil {
+			return err
+		}
+		for _, t := range rows {
+			current[t.Name] = t
+		}
+	}
+	seen := map[string]bool{}
+	for i, in := range tags {
+		tagField := fmt.Sprintf("%s.comment_tags[%d]", field, i)
+		name := strings.TrimSpace(in.Name)
+		if name == "" || len(name) > 50 {
+			p.invalid(tagField+".name", "must be 1-50 characters")
+			continue
+		}
+		if seen[name] {
+			p.invalid(tagField+".name", "tag %q is listed twice", name)
+			continue
+		}
+		seen[name] = true
+		color := p.tagColor(tagField+".color", in.Color)
+		isSystem := in.IsSystem
+
+		old, exists := current[name]
+		if !exists {
+			p.change(SiteConfigChange{Section: "comment_tag", Group: groupName, Name: name, Action: "create"}, func(tx *gorm.DB) error {
+				return tx.Create(&models.CommentTag{GroupID: *groupID, Name: name, Color: color, IsSystem: isSystem}).Error
+			})
+			continue
+		}
+		var fields []string
+		if old.DeletedAt.Valid {
+			fields = append(fields, "restored")
+		}
+		if old.Color != color {
+			fields = append(fields, "color")
+		}
+		if old.IsSystem != isSystem {
+			fields = append(fields, "is_system")
+		}
+		if len(fields) == 0 {
+			p.result.Unchanged++
+			continue
+		}
+		p.change(SiteConfigChange{Section: "comment_tag", Group: groupName, Name: name, Action: "update", Fields: fields}, func(tx *gorm.DB) error {
+			return tx.Unscoped().Model(&models.CommentTag{}).Where("id = ?", old.ID).Updates(map[string]interface{}{
+				"color": color, "is_system": isSystem, "deleted_at": nil,
+			}).Error
+		})
+	}
+	return nil
+}
+
+// animalTags plans upserting a group's animal tags by name, like commentTags
+func (p *siteConfigPlan) animalTags(field, groupName string, groupID *uint, groupExists bool, tags []SiteConfigAnimalTag) error {
+	current := map[string]models.AnimalTag{}
+	if groupExists {
+		var rows []models.AnimalTag
+		if err := p.db.Unscoped().Where("group_id = ?", *groupID).Find(&rows).Error; err != nil {
+			return err
+		}
+		for _, t := range rows {
+			current[t.Name] = t
+		}
+	}
+	seen := map[string]bool{}
+	for i, in := range tags {
+		tagField := fmt.Sprintf("%s.animal_tags[%d]", field, i)
+		name := strings.TrimSpace(in.Name)
+		if name == "" || len(name) > 50 {
+			p.invalid(tagField+".name", "must be 1-50 characters")
+			continue
+		}
+		if seen[name] {
+			p.invalid(tagField+".name", "tag %q is listed twice", name)
+			continue
+		}
+		seen[name] = true
+		if in.Category != "behavior" && in.Category != "walker_status" {
+			p.invalid(tagField+".category", "must be behavior or walker_status")
+			continue
+		}
+		color := p.tagColor(tagField+".color", in.Color)
+		tag := models.AnimalTag{Name: name, Category: in.Category, Color: color, Restricted: in.Restricted}
+
+		old, exists := current[name]
+		if !exists {
+			p.change(SiteConfigChange{Section: "animal_tag", Group: groupName, Name: name, Action: "create"}, func(tx *gorm.DB) error {
+				tag.GroupID = *groupID
+				return tx.Create(&tag).Error
+			})
+			continue
+		}
+		var fields []string
+		if old.DeletedAt.Valid {
+			fields = append(fields, "restored")
+		}
+		if old.Category != tag.Category {
+			fields = append(fields, "category")
+		}
+		if old.Color != tag.Color {
+			fields = append(fields, "color")
+		}
+		if old.Restricted != tag.Restricted {
+			fields = append(fields, "restricted")
+		}
+		if len(fields) == 0 {
+			p.result.Unchanged++
+			continue
+		}
+		p.change(SiteConfigChange{Section: "animal_tag", Group: groupName, Name: name, Action: "update", Fields: fields}, func(tx *gorm.DB) error {
+			return tx.Unscoped().Model(&models.AnimalTag{}).Where("id = ?", old.ID).Updates(map[string]interface{}{
+				"category": tag.Category, "color": tag.Color, "restricted": tag.Restricted, "deleted_at": nil,
+			}).Error
+		})
+	}
+	return nil
+}
+
+// ImportSiteConfig applies a SiteConfig from ExportSiteConfig (admin only).
+// Settings, breeds, groups, and tags are upserted by key or name; nothing
+// missing from the file is deleted. A status taxonomy in the file replaces
+// the site's or group's, as long as no animal holds a status it drops.
+// The whole file is validated before anything is saved, and the import is
+// all or nothing. With ?dry_run=true it returns the changes it would make
+// without saving them. Importing the same file twice changes nothing.
+// Route: POST /api/admin/site-config/import
+func ImportSiteConfig(db *gorm.DB, securityConfig *middleware.SecurityConfigStore, imageConfig *upload.ImageConfigStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		logger := middleware.GetLogger(c)
+
+		var cfg SiteConfig
+		if err := c.ShouldBindJSON(&cfg); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		if cfg.Version != siteConfigVersion {
+			respondBadRequest(c, fmt.Sprintf("Unsupported configuration version %d; expected %d", cfg.Version, siteConfigVersion))
+			return
+		}
+
+		plan := &siteConfigPlan{
+			db:     db,
+			result: SiteConfigImportResult{DryRun: c.Query("dry_run") == "true", Changes: []SiteConfigChange{}},
+		}
+		var siteStatuses []models.AnimalStatus
+		err := db.Where("group_id IS NULL").Order("order_index, id").Find(&siteStatuses).Error
+		if err == nil {
+			err = plan.settings(cfg.Settings)
+		}
+		if err == nil {
+			// Only groups without their own taxonomy inherit the site-wide one
+			inUse := db.Where("group_id NOT IN (?)",
+				db.Model(&models.AnimalStatus{}).Distinct("group_id").Where("group_id IS NOT NULL"))
+			err = plan.statuses("statuses", "", cfg.Statuses, nil, siteStatuses, inUse)
+		}
+		if err == nil {
+			err = plan.breeds(cfg.Breeds)
+		}
+		if err == nil {
+			err = plan.groups(cfg.Groups)
+		}
+		if err != nil {
+			logger.Error("Failed to plan site configuration import", err)
+			respondInternalError(c, "Failed to import site configuration")
+			return
+		}
+
+		if len(plan.errs) > 0 {
+			messages := make([]string, len(plan.errs))
+			for i, e := range plan.errs {
+				messages[i] = e.Field + ": " + e.Message
+			}
+			respondError(c, http.StatusBadRequest, ErrCodeValidationFailed,
+				"Invalid configuration: "+strings.Join(messages, "; "), plan.errs...)
+			return
+		}
+		if plan.result.DryRun || len(plan.steps) == 0 {
+			respondOK(c, plan.result)
+			return
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			for _, step := range plan.steps {
+				if err := step(tx); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			logger.Error("Failed to import site configuration", err)
+			respondInternalError(c, "Failed to import site configuration")
+			return
+		}
+		if plan.securityChanged {
+			securityConfig.Invalidate()
+		}
+		if plan.imageChanged {
+			imageConfig.Invalidate()
+		}
+
+		userID, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventSiteConfigImported, userID, map[string]interface{}{
+			"changes":   len(plan.result.Changes),
+			"unchanged": plan.result.Unchanged,
+		})
+		respondOK(c, plan.result)
+	}
+}
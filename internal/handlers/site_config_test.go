@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/upload"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestSiteConfigExportImport(t *testing.T) {
+	source := SetupTestDB(t)
+	admin := CreateTestUser(t, source, "admin", "admin@example.com", "password123", true)
+	require.NoError(t, source.Create(&models.SiteSetting{Key: "site_name", Value: "Paws"}).Error)
+	require.NoError(t, source.Create(&models.SiteSetting{Key: "groupme_api_token", Value: "hunter2"}).Error)
+	dogs := CreateTestGroup(t, source, "Dogs", "Dog walkers")
+	require.NoError(t, source.Create(&models.CommentTag{GroupID: dogs.ID, Name: "behavior", Color: "#3b82f6", IsSystem: true}).Error)
+	require.NoError(t, source.Create(&models.AnimalTag{GroupID: dogs.ID, Name: "reactive", Category: "behavior", Color: "#f97316", Restricted: true}).Error)
+	rows, err := buildAnimalStatuses(AnimalStatusesRequest{Statuses: []AnimalStatusInput{{Key: "available"}, {Key: "on_trial", Label: "On trial"}}}, &dogs.ID)
+	require.NoError(t, err)
+	require.NoError(t, replaceAnimalStatuses(source, &dogs.ID, rows))
+	require.NoError(t, source.Create(&models.Breed{Species: "Dog", Name: "Labrador Retriever", Aliases: models.StringList{"Lab"}}).Error)
+
+	c, w := accountTestContext(admin.ID, true, http.MethodGet, "/", nil)
+	ExportSiteConfig(source)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var cfg SiteConfig
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &cfg))
+	assert.Equal(t, "Paws", cfg.Settings["site_name"])
+	assert.NotContains(t, cfg.Settings, "groupme_api_token", "secrets are left out")
+	require.Len(t, cfg.Groups, 1)
+	assert.Len(t, cfg.Groups[0].Statuses, 2)
+
+	target := SetupTestDB(t)
+	targetAdmin := CreateTestUser(t, target, "admin", "admin@example.com", "password123", true)
+	existing := CreateTestGroup(t, target, "Dogs", "Dog walkers")
+	require.NoError(t, target.Create(&models.AnimalTag{GroupID: existing.ID, Name: "reactive", Category: "behavior", Color: "#000000"}).Error)
+	importConfig := func(db *gorm.DB, query string, body interface{}) (int, SiteConfigImportResult) {
+		c, w := accountTestContext(targetAdmin.ID, true, http.MethodPost, "/"+query, body)
+		ImportSiteConfig(db, middleware.NewSecurityConfigStore(db), upload.NewImageConfigStore(db))(c)
+		var result SiteConfigImportResult
+		_ = json.Unmarshal(w.Body.Bytes(), &result)
+		return w.Code, result
+	}
+
+	code, preview := importConfig(target, "?dry_run=true", cfg)
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, preview.DryRun)
+	changes := map[string]SiteConfigChange{}
+	for _, ch := range preview.Changes {
+		changes[ch.Section+":"+ch.Name] = ch
+	}
+	assert.Equal(t, "create", changes["setting:site_name"].Action)
+	assert.Equal(t, "create", changes["comment_tag:behavior"].Action)
+	assert.Equal(t, []string{"color", "restricted"}, changes["animal_tag:reactive"].Fields)
+	assert.Equal(t, "available, on_trial", changes["statuses:"].To)
+	assert.Equal(t, "create", changes["breed:Dog / Labrador Retriever"].Action)
+	assert.Equal(t, 1, preview.Unchanged, "the group itself matches")
+	var tagCount int64
+	target.Model(&models.CommentTag{}).Count(&tagCount)
+	assert.Zero(t, tagCount, "a dry run saves nothing")
+
+	code, applied := importConfig(target, "", cfg)
+	require.Equal(t, http.StatusOK, code)
+	assert.Len(t, applied.Changes, len(preview.Changes))
+	var tag models.AnimalTag
+	require.NoError(t, target.Where("group_id = ? AND name = ?", existing.ID, "reactive").First(&tag).Error)
+	assert.Equal(t, "#f97316", tag.Color)
+	assert.True(t, tag.Restricted)
+	statuses, err := effectiveAnimalStatuses(target, existing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "On trial", statuses[1].Label)
+
+	code, again := importConfig(target, "", cfg)
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, again.Changes, "importing the same file twice changes nothing")
+
+	t.Run("new groups are created with their tags", func(t *testing.T) {
+		empty := SetupTestDB(t)
+		code, result := importConfig(empty, "", cfg)
+		require.Equal(t, http.StatusOK, code)
+		assert.NotEmpty(t, result.Changes)
+		var group models.Group
+		require.NoError(t, empty.Where("name = ?", "Dogs").First(&group).Error)
+		var tags int64
+		empty.Model(&models.AnimalTag{}).Where("group_id = ?", group.ID).Count(&tags)
+		assert.Equal(t, int64(1), tags)
+		statuses, err := effectiveAnimalStatuses(empty, group.ID)
+		require.NoError(t, err)
+		assert.Len(t, statuses, 2)
+	})
+
+	t.Run("invalid files change nothing", func(t *testing.T) {
+		bad := cfg
+		bad.Settings = map[string]string{"site_name": "Paws 2", "slack_webhook_url": "https://hooks.example.com/x"}
+		bad.Groups = []SiteConfigGroup{{Name: "Cats", AnimalTags: []SiteConfigAnimalTag{{Name: "shy", Category: "behavior", Color: "blue"}}}}
+		code, _ := importConfig(target, "", bad)
+		assert.Equal(t, http.StatusBadRequest, code)
+		var siteName models.SiteSetting
+		require.NoError(t, target.Where("key = ?", "site_name").First(&siteName).Error)
+		assert.Equal(t, "Paws", siteName.Value)
+		assert.Error(t, target.Where("name = ?", "Cats").First(&models.Group{}).Error)
+
+		bad = cfg
+		bad.Version = 2
+		code, _ = importConfig(target, "", bad)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("statuses in use can't be dropped", func(t *testing.T) {
+		require.NoError(t, target.Create(&models.Animal{GroupID: existing.ID, Name: "Rex", Status: "on_trial"}).Error)
+		bad := cfg
+		bad.Groups = []SiteConfigGroup{cfg.Groups[0]}
+		bad.Groups[0].Statuses = []AnimalStatusInput{{Key: "available"}}
+		code, _ := importConfig(target, "", bad)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
+
+func TestIsSecretSettingKey(t *testing.T) {
+	for key, want := range map[string]bool{
+		"site_name":         false,
+		"hero_image_url":    false,
+		"slack_webhook_url": true,
+		"SMTP_PASSWORD":     true,
+		"maps_api_key":      true,
+	} {
+		assert.Equal(t, want, isSecretSettingKey(key), key)
+	}
+}
@@ -47,6 +47,7 @@ func parseTimestamp(s string) *time.Time {
 	formats := []string{
 		time.RFC3339,
 		"2006-01-02 15:04:05.999999999 -07:00",
+		"2006-01-02 15:04:05.999999999-07:00", // SQLite aggregates of time columns
 		"2006-01-02 15:04:05",
 		"2006-01-02T15:04:05.999999999Z",
 	}
@@ -258,7 +259,9 @@ func GetUserStatistics(db *gorm.DB) gin.HandlerFunc {
 }
 
 // GetCommentTagStatistics returns statistics for comment tags with pagination support
-// Accepts optional group_id query parameter to filter by group
+// Accepts optional group_id query parameter to filter by group, which the
+// caller must have access to. Without one, it covers the groups of the
+// caller's organization.
 func GetCommentTagStatistics(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Add explicit timeout for query execution
@@ -275,6 +278,24 @@ func GetCommentTagStatistics(db *gorm.DB) gin.HandlerFunc {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group_id parameter"})
 				return
 			}
+			userID, _ := c.Get("user_id")
+			isAdmin, _ := c.Get("is_admin")
+			if !checkGroupAccess(db, userID, isAdmin, groupIDStr) {
+				respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+				return
+			}
+		}
+
+		// Tags of the requested group, or of the caller's organization
+		tagFilter := ""
+		var tagArgs []interface{}
+		if groupIDStr != "" {
+			tagFilter = " AND ct.group_id = ?"
+			tagArgs = append(tagArgs, groupIDStr)
+		}
+		if scope, scopeArgs := middleware.OrganizationGroupFilter(c, "ct.group_id"); scope != "" {
+			tagFilter += " AND " + scope
+			tagArgs = append(tagArgs, scopeArgs...)
 		}
 
 		// Get pagination parameters
@@ -297,18 +318,10 @@ func GetCommentTagStatistics(db *gorm.DB) gin.HandlerFunc {
 
 		// Get total count of tags
 		var total int64
-		countQuery := `SELECT COUNT(*) FROM comment_tags WHERE deleted_at IS NULL`
-		if groupIDStr != "" {
-			countQuery += ` AND group_id = ?`
-			if err := db.WithContext(ctx).Raw(countQuery, groupIDStr).Scan(&total).Error; err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count tags"})
-				return
-			}
-		} else {
-			if err := db.WithContext(ctx).Raw(countQuery).Scan(&total).Error; err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count tags"})
-				return
-			}
+		countQuery := `SELECT COUNT(*) FROM comment_tags ct WHERE ct.deleted_at IS NULL` + tagFilter
+		if err := db.WithContext(ctx).Raw(countQuery, tagArgs...).Scan(&total).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count tags"})
+			return
 		}
 
 		// Use a single aggregated query with window functions to get all tag statistics
@@ -335,12 +348,13 @@ func GetCommentTagStatistics(db *gorm.DB) gin.HandlerFunc {
 				LEFT JOIN animal_comment_tags act ON act.comment_tag_id = ct.id
 				LEFT JOIN animal_comments ac ON ac.id = act.animal_comment_id AND ac.deleted_at IS NULL
 				LEFT JOIN animals a ON a.id = ac.animal_id AND a.deleted_at IS NULL
-				WHERE ct.deleted_at IS NULL
-		`
+				WHERE ct.deleted_at IS NULL` + tagFilter
+		args := tagArgs
 
 		// Add group filter if specified
 		if groupIDStr != "" {
 			query += " AND (a.group_id = ? OR a.group_id IS NULL)"
+			args = append(args, groupIDStr)
 		}
 
 		query += `
@@ -359,13 +373,8 @@ func GetCommentTagStatistics(db *gorm.DB) gin.HandlerFunc {
 		`
 
 		var rawStats []TagStatsRaw
-		var err error
 		start := time.Now()
-		if groupIDStr != "" {
-			err = db.WithContext(ctx).Raw(query, groupIDStr, limit, offset).Scan(&rawStats).Error
-		} else {
-			err = db.WithContext(ctx).Raw(query, limit, offset).Scan(&rawStats).Error
-		}
+		err := db.WithContext(ctx).Raw(query, append(args, limit, offset)...).Scan(&rawStats).Error
 
 		duration := time.Since(start)
 		if duration > 1*time.Second {
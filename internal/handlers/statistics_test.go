@@ -1,13 +1,16 @@
 package handlers
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 )
 
@@ -49,8 +52,9 @@ func setupStatisticsTestDB(t *testing.T) *gorm.DB {
 	db.Create(&comment)
 
 	tag := models.CommentTag{
-		Name:  "urgent",
-		Color: "#FF0000",
+		GroupID: group.ID,
+		Name:    "urgent",
+		Color:   "#FF0000",
 	}
 	db.Create(&tag)
 	db.Model(&comment).Association("Tags").Append(&tag)
@@ -160,6 +164,10 @@ func TestGetCommentTagStatistics(t *testing.T) {
 			w := httptest.NewRecorder()
 			c, _ := gin.CreateTestContext(w)
 			c.Request = httptest.NewRequest("GET", "/statistics/comment-tags", nil)
+			var user models.User
+			db.First(&user)
+			c.Set("user_id", user.ID)
+			c.Set("is_admin", false)
 
 			// Execute
 			handler := GetCommentTagStatistics(db)
@@ -172,3 +180,58 @@ func TestGetCommentTagStatistics(t *testing.T) {
 		})
 	}
 }
+
+func TestGetCommentTagStatistics_OrganizationScope(t *testing.T) {
+	db := SetupTestDB(t)
+	north := models.Organization{Name: "North", Slug: "north"}
+	south := models.Organization{Name: "South", Slug: "south"}
+	require.NoError(t, db.Create(&north).Error)
+	require.NoError(t, db.Create(&south).Error)
+
+	member := CreateTestUser(t, db, "member", "member@example.com", "password123", false)
+	require.NoError(t, db.Model(member).Update("organization_id", north.ID).Error)
+	northGroup := CreateTestGroup(t, db, "North Dogs", "")
+	southGroup := CreateTestGroup(t, db, "South Dogs", "")
+	require.NoError(t, db.Model(northGroup).Update("organization_id", north.ID).Error)
+	require.NoError(t, db.Model(southGroup).Update("organization_id", south.ID).Error)
+	AddUserToGroupWithAdmin(t, db, member.ID, northGroup.ID, false)
+
+	tagComment := func(groupID uint, animalName, tagName string) models.CommentTag {
+		animal := CreateTestAnimal(t, db, groupID, animalName, "Dog")
+		tag := models.CommentTag{GroupID: groupID, Name: tagName}
+		require.NoError(t, db.Create(&tag).Error)
+		comment := models.AnimalComment{AnimalID: animal.ID, UserID: member.ID, Content: "Noted", Tags: []models.CommentTag{tag}}
+		require.NoError(t, db.Create(&comment).Error)
+		return tag
+	}
+	northTag := tagComment(northGroup.ID, "Rex", "leash reactive")
+	tagComment(southGroup.ID, "Secret", "bite history")
+
+	// Through OrganizationScope, as the route is mounted
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", member.ID)
+		c.Set("is_admin", false)
+	}, middleware.OrganizationScope(db))
+	router.GET("/api/statistics/comment-tags", GetCommentTagStatistics(db))
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/statistics/comment-tags"+query, nil))
+		return w
+	}
+
+	w := get("")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resp struct {
+		Data  []CommentTagStatistics `json:"data"`
+		Total int64                  `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data, 1, "only the caller's organization's tags")
+	assert.Equal(t, northTag.ID, resp.Data[0].TagID)
+	assert.Equal(t, int64(1), resp.Total)
+	assert.NotContains(t, w.Body.String(), "Secret")
+
+	assert.Equal(t, http.StatusForbidden, get("?group_id="+itoa(southGroup.ID)).Code)
+	assert.Equal(t, http.StatusOK, get("?group_id="+itoa(northGroup.ID)).Code)
+}
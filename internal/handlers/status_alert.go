@@ -0,0 +1,389 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobStatusAlertEmail is the background job type that tells a group admin
+// an animal has been in a status longer than one of the group's rules allows.
+const JobStatusAlertEmail = "status_alert_email"
+
+const (
+	// maxStatusAlertRules bounds how many rules a group can have
+	maxStatusAlertRules = 50
+	// maxStatusAlertDays bounds a rule's limit, at ten years
+	maxStatusAlertDays = 3650
+	// maxStatusAlertMessageLength bounds a rule's message
+	maxStatusAlertMessageLength = 200
+	// statusAlertStopTimeout bounds how long stop() waits for an in-flight
+	// tick to finish, matching the other schedulers' shutdown wait.
+	statusAlertStopTimeout = 10 * time.Second
+)
+
+// StatusAlertRuleInput is one entry of a StatusAlertRulesRequest
+type StatusAlertRuleInput struct {
+	Status  string `json:"status" binding:"required"`
+	MaxDays int    `json:"max_days" binding:"required"`
+	Message string `json:"message"`
+}
+
+// StatusAlertRulesRequest replaces a group's status alert rules
+type StatusAlertRulesRequest struct {
+	Rules []StatusAlertRuleInput `json:"rules" binding:"dive"`
+}
+
+// StatusAlert is a status alert rule an animal has passed, as listed on the
+// animal in list responses
+type StatusAlert struct {
+	RuleID  uint      `json:"rule_id"`
+	Status  string    `json:"status"`
+	MaxDays int       `json:"max_days"`
+	Days    int       `json:"days"`  // Whole days in the status so far
+	Since   time.Time `json:"since"` // When the animal entered the status
+	Message string    `json:"message,omitempty"`
+}
+
+// statusAlertEmailJob is the payload of a JobStatusAlertEmail job
+type statusAlertEmailJob struct {
+	AnimalID    uint      `json:"animal_id"`
+	UserID      uint      `json:"user_id"`
+	Status      string    `json:"status"`
+	MaxDays     int       `json:"max_days"`
+	StatusSince time.Time `json:"status_since"`
+	Message     string    `json:"message,omitempty"`
+}
+
+// animalStatusSince returns when animal entered its current status. Animals
+// whose status has never changed count from arrival, as the engagement
+// analytics do.
+func animalStatusSince(animal models.Animal) time.Time {
+	if animal.LastStatusChange != nil {
+		return *animal.LastStatusChange
+	}
+	if animal.ArrivalDate != nil {
+		return *animal.ArrivalDate
+	}
+	return animal.CreatedAt
+}
+
+// statusAlertDue reports whether animal has been in rule's status for more
+// than rule.MaxDays days at now
+func statusAlertDue(rule models.StatusAlertRule, animal models.Animal, now time.Time) bool {
+	return animal.Status == rule.Status && now.After(animalStatusSince(animal).AddDate(0, 0, rule.MaxDays))
+}
+
+// statusAlertsFor returns the alerts each animal in animals is flagged with
+// under groupID's rules, keyed by animal ID. Animals with none are absent.
+func statusAlertsFor(db *gorm.DB, groupID uint, animals []models.Animal, now time.Time) (map[uint][]StatusAlert, error) {
+	if len(animals) == 0 {
+		return nil, nil
+	}
+	var rules []models.StatusAlertRule
+	if err := db.Where("group_id = ?", groupID).Order("max_days, id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	alerts := make(map[uint][]StatusAlert)
+	for _, animal := range animals {
+		for _, rule := range rules {
+			if !statusAlertDue(rule, animal, now) {
+				continue
+			}
+			since := animalStatusSince(animal)
+			alerts[animal.ID] = append(alerts[animal.ID], StatusAlert{
+				RuleID:  rule.ID,
+				Status:  rule.Status,
+				MaxDays: rule.MaxDays,
+				Days:    daysSince(since, now),
+				Since:   since,
+				Message: rule.Message,
+			})
+		}
+	}
+	return alerts, nil
+}
+
+// buildStatusAlertRules validates req against statuses, the taxonomy
+// groupID uses, and returns the rows to store
+func buildStatusAlertRules(groupID uint, req StatusAlertRulesRequest, statuses []models.AnimalStatus) ([]models.StatusAlertRule, error) {
+	if len(req.Rules) > maxStatusAlertRules {
+		return nil, fmt.Errorf("a group can have at most %d status alert rules", maxStatusAlertRules)
+	}
+	known := make(map[string]bool, len(statuses))
+	for _, s := range statuses {
+		known[s.Key] = true
+	}
+
+	type ruleKey struct {
+		status  string
+		maxDays int
+	}
+	seen := make(map[ruleKey]bool, len(req.Rules))
+	rows := make([]models.StatusAlertRule, 0, len(req.Rules))
+	for _, in := range req.Rules {
+		status := strings.TrimSpace(in.Status)
+		message := strings.TrimSpace(in.Message)
+		if !known[status] {
+			return nil, fmt.Errorf("invalid status %q: must be one of: %s", status, allowedStatusKeys(statuses))
+		}
+		if in.MaxDays < 1 || in.MaxDays > maxStatusAlertDays {
+			return nil, fmt.Errorf("max_days must be between 1 and %d", maxStatusAlertDays)
+		}
+		if len(message) > maxStatusAlertMessageLength {
+			return nil, fmt.Errorf("message must be at most %d characters", maxStatusAlertMessageLength)
+		}
+		key := ruleKey{status, in.MaxDays}
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate rule for %s after %d days", status, in.MaxDays)
+		}
+		seen[key] = true
+		rows = append(rows, models.StatusAlertRule{GroupID: groupID, Status: status, MaxDays: in.MaxDays, Message: message})
+	}
+	return rows, nil
+}
+
+// GetStatusAlertRules returns a group's status alert rules (any group member)
+// Route: GET /api/groups/:id/status-alerts
+func GetStatusAlertRules(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+
+		rules := []models.StatusAlertRule{}
+		if err := db.Where("group_id = ?", groupID).Order("status, max_days, id").Find(&rules).Error; err != nil {
+			middleware.GetLogger(c).Error("Failed to load status alert rules", err)
+			respondInternalError(c, "Failed to load status alert rules")
+			return
+		}
+		respondOK(c, rules)
+	}
+}
+
+// UpdateStatusAlertRules replaces a group's status alert rules (group admin
+// or site admin). An empty list turns the alerts off.
+// Route: PUT /api/groups/:id/status-alerts
+func UpdateStatusAlertRules(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
+			return
+		}
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+
+		var req StatusAlertRulesRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		statuses, err := effectiveAnimalStatuses(db, uint(gid))
+		if err != nil {
+			respondInternalError(c, "Failed to load animal statuses")
+			return
+		}
+		rows, err := buildStatusAlertRules(uint(gid), req, statuses)
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("group_id = ?", gid).Delete(&models.StatusAlertRule{}).Error; err != nil {
+				return err
+			}
+			if len(rows) == 0 {
+				return nil
+			}
+			return tx.Create(&rows).Error
+		}); err != nil {
+			middleware.GetLogger(c).Error("Failed to update status alert rules", err)
+			respondInternalError(c, "Failed to update status alert rules")
+			return
+		}
+
+		uid, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventGroupUpdated, uid, map[string]interface{}{
+			"group_id": gid,
+			"change":   "status_alerts",
+			"rules":    len(rows),
+		})
+		respondOK(c, rows)
+	}
+}
+
+// StartStatusAlertScheduler periodically queues emails to group admins about
+// animals that have passed one of their group's status alert rules. Returns
+// a stop function; call it during graceful shutdown, before closing the
+// database.
+func StartStatusAlertScheduler(db *gorm.DB, interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		for {
+			select {
+			case <-ticker.C:
+				queueStatusAlertEmails(context.Background(), db, time.Now())
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		select {
+		case <-finished:
+		case <-time.After(statusAlertStopTimeout):
+			logging.Warn(fmt.Sprintf("Status alert scheduler did not stop within %s of shutdown signal; proceeding with shutdown anyway", statusAlertStopTimeout))
+		}
+	}
+}
+
+// queueStatusAlertEmails queues an email to each group admin about every
+// animal that has newly passed one of its group's rules, and returns how
+// many alerts it queued for. Each alert is claimed with a StatusAlertNotice
+// row, so when several replicas run the scheduler an animal is only
+// reported once per stay in the status.
+func queueStatusAlertEmails(ctx context.Context, db *gorm.DB, now time.Time) int {
+	logger := logging.WithContext(ctx)
+	db = db.WithContext(ctx)
+
+	var rules []models.StatusAlertRule
+	if err := db.Order("group_id, id").Find(&rules).Error; err != nil {
+		logger.Error("Failed to list status alert rules", err)
+		return 0
+	}
+
+	queued := 0
+	for _, rule := range rules {
+		var animals []models.Animal
+		if err := db.Select("id", "group_id", "status", "last_status_change", "arrival_date", "created_at").
+			Where("group_id = ? AND status = ?", rule.GroupID, rule.Status).Find(&animals).Error; err != nil {
+			logger.WithField("rule_id", rule.ID).Error("Failed to list animals for status alert rule", err)
+			continue
+		}
+		for _, animal := range animals {
+			if !statusAlertDue(rule, animal, now) {
+				continue
+			}
+			since := animalStatusSince(animal)
+			err := db.Transaction(func(tx *gorm.DB) error {
+				claim := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.StatusAlertNotice{
+					AnimalID: animal.ID, Status: rule.Status, MaxDays: rule.MaxDays, StatusSince: since,
+				})
+				if claim.Error != nil || claim.RowsAffected == 0 {
+					return claim.Error // Already reported
+				}
+
+				var adminIDs []uint
+				if err := notifiableUsers(tx.Model(&models.User{})).
+					Joins("JOIN user_groups ON user_groups.user_id = users.id").
+					Where("user_groups.group_id = ? AND user_groups.is_group_admin = ?", rule.GroupID, true).
+					Pluck("users.id", &adminIDs).Error; err != nil {
+					return err
+				}
+				payloads := make([]interface{}, len(adminIDs))
+				for i, id := range adminIDs {
+					payloads[i] = statusAlertEmailJob{
+						AnimalID: animal.ID, UserID: id, Status: rule.Status,
+						MaxDays: rule.MaxDays, StatusSince: since, Message: rule.Message,
+					}
+				}
+				if err := jobs.EnqueueMany(tx, JobStatusAlertEmail, payloads); err != nil {
+					return err
+				}
+				if len(adminIDs) > 0 {
+					queued++
+				}
+				return nil
+			})
+			if err != nil {
+				logger.WithFields(map[string]interface{}{"rule_id": rule.ID, "animal_id": animal.ID}).
+					Error("Failed to queue status alert emails", err)
+			}
+		}
+	}
+	return queued
+}
+
+// statusAlertEmailJobHandler sends a JobStatusAlertEmail. Nothing is sent if
+// the animal has left the status since, or the recipient is gone, no longer
+// one of the group's admins, or has turned email notifications off.
+func statusAlertEmailJobHandler(db *gorm.DB, emailService *email.Service) jobs.Handler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var job statusAlertEmailJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return jobs.Permanent(err)
+		}
+		if emailService == nil || !emailService.IsConfigured() {
+			return errors.New("email service is not configured")
+		}
+		db := db.WithContext(ctx)
+
+		var animal models.Animal
+		var recipient models.User
+		for _, load := range []func() error{
+			func() error { return db.First(&animal, job.AnimalID).Error },
+			func() error { return notifiableUsers(db).First(&recipient, job.UserID).Error },
+			func() error {
+				return db.Where("user_id = ? AND group_id = ? AND is_group_admin = ?", job.UserID, animal.GroupID, true).
+					First(&models.UserGroup{}).Error
+			},
+		} {
+			if err := load(); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil
+				}
+				return err
+			}
+		}
+		if animal.Status != job.Status || !animalStatusSince(animal).Equal(job.StatusSince) {
+			return nil
+		}
+
+		statusLabel := job.Status
+		if def, ok, err := lookupAnimalStatus(db, animal.GroupID, job.Status); err == nil && ok && def.Label != "" {
+			statusLabel = def.Label
+		}
+		days := daysSince(job.StatusSince, time.Now())
+		link := fmt.Sprintf("%s/groups/%d/animals/%d/view", frontendURL(), animal.GroupID, animal.ID)
+		ctx = groupSenderContext(ctx, db, animal.GroupID)
+		return emailService.SendStatusAlertEmail(ctx, recipient.Email, animal.Name, statusLabel, days, job.MaxDays, job.Message, link)
+	}
+}
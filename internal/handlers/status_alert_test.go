@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusAlerts(t *testing.T) {
+	db := SetupTestDB(t)
+	lead := CreateTestUser(t, db, "lead", "lead@example.com", "password123", false)
+	member := CreateTestUser(t, db, "member", "member@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	AddUserToGroupWithAdmin(t, db, lead.ID, group.ID, true)
+	AddUserToGroupWithAdmin(t, db, member.ID, group.ID, false)
+	require.NoError(t, db.Model(&models.User{}).Where("id IN ?", []uint{lead.ID, member.ID}).
+		Update("email_notifications_enabled", true).Error)
+	params := gin.Params{{Key: "id", Value: itoa(group.ID)}}
+
+	setRules := func(userID uint, rules ...gin.H) int {
+		c, w := accountTestContext(userID, false, http.MethodPut, "/", gin.H{"rules": rules})
+		c.Params = params
+		UpdateStatusAlertRules(db)(c)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusForbidden, setRules(member.ID, gin.H{"status": "bite_quarantine", "max_days": 10}))
+	assert.Equal(t, http.StatusBadRequest, setRules(lead.ID, gin.H{"status": "hibernating", "max_days": 10}))
+	assert.Equal(t, http.StatusBadRequest, setRules(lead.ID, gin.H{"status": "bite_quarantine", "max_days": -1}))
+	assert.Equal(t, http.StatusBadRequest, setRules(lead.ID,
+		gin.H{"status": "bite_quarantine", "max_days": 10}, gin.H{"status": "bite_quarantine", "max_days": 10}))
+	require.Equal(t, http.StatusOK, setRules(lead.ID,
+		gin.H{"status": "bite_quarantine", "max_days": 10, "message": "Legal hold is over; schedule the release exam"},
+		gin.H{"status": "foster", "max_days": 60}))
+
+	now := time.Now()
+	inStatus := func(name, status string, days int) *models.Animal {
+		animal := CreateTestAnimal(t, db, group.ID, name, "Dog")
+		since := now.AddDate(0, 0, -days)
+		require.NoError(t, db.Model(animal).Updates(map[string]interface{}{"status": status, "last_status_change": since}).Error)
+		return animal
+	}
+	rocky := inStatus("Rocky", "bite_quarantine", 12)
+	inStatus("Bella", "bite_quarantine", 3)
+	inStatus("Max", "available", 90)
+
+	c, w := accountTestContext(member.ID, false, http.MethodGet, "/?status=all&sort=name", nil)
+	c.Params = params
+	GetAnimals(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var animals []animalWithCounts
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &animals))
+	require.Len(t, animals, 3)
+	alerts := map[string][]StatusAlert{}
+	for _, a := range animals {
+		alerts[a.Name] = a.Alerts
+	}
+	require.Len(t, alerts["Rocky"], 1)
+	assert.Equal(t, 12, alerts["Rocky"][0].Days)
+	assert.Equal(t, 10, alerts["Rocky"][0].MaxDays)
+	assert.Equal(t, "Legal hold is over; schedule the release exam", alerts["Rocky"][0].Message)
+	assert.NotNil(t, alerts["Bella"], "animals without alerts list none")
+	assert.Empty(t, alerts["Bella"])
+	assert.Empty(t, alerts["Max"], "rules only apply to their status")
+
+	// Group admins are emailed once per stay in the status
+	provider := &recordingEmailProvider{}
+	queue := jobs.NewQueue(db)
+	RegisterJobHandlers(queue, db, email.NewServiceWithProvider(provider, db), nil)
+	ctx := context.Background()
+	assert.Equal(t, 1, queueStatusAlertEmails(ctx, db, now))
+	assert.Equal(t, 1, queue.RunDue(ctx))
+	assert.Equal(t, []string{"lead@example.com"}, provider.sentTo)
+	assert.Equal(t, 0, queueStatusAlertEmails(ctx, db, now), "already reported")
+
+	// Rule edits don't repeat the alert, but a new stay in the status does
+	require.Equal(t, http.StatusOK, setRules(lead.ID, gin.H{"status": "bite_quarantine", "max_days": 10, "message": "Call animal control"}))
+	assert.Equal(t, 0, queueStatusAlertEmails(ctx, db, now))
+	require.NoError(t, db.Model(rocky).Update("last_status_change", now.AddDate(0, 0, -11)).Error)
+	assert.Equal(t, 1, queueStatusAlertEmails(ctx, db, now))
+
+	// An animal that moved on before the email went out isn't reported
+	require.NoError(t, db.Model(rocky).Updates(map[string]interface{}{"status": "available", "last_status_change": now}).Error)
+	provider.sentTo = nil
+	assert.Equal(t, 1, queue.RunDue(ctx))
+	assert.Empty(t, provider.sentTo)
+}
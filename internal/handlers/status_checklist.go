@@ -0,0 +1,391 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrCodeChecklistIncomplete is returned when a status change is blocked by
+// checklist items the animal hasn't met
+const ErrCodeChecklistIncomplete ErrorCode = "CHECKLIST_INCOMPLETE"
+
+// maxChecklistItems bounds the checklist items a group can define
+const maxChecklistItems = 100
+
+// defaultChecklistLabels label items that don't set their own
+var defaultChecklistLabels = map[string]string{
+	models.ChecklistPhoto:              "Photo",
+	models.ChecklistBehaviorAssessment: "Behavior assessment",
+	models.ChecklistWeight:             "Weight recorded",
+	models.ChecklistMicrochip:          "Microchipped",
+}
+
+// StatusChecklistItemRequest is one item of a StatusChecklistRequest
+type StatusChecklistItemRequest struct {
+	ToStatus string `json:"to_status" binding:"required"`
+	Kind     string `json:"kind" binding:"required"`
+	Field    string `json:"field"` // Custom field key, required for custom_field items
+	Label    string `json:"label"` // Defaults to the kind, or the custom field's name
+}
+
+// StatusChecklistRequest replaces a group's status checklists
+type StatusChecklistRequest struct {
+	Items []StatusChecklistItemRequest `json:"items"`
+}
+
+// ChecklistItemResult is a checklist item and whether an animal meets it
+type ChecklistItemResult struct {
+	ID       uint   `json:"id"`
+	Kind     string `json:"kind"`
+	Field    string `json:"field,omitempty"`
+	Label    string `json:"label"`
+	Complete bool   `json:"complete"`
+}
+
+// AnimalChecklist is an animal's progress toward one gated status
+type AnimalChecklist struct {
+	Status   string                `json:"status"`
+	Complete bool                  `json:"complete"`
+	Items    []ChecklistItemResult `json:"items"`
+}
+
+type checklistIncompleteResponse struct {
+	Error      string                `json:"error"`
+	Code       ErrorCode             `json:"code,omitempty"`
+	Status     string                `json:"status"`
+	Incomplete []ChecklistItemResult `json:"incomplete"`
+}
+
+// buildStatusChecklist validates a StatusChecklistRequest against the
+// group's statuses and custom fields
+func buildStatusChecklist(groupID uint, req StatusChecklistRequest, statuses []models.AnimalStatus, fields []models.AnimalCustomField) ([]models.StatusChecklistItem, error) {
+	if len(req.Items) > maxChecklistItems {
+		return nil, fmt.Errorf("at most %d checklist items are allowed", maxChecklistItems)
+	}
+	statusKeys := make(map[string]bool, len(statuses))
+	for _, s := range statuses {
+		statusKeys[s.Key] = true
+	}
+	fieldsByKey := make(map[string]models.AnimalCustomField, len(fields))
+	for _, f := range fields {
+		fieldsByKey[f.Key] = f
+	}
+
+	rows := make([]models.StatusChecklistItem, 0, len(req.Items))
+	for i, item := range req.Items {
+		toStatus := strings.TrimSpace(item.ToStatus)
+		if !statusKeys[toStatus] {
+			return nil, fmt.Errorf("item %d: unknown status %q", i+1, toStatus)
+		}
+		label := strings.TrimSpace(item.Label)
+		fieldKey := strings.TrimSpace(item.Field)
+		switch item.Kind {
+		case models.ChecklistCustomField:
+			field, ok := fieldsByKey[fieldKey]
+			if !ok {
+				return nil, fmt.Errorf("item %d: unknown custom field %q", i+1, fieldKey)
+			}
+			if label == "" {
+				label = field.Name
+			}
+		case models.ChecklistPhoto, models.ChecklistBehaviorAssessment, models.ChecklistWeight, models.ChecklistMicrochip:
+			if fieldKey != "" {
+				return nil, fmt.Errorf("item %d: field is only used by custom_field items", i+1)
+			}
+			if label == "" {
+				label = defaultChecklistLabels[item.Kind]
+			}
+		default:
+			return nil, fmt.Errorf("item %d: kind must be one of %s", i+1, strings.Join(models.ChecklistKinds, ", "))
+		}
+		if len(label) > 200 {
+			return nil, fmt.Errorf("item %d: label must be at most 200 characters", i+1)
+		}
+		rows = append(rows, models.StatusChecklistItem{
+			GroupID:    groupID,
+			ToStatus:   toStatus,
+			Kind:       item.Kind,
+			Field:      fieldKey,
+			Label:      label,
+			OrderIndex: i,
+		})
+	}
+	return rows, nil
+}
+
+// statusChecklistItems returns a group's checklist items in order, limited
+// to one status unless status is ""
+func statusChecklistItems(db *gorm.DB, groupID uint, status string) ([]models.StatusChecklistItem, error) {
+	items := []models.StatusChecklistItem{}
+	query := db.Where("group_id = ?", groupID)
+	if status != "" {
+		query = query.Where("to_status = ?", status)
+	}
+	err := query.Order("order_index, id").Find(&items).Error
+	return items, err
+}
+
+// evaluateChecklist reports which of items animal meets. animal holds the
+// values being saved, so an edit can complete an item in the same request
+// that changes the status.
+func evaluateChecklist(db *gorm.DB, animal models.Animal, items []models.StatusChecklistItem) ([]ChecklistItemResult, error) {
+	fields := map[string]models.AnimalCustomField{}
+	if len(items) > 0 {
+		groupFields, err := groupCustomFields(db, animal.GroupID)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range groupFields {
+			fields[f.Key] = f
+		}
+	}
+
+	// Records are counted once per kind, however many items use them
+	counts := map[string]int64{}
+	count := func(kind string, model interface{}) (bool, error) {
+		if n, ok := counts[kind]; ok {
+			return n > 0, nil
+		}
+		var n int64
+		if err := db.Model(model).Where("animal_id = ?", animal.ID).Count(&n).Error; err != nil {
+			return false, err
+		}
+		counts[kind] = n
+		return n > 0, nil
+	}
+
+	results := make([]ChecklistItemResult, 0, len(items))
+	for _, item := range items {
+		var complete bool
+		var err error
+		switch item.Kind {
+		case models.ChecklistPhoto:
+			complete = animal.ImageURL != ""
+			if !complete {
+				complete, err = count(item.Kind, &models.AnimalImage{})
+			}
+		case models.ChecklistBehaviorAssessment:
+			complete, err = count(item.Kind, &models.BehaviorAssessment{})
+		case models.ChecklistWeight:
+			complete, err = count(item.Kind, &models.WeightEntry{})
+		case models.ChecklistMicrochip:
+			complete = animal.MicrochipNumber != ""
+		case models.ChecklistCustomField:
+			value := animal.CustomFields[item.Field]
+			if fields[item.Field].Type == models.CustomFieldBoolean {
+				complete = value == "true"
+			} else {
+				complete = value != ""
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, ChecklistItemResult{ID: item.ID, Kind: item.Kind, Field: item.Field, Label: item.Label, Complete: complete})
+	}
+	return results, nil
+}
+
+// checkStatusChecklist responds with 409 and the unmet items when moving
+// animal into status is gated by items it doesn't meet. override lets the
+// change through anyway and records that it did. Returns false if it
+// responded.
+func checkStatusChecklist(c *gin.Context, db *gorm.DB, animal models.Animal, status string, override bool) bool {
+	items, err := statusChecklistItems(db, animal.GroupID, status)
+	if err == nil && len(items) == 0 {
+		return true
+	}
+	var results []ChecklistItemResult
+	if err == nil {
+		results, err = evaluateChecklist(db, animal, items)
+	}
+	if err != nil {
+		middleware.GetLogger(c).Error("Failed to check status checklist", err)
+		respondInternalError(c, "Failed to check status checklist")
+		return false
+	}
+
+	incomplete := []ChecklistItemResult{}
+	labels := []string{}
+	for _, r := range results {
+		if !r.Complete {
+			incomplete = append(incomplete, r)
+			labels = append(labels, r.Label)
+		}
+	}
+	if len(incomplete) == 0 {
+		return true
+	}
+	if override {
+		uid, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventAnimalUpdated, uid, map[string]interface{}{
+			"group_id":   animal.GroupID,
+			"animal_id":  animal.ID,
+			"change":     "checklist_override",
+			"status":     status,
+			"incomplete": labels,
+		})
+		return true
+	}
+
+	resp := checklistIncompleteResponse{
+		Error:      fmt.Sprintf("%s isn't ready to be %s: %s", animal.Name, status, strings.Join(labels, ", ")),
+		Status:     status,
+		Incomplete: incomplete,
+	}
+	if structuredErrorsRequested(c) {
+		resp.Code = ErrCodeChecklistIncomplete
+	}
+	c.JSON(http.StatusConflict, resp)
+	return false
+}
+
+// GetStatusChecklists returns the items that gate each status in a group
+// Route: GET /api/groups/:id/status-checklists
+func GetStatusChecklists(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+
+		items, err := statusChecklistItems(db, uint(gid), "")
+		if err != nil {
+			respondInternalError(c, "Failed to load status checklists")
+			return
+		}
+		respondOK(c, gin.H{"items": items})
+	}
+}
+
+// UpdateStatusChecklists replaces a group's status checklists (group admin
+// or site admin). Sending no items removes every gate.
+// Route: PUT /api/groups/:id/status-checklists
+func UpdateStatusChecklists(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Admin access required")
+			return
+		}
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+
+		var req StatusChecklistRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		statuses, err := effectiveAnimalStatuses(db, uint(gid))
+		if err != nil {
+			respondInternalError(c, "Failed to load animal statuses")
+			return
+		}
+		fields, err := groupCustomFields(db, uint(gid))
+		if err != nil {
+			respondInternalError(c, "Failed to load custom fields")
+			return
+		}
+		rows, err := buildStatusChecklist(uint(gid), req, statuses, fields)
+		if err != nil {
+			respondBadRequest(c, err.Error())
+			return
+		}
+
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("group_id = ?", gid).Delete(&models.StatusChecklistItem{}).Error; err != nil {
+				return err
+			}
+			if len(rows) == 0 {
+				return nil
+			}
+			return tx.Create(&rows).Error
+		}); err != nil {
+			middleware.GetLogger(c).Error("Failed to update status checklists", err)
+			respondInternalError(c, "Failed to update status checklists")
+			return
+		}
+
+		uid, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventGroupUpdated, uid, map[string]interface{}{
+			"group_id": gid,
+			"change":   "status_checklists",
+			"items":    len(rows),
+		})
+		respondOK(c, gin.H{"items": rows})
+	}
+}
+
+// GetAnimalChecklist returns an animal's progress on the checklist for each
+// gated status, or just ?status=
+// Route: GET /api/groups/:id/animals/:animalId/checklist
+func GetAnimalChecklist(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+
+		var animal models.Animal
+		if err := visibleAnimals(c, db, db.Where("id = ? AND group_id = ?", c.Param("animalId"), groupID), groupID).First(&animal).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeAnimalNotFound, "Animal not found")
+			return
+		}
+
+		items, err := statusChecklistItems(db, animal.GroupID, c.Query("status"))
+		if err != nil {
+			respondInternalError(c, "Failed to load status checklists")
+			return
+		}
+		results, err := evaluateChecklist(db, animal, items)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to evaluate status checklist", err)
+			respondInternalError(c, "Failed to load status checklists")
+			return
+		}
+
+		checklists := []AnimalChecklist{}
+		byStatus := map[string]int{}
+		for i, item := range items {
+			idx, ok := byStatus[item.ToStatus]
+			if !ok {
+				idx = len(checklists)
+				byStatus[item.ToStatus] = idx
+				checklists = append(checklists, AnimalChecklist{Status: item.ToStatus, Complete: true})
+			}
+			checklists[idx].Items = append(checklists[idx].Items, results[i])
+			checklists[idx].Complete = checklists[idx].Complete && results[i].Complete
+		}
+		respondOK(c, checklists)
+	}
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusChecklistGatesTransitions(t *testing.T) {
+	db := setupAnimalTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.BehaviorAssessment{}))
+	user, group := createAnimalTestUser(t, db, "admin", "admin@example.com", true)
+	params := gin.Params{{Key: "id", Value: itoa(group.ID)}}
+	require.NoError(t, db.Create(&models.AnimalCustomField{GroupID: group.ID, Key: "vaccinated", Name: "Vaccinated", Type: models.CustomFieldBoolean}).Error)
+
+	setChecklist := func(items []gin.H) int {
+		c, w := accountTestContext(user.ID, true, http.MethodPut, "/", gin.H{"items": items})
+		c.Params = params
+		UpdateStatusChecklists(db)(c)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusBadRequest, setChecklist([]gin.H{{"to_status": "adoptable", "kind": "photo"}}))
+	assert.Equal(t, http.StatusBadRequest, setChecklist([]gin.H{{"to_status": "available", "kind": "custom_field", "field": "spayed"}}))
+	assert.Equal(t, http.StatusBadRequest, setChecklist([]gin.H{{"to_status": "available", "kind": "vaccines"}}))
+	require.Equal(t, http.StatusOK, setChecklist([]gin.H{
+		{"to_status": "available", "kind": "custom_field", "field": "vaccinated"},
+		{"to_status": "available", "kind": "behavior_assessment"},
+		{"to_status": "available", "kind": "photo", "label": "Adoption photo"},
+	}))
+
+	rex := createTestAnimal(t, db, group.ID, "Rex", "Dog")
+	require.NoError(t, db.Model(rex).Update("status", "under_vet_care").Error)
+
+	update := func(body gin.H) (int, checklistIncompleteResponse) {
+		data, _ := json.Marshal(body)
+		c, w := setupAnimalTestContext(user.ID, true)
+		c.Params = append(params, gin.Param{Key: "animalId", Value: itoa(rex.ID)})
+		c.Request = httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(data))
+		c.Request.Header.Set("Content-Type", "application/json")
+		UpdateAnimal(db, nil, &embedding.StubEmbedder{})(c)
+		var resp checklistIncompleteResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	code, resp := update(gin.H{"name": "Rex", "species": "Dog", "status": "available"})
+	require.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, "available", resp.Status)
+	require.Len(t, resp.Incomplete, 3)
+	assert.Equal(t, "Vaccinated", resp.Incomplete[0].Label, "custom field items default to the field's name")
+	assert.Equal(t, "Adoption photo", resp.Incomplete[2].Label)
+
+	// Other transitions aren't gated
+	code, _ = update(gin.H{"name": "Rex", "species": "Dog", "status": "foster"})
+	require.Equal(t, http.StatusOK, code)
+
+	// Items can be completed in the same edit that changes the status
+	require.NoError(t, db.Create(&models.BehaviorAssessment{AnimalID: rex.ID, Score: 4, AssessedByID: user.ID, AssessedAt: time.Now()}).Error)
+	code, resp = update(gin.H{"name": "Rex", "species": "Dog", "status": "available", "custom_fields": gin.H{"vaccinated": true}})
+	require.Equal(t, http.StatusConflict, code)
+	require.Len(t, resp.Incomplete, 1)
+	assert.Equal(t, models.ChecklistPhoto, resp.Incomplete[0].Kind)
+
+	c, w := setupAnimalTestContext(user.ID, true)
+	c.Params = append(params, gin.Param{Key: "animalId", Value: itoa(rex.ID)})
+	c.Request = httptest.NewRequest(http.MethodGet, "/?status=available", nil)
+	GetAnimalChecklist(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var checklists []AnimalChecklist
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &checklists))
+	require.Len(t, checklists, 1)
+	assert.False(t, checklists[0].Complete)
+	assert.False(t, checklists[0].Items[0].Complete, "vaccinated wasn't saved by the blocked edit")
+
+	// An admin can push the change through anyway
+	code, _ = update(gin.H{"name": "Rex", "species": "Dog", "status": "available", "override_checklist": true})
+	require.Equal(t, http.StatusOK, code)
+	var saved models.Animal
+	require.NoError(t, db.First(&saved, rex.ID).Error)
+	assert.Equal(t, "available", saved.Status)
+
+	// Edits that keep the status aren't checked
+	code, _ = update(gin.H{"name": "Rex", "species": "Dog", "status": "available", "description": "Good boy"})
+	assert.Equal(t, http.StatusOK, code)
+}
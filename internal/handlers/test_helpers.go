@@ -31,6 +31,9 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Fatalf("Failed to create test database: %v", err)
 	}
+	if err := models.SetupJoinTables(db); err != nil {
+		t.Fatalf("Failed to set up join tables: %v", err)
+	}
 
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -41,19 +44,64 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 
 	// Run migrations for all models
 	err = db.AutoMigrate(
+		&models.Organization{},
+		&models.OrganizationSetting{},
 		&models.User{},
 		&models.Group{},
 		&models.UserGroup{},
+		&models.GroupJoinRequest{},
+		&models.WeeklyStatsReport{},
+		&models.StatusAlertRule{},
+		&models.StatusAlertNotice{},
+		&models.GroupFieldVisibility{},
+		&models.GroupRequiredField{},
+		&models.StatusChecklistItem{},
+		&models.OnboardingItem{},
+		&models.OnboardingCompletion{},
+		&models.MemberOnboarding{},
 		&models.Animal{},
 		&models.Update{},
+		&models.Discussion{},
+		&models.DiscussionReply{},
+		&models.DiscussionParticipant{},
 		&models.Announcement{},
+		&models.AnnouncementRead{},
+		&models.AnnouncementEmailEvent{},
+		&models.EmergencyBroadcast{},
+		&models.EmergencyBroadcastDelivery{},
 		&models.CommentTag{},
 		&models.AnimalComment{},
+		&models.CommentReaction{},
+		&models.CommentHistory{},
 		&models.SiteSetting{},
+		&models.EmailTemplate{},
 		&models.Protocol{},
+		&models.ProtocolVersion{},
+		&models.ProtocolAcknowledgment{},
+		&models.ProtocolAttachment{},
 		&models.AnimalTag{},
+		&models.Breed{},
+		&models.ProxiedImage{},
+		&models.UserQualification{},
+		&models.AnimalStatus{},
+		&models.AnimalCustomField{},
 		&models.AnimalNameHistory{},
+		&models.WeightEntry{},
+		&models.AnimalRelationship{},
+		&models.BehaviorAssessment{},
+		&models.SavedFilter{},
+		&models.FosterProfile{},
+		&models.AnimalView{},
 		&models.APIToken{},
+		&models.APIAccessRestriction{},
+		&models.UsernameHistory{},
+		&models.UserMerge{},
+		&models.UserIdentity{},
+		&models.Job{},
+		&models.DataExport{},
+		&models.AnimalChange{},
+		&models.AnimalShareLink{},
+		&models.KennelCardTemplate{},
 	)
 	if err != nil {
 		t.Fatalf("Failed to run migrations: %v", err)
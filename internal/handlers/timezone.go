@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// defaultTimeZone is the site's time zone, used by groups that haven't set
+// their own. Controlled by DEFAULT_TIME_ZONE, an IANA name; unset, it's the
+// server's zone, which schedules used before groups had time zones. Read
+// per call, matching emailVerificationRequired, so tests can use t.Setenv.
+func defaultTimeZone() *time.Location {
+	if name := os.Getenv("DEFAULT_TIME_ZONE"); name != "" {
+		if loc, err := loadTimeZone(name); err == nil {
+			return loc
+		}
+		logging.Warn(fmt.Sprintf("Ignoring invalid DEFAULT_TIME_ZONE %q; using the server's time zone", name))
+	}
+	return time.Local
+}
+
+// loadTimeZone loads an IANA time zone name such as "America/Chicago".
+// "Local" is refused: it means whatever zone the server happens to run in.
+func loadTimeZone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return loc, nil
+}
+
+// validateTimeZone checks a time zone setting: empty (use the default) or
+// an IANA name
+func validateTimeZone(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, err := loadTimeZone(name); err != nil {
+		return fmt.Errorf("time_zone must be an IANA time zone name such as America/Chicago")
+	}
+	return nil
+}
+
+// groupLocation returns the time zone of group's dates and schedules: its
+// own, or the site default
+func groupLocation(group models.Group) *time.Location {
+	if group.TimeZone != "" {
+		if loc, err := loadTimeZone(group.TimeZone); err == nil {
+			return loc
+		}
+	}
+	return defaultTimeZone()
+}
+
+// groupLocationByID loads groupID's time zone. A failed lookup falls back to
+// the site default.
+func groupLocationByID(ctx context.Context, db *gorm.DB, groupID uint) *time.Location {
+	var group models.Group
+	if err := db.WithContext(ctx).Select("id", "time_zone").First(&group, groupID).Error; err != nil {
+		return defaultTimeZone()
+	}
+	return groupLocation(group)
+}
+
+// inLocation reinterprets a date-only value, parsed as midnight UTC, as
+// midnight on the same date in loc. Full timestamps carry their own offset
+// and are left alone.
+func (nt *NullableTime) inLocation(loc *time.Location) {
+	if !nt.Valid || nt.Time == nil || !nt.DateOnly {
+		return
+	}
+	y, m, d := nt.Time.Date()
+	local := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	nt.Time = &local
+}
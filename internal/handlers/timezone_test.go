@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/embedding"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupTimeZone(t *testing.T) {
+	t.Setenv("DEFAULT_TIME_ZONE", "UTC")
+	db := SetupTestDB(t)
+	lead := CreateTestUser(t, db, "lead", "lead@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	AddUserToGroupWithAdmin(t, db, lead.ID, group.ID, true)
+	params := gin.Params{{Key: "id", Value: itoa(group.ID)}}
+
+	setTimeZone := func(name string) int {
+		c, w := accountTestContext(lead.ID, false, http.MethodPut, "/", gin.H{"time_zone": name})
+		c.Params = params
+		UpdateGroupDisplaySettings(db)(c)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusBadRequest, setTimeZone("Mars/Olympus_Mons"))
+	assert.Equal(t, http.StatusBadRequest, setTimeZone("Local"), "the server's zone isn't a setting")
+	require.Equal(t, http.StatusOK, setTimeZone("America/Chicago"))
+	chicago, err := time.LoadLocation("America/Chicago")
+	require.NoError(t, err)
+
+	// Date-only inputs are midnight in the group's zone
+	body, _ := json.Marshal(gin.H{"name": "Rex", "status": "bite_quarantine", "quarantine_start_date": "2026-03-06", "arrival_date": "2026-03-01T09:30:00-05:00"})
+	c, w := setupAnimalTestContext(lead.ID, false)
+	c.Params = params
+	c.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	CreateAnimal(db, nil, &embedding.StubEmbedder{})(c)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var animal models.Animal
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &animal))
+	require.NoError(t, db.First(&animal, animal.ID).Error)
+	assert.True(t, animal.QuarantineStartDate.Equal(time.Date(2026, 3, 6, 0, 0, 0, 0, chicago)), animal.QuarantineStartDate)
+	assert.True(t, animal.QuarantineEndDate.Equal(time.Date(2026, 3, 16, 0, 0, 0, 0, chicago)),
+		"ten days on is a Monday in Chicago, across the switch to daylight time: %v", animal.QuarantineEndDate)
+	assert.True(t, animal.ArrivalDate.Equal(time.Date(2026, 3, 1, 14, 30, 0, 0, time.UTC)), "timestamps keep their offset")
+
+	// Users choose the zone they see times in
+	profile := func(body gin.H) int {
+		c, w := accountTestContext(lead.ID, false, http.MethodPut, "/", body)
+		UpdateCurrentUserProfile(db)(c)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusBadRequest, profile(gin.H{"email": "lead@example.com", "time_zone": "Central"}))
+	require.Equal(t, http.StatusOK, profile(gin.H{"email": "lead@example.com", "time_zone": "Europe/Berlin"}))
+	require.Equal(t, http.StatusOK, profile(gin.H{"email": "lead@example.com"}), "older clients leave it alone")
+	var user models.User
+	require.NoError(t, db.First(&user, lead.ID).Error)
+	assert.Equal(t, "Europe/Berlin", user.TimeZone)
+}
+
+func TestWeeklyStatsFollowGroupTimeZone(t *testing.T) {
+	t.Setenv("DEFAULT_TIME_ZONE", "UTC")
+	db := SetupTestDB(t)
+	lead := CreateTestUser(t, db, "lead", "lead@example.com", "password123", false)
+	auckland := CreateTestGroup(t, db, "Auckland Dogs", "")
+	london := CreateTestGroup(t, db, "London Dogs", "")
+	require.NoError(t, db.Model(auckland).Update("time_zone", "Pacific/Auckland").Error)
+	for _, g := range []*models.Group{auckland, london} {
+		AddUserToGroupWithAdmin(t, db, lead.ID, g.ID, true)
+	}
+	require.NoError(t, db.Model(lead).Updates(map[string]interface{}{
+		"email_notifications_enabled": true, "weekly_stats_emails_enabled": true,
+	}).Error)
+
+	// Last week's reports have gone out
+	require.NoError(t, db.Create(&models.WeeklyStatsReport{GroupID: london.ID, WeekStart: time.Date(2026, 9, 28, 0, 0, 0, 0, time.UTC)}).Error)
+
+	// Sunday evening UTC is already Monday morning in Auckland
+	ctx := context.Background()
+	assert.Equal(t, 1, queueWeeklyStatsEmails(ctx, db, time.Date(2026, 10, 11, 20, 0, 0, 0, time.UTC)))
+	var report models.WeeklyStatsReport
+	require.NoError(t, db.Where("group_id = ?", auckland.ID).First(&report).Error)
+	assert.Equal(t, "2026-10-05", report.WeekStart.UTC().Format("2006-01-02"))
+
+	assert.Equal(t, 1, queueWeeklyStatsEmails(ctx, db, time.Date(2026, 10, 12, 7, 0, 0, 0, time.UTC)), "then London's turn")
+
+	// A group switching zones mid-week doesn't get the week twice
+	require.NoError(t, db.Model(london).Update("time_zone", "America/Los_Angeles").Error)
+	assert.Equal(t, 0, queueWeeklyStatsEmails(ctx, db, time.Date(2026, 10, 12, 20, 0, 0, 0, time.UTC)))
+}
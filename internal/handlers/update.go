@@ -124,12 +124,10 @@ func CreateUpdate(db *gorm.DB, emailService *email.Service, groupMeService *grou
 		}
 
 		if req.SendEmail && emailService != nil && emailService.IsConfigured() {
-			go func() {
-				bgCtx := context.Background()
-				if err := sendGroupAnnouncementEmails(bgCtx, rawDB, emailService, uint(gid), update.Title, update.Content); err != nil {
-					logging.WithContext(bgCtx).Error("Error sending group update emails", err)
-				}
-			}()
+			groupID := uint(gid)
+			if _, err := enqueueAnnouncementEmails(c.Request.Context(), db, []uint{groupID}, update.Title, update.Content); err != nil {
+				middleware.GetLogger(c).Error("Error queueing group update emails", err)
+			}
 		}
 
 		// Send to GroupMe if requested and service is available
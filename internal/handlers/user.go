@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
@@ -53,9 +54,15 @@ func GetAllUsers(db *gorm.DB) gin.HandlerFunc {
 			}
 		}
 
+		// ?email_undeliverable=true lists only users whose address is bouncing
+		filter := func(q *gorm.DB) *gorm.DB { return q }
+		if undeliverable, err := strconv.ParseBool(c.Query("email_undeliverable")); err == nil {
+			filter = func(q *gorm.DB) *gorm.DB { return q.Where("email_undeliverable = ?", undeliverable) }
+		}
+
 		// Get total count
 		var total int64
-		if err := db.Model(&models.User{}).Count(&total).Error; err != nil {
+		if err := db.Model(&models.User{}).Scopes(filter).Count(&total).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count users"})
 			return
 		}
@@ -63,6 +70,7 @@ func GetAllUsers(db *gorm.DB) gin.HandlerFunc {
 		// Get users with pagination
 		var users []models.User
 		if err := db.
+			Scopes(filter).
 			Preload("Groups", activeGroupsPreload).
 			Limit(limit).
 			Offset(offset).
@@ -188,6 +196,9 @@ type UpdateProfileRequest struct {
 	PhoneNumber     string `json:"phone_number" binding:"omitempty,max=20"`
 	HideEmail       bool   `json:"hide_email"`
 	HidePhoneNumber bool   `json:"hide_phone_number"`
+	// Omitted by older clients, which leave the setting unchanged; "" uses
+	// the group's or site's time zone
+	TimeZone *string `json:"time_zone"`
 }
 
 // UpdateCurrentUserProfile allows users to update their own profile information
@@ -226,18 +237,22 @@ func UpdateCurrentUserProfile(db *gorm.DB) gin.HandlerFunc {
 			}
 		}
 
-		// Validate username uniqueness if the user wants to change it
-		if req.Username != "" {
-			newUsername := strings.ToLower(strings.TrimSpace(req.Username))
-			if newUsername != strings.ToLower(user.Username) {
-				if err := validateUsernameUniqueness(ctx, db, newUsername, userIDUint); err != nil {
-					if errors.Is(err, ErrUsernameInUse) {
-						c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-					} else {
-						c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate username"})
-					}
-					return
-				}
+		// A username change here follows the same rules as PUT /api/me/username
+		newUsername := strings.ToLower(strings.TrimSpace(req.Username))
+		usernameChanged := newUsername != "" && newUsername != strings.ToLower(user.Username)
+		now := time.Now()
+		if usernameChanged {
+			if err := validateSelfUsernameChange(ctx, db, &user, newUsername, now); err != nil {
+				respondUsernameChangeError(c, err)
+				return
+			}
+		}
+
+		if req.TimeZone != nil {
+			*req.TimeZone = strings.TrimSpace(*req.TimeZone)
+			if err := validateTimeZone(*req.TimeZone); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
 			}
 		}
 
@@ -250,10 +265,33 @@ func UpdateCurrentUserProfile(db *gorm.DB) gin.HandlerFunc {
 			"hide_email":        req.HideEmail,
 			"hide_phone_number": req.HidePhoneNumber,
 		}
-		if req.Username != "" {
-			updates["username"] = strings.ToLower(strings.TrimSpace(req.Username))
+		if usernameChanged {
+			updates["username"] = newUsername
+			updates["username_changed_at"] = now
 		}
-		if err := db.Model(&user).Updates(updates).Error; err != nil {
+		if req.TimeZone != nil {
+			updates["time_zone"] = *req.TimeZone
+		}
+		emailChanged := req.Email != user.Email
+		if emailChanged {
+			for k, v := range clearedEmailVerification() {
+				updates[k] = v
+			}
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if usernameChanged {
+				if err := recordUsernameChange(tx, user.ID, user.Username, newUsername, user.ID); err != nil {
+					return err
+				}
+			}
+			if err := tx.Model(&user).Updates(updates).Error; err != nil {
+				return err
+			}
+			if emailChanged {
+				return enqueueEmailVerification(tx, user.ID, req.Email)
+			}
+			return nil
+		}); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
 			return
 		}
@@ -273,6 +311,7 @@ func UpdateCurrentUserProfile(db *gorm.DB) gin.HandlerFunc {
 			"phone_number":      user.PhoneNumber,
 			"hide_email":        user.HideEmail,
 			"hide_phone_number": user.HidePhoneNumber,
+			"time_zone":         user.TimeZone,
 		})
 	}
 }
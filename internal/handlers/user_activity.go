@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultUserActivityPageSize = 50
+	maxUserActivityPageSize     = 500
+)
+
+// UserActivity is one user's row in the activity summary. Comment counts
+// and dates cover comments that haven't been deleted; in the group variant,
+// only comments on that group's animals.
+type UserActivity struct {
+	UserID        uint       `json:"user_id"`
+	Username      string     `json:"username"`
+	FirstName     string     `json:"first_name"`
+	LastName      string     `json:"last_name"`
+	Email         string     `json:"email"`
+	CreatedAt     *time.Time `json:"created_at"`
+	LastLogin     *time.Time `json:"last_login"`
+	LastCommentAt *time.Time `json:"last_comment_at"`
+	CommentCount  int64      `json:"comment_count"`
+	// InactiveDays is whole days since the later of the last login and last
+	// comment; nil if the user has done neither
+	InactiveDays *int `json:"inactive_days"`
+}
+
+// userActivitySortKeys is the whitelist of ?sort= values for the activity
+// summary. Only these expressions ever reach ORDER BY.
+var userActivitySortKeys = map[string]animalSortKey{
+	"username": {order: func(dir string) []string {
+		return []string{"LOWER(u.username) " + dir}
+	}},
+	"created_at": {defaultDesc: true, order: func(dir string) []string {
+		return []string{"u.created_at " + dir}
+	}},
+	"last_login": {defaultDesc: true, order: func(dir string) []string {
+		return nullsLast("u.last_login", dir)
+	}},
+	"last_comment_at": {defaultDesc: true, order: func(dir string) []string {
+		return nullsLast("cs.last_comment_at", dir)
+	}},
+	"comment_count": {defaultDesc: true, order: func(dir string) []string {
+		return []string{"COALESCE(cs.comment_count, 0) " + dir}
+	}},
+}
+
+// userActivityParams is the parsed query string of an activity request
+type userActivityParams struct {
+	order         []string
+	limit, offset int
+	inactiveSince *time.Time
+}
+
+// parseUserActivityParams reads ?sort=, ?order=, ?limit=, ?offset=, and
+// ?inactive_days=
+func parseUserActivityParams(c *gin.Context, now time.Time) (userActivityParams, error) {
+	params := userActivityParams{limit: defaultUserActivityPageSize}
+
+	name := c.DefaultQuery("sort", "username")
+	key, ok := userActivitySortKeys[name]
+	if !ok {
+		names := make([]string, 0, len(userActivitySortKeys))
+		for n := range userActivitySortKeys {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return params, fmt.Errorf("sort must be one of: %s", strings.Join(names, ", "))
+	}
+	order := strings.ToLower(c.Query("order"))
+	if order != "" && order != "asc" && order != "desc" {
+		return params, fmt.Errorf("order must be asc or desc")
+	}
+	dir := "ASC"
+	if order == "desc" || (order == "" && key.defaultDesc) {
+		dir = "DESC"
+	}
+	params.order = append(key.order(dir), "u.id ASC")
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxUserActivityPageSize {
+			return params, fmt.Errorf("limit must be between 1 and %d", maxUserActivityPageSize)
+		}
+		params.limit = limit
+	}
+	if v := c.Query("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return params, fmt.Errorf("offset must be a non-negative number")
+		}
+		params.offset = offset
+	}
+	if v := c.Query("inactive_days"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days < 1 {
+			return params, fmt.Errorf("inactive_days must be a positive number")
+		}
+		since := now.AddDate(0, 0, -days)
+		params.inactiveSince = &since
+	}
+	return params, nil
+}
+
+// userActivityScope selects active users joined to their comment totals.
+// With groupID set, only that group's members and comments on its animals
+// count.
+func userActivityScope(groupID uint, inactiveSince *time.Time) func(*gorm.DB) *gorm.DB {
+	return func(q *gorm.DB) *gorm.DB {
+		comments := "SELECT ac.user_id, COUNT(*) AS comment_count, MAX(ac.created_at) AS last_comment_at " +
+			"FROM animal_comments ac WHERE ac.deleted_at IS NULL"
+		var args []interface{}
+		if groupID != 0 {
+			comments += " AND ac.animal_id IN (SELECT id FROM animals WHERE group_id = ?)"
+			args = append(args, groupID)
+		}
+		comments += " GROUP BY ac.user_id"
+
+		q = q.Table("users u").
+			Joins("LEFT JOIN ("+comments+") cs ON cs.user_id = u.id", args...).
+			Where("u.deleted_at IS NULL")
+		if groupID != 0 {
+			q = q.Where("u.id IN (SELECT user_id FROM user_groups WHERE group_id = ?)", groupID)
+		}
+		if inactiveSince != nil {
+			q = q.Where("(u.last_login IS NULL OR u.last_login < ?) AND (cs.last_comment_at IS NULL OR cs.last_comment_at < ?)",
+				*inactiveSince, *inactiveSince)
+		}
+		return q
+	}
+}
+
+// respondUserActivity lists users' login and comment activity, scoped to
+// groupID's members when it isn't 0
+func respondUserActivity(c *gin.Context, db *gorm.DB, groupID uint) {
+	now := time.Now()
+	params, err := parseUserActivityParams(c, now)
+	if err != nil {
+		respondBadRequest(c, err.Error())
+		return
+	}
+	scope := userActivityScope(groupID, params.inactiveSince)
+
+	var total int64
+	if err := db.Scopes(scope).Count(&total).Error; err != nil {
+		middleware.GetLogger(c).Error("Failed to count user activity", err)
+		respondInternalError(c, "Failed to fetch user activity")
+		return
+	}
+
+	// last_comment_at is an aggregate, which SQLite returns as text, so it's
+	// scanned as a string like the statistics endpoints do
+	var rows []struct {
+		UserID        uint
+		Username      string
+		FirstName     string
+		LastName      string
+		Email         string
+		CreatedAt     time.Time
+		LastLogin     *time.Time
+		LastCommentAt *string
+		CommentCount  int64
+	}
+	query := db.Scopes(scope).Select("u.id AS user_id, u.username, u.first_name, u.last_name, u.email, u.created_at, u.last_login, " +
+		"cs.last_comment_at, COALESCE(cs.comment_count, 0) AS comment_count")
+	for _, expr := range params.order {
+		query = query.Order(expr)
+	}
+	if err := query.Limit(params.limit).Offset(params.offset).Scan(&rows).Error; err != nil {
+		middleware.GetLogger(c).Error("Failed to fetch user activity", err)
+		respondInternalError(c, "Failed to fetch user activity")
+		return
+	}
+
+	activity := make([]UserActivity, len(rows))
+	for i, row := range rows {
+		createdAt := row.CreatedAt
+		item := UserActivity{
+			UserID:       row.UserID,
+			Username:     row.Username,
+			FirstName:    row.FirstName,
+			LastName:     row.LastName,
+			Email:        row.Email,
+			CreatedAt:    &createdAt,
+			LastLogin:    row.LastLogin,
+			CommentCount: row.CommentCount,
+		}
+		if row.LastCommentAt != nil {
+			item.LastCommentAt = parseTimestamp(*row.LastCommentAt)
+		}
+		last := item.LastLogin
+		if item.LastCommentAt != nil && (last == nil || item.LastCommentAt.After(*last)) {
+			last = item.LastCommentAt
+		}
+		if last != nil {
+			days := int(now.Sub(*last).Hours() / 24)
+			item.InactiveDays = &days
+		}
+		activity[i] = item
+	}
+
+	respondOK(c, gin.H{
+		"data":    activity,
+		"total":   total,
+		"limit":   params.limit,
+		"offset":  params.offset,
+		"hasMore": params.offset+len(activity) < int(total),
+	})
+}
+
+// GetUserActivity lists every user's last login, last comment, and comment
+// count so admins can spot inactive volunteers (site admin only). Sort with
+// ?sort= (username, created_at, last_login, last_comment_at, comment_count)
+// and ?order=; ?inactive_days=N keeps users with neither a login nor a
+// comment in the last N days.
+// Route: GET /api/admin/users/activity
+func GetUserActivity(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		respondUserActivity(c, db, 0)
+	}
+}
+
+// GetGroupMemberActivity is GetUserActivity for one group's members,
+// counting only comments on the group's animals (group admin or site admin).
+// Route: GET /api/groups/:id/members/activity
+func GetGroupMemberActivity(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, _ := middleware.GetUserID(c)
+		isAdmin, _ := c.Get("is_admin")
+		groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+		if !checkGroupAdminAccess(db, userID, isAdmin, c.Param("id")) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Only group admins can view member activity")
+			return
+		}
+		respondUserActivity(c, db, uint(groupID))
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUserActivity(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	active := CreateTestUser(t, db, "active", "active@example.com", "password123", false)
+	dormant := CreateTestUser(t, db, "dormant", "dormant@example.com", "password123", false)
+	CreateTestUser(t, db, "never", "never@example.com", "password123", false)
+	dogs := CreateTestGroup(t, db, "Dogs", "Dog group")
+	cats := CreateTestGroup(t, db, "Cats", "Cat group")
+	lead := CreateTestUser(t, db, "lead", "lead@example.com", "password123", false)
+	AddUserToGroupWithAdmin(t, db, lead.ID, dogs.ID, true)
+	AddUserToGroupWithAdmin(t, db, active.ID, dogs.ID, false)
+	AddUserToGroupWithAdmin(t, db, dormant.ID, dogs.ID, false)
+	AddUserToGroupWithAdmin(t, db, dormant.ID, cats.ID, false)
+	buddy := CreateTestAnimal(t, db, dogs.ID, "Buddy", "Dog")
+	whiskers := CreateTestAnimal(t, db, cats.ID, "Whiskers", "Cat")
+
+	now := time.Now().UTC()
+	longAgo := now.AddDate(0, 0, -60)
+	recently := now.AddDate(0, 0, -2)
+	require.NoError(t, db.Model(&models.User{}).Where("id = ?", active.ID).Update("last_login", recently).Error)
+	require.NoError(t, db.Model(&models.User{}).Where("id = ?", dormant.ID).Update("last_login", longAgo).Error)
+	for _, comment := range []models.AnimalComment{
+		{AnimalID: buddy.ID, UserID: active.ID, Content: "walk", CreatedAt: recently},
+		{AnimalID: buddy.ID, UserID: active.ID, Content: "walk again", CreatedAt: recently},
+		{AnimalID: buddy.ID, UserID: dormant.ID, Content: "old walk", CreatedAt: longAgo},
+		{AnimalID: whiskers.ID, UserID: dormant.ID, Content: "old brush", CreatedAt: longAgo},
+	} {
+		require.NoError(t, db.Create(&comment).Error)
+	}
+
+	type activityResponse struct {
+		Data  []UserActivity `json:"data"`
+		Total int64          `json:"total"`
+	}
+	get := func(handler gin.HandlerFunc, userID uint, isAdmin bool, groupID uint, query string) (int, activityResponse) {
+		c, w := accountTestContext(userID, isAdmin, http.MethodGet, "/api/admin/users/activity"+query, nil)
+		c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(groupID)}}
+		handler(c)
+		var resp activityResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+	names := func(rows []UserActivity) []string {
+		out := make([]string, len(rows))
+		for i, row := range rows {
+			out[i] = row.Username
+		}
+		return out
+	}
+
+	code, resp := get(GetUserActivity(db), admin.ID, true, 0, "?sort=comment_count")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"active", "dormant", "admin", "never", "lead"}, names(resp.Data))
+	assert.Equal(t, int64(5), resp.Total)
+	assert.Equal(t, int64(2), resp.Data[0].CommentCount)
+	require.NotNil(t, resp.Data[0].LastCommentAt)
+	require.NotNil(t, resp.Data[0].InactiveDays)
+	assert.Equal(t, 2, *resp.Data[0].InactiveDays)
+	assert.Nil(t, resp.Data[3].LastLogin)
+	assert.Nil(t, resp.Data[3].InactiveDays)
+
+	// Users with neither a login nor a comment in the window
+	code, resp = get(GetUserActivity(db), admin.ID, true, 0, "?inactive_days=30&sort=username")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"admin", "dormant", "lead", "never"}, names(resp.Data))
+	assert.Equal(t, int64(4), resp.Total)
+
+	code, resp = get(GetUserActivity(db), admin.ID, true, 0, "?sort=last_comment_at&order=asc&limit=2")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"dormant", "active"}, names(resp.Data))
+
+	code, _ = get(GetUserActivity(db), admin.ID, true, 0, "?sort=password")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = get(GetUserActivity(db), admin.ID, true, 0, "?inactive_days=0")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// The group variant counts only members and comments on the group's animals
+	code, resp = get(GetGroupMemberActivity(db), lead.ID, false, dogs.ID, "?sort=username")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"active", "dormant", "lead"}, names(resp.Data))
+	assert.Equal(t, int64(1), resp.Data[1].CommentCount)
+
+	code, _ = get(GetGroupMemberActivity(db), lead.ID, false, cats.ID, "")
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = get(GetGroupMemberActivity(db), active.ID, false, dogs.ID, "")
+	assert.Equal(t, http.StatusForbidden, code)
+}
@@ -29,6 +29,13 @@ type adminUserResponse struct {
 	// only in admin-scoped responses.
 	LockedUntil         *time.Time `json:"locked_until"`
 	FailedLoginAttempts int        `json:"failed_login_attempts"`
+	LockoutCount        int        `json:"lockout_count"`
+	// Set when the email provider reported the address as bouncing or
+	// complaining; emails to it are suppressed
+	EmailUndeliverable       bool       `json:"email_undeliverable"`
+	EmailUndeliverableReason string     `json:"email_undeliverable_reason,omitempty"`
+	EmailUndeliverableDetail string     `json:"email_undeliverable_detail,omitempty"`
+	EmailUndeliverableAt     *time.Time `json:"email_undeliverable_at,omitempty"`
 }
 
 // toAdminUserResponse copies admin-only fields into the outer struct to
@@ -41,6 +48,12 @@ func toAdminUserResponse(u models.User) adminUserResponse {
 		LastLogin:             u.LastLogin,
 		LockedUntil:           u.LockedUntil,
 		FailedLoginAttempts:   u.FailedLoginAttempts,
+		LockoutCount:          u.LockoutCount,
+
+		EmailUndeliverable:       u.EmailUndeliverable,
+		EmailUndeliverableReason: u.EmailUndeliverableReason,
+		EmailUndeliverableDetail: u.EmailUndeliverableDetail,
+		EmailUndeliverableAt:     u.EmailUndeliverableAt,
 	}
 }
 
@@ -105,6 +118,24 @@ func GetDeletedUsers(db *gorm.DB) gin.HandlerFunc {
 	}
 }
 
+// GetLockedUsers lists accounts that are currently locked out after failed
+// logins, soonest to unlock first (admin only)
+func GetLockedUsers(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		var users []models.User
+		if err := db.Preload("Groups", activeGroupsPreload).Where("locked_until > ?", time.Now()).Order("locked_until ASC").Find(&users).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch locked users"})
+			return
+		}
+		adminUsers := make([]adminUserResponse, len(users))
+		for i, u := range users {
+			adminUsers[i] = toAdminUserResponse(u)
+		}
+		c.JSON(http.StatusOK, adminUsers)
+	}
+}
+
 // RestoreUser restores a soft-deleted user (admin only)
 func RestoreUser(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -115,8 +146,12 @@ func RestoreUser(db *gorm.DB) gin.HandlerFunc {
 			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 			return
 		}
+		if user.AnonymizedAt != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "User's personal data has been erased; the account can't be restored"})
+			return
+		}
 		if user.DeletedAt.Valid {
-			if err := db.Unscoped().Model(&user).Update("deleted_at", nil).Error; err != nil {
+			if err := db.Unscoped().Model(&user).Updates(map[string]interface{}{"deleted_at": nil, "deactivation_requested_at": nil}).Error; err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore user"})
 				return
 			}
@@ -450,6 +485,16 @@ func GroupAdminCreateUser(db *gorm.DB, emailService *email.Service) gin.HandlerF
 			}
 		}
 
+		// The new user joins the organization that owns their groups
+		organizationID, err := organizationOfGroups(db, req.GroupIDs)
+		if errors.Is(err, errMixedOrganizations) {
+			respondError(c, http.StatusConflict, ErrCodeOrganizationMismatch, "A user's groups must all be in the same organization")
+			return
+		} else if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch groups"})
+			return
+		}
+
 		// Normalize username to lowercase
 		req.Username = strings.ToLower(strings.TrimSpace(req.Username))
 
@@ -529,6 +574,7 @@ func GroupAdminCreateUser(db *gorm.DB, emailService *email.Service) gin.HandlerF
 				return
 			}
 			user.Groups = groups
+			user.OrganizationID = organizationID
 
 			if err := db.Create(&user).Error; err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
@@ -583,6 +629,7 @@ func GroupAdminCreateUser(db *gorm.DB, emailService *email.Service) gin.HandlerF
 			return
 		}
 		user.Groups = groups
+		user.OrganizationID = organizationID
 
 		if err := db.Create(&user).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
@@ -702,6 +749,7 @@ func AdminResetUserPassword(db *gorm.DB) gin.HandlerFunc {
 			"reset_token_expiry":    nil,
 			"failed_login_attempts": 0,
 			"locked_until":          nil,
+			"lockout_count":         0,
 		}).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
 			return
@@ -758,6 +806,12 @@ func applyUserUpdate(ctx context.Context, db *gorm.DB, c *gin.Context, user *mod
 		"phone_number": strings.TrimSpace(req.PhoneNumber),
 		"email":        req.Email,
 	}
+	emailChanged := req.Email != user.Email
+	if emailChanged {
+		for k, v := range clearedEmailVerification() {
+			updates[k] = v
+		}
+	}
 
 	if req.Username != "" {
 		newUsername := strings.ToLower(strings.TrimSpace(req.Username))
@@ -774,7 +828,23 @@ func applyUserUpdate(ctx context.Context, db *gorm.DB, c *gin.Context, user *mod
 		updates["username"] = newUsername
 	}
 
-	if err := db.WithContext(ctx).Model(user).Updates(updates).Error; err != nil {
+	// Admin renames skip the self-service cooldown but are still recorded,
+	// so the old username stays reserved like any other given-up handle
+	changedBy, _ := middleware.GetUserID(c)
+	if err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if newUsername, ok := updates["username"].(string); ok && newUsername != strings.ToLower(user.Username) {
+			if err := recordUsernameChange(tx, user.ID, user.Username, newUsername, changedBy); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(user).Updates(updates).Error; err != nil {
+			return err
+		}
+		if emailChanged {
+			return enqueueEmailVerification(tx, user.ID, req.Email)
+		}
+		return nil
+	}); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
 		return
 	}
@@ -1141,12 +1211,14 @@ func UnlockUserAccount(db *gorm.DB) gin.HandlerFunc {
 		if err := db.Model(&user).Updates(map[string]interface{}{
 			"locked_until":          nil,
 			"failed_login_attempts": 0,
+			"lockout_count":         0,
 		}).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock account"})
 			return
 		}
 		user.LockedUntil = nil
 		user.FailedLoginAttempts = 0
+		user.LockoutCount = 0
 
 		// Audit log
 		logging.LogAccountUnlocked(ctx, user.ID, user.Username, currentUserID, c.ClientIP())
@@ -43,9 +43,12 @@ func setupUserAdminTestDB(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Fatalf("Failed to create test database: %v", err)
 	}
+	if err := models.SetupJoinTables(db); err != nil {
+		t.Fatalf("Failed to set up join tables: %v", err)
+	}
 
 	// Run migrations
-	err = db.AutoMigrate(&models.User{}, &models.Group{}, &models.UserGroup{})
+	err = db.AutoMigrate(&models.User{}, &models.Group{}, &models.UserGroup{}, &models.Job{})
 	if err != nil {
 		t.Fatalf("Failed to run migrations: %v", err)
 	}
@@ -734,6 +737,40 @@ func TestGetDeletedUsers(t *testing.T) {
 	}
 }
 
+// TestGetLockedUsers tests listing currently locked accounts
+func TestGetLockedUsers(t *testing.T) {
+	db := setupUserAdminTestDB(t)
+	admin := createUserAdminTestUser(t, db, "admin", "admin@test.com", true)
+	locked := createUserAdminTestUser(t, db, "locked", "locked@example.com", false)
+	expired := createUserAdminTestUser(t, db, "expired", "expired@example.com", false)
+	createUserAdminTestUser(t, db, "active", "active@example.com", false)
+
+	db.Model(&locked).Updates(map[string]interface{}{"locked_until": time.Now().Add(time.Hour), "failed_login_attempts": 5, "lockout_count": 2})
+	db.Model(&expired).Update("locked_until", time.Now().Add(-time.Hour))
+
+	c, w := setupUserAdminTestContext(admin.ID, true)
+	c.Request = httptest.NewRequest("GET", "/api/v1/admin/users/locked", nil)
+
+	handler := GetLockedUsers(db)
+	handler(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var users []adminUserResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &users); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if len(users) != 1 || users[0].Username != "locked" {
+		t.Fatalf("Expected only the locked user, got %+v", users)
+	}
+	if users[0].LockedUntil == nil || users[0].FailedLoginAttempts != 5 || users[0].LockoutCount != 2 {
+		t.Errorf("Expected lockout details in response, got %+v", users[0])
+	}
+}
+
 // TestRestoreUser tests restoring soft-deleted users
 func TestRestoreUser(t *testing.T) {
 	tests := []struct {
@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/upload"
+	"gorm.io/gorm"
+)
+
+// Avatar constraints. Avatars are cropped to a centered square and stored
+// twice: avatarSize for profile pages and avatarThumbnailSize for member
+// lists and comments.
+const (
+	avatarMaxBytes      = 5 * 1024 * 1024
+	avatarMinDimension  = 32
+	avatarSize          = 256
+	avatarThumbnailSize = 64
+)
+
+// AvatarResponse is a user's avatar URLs. Both are empty when the user has
+// no avatar, in which case clients show the user's initials.
+type AvatarResponse struct {
+	AvatarURL          string `json:"avatar_url"`
+	AvatarThumbnailURL string `json:"avatar_thumbnail_url"`
+}
+
+// replaceUserAvatar points a user's avatar at the given URLs (empty to
+// remove it) and deletes the images of the avatar it replaces.
+func replaceUserAvatar(ctx context.Context, db *gorm.DB, storageProvider storage.Provider, userID uint, avatarURL, thumbnailURL string) error {
+	var user models.User
+	if err := db.WithContext(ctx).Select("id", "avatar_url", "avatar_thumbnail_url").First(&user, userID).Error; err != nil {
+		return err
+	}
+	// Updates writes the new values back into user, so note the old ones first
+	oldURLs := []string{user.AvatarURL, user.AvatarThumbnailURL}
+	if err := db.WithContext(ctx).Model(&user).Updates(map[string]interface{}{
+		"avatar_url":           avatarURL,
+		"avatar_thumbnail_url": thumbnailURL,
+	}).Error; err != nil {
+		return err
+	}
+
+	var old []models.AnimalImage
+	for _, url := range oldURLs {
+		if url == "" {
+			continue
+		}
+		var images []models.AnimalImage
+		if err := db.WithContext(ctx).Where("animal_id IS NULL AND user_id = ? AND image_url = ?", userID, url).Find(&images).Error; err != nil {
+			return err
+		}
+		old = append(old, images...)
+	}
+	logger := logging.WithContext(ctx)
+	for _, img := range old {
+		if img.StorageProvider == "azure" && img.BlobIdentifier != "" && storageProvider != nil {
+			if err := storageProvider.DeleteImage(ctx, img.BlobIdentifier); err != nil {
+				logger.WithField("blob_identifier", img.BlobIdentifier).Warnf("Failed to delete old avatar from storage: %v", err)
+			}
+		}
+		if err := db.WithContext(ctx).Delete(&img).Error; err != nil {
+			logger.WithField("image_id", img.ID).Warnf("Failed to delete old avatar image record: %v", err)
+		}
+	}
+	return nil
+}
+
+// UploadCurrentUserAvatar replaces the current user's avatar. The upload must
+// be a JPEG, PNG, or GIF image of at most 5 MB and at least 32 pixels on its
+// shortest side. It is cropped to a centered square and stored at 256 and
+// 64 pixels.
+// Route: POST /api/me/avatar
+func UploadCurrentUserAvatar(db *gorm.DB, storageProvider storage.Provider, imageConfig *upload.ImageConfigStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		db := middleware.GetDB(c, db)
+		logger := middleware.GetLogger(c)
+		userID, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+
+		file, err := c.FormFile("image")
+		if err != nil {
+			respondBadRequest(c, "No file uploaded")
+			return
+		}
+		cfg := imageConfig.Get(ctx)
+		if err := upload.ValidateImageUpload(file, min(cfg.MaxUploadBytes, avatarMaxBytes)); err != nil {
+			respondBadRequest(c, "Invalid file: "+err.Error())
+			return
+		}
+
+		src, err := file.Open()
+		if err != nil {
+			logger.Error("Failed to open file", err)
+			respondInternalError(c, "Failed to read image")
+			return
+		}
+		defer src.Close()
+		data, err := io.ReadAll(src)
+		if err != nil {
+			logger.Error("Failed to read file bytes", err)
+			respondInternalError(c, "Failed to read image")
+			return
+		}
+
+		// Like logos, an avatar the server can't decode (e.g. HEIC or WebP)
+		// can't be cropped, so it isn't stored as uploaded
+		imgCfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			respondBadRequest(c, "Avatar must be a JPEG, PNG, or GIF image")
+			return
+		}
+		if min(imgCfg.Width, imgCfg.Height) < avatarMinDimension {
+			respondBadRequest(c, "Avatar must be at least 32x32 pixels")
+			return
+		}
+
+		cfg.PreserveTransparency = true
+		sizes, err := upload.ProcessSquareImage(bytes.NewReader(data), []int{avatarSize, avatarThumbnailSize}, cfg)
+		if err != nil {
+			if errors.Is(err, upload.ErrInvalidFile) {
+				respondBadRequest(c, "Avatar must be a JPEG, PNG, or GIF image")
+				return
+			}
+			logger.Error("Failed to process avatar", err)
+			respondInternalError(c, "Failed to process image")
+			return
+		}
+
+		var urls []string
+		for _, img := range sizes {
+			url, err := storeUnlinkedImage(ctx, db, storageProvider, img.Data, img.MimeType, userID)
+			if err != nil {
+				logger.Error("Failed to store avatar", err)
+				respondInternalError(c, "Failed to upload image")
+				return
+			}
+			urls = append(urls, url)
+		}
+		if err := replaceUserAvatar(ctx, db, storageProvider, userID, urls[0], urls[1]); err != nil {
+			logger.Error("Failed to save avatar", err)
+			respondInternalError(c, "Failed to save avatar")
+			return
+		}
+
+		respondOK(c, AvatarResponse{AvatarURL: urls[0], AvatarThumbnailURL: urls[1]})
+	}
+}
+
+// DeleteCurrentUserAvatar removes the current user's avatar, so their
+// initials are shown instead.
+// Route: DELETE /api/me/avatar
+func DeleteCurrentUserAvatar(db *gorm.DB, storageProvider storage.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+		if err := replaceUserAvatar(c.Request.Context(), db, storageProvider, userID, "", ""); err != nil {
+			middleware.GetLogger(c).Error("Failed to remove avatar", err)
+			respondInternalError(c, "Failed to remove avatar")
+			return
+		}
+		respondOK(c, AvatarResponse{})
+	}
+}
+
+// AdminResetUserAvatar removes another user's avatar, e.g. an inappropriate
+// photo (site admin only).
+// Route: DELETE /api/admin/users/:userId/avatar
+func AdminResetUserAvatar(db *gorm.DB, storageProvider storage.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		targetID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid user ID")
+			return
+		}
+		if err := replaceUserAvatar(c.Request.Context(), db, storageProvider, uint(targetID), "", ""); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				respondNotFound(c, "User not found")
+				return
+			}
+			middleware.GetLogger(c).Error("Failed to remove avatar", err)
+			respondInternalError(c, "Failed to remove avatar")
+			return
+		}
+
+		adminID, _ := middleware.GetUserID(c)
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventUserAvatarRemoved, adminID, map[string]interface{}{
+			"target_user_id": uint(targetID),
+		})
+		respondOK(c, AvatarResponse{})
+	}
+}
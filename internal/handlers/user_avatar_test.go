@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserAvatar(t *testing.T) {
+	db := SetupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AnimalImage{}))
+	user := CreateTestUser(t, db, "volunteer", "volunteer@example.com", "password123", false)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	group := CreateTestGroup(t, db, "Dogs", "Dog group")
+	AddUserToGroupWithAdmin(t, db, user.ID, group.ID, false)
+	provider := &mockStorageProvider{}
+
+	pngOfSize := func(width, height int) []byte {
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+		return buf.Bytes()
+	}
+	uploadAvatar := func(content []byte) (int, AvatarResponse) {
+		c, w := accountTestContext(user.ID, false, http.MethodPost, "/api/me/avatar", nil)
+		c.Request = createImageMultipartRequest(t, "image", "me.png", content)
+		UploadCurrentUserAvatar(db, provider, nil)(c)
+		var resp AvatarResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+	avatarImages := func() int64 {
+		var count int64
+		require.NoError(t, db.Model(&models.AnimalImage{}).Where("animal_id IS NULL AND user_id = ?", user.ID).Count(&count).Error)
+		return count
+	}
+
+	code, _ := uploadAvatar(pngOfSize(20, 400))
+	assert.Equal(t, http.StatusBadRequest, code, "avatars must be at least 32 pixels on the shortest side")
+	code, _ = uploadAvatar([]byte("not an image"))
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, first := uploadAvatar(pngOfSize(600, 300))
+	require.Equal(t, http.StatusOK, code)
+	assert.NotEmpty(t, first.AvatarURL)
+	assert.NotEqual(t, first.AvatarURL, first.AvatarThumbnailURL)
+	assert.Equal(t, int64(2), avatarImages())
+
+	// Replacing the avatar removes the old images
+	code, second := uploadAvatar(pngOfSize(100, 100))
+	require.Equal(t, http.StatusOK, code)
+	assert.NotEqual(t, first.AvatarURL, second.AvatarURL)
+	assert.Equal(t, int64(2), avatarImages())
+
+	// The avatar is exposed in /me and group member lists
+	c, w := accountTestContext(user.ID, false, http.MethodGet, "/api/me", nil)
+	GetCurrentUser(db)(c)
+	var me map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &me))
+	assert.Equal(t, second.AvatarURL, me["avatar_url"])
+	c, w = accountTestContext(user.ID, false, http.MethodGet, "/api/groups/1/members", nil)
+	c.Params = gin.Params{{Key: "id", Value: fmt.Sprint(group.ID)}}
+	GetGroupMembers(db)(c)
+	var members []map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &members))
+	require.Len(t, members, 1)
+	assert.Equal(t, second.AvatarThumbnailURL, members[0]["avatar_thumbnail_url"])
+
+	c, w = accountTestContext(user.ID, false, http.MethodDelete, "/api/me/avatar", nil)
+	DeleteCurrentUserAvatar(db, provider)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var reloaded models.User
+	require.NoError(t, db.First(&reloaded, user.ID).Error)
+	assert.Empty(t, reloaded.AvatarURL)
+	assert.Zero(t, avatarImages())
+
+	// Site admins can reset anyone's avatar
+	_, _ = uploadAvatar(pngOfSize(64, 64))
+	reset := func(target string) int {
+		c, w := accountTestContext(admin.ID, true, http.MethodDelete, "/api/admin/users/"+target+"/avatar", nil)
+		c.Params = gin.Params{{Key: "userId", Value: target}}
+		AdminResetUserAvatar(db, provider)(c)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusNotFound, reset("9999"))
+	assert.Equal(t, http.StatusOK, reset(fmt.Sprint(user.ID)))
+	require.NoError(t, db.First(&reloaded, user.ID).Error)
+	assert.Empty(t, reloaded.AvatarThumbnailURL)
+}
@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/auth"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// Bulk user actions
+const (
+	BulkUserDelete             = "delete"
+	BulkUserRestore            = "restore"
+	BulkUserAddToGroup         = "add-to-group"
+	BulkUserRemoveFromGroup    = "remove-from-group"
+	BulkUserForcePasswordReset = "force-password-reset"
+)
+
+// Outcomes of a bulk action for one user
+const (
+	BulkResultOK      = "ok"
+	BulkResultSkipped = "skipped" // Nothing to do, e.g. already a member
+	BulkResultFailed  = "failed"
+)
+
+// errBulkUsersFailed rolls back a bulk action when any user failed
+var errBulkUsersFailed = errors.New("bulk user action failed for some users")
+
+// BulkUserActionRequest applies one action to many users. group_id is
+// required for add-to-group and remove-from-group.
+type BulkUserActionRequest struct {
+	Action  string `json:"action" binding:"required,oneof=delete restore add-to-group remove-from-group force-password-reset"`
+	UserIDs []uint `json:"user_ids" binding:"required,min=1,max=500"`
+	GroupID uint   `json:"group_id"`
+}
+
+// BulkUserResult is what a bulk action did, or would have done, to one user
+type BulkUserResult struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username,omitempty"`
+	Status   string `json:"status"`
+	Message  string `json:"message,omitempty"`
+}
+
+// BulkUserActionResponse reports a bulk action. Applied is false when any
+// user failed, or on a dry run, in which case nothing was changed.
+type BulkUserActionResponse struct {
+	Action    string           `json:"action"`
+	Applied   bool             `json:"applied"`
+	DryRun    bool             `json:"dry_run"`
+	Succeeded int              `json:"succeeded"`
+	Skipped   int              `json:"skipped"`
+	Failed    int              `json:"failed"`
+	Results   []BulkUserResult `json:"results"`
+}
+
+// pendingResetEmail is a forced password reset email to send once the
+// transaction commits
+type pendingResetEmail struct {
+	result   int // Index in Results
+	to       string
+	username string
+	token    string
+}
+
+// bulkUserAction applies one action to one user in tx, returning the
+// user's status and a message. Any returned error rolls back the whole
+// request.
+type bulkUserAction func(tx *gorm.DB, user models.User, result int) (string, string, error)
+
+// BulkUserAction deletes, restores, adds to or removes from a group, or
+// forces a password reset for a list of users in one transaction (admin
+// only). Each user gets a result; if any user fails, for example because
+// they don't exist, nothing is changed and the response is 422 with the
+// results. Users the action doesn't change, such as one who is already a
+// member, are skipped without failing the request. Forced resets replace
+// the user's password with a random one and email them a reset link. With
+// ?dry_run=true the action is run and rolled back, so the results show what
+// it would do, and no emails are sent.
+// Route: POST /api/admin/users/bulk
+func BulkUserAction(db *gorm.DB, emailService *email.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		db := middleware.GetDB(c, db)
+		logger := middleware.GetLogger(c)
+		adminID, _ := middleware.GetUserID(c)
+
+		var req BulkUserActionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+
+		var group models.Group
+		if req.Action == BulkUserAddToGroup || req.Action == BulkUserRemoveFromGroup {
+			if req.GroupID == 0 {
+				respondBadRequest(c, "group_id is required for "+req.Action)
+				return
+			}
+			if err := db.First(&group, req.GroupID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					respondError(c, http.StatusNotFound, ErrCodeGroupNotFound, "Group not found")
+					return
+				}
+				respondInternalError(c, "Failed to fetch group")
+				return
+			}
+		}
+		if req.Action == BulkUserForcePasswordReset && (emailService == nil || !emailService.IsConfigured()) {
+			respondBadRequest(c, "Email service is not configured, so users couldn't receive their reset links")
+			return
+		}
+
+		// Repeated IDs are acted on once
+		var userIDs []uint
+		seen := make(map[uint]bool, len(req.UserIDs))
+		for _, id := range req.UserIDs {
+			if !seen[id] {
+				seen[id] = true
+				userIDs = append(userIDs, id)
+			}
+		}
+
+		var emails []pendingResetEmail
+		var action bulkUserAction
+		switch req.Action {
+		case BulkUserDelete:
+			action = func(tx *gorm.DB, user models.User, _ int) (string, string, error) {
+				if user.ID == adminID {
+					return BulkResultFailed, "You can't delete your own account", nil
+				}
+				if user.DeletedAt.Valid {
+					return BulkResultSkipped, "Already deleted", nil
+				}
+				return BulkResultOK, "", tx.Delete(&user).Error
+			}
+		case BulkUserRestore:
+			action = func(tx *gorm.DB, user models.User, _ int) (string, string, error) {
+				if user.AnonymizedAt != nil {
+					return BulkResultFailed, "Personal data has been erased; the account can't be restored", nil
+				}
+				if !user.DeletedAt.Valid {
+					return BulkResultSkipped, "Not deleted", nil
+				}
+				return BulkResultOK, "", tx.Unscoped().Model(&user).
+					Updates(map[string]interface{}{"deleted_at": nil, "deactivation_requested_at": nil}).Error
+			}
+		case BulkUserAddToGroup:
+			action = func(tx *gorm.DB, user models.User, _ int) (string, string, error) {
+				if user.DeletedAt.Valid {
+					return BulkResultFailed, "User is deleted", nil
+				}
+				if !middleware.SameOrganization(user.OrganizationID, group.OrganizationID) {
+					return BulkResultFailed, "User is in a different organization", nil
+				}
+				result := tx.Where(models.UserGroup{UserID: user.ID, GroupID: group.ID}).
+					FirstOrCreate(&models.UserGroup{UserID: user.ID, GroupID: group.ID})
+				if result.Error != nil {
+					return "", "", result.Error
+				}
+				if result.RowsAffected == 0 {
+					return BulkResultSkipped, "Already a member", nil
+				}
+				return BulkResultOK, "", nil
+			}
+		case BulkUserRemoveFromGroup:
+			action = func(tx *gorm.DB, user models.User, _ int) (string, string, error) {
+				result := tx.Where("user_id = ? AND group_id = ?", user.ID, group.ID).Delete(&models.UserGroup{})
+				if result.Error != nil {
+					return "", "", result.Error
+				}
+				if result.RowsAffected == 0 {
+					return BulkResultSkipped, "Not a member", nil
+				}
+				return BulkResultOK, "", nil
+			}
+		case BulkUserForcePasswordReset:
+			action = func(tx *gorm.DB, user models.User, result int) (string, string, error) {
+				if user.DeletedAt.Valid {
+					return BulkResultFailed, "User is deleted", nil
+				}
+				if user.RequiresPasswordSetup {
+					return BulkResultSkipped, "Hasn't set a password yet; resend the invitation instead", nil
+				}
+				token, updates, err := forcedPasswordResetUpdates()
+				if err != nil {
+					return "", "", err
+				}
+				if err := tx.Model(&user).Updates(updates).Error; err != nil {
+					return "", "", err
+				}
+				emails = append(emails, pendingResetEmail{result: result, to: user.Email, username: user.Username, token: token})
+				return BulkResultOK, "", nil
+			}
+		}
+
+		response := BulkUserActionResponse{Action: req.Action, DryRun: c.Query("dry_run") == "true", Results: make([]BulkUserResult, len(userIDs))}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var users []models.User
+			if err := tx.Unscoped().Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+				return err
+			}
+			byID := make(map[uint]models.User, len(users))
+			for _, u := range users {
+				byID[u.ID] = u
+			}
+
+			for i, id := range userIDs {
+				result := BulkUserResult{UserID: id, Status: BulkResultFailed, Message: "User not found"}
+				if user, ok := byID[id]; ok {
+					status, message, err := action(tx, user, i)
+					if err != nil {
+						return err
+					}
+					result = BulkUserResult{UserID: id, Username: user.Username, Status: status, Message: message}
+				}
+				response.Results[i] = result
+				switch result.Status {
+				case BulkResultOK:
+					response.Succeeded++
+				case BulkResultSkipped:
+					response.Skipped++
+				default:
+					response.Failed++
+				}
+			}
+			if response.Failed > 0 {
+				return errBulkUsersFailed
+			}
+			if response.DryRun {
+				return errDryRun
+			}
+			return nil
+		})
+		if errors.Is(err, errBulkUsersFailed) {
+			c.JSON(http.StatusUnprocessableEntity, response)
+			return
+		}
+		if errors.Is(err, errDryRun) {
+			respondOK(c, response)
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to apply bulk user action", err)
+			respondInternalError(c, "Failed to apply bulk user action")
+			return
+		}
+		response.Applied = true
+
+		for _, pending := range emails {
+			if err := emailService.SendPasswordResetEmail(ctx, pending.to, pending.username, pending.token); err != nil {
+				logger.Error("Failed to send forced password reset email", err)
+				response.Results[pending.result].Message = "Password reset, but the email couldn't be sent; the user can request a new link"
+			}
+		}
+		logBulkUserAction(c, req.Action, adminID, group.ID, response.Results)
+
+		logger.WithFields(map[string]interface{}{
+			"action":    req.Action,
+			"succeeded": response.Succeeded,
+			"skipped":   response.Skipped,
+		}).Info("Applied bulk user action")
+		respondOK(c, response)
+	}
+}
+
+// forcedPasswordResetUpdates returns a reset token and the column updates
+// that replace a user's password with an unusable random one and issue the
+// token, as a password reset request would.
+func forcedPasswordResetUpdates() (string, map[string]interface{}, error) {
+	token, err := generateSecureToken()
+	if err != nil {
+		return "", nil, err
+	}
+	hashedToken, err := auth.HashPassword(token)
+	if err != nil {
+		return "", nil, err
+	}
+	placeholder, err := generateSecureToken()
+	if err != nil {
+		return "", nil, err
+	}
+	hashedPlaceholder, err := auth.HashPassword(placeholder)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, map[string]interface{}{
+		"password":           hashedPlaceholder,
+		"reset_token":        hashedToken,
+		"reset_token_lookup": token[:TokenLookupPrefixLength],
+		"reset_token_expiry": time.Now().Add(PasswordResetTokenExpiry),
+	}, nil
+}
+
+// logBulkUserAction writes an audit entry for each user a committed bulk
+// action changed.
+func logBulkUserAction(c *gin.Context, action string, adminID, groupID uint, results []BulkUserResult) {
+	for _, result := range results {
+		if result.Status != BulkResultOK {
+			continue
+		}
+		switch action {
+		case BulkUserAddToGroup:
+			logMembershipChange(c, logging.AuditEventUserAddedToGroup, result.UserID, []uint{groupID})
+		case BulkUserRemoveFromGroup:
+			logMembershipChange(c, logging.AuditEventUserRemovedFromGroup, result.UserID, []uint{groupID})
+		default:
+			event := map[string]logging.AuditEvent{
+				BulkUserDelete:             logging.AuditEventUserDeleted,
+				BulkUserRestore:            logging.AuditEventUserRestored,
+				BulkUserForcePasswordReset: logging.AuditEventPasswordResetForced,
+			}[action]
+			logging.LogAdminAction(c.Request.Context(), event, adminID, map[string]interface{}{
+				"target_user_id": result.UserID,
+				"bulk":           true,
+			})
+		}
+	}
+}
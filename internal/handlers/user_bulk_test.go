@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/auth"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkUserAction(t *testing.T) {
+	db := SetupTestDB(t)
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	summer1 := CreateTestUser(t, db, "summer1", "summer1@example.com", "password123", false)
+	summer2 := CreateTestUser(t, db, "summer2", "summer2@example.com", "password123", false)
+	invited := CreateTestUser(t, db, "invited", "invited@example.com", "password123", false)
+	require.NoError(t, db.Model(invited).Update("requires_password_setup", true).Error)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	AddUserToGroupWithAdmin(t, db, summer2.ID, group.ID, false)
+
+	provider := &recordingEmailProvider{}
+	run := func(body gin.H) (int, BulkUserActionResponse) {
+		c, w := accountTestContext(admin.ID, true, http.MethodPost, "/api/admin/users/bulk", body)
+		BulkUserAction(db, email.NewServiceWithProvider(provider, db))(c)
+		var response BulkUserActionResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+	statuses := func(response BulkUserActionResponse) []string {
+		out := []string{}
+		for _, r := range response.Results {
+			out = append(out, r.Status)
+		}
+		return out
+	}
+	members := func() int64 {
+		var n int64
+		db.Model(&models.UserGroup{}).Where("group_id = ?", group.ID).Count(&n)
+		return n
+	}
+
+	code, _ := run(gin.H{"action": "add-to-group", "user_ids": []uint{summer1.ID}})
+	assert.Equal(t, http.StatusBadRequest, code, "group_id is required")
+	code, _ = run(gin.H{"action": "archive", "user_ids": []uint{summer1.ID}})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// One unknown user rolls back the whole request
+	code, response := run(gin.H{"action": "add-to-group", "group_id": group.ID, "user_ids": []uint{summer1.ID, 9999}})
+	require.Equal(t, http.StatusUnprocessableEntity, code)
+	assert.False(t, response.Applied)
+	assert.Equal(t, []string{BulkResultOK, BulkResultFailed}, statuses(response))
+	assert.Equal(t, int64(1), members())
+
+	code, response = run(gin.H{"action": "add-to-group", "group_id": group.ID, "user_ids": []uint{summer1.ID, summer2.ID, summer1.ID}})
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, response.Applied)
+	assert.Equal(t, []string{BulkResultOK, BulkResultSkipped}, statuses(response), "repeated IDs are acted on once")
+	assert.Equal(t, int64(2), members())
+
+	code, _ = run(gin.H{"action": "delete", "user_ids": []uint{summer1.ID, admin.ID}})
+	require.Equal(t, http.StatusUnprocessableEntity, code, "admins can't delete themselves")
+	code, response = run(gin.H{"action": "delete", "user_ids": []uint{summer1.ID, summer2.ID}})
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 2, response.Succeeded)
+	var remaining int64
+	db.Model(&models.User{}).Where("id IN ?", []uint{summer1.ID, summer2.ID}).Count(&remaining)
+	assert.Zero(t, remaining)
+
+	code, _ = run(gin.H{"action": "force-password-reset", "user_ids": []uint{summer1.ID}})
+	assert.Equal(t, http.StatusUnprocessableEntity, code, "deleted users can't be reset")
+
+	code, response = run(gin.H{"action": "restore", "user_ids": []uint{summer1.ID, summer2.ID, invited.ID}})
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{BulkResultOK, BulkResultOK, BulkResultSkipped}, statuses(response))
+
+	code, response = run(gin.H{"action": "force-password-reset", "user_ids": []uint{summer1.ID, invited.ID}})
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{BulkResultOK, BulkResultSkipped}, statuses(response))
+	assert.Equal(t, []string{"summer1@example.com"}, provider.sentTo)
+	var reset models.User
+	require.NoError(t, db.First(&reset, summer1.ID).Error)
+	assert.Error(t, auth.CheckPassword(reset.Password, "password123"), "the old password stops working")
+	assert.NotEmpty(t, reset.ResetTokenLookup)
+
+	code, response = run(gin.H{"action": "remove-from-group", "group_id": group.ID, "user_ids": []uint{summer1.ID, invited.ID}})
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{BulkResultOK, BulkResultSkipped}, statuses(response))
+	assert.Equal(t, int64(1), members())
+}
@@ -0,0 +1,456 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// defaultUserMergeUndoDays is how long an account merge can be undone
+const defaultUserMergeUndoDays = 14
+
+// userMergeUndoChunk caps the IDs per statement when an undo moves rows back
+const userMergeUndoChunk = 1000
+
+// userMergeUndoWindow returns how long a merge can be undone, from
+// USER_MERGE_UNDO_DAYS (default 14).
+func userMergeUndoWindow() time.Duration {
+	days := defaultUserMergeUndoDays
+	if v := os.Getenv("USER_MERGE_UNDO_DAYS"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed >= 1 {
+			days = parsed
+		} else {
+			logging.WithField("value", v).Warn("Invalid USER_MERGE_UNDO_DAYS, using default")
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// userMergeMoves lists the records that belong to a user through one column
+// and move to the target account on merge. unique names the other columns
+// of a unique index on column: a source row that would duplicate one of the
+// target's, such as a second reaction of the same type, is deleted instead.
+var userMergeMoves = []struct {
+	key    string
+	table  string
+	column string
+	unique []string
+}{
+	{"comments", "animal_comments", "user_id", nil},
+	{"comment_edits", "comment_histories", "edited_by", nil},
+	{"reactions", "comment_reactions", "user_id", []string{"comment_id", "type"}},
+	{"updates", "updates", "user_id", nil},
+	{"discussions", "discussions", "user_id", nil},
+	{"discussion_replies", "discussion_replies", "user_id", nil},
+	{"discussion_participation", "discussion_participants", "user_id", []string{"discussion_id"}},
+	{"announcements", "announcements", "user_id", nil},
+	{"announcement_reads", "announcement_reads", "user_id", []string{"announcement_id"}},
+	{"announcement_email_events", "announcement_email_events", "user_id", nil},
+	{"images", "animal_images", "user_id", nil},
+	{"videos", "animal_videos", "user_id", nil},
+	{"animal_changes", "animal_changes", "user_id", nil},
+	{"weights", "weight_entries", "recorded_by_id", nil},
+	{"behavior_assessments", "behavior_assessments", "assessed_by_id", nil},
+	{"protocol_acknowledgments", "protocol_acknowledgments", "user_id", []string{"protocol_id", "version"}},
+	{"qualifications", "user_qualifications", "user_id", []string{"animal_tag_id"}},
+	{"views", "animal_views", "user_id", nil},
+	{"sign_in_identities", "user_identities", "user_id", nil},
+	{"saved_filters", "saved_filters", "user_id", []string{"group_id", "name"}},
+	{"foster_profiles", "foster_profiles", "user_id", []string{"group_id"}},
+	{"onboarding_completions", "onboarding_completions", "user_id", []string{"item_id"}},
+	{"member_onboardings", "member_onboardings", "user_id", []string{"group_id"}},
+	{"join_requests", "group_join_requests", "user_id", nil},
+	{"api_tokens", "api_tokens", "user_id", nil},
+	{"emergency_broadcast_deliveries", "emergency_broadcast_deliveries", "user_id", nil},
+}
+
+// userMergeMembership is one of the source's group memberships. Moved
+// memberships were handed to the target; the others duplicated one of the
+// target's, which kept the higher of the two roles.
+type userMergeMembership struct {
+	GroupID            uint      `json:"group_id"`
+	IsGroupAdmin       bool      `json:"is_group_admin"`
+	Role               string    `json:"role"`
+	CreatedAt          time.Time `json:"created_at"`
+	Moved              bool      `json:"moved"`
+	TargetIsGroupAdmin bool      `json:"target_is_group_admin,omitempty"`
+	TargetRole         string    `json:"target_role,omitempty"`
+}
+
+// userMergeRecord is stored in UserMerge.Record
+type userMergeRecord struct {
+	Rows             map[string][]uint     `json:"rows"` // userMergeMoves key -> IDs moved to the target
+	Memberships      []userMergeMembership `json:"memberships"`
+	SkillTagIDs      []uint                `json:"skill_tag_ids"`       // All of the source's skill tags
+	MovedSkillTagIDs []uint                `json:"moved_skill_tag_ids"` // Those the target didn't already have
+}
+
+// MergeUsersRequest names the duplicate account to fold into :userId
+type MergeUsersRequest struct {
+	DuplicateUserID uint `json:"duplicate_user_id" binding:"required"`
+}
+
+// UserMergeResult is a merge with how many records of each kind moved to
+// the target, and how many duplicates of the target's were deleted
+type UserMergeResult struct {
+	Merge   models.UserMerge `json:"merge"`
+	Moved   map[string]int64 `json:"moved"`
+	Dropped map[string]int64 `json:"dropped"`
+}
+
+// higherGroupRole returns the more senior of two member roles
+func higherGroupRole(a, b string) string {
+	if a == models.GroupRoleVolunteer || b == models.GroupRoleVolunteer {
+		return models.GroupRoleVolunteer
+	}
+	if a != "" {
+		return a
+	}
+	return b
+}
+
+// mergeUsers folds source into target inside tx: source's comments, photos,
+// and other records move to target, its group memberships and skill tags are
+// added to target's, and source is soft-deleted. target's profile, and
+// whether either is a site admin, are left alone.
+func mergeUsers(tx *gorm.DB, target, source uint) (*userMergeRecord, map[string]int64, map[string]int64, error) {
+	record := &userMergeRecord{Rows: make(map[string][]uint, len(userMergeMoves))}
+	moved := make(map[string]int64, len(userMergeMoves)+2)
+	dropped := make(map[string]int64)
+
+	// The target keeps its own default saved filter in groups where both
+	// accounts have one
+	if err := tx.Exec(`UPDATE saved_filters SET is_default = ? WHERE user_id = ? AND is_default = ?
+		AND EXISTS (SELECT 1 FROM saved_filters o WHERE o.user_id = ? AND o.group_id = saved_filters.group_id AND o.is_default = ?)`,
+		false, source, true, target, true).Error; err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Raw tables, so soft-deleted rows move too and stay attributable
+	for _, m := range userMergeMoves {
+		if len(m.unique) > 0 {
+			conds := make([]string, len(m.unique))
+			for i, col := range m.unique {
+				conds[i] = fmt.Sprintf("o.%s = %s.%s", col, m.table, col)
+			}
+			sub := tx.Table(m.table+" AS o").Select("1").
+				Where("o."+m.column+" = ?", target).Where(strings.Join(conds, " AND "))
+			var dupIDs []uint
+			if err := tx.Table(m.table).Where(m.column+" = ?", source).Where("EXISTS (?)", sub).
+				Pluck("id", &dupIDs).Error; err != nil {
+				return nil, nil, nil, err
+			}
+			if len(dupIDs) > 0 {
+				if err := tx.Exec("DELETE FROM "+m.table+" WHERE id IN ?", dupIDs).Error; err != nil {
+					return nil, nil, nil, err
+				}
+				dropped[m.key] = int64(len(dupIDs))
+			}
+		}
+
+		var ids []uint
+		if err := tx.Table(m.table).Where(m.column+" = ?", source).Pluck("id", &ids).Error; err != nil {
+			return nil, nil, nil, err
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		if err := tx.Exec("UPDATE "+m.table+" SET "+m.column+" = ? WHERE "+m.column+" = ?", target, source).Error; err != nil {
+			return nil, nil, nil, err
+		}
+		record.Rows[m.key] = ids
+		moved[m.key] = int64(len(ids))
+	}
+
+	var targetGroups, sourceGroups []models.UserGroup
+	if err := tx.Where("user_id = ?", target).Find(&targetGroups).Error; err != nil {
+		return nil, nil, nil, err
+	}
+	if err := tx.Where("user_id = ?", source).Find(&sourceGroups).Error; err != nil {
+		return nil, nil, nil, err
+	}
+	targetByGroup := make(map[uint]models.UserGroup, len(targetGroups))
+	for _, ug := range targetGroups {
+		targetByGroup[ug.GroupID] = ug
+	}
+	for _, ug := range sourceGroups {
+		m := userMergeMembership{GroupID: ug.GroupID, IsGroupAdmin: ug.IsGroupAdmin, Role: ug.Role, CreatedAt: ug.CreatedAt}
+		existing, shared := targetByGroup[ug.GroupID]
+		if !shared {
+			m.Moved = true
+			if err := tx.Exec("UPDATE user_groups SET user_id = ? WHERE user_id = ? AND group_id = ?", target, source, ug.GroupID).Error; err != nil {
+				return nil, nil, nil, err
+			}
+			moved["groups"]++
+		} else {
+			m.TargetIsGroupAdmin = existing.IsGroupAdmin
+			m.TargetRole = existing.Role
+			if err := tx.Model(&models.UserGroup{}).Where("user_id = ? AND group_id = ?", target, ug.GroupID).
+				Updates(map[string]interface{}{
+					"is_group_admin": existing.IsGroupAdmin || ug.IsGroupAdmin,
+					"role":           higherGroupRole(existing.Role, ug.Role),
+				}).Error; err != nil {
+				return nil, nil, nil, err
+			}
+			if err := tx.Exec("DELETE FROM user_groups WHERE user_id = ? AND group_id = ?", source, ug.GroupID).Error; err != nil {
+				return nil, nil, nil, err
+			}
+		}
+		record.Memberships = append(record.Memberships, m)
+	}
+
+	var targetTags []uint
+	if err := tx.Table("user_skill_tag_assignments").Where("user_id = ?", target).Pluck("user_skill_tag_id", &targetTags).Error; err != nil {
+		return nil, nil, nil, err
+	}
+	if err := tx.Table("user_skill_tag_assignments").Where("user_id = ?", source).Pluck("user_skill_tag_id", &record.SkillTagIDs).Error; err != nil {
+		return nil, nil, nil, err
+	}
+	hasTag := make(map[uint]bool, len(targetTags))
+	for _, id := range targetTags {
+		hasTag[id] = true
+	}
+	for _, id := range record.SkillTagIDs {
+		if !hasTag[id] {
+			record.MovedSkillTagIDs = append(record.MovedSkillTagIDs, id)
+			if err := tx.Exec("INSERT INTO user_skill_tag_assignments (user_id, user_skill_tag_id) VALUES (?, ?)", target, id).Error; err != nil {
+				return nil, nil, nil, err
+			}
+		}
+	}
+	if err := tx.Exec("DELETE FROM user_skill_tag_assignments WHERE user_id = ?", source).Error; err != nil {
+		return nil, nil, nil, err
+	}
+	if len(record.MovedSkillTagIDs) > 0 {
+		moved["skill_tags"] = int64(len(record.MovedSkillTagIDs))
+	}
+
+	if err := tx.Delete(&models.User{}, source).Error; err != nil {
+		return nil, nil, nil, err
+	}
+	return record, moved, dropped, nil
+}
+
+// undoUserMerge reverses mergeUsers inside tx. Records the target created
+// since the merge stay with the target. Duplicates deleted by the merge
+// (counted in UserMergeResult.Dropped) are gone for good and aren't
+// restored, and neither is a default saved filter the merge unset.
+func undoUserMerge(tx *gorm.DB, merge *models.UserMerge, record *userMergeRecord) error {
+	target, source := merge.TargetUserID, merge.SourceUserID
+	for _, m := range userMergeMoves {
+		ids := record.Rows[m.key]
+		for start := 0; start < len(ids); start += userMergeUndoChunk {
+			chunk := ids[start:min(start+userMergeUndoChunk, len(ids))]
+			if err := tx.Exec("UPDATE "+m.table+" SET "+m.column+" = ? WHERE "+m.column+" = ? AND id IN ?", source, target, chunk).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, m := range record.Memberships {
+		if m.Moved {
+			if err := tx.Exec("UPDATE user_groups SET user_id = ? WHERE user_id = ? AND group_id = ?", source, target, m.GroupID).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		if err := tx.Model(&models.UserGroup{}).Where("user_id = ? AND group_id = ?", target, m.GroupID).
+			Updates(map[string]interface{}{"is_group_admin": m.TargetIsGroupAdmin, "role": m.TargetRole}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.UserGroup{UserID: source, GroupID: m.GroupID, IsGroupAdmin: m.IsGroupAdmin, Role: m.Role, CreatedAt: m.CreatedAt}).Error; err != nil {
+			return err
+		}
+	}
+
+	if len(record.MovedSkillTagIDs) > 0 {
+		if err := tx.Exec("DELETE FROM user_skill_tag_assignments WHERE user_id = ? AND user_skill_tag_id IN ?", target, record.MovedSkillTagIDs).Error; err != nil {
+			return err
+		}
+	}
+	for _, id := range record.SkillTagIDs {
+		if err := tx.Exec("INSERT INTO user_skill_tag_assignments (user_id, user_skill_tag_id) VALUES (?, ?)", source, id).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Unscoped().Model(&models.User{}).Where("id = ?", source).Update("deleted_at", nil).Error
+}
+
+// MergeUsers folds a duplicate account into the :userId account (admin
+// only). The duplicate is soft-deleted, and the merge can be undone with
+// UndoUserMerge until its undo deadline.
+// Route: POST /api/admin/users/:userId/merge
+func MergeUsers(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		logger := middleware.GetLogger(c)
+		adminID, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+
+		targetID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid user ID")
+			return
+		}
+		var req MergeUsersRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		if req.DuplicateUserID == uint(targetID) {
+			respondBadRequest(c, "An account can't be merged into itself")
+			return
+		}
+		if req.DuplicateUserID == adminID {
+			respondBadRequest(c, "You can't merge away your own account")
+			return
+		}
+
+		var target, source models.User
+		if err := db.First(&target, targetID).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeUserNotFound, "User not found")
+			return
+		}
+		if err := db.First(&source, req.DuplicateUserID).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeUserNotFound, "Duplicate user not found")
+			return
+		}
+		if !middleware.SameOrganization(target.OrganizationID, source.OrganizationID) {
+			respondError(c, http.StatusConflict, ErrCodeOrganizationMismatch, "Both accounts must belong to the same organization")
+			return
+		}
+
+		merge := models.UserMerge{
+			TargetUserID: target.ID,
+			SourceUserID: source.ID,
+			MergedByID:   adminID,
+			UndoDeadline: time.Now().Add(userMergeUndoWindow()),
+		}
+		var moved, dropped map[string]int64
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			record, m, d, err := mergeUsers(tx, target.ID, source.ID)
+			if err != nil {
+				return err
+			}
+			moved, dropped = m, d
+			data, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			merge.Record = string(data)
+			return tx.Create(&merge).Error
+		}); err != nil {
+			logger.Error("Failed to merge users", err)
+			respondInternalError(c, "Failed to merge accounts")
+			return
+		}
+
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventUserMerged, adminID, map[string]interface{}{
+			"merge_id":       merge.ID,
+			"target_user_id": target.ID,
+			"source_user_id": source.ID,
+			"undo_deadline":  merge.UndoDeadline,
+		})
+		respondOK(c, UserMergeResult{Merge: merge, Moved: moved, Dropped: dropped})
+	}
+}
+
+// GetUserMerges lists account merges, newest first (admin only)
+// Route: GET /api/admin/user-merges
+func GetUserMerges(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		var merges []models.UserMerge
+		if err := db.Order("created_at DESC, id DESC").Limit(200).Find(&merges).Error; err != nil {
+			respondInternalError(c, "Failed to fetch account merges")
+			return
+		}
+		respondOK(c, merges)
+	}
+}
+
+// errUserMergeNotUndoable is returned inside the undo transaction when
+// another request undid the merge first
+var errUserMergeNotUndoable = errors.New("merge already undone")
+
+// UndoUserMerge restores the duplicate account of a merge and moves its
+// records and memberships back (admin only). Duplicates the merge deleted,
+// such as a second identical reaction, aren't restored.
+// Route: POST /api/admin/user-merges/:mergeId/undo
+func UndoUserMerge(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		logger := middleware.GetLogger(c)
+		adminID, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+
+		var merge models.UserMerge
+		if err := db.First(&merge, c.Param("mergeId")).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeNotFound, "Account merge not found")
+			return
+		}
+		if merge.UndoneAt != nil {
+			respondError(c, http.StatusConflict, ErrCodeConflict, "This merge has already been undone")
+			return
+		}
+		if time.Now().After(merge.UndoDeadline) {
+			respondError(c, http.StatusConflict, ErrCodeConflict, "This merge can no longer be undone")
+			return
+		}
+		var record userMergeRecord
+		if err := json.Unmarshal([]byte(merge.Record), &record); err != nil {
+			logger.Error("Failed to read account merge record", err)
+			respondInternalError(c, "Failed to undo account merge")
+			return
+		}
+
+		now := time.Now()
+		err := db.Transaction(func(tx *gorm.DB) error {
+			res := tx.Model(&models.UserMerge{}).Where("id = ? AND undone_at IS NULL", merge.ID).
+				Updates(map[string]interface{}{"undone_at": now, "undone_by_id": adminID})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return errUserMergeNotUndoable
+			}
+			return undoUserMerge(tx, &merge, &record)
+		})
+		if errors.Is(err, errUserMergeNotUndoable) {
+			respondError(c, http.StatusConflict, ErrCodeConflict, "This merge has already been undone")
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to undo account merge", err)
+			respondInternalError(c, "Failed to undo account merge")
+			return
+		}
+
+		logging.LogAdminAction(c.Request.Context(), logging.AuditEventUserMergeUndone, adminID, map[string]interface{}{
+			"merge_id":       merge.ID,
+			"target_user_id": merge.TargetUserID,
+			"source_user_id": merge.SourceUserID,
+		})
+		merge.UndoneAt = &now
+		merge.UndoneByID = &adminID
+		respondOK(c, merge)
+	}
+}
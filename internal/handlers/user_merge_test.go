@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeUsers(t *testing.T) {
+	db := SetupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.CommentHistory{}, &models.Update{}, &models.Announcement{}, &models.AnnouncementRead{},
+		&models.AnimalImage{}, &models.AnimalVideo{}, &models.AnimalChange{}, &models.WeightEntry{}, &models.BehaviorAssessment{},
+		&models.ProtocolAcknowledgment{}, &models.UserQualification{}, &models.AnimalView{}, &models.UserIdentity{}, &models.APIToken{}))
+	admin := CreateTestUser(t, db, "admin", "admin@example.com", "password123", true)
+	keep := CreateTestUser(t, db, "jamie", "jamie@example.com", "password123", false)
+	dup := CreateTestUser(t, db, "jamie2", "jamie.work@example.com", "password123", false)
+	dogs := CreateTestGroup(t, db, "Dogs", "")
+	cats := CreateTestGroup(t, db, "Cats", "")
+	AddUserToGroupWithAdmin(t, db, keep.ID, dogs.ID, false)
+	AddUserToGroupWithAdmin(t, db, dup.ID, dogs.ID, true)
+	AddUserToGroupWithAdmin(t, db, dup.ID, cats.ID, false)
+
+	animal := models.Animal{GroupID: dogs.ID, Name: "Rex", Species: "Dog", Status: "available"}
+	require.NoError(t, db.Create(&animal).Error)
+	comment := models.AnimalComment{AnimalID: animal.ID, UserID: dup.ID, Content: "Walked twice"}
+	require.NoError(t, db.Create(&comment).Error)
+	other := models.AnimalComment{AnimalID: animal.ID, UserID: keep.ID, Content: "Fed"}
+	require.NoError(t, db.Create(&other).Error)
+	require.NoError(t, db.Create(&models.CommentReaction{CommentID: other.ID, UserID: keep.ID, Type: "like"}).Error)
+	require.NoError(t, db.Create(&models.CommentReaction{CommentID: other.ID, UserID: dup.ID, Type: "like"}).Error)
+	require.NoError(t, db.Create(&models.CommentReaction{CommentID: other.ID, UserID: dup.ID, Type: "love"}).Error)
+	tag := models.UserSkillTag{GroupID: dogs.ID, Name: "Reactive dogs"}
+	require.NoError(t, db.Create(&tag).Error)
+	require.NoError(t, db.Exec("INSERT INTO user_skill_tag_assignments (user_id, user_skill_tag_id) VALUES (?, ?)", dup.ID, tag.ID).Error)
+	require.NoError(t, db.Create(&models.SavedFilter{UserID: keep.ID, GroupID: dogs.ID, Name: "Fosters", IsDefault: true}).Error)
+	require.NoError(t, db.Create(&models.SavedFilter{UserID: dup.ID, GroupID: dogs.ID, Name: "Fosters"}).Error)
+	require.NoError(t, db.Create(&models.FosterProfile{UserID: keep.ID, GroupID: dogs.ID}).Error)
+	require.NoError(t, db.Create(&models.FosterProfile{UserID: dup.ID, GroupID: dogs.ID}).Error)
+	catFoster := models.FosterProfile{UserID: dup.ID, GroupID: cats.ID}
+	require.NoError(t, db.Create(&catFoster).Error)
+	require.NoError(t, db.Create(&models.OnboardingCompletion{ItemID: 1, UserID: keep.ID, CompletedByID: keep.ID}).Error)
+	require.NoError(t, db.Create(&models.OnboardingCompletion{ItemID: 1, UserID: dup.ID, CompletedByID: dup.ID}).Error)
+	require.NoError(t, db.Create(&models.OnboardingCompletion{ItemID: 2, UserID: dup.ID, CompletedByID: dup.ID}).Error)
+	catOnboarding := models.MemberOnboarding{UserID: dup.ID, GroupID: cats.ID}
+	require.NoError(t, db.Create(&catOnboarding).Error)
+	seniors := models.SavedFilter{UserID: dup.ID, GroupID: dogs.ID, Name: "Seniors", IsDefault: true}
+	require.NoError(t, db.Create(&seniors).Error)
+	birds := CreateTestGroup(t, db, "Birds", "")
+	joinRequest := models.GroupJoinRequest{GroupID: birds.ID, UserID: dup.ID, Status: models.JoinRequestPending}
+	require.NoError(t, db.Create(&joinRequest).Error)
+	token := models.APIToken{UserID: dup.ID, Name: "Sync script", TokenHash: "hash", TokenPrefix: "vm_abc", ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, db.Create(&token).Error)
+	broadcast := models.EmergencyBroadcast{GroupID: &dogs.ID, SentByID: admin.ID, Message: "Storm shelter open"}
+	require.NoError(t, db.Create(&broadcast).Error)
+	delivery := models.EmergencyBroadcastDelivery{BroadcastID: broadcast.ID, UserID: dup.ID, PhoneNumber: "+15555550100"}
+	require.NoError(t, db.Create(&delivery).Error)
+
+	call := func(handler gin.HandlerFunc, params gin.Params, body interface{}) (int, []byte) {
+		c, w := accountTestContext(admin.ID, true, http.MethodPost, "/", body)
+		c.Params = params
+		handler(c)
+		return w.Code, w.Body.Bytes()
+	}
+	mergeInto := gin.Params{{Key: "userId", Value: itoa(keep.ID)}}
+
+	code, _ := call(MergeUsers(db), mergeInto, gin.H{"duplicate_user_id": keep.ID})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, body := call(MergeUsers(db), mergeInto, gin.H{"duplicate_user_id": dup.ID})
+	require.Equal(t, http.StatusOK, code, string(body))
+	var result UserMergeResult
+	require.NoError(t, json.Unmarshal(body, &result))
+	assert.Equal(t, int64(1), result.Moved["comments"])
+	assert.Equal(t, int64(1), result.Moved["reactions"])
+	assert.Equal(t, int64(1), result.Dropped["reactions"], "the duplicate like is dropped")
+	assert.Equal(t, int64(1), result.Moved["groups"])
+	assert.Equal(t, int64(1), result.Moved["saved_filters"])
+	assert.Equal(t, int64(1), result.Dropped["saved_filters"], "the same-named filter is dropped")
+	assert.Equal(t, int64(1), result.Moved["foster_profiles"])
+	assert.Equal(t, int64(1), result.Dropped["foster_profiles"], "the kept account's profile in a shared group wins")
+	assert.Equal(t, int64(1), result.Moved["onboarding_completions"])
+	assert.Equal(t, int64(1), result.Dropped["onboarding_completions"], "an item both completed is dropped")
+	assert.Equal(t, int64(1), result.Moved["member_onboardings"])
+	assert.Equal(t, int64(1), result.Moved["join_requests"])
+	assert.Equal(t, int64(1), result.Moved["api_tokens"])
+	assert.Equal(t, int64(1), result.Moved["emergency_broadcast_deliveries"])
+	var defaults int64
+	db.Model(&models.SavedFilter{}).Where("user_id = ? AND group_id = ? AND is_default = ?", keep.ID, dogs.ID, true).Count(&defaults)
+	assert.Equal(t, int64(1), defaults, "the kept account's default filter stays the only one")
+
+	var reloaded models.AnimalComment
+	require.NoError(t, db.First(&reloaded, comment.ID).Error)
+	assert.Equal(t, keep.ID, reloaded.UserID)
+	var memberships []models.UserGroup
+	require.NoError(t, db.Where("user_id = ?", keep.ID).Order("group_id").Find(&memberships).Error)
+	require.Len(t, memberships, 2)
+	assert.True(t, memberships[0].IsGroupAdmin, "the duplicate's group admin role carries over")
+	var tagCount int64
+	db.Table("user_skill_tag_assignments").Where("user_id = ?", keep.ID).Count(&tagCount)
+	assert.Equal(t, int64(1), tagCount)
+	assert.Error(t, db.First(&models.User{}, dup.ID).Error, "the duplicate is soft-deleted")
+	var movedToken models.APIToken
+	require.NoError(t, db.First(&movedToken, token.ID).Error)
+	assert.Equal(t, keep.ID, movedToken.UserID)
+	var movedDelivery models.EmergencyBroadcastDelivery
+	require.NoError(t, db.First(&movedDelivery, delivery.ID).Error)
+	assert.Equal(t, keep.ID, movedDelivery.UserID)
+
+	// Approving the duplicate's pending request adds the kept account
+	c, w := accountTestContext(admin.ID, true, http.MethodPost, "/", nil)
+	c.Params = gin.Params{{Key: "id", Value: itoa(birds.ID)}, {Key: "requestId", Value: itoa(joinRequest.ID)}}
+	ApproveJoinRequest(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var birdMembers []models.UserGroup
+	require.NoError(t, db.Where("group_id = ?", birds.ID).Find(&birdMembers).Error)
+	require.Len(t, birdMembers, 1)
+	assert.Equal(t, keep.ID, birdMembers[0].UserID)
+	require.NoError(t, db.Where("user_id = ? AND group_id = ?", keep.ID, birds.ID).Delete(&models.UserGroup{}).Error)
+
+	undo := gin.Params{{Key: "mergeId", Value: itoa(result.Merge.ID)}}
+	code, body = call(UndoUserMerge(db), undo, nil)
+	require.Equal(t, http.StatusOK, code, string(body))
+
+	require.NoError(t, db.First(&models.User{}, dup.ID).Error, "undo restores the duplicate")
+	require.NoError(t, db.First(&reloaded, comment.ID).Error)
+	assert.Equal(t, dup.ID, reloaded.UserID)
+	require.NoError(t, db.Where("user_id = ?", keep.ID).Find(&memberships).Error)
+	require.Len(t, memberships, 1)
+	assert.False(t, memberships[0].IsGroupAdmin)
+	var dupGroups int64
+	db.Model(&models.UserGroup{}).Where("user_id = ?", dup.ID).Count(&dupGroups)
+	assert.Equal(t, int64(2), dupGroups)
+	db.Table("user_skill_tag_assignments").Where("user_id = ?", keep.ID).Count(&tagCount)
+	assert.Equal(t, int64(0), tagCount)
+	var filter models.SavedFilter
+	require.NoError(t, db.First(&filter, seniors.ID).Error)
+	assert.Equal(t, dup.ID, filter.UserID)
+	var onboarding models.MemberOnboarding
+	require.NoError(t, db.First(&onboarding, catOnboarding.ID).Error)
+	assert.Equal(t, dup.ID, onboarding.UserID)
+	var foster models.FosterProfile
+	require.NoError(t, db.First(&foster, catFoster.ID).Error)
+	assert.Equal(t, dup.ID, foster.UserID)
+	require.NoError(t, db.First(&movedToken, token.ID).Error)
+	assert.Equal(t, dup.ID, movedToken.UserID)
+
+	code, _ = call(UndoUserMerge(db), undo, nil)
+	assert.Equal(t, http.StatusConflict, code, "a merge can only be undone once")
+
+	t.Run("merges past their undo deadline stay merged", func(t *testing.T) {
+		code, body := call(MergeUsers(db), mergeInto, gin.H{"duplicate_user_id": dup.ID})
+		require.Equal(t, http.StatusOK, code, string(body))
+		var again UserMergeResult
+		require.NoError(t, json.Unmarshal(body, &again))
+		db.Model(&models.UserMerge{}).Where("id = ?", again.Merge.ID).Update("undo_deadline", time.Now().Add(-time.Minute))
+		code, _ = call(UndoUserMerge(db), gin.Params{{Key: "mergeId", Value: itoa(again.Merge.ID)}}, nil)
+		assert.Equal(t, http.StatusConflict, code)
+	})
+}
@@ -27,6 +27,8 @@ type UserProfileResponse struct {
 	LastName              string                     `json:"last_name"`
 	Email                 string                     `json:"email"`
 	PhoneNumber           string                     `json:"phone_number"`
+	AvatarURL             string                     `json:"avatar_url"`
+	AvatarThumbnailURL    string                     `json:"avatar_thumbnail_url"`
 	IsAdmin               bool                       `json:"is_admin"`
 	CreatedAt             string                     `json:"created_at"`
 	DefaultGroupID        *uint                      `json:"default_group_id"`
@@ -193,22 +195,26 @@ func GetUserProfile(db *gorm.DB) gin.HandlerFunc {
 		if !isOwnProfile && !isSiteAdmin && !isGroupAdminForSharedGroup {
 			// Return profile info respecting privacy settings
 			type RegularUserProfileResponse struct {
-				ID          uint           `json:"id"`
-				Username    string         `json:"username"`
-				FirstName   string         `json:"first_name,omitempty"`
-				LastName    string         `json:"last_name,omitempty"`
-				Email       string         `json:"email,omitempty"`
-				PhoneNumber string         `json:"phone_number,omitempty"`
-				CreatedAt   string         `json:"created_at"`
-				Groups      []models.Group `json:"groups"`
+				ID                 uint           `json:"id"`
+				Username           string         `json:"username"`
+				FirstName          string         `json:"first_name,omitempty"`
+				LastName           string         `json:"last_name,omitempty"`
+				Email              string         `json:"email,omitempty"`
+				PhoneNumber        string         `json:"phone_number,omitempty"`
+				AvatarURL          string         `json:"avatar_url"`
+				AvatarThumbnailURL string         `json:"avatar_thumbnail_url"`
+				CreatedAt          string         `json:"created_at"`
+				Groups             []models.Group `json:"groups"`
 			}
 			response := RegularUserProfileResponse{
-				ID:        user.ID,
-				Username:  user.Username,
-				FirstName: user.FirstName,
-				LastName:  user.LastName,
-				CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-				Groups:    user.Groups,
+				ID:                 user.ID,
+				Username:           user.Username,
+				FirstName:          user.FirstName,
+				LastName:           user.LastName,
+				AvatarURL:          user.AvatarURL,
+				AvatarThumbnailURL: user.AvatarThumbnailURL,
+				CreatedAt:          user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+				Groups:             user.Groups,
 			}
 			// Only include email if user hasn't hidden it
 			if !user.HideEmail {
@@ -229,41 +235,47 @@ func GetUserProfile(db *gorm.DB) gin.HandlerFunc {
 			// Actually, based on PERMISSIONS.md: "Group admins can always see contact info"
 			// So group admins bypass privacy settings for their group members
 			type GroupAdminProfileResponse struct {
-				ID          uint            `json:"id"`
-				Username    string          `json:"username"`
-				FirstName   string          `json:"first_name"`
-				LastName    string          `json:"last_name"`
-				Email       string          `json:"email"`
-				PhoneNumber string          `json:"phone_number"`
-				CreatedAt   string          `json:"created_at"`
-				Groups      []models.Group  `json:"groups"`
-				SkillTags   []SkillTagEntry `json:"skill_tags"`
+				ID                 uint            `json:"id"`
+				Username           string          `json:"username"`
+				FirstName          string          `json:"first_name"`
+				LastName           string          `json:"last_name"`
+				Email              string          `json:"email"`
+				PhoneNumber        string          `json:"phone_number"`
+				AvatarURL          string          `json:"avatar_url"`
+				AvatarThumbnailURL string          `json:"avatar_thumbnail_url"`
+				CreatedAt          string          `json:"created_at"`
+				Groups             []models.Group  `json:"groups"`
+				SkillTags          []SkillTagEntry `json:"skill_tags"`
 			}
 			c.JSON(http.StatusOK, GroupAdminProfileResponse{
-				ID:          user.ID,
-				Username:    user.Username,
-				FirstName:   user.FirstName,
-				LastName:    user.LastName,
-				Email:       user.Email,
-				PhoneNumber: user.PhoneNumber,
-				CreatedAt:   user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-				Groups:      user.Groups,
-				SkillTags:   fetchSkillTagsForUser(db, user.ID, currentUserIDUint),
+				ID:                 user.ID,
+				Username:           user.Username,
+				FirstName:          user.FirstName,
+				LastName:           user.LastName,
+				Email:              user.Email,
+				PhoneNumber:        user.PhoneNumber,
+				AvatarURL:          user.AvatarURL,
+				AvatarThumbnailURL: user.AvatarThumbnailURL,
+				CreatedAt:          user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+				Groups:             user.Groups,
+				SkillTags:          fetchSkillTagsForUser(db, user.ID, currentUserIDUint),
 			})
 			return
 		} // Build full profile response for own profile or admin viewing
 		profile := UserProfileResponse{
-			ID:             user.ID,
-			Username:       user.Username,
-			FirstName:      user.FirstName,
-			LastName:       user.LastName,
-			Email:          user.Email,
-			PhoneNumber:    user.PhoneNumber,
-			IsAdmin:        user.IsAdmin,
-			CreatedAt:      user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			DefaultGroupID: user.DefaultGroupID,
-			Groups:         user.Groups,
-			SkillTags:      fetchSkillTagsForUser(db, user.ID, 0),
+			ID:                 user.ID,
+			Username:           user.Username,
+			FirstName:          user.FirstName,
+			LastName:           user.LastName,
+			Email:              user.Email,
+			PhoneNumber:        user.PhoneNumber,
+			AvatarURL:          user.AvatarURL,
+			AvatarThumbnailURL: user.AvatarThumbnailURL,
+			IsAdmin:            user.IsAdmin,
+			CreatedAt:          user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			DefaultGroupID:     user.DefaultGroupID,
+			Groups:             user.Groups,
+			SkillTags:          fetchSkillTagsForUser(db, user.ID, 0),
 		}
 
 		// Calculate statistics
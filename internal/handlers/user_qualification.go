@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// AssignUserQualificationsRequest replaces a member's qualifications in a
+// group with the given restricted animal tags
+type AssignUserQualificationsRequest struct {
+	AnimalTagIDs []uint `json:"animal_tag_ids"`
+}
+
+// restrictedTagViewer reports whether restricted animal tags apply to the
+// current user in the group, and who the user is. They apply to everyone
+// except site admins and the group's admins.
+func restrictedTagViewer(c *gin.Context, db *gorm.DB, groupID string) (uint, bool) {
+	userID, _ := c.Get("user_id")
+	isAdmin, _ := c.Get("is_admin")
+	if checkGroupAdminAccess(db, userID, isAdmin, groupID) {
+		return 0, false
+	}
+	uid, _ := middleware.GetUserID(c)
+	return uid, true
+}
+
+// restrictedAnimalSQL is the condition that the animal whose ID is in
+// idColumn carries a restricted tag a user has no qualification for. Its
+// arguments are true and the user's ID.
+func restrictedAnimalSQL(idColumn string) string {
+	return `EXISTS (
+		SELECT 1 FROM animal_animal_tags aat
+		JOIN animal_tags t ON t.id = aat.animal_tag_id
+		WHERE aat.animal_id = ` + idColumn + ` AND t.restricted = ? AND t.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM user_qualifications q WHERE q.animal_tag_id = t.id AND q.user_id = ?))`
+}
+
+// hideRestrictedAnimals excludes animals carrying a restricted tag the user
+// has no qualification for. query must select from animals.
+func hideRestrictedAnimals(query *gorm.DB, userID uint) *gorm.DB {
+	return query.Where("NOT "+restrictedAnimalSQL("animals.id"), true, userID)
+}
+
+// visibleAnimals applies hideRestrictedAnimals for users that restricted
+// tags apply to in the group
+func visibleAnimals(c *gin.Context, db *gorm.DB, query *gorm.DB, groupID string) *gorm.DB {
+	if userID, restricted := restrictedTagViewer(c, db, groupID); restricted {
+		return hideRestrictedAnimals(query, userID)
+	}
+	return query
+}
+
+// GetUserQualifications lists qualifications in a group. Group admins and
+// site admins see every member's; other members see their own.
+// Route: GET /api/groups/:id/qualifications
+func GetUserQualifications(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Access denied")
+			return
+		}
+
+		query := db.Preload("AnimalTag").Preload("User", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "username", "first_name", "last_name")
+		}).Where("group_id = ?", groupID)
+		if uid, restricted := restrictedTagViewer(c, db, groupID); restricted {
+			query = query.Where("user_id = ?", uid)
+		}
+
+		qualifications := []models.UserQualification{}
+		if err := query.Order("user_id, animal_tag_id").Find(&qualifications).Error; err != nil {
+			respondInternalError(c, "Failed to fetch qualifications")
+			return
+		}
+		respondOK(c, qualifications)
+	}
+}
+
+// AssignUserQualifications replaces a member's qualifications in a group
+// (group admin or site admin). Every tag must be a restricted tag of the
+// group; an empty list removes them all.
+// Route: PUT /api/groups/:id/members/:userId/qualifications
+func AssignUserQualifications(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		groupID := c.Param("id")
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+
+		if !checkGroupAdminAccess(db, userID, isAdmin, groupID) {
+			respondError(c, http.StatusForbidden, ErrCodeAdminAccessRequired, "Only group admins can assign qualifications")
+			return
+		}
+		gid, err := strconv.ParseUint(groupID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+		targetID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid user ID")
+			return
+		}
+
+		var req AssignUserQualificationsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+
+		var membership models.UserGroup
+		if err := db.Where("user_id = ? AND group_id = ?", targetID, gid).First(&membership).Error; err != nil {
+			respondError(c, http.StatusNotFound, ErrCodeUserNotFound, "User is not a member of this group")
+			return
+		}
+
+		seen := make(map[uint]bool, len(req.AnimalTagIDs))
+		tagIDs := make([]uint, 0, len(req.AnimalTagIDs))
+		for _, id := range req.AnimalTagIDs {
+			if !seen[id] {
+				seen[id] = true
+				tagIDs = append(tagIDs, id)
+			}
+		}
+		if len(tagIDs) > 0 {
+			var count int64
+			if err := db.Model(&models.AnimalTag{}).
+				Where("id IN ? AND group_id = ? AND restricted = ?", tagIDs, gid, true).
+				Count(&count).Error; err != nil {
+				respondInternalError(c, "Failed to validate tags")
+				return
+			}
+			if int(count) != len(tagIDs) {
+				respondBadRequest(c, "Every tag must be a restricted animal tag of this group")
+				return
+			}
+		}
+
+		grantedBy, _ := middleware.GetUserID(c)
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("user_id = ? AND group_id = ?", targetID, gid).Delete(&models.UserQualification{}).Error; err != nil {
+				return err
+			}
+			if len(tagIDs) == 0 {
+				return nil
+			}
+			qualifications := make([]models.UserQualification, len(tagIDs))
+			for i, tagID := range tagIDs {
+				qualifications[i] = models.UserQualification{
+					GroupID:     uint(gid),
+					UserID:      uint(targetID),
+					AnimalTagID: tagID,
+					GrantedByID: grantedBy,
+				}
+			}
+			return tx.Create(&qualifications).Error
+		})
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to assign qualifications", err)
+			respondInternalError(c, "Failed to assign qualifications")
+			return
+		}
+
+		qualifications := []models.UserQualification{}
+		if err := db.Preload("AnimalTag").Where("user_id = ? AND group_id = ?", targetID, gid).
+			Order("animal_tag_id").Find(&qualifications).Error; err != nil {
+			respondInternalError(c, "Failed to fetch qualifications")
+			return
+		}
+		respondOK(c, qualifications)
+	}
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestrictedAnimalTags(t *testing.T) {
+	db := SetupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AnimalBQIncident{}, &models.AnimalImage{}, &models.AnimalVideo{}))
+	lead := CreateTestUser(t, db, "lead", "lead@example.com", "password123", false)
+	novice := CreateTestUser(t, db, "novice", "novice@example.com", "password123", false)
+	expert := CreateTestUser(t, db, "expert", "expert@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	AddUserToGroupWithAdmin(t, db, lead.ID, group.ID, true)
+	AddUserToGroupWithAdmin(t, db, novice.ID, group.ID, false)
+	AddUserToGroupWithAdmin(t, db, expert.ID, group.ID, false)
+	gid := fmt.Sprint(group.ID)
+
+	experienced := models.AnimalTag{GroupID: group.ID, Name: "experienced only", Category: "walker_status", Restricted: true}
+	friendly := models.AnimalTag{GroupID: group.ID, Name: "friendly", Category: "behavior"}
+	require.NoError(t, db.Create(&experienced).Error)
+	require.NoError(t, db.Create(&friendly).Error)
+	rex := CreateTestAnimal(t, db, group.ID, "Rex", "Dog")
+	buddy := CreateTestAnimal(t, db, group.ID, "Buddy", "Dog")
+	require.NoError(t, db.Model(rex).Association("Tags").Append(&experienced))
+	require.NoError(t, db.Model(buddy).Association("Tags").Append(&friendly))
+
+	listNames := func(userID uint) []string {
+		c, w := setupAnimalTestContext(userID, false)
+		c.Params = gin.Params{{Key: "id", Value: gid}}
+		c.Request = httptest.NewRequest(http.MethodGet, "/api/groups/"+gid+"/animals", nil)
+		GetAnimals(db)(c)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var animals []models.Animal
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &animals))
+		names := []string{}
+		for _, a := range animals {
+			names = append(names, a.Name)
+		}
+		return names
+	}
+	getRex := func(userID uint) int {
+		c, w := setupAnimalTestContext(userID, false)
+		c.Params = gin.Params{{Key: "id", Value: gid}, {Key: "animalId", Value: itoa(rex.ID)}}
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		GetAnimal(db)(c)
+		return w.Code
+	}
+	commentOnRex := func(userID uint) int {
+		c, w := accountTestContext(userID, false, http.MethodPost, "/", gin.H{"content": "Walked him today"})
+		c.Params = gin.Params{{Key: "id", Value: gid}, {Key: "animalId", Value: itoa(rex.ID)}}
+		CreateAnimalComment(db, nil)(c)
+		return w.Code
+	}
+
+	assert.ElementsMatch(t, []string{"Buddy"}, listNames(novice.ID))
+	assert.Equal(t, http.StatusNotFound, getRex(novice.ID))
+	assert.Equal(t, http.StatusNotFound, commentOnRex(novice.ID))
+	assert.ElementsMatch(t, []string{"Rex", "Buddy"}, listNames(lead.ID), "group admins see everything")
+
+	assign := func(userID, targetID uint, tagIDs ...uint) (int, []models.UserQualification) {
+		c, w := accountTestContext(userID, false, http.MethodPut, "/", gin.H{"animal_tag_ids": tagIDs})
+		c.Params = gin.Params{{Key: "id", Value: gid}, {Key: "userId", Value: itoa(targetID)}}
+		AssignUserQualifications(db)(c)
+		var qualifications []models.UserQualification
+		_ = json.Unmarshal(w.Body.Bytes(), &qualifications)
+		return w.Code, qualifications
+	}
+	code, _ := assign(novice.ID, expert.ID, experienced.ID)
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = assign(lead.ID, expert.ID, friendly.ID)
+	assert.Equal(t, http.StatusBadRequest, code, "only restricted tags are qualifications")
+	code, qualifications := assign(lead.ID, expert.ID, experienced.ID, experienced.ID)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, qualifications, 1)
+	assert.Equal(t, "experienced only", qualifications[0].AnimalTag.Name)
+	assert.Equal(t, lead.ID, qualifications[0].GrantedByID)
+
+	assert.ElementsMatch(t, []string{"Rex", "Buddy"}, listNames(expert.ID))
+	assert.Equal(t, http.StatusOK, getRex(expert.ID))
+	assert.Equal(t, http.StatusCreated, commentOnRex(expert.ID))
+
+	// Members only see their own qualifications
+	c, w := accountTestContext(novice.ID, false, http.MethodGet, "/", nil)
+	c.Params = gin.Params{{Key: "id", Value: gid}}
+	GetUserQualifications(db)(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, "[]", w.Body.String())
+
+	// Lifting the restriction makes the tag advisory again
+	require.NoError(t, db.Model(&experienced).Update("restricted", false).Error)
+	assert.ElementsMatch(t, []string{"Rex", "Buddy"}, listNames(novice.ID))
+
+	code, qualifications = assign(lead.ID, expert.ID)
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, qualifications)
+}
+
+func TestRestrictedAnimalTags_ReadPaths(t *testing.T) {
+	db := SetupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.AnimalImage{}, &models.AnimalVideo{}))
+	lead := CreateTestUser(t, db, "lead", "lead@example.com", "password123", false)
+	novice := CreateTestUser(t, db, "novice", "novice@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	AddUserToGroupWithAdmin(t, db, lead.ID, group.ID, true)
+	AddUserToGroupWithAdmin(t, db, novice.ID, group.ID, false)
+	gid := fmt.Sprint(group.ID)
+
+	experienced := models.AnimalTag{GroupID: group.ID, Name: "experienced only", Category: "walker_status", Restricted: true}
+	require.NoError(t, db.Create(&experienced).Error)
+	rex := CreateTestAnimal(t, db, group.ID, "Rex", "Dog")
+	buddy := CreateTestAnimal(t, db, group.ID, "Buddy", "Dog")
+	require.NoError(t, db.Model(rex).Association("Tags").Append(&experienced))
+	require.NoError(t, db.Create(&models.AnimalComment{AnimalID: rex.ID, UserID: lead.ID, Content: "Lunged at a bike"}).Error)
+	require.NoError(t, db.Create(&models.AnimalComment{AnimalID: buddy.ID, UserID: lead.ID, Content: "Sweet boy"}).Error)
+	require.NoError(t, db.Create(&models.AnimalChange{AnimalID: rex.ID, GroupID: group.ID, UserID: lead.ID, Source: models.AnimalChangeEdit,
+		Changes: models.AnimalFieldChanges{{Field: "status", Old: "available", New: "foster"}}}).Error)
+
+	call := func(handler gin.HandlerFunc, userID uint, animalID uint) (int, string) {
+		c, w := setupAnimalTestContext(userID, false)
+		c.Params = gin.Params{{Key: "id", Value: gid}, {Key: "animalId", Value: itoa(animalID)}}
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		handler(c)
+		return w.Code, w.Body.String()
+	}
+
+	t.Run("animal comments", func(t *testing.T) {
+		code, _ := call(GetAnimalComments(db), novice.ID, rex.ID)
+		assert.Equal(t, http.StatusNotFound, code)
+		code, body := call(GetAnimalComments(db), lead.ID, rex.ID)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Contains(t, body, "Lunged at a bike")
+	})
+
+	t.Run("latest group comments", func(t *testing.T) {
+		code, body := call(GetGroupLatestComments(db), novice.ID, 0)
+		require.Equal(t, http.StatusOK, code)
+		assert.Contains(t, body, "Sweet boy")
+		assert.NotContains(t, body, "Lunged at a bike")
+		_, body = call(GetGroupLatestComments(db), lead.ID, 0)
+		assert.Contains(t, body, "Lunged at a bike")
+	})
+
+	t.Run("activity feed", func(t *testing.T) {
+		code, body := call(GetGroupActivityFeed(db), novice.ID, 0)
+		require.Equal(t, http.StatusOK, code, body)
+		var feed struct {
+			Items []ActivityItem `json:"items"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(body), &feed))
+		require.Len(t, feed.Items, 1, "only Buddy's comment")
+		assert.Equal(t, "Sweet boy", feed.Items[0].Content)
+
+		_, body = call(GetGroupActivityFeed(db), lead.ID, 0)
+		require.NoError(t, json.Unmarshal([]byte(body), &feed))
+		assert.Len(t, feed.Items, 3)
+	})
+
+	t.Run("animal sub-resources", func(t *testing.T) {
+		handlers := map[string]gin.HandlerFunc{
+			"weights":   GetAnimalWeights(db),
+			"timeline":  GetAnimalTimeline(db),
+			"images":    GetAnimalImages(db),
+			"media":     GetAnimalMedia(db),
+			"checklist": GetAnimalChecklist(db),
+		}
+		for name, handler := range handlers {
+			code, body := call(handler, novice.ID, rex.ID)
+			assert.Equal(t, http.StatusNotFound, code, name)
+			code, body = call(handler, novice.ID, buddy.ID)
+			assert.Equal(t, http.StatusOK, code, "%s: %s", name, body)
+			code, body = call(handler, lead.ID, rex.ID)
+			assert.Equal(t, http.StatusOK, code, "%s: %s", name, body)
+		}
+	})
+
+	t.Run("compare", func(t *testing.T) {
+		compare := func(userID uint) int {
+			c, w := setupAnimalTestContext(userID, false)
+			c.Params = gin.Params{{Key: "id", Value: gid}}
+			c.Request = httptest.NewRequest(http.MethodGet, "/?ids="+itoa(rex.ID)+","+itoa(buddy.ID), nil)
+			CompareAnimals(db)(c)
+			return w.Code
+		}
+		assert.Equal(t, http.StatusNotFound, compare(novice.ID))
+		assert.Equal(t, http.StatusOK, compare(lead.ID))
+	})
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// usernameChangeCooldown is how long a user must wait between
+	// self-service username changes.
+	usernameChangeCooldown = 30 * 24 * time.Hour
+	// usernameReclaimWindow is how long a username someone gave up stays
+	// reserved for them, so a familiar handle can't be taken over by
+	// another volunteer right after it is released.
+	usernameReclaimWindow = 90 * 24 * time.Hour
+)
+
+// ErrUsernameRecentlyUsed is returned when a username was given up by
+// another user within usernameReclaimWindow.
+var ErrUsernameRecentlyUsed = errors.New("username was recently used by another account")
+
+// UsernameCooldownError is returned when the user changed their username
+// within usernameChangeCooldown.
+type UsernameCooldownError struct {
+	NextChangeAt time.Time
+}
+
+func (e *UsernameCooldownError) Error() string {
+	return fmt.Sprintf("username was changed recently; it can be changed again after %s", e.NextChangeAt.UTC().Format("2006-01-02"))
+}
+
+// ChangeUsernameRequest is the body of PUT /api/me/username.
+type ChangeUsernameRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=50,usernamechars"`
+}
+
+// validateUsernameAvailable checks that username is neither held by another
+// user nor one they gave up within usernameReclaimWindow. Users may always
+// take back their own old usernames.
+func validateUsernameAvailable(ctx context.Context, db *gorm.DB, username string, currentUserID uint, now time.Time) error {
+	if err := validateUsernameUniqueness(ctx, db, username, currentUserID); err != nil {
+		return err
+	}
+	return validateUsernameNotReleased(ctx, db, username, currentUserID, now)
+}
+
+// validateUsernameNotReleased returns ErrUsernameRecentlyUsed if a user other
+// than currentUserID gave up username within usernameReclaimWindow.
+func validateUsernameNotReleased(ctx context.Context, db *gorm.DB, username string, currentUserID uint, now time.Time) error {
+	var released int64
+	if err := db.WithContext(ctx).Model(&models.UsernameHistory{}).
+		Where("LOWER(old_username) = ? AND user_id != ? AND created_at > ?", strings.ToLower(username), currentUserID, now.Add(-usernameReclaimWindow)).
+		Count(&released).Error; err != nil {
+		return fmt.Errorf("database error checking username history: %w", err)
+	}
+	if released > 0 {
+		return ErrUsernameRecentlyUsed
+	}
+	return nil
+}
+
+// validateSelfUsernameChange checks that user may change their own username
+// to newUsername (already normalized) at now.
+func validateSelfUsernameChange(ctx context.Context, db *gorm.DB, user *models.User, newUsername string, now time.Time) error {
+	if user.UsernameChangedAt != nil {
+		if next := user.UsernameChangedAt.Add(usernameChangeCooldown); now.Before(next) {
+			return &UsernameCooldownError{NextChangeAt: next}
+		}
+	}
+	return validateUsernameAvailable(ctx, db, newUsername, user.ID, now)
+}
+
+// recordUsernameChange stores oldUsername in the user's username history.
+func recordUsernameChange(tx *gorm.DB, userID uint, oldUsername, newUsername string, changedBy uint) error {
+	return tx.Create(&models.UsernameHistory{
+		UserID:      userID,
+		OldUsername: oldUsername,
+		NewUsername: newUsername,
+		ChangedBy:   changedBy,
+	}).Error
+}
+
+// respondUsernameChangeError writes the response for an error returned by
+// validateSelfUsernameChange or validateUsernameAvailable.
+func respondUsernameChangeError(c *gin.Context, err error) {
+	var cooldown *UsernameCooldownError
+	switch {
+	case errors.As(err, &cooldown):
+		respondError(c, http.StatusConflict, ErrCodeConflict, cooldown.Error())
+	case errors.Is(err, ErrUsernameInUse), errors.Is(err, ErrUsernameRecentlyUsed):
+		respondError(c, http.StatusConflict, ErrCodeConflict, err.Error())
+	default:
+		middleware.GetLogger(c).Error("Failed to validate username", err)
+		respondInternalError(c, "Failed to validate username")
+	}
+}
+
+// ChangeCurrentUsername changes the current user's username. Changes are
+// limited to one per usernameChangeCooldown, and the old username is kept in
+// the user's history so nobody else can claim it for usernameReclaimWindow.
+// The response carries a fresh token whose claims hold the new username, or
+// a new CSRF token for a cookie session, whose cookie is renewed; tokens
+// issued earlier keep the old one until they are refreshed.
+// Route: PUT /api/me/username
+func ChangeCurrentUsername(db *gorm.DB) gin.HandlerFunc {
+	sessions := middleware.SessionConfigFromEnv()
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		db := middleware.GetDB(c, db)
+		userID, ok := middleware.GetUserID(c)
+		if !ok {
+			respondInternalError(c, "User context not found")
+			return
+		}
+
+		var req ChangeUsernameRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondValidationError(c, err)
+			return
+		}
+		newUsername := strings.ToLower(strings.TrimSpace(req.Username))
+
+		var user models.User
+		if err := db.First(&user, userID).Error; err != nil {
+			respondNotFound(c, "User not found")
+			return
+		}
+		if newUsername == strings.ToLower(user.Username) {
+			respondBadRequest(c, "That is already your username")
+			return
+		}
+
+		now := time.Now()
+		if err := validateSelfUsernameChange(ctx, db, &user, newUsername, now); err != nil {
+			respondUsernameChangeError(c, err)
+			return
+		}
+
+		oldUsername := user.Username
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := recordUsernameChange(tx, user.ID, oldUsername, newUsername, user.ID); err != nil {
+				return err
+			}
+			return tx.Model(&user).Updates(map[string]interface{}{
+				"username":            newUsername,
+				"username_changed_at": now,
+			}).Error
+		}); err != nil {
+			middleware.GetLogger(c).Error("Failed to change username", err)
+			respondInternalError(c, "Failed to change username")
+			return
+		}
+
+		logging.WithFields(map[string]interface{}{
+			"user_id":      user.ID,
+			"old_username": oldUsername,
+			"new_username": newUsername,
+		}).Info("User changed their username")
+
+		token, err := generateSessionToken(db, user.ID, newUsername, user.IsAdmin)
+		if err != nil {
+			respondInternalError(c, "Failed to generate token")
+			return
+		}
+		token, csrfToken, err := startSession(c, sessions, token, middleware.CookieSessionAuth(c))
+		if err != nil {
+			respondInternalError(c, "Failed to start session")
+			return
+		}
+		resp := gin.H{
+			"message":             "Username changed",
+			"username":            newUsername,
+			"username_changed_at": now,
+			"next_change_at":      now.Add(usernameChangeCooldown),
+			"token":               token,
+		}
+		if csrfToken != "" {
+			delete(resp, "token")
+			resp["csrf_token"] = csrfToken
+		}
+		respondOK(c, resp)
+	}
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/auth"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangeCurrentUsername(t *testing.T) {
+	t.Setenv("JWT_SECRET", "aB3dE5fG7hI9jK1lM3nO5pQ7rS9tU1vW3xY5zA7bC9dE1fG3hI5jK7lM9nO1pQ3")
+	db := SetupTestDB(t)
+	alice := CreateTestUser(t, db, "alice", "alice@example.com", "password123", false)
+	bob := CreateTestUser(t, db, "bob", "bob@example.com", "password123", false)
+
+	change := func(userID uint, username string) (int, map[string]interface{}) {
+		c, w := accountTestContext(userID, false, http.MethodPut, "/api/me/username", map[string]string{"username": username})
+		ChangeCurrentUsername(db)(c)
+		var body map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+		return w.Code, body
+	}
+
+	code, _ := change(alice.ID, "no spaces")
+	assert.Equal(t, http.StatusBadRequest, code, "usernamechars applies")
+	code, _ = change(alice.ID, "Alice")
+	assert.Equal(t, http.StatusBadRequest, code, "unchanged username")
+	code, _ = change(alice.ID, "BOB")
+	assert.Equal(t, http.StatusConflict, code, "taken case-insensitively")
+
+	code, body := change(alice.ID, "Alice.Walker")
+	require.Equal(t, http.StatusOK, code, body)
+	assert.Equal(t, "alice.walker", body["username"])
+
+	claims, err := auth.ValidateToken(body["token"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, claims.UserID)
+	assert.Equal(t, "alice.walker", claims.Username)
+
+	var updated models.User
+	require.NoError(t, db.First(&updated, alice.ID).Error)
+	assert.Equal(t, "alice.walker", updated.Username)
+	require.NotNil(t, updated.UsernameChangedAt)
+
+	var history []models.UsernameHistory
+	require.NoError(t, db.Where("user_id = ?", alice.ID).Find(&history).Error)
+	require.Len(t, history, 1)
+	assert.Equal(t, "alice", history[0].OldUsername)
+	assert.Equal(t, alice.ID, history[0].ChangedBy)
+
+	code, body = change(alice.ID, "alice2")
+	assert.Equal(t, http.StatusConflict, code, "second change within the cooldown")
+	assert.Contains(t, body["error"], "changed again after")
+
+	code, _ = change(bob.ID, "alice")
+	assert.Equal(t, http.StatusConflict, code, "a released username is reserved")
+
+	// Once the cooldown has passed, the owner can take back their old name
+	past := time.Now().Add(-usernameChangeCooldown - time.Hour)
+	require.NoError(t, db.Model(&updated).Update("username_changed_at", past).Error)
+	code, body = change(alice.ID, "alice")
+	require.Equal(t, http.StatusOK, code, body)
+
+	// After the reclaim window, another user can claim it too
+	require.NoError(t, db.Model(&models.UsernameHistory{}).Where("user_id = ?", alice.ID).
+		Update("created_at", time.Now().Add(-usernameReclaimWindow-time.Hour)).Error)
+	code, body = change(bob.ID, "alice.walker")
+	assert.Equal(t, http.StatusOK, code, body)
+}
+
+func TestUpdateCurrentUserProfile_UsernameCooldown(t *testing.T) {
+	db := SetupTestDB(t)
+	user := CreateTestUser(t, db, "carol", "carol@example.com", "password123", false)
+	recent := time.Now().Add(-24 * time.Hour)
+	require.NoError(t, db.Model(user).Update("username_changed_at", recent).Error)
+
+	update := func(username string) int {
+		c, w := accountTestContext(user.ID, false, http.MethodPut, "/api/me/profile", map[string]string{"username": username, "email": "carol@example.com"})
+		UpdateCurrentUserProfile(db)(c)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, update("carol"), "an unchanged username is not a change")
+	assert.Equal(t, http.StatusConflict, update("carol2"))
+
+	require.NoError(t, db.Model(user).Update("username_changed_at", nil).Error)
+	require.Equal(t, http.StatusOK, update("carol2"))
+	var history int64
+	db.Model(&models.UsernameHistory{}).Where("user_id = ? AND old_username = ?", user.ID, "carol").Count(&history)
+	assert.Equal(t, int64(1), history)
+}
+
+func TestRefreshToken(t *testing.T) {
+	t.Setenv("JWT_SECRET", "aB3dE5fG7hI9jK1lM3nO5pQ7rS9tU1vW3xY5zA7bC9dE1fG3hI5jK7lM9nO1pQ3")
+	db := SetupTestDB(t)
+	user := CreateTestUser(t, db, "dave", "dave@example.com", "password123", false)
+	require.NoError(t, db.Model(user).Updates(map[string]interface{}{"username": "david", "is_admin": true}).Error)
+
+	c, w := accountTestContext(user.ID, false, http.MethodPost, "/api/refresh", nil)
+	RefreshToken(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var body struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	claims, err := auth.ValidateToken(body.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "david", claims.Username)
+	assert.True(t, claims.IsAdmin, "claims are re-read from the database")
+
+	c, w = accountTestContext(user.ID, false, http.MethodPost, "/api/refresh", nil)
+	c.Set("api_token_auth", true)
+	RefreshToken(db)(c)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestRefreshTokenCarriesGroups(t *testing.T) {
+	t.Setenv("JWT_SECRET", "aB3dE5fG7hI9jK1lM3nO5pQ7rS9tU1vW3xY5zA7bC9dE1fG3hI5jK7lM9nO1pQ3")
+	db := SetupTestDB(t)
+	user := CreateTestUser(t, db, "erin", "erin@example.com", "password123", false)
+	dogs := CreateTestGroup(t, db, "Dogs", "Dog group")
+	cats := CreateTestGroup(t, db, "Cats", "Cat group")
+	gone := CreateTestGroup(t, db, "Gone", "Deleted group")
+	AddUserToGroupWithAdmin(t, db, user.ID, dogs.ID, false)
+	AddUserToGroupWithAdmin(t, db, user.ID, cats.ID, true)
+	AddUserToGroupWithAdmin(t, db, user.ID, gone.ID, false)
+	require.NoError(t, db.Delete(gone).Error)
+
+	c, w := accountTestContext(user.ID, false, http.MethodPost, "/api/refresh", nil)
+	RefreshToken(db)(c)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var body struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	claims, err := auth.ValidateToken(body.Token)
+	require.NoError(t, err)
+	require.NotNil(t, claims.Groups)
+	assert.Equal(t, []uint{dogs.ID, cats.ID}, claims.Groups.GroupIDs)
+	assert.Equal(t, []uint{cats.ID}, claims.Groups.AdminGroupIDs)
+}
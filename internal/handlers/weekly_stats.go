@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobWeeklyStatsEmail is the background job type that sends a group admin
+// their group's weekly statistics.
+const JobWeeklyStatsEmail = "weekly_stats_email"
+
+const (
+	// weeklyStatsSendHour is the hour on Monday, in the group's time zone,
+	// after which the previous week's emails go out
+	weeklyStatsSendHour = 7
+	// weeklyStatsTopVolunteers is how many of the most active volunteers a
+	// report lists
+	weeklyStatsTopVolunteers = 5
+	// weeklyStatsStopTimeout bounds how long stop() waits for an in-flight
+	// tick to finish, matching the announcement scheduler's shutdown wait.
+	weeklyStatsStopTimeout = 10 * time.Second
+)
+
+// weeklyStatsEmailJob is the payload of a JobWeeklyStatsEmail job
+type weeklyStatsEmailJob struct {
+	GroupID   uint      `json:"group_id"`
+	UserID    uint      `json:"user_id"`
+	WeekStart time.Time `json:"week_start"`
+}
+
+// WeeklyVolunteer is one of the most active volunteers of the week
+type WeeklyVolunteer struct {
+	UserID       uint   `json:"user_id"`
+	Username     string `json:"username"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	CommentCount int64  `json:"comment_count"`
+}
+
+// InactiveAnimal is an animal in care nobody commented on during the week.
+// LastCommentAt is its latest comment before the week ended, if any.
+type InactiveAnimal struct {
+	ID            uint       `json:"id"`
+	Name          string     `json:"name"`
+	Status        string     `json:"status"`
+	LastCommentAt *time.Time `json:"last_comment_at"`
+}
+
+// GroupWeeklyStats summarizes a group's week, from Monday WeekStart up to
+// (not including) the next Monday, WeekEnd. Deleted comments don't count.
+type GroupWeeklyStats struct {
+	GroupID         uint              `json:"group_id"`
+	GroupName       string            `json:"group_name"`
+	WeekStart       time.Time         `json:"week_start"`
+	WeekEnd         time.Time         `json:"week_end"`
+	NewAnimals      int64             `json:"new_animals"`
+	Adoptions       int64             `json:"adoptions"`
+	Comments        int64             `json:"comments"`
+	TopVolunteers   []WeeklyVolunteer `json:"top_volunteers"`
+	InactiveAnimals []InactiveAnimal  `json:"inactive_animals"`
+}
+
+// weekStartOf returns midnight on the Monday starting t's week, in t's
+// location
+func weekStartOf(t time.Time) time.Time {
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	y, m, d := t.AddDate(0, 0, -daysSinceMonday).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// exitStatuses lists the statuses of animals that have left care
+func exitStatuses() []string {
+	statuses := []string{"archived"}
+	for status := range statusOutcomes {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	return statuses
+}
+
+// buildGroupWeeklyStats gathers group's statistics for the week starting
+// weekStart
+func buildGroupWeeklyStats(db *gorm.DB, group models.Group, weekStart time.Time) (GroupWeeklyStats, error) {
+	weekEnd := weekStart.AddDate(0, 0, 7)
+	stats := GroupWeeklyStats{
+		GroupID:         group.ID,
+		GroupName:       group.Name,
+		WeekStart:       weekStart,
+		WeekEnd:         weekEnd,
+		TopVolunteers:   []WeeklyVolunteer{},
+		InactiveAnimals: []InactiveAnimal{},
+	}
+	groupAnimals := db.Model(&models.Animal{}).Select("id").Where("group_id = ?", group.ID)
+
+	for _, query := range []func() error{
+		func() error {
+			return db.Model(&models.Animal{}).
+				Where("group_id = ? AND created_at >= ? AND created_at < ?", group.ID, weekStart, weekEnd).
+				Count(&stats.NewAnimals).Error
+		},
+		func() error {
+			return db.Model(&models.Animal{}).
+				Where("group_id = ? AND outcome = ? AND outcome_date >= ? AND outcome_date < ?", group.ID, models.OutcomeAdopted, weekStart, weekEnd).
+				Count(&stats.Adoptions).Error
+		},
+		func() error {
+			return db.Model(&models.AnimalComment{}).
+				Where("animal_id IN (?) AND created_at >= ? AND created_at < ?", groupAnimals, weekStart, weekEnd).
+				Count(&stats.Comments).Error
+		},
+		func() error {
+			return db.Table("animal_comments ac").
+				Select("ac.user_id, u.username, u.first_name, u.last_name, COUNT(*) AS comment_count").
+				Joins("JOIN users u ON u.id = ac.user_id").
+				Where("ac.deleted_at IS NULL AND ac.animal_id IN (?) AND ac.created_at >= ? AND ac.created_at < ?", groupAnimals, weekStart, weekEnd).
+				Group("ac.user_id, u.username, u.first_name, u.last_name").
+				Order("comment_count DESC, u.username ASC").
+				Limit(weeklyStatsTopVolunteers).
+				Scan(&stats.TopVolunteers).Error
+		},
+		func() error {
+			// MAX(created_at) comes back from SQLite as text; see parseTimestamp
+			var rows []struct {
+				ID            uint
+				Name          string
+				Status        string
+				LastCommentAt *string
+			}
+			if err := db.Table("animals a").
+				Select("a.id, a.name, a.status, (SELECT MAX(ac.created_at) FROM animal_comments ac "+
+					"WHERE ac.animal_id = a.id AND ac.deleted_at IS NULL AND ac.created_at < ?) AS last_comment_at", weekEnd).
+				Where("a.group_id = ? AND a.deleted_at IS NULL AND a.created_at < ? AND a.status NOT IN ?", group.ID, weekEnd, exitStatuses()).
+				Where("NOT EXISTS (SELECT 1 FROM animal_comments ac WHERE ac.animal_id = a.id AND ac.deleted_at IS NULL "+
+					"AND ac.created_at >= ? AND ac.created_at < ?)", weekStart, weekEnd).
+				Order("a.name ASC, a.id ASC").
+				Scan(&rows).Error; err != nil {
+				return err
+			}
+			for _, row := range rows {
+				animal := InactiveAnimal{ID: row.ID, Name: row.Name, Status: row.Status}
+				if row.LastCommentAt != nil {
+					animal.LastCommentAt = parseTimestamp(*row.LastCommentAt)
+				}
+				stats.InactiveAnimals = append(stats.InactiveAnimals, animal)
+			}
+			return nil
+		},
+	} {
+		if err := query(); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// GetGroupWeeklyStats returns a group's weekly statistics, the same ones
+// the Monday email sends (group admin or site admin). ?week_start=YYYY-MM-DD
+// picks the week containing that date; by default it's the last full week.
+// Weeks run Monday to Monday in the group's time zone.
+// Route: GET /api/groups/:id/weekly-stats
+func GetGroupWeeklyStats(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := middleware.GetDB(c, db)
+		userID, _ := c.Get("user_id")
+		isAdmin, _ := c.Get("is_admin")
+		groupID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, ErrCodeInvalidID, "Invalid group ID")
+			return
+		}
+		if !checkGroupAdminAccess(db, userID, isAdmin, c.Param("id")) {
+			respondError(c, http.StatusForbidden, ErrCodeGroupAccessDenied, "Only group admins can view weekly statistics")
+			return
+		}
+
+		var group models.Group
+		if err := db.First(&group, groupID).Error; err != nil {
+			respondNotFound(c, "Group not found")
+			return
+		}
+
+		loc := groupLocation(group)
+		weekStart := weekStartOf(time.Now().In(loc)).AddDate(0, 0, -7)
+		if v := c.Query("week_start"); v != "" {
+			date, err := time.ParseInLocation("2006-01-02", v, loc)
+			if err != nil {
+				respondBadRequest(c, "week_start must be a date in YYYY-MM-DD format")
+				return
+			}
+			weekStart = weekStartOf(date)
+		}
+		stats, err := buildGroupWeeklyStats(db, group, weekStart)
+		if err != nil {
+			middleware.GetLogger(c).Error("Failed to build weekly statistics", err)
+			respondInternalError(c, "Failed to build weekly statistics")
+			return
+		}
+		respondOK(c, stats)
+	}
+}
+
+// StartWeeklyStatsScheduler periodically queues the weekly statistics
+// emails once the week has turned over. Returns a stop function; call it
+// during graceful shutdown, before closing the database.
+func StartWeeklyStatsScheduler(db *gorm.DB, interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		for {
+			select {
+			case <-ticker.C:
+				queueWeeklyStatsEmails(context.Background(), db, time.Now())
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		select {
+		case <-finished:
+		case <-time.After(weeklyStatsStopTimeout):
+			logging.Warn(fmt.Sprintf("Weekly stats scheduler did not stop within %s of shutdown signal; proceeding with shutdown anyway", weeklyStatsStopTimeout))
+		}
+	}
+}
+
+// queueWeeklyStatsEmails queues last week's email to each group admin who
+// opted in, once it's past weeklyStatsSendHour on Monday in the group's time
+// zone, and returns how many groups it queued for. Each group's week is
+// claimed with a WeeklyStatsReport row, so when several replicas run the
+// scheduler, or it runs again later in the week, a report only goes out
+// once. Claims are by calendar date, so changing a group's time zone
+// doesn't send a week twice.
+func queueWeeklyStatsEmails(ctx context.Context, db *gorm.DB, now time.Time) int {
+	logger := logging.WithContext(ctx)
+	db = db.WithContext(ctx)
+
+	var groups []models.Group
+	if err := db.Select("id", "time_zone").Order("id").Find(&groups).Error; err != nil {
+		logger.Error("Failed to list groups for weekly statistics", err)
+		return 0
+	}
+
+	queued := 0
+	for _, group := range groups {
+		groupID := group.ID
+		local := now.In(groupLocation(group))
+		thisWeek := weekStartOf(local)
+		if local.Before(thisWeek.Add(weeklyStatsSendHour * time.Hour)) {
+			continue
+		}
+		weekStart := thisWeek.AddDate(0, 0, -7)
+		y, m, d := weekStart.Date()
+		claimDate := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			claim := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&models.WeeklyStatsReport{GroupID: groupID, WeekStart: claimDate})
+			if claim.Error != nil || claim.RowsAffected == 0 {
+				return claim.Error // Another replica claimed it
+			}
+
+			var adminIDs []uint
+			if err := notifiableUsers(tx.Model(&models.User{})).
+				Joins("JOIN user_groups ON user_groups.user_id = users.id").
+				Where("user_groups.group_id = ? AND user_groups.is_group_admin = ?", groupID, true).
+				Where("users.weekly_stats_emails_enabled = ?", true).
+				Pluck("users.id", &adminIDs).Error; err != nil {
+				return err
+			}
+			payloads := make([]interface{}, len(adminIDs))
+			for i, id := range adminIDs {
+				payloads[i] = weeklyStatsEmailJob{GroupID: groupID, UserID: id, WeekStart: weekStart}
+			}
+			if err := jobs.EnqueueMany(tx, JobWeeklyStatsEmail, payloads); err != nil {
+				return err
+			}
+			if len(adminIDs) > 0 {
+				queued++
+			}
+			return nil
+		})
+		if err != nil {
+			logger.WithField("group_id", groupID).Error("Failed to queue weekly statistics emails", err)
+		}
+	}
+	return queued
+}
+
+// weeklyStatsEmailJobHandler sends a JobWeeklyStatsEmail. Nothing is sent
+// if the group or recipient is gone, the recipient is no longer one of the
+// group's admins, or they have since turned the emails off.
+func weeklyStatsEmailJobHandler(db *gorm.DB, emailService *email.Service) jobs.Handler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var job weeklyStatsEmailJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return jobs.Permanent(err)
+		}
+		if emailService == nil || !emailService.IsConfigured() {
+			return errors.New("email service is not configured")
+		}
+		db := db.WithContext(ctx)
+
+		var group models.Group
+		var recipient models.User
+		for _, load := range []func() error{
+			func() error { return db.First(&group, job.GroupID).Error },
+			func() error {
+				return notifiableUsers(db).Where("weekly_stats_emails_enabled = ?", true).First(&recipient, job.UserID).Error
+			},
+			func() error {
+				return db.Where("user_id = ? AND group_id = ? AND is_group_admin = ?", job.UserID, job.GroupID, true).
+					First(&models.UserGroup{}).Error
+			},
+		} {
+			if err := load(); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil
+				}
+				return err
+			}
+		}
+
+		// The payload decodes with a fixed offset; use the group's zone, as the scheduler did
+		stats, err := buildGroupWeeklyStats(db, group, job.WeekStart.In(groupLocation(group)))
+		if err != nil {
+			return err
+		}
+		summary := email.WeeklyStatsSummary{
+			GroupName:  stats.GroupName,
+			WeekStart:  stats.WeekStart,
+			WeekEnd:    stats.WeekEnd,
+			NewAnimals: stats.NewAnimals,
+			Adoptions:  stats.Adoptions,
+			Comments:   stats.Comments,
+		}
+		for _, v := range stats.TopVolunteers {
+			summary.TopVolunteers = append(summary.TopVolunteers, email.WeeklyStatsVolunteer{Name: v.Username, Comments: v.CommentCount})
+		}
+		for _, a := range stats.InactiveAnimals {
+			summary.InactiveAnimals = append(summary.InactiveAnimals, a.Name)
+		}
+
+		link := fmt.Sprintf("%s/groups/%d", frontendURL(), group.ID)
+		ctx = withGroupSender(ctx, group)
+		return emailService.SendWeeklyStatsEmail(ctx, recipient.Email, summary, link)
+	}
+}
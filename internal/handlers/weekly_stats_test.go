@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/email"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/jobs"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeekStartOf(t *testing.T) {
+	monday := time.Date(2026, 10, 5, 0, 0, 0, 0, time.Local)
+	for _, day := range []time.Time{
+		monday,
+		time.Date(2026, 10, 7, 15, 30, 0, 0, time.Local),
+		time.Date(2026, 10, 11, 23, 59, 0, 0, time.Local),
+	} {
+		assert.Equal(t, monday, weekStartOf(day), day.Weekday().String())
+	}
+}
+
+func TestGroupWeeklyStats(t *testing.T) {
+	db := SetupTestDB(t)
+	lead := CreateTestUser(t, db, "lead", "lead@example.com", "password123", false)
+	quiet := CreateTestUser(t, db, "quiet", "quiet@example.com", "password123", false)
+	alice := CreateTestUser(t, db, "alice", "alice@example.com", "password123", false)
+	bob := CreateTestUser(t, db, "bob", "bob@example.com", "password123", false)
+	group := CreateTestGroup(t, db, "Dogs", "")
+	AddUserToGroupWithAdmin(t, db, lead.ID, group.ID, true)
+	AddUserToGroupWithAdmin(t, db, quiet.ID, group.ID, true)
+	AddUserToGroupWithAdmin(t, db, alice.ID, group.ID, false)
+	AddUserToGroupWithAdmin(t, db, bob.ID, group.ID, false)
+	require.NoError(t, db.Model(&models.User{}).Where("id IN ?", []uint{lead.ID, quiet.ID}).
+		Update("email_notifications_enabled", true).Error)
+	require.NoError(t, db.Model(lead).Update("weekly_stats_emails_enabled", true).Error)
+
+	day := func(d, hour int) time.Time { return time.Date(2026, 10, d, hour, 0, 0, 0, time.Local) }
+	lastMonth := time.Date(2026, 9, 1, 12, 0, 0, 0, time.Local)
+	adoptedOn := day(7, 12)
+	animal := func(name, status string, createdAt time.Time) models.Animal {
+		a := models.Animal{GroupID: group.ID, Name: name, Species: "Dog", Status: status, CreatedAt: createdAt}
+		if status == "adopted" {
+			a.Outcome, a.OutcomeDate = models.OutcomeAdopted, &adoptedOn
+		}
+		require.NoError(t, db.Create(&a).Error)
+		return a
+	}
+	rex := animal("Rex", "available", lastMonth)
+	buddy := animal("Buddy", "available", day(6, 9))
+	animal("Max", "adopted", lastMonth)
+	luna := animal("Luna", "foster", lastMonth)
+	animal("Old Timer", "archived", lastMonth)
+
+	for _, comment := range []struct {
+		animalID, userID uint
+		at               time.Time
+	}{
+		{rex.ID, alice.ID, day(6, 10)},
+		{rex.ID, alice.ID, day(9, 10)},
+		{buddy.ID, bob.ID, day(8, 10)},
+		{luna.ID, bob.ID, time.Date(2026, 9, 30, 10, 0, 0, 0, time.Local)},
+		{luna.ID, alice.ID, day(13, 10)}, // The following week
+	} {
+		require.NoError(t, db.Create(&models.AnimalComment{
+			AnimalID: comment.animalID, UserID: comment.userID, Content: "Walked", CreatedAt: comment.at,
+		}).Error)
+	}
+
+	get := func(userID uint) (int, GroupWeeklyStats) {
+		c, w := accountTestContext(userID, false, http.MethodGet, "/?week_start=2026-10-08", nil)
+		c.Params = gin.Params{{Key: "id", Value: itoa(group.ID)}}
+		GetGroupWeeklyStats(db)(c)
+		var stats GroupWeeklyStats
+		_ = json.Unmarshal(w.Body.Bytes(), &stats)
+		return w.Code, stats
+	}
+	code, _ := get(alice.ID)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, stats := get(lead.ID)
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, day(5, 0).Equal(stats.WeekStart), "weeks start on Monday")
+	assert.True(t, day(12, 0).Equal(stats.WeekEnd))
+	assert.Equal(t, int64(1), stats.NewAnimals)
+	assert.Equal(t, int64(1), stats.Adoptions)
+	assert.Equal(t, int64(3), stats.Comments)
+	require.Len(t, stats.TopVolunteers, 2)
+	assert.Equal(t, "alice", stats.TopVolunteers[0].Username)
+	assert.Equal(t, int64(2), stats.TopVolunteers[0].CommentCount)
+	require.Len(t, stats.InactiveAnimals, 1, "animals that left care don't count")
+	assert.Equal(t, "Luna", stats.InactiveAnimals[0].Name)
+	require.NotNil(t, stats.InactiveAnimals[0].LastCommentAt)
+	assert.Equal(t, 30, stats.InactiveAnimals[0].LastCommentAt.In(time.Local).Day())
+
+	// The scheduler waits for Monday morning, then queues each week once
+	ctx := context.Background()
+	assert.Equal(t, 0, queueWeeklyStatsEmails(ctx, db, day(12, weeklyStatsSendHour-1)))
+	assert.Equal(t, 1, queueWeeklyStatsEmails(ctx, db, day(12, weeklyStatsSendHour)))
+	assert.Equal(t, 0, queueWeeklyStatsEmails(ctx, db, day(14, 9)))
+
+	provider := &recordingEmailProvider{}
+	queue := jobs.NewQueue(db)
+	RegisterJobHandlers(queue, db, email.NewServiceWithProvider(provider, db), nil)
+	assert.Equal(t, 1, queue.RunDue(ctx))
+	assert.Equal(t, []string{"lead@example.com"}, provider.sentTo, "only admins who opted in")
+}
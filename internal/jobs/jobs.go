@@ -0,0 +1,431 @@
+// Package jobs runs background work, such as email delivery, outside the
+// request that triggered it. Jobs are stored in the jobs table, so they
+// survive restarts and can be picked up by any replica. Failed attempts are
+// retried with exponential backoff.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultMaxAttempts is how many times a job runs before it is marked
+	// failed.
+	DefaultMaxAttempts = 5
+
+	defaultWorkerCount = 4
+
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = time.Hour
+
+	// attemptTimeout bounds a single run of a job handler.
+	attemptTimeout = 5 * time.Minute
+
+	// staleAfter is how long a job can stay running before it is assumed
+	// that its worker died (e.g. the replica was killed) and the job is
+	// claimed again.
+	staleAfter = 3 * attemptTimeout
+
+	// completedRetention is how long succeeded jobs are kept for inspection.
+	completedRetention = 7 * 24 * time.Hour
+
+	// stopTimeout bounds how long stop() waits for in-flight jobs during
+	// shutdown.
+	stopTimeout = 10 * time.Second
+
+	// enqueueBatchSize caps the rows per INSERT in EnqueueMany.
+	enqueueBatchSize = 500
+)
+
+// Errors returned by Requeue
+var (
+	ErrJobNotFound  = errors.New("job not found")
+	ErrJobNotFailed = errors.New("only failed jobs can be requeued")
+)
+
+// Handler runs one job with its JSON payload. Returning an error retries the
+// job, unless the error is wrapped with Permanent.
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying, e.g. a malformed payload. The
+// job is marked failed immediately.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// WorkerCount returns the number of job workers to start, from JOB_WORKERS
+// (default 4).
+func WorkerCount() int {
+	if v := os.Getenv("JOB_WORKERS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 1 {
+			return n
+		}
+		logging.WithField("value", v).Warn("Invalid JOB_WORKERS, using default")
+	}
+	return defaultWorkerCount
+}
+
+// Backoff returns the delay before retrying a job that has failed attempts
+// times: 30s, 1m, 2m, 4m, ... up to an hour.
+func Backoff(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return delay
+}
+
+// Enqueue stores a job of jobType to run as soon as a worker is free. Pass
+// a transaction as db to enqueue the job only if the transaction commits.
+func Enqueue(db *gorm.DB, jobType string, payload interface{}) (*models.Job, error) {
+	jobs, err := newJobs(jobType, []interface{}{payload})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Create(&jobs[0]).Error; err != nil {
+		return nil, err
+	}
+	return &jobs[0], nil
+}
+
+// EnqueueMany stores one job of jobType per payload.
+func EnqueueMany(db *gorm.DB, jobType string, payloads []interface{}) error {
+	if len(payloads) == 0 {
+		return nil
+	}
+	jobs, err := newJobs(jobType, payloads)
+	if err != nil {
+		return err
+	}
+	return db.CreateInBatches(&jobs, enqueueBatchSize).Error
+}
+
+func newJobs(jobType string, payloads []interface{}) ([]models.Job, error) {
+	now := time.Now()
+	jobs := make([]models.Job, len(payloads))
+	for i, payload := range payloads {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s job payload: %w", jobType, err)
+		}
+		jobs[i] = models.Job{
+			Type:        jobType,
+			Payload:     string(data),
+			Status:      models.JobStatusPending,
+			RunAt:       now,
+			MaxAttempts: DefaultMaxAttempts,
+		}
+	}
+	return jobs, nil
+}
+
+// Requeue resets a failed job so it runs again with a fresh set of attempts.
+func Requeue(db *gorm.DB, id uint) (*models.Job, error) {
+	var job models.Job
+	if err := db.First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	if job.Status != models.JobStatusFailed {
+		return nil, ErrJobNotFailed
+	}
+	result := db.Model(&models.Job{}).
+		Where("id = ? AND status = ?", id, models.JobStatusFailed).
+		Updates(map[string]interface{}{
+			"status":       models.JobStatusPending,
+			"attempts":     0,
+			"run_at":       time.Now(),
+			"locked_at":    nil,
+			"completed_at": nil,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrJobNotFailed
+	}
+	if err := db.First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Queue runs stored jobs with the handlers registered for their types. Jobs
+// of types with no registered handler are left pending, so a replica
+// running an older release doesn't fail jobs it doesn't know about.
+type Queue struct {
+	db        *gorm.DB
+	mu        sync.RWMutex
+	handlers  map[string]Handler
+	onFailure FailureHook
+}
+
+// FailureHook is called after a job is marked failed, with the error from
+// its last attempt.
+type FailureHook func(ctx context.Context, job models.Job, err error)
+
+// NewQueue returns a queue that reads jobs from db.
+func NewQueue(db *gorm.DB) *Queue {
+	return &Queue{db: db, handlers: make(map[string]Handler)}
+}
+
+// Register sets the handler for jobType. Call it before Start.
+func (q *Queue) Register(jobType string, handler Handler) {
+	q.mu.Lock()
+	q.handlers[jobType] = handler
+	q.mu.Unlock()
+}
+
+// OnFailure sets a hook that runs whenever a job is marked failed, e.g. to
+// alert operators. Call it before Start.
+func (q *Queue) OnFailure(hook FailureHook) {
+	q.mu.Lock()
+	q.onFailure = hook
+	q.mu.Unlock()
+}
+
+func (q *Queue) handler(jobType string) (Handler, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	h, ok := q.handlers[jobType]
+	return h, ok
+}
+
+func (q *Queue) types() []string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	types := make([]string, 0, len(q.handlers))
+	for t := range q.handlers {
+		types = append(types, t)
+	}
+	return types
+}
+
+// Start runs workers goroutines that poll for due jobs every pollInterval
+// while idle, and purges old succeeded jobs hourly. Returns a stop function;
+// call it during graceful shutdown, before closing the database.
+func (q *Queue) Start(workers int, pollInterval time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if q.RunNext(ctx) {
+					select {
+					case <-done:
+						return
+					default:
+						continue
+					}
+				}
+				select {
+				case <-time.After(pollInterval):
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := PurgeSucceeded(q.db, completedRetention); err != nil {
+					logging.Error("Failed to purge succeeded jobs", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		select {
+		case <-finished:
+		case <-time.After(stopTimeout):
+			logging.Warn(fmt.Sprintf("Job workers did not stop within %s of shutdown signal; proceeding with shutdown anyway", stopTimeout))
+		}
+		// Jobs interrupted here are retried once they go stale
+		cancel()
+	}
+}
+
+// RunDue runs jobs until none are due and returns how many it ran.
+func (q *Queue) RunDue(ctx context.Context) int {
+	n := 0
+	for q.RunNext(ctx) {
+		n++
+	}
+	return n
+}
+
+// RunNext claims and runs one due job, reporting whether there was one.
+func (q *Queue) RunNext(ctx context.Context) bool {
+	job, err := q.claim(ctx, time.Now())
+	if err != nil {
+		logging.Error("Failed to claim job", err)
+		return false
+	}
+	if job == nil {
+		return false
+	}
+	q.run(ctx, job)
+	return true
+}
+
+// claim marks the next due job as running and returns it, or nil when no
+// job is due. Running jobs whose worker has gone stale are claimed again.
+// The update is conditional on the attempt count read, so when several
+// workers or replicas race for a job only one wins.
+func (q *Queue) claim(ctx context.Context, now time.Time) (*models.Job, error) {
+	types := q.types()
+	if len(types) == 0 {
+		return nil, nil
+	}
+	db := q.db.WithContext(ctx)
+	for {
+		var job models.Job
+		err := db.Where("type IN ?", types).
+			Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_at < ?)",
+				models.JobStatusPending, now, models.JobStatusRunning, now.Add(-staleAfter)).
+			Order("run_at ASC, id ASC").
+			First(&job).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		result := db.Model(&models.Job{}).
+			Where("id = ? AND status = ? AND attempts = ?", job.ID, job.Status, job.Attempts).
+			Updates(map[string]interface{}{
+				"status":    models.JobStatusRunning,
+				"attempts":  job.Attempts + 1,
+				"locked_at": now,
+			})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue // Another worker claimed it
+		}
+		job.Status = models.JobStatusRunning
+		job.Attempts++
+		job.LockedAt = &now
+		return &job, nil
+	}
+}
+
+// run executes a claimed job and records the outcome.
+func (q *Queue) run(ctx context.Context, job *models.Job) {
+	logger := logging.WithContext(ctx).WithFields(map[string]interface{}{
+		"job_id":   job.ID,
+		"job_type": job.Type,
+		"attempt":  job.Attempts,
+	})
+
+	var err error
+	if job.Attempts > job.MaxAttempts {
+		// Only reachable by reclaiming a job whose last attempt went stale
+		err = Permanent(errors.New("job did not finish before its worker stopped"))
+	} else if handler, ok := q.handler(job.Type); ok {
+		err = runHandler(ctx, handler, json.RawMessage(job.Payload))
+	} else {
+		err = fmt.Errorf("no handler registered for job type %q", job.Type)
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"locked_at": nil}
+	var permanent permanentError
+	switch {
+	case err == nil:
+		updates["status"] = models.JobStatusSucceeded
+		updates["last_error"] = ""
+		updates["completed_at"] = now
+	case errors.As(err, &permanent) || job.Attempts >= job.MaxAttempts:
+		updates["status"] = models.JobStatusFailed
+		updates["last_error"] = err.Error()
+		updates["completed_at"] = now
+		logger.Error("Job failed", err)
+	default:
+		updates["status"] = models.JobStatusPending
+		updates["last_error"] = err.Error()
+		updates["run_at"] = now.Add(Backoff(job.Attempts))
+		logger.WithField("error", err.Error()).Warn("Job attempt failed, will retry")
+	}
+
+	// Record the outcome even if ctx was cancelled by shutdown mid-attempt
+	if err := q.db.Model(&models.Job{}).Where("id = ?", job.ID).Updates(updates).Error; err != nil {
+		logger.Error("Failed to record job result", err)
+	}
+
+	if updates["status"] == models.JobStatusFailed {
+		q.mu.RLock()
+		hook := q.onFailure
+		q.mu.RUnlock()
+		if hook != nil {
+			hook(context.WithoutCancel(ctx), *job, err)
+		}
+	}
+}
+
+// runHandler runs handler with a per-attempt timeout, turning a panic into
+// an error so one bad job can't take down a worker.
+func runHandler(ctx context.Context, handler Handler, payload json.RawMessage) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, attemptTimeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(ctx, payload)
+}
+
+// PurgeSucceeded deletes succeeded jobs that completed more than olderThan
+// ago, returning how many were deleted. Failed jobs are kept until an admin
+// requeues them.
+func PurgeSucceeded(db *gorm.DB, olderThan time.Duration) (int64, error) {
+	result := db.Where("status = ? AND completed_at < ?", models.JobStatusSucceeded, time.Now().Add(-olderThan)).
+		Delete(&models.Job{})
+	return result.RowsAffected, result.Error
+}
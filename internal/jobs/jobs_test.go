@@ -0,0 +1,201 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newJobsDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(&models.Job{}); err != nil {
+		t.Fatalf("failed to migrate test db: %v", err)
+	}
+	return db
+}
+
+func reloadJob(t *testing.T, db *gorm.DB, id uint) models.Job {
+	t.Helper()
+	var job models.Job
+	if err := db.First(&job, id).Error; err != nil {
+		t.Fatalf("failed to reload job %d: %v", id, err)
+	}
+	return job
+}
+
+func TestBackoff(t *testing.T) {
+	want := map[int]time.Duration{1: 30 * time.Second, 2: time.Minute, 3: 2 * time.Minute, 10: time.Hour}
+	for attempts, d := range want {
+		if got := Backoff(attempts); got != d {
+			t.Errorf("Backoff(%d) = %v, want %v", attempts, got, d)
+		}
+	}
+}
+
+func TestQueue_RetriesWithBackoffThenFails(t *testing.T) {
+	db := newJobsDB(t)
+	q := NewQueue(db)
+	var calls int32
+	q.Register("send", func(_ context.Context, payload json.RawMessage) error {
+		atomic.AddInt32(&calls, 1)
+		if string(payload) != `{"to":"a@example.org"}` {
+			t.Errorf("payload = %s", payload)
+		}
+		return errors.New("provider unavailable")
+	})
+
+	job, err := Enqueue(db, "send", map[string]string{"to": "a@example.org"})
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	if n := q.RunDue(context.Background()); n != 1 {
+		t.Fatalf("RunDue ran %d jobs, want 1", n)
+	}
+	got := reloadJob(t, db, job.ID)
+	if got.Status != models.JobStatusPending || got.Attempts != 1 || got.LastError != "provider unavailable" {
+		t.Errorf("after first failure: %+v", got)
+	}
+	if !got.RunAt.After(time.Now().Add(20 * time.Second)) {
+		t.Errorf("retry not delayed: run_at %v", got.RunAt)
+	}
+	if n := q.RunDue(context.Background()); n != 0 {
+		t.Errorf("job retried before its backoff elapsed")
+	}
+
+	// Exhaust the remaining attempts
+	for i := 1; i < DefaultMaxAttempts; i++ {
+		db.Model(&models.Job{}).Where("id = ?", job.ID).Update("run_at", time.Now().Add(-time.Second))
+		q.RunDue(context.Background())
+	}
+	got = reloadJob(t, db, job.ID)
+	if got.Status != models.JobStatusFailed || got.Attempts != DefaultMaxAttempts || got.CompletedAt == nil {
+		t.Errorf("after %d failures: %+v", DefaultMaxAttempts, got)
+	}
+	if calls != DefaultMaxAttempts {
+		t.Errorf("handler ran %d times, want %d", calls, DefaultMaxAttempts)
+	}
+
+	requeued, err := Requeue(db, job.ID)
+	if err != nil || requeued.Status != models.JobStatusPending || requeued.Attempts != 0 {
+		t.Fatalf("Requeue = %+v, %v", requeued, err)
+	}
+	if _, err := Requeue(db, job.ID); !errors.Is(err, ErrJobNotFailed) {
+		t.Errorf("Requeue of a pending job: err = %v, want ErrJobNotFailed", err)
+	}
+}
+
+func TestQueue_PermanentErrorsAndPanics(t *testing.T) {
+	db := newJobsDB(t)
+	q := NewQueue(db)
+	q.Register("bad", func(context.Context, json.RawMessage) error { return Permanent(errors.New("malformed")) })
+	q.Register("panics", func(context.Context, json.RawMessage) error { panic("boom") })
+	var failed []string
+	q.OnFailure(func(_ context.Context, job models.Job, err error) {
+		failed = append(failed, job.Type+": "+err.Error())
+	})
+
+	bad, _ := Enqueue(db, "bad", nil)
+	panics, _ := Enqueue(db, "panics", nil)
+	q.RunDue(context.Background())
+
+	if got := reloadJob(t, db, bad.ID); got.Status != models.JobStatusFailed || got.Attempts != 1 {
+		t.Errorf("permanent error should fail immediately: %+v", got)
+	}
+	if got := reloadJob(t, db, panics.ID); got.Status != models.JobStatusPending || got.LastError != "job panicked: boom" {
+		t.Errorf("panic should be retried like an error: %+v", got)
+	}
+	if len(failed) != 1 || failed[0] != "bad: malformed" {
+		t.Errorf("failure hook calls = %q, want only the failed job", failed)
+	}
+}
+
+func TestQueue_SkipsUnknownTypesAndReclaimsStaleJobs(t *testing.T) {
+	db := newJobsDB(t)
+	q := NewQueue(db)
+	var ran int32
+	q.Register("known", func(context.Context, json.RawMessage) error {
+		atomic.AddInt32(&ran, 1)
+		return nil
+	})
+
+	unknown, _ := Enqueue(db, "from_a_newer_release", nil)
+	stale, _ := Enqueue(db, "known", nil)
+	fresh, _ := Enqueue(db, "known", nil)
+	db.Model(&models.Job{}).Where("id = ?", stale.ID).Updates(map[string]interface{}{
+		"status": models.JobStatusRunning, "attempts": 1, "locked_at": time.Now().Add(-time.Hour),
+	})
+	db.Model(&models.Job{}).Where("id = ?", fresh.ID).Updates(map[string]interface{}{
+		"status": models.JobStatusRunning, "attempts": 1, "locked_at": time.Now(),
+	})
+
+	if n := q.RunDue(context.Background()); n != 1 || ran != 1 {
+		t.Fatalf("RunDue ran %d jobs (%d handler calls), want only the stale one", n, ran)
+	}
+	if got := reloadJob(t, db, stale.ID); got.Status != models.JobStatusSucceeded || got.Attempts != 2 {
+		t.Errorf("stale job: %+v", got)
+	}
+	if got := reloadJob(t, db, fresh.ID); got.Status != models.JobStatusRunning {
+		t.Errorf("job still running on another worker was reclaimed: %+v", got)
+	}
+	if got := reloadJob(t, db, unknown.ID); got.Status != models.JobStatusPending || got.Attempts != 0 {
+		t.Errorf("job of an unregistered type was touched: %+v", got)
+	}
+}
+
+func TestQueue_StartRunsJobs(t *testing.T) {
+	db := newJobsDB(t)
+	q := NewQueue(db)
+	done := make(chan struct{}, 3)
+	q.Register("tick", func(context.Context, json.RawMessage) error {
+		done <- struct{}{}
+		return nil
+	})
+	if err := EnqueueMany(db, "tick", []interface{}{1, 2, 3}); err != nil {
+		t.Fatalf("EnqueueMany: %v", err)
+	}
+
+	stop := q.Start(2, 10*time.Millisecond)
+	defer stop()
+	for i := 0; i < 3; i++ {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("only %d of 3 jobs ran", i)
+		}
+	}
+	stop()
+
+	var succeeded int64
+	db.Model(&models.Job{}).Where("status = ?", models.JobStatusSucceeded).Count(&succeeded)
+	if succeeded != 3 {
+		t.Errorf("%d jobs succeeded, want 3", succeeded)
+	}
+}
+
+func TestPurgeSucceeded(t *testing.T) {
+	db := newJobsDB(t)
+	old := time.Now().Add(-8 * 24 * time.Hour)
+	db.Create(&models.Job{Type: "x", Payload: "null", Status: models.JobStatusSucceeded, CompletedAt: &old})
+	db.Create(&models.Job{Type: "x", Payload: "null", Status: models.JobStatusFailed, CompletedAt: &old})
+	recent := time.Now()
+	db.Create(&models.Job{Type: "x", Payload: "null", Status: models.JobStatusSucceeded, CompletedAt: &recent})
+
+	n, err := PurgeSucceeded(db, 7*24*time.Hour)
+	if err != nil || n != 1 {
+		t.Errorf("PurgeSucceeded = %d, %v; want 1 old succeeded job deleted", n, err)
+	}
+}
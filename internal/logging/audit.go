@@ -3,6 +3,7 @@ package logging
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // AuditEvent represents different types of security events
@@ -18,27 +19,47 @@ const (
 	AuditEventRegistration         AuditEvent = "user_registration"
 
 	// Admin events
-	AuditEventUserCreated          AuditEvent = "user_created"
-	AuditEventUserDeleted          AuditEvent = "user_deleted"
-	AuditEventUserRestored         AuditEvent = "user_restored"
-	AuditEventUserPromoted         AuditEvent = "user_promoted"
-	AuditEventUserDemoted          AuditEvent = "user_demoted"
-	AuditEventAccountUnlocked      AuditEvent = "account_unlocked"
-	AuditEventGroupCreated         AuditEvent = "group_created"
-	AuditEventGroupUpdated         AuditEvent = "group_updated"
-	AuditEventGroupDeleted         AuditEvent = "group_deleted"
-	AuditEventUserAddedToGroup     AuditEvent = "user_added_to_group"
-	AuditEventUserRemovedFromGroup AuditEvent = "user_removed_from_group"
-	AuditEventAPITokenCreated      AuditEvent = "api_token_created"
-	AuditEventAPITokenRevoked      AuditEvent = "api_token_revoked"
+	AuditEventUserCreated             AuditEvent = "user_created"
+	AuditEventUserDeleted             AuditEvent = "user_deleted"
+	AuditEventUserRestored            AuditEvent = "user_restored"
+	AuditEventUserPromoted            AuditEvent = "user_promoted"
+	AuditEventUserDemoted             AuditEvent = "user_demoted"
+	AuditEventAccountUnlocked         AuditEvent = "account_unlocked"
+	AuditEventPasswordResetForced     AuditEvent = "password_reset_forced"
+	AuditEventGroupCreated            AuditEvent = "group_created"
+	AuditEventGroupUpdated            AuditEvent = "group_updated"
+	AuditEventGroupDeleted            AuditEvent = "group_deleted"
+	AuditEventUserAddedToGroup        AuditEvent = "user_added_to_group"
+	AuditEventUserRemovedFromGroup    AuditEvent = "user_removed_from_group"
+	AuditEventAPITokenCreated         AuditEvent = "api_token_created"
+	AuditEventAPITokenRevoked         AuditEvent = "api_token_revoked"
+	AuditEventPasswordLoginChanged    AuditEvent = "password_login_changed"
+	AuditEventUserAvatarRemoved       AuditEvent = "user_avatar_removed"
+	AuditEventEmailSuppressionCleared AuditEvent = "email_suppression_cleared"
+	AuditEventEmergencyBroadcast      AuditEvent = "emergency_broadcast"
+	AuditEventAPIAccessRestricted     AuditEvent = "api_access_restricted"
+	AuditEventAPIAccessRestored       AuditEvent = "api_access_restored"
+	AuditEventEmailTemplateUpdated    AuditEvent = "email_template_updated"
+	AuditEventOrganizationChanged     AuditEvent = "organization_changed"
+	AuditEventUserMerged              AuditEvent = "user_merged"
+	AuditEventUserMergeUndone         AuditEvent = "user_merge_undone"
+	AuditEventSiteConfigImported      AuditEvent = "site_config_imported"
 
 	// Data events
 	AuditEventAnimalCreated       AuditEvent = "animal_created"
 	AuditEventAnimalUpdated       AuditEvent = "animal_updated"
 	AuditEventAnimalDeleted       AuditEvent = "animal_deleted"
+	AuditEventAnimalMerged        AuditEvent = "animal_merged"
+	AuditEventLegalHoldPlaced     AuditEvent = "legal_hold_placed"
+	AuditEventLegalHoldLifted     AuditEvent = "legal_hold_lifted"
+	AuditEventBreedsMigrated      AuditEvent = "breeds_migrated"
 	AuditEventAnnouncementCreated AuditEvent = "announcement_created"
 	AuditEventAnnouncementDeleted AuditEvent = "announcement_deleted"
 	AuditEventImageUploaded       AuditEvent = "image_uploaded"
+	AuditEventImageApproved       AuditEvent = "image_approved"
+	AuditEventImageRejected       AuditEvent = "image_rejected"
+	AuditEventRetentionPurge      AuditEvent = "retention_purge"
+	AuditEventOrphanedUploadPurge AuditEvent = "orphaned_upload_purge"
 
 	// Security events
 	AuditEventRateLimitExceeded  AuditEvent = "rate_limit_exceeded"
@@ -98,13 +119,17 @@ func (al *AuditLogger) LogAuthFailure(ctx context.Context, username, ip, reason
 	})
 }
 
-// LogAccountLocked logs account lockout event
-func (al *AuditLogger) LogAccountLocked(ctx context.Context, userID uint, username, ip string, attempts int) {
+// LogAccountLocked logs account lockout event. lockoutCount is the number of
+// consecutive lockouts since the user's last successful login, including
+// this one.
+func (al *AuditLogger) LogAccountLocked(ctx context.Context, userID uint, username, ip string, attempts, lockoutCount int, lockedUntil time.Time) {
 	al.Log(ctx, AuditEventAccountLocked, map[string]interface{}{
 		"user_id":         userID,
 		"username":        username,
 		"ip":              ip,
 		"failed_attempts": attempts,
+		"lockout_count":   lockoutCount,
+		"locked_until":    lockedUntil.UTC().Format(time.RFC3339),
 	})
 }
 
@@ -154,6 +179,15 @@ func (al *AuditLogger) LogAdminAction(ctx context.Context, event AuditEvent, adm
 	al.Log(ctx, event, fields)
 }
 
+// LogSystemAction logs actions taken by scheduled jobs rather than a user
+func (al *AuditLogger) LogSystemAction(ctx context.Context, event AuditEvent, fields map[string]interface{}) {
+	if fields == nil {
+		fields = make(map[string]interface{})
+	}
+	fields["action_type"] = "system"
+	al.Log(ctx, event, fields)
+}
+
 // LogRateLimitExceeded logs rate limit violations
 func (al *AuditLogger) LogRateLimitExceeded(ctx context.Context, ip, endpoint string) {
 	al.Log(ctx, AuditEventRateLimitExceeded, map[string]interface{}{
@@ -196,8 +230,8 @@ func LogAuthFailure(ctx context.Context, username, ip, reason string) {
 }
 
 // LogAccountLocked logs account lockout using default audit logger
-func LogAccountLocked(ctx context.Context, userID uint, username, ip string, attempts int) {
-	defaultAuditLogger.LogAccountLocked(ctx, userID, username, ip, attempts)
+func LogAccountLocked(ctx context.Context, userID uint, username, ip string, attempts, lockoutCount int, lockedUntil time.Time) {
+	defaultAuditLogger.LogAccountLocked(ctx, userID, username, ip, attempts, lockoutCount, lockedUntil)
 }
 
 // LogAccountUnlocked logs admin account unlock using default audit logger
@@ -225,6 +259,11 @@ func LogAdminAction(ctx context.Context, event AuditEvent, adminID uint, fields
 	defaultAuditLogger.LogAdminAction(ctx, event, adminID, fields)
 }
 
+// LogSystemAction logs scheduled job actions using default audit logger
+func LogSystemAction(ctx context.Context, event AuditEvent, fields map[string]interface{}) {
+	defaultAuditLogger.LogSystemAction(ctx, event, fields)
+}
+
 // LogRateLimitExceeded logs rate limit violations using default audit logger
 func LogRateLimitExceeded(ctx context.Context, ip, endpoint string) {
 	defaultAuditLogger.LogRateLimitExceeded(ctx, ip, endpoint)
@@ -0,0 +1,65 @@
+package logging
+
+import "context"
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	loggerKey
+	debugKey
+)
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID, so
+// loggers built from it include the ID even in packages that only see a
+// context.Context (database queries, email sends).
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, or "". A
+// *gin.Context passed as ctx is also understood, through its "request_id"
+// key.
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if id, ok := ctx.Value(requestIDKey).(string); ok {
+		return id
+	}
+	id, _ := ctx.Value("request_id").(string)
+	return id
+}
+
+// ContextWithDebug returns a copy of ctx whose loggers log at DEBUG level,
+// whatever LOG_LEVEL says. Used for per-request debug logging.
+func ContextWithDebug(ctx context.Context) context.Context {
+	return context.WithValue(ctx, debugKey, true)
+}
+
+// DebugRequested reports whether ctx was marked by ContextWithDebug.
+func DebugRequested(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	debug, _ := ctx.Value(debugKey).(bool)
+	return debug
+}
+
+// NewContext returns a copy of ctx carrying logger, for FromContext.
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// FromContext returns the logger stored in ctx by NewContext (the request
+// logger, with its request fields), or else the default logger with ctx's
+// request ID and trace attached.
+func FromContext(ctx context.Context) *Logger {
+	if ctx == nil {
+		return defaultLogger
+	}
+	if logger, ok := ctx.Value(loggerKey).(*Logger); ok {
+		return logger
+	}
+	return defaultLogger.WithContext(ctx)
+}
@@ -124,7 +124,7 @@ func (l *Logger) WithContext(ctx context.Context) *Logger {
 	fields := make(map[string]interface{})
 
 	// Extract request ID from context
-	if requestID, ok := ctx.Value("request_id").(string); ok {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
 		fields["request_id"] = requestID
 	}
 
@@ -143,9 +143,17 @@ func (l *Logger) WithContext(ctx context.Context) *Logger {
 
 	newLogger := l.WithFields(fields)
 	newLogger.ctx = ctx
+	if DebugRequested(ctx) {
+		newLogger.level = DEBUG
+	}
 	return newLogger
 }
 
+// Enabled reports whether a message at level would be emitted by l
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.level
+}
+
 // log is the internal logging method
 func (l *Logger) log(level Level, msg string, err error) {
 	if level < l.level {
@@ -312,6 +312,49 @@ func TestLogger_Log_WithActiveSpan_DoesNotPanicWithoutOtelProvider(t *testing.T)
 	logger.Error("test error", nil)
 }
 
+func TestFromContext(t *testing.T) {
+	buf := &bytes.Buffer{}
+	oldLogger := defaultLogger
+	defer func() { defaultLogger = oldLogger }()
+	SetDefaultLogger(New(INFO, buf, true))
+
+	// Without a stored logger, the default logger picks up the request ID
+	ctx := ContextWithRequestID(context.Background(), "req-789")
+	FromContext(ctx).Info("from default")
+	if !strings.Contains(buf.String(), `"request_id":"req-789"`) {
+		t.Errorf("Expected request_id in output, got: %s", buf.String())
+	}
+
+	// A stored logger is returned as is
+	stored := New(INFO, buf, true).WithField("path", "/api/test")
+	if FromContext(NewContext(ctx, stored)) != stored {
+		t.Error("Expected FromContext to return the stored logger")
+	}
+
+	if FromContext(nil) != defaultLogger {
+		t.Error("Expected FromContext(nil) to return the default logger")
+	}
+}
+
+func TestLogger_WithContext_Debug(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := New(INFO, buf, true)
+
+	logger.WithContext(context.Background()).Debug("filtered")
+	if buf.Len() > 0 {
+		t.Errorf("Expected no output for debug message, got: %s", buf.String())
+	}
+
+	debugLogger := logger.WithContext(ContextWithDebug(context.Background()))
+	if !debugLogger.Enabled(DEBUG) {
+		t.Error("Expected a debug context to enable DEBUG")
+	}
+	debugLogger.Debug("per-request debug")
+	if !strings.Contains(buf.String(), "per-request debug") {
+		t.Errorf("Expected debug output, got: %s", buf.String())
+	}
+}
+
 // Helper type for testing errors
 type testError struct {
 	msg string
@@ -0,0 +1,132 @@
+package maintenance
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// defaultAccountDeletionGraceDays is how long a self-deactivated account can
+// still be restored by an admin before its personal data is erased.
+const defaultAccountDeletionGraceDays = 30
+
+// AccountDeletionGracePeriod returns the grace period between a user
+// deactivating their account and its anonymization, from
+// ACCOUNT_DELETION_GRACE_DAYS (default 30).
+func AccountDeletionGracePeriod() time.Duration {
+	days := defaultAccountDeletionGraceDays
+	if v := os.Getenv("ACCOUNT_DELETION_GRACE_DAYS"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed >= 1 {
+			days = parsed
+		} else {
+			logging.WithField("value", v).Warn("Invalid ACCOUNT_DELETION_GRACE_DAYS, using default")
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// AnonymizeUser permanently erases a user's personal data. The user row is
+// kept, soft-deleted, so their comments and posts stay intact but are no
+// longer attributable to them. Group memberships, skill tags, API tokens,
+// username history, saved filters, and avatar images are removed.
+func AnonymizeUser(db *gorm.DB, userID uint, now time.Time) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		placeholder := fmt.Sprintf("deleted-user-%d", userID)
+		var user models.User
+		if err := tx.Unscoped().Select("id", "avatar_url", "avatar_thumbnail_url").Where("id = ?", userID).Find(&user).Error; err != nil {
+			return err
+		}
+		if user.AvatarURL != "" || user.AvatarThumbnailURL != "" {
+			if err := tx.Unscoped().Where("animal_id IS NULL AND user_id = ? AND image_url IN ?", userID, []string{user.AvatarURL, user.AvatarThumbnailURL}).
+				Delete(&models.AnimalImage{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"username":                    placeholder,
+			"email":                       placeholder + "@deleted.invalid",
+			"first_name":                  "",
+			"last_name":                   "",
+			"phone_number":                "",
+			"avatar_url":                  "",
+			"avatar_thumbnail_url":        "",
+			"password":                    "!", // Not a bcrypt hash, so no password matches
+			"is_admin":                    false,
+			"default_group_id":            nil,
+			"email_notifications_enabled": false,
+			"email_verified_at":           nil,
+			"reset_token":                 "",
+			"reset_token_lookup":          "",
+			"setup_token":                 "",
+			"setup_token_lookup":          "",
+			"email_verification_token":    "",
+			"email_verification_lookup":   "",
+			"email_undeliverable_detail":  "", // Bounce diagnostics can quote the address
+			"scim_user_name":              "",
+			"scim_external_id":            "",
+			"deactivation_requested_at":   nil,
+			"anonymized_at":               now,
+			"deleted_at":                  now,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM user_groups WHERE user_id = ?", userID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM user_skill_tag_assignments WHERE user_id = ?", userID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UsernameHistory{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserIdentity{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.SavedFilter{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_id = ?", userID).Delete(&models.APIToken{}).Error
+	})
+}
+
+// PurgeDeactivatedAccounts anonymizes every account whose owner deactivated
+// it more than gracePeriod ago, returning how many were anonymized.
+// Accounts an admin restored in the meantime are skipped.
+func PurgeDeactivatedAccounts(db *gorm.DB, gracePeriod time.Duration) (int64, error) {
+	now := time.Now()
+	var ids []uint
+	if err := db.Unscoped().Model(&models.User{}).
+		Where("deactivation_requested_at IS NOT NULL AND deactivation_requested_at < ? AND deleted_at IS NOT NULL", now.Add(-gracePeriod)).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+
+	var purged int64
+	for _, id := range ids {
+		if err := AnonymizeUser(db, id, now); err != nil {
+			logging.WithField("user_id", id).Error("Failed to anonymize deactivated account", err)
+			continue
+		}
+		purged++
+	}
+	if purged > 0 {
+		logging.WithField("count", purged).Info("Anonymized deactivated accounts past their grace period")
+	}
+	return purged, nil
+}
+
+// StartAccountPurge periodically runs PurgeDeactivatedAccounts. Returns a
+// stop function; call it during graceful shutdown, before closing the
+// database.
+func StartAccountPurge(db *gorm.DB, gracePeriod, interval time.Duration) (stop func()) {
+	return runPeriodically("Account purge", interval, func() {
+		if _, err := PurgeDeactivatedAccounts(db, gracePeriod); err != nil {
+			logging.Error("Failed to purge deactivated accounts", err)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package maintenance
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// defaultDeletedCommentRetentionDays is how long a deleted comment can
+// still be restored by a group admin before it is permanently removed.
+const defaultDeletedCommentRetentionDays = 30
+
+// DeletedCommentRetention returns how long deleted comments are kept for
+// restoring, from DELETED_COMMENT_RETENTION_DAYS (default 30).
+func DeletedCommentRetention() time.Duration {
+	days := defaultDeletedCommentRetentionDays
+	if v := os.Getenv("DELETED_COMMENT_RETENTION_DAYS"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed >= 1 {
+			days = parsed
+		} else {
+			logging.WithField("value", v).Warn("Invalid DELETED_COMMENT_RETENTION_DAYS, using default")
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// PurgeDeletedComments permanently removes comments deleted more than
+// retention ago, along with their tags, edit history, and reactions.
+// Returns how many comments were removed.
+func PurgeDeletedComments(db *gorm.DB, retention time.Duration) (int64, error) {
+	var ids []uint
+	if err := db.Unscoped().Model(&models.AnimalComment{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", time.Now().Add(-retention)).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	purged, err := deleteComments(db, ids)
+	if err != nil {
+		return 0, err
+	}
+	logging.WithField("count", purged).Info("Purged deleted comments past their retention period")
+	return purged, nil
+}
+
+// deleteComments permanently removes comments ids along with their tags,
+// edit history, and reactions. Returns how many comments were removed.
+func deleteComments(db *gorm.DB, ids []uint) (int64, error) {
+	var purged int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM animal_comment_tags WHERE animal_comment_id IN ?", ids).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("comment_id IN ?", ids).Delete(&models.CommentHistory{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("comment_id IN ?", ids).Delete(&models.CommentReaction{}).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("id IN ?", ids).Delete(&models.AnimalComment{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return purged, nil
+}
+
+// StartCommentPurge periodically runs PurgeDeletedComments. Returns a stop
+// function; call it during graceful shutdown, before closing the database.
+func StartCommentPurge(db *gorm.DB, retention, interval time.Duration) (stop func()) {
+	return runPeriodically("Comment purge", interval, func() {
+		if _, err := PurgeDeletedComments(db, retention); err != nil {
+			logging.Error("Failed to purge deleted comments", err)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package maintenance
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
+	"gorm.io/gorm"
+)
+
+// defaultDataExportRetentionHours is how long a finished export can be
+// downloaded before its file is removed.
+const defaultDataExportRetentionHours = 24
+
+// DataExportRetention returns how long finished exports are kept, from
+// DATA_EXPORT_RETENTION_HOURS (default 24).
+func DataExportRetention() time.Duration {
+	hours := defaultDataExportRetentionHours
+	if v := os.Getenv("DATA_EXPORT_RETENTION_HOURS"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed >= 1 {
+			hours = parsed
+		} else {
+			logging.WithField("value", v).Warn("Invalid DATA_EXPORT_RETENTION_HOURS, using default")
+		}
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// PurgeExpiredExports deletes exports past their ExpiresAt, removing their
+// files from storageProvider first. Failed exports, which have no file, are
+// removed once they are older than retention. Returns how many exports were
+// removed.
+func PurgeExpiredExports(db *gorm.DB, storageProvider storage.Provider, retention time.Duration) (int64, error) {
+	now := time.Now()
+	var exports []models.DataExport
+	if err := db.Select("id", "file_provider", "file_blob_identifier").
+		Where("expires_at < ? OR (status = ? AND created_at < ?)", now, models.JobStatusFailed, now.Add(-retention)).
+		Find(&exports).Error; err != nil {
+		return 0, err
+	}
+	if len(exports) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]uint, 0, len(exports))
+	for _, export := range exports {
+		if storageProvider != nil && export.FileProvider != storage.ProviderPostgres && export.FileBlobIdentifier != "" {
+			err := storageProvider.DeleteDocument(context.Background(), export.FileBlobIdentifier)
+			if err != nil && err != storage.ErrNotFound {
+				// Keep the row so the blob delete is retried next run
+				logging.WithField("export_id", export.ID).Error("Failed to delete expired export file", err)
+				continue
+			}
+		}
+		ids = append(ids, export.ID)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result := db.Where("id IN ?", ids).Delete(&models.DataExport{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	logging.WithField("count", result.RowsAffected).Info("Purged expired data exports")
+	return result.RowsAffected, nil
+}
+
+// StartExportPurge periodically runs PurgeExpiredExports. Returns a stop
+// function; call it during graceful shutdown, before closing the database.
+func StartExportPurge(db *gorm.DB, storageProvider storage.Provider, retention, interval time.Duration) (stop func()) {
+	return runPeriodically("Export purge", interval, func() {
+		if _, err := PurgeExpiredExports(db, storageProvider, retention); err != nil {
+			logging.Error("Failed to purge expired data exports", err)
+		}
+	})
+}
@@ -0,0 +1,207 @@
+package maintenance
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/upload"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultVariantBackfillBatchSize is how many images BackfillImageVariants
+// loads at a time
+const defaultVariantBackfillBatchSize = 50
+
+// StoreImageVariants saves the variants generated for image. They go where
+// the image itself went: Azure blobs for Azure images (falling back to the
+// database if the upload fails, as image uploads do), database rows
+// otherwise. Variants the image already has are left alone.
+func StoreImageVariants(ctx context.Context, db *gorm.DB, storageProvider storage.Provider, image models.AnimalImage, variants map[string]*upload.ProcessedImage) error {
+	for _, v := range upload.ImageVariants {
+		processed, ok := variants[v.Name]
+		if !ok {
+			continue
+		}
+		record := models.AnimalImageVariant{
+			ImageID:         image.ID,
+			Size:            v.Name,
+			MimeType:        processed.MimeType,
+			Width:           processed.Width,
+			Height:          processed.Height,
+			FileSize:        int64(len(processed.Data)),
+			StorageProvider: storage.ProviderPostgres,
+		}
+		stored := false
+		if image.StorageProvider == storage.ProviderAzure && storageProvider != nil {
+			_, blobUUID, blobExt, err := storageProvider.UploadImage(ctx, processed.Data, processed.MimeType, map[string]string{
+				"variant_of": fmt.Sprint(image.ID),
+				"size":       v.Name,
+			})
+			if err == nil {
+				record.StorageProvider = storageProvider.Name()
+				record.BlobIdentifier = blobUUID + blobExt
+				stored = true
+			} else {
+				logging.WithContext(ctx).WithField("image_id", image.ID).
+					Warnf("Failed to upload %s variant to storage provider, falling back to PostgreSQL: %v", v.Name, err)
+			}
+		}
+		if !stored {
+			record.ImageData = processed.Data
+		}
+		if err := db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "image_id"}, {Name: "size"}},
+			DoNothing: true,
+		}).Create(&record).Error; err != nil {
+			return fmt.Errorf("save %s variant: %w", v.Name, err)
+		}
+	}
+	return nil
+}
+
+// DeleteImageVariants removes image's variants and their blobs
+func DeleteImageVariants(ctx context.Context, db *gorm.DB, storageProvider storage.Provider, imageID uint) error {
+	var variants []models.AnimalImageVariant
+	if err := db.WithContext(ctx).Select("id", "storage_provider", "blob_identifier").
+		Where("image_id = ?", imageID).Find(&variants).Error; err != nil {
+		return err
+	}
+	for _, v := range variants {
+		if v.StorageProvider == storage.ProviderAzure && v.BlobIdentifier != "" && storageProvider != nil {
+			if err := storageProvider.DeleteImage(ctx, v.BlobIdentifier); err != nil && err != storage.ErrNotFound {
+				logging.WithContext(ctx).WithField("blob_identifier", v.BlobIdentifier).
+					Warnf("Failed to delete image variant from storage: %v", err)
+			}
+		}
+	}
+	return db.WithContext(ctx).Where("image_id = ?", imageID).Delete(&models.AnimalImageVariant{}).Error
+}
+
+// VariantBackfillResult reports a BackfillImageVariants run
+type VariantBackfillResult struct {
+	ImagesChecked   int  `json:"images_checked"`
+	VariantsCreated int  `json:"variants_created"`
+	Failed          int  `json:"failed"`
+	DryRun          bool `json:"dry_run"`
+}
+
+// BackfillImageVariants generates the missing variants of images uploaded
+// before variants existed, up to limit images (0 for all), loading
+// batchSize at a time. Only images this server serves are considered, and
+// only variants smaller than the image; images whose size was never
+// recorded are checked and get it recorded. An image that fails, such as one
+// whose blob is gone, is logged and skipped. With dryRun, images are only
+// counted.
+func BackfillImageVariants(ctx context.Context, db *gorm.DB, storageProvider storage.Provider, cfg upload.ImageConfig, batchSize, limit int, dryRun bool) (VariantBackfillResult, error) {
+	if batchSize <= 0 {
+		batchSize = defaultVariantBackfillBatchSize
+	}
+	result := VariantBackfillResult{DryRun: dryRun}
+	logger := logging.WithContext(ctx)
+
+	// An image needs a variant when it's bigger than the variant's size (or
+	// its size is unknown) and doesn't have it yet
+	var needs []string
+	var args []interface{}
+	for _, v := range upload.ImageVariants {
+		needs = append(needs, `((animal_images.width = 0 OR animal_images.width > ? OR animal_images.height > ?)
+			AND NOT EXISTS (SELECT 1 FROM animal_image_variants v WHERE v.image_id = animal_images.id AND v.size = ?))`)
+		args = append(args, v.MaxDimension, v.MaxDimension, v.Name)
+	}
+	missing := "(" + strings.Join(needs, " OR ") + ")"
+
+	var lastID uint
+	for limit == 0 || result.ImagesChecked < limit {
+		size := batchSize
+		if limit > 0 {
+			size = min(size, limit-result.ImagesChecked)
+		}
+		var images []models.AnimalImage
+		if err := db.WithContext(ctx).
+			Select("id", "image_url", "mime_type", "width", "height", "storage_provider", "blob_identifier").
+			Where("animal_images.id > ? AND animal_images.image_url LIKE ?", lastID, "/api/images/%").
+			Where(missing, args...).
+			Order("animal_images.id").Limit(size).Find(&images).Error; err != nil {
+			return result, err
+		}
+		if len(images) == 0 {
+			break
+		}
+		lastID = images[len(images)-1].ID
+		result.ImagesChecked += len(images)
+		if dryRun {
+			continue
+		}
+
+		for _, image := range images {
+			created, err := backfillImage(ctx, db, storageProvider, cfg, image)
+			if err != nil {
+				result.Failed++
+				logger.WithField("image_id", image.ID).Warnf("Failed to generate image variants: %v", err)
+				continue
+			}
+			result.VariantsCreated += created
+		}
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// backfillImage generates and stores image's missing variants, returning
+// how many were created
+func backfillImage(ctx context.Context, db *gorm.DB, storageProvider storage.Provider, cfg upload.ImageConfig, image models.AnimalImage) (int, error) {
+	var data []byte
+	if image.StorageProvider == storage.ProviderAzure && image.BlobIdentifier != "" {
+		if storageProvider == nil {
+			return 0, fmt.Errorf("image is in Azure but no storage provider is configured")
+		}
+		blob, _, err := storageProvider.GetImage(ctx, image.BlobIdentifier)
+		if err != nil {
+			return 0, fmt.Errorf("fetch image: %w", err)
+		}
+		data = blob
+	} else {
+		var stored models.AnimalImage
+		if err := db.WithContext(ctx).Select("id", "image_data").First(&stored, image.ID).Error; err != nil {
+			return 0, fmt.Errorf("fetch image: %w", err)
+		}
+		data = stored.ImageData
+		if len(data) == 0 {
+			return 0, fmt.Errorf("image has no data")
+		}
+	}
+
+	// The stored image was already resized when it was uploaded, so it isn't
+	// shrunk again here
+	full, variants, err := upload.ProcessImageWithVariants(bytes.NewReader(data), 0, cfg)
+	if err != nil {
+		return 0, err
+	}
+	if image.Width == 0 {
+		if err := db.WithContext(ctx).Model(&models.AnimalImage{}).Where("id = ?", image.ID).
+			Updates(map[string]interface{}{"width": full.Width, "height": full.Height}).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	var existing []string
+	if err := db.WithContext(ctx).Model(&models.AnimalImageVariant{}).Where("image_id = ?", image.ID).
+		Pluck("size", &existing).Error; err != nil {
+		return 0, err
+	}
+	for _, size := range existing {
+		delete(variants, size)
+	}
+	if err := StoreImageVariants(ctx, db, storageProvider, image, variants); err != nil {
+		return 0, err
+	}
+	return len(variants), nil
+}
@@ -0,0 +1,244 @@
+package maintenance
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"gorm.io/gorm"
+)
+
+// defaultOrphanedUploadGraceDays is how old an unreferenced file must be
+// before it counts as orphaned, so an upload isn't reported before the form
+// that will reference it is saved
+const defaultOrphanedUploadGraceDays = 7
+
+// imageReferences are the columns that can hold an image URL. Soft-deleted
+// rows count, so a restored record never points at a purged file.
+var imageReferences = []struct{ table, column string }{
+	{"users", "avatar_url"},
+	{"users", "avatar_thumbnail_url"},
+	{"animals", "image_url"},
+	{"groups", "image_url"},
+	{"groups", "hero_image_url"},
+	{"groups", "logo_url"},
+	{"animal_comments", "image_url"},
+	{"comment_histories", "image_url"},
+	{"updates", "image_url"},
+	{"protocols", "image_url"},
+	{"protocol_versions", "image_url"},
+	{"site_settings", "value"},
+}
+
+// uploadPathPattern finds /uploads/<file> references inside a stored value,
+// which may be a bare URL, an absolute URL, or JSON holding several
+var uploadPathPattern = regexp.MustCompile(`/uploads/([^"'\s?#)<>]+)`)
+
+// OrphanedUploadConfig controls orphaned upload detection
+type OrphanedUploadConfig struct {
+	Dir       string `json:"dir"`        // Served at /uploads
+	GraceDays int    `json:"grace_days"` // Files modified more recently are never orphaned
+	AutoPurge bool   `json:"auto_purge"` // Whether the scheduled sweep deletes what it finds
+}
+
+// OrphanedUploadConfigFromEnv reads UPLOADS_DIR (default ./public/uploads),
+// ORPHANED_UPLOADS_GRACE_DAYS (default 7), and ORPHANED_UPLOADS_AUTO_PURGE
+// (default false, so the scheduled sweep only logs what it finds)
+func OrphanedUploadConfigFromEnv() OrphanedUploadConfig {
+	cfg := OrphanedUploadConfig{Dir: "./public/uploads", GraceDays: defaultOrphanedUploadGraceDays}
+	if v := os.Getenv("UPLOADS_DIR"); v != "" {
+		cfg.Dir = v
+	}
+	if v := os.Getenv("ORPHANED_UPLOADS_GRACE_DAYS"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed >= 1 {
+			cfg.GraceDays = parsed
+		} else {
+			logging.WithField("value", v).Warn("Invalid ORPHANED_UPLOADS_GRACE_DAYS, using default")
+		}
+	}
+	if v := os.Getenv("ORPHANED_UPLOADS_AUTO_PURGE"); v != "" {
+		autoPurge, err := strconv.ParseBool(v)
+		if err != nil {
+			logging.WithField("value", v).Warn("Invalid ORPHANED_UPLOADS_AUTO_PURGE, only reporting orphaned uploads")
+		}
+		cfg.AutoPurge = autoPurge
+	}
+	return cfg
+}
+
+// OrphanedUpload is a file in the uploads directory that nothing references
+type OrphanedUpload struct {
+	Name       string    `json:"name"` // Slash-separated path under the uploads directory
+	URL        string    `json:"url"`
+	Size       int64     `json:"size"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// referencedUploads returns the paths under /uploads/ that any
+// imageReferences column mentions
+func referencedUploads(db *gorm.DB) (map[string]bool, error) {
+	referenced := make(map[string]bool)
+	for _, ref := range imageReferences {
+		var values []string
+		if err := db.Table(ref.table).Where(ref.column+" LIKE ?", "%/uploads/%").
+			Distinct(ref.column).Pluck(ref.column, &values).Error; err != nil {
+			return nil, err
+		}
+		for _, value := range values {
+			for _, match := range uploadPathPattern.FindAllStringSubmatch(value, -1) {
+				referenced[match[1]] = true
+			}
+		}
+	}
+	return referenced, nil
+}
+
+// FindOrphanedUploads lists the files under cfg.Dir, oldest first, that no
+// animal, group, user, comment, update, protocol, or site setting
+// references and that weren't modified in the last cfg.GraceDays. Hidden
+// files such as .gitkeep are skipped. A missing directory has no orphans.
+func FindOrphanedUploads(db *gorm.DB, cfg OrphanedUploadConfig, now time.Time) ([]OrphanedUpload, error) {
+	referenced, err := referencedUploads(db)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := now.AddDate(0, 0, -cfg.GraceDays)
+	orphans := []OrphanedUpload{}
+	err = filepath.WalkDir(cfg.Dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == cfg.Dir && os.IsNotExist(err) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && p != cfg.Dir {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(cfg.Dir, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if referenced[name] {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.ModTime().Before(cutoff) {
+			return nil
+		}
+		orphans = append(orphans, OrphanedUpload{
+			Name:       name,
+			URL:        path.Join("/uploads", name),
+			Size:       info.Size(),
+			ModifiedAt: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(orphans, func(i, j int) bool { return orphans[i].ModifiedAt.Before(orphans[j].ModifiedAt) })
+	return orphans, nil
+}
+
+// OrphanedUploadPurge reports a purge. Failed lists files that couldn't be
+// deleted; they are reported again next time.
+type OrphanedUploadPurge struct {
+	Deleted    []string `json:"deleted"`
+	FreedBytes int64    `json:"freed_bytes"`
+	Failed     []string `json:"failed,omitempty"`
+}
+
+// PurgeOrphanedUploads deletes orphaned uploads. Orphans are found again
+// first, so a file referenced since it was listed survives; names, when not
+// empty, limits the purge to those orphans. The purge is written to the
+// audit log; adminID is the admin who asked, or 0 for the scheduled sweep.
+func PurgeOrphanedUploads(ctx context.Context, db *gorm.DB, cfg OrphanedUploadConfig, names []string, adminID uint, now time.Time) (*OrphanedUploadPurge, error) {
+	orphans, err := FindOrphanedUploads(db, cfg, now)
+	if err != nil {
+		return nil, err
+	}
+	var selected map[string]bool
+	if len(names) > 0 {
+		selected = make(map[string]bool, len(names))
+		for _, name := range names {
+			selected[name] = true
+		}
+	}
+
+	result := &OrphanedUploadPurge{Deleted: []string{}}
+	for _, orphan := range orphans {
+		if selected != nil && !selected[orphan.Name] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(cfg.Dir, filepath.FromSlash(orphan.Name))); err != nil && !os.IsNotExist(err) {
+			logging.WithField("file", orphan.Name).Error("Failed to delete orphaned upload", err)
+			result.Failed = append(result.Failed, orphan.Name)
+			continue
+		}
+		result.Deleted = append(result.Deleted, orphan.Name)
+		result.FreedBytes += orphan.Size
+	}
+
+	if len(result.Deleted) > 0 || len(result.Failed) > 0 {
+		fields := map[string]interface{}{
+			"deleted":     len(result.Deleted),
+			"failed":      len(result.Failed),
+			"freed_bytes": result.FreedBytes,
+			"grace_days":  cfg.GraceDays,
+		}
+		if adminID != 0 {
+			logging.LogAdminAction(ctx, logging.AuditEventOrphanedUploadPurge, adminID, fields)
+		} else {
+			logging.LogSystemAction(ctx, logging.AuditEventOrphanedUploadPurge, fields)
+		}
+	}
+	return result, nil
+}
+
+// StartOrphanedUploadSweep periodically looks for orphaned uploads, deleting
+// them when cfg.AutoPurge is set and otherwise logging how many there are.
+// Returns a stop function; call it during graceful shutdown, before closing
+// the database.
+func StartOrphanedUploadSweep(db *gorm.DB, cfg OrphanedUploadConfig, interval time.Duration) (stop func()) {
+	return runPeriodically("Orphaned upload sweep", interval, func() {
+		if cfg.AutoPurge {
+			if _, err := PurgeOrphanedUploads(context.Background(), db, cfg, nil, 0, time.Now()); err != nil {
+				logging.WithField("dir", cfg.Dir).Error("Orphaned upload purge failed", err)
+			}
+			return
+		}
+		orphans, err := FindOrphanedUploads(db, cfg, time.Now())
+		if err != nil {
+			logging.WithField("dir", cfg.Dir).Error("Orphaned upload sweep failed", err)
+			return
+		}
+		if len(orphans) > 0 {
+			var size int64
+			for _, orphan := range orphans {
+				size += orphan.Size
+			}
+			logging.WithFields(map[string]interface{}{
+				"count": len(orphans),
+				"bytes": size,
+			}).Info("Found orphaned uploads; purge them from the admin API or set ORPHANED_UPLOADS_AUTO_PURGE")
+		}
+	})
+}
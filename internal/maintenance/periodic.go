@@ -0,0 +1,45 @@
+package maintenance
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+)
+
+// purgeStopTimeout bounds how long stop() waits for an in-flight purge to
+// finish during shutdown.
+const purgeStopTimeout = 10 * time.Second
+
+// runPeriodically calls run every interval in a background goroutine.
+// Returns a stop function that waits up to purgeStopTimeout for an
+// in-flight run; name identifies the task in the log if it doesn't stop.
+func runPeriodically(name string, interval time.Duration, run func()) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		for {
+			select {
+			case <-ticker.C:
+				run()
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		select {
+		case <-finished:
+		case <-time.After(purgeStopTimeout):
+			logging.Warn(fmt.Sprintf("%s did not stop within %s of shutdown signal; proceeding with shutdown anyway", name, purgeStopTimeout))
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/storage"
+	"gorm.io/gorm"
+)
+
+// Entity types a retention rule can cover
+const (
+	RetentionDeletedUsers = "deleted_users" // Accounts an admin deleted; anonymized
+	RetentionComments     = "comments"      // Comments by age, deleted or not; permanently removed
+	RetentionStaleUploads = "stale_uploads" // Images uploaded but never linked to anything; permanently removed
+)
+
+// What a retention rule does to records past its window
+const (
+	RetentionActionAnonymize = "anonymize"
+	RetentionActionDelete    = "delete"
+)
+
+// retentionBatchSize bounds how many records one purge statement touches
+const retentionBatchSize = 500
+
+// RetentionRule is how long records of one entity type are kept. Days of 0
+// keeps them forever.
+type RetentionRule struct {
+	Entity string `json:"entity"`
+	Action string `json:"action"`
+	Days   int    `json:"days"`
+	EnvVar string `json:"env_var"` // Where the window is configured
+}
+
+// Enabled reports whether the rule ever purges anything
+func (r RetentionRule) Enabled() bool {
+	return r.Days > 0
+}
+
+// RetentionPolicy is the retention rule for every entity type, in the order
+// they are applied
+type RetentionPolicy struct {
+	Rules []RetentionRule `json:"rules"`
+	// DryRun makes scheduled runs report what they would purge without
+	// changing anything
+	DryRun bool `json:"dry_run"`
+}
+
+// retentionRules lists the entity types with their action and env var
+var retentionRules = []RetentionRule{
+	{Entity: RetentionDeletedUsers, Action: RetentionActionAnonymize, EnvVar: "RETENTION_DELETED_USERS_DAYS"},
+	{Entity: RetentionComments, Action: RetentionActionDelete, EnvVar: "RETENTION_COMMENTS_DAYS"},
+	{Entity: RetentionStaleUploads, Action: RetentionActionDelete, EnvVar: "RETENTION_STALE_UPLOADS_DAYS"},
+}
+
+// RetentionPolicyFromEnv reads each entity type's window from its
+// RETENTION_*_DAYS variable and dry-run mode from RETENTION_DRY_RUN. Every
+// window defaults to 0, so nothing is purged until it's configured.
+func RetentionPolicyFromEnv() RetentionPolicy {
+	policy := RetentionPolicy{Rules: make([]RetentionRule, len(retentionRules))}
+	for i, rule := range retentionRules {
+		if v := os.Getenv(rule.EnvVar); v != "" {
+			if parsed, err := strconv.Atoi(v); err == nil && parsed >= 0 {
+				rule.Days = parsed
+			} else {
+				logging.WithField("value", v).Warn(fmt.Sprintf("Invalid %s, keeping records forever", rule.EnvVar))
+			}
+		}
+		policy.Rules[i] = rule
+	}
+	if v := os.Getenv("RETENTION_DRY_RUN"); v != "" {
+		dryRun, err := strconv.ParseBool(v)
+		if err != nil {
+			logging.WithField("value", v).Warn("Invalid RETENTION_DRY_RUN, running in dry-run mode")
+			dryRun = true
+		}
+		policy.DryRun = dryRun
+	}
+	return policy
+}
+
+// RetentionResult reports one rule's run. Matched counts the records past
+// the window; Purged is how many were removed or anonymized, always 0 on a
+// dry run.
+type RetentionResult struct {
+	Entity  string     `json:"entity"`
+	Action  string     `json:"action"`
+	Days    int        `json:"days"`
+	Cutoff  *time.Time `json:"cutoff,omitempty"`
+	Matched int64      `json:"matched"`
+	Purged  int64      `json:"purged"`
+	Error   string     `json:"error,omitempty"`
+}
+
+// ApplyRetention runs every enabled rule in policy. With dryRun it only
+// counts what each rule would purge. Every rule that matched records is
+// written to the audit log; adminID is the admin who started the run, or 0
+// for a scheduled run.
+func ApplyRetention(ctx context.Context, db *gorm.DB, storageProvider storage.Provider, policy RetentionPolicy, dryRun bool, adminID uint) []RetentionResult {
+	now := time.Now()
+	results := make([]RetentionResult, 0, len(policy.Rules))
+	for _, rule := range policy.Rules {
+		result := RetentionResult{Entity: rule.Entity, Action: rule.Action, Days: rule.Days}
+		if !rule.Enabled() {
+			results = append(results, result)
+			continue
+		}
+		cutoff := now.AddDate(0, 0, -rule.Days)
+		result.Cutoff = &cutoff
+
+		ids, err := retentionCandidates(db, rule.Entity, cutoff)
+		if err == nil {
+			result.Matched = int64(len(ids))
+			if !dryRun && len(ids) > 0 {
+				result.Purged, err = purgeRetained(ctx, db, storageProvider, rule.Entity, ids, now)
+			}
+		}
+		if err != nil {
+			result.Error = err.Error()
+			logging.WithField("entity", rule.Entity).Error("Retention purge failed", err)
+		}
+		if result.Matched > 0 {
+			fields := map[string]interface{}{
+				"entity":  rule.Entity,
+				"action":  rule.Action,
+				"days":    rule.Days,
+				"cutoff":  cutoff.UTC().Format(time.RFC3339),
+				"matched": result.Matched,
+				"purged":  result.Purged,
+				"dry_run": dryRun,
+			}
+			if adminID != 0 {
+				logging.LogAdminAction(ctx, logging.AuditEventRetentionPurge, adminID, fields)
+			} else {
+				logging.LogSystemAction(ctx, logging.AuditEventRetentionPurge, fields)
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// retentionCandidates returns the IDs of entity's records past cutoff
+func retentionCandidates(db *gorm.DB, entity string, cutoff time.Time) ([]uint, error) {
+	var ids []uint
+	switch entity {
+	case RetentionDeletedUsers:
+		err := db.Unscoped().Model(&models.User{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ? AND anonymized_at IS NULL", cutoff).
+			Order("id").Pluck("id", &ids).Error
+		return ids, err
+	case RetentionComments:
+		err := db.Unscoped().Model(&models.AnimalComment{}).
+			Where("created_at < ?", cutoff).
+			Order("id").Pluck("id", &ids).Error
+		return ids, err
+	case RetentionStaleUploads:
+		query := db.Unscoped().Model(&models.AnimalImage{}).
+			Where("animal_images.animal_id IS NULL AND animal_images.created_at < ?", cutoff)
+		for _, ref := range imageReferences {
+			query = query.Where("NOT EXISTS (SELECT 1 FROM " + ref.table + " WHERE " + ref.table + "." + ref.column + " = animal_images.image_url)")
+		}
+		err := query.Order("animal_images.id").Pluck("animal_images.id", &ids).Error
+		return ids, err
+	}
+	return nil, fmt.Errorf("unknown retention entity %q", entity)
+}
+
+// purgeRetained anonymizes or deletes entity's records ids, in batches.
+// Returns how many were purged; records that fail stay for the next run.
+func purgeRetained(ctx context.Context, db *gorm.DB, storageProvider storage.Provider, entity string, ids []uint, now time.Time) (int64, error) {
+	var purged int64
+	switch entity {
+	case RetentionDeletedUsers:
+		for _, id := range ids {
+			if err := AnonymizeUser(db, id, now); err != nil {
+				logging.WithField("user_id", id).Error("Failed to anonymize deleted account", err)
+				continue
+			}
+			purged++
+		}
+		return purged, nil
+	case RetentionComments:
+		for start := 0; start < len(ids); start += retentionBatchSize {
+			n, err := deleteComments(db, ids[start:min(start+retentionBatchSize, len(ids))])
+			purged += n
+			if err != nil {
+				return purged, err
+			}
+		}
+		return purged, nil
+	case RetentionStaleUploads:
+		for start := 0; start < len(ids); start += retentionBatchSize {
+			n, err := deleteImages(ctx, db, storageProvider, ids[start:min(start+retentionBatchSize, len(ids))])
+			purged += n
+			if err != nil {
+				return purged, err
+			}
+		}
+		return purged, nil
+	}
+	return 0, fmt.Errorf("unknown retention entity %q", entity)
+}
+
+// deleteImages permanently removes image rows, deleting externally stored
+// files first. An image whose file can't be deleted is kept so the delete is
+// retried on the next run.
+func deleteImages(ctx context.Context, db *gorm.DB, storageProvider storage.Provider, ids []uint) (int64, error) {
+	var images []models.AnimalImage
+	if err := db.Unscoped().Select("id", "storage_provider", "blob_identifier").Where("id IN ?", ids).Find(&images).Error; err != nil {
+		return 0, err
+	}
+	deletable := make([]uint, 0, len(images))
+	for _, img := range images {
+		if storageProvider != nil && img.StorageProvider != storage.ProviderPostgres && img.BlobIdentifier != "" {
+			if err := storageProvider.DeleteImage(ctx, img.BlobIdentifier); err != nil && err != storage.ErrNotFound {
+				logging.WithField("image_id", img.ID).Error("Failed to delete stale upload file", err)
+				continue
+			}
+		}
+		if err := DeleteImageVariants(ctx, db, storageProvider, img.ID); err != nil {
+			logging.WithField("image_id", img.ID).Error("Failed to delete stale upload's variants", err)
+			continue
+		}
+		deletable = append(deletable, img.ID)
+	}
+	if len(deletable) == 0 {
+		return 0, nil
+	}
+	result := db.Unscoped().Where("id IN ?", deletable).Delete(&models.AnimalImage{})
+	return result.RowsAffected, result.Error
+}
+
+// StartRetentionPurge periodically applies policy, as a dry run when
+// policy.DryRun is set. Does nothing if no rule is enabled. Returns a stop
+// function; call it during graceful shutdown, before closing the database.
+func StartRetentionPurge(db *gorm.DB, storageProvider storage.Provider, policy RetentionPolicy, interval time.Duration) (stop func()) {
+	enabled := false
+	for _, rule := range policy.Rules {
+		enabled = enabled || rule.Enabled()
+	}
+	if !enabled {
+		return func() {}
+	}
+	return runPeriodically("Retention purge", interval, func() {
+		ApplyRetention(context.Background(), db, storageProvider, policy, policy.DryRun, 0)
+	})
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/alerting"
+)
+
+// ServerErrorAlerts counts 5xx responses toward the alerter's error-rate
+// threshold. Counting, and any alert it triggers, runs off the request
+// path so a slow sink never delays a response.
+func ServerErrorAlerts(alerter *alerting.Alerter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			route := c.FullPath()
+			if route == "" {
+				route = c.Request.URL.Path
+			}
+			go alerter.RecordServerError(context.Background(), route)
+		}
+	}
+}
@@ -0,0 +1,346 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// apiUsageMinutes is how many one-minute windows of history are kept
+	// per user
+	apiUsageMinutes = 60
+	// apiUsageIdleExpiry is how long a user's counters survive without a
+	// request
+	apiUsageIdleExpiry = 24 * time.Hour
+	// apiUsageSweepInterval is how often idle users are dropped
+	apiUsageSweepInterval = 10 * time.Minute
+	// apiUsageTopRoutes bounds the routes reported for one user
+	apiUsageTopRoutes = 10
+	// apiRestrictionTTL bounds how long a replica keeps enforcing a
+	// restriction another replica's admin lifted, or misses a new one.
+	// Changes made through this replica apply immediately via
+	// InvalidateRestrictions.
+	apiRestrictionTTL = 30 * time.Second
+	// unmatchedRoute labels requests that didn't match a route, so probing
+	// for URLs can't grow the route counters without bound
+	unmatchedRoute = "(unmatched)"
+)
+
+type usageMinute struct {
+	minute   int64 // Unix time / 60
+	requests int
+	rejected int
+}
+
+type userUsage struct {
+	minutes       [apiUsageMinutes]usageMinute
+	totalRequests int64
+	totalRejected int64
+	firstSeen     time.Time
+	lastSeen      time.Time
+	lastIP        string
+	routes        map[string]int64
+}
+
+// window returns the counters for now's minute, clearing a slot left over
+// from an hour ago.
+func (u *userUsage) window(now time.Time) *usageMinute {
+	minute := now.Unix() / 60
+	w := &u.minutes[minute%apiUsageMinutes]
+	if w.minute != minute {
+		*w = usageMinute{minute: minute}
+	}
+	return w
+}
+
+// APIUsageTracker counts each authenticated user's API requests in
+// one-minute windows and enforces per-user throttles and suspensions set by
+// site admins. Counts are kept in memory, so each replica sees only its own
+// traffic and they reset on restart; restrictions are stored in the
+// database and apply everywhere.
+type APIUsageTracker struct {
+	db  *gorm.DB
+	now func() time.Time
+
+	mu        sync.Mutex
+	users     map[uint]*userUsage
+	since     time.Time
+	lastSweep time.Time
+
+	restrictionsMu       sync.Mutex
+	restrictions         map[uint]models.APIAccessRestriction
+	restrictionsLoadedAt time.Time
+}
+
+// NewAPIUsageTracker creates a tracker that reads restrictions from db. A
+// nil db only counts requests.
+func NewAPIUsageTracker(db *gorm.DB) *APIUsageTracker {
+	now := time.Now()
+	return &APIUsageTracker{
+		db:        db,
+		now:       time.Now,
+		users:     make(map[uint]*userUsage),
+		since:     now,
+		lastSweep: now,
+	}
+}
+
+// Restriction returns userID's unexpired restriction, or nil. Restrictions
+// are cached for apiRestrictionTTL; if they can't be reloaded, the last
+// loaded set is used.
+func (t *APIUsageTracker) Restriction(ctx context.Context, userID uint) *models.APIAccessRestriction {
+	if t.db == nil {
+		return nil
+	}
+	t.restrictionsMu.Lock()
+	defer t.restrictionsMu.Unlock()
+	now := t.now()
+	if t.restrictionsLoadedAt.IsZero() || now.Sub(t.restrictionsLoadedAt) >= apiRestrictionTTL {
+		var active []models.APIAccessRestriction
+		if err := t.db.WithContext(ctx).Where("expires_at IS NULL OR expires_at > ?", now).Find(&active).Error; err != nil {
+			logging.Error("Failed to load API access restrictions", err)
+		} else {
+			t.restrictions = make(map[uint]models.APIAccessRestriction, len(active))
+			for _, r := range active {
+				t.restrictions[r.UserID] = r
+			}
+			t.restrictionsLoadedAt = now
+		}
+	}
+	r, ok := t.restrictions[userID]
+	if !ok || (r.ExpiresAt != nil && !r.ExpiresAt.After(now)) {
+		return nil
+	}
+	return &r
+}
+
+// InvalidateRestrictions forces the next Restriction to reload from the
+// database.
+func (t *APIUsageTracker) InvalidateRestrictions() {
+	t.restrictionsMu.Lock()
+	t.restrictionsLoadedAt = time.Time{}
+	t.restrictionsMu.Unlock()
+}
+
+// record counts a request by userID to route and reports whether
+// restriction lets it through. A throttled user may make RequestsPerMinute
+// accepted requests per clock minute; a suspended user none.
+func (t *APIUsageTracker) record(userID uint, route, ip string, restriction *models.APIAccessRestriction) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	if now.Sub(t.lastSweep) > apiUsageSweepInterval {
+		for id, u := range t.users {
+			if now.Sub(u.lastSeen) > apiUsageIdleExpiry {
+				delete(t.users, id)
+			}
+		}
+		t.lastSweep = now
+	}
+
+	u, ok := t.users[userID]
+	if !ok {
+		u = &userUsage{firstSeen: now, routes: make(map[string]int64)}
+		t.users[userID] = u
+	}
+	w := u.window(now)
+
+	allowed := true
+	if restriction != nil {
+		switch restriction.Mode {
+		case models.APIRestrictionSuspend:
+			allowed = false
+		case models.APIRestrictionThrottle:
+			allowed = w.requests-w.rejected < restriction.RequestsPerMinute
+		}
+	}
+
+	w.requests++
+	u.totalRequests++
+	if !allowed {
+		w.rejected++
+		u.totalRejected++
+	}
+	u.routes[route]++
+	u.lastSeen = now
+	u.lastIP = ip
+	return allowed
+}
+
+// APIUsageMinute is one minute of a user's requests
+type APIUsageMinute struct {
+	Minute   time.Time `json:"minute"`
+	Requests int       `json:"requests"`
+	Rejected int       `json:"rejected"`
+}
+
+// APIRouteUsage is how often a user called one route
+type APIRouteUsage struct {
+	Route    string `json:"route"`
+	Requests int64  `json:"requests"`
+}
+
+// APIUsage summarizes a user's requests to this replica. Requests include
+// the ones refused by a throttle or suspension, which are also counted in
+// Rejected. Totals cover everything since FirstSeen.
+type APIUsage struct {
+	UserID             uint             `json:"user_id"`
+	RequestsThisMinute int              `json:"requests_this_minute"`
+	RequestsLastHour   int              `json:"requests_last_hour"`
+	RejectedLastHour   int              `json:"rejected_last_hour"`
+	PeakPerMinute      int              `json:"peak_per_minute"` // Busiest minute in the last hour
+	TotalRequests      int64            `json:"total_requests"`
+	TotalRejected      int64            `json:"total_rejected"`
+	FirstSeen          time.Time        `json:"first_seen"`
+	LastSeen           time.Time        `json:"last_seen"`
+	LastIP             string           `json:"last_ip"`
+	Minutes            []APIUsageMinute `json:"minutes,omitempty"`    // Minutes with requests in the last hour, oldest first
+	TopRoutes          []APIRouteUsage  `json:"top_routes,omitempty"` // Most requested routes since FirstSeen
+}
+
+// summarize builds u's usage as of now. The caller holds t.mu.
+func summarize(userID uint, u *userUsage, now time.Time, detailed bool) APIUsage {
+	usage := APIUsage{
+		UserID:        userID,
+		TotalRequests: u.totalRequests,
+		TotalRejected: u.totalRejected,
+		FirstSeen:     u.firstSeen,
+		LastSeen:      u.lastSeen,
+		LastIP:        u.lastIP,
+	}
+	current := now.Unix() / 60
+	for _, w := range u.minutes {
+		if w.requests == 0 || current-w.minute >= apiUsageMinutes {
+			continue
+		}
+		usage.RequestsLastHour += w.requests
+		usage.RejectedLastHour += w.rejected
+		usage.PeakPerMinute = max(usage.PeakPerMinute, w.requests)
+		if w.minute == current {
+			usage.RequestsThisMinute = w.requests
+		}
+		if detailed {
+			usage.Minutes = append(usage.Minutes, APIUsageMinute{
+				Minute:   time.Unix(w.minute*60, 0).UTC(),
+				Requests: w.requests,
+				Rejected: w.rejected,
+			})
+		}
+	}
+	if !detailed {
+		return usage
+	}
+	sort.Slice(usage.Minutes, func(i, j int) bool { return usage.Minutes[i].Minute.Before(usage.Minutes[j].Minute) })
+	for route, n := range u.routes {
+		usage.TopRoutes = append(usage.TopRoutes, APIRouteUsage{Route: route, Requests: n})
+	}
+	sort.Slice(usage.TopRoutes, func(i, j int) bool {
+		a, b := usage.TopRoutes[i], usage.TopRoutes[j]
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		return a.Route < b.Route
+	})
+	if len(usage.TopRoutes) > apiUsageTopRoutes {
+		usage.TopRoutes = usage.TopRoutes[:apiUsageTopRoutes]
+	}
+	return usage
+}
+
+// Since returns when this replica started counting.
+func (t *APIUsageTracker) Since() time.Time {
+	return t.since
+}
+
+// Usage returns userID's usage with per-minute counts and top routes, or
+// ok=false if this replica hasn't seen a request from them recently.
+func (t *APIUsageTracker) Usage(userID uint) (APIUsage, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	u, ok := t.users[userID]
+	if !ok {
+		return APIUsage{}, false
+	}
+	return summarize(userID, u, t.now(), true), true
+}
+
+// TopConsumers returns up to limit users with requests in the last hour,
+// most requests first.
+func (t *APIUsageTracker) TopConsumers(limit int) []APIUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	top := []APIUsage{}
+	for id, u := range t.users {
+		if usage := summarize(id, u, now, false); usage.RequestsLastHour > 0 {
+			top = append(top, usage)
+		}
+	}
+	sort.Slice(top, func(i, j int) bool {
+		a, b := top[i], top[j]
+		if a.RequestsLastHour != b.RequestsLastHour {
+			return a.RequestsLastHour > b.RequestsLastHour
+		}
+		return a.UserID < b.UserID
+	})
+	if len(top) > limit {
+		top = top[:limit]
+	}
+	return top
+}
+
+// Track returns a middleware that counts each authenticated request and
+// refuses the ones a restriction doesn't allow: 403 while the user is
+// suspended, 429 once a throttled user has used the minute's budget. It
+// must run after AuthRequired to see the user.
+func (t *APIUsageTracker) Track() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := GetUserID(c)
+		if !ok {
+			c.Next()
+			return
+		}
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		restriction := t.Restriction(c.Request.Context(), userID)
+		if t.record(userID, route, c.ClientIP(), restriction) {
+			c.Next()
+			return
+		}
+
+		fields := map[string]interface{}{
+			"user_id":  userID,
+			"mode":     restriction.Mode,
+			"endpoint": c.Request.URL.Path,
+			"method":   c.Request.Method,
+		}
+		if restriction.Mode == models.APIRestrictionSuspend {
+			GetLogger(c).WithFields(fields).Warn("Request refused: API access suspended")
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":      "Your API access has been suspended",
+				"expires_at": restriction.ExpiresAt,
+			})
+			return
+		}
+
+		GetLogger(c).WithFields(fields).Warn("Request refused: API access throttled")
+		now := t.now()
+		setRateLimitHeaders(c, RateLimitResult{
+			Limit: restriction.RequestsPerMinute,
+			Reset: now.Truncate(time.Minute).Add(time.Minute),
+		})
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+			"error": "Too many requests. Please try again later.",
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newUsageTestRouter serves GET /animals/:id through the tracker as whichever
+// user the X-User header names.
+func newUsageTestRouter(tracker *APIUsageTracker) *gin.Engine {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if user := c.GetHeader("X-User"); user != "" {
+			id, _ := strconv.ParseUint(user, 10, 32)
+			c.Set("user_id", uint(id))
+		}
+	}, tracker.Track())
+	router.GET("/animals/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func usageTestRequest(router *gin.Engine, user, path string) int {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("X-User", user)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestAPIUsageTracker(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(&models.APIAccessRestriction{}); err != nil {
+		t.Fatalf("failed to migrate test db: %v", err)
+	}
+
+	now := time.Date(2026, 10, 16, 12, 0, 30, 0, time.UTC)
+	tracker := NewAPIUsageTracker(db)
+	tracker.now = func() time.Time { return now }
+	router := newUsageTestRouter(tracker)
+
+	for i := 0; i < 3; i++ {
+		usageTestRequest(router, "1", "/animals/7")
+	}
+	usageTestRequest(router, "1", "/nowhere")
+	usageTestRequest(router, "2", "/animals/8")
+	usageTestRequest(router, "", "/animals/9")
+
+	usage, ok := tracker.Usage(1)
+	if !ok {
+		t.Fatal("expected usage for user 1")
+	}
+	if usage.RequestsThisMinute != 4 || usage.RequestsLastHour != 4 || usage.TotalRequests != 4 {
+		t.Errorf("usage = %+v, want 4 requests", usage)
+	}
+	if len(usage.TopRoutes) != 2 || usage.TopRoutes[0] != (APIRouteUsage{Route: "/animals/:id", Requests: 3}) ||
+		usage.TopRoutes[1].Route != unmatchedRoute {
+		t.Errorf("top routes = %+v", usage.TopRoutes)
+	}
+	if _, ok := tracker.Usage(3); ok {
+		t.Error("unauthenticated requests shouldn't be tracked")
+	}
+
+	// Throttle user 2 to two requests a minute
+	if err := db.Create(&models.APIAccessRestriction{UserID: 2, Mode: models.APIRestrictionThrottle, RequestsPerMinute: 2}).Error; err != nil {
+		t.Fatal(err)
+	}
+	tracker.InvalidateRestrictions()
+	codes := []int{}
+	for i := 0; i < 3; i++ {
+		codes = append(codes, usageTestRequest(router, "2", "/animals/8"))
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusTooManyRequests || codes[2] != http.StatusTooManyRequests {
+		t.Errorf("throttled codes = %v, want the earlier request to use the budget", codes)
+	}
+	now = now.Add(time.Minute)
+	if code := usageTestRequest(router, "2", "/animals/8"); code != http.StatusOK {
+		t.Errorf("next minute code = %d, want 200", code)
+	}
+
+	// Suspend user 1 for ten minutes
+	until := now.Add(10 * time.Minute)
+	if err := db.Create(&models.APIAccessRestriction{UserID: 1, Mode: models.APIRestrictionSuspend, ExpiresAt: &until}).Error; err != nil {
+		t.Fatal(err)
+	}
+	tracker.InvalidateRestrictions()
+	if code := usageTestRequest(router, "1", "/animals/7"); code != http.StatusForbidden {
+		t.Errorf("suspended code = %d, want 403", code)
+	}
+	now = until
+	if code := usageTestRequest(router, "1", "/animals/7"); code != http.StatusOK {
+		t.Errorf("code after suspension expired = %d, want 200", code)
+	}
+
+	usage, _ = tracker.Usage(2)
+	if usage.RequestsLastHour != 5 || usage.RejectedLastHour != 2 || usage.PeakPerMinute != 4 || len(usage.Minutes) != 2 {
+		t.Errorf("user 2 usage = %+v", usage)
+	}
+	top := tracker.TopConsumers(1)
+	if len(top) != 1 || top[0].UserID != 1 || top[0].RequestsLastHour != 6 {
+		t.Errorf("top consumers = %+v, want user 1 with 6 requests", top)
+	}
+
+	// An hour later the per-minute history has rolled off but totals remain
+	now = now.Add(time.Hour)
+	usage, _ = tracker.Usage(1)
+	if usage.RequestsLastHour != 0 || usage.TotalRequests != 6 || len(usage.Minutes) != 0 {
+		t.Errorf("usage after an hour = %+v", usage)
+	}
+	if top := tracker.TopConsumers(10); len(top) != 0 {
+		t.Errorf("top consumers after an hour = %+v, want none", top)
+	}
+}
@@ -0,0 +1,170 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIVersion is a major version of the HTTP API, served under /api/v<N>.
+type APIVersion int
+
+// APIv1 is the first versioned API. The unversioned /api prefix is an alias
+// for it so existing clients and stored URLs (e.g. /api/images/:uuid) keep
+// working.
+const APIv1 APIVersion = 1
+
+// DefaultAPIVersion is the version served under the unversioned /api prefix.
+const DefaultAPIVersion = APIv1
+
+func (v APIVersion) String() string {
+	return "v" + strconv.Itoa(int(v))
+}
+
+// APIVersionHeader is the response header naming the version that served a
+// request.
+const APIVersionHeader = "API-Version"
+
+// APIVersionConfig describes a version the router serves. A zero Deprecated
+// means the version is current; once set, every response for the version
+// carries Deprecation (and Sunset, if set) headers pointing clients at the
+// newest version.
+type APIVersionConfig struct {
+	Version    APIVersion
+	Deprecated time.Time
+	Sunset     time.Time
+}
+
+// APIVersions lists the versions served, oldest first.
+var APIVersions = []APIVersionConfig{
+	{Version: APIv1},
+}
+
+type apiVersionCtxKey struct{}
+
+// VersionedAPI serves /api/v<N>/... from the routes registered under /api,
+// recording the version on the request context for Versioned and
+// RequestAPIVersion. Unversioned /api requests are served as
+// DefaultAPIVersion, and versions not in versions get a JSON 404 rather
+// than falling through to the SPA.
+//
+// Routes are registered once; a version that changes a response shape does
+// so per route with Versioned, so a new version only has to wire the
+// handlers that differ.
+func VersionedAPI(next http.Handler, versions ...APIVersionConfig) http.Handler {
+	configs := make(map[APIVersion]APIVersionConfig, len(versions))
+	var latest APIVersion
+	for _, cfg := range versions {
+		configs[cfg.Version] = cfg
+		if cfg.Version > latest {
+			latest = cfg.Version
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if path != "/api" && !strings.HasPrefix(path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		version, unversioned := DefaultAPIVersion, path
+		if v, rest, ok := splitAPIVersion(path); ok {
+			cfg, served := configs[v]
+			if !served {
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.WriteHeader(http.StatusNotFound)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": "Unsupported API version " + v.String()})
+				return
+			}
+			version, unversioned = cfg.Version, "/api"+rest
+		}
+
+		w.Header().Set(APIVersionHeader, version.String())
+		if cfg := configs[version]; !cfg.Deprecated.IsZero() {
+			successor := ""
+			if latest != version {
+				successor = "/api/" + latest.String()
+			}
+			setDeprecationHeaders(w.Header(), cfg.Deprecated, cfg.Sunset, successor)
+		}
+
+		r = r.WithContext(context.WithValue(r.Context(), apiVersionCtxKey{}, version))
+		if unversioned != path {
+			u := *r.URL
+			u.Path, u.RawPath = unversioned, ""
+			r.URL = &u
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// splitAPIVersion splits "/api/v2/groups" into 2 and "/groups".
+func splitAPIVersion(path string) (APIVersion, string, bool) {
+	segment, rest, _ := strings.Cut(strings.TrimPrefix(path, "/api/"), "/")
+	if len(segment) < 2 || segment[0] != 'v' {
+		return 0, "", false
+	}
+	n, err := strconv.Atoi(segment[1:])
+	if err != nil || n < 1 || strconv.Itoa(n) != segment[1:] {
+		return 0, "", false
+	}
+	if rest != "" || strings.HasSuffix(path, "/") {
+		rest = "/" + rest
+	}
+	return APIVersion(n), rest, true
+}
+
+// RequestAPIVersion returns the API version a request is being served as,
+// DefaultAPIVersion if VersionedAPI did not handle it.
+func RequestAPIVersion(r *http.Request) APIVersion {
+	if v, ok := r.Context().Value(apiVersionCtxKey{}).(APIVersion); ok {
+		return v
+	}
+	return DefaultAPIVersion
+}
+
+// Versioned picks a handler by API version: byVersion[v] handles requests
+// for version v and every later version until the next override, and base
+// handles versions older than all of them. A v2 response shape is wired as
+//
+//	Versioned(handlers.GetThing(db), map[APIVersion]gin.HandlerFunc{2: handlers.GetThingV2(db)})
+func Versioned(base gin.HandlerFunc, byVersion map[APIVersion]gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requested := RequestAPIVersion(c.Request)
+		handler, best := base, APIVersion(0)
+		for v, h := range byVersion {
+			if v <= requested && v > best {
+				handler, best = h, v
+			}
+		}
+		handler(c)
+	}
+}
+
+// Deprecated marks a single route as deprecated: responses carry a
+// Deprecation header with the given date, a Sunset header if sunset is set,
+// and a successor-version Link if successor (a path) is set.
+func Deprecated(at, sunset time.Time, successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		setDeprecationHeaders(c.Writer.Header(), at, sunset, successor)
+		c.Next()
+	}
+}
+
+// setDeprecationHeaders writes the RFC 9745 Deprecation header and the
+// RFC 8594 Sunset header.
+func setDeprecationHeaders(h http.Header, at, sunset time.Time, successor string) {
+	h.Set("Deprecation", "@"+strconv.FormatInt(at.Unix(), 10))
+	if !sunset.IsZero() {
+		h.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+	}
+	if successor != "" {
+		h.Set("Link", "<"+successor+`>; rel="successor-version"`)
+	}
+}
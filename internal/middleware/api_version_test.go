@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestVersionedAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/animals/:id", Versioned(
+		func(c *gin.Context) { c.String(http.StatusOK, "v1 "+c.Param("id")) },
+		map[APIVersion]gin.HandlerFunc{3: func(c *gin.Context) { c.String(http.StatusOK, "v3 "+c.Param("id")) }},
+	))
+	router.GET("/api/videos", func(c *gin.Context) { c.String(http.StatusOK, "videos") })
+	router.NoRoute(func(c *gin.Context) { c.String(http.StatusOK, "spa") })
+
+	deprecated := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	handler := VersionedAPI(router,
+		APIVersionConfig{Version: APIv1, Deprecated: deprecated, Sunset: sunset},
+		APIVersionConfig{Version: 2},
+		APIVersionConfig{Version: 3},
+	)
+
+	tests := []struct {
+		path, body, version string
+		status              int
+	}{
+		{"/api/animals/7", "v1 7", "v1", http.StatusOK},
+		{"/api/v1/animals/7", "v1 7", "v1", http.StatusOK},
+		{"/api/v2/animals/7", "v1 7", "v2", http.StatusOK},
+		{"/api/v3/animals/7", "v3 7", "v3", http.StatusOK},
+		{"/api/videos", "videos", "v1", http.StatusOK},
+		{"/api/v9/animals/7", "Unsupported API version v9", "", http.StatusNotFound},
+		{"/dashboard", "spa", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.body) {
+			t.Errorf("%s: got %d %q, want %d %q", tt.path, w.Code, w.Body.String(), tt.status, tt.body)
+		}
+		if got := w.Header().Get(APIVersionHeader); got != tt.version {
+			t.Errorf("%s: %s = %q, want %q", tt.path, APIVersionHeader, got, tt.version)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/animals/7", nil))
+	if got := w.Header().Get("Deprecation"); got != "@1767225600" {
+		t.Errorf("Deprecation = %q", got)
+	}
+	if got := w.Header().Get("Sunset"); got != "Fri, 01 Jan 2027 00:00:00 GMT" {
+		t.Errorf("Sunset = %q", got)
+	}
+	if got := w.Header().Get("Link"); got != `</api/v3>; rel="successor-version"` {
+		t.Errorf("Link = %q", got)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v3/animals/7", nil))
+	if got := w.Header().Get("Deprecation"); got != "" {
+		t.Errorf("current version got Deprecation = %q", got)
+	}
+}
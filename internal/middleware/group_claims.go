@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/auth"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+)
+
+// defaultGroupClaimsMaxAgeMinutes is how long a token's group claims are
+// trusted after it was issued
+const defaultGroupClaimsMaxAgeMinutes = 15
+
+// GroupClaimsMaxAge returns how long after issue a token's group claims
+// stand in for the database, from GROUP_CLAIMS_MAX_AGE_MINUTES (default 15).
+// 0 turns group claims off, so every access check queries the database.
+func GroupClaimsMaxAge() time.Duration {
+	minutes := defaultGroupClaimsMaxAgeMinutes
+	if v := os.Getenv("GROUP_CLAIMS_MAX_AGE_MINUTES"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed >= 0 {
+			minutes = parsed
+		} else {
+			logging.WithField("value", v).Warn("Invalid GROUP_CLAIMS_MAX_AGE_MINUTES, using default")
+		}
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+type groupClaimsKey struct{}
+
+// GroupMemberships are the authenticated user's group memberships, taken
+// from fresh token claims
+type GroupMemberships struct {
+	UserID uint
+	auth.GroupClaims
+}
+
+// WithGroupMemberships attaches memberships to ctx
+func WithGroupMemberships(ctx context.Context, m *GroupMemberships) context.Context {
+	return context.WithValue(ctx, groupClaimsKey{}, m)
+}
+
+// GroupMembershipsFromContext returns the memberships AuthRequired attached
+// to a request context, or nil if the token had none or they were stale.
+// A *gorm.DB from GetDB carries the request context in Statement.Context.
+func GroupMembershipsFromContext(ctx context.Context) *GroupMemberships {
+	if ctx == nil {
+		return nil
+	}
+	m, _ := ctx.Value(groupClaimsKey{}).(*GroupMemberships)
+	return m
+}
+
+// IsMember reports whether the claims show userID in groupID. False means
+// only "not as of the token", so callers fall back to the database: the user
+// may have joined since.
+func (m *GroupMemberships) IsMember(userID, groupID uint) bool {
+	return m != nil && m.UserID == userID && slices.Contains(m.GroupIDs, groupID)
+}
+
+// IsGroupAdmin reports whether the claims show userID as an admin of
+// groupID. Like IsMember, false means check the database.
+func (m *GroupMemberships) IsGroupAdmin(userID, groupID uint) bool {
+	return m != nil && m.UserID == userID && slices.Contains(m.AdminGroupIDs, groupID)
+}
+
+// groupMembershipsFromClaims returns claims' memberships if the token
+// carries them and was issued within maxAge, or nil
+func groupMembershipsFromClaims(claims *auth.Claims, maxAge time.Duration, now time.Time) *GroupMemberships {
+	if claims.Groups == nil || maxAge <= 0 || claims.IssuedAt == nil {
+		return nil
+	}
+	if now.Sub(claims.IssuedAt.Time) >= maxAge {
+		return nil
+	}
+	return &GroupMemberships{UserID: claims.UserID, GroupClaims: *claims.Groups}
+}
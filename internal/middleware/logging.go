@@ -1,19 +1,47 @@
 package middleware
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
 )
 
+// DebugLogHeader turns on DEBUG logging, including every database query,
+// for one request. Its value must match LOG_DEBUG_TOKEN.
+const DebugLogHeader = "X-Debug-Log"
+
+// debugLogRequested reports whether the request carries the debug token.
+// With no token configured, per-request debug logging is off.
+func debugLogRequested(c *gin.Context, want *[sha256.Size]byte) bool {
+	presented := c.GetHeader(DebugLogHeader)
+	if want == nil || presented == "" {
+		return false
+	}
+	got := sha256.Sum256([]byte(presented))
+	return subtle.ConstantTimeCompare(got[:], want[:]) == 1
+}
+
 // LoggingMiddleware logs HTTP requests with structured logging. Per-route
 // request count/duration metrics are NOT recorded here — otelgin.Middleware
 // (registered separately in cmd/api/main.go) already emits the standard
 // http.server.request.duration histogram with method/route/status
 // attributes via the same MeterProvider; adding a second, differently-named
 // metric here would just double-count the same signal in Axiom.
+//
+// The request logger is also stored on the request context, so code that
+// only sees a context.Context (GORM's logger, the email service) logs with
+// the same request fields via logging.FromContext.
 func LoggingMiddleware() gin.HandlerFunc {
+	var debugToken *[sha256.Size]byte
+	if token := strings.TrimSpace(os.Getenv("LOG_DEBUG_TOKEN")); token != "" {
+		sum := sha256.Sum256([]byte(token))
+		debugToken = &sum
+	}
 	return func(c *gin.Context) {
 		// Start timer
 		start := time.Now()
@@ -23,6 +51,10 @@ func LoggingMiddleware() gin.HandlerFunc {
 		// Get request ID from context
 		requestID, _ := c.Get("request_id")
 
+		if debugLogRequested(c, debugToken) {
+			c.Request = c.Request.WithContext(logging.ContextWithDebug(c.Request.Context()))
+		}
+
 		// Create logger with request context. WithContext first so trace_id/
 		// span_id from the otelgin span (already on c.Request.Context() by
 		// the time this middleware runs) get attached, then layer the
@@ -40,6 +72,7 @@ func LoggingMiddleware() gin.HandlerFunc {
 
 		// Add logger to context for use in handlers
 		c.Set("logger", logger)
+		c.Request = c.Request.WithContext(logging.NewContext(c.Request.Context(), logger))
 
 		// Process request
 		c.Next()
@@ -89,6 +122,7 @@ func GetLogger(c *gin.Context) *logging.Logger {
 			return l
 		}
 	}
-	// Return default logger with request context if not found
-	return logging.WithContext(c.Request.Context())
+	// Fall back to the logger on the request context, or the default
+	// logger with the request's context fields
+	return logging.FromContext(c.Request.Context())
 }
@@ -0,0 +1,352 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CaptchaTokenHeader carries the client's CAPTCHA response on throttled
+// endpoints.
+const CaptchaTokenHeader = "X-Captcha-Token"
+
+// loginThrottleSweepInterval is how often entries nobody has failed from in
+// a while are dropped.
+const loginThrottleSweepInterval = 10 * time.Minute
+
+// CaptchaVerifier checks a CAPTCHA response token sent by a client that has
+// failed too often. Implementations wrap a provider such as hCaptcha,
+// Cloudflare Turnstile, or reCAPTCHA.
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token, remoteIP string) (bool, error)
+}
+
+// SiteVerifyCaptcha verifies tokens against a provider's "siteverify"
+// endpoint, the protocol hCaptcha, Turnstile, and reCAPTCHA share: a form
+// POST of secret, response, and remoteip answered with {"success": bool}.
+type SiteVerifyCaptcha struct {
+	VerifyURL string
+	Secret    string
+	Client    *http.Client // nil uses a client with a 10 second timeout
+}
+
+// Verify implements CaptchaVerifier.
+func (s *SiteVerifyCaptcha) Verify(ctx context.Context, token, remoteIP string) (bool, error) {
+	form := url.Values{"secret": {s.Secret}, "response": {token}, "remoteip": {remoteIP}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.VerifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, err
+	}
+	return result.Success, nil
+}
+
+// CaptchaVerifierFromEnv returns a SiteVerifyCaptcha when CAPTCHA_VERIFY_URL
+// and CAPTCHA_SECRET are both set, otherwise nil.
+func CaptchaVerifierFromEnv() CaptchaVerifier {
+	verifyURL, secret := os.Getenv("CAPTCHA_VERIFY_URL"), os.Getenv("CAPTCHA_SECRET")
+	if verifyURL == "" || secret == "" {
+		return nil
+	}
+	return &SiteVerifyCaptcha{VerifyURL: verifyURL, Secret: secret}
+}
+
+// LoginThrottleConfig is the per-IP backoff policy. After FreeAttempts
+// failures from one IP, each further failure blocks it for twice as long as
+// the last, starting at BaseDelay and capped at MaxDelay. An IP's failures
+// are forgotten once none has been seen for twice MaxDelay.
+type LoginThrottleConfig struct {
+	FreeAttempts int
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+}
+
+// LoginThrottleConfigFromEnv reads LOGIN_THROTTLE_FREE_ATTEMPTS (default 5),
+// LOGIN_THROTTLE_BASE_DELAY_SECONDS (default 30), and
+// LOGIN_THROTTLE_MAX_DELAY_MINUTES (default 60).
+func LoginThrottleConfigFromEnv() LoginThrottleConfig {
+	return LoginThrottleConfig{
+		FreeAttempts: RateLimitFromEnv("LOGIN_THROTTLE_FREE_ATTEMPTS", 5),
+		BaseDelay:    time.Duration(RateLimitFromEnv("LOGIN_THROTTLE_BASE_DELAY_SECONDS", 30)) * time.Second,
+		MaxDelay:     time.Duration(RateLimitFromEnv("LOGIN_THROTTLE_MAX_DELAY_MINUTES", 60)) * time.Minute,
+	}
+}
+
+// delay returns how long to block after the given number of failures.
+func (cfg LoginThrottleConfig) delay(failures int) time.Duration {
+	over := failures - cfg.FreeAttempts
+	if over <= 0 {
+		return 0
+	}
+	d := float64(cfg.BaseDelay) * math.Pow(2, float64(over-1))
+	if d > float64(cfg.MaxDelay) {
+		return cfg.MaxDelay
+	}
+	return time.Duration(d)
+}
+
+type throttleEntry struct {
+	failures     int
+	lastFailure  time.Time
+	blockedUntil time.Time
+}
+
+// LoginThrottler tracks failed attempts per client IP and endpoint, in
+// memory, so each replica throttles independently. Unlike account lockout,
+// it slows down whoever is guessing without locking out the account owner.
+type LoginThrottler struct {
+	cfg      LoginThrottleConfig
+	verifier CaptchaVerifier
+	now      func() time.Time
+
+	mu        sync.Mutex
+	entries   map[string]*throttleEntry // endpoint + "|" + IP
+	lastSweep time.Time
+
+	since             time.Time
+	blocked           map[string]int64 // endpoint -> requests refused during backoff
+	captchaChallenges int64            // requests refused for a missing or wrong CAPTCHA
+	captchaPassed     int64
+}
+
+// NewLoginThrottler creates a throttler. verifier may be nil, in which case
+// throttled IPs simply wait out their backoff.
+func NewLoginThrottler(cfg LoginThrottleConfig, verifier CaptchaVerifier) *LoginThrottler {
+	now := time.Now()
+	return &LoginThrottler{
+		cfg:       cfg,
+		verifier:  verifier,
+		now:       time.Now,
+		entries:   make(map[string]*throttleEntry),
+		lastSweep: now,
+		since:     now,
+		blocked:   make(map[string]int64),
+	}
+}
+
+// entry returns key's entry for recording a failure, first forgetting
+// failures that are too old. The caller holds t.mu.
+func (t *LoginThrottler) entry(key string, now time.Time) *throttleEntry {
+	forgetAfter := 2 * t.cfg.MaxDelay
+	if now.Sub(t.lastSweep) > loginThrottleSweepInterval {
+		for k, e := range t.entries {
+			if now.Sub(e.lastFailure) > forgetAfter {
+				delete(t.entries, k)
+			}
+		}
+		t.lastSweep = now
+	}
+	e, ok := t.entries[key]
+	if !ok || now.Sub(e.lastFailure) > forgetAfter {
+		e = &throttleEntry{}
+		t.entries[key] = e
+	}
+	return e
+}
+
+// check reports whether a request may proceed: how long it must still wait,
+// and whether it has to solve a CAPTCHA first.
+func (t *LoginThrottler) check(key string) (wait time.Duration, needsCaptcha bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	e, ok := t.entries[key]
+	if !ok || now.Sub(e.lastFailure) > 2*t.cfg.MaxDelay {
+		return 0, false
+	}
+	return e.blockedUntil.Sub(now), t.verifier != nil && e.failures >= t.cfg.FreeAttempts
+}
+
+// fail records a failed attempt.
+func (t *LoginThrottler) fail(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	e := t.entry(key, now)
+	e.failures++
+	e.lastFailure = now
+	if d := t.cfg.delay(e.failures); d > 0 {
+		e.blockedUntil = now.Add(d)
+	}
+}
+
+// succeed clears key's failures.
+func (t *LoginThrottler) succeed(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.entries, key)
+}
+
+// Unblock forgets all failures from ip, on every endpoint. It reports whether
+// there were any.
+func (t *LoginThrottler) Unblock(ip string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	found := false
+	for key := range t.entries {
+		if strings.HasSuffix(key, "|"+ip) {
+			delete(t.entries, key)
+			found = true
+		}
+	}
+	return found
+}
+
+// ThrottledIP is one IP with failures on one endpoint.
+type ThrottledIP struct {
+	IP           string     `json:"ip"`
+	Endpoint     string     `json:"endpoint"`
+	Failures     int        `json:"failures"`
+	LastFailure  time.Time  `json:"last_failure"`
+	BlockedUntil *time.Time `json:"blocked_until,omitempty"`
+}
+
+// LoginThrottleStats reports what this replica's throttler has done since it
+// started.
+type LoginThrottleStats struct {
+	Since             time.Time        `json:"since"`
+	CaptchaEnabled    bool             `json:"captcha_enabled"`
+	BlockedTotal      int64            `json:"blocked_total"`
+	BlockedByEndpoint map[string]int64 `json:"blocked_by_endpoint"`
+	CaptchaChallenges int64            `json:"captcha_challenges"`
+	CaptchaPassed     int64            `json:"captcha_passed"`
+	// ThrottledIPs lists IPs that are blocked now or have reached the free
+	// attempt limit, most failures first
+	ThrottledIPs []ThrottledIP `json:"throttled_ips"`
+}
+
+// Stats returns the throttler's counters and currently throttled IPs.
+func (t *LoginThrottler) Stats() LoginThrottleStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	stats := LoginThrottleStats{
+		Since:             t.since,
+		CaptchaEnabled:    t.verifier != nil,
+		BlockedByEndpoint: make(map[string]int64, len(t.blocked)),
+		CaptchaChallenges: t.captchaChallenges,
+		CaptchaPassed:     t.captchaPassed,
+		ThrottledIPs:      []ThrottledIP{},
+	}
+	for endpoint, n := range t.blocked {
+		stats.BlockedByEndpoint[endpoint] = n
+		stats.BlockedTotal += n
+	}
+	for key, e := range t.entries {
+		blocked := e.blockedUntil.After(now)
+		if !blocked && (e.failures < t.cfg.FreeAttempts || now.Sub(e.lastFailure) > 2*t.cfg.MaxDelay) {
+			continue
+		}
+		endpoint, ip, _ := strings.Cut(key, "|")
+		throttled := ThrottledIP{IP: ip, Endpoint: endpoint, Failures: e.failures, LastFailure: e.lastFailure}
+		if blocked {
+			until := e.blockedUntil
+			throttled.BlockedUntil = &until
+		}
+		stats.ThrottledIPs = append(stats.ThrottledIPs, throttled)
+	}
+	sort.Slice(stats.ThrottledIPs, func(i, j int) bool {
+		a, b := stats.ThrottledIPs[i], stats.ThrottledIPs[j]
+		if a.Failures != b.Failures {
+			return a.Failures > b.Failures
+		}
+		return a.IP+a.Endpoint < b.IP+b.Endpoint
+	})
+	return stats
+}
+
+// Throttle returns a middleware that applies the throttler to one endpoint.
+// failed reports, from the handler's response status, whether the request
+// counts as a failed attempt; any other 2xx response clears the IP's
+// failures on this endpoint.
+//
+// An IP past its free attempts is refused with 429 until its backoff ends.
+// When a CAPTCHA verifier is configured, such an IP may instead proceed by
+// sending a valid token in the X-Captcha-Token header; without one it is
+// refused with "captcha_required". If the verifier itself fails, the
+// backoff alone decides.
+func (t *LoginThrottler) Throttle(endpoint string, failed func(status int) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clientIP := c.ClientIP()
+		key := endpoint + "|" + clientIP
+		wait, needsCaptcha := t.check(key)
+
+		allowed := wait <= 0
+		if needsCaptcha {
+			var passed bool
+			var err error
+			if token := c.GetHeader(CaptchaTokenHeader); token != "" {
+				passed, err = t.verifier.Verify(c.Request.Context(), token, clientIP)
+			}
+			switch {
+			case err != nil:
+				GetLogger(c).WithField("ip", clientIP).Warnf("CAPTCHA verification failed: %v", err)
+			case passed:
+				allowed = true
+				t.count(func() { t.captchaPassed++ })
+			default:
+				t.count(func() { t.captchaChallenges++ })
+				GetLogger(c).WithFields(map[string]interface{}{"ip": clientIP, "endpoint": endpoint}).
+					Warn("Login attempt requires CAPTCHA")
+				c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+					"error":            "Too many failed attempts. Please complete the CAPTCHA and try again.",
+					"captcha_required": true,
+				})
+				return
+			}
+		}
+		if !allowed {
+			t.count(func() { t.blocked[endpoint]++ })
+			retryAfter := int(math.Ceil(wait.Seconds()))
+			GetLogger(c).WithFields(map[string]interface{}{"ip": clientIP, "endpoint": endpoint, "retry_after": retryAfter}).
+				Warn("Login attempt throttled")
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":               "Too many failed attempts from your network. Please try again later.",
+				"retry_after_seconds": retryAfter,
+			})
+			return
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if failed(status) {
+			t.fail(key)
+		} else if status >= 200 && status < 300 {
+			t.succeed(key)
+		}
+	}
+}
+
+// count updates a counter under the throttler's lock.
+func (t *LoginThrottler) count(update func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	update()
+}
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stubCaptcha struct {
+	err error
+}
+
+func (s stubCaptcha) Verify(_ context.Context, token, _ string) (bool, error) {
+	return token == "good", s.err
+}
+
+// newThrottleTestRouter serves POST /login through the throttler, answering
+// with whatever status the test sets in *status.
+func newThrottleTestRouter(throttler *LoginThrottler, status *int) *gin.Engine {
+	router := gin.New()
+	router.POST("/login", throttler.Throttle("login", func(s int) bool { return s == http.StatusUnauthorized }), func(c *gin.Context) {
+		c.Status(*status)
+	})
+	return router
+}
+
+func throttleTestRequest(router *gin.Engine, ip, captcha string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/login", nil)
+	req.RemoteAddr = ip + ":1234"
+	if captcha != "" {
+		req.Header.Set(CaptchaTokenHeader, captcha)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestLoginThrottle_Backoff(t *testing.T) {
+	clock := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	throttler := NewLoginThrottler(LoginThrottleConfig{FreeAttempts: 2, BaseDelay: time.Minute, MaxDelay: 3 * time.Minute}, nil)
+	throttler.now = func() time.Time { return clock }
+	status := http.StatusUnauthorized
+	router := newThrottleTestRouter(throttler, &status)
+
+	// Two free failures, then each failure blocks for longer
+	for i := 0; i < 3; i++ {
+		if w := throttleTestRequest(router, "10.0.0.1", ""); w.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: got %d, want 401", i+1, w.Code)
+		}
+	}
+	w := throttleTestRequest(router, "10.0.0.1", "")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "60" {
+		t.Fatalf("got %d with Retry-After %q, want 429 with 60", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := throttleTestRequest(router, "10.0.0.2", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("other IPs should not be throttled, got %d", w.Code)
+	}
+
+	clock = clock.Add(61 * time.Second)
+	throttleTestRequest(router, "10.0.0.1", "")
+	if w := throttleTestRequest(router, "10.0.0.1", ""); w.Header().Get("Retry-After") != "120" {
+		t.Errorf("second block: Retry-After %q, want 120", w.Header().Get("Retry-After"))
+	}
+	clock = clock.Add(121 * time.Second)
+	throttleTestRequest(router, "10.0.0.1", "")
+	if w := throttleTestRequest(router, "10.0.0.1", ""); w.Header().Get("Retry-After") != "180" {
+		t.Errorf("blocks are capped at MaxDelay: Retry-After %q, want 180", w.Header().Get("Retry-After"))
+	}
+
+	stats := throttler.Stats()
+	if stats.BlockedTotal != 3 || stats.BlockedByEndpoint["login"] != 3 {
+		t.Errorf("blocked counts = %d/%v, want 3", stats.BlockedTotal, stats.BlockedByEndpoint)
+	}
+	if len(stats.ThrottledIPs) != 1 || stats.ThrottledIPs[0].IP != "10.0.0.1" || stats.ThrottledIPs[0].Failures != 5 {
+		t.Errorf("throttled IPs = %+v", stats.ThrottledIPs)
+	}
+
+	// A successful login clears the IP's failures
+	clock = clock.Add(181 * time.Second)
+	status = http.StatusOK
+	throttleTestRequest(router, "10.0.0.1", "")
+	if stats := throttler.Stats(); len(stats.ThrottledIPs) != 0 {
+		t.Errorf("success should clear failures, got %+v", stats.ThrottledIPs)
+	}
+}
+
+func TestLoginThrottle_Captcha(t *testing.T) {
+	throttler := NewLoginThrottler(LoginThrottleConfig{FreeAttempts: 1, BaseDelay: time.Hour, MaxDelay: time.Hour}, stubCaptcha{})
+	status := http.StatusUnauthorized
+	router := newThrottleTestRouter(throttler, &status)
+
+	throttleTestRequest(router, "10.0.0.1", "")
+	w := throttleTestRequest(router, "10.0.0.1", "")
+	var body map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusTooManyRequests || body["captcha_required"] != true {
+		t.Fatalf("got %d %s, want 429 with captcha_required", w.Code, w.Body.String())
+	}
+	if w := throttleTestRequest(router, "10.0.0.1", "wrong"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("wrong CAPTCHA: got %d, want 429", w.Code)
+	}
+	// A solved CAPTCHA gets through even while the IP is blocked
+	if w := throttleTestRequest(router, "10.0.0.1", "good"); w.Code != http.StatusUnauthorized {
+		t.Errorf("solved CAPTCHA: got %d, want 401 from the handler", w.Code)
+	}
+
+	// When the verifier is down, the backoff alone decides
+	throttler.verifier = stubCaptcha{err: errors.New("provider unavailable")}
+	if w := throttleTestRequest(router, "10.0.0.1", "good"); w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("verifier error: got %d, want 429 with Retry-After", w.Code)
+	}
+
+	stats := throttler.Stats()
+	if !stats.CaptchaEnabled || stats.CaptchaChallenges != 2 || stats.CaptchaPassed != 1 {
+		t.Errorf("captcha stats = %+v", stats)
+	}
+	if !throttler.Unblock("10.0.0.1") || throttler.Unblock("10.0.0.1") {
+		t.Error("Unblock should clear the IP once")
+	}
+	if w := throttleTestRequest(router, "10.0.0.1", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("after Unblock: got %d, want 401", w.Code)
+	}
+}
+
+func TestSiteVerifyCaptcha(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		ok := r.PostForm.Get("secret") == "s3cret" && r.PostForm.Get("response") == "token" && r.PostForm.Get("remoteip") == "10.0.0.1"
+		_ = json.NewEncoder(w).Encode(map[string]bool{"success": ok})
+	}))
+	defer server.Close()
+
+	verifier := &SiteVerifyCaptcha{VerifyURL: server.URL, Secret: "s3cret"}
+	if ok, err := verifier.Verify(context.Background(), "token", "10.0.0.1"); err != nil || !ok {
+		t.Errorf("valid token: got %v, %v", ok, err)
+	}
+	if ok, err := verifier.Verify(context.Background(), "other", "10.0.0.1"); err != nil || ok {
+		t.Errorf("invalid token: got %v, %v", ok, err)
+	}
+}
@@ -3,7 +3,7 @@ package middleware
 import (
 	"context"
 	"net/http"
-	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,28 +14,26 @@ import (
 	"gorm.io/gorm"
 )
 
-// CORS middleware to handle cross-origin requests
-func CORS() gin.HandlerFunc {
+// CORS middleware to handle cross-origin requests. Allowed origins, methods,
+// and headers, and how long a preflight may be cached, come from environment
+// variables, then site settings; see SecurityConfigStore. A nil store uses
+// the environment only.
+func CORS(store *SecurityConfigStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get allowed origins from environment variable
-		allowedOrigins := os.Getenv("ALLOWED_ORIGINS")
-		if allowedOrigins == "" {
-			// Default for development
-			allowedOrigins = "http://localhost:5173,http://localhost:3000"
-		}
+		cfg := store.Get(c.Request.Context())
 
 		origin := c.Request.Header.Get("Origin")
-		// Check if the origin is in the allowed list
-		if origin != "" && contains(strings.Split(allowedOrigins, ","), origin) {
-			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
-		} else if allowedOrigins == "*" {
-			// Allow wildcard only if explicitly set to "*"
-			c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		if allowOrigin, ok := cfg.AllowsOrigin(origin); ok {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", allowOrigin)
 		}
+		c.Writer.Header().Add("Vary", "Origin")
 
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", strings.Join(cfg.AllowedHeaders, ", "))
+		c.Writer.Header().Set("Access-Control-Allow-Methods", strings.Join(cfg.AllowedMethods, ", "))
+		if c.Request.Method == "OPTIONS" && cfg.CORSMaxAge > 0 {
+			c.Writer.Header().Set("Access-Control-Max-Age", strconv.Itoa(cfg.CORSMaxAge))
+		}
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -58,12 +56,26 @@ func contains(slice []string, str string) bool {
 
 // AuthRequired middleware to protect routes. Accepts either a JWT (issued at
 // login) or an API token (prefixed "pat_", generated via the admin API
-// tokens endpoints) in the Authorization header.
+// tokens endpoints) in the Authorization header. With cookie sessions on
+// (see SessionConfigFromEnv), a request without the header may instead use
+// the JWT in the session cookie; pair it with CSRFRequired. A JWT's group
+// claims are attached to the request context while fresh; see
+// GroupMembershipsFromContext.
 func AuthRequired(db *gorm.DB) gin.HandlerFunc {
+	groupClaimsMaxAge := GroupClaimsMaxAge()
+	sessions := SessionConfigFromEnv()
 	return func(c *gin.Context) {
 		ctx := c.Request.Context()
 		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
+
+		var token string
+		fromCookie := false
+		if authHeader == "" && sessions.Cookies() {
+			token, _ = c.Cookie(SessionCookie)
+			fromCookie = token != ""
+		}
+
+		if authHeader == "" && !fromCookie {
 			// Log unauthorized access attempt
 			logger := GetLogger(c)
 			logger.WithFields(map[string]interface{}{
@@ -78,7 +90,7 @@ func AuthRequired(db *gorm.DB) gin.HandlerFunc {
 
 		// Extract token from "Bearer <token>"
 		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
+		if !fromCookie && (len(parts) != 2 || parts[0] != "Bearer") {
 			logger := GetLogger(c)
 			logger.WithFields(map[string]interface{}{
 				"ip":       c.ClientIP(),
@@ -90,9 +102,11 @@ func AuthRequired(db *gorm.DB) gin.HandlerFunc {
 			return
 		}
 
-		token := parts[1]
+		if !fromCookie {
+			token = parts[1]
+		}
 
-		if auth.IsAPIToken(token) {
+		if !fromCookie && auth.IsAPIToken(token) {
 			userID, isAdmin, ok := authenticateAPIToken(ctx, db, token)
 			if !ok {
 				logger := GetLogger(c)
@@ -111,6 +125,7 @@ func AuthRequired(db *gorm.DB) gin.HandlerFunc {
 
 			c.Set("user_id", userID)
 			c.Set("is_admin", isAdmin)
+			c.Set("api_token_auth", true)
 			c.Next()
 			return
 		}
@@ -137,6 +152,12 @@ func AuthRequired(db *gorm.DB) gin.HandlerFunc {
 		// Store user info in context
 		c.Set("user_id", claims.UserID)
 		c.Set("is_admin", claims.IsAdmin)
+		if fromCookie {
+			c.Set(sessionCookieAuthKey, true)
+		}
+		if memberships := groupMembershipsFromClaims(claims, groupClaimsMaxAge, time.Now()); memberships != nil {
+			c.Request = c.Request.WithContext(WithGroupMemberships(ctx, memberships))
+		}
 		c.Next()
 	}
 }
@@ -5,12 +5,15 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/auth"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
 	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -149,7 +152,7 @@ func TestCORS(t *testing.T) {
 
 			// Create test server
 			router := gin.New()
-			router.Use(CORS())
+			router.Use(CORS(nil))
 			router.GET("/test", func(c *gin.Context) {
 				c.JSON(200, gin.H{"message": "ok"})
 			})
@@ -188,6 +191,40 @@ func TestCORS(t *testing.T) {
 	}
 }
 
+func TestCORS_MethodsAndHeaders(t *testing.T) {
+	clearSecurityEnv(t)
+	preflight := func(store *SecurityConfigStore) http.Header {
+		router := gin.New()
+		router.Use(CORS(store))
+		req, _ := http.NewRequest(http.MethodOptions, "/test", nil)
+		req.Header.Set("Origin", "http://localhost:5173")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Header()
+	}
+
+	h := preflight(nil)
+	if h.Get("Access-Control-Allow-Methods") != defaultAllowedMethods || h.Get("Access-Control-Max-Age") != "600" {
+		t.Errorf("default preflight headers = %v", h)
+	}
+	if !strings.Contains(h.Get("Access-Control-Allow-Headers"), CSRFHeader) {
+		t.Errorf("Allow-Headers = %q, want the CSRF header allowed", h.Get("Access-Control-Allow-Headers"))
+	}
+
+	store := NewSecurityConfigStore(newSecuritySettingsDB(t, map[string]string{
+		SettingAllowedMethods: "get,post",
+		SettingAllowedHeaders: "Content-Type",
+		SettingCORSMaxAge:     "0",
+	}))
+	h = preflight(store)
+	if h.Get("Access-Control-Allow-Methods") != "GET, POST" || h.Get("Access-Control-Allow-Headers") != "Content-Type" {
+		t.Errorf("configured preflight headers = %v", h)
+	}
+	if _, ok := h["Access-Control-Max-Age"]; ok {
+		t.Error("a max age of 0 shouldn't let browsers cache preflights")
+	}
+}
+
 func TestAuthRequired(t *testing.T) {
 	// Generate a valid token for testing
 	validToken, _ := auth.GenerateToken(1, false)
@@ -523,7 +560,7 @@ func TestSecurityHeaders(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create test server
 			router := gin.New()
-			router.Use(SecurityHeaders())
+			router.Use(SecurityHeaders(nil))
 			router.GET("/test", func(c *gin.Context) {
 				c.JSON(200, gin.H{"message": "ok"})
 			})
@@ -814,6 +851,9 @@ func TestRequestID(t *testing.T) {
 			if requestID != "test-request-id-123" {
 				t.Errorf("Expected request_id to be test-request-id-123, got %s", requestID)
 			}
+			if got := logging.RequestIDFromContext(c.Request.Context()); got != "test-request-id-123" {
+				t.Errorf("Expected request context to carry test-request-id-123, got %s", got)
+			}
 			c.String(200, "ok")
 		})
 
@@ -834,6 +874,39 @@ func TestRequestID(t *testing.T) {
 	})
 }
 
+func TestLoggingMiddleware_DebugHeader(t *testing.T) {
+	t.Setenv("LOG_DEBUG_TOKEN", "debug-secret")
+	router := gin.New()
+	router.Use(RequestID(), LoggingMiddleware())
+	var debug bool
+	router.GET("/test", func(c *gin.Context) {
+		debug = logging.DebugRequested(c.Request.Context())
+		if logging.FromContext(c.Request.Context()) != GetLogger(c) {
+			t.Error("Expected the request logger on the request context")
+		}
+		c.String(200, "ok")
+	})
+
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{"wrong", false},
+		{"debug-secret", true},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", "/test", nil)
+		if tt.header != "" {
+			req.Header.Set(DebugLogHeader, tt.header)
+		}
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		if debug != tt.want {
+			t.Errorf("header %q: expected debug %v, got %v", tt.header, tt.want, debug)
+		}
+	}
+}
+
 func TestAuthRequired_APIToken(t *testing.T) {
 	buildRouter := func(db *gorm.DB) *gin.Engine {
 		router := gin.New()
@@ -1006,3 +1079,168 @@ func TestAuthRequired_APIToken(t *testing.T) {
 		}
 	})
 }
+
+func TestRateLimitHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RateLimit(2, time.Minute))
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(200, gin.H{"message": "ok"})
+	})
+
+	for i, wantRemaining := range []string{"1", "0"} {
+		req, _ := http.NewRequest("GET", "/test", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != 200 {
+			t.Fatalf("Request %d: expected status 200, got %d", i+1, w.Code)
+		}
+		if got := w.Header().Get("X-RateLimit-Limit"); got != "2" {
+			t.Errorf("Request %d: X-RateLimit-Limit = %q, want 2", i+1, got)
+		}
+		if got := w.Header().Get("X-RateLimit-Remaining"); got != wantRemaining {
+			t.Errorf("Request %d: X-RateLimit-Remaining = %q, want %s", i+1, got, wantRemaining)
+		}
+		if w.Header().Get("Retry-After") != "" {
+			t.Errorf("Request %d: Retry-After set on an allowed request", i+1)
+		}
+	}
+
+	req, _ := http.NewRequest("GET", "/test", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429, got %d", w.Code)
+	}
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	if err != nil || retryAfter < 1 || retryAfter > 60 {
+		t.Errorf("Retry-After = %q, want 1-60 seconds", w.Header().Get("Retry-After"))
+	}
+	reset, err := strconv.ParseInt(w.Header().Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil || reset < time.Now().Unix() {
+		t.Errorf("X-RateLimit-Reset = %q, want a future Unix time", w.Header().Get("X-RateLimit-Reset"))
+	}
+}
+
+func TestRateLimitByUser_SeparateFromIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// One user exhausting their budget doesn't affect anonymous requests
+	// from the same IP, and vice versa.
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if c.GetHeader("X-Test-User") != "" {
+			c.Set("user_id", uint(7))
+		}
+		c.Next()
+	})
+	router.Use(RateLimitByUser(1, time.Minute))
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(200, gin.H{"message": "ok"})
+	})
+
+	send := func(asUser bool) int {
+		req, _ := http.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = "10.0.0.5:4000"
+		if asUser {
+			req.Header.Set("X-Test-User", "1")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := send(true); code != 200 {
+		t.Fatalf("user request: expected 200, got %d", code)
+	}
+	if code := send(true); code != http.StatusTooManyRequests {
+		t.Errorf("second user request: expected 429, got %d", code)
+	}
+	if code := send(false); code != 200 {
+		t.Errorf("anonymous request from same IP: expected 200, got %d", code)
+	}
+}
+
+func TestRateLimitFromEnv(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{"", 30},
+		{"120", 120},
+		{"0", 30},
+		{"-5", 30},
+		{"lots", 30},
+	}
+	for _, tt := range tests {
+		t.Setenv("TEST_RATE_LIMIT_PER_MINUTE", tt.value)
+		if got := RateLimitFromEnv("TEST_RATE_LIMIT_PER_MINUTE", 30); got != tt.want {
+			t.Errorf("RateLimitFromEnv(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestAuthRequired_GroupClaims(t *testing.T) {
+	buildRouter := func() *gin.Engine {
+		router := gin.New()
+		router.Use(AuthRequired(newMiddlewareTestDB(t)))
+		router.GET("/protected", func(c *gin.Context) {
+			m := GroupMembershipsFromContext(c.Request.Context())
+			if m == nil {
+				c.JSON(200, gin.H{"claims": false})
+				return
+			}
+			c.JSON(200, gin.H{"claims": true, "member": m.IsMember(7, 3), "admin": m.IsGroupAdmin(7, 3)})
+		})
+		return router
+	}
+	call := func(router *gin.Engine, token string) string {
+		req, _ := http.NewRequest("GET", "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != 200 {
+			t.Fatalf("status = %d, want 200, body = %s", w.Code, w.Body.String())
+		}
+		return w.Body.String()
+	}
+
+	withGroups, err := auth.GenerateUserTokenWithGroups(7, "vol", false, &auth.GroupClaims{GroupIDs: []uint{3}})
+	if err != nil {
+		t.Fatalf("GenerateUserTokenWithGroups() error = %v", err)
+	}
+	withoutGroups, err := auth.GenerateToken(7, false)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	if got := call(buildRouter(), withGroups); got != `{"admin":false,"claims":true,"member":true}` {
+		t.Errorf("fresh group claims: body = %s", got)
+	}
+	if got := call(buildRouter(), withoutGroups); got != `{"claims":false}` {
+		t.Errorf("token without group claims: body = %s", got)
+	}
+
+	t.Setenv("GROUP_CLAIMS_MAX_AGE_MINUTES", "0")
+	if got := call(buildRouter(), withGroups); got != `{"claims":false}` {
+		t.Errorf("group claims turned off: body = %s", got)
+	}
+}
+
+func TestGroupMembershipsFromClaims(t *testing.T) {
+	issued := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	claims := &auth.Claims{
+		UserID:           7,
+		Groups:           &auth.GroupClaims{GroupIDs: []uint{3}},
+		RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(issued)},
+	}
+	if m := groupMembershipsFromClaims(claims, 15*time.Minute, issued.Add(14*time.Minute)); m == nil || !m.IsMember(7, 3) {
+		t.Errorf("claims within max age = %+v, want membership in group 3", m)
+	}
+	if m := groupMembershipsFromClaims(claims, 15*time.Minute, issued.Add(15*time.Minute)); m != nil {
+		t.Errorf("claims at max age = %+v, want nil so the database is checked", m)
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+)
+
+func TestOrganizationScope(t *testing.T) {
+	db := newMiddlewareTestDB(t)
+	if err := db.AutoMigrate(&models.Organization{}, &models.Group{}); err != nil {
+		t.Fatalf("failed to migrate test db: %v", err)
+	}
+	north := models.Organization{Name: "North", Slug: "north"}
+	south := models.Organization{Name: "South", Slug: "south"}
+	db.Create(&north)
+	db.Create(&south)
+	northGroup := models.Group{Name: "North Dogs", OrganizationID: &north.ID}
+	unscopedGroup := models.Group{Name: "Unscoped"}
+	db.Create(&northGroup)
+	db.Create(&unscopedGroup)
+	northUser := models.User{Username: "n", Email: "n@example.com", Password: "x", OrganizationID: &north.ID}
+	southUser := models.User{Username: "s", Email: "s@example.com", Password: "x", OrganizationID: &south.ID}
+	siteAdmin := models.User{Username: "a", Email: "a@example.com", Password: "x", IsAdmin: true}
+	db.Create(&northUser)
+	db.Create(&southUser)
+	db.Create(&siteAdmin)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		var user models.User
+		db.Where("username = ?", c.GetHeader("X-User")).First(&user)
+		c.Set("user_id", user.ID)
+		c.Set("is_admin", user.IsAdmin)
+	}, OrganizationScope(db))
+	router.GET("/api/groups/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name  string
+		user  string
+		group uint
+		want  int
+	}{
+		{"same organization", "n", northGroup.ID, http.StatusOK},
+		{"other organization looks missing", "s", northGroup.ID, http.StatusNotFound},
+		{"organization user and unscoped group", "n", unscopedGroup.ID, http.StatusNotFound},
+		{"site admin reaches every organization", "a", northGroup.ID, http.StatusOK},
+		{"missing group is left to the handler", "s", 9999, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/groups/"+strconv.FormatUint(uint64(tt.group), 10), nil)
+			req.Header.Set("X-User", tt.user)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
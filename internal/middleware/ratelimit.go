@@ -2,7 +2,10 @@ package middleware
 
 import (
 	"fmt"
+	"math"
 	"net/http"
+	"os"
+	"strconv"
 	"sync"
 	"time"
 
@@ -60,19 +63,36 @@ func (rl *RateLimiter) cleanup() {
 	}
 }
 
+// RateLimitResult is the state of a bucket after a request was counted
+// against it.
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	Reset     time.Time // When the bucket refills
+}
+
 // Allow checks if a request should be allowed based on the key (e.g., IP address or user ID)
 func (rl *RateLimiter) Allow(key string) bool {
+	return rl.Take(key).Allowed
+}
+
+// Take counts a request against key's bucket and reports the bucket's state.
+func (rl *RateLimiter) Take(key string) RateLimitResult {
 	rl.mu.RLock()
 	b, exists := rl.buckets[key]
 	rl.mu.RUnlock()
 
 	if !exists {
 		rl.mu.Lock()
-		b = &bucket{
-			tokens:     rl.rate,
-			lastRefill: time.Now(),
+		// Re-check: another request may have created the bucket meanwhile
+		if b, exists = rl.buckets[key]; !exists {
+			b = &bucket{
+				tokens:     rl.rate,
+				lastRefill: time.Now(),
+			}
+			rl.buckets[key] = b
 		}
-		rl.buckets[key] = b
 		rl.mu.Unlock()
 	}
 
@@ -88,30 +108,52 @@ func (rl *RateLimiter) Allow(key string) bool {
 		b.lastRefill = now
 	}
 
+	result := RateLimitResult{Limit: rl.rate, Reset: b.lastRefill.Add(rl.window)}
 	if b.tokens > 0 {
 		b.tokens--
-		return true
+		result.Allowed = true
 	}
+	result.Remaining = b.tokens
+	return result
+}
 
-	return false
+// RateLimitFromEnv returns the per-minute budget set in envKey, or
+// defaultRate when it is unset or not a positive integer.
+func RateLimitFromEnv(envKey string, defaultRate int) int {
+	if v := os.Getenv(envKey); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return defaultRate
 }
 
-// RateLimit returns a middleware that rate limits requests based on IP address
-func RateLimit(rate int, window time.Duration) gin.HandlerFunc {
-	limiter := NewRateLimiter(rate, window)
+// setRateLimitHeaders reports a bucket's state in the X-RateLimit-* headers
+// (reset as Unix seconds), plus Retry-After in seconds when it is empty. When
+// several limiters apply to a route, the innermost one's headers win.
+func setRateLimitHeaders(c *gin.Context, result RateLimitResult) {
+	c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(result.Reset.Unix(), 10))
+	if !result.Allowed {
+		retryAfter := int(math.Ceil(time.Until(result.Reset).Seconds()))
+		c.Header("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+	}
+}
 
+// rateLimitBy returns a middleware that counts each request against the
+// bucket named by key.
+func rateLimitBy(limiter *RateLimiter, key func(c *gin.Context) (string, map[string]interface{})) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Use client IP as the key
-		clientIP := c.ClientIP()
+		k, fields := key(c)
+		result := limiter.Take(k)
+		setRateLimitHeaders(c, result)
 
-		if !limiter.Allow(clientIP) {
+		if !result.Allowed {
 			// Log rate limit exceeded with request ID for tracing
-			logger := GetLogger(c)
-			logger.WithFields(map[string]interface{}{
-				"ip":       clientIP,
-				"endpoint": c.Request.URL.Path,
-				"method":   c.Request.Method,
-			}).Warn("Rate limit exceeded")
+			fields["endpoint"] = c.Request.URL.Path
+			fields["method"] = c.Request.Method
+			GetLogger(c).WithFields(fields).Warn("Rate limit exceeded")
 
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error": "Too many requests. Please try again later.",
@@ -124,49 +166,24 @@ func RateLimit(rate int, window time.Duration) gin.HandlerFunc {
 	}
 }
 
-// RateLimitByUser returns a middleware that rate limits requests based on authenticated user ID
-func RateLimitByUser(rate int, window time.Duration) gin.HandlerFunc {
-	limiter := NewRateLimiter(rate, window)
+// RateLimit returns a middleware that rate limits requests based on IP address
+func RateLimit(rate int, window time.Duration) gin.HandlerFunc {
+	return rateLimitBy(NewRateLimiter(rate, window), func(c *gin.Context) (string, map[string]interface{}) {
+		clientIP := c.ClientIP()
+		return clientIP, map[string]interface{}{"ip": clientIP}
+	})
+}
 
-	return func(c *gin.Context) {
-		// Get user ID from context (set by AuthRequired middleware)
-		userID, exists := c.Get("user_id")
-		if !exists {
-			// If no user context, fall back to IP-based rate limiting
-			clientIP := c.ClientIP()
-			if !limiter.Allow(clientIP) {
-				logger := GetLogger(c)
-				logger.WithFields(map[string]interface{}{
-					"ip":       clientIP,
-					"endpoint": c.Request.URL.Path,
-					"method":   c.Request.Method,
-				}).Warn("Rate limit exceeded (unauthenticated)")
-
-				c.JSON(http.StatusTooManyRequests, gin.H{
-					"error": "Too many requests. Please try again later.",
-				})
-				c.Abort()
-				return
-			}
-		} else {
-			// Use user ID as the key
-			key := fmt.Sprintf("user_%d", userID.(uint))
-			if !limiter.Allow(key) {
-				logger := GetLogger(c)
-				logger.WithFields(map[string]interface{}{
-					"user_id":  userID,
-					"endpoint": c.Request.URL.Path,
-					"method":   c.Request.Method,
-				}).Warn("Rate limit exceeded (authenticated)")
-
-				c.JSON(http.StatusTooManyRequests, gin.H{
-					"error": "Too many requests. Please try again later.",
-				})
-				c.Abort()
-				return
-			}
+// RateLimitByUser returns a middleware that rate limits requests based on
+// the authenticated user ID (the JWT subject or API token owner), falling
+// back to the client IP for unauthenticated requests. It must run after
+// AuthRequired to see the user.
+func RateLimitByUser(rate int, window time.Duration) gin.HandlerFunc {
+	return rateLimitBy(NewRateLimiter(rate, window), func(c *gin.Context) (string, map[string]interface{}) {
+		if userID, ok := GetUserID(c); ok {
+			return fmt.Sprintf("user_%d", userID), map[string]interface{}{"user_id": userID}
 		}
-
-		c.Next()
-	}
+		clientIP := c.ClientIP()
+		return "ip_" + clientIP, map[string]interface{}{"ip": clientIP}
+	})
 }
@@ -3,6 +3,7 @@ package middleware
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
 )
 
 const RequestIDKey = "X-Request-ID"
@@ -18,8 +19,10 @@ func RequestID() gin.HandlerFunc {
 			requestID = uuid.New().String()
 		}
 
-		// Set request ID in context for use in handlers
+		// Set request ID in context for use in handlers, and on the request
+		// context so database and email logs carry it too
 		c.Set("request_id", requestID)
+		c.Request = c.Request.WithContext(logging.ContextWithRequestID(c.Request.Context(), requestID))
 
 		// Add request ID to response headers
 		c.Header(RequestIDKey, requestID)
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+)
+
+// minSCIMTokenLength is the shortest SCIM bearer token accepted; the token
+// grants full control over user provisioning.
+const minSCIMTokenLength = 32
+
+// SCIMTokenFromEnv returns the bearer token identity providers use for SCIM
+// provisioning, from SCIM_BEARER_TOKEN. An empty token with a nil error means
+// SCIM is not configured and its routes should not be registered.
+func SCIMTokenFromEnv() (string, error) {
+	token := strings.TrimSpace(os.Getenv("SCIM_BEARER_TOKEN"))
+	if token != "" && len(token) < minSCIMTokenLength {
+		return "", errors.New("SCIM_BEARER_TOKEN must be at least 32 characters")
+	}
+	return token, nil
+}
+
+// SCIMAuthRequired accepts only requests bearing token. Failures get a SCIM
+// error body, since identity providers are the only callers.
+func SCIMAuthRequired(token string) gin.HandlerFunc {
+	want := sha256.Sum256([]byte(token))
+	return func(c *gin.Context) {
+		presented, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		got := sha256.Sum256([]byte(presented))
+		if !ok || subtle.ConstantTimeCompare(got[:], want[:]) != 1 {
+			logging.LogUnauthorizedAccess(c.Request.Context(), c.ClientIP(), c.Request.URL.Path, "invalid_scim_token")
+			c.Header("Content-Type", "application/scim+json")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"schemas": []string{"urn:ietf:params:scim:api:messages:2.0:Error"},
+				"status":  "401",
+				"detail":  "Invalid or missing bearer token",
+			})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSCIMTokenFromEnv(t *testing.T) {
+	t.Setenv("SCIM_BEARER_TOKEN", "")
+	if token, err := SCIMTokenFromEnv(); token != "" || err != nil {
+		t.Errorf("unset token: got %q, %v; want disabled", token, err)
+	}
+
+	t.Setenv("SCIM_BEARER_TOKEN", "too-short")
+	if _, err := SCIMTokenFromEnv(); err == nil {
+		t.Error("expected an error for a short token")
+	}
+
+	long := strings.Repeat("k", minSCIMTokenLength)
+	t.Setenv("SCIM_BEARER_TOKEN", " "+long+" ")
+	if token, err := SCIMTokenFromEnv(); token != long || err != nil {
+		t.Errorf("got %q, %v; want the trimmed token", token, err)
+	}
+}
+
+func TestSCIMAuthRequired(t *testing.T) {
+	token := strings.Repeat("s", minSCIMTokenLength)
+	router := gin.New()
+	router.GET("/scim/v2/Users", SCIMAuthRequired(token), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"valid token", "Bearer " + token, http.StatusOK},
+		{"wrong token", "Bearer " + strings.Repeat("x", minSCIMTokenLength), http.StatusUnauthorized},
+		{"missing header", "", http.StatusUnauthorized},
+		{"not a bearer token", "Basic " + token, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/scim/v2/Users", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("got %d, want %d", w.Code, tt.want)
+			}
+			if tt.want == http.StatusUnauthorized && !strings.Contains(w.Header().Get("Content-Type"), "application/scim+json") {
+				t.Errorf("got Content-Type %q, want a SCIM error", w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
@@ -1,37 +1,29 @@
 package middleware
 
 import (
-	"os"
+	"fmt"
 
 	"github.com/gin-gonic/gin"
 )
 
-// SecurityHeaders adds security headers to all responses
-func SecurityHeaders() gin.HandlerFunc {
+// SecurityHeaders adds security headers to all responses. CSP, frame options,
+// and HSTS are resolved through store (site settings with env override); a
+// nil store uses environment variables and the built-in defaults.
+func SecurityHeaders(store *SecurityConfigStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		cfg := store.Get(c.Request.Context())
+
 		// Prevent MIME type sniffing
 		c.Header("X-Content-Type-Options", "nosniff")
 
 		// Prevent clickjacking attacks
-		c.Header("X-Frame-Options", "DENY")
+		c.Header("X-Frame-Options", cfg.FrameOptions)
 
 		// Enable XSS protection (legacy but still useful)
 		c.Header("X-XSS-Protection", "1; mode=block")
 
-		// Content Security Policy - strict policy for security
-		// Note: Adjust CSP based on your frontend requirements
-		csp := "default-src 'self'; " +
-			"script-src 'self' 'unsafe-inline' 'unsafe-eval'; " +
-			"style-src 'self' 'unsafe-inline'; " +
-			"img-src 'self' data: blob: https:; " +
-			"media-src 'self' blob:; " +
-			"font-src 'self' data:; " +
-			"frame-src 'self' blob:; " +
-			"connect-src 'self'; " +
-			"frame-ancestors 'none'; " +
-			"base-uri 'self'; " +
-			"form-action 'self'"
-		c.Header("Content-Security-Policy", csp)
+		// Content Security Policy - strict by default; see defaultCSP
+		c.Header("Content-Security-Policy", cfg.ContentSecurityPolicy)
 
 		// Referrer policy - don't leak referrer information
 		c.Header("Referrer-Policy", "strict-origin-when-cross-origin")
@@ -39,10 +31,9 @@ func SecurityHeaders() gin.HandlerFunc {
 		// Permissions policy - restrict feature access
 		c.Header("Permissions-Policy", "geolocation=(), microphone=(), camera=()")
 
-		// HSTS - enable in production when HTTPS is configured
-		// Check if running in production and enable HSTS
-		if os.Getenv("ENV") == "production" || os.Getenv("ENABLE_HSTS") == "true" {
-			c.Header("Strict-Transport-Security", "max-age=31536000; includeSubDomains; preload")
+		// HSTS - on by default in production, when HTTPS is configured
+		if cfg.HSTSEnabled {
+			c.Header("Strict-Transport-Security", fmt.Sprintf("max-age=%d; includeSubDomains; preload", cfg.HSTSMaxAge))
 		}
 
 		c.Next()
@@ -0,0 +1,394 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// Site setting keys for CORS, security headers, the login lockout policy,
+// and password login. Each can be overridden by the environment variable named in
+// securitySettingEnv.
+const (
+	SettingAllowedOrigins        = "cors_allowed_origins"
+	SettingAllowedMethods        = "cors_allowed_methods"
+	SettingAllowedHeaders        = "cors_allowed_headers"
+	SettingCORSMaxAge            = "cors_max_age"
+	SettingHSTSEnabled           = "hsts_enabled"
+	SettingHSTSMaxAge            = "hsts_max_age"
+	SettingContentSecurityPolicy = "content_security_policy"
+	SettingFrameOptions          = "frame_options"
+	SettingLockoutMaxAttempts    = "lockout_max_attempts"
+	SettingLockoutDuration       = "lockout_duration_minutes"
+	SettingLockoutBackoff        = "lockout_backoff_multiplier"
+	SettingLockoutMaxDuration    = "lockout_max_duration_minutes"
+	SettingPasswordLoginEnabled  = "password_login_enabled"
+)
+
+// Where an effective security value came from.
+const (
+	SecuritySourceEnv     = "env"
+	SecuritySourceSetting = "setting"
+	SecuritySourceDefault = "default"
+)
+
+var securitySettingEnv = map[string]string{
+	SettingAllowedOrigins:        "ALLOWED_ORIGINS",
+	SettingAllowedMethods:        "CORS_ALLOWED_METHODS",
+	SettingAllowedHeaders:        "CORS_ALLOWED_HEADERS",
+	SettingCORSMaxAge:            "CORS_MAX_AGE",
+	SettingHSTSEnabled:           "ENABLE_HSTS",
+	SettingHSTSMaxAge:            "HSTS_MAX_AGE",
+	SettingContentSecurityPolicy: "CONTENT_SECURITY_POLICY",
+	SettingFrameOptions:          "FRAME_OPTIONS",
+	SettingLockoutMaxAttempts:    "LOCKOUT_MAX_ATTEMPTS",
+	SettingLockoutDuration:       "LOCKOUT_DURATION_MINUTES",
+	SettingLockoutBackoff:        "LOCKOUT_BACKOFF_MULTIPLIER",
+	SettingLockoutMaxDuration:    "LOCKOUT_MAX_DURATION_MINUTES",
+	SettingPasswordLoginEnabled:  "PASSWORD_LOGIN_ENABLED",
+}
+
+const (
+	defaultAllowedOrigins = "http://localhost:5173,http://localhost:3000"
+	defaultAllowedMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+	defaultAllowedHeaders = "Content-Type, Authorization, " + CSRFHeader
+	defaultCORSMaxAge     = 600 // seconds
+	maxCORSMaxAge         = 86400
+	defaultHSTSMaxAge     = 31536000
+	defaultFrameOptions   = "DENY"
+	defaultCSP            = "default-src 'self'; " +
+		"script-src 'self' 'unsafe-inline' 'unsafe-eval'; " +
+		"style-src 'self' 'unsafe-inline'; " +
+		"img-src 'self' data: blob: https:; " +
+		"media-src 'self' blob:; " +
+		"font-src 'self' data:; " +
+		"frame-src 'self' blob:; " +
+		"connect-src 'self'; " +
+		"frame-ancestors 'none'; " +
+		"base-uri 'self'; " +
+		"form-action 'self'"
+
+	defaultLockoutMaxAttempts = 5
+	defaultLockoutDuration    = 30   // minutes
+	defaultLockoutBackoff     = 1    // no backoff
+	defaultLockoutMaxDuration = 1440 // minutes
+	maxLockoutDurationMinutes = 7 * 24 * 60
+
+	// securityConfigTTL bounds how long a replica serves stale settings
+	// after another replica's admin changes them. Changes made through this
+	// replica apply immediately via Invalidate.
+	securityConfigTTL = 30 * time.Second
+)
+
+// SecurityConfig is the effective CORS, security header, login lockout, and
+// password login configuration.
+type SecurityConfig struct {
+	AllowedOrigins        []string `json:"allowed_origins"`
+	AllowedMethods        []string `json:"allowed_methods"`
+	AllowedHeaders        []string `json:"allowed_headers"`
+	CORSMaxAge            int      `json:"cors_max_age"` // Seconds browsers may cache a preflight
+	HSTSEnabled           bool     `json:"hsts_enabled"`
+	HSTSMaxAge            int      `json:"hsts_max_age"`
+	ContentSecurityPolicy string   `json:"content_security_policy"`
+	FrameOptions          string   `json:"frame_options"`
+
+	// An account is locked after LockoutMaxAttempts consecutive failed
+	// logins. Each further lockout before a successful login lasts
+	// LockoutBackoffMultiplier times longer, up to LockoutMaxDurationMinutes.
+	LockoutMaxAttempts        int `json:"lockout_max_attempts"`
+	LockoutDurationMinutes    int `json:"lockout_duration_minutes"`
+	LockoutBackoffMultiplier  int `json:"lockout_backoff_multiplier"`
+	LockoutMaxDurationMinutes int `json:"lockout_max_duration_minutes"`
+
+	// PasswordLoginEnabled is false when volunteers must sign in through an
+	// OIDC provider. Site admins can always use their password, so a
+	// misconfigured provider can't lock everyone out.
+	PasswordLoginEnabled bool `json:"password_login_enabled"`
+
+	Sources map[string]string `json:"sources"` // setting key -> env, setting, or default
+}
+
+// LockoutDuration returns how long to lock an account that has already been
+// locked previousLockouts times since its last successful login.
+func (cfg SecurityConfig) LockoutDuration(previousLockouts int) time.Duration {
+	minutes := cfg.LockoutDurationMinutes
+	for i := 0; i < previousLockouts && minutes < cfg.LockoutMaxDurationMinutes && cfg.LockoutBackoffMultiplier > 1; i++ {
+		minutes *= cfg.LockoutBackoffMultiplier
+	}
+	if minutes > cfg.LockoutMaxDurationMinutes {
+		minutes = cfg.LockoutMaxDurationMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// AllowsOrigin reports whether origin may make credentialed cross-origin
+// requests, and the Access-Control-Allow-Origin value to send.
+func (cfg SecurityConfig) AllowsOrigin(origin string) (string, bool) {
+	for _, allowed := range cfg.AllowedOrigins {
+		if origin != "" && allowed == origin {
+			return origin, true
+		}
+	}
+	if len(cfg.AllowedOrigins) == 1 && cfg.AllowedOrigins[0] == "*" {
+		return "*", true
+	}
+	return "", false
+}
+
+// SecurityConfigStore resolves SecurityConfig from environment variables and
+// site settings, caching the result for securityConfigTTL. A nil store
+// resolves from environment variables and defaults only.
+type SecurityConfigStore struct {
+	db       *gorm.DB
+	mu       sync.Mutex
+	cfg      SecurityConfig
+	loadedAt time.Time
+}
+
+// NewSecurityConfigStore returns a store that reads site settings from db.
+func NewSecurityConfigStore(db *gorm.DB) *SecurityConfigStore {
+	return &SecurityConfigStore{db: db}
+}
+
+// Get returns the effective configuration, reloading it from site settings
+// when the cached copy has expired. If settings can't be read, the last
+// loaded configuration (or env and defaults) is used.
+func (s *SecurityConfigStore) Get(ctx context.Context) SecurityConfig {
+	if s == nil || s.db == nil {
+		return resolveSecurityConfig(nil)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.loadedAt.IsZero() && time.Since(s.loadedAt) < securityConfigTTL {
+		return s.cfg
+	}
+
+	keys := make([]string, 0, len(securitySettingEnv))
+	for key := range securitySettingEnv {
+		keys = append(keys, key)
+	}
+	var settings []models.SiteSetting
+	if err := s.db.WithContext(ctx).Where("key IN ?", keys).Find(&settings).Error; err != nil {
+		logging.Error("Failed to load security settings", err)
+		if s.loadedAt.IsZero() {
+			return resolveSecurityConfig(nil)
+		}
+		return s.cfg
+	}
+	values := make(map[string]string, len(settings))
+	for _, setting := range settings {
+		values[setting.Key] = setting.Value
+	}
+	s.cfg = resolveSecurityConfig(values)
+	s.loadedAt = time.Now()
+	return s.cfg
+}
+
+// Invalidate forces the next Get to reload site settings.
+func (s *SecurityConfigStore) Invalidate() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+// resolveSecurityConfig merges environment variables, site setting values,
+// and defaults, in that order of precedence. Invalid setting values (e.g.
+// rows written before validation existed) fall back to the default.
+func resolveSecurityConfig(settings map[string]string) SecurityConfig {
+	cfg := SecurityConfig{Sources: make(map[string]string, len(securitySettingEnv))}
+	lookup := func(key string) (string, string) {
+		if v := os.Getenv(securitySettingEnv[key]); v != "" {
+			return v, SecuritySourceEnv
+		}
+		if v := strings.TrimSpace(settings[key]); v != "" && ValidateSecuritySetting(key, v) == nil {
+			return v, SecuritySourceSetting
+		}
+		return "", SecuritySourceDefault
+	}
+
+	origins, source := lookup(SettingAllowedOrigins)
+	if source == SecuritySourceDefault {
+		origins = defaultAllowedOrigins
+	}
+	cfg.AllowedOrigins = splitList(origins)
+	cfg.Sources[SettingAllowedOrigins] = source
+
+	methods, source := lookup(SettingAllowedMethods)
+	if source == SecuritySourceDefault {
+		methods = defaultAllowedMethods
+	}
+	cfg.AllowedMethods = splitList(strings.ToUpper(methods))
+	cfg.Sources[SettingAllowedMethods] = source
+
+	headers, source := lookup(SettingAllowedHeaders)
+	if source == SecuritySourceDefault {
+		headers = defaultAllowedHeaders
+	}
+	cfg.AllowedHeaders = splitList(headers)
+	cfg.Sources[SettingAllowedHeaders] = source
+
+	hsts, source := lookup(SettingHSTSEnabled)
+	if source == SecuritySourceDefault {
+		cfg.HSTSEnabled = os.Getenv("ENV") == "production"
+	} else {
+		cfg.HSTSEnabled = hsts == "true"
+	}
+	cfg.Sources[SettingHSTSEnabled] = source
+
+	cfg.HSTSMaxAge = defaultHSTSMaxAge
+	maxAge, source := lookup(SettingHSTSMaxAge)
+	if n, err := strconv.Atoi(maxAge); err == nil && n >= 0 {
+		cfg.HSTSMaxAge = n
+	} else {
+		source = SecuritySourceDefault
+	}
+	cfg.Sources[SettingHSTSMaxAge] = source
+
+	cfg.ContentSecurityPolicy, source = lookup(SettingContentSecurityPolicy)
+	if source == SecuritySourceDefault {
+		cfg.ContentSecurityPolicy = defaultCSP
+	}
+	cfg.Sources[SettingContentSecurityPolicy] = source
+
+	frame, source := lookup(SettingFrameOptions)
+	if source == SecuritySourceDefault {
+		frame = defaultFrameOptions
+	}
+	cfg.FrameOptions = strings.ToUpper(frame)
+	cfg.Sources[SettingFrameOptions] = source
+
+	intValue := func(key string, def int) int {
+		v, source := lookup(key)
+		if err := ValidateSecuritySetting(key, v); v == "" || err != nil {
+			v, source = "", SecuritySourceDefault
+		}
+		cfg.Sources[key] = source
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+		return def
+	}
+	cfg.CORSMaxAge = intValue(SettingCORSMaxAge, defaultCORSMaxAge)
+	cfg.LockoutMaxAttempts = intValue(SettingLockoutMaxAttempts, defaultLockoutMaxAttempts)
+	cfg.LockoutDurationMinutes = intValue(SettingLockoutDuration, defaultLockoutDuration)
+	cfg.LockoutBackoffMultiplier = intValue(SettingLockoutBackoff, defaultLockoutBackoff)
+	cfg.LockoutMaxDurationMinutes = intValue(SettingLockoutMaxDuration, defaultLockoutMaxDuration)
+	if cfg.LockoutMaxDurationMinutes < cfg.LockoutDurationMinutes {
+		cfg.LockoutMaxDurationMinutes = cfg.LockoutDurationMinutes
+	}
+
+	passwordLogin, source := lookup(SettingPasswordLoginEnabled)
+	cfg.PasswordLoginEnabled = passwordLogin != "false"
+	cfg.Sources[SettingPasswordLoginEnabled] = source
+
+	return cfg
+}
+
+// splitList splits a comma-separated setting value, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// IsSecuritySetting reports whether key is one of the security setting keys.
+func IsSecuritySetting(key string) bool {
+	_, ok := securitySettingEnv[key]
+	return ok
+}
+
+// ValidateSecuritySetting checks a site setting value for a security key.
+// An empty value is always valid and means "use the default".
+func ValidateSecuritySetting(key, value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("%s must be a single line", key)
+	}
+
+	switch key {
+	case SettingAllowedOrigins:
+		if value == "*" {
+			return nil
+		}
+		for _, o := range strings.Split(value, ",") {
+			o = strings.TrimSpace(o)
+			u, err := url.Parse(o)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+				u.Path != "" || u.RawQuery != "" || u.Fragment != "" {
+				return fmt.Errorf("%s: %q is not an origin like https://example.org", key, o)
+			}
+		}
+	case SettingAllowedMethods:
+		for _, m := range splitList(value) {
+			switch strings.ToUpper(m) {
+			case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions:
+			default:
+				return fmt.Errorf("%s: %q is not an HTTP method", key, m)
+			}
+		}
+	case SettingAllowedHeaders:
+		for _, h := range splitList(value) {
+			if strings.IndexFunc(h, func(r rune) bool {
+				return !(r == '-' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+			}) >= 0 {
+				return fmt.Errorf("%s: %q is not a header name", key, h)
+			}
+		}
+	case SettingCORSMaxAge:
+		if n, err := strconv.Atoi(value); err != nil || n < 0 || n > maxCORSMaxAge {
+			return fmt.Errorf("%s must be between 0 and %d seconds", key, maxCORSMaxAge)
+		}
+	case SettingHSTSEnabled, SettingPasswordLoginEnabled:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%s must be true or false", key)
+		}
+	case SettingHSTSMaxAge:
+		if n, err := strconv.Atoi(value); err != nil || n < 0 {
+			return fmt.Errorf("%s must be a non-negative number of seconds", key)
+		}
+	case SettingContentSecurityPolicy:
+		if len(value) > 2000 {
+			return fmt.Errorf("%s must be 2000 characters or less", key)
+		}
+	case SettingFrameOptions:
+		if v := strings.ToUpper(value); v != "DENY" && v != "SAMEORIGIN" {
+			return fmt.Errorf("%s must be DENY or SAMEORIGIN", key)
+		}
+	case SettingLockoutMaxAttempts:
+		if n, err := strconv.Atoi(value); err != nil || n < 1 || n > 100 {
+			return fmt.Errorf("%s must be between 1 and 100", key)
+		}
+	case SettingLockoutDuration, SettingLockoutMaxDuration:
+		if n, err := strconv.Atoi(value); err != nil || n < 1 || n > maxLockoutDurationMinutes {
+			return fmt.Errorf("%s must be between 1 and %d minutes", key, maxLockoutDurationMinutes)
+		}
+	case SettingLockoutBackoff:
+		if n, err := strconv.Atoi(value); err != nil || n < 1 || n > 10 {
+			return fmt.Errorf("%s must be between 1 and 10", key)
+		}
+	default:
+		return errors.New("not a security setting")
+	}
+	return nil
+}
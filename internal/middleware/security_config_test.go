@@ -0,0 +1,212 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// clearSecurityEnv unsets every env override so site settings take effect.
+func clearSecurityEnv(t *testing.T) {
+	t.Helper()
+	for _, env := range securitySettingEnv {
+		t.Setenv(env, "")
+	}
+	t.Setenv("ENV", "")
+}
+
+func newSecuritySettingsDB(t *testing.T, settings map[string]string) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test db: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(&models.SiteSetting{}); err != nil {
+		t.Fatalf("failed to migrate test db: %v", err)
+	}
+	for key, value := range settings {
+		db.Create(&models.SiteSetting{Key: key, Value: value})
+	}
+	return db
+}
+
+func TestResolveSecurityConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		clearSecurityEnv(t)
+		cfg := resolveSecurityConfig(nil)
+		if cfg.FrameOptions != "DENY" || cfg.ContentSecurityPolicy != defaultCSP || cfg.HSTSEnabled {
+			t.Errorf("unexpected defaults: %+v", cfg)
+		}
+		if len(cfg.AllowedOrigins) != 2 {
+			t.Errorf("AllowedOrigins = %v, want the two localhost dev origins", cfg.AllowedOrigins)
+		}
+	})
+
+	t.Run("settings apply and env overrides them", func(t *testing.T) {
+		clearSecurityEnv(t)
+		t.Setenv("FRAME_OPTIONS", "DENY")
+		cfg := resolveSecurityConfig(map[string]string{
+			SettingAllowedOrigins: "https://volunteers.example.org, https://admin.example.org",
+			SettingHSTSEnabled:    "true",
+			SettingHSTSMaxAge:     "600",
+			SettingFrameOptions:   "sameorigin",
+		})
+		if len(cfg.AllowedOrigins) != 2 || cfg.AllowedOrigins[1] != "https://admin.example.org" {
+			t.Errorf("AllowedOrigins = %v", cfg.AllowedOrigins)
+		}
+		if !cfg.HSTSEnabled || cfg.HSTSMaxAge != 600 {
+			t.Errorf("HSTS = %v/%d, want true/600", cfg.HSTSEnabled, cfg.HSTSMaxAge)
+		}
+		if cfg.FrameOptions != "DENY" || cfg.Sources[SettingFrameOptions] != SecuritySourceEnv {
+			t.Errorf("FrameOptions = %q from %q, want DENY from env", cfg.FrameOptions, cfg.Sources[SettingFrameOptions])
+		}
+		if cfg.Sources[SettingAllowedOrigins] != SecuritySourceSetting {
+			t.Errorf("origins source = %q, want setting", cfg.Sources[SettingAllowedOrigins])
+		}
+	})
+
+	t.Run("invalid stored values fall back to defaults", func(t *testing.T) {
+		clearSecurityEnv(t)
+		cfg := resolveSecurityConfig(map[string]string{
+			SettingFrameOptions:          "ALLOW-FROM https://evil.example",
+			SettingContentSecurityPolicy: "default-src 'self'\r\nX-Injected: 1",
+		})
+		if cfg.FrameOptions != "DENY" || cfg.ContentSecurityPolicy != defaultCSP {
+			t.Errorf("invalid settings were applied: %+v", cfg)
+		}
+	})
+}
+
+func TestSecurityConfig_LockoutDuration(t *testing.T) {
+	clearSecurityEnv(t)
+	cfg := resolveSecurityConfig(nil)
+	if cfg.LockoutMaxAttempts != 5 || cfg.LockoutDuration(3) != 30*time.Minute {
+		t.Errorf("default policy = %d attempts, %v after 3 lockouts; want 5 and 30m without backoff", cfg.LockoutMaxAttempts, cfg.LockoutDuration(3))
+	}
+
+	t.Setenv("LOCKOUT_BACKOFF_MULTIPLIER", "2")
+	cfg = resolveSecurityConfig(map[string]string{
+		SettingLockoutDuration:    "10",
+		SettingLockoutMaxDuration: "60",
+	})
+	want := []time.Duration{10 * time.Minute, 20 * time.Minute, 40 * time.Minute, 60 * time.Minute, 60 * time.Minute}
+	for previous, d := range want {
+		if got := cfg.LockoutDuration(previous); got != d {
+			t.Errorf("LockoutDuration(%d) = %v, want %v", previous, got, d)
+		}
+	}
+	if cfg.Sources[SettingLockoutBackoff] != SecuritySourceEnv || cfg.Sources[SettingLockoutDuration] != SecuritySourceSetting {
+		t.Errorf("unexpected sources: %v", cfg.Sources)
+	}
+}
+
+func TestSecurityConfig_PasswordLogin(t *testing.T) {
+	clearSecurityEnv(t)
+	if !resolveSecurityConfig(nil).PasswordLoginEnabled {
+		t.Error("password login should be enabled by default")
+	}
+	cfg := resolveSecurityConfig(map[string]string{SettingPasswordLoginEnabled: "false"})
+	if cfg.PasswordLoginEnabled || cfg.Sources[SettingPasswordLoginEnabled] != SecuritySourceSetting {
+		t.Errorf("setting false: enabled = %v, source %q", cfg.PasswordLoginEnabled, cfg.Sources[SettingPasswordLoginEnabled])
+	}
+	t.Setenv("PASSWORD_LOGIN_ENABLED", "true")
+	if !resolveSecurityConfig(map[string]string{SettingPasswordLoginEnabled: "false"}).PasswordLoginEnabled {
+		t.Error("the environment variable should win over the setting")
+	}
+}
+
+func TestValidateSecuritySetting(t *testing.T) {
+	tests := []struct {
+		key, value string
+		wantErr    bool
+	}{
+		{SettingAllowedOrigins, "https://a.example.org,http://localhost:5173", false},
+		{SettingAllowedOrigins, "*", false},
+		{SettingAllowedOrigins, "https://a.example.org/path", true},
+		{SettingAllowedOrigins, "a.example.org", true},
+		{SettingAllowedMethods, "GET, post, PATCH", false},
+		{SettingAllowedMethods, "GET, CONNECT", true},
+		{SettingAllowedHeaders, "Content-Type, X-CSRF-Token", false},
+		{SettingAllowedHeaders, "X Bad", true},
+		{SettingCORSMaxAge, "0", false},
+		{SettingCORSMaxAge, "90000", true},
+		{SettingHSTSEnabled, "yes", true},
+		{SettingHSTSMaxAge, "-1", true},
+		{SettingFrameOptions, "SAMEORIGIN", false},
+		{SettingFrameOptions, "ALLOWALL", true},
+		{SettingContentSecurityPolicy, "default-src 'self'\nX-Injected: 1", true},
+		{SettingContentSecurityPolicy, "", false},
+		{SettingLockoutMaxAttempts, "0", true},
+		{SettingLockoutMaxAttempts, "10", false},
+		{SettingLockoutDuration, "15", false},
+		{SettingLockoutMaxDuration, "20000", true},
+		{SettingLockoutBackoff, "2", false},
+		{SettingLockoutBackoff, "1.5", true},
+		{SettingPasswordLoginEnabled, "false", false},
+		{SettingPasswordLoginEnabled, "no", true},
+	}
+	for _, tt := range tests {
+		err := ValidateSecuritySetting(tt.key, tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateSecuritySetting(%q, %q) error = %v, wantErr %v", tt.key, tt.value, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSecurityConfigStore_HotReload(t *testing.T) {
+	clearSecurityEnv(t)
+	db := newSecuritySettingsDB(t, map[string]string{SettingAllowedOrigins: "https://old.example.org"})
+	store := NewSecurityConfigStore(db)
+
+	router := gin.New()
+	router.Use(SecurityHeaders(store), CORS(store))
+	router.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(origin string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/test", nil)
+		req.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if got := request("https://old.example.org").Header().Get("Access-Control-Allow-Origin"); got != "https://old.example.org" {
+		t.Fatalf("Allow-Origin = %q, want the configured origin", got)
+	}
+
+	db.Model(&models.SiteSetting{}).Where("key = ?", SettingAllowedOrigins).Update("value", "https://new.example.org")
+	db.Create(&models.SiteSetting{Key: SettingFrameOptions, Value: "SAMEORIGIN"})
+	if got := request("https://new.example.org").Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Allow-Origin = %q before invalidation, want cached config", got)
+	}
+
+	store.Invalidate()
+	w := request("https://new.example.org")
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://new.example.org" {
+		t.Errorf("Allow-Origin = %q after invalidation, want the new origin", got)
+	}
+	if got := w.Header().Get("X-Frame-Options"); got != "SAMEORIGIN" {
+		t.Errorf("X-Frame-Options = %q, want SAMEORIGIN", got)
+	}
+}
+
+func TestSecurityConfigStore_FallsBackWhenSettingsUnavailable(t *testing.T) {
+	clearSecurityEnv(t)
+	db := newSecuritySettingsDB(t, nil)
+	sqlDB, _ := db.DB()
+	sqlDB.Close()
+
+	cfg := NewSecurityConfigStore(db).Get(context.Background())
+	if cfg.FrameOptions != "DENY" || cfg.ContentSecurityPolicy != defaultCSP {
+		t.Errorf("expected defaults when settings can't be read, got %+v", cfg)
+	}
+}
@@ -0,0 +1,184 @@
+package middleware
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/auth"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+)
+
+// Session modes, from SESSION_MODE. A bearer session hands the JWT to the
+// client to send in the Authorization header. A cookie session keeps it in
+// an HttpOnly cookie scripts can't read, and state-changing requests must
+// carry the session's CSRF token. Bearer JWTs and API tokens are accepted
+// in either mode.
+const (
+	SessionModeBearer = "bearer"
+	SessionModeCookie = "cookie"
+)
+
+const (
+	// SessionCookie holds a cookie session's JWT
+	SessionCookie = "session"
+	// CSRFCookie holds the session's CSRF token, readable by the frontend
+	CSRFCookie = "csrf_token"
+	// CSRFHeader carries the CSRF token on state-changing requests
+	CSRFHeader = "X-CSRF-Token"
+
+	sessionCookiePath = "/api"
+	csrfPurpose       = "csrf"
+	// sessionCookieAuthKey marks a request authenticated by the session cookie
+	sessionCookieAuthKey = "session_cookie_auth"
+)
+
+// SessionConfig is how logins hand out sessions, and the attributes of
+// session cookies.
+type SessionConfig struct {
+	Mode     string
+	SameSite http.SameSite
+	Secure   bool
+	Domain   string // Empty scopes cookies to the API's host
+}
+
+// SessionConfigFromEnv reads SESSION_MODE (bearer or cookie; default
+// bearer), SESSION_COOKIE_SAMESITE (lax, strict, or none; default lax),
+// SESSION_COOKIE_SECURE (default true when ENV=production), and
+// SESSION_COOKIE_DOMAIN. Invalid values fall back to the default. SameSite
+// none cookies are always Secure, since browsers drop them otherwise.
+func SessionConfigFromEnv() SessionConfig {
+	cfg := SessionConfig{
+		Mode:     SessionModeBearer,
+		SameSite: http.SameSiteLaxMode,
+		Secure:   os.Getenv("ENV") == "production",
+		Domain:   strings.TrimSpace(os.Getenv("SESSION_COOKIE_DOMAIN")),
+	}
+	switch v := strings.ToLower(strings.TrimSpace(os.Getenv("SESSION_MODE"))); v {
+	case "", SessionModeBearer:
+	case SessionModeCookie:
+		cfg.Mode = SessionModeCookie
+	default:
+		logging.WithField("value", v).Warn("Invalid SESSION_MODE, using bearer")
+	}
+	switch v := strings.ToLower(strings.TrimSpace(os.Getenv("SESSION_COOKIE_SAMESITE"))); v {
+	case "", "lax":
+	case "strict":
+		cfg.SameSite = http.SameSiteStrictMode
+	case "none":
+		cfg.SameSite = http.SameSiteNoneMode
+	default:
+		logging.WithField("value", v).Warn("Invalid SESSION_COOKIE_SAMESITE, using lax")
+	}
+	switch v := strings.TrimSpace(os.Getenv("SESSION_COOKIE_SECURE")); v {
+	case "":
+	case "true", "false":
+		cfg.Secure = v == "true"
+	default:
+		logging.WithField("value", v).Warn("Invalid SESSION_COOKIE_SECURE, using default")
+	}
+	if cfg.SameSite == http.SameSiteNoneMode {
+		cfg.Secure = true
+	}
+	return cfg
+}
+
+// Cookies reports whether logins start cookie sessions
+func (cfg SessionConfig) Cookies() bool {
+	return cfg.Mode == SessionModeCookie
+}
+
+// StartCookieSession stores token in the session cookie, and its CSRF token
+// in a cookie the frontend can read, until the token expires. It returns
+// the CSRF token.
+func (cfg SessionConfig) StartCookieSession(c *gin.Context, token string) (string, error) {
+	claims, err := auth.ValidateToken(token)
+	if err != nil {
+		return "", err
+	}
+	csrfToken, err := CSRFToken(token)
+	if err != nil {
+		return "", err
+	}
+	expires := time.Now().Add(24 * time.Hour)
+	if claims.ExpiresAt != nil {
+		expires = claims.ExpiresAt.Time
+	}
+	cfg.setCookie(c, SessionCookie, token, sessionCookiePath, expires, true)
+	cfg.setCookie(c, CSRFCookie, csrfToken, "/", expires, false)
+	return csrfToken, nil
+}
+
+// EndCookieSession clears the session and CSRF cookies
+func (cfg SessionConfig) EndCookieSession(c *gin.Context) {
+	cfg.setCookie(c, SessionCookie, "", sessionCookiePath, time.Unix(0, 0), true)
+	cfg.setCookie(c, CSRFCookie, "", "/", time.Unix(0, 0), false)
+}
+
+func (cfg SessionConfig) setCookie(c *gin.Context, name, value, path string, expires time.Time, httpOnly bool) {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   cfg.Domain,
+		Expires:  expires,
+		Secure:   cfg.Secure,
+		HttpOnly: httpOnly,
+		SameSite: cfg.SameSite,
+	}
+	if value == "" {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(c.Writer, cookie)
+}
+
+// CSRFToken returns the CSRF token of the session whose JWT is
+// sessionToken. It is a signature of the JWT, so it can't be forged or
+// carried over to another session, and nothing is stored server-side.
+func CSRFToken(sessionToken string) (string, error) {
+	return auth.Sign(csrfPurpose, sessionToken)
+}
+
+// CookieSessionAuth reports whether AuthRequired authenticated the request
+// with the session cookie
+func CookieSessionAuth(c *gin.Context) bool {
+	return c.GetBool(sessionCookieAuthKey)
+}
+
+// CSRFRequired rejects state-changing requests authenticated by the session
+// cookie unless the CSRF header carries the session's CSRF token. Browsers
+// attach cookies to cross-site requests on their own, but never an
+// Authorization header, so bearer JWT and API token requests pass. Use it
+// after AuthRequired.
+func CSRFRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if !CookieSessionAuth(c) {
+			c.Next()
+			return
+		}
+
+		session, _ := c.Cookie(SessionCookie)
+		if !auth.VerifySignature(csrfPurpose, session, c.GetHeader(CSRFHeader)) {
+			logger := GetLogger(c)
+			logger.WithFields(map[string]interface{}{
+				"ip":       c.ClientIP(),
+				"endpoint": c.Request.URL.Path,
+				"method":   c.Request.Method,
+			}).Warn("Missing or invalid CSRF token")
+
+			logging.LogUnauthorizedAccess(c.Request.Context(), c.ClientIP(), c.Request.URL.Path, "invalid_csrf_token")
+
+			c.JSON(http.StatusForbidden, gin.H{"error": "Missing or invalid CSRF token"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
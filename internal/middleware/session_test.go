@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/auth"
+)
+
+func TestSessionConfigFromEnv(t *testing.T) {
+	t.Setenv("ENV", "")
+	t.Setenv("SESSION_MODE", "")
+	t.Setenv("SESSION_COOKIE_SAMESITE", "")
+	t.Setenv("SESSION_COOKIE_SECURE", "")
+	if cfg := SessionConfigFromEnv(); cfg.Cookies() || cfg.SameSite != http.SameSiteLaxMode || cfg.Secure {
+		t.Errorf("defaults = %+v, want bearer sessions with lax, insecure cookies", cfg)
+	}
+
+	t.Setenv("SESSION_MODE", "Cookie")
+	t.Setenv("SESSION_COOKIE_SAMESITE", "none")
+	t.Setenv("SESSION_COOKIE_SECURE", "false")
+	if cfg := SessionConfigFromEnv(); !cfg.Cookies() || cfg.SameSite != http.SameSiteNoneMode || !cfg.Secure {
+		t.Errorf("cfg = %+v, want cookie sessions whose SameSite=None cookies are Secure", cfg)
+	}
+
+	t.Setenv("SESSION_MODE", "jwt")
+	if SessionConfigFromEnv().Cookies() {
+		t.Error("an invalid SESSION_MODE should fall back to bearer")
+	}
+}
+
+func TestCookieSession(t *testing.T) {
+	t.Setenv("SESSION_MODE", "cookie")
+	token, _ := auth.GenerateToken(1, false)
+	csrfToken, err := CSRFToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherToken, _ := auth.GenerateToken(2, false)
+	otherCSRF, _ := CSRFToken(otherToken)
+
+	router := gin.New()
+	router.Use(AuthRequired(nil), CSRFRequired())
+	handler := func(c *gin.Context) {
+		userID, _ := GetUserID(c)
+		c.JSON(http.StatusOK, gin.H{"user_id": userID})
+	}
+	router.GET("/test", handler)
+	router.POST("/test", handler)
+
+	tests := []struct {
+		name       string
+		method     string
+		cookie     string
+		authHeader string
+		csrfHeader string
+		wantStatus int
+	}{
+		{"cookie authenticates reads", http.MethodGet, token, "", "", http.StatusOK},
+		{"writes need the CSRF token", http.MethodPost, token, "", "", http.StatusForbidden},
+		{"writes with the CSRF token", http.MethodPost, token, "", csrfToken, http.StatusOK},
+		{"another session's CSRF token", http.MethodPost, token, "", otherCSRF, http.StatusForbidden},
+		{"bearer writes need no CSRF token", http.MethodPost, "", "Bearer " + token, "", http.StatusOK},
+		{"the header wins over the cookie", http.MethodPost, token, "Bearer invalid", csrfToken, http.StatusUnauthorized},
+		{"invalid cookie", http.MethodGet, "invalid", "", "", http.StatusUnauthorized},
+		{"no credentials", http.MethodGet, "", "", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, "/test", nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: SessionCookie, Value: tt.cookie})
+			}
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			if tt.csrfHeader != "" {
+				req.Header.Set(CSRFHeader, tt.csrfHeader)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+
+	t.Run("cookies are ignored in bearer mode", func(t *testing.T) {
+		t.Setenv("SESSION_MODE", "bearer")
+		router := gin.New()
+		router.Use(AuthRequired(nil))
+		router.GET("/test", handler)
+		req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+		req.AddCookie(&http.Cookie{Name: SessionCookie, Value: token})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("status = %d, want 401", w.Code)
+		}
+	})
+}
+
+func TestStartCookieSession(t *testing.T) {
+	token, _ := auth.GenerateToken(1, false)
+	cfg := SessionConfig{Mode: SessionModeCookie, SameSite: http.SameSiteStrictMode, Secure: true}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	csrfToken, err := cfg.StartCookieSession(c, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cookies := map[string]*http.Cookie{}
+	for _, cookie := range w.Result().Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	session, csrf := cookies[SessionCookie], cookies[CSRFCookie]
+	if session == nil || session.Value != token || !session.HttpOnly || !session.Secure || session.SameSite != http.SameSiteStrictMode || session.Path != "/api" {
+		t.Errorf("session cookie = %+v", session)
+	}
+	if csrf == nil || csrf.Value != csrfToken || csrf.HttpOnly {
+		t.Errorf("CSRF cookie = %+v, want one the frontend can read", csrf)
+	}
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	cfg.EndCookieSession(c)
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Value != "" || cookie.MaxAge >= 0 {
+			t.Errorf("cookie %s wasn't cleared: %+v", cookie.Name, cookie)
+		}
+	}
+}
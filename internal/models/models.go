@@ -3,6 +3,7 @@ package models
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -35,6 +36,7 @@ type User struct {
 	SkillTags                 []UserSkillTag `gorm:"many2many:user_skill_tag_assignments;" json:"skill_tags,omitempty"`
 	FailedLoginAttempts       int            `gorm:"default:0" json:"-"`
 	LockedUntil               *time.Time     `json:"-"`
+	LockoutCount              int            `gorm:"default:0" json:"-"` // Consecutive lockouts since the last successful login
 	LastLogin                 *time.Time     `json:"-"`
 	ResetToken                string         `json:"-"`
 	ResetTokenExpiry          *time.Time     `json:"-"`
@@ -44,7 +46,68 @@ type User struct {
 	SetupTokenLookup          string         `gorm:"index;default:''" json:"-"` // Plaintext prefix for indexed token lookup
 	RequiresPasswordSetup     bool           `gorm:"default:false" json:"-"`    // Flag to prevent login before password setup
 	EmailNotificationsEnabled bool           `gorm:"default:false" json:"email_notifications_enabled"`
+	AnimalChangeEmailsEnabled bool           `gorm:"default:false" json:"animal_change_emails_enabled"`
+	WeeklyStatsEmailsEnabled  bool           `gorm:"default:false" json:"weekly_stats_emails_enabled"`
+	EmailVerifiedAt           *time.Time     `json:"email_verified_at"` // nil until the user proves ownership of Email
+	EmailVerificationToken    string         `json:"-"`                 // bcrypt hash of the emailed verification token
+	EmailVerificationExpiry   *time.Time     `json:"-"`
+	EmailVerificationLookup   string         `gorm:"index;default:''" json:"-"`    // Plaintext prefix for indexed token lookup
+	EmailUndeliverable        bool           `gorm:"default:false;index" json:"-"` // The provider reported a hard bounce or spam complaint; sends are suppressed
+	EmailUndeliverableReason  string         `gorm:"default:''" json:"-"`          // "bounce" or "complaint"
+	EmailUndeliverableDetail  string         `gorm:"default:''" json:"-"`          // Provider's diagnostic for the bounce
+	EmailUndeliverableAt      *time.Time     `json:"-"`
 	ShowLengthOfStay          bool           `gorm:"default:false" json:"show_length_of_stay"`
+	DeactivationRequestedAt   *time.Time     `gorm:"index" json:"-"`                                    // Set when the user closes their own account; anonymized after the grace period
+	AnonymizedAt              *time.Time     `json:"-"`                                                 // Personal data permanently erased; the account can't be restored
+	UsernameChangedAt         *time.Time     `json:"username_changed_at"`                               // Last self-service username change; nil if never changed
+	SCIMUserName              string         `gorm:"column:scim_user_name;index;default:''" json:"-"`   // userName from the identity provider for SCIM-provisioned users
+	SCIMExternalID            string         `gorm:"column:scim_external_id;index;default:''" json:"-"` // externalId from the identity provider
+	PasswordLoginDisabled     bool           `gorm:"default:false" json:"password_login_disabled"`      // User must sign in through an OIDC provider
+	AvatarURL                 string         `gorm:"default:''" json:"avatar_url"`                      // Square profile photo up to 256px; empty means show initials
+	AvatarThumbnailURL        string         `gorm:"default:''" json:"avatar_thumbnail_url"`            // 64px copy of the avatar for member lists and comments
+	SMSOptIn                  bool           `gorm:"column:sms_opt_in;default:false" json:"sms_opt_in"` // User agreed to emergency broadcast texts at PhoneNumber
+	TimeZone                  string         `gorm:"default:''" json:"time_zone"`                       // IANA name times are shown in, e.g. "America/Chicago"; empty for the group's or site's
+	OrganizationID            *uint          `gorm:"index" json:"organization_id"`                      // The organization the user belongs to; nil for an instance without organizations
+	IsOrgAdmin                bool           `gorm:"default:false" json:"is_org_admin"`                 // Admin of every group in OrganizationID and its settings
+}
+
+// UserIdentity links a User to an account at an OIDC provider (Google,
+// Microsoft). Subject is the provider's stable user ID, so the link survives
+// email changes on either side.
+type UserIdentity struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	Provider   string     `gorm:"not null;uniqueIndex:idx_user_identities_provider_subject" json:"provider"`
+	Subject    string     `gorm:"not null;uniqueIndex:idx_user_identities_provider_subject" json:"-"`
+	Email      string     `gorm:"default:''" json:"email"` // Email at the provider when the account was linked
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// UsernameHistory records a username a user gave up, so it can't be claimed by
+// someone else straight away.
+type UsernameHistory struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UserID      uint      `gorm:"not null;index" json:"user_id"`
+	OldUsername string    `gorm:"not null;index" json:"old_username"`
+	NewUsername string    `gorm:"not null" json:"new_username"`
+	ChangedBy   uint      `gorm:"not null" json:"changed_by"` // User ID who made the change; differs from UserID for admin renames
+}
+
+// UserMerge records an admin folding a duplicate account (the source) into
+// another (the target). Record is the JSON the merge needs to put things
+// back, so it can be undone until UndoDeadline.
+type UserMerge struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	TargetUserID uint       `gorm:"not null;index" json:"target_user_id"`
+	SourceUserID uint       `gorm:"not null;index" json:"source_user_id"`
+	MergedByID   uint       `gorm:"not null" json:"merged_by_id"`
+	Record       string     `gorm:"type:text" json:"-"`
+	UndoDeadline time.Time  `json:"undo_deadline"`
+	UndoneAt     *time.Time `json:"undone_at,omitempty"`
+	UndoneByID   *uint      `json:"undone_by_id,omitempty"`
 }
 
 // APIToken represents a personal access token that authenticates API
@@ -63,6 +126,26 @@ type APIToken struct {
 	LastUsedAt  *time.Time     `json:"last_used_at"`
 }
 
+// API access restriction modes
+const (
+	APIRestrictionThrottle = "throttle"
+	APIRestrictionSuspend  = "suspend"
+)
+
+// APIAccessRestriction throttles or suspends one user's API access until it
+// expires or a site admin lifts it. A user has at most one restriction.
+type APIAccessRestriction struct {
+	ID                uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	UserID            uint       `gorm:"uniqueIndex;not null" json:"user_id"`
+	Mode              string     `gorm:"not null" json:"mode"`
+	RequestsPerMinute int        `gorm:"default:0" json:"requests_per_minute"` // Throttle budget; unused when suspended
+	Reason            string     `gorm:"default:''" json:"reason"`
+	ExpiresAt         *time.Time `gorm:"index" json:"expires_at"` // nil until lifted
+	CreatedByID       uint       `json:"created_by_id"`
+}
+
 // Group represents a volunteer group (dogs, cats, modsquad, etc.)
 type Group struct {
 	ID             uint            `gorm:"primaryKey" json:"id"`
@@ -76,12 +159,52 @@ type Group struct {
 	HasProtocols   bool            `gorm:"column:has_protocols;default:false" json:"has_protocols"`     // Enable protocols feature for this group
 	GroupMeBotID   string          `gorm:"column:groupme_bot_id" json:"-"`                              // GroupMe Bot ID — omitted from API responses; exposed via adminGroupResponse only
 	GroupMeEnabled bool            `gorm:"column:groupme_enabled;default:false" json:"groupme_enabled"` // Enable GroupMe integration for this group
+	PublicSharing  bool            `gorm:"column:public_sharing;default:false" json:"public_sharing"`   // Allow public, no-login share links for this group's animals
+	AccentColor    string          `gorm:"default:''" json:"accent_color"`                              // Branding: #rrggbb, or empty for the site default
+	LogoURL        string          `gorm:"default:''" json:"logo_url"`                                  // Branding: set via the logo upload endpoint only
+	WelcomeText    string          `gorm:"default:''" json:"welcome_text"`                              // Branding: welcome message for the group page
+	PublicFeed     bool            `gorm:"column:public_feed;default:false" json:"public_feed"`         // Publish available animals as an unauthenticated JSON/RSS feed
+	Slug           string          `gorm:"default:''" json:"slug"`                                      // URL name for the public feed; unique among groups that set one
 	Users          []User          `gorm:"many2many:user_groups;" json:"users,omitempty"`
 	Animals        []Animal        `gorm:"foreignKey:GroupID" json:"animals,omitempty"`
 	Updates        []Update        `gorm:"foreignKey:GroupID" json:"updates,omitempty"`
 	Protocols      []Protocol      `gorm:"foreignKey:GroupID" json:"protocols,omitempty"`
 	Scripts        []Script        `gorm:"foreignKey:GroupID" json:"scripts,omitempty"`
 	Documents      []GroupDocument `gorm:"foreignKey:GroupID" json:"documents,omitempty"`
+
+	// Display settings: animal list defaults for requests that don't give
+	// their own, the fields animal cards show, the group's time zone,
+	// whether active animals' names must be unique, and whether bonded
+	// partners must be adopted together
+	DefaultStatusFilter string     `gorm:"default:''" json:"default_status_filter"`    // Comma-separated statuses or "all"; empty for available, bite_quarantine, and under_vet_care
+	DefaultSort         string     `gorm:"default:''" json:"default_sort"`             // A ?sort= key; empty for the order animals were added
+	DefaultSortOrder    string     `gorm:"default:''" json:"default_sort_order"`       // "asc", "desc", or empty for the sort's own default
+	CardFields          StringList `gorm:"type:text" json:"card_fields"`               // Animal fields shown on list cards, in order; empty for the app's default
+	TimeZone            string     `gorm:"default:''" json:"time_zone"`                // IANA name for the group's dates and schedules; empty for the site default
+	UniqueAnimalNames   bool       `gorm:"default:false" json:"unique_animal_names"`   // Reserve each active animal's name so no two share it
+	AdoptBondedTogether bool       `gorm:"default:false" json:"adopt_bonded_together"` // Only adopt an animal out with its bonded partners
+	OrganizationID      *uint      `gorm:"index" json:"organization_id"`               // The organization that owns the group; nil for an instance without organizations
+
+	// Email sender identity for the group's notification emails, managed
+	// through the email-sender endpoints. The reply-to address is only used
+	// once verified.
+	EmailSenderName        string     `gorm:"default:''" json:"-"`       // Display name on the group's emails; empty for the site's
+	EmailReplyTo           string     `gorm:"default:''" json:"-"`       // Where replies go; empty for the site's from address
+	EmailReplyToVerifiedAt *time.Time `json:"-"`                         // Set once the reply-to address's verification link is followed
+	EmailReplyToToken      string     `gorm:"default:''" json:"-"`       // bcrypt hash of the outstanding verification token
+	EmailReplyToLookup     string     `gorm:"default:'';index" json:"-"` // Plaintext token prefix for lookups
+	EmailReplyToExpiry     *time.Time `json:"-"`
+
+	// Size URLs for the group's images, filled in after loading
+	ImageVariants     *ImageVariantURLs `gorm:"-" json:"image_variants,omitempty"`
+	HeroImageVariants *ImageVariantURLs `gorm:"-" json:"hero_image_variants,omitempty"`
+}
+
+// AfterFind fills in the group's ImageVariants and HeroImageVariants.
+func (g *Group) AfterFind(tx *gorm.DB) error {
+	g.ImageVariants = VariantURLs(g.ImageURL)
+	g.HeroImageVariants = VariantURLs(g.HeroImageURL)
+	return nil
 }
 
 // Animal represents an animal in a group
@@ -90,12 +213,17 @@ type Animal struct {
 	CreatedAt                      time.Time           `json:"created_at"`
 	UpdatedAt                      time.Time           `json:"updated_at"`
 	DeletedAt                      gorm.DeletedAt      `gorm:"index" json:"-"`
-	GroupID                        uint                `gorm:"not null;index:idx_animal_group_status" json:"group_id"`
+	GroupID                        uint                `gorm:"not null;index:idx_animal_group_status;index:idx_animal_group_external" json:"group_id"`
+	ExternalID                     string              `gorm:"index:idx_animal_group_external" json:"external_id,omitempty"` // ID in the shelter's own system; CSV upsert imports match on it
+	MicrochipNumber                string              `gorm:"index" json:"microchip_number"`                                // 15-digit ISO chip, or a 9-digit or 10-character legacy chip; unique in the group
+	LicenseNumber                  string              `gorm:"index" json:"license_number"`                                  // Local pet license or registration number; unique in the group
 	Name                           string              `gorm:"not null" json:"name"`
 	Species                        string              `json:"species"`
 	Breed                          string              `json:"breed"`
 	Age                            int                 `json:"age"`
 	EstimatedBirthDate             *time.Time          `json:"estimated_birth_date"` // Estimated date of birth for real-time age calculation
+	AgeYears                       int                 `gorm:"-" json:"age_years"`   // Computed from EstimatedBirthDate on load
+	AgeMonths                      int                 `gorm:"-" json:"age_months"`  // Computed from EstimatedBirthDate on load
 	Description                    string              `json:"description"`
 	TrainerNotes                   string              `json:"trainer_notes"` // Optional notes for trainer meetings
 	ImageURL                       string              `json:"image_url"`
@@ -110,6 +238,15 @@ type Animal struct {
 	ArchivedDate                   *time.Time          `json:"archived_date"`                                                   // When animal was archived
 	LastStatusChange               *time.Time          `json:"last_status_change"`                                              // Timestamp of last status change
 	IsReturned                     bool                `gorm:"default:false" json:"is_returned"`                                // Manually set by admins to indicate this animal was previously adopted and returned
+	IntakeSource                   string              `json:"intake_source"`                                                   // One of IntakeSources; empty when unknown
+	Outcome                        string              `json:"outcome"`                                                         // One of AnimalOutcomes once the animal has left care; empty while in care
+	OutcomeDate                    *time.Time          `gorm:"index" json:"outcome_date"`                                       // When Outcome was set
+	Size                           string              `gorm:"index" json:"size"`                                               // One of AnimalSizes; empty when not recorded
+	EnergyLevel                    string              `gorm:"index" json:"energy_level"`                                       // One of EnergyLevels; empty when not recorded
+	LegalHold                      bool                `gorm:"default:false;index" json:"legal_hold"`                           // Site admins only; blocks status changes, transfers, and deletion while set
+	LegalHoldReason                string              `json:"legal_hold_reason,omitempty"`                                     // Why the hold was placed, e.g. a bite case number
+	LegalHoldAt                    *time.Time          `json:"legal_hold_at,omitempty"`                                         // When the hold was placed
+	LegalHoldByID                  *uint               `json:"legal_hold_by_id,omitempty"`                                      // Site admin who placed the hold
 	ProtocolDocumentURL            string              `json:"protocol_document_url"`                                           // URL to protocol document (PDF/DOCX)
 	ProtocolDocumentName           string              `json:"protocol_document_name"`                                          // Original filename of protocol document
 	ProtocolDocumentData           []byte              `gorm:"type:bytea" json:"-"`                                             // Binary data of protocol document (null when using Azure)
@@ -124,8 +261,62 @@ type Animal struct {
 	BQIncidents                    []AnimalBQIncident  `gorm:"foreignKey:AnimalID" json:"bq_incidents,omitempty"`               // Bite-quarantine incidents for this animal
 	Images                         []AnimalImage       `gorm:"foreignKey:AnimalID" json:"images,omitempty"`                     // Images uploaded for this animal
 	Scripts                        []Script            `gorm:"many2many:animal_scripts;" json:"scripts,omitempty"`              // Scripts linked to this animal's protocol
+	CurrentWeight                  *WeightEntry        `gorm:"-" json:"current_weight,omitempty"`                               // Most recent weigh-in; populated on the detail endpoint only
+	CommentTagCounts               []CommentTagCount   `gorm:"-" json:"comment_tag_counts,omitempty"`                           // Comments per comment tag; populated on the detail endpoint only
+	PinnedComments                 []AnimalComment     `gorm:"-" json:"pinned_comments,omitempty"`                              // Pinned comments, newest pin first; populated on the detail endpoint only
+	RelatedAnimals                 []RelatedAnimal     `gorm:"-" json:"related_animals,omitempty"`                              // Bonded partners, littermates, parents, and offspring; populated on the detail endpoint only
+	CustomFields                   AnimalCustomValues  `gorm:"type:jsonb" json:"custom_fields,omitempty"`                       // Values of the group's AnimalCustomFields, keyed by field key
+
+	// Sizes of the profile image for list views; filled from ImageURL on load
+	ImageVariants *ImageVariantURLs `gorm:"-" json:"image_variants,omitempty"`
 }
 
+// Intake sources accepted on Animal.IntakeSource, following the Asilomar
+// Accords intake categories
+const (
+	IntakeStray            = "stray"
+	IntakeOwnerSurrender   = "owner_surrender"
+	IntakeTransfer         = "transfer"
+	IntakeReturnedAdoption = "returned_adoption"
+	IntakeBornInCare       = "born_in_care"
+	IntakeOther            = "other"
+)
+
+// IntakeSources lists the accepted intake sources
+var IntakeSources = []string{IntakeStray, IntakeOwnerSurrender, IntakeTransfer, IntakeReturnedAdoption, IntakeBornInCare, IntakeOther}
+
+// Outcomes accepted on Animal.Outcome, following the Asilomar Accords
+// outcome categories
+const (
+	OutcomeAdopted         = "adopted"
+	OutcomeReturnedToOwner = "returned_to_owner"
+	OutcomeTransferred     = "transferred"
+	OutcomeOtherLive       = "other_live"
+	OutcomeEuthanized      = "euthanized"
+	OutcomeDied            = "died"
+	OutcomeLost            = "lost"
+)
+
+// AnimalOutcomes lists the accepted outcomes
+var AnimalOutcomes = []string{OutcomeAdopted, OutcomeReturnedToOwner, OutcomeTransferred, OutcomeOtherLive, OutcomeEuthanized, OutcomeDied, OutcomeLost}
+
+// LiveOutcomes are the outcomes counted as live releases
+var LiveOutcomes = []string{OutcomeAdopted, OutcomeReturnedToOwner, OutcomeTransferred, OutcomeOtherLive}
+
+// AnimalSizes lists the sizes accepted on Animal.Size. They are the foster
+// sizes, so foster matching can use an animal's recorded size.
+var AnimalSizes = []string{FosterSizeSmall, FosterSizeMedium, FosterSizeLarge}
+
+// Energy levels accepted on Animal.EnergyLevel
+const (
+	EnergyLevelLow    = "low"
+	EnergyLevelMedium = "medium"
+	EnergyLevelHigh   = "high"
+)
+
+// EnergyLevels lists the accepted energy levels
+var EnergyLevels = []string{EnergyLevelLow, EnergyLevelMedium, EnergyLevelHigh}
+
 // AgeDisplay computes the animal's age in years and months from EstimatedBirthDate.
 // Falls back to (Age, 0) when EstimatedBirthDate is nil.
 func (a *Animal) AgeDisplay() (years int, months int) {
@@ -156,6 +347,30 @@ func (a *Animal) AgeYearsFromBirthDate() int {
 	return y
 }
 
+// BirthDateFromAge estimates a birth date for an animal that is years old
+// on now, used when only an integer age is known.
+func BirthDateFromAge(years int, now time.Time) time.Time {
+	y, m, d := now.Date()
+	return time.Date(y-years, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// AfterFind fills AgeYears and AgeMonths so responses show the current age
+// rather than the Age stored when the animal was last saved, and
+// ImageVariants from ImageURL.
+func (a *Animal) AfterFind(tx *gorm.DB) error {
+	a.AgeYears, a.AgeMonths = a.AgeDisplay()
+	a.ImageVariants = VariantURLs(a.ImageURL)
+	return nil
+}
+
+// AfterSave keeps AgeYears, AgeMonths, and ImageVariants current on the
+// struct returned by create and update handlers.
+func (a *Animal) AfterSave(tx *gorm.DB) error {
+	a.AgeYears, a.AgeMonths = a.AgeDisplay()
+	a.ImageVariants = VariantURLs(a.ImageURL)
+	return nil
+}
+
 // calendarDaysSince returns the number of calendar days between t and now,
 // comparing dates rather than raw hours to avoid DST skew.
 // Returns 0 for future timestamps.
@@ -225,34 +440,158 @@ type Update struct {
 	User        User           `gorm:"foreignKey:UserID" json:"user,omitempty"`
 }
 
-// Announcement represents a site-wide announcement/update
+// Discussion is a conversation thread in a group, open to every member and
+// not tied to an animal. Unlike an Update, members reply to it.
+type Discussion struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time      `gorm:"index:idx_discussion_group_created" json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	GroupID     uint           `gorm:"not null;index:idx_discussion_group_created" json:"group_id"`
+	UserID      uint           `gorm:"not null;index" json:"user_id"`
+	Title       string         `gorm:"not null" json:"title"`
+	Content     string         `gorm:"not null" json:"content"`
+	IsEdited    bool           `gorm:"default:false" json:"is_edited"`
+	ReplyCount  int            `gorm:"default:0" json:"reply_count"`
+	LastReplyAt *time.Time     `gorm:"index" json:"last_reply_at"` // Threads are listed by latest activity
+	User        User           `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// DiscussionReply is a message in a Discussion. A reply with a ParentID
+// answers another reply in the same discussion.
+type DiscussionReply struct {
+	ID           uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt    time.Time      `gorm:"index:idx_discussion_reply_created" json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	DiscussionID uint           `gorm:"not null;index:idx_discussion_reply_created" json:"discussion_id"`
+	ParentID     *uint          `gorm:"index" json:"parent_id"`
+	UserID       uint           `gorm:"not null;index" json:"user_id"`
+	Content      string         `gorm:"not null" json:"content"`
+	IsEdited     bool           `gorm:"default:false" json:"is_edited"`
+	User         User           `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// DiscussionParticipant records that a user started or replied to a
+// discussion, so they hear about new replies, or that they muted it.
+type DiscussionParticipant struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	DiscussionID uint      `gorm:"not null;uniqueIndex:idx_discussion_participant" json:"discussion_id"`
+	UserID       uint      `gorm:"not null;uniqueIndex:idx_discussion_participant;index" json:"user_id"`
+	Muted        bool      `gorm:"default:false" json:"muted"`
+}
+
+// Announcement represents an announcement/update. It is site-wide unless it
+// has a GroupID (posted from a group page) or targets Groups, in which case
+// only members of those groups see it.
 type Announcement struct {
 	ID          uint           `gorm:"primaryKey" json:"id"`
 	CreatedAt   time.Time      `gorm:"index:idx_announcement_created" json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 	UserID      uint           `gorm:"not null;index" json:"user_id"`
+	GroupID     *uint          `gorm:"index" json:"group_id,omitempty"` // Set for group announcements; scopes scheduled notifications to the group
 	Title       string         `gorm:"not null" json:"title"`
 	Content     string         `gorm:"not null" json:"content"`
 	SendEmail   bool           `gorm:"default:false" json:"send_email"`
 	SendGroupMe bool           `gorm:"default:false" json:"send_groupme"`
+	PublishAt   *time.Time     `gorm:"index" json:"publish_at"` // Hidden from non-admins until this time; nil publishes immediately
+	ExpiresAt   *time.Time     `gorm:"index" json:"expires_at"` // Hidden from non-admins from this time; nil never expires
+	NotifiedAt  *time.Time     `json:"notified_at"`             // When email/GroupMe notifications were dispatched
 	User        User           `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Groups      []Group        `gorm:"many2many:announcement_groups;" json:"groups,omitempty"`
+	IsRead      bool           `gorm:"-" json:"is_read"` // Whether the requesting user has marked it read
+}
+
+// AnnouncementRead records that a user has read an announcement
+type AnnouncementRead struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt      time.Time `json:"read_at"`
+	AnnouncementID uint      `gorm:"not null;uniqueIndex:idx_announcement_read_user" json:"announcement_id"`
+	UserID         uint      `gorm:"not null;uniqueIndex:idx_announcement_read_user;index" json:"user_id"`
+}
+
+// Types of AnnouncementEmailEvent
+const (
+	EmailEventDelivered = "delivered" // Handed to the email provider
+	EmailEventOpened    = "opened"    // The tracking pixel was loaded
+	EmailEventClicked   = "clicked"   // A tracked link was followed
+)
+
+// AnnouncementEmailEvent records an announcement email being delivered to,
+// opened by, or clicked through by one recipient. Opens and clicks are only
+// recorded while the email_tracking site setting is on.
+type AnnouncementEmailEvent struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt      time.Time `json:"created_at"`
+	AnnouncementID uint      `gorm:"not null;index:idx_announcement_email_event" json:"announcement_id"`
+	Type           string    `gorm:"not null;index:idx_announcement_email_event" json:"type"`
+	UserID         uint      `gorm:"not null;index" json:"user_id"`
+	URL            string    `json:"url,omitempty"` // The link followed, for clicks
+}
+
+// Emergency broadcast delivery statuses
+const (
+	SMSDeliveryPending   = "pending" // Not yet accepted by the SMS provider
+	SMSDeliverySent      = "sent"
+	SMSDeliveryDelivered = "delivered"
+	SMSDeliveryFailed    = "failed"
+)
+
+// EmergencyBroadcast is an urgent text message to the members of a group who
+// opted in to SMS, or to every opted-in user when GroupID is nil.
+type EmergencyBroadcast struct {
+	ID             uint                         `gorm:"primaryKey" json:"id"`
+	CreatedAt      time.Time                    `gorm:"index" json:"created_at"`
+	GroupID        *uint                        `gorm:"index" json:"group_id"` // nil for site-wide broadcasts
+	SentByID       uint                         `gorm:"not null;index" json:"sent_by_id"`
+	Message        string                       `gorm:"type:text;not null" json:"message"`
+	RecipientCount int                          `gorm:"default:0" json:"recipient_count"`
+	SentBy         User                         `gorm:"foreignKey:SentByID" json:"sent_by,omitempty"`
+	Group          *Group                       `gorm:"foreignKey:GroupID" json:"group,omitempty"`
+	Deliveries     []EmergencyBroadcastDelivery `gorm:"foreignKey:BroadcastID" json:"deliveries,omitempty"`
+}
+
+// EmergencyBroadcastDelivery tracks one recipient's copy of an emergency
+// broadcast, updated by the SMS provider's status callbacks.
+type EmergencyBroadcastDelivery struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	BroadcastID       uint      `gorm:"not null;index" json:"broadcast_id"`
+	UserID            uint      `gorm:"not null;index" json:"user_id"`
+	PhoneNumber       string    `gorm:"not null" json:"phone_number"`                   // E.164 number the message went to
+	ProviderMessageID string    `gorm:"index;default:''" json:"provider_message_id"`    // Matches status callbacks to the delivery
+	Status            string    `gorm:"not null;default:'pending';index" json:"status"` // pending, sent, delivered, or failed
+	Error             string    `gorm:"default:''" json:"error,omitempty"`              // Last send error or provider error code
+	User              User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
 }
 
 // AnimalComment represents a comment on an animal (social media style)
 type AnimalComment struct {
-	ID        uint             `gorm:"primaryKey" json:"id"`
-	CreatedAt time.Time        `gorm:"index:idx_comment_animal_created" json:"created_at"`
-	UpdatedAt time.Time        `json:"updated_at"`
-	DeletedAt gorm.DeletedAt   `gorm:"index" json:"-"`
-	AnimalID  uint             `gorm:"not null;index:idx_comment_animal_created" json:"animal_id"`
-	UserID    uint             `gorm:"not null;index" json:"user_id"`
-	Content   string           `gorm:"not null" json:"content"`
-	ImageURL  string           `json:"image_url"`
-	IsEdited  bool             `gorm:"default:false" json:"is_edited"`
-	Metadata  *SessionMetadata `gorm:"type:jsonb" json:"metadata,omitempty"`
-	Tags      []CommentTag     `gorm:"many2many:animal_comment_tags;" json:"tags,omitempty"`
-	User      User             `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time      `gorm:"index:idx_comment_animal_created" json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	AnimalID  uint           `gorm:"not null;index:idx_comment_animal_created" json:"animal_id"`
+	UserID    uint           `gorm:"not null;index" json:"user_id"`
+	Content   string         `gorm:"not null" json:"content"`
+	// Original author of an imported comment who has no account here; the
+	// comment then belongs to the admin who imported it
+	AuthorName string `gorm:"default:''" json:"author_name,omitempty"`
+	// Client-generated UUID of a comment written offline and sent through
+	// the batch endpoint; resending it returns the comment already saved
+	ClientID   *string          `gorm:"uniqueIndex" json:"client_id,omitempty"`
+	ImageURL   string           `json:"image_url"`
+	IsEdited   bool             `gorm:"default:false" json:"is_edited"`
+	Pinned     bool             `gorm:"default:false" json:"pinned"` // Listed first on the animal, set by a group admin
+	PinnedAt   *time.Time       `json:"pinned_at,omitempty"`
+	PinnedByID *uint            `json:"pinned_by_id,omitempty"`
+	Metadata   *SessionMetadata `gorm:"type:jsonb" json:"metadata,omitempty"`
+	Tags       []CommentTag     `gorm:"many2many:animal_comment_tags;" json:"tags,omitempty"`
+	User       User             `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Reactions  []ReactionCount  `gorm:"-" json:"reactions,omitempty"` // Populated on list endpoints only
 }
 
 // NonDeletedAnimalCommentsQuery scopes a query to AnimalComment rows whose
@@ -301,6 +640,29 @@ type CommentHistory struct {
 	User      User             `gorm:"foreignKey:EditedBy" json:"user,omitempty"`
 }
 
+// Comment reaction types
+const (
+	ReactionThumbsUp = "thumbs_up"
+	ReactionHeart    = "heart"
+)
+
+// CommentReaction is one user's reaction of one type to a comment. A user
+// can leave each reaction type once per comment.
+type CommentReaction struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	CommentID uint      `gorm:"not null;uniqueIndex:idx_comment_reactions_comment_user_type" json:"comment_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_comment_reactions_comment_user_type;index" json:"user_id"`
+	Type      string    `gorm:"not null;size:20;uniqueIndex:idx_comment_reactions_comment_user_type" json:"type"`
+}
+
+// ReactionCount is how many users left one reaction type on a comment
+type ReactionCount struct {
+	Type    string `json:"type"`
+	Count   int64  `json:"count"`
+	Reacted bool   `json:"reacted"` // Whether the requesting user is one of them
+}
+
 // SessionMetadata stores structured session report data
 type SessionMetadata struct {
 	SessionGoal      string `json:"session_goal,omitempty"`
@@ -334,6 +696,58 @@ func (sm *SessionMetadata) Value() (driver.Value, error) {
 	return json.Marshal(sm)
 }
 
+// Sources of an AnimalChange
+const (
+	AnimalChangeEdit      = "edit"
+	AnimalChangeAdminEdit = "admin_edit"
+	AnimalChangeBulk      = "bulk_update"
+)
+
+// AnimalFieldChange is one field an edit changed, with the old and new
+// values formatted for display.
+type AnimalFieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// AnimalFieldChanges is stored as JSON in AnimalChange.Changes
+type AnimalFieldChanges []AnimalFieldChange
+
+// Scan implements sql.Scanner interface to convert database value to AnimalFieldChanges
+func (fc *AnimalFieldChanges) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, fc)
+	case string:
+		return json.Unmarshal([]byte(v), fc)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer interface to convert AnimalFieldChanges to database value
+func (fc AnimalFieldChanges) Value() (driver.Value, error) {
+	if fc == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(fc)
+	return string(data), err
+}
+
+// AnimalChange records which fields of an animal someone changed, for the
+// group's activity feed. An edit that moves an animal to another group is
+// recorded in both groups.
+type AnimalChange struct {
+	ID        uint               `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time          `gorm:"index:idx_animal_changes_group_created,priority:2" json:"created_at"`
+	GroupID   uint               `gorm:"not null;index:idx_animal_changes_group_created,priority:1" json:"group_id"`
+	AnimalID  uint               `gorm:"not null;index" json:"animal_id"`
+	UserID    uint               `gorm:"not null" json:"user_id"`
+	Source    string             `gorm:"not null" json:"source"` // AnimalChangeEdit, AnimalChangeAdminEdit, or AnimalChangeBulk
+	Changes   AnimalFieldChanges `gorm:"type:text;not null" json:"changes"`
+	User      User               `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
 // CommentTag represents a tag that can be applied to comments
 // Tags are group-specific - each group has its own set of tags
 type CommentTag struct {
@@ -347,6 +761,14 @@ type CommentTag struct {
 	IsSystem  bool           `gorm:"default:false" json:"is_system"` // True for behavior/medical tags
 }
 
+// CommentTagCount is how many of an animal's comments carry a comment tag
+type CommentTagCount struct {
+	TagID uint   `json:"tag_id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+	Count int64  `json:"count"`
+}
+
 // SiteSetting represents configurable site settings
 type SiteSetting struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
@@ -356,6 +778,45 @@ type SiteSetting struct {
 	Value     string    `gorm:"type:text" json:"value"`
 }
 
+// Organization is a tenant above groups: a shelter with its own groups,
+// users, and settings on a shared instance. Users only see groups in their
+// own organization. Users and groups without one share the instance's
+// unscoped space, so existing installs keep working unchanged.
+type Organization struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	Name      string         `gorm:"uniqueIndex;not null" json:"name"`
+	Slug      string         `gorm:"uniqueIndex;not null" json:"slug"` // URL name, used to look up the organization's public settings
+}
+
+// OrganizationSetting overrides a site setting (site name, description,
+// hero image) for one organization
+type OrganizationSetting struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	OrganizationID uint      `gorm:"not null;uniqueIndex:idx_organization_settings_org_key" json:"organization_id"`
+	Key            string    `gorm:"not null;uniqueIndex:idx_organization_settings_org_key" json:"key"`
+	Value          string    `gorm:"type:text" json:"value"`
+}
+
+// EmailTemplate is one saved version of an admin's replacement for a
+// built-in email. Saving creates a new version; the active one, if any, is
+// sent instead of the built-in.
+type EmailTemplate struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	Type        string    `gorm:"not null;uniqueIndex:idx_email_template_version" json:"type"`
+	Version     int       `gorm:"not null;uniqueIndex:idx_email_template_version" json:"version"`
+	Subject     string    `gorm:"not null" json:"subject"`
+	Body        string    `gorm:"type:text;not null" json:"body"`
+	Active      bool      `gorm:"default:false" json:"active"`
+	CreatedByID uint      `json:"created_by_id"`
+	CreatedBy   *User     `gorm:"foreignKey:CreatedByID" json:"created_by,omitempty"`
+}
+
 // Protocol represents a protocol/procedure for a group
 type Protocol struct {
 	ID         uint           `gorm:"primaryKey" json:"id"`
@@ -367,6 +828,67 @@ type Protocol struct {
 	Content    string         `gorm:"type:text;not null" json:"content"`
 	ImageURL   string         `json:"image_url"`
 	OrderIndex int            `gorm:"default:0;index:idx_protocols_group_order" json:"order_index"` // For custom ordering
+	// ContentFormat tells clients how to render Content: "plain" or "markdown"
+	ContentFormat string `gorm:"not null;default:'plain'" json:"content_format"`
+	// Version starts at 1 and increments whenever the title, content, or image
+	// changes; acknowledgments are recorded against a specific version.
+	Version                int  `gorm:"not null;default:1" json:"version"`
+	RequiresAcknowledgment bool `gorm:"default:false" json:"requires_acknowledgment"`
+	// Acknowledged is computed per request for the calling user against the
+	// current Version; it is not persisted.
+	Acknowledged bool `gorm:"-" json:"acknowledged"`
+	// Attachments are files and images shown alongside the protocol
+	Attachments []ProtocolAttachment `gorm:"foreignKey:ProtocolID" json:"attachments,omitempty"`
+}
+
+// ProtocolVersion is an immutable snapshot of a protocol's content at one version
+type ProtocolVersion struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	ProtocolID uint      `gorm:"not null;uniqueIndex:idx_protocol_version" json:"protocol_id"`
+	Version    int       `gorm:"not null;uniqueIndex:idx_protocol_version" json:"version"`
+	Title      string    `gorm:"not null" json:"title"`
+	Content    string    `gorm:"type:text;not null" json:"content"`
+	ImageURL   string    `json:"image_url"`
+	EditedByID *uint     `gorm:"index" json:"edited_by_id"` // nil for snapshots backfilled from pre-versioning content
+	// ContentFormat is "plain" or "markdown", as on Protocol
+	ContentFormat string `gorm:"not null;default:'plain'" json:"content_format"`
+}
+
+// Protocol content formats
+const (
+	ProtocolFormatPlain    = "plain"
+	ProtocolFormatMarkdown = "markdown"
+)
+
+// ProtocolAttachment is a file or image attached to a protocol. Files are
+// stored like group documents and served through /api/protocol-attachments.
+type ProtocolAttachment struct {
+	ID                   uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt            time.Time      `json:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at"`
+	DeletedAt            gorm.DeletedAt `gorm:"index" json:"-"`
+	ProtocolID           uint           `gorm:"not null;index" json:"protocol_id"`
+	GroupID              uint           `gorm:"not null;index" json:"group_id"`
+	IsImage              bool           `gorm:"default:false" json:"is_image"`
+	FileURL              string         `json:"file_url"`
+	FileName             string         `json:"file_name"`
+	FileType             string         `json:"file_type"`
+	FileSize             int64          `json:"file_size"`
+	FileProvider         string         `gorm:"default:'postgres'" json:"-"`
+	FileBlobIdentifier   string         `gorm:"index" json:"-"`
+	FileBlobExtension    string         `json:"-"`
+	FileData             []byte         `gorm:"type:bytea" json:"-"`
+	FileUploadedByUserID *uint          `json:"file_uploaded_by_user_id"`
+}
+
+// ProtocolAcknowledgment records that a user has read a specific protocol version
+type ProtocolAcknowledgment struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time `json:"acknowledged_at"`
+	ProtocolID uint      `gorm:"not null;uniqueIndex:idx_protocol_ack_user_version" json:"protocol_id"`
+	Version    int       `gorm:"not null;uniqueIndex:idx_protocol_ack_user_version" json:"version"`
+	UserID     uint      `gorm:"not null;uniqueIndex:idx_protocol_ack_user_version;index" json:"user_id"`
 }
 
 // Script represents a reusable script/procedure file uploaded to a group.
@@ -417,14 +939,146 @@ type GroupDocument struct {
 // AnimalTag represents a tag that can be applied to animals
 // Tags are group-specific - each group has its own set of tags
 type AnimalTag struct {
-	ID        uint           `gorm:"primaryKey" json:"id"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
-	GroupID   uint           `gorm:"index;uniqueIndex:idx_animal_tag_group_name" json:"group_id"` // Group this tag belongs to - NOT NULL enforced via raw SQL after migration
-	Name      string         `gorm:"not null;uniqueIndex:idx_animal_tag_group_name" json:"name"`
-	Category  string         `gorm:"not null" json:"category"`       // "behavior" or "walker_status"
-	Color     string         `gorm:"default:'#6b7280'" json:"color"` // Hex color for UI display
+	ID         uint           `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+	GroupID    uint           `gorm:"index;uniqueIndex:idx_animal_tag_group_name" json:"group_id"` // Group this tag belongs to - NOT NULL enforced via raw SQL after migration
+	Name       string         `gorm:"not null;uniqueIndex:idx_animal_tag_group_name" json:"name"`
+	Category   string         `gorm:"not null" json:"category"`        // "behavior" or "walker_status"
+	Color      string         `gorm:"default:'#6b7280'" json:"color"`  // Hex color for UI display
+	Restricted bool           `gorm:"default:false" json:"restricted"` // Animals with the tag are hidden from users without a matching UserQualification; otherwise the tag is advisory
+}
+
+// UserQualification lets a user see and comment on animals carrying a
+// restricted AnimalTag, e.g. an "experienced only" tag. Granted by group
+// admins; group admins and site admins don't need one.
+type UserQualification struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	GroupID     uint      `gorm:"not null;index" json:"group_id"`
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_user_qualification_tag" json:"user_id"`
+	AnimalTagID uint      `gorm:"not null;uniqueIndex:idx_user_qualification_tag;index" json:"animal_tag_id"`
+	GrantedByID uint      `json:"granted_by_id"`
+	User        User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	AnimalTag   AnimalTag `gorm:"foreignKey:AnimalTagID" json:"animal_tag,omitempty"`
+}
+
+// AnimalStatus defines one allowed value of Animal.Status. Rows with a nil
+// GroupID form the site-wide taxonomy; rows with a GroupID replace it entirely
+// for that group. When neither is configured, DefaultAnimalStatuses applies.
+type AnimalStatus struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	GroupID    *uint     `gorm:"index:idx_animal_status_group_order" json:"group_id"` // nil = site-wide
+	Key        string    `gorm:"not null" json:"key"`                                 // Value stored in animals.status
+	Label      string    `gorm:"not null" json:"label"`
+	Color      string    `gorm:"default:'#6b7280'" json:"color"` // Hex color for UI display
+	DateField  string    `gorm:"default:''" json:"date_field"`   // Animal date column stamped on entering this status ("" = none)
+	OrderIndex int       `gorm:"default:0;index:idx_animal_status_group_order" json:"order_index"`
+}
+
+// Types of an AnimalCustomField
+const (
+	CustomFieldText    = "text"
+	CustomFieldNumber  = "number"
+	CustomFieldDate    = "date"
+	CustomFieldBoolean = "boolean"
+	CustomFieldSelect  = "select"
+)
+
+// AnimalCustomField defines one extra field a group tracks on its animals,
+// such as a microchip number. Values live in Animal.CustomFields under Key.
+type AnimalCustomField struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	GroupID    uint       `gorm:"not null;index:idx_animal_custom_field_group_order" json:"group_id"`
+	Key        string     `gorm:"not null" json:"key"` // Key in Animal.CustomFields
+	Name       string     `gorm:"not null" json:"name"`
+	Type       string     `gorm:"not null;default:'text'" json:"type"` // CustomFieldText, CustomFieldNumber, CustomFieldDate, CustomFieldBoolean, or CustomFieldSelect
+	Required   bool       `gorm:"default:false" json:"required"`
+	Options    StringList `gorm:"type:text" json:"options,omitempty"` // Allowed values of a CustomFieldSelect field
+	OrderIndex int        `gorm:"default:0;index:idx_animal_custom_field_group_order" json:"order_index"`
+}
+
+// StringList is a list of strings stored as JSON
+type StringList []string
+
+// Scan implements sql.Scanner interface to convert database value to StringList
+func (sl *StringList) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, sl)
+	case string:
+		return json.Unmarshal([]byte(v), sl)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer interface to convert StringList to database value
+func (sl StringList) Value() (driver.Value, error) {
+	if len(sl) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(sl)
+	return string(data), err
+}
+
+// Breed is a canonical breed in the site's managed breed list. Free-text
+// breeds entered on animals are normalized to Name when they match it or one
+// of its Aliases, case-insensitively, within the same species.
+type Breed struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Species   string     `gorm:"not null;uniqueIndex:idx_breed_species_name" json:"species"`
+	Name      string     `gorm:"not null;uniqueIndex:idx_breed_species_name" json:"name"`
+	Aliases   StringList `gorm:"type:text" json:"aliases"` // Other spellings that normalize to Name, e.g. "Lab" for "Labrador Retriever"
+}
+
+// AnimalCustomValues holds an animal's custom field values by field key.
+// Values are stored as strings in a canonical form for their field's type:
+// numbers as decimals, dates as YYYY-MM-DD, booleans as "true" or "false".
+type AnimalCustomValues map[string]string
+
+// Scan implements sql.Scanner interface to convert database value to AnimalCustomValues
+func (cv *AnimalCustomValues) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, cv)
+	case string:
+		return json.Unmarshal([]byte(v), cv)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer interface to convert AnimalCustomValues to database value
+func (cv AnimalCustomValues) Value() (driver.Value, error) {
+	if len(cv) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(cv)
+	return string(data), err
+}
+
+// Animal date columns an AnimalStatus can drive via DateField.
+const (
+	StatusDateFieldFoster     = "foster_start_date"
+	StatusDateFieldQuarantine = "quarantine_start_date"
+	StatusDateFieldArchived   = "archived_date"
+)
+
+// DefaultAnimalStatuses is the built-in taxonomy used when no site-wide or
+// group-specific statuses are configured. The built-in keys keep their
+// dedicated handling (e.g. bite-quarantine incidents) regardless of config.
+var DefaultAnimalStatuses = []AnimalStatus{
+	{Key: "available", Label: "Available", Color: "#22c55e", OrderIndex: 0},
+	{Key: "foster", Label: "Foster", Color: "#3b82f6", DateField: StatusDateFieldFoster, OrderIndex: 1},
+	{Key: "bite_quarantine", Label: "Bite Quarantine", Color: "#ef4444", DateField: StatusDateFieldQuarantine, OrderIndex: 2},
+	{Key: "under_vet_care", Label: "Under Vet Care", Color: "#f59e0b", OrderIndex: 3},
+	{Key: "archived", Label: "Archived", Color: "#6b7280", DateField: StatusDateFieldArchived, OrderIndex: 4},
 }
 
 // UserSkillTag represents a skill-level tag that can be assigned to group members
@@ -458,8 +1112,116 @@ type AnimalImage struct {
 	StorageProvider  string         `gorm:"default:'postgres'" json:"-"` // Storage backend: "postgres" or "azure"
 	BlobIdentifier   string         `json:"-"`                           // Azure blob identifier (UUID without extension)
 	BlobExtension    string         `json:"-"`                           // File extension (e.g., ".jpg", ".png") for blob storage
+	ModerationStatus string         `gorm:"default:'approved';index" json:"moderation_status"`
+	ModerationLabels StringList     `gorm:"type:text" json:"moderation_labels,omitempty"` // Why the moderator quarantined the image
+	ModeratedByID    *uint          `json:"moderated_by_id,omitempty"`                    // Admin who approved a quarantined image
+	ModeratedAt      *time.Time     `json:"moderated_at,omitempty"`
 	User             User           `gorm:"foreignKey:UserID" json:"user,omitempty"`
 	Animal           Animal         `gorm:"foreignKey:AnimalID" json:"animal,omitempty"`
+
+	// Sizes for list views; filled from ImageURL on load
+	Variants *ImageVariantURLs `gorm:"-" json:"variants,omitempty"`
+}
+
+// AfterFind fills Variants from ImageURL
+func (i *AnimalImage) AfterFind(tx *gorm.DB) error {
+	i.Variants = VariantURLs(i.ImageURL)
+	return nil
+}
+
+// AfterSave fills Variants on the struct returned by upload handlers
+func (i *AnimalImage) AfterSave(tx *gorm.DB) error {
+	i.Variants = VariantURLs(i.ImageURL)
+	return nil
+}
+
+// AnimalImage moderation statuses. Pending images are hidden everywhere
+// until an admin approves them.
+const (
+	ImageModerationApproved = "approved"
+	ImageModerationPending  = "pending"
+)
+
+// Image variant sizes. Every uploaded animal image is also stored as a
+// smaller copy per size for list views; see upload.ImageVariants.
+const (
+	ImageVariantThumb = "thumb"
+	ImageVariantCard  = "card"
+)
+
+// AnimalImageVariant is a smaller copy of an AnimalImage. It is served at
+// the image's URL with ?size=<Size>. Images no bigger than a size have no
+// variant for it, and the full image is served instead.
+type AnimalImageVariant struct {
+	ID              uint        `gorm:"primaryKey" json:"id"`
+	CreatedAt       time.Time   `json:"created_at"`
+	ImageID         uint        `gorm:"not null;uniqueIndex:idx_image_variant_size" json:"image_id"`
+	Size            string      `gorm:"not null;uniqueIndex:idx_image_variant_size" json:"size"`
+	ImageData       []byte      `gorm:"type:bytea" json:"-"` // Null when using Azure
+	MimeType        string      `json:"mime_type"`
+	Width           int         `json:"width"`
+	Height          int         `json:"height"`
+	FileSize        int64       `json:"file_size"`
+	StorageProvider string      `gorm:"default:'postgres'" json:"-"`
+	BlobIdentifier  string      `json:"-"` // Azure blob UUID+ext
+	Image           AnimalImage `gorm:"foreignKey:ImageID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// ImageVariantURLs are the URLs of an image's sizes, smallest first
+type ImageVariantURLs struct {
+	Thumb string `json:"thumb"`
+	Card  string `json:"card"`
+	Full  string `json:"full"`
+}
+
+// ExternalImageProxy returns the image proxy URL for an external image at
+// one of the variant sizes ("" for full size), or "" when the image can't be
+// proxied. It is set at startup when the proxy is enabled; see
+// handlers.ProxiedImageURL.
+var ExternalImageProxy func(imageURL, size string) string
+
+// VariantURLs returns the size URLs for an uploaded image's URL, the proxy
+// URLs for an external image the image proxy accepts, or nil for any other
+// URL.
+func VariantURLs(imageURL string) *ImageVariantURLs {
+	if !strings.HasPrefix(imageURL, "/api/images/") || strings.Contains(imageURL, "?") {
+		return proxiedVariantURLs(imageURL)
+	}
+	return &ImageVariantURLs{
+		Thumb: imageURL + "?size=" + ImageVariantThumb,
+		Card:  imageURL + "?size=" + ImageVariantCard,
+		Full:  imageURL,
+	}
+}
+
+func proxiedVariantURLs(imageURL string) *ImageVariantURLs {
+	if ExternalImageProxy == nil || imageURL == "" {
+		return nil
+	}
+	full := ExternalImageProxy(imageURL, "")
+	if full == "" {
+		return nil
+	}
+	return &ImageVariantURLs{
+		Thumb: ExternalImageProxy(imageURL, ImageVariantThumb),
+		Card:  ExternalImageProxy(imageURL, ImageVariantCard),
+		Full:  full,
+	}
+}
+
+// ProxiedImage is a cached copy of an external image fetched by the image
+// proxy, one row per source URL and size. Rows are evicted least recently
+// used first once the cache outgrows its limit.
+type ProxiedImage struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	SourceHash string    `gorm:"size:64;not null;uniqueIndex:idx_proxied_image_source" json:"-"` // SHA-256 of SourceURL, hex
+	Size       string    `gorm:"not null;default:'';uniqueIndex:idx_proxied_image_source" json:"size"`
+	SourceURL  string    `gorm:"type:text;not null" json:"source_url"`
+	MimeType   string    `json:"mime_type"`
+	Bytes      int64     `gorm:"not null" json:"bytes"`
+	Data       []byte    `gorm:"type:bytea" json:"-"`
+	LastUsedAt time.Time `gorm:"index" json:"last_used_at"`
 }
 
 // AnimalVideo represents a video uploaded for an animal
@@ -488,9 +1250,18 @@ type AnimalNameHistory struct {
 	AnimalID  uint      `gorm:"not null;index:idx_name_history_animal" json:"animal_id"`
 	OldName   string    `gorm:"not null" json:"old_name"`
 	NewName   string    `gorm:"not null" json:"new_name"`
-	ChangedBy uint      `gorm:"not null" json:"changed_by"` // User ID who made the change
+	ChangedBy uint      `gorm:"not null" json:"changed_by"`         // User ID who made the change
+	Source    string    `gorm:"default:''" json:"source,omitempty"` // NameChangeEdit, NameChangeMerge, or NameChangeImport; empty for older records
 }
 
+// Sources of an AnimalNameHistory. Renames made by an edit are also in that
+// edit's AnimalChange.
+const (
+	NameChangeEdit   = "edit"
+	NameChangeMerge  = "merge"
+	NameChangeImport = "import"
+)
+
 // AnimalBQIncident records one bite-quarantine episode for an animal.
 // EndDate is nil while the episode is active; it is stamped when the animal leaves BQ.
 type AnimalBQIncident struct {
@@ -502,6 +1273,242 @@ type AnimalBQIncident struct {
 	EndDate         *time.Time `json:"end_date"`
 }
 
+// Weight units accepted on WeightEntry.Unit
+const (
+	WeightUnitLB = "lb"
+	WeightUnitKG = "kg"
+)
+
+// Animal relationship types. A parent relationship's AnimalID is the parent
+// and RelatedAnimalID the offspring; the others go both ways and are stored
+// with the lower animal ID first.
+const (
+	AnimalRelationshipBondedPair = "bonded_pair"
+	AnimalRelationshipLittermate = "littermate"
+	AnimalRelationshipParent     = "parent"
+)
+
+// AnimalRelationship links two animals, e.g. a bonded pair that must be
+// adopted together. Two animals have at most one relationship.
+type AnimalRelationship struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	AnimalID        uint      `gorm:"not null;uniqueIndex:idx_animal_relationship_pair" json:"animal_id"`
+	RelatedAnimalID uint      `gorm:"not null;uniqueIndex:idx_animal_relationship_pair;index" json:"related_animal_id"`
+	Type            string    `gorm:"not null" json:"type"` // One of the AnimalRelationship type constants
+	Notes           string    `json:"notes"`
+	CreatedByID     uint      `json:"created_by_id"`
+}
+
+// RelatedAnimal is an animal linked to another, as seen from that other
+// animal. Relation is bonded_pair, littermate, parent (this is the other
+// animal's parent), or offspring.
+type RelatedAnimal struct {
+	RelationshipID uint   `json:"relationship_id"`
+	Relation       string `json:"relation"`
+	Notes          string `json:"notes"`
+	AnimalID       uint   `json:"animal_id"`
+	GroupID        uint   `json:"group_id"`
+	Name           string `json:"name"`
+	Status         string `json:"status"`
+	ImageURL       string `json:"image_url"`
+}
+
+// WeightEntry records one weigh-in for an animal
+type WeightEntry struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	AnimalID     uint      `gorm:"not null;index:idx_weight_entry_animal_date" json:"animal_id"`
+	Weight       float64   `gorm:"not null" json:"weight"`
+	Unit         string    `gorm:"not null;default:'lb'" json:"unit"` // "lb" or "kg"
+	RecordedAt   time.Time `gorm:"not null;index:idx_weight_entry_animal_date" json:"recorded_at"`
+	RecordedByID uint      `gorm:"not null" json:"recorded_by_id"`
+	RecordedBy   *User     `gorm:"foreignKey:RecordedByID" json:"recorded_by,omitempty"`
+	Notes        string    `json:"notes"`
+}
+
+// Behavior assessment score range: 1 needs the most work, 5 is the easiest
+// to place
+const (
+	BehaviorScoreMin = 1
+	BehaviorScoreMax = 5
+)
+
+// BehaviorAssessment records one behavior evaluation of an animal. The
+// newest by AssessedAt is the animal's current score.
+type BehaviorAssessment struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	AnimalID     uint      `gorm:"not null;index:idx_behavior_assessment_animal_date" json:"animal_id"`
+	Score        int       `gorm:"not null" json:"score"`
+	AssessedAt   time.Time `gorm:"not null;index:idx_behavior_assessment_animal_date" json:"assessed_at"`
+	AssessedByID uint      `gorm:"not null" json:"assessed_by_id"`
+	AssessedBy   *User     `gorm:"foreignKey:AssessedByID" json:"assessed_by,omitempty"`
+	Notes        string    `json:"notes"`
+}
+
+// SavedFilter is a named set of animal list query parameters (e.g.
+// status=foster) a user keeps for one group. At most one per user and group
+// is the default, which GET /animals applies when no filters are given.
+type SavedFilter struct {
+	ID        uint         `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	UserID    uint         `gorm:"not null;index:idx_saved_filter_user_group" json:"user_id"`
+	GroupID   uint         `gorm:"not null;index:idx_saved_filter_user_group" json:"group_id"`
+	Name      string       `gorm:"not null" json:"name"`
+	Params    FilterParams `gorm:"type:text" json:"params"`
+	IsDefault bool         `gorm:"default:false" json:"is_default"`
+}
+
+// FilterParams holds animal list query parameters by name, one value each
+type FilterParams map[string]string
+
+// Scan implements sql.Scanner interface to convert database value to FilterParams
+func (fp *FilterParams) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, fp)
+	case string:
+		return json.Unmarshal([]byte(v), fp)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer interface to convert FilterParams to database value
+func (fp FilterParams) Value() (driver.Value, error) {
+	if len(fp) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(fp)
+	return string(data), err
+}
+
+// Foster sizes, from the animal's recorded size or else its latest
+// weigh-in; see FosterProfile.Sizes and AnimalSizes
+const (
+	FosterSizeSmall  = "small"  // Under 25 lb
+	FosterSizeMedium = "medium" // 25 to 60 lb
+	FosterSizeLarge  = "large"  // Over 60 lb
+)
+
+// FosterProfile is a volunteer's foster availability in one group, kept up
+// to date by the volunteer. Empty Species or Sizes accept any animal.
+type FosterProfile struct {
+	ID            uint            `gorm:"primaryKey" json:"id"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	UserID        uint            `gorm:"not null;uniqueIndex:idx_foster_profile_user_group" json:"user_id"`
+	GroupID       uint            `gorm:"not null;uniqueIndex:idx_foster_profile_user_group;index" json:"group_id"`
+	Available     bool            `gorm:"default:false" json:"available"`          // Taking new fosters at all
+	Capacity      int             `gorm:"not null;default:0" json:"capacity"`      // Animals the volunteer can foster at once
+	CurrentCount  int             `gorm:"not null;default:0" json:"current_count"` // Animals the volunteer is fostering now
+	Species       StringList      `gorm:"type:text" json:"species"`                // Species accepted, e.g. "Dog"
+	Sizes         StringList      `gorm:"type:text" json:"sizes"`                  // FosterSizeSmall, FosterSizeMedium, or FosterSizeLarge
+	BlackoutDates FosterBlackouts `gorm:"type:text" json:"blackout_dates"`
+	Notes         string          `json:"notes"`
+	User          *User           `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// FosterBlackout is an inclusive range of YYYY-MM-DD dates a volunteer
+// can't foster
+type FosterBlackout struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Note  string `json:"note,omitempty"`
+}
+
+// FosterBlackouts is a list of FosterBlackout stored as JSON
+type FosterBlackouts []FosterBlackout
+
+// Scan implements sql.Scanner interface to convert database value to FosterBlackouts
+func (fb *FosterBlackouts) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, fb)
+	case string:
+		return json.Unmarshal([]byte(v), fb)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer interface to convert FosterBlackouts to database value
+func (fb FosterBlackouts) Value() (driver.Value, error) {
+	if len(fb) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(fb)
+	return string(data), err
+}
+
+// AnimalView records a user opening an animal's detail page. Views are
+// throttled per user and animal, so one row is at most one visit.
+type AnimalView struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	AnimalID  uint      `gorm:"not null;index:idx_animal_view_animal_user" json:"animal_id"`
+	UserID    uint      `gorm:"not null;index:idx_animal_view_animal_user" json:"user_id"`
+}
+
+// AnimalShareLink is a public, read-only link to an animal's share page
+// (e.g. printed as a QR code on a kennel card). The link's URL carries a
+// signature of its ID, so nothing secret is stored; setting RevokedAt or
+// turning off the group's PublicSharing disables it.
+type AnimalShareLink struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	AnimalID     uint       `gorm:"not null;index" json:"animal_id"`
+	GroupID      uint       `gorm:"not null;index" json:"group_id"`
+	CreatedByID  uint       `gorm:"not null" json:"created_by_id"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	ViewCount    int        `gorm:"not null;default:0" json:"view_count"`
+	LastViewedAt *time.Time `json:"last_viewed_at"`
+}
+
+// Kennel card page sizes
+const (
+	KennelCardPageLetter     = "letter"
+	KennelCardPageHalfLetter = "half_letter"
+	KennelCardPageA4         = "a4"
+)
+
+// KennelCardTemplate controls how a group's printable kennel cards look. A
+// group without a row uses DefaultKennelCardTemplate.
+type KennelCardTemplate struct {
+	ID              uint      `gorm:"primaryKey" json:"-"`
+	CreatedAt       time.Time `json:"-"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	GroupID         uint      `gorm:"not null;uniqueIndex" json:"group_id"`
+	PageSize        string    `gorm:"not null" json:"page_size"`    // letter, half_letter, or a4
+	Heading         string    `json:"heading"`                      // Banner text; blank uses the group name
+	FooterText      string    `json:"footer_text"`                  // e.g. adoption contact details
+	AccentColor     string    `gorm:"not null" json:"accent_color"` // Banner color, #rrggbb
+	ShowPhoto       bool      `gorm:"not null" json:"show_photo"`
+	ShowBreed       bool      `gorm:"not null" json:"show_breed"`
+	ShowAge         bool      `gorm:"not null" json:"show_age"`
+	ShowTags        bool      `gorm:"not null" json:"show_tags"`
+	ShowDescription bool      `gorm:"not null" json:"show_description"`
+	ShowQRCode      bool      `gorm:"not null" json:"show_qr_code"` // QR code for the animal's newest active share link, if any
+}
+
+// DefaultKennelCardTemplate is the template a group uses until its admins
+// save their own.
+func DefaultKennelCardTemplate(groupID uint) KennelCardTemplate {
+	return KennelCardTemplate{
+		GroupID:         groupID,
+		PageSize:        KennelCardPageLetter,
+		AccentColor:     "#1f6f8b",
+		ShowPhoto:       true,
+		ShowBreed:       true,
+		ShowAge:         true,
+		ShowTags:        true,
+		ShowDescription: true,
+		ShowQRCode:      true,
+	}
+}
+
 // UserGroup represents the many-to-many relationship between users and groups
 // with additional fields for group-level permissions
 type UserGroup struct {
@@ -509,6 +1516,235 @@ type UserGroup struct {
 	GroupID      uint      `gorm:"primaryKey;index:idx_user_groups_group_id" json:"group_id"`
 	CreatedAt    time.Time `json:"created_at"`
 	IsGroupAdmin bool      `gorm:"default:false;index:idx_user_groups_user_admin" json:"is_group_admin"` // User has admin privileges for this specific group
+	Role         string    `gorm:"default:'volunteer'" json:"role"`                                      // GroupRoleNewVolunteer or GroupRoleVolunteer; group admins rank above either
 	User         User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
 	Group        Group     `gorm:"foreignKey:GroupID" json:"group,omitempty"`
 }
+
+// SetupJoinTables makes memberships added through User.Groups or
+// Group.Users write full UserGroup rows, with CreatedAt set, instead of bare
+// user and group IDs. Call it once on each connection before use.
+func SetupJoinTables(db *gorm.DB) error {
+	if err := db.SetupJoinTable(&User{}, "Groups", &UserGroup{}); err != nil {
+		return err
+	}
+	return db.SetupJoinTable(&Group{}, "Users", &UserGroup{})
+}
+
+// Group roles, lowest first. Members are GroupRoleNewVolunteer or
+// GroupRoleVolunteer (UserGroup.Role); group admins are GroupRoleAdmin
+// whatever their Role, and site admins rank above everyone.
+const (
+	GroupRoleNewVolunteer = "new_volunteer"
+	GroupRoleVolunteer    = "volunteer"
+	GroupRoleAdmin        = "group_admin"
+	GroupRoleSiteAdmin    = "site_admin"
+)
+
+// GroupRoles lists the group roles, lowest first
+var GroupRoles = []string{GroupRoleNewVolunteer, GroupRoleVolunteer, GroupRoleAdmin, GroupRoleSiteAdmin}
+
+// GroupFieldVisibility sets the lowest group role that sees an animal field
+// in a group's responses, in place of the built-in default for it
+type GroupFieldVisibility struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	GroupID   uint      `gorm:"not null;uniqueIndex:idx_group_field_visibility" json:"group_id"`
+	Field     string    `gorm:"not null;uniqueIndex:idx_group_field_visibility" json:"field"` // An animal JSON field, or "field.<key>" for a custom field
+	MinRole   string    `gorm:"not null" json:"min_role"`                                     // One of GroupRoles
+}
+
+// GroupRequiredField makes an animal field required when a group's animals
+// are created, beyond the name that's always required
+type GroupRequiredField struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	GroupID   uint      `gorm:"not null;uniqueIndex:idx_group_required_field" json:"group_id"`
+	Field     string    `gorm:"not null;uniqueIndex:idx_group_required_field" json:"field"` // An AnimalRequest JSON field
+}
+
+// Kinds of StatusChecklistItem
+const (
+	ChecklistPhoto              = "photo"               // The animal has a photo
+	ChecklistBehaviorAssessment = "behavior_assessment" // The animal has a behavior assessment
+	ChecklistWeight             = "weight"              // The animal has a weight on record
+	ChecklistMicrochip          = "microchip"           // The animal has a microchip number
+	ChecklistCustomField        = "custom_field"        // A custom field has a value; a boolean one must be true
+)
+
+// ChecklistKinds lists the kinds of StatusChecklistItem
+var ChecklistKinds = []string{ChecklistPhoto, ChecklistBehaviorAssessment, ChecklistWeight, ChecklistMicrochip, ChecklistCustomField}
+
+// StatusChecklistItem is one thing an animal in a group needs before it can
+// move into a status, such as a vaccination field being checked before it's
+// marked available
+type StatusChecklistItem struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	GroupID    uint      `gorm:"not null;index:idx_status_checklist_group_status" json:"group_id"`
+	ToStatus   string    `gorm:"not null;index:idx_status_checklist_group_status" json:"to_status"` // Status the item gates
+	Kind       string    `gorm:"not null" json:"kind"`                                              // One of ChecklistKinds
+	Field      string    `gorm:"default:''" json:"field,omitempty"`                                 // Custom field key, for custom_field items
+	Label      string    `gorm:"not null" json:"label"`
+	OrderIndex int       `gorm:"default:0" json:"order_index"`
+}
+
+// Kinds of OnboardingItem
+const (
+	OnboardingProtocols = "protocols" // Acknowledged every group protocol that asks for it; checked automatically
+	OnboardingProfile   = "profile"   // First name, last name, and phone number filled in; checked automatically
+	OnboardingTask      = "task"      // Checked off by the member
+	OnboardingConfirmed = "confirmed" // Checked off by a group admin, such as attending an orientation
+)
+
+// OnboardingKinds lists the kinds of OnboardingItem
+var OnboardingKinds = []string{OnboardingProtocols, OnboardingProfile, OnboardingTask, OnboardingConfirmed}
+
+// OnboardingItem is one step of a group's onboarding checklist for new
+// members
+type OnboardingItem struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	GroupID     uint      `gorm:"not null;index" json:"group_id"`
+	Kind        string    `gorm:"not null" json:"kind"` // One of OnboardingKinds
+	Label       string    `gorm:"not null" json:"label"`
+	Description string    `gorm:"type:text" json:"description"`
+	OrderIndex  int       `gorm:"default:0" json:"order_index"`
+}
+
+// OnboardingCompletion records that a user finished a task or confirmed
+// onboarding item. Automatic items are never stored.
+type OnboardingCompletion struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt     time.Time `json:"completed_at"`
+	ItemID        uint      `gorm:"not null;uniqueIndex:idx_onboarding_completion" json:"item_id"`
+	UserID        uint      `gorm:"not null;uniqueIndex:idx_onboarding_completion;index" json:"user_id"`
+	CompletedByID uint      `json:"completed_by_id"` // The member, or the group admin who confirmed it
+}
+
+// MemberOnboarding marks a member as onboarding in a group. It is created
+// for members who join once the group has an onboarding checklist, and
+// claims the member's welcome email.
+type MemberOnboarding struct {
+	ID                 uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt          time.Time  `json:"started_at"`
+	UserID             uint       `gorm:"not null;uniqueIndex:idx_member_onboarding" json:"user_id"`
+	GroupID            uint       `gorm:"not null;uniqueIndex:idx_member_onboarding;index" json:"group_id"`
+	WelcomeEmailSentAt *time.Time `json:"welcome_email_sent_at"`
+}
+
+// Group join request statuses
+const (
+	JoinRequestPending  = "pending"
+	JoinRequestApproved = "approved"
+	JoinRequestDenied   = "denied"
+)
+
+// GroupJoinRequest is a user's request to join a group, reviewed by the
+// group's admins. Approving it adds the user to the group.
+type GroupJoinRequest struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	GroupID      uint       `gorm:"not null;index:idx_join_requests_group_status" json:"group_id"`
+	UserID       uint       `gorm:"not null;index" json:"user_id"`
+	Message      string     `json:"message"`                                                                       // Optional note from the requester
+	Status       string     `gorm:"not null;default:'pending';index:idx_join_requests_group_status" json:"status"` // pending, approved, or denied
+	ReviewedByID *uint      `json:"reviewed_by_id,omitempty"`
+	ReviewedAt   *time.Time `json:"reviewed_at,omitempty"`
+	DenyReason   string     `json:"deny_reason,omitempty"` // Shown to the requester when denied
+	User         User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Group        Group      `gorm:"foreignKey:GroupID" json:"group,omitempty"`
+}
+
+// WeeklyStatsReport records that a group's weekly statistics email went
+// out for the week starting WeekStart, so each week is only sent once
+type WeeklyStatsReport struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	GroupID   uint      `gorm:"not null;uniqueIndex:idx_weekly_stats_group_week" json:"group_id"`
+	WeekStart time.Time `gorm:"not null;uniqueIndex:idx_weekly_stats_group_week" json:"week_start"` // The Monday's date, at midnight UTC
+}
+
+// StatusAlertRule flags a group's animals that have been in Status for more
+// than MaxDays days, and has the group's admins emailed when one does
+type StatusAlertRule struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	GroupID   uint      `gorm:"not null;index" json:"group_id"`
+	Status    string    `gorm:"not null" json:"status"` // An animal status key
+	MaxDays   int       `gorm:"not null" json:"max_days"`
+	Message   string    `gorm:"default:''" json:"message"` // What to do about it, e.g. "Legal hold is over; schedule the release exam"
+}
+
+// StatusAlertNotice records that a group's admins were emailed about an
+// animal passing a StatusAlertRule, so each stay in a status is only
+// reported once per limit. Notices are keyed by the rule's status and limit
+// rather than its ID, since saving a group's rules replaces the rows.
+type StatusAlertNotice struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	AnimalID    uint      `gorm:"not null;uniqueIndex:idx_status_alert_notice" json:"animal_id"`
+	Status      string    `gorm:"not null;uniqueIndex:idx_status_alert_notice" json:"status"`
+	MaxDays     int       `gorm:"not null;uniqueIndex:idx_status_alert_notice" json:"max_days"`
+	StatusSince time.Time `gorm:"not null;uniqueIndex:idx_status_alert_notice" json:"status_since"` // When the animal entered the status
+}
+
+// Job statuses
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+)
+
+// Job is a unit of background work run by the internal/jobs worker pool.
+// Payload is the job type's JSON-encoded input.
+type Job struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	Type        string     `gorm:"not null;index" json:"type"`
+	Payload     string     `gorm:"type:text;not null" json:"payload"`
+	Status      string     `gorm:"not null;default:'pending';index:idx_job_status_run_at" json:"status"`
+	RunAt       time.Time  `gorm:"not null;index:idx_job_status_run_at" json:"run_at"` // Earliest start of the next attempt
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts int        `gorm:"not null;default:5" json:"max_attempts"`
+	LastError   string     `gorm:"type:text" json:"last_error"`
+	LockedAt    *time.Time `json:"locked_at"` // When a worker claimed the current attempt
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+// Data export kinds
+const (
+	DataExportAnimals  = "animals"
+	DataExportComments = "comments"
+)
+
+// DataExport is a CSV export built by a background job for the user who
+// requested it. Status uses the Job statuses. The finished file is kept,
+// like a GroupDocument, in the storage provider or in FileData, until
+// ExpiresAt.
+type DataExport struct {
+	ID                 uint       `gorm:"primaryKey" json:"id"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	UserID             uint       `gorm:"not null;index" json:"user_id"`
+	Kind               string     `gorm:"not null" json:"kind"`
+	GroupID            *uint      `json:"group_id"`
+	AnimalID           *uint      `json:"animal_id"`
+	Tags               string     `json:"tags"` // Comma-separated comment tag filter
+	Status             string     `gorm:"not null;default:'pending'" json:"status"`
+	RowsTotal          int        `gorm:"not null;default:0" json:"rows_total"`
+	RowsDone           int        `gorm:"not null;default:0" json:"rows_done"`
+	Error              string     `gorm:"type:text" json:"error,omitempty"`
+	FileName           string     `json:"file_name"`
+	FileSize           int64      `json:"file_size"`
+	FileProvider       string     `json:"-"`
+	FileBlobIdentifier string     `json:"-"`
+	FileData           []byte     `gorm:"type:bytea" json:"-"`
+	CompletedAt        *time.Time `json:"completed_at"`
+	ExpiresAt          *time.Time `gorm:"index" json:"expires_at"`
+}
@@ -0,0 +1,70 @@
+// Package moderation screens uploaded images before they are published,
+// behind a provider-agnostic Moderator interface. A moderator that flags an
+// image sends it to quarantine, where it stays hidden until an admin approves
+// or rejects it.
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// Verdict is a moderator's decision about one image
+type Verdict string
+
+const (
+	VerdictApprove    Verdict = "approve"
+	VerdictQuarantine Verdict = "quarantine"
+)
+
+// Result is what a moderator concluded about an image
+type Result struct {
+	Verdict Verdict
+	// Labels are the moderator's reasons or detected content, e.g. "nudity",
+	// shown to admins reviewing a quarantined image
+	Labels []string
+}
+
+// Quarantined reports whether the image must wait for admin review
+func (r Result) Quarantined() bool {
+	return r.Verdict != VerdictApprove
+}
+
+// Moderator defines the interface that all moderation providers must implement
+type Moderator interface {
+	// ModerateImage inspects an image as it will be stored. Callers treat an
+	// error as a quarantine verdict, so a moderator outage never publishes
+	// unscreened images.
+	ModerateImage(ctx context.Context, data []byte, mimeType string) (Result, error)
+
+	// GetProviderName returns the name of the provider for logging
+	GetProviderName() string
+}
+
+// ProviderType represents the type of moderation provider
+type ProviderType string
+
+const (
+	ProviderTypeNone    ProviderType = "none"
+	ProviderTypeWebhook ProviderType = "webhook"
+)
+
+// NewModerator creates a moderator based on environment configuration.
+// Returns a nil moderator, which approves every image, if MODERATION_PROVIDER
+// is unset or "none".
+func NewModerator() (Moderator, error) {
+	providerType := os.Getenv("MODERATION_PROVIDER")
+	switch ProviderType(providerType) {
+	case "", ProviderTypeNone:
+		return nil, nil
+	case ProviderTypeWebhook:
+		provider := NewWebhookModerator()
+		if provider.URL == "" {
+			return nil, fmt.Errorf("MODERATION_WEBHOOK_URL is required for the webhook moderation provider")
+		}
+		return provider, nil
+	default:
+		return nil, fmt.Errorf("unsupported moderation provider: %s", providerType)
+	}
+}
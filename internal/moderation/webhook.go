@@ -0,0 +1,105 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultWebhookTimeout = 15 * time.Second
+	maxWebhookResponse    = 64 << 10
+	maxLabels             = 20
+	maxLabelLength        = 100
+)
+
+// WebhookModerator implements the Moderator interface by POSTing each image
+// to an external service, such as a wrapper around an NSFW or labelling API.
+//
+// The request body is the raw image with its Content-Type, authenticated with
+// "Authorization: Bearer <token>" when a token is set. The service answers
+// 200 with {"verdict": "approve" | "quarantine", "labels": ["..."]}.
+type WebhookModerator struct {
+	URL    string
+	Token  string
+	client *http.Client
+}
+
+// NewWebhookModerator creates a webhook moderator from environment variables
+func NewWebhookModerator() *WebhookModerator {
+	timeout := defaultWebhookTimeout
+	if seconds, err := strconv.Atoi(os.Getenv("MODERATION_WEBHOOK_TIMEOUT_SECONDS")); err == nil && seconds > 0 {
+		timeout = time.Duration(seconds) * time.Second
+	}
+	return &WebhookModerator{
+		URL:    os.Getenv("MODERATION_WEBHOOK_URL"),
+		Token:  os.Getenv("MODERATION_WEBHOOK_TOKEN"),
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// GetProviderName returns the provider name for logging
+func (m *WebhookModerator) GetProviderName() string {
+	return "webhook"
+}
+
+// webhookResponse is the body the moderation service answers with
+type webhookResponse struct {
+	Verdict Verdict  `json:"verdict"`
+	Labels  []string `json:"labels"`
+}
+
+// ModerateImage sends the image to the webhook and returns its verdict
+func (m *WebhookModerator) ModerateImage(ctx context.Context, data []byte, mimeType string) (Result, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.URL, bytes.NewReader(data))
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", mimeType)
+	req.Header.Set("Accept", "application/json")
+	if m.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+m.Token)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("moderation webhook returned status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponse))
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to read response: %w", err)
+	}
+	var parsed webhookResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return Result{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if parsed.Verdict != VerdictApprove && parsed.Verdict != VerdictQuarantine {
+		return Result{}, fmt.Errorf("moderation webhook returned unknown verdict %q", parsed.Verdict)
+	}
+
+	labels := make([]string, 0, min(len(parsed.Labels), maxLabels))
+	for _, label := range parsed.Labels {
+		if len(labels) == maxLabels {
+			break
+		}
+		if label == "" {
+			continue
+		}
+		if len(label) > maxLabelLength {
+			label = label[:maxLabelLength]
+		}
+		labels = append(labels, label)
+	}
+	return Result{Verdict: parsed.Verdict, Labels: labels}, nil
+}
@@ -0,0 +1,98 @@
+package moderation
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestWebhookModerator(server *httptest.Server) *WebhookModerator {
+	return &WebhookModerator{URL: server.URL, Token: "secret", client: server.Client()}
+}
+
+func TestWebhookModerator_ModerateImage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected method %s", r.Method)
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("unexpected Authorization header %q", r.Header.Get("Authorization"))
+		}
+		if r.Header.Get("Content-Type") != "image/png" {
+			t.Errorf("unexpected Content-Type %q", r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		if string(body) == "safe" {
+			_, _ = w.Write([]byte(`{"verdict":"approve"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"verdict":"quarantine","labels":["nudity",""]}`))
+	}))
+	defer server.Close()
+	moderator := newTestWebhookModerator(server)
+
+	result, err := moderator.ModerateImage(context.Background(), []byte("safe"), "image/png")
+	if err != nil {
+		t.Fatalf("ModerateImage: %v", err)
+	}
+	if result.Quarantined() {
+		t.Errorf("expected approval, got %+v", result)
+	}
+
+	result, err = moderator.ModerateImage(context.Background(), []byte("unsafe"), "image/png")
+	if err != nil {
+		t.Fatalf("ModerateImage: %v", err)
+	}
+	if !result.Quarantined() || len(result.Labels) != 1 || result.Labels[0] != "nudity" {
+		t.Errorf("expected quarantine labelled nudity, got %+v", result)
+	}
+}
+
+func TestWebhookModerator_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"server error", http.StatusInternalServerError, `{"verdict":"approve"}`},
+		{"invalid JSON", http.StatusOK, `approve`},
+		{"unknown verdict", http.StatusOK, `{"verdict":"maybe"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			if _, err := newTestWebhookModerator(server).ModerateImage(context.Background(), []byte("x"), "image/jpeg"); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestNewModerator(t *testing.T) {
+	t.Setenv("MODERATION_PROVIDER", "")
+	if m, err := NewModerator(); m != nil || err != nil {
+		t.Errorf("unset provider should disable moderation, got %v, %v", m, err)
+	}
+
+	t.Setenv("MODERATION_PROVIDER", "webhook")
+	t.Setenv("MODERATION_WEBHOOK_URL", "")
+	if _, err := NewModerator(); err == nil {
+		t.Error("webhook provider without a URL should fail")
+	}
+	t.Setenv("MODERATION_WEBHOOK_URL", "https://moderation.example.com/check")
+	if m, err := NewModerator(); err != nil || m.GetProviderName() != "webhook" {
+		t.Errorf("expected webhook moderator, got %v, %v", m, err)
+	}
+
+	t.Setenv("MODERATION_PROVIDER", "bogus")
+	if _, err := NewModerator(); err == nil {
+		t.Error("unknown provider should fail")
+	}
+}
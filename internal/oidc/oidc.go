@@ -0,0 +1,394 @@
+// Package oidc implements the OpenID Connect authorization code flow, with
+// PKCE, for signing in with Google or Microsoft accounts: provider
+// discovery, the authorization redirect, the code exchange, and ID token
+// verification against the provider's published signing keys.
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// requestTimeout bounds each call to a provider (discovery, keys, token
+	// exchange); a login waits on them.
+	requestTimeout = 10 * time.Second
+
+	// maxResponseBodyBytes bounds how much of a provider response is read.
+	maxResponseBodyBytes = 1 << 20
+
+	// discoveryTTL is how long a discovery document is cached.
+	discoveryTTL = time.Hour
+
+	// keyRefreshInterval is the least time between signing key fetches, so
+	// tokens with unknown key IDs can't make us hammer the provider.
+	keyRefreshInterval = time.Minute
+)
+
+// Microsoft's multi-tenant endpoints issue tokens whose issuer names the
+// user's tenant; discovery documents hold this placeholder for it.
+const tenantPlaceholder = "{tenantid}"
+
+// Claims are the verified identity claims of an ID token.
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	GivenName     string
+	FamilyName    string
+}
+
+// Provider is an OpenID Connect identity provider the app is registered
+// with as a client.
+type Provider struct {
+	Name         string // URL-safe identifier, e.g. "google"
+	DisplayName  string // Shown on the login button, e.g. "Google"
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+
+	// TrustEmail treats the email claim as verified when the token carries
+	// no email_verified claim. Microsoft omits the claim; for a
+	// single-tenant app the organization controls its addresses.
+	TrustEmail bool
+
+	client *http.Client
+
+	mu            sync.Mutex
+	discovery     *discoveryDocument
+	discoveredAt  time.Time
+	keys          map[string]*rsa.PublicKey
+	keysFetchedAt time.Time
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// NewProvider returns a provider discovered from issuer's
+// /.well-known/openid-configuration.
+func NewProvider(name, displayName, issuer, clientID, clientSecret string) *Provider {
+	return &Provider{
+		Name:         name,
+		DisplayName:  displayName,
+		Issuer:       strings.TrimRight(issuer, "/"),
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		client:       &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Providers is the set of configured providers, in display order.
+type Providers struct {
+	list []*Provider
+}
+
+// NewProviders returns a set of the given providers.
+func NewProviders(providers ...*Provider) *Providers {
+	return &Providers{list: providers}
+}
+
+// ProvidersFromEnv configures Google (OIDC_GOOGLE_CLIENT_ID and
+// OIDC_GOOGLE_CLIENT_SECRET) and Microsoft (OIDC_MICROSOFT_CLIENT_ID,
+// OIDC_MICROSOFT_CLIENT_SECRET, and optionally OIDC_MICROSOFT_TENANT, which
+// defaults to "common") from the environment. Providers without a client ID
+// are left out; a client ID without a secret is an error.
+func ProvidersFromEnv() (*Providers, error) {
+	providers := &Providers{}
+
+	if id := strings.TrimSpace(os.Getenv("OIDC_GOOGLE_CLIENT_ID")); id != "" {
+		secret := strings.TrimSpace(os.Getenv("OIDC_GOOGLE_CLIENT_SECRET"))
+		if secret == "" {
+			return nil, errors.New("OIDC_GOOGLE_CLIENT_SECRET is required when OIDC_GOOGLE_CLIENT_ID is set")
+		}
+		providers.list = append(providers.list, NewProvider("google", "Google", "https://accounts.google.com", id, secret))
+	}
+
+	if id := strings.TrimSpace(os.Getenv("OIDC_MICROSOFT_CLIENT_ID")); id != "" {
+		secret := strings.TrimSpace(os.Getenv("OIDC_MICROSOFT_CLIENT_SECRET"))
+		if secret == "" {
+			return nil, errors.New("OIDC_MICROSOFT_CLIENT_SECRET is required when OIDC_MICROSOFT_CLIENT_ID is set")
+		}
+		tenant := strings.TrimSpace(os.Getenv("OIDC_MICROSOFT_TENANT"))
+		if tenant == "" {
+			tenant = "common"
+		}
+		if strings.ContainsAny(tenant, "/?#") {
+			return nil, fmt.Errorf("OIDC_MICROSOFT_TENANT %q is not a tenant ID or domain", tenant)
+		}
+		p := NewProvider("microsoft", "Microsoft", "https://login.microsoftonline.com/"+tenant+"/v2.0", id, secret)
+		switch tenant {
+		case "common", "organizations", "consumers":
+		default:
+			p.TrustEmail = true
+		}
+		providers.list = append(providers.list, p)
+	}
+
+	return providers, nil
+}
+
+// Get returns the provider called name.
+func (ps *Providers) Get(name string) (*Provider, bool) {
+	if ps == nil {
+		return nil, false
+	}
+	for _, p := range ps.list {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// List returns the configured providers.
+func (ps *Providers) List() []*Provider {
+	if ps == nil {
+		return nil
+	}
+	return ps.list
+}
+
+// AuthCodeURL returns the provider URL to send the browser to. state and
+// nonce are echoed back in the callback and ID token; verifier is the PKCE
+// code verifier later passed to Exchange.
+func (p *Provider) AuthCodeURL(ctx context.Context, redirectURI, state, nonce, verifier string) (string, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.ClientID},
+		"redirect_uri":          {redirectURI},
+		"scope":                 {"openid email profile"},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+		"prompt":                {"select_account"},
+	}
+	sep := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return d.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange trades an authorization code for tokens and returns the verified
+// claims of the ID token, which must carry nonce.
+func (p *Provider) Exchange(ctx context.Context, code, redirectURI, verifier, nonce string) (*Claims, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {p.ClientID},
+		"client_secret": {p.ClientSecret},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := p.doJSON(req, &tokens); err != nil {
+		return nil, fmt.Errorf("token exchange: %w", err)
+	}
+	if tokens.IDToken == "" {
+		return nil, errors.New("token exchange: no id_token in response")
+	}
+	return p.verify(ctx, d, tokens.IDToken, nonce)
+}
+
+// idTokenClaims are the ID token claims read by verify. email_verified is
+// usually a boolean but some providers send the string "true".
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string `json:"nonce"`
+	Email         string `json:"email"`
+	EmailVerified any    `json:"email_verified"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+	TenantID      string `json:"tid"`      // Microsoft
+	EmailDomainOK any    `json:"xms_edov"` // Microsoft: the email's domain is verified by the tenant
+}
+
+func truthy(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
+
+func (p *Provider) verify(ctx context.Context, d *discoveryDocument, rawIDToken, nonce string) (*Claims, error) {
+	var c idTokenClaims
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithAudience(p.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if _, err := parser.ParseWithClaims(rawIDToken, &c, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, d, kid)
+	}); err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+
+	wantIssuer := d.Issuer
+	if strings.Contains(wantIssuer, tenantPlaceholder) {
+		if c.TenantID == "" {
+			return nil, errors.New("invalid id_token: no tenant")
+		}
+		wantIssuer = strings.ReplaceAll(wantIssuer, tenantPlaceholder, c.TenantID)
+	}
+	if c.Issuer != wantIssuer {
+		return nil, fmt.Errorf("invalid id_token: issuer %q, want %q", c.Issuer, wantIssuer)
+	}
+	if subtle.ConstantTimeCompare([]byte(c.Nonce), []byte(nonce)) != 1 {
+		return nil, errors.New("invalid id_token: nonce mismatch")
+	}
+	if c.Subject == "" {
+		return nil, errors.New("invalid id_token: no subject")
+	}
+
+	verified := truthy(c.EmailVerified)
+	if c.EmailVerified == nil {
+		verified = p.TrustEmail || truthy(c.EmailDomainOK)
+	}
+	return &Claims{
+		Subject:       c.Subject,
+		Email:         strings.TrimSpace(c.Email),
+		EmailVerified: verified && c.Email != "",
+		GivenName:     c.GivenName,
+		FamilyName:    c.FamilyName,
+	}, nil
+}
+
+// discover returns the provider's discovery document, cached for
+// discoveryTTL.
+func (p *Provider) discover(ctx context.Context) (*discoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil && time.Since(p.discoveredAt) < discoveryTTL {
+		return p.discovery, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var d discoveryDocument
+	if err := p.doJSON(req, &d); err != nil {
+		return nil, fmt.Errorf("discovery: %w", err)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" || d.Issuer == "" {
+		return nil, errors.New("discovery: document is missing endpoints")
+	}
+	p.discovery = &d
+	p.discoveredAt = time.Now()
+	return p.discovery, nil
+}
+
+// key returns the signing key kid, refetching the provider's keys when kid
+// is unknown (providers rotate them) at most once per keyRefreshInterval.
+func (p *Provider) key(ctx context.Context, d *discoveryDocument, kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if k, ok := p.keys[kid]; ok {
+		return k, nil
+	}
+	if time.Since(p.keysFetchedAt) < keyRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := p.doJSON(req, &set); err != nil {
+		return nil, fmt.Errorf("signing keys: %w", err)
+	}
+	p.keysFetchedAt = time.Now()
+	p.keys = make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil || len(e) > 4 {
+			continue
+		}
+		p.keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+
+	if k, ok := p.keys[kid]; ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// doJSON sends req and decodes a 200 response into v.
+func (p *Provider) doJSON(req *http.Request, v any) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodyBytes))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		// Token endpoint errors are {"error": "...", "error_description": "..."};
+		// don't echo anything else, which could include the request
+		var oauthErr struct {
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal(body, &oauthErr)
+		return fmt.Errorf("%s returned %d %s", req.URL.Host, resp.StatusCode, oauthErr.Error)
+	}
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,214 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeIssuer is an OpenID provider whose token endpoint returns idToken
+// signed with its key, after checking the PKCE verifier.
+type fakeIssuer struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	issuer   string // Issuer in the discovery document; defaults to the server URL
+	idClaims jwt.MapClaims
+	verifier string
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeIssuer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		issuer := f.issuer
+		if issuer == "" {
+			issuer = f.server.URL
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer,
+			"authorization_endpoint": f.server.URL + "/authorize",
+			"token_endpoint":         f.server.URL + "/token",
+			"jwks_uri":               f.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("code") != "good-code" || r.PostFormValue("code_verifier") != f.verifier {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, f.idClaims)
+		token.Header["kid"] = "k1"
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Error(err)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": signed})
+	})
+	f.server = httptest.NewServer(mux)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeIssuer) claims(overrides jwt.MapClaims) jwt.MapClaims {
+	now := time.Now()
+	c := jwt.MapClaims{
+		"iss":            f.server.URL,
+		"aud":            "client-id",
+		"sub":            "subject-1",
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"nonce":          "the-nonce",
+		"email":          "jane@example.org",
+		"email_verified": true,
+		"given_name":     "Jane",
+	}
+	for k, v := range overrides {
+		if v == nil {
+			delete(c, k)
+		} else {
+			c[k] = v
+		}
+	}
+	return c
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	f := newFakeIssuer(t)
+	p := NewProvider("test", "Test", f.server.URL, "client-id", "secret")
+
+	raw, err := p.AuthCodeURL(context.Background(), "https://app.example.org/cb", "st", "no", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(raw)
+	q := u.Query()
+	challenge := sha256.Sum256([]byte("verifier"))
+	if !strings.HasPrefix(raw, f.server.URL+"/authorize?") ||
+		q.Get("state") != "st" || q.Get("nonce") != "no" || q.Get("client_id") != "client-id" ||
+		q.Get("code_challenge") != base64.RawURLEncoding.EncodeToString(challenge[:]) || q.Get("code_challenge_method") != "S256" {
+		t.Errorf("unexpected auth URL %s", raw)
+	}
+}
+
+func TestExchange(t *testing.T) {
+	tests := []struct {
+		name       string
+		overrides  jwt.MapClaims
+		issuer     string
+		trustEmail bool
+		code       string
+		wantErr    string
+		wantEmail  bool
+	}{
+		{name: "valid", wantEmail: true},
+		{name: "unverified email", overrides: jwt.MapClaims{"email_verified": false}},
+		{name: "string email_verified", overrides: jwt.MapClaims{"email_verified": "true"}, wantEmail: true},
+		{name: "no email_verified claim", overrides: jwt.MapClaims{"email_verified": nil}},
+		{name: "trusted tenant email", overrides: jwt.MapClaims{"email_verified": nil}, trustEmail: true, wantEmail: true},
+		{name: "wrong audience", overrides: jwt.MapClaims{"aud": "someone-else"}, wantErr: "audience"},
+		{name: "expired", overrides: jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}, wantErr: "expired"},
+		{name: "wrong issuer", overrides: jwt.MapClaims{"iss": "https://evil.example"}, wantErr: "issuer"},
+		{name: "wrong nonce", overrides: jwt.MapClaims{"nonce": "replayed"}, wantErr: "nonce"},
+		{name: "bad code", code: "bad-code", wantErr: "invalid_grant"},
+		{
+			name:      "tenant issuer",
+			issuer:    "https://login.example/{tenantid}/v2.0",
+			overrides: jwt.MapClaims{"iss": "https://login.example/t-1/v2.0", "tid": "t-1"},
+			wantEmail: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeIssuer(t)
+			f.issuer = tt.issuer
+			f.idClaims = f.claims(tt.overrides)
+			f.verifier = "verifier"
+			p := NewProvider("test", "Test", f.server.URL, "client-id", "secret")
+			p.TrustEmail = tt.trustEmail
+			code := tt.code
+			if code == "" {
+				code = "good-code"
+			}
+
+			claims, err := p.Exchange(context.Background(), code, "https://app.example.org/cb", "verifier", "the-nonce")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.Subject != "subject-1" || claims.Email != "jane@example.org" || claims.GivenName != "Jane" {
+				t.Errorf("unexpected claims %+v", claims)
+			}
+			if claims.EmailVerified != tt.wantEmail {
+				t.Errorf("EmailVerified = %v, want %v", claims.EmailVerified, tt.wantEmail)
+			}
+		})
+	}
+}
+
+func TestProvidersFromEnv(t *testing.T) {
+	t.Setenv("OIDC_GOOGLE_CLIENT_ID", "")
+	t.Setenv("OIDC_MICROSOFT_CLIENT_ID", "")
+	ps, err := ProvidersFromEnv()
+	if err != nil || len(ps.List()) != 0 {
+		t.Fatalf("got %v, %v; want no providers", ps.List(), err)
+	}
+
+	t.Setenv("OIDC_GOOGLE_CLIENT_ID", "g-id")
+	t.Setenv("OIDC_GOOGLE_CLIENT_SECRET", "")
+	if _, err := ProvidersFromEnv(); err == nil {
+		t.Error("expected an error for a client ID without a secret")
+	}
+
+	t.Setenv("OIDC_GOOGLE_CLIENT_SECRET", "g-secret")
+	t.Setenv("OIDC_MICROSOFT_CLIENT_ID", "m-id")
+	t.Setenv("OIDC_MICROSOFT_CLIENT_SECRET", "m-secret")
+	t.Setenv("OIDC_MICROSOFT_TENANT", "contoso.onmicrosoft.com")
+	ps, err = ProvidersFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ms, ok := ps.Get("microsoft")
+	if !ok || len(ps.List()) != 2 {
+		t.Fatalf("got %d providers; want google and microsoft", len(ps.List()))
+	}
+	if ms.Issuer != "https://login.microsoftonline.com/contoso.onmicrosoft.com/v2.0" || !ms.TrustEmail {
+		t.Errorf("single-tenant Microsoft: issuer %q, TrustEmail %v", ms.Issuer, ms.TrustEmail)
+	}
+
+	t.Setenv("OIDC_MICROSOFT_TENANT", "")
+	ps, _ = ProvidersFromEnv()
+	if ms, _ := ps.Get("microsoft"); ms.TrustEmail {
+		t.Error("multi-tenant Microsoft must not trust unverified emails")
+	}
+}
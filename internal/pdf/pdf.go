@@ -0,0 +1,225 @@
+// Package pdf writes simple PDF documents: text in the standard Helvetica
+// fonts, filled rectangles, lines, and RGB images. It covers what printable
+// pages like kennel cards need and nothing more. Fonts are not embedded, so
+// text is limited to the Windows-1252 character set; anything outside it is
+// printed as "?".
+//
+// Coordinates are in points (1/72 inch) measured from the top-left corner of
+// the page, with y growing downwards.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+)
+
+// Size is a page size in points.
+type Size struct {
+	Width, Height float64
+}
+
+// Common page sizes.
+var (
+	Letter     = Size{612, 792}
+	HalfLetter = Size{396, 612}
+	A4         = Size{595.28, 841.89}
+)
+
+// Font is one of the standard PDF fonts.
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+var fontNames = [...]string{Helvetica: "Helvetica", HelveticaBold: "Helvetica-Bold"}
+
+// Document is a PDF being built page by page. The zero value is not usable;
+// create one with New.
+type Document struct {
+	size   Size
+	pages  []*bytes.Buffer
+	images [][]byte // complete image XObject bodies
+}
+
+// New starts a document whose pages are all the given size.
+func New(size Size) *Document {
+	return &Document{size: size}
+}
+
+// Size returns the page size.
+func (d *Document) Size() Size {
+	return d.size
+}
+
+// AddPage starts a new page; subsequent drawing goes to it.
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+func (d *Document) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// y converts a top-down coordinate to PDF's bottom-up one.
+func (d *Document) y(y float64) float64 {
+	return d.size.Height - y
+}
+
+func rgb(c color.Color) (float64, float64, float64) {
+	r, g, b, _ := c.RGBA()
+	return float64(r) / 0xffff, float64(g) / 0xffff, float64(b) / 0xffff
+}
+
+// SetFillColor sets the color used by Text and Rect.
+func (d *Document) SetFillColor(c color.Color) {
+	r, g, b := rgb(c)
+	fmt.Fprintf(d.page(), "%.3f %.3f %.3f rg\n", r, g, b)
+}
+
+// SetStrokeColor sets the color used by Line.
+func (d *Document) SetStrokeColor(c color.Color) {
+	r, g, b := rgb(c)
+	fmt.Fprintf(d.page(), "%.3f %.3f %.3f RG\n", r, g, b)
+}
+
+// Text draws s with its baseline at y, starting at x.
+func (d *Document) Text(font Font, size, x, y float64, s string) {
+	fmt.Fprintf(d.page(), "BT /F%d %.2f Tf %.2f %.2f Td (%s) Tj ET\n",
+		font+1, size, x, d.y(y), escape(encodeWinAnsi(s)))
+}
+
+// Rect fills a rectangle whose top-left corner is (x, y).
+func (d *Document) Rect(x, y, w, h float64) {
+	fmt.Fprintf(d.page(), "%.2f %.2f %.2f %.2f re f\n", x, d.y(y+h), w, h)
+}
+
+// Line strokes a straight line.
+func (d *Document) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(d.page(), "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, d.y(y1), x2, d.y(y2))
+}
+
+// Image draws img scaled to fill the w×h box whose top-left corner is (x, y).
+// Transparent pixels are composited onto white.
+func (d *Document) Image(img image.Image, x, y, w, h float64) error {
+	b := img.Bounds()
+	raw := make([]byte, 0, b.Dx()*b.Dy()*3)
+	for py := b.Min.Y; py < b.Max.Y; py++ {
+		for px := b.Min.X; px < b.Max.X; px++ {
+			r, g, bl, a := img.At(px, py).RGBA()
+			// Colors are alpha-premultiplied, so adding the white
+			// background's share is enough to flatten them
+			white := 0xffff - a
+			raw = append(raw, byte((r+white)>>8), byte((g+white)>>8), byte((bl+white)>>8))
+		}
+	}
+
+	var data bytes.Buffer
+	zw := zlib.NewWriter(&data)
+	if _, err := zw.Write(raw); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	var obj bytes.Buffer
+	fmt.Fprintf(&obj, "<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode /Length %d >>\nstream\n",
+		b.Dx(), b.Dy(), data.Len())
+	obj.Write(data.Bytes())
+	obj.WriteString("\nendstream")
+	d.images = append(d.images, obj.Bytes())
+
+	fmt.Fprintf(d.page(), "q %.2f 0 0 %.2f %.2f %.2f cm /Im%d Do Q\n", w, h, x, d.y(y+h), len(d.images))
+	return nil
+}
+
+// Bytes returns the finished document.
+func (d *Document) Bytes() []byte {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	// Object numbers: catalog, page tree, two fonts, images, then a page
+	// and its content stream for each page
+	const (
+		catalogObj = 1
+		pagesObj   = 2
+		fontObj    = 3
+	)
+	imageObj := fontObj + len(fontNames)
+	pageObj := imageObj + len(d.images)
+
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object(fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObj))
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", pageObj+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+
+	for _, name := range fontNames {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+	}
+	for _, img := range d.images {
+		object(string(img))
+	}
+
+	var resources strings.Builder
+	resources.WriteString("<< /Font <<")
+	for i := range fontNames {
+		fmt.Fprintf(&resources, " /F%d %d 0 R", i+1, fontObj+i)
+	}
+	resources.WriteString(" >>")
+	if len(d.images) > 0 {
+		resources.WriteString(" /XObject <<")
+		for i := range d.images {
+			fmt.Fprintf(&resources, " /Im%d %d 0 R", i+1, imageObj+i)
+		}
+		resources.WriteString(" >>")
+	}
+	resources.WriteString(" >>")
+
+	for i, content := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Resources %s /Contents %d 0 R >>",
+			pagesObj, d.size.Width, d.size.Height, resources.String(), pageObj+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, catalogObj, xref)
+	return out.Bytes()
+}
+
+// escape backslash-escapes the characters that are special inside a PDF
+// literal string.
+func escape(b []byte) string {
+	var s strings.Builder
+	for _, c := range b {
+		if c == '(' || c == ')' || c == '\\' {
+			s.WriteByte('\\')
+		}
+		s.WriteByte(c)
+	}
+	return s.String()
+}
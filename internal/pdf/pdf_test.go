@@ -0,0 +1,137 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// checkStructure verifies that the xref table points at each object and that
+// startxref points at the table, which is what readers rely on to open a file.
+func checkStructure(t *testing.T, doc []byte) {
+	t.Helper()
+	if !bytes.HasPrefix(doc, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+		t.Fatalf("missing header or trailer")
+	}
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(doc)
+	if m == nil {
+		t.Fatalf("no startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(doc[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(doc[xref:], -1)
+	if len(entries) == 0 {
+		t.Fatalf("empty xref table")
+	}
+	for i, e := range entries {
+		off, _ := strconv.Atoi(string(e[1]))
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(doc[off:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", i+1, doc[off:off+10])
+		}
+	}
+	if !bytes.Contains(doc, []byte(fmt.Sprintf("/Size %d ", len(entries)+1))) {
+		t.Errorf("trailer /Size does not match %d objects", len(entries))
+	}
+}
+
+func TestDocument(t *testing.T) {
+	d := New(Letter)
+	d.AddPage()
+	d.SetFillColor(color.RGBA{0x1f, 0x6f, 0x8b, 0xff})
+	d.Rect(0, 0, 612, 72)
+	d.Text(HelveticaBold, 24, 36, 48, "Rex (the good boy) \\ café")
+	d.SetStrokeColor(color.Black)
+	d.Line(36, 100, 576, 100, 1)
+
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	if err := d.Image(img, 36, 120, 200, 200); err != nil {
+		t.Fatalf("Image: %v", err)
+	}
+	d.AddPage()
+	d.Text(Helvetica, 12, 36, 36, "Page two")
+
+	doc := d.Bytes()
+	checkStructure(t, doc)
+
+	for _, want := range []string{
+		"/Count 2",
+		"/BaseFont /Helvetica-Bold",
+		"/MediaBox [0 0 612.00 792.00]",
+		"/Width 2 /Height 2",
+		"/Im1 Do",
+		// Parentheses and backslashes are escaped; é is one Windows-1252 byte
+		"(Rex \\(the good boy\\) \\\\ caf\xe9) Tj",
+		// y is measured from the top: a baseline 48pt down sits at 792-48
+		"36.00 744.00 Td",
+		"0 720.00 612.00 72.00 re f",
+	} {
+		if !bytes.Contains(doc, []byte(want)) {
+			t.Errorf("document does not contain %q", want)
+		}
+	}
+}
+
+func TestDocument_Empty(t *testing.T) {
+	doc := New(A4).Bytes()
+	checkStructure(t, doc)
+	if !bytes.Contains(doc, []byte("/Count 1")) {
+		t.Errorf("an empty document should still have one page")
+	}
+}
+
+func TestEncodeWinAnsi(t *testing.T) {
+	got := encodeWinAnsi("a\tb\x01 “Rex” — 🐕")
+	want := []byte("a b \x93Rex\x94 \x97 ?")
+	if !bytes.Equal(got, want) {
+		t.Errorf("encodeWinAnsi = %q, want %q", got, want)
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	// "Hi" is H (722) + i (222) in Helvetica
+	if got := TextWidth(Helvetica, 10, "Hi"); got != 9.44 {
+		t.Errorf("TextWidth(Helvetica) = %v, want 9.44", got)
+	}
+	if TextWidth(HelveticaBold, 10, "Hi") <= TextWidth(Helvetica, 10, "Hi") {
+		t.Errorf("bold text should be wider")
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	width := TextWidth(Helvetica, 10, "loves long walks")
+	got := WrapText(Helvetica, 10, "Rex loves long walks and\nnaps in the sun", width)
+	want := []string{"Rex loves long", "walks and", "naps in the sun"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("WrapText = %q, want %q", got, want)
+	}
+
+	// A word wider than the line is split between characters
+	got = WrapText(Helvetica, 10, "Supercalifragilistic", TextWidth(Helvetica, 10, "Supercal"))
+	if len(got) < 2 || strings.Join(got, "") != "Supercalifragilistic" {
+		t.Errorf("WrapText long word = %q", got)
+	}
+	for _, line := range got {
+		if TextWidth(Helvetica, 10, line) > TextWidth(Helvetica, 10, "Supercal") {
+			t.Errorf("line %q is too wide", line)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := Truncate(Helvetica, 10, "short", 100); got != "short" {
+		t.Errorf("Truncate kept %q, want it unchanged", got)
+	}
+	max := TextWidth(Helvetica, 10, "Rex loves…")
+	got := Truncate(Helvetica, 10, "Rex loves long walks", max)
+	if got != "Rex loves…" {
+		t.Errorf("Truncate = %q", got)
+	}
+}
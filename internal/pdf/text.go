@@ -0,0 +1,143 @@
+package pdf
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Advance widths of the printable ASCII characters (32-126) in thousandths of
+// the font size, from the standard Helvetica AFM metrics.
+var asciiWidths = [...][95]int{
+	Helvetica: {
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	},
+	HelveticaBold: {
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+}
+
+// Windows-1252 assigns printable characters to 0x80-0x9F where Latin-1 has
+// control codes.
+var winAnsiExtras = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91,
+	'’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98,
+	'™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// Widths of the non-ASCII characters likely to show up in pasted text. Other
+// non-ASCII characters use fallbackWidth, which is right for most accented
+// letters and close enough for the rest.
+var winAnsiWidths = map[byte][2]int{
+	0x85: {1000, 1000}, 0x91: {222, 278}, 0x92: {222, 278}, 0x93: {333, 500},
+	0x94: {333, 500}, 0x95: {350, 350}, 0x96: {556, 556}, 0x97: {1000, 1000},
+	0xA0: {278, 278},
+}
+
+const fallbackWidth = 556
+
+// encodeWinAnsi converts s to the Windows-1252 bytes the standard fonts are
+// set up to use. Tabs become spaces, other control characters are dropped,
+// and characters outside the encoding become "?".
+func encodeWinAnsi(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == '\t':
+			out = append(out, ' ')
+		case r < 0x20 || r == 0x7F || (r >= 0x80 && r < 0xA0):
+			// control characters
+		case r <= 0xFF:
+			out = append(out, byte(r))
+		default:
+			if b, ok := winAnsiExtras[r]; ok {
+				out = append(out, b)
+			} else {
+				out = append(out, '?')
+			}
+		}
+	}
+	return out
+}
+
+// TextWidth returns how wide s is when drawn in font at size.
+func TextWidth(font Font, size float64, s string) float64 {
+	total := 0
+	for _, c := range encodeWinAnsi(s) {
+		switch {
+		case c >= 32 && c <= 126:
+			total += asciiWidths[font][c-32]
+		default:
+			if w, ok := winAnsiWidths[c]; ok {
+				total += w[font]
+			} else {
+				total += fallbackWidth
+			}
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// WrapText breaks s into lines no wider than maxWidth, splitting at spaces
+// and keeping explicit line breaks. Words too long for a line on their own
+// are split between characters.
+func WrapText(font Font, size float64, s string, maxWidth float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		line := ""
+		for _, word := range strings.FieldsFunc(paragraph, unicode.IsSpace) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if TextWidth(font, size, candidate) <= maxWidth {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			line = word
+			for TextWidth(font, size, line) > maxWidth {
+				head, tail := splitToWidth(font, size, line, maxWidth)
+				lines = append(lines, head)
+				line = tail
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// splitToWidth splits s after the most characters that fit in maxWidth,
+// always keeping at least one so wrapping makes progress.
+func splitToWidth(font Font, size float64, s string, maxWidth float64) (string, string) {
+	runes := []rune(s)
+	n := 1
+	for n < len(runes) && TextWidth(font, size, string(runes[:n+1])) <= maxWidth {
+		n++
+	}
+	return string(runes[:n]), string(runes[n:])
+}
+
+// Truncate shortens s with a trailing ellipsis so it fits in maxWidth.
+func Truncate(font Font, size float64, s string, maxWidth float64) string {
+	if TextWidth(font, size, s) <= maxWidth {
+		return s
+	}
+	runes := []rune(strings.TrimRightFunc(s, unicode.IsSpace))
+	for len(runes) > 0 && TextWidth(font, size, string(runes)+"…") > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimRightFunc(string(runes), unicode.IsSpace) + "…"
+}
@@ -0,0 +1,281 @@
+// Package qrcode encodes short strings (such as URLs) as QR Code symbols and
+// renders them to PNG. It implements only what the app needs: byte mode,
+// error correction level M, versions 1 through 10 (up to 213 bytes).
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// ErrTooLong is returned when content does not fit in a version 10 symbol.
+var ErrTooLong = errors.New("qrcode: content too long")
+
+const (
+	minVersion = 1
+	maxVersion = 10
+	quietZone  = 4 // modules of light border required around the symbol
+)
+
+// blockLayout describes the error correction block structure of one version
+// at level M.
+type blockLayout struct {
+	ecPerBlock int
+	groups     [][2]int // {number of blocks, data codewords per block}
+}
+
+var levelM = [maxVersion + 1]blockLayout{
+	1:  {10, [][2]int{{1, 16}}},
+	2:  {16, [][2]int{{1, 28}}},
+	3:  {26, [][2]int{{1, 44}}},
+	4:  {18, [][2]int{{2, 32}}},
+	5:  {24, [][2]int{{2, 43}}},
+	6:  {16, [][2]int{{4, 27}}},
+	7:  {18, [][2]int{{4, 31}}},
+	8:  {22, [][2]int{{2, 38}, {2, 39}}},
+	9:  {22, [][2]int{{3, 36}, {2, 37}}},
+	10: {26, [][2]int{{4, 43}, {1, 44}}},
+}
+
+var alignmentPositions = [maxVersion + 1][]int{
+	2:  {6, 18},
+	3:  {6, 22},
+	4:  {6, 26},
+	5:  {6, 30},
+	6:  {6, 34},
+	7:  {6, 22, 38},
+	8:  {6, 24, 42},
+	9:  {6, 26, 46},
+	10: {6, 28, 50},
+}
+
+func (l blockLayout) dataCodewords() int {
+	n := 0
+	for _, g := range l.groups {
+		n += g[0] * g[1]
+	}
+	return n
+}
+
+// Code is an encoded QR Code symbol.
+type Code struct {
+	Version int
+	Size    int // modules per side, excluding the quiet zone
+	modules [][]bool
+}
+
+// Dark reports whether the module at column x, row y is dark.
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y][x]
+}
+
+// Encode returns the smallest symbol that holds content.
+func Encode(content string) (*Code, error) {
+	data := []byte(content)
+	version := 0
+	for v := minVersion; v <= maxVersion; v++ {
+		if 4+charCountBits(v)+8*len(data) <= 8*levelM[v].dataCodewords() {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	codewords := addErrorCorrection(version, encodeData(version, data))
+	s := newSymbol(version)
+	s.drawFunctionPatterns()
+	s.drawCodewords(codewords)
+
+	best, bestPenalty := -1, 0
+	for mask := 0; mask < 8; mask++ {
+		s.applyMask(mask)
+		s.drawFormatBits(mask)
+		if p := s.penalty(); best < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		s.applyMask(mask) // masking is an XOR, so applying it again undoes it
+	}
+	s.applyMask(best)
+	s.drawFormatBits(best)
+
+	return &Code{Version: version, Size: s.size, modules: s.modules}, nil
+}
+
+// Image renders the symbol with a quiet zone, scale pixels per module.
+func (c *Code) Image(scale int) image.Image {
+	if scale < 1 {
+		scale = 1
+	}
+	side := (c.Size + 2*quietZone) * scale
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{color.White, color.Black})
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.modules[y][x] {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex((x+quietZone)*scale+dx, (y+quietZone)*scale+dy, 1)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// PNG renders the symbol as a PNG image, scale pixels per module.
+func (c *Code) PNG(scale int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.Image(scale)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func charCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// encodeData builds the data codewords: byte mode indicator, character
+// count, payload, terminator, then padding up to the version's capacity.
+func encodeData(version int, data []byte) []byte {
+	capacity := levelM[version].dataCodewords()
+	var bb bitBuffer
+	bb.append(0x4, 4)
+	bb.append(uint32(len(data)), charCountBits(version))
+	for _, b := range data {
+		bb.append(uint32(b), 8)
+	}
+	bb.append(0, min(4, capacity*8-len(bb)))
+	bb.append(0, (8-len(bb)%8)%8)
+
+	out := bb.bytes()
+	for pad := byte(0xEC); len(out) < capacity; pad ^= 0xEC ^ 0x11 {
+		out = append(out, pad)
+	}
+	return out
+}
+
+// addErrorCorrection splits data into blocks, appends Reed-Solomon error
+// correction to each, and interleaves the result.
+func addErrorCorrection(version int, data []byte) []byte {
+	layout := levelM[version]
+	divisor := rsDivisor(layout.ecPerBlock)
+
+	var dataBlocks, ecBlocks [][]byte
+	for _, g := range layout.groups {
+		for i := 0; i < g[0]; i++ {
+			block := data[:g[1]]
+			data = data[g[1]:]
+			dataBlocks = append(dataBlocks, block)
+			ecBlocks = append(ecBlocks, rsRemainder(block, divisor))
+		}
+	}
+
+	var out []byte
+	for i := 0; ; i++ {
+		added := false
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				out = append(out, block[i])
+				added = true
+			}
+		}
+		if !added {
+			break
+		}
+	}
+	for i := 0; i < layout.ecPerBlock; i++ {
+		for _, block := range ecBlocks {
+			out = append(out, block[i])
+		}
+	}
+	return out
+}
+
+type bitBuffer []bool
+
+func (b *bitBuffer) append(v uint32, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, v>>uint(i)&1 == 1)
+	}
+}
+
+func (b bitBuffer) bytes() []byte {
+	out := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			out[i/8] |= 0x80 >> uint(i%8)
+		}
+	}
+	return out
+}
+
+// gfMul multiplies two elements of GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1.
+func gfMul(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int(y>>uint(i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+// rsDivisor returns the coefficients (highest degree first, leading 1
+// omitted) of the Reed-Solomon generator polynomial of the given degree.
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMul(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMul(root, 0x02)
+	}
+	return result
+}
+
+// rsRemainder returns the error correction codewords for data.
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, d := range divisor {
+			result[i] ^= gfMul(d, factor)
+		}
+	}
+	return result
+}
+
+// formatBits returns the 15-bit format information for level M and mask.
+func formatBits(mask int) uint32 {
+	data := uint32(mask) // level M's indicator is 00
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// versionBits returns the 18-bit version information (versions 7 and up).
+func versionBits(version int) uint32 {
+	rem := uint32(version)
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	return uint32(version)<<12 | rem
+}
@@ -0,0 +1,191 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestRSRemainder(t *testing.T) {
+	// Worked example from the standard: "HELLO WORLD" at 1-M
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := rsRemainder(data, rsDivisor(10)); !bytes.Equal(got, want) {
+		t.Errorf("rsRemainder = %v, want %v", got, want)
+	}
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	want := []string{
+		"101010000010010", "101000100100101", "101111001111100", "101101101001011",
+		"100010111111001", "100000011001110", "100111110010111", "100101010100000",
+	}
+	for mask, w := range want {
+		if got := fmt.Sprintf("%015b", formatBits(mask)); got != w {
+			t.Errorf("formatBits(%d) = %s, want %s", mask, got, w)
+		}
+	}
+	if got := fmt.Sprintf("%018b", versionBits(7)); got != "000111110010010100" {
+		t.Errorf("versionBits(7) = %s", got)
+	}
+}
+
+func TestEncode_PicksSmallestVersion(t *testing.T) {
+	tests := []struct {
+		length  int
+		version int
+	}{
+		{14, 1}, // 16 data codewords hold 14 bytes plus the 12-bit header
+		{15, 2},
+		{60, 4},
+		{213, 10},
+	}
+	for _, tt := range tests {
+		code, err := Encode(strings.Repeat("a", tt.length))
+		if err != nil {
+			t.Fatalf("Encode(%d bytes): %v", tt.length, err)
+		}
+		if code.Version != tt.version || code.Size != tt.version*4+17 {
+			t.Errorf("Encode(%d bytes) = version %d size %d, want version %d", tt.length, code.Version, code.Size, tt.version)
+		}
+	}
+	if _, err := Encode(strings.Repeat("a", 214)); err != ErrTooLong {
+		t.Errorf("Encode(214 bytes) err = %v, want ErrTooLong", err)
+	}
+}
+
+// TestEncode_RoundTrip reads symbols back the way a scanner would: format
+// information from the grid, unmask, collect codewords, de-interleave, and
+// check both the payload and each block's error correction.
+func TestEncode_RoundTrip(t *testing.T) {
+	for _, content := range []string{
+		"https://volunteers.example.org/share/42.abcdef",
+		strings.Repeat("kennel card ", 12),
+		strings.Repeat("x", 200),
+	} {
+		code, err := Encode(content)
+		if err != nil {
+			t.Fatalf("Encode: %v", err)
+		}
+		got, err := decode(code)
+		if err != nil {
+			t.Fatalf("version %d: %v", code.Version, err)
+		}
+		if got != content {
+			t.Errorf("version %d decoded %q, want %q", code.Version, got, content)
+		}
+	}
+}
+
+func decode(code *Code) (string, error) {
+	// Read the top-left format copy back in the order it was written
+	var format uint32
+	read := func(x, y, i int) {
+		if code.Dark(x, y) {
+			format |= 1 << uint(i)
+		}
+	}
+	for i := 0; i <= 5; i++ {
+		read(8, i, i)
+	}
+	read(8, 7, 6)
+	read(8, 8, 7)
+	read(7, 8, 8)
+	for i := 9; i < 15; i++ {
+		read(14-i, 8, i)
+	}
+	mask := -1
+	for m := 0; m < 8; m++ {
+		if formatBits(m) == format {
+			mask = m
+		}
+	}
+	if mask < 0 {
+		return "", fmt.Errorf("unrecognised format bits %015b", format)
+	}
+
+	// A fresh symbol tells us which modules are function patterns
+	s := newSymbol(code.Version)
+	s.drawFunctionPatterns()
+	var bits bitBuffer
+	s.eachDataModule(func(x, y int) {
+		bits = append(bits, code.Dark(x, y) != maskBit(mask, x, y))
+	})
+	raw := bits.bytes()
+
+	layout := levelM[code.Version]
+	var blocks [][]byte
+	for _, g := range layout.groups {
+		for i := 0; i < g[0]; i++ {
+			blocks = append(blocks, make([]byte, 0, g[1]+layout.ecPerBlock))
+		}
+	}
+	pos := 0
+	longest := layout.groups[len(layout.groups)-1][1]
+	for i := 0; i < longest; i++ {
+		for b := range blocks {
+			if i < cap(blocks[b])-layout.ecPerBlock {
+				blocks[b] = append(blocks[b], raw[pos])
+				pos++
+			}
+		}
+	}
+	var data []byte
+	ecStart := pos
+	for b, block := range blocks {
+		ec := make([]byte, layout.ecPerBlock)
+		for i := range ec {
+			ec[i] = raw[ecStart+i*len(blocks)+b]
+		}
+		if want := rsRemainder(block, rsDivisor(layout.ecPerBlock)); !bytes.Equal(ec, want) {
+			return "", fmt.Errorf("block %d error correction mismatch", b)
+		}
+		data = append(data, block...)
+	}
+
+	if data[0]>>4 != 0x4 {
+		return "", fmt.Errorf("mode %x, want byte mode", data[0]>>4)
+	}
+	var payload bitBuffer
+	for _, b := range data {
+		payload.append(uint32(b), 8)
+	}
+	payload = payload[4:]
+	n := 0
+	for _, bit := range payload[:charCountBits(code.Version)] {
+		n <<= 1
+		if bit {
+			n |= 1
+		}
+	}
+	payload = payload[charCountBits(code.Version):]
+	return string(payload[:n*8].bytes()), nil
+}
+
+func TestPNG(t *testing.T) {
+	code, err := Encode("https://example.org")
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	data, err := code.PNG(4)
+	if err != nil {
+		t.Fatalf("PNG: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("png.Decode: %v", err)
+	}
+	side := (code.Size + 2*quietZone) * 4
+	if b := img.Bounds(); b.Dx() != side || b.Dy() != side {
+		t.Errorf("image is %v, want %dx%d", b, side, side)
+	}
+	// Top-left corner of the finder pattern sits just inside the quiet zone
+	if r, _, _, _ := img.At(quietZone*4, quietZone*4).RGBA(); r != 0 {
+		t.Errorf("finder corner is not dark")
+	}
+	if r, _, _, _ := img.At(0, 0).RGBA(); r == 0 {
+		t.Errorf("quiet zone is not light")
+	}
+}
@@ -0,0 +1,253 @@
+package qrcode
+
+// symbol is the module grid under construction. isFunction marks modules
+// reserved for finder, timing, alignment, format and version patterns,
+// which data placement and masking skip.
+type symbol struct {
+	version    int
+	size       int
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+func newSymbol(version int) *symbol {
+	size := version*4 + 17
+	s := &symbol{version: version, size: size}
+	s.modules = make([][]bool, size)
+	s.isFunction = make([][]bool, size)
+	for i := range s.modules {
+		s.modules[i] = make([]bool, size)
+		s.isFunction[i] = make([]bool, size)
+	}
+	return s
+}
+
+func (s *symbol) setFunction(x, y int, dark bool) {
+	s.modules[y][x] = dark
+	s.isFunction[y][x] = true
+}
+
+func (s *symbol) drawFunctionPatterns() {
+	for i := 0; i < s.size; i++ {
+		s.setFunction(6, i, i%2 == 0)
+		s.setFunction(i, 6, i%2 == 0)
+	}
+
+	s.drawFinder(3, 3)
+	s.drawFinder(s.size-4, 3)
+	s.drawFinder(3, s.size-4)
+
+	pos := alignmentPositions[s.version]
+	last := len(pos) - 1
+	for i := range pos {
+		for j := range pos {
+			// Skip the three positions that overlap finder patterns
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			s.drawAlignment(pos[i], pos[j])
+		}
+	}
+
+	// Reserve the format areas now; drawFormatBits fills them per mask
+	s.drawFormatBits(0)
+
+	if s.version >= 7 {
+		bits := versionBits(s.version)
+		for i := 0; i < 18; i++ {
+			dark := bits>>uint(i)&1 == 1
+			a, b := s.size-11+i%3, i/3
+			s.setFunction(a, b, dark)
+			s.setFunction(b, a, dark)
+		}
+	}
+}
+
+// drawFinder draws a finder pattern and its separator centred on (cx, cy).
+func (s *symbol) drawFinder(cx, cy int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			x, y := cx+dx, cy+dy
+			if x < 0 || x >= s.size || y < 0 || y >= s.size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			s.setFunction(x, y, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (s *symbol) drawAlignment(cx, cy int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			s.setFunction(cx+dx, cy+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+func (s *symbol) drawFormatBits(mask int) {
+	bits := formatBits(mask)
+	bit := func(i int) bool { return bits>>uint(i)&1 == 1 }
+
+	// Copy around the top-left finder
+	for i := 0; i <= 5; i++ {
+		s.setFunction(8, i, bit(i))
+	}
+	s.setFunction(8, 7, bit(6))
+	s.setFunction(8, 8, bit(7))
+	s.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		s.setFunction(14-i, 8, bit(i))
+	}
+
+	// Copy split between the top-right and bottom-left finders
+	for i := 0; i < 8; i++ {
+		s.setFunction(s.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		s.setFunction(8, s.size-15+i, bit(i))
+	}
+	s.setFunction(8, s.size-8, true) // always-dark module
+}
+
+// drawCodewords places codeword bits in the zigzag order defined by the
+// standard: two-module columns from the right, alternating up and down,
+// skipping the vertical timing pattern.
+func (s *symbol) drawCodewords(codewords []byte) {
+	i := 0
+	s.eachDataModule(func(x, y int) {
+		if i < len(codewords)*8 {
+			s.modules[y][x] = codewords[i/8]>>uint(7-i%8)&1 == 1
+			i++
+		}
+	})
+}
+
+func (s *symbol) eachDataModule(fn func(x, y int)) {
+	for right := s.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < s.size; vert++ {
+			y := vert
+			if upward {
+				y = s.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if !s.isFunction[y][x] {
+					fn(x, y)
+				}
+			}
+		}
+	}
+}
+
+func (s *symbol) applyMask(mask int) {
+	for y := 0; y < s.size; y++ {
+		for x := 0; x < s.size; x++ {
+			if !s.isFunction[y][x] && maskBit(mask, x, y) {
+				s.modules[y][x] = !s.modules[y][x]
+			}
+		}
+	}
+}
+
+func maskBit(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// penalty scores the symbol using the four rules from the standard; the
+// encoder keeps the mask with the lowest score.
+func (s *symbol) penalty() int {
+	result := 0
+	dark := 0
+	finderLike := [][]bool{
+		{true, false, true, true, true, false, true, false, false, false, false},
+		{false, false, false, false, true, false, true, true, true, false, true},
+	}
+
+	for i := 0; i < s.size; i++ {
+		row := s.modules[i]
+		col := make([]bool, s.size)
+		for j := range col {
+			col[j] = s.modules[j][i]
+		}
+		for _, line := range [][]bool{row, col} {
+			// Rule 1: runs of five or more same-coloured modules
+			run := 1
+			for j := 1; j <= len(line); j++ {
+				if j < len(line) && line[j] == line[j-1] {
+					run++
+					continue
+				}
+				if run >= 5 {
+					result += 3 + run - 5
+				}
+				run = 1
+			}
+			// Rule 3: patterns resembling a finder
+			for j := 0; j+11 <= len(line); j++ {
+				for _, p := range finderLike {
+					if equalBools(line[j:j+11], p) {
+						result += 40
+					}
+				}
+			}
+		}
+	}
+
+	for y := 0; y < s.size; y++ {
+		for x := 0; x < s.size; x++ {
+			c := s.modules[y][x]
+			if c {
+				dark++
+			}
+			// Rule 2: 2x2 blocks of one colour
+			if x+1 < s.size && y+1 < s.size &&
+				c == s.modules[y][x+1] && c == s.modules[y+1][x] && c == s.modules[y+1][x+1] {
+				result += 3
+			}
+		}
+	}
+
+	// Rule 4: deviation of the dark proportion from 50%, in 5% steps
+	total := s.size * s.size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	result += k * 10
+	return result
+}
+
+func equalBools(a, b []bool) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
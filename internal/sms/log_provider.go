@@ -0,0 +1,42 @@
+package sms
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+)
+
+// LogProvider implements the Provider interface by writing each message to
+// the application log instead of sending it. It is meant for local
+// development only.
+type LogProvider struct{}
+
+// NewLogProvider creates a new log-only provider
+func NewLogProvider() *LogProvider {
+	return &LogProvider{}
+}
+
+// IsConfigured always returns true; the log provider needs no configuration
+func (p *LogProvider) IsConfigured() bool {
+	return true
+}
+
+// GetProviderName returns the provider name for logging
+func (p *LogProvider) GetProviderName() string {
+	return "log"
+}
+
+// Send logs the message instead of sending it
+func (p *LogProvider) Send(ctx context.Context, to, body string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	id := "log-" + uuid.NewString()
+	logging.WithContext(ctx).WithFields(map[string]interface{}{
+		"to":         to,
+		"body":       body,
+		"message_id": id,
+	}).Info("SMS logged instead of sent (SMS_PROVIDER=log)")
+	return id, nil
+}
@@ -0,0 +1,63 @@
+package sms
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+// ErrInvalidPhoneNumber is returned by NormalizePhoneNumber for numbers that
+// can't be turned into E.164
+var ErrInvalidPhoneNumber = errors.New("invalid phone number")
+
+// DefaultCountryCode returns the country calling code assumed for numbers
+// entered without one, from SMS_DEFAULT_COUNTRY_CODE (default "1").
+func DefaultCountryCode() string {
+	if cc := strings.TrimPrefix(strings.TrimSpace(os.Getenv("SMS_DEFAULT_COUNTRY_CODE")), "+"); cc != "" {
+		return cc
+	}
+	return "1"
+}
+
+// NormalizePhoneNumber converts a phone number as a user typed it, e.g.
+// "(555) 123-4567" or "+44 7700 900123", to E.164 ("+15551234567").
+// Numbers without a "+" or "00" international prefix are taken to be in
+// countryCode, dropping a leading trunk "0" (or "1" for country code 1).
+func NormalizePhoneNumber(raw, countryCode string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	international := strings.HasPrefix(raw, "+")
+	var digits strings.Builder
+	for _, r := range strings.TrimPrefix(raw, "+") {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", ErrInvalidPhoneNumber
+		}
+	}
+
+	number := digits.String()
+	switch {
+	case international:
+	case strings.HasPrefix(number, "00"):
+		number = number[2:]
+	case countryCode == "1":
+		// North American numbers are 10 digits, optionally after a trunk 1
+		if len(number) == 11 {
+			number = strings.TrimPrefix(number, "1")
+		}
+		if len(number) != 10 {
+			return "", ErrInvalidPhoneNumber
+		}
+		number = countryCode + number
+	default:
+		number = countryCode + strings.TrimPrefix(number, "0")
+	}
+
+	// E.164 allows at most 15 digits; nothing real is shorter than 8
+	if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+		return "", ErrInvalidPhoneNumber
+	}
+	return "+" + number, nil
+}
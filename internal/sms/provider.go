@@ -0,0 +1,72 @@
+// Package sms sends text messages, used for emergency broadcasts where
+// email is too slow.
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Provider defines the interface that all SMS providers must implement
+type Provider interface {
+	// Send sends body to the E.164 phone number to and returns the
+	// provider's message ID, which delivery status callbacks refer to
+	Send(ctx context.Context, to, body string) (string, error)
+
+	// IsConfigured returns true if the provider is properly configured
+	IsConfigured() bool
+
+	// GetProviderName returns the name of the provider for logging
+	GetProviderName() string
+}
+
+// ProviderType represents the type of SMS provider
+type ProviderType string
+
+const (
+	ProviderTypeTwilio ProviderType = "twilio"
+	ProviderTypeLog    ProviderType = "log" // Development only: logs messages instead of sending them
+)
+
+var (
+	// ErrRecipientRejected is returned when the provider will never deliver
+	// to the number, e.g. it isn't a mobile number or the recipient replied
+	// STOP. Retrying doesn't help.
+	ErrRecipientRejected = errors.New("recipient rejected")
+
+	// ErrInvalidWebhookSignature is returned when a status callback's
+	// signature doesn't verify
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+)
+
+// Delivery statuses reported by status callbacks
+const (
+	StatusSent      = "sent"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+// StatusUpdate is a delivery status reported by the provider for a message
+// returned by Send.
+type StatusUpdate struct {
+	MessageID string
+	Status    string // StatusSent, StatusDelivered, or StatusFailed
+	ErrorCode string // Provider error code for failed messages
+}
+
+// NewProvider creates an SMS provider from the SMS_PROVIDER environment
+// variable. Returns a nil provider if it's unset: SMS is opt-in.
+func NewProvider() (Provider, error) {
+	switch providerType := ProviderType(os.Getenv("SMS_PROVIDER")); providerType {
+	case "":
+		return nil, nil
+	case ProviderTypeTwilio:
+		return NewTwilioProvider(), nil
+	case ProviderTypeLog:
+		return NewLogProvider(), nil
+	default:
+		return nil, fmt.Errorf("unsupported SMS provider: %s", providerType)
+	}
+}
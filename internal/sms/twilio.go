@@ -0,0 +1,195 @@
+package sms
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const defaultTwilioAPIURL = "https://api.twilio.com/2010-04-01"
+
+// twilioRejectedCodes are Twilio errors for recipients that will never
+// receive the message: not a valid mobile number, unsubscribed (replied
+// STOP), or not reachable by SMS.
+var twilioRejectedCodes = map[int]bool{
+	21211: true, // Invalid 'To' phone number
+	21408: true, // Permission to send to this region is not enabled
+	21610: true, // Recipient has unsubscribed
+	21612: true, // 'To' number is not currently reachable
+	21614: true, // 'To' number is not a valid mobile number
+}
+
+// TwilioProvider implements the Provider interface using the Twilio
+// Programmable Messaging API
+type TwilioProvider struct {
+	AccountSID string
+	AuthToken  string
+	FromNumber string
+	// StatusCallbackURL is where Twilio posts delivery status updates;
+	// without it messages stay "sent"
+	StatusCallbackURL string
+	client            *http.Client
+	apiURL            string // Configurable API URL for testing
+}
+
+// NewTwilioProvider creates a new Twilio provider from environment variables
+func NewTwilioProvider() *TwilioProvider {
+	apiURL := os.Getenv("TWILIO_API_URL")
+	if apiURL == "" {
+		apiURL = defaultTwilioAPIURL
+	}
+	return &TwilioProvider{
+		AccountSID:        os.Getenv("TWILIO_ACCOUNT_SID"),
+		AuthToken:         os.Getenv("TWILIO_AUTH_TOKEN"),
+		FromNumber:        os.Getenv("TWILIO_FROM_NUMBER"),
+		StatusCallbackURL: os.Getenv("TWILIO_STATUS_CALLBACK_URL"),
+		apiURL:            strings.TrimSuffix(apiURL, "/"),
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// IsConfigured checks if the Twilio provider is properly configured
+func (p *TwilioProvider) IsConfigured() bool {
+	return p.AccountSID != "" && p.AuthToken != "" && p.FromNumber != ""
+}
+
+// GetProviderName returns the provider name for logging
+func (p *TwilioProvider) GetProviderName() string {
+	return "twilio"
+}
+
+// twilioMessageResponse is the part of a Twilio Message resource, or error
+// response, that Send reads
+type twilioMessageResponse struct {
+	SID     string `json:"sid"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Send sends an SMS using the Twilio Messages API
+func (p *TwilioProvider) Send(ctx context.Context, to, body string) (string, error) {
+	if !p.IsConfigured() {
+		return "", fmt.Errorf("Twilio provider is not configured")
+	}
+
+	form := url.Values{}
+	form.Set("To", to)
+	form.Set("From", p.FromNumber)
+	form.Set("Body", body)
+	if p.StatusCallbackURL != "" {
+		form.Set("StatusCallback", p.StatusCallbackURL)
+	}
+
+	endpoint := fmt.Sprintf("%s/Accounts/%s/Messages.json", p.apiURL, url.PathEscape(p.AccountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(p.AccountSID, p.AuthToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	var message twilioMessageResponse
+	_ = json.Unmarshal(respBody, &message)
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		if twilioRejectedCodes[message.Code] {
+			return "", fmt.Errorf("%w: Twilio error %d: %s", ErrRecipientRejected, message.Code, message.Message)
+		}
+		if message.Message != "" {
+			return "", fmt.Errorf("Twilio API error %d: %s", message.Code, message.Message)
+		}
+		return "", fmt.Errorf("Twilio API error: status %d", resp.StatusCode)
+	}
+	if message.SID == "" {
+		return "", fmt.Errorf("Twilio API returned no message SID")
+	}
+	return message.SID, nil
+}
+
+// TwilioStatusWebhook verifies and reads the delivery status callbacks
+// Twilio posts to TWILIO_STATUS_CALLBACK_URL. Callbacks are signed with the
+// account's auth token over the exact callback URL, so CallbackURL must
+// match what Twilio was given, including scheme and any query string.
+type TwilioStatusWebhook struct {
+	AuthToken   string
+	CallbackURL string
+}
+
+// NewTwilioStatusWebhook creates a Twilio status webhook from the
+// TWILIO_AUTH_TOKEN and TWILIO_STATUS_CALLBACK_URL environment variables.
+// Returns nil if either is unset.
+func NewTwilioStatusWebhook() *TwilioStatusWebhook {
+	token := os.Getenv("TWILIO_AUTH_TOKEN")
+	callbackURL := os.Getenv("TWILIO_STATUS_CALLBACK_URL")
+	if token == "" || callbackURL == "" {
+		return nil
+	}
+	return &TwilioStatusWebhook{AuthToken: token, CallbackURL: callbackURL}
+}
+
+// TwilioSignature computes the X-Twilio-Signature for a callback: the
+// base64 HMAC-SHA1, keyed with the auth token, of the URL followed by each
+// form parameter's name and value in name order.
+func TwilioSignature(authToken, callbackURL string, form url.Values) string {
+	names := make([]string, 0, len(form))
+	for name := range form {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(callbackURL))
+	for _, name := range names {
+		for _, value := range form[name] {
+			mac.Write([]byte(name + value))
+		}
+	}
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Parse verifies a status callback's signature and returns the status it
+// reports. Twilio's intermediate statuses (queued, sending, accepted) are
+// reported as StatusSent; undelivered and canceled as StatusFailed.
+func (w *TwilioStatusWebhook) Parse(form url.Values, signature string) (StatusUpdate, error) {
+	expected := TwilioSignature(w.AuthToken, w.CallbackURL, form)
+	if signature == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(signature)) != 1 {
+		return StatusUpdate{}, ErrInvalidWebhookSignature
+	}
+
+	update := StatusUpdate{MessageID: form.Get("MessageSid"), ErrorCode: form.Get("ErrorCode")}
+	if update.MessageID == "" {
+		return StatusUpdate{}, fmt.Errorf("status callback has no MessageSid")
+	}
+	switch form.Get("MessageStatus") {
+	case "delivered", "read":
+		update.Status = StatusDelivered
+	case "failed", "undelivered", "canceled":
+		update.Status = StatusFailed
+	default:
+		update.Status = StatusSent
+	}
+	return update, nil
+}
@@ -0,0 +1,141 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestTwilioProvider_Send(t *testing.T) {
+	var got url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Accounts/AC123/Messages.json" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "AC123" || pass != "secret" {
+			t.Errorf("unexpected basic auth %q %q", user, pass)
+		}
+		_ = r.ParseForm()
+		got = r.PostForm
+		switch r.PostForm.Get("To") {
+		case "+15550000000":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code": 21610, "message": "Attempt to send to unsubscribed recipient"}`))
+		case "+15550000001":
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"code": 20429, "message": "Too Many Requests"}`))
+		default:
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"sid": "SM42", "status": "queued"}`))
+		}
+	}))
+	defer server.Close()
+
+	provider := &TwilioProvider{
+		AccountSID:        "AC123",
+		AuthToken:         "secret",
+		FromNumber:        "+15559990000",
+		StatusCallbackURL: "https://example.org/api/webhooks/sms/twilio",
+		client:            server.Client(),
+		apiURL:            server.URL,
+	}
+
+	id, err := provider.Send(context.Background(), "+15551234567", "Buddy got out near the east gate")
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if id != "SM42" {
+		t.Errorf("message ID = %q, want SM42", id)
+	}
+	if got.Get("From") != "+15559990000" || got.Get("Body") != "Buddy got out near the east gate" ||
+		got.Get("StatusCallback") != "https://example.org/api/webhooks/sms/twilio" {
+		t.Errorf("unexpected form %v", got)
+	}
+
+	if _, err := provider.Send(context.Background(), "+15550000000", "hi"); !errors.Is(err, ErrRecipientRejected) {
+		t.Errorf("unsubscribed recipient: got %v, want ErrRecipientRejected", err)
+	}
+	if _, err := provider.Send(context.Background(), "+15550000001", "hi"); err == nil || errors.Is(err, ErrRecipientRejected) {
+		t.Errorf("rate limited: got %v, want a retryable error", err)
+	}
+
+	if _, err := (&TwilioProvider{}).Send(context.Background(), "+15551234567", "hi"); err == nil {
+		t.Error("expected an error from an unconfigured provider")
+	}
+}
+
+func TestTwilioStatusWebhook_Parse(t *testing.T) {
+	// Example from Twilio's webhook security documentation
+	form := url.Values{
+		"CallSid": {"CA1234567890ABCDE"},
+		"Caller":  {"+12349013030"},
+		"Digits":  {"1234"},
+		"From":    {"+12349013030"},
+		"To":      {"+18005551212"},
+	}
+	callbackURL := "https://mycompany.com/myapp.php?foo=1&bar=2"
+	if got := TwilioSignature("12345", callbackURL, form); got != "0/KCTR6DLpKmkAf8muzZqo1nDgQ=" {
+		t.Errorf("TwilioSignature = %q", got)
+	}
+
+	webhook := &TwilioStatusWebhook{AuthToken: "secret", CallbackURL: "https://example.org/api/webhooks/sms/twilio"}
+	tests := []struct {
+		status, want string
+	}{
+		{"queued", StatusSent},
+		{"sent", StatusSent},
+		{"delivered", StatusDelivered},
+		{"undelivered", StatusFailed},
+		{"failed", StatusFailed},
+	}
+	for _, tt := range tests {
+		form := url.Values{"MessageSid": {"SM42"}, "MessageStatus": {tt.status}, "ErrorCode": {"30003"}}
+		update, err := webhook.Parse(form, TwilioSignature("secret", webhook.CallbackURL, form))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.status, err)
+		}
+		if update.MessageID != "SM42" || update.Status != tt.want {
+			t.Errorf("%s: got %+v, want status %s", tt.status, update, tt.want)
+		}
+	}
+
+	form = url.Values{"MessageSid": {"SM42"}, "MessageStatus": {"delivered"}}
+	if _, err := webhook.Parse(form, TwilioSignature("wrong", webhook.CallbackURL, form)); !errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Errorf("wrong key: got %v, want ErrInvalidWebhookSignature", err)
+	}
+	if _, err := webhook.Parse(form, ""); !errors.Is(err, ErrInvalidWebhookSignature) {
+		t.Errorf("missing signature: got %v, want ErrInvalidWebhookSignature", err)
+	}
+}
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	tests := []struct {
+		raw, countryCode, want string
+	}{
+		{"(555) 123-4567", "1", "+15551234567"},
+		{"1-555-123-4567", "1", "+15551234567"},
+		{"+1 555.123.4567", "1", "+15551234567"},
+		{"07700 900123", "44", "+447700900123"},
+		{"0044 7700 900123", "1", "+447700900123"},
+		{"+44 7700 900123", "1", "+447700900123"},
+		{"123-4567", "1", ""},
+		{"555-CALL-NOW", "1", ""},
+		{"", "1", ""},
+		{"+1234567890123456", "1", ""},
+	}
+	for _, tt := range tests {
+		got, err := NormalizePhoneNumber(tt.raw, tt.countryCode)
+		if tt.want == "" {
+			if !errors.Is(err, ErrInvalidPhoneNumber) {
+				t.Errorf("%q: got %q, %v; want ErrInvalidPhoneNumber", tt.raw, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%q: got %q, %v; want %q", tt.raw, got, err, tt.want)
+		}
+	}
+}
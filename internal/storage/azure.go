@@ -133,6 +133,24 @@ func (a *AzureBlobProvider) ensureContainer(ctx context.Context) error {
 	return nil
 }
 
+// healthProbeBlob is overwritten by every CheckWritable call, so probing
+// never accumulates blobs in the container.
+const healthProbeBlob = "health/readiness-probe"
+
+// CheckWritable uploads a tiny fixed-name blob to confirm the container
+// accepts writes with the configured credentials. It writes on every call,
+// so callers cache the result rather than probing per request.
+func (a *AzureBlobProvider) CheckWritable(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "storage.azure.check_writable")
+	defer span.End()
+
+	blockBlobClient := a.client.ServiceClient().NewContainerClient(a.containerName).NewBlockBlobClient(healthProbeBlob)
+	if _, err := blockBlobClient.UploadBuffer(ctx, []byte("ok"), nil); err != nil {
+		return telemetry.Fail(span, fmt.Errorf("azure container is not writable: %w", err), "health probe upload failed")
+	}
+	return nil
+}
+
 // UploadImage uploads an image to Azure Blob Storage
 func (a *AzureBlobProvider) UploadImage(ctx context.Context, data []byte, mimeType string, metadata map[string]string) (url, identifier, extension string, err error) {
 	ctx, span := tracer.Start(ctx, "storage.azure.upload_image", trace.WithAttributes(
@@ -26,6 +26,17 @@ func (p *PostgresProvider) Name() string {
 	return "postgres"
 }
 
+// CheckWritable verifies the database accepts writes to the image table.
+// The UPDATE matches no rows, but Postgres still rejects it up front on a
+// read-only connection (e.g. a failed-over replica), which is the failure
+// this is meant to catch.
+func (p *PostgresProvider) CheckWritable(ctx context.Context) error {
+	if err := p.db.WithContext(ctx).Exec("UPDATE animal_images SET updated_at = updated_at WHERE 1 = 0").Error; err != nil {
+		return fmt.Errorf("image storage is not writable: %w", err)
+	}
+	return nil
+}
+
 // UploadImage generates a UUID and URL for image storage
 // For Postgres, the actual database insertion is handled by the caller
 func (p *PostgresProvider) UploadImage(ctx context.Context, data []byte, mimeType string, metadata map[string]string) (url, identifier, extension string, err error) {
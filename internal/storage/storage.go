@@ -63,6 +63,14 @@ type Provider interface {
 	GetDocumentURL(identifier string) string
 }
 
+// HealthChecker is implemented by providers that can verify, cheaply, that
+// uploads would currently succeed. Used by the readiness endpoint, which
+// caches the result for a minute; providers that don't implement it are
+// reported without a writability check.
+type HealthChecker interface {
+	CheckWritable(ctx context.Context) error
+}
+
 // Config holds storage provider configuration
 type Config struct {
 	// Provider specifies which storage backend to use ("postgres" or "azure")
@@ -0,0 +1,155 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+)
+
+// heifBrands are the ftyp brands of HEIF still images. iPhones write heic;
+// mif1 and msf1 are the generic brands other cameras use.
+var heifBrands = map[string]bool{
+	"heic": true, "heix": true, "heim": true, "heis": true,
+	"hevc": true, "hevx": true, "mif1": true, "msf1": true,
+}
+
+// defaultHEIFConverter is libheif's command line converter. Debian ships it
+// in libheif-examples.
+const defaultHEIFConverter = "heif-convert"
+
+// heifConvertTimeout bounds one conversion, so a malformed file can't hold
+// an upload request open.
+const heifConvertTimeout = 30 * time.Second
+
+// HEIFDecoder decodes a HEIC/HEIF file. It should return an error wrapping
+// ErrUnsupportedCodec when the file uses a codec it can't decode.
+type HEIFDecoder func(data []byte) (image.Image, error)
+
+// heifDecoder decodes HEIF uploads; nil means they are rejected with
+// ErrUnsupportedCodec. The standard library has no HEVC decoder.
+var heifDecoder HEIFDecoder
+
+// RegisterHEIFDecoder makes HEIC/HEIF uploads decodable, so they are
+// converted like any other image. Call it at startup, before any upload is
+// processed.
+func RegisterHEIFDecoder(decoder HEIFDecoder) {
+	heifDecoder = decoder
+}
+
+// IsHEIF reports whether data starts with the ftyp box of a HEIF image,
+// such as an iPhone's HEIC photo.
+func IsHEIF(data []byte) bool {
+	if len(data) < 16 || string(data[4:8]) != "ftyp" {
+		return false
+	}
+	size := int(binary.BigEndian.Uint32(data))
+	if size < 16 || size > len(data) {
+		return false
+	}
+	if heifBrands[string(data[8:12])] {
+		return true
+	}
+	// Compatible brands follow the major brand and its minor version
+	for pos := 16; pos+4 <= size; pos += 4 {
+		if heifBrands[string(data[pos:pos+4])] {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeHEIF decodes a HEIF upload with the registered decoder. Returns an
+// error wrapping ErrInvalidFile, and ErrUnsupportedCodec when there is no
+// decoder or it can't handle the file's codec.
+func decodeHEIF(data []byte) (image.Image, error) {
+	if heifDecoder == nil {
+		return nil, fmt.Errorf("%w: %w: HEIC/HEIF images can't be converted on this server", ErrInvalidFile, ErrUnsupportedCodec)
+	}
+	img, err := heifDecoder(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+	return img, nil
+}
+
+// HEIFConverterCommand returns a HEIFDecoder that runs a libheif-style
+// converter, invoked as `command input.heic output.png`. The converter
+// applies the image's rotation and mirroring, so the result is upright.
+func HEIFConverterCommand(command string) HEIFDecoder {
+	return func(data []byte) (image.Image, error) {
+		dir, err := os.MkdirTemp("", "heif-convert-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create conversion directory: %w", err)
+		}
+		defer os.RemoveAll(dir)
+
+		input := filepath.Join(dir, "input.heic")
+		output := filepath.Join(dir, "output.png")
+		if err := os.WriteFile(input, data, 0o600); err != nil {
+			return nil, fmt.Errorf("failed to write HEIF input: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), heifConvertTimeout)
+		defer cancel()
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, command, input, output)
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			msg := strings.TrimSpace(stderr.String())
+			if strings.Contains(strings.ToLower(msg), "unsupported") {
+				return nil, fmt.Errorf("%w: %s", ErrUnsupportedCodec, msg)
+			}
+			return nil, fmt.Errorf("%s failed: %v: %s", filepath.Base(command), err, msg)
+		}
+
+		// Files with several images are written as output-1.png,
+		// output-2.png, and so on; the first is the primary image
+		if _, err := os.Stat(output); err != nil {
+			numbered, _ := filepath.Glob(filepath.Join(dir, "output-*.png"))
+			if len(numbered) == 0 {
+				return nil, errors.New(filepath.Base(command) + " wrote no image")
+			}
+			sort.Strings(numbered)
+			output = numbered[0]
+		}
+		f, err := os.Open(output)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open converted image: %w", err)
+		}
+		defer f.Close()
+		return png.Decode(f)
+	}
+}
+
+// ConfigureHEIFFromEnv registers a HEIFConverterCommand for HEIF_CONVERTER
+// (default heif-convert). Setting it to "none", or the converter not being
+// installed, leaves HEIC/HEIF uploads unsupported.
+func ConfigureHEIFFromEnv() {
+	command := os.Getenv("HEIF_CONVERTER")
+	if command == "" {
+		command = defaultHEIFConverter
+	}
+	if command == "none" {
+		logging.Info("HEIC/HEIF conversion disabled by HEIF_CONVERTER")
+		return
+	}
+	path, err := exec.LookPath(command)
+	if err != nil {
+		logging.WithField("command", command).Warn("HEIF converter not found; HEIC/HEIF photo uploads will be rejected")
+		return
+	}
+	RegisterHEIFDecoder(HEIFConverterCommand(path))
+	logging.WithField("command", path).Info("HEIC/HEIF uploads will be converted")
+}
@@ -0,0 +1,160 @@
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	return data
+}
+
+// useHEIFDecoder registers decoder for the rest of the test
+func useHEIFDecoder(t *testing.T, decoder HEIFDecoder) {
+	t.Helper()
+	RegisterHEIFDecoder(decoder)
+	t.Cleanup(func() { RegisterHEIFDecoder(nil) })
+}
+
+func TestIsHEIF(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"iPhone photo", readFixture(t, "photo.heic"), true},
+		{"generic HEIF brand", append([]byte("\x00\x00\x00\x18ftypmsf1\x00\x00\x00\x00mif1msf1"), make([]byte, 8)...), true},
+		{"compatible brand only", []byte("\x00\x00\x00\x14ftypXYZ \x00\x00\x00\x00heix"), true},
+		{"MP4 video", []byte("\x00\x00\x00\x18ftypisom\x00\x00\x02\x00isomiso2mp41"), false},
+		{"PNG", readFixture(t, "photo-decoded.png"), false},
+		{"truncated ftyp", []byte("\x00\x00\x00\x40ftypheic\x00\x00\x00\x00"), false},
+		{"empty", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsHEIF(tt.data); got != tt.want {
+				t.Errorf("IsHEIF() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessImageHEIF(t *testing.T) {
+	heic := readFixture(t, "photo.heic")
+	cfg := resolveImageConfig(nil)
+
+	t.Run("without a decoder the codec is reported", func(t *testing.T) {
+		_, err := ProcessImage(bytes.NewReader(heic), 100, cfg)
+		if !errors.Is(err, ErrUnsupportedCodec) || !errors.Is(err, ErrInvalidFile) {
+			t.Fatalf("err = %v, want ErrUnsupportedCodec and ErrInvalidFile", err)
+		}
+
+		// Callers that keep undecodable uploads still store it as uploaded
+		data, mimeType, err := ProcessImageOrOriginal(heic, "image/heic", 100, cfg)
+		if err != nil || mimeType != "image/heic" || len(data) != len(heic) {
+			t.Errorf("got %d bytes of %s (err %v), want the upload unchanged", len(data), mimeType, err)
+		}
+	})
+
+	t.Run("a registered decoder converts to JPEG", func(t *testing.T) {
+		decoded := readFixture(t, "photo-decoded.png")
+		var received []byte
+		useHEIFDecoder(t, func(data []byte) (image.Image, error) {
+			received = data
+			return png.Decode(bytes.NewReader(decoded))
+		})
+
+		processed, err := ProcessImage(bytes.NewReader(heic), 100, cfg)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !bytes.Equal(received, heic) {
+			t.Error("decoder should receive the whole upload")
+		}
+		if processed.MimeType != "image/jpeg" || processed.SourceFormat != "heif" {
+			t.Errorf("got %s from %s, want image/jpeg from heif", processed.MimeType, processed.SourceFormat)
+		}
+		if processed.Width != 8 || processed.Height != 6 {
+			t.Errorf("size = %dx%d, want 8x6", processed.Width, processed.Height)
+		}
+		if _, err := png.Decode(bytes.NewReader(processed.Data)); err == nil {
+			t.Error("output should be re-encoded, not the decoder's PNG")
+		}
+	})
+
+	t.Run("decoder errors keep their codec", func(t *testing.T) {
+		useHEIFDecoder(t, func([]byte) (image.Image, error) {
+			return nil, errors.Join(ErrUnsupportedCodec, errors.New("no AV1 plugin"))
+		})
+		_, err := ProcessImage(bytes.NewReader(heic), 100, cfg)
+		if !errors.Is(err, ErrUnsupportedCodec) || !errors.Is(err, ErrInvalidFile) {
+			t.Errorf("err = %v, want ErrUnsupportedCodec and ErrInvalidFile", err)
+		}
+	})
+}
+
+func TestHEIFConverterCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake converters are shell scripts")
+	}
+	heic := readFixture(t, "photo.heic")
+	decoded, err := filepath.Abs(filepath.Join("testdata", "photo-decoded.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// converter writes a fake heif-convert that runs script with the input
+	// as $1 and the output as $2
+	converter := func(script string) string {
+		path := filepath.Join(t.TempDir(), "heif-convert")
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Run("converts to the output file", func(t *testing.T) {
+		img, err := HEIFConverterCommand(converter(`[ -s "$1" ] || exit 1; cp ` + decoded + ` "$2"`))(heic)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if b := img.Bounds(); b.Dx() != 8 || b.Dy() != 6 {
+			t.Errorf("size = %dx%d, want 8x6", b.Dx(), b.Dy())
+		}
+	})
+
+	t.Run("uses the first of several images", func(t *testing.T) {
+		script := `base="${2%.png}"; cp ` + decoded + ` "$base-1.png"; printf junk > "$base-2.png"`
+		if _, err := HEIFConverterCommand(converter(script))(heic); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("unsupported codecs are reported", func(t *testing.T) {
+		_, err := HEIFConverterCommand(converter(`echo "Unsupported feature: Unsupported codec" >&2; exit 1`))(heic)
+		if !errors.Is(err, ErrUnsupportedCodec) {
+			t.Errorf("err = %v, want ErrUnsupportedCodec", err)
+		}
+	})
+
+	t.Run("other failures are plain errors", func(t *testing.T) {
+		_, err := HEIFConverterCommand(converter(`echo "Invalid input: No 'ftyp' box" >&2; exit 1`))(heic)
+		if err == nil || errors.Is(err, ErrUnsupportedCodec) {
+			t.Errorf("err = %v, want a conversion failure", err)
+		}
+		if _, err := HEIFConverterCommand(converter(`exit 0`))(heic); err == nil {
+			t.Error("a converter that writes nothing should fail")
+		}
+	})
+}
@@ -0,0 +1,222 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/logging"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"gorm.io/gorm"
+)
+
+// Site setting keys for image uploads. Each can be overridden by the
+// environment variable named in imageSettingEnv.
+const (
+	SettingImageMaxUploadMB          = "image_max_upload_mb"
+	SettingImageMaxHeroUploadMB      = "image_max_hero_upload_mb"
+	SettingImageMaxDimension         = "image_max_dimension"
+	SettingImageMaxHeroDimension     = "image_max_hero_dimension"
+	SettingImageJPEGQuality          = "image_jpeg_quality"
+	SettingImagePreserveTransparency = "image_preserve_transparency"
+	SettingImageOutputFormat         = "image_output_format"
+)
+
+// Where an effective image setting came from.
+const (
+	ImageSourceEnv     = "env"
+	ImageSourceSetting = "setting"
+	ImageSourceDefault = "default"
+)
+
+var imageSettingEnv = map[string]string{
+	SettingImageMaxUploadMB:          "IMAGE_MAX_UPLOAD_MB",
+	SettingImageMaxHeroUploadMB:      "IMAGE_MAX_HERO_UPLOAD_MB",
+	SettingImageMaxDimension:         "IMAGE_MAX_DIMENSION",
+	SettingImageMaxHeroDimension:     "IMAGE_MAX_HERO_DIMENSION",
+	SettingImageJPEGQuality:          "IMAGE_JPEG_QUALITY",
+	SettingImagePreserveTransparency: "IMAGE_PRESERVE_TRANSPARENCY",
+	SettingImageOutputFormat:         "IMAGE_OUTPUT_FORMAT",
+}
+
+const (
+	defaultImageMaxDimension     = 1200
+	defaultImageMaxHeroDimension = 2560
+	defaultImageJPEGQuality      = 85
+
+	// maxImageUploadMB caps the upload size settings.
+	maxImageUploadMB = 50
+
+	// MaxImageRequestBodySize is the request body limit for image upload
+	// routes: the largest configurable upload plus room for the multipart
+	// envelope.
+	MaxImageRequestBodySize = (maxImageUploadMB + 1) * 1024 * 1024
+
+	// imageConfigTTL bounds how long a replica serves stale settings after
+	// another replica's admin changes them.
+	imageConfigTTL = 30 * time.Second
+)
+
+// ImageConfig is the effective image upload and processing configuration.
+type ImageConfig struct {
+	MaxUploadBytes       int64             `json:"max_upload_bytes"`
+	MaxHeroUploadBytes   int64             `json:"max_hero_upload_bytes"`
+	MaxDimension         int               `json:"max_dimension"`
+	MaxHeroDimension     int               `json:"max_hero_dimension"`
+	JPEGQuality          int               `json:"jpeg_quality"`
+	PreserveTransparency bool              `json:"preserve_transparency"`
+	OutputFormat         string            `json:"output_format"`
+	Sources              map[string]string `json:"sources"` // setting key -> env, setting, or default
+}
+
+// ImageConfigStore resolves ImageConfig from environment variables and site
+// settings, caching the result for imageConfigTTL. A nil store resolves from
+// environment variables and defaults only.
+type ImageConfigStore struct {
+	db       *gorm.DB
+	mu       sync.Mutex
+	cfg      ImageConfig
+	loadedAt time.Time
+}
+
+// NewImageConfigStore returns a store that reads site settings from db.
+func NewImageConfigStore(db *gorm.DB) *ImageConfigStore {
+	return &ImageConfigStore{db: db}
+}
+
+// Get returns the effective configuration, reloading it from site settings
+// when the cached copy has expired. If settings can't be read, the last
+// loaded configuration (or env and defaults) is used.
+func (s *ImageConfigStore) Get(ctx context.Context) ImageConfig {
+	if s == nil || s.db == nil {
+		return resolveImageConfig(nil)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.loadedAt.IsZero() && time.Since(s.loadedAt) < imageConfigTTL {
+		return s.cfg
+	}
+
+	keys := make([]string, 0, len(imageSettingEnv))
+	for key := range imageSettingEnv {
+		keys = append(keys, key)
+	}
+	var settings []models.SiteSetting
+	if err := s.db.WithContext(ctx).Where("key IN ?", keys).Find(&settings).Error; err != nil {
+		logging.Error("Failed to load image settings", err)
+		if s.loadedAt.IsZero() {
+			return resolveImageConfig(nil)
+		}
+		return s.cfg
+	}
+	values := make(map[string]string, len(settings))
+	for _, setting := range settings {
+		values[setting.Key] = setting.Value
+	}
+	s.cfg = resolveImageConfig(values)
+	s.loadedAt = time.Now()
+	return s.cfg
+}
+
+// Invalidate forces the next Get to reload site settings.
+func (s *ImageConfigStore) Invalidate() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+// resolveImageConfig merges environment variables, site setting values, and
+// defaults, in that order of precedence. Invalid values fall back to the
+// next source.
+func resolveImageConfig(settings map[string]string) ImageConfig {
+	cfg := ImageConfig{Sources: make(map[string]string, len(imageSettingEnv))}
+	lookup := func(key string) (string, string) {
+		if v := strings.TrimSpace(os.Getenv(imageSettingEnv[key])); v != "" {
+			if err := ValidateImageSetting(key, v); err == nil {
+				return v, ImageSourceEnv
+			}
+			logging.WithField("value", v).Warn("Invalid " + imageSettingEnv[key] + ", ignoring")
+		}
+		if v := strings.TrimSpace(settings[key]); v != "" && ValidateImageSetting(key, v) == nil {
+			return v, ImageSourceSetting
+		}
+		return "", ImageSourceDefault
+	}
+	intValue := func(key string, def int) int {
+		v, source := lookup(key)
+		cfg.Sources[key] = source
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+		return def
+	}
+
+	cfg.MaxUploadBytes = int64(intValue(SettingImageMaxUploadMB, MaxImageSize/(1024*1024))) * 1024 * 1024
+	cfg.MaxHeroUploadBytes = int64(intValue(SettingImageMaxHeroUploadMB, MaxHeroImageSize/(1024*1024))) * 1024 * 1024
+	cfg.MaxDimension = intValue(SettingImageMaxDimension, defaultImageMaxDimension)
+	cfg.MaxHeroDimension = intValue(SettingImageMaxHeroDimension, defaultImageMaxHeroDimension)
+	cfg.JPEGQuality = intValue(SettingImageJPEGQuality, defaultImageJPEGQuality)
+
+	preserve, source := lookup(SettingImagePreserveTransparency)
+	cfg.PreserveTransparency = preserve == "true"
+	cfg.Sources[SettingImagePreserveTransparency] = source
+
+	format, source := lookup(SettingImageOutputFormat)
+	if source == ImageSourceDefault {
+		format = FormatJPEG
+	}
+	cfg.OutputFormat = strings.ToLower(format)
+	cfg.Sources[SettingImageOutputFormat] = source
+
+	return cfg
+}
+
+// IsImageSetting reports whether key is one of the image setting keys.
+func IsImageSetting(key string) bool {
+	_, ok := imageSettingEnv[key]
+	return ok
+}
+
+// ValidateImageSetting checks a site setting value for an image key. An
+// empty value is always valid and means "use the default".
+func ValidateImageSetting(key, value string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	intBetween := func(min, max int, unit string) error {
+		if n, err := strconv.Atoi(value); err != nil || n < min || n > max {
+			return fmt.Errorf("%s must be %s between %d and %d", key, unit, min, max)
+		}
+		return nil
+	}
+
+	switch key {
+	case SettingImageMaxUploadMB, SettingImageMaxHeroUploadMB:
+		return intBetween(1, maxImageUploadMB, "a size in MB")
+	case SettingImageMaxDimension, SettingImageMaxHeroDimension:
+		return intBetween(100, 10000, "a pixel size")
+	case SettingImageJPEGQuality:
+		return intBetween(1, 100, "a quality")
+	case SettingImagePreserveTransparency:
+		if value != "true" && value != "false" {
+			return fmt.Errorf("%s must be true or false", key)
+		}
+	case SettingImageOutputFormat:
+		if _, ok := imageEncoders[strings.ToLower(value)]; !ok {
+			return fmt.Errorf("%s must be one of: %s", key, strings.Join(OutputFormats(), ", "))
+		}
+	default:
+		return errors.New("not an image setting")
+	}
+	return nil
+}
@@ -0,0 +1,440 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+)
+
+// EXIF orientation values (TIFF tag 0x0112) other than 1, the default.
+const (
+	orientationFlipH      = 2
+	orientationRotate180  = 3
+	orientationFlipV      = 4
+	orientationTranspose  = 5
+	orientationRotate90   = 6 // Rotate 90° clockwise to display
+	orientationTransverse = 7
+	orientationRotate270  = 8 // Rotate 90° counter-clockwise to display
+
+	exifOrientationTag = 0x0112
+)
+
+// JPEG markers that matter when reading or stripping metadata.
+const (
+	jpegMarkerSOS   byte = 0xDA
+	jpegMarkerAPP1  byte = 0xE1
+	jpegMarkerAPP13 byte = 0xED
+	jpegMarkerCOM   byte = 0xFE
+)
+
+// StripMetadata removes EXIF, XMP, and text metadata, such as the GPS
+// position of the camera, from an image stored as uploaded. Re-encoded images
+// carry no metadata, so this is for uploads the server can't decode. JPEG,
+// PNG, WebP, and HEIC/HEIF are supported; other data, and data too malformed
+// to parse, is returned unchanged.
+func StripMetadata(data []byte, mimeType string) []byte {
+	var stripped []byte
+	switch mimeType {
+	case "image/jpeg", "image/jpg":
+		stripped = stripJPEGMetadata(data)
+	case "image/png":
+		stripped = stripPNGMetadata(data)
+	case "image/webp":
+		stripped = stripWebPMetadata(data)
+	case "image/heic", "image/heif":
+		stripped = stripHEIFMetadata(data)
+	}
+	if stripped == nil {
+		return data
+	}
+	return stripped
+}
+
+// jpegSegments calls fn with the marker and full bytes (marker included) of
+// each JPEG segment before the image data, then returns the offset of the
+// start-of-scan marker. It returns -1 if data isn't a well-formed JPEG.
+func jpegSegments(data []byte, fn func(marker byte, segment []byte)) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return -1
+	}
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return -1
+		}
+		marker := data[pos+1]
+		if marker == 0xFF { // Fill byte
+			pos++
+			continue
+		}
+		if marker == jpegMarkerSOS {
+			return pos
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			return -1
+		}
+		fn(marker, data[pos:pos+2+length])
+		pos += 2 + length
+	}
+	return -1
+}
+
+// stripJPEGMetadata drops APP1 (EXIF and XMP), APP13 (IPTC), and comment
+// segments. Other segments, including ICC color profiles, are kept.
+func stripJPEGMetadata(data []byte) []byte {
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:min(2, len(data))])
+	sos := jpegSegments(data, func(marker byte, segment []byte) {
+		if marker != jpegMarkerAPP1 && marker != jpegMarkerAPP13 && marker != jpegMarkerCOM {
+			out.Write(segment)
+		}
+	})
+	if sos < 0 {
+		return nil
+	}
+	out.Write(data[sos:])
+	return out.Bytes()
+}
+
+// jpegOrientation returns the EXIF orientation of a JPEG, or 1 if it has
+// none.
+func jpegOrientation(data []byte) int {
+	orientation := 1
+	jpegSegments(data, func(marker byte, segment []byte) {
+		payload := segment[4:]
+		if marker == jpegMarkerAPP1 && bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+			if o := tiffOrientation(payload[6:]); o != 0 {
+				orientation = o
+			}
+		}
+	})
+	return orientation
+}
+
+// tiffOrientation reads the orientation tag from the first IFD of a TIFF
+// header, as found in EXIF. It returns 0 if there is none.
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == exifOrientationTag {
+			if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// applyOrientation transforms img so it displays upright given its EXIF
+// orientation.
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation < orientationFlipH || orientation > orientationRotate270 {
+		return img
+	}
+	bounds := img.Bounds()
+	src := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+	w, h := bounds.Dx(), bounds.Dy()
+
+	// Orientations 5-8 swap width and height
+	dw, dh := w, h
+	if orientation >= orientationTranspose {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case orientationFlipH:
+				dx, dy = w-1-x, y
+			case orientationRotate180:
+				dx, dy = w-1-x, h-1-y
+			case orientationFlipV:
+				dx, dy = x, h-1-y
+			case orientationTranspose:
+				dx, dy = y, x
+			case orientationRotate90:
+				dx, dy = h-1-y, x
+			case orientationTransverse:
+				dx, dy = h-1-y, w-1-x
+			case orientationRotate270:
+				dx, dy = y, w-1-x
+			}
+			copy(dst.Pix[dy*dst.Stride+dx*4:dy*dst.Stride+dx*4+4], src.Pix[y*src.Stride+x*4:y*src.Stride+x*4+4])
+		}
+	}
+	return dst
+}
+
+// stripPNGMetadata drops eXIf, text, and timestamp chunks.
+func stripPNGMetadata(data []byte) []byte {
+	const signature = "\x89PNG\r\n\x1a\n"
+	if !bytes.HasPrefix(data, []byte(signature)) {
+		return nil
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.WriteString(signature)
+	for pos := len(signature); pos < len(data); {
+		if pos+12 > len(data) {
+			return nil
+		}
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return nil
+		}
+		switch string(data[pos+4 : pos+8]) {
+		case "eXIf", "tEXt", "zTXt", "iTXt", "tIME":
+		default:
+			out.Write(data[pos:end])
+		}
+		pos = end
+	}
+	return out.Bytes()
+}
+
+// stripWebPMetadata drops the EXIF and XMP chunks of a WebP file and clears
+// the VP8X flags that announce them.
+func stripWebPMetadata(data []byte) []byte {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:12])
+	for pos := 12; pos < len(data); {
+		if pos+8 > len(data) {
+			return nil
+		}
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size + size%2 // Chunks are padded to an even size
+		if size < 0 || end > len(data) {
+			return nil
+		}
+		chunk := data[pos:end]
+		switch string(chunk[:4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk = append([]byte(nil), chunk...)
+			if len(chunk) > 8 {
+				chunk[8] &^= 0x08 | 0x04 // EXIF and XMP flags
+			}
+			out.Write(chunk)
+		default:
+			out.Write(chunk)
+		}
+		pos = end
+	}
+	stripped := out.Bytes()
+	binary.LittleEndian.PutUint32(stripped[4:], uint32(len(stripped)-8))
+	return stripped
+}
+
+// isoBox is one box of an ISO base media file (HEIF is one).
+type isoBox struct {
+	boxType string
+	body    []byte // Contents after the header
+}
+
+// isoBoxes splits data into boxes. It returns nil if data is malformed.
+func isoBoxes(data []byte) []isoBox {
+	var boxes []isoBox
+	for pos := 0; pos < len(data); {
+		if pos+8 > len(data) {
+			return nil
+		}
+		size := int(binary.BigEndian.Uint32(data[pos:]))
+		header := 8
+		switch size {
+		case 0: // Extends to the end of the data
+			size = len(data) - pos
+		case 1:
+			if pos+16 > len(data) {
+				return nil
+			}
+			large := binary.BigEndian.Uint64(data[pos+8:])
+			if large > uint64(len(data)-pos) {
+				return nil
+			}
+			size, header = int(large), 16
+		}
+		if size < header || pos+size > len(data) {
+			return nil
+		}
+		boxes = append(boxes, isoBox{
+			boxType: string(data[pos+4 : pos+8]),
+			body:    data[pos+header : pos+size],
+		})
+		pos += size
+	}
+	return boxes
+}
+
+// readUintN reads an n-byte big-endian unsigned integer (n is 0, 2, 4, or 8).
+func readUintN(b []byte, n int) (uint64, bool) {
+	if len(b) < n {
+		return 0, false
+	}
+	switch n {
+	case 0:
+		return 0, true
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), true
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), true
+	case 8:
+		return binary.BigEndian.Uint64(b), true
+	}
+	return 0, false
+}
+
+// stripHEIFMetadata blanks the EXIF and XMP items of a HEIF file in place.
+// Removing them would shift the offsets of every other item, so their bytes
+// are zeroed instead, which leaves them unreadable.
+func stripHEIFMetadata(data []byte) []byte {
+	var meta []byte
+	for _, box := range isoBoxes(data) {
+		if box.boxType == "meta" && len(box.body) >= 4 {
+			meta = box.body[4:] // Skip FullBox version and flags
+		}
+	}
+	if meta == nil {
+		return nil
+	}
+	children := isoBoxes(meta)
+
+	metadataItems := map[uint64]bool{}
+	for _, box := range children {
+		if box.boxType != "iinf" || len(box.body) < 4 {
+			continue
+		}
+		body := box.body[4:]
+		if box.body[0] == 0 {
+			body = body[min(2, len(body)):]
+		} else {
+			body = body[min(4, len(body)):]
+		}
+		for _, infe := range isoBoxes(body) {
+			if infe.boxType != "infe" || len(infe.body) < 4 || infe.body[0] < 2 {
+				continue
+			}
+			idSize := 2
+			if infe.body[0] >= 3 {
+				idSize = 4
+			}
+			rest := infe.body[4:]
+			id, ok := readUintN(rest, idSize)
+			if !ok || len(rest) < idSize+6 {
+				continue
+			}
+			itemType := string(rest[idSize+2 : idSize+6])
+			if itemType == "Exif" {
+				metadataItems[id] = true
+			} else if itemType == "mime" {
+				// item_name and content_type follow as NUL-terminated strings
+				strs := bytes.SplitN(rest[idSize+6:], []byte{0}, 3)
+				if len(strs) >= 2 && string(strs[1]) == "application/rdf+xml" {
+					metadataItems[id] = true
+				}
+			}
+		}
+	}
+	if len(metadataItems) == 0 {
+		return data
+	}
+
+	out := append([]byte(nil), data...)
+	for _, box := range children {
+		if box.boxType != "iloc" || len(box.body) < 6 {
+			continue
+		}
+		version := box.body[0]
+		b := box.body[4:]
+		offsetSize, lengthSize := int(b[0]>>4), int(b[0]&0x0F)
+		baseOffsetSize, indexSize := int(b[1]>>4), int(b[1]&0x0F)
+		if version == 0 {
+			indexSize = 0
+		}
+		b = b[2:]
+		countSize := 2
+		if version >= 2 {
+			countSize = 4
+		}
+		itemCount, ok := readUintN(b, countSize)
+		if !ok {
+			return nil
+		}
+		b = b[countSize:]
+		for i := uint64(0); i < itemCount; i++ {
+			id, ok := readUintN(b, countSize)
+			if !ok {
+				return nil
+			}
+			b = b[countSize:]
+			constructionMethod := 0
+			if version >= 1 {
+				if len(b) < 2 {
+					return nil
+				}
+				constructionMethod = int(b[1] & 0x0F)
+				b = b[2:]
+			}
+			if len(b) < 2 {
+				return nil
+			}
+			b = b[2:] // data_reference_index
+			baseOffset, ok := readUintN(b, baseOffsetSize)
+			if !ok || len(b) < baseOffsetSize+2 {
+				return nil
+			}
+			b = b[baseOffsetSize:]
+			extentCount := int(binary.BigEndian.Uint16(b))
+			b = b[2:]
+			for e := 0; e < extentCount; e++ {
+				b = b[min(indexSize, len(b)):]
+				extentOffset, ok1 := readUintN(b, offsetSize)
+				b = b[min(offsetSize, len(b)):]
+				extentLength, ok2 := readUintN(b, lengthSize)
+				b = b[min(lengthSize, len(b)):]
+				if !ok1 || !ok2 {
+					return nil
+				}
+				// Only items stored at file offsets (construction method 0)
+				// can be blanked
+				if !metadataItems[id] || constructionMethod != 0 {
+					continue
+				}
+				start := baseOffset + extentOffset
+				end := start + extentLength
+				if extentLength == 0 || end > uint64(len(out)) || end < start {
+					continue
+				}
+				clear(out[start:end])
+			}
+		}
+	}
+	return out
+}
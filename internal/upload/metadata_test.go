@@ -0,0 +1,236 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+// exifWithOrientation returns an APP1 segment holding a big-endian EXIF
+// block whose only tag is the given orientation.
+func exifWithOrientation(orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")
+	tiff = binary.BigEndian.AppendUint16(tiff, 1) // One IFD entry
+	tiff = binary.BigEndian.AppendUint16(tiff, exifOrientationTag)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3) // SHORT
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0) // Padding and next IFD offset
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, jpegMarkerAPP1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// encodeTestJPEG returns a width x height JPEG with the given segments
+// inserted after its start-of-image marker.
+func encodeTestJPEG(t *testing.T, width, height int, segments ...[]byte) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+	data := buf.Bytes()
+	out := append([]byte(nil), data[:2]...)
+	for _, segment := range segments {
+		out = append(out, segment...)
+	}
+	return append(out, data[2:]...)
+}
+
+func pngChunk(chunkType string, body []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(body)))
+	chunk = append(chunk, chunkType...)
+	chunk = append(chunk, body...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+func testISOBox(boxType string, body []byte) []byte {
+	box := binary.BigEndian.AppendUint32(nil, uint32(len(body)+8))
+	return append(append(box, boxType...), body...)
+}
+
+func TestProcessImageAppliesEXIFOrientation(t *testing.T) {
+	cfg := resolveImageConfig(nil)
+	for _, tc := range []struct {
+		orientation           uint16
+		wantWidth, wantHeight int
+	}{
+		{1, 40, 20},
+		{3, 40, 20},
+		{6, 20, 40},
+		{8, 20, 40},
+	} {
+		data := encodeTestJPEG(t, 40, 20, exifWithOrientation(tc.orientation))
+		processed, err := ProcessImage(bytes.NewReader(data), 0, cfg)
+		if err != nil {
+			t.Fatalf("orientation %d: unexpected error: %v", tc.orientation, err)
+		}
+		if processed.Width != tc.wantWidth || processed.Height != tc.wantHeight {
+			t.Errorf("orientation %d: got %dx%d, want %dx%d", tc.orientation,
+				processed.Width, processed.Height, tc.wantWidth, tc.wantHeight)
+		}
+		if bytes.Contains(processed.Data, []byte("Exif\x00\x00")) {
+			t.Errorf("orientation %d: output still carries EXIF", tc.orientation)
+		}
+	}
+}
+
+func TestApplyOrientationMovesPixels(t *testing.T) {
+	// A 2x1 image with a red pixel on the left
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	red := color.NRGBA{R: 255, A: 255}
+	img.Set(0, 0, red)
+
+	rotated := applyOrientation(img, orientationRotate90)
+	if b := rotated.Bounds(); b.Dx() != 1 || b.Dy() != 2 {
+		t.Fatalf("rotated bounds = %v", b)
+	}
+	if got := color.NRGBAModel.Convert(rotated.At(0, 0)); got != red {
+		t.Errorf("rotating 90° clockwise should put the left pixel on top, got %v", got)
+	}
+	flipped := applyOrientation(img, orientationFlipH)
+	if got := color.NRGBAModel.Convert(flipped.At(1, 0)); got != red {
+		t.Errorf("flipping should move the left pixel right, got %v", got)
+	}
+}
+
+func TestStripMetadata(t *testing.T) {
+	t.Run("jpeg", func(t *testing.T) {
+		comment := append([]byte{0xFF, jpegMarkerCOM, 0x00, 0x07}, "hello"...)
+		data := encodeTestJPEG(t, 4, 4, exifWithOrientation(6), comment)
+		stripped := StripMetadata(data, "image/jpeg")
+		if bytes.Contains(stripped, []byte("Exif")) || bytes.Contains(stripped, []byte("hello")) {
+			t.Error("EXIF and comments should be removed")
+		}
+		if _, err := jpeg.Decode(bytes.NewReader(stripped)); err != nil {
+			t.Errorf("stripped JPEG doesn't decode: %v", err)
+		}
+	})
+
+	t.Run("png", func(t *testing.T) {
+		data := encodeTestPNG(t, 2, 2, color.White)
+		iend := len(data) - 12
+		withText := append(append(append([]byte(nil), data[:iend]...),
+			pngChunk("tEXt", []byte("GPS\x0051.5,-0.1"))...), data[iend:]...)
+		stripped := StripMetadata(withText, "image/png")
+		if !bytes.Equal(stripped, data) {
+			t.Error("text chunks should be removed, leaving the original image")
+		}
+	})
+
+	t.Run("webp", func(t *testing.T) {
+		vp8x := make([]byte, 10)
+		vp8x[0] = 0x08 | 0x04 // EXIF and XMP flags
+		var body []byte
+		body = append(body, "VP8X"...)
+		body = binary.LittleEndian.AppendUint32(body, 10)
+		body = append(body, vp8x...)
+		body = append(body, "EXIF"...)
+		body = binary.LittleEndian.AppendUint32(body, 3)
+		body = append(body, "GPS\x00"...) // Padded to an even size
+		body = append(body, "VP8L"...)
+		body = binary.LittleEndian.AppendUint32(body, 2)
+		body = append(body, 1, 2)
+		data := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)+4))...)
+		data = append(append(data, "WEBP"...), body...)
+
+		stripped := StripMetadata(data, "image/webp")
+		if bytes.Contains(stripped, []byte("EXIF")) {
+			t.Error("EXIF chunk should be removed")
+		}
+		if stripped[20] != 0 {
+			t.Errorf("VP8X flags = %#x, want 0", stripped[20])
+		}
+		if size := binary.LittleEndian.Uint32(stripped[4:]); int(size) != len(stripped)-8 {
+			t.Errorf("RIFF size = %d, want %d", size, len(stripped)-8)
+		}
+		if !bytes.HasSuffix(stripped, []byte("VP8L\x02\x00\x00\x00\x01\x02")) {
+			t.Error("image data should be kept")
+		}
+	})
+
+	t.Run("heif", func(t *testing.T) {
+		exif := []byte("Exif GPS 51.5,-0.1")
+		pixels := []byte("pixels")
+		ftyp := testISOBox("ftyp", []byte("heic\x00\x00\x00\x00heic"))
+
+		infe := func(id uint16, itemType string) []byte {
+			body := []byte{2, 0, 0, 0}
+			body = binary.BigEndian.AppendUint16(body, id)
+			body = append(body, 0, 0)
+			return testISOBox("infe", append(append(body, itemType...), 0))
+		}
+		iinf := testISOBox("iinf", append([]byte{0, 0, 0, 0, 0, 2},
+			append(infe(1, "hvc1"), infe(2, "Exif")...)...))
+
+		buildMeta := func(imageOffset, exifOffset uint32) []byte {
+			iloc := []byte{0, 0, 0, 0, 0x44, 0x00, 0, 2}
+			for _, item := range []struct {
+				id             uint16
+				offset, length uint32
+			}{{1, imageOffset, uint32(len(pixels))}, {2, exifOffset, uint32(len(exif))}} {
+				iloc = binary.BigEndian.AppendUint16(iloc, item.id)
+				iloc = append(iloc, 0, 0, 0, 1) // data_reference_index, one extent
+				iloc = binary.BigEndian.AppendUint32(iloc, item.offset)
+				iloc = binary.BigEndian.AppendUint32(iloc, item.length)
+			}
+			return testISOBox("meta", append([]byte{0, 0, 0, 0}, append(iinf, testISOBox("iloc", iloc)...)...))
+		}
+		// Offsets don't change the meta box's size, so measure it first
+		mdatStart := uint32(len(ftyp) + len(buildMeta(0, 0)) + 8)
+		meta := buildMeta(mdatStart, mdatStart+uint32(len(pixels)))
+		data := append(append(ftyp, meta...), testISOBox("mdat", append(append([]byte(nil), pixels...), exif...))...)
+
+		stripped := StripMetadata(data, "image/heic")
+		if len(stripped) != len(data) {
+			t.Fatalf("length changed from %d to %d", len(data), len(stripped))
+		}
+		if bytes.Contains(stripped, exif) {
+			t.Error("EXIF item should be blanked")
+		}
+		if !bytes.Contains(stripped, pixels) {
+			t.Error("image item should be kept")
+		}
+	})
+
+	t.Run("malformed and unknown input is unchanged", func(t *testing.T) {
+		for _, tc := range []struct {
+			data     []byte
+			mimeType string
+		}{
+			{[]byte{0xFF, 0xD8, 0xFF}, "image/jpeg"},
+			{[]byte("\x89PNG\r\n\x1a\n\x00\x00"), "image/png"},
+			{[]byte("RIFF\x00\x00\x00\x00WEBPVP8"), "image/webp"},
+			{[]byte("\x00\x00\x00\x10ftyp"), "image/heic"},
+			{[]byte("GIF89a"), "image/gif"},
+		} {
+			if got := StripMetadata(tc.data, tc.mimeType); !bytes.Equal(got, tc.data) {
+				t.Errorf("%s: got %q, want input unchanged", tc.mimeType, got)
+			}
+		}
+	})
+}
+
+func TestProcessImageOrOriginalStripsUndecodableUploads(t *testing.T) {
+	comment := append([]byte{0xFF, jpegMarkerCOM, 0x00, 0x07}, "hello"...)
+	// A JPEG header with no image data can't be decoded, so it's kept as uploaded
+	data := append([]byte{0xFF, 0xD8}, comment...)
+	data = append(data, 0xFF, jpegMarkerSOS, 0x00, 0x02)
+
+	got, mimeType, err := ProcessImageOrOriginal(data, "image/jpeg", 0, resolveImageConfig(nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mimeType != "image/jpeg" {
+		t.Errorf("mime type = %q", mimeType)
+	}
+	if bytes.Contains(got, []byte("hello")) {
+		t.Error("metadata should be stripped from uploads kept as uploaded")
+	}
+}
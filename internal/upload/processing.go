@@ -0,0 +1,215 @@
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // Register GIF format
+	"image/jpeg"
+	"image/png"
+	"io"
+	"sort"
+
+	"github.com/nfnt/resize"
+)
+
+// Image output formats.
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatWebP = "webp"
+)
+
+// ImageEncoder writes img in one output format. quality is the configured
+// JPEG quality, for encoders that take one.
+type ImageEncoder struct {
+	MimeType string
+	Encode   func(w io.Writer, img image.Image, quality int) error
+}
+
+// imageEncoders holds the output formats this build can write. The standard
+// library has no WebP encoder, so FormatWebP is only accepted once an
+// encoder is registered with RegisterImageEncoder.
+var imageEncoders = map[string]ImageEncoder{
+	FormatJPEG: {MimeType: "image/jpeg", Encode: func(w io.Writer, img image.Image, quality int) error {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	}},
+	FormatPNG: {MimeType: "image/png", Encode: func(w io.Writer, img image.Image, _ int) error {
+		return png.Encode(w, img)
+	}},
+}
+
+// RegisterImageEncoder makes format available as an image output format.
+// Call it from an init function, before any upload is processed.
+func RegisterImageEncoder(format string, encoder ImageEncoder) {
+	imageEncoders[format] = encoder
+}
+
+// OutputFormats returns the output formats that can be configured.
+func OutputFormats() []string {
+	formats := make([]string, 0, len(imageEncoders))
+	for format := range imageEncoders {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// ProcessedImage is an uploaded image after resizing and re-encoding.
+type ProcessedImage struct {
+	Data         []byte
+	MimeType     string
+	Width        int
+	Height       int
+	SourceFormat string // Format the upload was decoded from, e.g. "png"
+}
+
+// ProcessImage decodes an uploaded image, shrinks it so neither side exceeds
+// maxDimension, and encodes it in cfg.OutputFormat. JPEGs are turned upright
+// according to their EXIF orientation; the output carries no EXIF or other
+// metadata, so camera details and GPS positions are dropped. Images with transparent
+// pixels are written as PNG when cfg.PreserveTransparency is set and the
+// output format can't hold transparency; otherwise they are flattened onto
+// white. HEIC/HEIF uploads are converted when a HEIFDecoder is registered.
+// Returns an error wrapping ErrInvalidFile if the upload can't be decoded,
+// and also ErrUnsupportedCodec if it's a HEIF image this server can't convert.
+func ProcessImage(r io.Reader, maxDimension int, cfg ImageConfig) (*ProcessedImage, error) {
+	img, sourceFormat, orientation, err := decodeImage(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeImage(applyOrientation(fitWithin(img, maxDimension), orientation), sourceFormat, cfg)
+}
+
+// decodeImage decodes an upload and returns its EXIF orientation (1 unless
+// it's a JPEG that sets one). HEIC/HEIF uploads go to the registered
+// HEIFDecoder, which returns them upright. Returns an error wrapping
+// ErrInvalidFile if the upload can't be decoded, and also ErrUnsupportedCodec
+// if it's HEIF and can't be converted.
+func decodeImage(r io.Reader) (image.Image, string, int, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("failed to read image: %w", err)
+	}
+	if IsHEIF(data) {
+		img, err := decodeHEIF(data)
+		if err != nil {
+			return nil, "", 0, err
+		}
+		return img, "heif", 1, nil
+	}
+	img, sourceFormat, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	orientation := 1
+	if sourceFormat == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+	return img, sourceFormat, orientation, nil
+}
+
+// ProcessSquareImage decodes an uploaded image once, crops it to a centered
+// square, and encodes one copy per size (each at most size x size; smaller
+// uploads aren't enlarged). Orientation, metadata, output formats, and
+// transparency follow ProcessImage. Returns an error wrapping ErrInvalidFile
+// if the upload can't be decoded.
+func ProcessSquareImage(r io.Reader, sizes []int, cfg ImageConfig) ([]*ProcessedImage, error) {
+	img, sourceFormat, orientation, err := decodeImage(r)
+	if err != nil {
+		return nil, err
+	}
+	square := applyOrientation(cropSquare(img), orientation)
+
+	out := make([]*ProcessedImage, 0, len(sizes))
+	for _, size := range sizes {
+		scaled := square
+		if square.Bounds().Dx() > size {
+			scaled = resize.Resize(uint(size), uint(size), square, resize.Lanczos3)
+		}
+		processed, err := encodeImage(scaled, sourceFormat, cfg)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, processed)
+	}
+	return out, nil
+}
+
+// cropSquare returns the largest centered square of img.
+func cropSquare(img image.Image) image.Image {
+	bounds := img.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	x := bounds.Min.X + (bounds.Dx()-side)/2
+	y := bounds.Min.Y + (bounds.Dy()-side)/2
+	square := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(square, square.Bounds(), img, image.Point{X: x, Y: y}, draw.Src)
+	return square
+}
+
+// encodeImage writes img in cfg.OutputFormat, handling transparency as
+// described on ProcessImage.
+func encodeImage(img image.Image, sourceFormat string, cfg ImageConfig) (*ProcessedImage, error) {
+	format := cfg.OutputFormat
+	encoder, ok := imageEncoders[format]
+	if !ok {
+		format, encoder = FormatJPEG, imageEncoders[FormatJPEG]
+	}
+	if format == FormatJPEG && !isOpaque(img) {
+		if cfg.PreserveTransparency {
+			encoder = imageEncoders[FormatPNG]
+		} else {
+			img = flatten(img, color.White)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := encoder.Encode(&buf, img, cfg.JPEGQuality); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	bounds := img.Bounds()
+	return &ProcessedImage{
+		Data:         buf.Bytes(),
+		MimeType:     encoder.MimeType,
+		Width:        bounds.Dx(),
+		Height:       bounds.Dy(),
+		SourceFormat: sourceFormat,
+	}, nil
+}
+
+// ProcessImageOrOriginal runs ProcessImage on data. Uploads the server can't
+// decode, such as HEIC without a HEIFDecoder, are returned as uploaded, with
+// their mimeType, except that StripMetadata removes their metadata. This is
+// for callers that store images as uploaded when they can't be processed.
+func ProcessImageOrOriginal(data []byte, mimeType string, maxDimension int, cfg ImageConfig) ([]byte, string, error) {
+	processed, err := ProcessImage(bytes.NewReader(data), maxDimension, cfg)
+	if err != nil {
+		if errors.Is(err, ErrInvalidFile) {
+			return StripMetadata(data, mimeType), mimeType, nil
+		}
+		return nil, "", err
+	}
+	return processed.Data, processed.MimeType, nil
+}
+
+// isOpaque reports whether img has no transparent pixels. Image types that
+// can't report it are assumed to be opaque.
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return true
+}
+
+// flatten draws img over a solid background, removing transparency.
+func flatten(img image.Image, background color.Color) image.Image {
+	bounds := img.Bounds()
+	flat := image.NewRGBA(bounds)
+	draw.Draw(flat, bounds, image.NewUniform(background), image.Point{}, draw.Src)
+	draw.Draw(flat, bounds, img, bounds.Min, draw.Over)
+	return flat
+}
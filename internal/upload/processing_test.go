@@ -0,0 +1,254 @@
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func encodeTestPNG(t *testing.T, width, height int, fill color.Color) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, fill)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcessImage(t *testing.T) {
+	opaque := color.NRGBA{R: 200, G: 10, B: 10, A: 255}
+	transparent := color.NRGBA{R: 200, G: 10, B: 10, A: 0}
+	defaults := resolveImageConfig(nil)
+
+	tests := []struct {
+		name         string
+		data         []byte
+		maxDimension int
+		configure    func(*ImageConfig)
+		wantMime     string
+		wantWidth    int
+		wantHeight   int
+	}{
+		{
+			name:         "large image is shrunk to the max dimension",
+			data:         encodeTestPNG(t, 400, 200, opaque),
+			maxDimension: 100,
+			wantMime:     "image/jpeg",
+			wantWidth:    100,
+			wantHeight:   50,
+		},
+		{
+			name:         "small image keeps its size",
+			data:         encodeTestPNG(t, 40, 80, opaque),
+			maxDimension: 100,
+			wantMime:     "image/jpeg",
+			wantWidth:    40,
+			wantHeight:   80,
+		},
+		{
+			name:         "transparent image is flattened to JPEG by default",
+			data:         encodeTestPNG(t, 20, 20, transparent),
+			maxDimension: 100,
+			wantMime:     "image/jpeg",
+			wantWidth:    20,
+			wantHeight:   20,
+		},
+		{
+			name:         "transparent image stays PNG when transparency is preserved",
+			data:         encodeTestPNG(t, 20, 20, transparent),
+			maxDimension: 100,
+			configure:    func(cfg *ImageConfig) { cfg.PreserveTransparency = true },
+			wantMime:     "image/png",
+			wantWidth:    20,
+			wantHeight:   20,
+		},
+		{
+			name:         "opaque image stays JPEG when transparency is preserved",
+			data:         encodeTestPNG(t, 20, 20, opaque),
+			maxDimension: 100,
+			configure:    func(cfg *ImageConfig) { cfg.PreserveTransparency = true },
+			wantMime:     "image/jpeg",
+			wantWidth:    20,
+			wantHeight:   20,
+		},
+		{
+			name:         "PNG output format",
+			data:         encodeTestPNG(t, 20, 20, opaque),
+			maxDimension: 100,
+			configure:    func(cfg *ImageConfig) { cfg.OutputFormat = FormatPNG },
+			wantMime:     "image/png",
+			wantWidth:    20,
+			wantHeight:   20,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaults
+			if tt.configure != nil {
+				tt.configure(&cfg)
+			}
+			processed, err := ProcessImage(bytes.NewReader(tt.data), tt.maxDimension, cfg)
+			if err != nil {
+				t.Fatalf("ProcessImage() error = %v", err)
+			}
+			if processed.MimeType != tt.wantMime {
+				t.Errorf("MimeType = %s, want %s", processed.MimeType, tt.wantMime)
+			}
+			if processed.Width != tt.wantWidth || processed.Height != tt.wantHeight {
+				t.Errorf("size = %dx%d, want %dx%d", processed.Width, processed.Height, tt.wantWidth, tt.wantHeight)
+			}
+			if processed.SourceFormat != "png" {
+				t.Errorf("SourceFormat = %s, want png", processed.SourceFormat)
+			}
+			if _, _, err := image.Decode(bytes.NewReader(processed.Data)); err != nil {
+				t.Errorf("output is not a valid image: %v", err)
+			}
+		})
+	}
+}
+
+func TestProcessSquareImage(t *testing.T) {
+	// A wide image whose center square is blue, with green bars either side
+	wide := image.NewNRGBA(image.Rect(0, 0, 400, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 400; x++ {
+			if x < 100 || x >= 300 {
+				wide.Set(x, y, color.NRGBA{G: 255, A: 255})
+			} else {
+				wide.Set(x, y, color.NRGBA{B: 255, A: 255})
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, wide); err != nil {
+		t.Fatalf("failed to encode test image: %v", err)
+	}
+	cfg := resolveImageConfig(nil)
+	cfg.OutputFormat = FormatPNG
+
+	images, err := ProcessSquareImage(bytes.NewReader(buf.Bytes()), []int{128, 32}, cfg)
+	if err != nil {
+		t.Fatalf("ProcessSquareImage: %v", err)
+	}
+	for i, want := range []int{128, 32} {
+		if images[i].Width != want || images[i].Height != want {
+			t.Errorf("size %d: got %dx%d", want, images[i].Width, images[i].Height)
+		}
+		img, _, err := image.Decode(bytes.NewReader(images[i].Data))
+		if err != nil {
+			t.Fatalf("decode output: %v", err)
+		}
+		for _, p := range []image.Point{{0, 0}, {want - 1, want / 2}} {
+			if r, g, b, _ := img.At(p.X, p.Y).RGBA(); g > 0x2000 || b < 0xd000 || r > 0x2000 {
+				t.Errorf("size %d: pixel %v is not from the centered square", want, p)
+			}
+		}
+	}
+
+	small, err := ProcessSquareImage(bytes.NewReader(encodeTestPNG(t, 40, 80, color.NRGBA{A: 255})), []int{128}, cfg)
+	if err != nil {
+		t.Fatalf("ProcessSquareImage: %v", err)
+	}
+	if small[0].Width != 40 || small[0].Height != 40 {
+		t.Errorf("small uploads are cropped but not enlarged: got %dx%d", small[0].Width, small[0].Height)
+	}
+
+	if _, err := ProcessSquareImage(bytes.NewReader([]byte("not an image")), []int{128}, cfg); !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("expected ErrInvalidFile, got %v", err)
+	}
+}
+
+func TestProcessImageWithVariants(t *testing.T) {
+	cfg := resolveImageConfig(nil)
+	opaque := color.NRGBA{R: 10, G: 200, B: 10, A: 255}
+
+	full, variants, err := ProcessImageWithVariants(bytes.NewReader(encodeTestPNG(t, 1600, 800, opaque)), 1000, cfg)
+	if err != nil {
+		t.Fatalf("ProcessImageWithVariants: %v", err)
+	}
+	if full.Width != 1000 || full.Height != 500 {
+		t.Errorf("full: got %dx%d, want 1000x500", full.Width, full.Height)
+	}
+	for name, want := range map[string][2]int{"thumb": {200, 100}, "card": {600, 300}} {
+		v, ok := variants[name]
+		if !ok {
+			t.Fatalf("missing %s variant", name)
+		}
+		if v.Width != want[0] || v.Height != want[1] || v.MimeType != "image/jpeg" {
+			t.Errorf("%s: got %dx%d %s", name, v.Width, v.Height, v.MimeType)
+		}
+	}
+
+	// A card-size copy of a 400px image would be the image itself
+	_, variants, err = ProcessImageWithVariants(bytes.NewReader(encodeTestPNG(t, 300, 400, opaque)), 1000, cfg)
+	if err != nil {
+		t.Fatalf("ProcessImageWithVariants: %v", err)
+	}
+	if _, ok := variants["card"]; ok || len(variants) != 1 {
+		t.Errorf("expected only a thumb variant, got %v", len(variants))
+	}
+	if thumb := variants["thumb"]; thumb.Width != 150 || thumb.Height != 200 {
+		t.Errorf("thumb: got %dx%d, want 150x200", thumb.Width, thumb.Height)
+	}
+}
+
+func TestProcessImageOrOriginal(t *testing.T) {
+	cfg := resolveImageConfig(nil)
+
+	heic := []byte("not an image the server can decode")
+	data, mimeType, err := ProcessImageOrOriginal(heic, "image/heic", 100, cfg)
+	if err != nil {
+		t.Fatalf("ProcessImageOrOriginal() error = %v", err)
+	}
+	if !bytes.Equal(data, heic) || mimeType != "image/heic" {
+		t.Errorf("undecodable upload should be returned unchanged, got %s", mimeType)
+	}
+
+	if _, err := ProcessImage(bytes.NewReader(heic), 100, cfg); !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("ProcessImage() error = %v, want ErrInvalidFile", err)
+	}
+
+	_, mimeType, err = ProcessImageOrOriginal(encodeTestPNG(t, 10, 10, color.Black), "image/png", 100, cfg)
+	if err != nil || mimeType != "image/jpeg" {
+		t.Errorf("decodable upload should be re-encoded, got %s, %v", mimeType, err)
+	}
+}
+
+func TestResolveImageConfig(t *testing.T) {
+	t.Setenv("IMAGE_MAX_UPLOAD_MB", "")
+	t.Setenv("IMAGE_JPEG_QUALITY", "70")
+	t.Setenv("IMAGE_MAX_DIMENSION", "not-a-number")
+
+	cfg := resolveImageConfig(map[string]string{
+		SettingImageMaxUploadMB:  "20",
+		SettingImageJPEGQuality:  "90",
+		SettingImageMaxDimension: "1600",
+		SettingImageOutputFormat: "gif", // Invalid, ignored
+	})
+
+	if cfg.MaxUploadBytes != 20*1024*1024 || cfg.Sources[SettingImageMaxUploadMB] != ImageSourceSetting {
+		t.Errorf("MaxUploadBytes = %d (%s), want the site setting", cfg.MaxUploadBytes, cfg.Sources[SettingImageMaxUploadMB])
+	}
+	if cfg.JPEGQuality != 70 || cfg.Sources[SettingImageJPEGQuality] != ImageSourceEnv {
+		t.Errorf("JPEGQuality = %d (%s), want the env override", cfg.JPEGQuality, cfg.Sources[SettingImageJPEGQuality])
+	}
+	if cfg.MaxDimension != 1600 {
+		t.Errorf("MaxDimension = %d, want the site setting when the env value is invalid", cfg.MaxDimension)
+	}
+	if cfg.OutputFormat != FormatJPEG || cfg.Sources[SettingImageOutputFormat] != ImageSourceDefault {
+		t.Errorf("OutputFormat = %s, want the default", cfg.OutputFormat)
+	}
+	if cfg.MaxHeroUploadBytes != MaxHeroImageSize {
+		t.Errorf("MaxHeroUploadBytes = %d, want %d", cfg.MaxHeroUploadBytes, MaxHeroImageSize)
+	}
+}
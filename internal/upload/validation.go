@@ -34,6 +34,10 @@ var (
 
 	// ErrInvalidFile is returned when file is invalid or corrupted
 	ErrInvalidFile = errors.New("invalid or corrupted file")
+
+	// ErrUnsupportedCodec is returned, alongside ErrInvalidFile, for images
+	// in a recognised format that this server has no decoder for
+	ErrUnsupportedCodec = errors.New("unsupported image codec")
 )
 
 // AllowedImageTypes maps file extensions to their MIME types
@@ -0,0 +1,77 @@
+package upload
+
+import (
+	"image"
+	"io"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+	"github.com/nfnt/resize"
+)
+
+// ImageVariant is a smaller copy of an image, at most MaxDimension pixels on
+// its longer side. The full-size image is the upload itself, limited to
+// ImageConfig.MaxDimension.
+type ImageVariant struct {
+	Name         string
+	MaxDimension int
+}
+
+// ImageVariants are the copies generated for each animal image, smallest
+// first.
+var ImageVariants = []ImageVariant{
+	{Name: models.ImageVariantThumb, MaxDimension: 200},
+	{Name: models.ImageVariantCard, MaxDimension: 600},
+}
+
+// IsImageVariant reports whether name is one of ImageVariants.
+func IsImageVariant(name string) bool {
+	for _, v := range ImageVariants {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ProcessImageWithVariants is ProcessImage plus a copy of the result for
+// each of ImageVariants, decoding the upload once. Variants the full image
+// is already no bigger than are left out, since they would be the same
+// image; clients use the full image for them.
+func ProcessImageWithVariants(r io.Reader, maxDimension int, cfg ImageConfig) (*ProcessedImage, map[string]*ProcessedImage, error) {
+	img, sourceFormat, orientation, err := decodeImage(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	img = applyOrientation(fitWithin(img, maxDimension), orientation)
+
+	full, err := encodeImage(img, sourceFormat, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	variants := make(map[string]*ProcessedImage, len(ImageVariants))
+	for _, v := range ImageVariants {
+		bounds := img.Bounds()
+		if bounds.Dx() <= v.MaxDimension && bounds.Dy() <= v.MaxDimension {
+			continue
+		}
+		processed, err := encodeImage(fitWithin(img, v.MaxDimension), sourceFormat, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		variants[v.Name] = processed
+	}
+	return full, variants, nil
+}
+
+// fitWithin shrinks img so neither side exceeds maxDimension, keeping its
+// aspect ratio. Smaller images, and a maxDimension of 0, leave it as is.
+func fitWithin(img image.Image, maxDimension int) image.Image {
+	bounds := img.Bounds()
+	if maxDimension <= 0 || (bounds.Dx() <= maxDimension && bounds.Dy() <= maxDimension) {
+		return img
+	}
+	if bounds.Dx() > bounds.Dy() {
+		return resize.Resize(uint(maxDimension), 0, img, resize.Lanczos3)
+	}
+	return resize.Resize(0, uint(maxDimension), img, resize.Lanczos3)
+}
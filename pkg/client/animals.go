@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+func (o AnimalListOptions) query() url.Values {
+	q := url.Values{}
+	for key, value := range map[string]string{"status": o.Status, "name": o.Name, "sort": o.Sort, "order": o.Order} {
+		if value != "" {
+			q.Set(key, value)
+		}
+	}
+	for key, value := range o.Fields {
+		q.Set("field."+key, value)
+	}
+	if len(o.Size) > 0 {
+		q.Set("size", strings.Join(o.Size, ","))
+	}
+	if len(o.EnergyLevel) > 0 {
+		q.Set("energy_level", strings.Join(o.EnergyLevel, ","))
+	}
+	return q
+}
+
+// AnimalsPage returns one page of a group's animals. The total comes from
+// the X-Total-Count header.
+// Route: GET /api/groups/:id/animals
+func (c *Client) AnimalsPage(ctx context.Context, groupID uint, opts AnimalListOptions, limit, offset int) (Page[AnimalListItem], error) {
+	q := opts.query()
+	q.Set("limit", strconv.Itoa(limit))
+	q.Set("offset", strconv.Itoa(offset))
+	var items []AnimalListItem
+	header, err := c.Do(ctx, http.MethodGet, fmt.Sprintf("/groups/%d/animals", groupID), q, nil, &items)
+	if err != nil {
+		return Page[AnimalListItem]{}, err
+	}
+	total, _ := strconv.ParseInt(header.Get("X-Total-Count"), 10, 64)
+	return Page[AnimalListItem]{
+		Items:   items,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+		HasMore: int64(offset+len(items)) < total,
+	}, nil
+}
+
+// Animals iterates over all of a group's animals matching opts, a page at
+// a time
+func (c *Client) Animals(ctx context.Context, groupID uint, opts AnimalListOptions) iter.Seq2[AnimalListItem, error] {
+	return Paginate(ctx, DefaultPageSize, func(ctx context.Context, limit, offset int) (Page[AnimalListItem], error) {
+		return c.AnimalsPage(ctx, groupID, opts, limit, offset)
+	})
+}
+
+// AnimalFacets counts a group's animals matching opts by size and energy
+// level. Each facet's counts leave out that facet's own filter.
+// Route: GET /api/groups/:id/animals?facets=true
+func (c *Client) AnimalFacets(ctx context.Context, groupID uint, opts AnimalListOptions) (AnimalFacetCounts, error) {
+	q := opts.query()
+	q.Set("facets", "true")
+	q.Set("limit", "1")
+	var result struct {
+		Facets AnimalFacetCounts `json:"facets"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/groups/%d/animals", groupID), q, &result); err != nil {
+		return nil, err
+	}
+	return result.Facets, nil
+}
+
+// Animal returns one animal with its tags, current weight, and pinned
+// comments
+// Route: GET /api/groups/:id/animals/:animalId
+func (c *Client) Animal(ctx context.Context, groupID, animalID uint) (*Animal, error) {
+	var animal Animal
+	if err := c.get(ctx, fmt.Sprintf("/groups/%d/animals/%d", groupID, animalID), nil, &animal); err != nil {
+		return nil, err
+	}
+	return &animal, nil
+}
+
+// CreateAnimal adds an animal to a group. Group admins only.
+// Route: POST /api/groups/:id/animals
+func (c *Client) CreateAnimal(ctx context.Context, groupID uint, req AnimalRequest) (*Animal, error) {
+	var animal Animal
+	if err := c.post(ctx, fmt.Sprintf("/groups/%d/animals", groupID), req, &animal); err != nil {
+		return nil, err
+	}
+	return &animal, nil
+}
+
+// UpdateAnimal replaces an animal's fields. Group admins only.
+// Route: PUT /api/groups/:id/animals/:animalId
+func (c *Client) UpdateAnimal(ctx context.Context, groupID, animalID uint, req AnimalRequest) (*Animal, error) {
+	var animal Animal
+	if err := c.put(ctx, fmt.Sprintf("/groups/%d/animals/%d", groupID, animalID), req, &animal); err != nil {
+		return nil, err
+	}
+	return &animal, nil
+}
+
+// AnimalCommentsPage returns one page of an animal's comments, newest
+// first. The server caps limit at 100.
+// Route: GET /api/groups/:id/animals/:animalId/comments
+func (c *Client) AnimalCommentsPage(ctx context.Context, groupID, animalID uint, limit, offset int) (Page[AnimalComment], error) {
+	q := url.Values{"limit": {strconv.Itoa(limit)}, "offset": {strconv.Itoa(offset)}}
+	var resp struct {
+		Comments []AnimalComment `json:"comments"`
+		Total    int64           `json:"total"`
+		Limit    int             `json:"limit"`
+		Offset   int             `json:"offset"`
+		HasMore  bool            `json:"hasMore"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/groups/%d/animals/%d/comments", groupID, animalID), q, &resp); err != nil {
+		return Page[AnimalComment]{}, err
+	}
+	return Page[AnimalComment]{Items: resp.Comments, Total: resp.Total, Limit: resp.Limit, Offset: resp.Offset, HasMore: resp.HasMore}, nil
+}
+
+// AnimalComments iterates over all of an animal's comments, newest first
+func (c *Client) AnimalComments(ctx context.Context, groupID, animalID uint) iter.Seq2[AnimalComment, error] {
+	return Paginate(ctx, DefaultPageSize, func(ctx context.Context, limit, offset int) (Page[AnimalComment], error) {
+		return c.AnimalCommentsPage(ctx, groupID, animalID, limit, offset)
+	})
+}
+
+// CreateAnimalComment comments on an animal
+// Route: POST /api/groups/:id/animals/:animalId/comments
+func (c *Client) CreateAnimalComment(ctx context.Context, groupID, animalID uint, req AnimalCommentRequest) (*AnimalComment, error) {
+	var comment AnimalComment
+	if err := c.post(ctx, fmt.Sprintf("/groups/%d/animals/%d/comments", groupID, animalID), req, &comment); err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
@@ -0,0 +1,41 @@
+package client
+
+import "context"
+
+// Login signs in with a username and password and authenticates later
+// requests with the JWT it returns. It asks for a bearer token, so it works
+// when browsers get cookie sessions. Accounts that must sign in through
+// single sign-on can't use it; give them an API token instead.
+// Route: POST /api/login
+func (c *Client) Login(ctx context.Context, username, password string) (*AuthResponse, error) {
+	var resp AuthResponse
+	if err := c.post(ctx, "/login", LoginRequest{Username: username, Password: password, Session: "bearer"}, &resp); err != nil {
+		return nil, err
+	}
+	c.SetToken(resp.Token)
+	return &resp, nil
+}
+
+// RefreshToken exchanges the current JWT for a new one, and uses it from
+// then on. API tokens can't be refreshed.
+// Route: POST /api/refresh
+func (c *Client) RefreshToken(ctx context.Context) error {
+	var resp struct {
+		Token string `json:"token"`
+	}
+	if err := c.post(ctx, "/refresh", nil, &resp); err != nil {
+		return err
+	}
+	c.SetToken(resp.Token)
+	return nil
+}
+
+// Me returns the authenticated user, with their groups
+// Route: GET /api/me
+func (c *Client) Me(ctx context.Context) (*CurrentUser, error) {
+	var user CurrentUser
+	if err := c.get(ctx, "/me", nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,229 @@
+// Package client is a typed Go client for the volunteer media API.
+//
+// Response types are the server's own models, so they can't drift from
+// what the API returns; request bodies mirror the handlers' request types
+// and are checked against them in this package's tests.
+//
+//	c, err := client.New("https://volunteers.example.org", client.WithToken(os.Getenv("API_TOKEN")))
+//	if err != nil { ... }
+//	for animal, err := range c.Animals(ctx, groupID, client.AnimalListOptions{Status: "all"}) {
+//		if err != nil { ... }
+//		fmt.Println(animal.Name)
+//	}
+//
+// Either kind of credential works: a personal API token (pat_...) passed to
+// WithToken, or a JWT from Login. API errors are returned as *APIError.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiPrefix pins requests to version 1 of the API, so a later default
+// version can't change response shapes under the client
+const apiPrefix = "/api/v1"
+
+// structuredErrorsMediaType asks the server for error bodies with a code
+// and field details; see handlers.StructuredErrorsMediaType
+const structuredErrorsMediaType = "application/vnd.volunteer-media.v2+json"
+
+const defaultTimeout = 30 * time.Second
+
+// Client calls the API. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	userAgent  string
+
+	mu    sync.RWMutex
+	token string
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithToken authenticates requests with an API token or a JWT
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithHTTPClient sends requests with hc instead of a client with a 30
+// second timeout
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithUserAgent sets the User-Agent header, so server logs can tell tools
+// apart
+func WithUserAgent(ua string) Option {
+	return func(c *Client) { c.userAgent = ua }
+}
+
+// New returns a client for the server at baseURL, e.g.
+// "https://volunteers.example.org"
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("client: invalid base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("client: base URL must be an absolute http or https URL")
+	}
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		userAgent:  "go-volunteer-media-client",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// SetToken replaces the credential sent with later requests
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+// Token returns the credential sent with requests, such as the JWT saved
+// by Login
+func (c *Client) Token() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.token
+}
+
+// APIError is an error response from the API
+type APIError struct {
+	StatusCode int           `json:"-"`
+	Message    string        `json:"error"`
+	Code       string        `json:"code"` // Stable machine-readable code, e.g. "VALIDATION_FAILED"; empty on a few older routes
+	Details    []FieldError  `json:"details"`
+	RetryAfter time.Duration `json:"-"` // From the Retry-After header of a 429
+}
+
+// FieldError describes one invalid request field of an APIError
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("api: HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("api: HTTP %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is an APIError with status 404
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsForbidden reports whether err is an APIError with status 403
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+// IsUnauthorized reports whether err is an APIError with status 401, such
+// as for an expired JWT or a revoked API token
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// Do sends a request to an API path (relative to /api/v1, e.g.
+// "/groups/3/animals") and decodes a JSON response into out, which may be
+// nil. body, if not nil, is sent as JSON. It returns the response headers
+// for endpoints that report extra data there. Use it for endpoints this
+// package has no method for.
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, body, out any) (http.Header, error) {
+	u := *c.baseURL
+	u.Path = c.baseURL.Path + apiPrefix + path
+	u.RawQuery = query.Encode()
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("client: encoding request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json, "+structuredErrorsMediaType)
+	req.Header.Set("User-Agent", c.userAgent)
+	if token := c.Token(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		_ = json.Unmarshal(data, apiErr)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			var seconds int
+			if _, err := fmt.Sscanf(resp.Header.Get("Retry-After"), "%d", &seconds); err == nil {
+				apiErr.RetryAfter = time.Duration(seconds) * time.Second
+			}
+		}
+		return resp.Header, apiErr
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp.Header, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.Header, fmt.Errorf("client: decoding %s %s response: %w", method, path, err)
+	}
+	return resp.Header, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	_, err := c.Do(ctx, http.MethodGet, path, query, nil, out)
+	return err
+}
+
+func (c *Client) post(ctx context.Context, path string, body, out any) error {
+	_, err := c.Do(ctx, http.MethodPost, path, nil, body, out)
+	return err
+}
+
+func (c *Client) put(ctx context.Context, path string, body, out any) error {
+	_, err := c.Do(ctx, http.MethodPut, path, nil, body, out)
+	return err
+}
+
+func (c *Client) delete(ctx context.Context, path string) error {
+	_, err := c.Do(ctx, http.MethodDelete, path, nil, nil, nil)
+	return err
+}
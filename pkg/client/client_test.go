@@ -0,0 +1,179 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/handlers"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/middleware"
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+)
+
+// newTestServer serves a few real routes the way cmd/api does: under /api,
+// behind AuthRequired, with the version prefix handled by VersionedAPI
+func newTestServer(t *testing.T) (*httptest.Server, *models.User, *models.Group) {
+	t.Helper()
+	os.Setenv("JWT_SECRET", "aB3dE5fG7hI9jK1lM3nO5pQ7rS9tU1vW3xY5zA7bC9dE1fG3hI5jK7lM9nO1pQ3")
+	gin.SetMode(gin.TestMode)
+	db := handlers.SetupTestDB(t)
+	user := handlers.CreateTestUser(t, db, "volunteer", "volunteer@example.com", "password123", false)
+	group := handlers.CreateTestGroup(t, db, "Dogs", "")
+	handlers.AddUserToGroupWithAdmin(t, db, user.ID, group.ID, false)
+	for _, name := range []string{"Ace", "Bella", "Cooper", "Daisy", "Echo"} {
+		if err := db.Create(&models.Animal{GroupID: group.ID, Name: name, Species: "Dog", Status: "available"}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	router := gin.New()
+	api := router.Group("/api")
+	api.POST("/login", handlers.Login(db, middleware.NewSecurityConfigStore(db)))
+	protected := api.Group("/")
+	protected.Use(middleware.AuthRequired(db))
+	protected.GET("/me", handlers.GetCurrentUser(db))
+	protected.GET("/groups/:id/animals", handlers.GetAnimals(db))
+	protected.GET("/groups/:id/animals/:animalId", handlers.GetAnimal(db))
+	protected.GET("/groups/:id/discussions", handlers.GetDiscussions(db))
+	protected.POST("/groups/:id/discussions", handlers.CreateDiscussion(db))
+	server := httptest.NewServer(middleware.VersionedAPI(router, middleware.APIVersions...))
+	t.Cleanup(server.Close)
+	return server, user, group
+}
+
+func TestClient(t *testing.T) {
+	// API clients get bearer tokens even when browsers get cookie sessions
+	t.Setenv("SESSION_MODE", "cookie")
+	server, user, group := newTestServer(t)
+	ctx := context.Background()
+	c, err := New(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Me(ctx); !IsUnauthorized(err) {
+		t.Fatalf("Me without a token: got %v, want 401", err)
+	}
+	if _, err := c.Login(ctx, "volunteer", "wrong"); !IsUnauthorized(err) {
+		t.Fatalf("Login with a bad password: got %v, want 401", err)
+	}
+	auth, err := c.Login(ctx, "volunteer", "password123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth.Token == "" || c.Token() != auth.Token {
+		t.Fatal("Login should keep the JWT for later requests")
+	}
+
+	me, err := c.Me(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if me.ID != user.ID || me.Username != "volunteer" {
+		t.Errorf("Me = %d %q, want %d volunteer", me.ID, me.Username, user.ID)
+	}
+
+	page, err := c.AnimalsPage(ctx, group.ID, AnimalListOptions{Sort: "name"}, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 5 || !page.HasMore || len(page.Items) != 2 || page.Items[0].Name != "Cooper" {
+		t.Errorf("AnimalsPage = %+v, want Cooper and Daisy of 5", page)
+	}
+
+	var names []string
+	for animal, err := range Paginate(ctx, 2, func(ctx context.Context, limit, offset int) (Page[AnimalListItem], error) {
+		return c.AnimalsPage(ctx, group.ID, AnimalListOptions{Sort: "name"}, limit, offset)
+	}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, animal.Name)
+	}
+	if got := strings.Join(names, ","); got != "Ace,Bella,Cooper,Daisy,Echo" {
+		t.Errorf("paging through animals = %s", got)
+	}
+
+	facets, err := c.AnimalFacets(ctx, group.ID, AnimalListOptions{Status: "all"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if facets["size"]["unknown"] != 5 || facets["size"]["small"] != 0 {
+		t.Errorf("AnimalFacets = %v, want 5 animals without a size", facets)
+	}
+
+	created, err := c.CreateDiscussion(ctx, group.ID, DiscussionRequest{Title: "Supplies", Content: "We need towels"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	discussions, err := Collect(c.Discussions(ctx, group.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(discussions) != 1 || discussions[0].ID != created.ID {
+		t.Errorf("Discussions = %+v, want the one created", discussions)
+	}
+
+	_, err = c.CreateDiscussion(ctx, group.ID, DiscussionRequest{Title: "x"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Code != "VALIDATION_FAILED" || len(apiErr.Details) == 0 {
+		t.Errorf("invalid discussion: got %#v, want a structured validation error", err)
+	}
+	if _, err := c.Animal(ctx, group.ID+1, 1); !IsForbidden(err) {
+		t.Errorf("Animal in another group: got %v, want 403", err)
+	}
+}
+
+// TestRequestTypesMatchHandlers keeps the request bodies in this package in
+// step with the handlers that decode them
+func TestRequestTypesMatchHandlers(t *testing.T) {
+	for _, pair := range []struct{ client, server any }{
+		{LoginRequest{}, handlers.LoginRequest{}},
+		{AuthResponse{}, handlers.AuthResponse{}},
+		{AnimalRequest{}, handlers.AnimalRequest{}},
+		{AnimalCommentRequest{}, handlers.AnimalCommentRequest{}},
+		{UpdateRequest{}, handlers.UpdateRequest{}},
+		{DiscussionRequest{}, handlers.DiscussionRequest{}},
+		{DiscussionReplyRequest{}, handlers.DiscussionReplyRequest{}},
+		{DiscussionDetail{}, handlers.DiscussionDetail{}},
+		{StatusAlert{}, handlers.StatusAlert{}},
+		{FosterProfileRequest{}, handlers.FosterProfileRequest{}},
+		{FosterMatch{}, handlers.FosterMatch{}},
+		{FosterMatches{}, handlers.FosterMatches{}},
+	} {
+		got, want := jsonFields(reflect.TypeOf(pair.client)), jsonFields(reflect.TypeOf(pair.server))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%T fields %v, want %v like %T", pair.client, got, want, pair.server)
+		}
+	}
+}
+
+// jsonFields returns the JSON names of a struct's fields, including those
+// of embedded structs
+func jsonFields(t reflect.Type) map[string]bool {
+	fields := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if f.Anonymous && name == "" {
+			for n := range jsonFields(f.Type) {
+				fields[n] = true
+			}
+			continue
+		}
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = true
+	}
+	return fields
+}
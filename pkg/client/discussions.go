@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/url"
+	"strconv"
+)
+
+// DiscussionsPage returns one page of a group's discussions, most recently
+// active first, without their replies. The server caps limit at 100.
+// Route: GET /api/groups/:id/discussions
+func (c *Client) DiscussionsPage(ctx context.Context, groupID uint, limit, offset int) (Page[Discussion], error) {
+	q := url.Values{"limit": {strconv.Itoa(limit)}, "offset": {strconv.Itoa(offset)}}
+	var resp listEnvelope[Discussion]
+	if err := c.get(ctx, fmt.Sprintf("/groups/%d/discussions", groupID), q, &resp); err != nil {
+		return Page[Discussion]{}, err
+	}
+	return resp.page(), nil
+}
+
+// Discussions iterates over all of a group's discussions
+func (c *Client) Discussions(ctx context.Context, groupID uint) iter.Seq2[Discussion, error] {
+	return Paginate(ctx, DefaultPageSize, func(ctx context.Context, limit, offset int) (Page[Discussion], error) {
+		return c.DiscussionsPage(ctx, groupID, limit, offset)
+	})
+}
+
+// Discussion returns a discussion with all of its replies
+// Route: GET /api/groups/:id/discussions/:discussionId
+func (c *Client) Discussion(ctx context.Context, groupID, discussionID uint) (*DiscussionDetail, error) {
+	var detail DiscussionDetail
+	if err := c.get(ctx, fmt.Sprintf("/groups/%d/discussions/%d", groupID, discussionID), nil, &detail); err != nil {
+		return nil, err
+	}
+	return &detail, nil
+}
+
+// CreateDiscussion starts a discussion in a group
+// Route: POST /api/groups/:id/discussions
+func (c *Client) CreateDiscussion(ctx context.Context, groupID uint, req DiscussionRequest) (*Discussion, error) {
+	var discussion Discussion
+	if err := c.post(ctx, fmt.Sprintf("/groups/%d/discussions", groupID), req, &discussion); err != nil {
+		return nil, err
+	}
+	return &discussion, nil
+}
+
+// ReplyToDiscussion posts a reply, optionally to another reply
+// Route: POST /api/groups/:id/discussions/:discussionId/replies
+func (c *Client) ReplyToDiscussion(ctx context.Context, groupID, discussionID uint, req DiscussionReplyRequest) (*DiscussionReply, error) {
+	var reply DiscussionReply
+	if err := c.post(ctx, fmt.Sprintf("/groups/%d/discussions/%d/replies", groupID, discussionID), req, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+// MuteDiscussion stops or resumes reply emails for a discussion
+// Route: PUT /api/groups/:id/discussions/:discussionId/mute
+func (c *Client) MuteDiscussion(ctx context.Context, groupID, discussionID uint, muted bool) error {
+	return c.put(ctx, fmt.Sprintf("/groups/%d/discussions/%d/mute", groupID, discussionID), map[string]bool{"muted": muted}, nil)
+}
+
+// DeleteDiscussion deletes a discussion and its replies. Its author and
+// group admins only.
+// Route: DELETE /api/groups/:id/discussions/:discussionId
+func (c *Client) DeleteDiscussion(ctx context.Context, groupID, discussionID uint) error {
+	return c.delete(ctx, fmt.Sprintf("/groups/%d/discussions/%d", groupID, discussionID))
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// MyFosterProfile returns the user's foster profile in a group; its ID is
+// zero if they never saved one
+// Route: GET /api/groups/:id/foster-profile
+func (c *Client) MyFosterProfile(ctx context.Context, groupID uint) (*FosterProfile, error) {
+	var profile FosterProfile
+	if err := c.get(ctx, fmt.Sprintf("/groups/%d/foster-profile", groupID), nil, &profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// UpdateMyFosterProfile creates or replaces the user's foster profile in a
+// group
+// Route: PUT /api/groups/:id/foster-profile
+func (c *Client) UpdateMyFosterProfile(ctx context.Context, groupID uint, req FosterProfileRequest) (*FosterProfile, error) {
+	var profile FosterProfile
+	if err := c.put(ctx, fmt.Sprintf("/groups/%d/foster-profile", groupID), req, &profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// FosterMatches lists the group members who can foster an animal over a
+// date range. Group admins only.
+// Route: GET /api/groups/:id/fosters
+func (c *Client) FosterMatches(ctx context.Context, groupID uint, opts FosterMatchOptions) (*FosterMatches, error) {
+	q := url.Values{}
+	if opts.AnimalID != 0 {
+		q.Set("animal_id", strconv.FormatUint(uint64(opts.AnimalID), 10))
+	}
+	if opts.From != "" {
+		q.Set("from", opts.From)
+	}
+	if opts.To != "" {
+		q.Set("to", opts.To)
+	}
+	if opts.All {
+		q.Set("all", "true")
+	}
+	var matches FosterMatches
+	if err := c.get(ctx, fmt.Sprintf("/groups/%d/fosters", groupID), q, &matches); err != nil {
+		return nil, err
+	}
+	return &matches, nil
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"fmt"
+)
+
+// Groups lists the groups the user can see: their own, or every group for
+// site admins
+// Route: GET /api/groups
+func (c *Client) Groups(ctx context.Context) ([]Group, error) {
+	var groups []Group
+	if err := c.get(ctx, "/groups", nil, &groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// Group returns one group
+// Route: GET /api/groups/:id
+func (c *Client) Group(ctx context.Context, groupID uint) (*Group, error) {
+	var group Group
+	if err := c.get(ctx, fmt.Sprintf("/groups/%d", groupID), nil, &group); err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// Updates lists a group's updates, newest first
+// Route: GET /api/groups/:id/updates
+func (c *Client) Updates(ctx context.Context, groupID uint) ([]Update, error) {
+	var updates []Update
+	if err := c.get(ctx, fmt.Sprintf("/groups/%d/updates", groupID), nil, &updates); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+// CreateUpdate posts an update to a group, optionally emailing members and
+// posting it to GroupMe
+// Route: POST /api/groups/:id/updates
+func (c *Client) CreateUpdate(ctx context.Context, groupID uint, req UpdateRequest) (*Update, error) {
+	var update Update
+	if err := c.post(ctx, fmt.Sprintf("/groups/%d/updates", groupID), req, &update); err != nil {
+		return nil, err
+	}
+	return &update, nil
+}
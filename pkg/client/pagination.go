@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"iter"
+)
+
+// DefaultPageSize is the page size the list iterators request
+const DefaultPageSize = 100
+
+// Page is one page of a paged list
+type Page[T any] struct {
+	Items   []T
+	Total   int64 // Items across all pages
+	Limit   int
+	Offset  int
+	HasMore bool
+}
+
+// PageFunc fetches the page of at most limit items starting at offset
+type PageFunc[T any] func(ctx context.Context, limit, offset int) (Page[T], error)
+
+// Paginate iterates over every item of a paged list, requesting pageSize
+// items at a time (DefaultPageSize if zero or less). Iteration stops after
+// the first error, which is yielded with a zero item.
+func Paginate[T any](ctx context.Context, pageSize int, fetch PageFunc[T]) iter.Seq2[T, error] {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	return func(yield func(T, error) bool) {
+		offset := 0
+		for {
+			page, err := fetch(ctx, pageSize, offset)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range page.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			offset += len(page.Items)
+			if !page.HasMore || len(page.Items) == 0 {
+				return
+			}
+		}
+	}
+}
+
+// Collect gathers the items of an iterator such as Client.Animals into a
+// slice, stopping at the first error
+func Collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// listEnvelope is the body of list endpoints that page with limit and
+// offset and report the total alongside the items
+type listEnvelope[T any] struct {
+	Items   []T   `json:"items"`
+	Total   int64 `json:"total"`
+	Limit   int   `json:"limit"`
+	Offset  int   `json:"offset"`
+	HasMore bool  `json:"hasMore"`
+}
+
+func (e listEnvelope[T]) page() Page[T] {
+	return Page[T]{Items: e.Items, Total: e.Total, Limit: e.Limit, Offset: e.Offset, HasMore: e.HasMore}
+}
@@ -0,0 +1,183 @@
+package client
+
+import (
+	"time"
+
+	"github.com/networkengineer-cloud/go-volunteer-media/internal/models"
+)
+
+// Response types are the server's models, aliased so callers outside this
+// module can name them
+type (
+	User            = models.User
+	Group           = models.Group
+	Animal          = models.Animal
+	AnimalTag       = models.AnimalTag
+	AnimalComment   = models.AnimalComment
+	SessionMetadata = models.SessionMetadata
+	Update          = models.Update
+	Discussion      = models.Discussion
+	DiscussionReply = models.DiscussionReply
+	FosterProfile   = models.FosterProfile
+	FosterBlackout  = models.FosterBlackout
+)
+
+// LoginRequest mirrors handlers.LoginRequest
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Session  string `json:"session,omitempty"` // "bearer" returns the JWT even when the server uses cookie sessions
+}
+
+// AuthResponse mirrors handlers.AuthResponse
+type AuthResponse struct {
+	Token     string     `json:"token,omitempty"`
+	CSRFToken string     `json:"csrf_token,omitempty"` // Cookie sessions only
+	User      User       `json:"user"`
+	LastLogin *time.Time `json:"last_login,omitempty"`
+}
+
+// CurrentUser is the response of GET /me: the user plus whether they
+// administer any group
+type CurrentUser struct {
+	User
+	IsGroupAdmin bool `json:"is_group_admin"`
+}
+
+// AnimalRequest mirrors handlers.AnimalRequest. Dates are YYYY-MM-DD or
+// RFC 3339 strings. Pointer fields are left unchanged on update when nil.
+type AnimalRequest struct {
+	Name                      string         `json:"name"`
+	Species                   string         `json:"species"`
+	Breed                     string         `json:"breed"`
+	Age                       int            `json:"age"`
+	EstimatedBirthDate        *string        `json:"estimated_birth_date,omitempty"`
+	BirthDate                 *string        `json:"birth_date,omitempty"`
+	Description               string         `json:"description"`
+	TrainerNotes              string         `json:"trainer_notes"`
+	ImageURL                  string         `json:"image_url,omitempty"`
+	Status                    string         `json:"status"`
+	GroupID                   uint           `json:"group_id,omitempty"`
+	ArrivalDate               *string        `json:"arrival_date,omitempty"`
+	QuarantineStartDate       *string        `json:"quarantine_start_date,omitempty"`
+	QuarantineEndDate         *string        `json:"quarantine_end_date,omitempty"`
+	QuarantineApprovalStatus  *string        `json:"quarantine_approval_status,omitempty"`
+	QuarantineIncidentDetails *string        `json:"quarantine_incident_details,omitempty"`
+	IsReturned                *bool          `json:"is_returned,omitempty"`
+	CustomFields              map[string]any `json:"custom_fields,omitempty"`
+	IntakeSource              *string        `json:"intake_source,omitempty"`
+	Outcome                   *string        `json:"outcome,omitempty"`
+	Size                      *string        `json:"size,omitempty"`
+	EnergyLevel               *string        `json:"energy_level,omitempty"`
+	MicrochipNumber           *string        `json:"microchip_number,omitempty"`
+	LicenseNumber             *string        `json:"license_number,omitempty"`
+	OverrideChecklist         bool           `json:"override_checklist,omitempty"`
+}
+
+// AnimalListItem is one animal in a group's animal list, with its media
+// counts and status alerts
+type AnimalListItem struct {
+	Animal
+	ImageCount int           `json:"image_count"`
+	VideoCount int           `json:"video_count"`
+	Alerts     []StatusAlert `json:"alerts"`
+}
+
+// StatusAlert mirrors handlers.StatusAlert: an animal has been in a status
+// longer than its group allows
+type StatusAlert struct {
+	RuleID  uint      `json:"rule_id"`
+	Status  string    `json:"status"`
+	MaxDays int       `json:"max_days"`
+	Days    int       `json:"days"`
+	Since   time.Time `json:"since"`
+	Message string    `json:"message,omitempty"`
+}
+
+// AnimalListOptions filters and sorts a group's animal list. Zero values
+// use the group's defaults.
+type AnimalListOptions struct {
+	Status      string            // Comma-separated statuses, or "all"
+	Name        string            // Case-insensitive name search
+	Sort        string            // A sort key, e.g. "name" or "arrival_date"
+	Order       string            // "asc" or "desc"
+	Fields      map[string]string // Custom field filters, by field key
+	Size        []string          // Any of these sizes; "unknown" matches animals without one
+	EnergyLevel []string          // Any of these energy levels; "unknown" matches animals without one
+}
+
+// AnimalFacetCounts mirrors handlers.AnimalFacetCounts: per facet ("size",
+// "energy_level"), the number of animals with each value
+type AnimalFacetCounts map[string]map[string]int64
+
+// AnimalCommentRequest mirrors handlers.AnimalCommentRequest
+type AnimalCommentRequest struct {
+	Content  string           `json:"content"`
+	ImageURL string           `json:"image_url"`
+	TagIDs   []uint           `json:"tag_ids"`
+	Metadata *SessionMetadata `json:"metadata"`
+}
+
+// UpdateRequest mirrors handlers.UpdateRequest
+type UpdateRequest struct {
+	Title       string `json:"title"`
+	Content     string `json:"content"`
+	ImageURL    string `json:"image_url"`
+	SendEmail   bool   `json:"send_email"`
+	SendGroupMe bool   `json:"send_groupme"`
+}
+
+// DiscussionRequest mirrors handlers.DiscussionRequest
+type DiscussionRequest struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+// DiscussionReplyRequest mirrors handlers.DiscussionReplyRequest
+type DiscussionReplyRequest struct {
+	Content  string `json:"content"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+// DiscussionDetail mirrors handlers.DiscussionDetail
+type DiscussionDetail struct {
+	Discussion
+	Replies []DiscussionReply `json:"replies"`
+	Muted   bool              `json:"muted"`
+}
+
+// FosterProfileRequest mirrors handlers.FosterProfileRequest
+type FosterProfileRequest struct {
+	Available     bool             `json:"available"`
+	Capacity      int              `json:"capacity"`
+	CurrentCount  int              `json:"current_count"`
+	Species       []string         `json:"species"`
+	Sizes         []string         `json:"sizes"`
+	BlackoutDates []FosterBlackout `json:"blackout_dates"`
+	Notes         string           `json:"notes"`
+}
+
+// FosterMatch mirrors handlers.FosterMatch
+type FosterMatch struct {
+	Profile   FosterProfile `json:"profile"`
+	OpenSlots int           `json:"open_slots"`
+	Match     bool          `json:"match"`
+	Reasons   []string      `json:"reasons,omitempty"`
+}
+
+// FosterMatches mirrors handlers.FosterMatches
+type FosterMatches struct {
+	AnimalID   *uint         `json:"animal_id,omitempty"`
+	AnimalSize string        `json:"animal_size,omitempty"`
+	From       string        `json:"from"`
+	To         string        `json:"to"`
+	Items      []FosterMatch `json:"items"`
+}
+
+// FosterMatchOptions narrows GetFosterMatches. Dates are YYYY-MM-DD.
+type FosterMatchOptions struct {
+	AnimalID uint
+	From     string
+	To       string
+	All      bool // Include profiles that don't match, with their reasons
+}